@@ -0,0 +1,70 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+	"github.com/mitchellh/cli"
+)
+
+// userAliases maps the names of the user-defined commands from the "alias"
+// block of the CLI configuration to the arguments they stand for. Aliases
+// that would shadow a built-in command are not included.
+var userAliases map[string]string
+
+// initAliases sets userAliases from the given CLI configuration, warning
+// about any alias that has the same name as a built-in command.
+func initAliases(configured map[string]string, commands map[string]cli.CommandFactory, ui cli.Ui) {
+	userAliases = make(map[string]string, len(configured))
+	for name, value := range configured {
+		if _, exists := commands[name]; exists || name == "help" {
+			ui.Warn(fmt.Sprintf("Ignoring the alias %q in the CLI configuration, because there is already a command with that name.", name))
+			continue
+		}
+		userAliases[name] = value
+	}
+}
+
+// expandAlias replaces a user-defined alias given as the subcommand in args
+// with the arguments it stands for, keeping any arguments that follow it.
+//
+// Aliases are expanded only once, so an alias can't refer to another alias.
+func expandAlias(aliases map[string]string, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	value, ok := aliases[args[0]]
+	if !ok {
+		return args, nil
+	}
+
+	expanded, err := shellwords.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing the alias %q from the CLI configuration: %s", args[0], err)
+	}
+	log.Printf("[INFO] Expanding alias %q to %q", args[0], expanded)
+	return append(expanded, args[1:]...), nil
+}
+
+// listAliases lists the given aliases along with what they expand to, in
+// the same layout as listCommands.
+func listAliases(aliases map[string]string, maxKeyLen int) string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	for _, name := range names {
+		key := fmt.Sprintf("%s%s", name, strings.Repeat(" ", maxKeyLen-len(name)))
+		buf.WriteString(fmt.Sprintf("  %s  Alias for %q\n", key, aliases[name]))
+	}
+	return buf.String()
+}
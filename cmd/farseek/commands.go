@@ -7,6 +7,9 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/go-retryablehttp"
@@ -21,7 +24,9 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/command/views"
 	"github.com/rafagsiqueira/farseek/internal/getmodules"
 	"github.com/rafagsiqueira/farseek/internal/getproviders"
+	"github.com/rafagsiqueira/farseek/internal/planlimits"
 	pluginDiscovery "github.com/rafagsiqueira/farseek/internal/plugin/discovery"
+	"github.com/rafagsiqueira/farseek/internal/runtask"
 	"github.com/rafagsiqueira/farseek/internal/terminal"
 )
 
@@ -30,6 +35,19 @@ import (
 // that assume that Farseek is being run from a command prompt.
 const runningInAutomationEnvName = "TF_IN_AUTOMATION"
 
+// offlineEnvName gives the name of an environment variable that can be set
+// to 1 or true to run commands in offline mode, as with the -offline option
+// of the init command.
+const offlineEnvName = "FARSEEK_OFFLINE"
+
+// recordEnvName and replayEnvName give the names of environment variables
+// that can be set to a directory to record the calls to providers there, or
+// to replay the calls recorded there instead of starting the providers.
+const (
+	recordEnvName = "FARSEEK_RECORD"
+	replayEnvName = "FARSEEK_REPLAY"
+)
+
 // commands is the mapping of all the available Farseek commands.
 var commands map[string]cli.CommandFactory
 
@@ -53,6 +71,10 @@ var hiddenCommands map[string]struct{}
 // Ui is the cli.Ui used for communicating to the outside world.
 var Ui cli.Ui
 
+// commandView is the view shared by all commands, which realMain uses to
+// summarize the codes of the errors that a failed command reported.
+var commandView *views.View
+
 func initCommands(
 	ctx context.Context,
 	originalWorkingDir string,
@@ -69,6 +91,8 @@ func initCommands(
 		inAutomation = true
 	}
 
+	offline, _ := strconv.ParseBool(os.Getenv(offlineEnvName))
+
 	for userHost, hostConfig := range config.Hosts {
 		host, err := svchost.ForComparison(userHost)
 		if err != nil {
@@ -86,10 +110,13 @@ func initCommands(
 
 	wd := workingDir(originalWorkingDir, os.Getenv("TF_DATA_DIR"))
 
+	preApplyHooks, postApplyHooks := externalApplyHooks(config)
+
+	commandView = views.NewView(streams).SetRunningInAutomation(inAutomation).SetSuppressedWarnings(config.SuppressWarnings)
 	meta := command.Meta{
 		WorkingDir: wd,
 		Streams:    streams,
-		View:       views.NewView(streams).SetRunningInAutomation(inAutomation),
+		View:       commandView,
 
 		Color:            true,
 		GlobalPluginDirs: globalPluginDirs(),
@@ -99,6 +126,7 @@ func initCommands(
 		BrowserLauncher: browserLauncher(),
 
 		RunningInAutomation: inAutomation,
+		Offline:             offline,
 		CLIConfigDir:        configDir,
 		PluginCacheDir:      config.PluginCacheDir,
 
@@ -114,10 +142,32 @@ func initCommands(
 			return newRegistryHTTPClient(ctx, config.RegistryProtocols)
 		},
 		ModulePackageFetcher: modulePkgFetcher,
+		OCIModulePackagePushStore: func(ctx context.Context, registryDomain, repositoryName string) (getmodules.OCIRepositoryPushStore, error) {
+			return ociModulePackagePushStore(ctx, config.OCICredentialsPolicy, registryDomain, repositoryName)
+		},
 		ProviderSource:       providerSrc,
 		ProviderDevOverrides: providerDevOverrides,
 		UnmanagedProviders:   unmanagedProviders,
 
+		StrictProviderDevOverrides: config.StrictProviderDevOverrides,
+
+		ProviderCredentialsHelpers: providerCredentialsHelpers(config),
+		ProviderGRPC:               providerGRPCOptions(config),
+		ProviderRecorder:           providerRecorder(),
+		ProviderReplayer:           providerReplayer(),
+		DefaultTags:                providerDefaultTags(config),
+
+		RequireSignedCommits:   config.RequireSignedCommits,
+		TrustedSigningKeys:     config.TrustedSigningKeys,
+		ApplyBranches:          config.ApplyBranches,
+		TagPolicyPath:          config.TagPolicy,
+		ModuleDeprecationsPath: config.ModuleDeprecations,
+		PlanLimits:             planLimits(config),
+		RunTasks:               runTasks(config),
+
+		PreApplyHooks:  preApplyHooks,
+		PostApplyHooks: postApplyHooks,
+
 		AllowExperimentalFeatures: experimentsAreAllowed(),
 
 		// ProviderSourceLocationConfig is used for some commands that do not make
@@ -133,6 +183,18 @@ func initCommands(
 	// that to match.
 
 	commands = map[string]cli.CommandFactory{
+		"adopt": func() (cli.Command, error) {
+			return &command.AdoptCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"agent": func() (cli.Command, error) {
+			return &command.AgentCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"apply": func() (cli.Command, error) {
 			return &command.ApplyCommand{
 				Meta: meta,
@@ -145,6 +207,12 @@ func initCommands(
 			}, nil
 		},
 
+		"convert-state": func() (cli.Command, error) {
+			return &command.ConvertStateCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"destroy": func() (cli.Command, error) {
 			return &command.ApplyCommand{
 				Meta:    meta,
@@ -152,6 +220,12 @@ func initCommands(
 			}, nil
 		},
 
+		"doctor": func() (cli.Command, error) {
+			return &command.DoctorCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"env": func() (cli.Command, error) {
 			return &command.WorkspaceCommand{
 				Meta:       meta,
@@ -217,18 +291,60 @@ func initCommands(
 			}, nil
 		},
 
+		"inventory": func() (cli.Command, error) {
+			return &command.InventoryCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"lint": func() (cli.Command, error) {
+			return &command.LintCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"metadata": func() (cli.Command, error) {
 			return &command.MetadataCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"metadata dump": func() (cli.Command, error) {
+			return &command.MetadataDumpCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"metadata functions": func() (cli.Command, error) {
 			return &command.MetadataFunctionsCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"mirror-serve": func() (cli.Command, error) {
+			return &command.MirrorServeCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"modules": func() (cli.Command, error) {
+			return &command.ModulesCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"modules outdated": func() (cli.Command, error) {
+			return &command.ModulesOutdatedCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"modules tree": func() (cli.Command, error) {
+			return &command.ModulesTreeCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"output": func() (cli.Command, error) {
 			return &command.OutputCommand{
 				Meta: meta,
@@ -241,12 +357,24 @@ func initCommands(
 			}, nil
 		},
 
+		"preflight": func() (cli.Command, error) {
+			return &command.PreflightCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"providers": func() (cli.Command, error) {
 			return &command.ProvidersCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"providers dev-status": func() (cli.Command, error) {
+			return &command.ProvidersDevStatusCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"providers lock": func() (cli.Command, error) {
 			return &command.ProvidersLockCommand{
 				Meta: meta,
@@ -265,18 +393,103 @@ func initCommands(
 			}, nil
 		},
 
+		"providers unlock": func() (cli.Command, error) {
+			return &command.ProvidersUnlockCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"providers upgrade": func() (cli.Command, error) {
+			return &command.ProvidersLockCommand{
+				Meta:    meta,
+				Upgrade: true,
+			}, nil
+		},
+
 		"push": func() (cli.Command, error) {
 			return &command.PushCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"recover": func() (cli.Command, error) {
+			return &command.RecoverCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"recover rebuild-state": func() (cli.Command, error) {
+			return &command.RecoverRebuildStateCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"revert": func() (cli.Command, error) {
+			return &command.RevertCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"run-all": func() (cli.Command, error) {
+			return &command.RunAllCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"sbom": func() (cli.Command, error) {
+			return &command.SBOMCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"registry": func() (cli.Command, error) {
+			return &command.RegistryCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"registry publish-module": func() (cli.Command, error) {
+			return &command.RegistryPublishModuleCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"show": func() (cli.Command, error) {
 			return &command.ShowCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"state": func() (cli.Command, error) {
+			return &command.StateCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state forget": func() (cli.Command, error) {
+			return &command.StateForgetCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state pull": func() (cli.Command, error) {
+			return &command.StatePullCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state push": func() (cli.Command, error) {
+			return &command.StatePushCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"state query": func() (cli.Command, error) {
+			return &command.StateQueryCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"taint": func() (cli.Command, error) {
 			return &command.TaintCommand{
 				Meta: meta,
@@ -395,6 +608,102 @@ func credentialsSource(config *cliconfig.Config) (svcauth.CredentialsSource, err
 	return config.CredentialsSource(helperPlugins)
 }
 
+// externalApplyHooks returns the programs that the "hooks" blocks of the
+// given CLI configuration run before and after each change that apply makes.
+func externalApplyHooks(config *cliconfig.Config) (pre, post []*command.ExternalApplyHook) {
+	for _, hooks := range config.Hooks {
+		if hooks == nil {
+			continue
+		}
+		for _, hook := range hooks.PreApply {
+			if hook != nil && hook.Command != "" {
+				pre = append(pre, externalApplyHook(hook))
+			}
+		}
+		for _, hook := range hooks.PostApply {
+			if hook != nil && hook.Command != "" {
+				post = append(post, externalApplyHook(hook))
+			}
+		}
+	}
+	return pre, post
+}
+
+func externalApplyHook(hook *cliconfig.ConfigApplyHook) *command.ExternalApplyHook {
+	// We expect the config was already validated by the time we get here,
+	// so an invalid timeout just means the default.
+	timeout, _ := time.ParseDuration(hook.Timeout)
+	return &command.ExternalApplyHook{
+		ResourceTypes:     hook.ResourceTypes,
+		Command:           hook.Command,
+		Args:              hook.Args,
+		Timeout:           timeout,
+		ContinueOnFailure: hook.OnFailure == "continue",
+	}
+}
+
+// runTasks returns the run tasks of the given CLI configuration, ordered by
+// name so that they're called in a predictable order.
+func runTasks(config *cliconfig.Config) []*runtask.Task {
+	names := make([]string, 0, len(config.RunTasks))
+	for name := range config.RunTasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ret []*runtask.Task
+	for _, name := range names {
+		task := config.RunTasks[name]
+		if task == nil || task.URL == "" {
+			continue
+		}
+		// We expect the config was already validated by the time we get
+		// here, so empty or invalid settings just mean the defaults.
+		timeout, _ := time.ParseDuration(task.Timeout)
+		stage := runtask.PostPlan
+		if task.Stage == string(runtask.PrePlan) {
+			stage = runtask.PrePlan
+		}
+		level := runtask.Mandatory
+		if task.EnforcementLevel == string(runtask.Advisory) {
+			level = runtask.Advisory
+		}
+		ret = append(ret, &runtask.Task{
+			Name:             name,
+			URL:              task.URL,
+			Stage:            stage,
+			EnforcementLevel: level,
+			Timeout:          timeout,
+		})
+	}
+	return ret
+}
+
+// planLimits returns the limits from the "plan_limits" blocks of the CLI
+// configuration. If several blocks set the same limit, the last one wins.
+func planLimits(config *cliconfig.Config) planlimits.Limits {
+	const source = "the plan_limits block of the CLI configuration"
+	limit := func(max *int) *planlimits.Limit {
+		if max == nil {
+			return nil
+		}
+		return &planlimits.Limit{Max: *max, Source: source}
+	}
+
+	var ret planlimits.Limits
+	for _, block := range config.PlanLimits {
+		if block == nil {
+			continue
+		}
+		ret = ret.Override(planlimits.Limits{
+			Create:  limit(block.MaxCreate),
+			Update:  limit(block.MaxUpdate),
+			Destroy: limit(block.MaxDestroy),
+		})
+	}
+	return ret
+}
+
 func getAliasCommandKeys() []string {
 	keys := []string{}
 	for key, cmdFact := range commands {
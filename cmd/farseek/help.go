@@ -43,6 +43,16 @@ func helpFunc(commands map[string]cli.CommandFactory) string {
 	}
 	sort.Strings(otherCommands)
 
+	for name := range userAliases {
+		if len(name) > maxKeyLen {
+			maxKeyLen = len(name)
+		}
+	}
+	var aliasesText string
+	if len(userAliases) > 0 {
+		aliasesText = "\nAliases from the CLI configuration:\n" + listAliases(userAliases, maxKeyLen)
+	}
+
 	// The output produced by this is included in the docs at
 	// website/source/docs/cli/commands/index.html.markdown; if you
 	// change this then consider updating that to match.
@@ -56,13 +66,13 @@ less common or more advanced commands.
 Main commands:
 %s
 All other commands:
-%s
+%s%s
 Global options (use these before the subcommand, if any):
   -chdir=DIR    Switch to a different working directory before executing the
                 given subcommand.
   -help         Show this help output, or the help for a specified subcommand.
   -version      An alias for the "version" subcommand.
-`, listCommands(commands, primaryCommands, maxKeyLen), listCommands(commands, otherCommands, maxKeyLen))
+`, listCommands(commands, primaryCommands, maxKeyLen), listCommands(commands, otherCommands, maxKeyLen), aliasesText)
 
 	return strings.TrimSpace(helpText)
 }
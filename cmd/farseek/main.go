@@ -281,6 +281,8 @@ func realMain() int {
 		initCommands(ctx, originalWd, streams, config, services, modulePkgFetcher, providerSrc, providerDevOverrides, unmanagedProviders)
 	}
 
+	initAliases(config.Aliases, commands, Ui)
+
 	// Attempt to ensure the config directory exists.
 	configDir, err := cliconfig.ConfigDir()
 	if err != nil {
@@ -292,6 +294,19 @@ func realMain() int {
 	// Make sure we clean up any managed plugins at the end of this
 	defer plugin.CleanupClients()
 
+	// "farseek help [subcommand]" is the same as "farseek -help [subcommand]".
+	if len(args) > 0 && args[0] == "help" {
+		args = append([]string{"-help"}, args[1:]...)
+	}
+
+	// User-defined aliases are expanded before anything else looks at the
+	// subcommand, so they behave exactly like typing out the full command.
+	args, err = expandAlias(userAliases, args)
+	if err != nil {
+		Ui.Error(err.Error())
+		return 1
+	}
+
 	// Build the CLI so far, we do this so we can query the subcommand.
 	cliRunner := &cli.CLI{
 		Args:       args,
@@ -391,6 +406,9 @@ func realMain() int {
 		for _, panicLog := range logging.PluginPanics() {
 			Ui.Error(panicLog)
 		}
+		if commandView != nil {
+			commandView.ErrorCodeSummary()
+		}
 	}
 
 	return exitCode
@@ -365,3 +365,85 @@ func TestMkConfigDir_noparent(t *testing.T) {
 		t.Fatalf("Expected error: %s, but got: %v", expectedError, err)
 	}
 }
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"preview": "plan -compact-warnings",
+		"quoted":  `plan -var 'name=hello world'`,
+		"nested":  "preview -detailed-exitcode",
+	}
+
+	tests := map[string]struct {
+		args []string
+		want []string
+	}{
+		"no args": {
+			nil,
+			nil,
+		},
+		"not an alias": {
+			[]string{"plan", "-out=tfplan"},
+			[]string{"plan", "-out=tfplan"},
+		},
+		"alias with extra args": {
+			[]string{"preview", "-out=tfplan"},
+			[]string{"plan", "-compact-warnings", "-out=tfplan"},
+		},
+		"alias with quoted args": {
+			[]string{"quoted"},
+			[]string{"plan", "-var", "name=hello world"},
+		},
+		"alias is only expanded once": {
+			[]string{"nested"},
+			[]string{"preview", "-detailed-exitcode"},
+		},
+		"alias only in subcommand position": {
+			[]string{"-help", "preview"},
+			[]string{"-help", "preview"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := expandAlias(aliases, test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMain_alias(t *testing.T) {
+	// Restore original CLI args
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	// Set up test command and restore that
+	commands = make(map[string]cli.CommandFactory)
+	defer func() {
+		commands = nil
+	}()
+	testCommandName := "unit-test-cli-args"
+	testCommand := &testCommandCLI{}
+	commands[testCommandName] = func() (cli.Command, error) {
+		return testCommand, nil
+	}
+
+	configFile := filepath.Join(t.TempDir(), "farseekrc")
+	config := fmt.Sprintf("alias {\n  short = %q\n}\n", testCommandName+" -foo")
+	if err := os.WriteFile(configFile, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TF_CLI_CONFIG_FILE", configFile)
+
+	os.Args = []string{"farseek", "short", "bar"}
+	if exit := realMain(); exit != 0 {
+		t.Fatalf("unexpected exit status %d; want 0", exit)
+	}
+	if want := []string{"-foo", "bar"}; !reflect.DeepEqual(testCommand.Args, want) {
+		t.Fatalf("wrong args\ngot:  %#v\nwant: %#v", testCommand.Args, want)
+	}
+}
@@ -36,3 +36,14 @@ func (m *modulePackageFetcherEnvironment) OCIRepositoryStore(ctx context.Context
 	}
 	return getOCIRepositoryStore(ctx, registryDomainName, repositoryPath, credsPolicy)
 }
+
+// ociModulePackagePushStore returns the repository that
+// "farseek registry publish-module" publishes a module package to, when its
+// destination is an OCI repository.
+func ociModulePackagePushStore(ctx context.Context, getOCICredsPolicy ociCredsPolicyBuilder, registryDomainName string, repositoryPath string) (getmodules.OCIRepositoryPushStore, error) {
+	credsPolicy, err := getOCICredsPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials configuration for OCI registries: %w", err)
+	}
+	return getOCIRepositoryStore(ctx, registryDomainName, repositoryPath, credsPolicy)
+}
@@ -161,9 +161,9 @@ func getOCIRepositoryORASClient(ctx context.Context, registryDomain, repositoryN
 	}, nil
 }
 
-// ociRepositoryStore represents the combined needs of both
-// [getproviders.OCIRepositoryStore] and [getmodules.OCIRepositoryStore],
-// both of which are intentionally defined to be subsets of the API
+// ociRepositoryStore represents the combined needs of
+// [getproviders.OCIRepositoryStore], [getmodules.OCIRepositoryStore] and
+// [getmodules.OCIRepositoryPushStore], all of which are intentionally defined to be subsets of the API
 // used by ORAS-Go so that we can use the implementations from that
 // library without directly exposing any ORAS-Go symbols in the
 // public API of any of our packages, since we want to reserve the
@@ -171,6 +171,7 @@ func getOCIRepositoryORASClient(ctx context.Context, registryDomain, repositoryN
 type ociRepositoryStore interface {
 	getproviders.OCIRepositoryStore
 	getmodules.OCIRepositoryStore
+	getmodules.OCIRepositoryPushStore
 }
 
 // ociCredentialsLookupEnv is our implementation of ociauthconfig.CredentialsLookupEnvironment
@@ -15,8 +15,11 @@ import (
 	"github.com/opentofu/svchost/disco"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command"
 	"github.com/rafagsiqueira/farseek/internal/command/cliconfig"
+	"github.com/rafagsiqueira/farseek/internal/defaulttags"
 	"github.com/rafagsiqueira/farseek/internal/getproviders"
+	"github.com/rafagsiqueira/farseek/internal/providerrecord"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -298,6 +301,88 @@ func providerDevOverrides(configs []*cliconfig.ProviderInstallation) map[addrs.P
 	return configs[0].DevOverrides
 }
 
+// providerCredentialsHelpers returns the credentials helper programs from
+// the given CLI configuration, keyed by the provider they supply.
+func providerCredentialsHelpers(config *cliconfig.Config) map[addrs.Provider]*command.ProviderCredentialsHelper {
+	if len(config.ProviderCredentialsHelpers) == 0 {
+		return nil
+	}
+
+	ret := make(map[addrs.Provider]*command.ProviderCredentialsHelper, len(config.ProviderCredentialsHelpers))
+	for givenAddr, helper := range config.ProviderCredentialsHelpers {
+		addr, diags := addrs.ParseProviderSourceString(givenAddr)
+		if diags.HasErrors() || helper == nil {
+			// We expect the config was already validated by the time we get
+			// here, so we'll just ignore invalid blocks.
+			continue
+		}
+		ret[addr] = &command.ProviderCredentialsHelper{
+			Command: helper.Command,
+			Args:    helper.Args,
+		}
+	}
+	return ret
+}
+
+// providerGRPCOptions returns the settings for the gRPC connections to
+// providers from the given CLI configuration, or nil if it has none.
+func providerGRPCOptions(config *cliconfig.Config) *command.ProviderGRPCOptions {
+	if config.ProviderGRPC == nil {
+		return nil
+	}
+	return &command.ProviderGRPCOptions{
+		MaxRecvMsgSize:    config.ProviderGRPC.MaxRecvMsgSize,
+		MaxSendMsgSize:    config.ProviderGRPC.MaxSendMsgSize,
+		KeepaliveInterval: config.ProviderGRPC.KeepaliveInterval,
+		KeepaliveTimeout:  config.ProviderGRPC.KeepaliveTimeout,
+		CallTimeout:       config.ProviderGRPC.CallTimeout,
+	}
+}
+
+// providerRecorder returns the recorder of the calls to providers that the
+// environment asks for, if any. Replaying takes precedence over recording,
+// so a recorder is never returned along with a replayer.
+func providerRecorder() *providerrecord.Recorder {
+	dir := os.Getenv(recordEnvName)
+	if dir == "" || os.Getenv(replayEnvName) != "" {
+		return nil
+	}
+	return providerrecord.NewRecorder(dir)
+}
+
+// providerReplayer returns the replayer of recorded calls to providers that
+// the environment asks for, if any.
+func providerReplayer() *providerrecord.Replayer {
+	dir := os.Getenv(replayEnvName)
+	if dir == "" {
+		return nil
+	}
+	return providerrecord.NewReplayer(dir)
+}
+
+// providerDefaultTags returns the default tags from the given CLI
+// configuration, keyed by the provider whose resources get them.
+func providerDefaultTags(config *cliconfig.Config) map[addrs.Provider]*defaulttags.Defaults {
+	if len(config.DefaultTags) == 0 {
+		return nil
+	}
+
+	ret := make(map[addrs.Provider]*defaulttags.Defaults, len(config.DefaultTags))
+	for givenAddr, defaults := range config.DefaultTags {
+		addr, diags := addrs.ParseProviderSourceString(givenAddr)
+		if diags.HasErrors() || defaults == nil {
+			// We expect the config was already validated by the time we get
+			// here, so we'll just ignore invalid blocks.
+			continue
+		}
+		ret[addr] = &defaulttags.Defaults{
+			Attribute: defaults.Attribute,
+			Tags:      defaults.Tags,
+		}
+	}
+	return ret
+}
+
 // providerSourceLocationConfig is meant to build a global configuration for the
 // remote locations to download a provider from. This is built out of the
 // TF_PROVIDER_DOWNLOAD_RETRY env variable and is meant to be passed through
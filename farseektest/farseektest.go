@@ -0,0 +1,330 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package farseektest helps module authors test their configurations with
+// Go tests, by planning them in-process with mock providers and comparing
+// the rendered plan with a golden file:
+//
+//	func TestPlan(t *testing.T) {
+//		schemas, err := os.ReadFile("testdata/schemas.json")
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		farseektest.AssertPlan(t, ".", "testdata/plan.golden", farseektest.Options{
+//			Schemas:   schemas,
+//			Variables: map[string]string{"environment": "prod"},
+//		})
+//	}
+//
+// The schemas are the output of farseek providers schema -json for the
+// configuration. Run the tests with the FARSEEKTEST_UPDATE environment
+// variable set to write the golden files.
+package farseektest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/mitchellh/colorstring"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	terraformProvider "github.com/rafagsiqueira/farseek/internal/builtin/providers/tf"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonformat"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonprovider"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configload"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/registry"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/terminal"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// UpdateEnvName is the name of the environment variable that makes
+// [AssertPlan] write the golden files instead of comparing with them, when
+// it's set to any non-empty value.
+const UpdateEnvName = "FARSEEKTEST_UPDATE"
+
+// Options are the options for planning a configuration.
+type Options struct {
+	// Schemas is the output of farseek providers schema -json for the
+	// configuration. Each provider in it is replaced by a mock provider
+	// with its schema, which plans each resource as its configuration says,
+	// with the computed attributes unknown until apply.
+	Schemas []byte
+
+	// DataSources are the results of reading data sources, by data source
+	// type, each a JSON object of some of the attributes of the data source.
+	// The other attributes are as the configuration sets them, or null.
+	DataSources map[string]string
+
+	// Variables are the values of the input variables of the root module,
+	// as they'd be given with the -var option.
+	Variables map[string]string
+}
+
+// Plan plans the configuration in the given directory with mock providers,
+// and returns the plan as farseek plan renders it, without colors. It plans
+// as if nothing had been applied yet, so every resource is to be created.
+//
+// Plan fails the test if the configuration is invalid or the plan has
+// errors.
+func Plan(t *testing.T, dir string, opts Options) string {
+	t.Helper()
+
+	schemas, err := parseSchemas(opts.Schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataSources, err := parseDataSources(opts.DataSources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := t.Context()
+	config, diags := loadConfig(ctx, t, dir, opts.Variables)
+	if diags.HasErrors() {
+		t.Fatalf("invalid configuration: %s", diags.Err())
+	}
+
+	factories := map[addrs.Provider]providers.Factory{
+		addrs.NewBuiltInProvider("terraform"): providers.FactoryFixed(terraformProvider.NewProvider()),
+	}
+	for addr, schema := range schemas {
+		if addr.IsBuiltIn() {
+			continue
+		}
+		factories[addr] = providers.FactoryFixed(mockProvider(schema, dataSources))
+	}
+	fctx, diags := farseek.NewContext(&farseek.ContextOpts{Providers: factories})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	variables, diags := inputValues(config, opts.Variables)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	state := states.NewState()
+	plan, diags := fctx.Plan(ctx, config, state, &farseek.PlanOpts{
+		Mode:         plans.NormalMode,
+		SetVariables: variables,
+	})
+	if diags.HasErrors() {
+		t.Fatalf("plan failed: %s", diags.Err())
+	}
+	for _, diag := range diags {
+		desc := diag.Description()
+		t.Logf("Warning: %s: %s", desc.Summary, desc.Detail)
+	}
+
+	planSchemas, diags := fctx.Schemas(ctx, config, state)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	return render(t, plan, planSchemas)
+}
+
+// AssertPlan plans the configuration in the given directory like [Plan], and
+// fails the test if the rendered plan differs from the contents of the
+// golden file, showing how. If the environment variable named by
+// [UpdateEnvName] is set, AssertPlan writes the rendered plan to the golden
+// file instead.
+func AssertPlan(t *testing.T, dir, golden string, opts Options) {
+	t.Helper()
+
+	got := Plan(t, dir, opts)
+	if os.Getenv(UpdateEnvName) != "" {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %s; set %s=1 to write it", err, UpdateEnvName)
+	}
+	if diff := cmp.Diff(string(want), got); diff != "" {
+		t.Errorf("plan differs from %s; set %s=1 to update it\n%s", golden, UpdateEnvName, diff)
+	}
+}
+
+// loadConfig installs the modules of the configuration in the given
+// directory into a temporary directory, and loads it.
+func loadConfig(ctx context.Context, t *testing.T, dir string, vars map[string]string) (*configs.Config, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	call := configs.NewStaticModuleCall(addrs.RootModule, func(variable *configs.Variable) (cty.Value, hcl.Diagnostics) {
+		raw, ok := vars[variable.Name]
+		if !ok {
+			if variable.Required() {
+				return cty.NilVal, hcl.Diagnostics{&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "No value for required variable",
+					Detail:   fmt.Sprintf("The root module input variable %q is not set, and has no default value. Set it in Options.Variables.", variable.Name),
+					Subject:  variable.DeclRange.Ptr(),
+				}}
+			}
+			return variable.Default, nil
+		}
+		return variable.ParsingMode.Parse(variable.Name, raw)
+	}, dir, "default")
+
+	loader := configload.NewLoaderForTests(t)
+	inst := initwd.NewModuleInstaller(loader.ModulesDir(), loader, registry.NewClient(ctx, nil, nil), nil)
+	_, moreDiags := inst.InstallModules(ctx, dir, "tests", true, false, initwd.ModuleInstallHooksImpl{}, call)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if err := loader.RefreshModules(); err != nil {
+		return nil, diags.Append(err)
+	}
+
+	config, hclDiags := loader.LoadConfig(ctx, dir, call)
+	return config, diags.Append(hclDiags)
+}
+
+// inputValues parses the given values of the input variables of the root
+// module, as the -var option does, and leaves the others to their defaults.
+func inputValues(config *configs.Config, vars map[string]string) (farseek.InputValues, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	ret := make(farseek.InputValues, len(vars))
+	for name, raw := range vars {
+		variable, ok := config.Module.Variables[name]
+		if !ok {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Value for undeclared variable",
+				fmt.Sprintf("The root module doesn't declare a variable named %q.", name),
+			))
+			continue
+		}
+		val, hclDiags := variable.ParsingMode.Parse(name, raw)
+		diags = diags.Append(hclDiags)
+		ret[name] = &farseek.InputValue{
+			Value:      val,
+			SourceType: farseek.ValueFromCaller,
+		}
+	}
+
+	// Farseek Core substitutes the defaults of the variables that are left
+	// unset, but still expects an entry for each of them.
+	for name, variable := range config.Module.Variables {
+		if _, ok := ret[name]; ok {
+			continue
+		}
+		if variable.Required() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "No value for required variable",
+				Detail:   fmt.Sprintf("The root module input variable %q is not set, and has no default value. Set it in Options.Variables.", name),
+				Subject:  variable.DeclRange.Ptr(),
+			})
+			continue
+		}
+		ret[name] = &farseek.InputValue{
+			Value:       cty.NilVal,
+			SourceType:  farseek.ValueFromConfig,
+			SourceRange: tfdiags.SourceRangeFromHCL(variable.DeclRange),
+		}
+	}
+	return ret, diags
+}
+
+// parseDataSources returns the attributes of each data source type in the
+// given JSON objects, still in JSON.
+func parseDataSources(src map[string]string) (map[string]map[string]json.RawMessage, error) {
+	ret := make(map[string]map[string]json.RawMessage, len(src))
+	for typeName, obj := range src {
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(obj), &attrs); err != nil {
+			return nil, fmt.Errorf("invalid result for data source %s: %w", typeName, err)
+		}
+		ret[typeName] = attrs
+	}
+	return ret, nil
+}
+
+// mockProvider returns a mock provider with the given schema. It plans
+// resources with the proposed new state, and reads data sources with the
+// given attributes.
+func mockProvider(schema providers.ProviderSchema, dataSources map[string]map[string]json.RawMessage) *farseek.MockProvider {
+	return &farseek.MockProvider{
+		GetProviderSchemaResponse: &schema,
+		ReadDataSourceFn: func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+			attrs := req.Config.AsValueMap()
+			if attrs == nil {
+				attrs = make(map[string]cty.Value)
+			}
+			for name, raw := range dataSources[req.TypeName] {
+				ty := req.Config.Type()
+				if !ty.HasAttribute(name) {
+					resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("data source %s has no attribute %q", req.TypeName, name))
+					return resp
+				}
+				val, err := ctyjson.Unmarshal(raw, ty.AttributeType(name))
+				if err != nil {
+					resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("invalid value for attribute %q of data source %s: %w", name, req.TypeName, err))
+					return resp
+				}
+				attrs[name] = val
+			}
+			// Attributes still unknown in the configuration are only known
+			// once read, so without a result for them they're null.
+			for name, val := range attrs {
+				if !val.IsWhollyKnown() {
+					attrs[name] = cty.NullVal(val.Type())
+				}
+			}
+			resp.State = cty.ObjectVal(attrs)
+			return resp
+		},
+	}
+}
+
+// render renders the plan as farseek plan does, without colors.
+func render(t *testing.T, plan *plans.Plan, schemas *farseek.Schemas) string {
+	t.Helper()
+
+	outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
+	if err != nil {
+		t.Fatalf("failed to render the plan: %s", err)
+	}
+
+	streams, done := terminal.StreamsForTesting(t)
+	renderer := jsonformat.Renderer{
+		Colorize: &colorstring.Colorize{
+			Colors:  colorstring.DefaultColors,
+			Disable: true,
+		},
+		Streams:             streams,
+		RunningInAutomation: true,
+	}
+	renderer.RenderHumanPlan(jsonformat.Plan{
+		PlanFormatVersion:     jsonplan.FormatVersion,
+		ProviderFormatVersion: jsonprovider.FormatVersion,
+		OutputChanges:         outputs,
+		ResourceChanges:       changed,
+		ResourceDrift:         drift,
+		ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
+		RelevantAttributes:    attrs,
+	}, plan.UIMode)
+	return done(t).Stdout()
+}
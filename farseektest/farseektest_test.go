@@ -0,0 +1,56 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseektest
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAssertPlan(t *testing.T) {
+	schemas, err := os.ReadFile("testdata/plan/schemas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertPlan(t, "testdata/plan", "testdata/plan/plan.golden", Options{
+		Schemas:     schemas,
+		DataSources: map[string]string{"test_image": `{"id": "img-123"}`},
+		Variables:   map[string]string{"environment": "prod", "size": "2"},
+	})
+}
+
+func TestPlan_noDataSource(t *testing.T) {
+	schemas, err := os.ReadFile("testdata/plan/schemas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Plan(t, "testdata/plan", Options{
+		Schemas:   schemas,
+		Variables: map[string]string{"environment": "dev"},
+	})
+	// Without a result for the data source, its computed id is null, and
+	// the variable's default applies.
+	for _, want := range []string{`+ size     = 1`, `+ tags     = {`, `"dev"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseSchemas_invalid(t *testing.T) {
+	for name, src := range map[string]string{
+		"not JSON":       `{`,
+		"wrong version":  `{"format_version": "0.1"}`,
+		"bad address":    `{"format_version": "1.0", "provider_schemas": {"a/b/c/d": {}}}`,
+		"bad attribute":  `{"format_version": "1.0", "provider_schemas": {"hashicorp/test": {"provider": {"block": {"attributes": {"a": {"type": "strong"}}}}}}}`,
+		"bad block mode": `{"format_version": "1.0", "provider_schemas": {"hashicorp/test": {"provider": {"block": {"block_types": {"b": {"nesting_mode": "tree"}}}}}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseSchemas([]byte(src)); err == nil {
+				t.Fatal("succeeded; want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseektest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonprovider"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+)
+
+// parseSchemas returns the provider schemas in the given output of
+// farseek providers schema -json.
+func parseSchemas(src []byte) (map[addrs.Provider]providers.ProviderSchema, error) {
+	var doc jsonprovider.Providers
+	if err := json.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("invalid provider schemas: %w", err)
+	}
+	if doc.FormatVersion != jsonprovider.FormatVersion {
+		return nil, fmt.Errorf("unsupported provider schemas format version %q", doc.FormatVersion)
+	}
+
+	ret := make(map[addrs.Provider]providers.ProviderSchema, len(doc.Schemas))
+	for source, p := range doc.Schemas {
+		addr, diags := addrs.ParseProviderSourceString(source)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("invalid provider address %q: %w", source, diags.Err())
+		}
+		schema, err := providerSchema(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema for %s: %w", addr, err)
+		}
+		ret[addr] = schema
+	}
+	return ret, nil
+}
+
+func providerSchema(p *jsonprovider.Provider) (providers.ProviderSchema, error) {
+	ret := providers.ProviderSchema{
+		ResourceTypes:      make(map[string]providers.Schema, len(p.ResourceSchemas)),
+		DataSources:        make(map[string]providers.Schema, len(p.DataSourceSchemas)),
+		EphemeralResources: make(map[string]providers.Schema, len(p.EphemeralResourceSchemas)),
+	}
+	var err error
+	if ret.Provider, err = schema(p.Provider); err != nil {
+		return ret, fmt.Errorf("provider: %w", err)
+	}
+	for name, s := range p.ResourceSchemas {
+		if ret.ResourceTypes[name], err = schema(s); err != nil {
+			return ret, fmt.Errorf("resource type %s: %w", name, err)
+		}
+	}
+	for name, s := range p.DataSourceSchemas {
+		if ret.DataSources[name], err = schema(s); err != nil {
+			return ret, fmt.Errorf("data source %s: %w", name, err)
+		}
+	}
+	for name, s := range p.EphemeralResourceSchemas {
+		if ret.EphemeralResources[name], err = schema(s); err != nil {
+			return ret, fmt.Errorf("ephemeral resource type %s: %w", name, err)
+		}
+	}
+	return ret, nil
+}
+
+func schema(s *jsonprovider.Schema) (providers.Schema, error) {
+	if s == nil {
+		return providers.Schema{Block: &configschema.Block{}}, nil
+	}
+	block, err := configBlock(s.Block)
+	if err != nil {
+		return providers.Schema{}, err
+	}
+	return providers.Schema{Version: int64(s.Version), Block: block}, nil
+}
+
+func configBlock(b *jsonprovider.Block) (*configschema.Block, error) {
+	ret := &configschema.Block{
+		Attributes: make(map[string]*configschema.Attribute),
+		BlockTypes: make(map[string]*configschema.NestedBlock),
+	}
+	if b == nil {
+		return ret, nil
+	}
+	ret.Description = b.Description
+	ret.DescriptionKind = stringKind(b.DescriptionKind)
+	ret.Deprecated = b.Deprecated
+	ret.Ephemeral = b.Ephemeral
+
+	for name, a := range b.Attributes {
+		attr, err := configAttribute(a)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", name, err)
+		}
+		ret.Attributes[name] = attr
+	}
+	for name, bt := range b.BlockTypes {
+		nesting, err := nestingMode(bt.NestingMode)
+		if err != nil {
+			return nil, fmt.Errorf("block %s: %w", name, err)
+		}
+		block, err := configBlock(bt.Block)
+		if err != nil {
+			return nil, fmt.Errorf("block %s: %w", name, err)
+		}
+		ret.BlockTypes[name] = &configschema.NestedBlock{
+			Block:    *block,
+			Nesting:  nesting,
+			MinItems: int(bt.MinItems),
+			MaxItems: int(bt.MaxItems),
+		}
+	}
+	return ret, nil
+}
+
+func configAttribute(a *jsonprovider.Attribute) (*configschema.Attribute, error) {
+	ret := &configschema.Attribute{
+		Description:     a.Description,
+		DescriptionKind: stringKind(a.DescriptionKind),
+		Deprecated:      a.Deprecated,
+		Required:        a.Required,
+		Optional:        a.Optional,
+		Computed:        a.Computed,
+		Sensitive:       a.Sensitive,
+		WriteOnly:       a.WriteOnly,
+	}
+	if a.AttributeType != nil {
+		var ty cty.Type
+		if err := json.Unmarshal(a.AttributeType, &ty); err != nil {
+			return nil, err
+		}
+		ret.Type = ty
+	}
+	if a.AttributeNestedType != nil {
+		nesting, err := nestingMode(a.AttributeNestedType.NestingMode)
+		if err != nil {
+			return nil, err
+		}
+		ret.NestedType = &configschema.Object{
+			Attributes: make(map[string]*configschema.Attribute, len(a.AttributeNestedType.Attributes)),
+			Nesting:    nesting,
+		}
+		for name, na := range a.AttributeNestedType.Attributes {
+			attr, err := configAttribute(na)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			ret.NestedType.Attributes[name] = attr
+		}
+	}
+	return ret, nil
+}
+
+func nestingMode(s string) (configschema.NestingMode, error) {
+	switch s {
+	case "single":
+		return configschema.NestingSingle, nil
+	case "group":
+		return configschema.NestingGroup, nil
+	case "list":
+		return configschema.NestingList, nil
+	case "set":
+		return configschema.NestingSet, nil
+	case "map":
+		return configschema.NestingMap, nil
+	default:
+		return configschema.NestingSingle, fmt.Errorf("invalid nesting mode %q", s)
+	}
+}
+
+func stringKind(s string) configschema.StringKind {
+	if s == "markdown" {
+		return configschema.StringMarkdown
+	}
+	return configschema.StringPlain
+}
@@ -0,0 +1,23 @@
+variable "environment" {
+  type = string
+}
+
+variable "size" {
+  type    = number
+  default = 1
+}
+
+data "test_image" "base" {
+  name = "base"
+}
+
+resource "test_instance" "web" {
+  image    = data.test_image.base.id
+  size     = var.size
+  tags     = { environment = var.environment }
+  password = "hunter2"
+}
+
+output "instance_id" {
+  value = test_instance.web.id
+}
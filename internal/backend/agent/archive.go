@@ -0,0 +1,208 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackDir writes a gzipped tar archive of the regular files and directories
+// under dir to w. Any directories whose base names appear in skip, such as
+// the local data directory, are omitted along with their contents.
+//
+// Symlinks and other special files are also omitted, since the agent could
+// not safely reproduce them.
+func PackDir(dir string, w io.Writer, skip ...string) error {
+	return packDir(dir, w, nil, skip)
+}
+
+// packChanged is like PackDir, but includes only the regular files whose
+// digests differ from those in before, as returned by fileDigests, or which
+// are not in before at all. Directories are created implicitly when the
+// archive is unpacked, so it has no separate entries for them.
+func packChanged(dir string, w io.Writer, before map[string][sha256.Size]byte, skip ...string) error {
+	return packDir(dir, w, func(rel, path string) (bool, error) {
+		digest, err := fileDigest(path)
+		if err != nil {
+			return false, err
+		}
+		prev, ok := before[rel]
+		return !ok || prev != digest, nil
+	}, skip)
+}
+
+// packDir implements PackDir and packChanged. If include is not nil, it
+// decides which regular files to include, given their slash-separated paths
+// relative to dir and their actual paths, and directories have no entries.
+func packDir(dir string, w io.Writer, include func(rel, path string) (bool, error), skip []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := walkDir(dir, skip, func(rel, path string, d fs.DirEntry) error {
+		if include != nil {
+			if d.IsDir() {
+				return nil
+			}
+			ok, err := include(rel, path)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// fileDigests returns the SHA-256 digest of each of the regular files under
+// dir, by slash-separated path relative to dir, leaving out directories
+// whose base names appear in skip.
+func fileDigests(dir string, skip ...string) (map[string][sha256.Size]byte, error) {
+	ret := make(map[string][sha256.Size]byte)
+	err := walkDir(dir, skip, func(rel, path string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+		digest, err := fileDigest(path)
+		if err != nil {
+			return err
+		}
+		ret[rel] = digest
+		return nil
+	})
+	return ret, err
+}
+
+func fileDigest(path string) ([sha256.Size]byte, error) {
+	var ret [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return ret, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ret, err
+	}
+	copy(ret[:], h.Sum(nil))
+	return ret, nil
+}
+
+// walkDir calls fn for each of the regular files and directories under dir,
+// other than dir itself, with its slash-separated path relative to dir.
+// Directories whose base names appear in skip are left out along with their
+// contents.
+func walkDir(dir string, skip []string, fn func(rel, path string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			for _, name := range skip {
+				if d.Name() == name {
+					return filepath.SkipDir
+				}
+			}
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		return fn(filepath.ToSlash(rel), path, d)
+	})
+}
+
+// UnpackDir extracts an archive created by PackDir into dir, which must
+// already exist. Any entry that would be written outside of dir causes an
+// error.
+func UnpackDir(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if rel, err := filepath.Rel(dir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q is outside of the target directory", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("archive entry %q has unsupported type", hdr.Name)
+		}
+	}
+}
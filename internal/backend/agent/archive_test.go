@@ -0,0 +1,75 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPackDir_roundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "main.tf"), `resource "test_instance" "foo" {}`)
+	writeTestFile(t, filepath.Join(src, "modules", "child", "main.tf"), `output "foo" { value = 1 }`)
+	writeTestFile(t, filepath.Join(src, ".terraform", "providers", "junk"), "junk")
+
+	var buf bytes.Buffer
+	if err := PackDir(src, &buf, ".terraform"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if err := UnpackDir(&buf, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dst, "modules", "child", "main.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `output "foo" { value = 1 }`; string(got) != want {
+		t.Errorf("wrong content\ngot:  %s\nwant: %s", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".terraform")); !os.IsNotExist(err) {
+		t.Errorf("skipped directory was unpacked")
+	}
+}
+
+func TestUnpackDir_outsideTarget(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("boop")
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gz.Close()
+
+	err := UnpackDir(&buf, t.TempDir())
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if got, want := err.Error(), "outside of the target directory"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Client sends operation requests to an agent.
+type Client struct {
+	// BaseURL is the URL of the agent, such as "http://runner:8700".
+	BaseURL string
+
+	// Token, if set, is sent as a bearer token with each request.
+	Token string
+
+	// HTTPClient is used to make requests. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+}
+
+// Run sends the given request to the agent and then calls handle for each
+// log and output event that it sends back, in order, before returning the
+// final result of the operation.
+//
+// The request's Version field is set automatically.
+func (c *Client) Run(ctx context.Context, req *Request, handle func(*Event)) (*Result, error) {
+	req.Version = ProtocolVersion
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(c.BaseURL, "/") + OperationsPath
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("agent responded with %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		var ev Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("invalid event from agent: %w", err)
+		}
+		switch ev.Type {
+		case EventResult:
+			if ev.Result == nil {
+				return nil, errors.New("agent sent a result event without a result")
+			}
+			return ev.Result, nil
+		case EventError:
+			return nil, fmt.Errorf("agent failed to run the operation: %s", ev.Error)
+		default:
+			handle(&ev)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events from agent: %w", err)
+	}
+	return nil, errors.New("agent closed the connection without sending a result")
+}
@@ -0,0 +1,120 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package agent implements a minimal protocol for running Farseek plan and
+// apply operations on a remote runner, known as an agent.
+//
+// The client sends a single Request containing an archive of the working
+// directory, and the agent responds with a stream of newline-delimited JSON
+// Event objects: the machine-readable UI log lines of the remote operation
+// followed by a single final event describing its result, including any
+// files that the operation changed. Any approval that
+// an apply needs is collected by the client between a remote plan and a
+// remote apply of the resulting saved plan.
+package agent
+
+import (
+	"encoding/json"
+)
+
+// ProtocolVersion is the version of the request and event formats defined
+// in this file. The agent rejects requests for any other version.
+const ProtocolVersion = 1
+
+// OperationsPath is the HTTP path, relative to the agent's base URL, that
+// accepts operation requests.
+const OperationsPath = "/v1/operations"
+
+// TokenEnvName is the environment variable that both the agent and its
+// clients consult for the shared secret used to authenticate requests.
+const TokenEnvName = "FARSEEK_AGENT_TOKEN"
+
+// OperationType selects which Farseek command the agent runs.
+type OperationType string
+
+const (
+	OperationPlan  OperationType = "plan"
+	OperationApply OperationType = "apply"
+)
+
+// Request describes a single operation for the agent to run.
+type Request struct {
+	Version   int           `json:"version"`
+	Operation OperationType `json:"operation"`
+
+	// Args are additional command line arguments for the remote command.
+	// The agent adds the arguments it needs to control the output format,
+	// input handling and plan file location itself, so those must not be
+	// included here.
+	Args []string `json:"args,omitempty"`
+
+	// AutoApprove must be set to apply without a saved plan, because the
+	// agent cannot prompt for approval itself.
+	AutoApprove bool `json:"auto_approve,omitempty"`
+
+	// Config is a gzipped tar archive of the working directory, as created
+	// by PackDir. If the working directory is in a Git repository, the
+	// archive holds the whole repository, including its metadata, so that
+	// the agent discovers the changed resources the same way as the client.
+	Config []byte `json:"config"`
+
+	// Dir is the slash-separated path of the working directory within the
+	// Config archive, or empty if it's the root of the archive.
+	Dir string `json:"dir,omitempty"`
+
+	// PlanFile optionally contains a saved plan to apply, as previously
+	// returned in the result of a plan operation.
+	PlanFile []byte `json:"plan_file,omitempty"`
+}
+
+// EventType identifies the kind of each event in an agent's response.
+type EventType string
+
+const (
+	// EventLog carries one line of the machine-readable UI output of the
+	// remote operation, unchanged.
+	EventLog EventType = "log"
+
+	// EventOutput carries one line of any other output from the agent, such
+	// as from preparing the remote working directory.
+	EventOutput EventType = "output"
+
+	// EventResult is always the final event of a successful exchange.
+	EventResult EventType = "result"
+
+	// EventError reports that the agent failed to run the operation at all.
+	EventError EventType = "error"
+)
+
+// Event is a single message in an agent's response stream.
+type Event struct {
+	Type EventType `json:"type"`
+
+	// Log is set for EventLog.
+	Log json.RawMessage `json:"log,omitempty"`
+
+	// Output is set for EventOutput.
+	Output string `json:"output,omitempty"`
+
+	// Result is set for EventResult.
+	Result *Result `json:"result,omitempty"`
+
+	// Error is set for EventError.
+	Error string `json:"error,omitempty"`
+}
+
+// Result describes the outcome of a remote operation.
+type Result struct {
+	// ExitCode is the exit status of the remote command.
+	ExitCode int `json:"exit_code"`
+
+	// PlanFile is the saved plan produced by a plan operation, if any.
+	PlanFile []byte `json:"plan_file,omitempty"`
+
+	// Files is a gzipped tar archive, in the same format as Request.Config,
+	// of the files that the operation created or changed in the working
+	// directory, such as a local state file, the recorded commit, and the
+	// recovery journal in the data directory. The client writes them back
+	// into its own working directory.
+	Files []byte `json:"files,omitempty"`
+}
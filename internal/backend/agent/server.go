@@ -0,0 +1,304 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// planFileName is the name of the saved plan file that the agent asks the
+// remote command to write, or from which it applies, within its temporary
+// working directory.
+const planFileName = "farseek-agent.tfplan"
+
+// Runner runs a single Farseek command with the given arguments in dir,
+// writing its standard output to stdout and returning its exit status.
+//
+// A non-nil error means that the command could not be run at all, as
+// opposed to it running and then failing.
+type Runner func(ctx context.Context, dir string, args []string, stdout io.Writer) (int, error)
+
+// ExecRunner returns a Runner that executes the Farseek binary at the given
+// path as a child process. Its standard error is discarded, because all of
+// the relevant output is included in the machine-readable UI on stdout.
+func ExecRunner(executable string) Runner {
+	return func(ctx context.Context, dir string, args []string, stdout io.Writer) (int, error) {
+		cmd := exec.CommandContext(ctx, executable, args...)
+		cmd.Dir = dir
+		cmd.Stdout = stdout
+		cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1")
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		if err != nil {
+			return 1, err
+		}
+		return 0, nil
+	}
+}
+
+// Server is an http.Handler which accepts operation requests from clients
+// and runs them using its Runner, one at a time.
+type Server struct {
+	// Run runs each of the commands needed for an operation.
+	Run Runner
+
+	// Token, if set, is a shared secret that clients must present as a
+	// bearer token in the Authorization header.
+	Token string
+
+	// TempDir is the directory in which the server creates a temporary
+	// working directory for each operation. If empty, the system default
+	// temporary directory is used.
+	TempDir string
+
+	// The agent works on a single operation at a time, since concurrent
+	// operations would typically contend for the same remote objects.
+	mu sync.Mutex
+}
+
+var _ http.Handler = (*Server)(nil)
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != OperationsPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Token != "" {
+		want := "Bearer " + s.Token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if err := validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	events := newEventWriter(w)
+
+	result, err := s.runOperation(r.Context(), &req, events)
+	if err != nil {
+		log.Printf("[ERROR] agent: %s operation failed: %s", req.Operation, err)
+		events.write(Event{Type: EventError, Error: err.Error()})
+		return
+	}
+	events.write(Event{Type: EventResult, Result: result})
+}
+
+func validateRequest(req *Request) error {
+	if req.Version != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d; this agent supports version %d", req.Version, ProtocolVersion)
+	}
+	switch req.Operation {
+	case OperationPlan:
+		if len(req.PlanFile) != 0 {
+			return errors.New("a plan operation cannot include a saved plan")
+		}
+	case OperationApply:
+		if len(req.PlanFile) == 0 && !req.AutoApprove {
+			return errors.New("an apply operation requires either a saved plan or auto-approve, because the agent cannot prompt for approval")
+		}
+	default:
+		return fmt.Errorf("unsupported operation %q", req.Operation)
+	}
+	if req.Dir != "" && !filepath.IsLocal(filepath.FromSlash(req.Dir)) {
+		return fmt.Errorf("the working directory %q is not within the configuration archive", req.Dir)
+	}
+	for _, arg := range req.Args {
+		if !strings.HasPrefix(arg, "-") {
+			// Values of flags given as separate arguments.
+			continue
+		}
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		switch name {
+		case "chdir", "json", "input", "out", "auto-approve":
+			return fmt.Errorf("the -%s option is controlled by the agent and must not be included in the request arguments", name)
+		}
+	}
+	return nil
+}
+
+func (s *Server) runOperation(ctx context.Context, req *Request, events *eventWriter) (*Result, error) {
+	// The working directory only lasts for the operation, so the files that
+	// it changes are returned to the client in the result.
+	root, err := os.MkdirTemp(s.TempDir, "farseek-agent-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := UnpackDir(bytes.NewReader(req.Config), root); err != nil {
+		return nil, fmt.Errorf("failed to unpack configuration: %w", err)
+	}
+	dir := filepath.Join(root, filepath.FromSlash(req.Dir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	// The working directory archive deliberately excludes the local data
+	// directory, so we must always initialize it again here.
+	out := events.lines(EventOutput)
+	code, err := s.Run(ctx, dir, []string{"init", "-input=false", "-no-color"}, out)
+	out.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run init: %w", err)
+	}
+	if code != 0 {
+		return &Result{ExitCode: code}, nil
+	}
+
+	// Whatever init installed is specific to this host, so only the files
+	// that the operation itself changes after this are returned.
+	before, err := fileDigests(dir, ".git")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read working directory: %w", err)
+	}
+
+	planPath := filepath.Join(dir, planFileName)
+	args := []string{string(req.Operation), "-json", "-input=false"}
+	switch {
+	case req.Operation == OperationPlan:
+		args = append(args, "-out="+planFileName)
+		args = append(args, req.Args...)
+	case len(req.PlanFile) != 0:
+		if err := os.WriteFile(planPath, req.PlanFile, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write saved plan: %w", err)
+		}
+		args = append(args, req.Args...)
+		args = append(args, planFileName)
+	default:
+		args = append(args, "-auto-approve")
+		args = append(args, req.Args...)
+	}
+
+	out = events.lines(EventLog)
+	code, err = s.Run(ctx, dir, args, out)
+	out.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", req.Operation, err)
+	}
+
+	result := &Result{ExitCode: code}
+	if req.Operation == OperationPlan {
+		planFile, err := os.ReadFile(planPath)
+		switch {
+		case err == nil:
+			result.PlanFile = planFile
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to read saved plan: %w", err)
+		}
+	}
+
+	// The saved plan is returned separately, and isn't one of the client's
+	// files.
+	if err := os.Remove(planPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove saved plan: %w", err)
+	}
+	var files bytes.Buffer
+	if err := packChanged(dir, &files, before, ".git"); err != nil {
+		return nil, fmt.Errorf("failed to archive changed files: %w", err)
+	}
+	result.Files = files.Bytes()
+	return result, nil
+}
+
+// eventWriter serializes events onto an HTTP response, flushing after each
+// one so that the client sees them as soon as they are produced.
+type eventWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func newEventWriter(w io.Writer) *eventWriter {
+	return &eventWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (ew *eventWriter) write(ev Event) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	if err := ew.enc.Encode(ev); err != nil {
+		log.Printf("[WARN] agent: failed to send event: %s", err)
+		return
+	}
+	if f, ok := ew.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// lines returns a writer that sends each complete line written to it as a
+// separate event of the given type, which must be either EventLog or
+// EventOutput. Lines that are not valid JSON are always sent as
+// EventOutput.
+//
+// The caller must close the returned writer once it has finished writing,
+// which waits until all of the lines have been sent.
+func (ew *eventWriter) lines(ty EventType) io.WriteCloser {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sc := bufio.NewScanner(pr)
+		sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for sc.Scan() {
+			line := sc.Bytes()
+			if ty == EventLog && json.Valid(line) {
+				ew.write(Event{Type: EventLog, Log: append(json.RawMessage(nil), line...)})
+				continue
+			}
+			ew.write(Event{Type: EventOutput, Output: string(line)})
+		}
+		pr.CloseWithError(sc.Err())
+	}()
+	return &lineWriter{pw: pw, done: done}
+}
+
+type lineWriter struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	return lw.pw.Write(p)
+}
+
+func (lw *lineWriter) Close() error {
+	err := lw.pw.Close()
+	<-lw.done
+	return err
+}
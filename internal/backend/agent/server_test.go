@@ -0,0 +1,211 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServer_plan(t *testing.T) {
+	var gotArgs [][]string
+	server := &Server{
+		Token: "s3cr3t",
+		Run: func(_ context.Context, dir string, args []string, stdout io.Writer) (int, error) {
+			gotArgs = append(gotArgs, args)
+			if _, err := os.Stat(filepath.Join(dir, "main.tf")); err != nil {
+				return 1, fmt.Errorf("configuration was not unpacked: %w", err)
+			}
+			if args[0] == "init" {
+				fmt.Fprintln(stdout, "Farseek has been successfully initialized!")
+				return 0, nil
+			}
+			fmt.Fprintln(stdout, `{"@message":"Plan: 1 to add, 0 to change, 0 to destroy.","type":"change_summary"}`)
+			return 2, os.WriteFile(filepath.Join(dir, planFileName), []byte("plan"), 0600)
+		},
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "main.tf"), `resource "test_instance" "foo" {}`)
+	var config bytes.Buffer
+	if err := PackDir(src, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &Client{BaseURL: ts.URL, Token: "s3cr3t"}
+	var events []string
+	result, err := client.Run(context.Background(), &Request{
+		Operation: OperationPlan,
+		Args:      []string{"-detailed-exitcode"},
+		Config:    config.Bytes(),
+	}, func(ev *Event) {
+		switch ev.Type {
+		case EventLog:
+			events = append(events, "log: "+string(ev.Log))
+		case EventOutput:
+			events = append(events, "output: "+ev.Output)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantArgs := [][]string{
+		{"init", "-input=false", "-no-color"},
+		{"plan", "-json", "-input=false", "-out=" + planFileName, "-detailed-exitcode"},
+	}
+	if diff := cmp.Diff(wantArgs, gotArgs); diff != "" {
+		t.Errorf("wrong arguments\n%s", diff)
+	}
+	wantEvents := []string{
+		"output: Farseek has been successfully initialized!",
+		`log: {"@message":"Plan: 1 to add, 0 to change, 0 to destroy.","type":"change_summary"}`,
+	}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("wrong events\n%s", diff)
+	}
+	if got, want := result.ExitCode, 2; got != want {
+		t.Errorf("wrong exit code %d; want %d", got, want)
+	}
+	if got, want := string(result.PlanFile), "plan"; got != want {
+		t.Errorf("wrong plan file %q; want %q", got, want)
+	}
+}
+
+func TestServer_rejected(t *testing.T) {
+	server := &Server{
+		Token: "s3cr3t",
+		Run: func(context.Context, string, []string, io.Writer) (int, error) {
+			t.Fatal("unexpected call to Run")
+			return 1, nil
+		},
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	testCases := map[string]struct {
+		token   string
+		req     *Request
+		wantErr string
+	}{
+		"wrong token": {
+			token:   "wrong",
+			req:     &Request{Operation: OperationPlan},
+			wantErr: "401 Unauthorized",
+		},
+		"apply without approval": {
+			token:   "s3cr3t",
+			req:     &Request{Operation: OperationApply},
+			wantErr: "requires either a saved plan or auto-approve",
+		},
+		"working directory outside of archive": {
+			token:   "s3cr3t",
+			req:     &Request{Operation: OperationPlan, Dir: "../elsewhere"},
+			wantErr: "is not within the configuration archive",
+		},
+		"agent-controlled argument": {
+			token:   "s3cr3t",
+			req:     &Request{Operation: OperationPlan, Args: []string{"-out=elsewhere"}},
+			wantErr: "-out option is controlled by the agent",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &Client{BaseURL: ts.URL, Token: tc.token}
+			_, err := client.Run(context.Background(), tc.req, func(*Event) {})
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if got := err.Error(); !strings.Contains(got, tc.wantErr) {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_changedFiles(t *testing.T) {
+	server := &Server{
+		Run: func(_ context.Context, dir string, args []string, stdout io.Writer) (int, error) {
+			if got, want := filepath.Base(dir), "infra"; got != want {
+				return 1, fmt.Errorf("operation runs in %s; want a directory named %s", dir, want)
+			}
+			switch args[0] {
+			case "init":
+				writeTestFile(t, filepath.Join(dir, ".farseek", "providers", "test"), "provider")
+				return 0, nil
+			default:
+				writeTestFile(t, filepath.Join(dir, "terraform.tfstate"), "new state")
+				writeTestFile(t, filepath.Join(dir, ".farseek", "recovery.jsonl"), "journal")
+				// A partially-failed apply still returns what it changed.
+				return 1, nil
+			}
+		},
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "infra", "main.tf"), `resource "test_instance" "foo" {}`)
+	writeTestFile(t, filepath.Join(src, "infra", "terraform.tfstate"), "old state")
+	writeTestFile(t, filepath.Join(src, "infra", "unchanged.txt"), "unchanged")
+	writeTestFile(t, filepath.Join(src, "README.md"), "outside of the working directory")
+	var config bytes.Buffer
+	if err := PackDir(src, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &Client{BaseURL: ts.URL}
+	result, err := client.Run(context.Background(), &Request{
+		Operation:   OperationApply,
+		AutoApprove: true,
+		Config:      config.Bytes(),
+		Dir:         "infra",
+	}, func(*Event) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := result.ExitCode, 1; got != want {
+		t.Errorf("wrong exit code %d; want %d", got, want)
+	}
+
+	dst := t.TempDir()
+	if err := UnpackDir(bytes.NewReader(result.Files), dst); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	err = filepath.WalkDir(dst, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		got[filepath.ToSlash(rel)] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"terraform.tfstate":       "new state",
+		".farseek/recovery.jsonl": "journal",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong changed files\n%s", diff)
+	}
+}
@@ -10,6 +10,7 @@ package backend
 import (
 	"context"
 	"errors"
+	"io"
 	"log"
 	"os"
 
@@ -23,13 +24,18 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configload"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/defaulttags"
 	"github.com/rafagsiqueira/farseek/internal/depsfile"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/moduledeprecations"
+	"github.com/rafagsiqueira/farseek/internal/planlimits"
 	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
+	"github.com/rafagsiqueira/farseek/internal/runtask"
 	"github.com/rafagsiqueira/farseek/internal/states"
 	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/rafagsiqueira/farseek/internal/tagpolicy"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -259,6 +265,14 @@ type Operation struct {
 	PlanOutPath    string // PlanOutPath is the path to save the plan
 	PlanOutBackend *plans.Backend
 
+	// PlanOutWriter, if set, receives the saved plan instead of a file at
+	// PlanOutPath, which is then used only to describe where the plan went.
+	PlanOutWriter io.Writer
+
+	// PlanOutCompress selects the compressed plan file format for the saved
+	// plan.
+	PlanOutCompress bool
+
 	// ConfigDir is the path to the directory containing the configuration's
 	// root module.
 	ConfigDir string
@@ -285,6 +299,16 @@ type Operation struct {
 	// plan and apply arguments but may not work for all backends.
 	PlanFile *planfile.WrappedPlanFile
 
+	// PlanFilePath is the path that PlanFile was read from, if it was read
+	// from a file rather than a stream.
+	PlanFilePath string
+
+	// ApplyTargets restricts the apply of PlanFile to the planned changes
+	// for these addresses. The other changes are kept in a remainder plan
+	// file next to PlanFilePath, and the saved plan is marked as partially
+	// applied.
+	ApplyTargets []addrs.Targetable
+
 	// The options below are more self-explanatory and affect the runtime
 	// behavior of the operation.
 	PlanMode     plans.Mode
@@ -292,6 +316,30 @@ type Operation struct {
 	Targets      []addrs.Targetable
 	Excludes     []addrs.Targetable
 	ForceReplace []addrs.AbsResourceInstance
+
+	// ForceReplaceReasons are the reasons given for replacing some of the
+	// ForceReplace addresses, by the address of the resource instance. The
+	// plan records them, so that they're shown with the replacements.
+	ForceReplaceReasons map[string]string
+
+	// DestroyBatchSize, if positive, splits the changes of a destroy into
+	// batches of at most this many resource instances, which are confirmed
+	// and applied one after another. Each batch only destroys objects that
+	// nothing left in later batches depends on.
+	DestroyBatchSize int
+
+	// ReviewPlan replaces the plan shown before the approval prompt with a
+	// summary, and lets the user page through, search and filter the
+	// changes at the prompt, for plans too large to read in full.
+	ReviewPlan bool
+
+	// RefreshProviders, if not empty, limits refreshing to the managed
+	// resources of these providers, given by their local names in the root
+	// module or by their source addresses.
+	RefreshProviders []string
+	// DataSourceCache, if set, caches the results of reading data resources
+	// during planning, for the plans that follow.
+	DataSourceCache *farseek.DataSourceCache
 	// Injected by the command creating the operation (plan/apply/refresh/etc...)
 	Variables map[string]UnparsedVariableValue
 	RootCall  configs.StaticModuleCall
@@ -308,6 +356,49 @@ type Operation struct {
 	// the variables set in the plan are used instead, and they must be valid.
 	AllowUnsetVariables bool
 
+	// InputStrict is set for -input=strict, where UIIn records the prompts
+	// instead of asking for input. The operation then carries on past
+	// required variables that have no value, until every prompt has been
+	// reached, so that all the missing input can be reported at once.
+	InputStrict bool
+
+	// Runtime selects the language runtime for the operation. If empty, the
+	// experimental runtime is used only if the TOFU_X_EXPERIMENTAL_RUNTIME
+	// environment variable is set. The experimental runtime is only
+	// available in experiments-enabled builds.
+	Runtime plans.Runtime
+
+	// CompareRuntimes, for a plan operation, also plans with the
+	// experimental runtime and reports how that plan differs from the one
+	// made by the legacy runtime, which is the plan that's used.
+	CompareRuntimes bool
+
+	// TagPolicy, if set, is checked against the changes of the plan made by
+	// a plan or apply operation. Violations of its rules with the "error"
+	// severity make the plan errored, so that it can't be applied.
+	TagPolicy *tagpolicy.Policy
+
+	// ModuleDeprecations, if set, is used to warn about the module calls
+	// of the configuration of a plan or apply operation that use a
+	// deprecated version of a module.
+	ModuleDeprecations *moduledeprecations.Checker
+
+	// PlanLimits are checked against the number of objects that the plan
+	// made by a plan or apply operation creates, updates and destroys.
+	// Exceeding one makes the plan errored, unless AllowExceedingLimits is
+	// set, in which case it's only a warning.
+	PlanLimits           planlimits.Limits
+	AllowExceedingLimits bool
+
+	// RunTasks are the external services that check the configuration
+	// before a plan or apply operation plans it, and the plan before it's
+	// saved or applied.
+	RunTasks []*runtask.Task
+
+	// DefaultTags are injected into the configuration of every resource of
+	// the providers they're keyed by, before it's planned or applied.
+	DefaultTags map[addrs.Provider]*defaulttags.Defaults
+
 	// View implements the logic for all UI interactions.
 	View views.Operation
 
@@ -342,6 +433,16 @@ type Operation struct {
 	// FarseekBaseSHA is the Git SHA used as the baseline for discovery.
 	// Required for looking up deleted resource IDs.
 	FarseekBaseSHA string
+
+	// DiscoveryDir is the directory, in the git repository, that discovery
+	// ran in. If empty, it is ConfigDir. It differs from ConfigDir when the
+	// configuration was taken from another commit.
+	DiscoveryDir string
+
+	// RecoveryJournalPath is where an apply in FarseekMode records the
+	// changes it has applied so far, so that an interrupted apply can be
+	// recovered with "farseek recover". If empty, no journal is written.
+	RecoveryJournalPath string
 }
 
 // HasConfig returns true if and only if the operation has a ConfigDir value
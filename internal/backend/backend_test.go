@@ -159,3 +159,28 @@ func testTempFile(t testing.TB, baseDir ...string) *os.File {
 	})
 	return f
 }
+
+func TestWorkspaceMetadata_MatchesTag(t *testing.T) {
+	meta := &WorkspaceMetadata{
+		Tags: map[string]string{"env": "prod", "team": ""},
+	}
+	tests := map[string]bool{
+		"env=prod":    true,
+		"env":         true,
+		"env=staging": false,
+		"env=":        false,
+		"team":        true,
+		"team=":       true,
+		"owner":       false,
+	}
+	for tag, want := range tests {
+		if got := meta.MatchesTag(tag); got != want {
+			t.Errorf("MatchesTag(%q) = %t; want %t", tag, got, want)
+		}
+	}
+
+	var none *WorkspaceMetadata
+	if none.MatchesTag("env") {
+		t.Errorf("nil metadata matches a tag")
+	}
+}
@@ -53,10 +53,16 @@ type Local struct {
 	//
 	// StateWorkspaceDir is the path to the folder containing data for
 	// non-default workspaces. This defaults to DefaultWorkspaceDir if not set.
-	StatePath         string
-	StateOutPath      string
-	StateBackupPath   string
-	StateWorkspaceDir string
+	//
+	// StateWorkspacePaths are the state file paths of the non-default
+	// workspaces given in the "workspaces" argument, by workspace name. They
+	// are used instead of paths in StateWorkspaceDir, and those workspaces
+	// always exist.
+	StatePath           string
+	StateOutPath        string
+	StateBackupPath     string
+	StateWorkspaceDir   string
+	StateWorkspacePaths map[string]string
 
 	// The OverrideState* paths are set based on per-operation CLI arguments
 	// and will override what'd be built from the State* fields if non-empty.
@@ -128,6 +134,12 @@ func (b *Local) ConfigSchema() *configschema.Block {
 				Type:     cty.String,
 				Optional: true,
 			},
+			"workspaces": {
+				Type: cty.Map(cty.Object(map[string]cty.Type{
+					"path": cty.String,
+				})),
+				Optional: true,
+			},
 		},
 	}
 }
@@ -163,6 +175,30 @@ func (b *Local) PrepareConfig(obj cty.Value) (cty.Value, tfdiags.Diagnostics) {
 		}
 	}
 
+	if val := obj.GetAttr("workspaces"); !val.IsNull() && val.IsKnown() {
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			name := k.AsString()
+			path := cty.Path{cty.GetAttrStep{Name: "workspaces"}, cty.IndexStep{Key: k}}
+			switch {
+			case name == backend.DefaultStateName:
+				diags = diags.Append(tfdiags.AttributeValue(
+					tfdiags.Error,
+					"Invalid local workspace",
+					`The "path" attribute sets the state file path of the default workspace, so it can't be in "workspaces".`,
+					path,
+				))
+			case v.IsNull() || v.GetAttr("path").IsNull() || v.GetAttr("path").AsString() == "":
+				diags = diags.Append(tfdiags.AttributeValue(
+					tfdiags.Error,
+					"Invalid local state file path",
+					fmt.Sprintf(`The "path" attribute of workspace %q must not be empty.`, name),
+					path,
+				))
+			}
+		}
+	}
+
 	return obj, diags
 }
 
@@ -189,6 +225,15 @@ func (b *Local) Configure(ctx context.Context, obj cty.Value) tfdiags.Diagnostic
 		b.StateWorkspaceDir = DefaultWorkspaceDir
 	}
 
+	b.StateWorkspacePaths = nil
+	if val := obj.GetAttr("workspaces"); !val.IsNull() {
+		b.StateWorkspacePaths = make(map[string]string, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			b.StateWorkspacePaths[k.AsString()] = v.GetAttr("path").AsString()
+		}
+	}
+
 	return diags
 }
 
@@ -205,19 +250,22 @@ func (b *Local) Workspaces(ctx context.Context) ([]string, error) {
 	// the listing always start with "default"
 	envs := []string{backend.DefaultStateName}
 
+	// the workspaces with their own state file paths always exist
+	var listed []string
+	for name := range b.StateWorkspacePaths {
+		listed = append(listed, name)
+	}
+
 	entries, err := os.ReadDir(b.stateWorkspaceDir())
 	// no error if there's no envs configured
-	if os.IsNotExist(err) {
-		return envs, nil
-	}
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
 
-	var listed []string
 	for _, entry := range entries {
-		if entry.IsDir() {
-			listed = append(listed, filepath.Base(entry.Name()))
+		name := filepath.Base(entry.Name())
+		if _, ok := b.StateWorkspacePaths[name]; entry.IsDir() && !ok {
+			listed = append(listed, name)
 		}
 	}
 
@@ -244,6 +292,10 @@ func (b *Local) DeleteWorkspace(ctx context.Context, name string, force bool) er
 		return errors.New("cannot delete default state")
 	}
 
+	if _, ok := b.StateWorkspacePaths[name]; ok {
+		return fmt.Errorf("cannot delete workspace %q, which is declared in the \"workspaces\" argument of the backend configuration", name)
+	}
+
 	delete(b.states, name)
 	return os.RemoveAll(filepath.Join(b.stateWorkspaceDir(), name))
 }
@@ -419,6 +471,8 @@ func (b *Local) StatePaths(name string) (stateIn, stateOut, backupOut string) {
 	if statePath == "" {
 		if isDefault {
 			statePath = b.StatePath // s.StatePath applies only to the default workspace, since StateWorkspaceDir is used otherwise
+		} else {
+			statePath = b.StateWorkspacePaths[name]
 		}
 		if statePath == "" {
 			statePath = filepath.Join(baseDir, DefaultStateFilename)
@@ -481,6 +535,10 @@ func (b *Local) createState(name string) error {
 	if name == backend.DefaultStateName {
 		return nil
 	}
+	if _, ok := b.StateWorkspacePaths[name]; ok {
+		// the state manager creates the directory of the state file
+		return nil
+	}
 
 	stateDir := filepath.Join(b.stateWorkspaceDir(), name)
 	s, err := os.Stat(stateDir)
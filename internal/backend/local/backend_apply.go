@@ -56,7 +56,12 @@ func (b *Local) opApply(
 
 	// TEMP: Opt-in support for testing with the new experimental language
 	// runtime. Refer to backend_temp_new_runtime.go for more information.
-	if experimentalRuntimeEnabled() {
+	experimental, runtimeDiags := useExperimentalRuntime(op)
+	if runtimeDiags.HasErrors() {
+		op.ReportResult(runningOp, runtimeDiags)
+		return
+	}
+	if experimental {
 		b.opApplyWithExperimentalRuntime(stopCtx, cancelCtx, op, runningOp)
 		return
 	}
@@ -77,13 +82,13 @@ func (b *Local) opApply(
 	// If we have a nil module at this point, then set it to an empty tree
 	// to avoid any potential crashes.
 	if op.PlanFile == nil && op.PlanMode != plans.DestroyMode && !op.HasConfig() {
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"No configuration files",
 			"Apply requires configuration to be present. Applying without a configuration "+
 				"would mark everything for destruction, which is normally not what is desired. "+
 				"If you would like to destroy everything, run 'farseek destroy' instead.",
-		))
+		), tfdiags.CodeNoConfigurationFiles))
 		op.ReportResult(runningOp, diags)
 		return
 	}
@@ -93,6 +98,26 @@ func (b *Local) opApply(
 		op.Hooks = append(op.Hooks, stateHook)
 	}
 
+	// In Farseek mode there's no state to persist periodically, so we keep
+	// a lightweight journal of the changes applied so far instead.
+	var recoveryHook *RecoveryHook
+	if op.FarseekMode && op.RecoveryJournalPath != "" {
+		recoveryHook = NewRecoveryHook(op.RecoveryJournalPath, defaultPersistInterval*time.Second)
+		op.Hooks = append(op.Hooks, recoveryHook)
+		defer func() {
+			if err := recoveryHook.Flush(); err != nil {
+				log.Printf("[ERROR] backend/local: failed to write recovery journal: %s", err)
+			}
+		}()
+		if recoveryHook.HasPreviousJournal() {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Previous apply was interrupted",
+				fmt.Sprintf("The recovery journal %s records changes from an earlier apply that did not finish. Run \"farseek recover\" to see how to bring those objects back under management.", op.RecoveryJournalPath),
+			), tfdiags.CodeApplyInterrupted))
+		}
+	}
+
 	// Get our context
 	lr, _, opState, contextDiags := b.localRun(ctx, op)
 	diags = diags.Append(contextDiags)
@@ -116,6 +141,9 @@ func (b *Local) opApply(
 		lr.InputState = states.NewState()
 
 		// Inject relevant discovered resources into the state.
+		deps := historicalDependencies(op)
+		imported := importedIDs(lr.Config)
+		providers := &discoveredProviders{op: op, config: lr.Config}
 		for _, dr := range op.DiscoveredResources {
 			if dr.IsNew {
 				// Truly new resources should NOT be in the state, so Farseek plans to create them.
@@ -124,24 +152,29 @@ func (b *Local) opApply(
 			}
 
 			// For resources that existed in history, we inject them into state so Farseek refreshes/destroys them.
-			addr, diags := addrs.ParseAbsResourceInstanceStr(dr.Address)
-			if diags.HasErrors() {
-				log.Printf("[WARN] backend/local: Farseek failed to parse address %s: %s", dr.Address, diags.Err())
+			addr, parseDiags := addrs.ParseAbsResourceInstanceStr(dr.Address)
+			if parseDiags.HasErrors() {
+				log.Printf("[WARN] backend/local: Farseek failed to parse address %s: %s", dr.Address, parseDiags.Err())
+				continue
+			}
+
+			providerAddr, providerKey, skipped := providers.For(addr)
+			if skipped != nil {
+				// Excluded rather than left out of the state, so that the
+				// object isn't planned for creation either.
+				diags = diags.Append(skipped)
+				lr.PlanOpts.Excludes = append(lr.PlanOpts.Excludes, addr)
 				continue
 			}
 
 			log.Printf("[DEBUG] backend/local: Farseek injecting resource %s into input state", addr)
 			mod := lr.InputState.EnsureModule(addr.Module)
-			providerAddr := addrs.AbsProviderConfig{
-				Module:   addr.Module.Module(),
-				Provider: addrs.ImpliedProviderForUnqualifiedType(addr.Resource.Resource.ImpliedProvider()),
-			}
 
 			// Try to recover attributes (id/name) from history to help the provider identify the resource.
 			jsonAttrs := "{}"
 			if op.FarseekBaseSHA != "" {
-				nameVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(op.ConfigDir, op.FarseekBaseSHA, dr.Filename, dr.Address, "name")
-				idVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(op.ConfigDir, op.FarseekBaseSHA, dr.Filename, dr.Address, "id")
+				nameVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(discoveryDir(op), op.FarseekBaseSHA, dr.Filename, dr.Address, "name")
+				idVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(discoveryDir(op), op.FarseekBaseSHA, dr.Filename, dr.Address, "id")
 
 				id := idVal
 				if id == "" {
@@ -156,13 +189,24 @@ func (b *Local) opApply(
 					}
 				}
 			}
+			if id, ok := imported[dr.Address]; ok && jsonAttrs == "{}" {
+				jsonAttrs = fmt.Sprintf(`{"id":%q}`, id)
+			}
 
 			src := &states.ResourceInstanceObjectSrc{
-				Status:    states.ObjectReady,
-				AttrsJSON: []byte(jsonAttrs),
+				Status:       states.ObjectReady,
+				AttrsJSON:    []byte(jsonAttrs),
+				Dependencies: deps[dr.Address],
 			}
-			mod.SetResourceInstanceCurrent(addr.Resource, src, providerAddr, addrs.NoKey)
+			mod.SetResourceInstanceCurrent(addr.Resource, src, providerAddr, providerKey)
 		}
+		diags = diags.Append(recoverRemovedResources(ctx, op, lr))
+		if diags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+		addUnrefreshedAttributes(ctx, op, lr)
+		addExportedOutputs(op, lr)
 	}
 	runningOp.State = lr.InputState
 
@@ -181,17 +225,42 @@ func (b *Local) opApply(
 			defaultPersistInterval, persistIntervalEnvironmentVariableName, persistInterval))
 	}
 	stateHook.PersistInterval = time.Duration(persistInterval) * time.Second
+	if recoveryHook != nil {
+		recoveryHook.PersistInterval = stateHook.PersistInterval
+	}
 
 	var plan *plans.Plan
+	// remainderPlan holds the changes left out of a targeted apply of a
+	// saved plan.
+	var remainderPlan *plans.Plan
+	// batches are the resource instances that a batched destroy destroys
+	// in each batch, and confirmBatches is set if each batch must be
+	// approved.
+	var batches [][]addrs.AbsResourceInstance
+	var confirmBatches bool
 	// If we weren't given a plan, then we refresh/plan
 	if op.PlanFile == nil {
 
+		moreDiags = runPrePlanTasks(ctx, op, lr)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
 		// Perform the plan
 		log.Printf("[INFO] backend/local: apply calling Plan")
 		plan, moreDiags = lr.Core.Plan(ctx, lr.Config, lr.InputState, lr.PlanOpts)
 
 		// FarseekMode: Suppress updates to attributes not present in the configuration
 		b.filterPlanChanges(ctx, op, lr, plan)
+		recordCommits(op, plan)
+		recordReplaceReasons(op, plan)
+		recordOutOfBandChanges(ctx, op, lr, plan)
+		filterRefreshOnlyOutputs(op, plan)
+		moreDiags = moreDiags.Append(checkTagPolicy(ctx, op, lr, plan))
+		moreDiags = moreDiags.Append(checkPlanLimits(op, plan))
+		moreDiags = moreDiags.Append(runPostPlanTasks(ctx, op, lr, plan))
 
 		diags = diags.Append(moreDiags)
 		if moreDiags.HasErrors() {
@@ -212,7 +281,27 @@ func (b *Local) opApply(
 		trivialPlan := !plan.CanApply()
 		hasUI := op.UIOut != nil && op.UIIn != nil
 		mustConfirm := hasUI && !op.AutoApprove && !trivialPlan
-		op.View.Plan(plan, schemas)
+		// With -review, the plan is explored at the approval prompt
+		// instead of being rendered in full.
+		review := mustConfirm && op.ReviewPlan
+		if !review {
+			op.View.Plan(plan, schemas)
+		}
+
+		// A batched destroy asks for approval of each batch instead of the
+		// whole plan, unless there's only one.
+		if op.DestroyBatchSize > 0 && op.PlanMode == plans.DestroyMode && !trivialPlan {
+			batches, moreDiags = destroyBatches(plan, lr.Config, schemas, op.DestroyBatchSize)
+			diags = diags.Append(moreDiags)
+			if moreDiags.HasErrors() {
+				op.ReportResult(runningOp, diags)
+				return
+			}
+			if len(batches) < 2 {
+				batches = nil
+			}
+		}
+		confirmBatches = mustConfirm && len(batches) > 0
 
 		if testHookStopPlanApply != nil {
 			testHookStopPlanApply()
@@ -230,7 +319,7 @@ func (b *Local) opApply(
 			return
 		}
 
-		if mustConfirm {
+		if mustConfirm && !confirmBatches {
 			var desc, query string
 			switch op.PlanMode {
 			case plans.DestroyMode:
@@ -266,11 +355,17 @@ func (b *Local) opApply(
 				diags = nil // reset so we won't show the same diagnostics again later
 			}
 
-			v, err := op.UIIn.Input(stopCtx, &farseek.InputOpts{
-				Id:          "approve",
-				Query:       "\n" + query,
-				Description: desc,
-			})
+			var v string
+			var err error
+			if review {
+				v, err = reviewPlan(stopCtx, op, plan, schemas, query, desc)
+			} else {
+				v, err = op.UIIn.Input(stopCtx, &farseek.InputOpts{
+					Id:          "approve",
+					Query:       "\n" + query,
+					Description: desc,
+				})
+			}
 			if err != nil {
 				diags = diags.Append(fmt.Errorf("error asking for approval: %w", err))
 				op.ReportResult(runningOp, diags)
@@ -281,7 +376,7 @@ func (b *Local) opApply(
 				runningOp.Result = backend.OperationFailure
 				return
 			}
-		} else {
+		} else if !mustConfirm {
 			// If we didn't ask for confirmation from the user, and they have
 			// included any failing checks in their configuration, then they
 			// will see a very confusing output after the apply operation
@@ -312,14 +407,46 @@ func (b *Local) opApply(
 	} else {
 		plan = lr.Plan
 		if plan.Errored {
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Cannot apply incomplete plan",
 				"Farseek encountered an error when generating this plan, so it cannot be applied.",
-			))
+			), tfdiags.CodeIncompletePlan))
 			op.ReportResult(runningOp, diags)
 			return
 		}
+		if pf, ok := op.PlanFile.Local(); ok {
+			applied, err := pf.ReadAppliedAddrs()
+			if err != nil {
+				diags = diags.Append(err)
+				op.ReportResult(runningOp, diags)
+				return
+			}
+			if len(applied) > 0 {
+				diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Saved plan is partially applied",
+					fmt.Sprintf("The changes for %d resource instances in this plan were already applied with -target. Apply the remainder plan file that was saved at that time instead.", len(applied)),
+				), tfdiags.CodePlanPartiallyApplied))
+				op.ReportResult(runningOp, diags)
+				return
+			}
+		}
+		if len(op.ApplyTargets) > 0 {
+			if op.PlanFilePath == "" {
+				// This is always a bug in the operation caller, which must
+				// reject -target for plans that aren't read from a file.
+				diags = diags.Append(fmt.Errorf("ApplyTargets set without also setting PlanFilePath (this is a bug in Farseek)"))
+				op.ReportResult(runningOp, diags)
+				return
+			}
+			plan, remainderPlan, moreDiags = splitPlanForTargets(plan, lr.Config, schemas, op.ApplyTargets)
+			diags = diags.Append(moreDiags)
+			if moreDiags.HasErrors() {
+				op.ReportResult(runningOp, diags)
+				return
+			}
+		}
 		for _, change := range plan.Changes.Resources {
 			if change.Action != plans.NoOp {
 				op.View.PlannedChange(change)
@@ -330,6 +457,62 @@ func (b *Local) opApply(
 	// Set up our hook for continuous state updates
 	stateHook.StateMgr = opState
 
+	var applyState *states.State
+	var ok bool
+	if len(batches) > 0 {
+		applyState, diags, ok = b.applyDestroyBatches(ctx, stopCtx, cancelCtx, op, runningOp, lr, opState, schemas, plan, batches, confirmBatches, recoveryHook, diags)
+	} else {
+		applyState, diags, ok = b.applyAndPersist(ctx, stopCtx, cancelCtx, op, runningOp, lr, opState, schemas, plan, diags)
+	}
+	if !ok {
+		return
+	}
+
+	if remainderPlan != nil {
+		pf, _ := op.PlanFile.Local()
+		diags = diags.Append(writePartialPlanFiles(op, pf, opState, plan, remainderPlan))
+		if diags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+	}
+
+	if recoveryHook != nil {
+		if err := recoveryHook.Discard(); err != nil {
+			log.Printf("[WARN] backend/local: failed to remove recovery journal: %s", err)
+		}
+	}
+
+	if op.FarseekMode && op.ConfigDir != "" {
+		if err := exportOutputs(op.ConfigDir, lr.Config, plan, applyState); err != nil {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to export outputs",
+				fmt.Sprintf("Farseek could not update %s, so other stacks may read outdated outputs from it: %s.", farseek.OutputsFilename, err),
+			), tfdiags.CodeOutputsExportFailed))
+		}
+	}
+
+	// If we've accumulated any warnings along the way then we'll show them
+	// here just before we show the summary and next steps. If we encountered
+	// errors then we would've returned early at some other point above.
+	op.View.Diagnostics(diags)
+}
+
+// applyAndPersist applies the given plan and persists the resulting state,
+// adding the diagnostics of the apply to the given ones. It returns false if
+// the operation is over because the apply was canceled or failed, in which
+// case the result was already reported.
+func (b *Local) applyAndPersist(
+	ctx, stopCtx, cancelCtx context.Context,
+	op *backend.Operation,
+	runningOp *backend.RunningOperation,
+	lr *backend.LocalRun,
+	opState statemgr.Full,
+	schemas *farseek.Schemas,
+	plan *plans.Plan,
+	diags tfdiags.Diagnostics,
+) (*states.State, tfdiags.Diagnostics, bool) {
 	// Start to apply in a goroutine so that we can be interrupted.
 	var applyState *states.State
 	var applyDiags tfdiags.Diagnostics
@@ -339,11 +522,12 @@ func (b *Local) opApply(
 		defer panicHandler()
 		defer close(doneCh)
 		log.Printf("[INFO] backend/local: apply calling Apply")
+		setCrashPlanSummary(plan)
 		applyState, applyDiags = lr.Core.Apply(ctx, plan, lr.Config, lr.ApplyOpts)
 	}()
 
 	if b.opWait(doneCh, stopCtx, cancelCtx, lr.Core, opState, op.View) {
-		return
+		return nil, diags, false
 	}
 	diags = diags.Append(applyDiags)
 
@@ -352,37 +536,31 @@ func (b *Local) opApply(
 	if diags.HasErrors() && applyState == nil {
 		log.Printf("[ERROR] backend/local: apply returned nil state")
 		op.ReportResult(runningOp, diags)
-		return
+		return nil, diags, false
 	}
 
 	// Store the final state
 	runningOp.State = applyState
-	if true {
-		err := statemgr.WriteAndPersist(context.TODO(), opState, applyState, schemas)
-		if err != nil {
-			// Export the state file from the state manager and assign the new
-			// state. This is needed to preserve the existing serial and lineage.
-			stateFile := statemgr.Export(opState)
-			if stateFile == nil {
-				stateFile = &statefile.File{}
-			}
-			stateFile.State = applyState
-
-			diags = diags.Append(b.backupStateForError(stateFile, err, op.View))
-			op.ReportResult(runningOp, diags)
-			return
+	err := statemgr.WriteAndPersist(context.TODO(), opState, applyState, schemas)
+	if err != nil {
+		// Export the state file from the state manager and assign the new
+		// state. This is needed to preserve the existing serial and lineage.
+		stateFile := statemgr.Export(opState)
+		if stateFile == nil {
+			stateFile = &statefile.File{}
 		}
+		stateFile.State = applyState
+
+		diags = diags.Append(b.backupStateForError(stateFile, err, op.View))
+		op.ReportResult(runningOp, diags)
+		return nil, diags, false
 	}
 
 	if applyDiags.HasErrors() {
 		op.ReportResult(runningOp, diags)
-		return
+		return nil, diags, false
 	}
-
-	// If we've accumulated any warnings along the way then we'll show them
-	// here just before we show the summary and next steps. If we encountered
-	// errors then we would've returned early at some other point above.
-	op.View.Diagnostics(diags)
+	return applyState, diags, true
 }
 
 // backupStateForError is called in a scenario where we're unable to persist the
@@ -393,20 +571,20 @@ func (b *Local) opApply(
 func (b *Local) backupStateForError(stateFile *statefile.File, err error, view views.Operation) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
-	diags = diags.Append(tfdiags.Sourceless(
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 		tfdiags.Error,
 		"Failed to save state",
 		fmt.Sprintf("Error saving state: %s", err),
-	))
+	), tfdiags.CodeStatePersistFailed))
 
 	local := statemgr.NewFilesystem("errored.tfstate", b.encryption)
 	writeErr := local.WriteStateForMigration(stateFile, true)
 	if writeErr != nil {
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Failed to create local state file",
 			fmt.Sprintf("Error creating local state file for recovery: %s", writeErr),
-		))
+		), tfdiags.CodeStatePersistFailed))
 
 		// To avoid leaving the user with no state at all, our last resort
 		// is to print the JSON state out onto the terminal. This is an awful
@@ -414,26 +592,26 @@ func (b *Local) backupStateForError(stateFile *statefile.File, err error, view v
 		// but at least the user has _some_ path to recover if we end up
 		// here for some reason.
 		if dumpErr := view.EmergencyDumpState(stateFile, b.encryption); dumpErr != nil {
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Failed to serialize state",
 				fmt.Sprintf(stateWriteFatalErrorFmt, dumpErr),
-			))
+			), tfdiags.CodeStatePersistFailed))
 		}
 
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Failed to persist state to backend",
 			stateWriteConsoleFallbackError,
-		))
+		), tfdiags.CodeStatePersistFailed))
 		return diags
 	}
 
-	diags = diags.Append(tfdiags.Sourceless(
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 		tfdiags.Error,
 		"Failed to persist state to backend",
 		stateWriteBackedUpError,
-	))
+	), tfdiags.CodeStatePersistFailed))
 
 	return diags
 }
@@ -0,0 +1,218 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// destroyBatches divides the changes of a destroy plan into batches of at
+// most size resource instances. Every object is destroyed in the same batch
+// as the objects that depend on it, or in a later one, so that each batch can
+// be applied on its own; within a batch, Farseek Core destroys the objects
+// in parallel as usual. Changes without actions are left out.
+func destroyBatches(plan *plans.Plan, config *configs.Config, schemas *farseek.Schemas, size int) ([][]addrs.AbsResourceInstance, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	var changes []*plans.ResourceInstanceChangeSrc
+	for _, change := range plan.Changes.Resources {
+		if change.Action != plans.NoOp {
+			changes = append(changes, change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Addr.Less(changes[j].Addr)
+	})
+
+	// dependents counts, for each change, how many of the other changes
+	// destroy objects that depend on its object and so must come first.
+	deps := make([][]int, len(changes))
+	dependents := make([]int, len(changes))
+	for i, change := range changes {
+		changeDeps := changeDependencies(change, config, schemas, plan.PriorState)
+		resource := change.Addr.ContainingResource().String()
+		for j, other := range changes {
+			// The instances of a resource never depend on one another.
+			if other.Addr.ContainingResource().String() == resource || !dependsOn(changeDeps, other.Addr) {
+				continue
+			}
+			deps[i] = append(deps[i], j)
+			dependents[j]++
+		}
+	}
+
+	var ready []int
+	for i := range changes {
+		if dependents[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	var batches [][]addrs.AbsResourceInstance
+	count := 0
+	for len(ready) > 0 {
+		var batch []addrs.AbsResourceInstance
+		for len(ready) > 0 && len(batch) < size {
+			i := ready[0]
+			ready = ready[1:]
+			batch = append(batch, changes[i].Addr)
+			for _, j := range deps[i] {
+				dependents[j]--
+				if dependents[j] == 0 {
+					ready = append(ready, j)
+				}
+			}
+			sort.Ints(ready)
+		}
+		count += len(batch)
+		batches = append(batches, batch)
+	}
+
+	if count < len(changes) {
+		var cycle []string
+		for i, change := range changes {
+			if dependents[i] > 0 {
+				cycle = append(cycle, change.Addr.String())
+			}
+		}
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Dependency cycle",
+			fmt.Sprintf("The destroy can't be split into batches, because these resource instances depend on each other:\n  - %s", strings.Join(cycle, "\n  - ")),
+		))
+		return nil, diags
+	}
+	return batches, diags
+}
+
+// applyDestroyBatches applies a destroy plan one batch at a time, asking for
+// approval of each batch first if confirm is set. The state is persisted and
+// the recovery journal, if any, is written after every batch, so that if the
+// operator declines a batch or Farseek is interrupted between batches, what
+// was already destroyed is on record.
+//
+// It returns false if the operation is over without all of the batches
+// having been applied, in which case the result was already reported.
+func (b *Local) applyDestroyBatches(
+	ctx, stopCtx, cancelCtx context.Context,
+	op *backend.Operation,
+	runningOp *backend.RunningOperation,
+	lr *backend.LocalRun,
+	opState statemgr.Full,
+	schemas *farseek.Schemas,
+	plan *plans.Plan,
+	batches [][]addrs.AbsResourceInstance,
+	confirm bool,
+	recoveryHook *RecoveryHook,
+	diags tfdiags.Diagnostics,
+) (*states.State, tfdiags.Diagnostics, bool) {
+	state := plan.PriorState
+	remainder := plan
+	for i, batch := range batches {
+		// stopped reports that the destroy ended before this batch, which is
+		// worth a warning if some objects are already gone.
+		stopped := func() {
+			if i == 0 {
+				return
+			}
+			left := 0
+			for _, batch := range batches[i:] {
+				left += len(batch)
+			}
+			detail := fmt.Sprintf("Farseek applied %d of the %d batches of this destroy before stopping, so %d resource instances were not destroyed. Run the destroy again to destroy them.", i, len(batches), left)
+			if recoveryHook != nil {
+				detail += fmt.Sprintf(" The recovery journal %s records the objects that were destroyed.", recoveryHook.Path)
+			}
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Destroy stopped partway",
+				detail,
+			), tfdiags.CodeDestroyStopped))
+		}
+
+		if stopCtx.Err() != nil {
+			diags = diags.Append(errors.New("execution halted"))
+			stopped()
+			runningOp.Result = backend.OperationFailure
+			op.ReportResult(runningOp, diags)
+			return nil, diags, false
+		}
+
+		targets := make([]addrs.Targetable, len(batch))
+		for j, addr := range batch {
+			targets[j] = addr
+		}
+		selected, rest, moreDiags := splitPlanForTargets(remainder, lr.Config, schemas, targets)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return nil, diags, false
+		}
+		// Each batch starts from the objects that the earlier ones left.
+		// Unlike a targeted apply, a batch leaves nothing out of the
+		// destroy, so it isn't reported as targeted.
+		selected.PriorState = state
+		selected.TargetAddrs = nil
+
+		if confirm {
+			// As for a single approval prompt, any warnings so far are
+			// shown first.
+			if len(diags) > 0 {
+				op.View.Diagnostics(diags)
+				diags = nil
+			}
+			var desc strings.Builder
+			desc.WriteString("Farseek will destroy:\n")
+			for _, addr := range batch {
+				fmt.Fprintf(&desc, "  - %s\n", addr)
+			}
+			desc.WriteString("There is no undo. Only 'yes' will be accepted to confirm.")
+			v, err := op.UIIn.Input(stopCtx, &farseek.InputOpts{
+				Id:          "approve",
+				Query:       fmt.Sprintf("\nDo you want to destroy batch %d of %d?", i+1, len(batches)),
+				Description: desc.String(),
+			})
+			if err != nil {
+				diags = diags.Append(fmt.Errorf("error asking for approval: %w", err))
+				stopped()
+				op.ReportResult(runningOp, diags)
+				return nil, diags, false
+			}
+			if v != "yes" {
+				op.View.Cancelled(op.PlanMode)
+				stopped()
+				op.View.Diagnostics(diags)
+				runningOp.Result = backend.OperationFailure
+				return nil, diags, false
+			}
+		}
+
+		log.Printf("[INFO] backend/local: applying batch %d of %d of the destroy", i+1, len(batches))
+		var ok bool
+		state, diags, ok = b.applyAndPersist(ctx, stopCtx, cancelCtx, op, runningOp, lr, opState, schemas, selected, diags)
+		if recoveryHook != nil {
+			if err := recoveryHook.Flush(); err != nil {
+				log.Printf("[ERROR] backend/local: failed to write recovery journal: %s", err)
+			}
+		}
+		if !ok {
+			return nil, diags, false
+		}
+		remainder = rest
+	}
+	return state, diags, true
+}
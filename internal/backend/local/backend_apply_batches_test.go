@@ -0,0 +1,134 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+)
+
+func TestDestroyBatches(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/apply-targets", "tests")
+	schemas := &farseek.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			addrs.NewDefaultProvider("test"): applyFixtureSchema(),
+		},
+	}
+
+	// test_instance.baz depends on test_instance.bar through a local value,
+	// which depends on test_instance.foo.
+	changes := &plans.Changes{}
+	for _, name := range []string{"foo", "bar", "baz", "other", "unchanged"} {
+		action := plans.Delete
+		if name == "unchanged" {
+			action = plans.NoOp
+		}
+		changes.Resources = append(changes.Resources, &plans.ResourceInstanceChangeSrc{
+			Addr:      mustResourceInstanceAddr("test_instance." + name),
+			ChangeSrc: plans.ChangeSrc{Action: action},
+		})
+	}
+	plan := &plans.Plan{Changes: changes, PriorState: states.NewState()}
+
+	tests := map[int][]string{
+		1: {"test_instance.baz", "test_instance.bar", "test_instance.foo", "test_instance.other"},
+		2: {"test_instance.baz,test_instance.bar", "test_instance.foo,test_instance.other"},
+		3: {"test_instance.baz,test_instance.bar,test_instance.foo", "test_instance.other"},
+		5: {"test_instance.baz,test_instance.bar,test_instance.foo,test_instance.other"},
+	}
+	for size, want := range tests {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			batches, diags := destroyBatches(plan, config, schemas, size)
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+			var got []string
+			for _, batch := range batches {
+				var addrs []string
+				for _, addr := range batch {
+					addrs = append(addrs, addr.String())
+				}
+				got = append(got, strings.Join(addrs, ","))
+			}
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("wrong batches\n got: %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLocal_applyDestroyBatches(t *testing.T) {
+	b := TestLocal(t)
+	provider := mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`)
+	testStateFile(t, b.StatePath, states.BuildState(func(s *states.SyncState) {
+		for _, name := range []string{"foo", "bar", "baz", "other"} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_instance."+name), &states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(fmt.Sprintf(`{"id":%q,"ami":"bar"}`, name)),
+			}, provider, addrs.NoKey)
+		}
+	}))
+	TestLocalProvider(t, b, "test", applyFixtureSchema())
+
+	// The operator approves the first batch and declines the second.
+	var queries []string
+	op, done := testOperationApply(t, "./testdata/apply-targets")
+	op.PlanMode = plans.DestroyMode
+	op.PlanRefresh = true
+	op.DestroyBatchSize = 2
+	op.UIOut = cli.NewMockUi()
+	op.UIIn = &farseek.MockUIInput{
+		InputFn: func(opts *farseek.InputOpts) (string, error) {
+			queries = append(queries, opts.Query)
+			if len(queries) == 1 {
+				return "yes", nil
+			}
+			return "no", nil
+		},
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	output := done(t)
+	if run.Result == backend.OperationSuccess {
+		t.Fatalf("destroy succeeded; want it stopped partway\n%s", output.All())
+	}
+
+	if got, want := strings.Join(queries, ""), "\nDo you want to destroy batch 1 of 2?\nDo you want to destroy batch 2 of 2?"; got != want {
+		t.Errorf("wrong prompts\n got: %q\nwant: %q", got, want)
+	}
+	if got, want := strings.Join(strings.Fields(output.All()), " "), "Farseek applied 1 of the 2 batches of this destroy before stopping, so 2 resource instances were not destroyed."; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+
+	// The objects destroyed by the first batch are gone from the persisted
+	// state, and the rest are still there.
+	stateMgr := statemgr.NewFilesystem(b.StateOutPath, encryption.StateEncryptionDisabled())
+	if err := stateMgr.RefreshState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	state := stateMgr.State()
+	for name, want := range map[string]bool{"foo": true, "bar": false, "baz": false, "other": true} {
+		if got := state.ResourceInstance(mustResourceInstanceAddr("test_instance."+name)) != nil; got != want {
+			t.Errorf("test_instance.%s in state is %t; want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+// reviewPageSize is how many changes a page of the plan review lists.
+const reviewPageSize = 20
+
+const reviewHelp = `Review commands:
+  <enter>, next       Show the next page of changes.
+  list                List all the changes from the start.
+  /TEXT, search TEXT  List the changes whose address contains TEXT.
+  destroys            List the changes that destroy or replace objects.
+  module ADDR         List the changes in a module, such as module.network,
+                      or "root" for the root module.
+  show ADDRESS        Show the full details of the change to a resource.
+  help                Show these commands.
+  yes                 Approve the plan.
+  no                  Cancel.`
+
+// planReview is an interactive review of a plan at the approval prompt, for
+// plans too large to read in the terminal history. The changes are listed a
+// page at a time, and can be searched, filtered, and shown in full.
+type planReview struct {
+	op      *backend.Operation
+	plan    *plans.Plan
+	schemas *farseek.Schemas
+
+	// changes are the changes of the plan that do something, sorted by
+	// address.
+	changes []*plans.ResourceInstanceChangeSrc
+
+	// filter describes the changes in matches, and next is the index in
+	// matches of the next page.
+	filter  string
+	matches []*plans.ResourceInstanceChangeSrc
+	next    int
+}
+
+// reviewPlan shows a summary of the plan and lets the user explore it until
+// they answer the approval prompt given by query and desc, returning the
+// answer.
+func reviewPlan(ctx context.Context, op *backend.Operation, plan *plans.Plan, schemas *farseek.Schemas, query, desc string) (string, error) {
+	r := &planReview{
+		op:      op,
+		plan:    plan,
+		schemas: schemas,
+	}
+	for _, change := range plan.Changes.Resources {
+		if change.Action != plans.NoOp {
+			r.changes = append(r.changes, change)
+		}
+	}
+	slices.SortStableFunc(r.changes, func(a, b *plans.ResourceInstanceChangeSrc) int {
+		return strings.Compare(a.Addr.String(), b.Addr.String())
+	})
+	r.setFilter("all changes", r.changes)
+
+	op.UIOut.Output(r.summary())
+	op.UIOut.Output(reviewHelp)
+
+	for {
+		v, err := op.UIIn.Input(ctx, &farseek.InputOpts{
+			Id:          "approve",
+			Query:       "\n" + query,
+			Description: desc + "\nEnter \"help\" to list the commands for reviewing the plan.",
+		})
+		if err != nil {
+			return "", err
+		}
+
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(v), " ")
+		arg = strings.TrimSpace(arg)
+		if search, ok := strings.CutPrefix(cmd, "/"); ok {
+			cmd, arg = "search", strings.TrimSpace(search+" "+arg)
+		}
+		switch cmd {
+		case "yes", "no", "quit", "q":
+			return cmd, nil
+		case "", "next", "n":
+		case "list":
+			r.setFilter("all changes", r.changes)
+		case "search":
+			r.setFilter(fmt.Sprintf("changes matching %q", arg), r.filterChanges(func(change *plans.ResourceInstanceChangeSrc) bool {
+				return strings.Contains(change.Addr.String(), arg)
+			}))
+		case "destroys":
+			r.setFilter("changes that destroy objects", r.filterChanges(func(change *plans.ResourceInstanceChangeSrc) bool {
+				return change.Action == plans.Delete || change.Action.IsReplace()
+			}))
+		case "module":
+			r.setFilter(fmt.Sprintf("changes in %s", arg), r.filterChanges(func(change *plans.ResourceInstanceChangeSrc) bool {
+				return inReviewModule(change.Addr.Module, arg)
+			}))
+		case "show":
+			r.show(arg)
+			continue
+		case "help", "?":
+			op.UIOut.Output(reviewHelp)
+			continue
+		default:
+			op.UIOut.Output(fmt.Sprintf("Unknown command %q. Enter \"help\" to list the commands, or \"yes\" to approve.", v))
+			continue
+		}
+		op.UIOut.Output(r.page())
+	}
+}
+
+// summary counts the changes in the plan by action and by module.
+func (r *planReview) summary() string {
+	var buf strings.Builder
+	var add, change, destroy, replace, other int
+	modules := make(map[string]int)
+	for _, rc := range r.changes {
+		switch {
+		case rc.Action == plans.Create:
+			add++
+		case rc.Action == plans.Update:
+			change++
+		case rc.Action == plans.Delete:
+			destroy++
+		case rc.Action.IsReplace():
+			replace++
+		default:
+			other++
+		}
+		modules[reviewModuleName(rc.Addr.Module)]++
+	}
+	fmt.Fprintf(&buf, "\nThe plan has %d changes to review: %d to add, %d to change, %d to destroy, %d to replace", len(r.changes), add, change, destroy, replace)
+	if other != 0 {
+		fmt.Fprintf(&buf, ", %d other", other)
+	}
+	buf.WriteString(".\n\nChanges by module:\n")
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "  %-40s %d\n", name, modules[name])
+	}
+	return buf.String()
+}
+
+func (r *planReview) filterChanges(match func(*plans.ResourceInstanceChangeSrc) bool) []*plans.ResourceInstanceChangeSrc {
+	var ret []*plans.ResourceInstanceChangeSrc
+	for _, change := range r.changes {
+		if match(change) {
+			ret = append(ret, change)
+		}
+	}
+	return ret
+}
+
+func (r *planReview) setFilter(filter string, matches []*plans.ResourceInstanceChangeSrc) {
+	r.filter = filter
+	r.matches = matches
+	r.next = 0
+}
+
+// page lists the next page of the changes that match the current filter,
+// starting over once they have all been listed.
+func (r *planReview) page() string {
+	if len(r.matches) == 0 {
+		return fmt.Sprintf("No %s.", r.filter)
+	}
+	if r.next >= len(r.matches) {
+		r.next = 0
+	}
+	end := min(r.next+reviewPageSize, len(r.matches))
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "\n%s, %d-%d of %d:\n", capitalize(r.filter), r.next+1, end, len(r.matches))
+	for _, change := range r.matches[r.next:end] {
+		fmt.Fprintf(&buf, "  %-3s %s", reviewActionSymbol(change.Action), change.Addr)
+		if change.DeposedKey != states.NotDeposed {
+			fmt.Fprintf(&buf, " (deposed object %s)", change.DeposedKey)
+		}
+		buf.WriteString("\n")
+	}
+	if end < len(r.matches) {
+		buf.WriteString("Press enter for more.\n")
+	}
+	r.next = end
+	return buf.String()
+}
+
+// show renders the changes to the given resource instance, or to all the
+// instances of the given resource, in full.
+func (r *planReview) show(addr string) {
+	var matches []*plans.ResourceInstanceChangeSrc
+	for _, change := range r.changes {
+		if change.Addr.String() == addr || change.Addr.ContainingResource().String() == addr {
+			matches = append(matches, change)
+		}
+	}
+	if len(matches) == 0 {
+		r.op.UIOut.Output(fmt.Sprintf("The plan has no changes to %q.", addr))
+		return
+	}
+
+	plan := *r.plan
+	plan.Changes = &plans.Changes{Resources: matches}
+	plan.DriftedResources = nil
+	r.op.View.Plan(&plan, r.schemas)
+}
+
+// inReviewModule returns whether the given module instance is the module
+// given by name, or one of its instances or descendants. The name "root"
+// selects the root module alone.
+func inReviewModule(module addrs.ModuleInstance, name string) bool {
+	if name == "root" {
+		return module.IsRoot()
+	}
+	s := module.String()
+	return s == name || strings.HasPrefix(s, name+".") || strings.HasPrefix(s, name+"[") || module.Module().String() == name
+}
+
+func reviewModuleName(module addrs.ModuleInstance) string {
+	if module.IsRoot() {
+		return "(root)"
+	}
+	return module.Module().String()
+}
+
+func reviewActionSymbol(action plans.Action) string {
+	switch action {
+	case plans.Create:
+		return "+"
+	case plans.Update:
+		return "~"
+	case plans.Delete:
+		return "-"
+	case plans.DeleteThenCreate:
+		return "-/+"
+	case plans.CreateThenDelete:
+		return "+/-"
+	case plans.Read:
+		return "<="
+	case plans.Forget:
+		return "."
+	case plans.ForgetThenCreate:
+		return "./+"
+	}
+	return "?"
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
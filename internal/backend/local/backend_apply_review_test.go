@@ -0,0 +1,76 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+)
+
+func TestReviewPlan(t *testing.T) {
+	changes := &plans.Changes{}
+	add := func(addr string, action plans.Action) {
+		changes.Resources = append(changes.Resources, &plans.ResourceInstanceChangeSrc{
+			Addr:        mustResourceInstanceAddr(addr),
+			PrevRunAddr: mustResourceInstanceAddr(addr),
+			ChangeSrc:   plans.ChangeSrc{Action: action},
+		})
+	}
+	for i := range 25 {
+		add(fmt.Sprintf("test_instance.web[%d]", i), plans.Create)
+	}
+	add("module.network.test_instance.subnet", plans.Delete)
+	add("module.network.test_instance.vpc", plans.DeleteThenCreate)
+	add("test_instance.unchanged", plans.NoOp)
+	plan := &plans.Plan{Changes: changes}
+
+	answers := []string{"", "", "destroys", "/vpc", "module root", "frobnicate", "yes"}
+	ui := cli.NewMockUi()
+	op := &backend.Operation{
+		UIOut: ui,
+		UIIn: &farseek.MockUIInput{
+			InputFn: func(opts *farseek.InputOpts) (string, error) {
+				answer := answers[0]
+				answers = answers[1:]
+				return answer, nil
+			},
+		},
+	}
+
+	got, err := reviewPlan(context.Background(), op, plan, nil, "Do you want to perform these actions?", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "yes" {
+		t.Errorf("wrong answer %q", got)
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"The plan has 27 changes to review: 25 to add, 0 to change, 1 to destroy, 1 to replace.",
+		"  (root)                                   25\n  module.network                           2\n",
+		"All changes, 1-20 of 27:\n  -   module.network.test_instance.subnet\n  -/+ module.network.test_instance.vpc\n  +   test_instance.web[0]\n",
+		"Press enter for more.",
+		"All changes, 21-27 of 27:",
+		"Changes that destroy objects, 1-2 of 2:\n  -   module.network.test_instance.subnet\n  -/+ module.network.test_instance.vpc\n",
+		"Changes matching \"vpc\", 1-1 of 1:\n  -/+ module.network.test_instance.vpc\n",
+		"Changes in root, 1-20 of 25:",
+		"Unknown command \"frobnicate\".",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "test_instance.unchanged") {
+		t.Errorf("output lists a change that does nothing:\n%s", output)
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/lang"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// splitPlanForTargets divides a saved plan into the changes selected by
+// targets, which are applied now, and the remaining changes, which are kept
+// for a later apply.
+//
+// A targeted change can't be applied ahead of an untargeted change it depends
+// on, and a targeted destroy can't be applied ahead of an untargeted change
+// that depends on the destroyed object, so both are reported as errors.
+func splitPlanForTargets(plan *plans.Plan, config *configs.Config, schemas *farseek.Schemas, targets []addrs.Targetable) (selected, remainder *plans.Plan, diags tfdiags.Diagnostics) {
+	var selectedChanges, remainingChanges []*plans.ResourceInstanceChangeSrc
+	for _, change := range plan.Changes.Resources {
+		if targetsContain(targets, change.Addr) {
+			selectedChanges = append(selectedChanges, change)
+		} else {
+			remainingChanges = append(remainingChanges, change)
+		}
+	}
+
+	if !hasActions(selectedChanges) {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No changes selected",
+			"None of the changes in the saved plan match the given -target addresses.",
+		), tfdiags.CodeNoChangesSelected))
+		return nil, nil, diags
+	}
+
+	missing := make(map[string]string)
+	for _, change := range selectedChanges {
+		if change.Action == plans.NoOp {
+			continue
+		}
+		deps := changeDependencies(change, config, schemas, plan.PriorState)
+		for _, other := range remainingChanges {
+			if other.Action == plans.NoOp {
+				continue
+			}
+			switch {
+			case change.Action != plans.Delete && dependsOn(deps, other.Addr):
+				missing[other.Addr.String()] = change.Addr.String()
+			case change.Action.IsReplace() || change.Action == plans.Delete:
+				if dependsOn(changeDependencies(other, config, schemas, plan.PriorState), change.Addr) {
+					missing[other.Addr.String()] = change.Addr.String()
+				}
+			}
+		}
+	}
+	if len(missing) > 0 {
+		keys := make([]string, 0, len(missing))
+		for addr := range missing {
+			keys = append(keys, addr)
+		}
+		sort.Strings(keys)
+		var buf strings.Builder
+		for _, addr := range keys {
+			fmt.Fprintf(&buf, "\n  - %s (required by %s)", addr, missing[addr])
+		}
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Targeted changes have untargeted dependencies",
+			fmt.Sprintf("The targeted changes can't be applied on their own, because they depend on other changes in the saved plan:%s\n\nAdd -target options for these addresses too.", buf.String()),
+		), tfdiags.CodeUntargetedDependencies))
+		return nil, nil, diags
+	}
+
+	selected = planWithChanges(plan, selectedChanges)
+	selected.TargetAddrs = targets
+	remainder = planWithChanges(plan, remainingChanges)
+	return selected, remainder, diags
+}
+
+func planWithChanges(plan *plans.Plan, changes []*plans.ResourceInstanceChangeSrc) *plans.Plan {
+	ret := *plan
+	ret.Changes = &plans.Changes{
+		Resources: changes,
+		Outputs:   plan.Changes.Outputs,
+	}
+	return &ret
+}
+
+// changeDependencies returns the addresses that the object affected by the
+// given change depends on, taken both from the references in its
+// configuration and from the dependencies recorded in the prior state.
+func changeDependencies(change *plans.ResourceInstanceChangeSrc, config *configs.Config, schemas *farseek.Schemas, priorState *states.State) []addrs.Targetable {
+	var deps []addrs.Targetable
+
+	if priorState != nil {
+		if obj := priorState.ResourceInstance(change.Addr); obj != nil && obj.Current != nil {
+			for _, dep := range obj.Current.Dependencies {
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	modCfg := config.DescendentForInstance(change.Addr.Module)
+	if modCfg == nil {
+		return deps
+	}
+	rc := modCfg.Module.ResourceByAddr(change.Addr.Resource.Resource)
+	if rc == nil {
+		return deps
+	}
+
+	var refs []*addrs.Reference
+	if schema, _ := schemas.ResourceTypeConfig(rc.Provider, rc.Mode, rc.Type); schema != nil {
+		moreRefs, _ := lang.ReferencesInBlock(addrs.ParseRef, rc.Config, schema)
+		refs = append(refs, moreRefs...)
+	}
+	for _, expr := range []hcl.Expression{rc.Count, rc.ForEach} {
+		moreRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, expr)
+		refs = append(refs, moreRefs...)
+	}
+	moreRefs, _ := lang.References(addrs.ParseRef, rc.DependsOn)
+	refs = append(refs, moreRefs...)
+
+	return append(deps, referencedTargets(modCfg, refs, make(map[string]bool))...)
+}
+
+// referencedTargets resolves references made from within the given module
+// to the resources and module calls they refer to, following local values.
+func referencedTargets(modCfg *configs.Config, refs []*addrs.Reference, seenLocals map[string]bool) []addrs.Targetable {
+	var ret []addrs.Targetable
+	for _, ref := range refs {
+		switch subject := ref.Subject.(type) {
+		case addrs.Resource:
+			ret = append(ret, subject.InModule(modCfg.Path))
+		case addrs.ResourceInstance:
+			ret = append(ret, subject.ContainingResource().InModule(modCfg.Path))
+		case addrs.ModuleCall:
+			ret = append(ret, modCfg.Path.Child(subject.Name))
+		case addrs.ModuleCallInstance:
+			ret = append(ret, modCfg.Path.Child(subject.Call.Name))
+		case addrs.ModuleCallInstanceOutput:
+			ret = append(ret, modCfg.Path.Child(subject.Call.Call.Name))
+		case addrs.LocalValue:
+			local := modCfg.Module.Locals[subject.Name]
+			if local == nil || seenLocals[subject.Name] {
+				continue
+			}
+			seenLocals[subject.Name] = true
+			localRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, local.Expr)
+			ret = append(ret, referencedTargets(modCfg, localRefs, seenLocals)...)
+		}
+	}
+	return ret
+}
+
+func dependsOn(deps []addrs.Targetable, addr addrs.AbsResourceInstance) bool {
+	for _, dep := range deps {
+		if dep.TargetContains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func targetsContain(targets []addrs.Targetable, addr addrs.AbsResourceInstance) bool {
+	for _, target := range targets {
+		if target.TargetContains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActions(changes []*plans.ResourceInstanceChangeSrc) bool {
+	for _, change := range changes {
+		if change.Action != plans.NoOp {
+			return true
+		}
+	}
+	return false
+}
+
+// writePartialPlanFiles records a targeted apply of the saved plan at
+// op.PlanFilePath:
+// the remaining changes are written to a new remainder plan file and the
+// saved plan itself is marked as partially applied, so that it can't be
+// applied again by mistake.
+//
+// The remainder plan is based on the state that opState persisted after the
+// targeted apply, so that it isn't stale and applies to the objects as the
+// targeted changes left them.
+func writePartialPlanFiles(op *backend.Operation, pf *planfile.Reader, opState statemgr.Full, applied, remainder *plans.Plan) (diags tfdiags.Diagnostics) {
+	path := op.PlanFilePath
+
+	args, err := pf.CreateArgs()
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read plan file",
+			fmt.Sprintf("The saved plan could not be read to record the targeted apply: %s.", err),
+		), tfdiags.CodeSavedPlanRemainderNotSaved))
+		return diags
+	}
+
+	if hasActions(remainder.Changes.Resources) {
+		remainderPath := planfile.RemainderPath(path)
+		stateFile := statemgr.Export(opState)
+		remainderArgs := args
+		remainderArgs.StateFile = stateFile
+		remainderArgs.PreviousRunStateFile = stateFile
+		remainderArgs.Plan = remainder
+		log.Printf("[INFO] backend/local: writing remainder plan to: %s", remainderPath)
+		if err := planfile.Create(remainderPath, remainderArgs, op.Encryption.Plan()); err != nil {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to write remainder plan file",
+				fmt.Sprintf("The changes that were not targeted could not be saved: %s.", err),
+			), tfdiags.CodeSavedPlanRemainderNotSaved))
+			return diags
+		}
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Saved plan partially applied",
+			fmt.Sprintf("Only the targeted changes were applied. The remaining changes were saved to %s; apply that plan file to complete the changes.", remainderPath),
+		), tfdiags.CodePlanPartiallyApplied))
+	}
+
+	for _, change := range applied.Changes.Resources {
+		if change.Action != plans.NoOp {
+			args.AppliedAddrs = append(args.AppliedAddrs, change.Addr)
+		}
+	}
+	if err := planfile.Create(path, args, op.Encryption.Plan()); err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to update plan file",
+			fmt.Sprintf("The saved plan could not be marked as partially applied: %s. Don't apply it again.", err),
+		), tfdiags.CodeSavedPlanRemainderNotSaved))
+	}
+	return diags
+}
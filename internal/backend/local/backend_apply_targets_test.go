@@ -0,0 +1,235 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+)
+
+func TestSplitPlanForTargets(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/apply-targets", "tests")
+	schemas := &farseek.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			addrs.NewDefaultProvider("test"): applyFixtureSchema(),
+		},
+	}
+
+	change := func(name string, action plans.Action) *plans.ResourceInstanceChangeSrc {
+		return &plans.ResourceInstanceChangeSrc{
+			Addr: addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: name,
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			ChangeSrc: plans.ChangeSrc{Action: action},
+		}
+	}
+	target := func(name string) addrs.Targetable {
+		target, diags := addrs.ParseTargetStr(name)
+		if diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+		return target.Subject
+	}
+
+	tests := map[string]struct {
+		changes       []*plans.ResourceInstanceChangeSrc
+		targets       []addrs.Targetable
+		wantSelected  []string
+		wantRemainder []string
+		wantErr       string
+	}{
+		"independent": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("foo", plans.Create),
+				change("other", plans.Create),
+			},
+			targets:       []addrs.Targetable{target("test_instance.other")},
+			wantSelected:  []string{"test_instance.other"},
+			wantRemainder: []string{"test_instance.foo"},
+		},
+		"dependency targeted too": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("foo", plans.Create),
+				change("bar", plans.Create),
+				change("other", plans.Create),
+			},
+			targets:       []addrs.Targetable{target("test_instance.foo"), target("test_instance.bar")},
+			wantSelected:  []string{"test_instance.foo", "test_instance.bar"},
+			wantRemainder: []string{"test_instance.other"},
+		},
+		"dependency without changes": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("foo", plans.NoOp),
+				change("bar", plans.Update),
+			},
+			targets:       []addrs.Targetable{target("test_instance.bar")},
+			wantSelected:  []string{"test_instance.bar"},
+			wantRemainder: []string{"test_instance.foo"},
+		},
+		"untargeted dependency": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("foo", plans.Create),
+				change("bar", plans.Create),
+			},
+			targets: []addrs.Targetable{target("test_instance.bar")},
+			wantErr: "test_instance.foo (required by test_instance.bar)",
+		},
+		"untargeted dependency through a local value": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("bar", plans.Update),
+				change("baz", plans.Update),
+			},
+			targets: []addrs.Targetable{target("test_instance.baz")},
+			wantErr: "test_instance.bar (required by test_instance.baz)",
+		},
+		"untargeted dependent of a destroyed object": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("foo", plans.DeleteThenCreate),
+				change("bar", plans.Update),
+			},
+			targets: []addrs.Targetable{target("test_instance.foo")},
+			wantErr: "test_instance.bar (required by test_instance.foo)",
+		},
+		"nothing selected": {
+			changes: []*plans.ResourceInstanceChangeSrc{
+				change("foo", plans.Create),
+			},
+			targets: []addrs.Targetable{target("test_instance.other")},
+			wantErr: "None of the changes in the saved plan match",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan := &plans.Plan{
+				Changes:    &plans.Changes{Resources: test.changes},
+				PriorState: states.NewState(),
+			}
+
+			selected, remainder, diags := splitPlanForTargets(plan, config, schemas, test.targets)
+			if test.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatal("expected an error")
+				}
+				if got := diags.Err().Error(); !strings.Contains(got, test.wantErr) {
+					t.Fatalf("wrong error\n got: %s\nwant: %s", got, test.wantErr)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+
+			if got := changeAddrs(selected); strings.Join(got, ",") != strings.Join(test.wantSelected, ",") {
+				t.Errorf("wrong selected changes %v; want %v", got, test.wantSelected)
+			}
+			if got := changeAddrs(remainder); strings.Join(got, ",") != strings.Join(test.wantRemainder, ",") {
+				t.Errorf("wrong remaining changes %v; want %v", got, test.wantRemainder)
+			}
+			if len(selected.TargetAddrs) != len(test.targets) {
+				t.Errorf("selected plan has targets %v; want %v", selected.TargetAddrs, test.targets)
+			}
+		})
+	}
+}
+
+func changeAddrs(plan *plans.Plan) []string {
+	var ret []string
+	for _, change := range plan.Changes.Resources {
+		ret = append(ret, change.Addr.String())
+	}
+	return ret
+}
+
+func TestLocal_applyPlanFileTargetsThenRemainder(t *testing.T) {
+	b := TestLocal(t)
+	TestLocalProvider(t, b, "test", applyFixtureSchema())
+
+	planPath := filepath.Join(t.TempDir(), "plan.tfplan")
+	op, done := testOperationPlan(t, "./testdata/apply-targets")
+	op.PlanRefresh = true
+	op.PlanOutPath = planPath
+	cfg := cty.ObjectVal(map[string]cty.Value{
+		"path": cty.StringVal(b.StatePath),
+	})
+	cfgRaw, err := plans.NewDynamicValue(cfg, cfg.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.PlanOutBackend = &plans.Backend{
+		// Just a placeholder so that we can generate a valid plan file.
+		Type:   "local",
+		Config: cfgRaw,
+	}
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("plan operation failed\n%s", done(t).All())
+	}
+
+	// apply runs the saved plan at path, limited to the given targets if
+	// any, and returns the resulting state.
+	apply := func(path string, targets ...addrs.Targetable) *states.State {
+		t.Helper()
+		planFile, err := planfile.OpenWrapped(path, encryption.PlanEncryptionDisabled())
+		if err != nil {
+			t.Fatal(err)
+		}
+		op, done := testOperationApply(t, "./testdata/apply-targets")
+		op.PlanFile = planFile
+		op.PlanFilePath = path
+		op.ApplyTargets = targets
+		run, err := b.Operation(context.Background(), op)
+		if err != nil {
+			t.Fatalf("bad: %s", err)
+		}
+		<-run.Done()
+		if run.Result != backend.OperationSuccess {
+			t.Fatalf("apply of %s failed\n%s", path, done(t).All())
+		}
+		done(t)
+
+		stateMgr := statemgr.NewFilesystem(b.StateOutPath, encryption.StateEncryptionDisabled())
+		if err := stateMgr.RefreshState(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return stateMgr.State()
+	}
+
+	state := apply(planPath, mustResourceInstanceAddr("test_instance.other"))
+	for name, want := range map[string]bool{"foo": false, "bar": false, "baz": false, "other": true} {
+		if got := state.ResourceInstance(mustResourceInstanceAddr("test_instance."+name)) != nil; got != want {
+			t.Errorf("after the targeted apply, test_instance.%s in state is %t; want %t", name, got, want)
+		}
+	}
+
+	// The remainder plan applies to the state that the targeted apply left,
+	// so it isn't stale and completes the changes.
+	state = apply(planfile.RemainderPath(planPath))
+	for _, name := range []string{"foo", "bar", "baz", "other"} {
+		if state.ResourceInstance(mustResourceInstanceAddr("test_instance."+name)) == nil {
+			t.Errorf("after applying the remainder, test_instance.%s is not in state", name)
+		}
+	}
+}
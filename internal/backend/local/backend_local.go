@@ -11,14 +11,18 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+
 	"github.com/rafagsiqueira/farseek/internal/backend"
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configload"
+	"github.com/rafagsiqueira/farseek/internal/defaulttags"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	farseekmigrate "github.com/rafagsiqueira/farseek/internal/farseekmigrate"
 	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
@@ -88,7 +92,7 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 	coreOpts.Hooks = op.Hooks
 	coreOpts.Encryption = op.Encryption
 
-	var ctxDiags tfdiags.Diagnostics
+	var ctxDiags, heldDiags tfdiags.Diagnostics
 	var configSnap *configload.Snapshot
 
 	if lp, ok := op.PlanFile.Local(); ok {
@@ -112,7 +116,7 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 		op.ConfigLoader.ImportSourcesFromSnapshot(configSnap)
 	} else {
 		log.Printf("[TRACE] backend/local: populating backend.LocalRun for current working directory")
-		ret, configSnap, ctxDiags = b.localRunDirect(ctx, op, ret, &coreOpts, s)
+		ret, configSnap, heldDiags, ctxDiags = b.localRunDirect(ctx, op, ret, &coreOpts, s)
 	}
 	diags = diags.Append(ctxDiags)
 	if diags.HasErrors() {
@@ -133,7 +137,16 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 				return nil, nil, nil, diags
 			}
 		}
+	}
+
+	// Now that every prompt has been reached, we can report the errors that
+	// strict input held back.
+	diags = diags.Append(heldDiags)
+	if diags.HasErrors() {
+		return nil, nil, nil, diags
+	}
 
+	if op.Type != backend.OperationTypeInvalid {
 		// If validation is enabled, validate
 		if b.OpValidation {
 			log.Printf("[TRACE] backend/local: running validation operation")
@@ -145,16 +158,22 @@ func (b *Local) localRun(ctx context.Context, op *backend.Operation) (*backend.L
 	return ret, configSnap, s, diags
 }
 
-func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *backend.LocalRun, coreOpts *farseek.ContextOpts, s statemgr.Full) (*backend.LocalRun, *configload.Snapshot, tfdiags.Diagnostics) {
-	var diags tfdiags.Diagnostics
+// localRunDirect populates the given run from the configuration in the
+// working directory. With strict input, the errors for required variables
+// that have no value are returned separately as held diagnostics, with
+// unknown values standing in for the variables, so that the prompts for
+// provider arguments are reached too.
+func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *backend.LocalRun, coreOpts *farseek.ContextOpts, s statemgr.Full) (*backend.LocalRun, *configload.Snapshot, tfdiags.Diagnostics, tfdiags.Diagnostics) {
+	var diags, heldDiags tfdiags.Diagnostics
 
 	// Load the configuration using the caller-provided configuration loader.
 	config, configSnap, configDiags := op.ConfigLoader.LoadConfigWithSnapshot(ctx, op.ConfigDir, op.RootCall)
 	diags = diags.Append(configDiags)
 	if configDiags.HasErrors() {
-		return nil, nil, diags
+		return nil, nil, nil, diags
 	}
 	run.Config = config
+	defaulttags.Apply(config, op.DefaultTags)
 
 	if errs := config.VerifyDependencySelections(op.DependencyLocks); len(errs) > 0 {
 		var buf strings.Builder
@@ -172,14 +191,14 @@ func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *
 		default:
 			suggestion = "To update the locked dependency selections to match a changed configuration, run:\n  farseek init -upgrade"
 		}
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Inconsistent dependency lock file",
 			fmt.Sprintf(
 				"The following dependency selections recorded in the lock file are inconsistent with the current configuration:%s\n\n%s",
 				buf.String(), suggestion,
 			),
-		))
+		), tfdiags.CodeInconsistentLockFile))
 	}
 
 	var rawVariables map[string]backend.UnparsedVariableValue
@@ -198,9 +217,19 @@ func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *
 	}
 
 	variables, varDiags := backend.ParseVariableValues(rawVariables, config.Module.Variables)
+	if varDiags.HasErrors() && op.InputStrict {
+		heldDiags = varDiags
+		variables, varDiags = backend.ParseVariableValues(b.stubUnsetRequiredVariables(rawVariables, config.Module.Variables), config.Module.Variables)
+	}
 	diags = diags.Append(varDiags)
 	if diags.HasErrors() {
-		return nil, nil, diags
+		return nil, nil, nil, diags
+	}
+
+	refreshProviders, moreDiags := resolveRefreshProviders(config, op.RefreshProviders)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, nil, nil, diags
 	}
 
 	planOpts := &farseek.PlanOpts{
@@ -210,6 +239,8 @@ func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *
 		ForceReplace:       op.ForceReplace,
 		SetVariables:       variables,
 		SkipRefresh:        op.Type != backend.OperationTypeRefresh && !op.PlanRefresh,
+		RefreshProviders:   refreshProviders,
+		DataSourceCache:    op.DataSourceCache,
 		GenerateConfigPath: op.GenerateConfigOut,
 		FarseekMode:        op.FarseekMode,
 	}
@@ -227,7 +258,7 @@ func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *
 		migratedState, migrateDiags := farseekmigrate.MigrateStateProviderAddresses(config, state)
 		diags = diags.Append(migrateDiags)
 		if migrateDiags.HasErrors() {
-			return nil, nil, diags
+			return nil, nil, nil, diags
 		}
 		state = migratedState
 	}
@@ -236,10 +267,50 @@ func (b *Local) localRunDirect(ctx context.Context, op *backend.Operation, run *
 	tfCtx, moreDiags := farseek.NewContext(coreOpts)
 	diags = diags.Append(moreDiags)
 	if moreDiags.HasErrors() {
-		return nil, nil, diags
+		return nil, nil, nil, diags
 	}
 	run.Core = tfCtx
-	return run, configSnap, diags
+	return run, configSnap, heldDiags, diags
+}
+
+// resolveRefreshProviders resolves the providers that refreshing is limited
+// to, given by their local names in the root module or their source
+// addresses, returning errors for those that the configuration doesn't use.
+func resolveRefreshProviders(config *configs.Config, names []string) ([]addrs.Provider, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(names) == 0 {
+		return nil, diags
+	}
+
+	used := config.ProviderTypes()
+	var ret []addrs.Provider
+	for _, name := range names {
+		var provider addrs.Provider
+		if strings.Contains(name, "/") {
+			var moreDiags tfdiags.Diagnostics
+			provider, moreDiags = addrs.ParseProviderSourceString(name)
+			if moreDiags.HasErrors() {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid refresh scope",
+					fmt.Sprintf("The provider %q of the -refresh-scope option isn't a valid provider source address: %s", name, moreDiags.Err()),
+				))
+				continue
+			}
+		} else {
+			provider = config.Module.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: name})
+		}
+		if !slices.Contains(used, provider) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid refresh scope",
+				fmt.Sprintf("The -refresh-scope option limits refreshing to the resources of provider %q, which the configuration doesn't use. Use the local name of the provider in the root module, such as \"aws\", or its source address, such as \"hashicorp/aws\".", name),
+			))
+			continue
+		}
+		ret = append(ret, provider)
+	}
+	return ret, diags
 }
 
 func (b *Local) localRunForPlanFile(ctx context.Context, op *backend.Operation, pf *planfile.Reader, run *backend.LocalRun, coreOpts *farseek.ContextOpts, currentStateMeta *statemgr.SnapshotMeta) (*backend.LocalRun, *configload.Snapshot, tfdiags.Diagnostics) {
@@ -283,6 +354,7 @@ func (b *Local) localRunForPlanFile(ctx context.Context, op *backend.Operation,
 		return nil, snap, diags
 	}
 	run.Config = config
+	defaulttags.Apply(config, op.DefaultTags)
 
 	// Check that all provided variables are in the configuration
 	_, undeclaredDiags := backend.ParseUndeclaredVariableValues(op.Variables, config.Module.Variables)
@@ -305,14 +377,14 @@ func (b *Local) localRunForPlanFile(ctx context.Context, op *backend.Operation,
 		for _, err := range errs {
 			fmt.Fprintf(&buf, "\n  - %s", err.Error())
 		}
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Inconsistent dependency lock file",
 			fmt.Sprintf(
 				"The following dependency selections recorded in the lock file are inconsistent with the configuration in the saved plan:%s\n\nA saved plan can be applied only to the same configuration it was created from. Create a new plan from the updated configuration.",
 				buf.String(),
 			),
-		))
+		), tfdiags.CodeInconsistentLockFile))
 	}
 
 	// This check is an important complement to the check above: the locked
@@ -327,11 +399,11 @@ func (b *Local) localRunForPlanFile(ctx context.Context, op *backend.Operation,
 	depLocksFromPlan, moreDiags := pf.ReadDependencyLocks()
 	diags = diags.Append(moreDiags)
 	if depLocksFromPlan != nil && !op.DependencyLocks.Equal(depLocksFromPlan) {
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Inconsistent dependency lock file",
 			"The given plan file was created with a different set of external dependency selections than the current configuration. A saved plan can be applied only to the same configuration it was created from.\n\nCreate a new plan from the updated configuration.",
-		))
+		), tfdiags.CodeInconsistentLockFile))
 	}
 
 	// A plan file also contains a snapshot of the prior state the changes
@@ -361,18 +433,18 @@ func (b *Local) localRunForPlanFile(ctx context.Context, op *backend.Operation,
 
 		switch {
 		case !firstPlan && priorStateFile.Lineage != currentStateMeta.Lineage:
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Saved plan does not match the given state",
 				"The given plan file can not be applied because it was created from a different state lineage.",
-			))
+			), tfdiags.CodeStalePlan))
 
 		case priorStateFile.Serial != currentStateMeta.Serial:
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Saved plan is stale",
 				"The given plan file can no longer be applied because the state was changed by another operation after the plan was created.",
-			))
+			), tfdiags.CodeStalePlan))
 		}
 	}
 	// When we're applying a saved plan, the input state is the "prior state"
@@ -442,12 +514,7 @@ func (b *Local) interactiveCollectVariables(ctx context.Context, existing map[st
 		if vc.Ephemeral {
 			varUiInput = farseek.NewEphemeralSuffixUIInput(varUiInput)
 		}
-		rawValue, err := varUiInput.Input(ctx, &farseek.InputOpts{
-			Id:          fmt.Sprintf("var.%s", name),
-			Query:       fmt.Sprintf("var.%s", name),
-			Description: vc.InputPrompt(),
-			Secret:      vc.Sensitive || vc.Ephemeral,
-		})
+		value, err := promptVariable(ctx, varUiInput, name, vc)
 		if err != nil {
 			// Since interactive prompts are best-effort, we'll just continue
 			// here and let subsequent validation report this as a variable
@@ -455,7 +522,7 @@ func (b *Local) interactiveCollectVariables(ctx context.Context, existing map[st
 			log.Printf("[WARN] backend/local: Failed to request user input for variable %q: %s", name, err)
 			continue
 		}
-		ret[name] = unparsedInteractiveVariableValue{Name: name, RawValue: rawValue}
+		ret[name] = value
 	}
 	return ret
 }
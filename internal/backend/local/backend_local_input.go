@@ -0,0 +1,277 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/lang"
+	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// maxVariableInputAttempts is how many times we prompt for the value of a
+// variable that is invalid, before we give up and let the usual variable
+// validation report the last value given.
+const maxVariableInputAttempts = 3
+
+// promptVariable prompts for the value of the given root module variable,
+// in a way that suits its type, and checks the value against the type and
+// the validation rules of the variable, prompting again if it doesn't
+// conform.
+func promptVariable(ctx context.Context, uiInput farseek.UIInput, name string, vc *configs.Variable) (backend.UnparsedVariableValue, error) {
+	description := variableInputDescription(vc)
+	var raw string
+	for range maxVariableInputAttempts {
+		var val cty.Value
+		var err error
+		raw, val, err = readVariableInput(ctx, uiInput, name, vc, description)
+		if err != nil {
+			return nil, err
+		}
+		problem := checkVariableInput(name, vc, val)
+		if problem == "" {
+			return parsedInteractiveVariableValue{Value: val}, nil
+		}
+		description = fmt.Sprintf("Error: %s\n\n%s", problem, variableInputDescription(vc))
+	}
+
+	// The usual variable validation reports what's wrong with the last
+	// value, as if it came from the command line.
+	return unparsedInteractiveVariableValue{Name: name, RawValue: raw}, nil
+}
+
+// variableInputDescription returns the description shown when prompting
+// for the value of the given variable, with its type and how to enter a
+// value of that type.
+func variableInputDescription(vc *configs.Variable) string {
+	var lines []string
+	if prompt := vc.InputPrompt(); prompt != "" {
+		lines = append(lines, prompt, "")
+	}
+
+	ty := vc.ConstraintType
+	if ty == cty.DynamicPseudoType && vc.ParsingMode == configs.VariableParseLiteral {
+		// The variable doesn't declare a type, so we take a string as
+		// we always have.
+		return strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+	lines = append(lines, fmt.Sprintf("Type: %s", typeexpr.TypeString(ty)))
+	if defaults := vc.TypeDefaults; defaults != nil && len(defaults.DefaultValues) != 0 {
+		names := make([]string, 0, len(defaults.DefaultValues))
+		for name := range defaults.DefaultValues {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		var parts []string
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s = %s", name, hclwrite.TokensForValue(defaults.DefaultValues[name]).Bytes()))
+		}
+		lines = append(lines, fmt.Sprintf("Optional attributes default to: %s", strings.Join(parts, ", ")))
+	}
+
+	switch {
+	case ty == cty.Bool:
+		lines = append(lines, "Enter true or false.")
+	case ty == cty.Number:
+		lines = append(lines, "Enter a number.")
+	case ty == cty.String:
+	case isPrimitiveCollection(ty):
+		lines = append(lines, "Enter the elements one at a time, and an empty value when done, or the whole value as an expression such as [\"a\", \"b\"].")
+	default:
+		lines = append(lines, "Enter the value as an expression, such as {name = \"a\"} or [\"a\", \"b\"].")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readVariableInput reads a value of the type of the given variable,
+// returning the raw text that was entered and the value it represents, or
+// cty.NilVal if the text isn't valid for the type.
+func readVariableInput(ctx context.Context, uiInput farseek.UIInput, name string, vc *configs.Variable, description string) (string, cty.Value, error) {
+	raw, err := uiInput.Input(ctx, &farseek.InputOpts{
+		Id:          fmt.Sprintf("var.%s", name),
+		Query:       fmt.Sprintf("var.%s", name),
+		Description: description,
+		Secret:      vc.Sensitive || vc.Ephemeral,
+	})
+	if err != nil {
+		return "", cty.NilVal, err
+	}
+
+	ty := vc.ConstraintType
+	if !isPrimitiveCollection(ty) || strings.HasPrefix(strings.TrimSpace(raw), "[") {
+		return raw, parseVariableInput(name, vc.ParsingMode, ty, raw), nil
+	}
+
+	// Lists and sets of primitive values are entered one element at a time,
+	// until an empty value.
+	elemTy := ty.ElementType()
+	var elems []cty.Value
+	var raws []string
+	for i := 1; strings.TrimSpace(raw) != ""; i++ {
+		elem := parseVariableInput(name, configs.VariableParseLiteral, elemTy, raw)
+		if elem == cty.NilVal {
+			return fmt.Sprintf("[%s]", strings.Join(append(raws, raw), ", ")), cty.NilVal, nil
+		}
+		elems = append(elems, elem)
+		raws = append(raws, string(hclwrite.TokensForValue(elem).Bytes()))
+
+		raw, err = uiInput.Input(ctx, &farseek.InputOpts{
+			Id:     fmt.Sprintf("var.%s[%d]", name, i),
+			Query:  fmt.Sprintf("var.%s[%d]", name, i),
+			Secret: vc.Sensitive || vc.Ephemeral,
+		})
+		if err != nil {
+			return "", cty.NilVal, err
+		}
+	}
+	raw = fmt.Sprintf("[%s]", strings.Join(raws, ", "))
+	if len(elems) == 0 {
+		return raw, cty.ListValEmpty(elemTy), nil
+	}
+	return raw, cty.TupleVal(elems), nil
+}
+
+// parseVariableInput parses raw as a value of the given type, returning
+// cty.NilVal if it isn't valid.
+func parseVariableInput(name string, mode configs.VariableParsingMode, ty cty.Type, raw string) cty.Value {
+	switch ty {
+	case cty.Bool:
+		switch strings.ToLower(strings.TrimSpace(raw)) {
+		case "true", "yes", "y", "1":
+			return cty.True
+		case "false", "no", "n", "0":
+			return cty.False
+		}
+		return cty.NilVal
+	case cty.Number:
+		val, err := cty.ParseNumberVal(strings.TrimSpace(raw))
+		if err != nil {
+			return cty.NilVal
+		}
+		return val
+	case cty.String:
+		if mode == configs.VariableParseLiteral {
+			return cty.StringVal(raw)
+		}
+	}
+	val, diags := mode.Parse(name, raw)
+	if diags.HasErrors() {
+		return cty.NilVal
+	}
+	return val
+}
+
+// checkVariableInput checks that the given value conforms to the type of
+// the given variable and to those of its validation rules that only refer
+// to the variable itself, returning a description of the problem if not.
+func checkVariableInput(name string, vc *configs.Variable, val cty.Value) string {
+	if val == cty.NilVal {
+		switch vc.ConstraintType {
+		case cty.Bool:
+			return "The value must be true or false."
+		case cty.Number:
+			return "The value must be a number."
+		}
+		return fmt.Sprintf("The value isn't valid for the type %s.", typeexpr.TypeString(vc.ConstraintType))
+	}
+
+	if vc.TypeDefaults != nil && !val.IsNull() {
+		val = vc.TypeDefaults.Apply(val)
+	}
+	val, err := convert.Convert(val, vc.ConstraintType)
+	if err != nil {
+		return fmt.Sprintf("The value isn't valid for the type %s: %s.", typeexpr.TypeString(vc.ConstraintType), tfdiags.FormatError(err))
+	}
+	if len(vc.Validations) == 0 {
+		return ""
+	}
+
+	if vc.Sensitive {
+		val = val.Mark(marks.Sensitive)
+	}
+	if vc.Ephemeral {
+		val = val.Mark(marks.Ephemeral)
+	}
+	hclCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{name: val}),
+		},
+		Functions: (&lang.Scope{BaseDir: ".", PureOnly: true}).Functions(),
+	}
+	for _, validation := range vc.Validations {
+		// Rules that refer to other objects are checked later, along with
+		// the rest of the configuration.
+		if !refersOnlyToVariable(name, validation.Condition) || !refersOnlyToVariable(name, validation.ErrorMessage) {
+			continue
+		}
+		result, diags := validation.Condition.Value(hclCtx)
+		if diags.HasErrors() || result.IsNull() || !result.IsWhollyKnown() {
+			continue
+		}
+		result, _ = result.Unmark()
+		if result.Type() != cty.Bool || result.True() {
+			continue
+		}
+
+		msg, diags := validation.ErrorMessage.Value(hclCtx)
+		switch {
+		case diags.HasErrors() || msg.IsNull() || !msg.IsKnown() || msg.Type() != cty.String:
+			return "The value doesn't satisfy the validation rules of the variable."
+		case marks.Has(msg, marks.Sensitive) || marks.Has(msg, marks.Ephemeral):
+			return "The value doesn't satisfy the validation rules of the variable. The error message included a sensitive value, so it is not displayed."
+		}
+		return msg.AsString()
+	}
+	return ""
+}
+
+// refersOnlyToVariable returns whether the given expression refers to no
+// other object than the root module variable of the given name.
+func refersOnlyToVariable(name string, expr hcl.Expression) bool {
+	refs, diags := lang.ReferencesInExpr(addrs.ParseRef, expr)
+	if diags.HasErrors() {
+		return false
+	}
+	for _, ref := range refs {
+		if v, ok := ref.Subject.(addrs.InputVariable); !ok || v.Name != name {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrimitiveCollection returns whether the given type is a list or a set
+// of primitive values, whose elements can be entered one at a time.
+func isPrimitiveCollection(ty cty.Type) bool {
+	return (ty.IsListType() || ty.IsSetType()) && ty.ElementType().IsPrimitiveType()
+}
+
+// parsedInteractiveVariableValue is the value of a variable that the user
+// entered interactively, already parsed for its type.
+type parsedInteractiveVariableValue struct {
+	Value cty.Value
+}
+
+var _ backend.UnparsedVariableValue = parsedInteractiveVariableValue{}
+
+func (v parsedInteractiveVariableValue) ParseVariableValue(mode configs.VariableParsingMode) (*farseek.InputValue, tfdiags.Diagnostics) {
+	return &farseek.InputValue{
+		Value:      v.Value,
+		SourceType: farseek.ValueFromInput,
+	}, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+)
+
+func TestPromptVariable(t *testing.T) {
+	tests := map[string]struct {
+		vc      *configs.Variable
+		answers []string
+		want    cty.Value
+	}{
+		"string": {
+			vc:      &configs.Variable{ConstraintType: cty.String, ParsingMode: configs.VariableParseLiteral},
+			answers: []string{"hello"},
+			want:    cty.StringVal("hello"),
+		},
+		"bool": {
+			vc:      &configs.Variable{ConstraintType: cty.Bool, ParsingMode: configs.VariableParseLiteral},
+			answers: []string{"maybe", "yes"},
+			want:    cty.True,
+		},
+		"number": {
+			vc:      &configs.Variable{ConstraintType: cty.Number, ParsingMode: configs.VariableParseLiteral},
+			answers: []string{"12.5"},
+			want:    cty.NumberFloatVal(12.5),
+		},
+		"list by element": {
+			vc:      &configs.Variable{ConstraintType: cty.List(cty.String), ParsingMode: configs.VariableParseHCL},
+			answers: []string{"a", "b", ""},
+			want:    cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		},
+		"list as expression": {
+			vc:      &configs.Variable{ConstraintType: cty.List(cty.Number), ParsingMode: configs.VariableParseHCL},
+			answers: []string{"[1, 2]"},
+			want:    cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+		},
+		"validation": {
+			vc: &configs.Variable{
+				ConstraintType: cty.Number,
+				ParsingMode:    configs.VariableParseLiteral,
+				Validations: []*configs.CheckRule{
+					{
+						Condition:    testExpr(t, "var.foo > 0"),
+						ErrorMessage: testExpr(t, `"The value must be positive."`),
+					},
+				},
+			},
+			answers: []string{"-1", "3"},
+			want:    cty.NumberIntVal(3),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var descriptions []string
+			answers := test.answers
+			input := &farseek.MockUIInput{
+				InputFn: func(opts *farseek.InputOpts) (string, error) {
+					descriptions = append(descriptions, opts.Description)
+					answer := answers[0]
+					answers = answers[1:]
+					return answer, nil
+				},
+			}
+
+			got, err := promptVariable(context.Background(), input, "foo", test.vc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(answers) != 0 {
+				t.Errorf("unused answers %q", answers)
+			}
+			val, diags := got.ParseVariableValue(test.vc.ParsingMode)
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+			if !val.Value.RawEquals(test.want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", val.Value, test.want)
+			}
+			if val.SourceType != farseek.ValueFromInput {
+				t.Errorf("wrong source type %s", val.SourceType)
+			}
+			if name == "validation" && !strings.HasPrefix(descriptions[1], "Error: The value must be positive.") {
+				t.Errorf("re-prompt doesn't show the validation error:\n%s", descriptions[1])
+			}
+		})
+	}
+}
+
+func TestPromptVariable_giveUp(t *testing.T) {
+	vc := &configs.Variable{ConstraintType: cty.Bool, ParsingMode: configs.VariableParseLiteral}
+	input := &farseek.MockUIInput{InputReturnString: "maybe"}
+
+	got, err := promptVariable(context.Background(), input, "foo", vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// After too many attempts, the raw value is left for the usual variable
+	// validation to report.
+	want := unparsedInteractiveVariableValue{Name: "foo", RawValue: "maybe"}
+	if got != want {
+		t.Errorf("wrong result %#v; want %#v", got, want)
+	}
+}
+
+func TestVariableInputDescription(t *testing.T) {
+	vc := &configs.Variable{
+		Description:    "Whether to enable the thing.",
+		ConstraintType: cty.Bool,
+		ParsingMode:    configs.VariableParseLiteral,
+	}
+	want := "Whether to enable the thing.\n\nType: bool\nEnter true or false."
+	if got := variableInputDescription(vc); got != want {
+		t.Errorf("wrong description\ngot:  %q\nwant: %q", got, want)
+	}
+
+	untyped := &configs.Variable{ConstraintType: cty.DynamicPseudoType, ParsingMode: configs.VariableParseLiteral}
+	if got := variableInputDescription(untyped); got != "" {
+		t.Errorf("unexpected description for untyped variable %q", got)
+	}
+}
+
+func testExpr(t *testing.T, src string) hcl.Expression {
+	t.Helper()
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	return expr
+}
@@ -12,10 +12,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/clistate"
@@ -249,3 +252,23 @@ func (s *stateStorageThatFailsRefresh) RefreshState(_ context.Context) error {
 func (s *stateStorageThatFailsRefresh) PersistState(_ context.Context, schemas *farseek.Schemas) error {
 	return fmt.Errorf("unimplemented")
 }
+
+func TestResolveRefreshProviders(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/apply-targets", "tests")
+	testProvider := addrs.NewDefaultProvider("test")
+
+	got, diags := resolveRefreshProviders(config, []string{"test", "hashicorp/test"})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if want := []addrs.Provider{testProvider, testProvider}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong providers %#v; want %#v", got, want)
+	}
+
+	for _, name := range []string{"aws", "hashicorp/aws", "not a provider/"} {
+		_, diags := resolveRefreshProviders(config, []string{name})
+		if got, want := diags.Err().Error(), "Invalid refresh scope"; !strings.Contains(got, want) {
+			t.Errorf("wrong error for %q\n got: %s\nwant: %s", name, got, want)
+		}
+	}
+}
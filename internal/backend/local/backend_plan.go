@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"slices"
+	"strings"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
@@ -35,7 +37,12 @@ func (b *Local) opPlan(
 
 	// TEMP: Opt-in support for testing with the new experimental language
 	// runtime. Refer to backend_temp_new_runtime.go for more information.
-	if experimentalRuntimeEnabled() {
+	experimental, runtimeDiags := useExperimentalRuntime(op)
+	if runtimeDiags.HasErrors() {
+		op.ReportResult(runningOp, runtimeDiags)
+		return
+	}
+	if experimental {
 		b.opPlanWithExperimentalRuntime(stopCtx, cancelCtx, op, runningOp)
 		return
 	}
@@ -66,14 +73,14 @@ func (b *Local) opPlan(
 
 	// Local planning requires a config, unless we're planning to destroy.
 	if op.PlanMode != plans.DestroyMode && !op.HasConfig() {
-		diags = diags.Append(tfdiags.Sourceless(
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 			tfdiags.Error,
 			"No configuration files",
 			"Plan requires configuration to be present. Planning without a configuration would "+
 				"mark everything for destruction, which is normally not what is desired. If you "+
 				"would like to destroy everything, run plan with the -destroy option. Otherwise, "+
 				"create a Farseek configuration file (.tf file) and try again.",
-		))
+		), tfdiags.CodeNoConfigurationFiles))
 		op.ReportResult(runningOp, diags)
 		return
 	}
@@ -106,6 +113,7 @@ func (b *Local) opPlan(
 		op.ReportResult(runningOp, diags)
 		return
 	}
+	diags = diags.Append(op.ModuleDeprecations.Check(lr.Config))
 	// the state was locked during successful context creation; unlock the state
 	// when the operation completes
 	defer func() {
@@ -128,6 +136,9 @@ func (b *Local) opPlan(
 		lr.InputState = states.NewState()
 
 		// Inject relevant discovered resources into the state.
+		deps := historicalDependencies(op)
+		imported := importedIDs(lr.Config)
+		providers := &discoveredProviders{op: op, config: lr.Config}
 		for _, dr := range op.DiscoveredResources {
 			if dr.IsNew {
 				// Truly new resources should NOT be in the state, so Farseek plans to create them.
@@ -136,24 +147,29 @@ func (b *Local) opPlan(
 			}
 
 			// For resources that existed in history, we inject them into state so Farseek refreshes/destroys them.
-			addr, diags := addrs.ParseAbsResourceInstanceStr(dr.Address)
-			if diags.HasErrors() {
-				log.Printf("[WARN] backend/local: Farseek failed to parse address %s: %s", dr.Address, diags.Err())
+			addr, parseDiags := addrs.ParseAbsResourceInstanceStr(dr.Address)
+			if parseDiags.HasErrors() {
+				log.Printf("[WARN] backend/local: Farseek failed to parse address %s: %s", dr.Address, parseDiags.Err())
+				continue
+			}
+
+			providerAddr, providerKey, skipped := providers.For(addr)
+			if skipped != nil {
+				// Excluded rather than left out of the state, so that the
+				// object isn't planned for creation either.
+				diags = diags.Append(skipped)
+				lr.PlanOpts.Excludes = append(lr.PlanOpts.Excludes, addr)
 				continue
 			}
 
 			log.Printf("[DEBUG] backend/local: Farseek injecting resource %s into input state", addr)
 			mod := lr.InputState.EnsureModule(addr.Module)
-			providerAddr := addrs.AbsProviderConfig{
-				Module:   addr.Module.Module(),
-				Provider: addrs.ImpliedProviderForUnqualifiedType(addr.Resource.Resource.ImpliedProvider()),
-			}
 
 			// Try to recover attributes (id/name) from history to help the provider identify the resource.
 			jsonAttrs := "{}"
 			if op.FarseekBaseSHA != "" {
-				nameVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(op.ConfigDir, op.FarseekBaseSHA, dr.Filename, dr.Address, "name")
-				idVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(op.ConfigDir, op.FarseekBaseSHA, dr.Filename, dr.Address, "id")
+				nameVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(discoveryDir(op), op.FarseekBaseSHA, dr.Filename, dr.Address, "name")
+				idVal, _ := farseek.Discovery.GetResourceAttributeFromSHA(discoveryDir(op), op.FarseekBaseSHA, dr.Filename, dr.Address, "id")
 
 				id := idVal
 				if id == "" {
@@ -168,13 +184,24 @@ func (b *Local) opPlan(
 					}
 				}
 			}
+			if id, ok := imported[dr.Address]; ok && jsonAttrs == "{}" {
+				jsonAttrs = fmt.Sprintf(`{"id":%q}`, id)
+			}
 
 			src := &states.ResourceInstanceObjectSrc{
-				Status:    states.ObjectReady,
-				AttrsJSON: []byte(jsonAttrs),
+				Status:       states.ObjectReady,
+				AttrsJSON:    []byte(jsonAttrs),
+				Dependencies: deps[dr.Address],
 			}
-			mod.SetResourceInstanceCurrent(addr.Resource, src, providerAddr, addrs.NoKey)
+			mod.SetResourceInstanceCurrent(addr.Resource, src, providerAddr, providerKey)
 		}
+		diags = diags.Append(recoverRemovedResources(ctx, op, lr))
+		if diags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+		addUnrefreshedAttributes(ctx, op, lr)
+		addExportedOutputs(op, lr)
 
 		runningOp.State = lr.InputState
 	}
@@ -188,10 +215,24 @@ func (b *Local) opPlan(
 	go func() {
 		defer panicHandler()
 		defer close(doneCh)
-		plan, planDiags = lr.Core.Plan(ctx, lr.Config, lr.InputState, lr.PlanOpts)
+		planDiags = runPrePlanTasks(ctx, op, lr)
+		if planDiags.HasErrors() {
+			return
+		}
+		var moreDiags tfdiags.Diagnostics
+		plan, moreDiags = lr.Core.Plan(ctx, lr.Config, lr.InputState, lr.PlanOpts)
+		planDiags = planDiags.Append(moreDiags)
+		setCrashPlanSummary(plan)
 
 		// FarseekMode: Suppress updates to attributes not present in the configuration
 		b.filterPlanChanges(ctx, op, lr, plan)
+		recordCommits(op, plan)
+		recordReplaceReasons(op, plan)
+		recordOutOfBandChanges(ctx, op, lr, plan)
+		filterRefreshOnlyOutputs(op, plan)
+		planDiags = planDiags.Append(checkTagPolicy(ctx, op, lr, plan))
+		planDiags = planDiags.Append(checkPlanLimits(op, plan))
+		planDiags = planDiags.Append(runPostPlanTasks(ctx, op, lr, plan))
 	}()
 
 	if b.opWait(doneCh, stopCtx, cancelCtx, lr.Core, opState, op.View) {
@@ -218,6 +259,13 @@ func (b *Local) opPlan(
 
 	// Record whether this plan includes any side-effects that could be applied.
 	runningOp.PlanEmpty = !plan.CanApply()
+	plan.Runtime = plans.LegacyRuntime
+
+	// TEMP: Compare with the plan of the new experimental language runtime,
+	// if requested. Refer to backend_temp_new_runtime.go for more information.
+	if op.CompareRuntimes {
+		diags = diags.Append(b.compareExperimentalPlan(ctx, op, plan, lr.InputState))
+	}
 
 	// Save the plan to disk
 	if path := op.PlanOutPath; path != "" {
@@ -249,14 +297,22 @@ func (b *Local) opPlan(
 			State: plan.PrevRunState,
 		}
 
-		log.Printf("[INFO] backend/local: writing plan output to: %s", path)
-		err := planfile.Create(path, planfile.CreateArgs{
+		createArgs := planfile.CreateArgs{
 			ConfigSnapshot:       configSnap,
 			PreviousRunStateFile: prevStateFile,
 			StateFile:            plannedStateFile,
 			Plan:                 plan,
 			DependencyLocks:      op.DependencyLocks,
-		}, op.Encryption.Plan())
+			Compress:             op.PlanOutCompress,
+		}
+		var err error
+		if op.PlanOutWriter != nil {
+			log.Printf("[INFO] backend/local: writing plan output to stream: %s", path)
+			err = planfile.Write(op.PlanOutWriter, createArgs, op.Encryption.Plan())
+		} else {
+			log.Printf("[INFO] backend/local: writing plan output to: %s", path)
+			err = planfile.Create(path, createArgs, op.Encryption.Plan())
+		}
 		if err != nil {
 			diags = diags.Append(tfdiags.Sourceless(
 				tfdiags.Error,
@@ -337,3 +393,31 @@ func maybeWriteGeneratedConfig(plan *plans.Plan, out string) (wroteConfig bool,
 
 	return wroteConfig, diags
 }
+
+// setCrashPlanSummary records the planned actions in the crash report, so
+// that a crash while planning or applying shows what Farseek was working
+// on. Only the addresses and actions are recorded, never the values.
+func setCrashPlanSummary(plan *plans.Plan) {
+	if plan == nil || plan.Changes == nil {
+		logging.SetCrashContext("Plan summary", "")
+		return
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Mode: %s\n", plan.UIMode)
+	counts := make(map[plans.Action]int)
+	var lines []string
+	for _, rc := range plan.Changes.Resources {
+		counts[rc.Action]++
+		if rc.Action != plans.NoOp {
+			lines = append(lines, fmt.Sprintf("%s %s", rc.Action, rc.Addr))
+		}
+	}
+	fmt.Fprintf(&buf, "Resource changes: %d to create, %d to update, %d to replace, %d to delete, %d unchanged\n",
+		counts[plans.Create], counts[plans.Update],
+		counts[plans.DeleteThenCreate]+counts[plans.CreateThenDelete], counts[plans.Delete], counts[plans.NoOp])
+	slices.Sort(lines)
+	for _, line := range lines {
+		fmt.Fprintf(&buf, "  %s\n", line)
+	}
+	logging.SetCrashContext("Plan summary", buf.String())
+}
@@ -7,15 +7,20 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
 )
 
 func TestLocal_planFarseekMode_ChecksExistence(t *testing.T) {
@@ -230,3 +235,192 @@ resource "test_instance" "foo" {
 		}
 	}
 }
+
+func TestLocal_planFarseekMode_OutOfBandChanges(t *testing.T) {
+	td := t.TempDir()
+	tfConfig := `
+resource "test_instance" "foo" {
+  ami = "ami-1"
+}
+`
+	if err := os.WriteFile(filepath.Join(td, "main.tf"), []byte(tfConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The configuration at the base SHA is the same as the current one.
+	mod, diags := configs.NewParser(nil).LoadConfigDir(td, configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	oldDiscovery := farseek.Discovery
+	t.Cleanup(func() { farseek.Discovery = oldDiscovery })
+	farseek.Discovery = historicalDiscoverer{resources: mod.ManagedResources}
+
+	b := TestLocal(t)
+	p := TestLocalProvider(t, b, "test", planFixtureSchema())
+	// Someone changed the AMI in the console.
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		return providers.ReadResourceResponse{
+			NewState: cty.ObjectVal(map[string]cty.Value{
+				"ami": cty.StringVal("ami-2"),
+				"network_interface": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"device_index": cty.Number,
+					"description":  cty.String,
+				})),
+			}),
+		}
+	}
+
+	op, done := testOperationPlan(t, td)
+	op.PlanRefresh = true
+	op.FarseekMode = true
+	op.FarseekBaseSHA = "base"
+	op.DiscoveredResources = []farseek.DiscoveredResource{
+		{Address: "test_instance.foo", Filename: "main.tf"},
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-run.Done()
+	output := done(t)
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("plan operation failed. Output:\n%s", output.Stderr())
+	}
+
+	got := output.Stdout()
+	for _, want := range []string{
+		"Objects have changed outside of version control",
+		"# test_instance.foo\n",
+		`~ ami = "ami-1" -> "ami-2"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, got)
+		}
+	}
+}
+
+func TestLocal_planFarseekMode_RefreshOnlyOutputs(t *testing.T) {
+	td := t.TempDir()
+	tfConfig := `
+resource "test_instance" "foo" {
+  ami = "ami-1"
+}
+
+output "ami" {
+  value = test_instance.foo.ami
+}
+
+output "static" {
+  value = "static"
+}
+
+output "added" {
+  value = "added"
+}
+`
+	if err := os.WriteFile(filepath.Join(td, "main.tf"), []byte(tfConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The outputs as of the last apply, before "added" was declared.
+	err := farseek.WriteOutputsFile(td, map[string]*states.OutputValue{
+		"ami":    {Value: cty.StringVal("ami-1")},
+		"static": {Value: cty.StringVal("static")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := TestLocal(t)
+	p := TestLocalProvider(t, b, "test", planFixtureSchema())
+	// Someone changed the AMI in the console.
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		return providers.ReadResourceResponse{
+			NewState: cty.ObjectVal(map[string]cty.Value{
+				"ami": cty.StringVal("ami-2"),
+				"network_interface": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"device_index": cty.Number,
+					"description":  cty.String,
+				})),
+			}),
+		}
+	}
+
+	op, done := testOperationPlan(t, td)
+	op.PlanRefresh = true
+	op.PlanMode = plans.RefreshOnlyMode
+	op.FarseekMode = true
+	op.DiscoveredResources = []farseek.DiscoveredResource{
+		{Address: "test_instance.foo", Filename: "main.tf"},
+	}
+	outDir := t.TempDir()
+	planPath := filepath.Join(outDir, "plan.tfplan")
+	op.PlanOutPath = planPath
+	cfg := cty.ObjectVal(map[string]cty.Value{
+		"path": cty.StringVal(b.StatePath),
+	})
+	cfgRaw, err := plans.NewDynamicValue(cfg, cfg.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.PlanOutBackend = &plans.Backend{
+		Type:   "local",
+		Config: cfgRaw,
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	<-run.Done()
+	output := done(t)
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("plan operation failed. Output:\n%s", output.Stderr())
+	}
+
+	got := output.Stdout()
+	for _, want := range []string{
+		"Changes to Outputs:",
+		`= "ami-1" -> "ami-2"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "added") {
+		t.Errorf("output shows an output that wasn't exported\n%s", got)
+	}
+
+	plan := testReadPlan(t, planPath)
+	actions := make(map[string]plans.Action)
+	for _, oc := range plan.Changes.Outputs {
+		actions[oc.Addr.OutputValue.Name] = oc.Action
+	}
+	want := map[string]plans.Action{
+		"ami":    plans.Update,
+		"static": plans.NoOp,
+	}
+	if diff := cmp.Diff(want, actions); diff != "" {
+		t.Errorf("wrong output changes\n%s", diff)
+	}
+}
+
+// historicalDiscoverer returns the given resources as the configuration at
+// any SHA.
+type historicalDiscoverer struct {
+	farseek.GitDiscoverer
+	resources map[string]*configs.Resource
+}
+
+func (d historicalDiscoverer) GetResourceAttributeFromSHA(dir, sha, filename, address, attribute string) (string, error) {
+	return "", nil
+}
+
+func (d historicalDiscoverer) GetResourceDependenciesFromSHA(dir, sha string) (map[string][]addrs.ConfigResource, error) {
+	return nil, nil
+}
+
+func (d historicalDiscoverer) GetResourcesFromSHA(dir, sha string) (map[string]*configs.Resource, error) {
+	return d.resources, nil
+}
@@ -8,12 +8,17 @@
 package local
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
@@ -22,14 +27,18 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/command/clistate"
 	"github.com/rafagsiqueira/farseek/internal/command/views"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/defaulttags"
 	"github.com/rafagsiqueira/farseek/internal/depsfile"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/planlimits"
 	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
 	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/runtask"
 	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tagpolicy"
 	"github.com/rafagsiqueira/farseek/internal/terminal"
 )
 
@@ -61,6 +70,161 @@ func TestLocal_planBasic(t *testing.T) {
 	}
 }
 
+func TestLocal_planTagPolicy(t *testing.T) {
+	b := TestLocal(t)
+	schema := planFixtureSchema()
+	schema.ResourceTypes["test_instance"].Block.Attributes["tags"] = &configschema.Attribute{
+		Type:     cty.Map(cty.String),
+		Optional: true,
+	}
+	TestLocalProvider(t, b, "test", schema)
+
+	op, done := testOperationPlan(t, "./testdata/plan")
+	op.TagPolicy = &tagpolicy.Policy{
+		Rules: []*tagpolicy.Rule{
+			{Prefix: "test_", Attribute: "tags", Required: []string{"owner"}, Severity: "error"},
+		},
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result == backend.OperationSuccess {
+		t.Fatal("plan operation succeeded; want failure")
+	}
+	if !run.PlanEmpty {
+		t.Error("plan should be empty, because it can't be applied")
+	}
+
+	if got, want := done(t).Stderr(), "Missing required tags"; !strings.Contains(got, want) {
+		t.Fatalf("missing error %q:\n%s", want, got)
+	}
+}
+
+func TestLocal_planLimits(t *testing.T) {
+	for name, allow := range map[string]bool{"exceeded": false, "allowed": true} {
+		t.Run(name, func(t *testing.T) {
+			b := TestLocal(t)
+			TestLocalProvider(t, b, "test", planFixtureSchema())
+
+			op, done := testOperationPlan(t, "./testdata/plan")
+			op.PlanLimits = planlimits.Limits{
+				Create: &planlimits.Limit{Max: 0, Source: "the -max-create option"},
+			}
+			op.AllowExceedingLimits = allow
+
+			run, err := b.Operation(context.Background(), op)
+			if err != nil {
+				t.Fatalf("bad: %s", err)
+			}
+			<-run.Done()
+			output := done(t)
+			if got, want := run.Result == backend.OperationSuccess, allow; got != want {
+				t.Fatalf("wrong result %v\n%s", run.Result, output.Stderr())
+			}
+			if got, want := output.All(), "Plan exceeds the create limit"; !strings.Contains(got, want) {
+				t.Fatalf("missing diagnostic %q:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestLocal_planDefaultTags(t *testing.T) {
+	b := TestLocal(t)
+	schema := planFixtureSchema()
+	schema.ResourceTypes["test_instance"].Block.Attributes["tags"] = &configschema.Attribute{
+		Type:     cty.Map(cty.String),
+		Optional: true,
+	}
+	p := TestLocalProvider(t, b, "test", schema)
+
+	op, done := testOperationPlan(t, "./testdata/plan")
+	op.DefaultTags = map[addrs.Provider]*defaulttags.Defaults{
+		addrs.NewDefaultProvider("test"): {
+			Tags: map[string]string{"owner": "platform"},
+		},
+	}
+	// The default tags satisfy the tag policy.
+	op.TagPolicy = &tagpolicy.Policy{
+		Rules: []*tagpolicy.Rule{
+			{Prefix: "test_", Attribute: "tags", Required: []string{"owner"}, Severity: "error"},
+		},
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("plan operation failed\n%s", done(t).Stderr())
+	}
+	done(t)
+
+	if !p.PlanResourceChangeCalled {
+		t.Fatal("PlanResourceChange not called")
+	}
+	got := p.PlanResourceChangeRequest.Config.GetAttr("tags")
+	want := cty.MapVal(map[string]cty.Value{"owner": cty.StringVal("platform")})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong tags in the configuration\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestLocal_planRunTasks(t *testing.T) {
+	b := TestLocal(t)
+	TestLocalProvider(t, b, "test", planFixtureSchema())
+
+	var stages []runtask.Stage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req runtask.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %s", err)
+		}
+		stages = append(stages, req.Stage)
+		switch req.Stage {
+		case runtask.PrePlan:
+			if !bytes.Contains(req.Configuration, []byte(`"test_instance"`)) {
+				t.Errorf("configuration missing from request: %s", req.Configuration)
+			}
+			w.Write([]byte(`{"status":"passed"}`))
+		case runtask.PostPlan:
+			if !bytes.Contains(req.Plan, []byte(`"resource_changes"`)) {
+				t.Errorf("plan missing from request: %s", req.Plan)
+			}
+			w.Write([]byte(`{"status":"failed","message":"Instances must not be public."}`))
+		}
+	}))
+	defer server.Close()
+
+	op, done := testOperationPlan(t, "./testdata/plan")
+	op.RunTasks = []*runtask.Task{
+		{Name: "lint", URL: server.URL, Stage: runtask.PrePlan, EnforcementLevel: runtask.Mandatory},
+		{Name: "scanner", URL: server.URL, Stage: runtask.PostPlan, EnforcementLevel: runtask.Mandatory},
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result == backend.OperationSuccess {
+		t.Fatal("plan operation succeeded; want failure")
+	}
+	if !run.PlanEmpty {
+		t.Error("plan should be empty, because it can't be applied")
+	}
+	if diff := cmp.Diff([]runtask.Stage{runtask.PrePlan, runtask.PostPlan}, stages); diff != "" {
+		t.Errorf("wrong run task calls\n%s", diff)
+	}
+
+	if got, want := done(t).Stderr(), "Instances must not be public."; !strings.Contains(got, want) {
+		t.Fatalf("missing error %q:\n%s", want, got)
+	}
+}
+
 func TestLocal_planInAutomation(t *testing.T) {
 	b := TestLocal(t)
 	TestLocalProvider(t, b, "test", planFixtureSchema())
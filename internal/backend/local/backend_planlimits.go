@@ -0,0 +1,22 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// checkPlanLimits checks the number of objects that the plan creates, updates
+// and destroys against the limits of the operation. A plan that exceeds a
+// limit is marked as errored, so that it can't be applied, unless the
+// operation allows exceeding them.
+func checkPlanLimits(op *backend.Operation, plan *plans.Plan) tfdiags.Diagnostics {
+	diags := op.PlanLimits.Check(plan, op.AllowExceedingLimits)
+	if diags.HasErrors() {
+		plan.Errored = true
+	}
+	return diags
+}
@@ -28,7 +28,12 @@ func (b *Local) opRefresh(
 
 	// TEMP: Opt-in support for testing with the new experimental language
 	// runtime. Refer to backend_temp_new_runtime.go for more information.
-	if experimentalRuntimeEnabled() {
+	experimental, runtimeDiags := useExperimentalRuntime(op)
+	if runtimeDiags.HasErrors() {
+		op.ReportResult(runningOp, runtimeDiags)
+		return
+	}
+	if experimental {
 		b.opRefreshWithExperimentalRuntime(stopCtx, cancelCtx, op, runningOp)
 		return
 	}
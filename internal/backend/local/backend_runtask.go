@@ -0,0 +1,81 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonconfig"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
+	"github.com/rafagsiqueira/farseek/internal/httpclient"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/runtask"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// runPrePlanTasks calls the pre_plan run tasks of the operation with the
+// JSON representation of the configuration.
+func runPrePlanTasks(ctx context.Context, op *backend.Operation, lr *backend.LocalRun) tfdiags.Diagnostics {
+	if !hasRunTasks(op, runtask.PrePlan) {
+		return nil
+	}
+	schemas, diags := lr.Core.Schemas(ctx, lr.Config, lr.InputState)
+	if diags.HasErrors() {
+		// The same errors are reported when planning.
+		return nil
+	}
+	config, err := jsonconfig.Marshal(lr.Config, schemas)
+	if err != nil {
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to prepare run tasks",
+			fmt.Sprintf("Farseek couldn't produce the JSON representation of the configuration for the pre_plan run tasks: %s.", err),
+		))
+	}
+	return runtask.Run(ctx, httpclient.New(ctx), op.RunTasks, runtask.PrePlan, runtask.Request{
+		Configuration: config,
+	})
+}
+
+// runPostPlanTasks calls the post_plan run tasks of the operation with the
+// JSON representation of the plan. If a mandatory task fails, the plan is
+// marked as errored, so that it can't be applied.
+func runPostPlanTasks(ctx context.Context, op *backend.Operation, lr *backend.LocalRun, plan *plans.Plan) tfdiags.Diagnostics {
+	if plan == nil || plan.Errored || !hasRunTasks(op, runtask.PostPlan) {
+		return nil
+	}
+	schemas, diags := lr.Core.Schemas(ctx, lr.Config, lr.InputState)
+	if diags.HasErrors() {
+		// The same errors are reported when rendering the plan.
+		return nil
+	}
+	planJSON, err := jsonplan.Marshal(lr.Config, plan, statefile.New(plan.PriorState, "", 0), schemas)
+	if err != nil {
+		plan.Errored = true
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to prepare run tasks",
+			fmt.Sprintf("Farseek couldn't produce the JSON representation of the plan for the post_plan run tasks: %s.", err),
+		))
+	}
+	diags = runtask.Run(ctx, httpclient.New(ctx), op.RunTasks, runtask.PostPlan, runtask.Request{
+		Plan: planJSON,
+	})
+	if diags.HasErrors() {
+		plan.Errored = true
+	}
+	return diags
+}
+
+func hasRunTasks(op *backend.Operation, stage runtask.Stage) bool {
+	for _, task := range op.RunTasks {
+		if task.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
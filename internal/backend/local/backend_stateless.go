@@ -5,13 +5,658 @@ package local
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/defaulttags"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/refactoring"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
+// discoveryDir returns the directory that the discovery of the given
+// operation ran in, where the git history of its configuration is.
+func discoveryDir(op *backend.Operation) string {
+	if op.DiscoveryDir != "" {
+		return op.DiscoveryDir
+	}
+	return op.ConfigDir
+}
+
+// historicalDependencies infers the dependencies between the resources that
+// a stateless operation injects into its input state, which otherwise have
+// none, from the configuration at the base SHA. Without them, destroys could
+// run in any order, such as removing a network before the subnets in it.
+func historicalDependencies(op *backend.Operation) map[string][]addrs.ConfigResource {
+	if len(op.DiscoveredResources) == 0 {
+		return nil
+	}
+	sha := op.FarseekBaseSHA
+	if sha == "" {
+		sha = "HEAD"
+	}
+	deps, err := farseek.Discovery.GetResourceDependenciesFromSHA(discoveryDir(op), sha)
+	if err != nil {
+		log.Printf("[WARN] backend/local: Farseek failed to infer dependencies at %s: %s", sha, err)
+		return nil
+	}
+	return deps
+}
+
+// importedIDs returns the IDs given literally in the import blocks of the
+// root module, keyed by the address of the resource instance they import.
+// Stateless operations use them to identify the objects of resources whose
+// configuration doesn't, such as those listed by "farseek convert-state
+// -stateless".
+func importedIDs(config *configs.Config) map[string]string {
+	if config == nil {
+		return nil
+	}
+	ids := make(map[string]string)
+	for _, imp := range config.Module.Import {
+		if imp.ResolvedTo == nil || imp.ID == nil {
+			continue
+		}
+		val, diags := imp.ID.Value(nil)
+		if diags.HasErrors() || !val.IsKnown() || val.IsNull() || val.Type() != cty.String {
+			continue
+		}
+		ids[imp.ResolvedTo.String()] = val.AsString()
+	}
+	return ids
+}
+
+// discoveredProviders resolves the provider configuration of each object
+// that a stateless operation injects into state for a discovered resource.
+//
+// The provider comes from the resource's configuration: the current one if
+// the resource is still configured, or the one at the base SHA if it was
+// removed since. Either way, its local name is resolved through the
+// required_providers of its module and its alias is kept, so that the object
+// is refreshed by the same provider configuration that manages it. The
+// implied provider of the resource type is only a last resort.
+type discoveredProviders struct {
+	op     *backend.Operation
+	config *configs.Config
+
+	// historical are the managed resources of the root module at the base
+	// SHA, loaded on first use since most resources are still configured.
+	historical       map[string]*configs.Resource
+	loadedHistorical bool
+}
+
+// For returns the provider configuration and its instance key for the
+// object of the given discovered resource instance.
+//
+// If the resource is configured with an instance of a provider whose key
+// can't be resolved, For returns a warning instead, and the object must be
+// left out of the operation rather than refreshed through a guessed
+// provider configuration.
+func (p *discoveredProviders) For(addr addrs.AbsResourceInstance) (addrs.AbsProviderConfig, addrs.InstanceKey, tfdiags.Diagnostic) {
+	implied := addr.Resource.Resource.ImpliedProvider()
+	var rc *configs.Resource
+	var mc *configs.Config
+	if p.config != nil {
+		mc = p.config.DescendentForInstance(addr.Module)
+	}
+	if mc != nil {
+		rc = mc.Module.ResourceByAddr(addr.Resource.Resource)
+	}
+	configured := rc != nil
+	if rc == nil && addr.Module.IsRoot() {
+		rc = p.historicalResource(addr.Resource.Resource)
+	}
+
+	ret := addrs.AbsProviderConfig{
+		Module:   addr.Module.Module(),
+		Provider: addrs.ImpliedProviderForUnqualifiedType(implied),
+	}
+	switch {
+	case rc != nil && !rc.Provider.IsZero():
+		ret.Provider = rc.Provider
+		key, err := providerInstanceKey(addr, rc)
+		if err != nil && configured {
+			return ret, addrs.NoKey, tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Resource instance left out",
+				fmt.Sprintf("Farseek can't tell which instance of %s manages %s, because %s, so it has left %s and anything that depends on it out of this operation.", rc.ProviderConfigAddr().StringCompact(), addr, err, addr),
+			), tfdiags.CodeDiscoveredResourceSkipped)
+		}
+		if err != nil {
+			// recoverRemovedResources reports removed resources whose
+			// provider instance can't be resolved.
+			return ret, addrs.NoKey, nil
+		}
+		ret.Alias = rc.ProviderConfigAddr().Alias
+		return ret, key, nil
+	case mc != nil:
+		// A resource removed from a module that's still configured most
+		// likely used the provider that the module calls by that name.
+		ret.Provider = mc.Module.ImpliedProviderForUnqualifiedType(implied)
+	}
+	return ret, addrs.NoKey, nil
+}
+
+// historicalResource returns the configuration of the given root module
+// resource at the base SHA, or nil if there's none.
+func (p *discoveredProviders) historicalResource(addr addrs.Resource) *configs.Resource {
+	if !p.loadedHistorical {
+		p.loadedHistorical = true
+		sha := p.op.FarseekBaseSHA
+		if sha == "" {
+			sha = "HEAD"
+		}
+		historical, err := farseek.Discovery.GetResourcesFromSHA(discoveryDir(p.op), sha)
+		if err != nil {
+			log.Printf("[WARN] backend/local: Farseek failed to load the resources at %s to resolve their providers: %s", sha, err)
+		}
+		p.historical = historical
+	}
+	return p.historical[addr.String()]
+}
+
+// recoverRemovedResources adds to a stateless operation what the
+// configuration at the base SHA says about the resources that have since been
+// removed from the configuration, so that destroying them behaves as it would
+// have with state:
+//
+//   - Their destroy-time provisioners are added to the configuration as if
+//     declared in "removed" blocks, unless there is such a block already.
+//   - The objects injected for them into the input state are given the
+//     provider configurations they used, such as one instance of a provider
+//     with for_each, instead of the default one of their implied providers.
+//   - The literal values of their arguments are added to those objects, so
+//     that those provisioners can refer to them through self.
+func recoverRemovedResources(ctx context.Context, op *backend.Operation, lr *backend.LocalRun) tfdiags.Diagnostics {
+	var ret tfdiags.Diagnostics
+	var removed []addrs.AbsResourceInstance
+	for _, dr := range op.DiscoveredResources {
+		if dr.IsNew || dr.Config != nil {
+			continue
+		}
+		addr, diags := addrs.ParseAbsResourceInstanceStr(dr.Address)
+		if diags.HasErrors() || !addr.Module.IsRoot() {
+			continue
+		}
+		if lr.Config.Module.ResourceByAddr(addr.Resource.Resource) != nil {
+			// Still configured, as for a destroy of the whole stack.
+			continue
+		}
+		removed = append(removed, addr)
+	}
+	if len(removed) == 0 {
+		return ret
+	}
+
+	sha := op.FarseekBaseSHA
+	if sha == "" {
+		sha = "HEAD"
+	}
+	historical, err := farseek.Discovery.GetResourcesFromSHA(discoveryDir(op), sha)
+	if err != nil {
+		log.Printf("[WARN] backend/local: Farseek failed to recover removed resources at %s: %s", sha, err)
+		return ret
+	}
+
+	schemas, diags := lr.Core.Schemas(ctx, lr.Config, lr.InputState)
+	if diags.HasErrors() {
+		// The operation itself will report the problem.
+		log.Printf("[WARN] backend/local: Farseek failed to load schemas to recover removed resources: %s", diags.Err())
+		schemas = nil
+	}
+
+	for _, addr := range removed {
+		rc := historical[addr.Resource.Resource.String()]
+		if rc == nil || rc.Managed == nil {
+			continue
+		}
+
+		if provs := historicalDestroyProvisioners(rc); len(provs) != 0 && refactoring.FindResourceRemovedStatement(lr.Config, addr.ConfigResource()) == nil {
+			log.Printf("[DEBUG] backend/local: Farseek recovered %d destroy-time provisioners for %s", len(provs), addr)
+			lr.Config.Module.Removed = append(lr.Config.Module.Removed, &configs.Removed{
+				From:         &addrs.RemoveEndpoint{RelSubject: addr.ConfigResource()},
+				Destroy:      true,
+				DestroySet:   true,
+				Provisioners: provs,
+				DeclRange:    rc.DeclRange,
+			})
+		}
+
+		ret = ret.Append(setHistoricalProvider(lr.InputState, addr, rc))
+		if schemas != nil {
+			addHistoricalAttributes(lr.InputState, addr, rc, schemas)
+		}
+	}
+	return ret
+}
+
+// historicalDestroyProvisioners returns the destroy-time provisioners of the
+// given resource, each with the resource's own connection block merged into
+// its own, since "removed" blocks can't have one.
+func historicalDestroyProvisioners(rc *configs.Resource) []*configs.Provisioner {
+	var ret []*configs.Provisioner
+	for _, p := range rc.Managed.Provisioners {
+		if p.When != configs.ProvisionerWhenDestroy {
+			continue
+		}
+		prov := *p
+		if base := rc.Managed.Connection; base != nil {
+			conn := *base
+			if prov.Connection != nil {
+				conn.Config = configs.MergeBodies(base.Config, prov.Connection.Config)
+			}
+			prov.Connection = &conn
+		}
+		ret = append(ret, &prov)
+	}
+	return ret
+}
+
+// setHistoricalProvider sets the provider configuration of the object
+// injected into state for the given resource instance to the one that the
+// given historical resource configuration refers to. An instance key of the
+// provider configuration is resolved if it's a literal, or derived from the
+// resource instance's own key through each.key or count.index; otherwise
+// it's an error, since the object would be destroyed with the wrong
+// provider instance.
+func setHistoricalProvider(state *states.State, addr addrs.AbsResourceInstance, rc *configs.Resource) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	ms := state.Module(addr.Module)
+	is := state.ResourceInstance(addr)
+	if ms == nil || is == nil || is.Current == nil || rc.Provider.IsZero() {
+		return diags
+	}
+
+	providerKey, err := providerInstanceKey(addr, rc)
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unknown provider instance for removed resource",
+			fmt.Sprintf("Farseek can't tell which instance of %s to destroy %s with, because %s. Add the resource back to the configuration with for_each set to an empty collection until its instances have been destroyed.", rc.ProviderConfigAddr().StringCompact(), addr, err),
+		), tfdiags.CodeUnknownProviderInstance))
+		return diags
+	}
+	provider := addrs.AbsProviderConfig{
+		Module:   addr.Module.Module(),
+		Provider: rc.Provider,
+		Alias:    rc.ProviderConfigAddr().Alias,
+	}
+	ms.SetResourceInstanceCurrent(addr.Resource, is.Current, provider, providerKey)
+	return diags
+}
+
+// providerInstanceKey returns the instance key of the provider configuration
+// that the given resource configuration refers to for the given instance of
+// the resource, or an error if it can't be resolved before planning.
+func providerInstanceKey(addr addrs.AbsResourceInstance, rc *configs.Resource) (addrs.InstanceKey, error) {
+	ref := rc.ProviderConfigRef
+	if ref == nil || ref.KeyExpression == nil {
+		return addrs.NoKey, nil
+	}
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{}}
+	switch key := addr.Resource.Key.(type) {
+	case addrs.StringKey:
+		ctx.Variables["each"] = cty.ObjectVal(map[string]cty.Value{
+			"key":   cty.StringVal(string(key)),
+			"value": cty.DynamicVal,
+		})
+	case addrs.IntKey:
+		ctx.Variables["count"] = cty.ObjectVal(map[string]cty.Value{
+			"index": cty.NumberIntVal(int64(key)),
+		})
+	}
+	val, diags := ref.KeyExpression.Value(ctx)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return nil, fmt.Errorf("its key can only be evaluated from each.key, count.index or literal values")
+	}
+	key, err := addrs.ParseInstanceKey(val)
+	if err != nil {
+		return nil, fmt.Errorf("its key is invalid: %w", err)
+	}
+	return key, nil
+}
+
+// addHistoricalAttributes adds the literal values of the arguments of the
+// given historical resource configuration to the object injected for it into
+// state, leaving the attributes that the object already has alone. Arguments
+// whose values depend on anything else are left out.
+func addHistoricalAttributes(state *states.State, addr addrs.AbsResourceInstance, rc *configs.Resource, schemas *farseek.Schemas) {
+	rs := state.Resource(addr.ContainingResource())
+	is := state.ResourceInstance(addr)
+	if rs == nil || is == nil || is.Current == nil {
+		return
+	}
+	schema, _ := schemas.ResourceTypeConfig(rs.ProviderConfig.Provider, addr.Resource.Resource.Mode, addr.Resource.Resource.Type)
+	if schema == nil {
+		return
+	}
+
+	attrs := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(is.Current.AttrsJSON, &attrs); err != nil {
+		return
+	}
+
+	bodySchema := &hcl.BodySchema{}
+	for name := range schema.Attributes {
+		bodySchema.Attributes = append(bodySchema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+	content, _, _ := rc.Config.PartialContent(bodySchema)
+	changed := false
+	for name, attr := range content.Attributes {
+		if _, exists := attrs[name]; exists {
+			continue
+		}
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || !val.IsWhollyKnown() {
+			continue
+		}
+		ty := schema.Attributes[name].ImpliedType()
+		val, err := convert.Convert(val, ty)
+		if err != nil {
+			continue
+		}
+		src, err := ctyjson.Marshal(val, ty)
+		if err != nil {
+			continue
+		}
+		attrs[name] = src
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	src, err := json.Marshal(attrs)
+	if err != nil {
+		return
+	}
+	is.Current.AttrsJSON = src
+}
+
+// addUnrefreshedAttributes adds the literal values of the arguments that the
+// configuration at the base SHA declared to the objects injected into state
+// for the resources whose providers are outside of the refresh scope. They
+// aren't refreshed, so without them the plan would compare the
+// configuration with objects that only have an id, rather than with what
+// was last applied.
+func addUnrefreshedAttributes(ctx context.Context, op *backend.Operation, lr *backend.LocalRun) {
+	if op.FarseekBaseSHA == "" || lr.PlanOpts == nil || lr.PlanOpts.SkipRefresh || len(lr.PlanOpts.RefreshProviders) == 0 {
+		return
+	}
+	ms := lr.InputState.Module(addrs.RootModuleInstance)
+	if ms == nil {
+		return
+	}
+
+	var unrefreshed []addrs.AbsResourceInstance
+	for _, rs := range ms.Resources {
+		rc := lr.Config.Module.ResourceByAddr(rs.Addr.Resource)
+		if rc == nil || rc.Mode != addrs.ManagedResourceMode || slices.Contains(lr.PlanOpts.RefreshProviders, rc.Provider) {
+			continue
+		}
+		for key := range rs.Instances {
+			unrefreshed = append(unrefreshed, rs.Addr.Instance(key))
+		}
+	}
+	if len(unrefreshed) == 0 {
+		return
+	}
+
+	historical, err := farseek.Discovery.GetResourcesFromSHA(discoveryDir(op), op.FarseekBaseSHA)
+	if err != nil {
+		log.Printf("[WARN] backend/local: Farseek failed to read the resources that aren't refreshed at %s: %s", op.FarseekBaseSHA, err)
+		return
+	}
+	schemas, diags := lr.Core.Schemas(ctx, lr.Config, lr.InputState)
+	if diags.HasErrors() {
+		// The operation itself will report the problem.
+		log.Printf("[WARN] backend/local: Farseek failed to load schemas for the resources that aren't refreshed: %s", diags.Err())
+		return
+	}
+
+	for _, addr := range unrefreshed {
+		hrc := historical[addr.Resource.Resource.String()]
+		if hrc == nil || hrc.Managed == nil {
+			continue
+		}
+		log.Printf("[DEBUG] backend/local: Farseek planning %s from its configuration at %s, since it isn't refreshed", addr, op.FarseekBaseSHA)
+		defaulttags.ApplyResource(hrc, op.DefaultTags[hrc.Provider])
+		addHistoricalAttributes(lr.InputState, addr, hrc, schemas)
+	}
+}
+
+// recordCommits records in a stateless plan the commits that last changed
+// the resources the operation discovered, so that the plan can show them.
+func recordCommits(op *backend.Operation, plan *plans.Plan) {
+	if !op.FarseekMode || plan == nil {
+		return
+	}
+	for _, dr := range op.DiscoveredResources {
+		if dr.Commit == nil {
+			continue
+		}
+		if plan.Commits == nil {
+			plan.Commits = make(map[string]*plans.Commit)
+		}
+		plan.Commits[dr.Address] = dr.Commit
+	}
+}
+
+// recordReplaceReasons records in the plan the reasons given for the
+// replacements that the operation requested, so that the plan can show them
+// and they're saved with it.
+func recordReplaceReasons(op *backend.Operation, plan *plans.Plan) {
+	if plan == nil {
+		return
+	}
+	for _, addr := range plan.ForceReplaceAddrs {
+		reason, ok := op.ForceReplaceReasons[addr.String()]
+		if !ok {
+			continue
+		}
+		if plan.ForceReplaceReasons == nil {
+			plan.ForceReplaceReasons = make(map[string]string)
+		}
+		plan.ForceReplaceReasons[addr.String()] = reason
+	}
+}
+
+// recordOutOfBandChanges records in a stateless plan the attributes whose
+// refreshed remote values differ from the literal values that the
+// configuration declared at the base SHA. The configuration didn't change
+// them, so something outside of version control did, and the plan can show
+// them apart from the changes that the configuration drives.
+//
+// Only the resources of the root module are compared, as the configuration
+// at the base SHA is only loaded for the root module, and arguments whose
+// values depend on anything else are left out.
+func recordOutOfBandChanges(ctx context.Context, op *backend.Operation, lr *backend.LocalRun, plan *plans.Plan) {
+	if !op.FarseekMode || op.FarseekBaseSHA == "" || plan == nil || plan.Changes == nil {
+		return
+	}
+
+	var changes []*plans.ResourceInstanceChangeSrc
+	for _, rc := range plan.Changes.Resources {
+		if rc.Addr.Resource.Resource.Mode != addrs.ManagedResourceMode || !rc.Addr.Module.IsRoot() || rc.DeposedKey != states.NotDeposed {
+			continue
+		}
+		switch rc.Action {
+		case plans.NoOp, plans.Update, plans.DeleteThenCreate, plans.CreateThenDelete:
+			changes = append(changes, rc)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	historical, err := farseek.Discovery.GetResourcesFromSHA(discoveryDir(op), op.FarseekBaseSHA)
+	if err != nil {
+		log.Printf("[WARN] backend/local: Farseek failed to detect out-of-band changes since %s: %s", op.FarseekBaseSHA, err)
+		return
+	}
+	schemas, diags := lr.Core.Schemas(ctx, lr.Config, lr.InputState)
+	if diags.HasErrors() {
+		// The same errors are reported when rendering the plan.
+		return
+	}
+
+	for _, rc := range changes {
+		hrc := historical[rc.Addr.Resource.Resource.String()]
+		if hrc == nil || hrc.Config == nil {
+			continue
+		}
+		provider := rc.ProviderAddr.Provider
+		schema, _ := schemas.ResourceTypeConfig(provider, addrs.ManagedResourceMode, rc.Addr.Resource.Resource.Type)
+		if schema == nil {
+			continue
+		}
+		before, err := rc.Before.Decode(schema.ImpliedType())
+		if err != nil || before.IsNull() || !before.IsKnown() {
+			continue
+		}
+		defaulttags.ApplyResource(hrc, op.DefaultTags[provider])
+
+		bodySchema := &hcl.BodySchema{}
+		for name := range schema.Attributes {
+			bodySchema.Attributes = append(bodySchema.Attributes, hcl.AttributeSchema{Name: name})
+		}
+		content, _, _ := hrc.Config.PartialContent(bodySchema)
+		var found []*plans.OutOfBandChange
+		for name, attr := range content.Attributes {
+			declared, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !declared.IsWhollyKnown() || declared.IsNull() {
+				continue
+			}
+			declared, err := convert.Convert(declared, schema.Attributes[name].ImpliedType())
+			if err != nil {
+				continue
+			}
+			actual := before.GetAttr(name)
+			if eq := actual.Equals(declared); !eq.IsKnown() || eq.True() {
+				continue
+			}
+			found = append(found, &plans.OutOfBandChange{
+				Attribute: name,
+				Declared:  declared,
+				Actual:    actual,
+				Sensitive: schema.Attributes[name].Sensitive,
+			})
+		}
+		if len(found) == 0 {
+			continue
+		}
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Attribute < found[j].Attribute
+		})
+		if plan.OutOfBandChanges == nil {
+			plan.OutOfBandChanges = make(map[string][]*plans.OutOfBandChange)
+		}
+		plan.OutOfBandChanges[rc.Addr.String()] = found
+	}
+}
+
+// addExportedOutputs adds the root module outputs that the configuration
+// root exported when it was last applied to the input state of a stateless
+// refresh-only plan. The plan then compares them with the outputs evaluated
+// from the refreshed resources, to show how changes outside of Farseek
+// changed the outputs.
+func addExportedOutputs(op *backend.Operation, lr *backend.LocalRun) {
+	if op.PlanMode != plans.RefreshOnlyMode || op.ConfigDir == "" {
+		return
+	}
+	exported, err := farseek.ReadOutputsFile(op.ConfigDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] backend/local: ignoring previously exported outputs: %s", err)
+		}
+		return
+	}
+
+	mod := lr.InputState.EnsureModule(addrs.RootModuleInstance)
+	for name, output := range exported {
+		if _, declared := lr.Config.Module.Outputs[name]; !declared {
+			continue
+		}
+		mod.SetOutputValue(name, output.Value, output.Sensitive, output.Deprecated)
+	}
+}
+
+// filterRefreshOnlyOutputs leaves out of a stateless refresh-only plan the
+// changes of root module outputs that weren't exported when the
+// configuration root was last applied, or whose value depends on resources
+// that the operation didn't discover, so that the plan only shows the
+// outputs whose values changed outside of Farseek.
+func filterRefreshOnlyOutputs(op *backend.Operation, plan *plans.Plan) {
+	if !op.FarseekMode || plan == nil || plan.Changes == nil || plan.UIMode != plans.RefreshOnlyMode {
+		return
+	}
+
+	var outputs []*plans.OutputChangeSrc
+	for _, oc := range plan.Changes.Outputs {
+		if oc.Addr.Module.IsRoot() {
+			if oc.Action == plans.Create {
+				continue
+			}
+			change, err := oc.Decode()
+			if err != nil || !change.After.IsWhollyKnown() {
+				continue
+			}
+		}
+		outputs = append(outputs, oc)
+	}
+	plan.Changes.Outputs = outputs
+}
+
+// exportOutputs updates the exported outputs file of the configuration root
+// in dir after a stateless apply. A stateless apply only evaluates the
+// outputs that depend on the resources it targeted, so the others keep their
+// previously exported values, while outputs that are no longer declared are
+// dropped. Destroying the stack removes the file.
+func exportOutputs(dir string, config *configs.Config, plan *plans.Plan, state *states.State) error {
+	if plan.UIMode == plans.DestroyMode {
+		err := os.Remove(filepath.Join(dir, farseek.OutputsFilename))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	previous, err := farseek.ReadOutputsFile(dir)
+	if err != nil && !os.IsNotExist(err) {
+		// We'll replace a corrupt file rather than failing every apply.
+		log.Printf("[WARN] backend/local: ignoring previously exported outputs: %s", err)
+	}
+	var current map[string]*states.OutputValue
+	if mod := state.RootModule(); mod != nil {
+		current = mod.OutputValues
+	}
+
+	outputs := make(map[string]*states.OutputValue)
+	for name := range config.Module.Outputs {
+		if output, ok := current[name]; ok {
+			outputs[name] = output
+		} else if output, ok := previous[name]; ok {
+			outputs[name] = output
+		}
+	}
+	return farseek.WriteOutputsFile(dir, outputs)
+}
+
 func (b *Local) filterPlanChanges(
 	ctx context.Context,
 	op *backend.Operation,
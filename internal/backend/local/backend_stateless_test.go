@@ -0,0 +1,249 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestExportOutputs(t *testing.T) {
+	dir := t.TempDir()
+	err := farseek.WriteOutputsFile(dir, map[string]*states.OutputValue{
+		"untouched": {Value: cty.StringVal("previous")},
+		"updated":   {Value: cty.StringVal("previous")},
+		"removed":   {Value: cty.StringVal("previous")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &configs.Config{
+		Module: &configs.Module{
+			Outputs: map[string]*configs.Output{
+				"untouched": {Name: "untouched"},
+				"updated":   {Name: "updated"},
+				"added":     {Name: "added"},
+			},
+		},
+	}
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetOutputValue(addrs.OutputValue{Name: "updated"}.Absolute(addrs.RootModuleInstance), cty.StringVal("new"), false, "")
+		s.SetOutputValue(addrs.OutputValue{Name: "added"}.Absolute(addrs.RootModuleInstance), cty.StringVal("new"), false, "")
+	})
+
+	if err := exportOutputs(dir, config, &plans.Plan{UIMode: plans.NormalMode}, state); err != nil {
+		t.Fatal(err)
+	}
+	got, err := farseek.ReadOutputsFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]cty.Value{
+		"untouched": cty.StringVal("previous"),
+		"updated":   cty.StringVal("new"),
+		"added":     cty.StringVal("new"),
+	}
+	if len(got) != len(want) {
+		t.Errorf("wrong outputs exported: %#v", got)
+	}
+	for name, v := range want {
+		if got[name] == nil || !got[name].Value.RawEquals(v) {
+			t.Errorf("wrong value for %s: %#v", name, got[name])
+		}
+	}
+
+	// Destroying the stack removes its exported outputs.
+	if err := exportOutputs(dir, config, &plans.Plan{UIMode: plans.DestroyMode}, states.NewState()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, farseek.OutputsFilename)); !os.IsNotExist(err) {
+		t.Errorf("outputs file still exists after destroy: %v", err)
+	}
+}
+
+func TestImportedIDs(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/stateless-imports", "tests")
+
+	got := importedIDs(config)
+	want := map[string]string{
+		// Only literal IDs can be used before planning.
+		"test_instance.literal": "i-123",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong IDs\n%s", diff)
+	}
+}
+
+func TestSetHistoricalProvider(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/stateless-providers", "tests")
+	provider := addrs.NewDefaultProvider("test")
+
+	tests := map[string]struct {
+		Addr, Resource string
+		WantProvider   addrs.AbsProviderConfig
+		WantKey        addrs.InstanceKey
+		WantErr        string
+	}{
+		"each.key": {
+			Addr:         `test_instance.regional["eu-west-1"]`,
+			Resource:     "test_instance.regional",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: provider, Alias: "by_region"},
+			WantKey:      addrs.StringKey("eu-west-1"),
+		},
+		"literal key": {
+			Addr:         "test_instance.pinned",
+			Resource:     "test_instance.pinned",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: provider, Alias: "by_region"},
+			WantKey:      addrs.StringKey("eu-west-1"),
+		},
+		"alias": {
+			Addr:         "test_instance.aliased",
+			Resource:     "test_instance.aliased",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: provider, Alias: "west"},
+			WantKey:      addrs.NoKey,
+		},
+		"default": {
+			Addr:         "test_instance.default",
+			Resource:     "test_instance.default",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: provider},
+			WantKey:      addrs.NoKey,
+		},
+		"unresolvable": {
+			// Without an instance key for each.key, the provider instance
+			// is unknown, and guessing could destroy the object with the
+			// wrong one.
+			Addr:         "test_instance.regional",
+			Resource:     "test_instance.regional",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: provider},
+			WantKey:      addrs.NoKey,
+			WantErr:      "Farseek can't tell which instance of test.by_region to destroy test_instance.regional with",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr := mustResourceInstanceAddr(test.Addr)
+			state := states.BuildState(func(s *states.SyncState) {
+				s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+					Status:    states.ObjectReady,
+					AttrsJSON: []byte(`{}`),
+				}, addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: provider}, addrs.NoKey)
+			})
+
+			diags := setHistoricalProvider(state, addr, config.Module.ManagedResources[test.Resource])
+			switch {
+			case test.WantErr == "" && diags.HasErrors():
+				t.Fatalf("unexpected error: %s", diags.Err())
+			case test.WantErr != "" && (!diags.HasErrors() || !strings.Contains(diags.Err().Error(), test.WantErr)):
+				t.Fatalf("wrong diagnostics %v; want error containing %q", diags.Err(), test.WantErr)
+			}
+
+			if got := state.Resource(addr.ContainingResource()).ProviderConfig; got.String() != test.WantProvider.String() {
+				t.Errorf("wrong provider %s; want %s", got, test.WantProvider)
+			}
+			if got := state.ResourceInstance(addr).ProviderKey; got != test.WantKey {
+				t.Errorf("wrong provider key %#v; want %#v", got, test.WantKey)
+			}
+		})
+	}
+}
+
+func TestDiscoveredProviders(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/stateless-providers-namespaced", "tests")
+	historical, _ := initwd.MustLoadConfigForTests(t, "./testdata/stateless-providers", "tests")
+	oldDiscovery := farseek.Discovery
+	t.Cleanup(func() { farseek.Discovery = oldDiscovery })
+	farseek.Discovery = historicalDiscoverer{resources: historical.Module.ManagedResources}
+
+	acme := addrs.NewProvider(addrs.DefaultProviderRegistryHost, "acme", "test")
+	child := addrs.RootModule.Child("child")
+	tests := map[string]struct {
+		Addr         string
+		WantProvider addrs.AbsProviderConfig
+		WantKey      addrs.InstanceKey
+		WantSkipped  string
+	}{
+		"configured alias": {
+			Addr:         "test_instance.aliased",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: acme, Alias: "west"},
+			WantKey:      addrs.NoKey,
+		},
+		"configured instance": {
+			Addr:         `test_instance.regional["us-east-1"]`,
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: acme, Alias: "by_region"},
+			WantKey:      addrs.StringKey("us-east-1"),
+		},
+		"configured unresolvable": {
+			// Rather than refresh the object through the default
+			// configuration, where it would look absent, it's left out.
+			Addr:        "test_instance.regional",
+			WantSkipped: "Farseek can't tell which instance of test.by_region manages test_instance.regional",
+		},
+		"configured in module": {
+			Addr:         "module.child.test_instance.foo",
+			WantProvider: addrs.AbsProviderConfig{Module: child, Provider: acme},
+			WantKey:      addrs.NoKey,
+		},
+		"removed from module": {
+			Addr:         "module.child.test_instance.gone",
+			WantProvider: addrs.AbsProviderConfig{Module: child, Provider: acme},
+			WantKey:      addrs.NoKey,
+		},
+		"removed from root": {
+			Addr:         "test_instance.pinned",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: addrs.NewDefaultProvider("test"), Alias: "by_region"},
+			WantKey:      addrs.StringKey("eu-west-1"),
+		},
+		"unknown in root": {
+			Addr:         "test_instance.unknown",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule, Provider: acme},
+			WantKey:      addrs.NoKey,
+		},
+		"removed module": {
+			Addr:         "module.gone.test_instance.foo",
+			WantProvider: addrs.AbsProviderConfig{Module: addrs.RootModule.Child("gone"), Provider: addrs.NewDefaultProvider("test")},
+			WantKey:      addrs.NoKey,
+		},
+	}
+
+	providers := &discoveredProviders{op: &backend.Operation{FarseekBaseSHA: "base"}, config: config}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotProvider, gotKey, skipped := providers.For(mustResourceInstanceAddr(test.Addr))
+			if test.WantSkipped != "" {
+				if skipped == nil || !strings.Contains(skipped.Description().Detail, test.WantSkipped) {
+					t.Fatalf("wrong diagnostic %v; want warning containing %q", skipped, test.WantSkipped)
+				}
+				if skipped.Severity() != tfdiags.Warning {
+					t.Errorf("wrong severity %s; want warning", skipped.Severity())
+				}
+				return
+			}
+			if skipped != nil {
+				t.Fatalf("unexpected diagnostic: %s", skipped.Description().Detail)
+			}
+			if gotProvider.String() != test.WantProvider.String() {
+				t.Errorf("wrong provider %s; want %s", gotProvider, test.WantProvider)
+			}
+			if gotKey != test.WantKey {
+				t.Errorf("wrong provider key %#v; want %#v", gotKey, test.WantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// checkTagPolicy checks the changes of the plan against the tag policy of the
+// operation, if it has one. A plan that violates a rule with the "error"
+// severity is marked as errored, so that it can't be applied.
+func checkTagPolicy(ctx context.Context, op *backend.Operation, lr *backend.LocalRun, plan *plans.Plan) tfdiags.Diagnostics {
+	if op.TagPolicy == nil || plan == nil {
+		return nil
+	}
+	schemas, diags := lr.Core.Schemas(ctx, lr.Config, lr.InputState)
+	if diags.HasErrors() {
+		// The same errors are reported when rendering the plan.
+		return nil
+	}
+	diags = op.TagPolicy.Check(plan, schemas, lr.Config)
+	if diags.HasErrors() {
+		plan.Errored = true
+	}
+	return diags
+}
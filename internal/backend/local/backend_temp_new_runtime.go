@@ -11,8 +11,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync/atomic"
 
 	"github.com/apparentlymart/go-versions/versions"
@@ -81,6 +84,35 @@ func experimentalRuntimeEnabled() bool {
 	return optIn != ""
 }
 
+// useExperimentalRuntime decides whether the given operation runs with the
+// experimental runtime.
+//
+// The operation's own choice of runtime, from the -runtime option, takes
+// precedence over the environment variable checked by
+// [experimentalRuntimeEnabled], so that each command can opt in or out
+// separately. Asking for the experimental runtime in a build where it isn't
+// allowed is an error, rather than silently using the legacy runtime.
+func useExperimentalRuntime(op *backend.Operation) (bool, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if (op.Runtime == plans.ExperimentalRuntime || op.CompareRuntimes) && !experimentalRuntimeAllowed.Load() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Experimental runtime not available",
+			"The -runtime option can only select the experimental language runtime in an experiments-enabled build of Farseek.",
+		))
+		return false, diags
+	}
+
+	switch op.Runtime {
+	case plans.LegacyRuntime:
+		return false, diags
+	case plans.ExperimentalRuntime:
+		return true, diags
+	default:
+		return experimentalRuntimeEnabled(), diags
+	}
+}
+
 func (b *Local) opPlanWithExperimentalRuntime(stopCtx context.Context, cancelCtx context.Context, op *backend.Operation, runningOp *backend.RunningOperation) {
 	var diags tfdiags.Diagnostics
 	log.Println("[WARN] Using plan implementation from the experimental language runtime")
@@ -148,6 +180,68 @@ func (b *Local) opPlanWithExperimentalRuntime(stopCtx context.Context, cancelCtx
 		prevRoundState = states.NewState() // this is the first round, starting with an empty state
 	}
 
+	plan, moreDiags := b.planWithExperimentalRuntime(ctx, op, prevRoundState)
+	diags = diags.Append(moreDiags)
+	// We intentionally continue with errors here because we make a best effort
+	// to render a partial plan output even when we have errors, in case
+	// the partial plan is helpful for debugging.
+
+	// Even if there are errors we need to handle anything that may be
+	// contained within the plan, so only exit if there is no data at all.
+	if plan == nil {
+		runningOp.PlanEmpty = true
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
+	// Record whether this plan includes any side-effects that could be applied.
+	runningOp.PlanEmpty = !plan.CanApply()
+
+	wroteConfig := false
+	if path := op.PlanOutPath; path != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Saved plan files not supported",
+			"The experimental language runtime cannot yet support -out=PLANFILE.",
+		))
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
+	// TODO: Actually render the plan. But to do that we need provider schemas
+	// and our schema-loading code expects us to be holding an old-style
+	// *configs.Config, so we have some more work to do before we can do that.
+	// For now, we'll just show the internals of the plan object.
+	// (Note that we are expecting that [planning.PlanChanges] will return
+	// something other than [plans.Plan] before long, because in our new
+	// approach we want to save the execution graph as part of the plan and
+	// so our old model is not sufficient. This is just a placeholder for now.)
+	spew.Dump(plan)
+
+	// If we've accumulated any diagnostics along the way then we'll show them
+	// here just before we show the summary and next steps. This can potentially
+	// include errors, because we intentionally try to show a partial plan
+	// above even if Farseek Core encountered an error partway through
+	// creating it.
+	op.ReportResult(runningOp, diags)
+
+	if !runningOp.PlanEmpty {
+		if wroteConfig {
+			op.View.PlanNextStep(op.PlanOutPath, op.GenerateConfigOut)
+		} else {
+			op.View.PlanNextStep(op.PlanOutPath, "")
+		}
+	}
+}
+
+// planWithExperimentalRuntime plans the changes for the given operation with
+// the experimental runtime, starting from the given state.
+//
+// As with the legacy runtime, the result can be a partial plan even when the
+// diagnostics have errors.
+func (b *Local) planWithExperimentalRuntime(ctx context.Context, op *backend.Operation, prevRoundState *states.State) (*plans.Plan, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
 	plugins := plugins.NewRuntimePlugins(b.ContextOpts.Providers, b.ContextOpts.Provisioners)
 	evalCtx := &eval.EvalContext{
 		RootModuleDir:      op.ConfigDir,
@@ -184,74 +278,110 @@ func (b *Local) opPlanWithExperimentalRuntime(stopCtx context.Context, cancelCtx
 	rootModuleSource, err := addrs.ParseModuleSource(configDir)
 	if err != nil {
 		diags = diags.Append(fmt.Errorf("invalid root module source address: %w", err))
-		op.ReportResult(runningOp, diags)
-		return
+		return nil, diags
 	}
 	configCall := &eval.ConfigCall{
 		RootModuleSource: rootModuleSource,
 		// TODO: InputValues
 		AllowImpureFunctions: false,
 		EvalContext:          evalCtx,
+		Parallelism:          b.ContextOpts.Parallelism,
 	}
 	configInst, moreDiags := eval.NewConfigInstance(ctx, configCall)
 	diags = diags.Append(moreDiags)
 	if moreDiags.HasErrors() {
-		op.ReportResult(runningOp, diags)
-		return
+		return nil, diags
 	}
 
 	plan, moreDiags := planning.PlanChanges(ctx, prevRoundState, configInst, plugins)
 	diags = diags.Append(moreDiags)
-	// We intentionally continue with errors here because we make a best effort
-	// to render a partial plan output even when we have errors, in case
-	// the partial plan is helpful for debugging.
-
-	// Even if there are errors we need to handle anything that may be
-	// contained within the plan, so only exit if there is no data at all.
-	if plan == nil {
-		runningOp.PlanEmpty = true
-		op.ReportResult(runningOp, diags)
-		return
+	if plan != nil {
+		plan.Runtime = plans.ExperimentalRuntime
 	}
+	return plan, diags
+}
 
-	// Record whether this plan includes any side-effects that could be applied.
-	runningOp.PlanEmpty = !plan.CanApply()
+// compareExperimentalPlan plans the changes for the given operation with the
+// experimental runtime too, and returns a warning that reports how that plan
+// differs from the given plan made by the legacy runtime.
+//
+// The problems that the experimental runtime reports are returned only as
+// warnings, because the legacy runtime's plan is the one that's used.
+func (b *Local) compareExperimentalPlan(ctx context.Context, op *backend.Operation, legacyPlan *plans.Plan, prevRoundState *states.State) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
 
-	wroteConfig := false
-	if path := op.PlanOutPath; path != "" {
+	log.Println("[INFO] backend/local: comparing the plan with the experimental language runtime")
+	experimentalPlan, moreDiags := b.planWithExperimentalRuntime(ctx, op, prevRoundState)
+	diags = diags.Append(tfdiags.OverrideAll(moreDiags, tfdiags.Warning, nil))
+	if experimentalPlan == nil {
 		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Saved plan files not supported",
-			"The experimental language runtime cannot yet support -out=PLANFILE.",
+			tfdiags.Warning,
+			"Runtime comparison failed",
+			"The experimental language runtime didn't produce a plan to compare. Its problems are reported above.",
 		))
-		op.ReportResult(runningOp, diags)
-		return
+		return diags
 	}
 
-	// TODO: Actually render the plan. But to do that we need provider schemas
-	// and our schema-loading code expects us to be holding an old-style
-	// *configs.Config, so we have some more work to do before we can do that.
-	// For now, we'll just show the internals of the plan object.
-	// (Note that we are expecting that [planning.PlanChanges] will return
-	// something other than [plans.Plan] before long, because in our new
-	// approach we want to save the execution graph as part of the plan and
-	// so our old model is not sufficient. This is just a placeholder for now.)
-	spew.Dump(plan)
+	differences := diffRuntimePlans(legacyPlan, experimentalPlan)
+	if len(differences) == 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Runtimes planned the same changes",
+			"The experimental language runtime planned the same actions for every resource instance as the legacy runtime.",
+		))
+		return diags
+	}
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Runtimes planned different changes",
+		fmt.Sprintf(
+			"The experimental language runtime planned different actions than the legacy runtime, whose plan is shown:\n%s",
+			strings.Join(differences, "\n"),
+		),
+	))
+	return diags
+}
 
-	// If we've accumulated any diagnostics along the way then we'll show them
-	// here just before we show the summary and next steps. This can potentially
-	// include errors, because we intentionally try to show a partial plan
-	// above even if Farseek Core encountered an error partway through
-	// creating it.
-	op.ReportResult(runningOp, diags)
+// diffRuntimePlans describes the differences between the actions planned
+// for each resource instance in plans made by the legacy and experimental
+// runtimes, in order of the resource instance addresses.
+func diffRuntimePlans(legacyPlan, experimentalPlan *plans.Plan) []string {
+	actions := func(plan *plans.Plan) map[string]plans.Action {
+		ret := make(map[string]plans.Action)
+		if plan.Changes == nil {
+			return ret
+		}
+		for _, change := range plan.Changes.Resources {
+			key := change.Addr.String()
+			if change.DeposedKey != states.NotDeposed {
+				key += fmt.Sprintf(" (deposed object %s)", change.DeposedKey)
+			}
+			ret[key] = change.Action
+		}
+		return ret
+	}
+	legacy := actions(legacyPlan)
+	experimental := actions(experimentalPlan)
 
-	if !runningOp.PlanEmpty {
-		if wroteConfig {
-			op.View.PlanNextStep(op.PlanOutPath, op.GenerateConfigOut)
-		} else {
-			op.View.PlanNextStep(op.PlanOutPath, "")
+	var ret []string
+	for _, key := range slices.Sorted(maps.Keys(legacy)) {
+		want := legacy[key]
+		got, ok := experimental[key]
+		switch {
+		case !ok && want != plans.NoOp:
+			ret = append(ret, fmt.Sprintf("  - %s: legacy runtime planned %s, experimental runtime planned nothing", key, want))
+		case ok && got != want:
+			ret = append(ret, fmt.Sprintf("  - %s: legacy runtime planned %s, experimental runtime planned %s", key, want, got))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(experimental)) {
+		if got := experimental[key]; got != plans.NoOp {
+			if _, ok := legacy[key]; !ok {
+				ret = append(ret, fmt.Sprintf("  - %s: legacy runtime planned nothing, experimental runtime planned %s", key, got))
+			}
 		}
 	}
+	return ret
 }
 
 func (b *Local) opApplyWithExperimentalRuntime(stopCtx context.Context, cancelCtx context.Context, op *backend.Operation, runningOp *backend.RunningOperation) {
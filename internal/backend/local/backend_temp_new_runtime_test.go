@@ -0,0 +1,106 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+)
+
+func TestUseExperimentalRuntime(t *testing.T) {
+	t.Cleanup(func() { SetExperimentalRuntimeAllowed(false) })
+
+	tests := map[string]struct {
+		allowed bool
+		op      backend.Operation
+		want    bool
+		wantErr bool
+	}{
+		"default": {
+			allowed: true,
+			want:    false,
+		},
+		"legacy": {
+			allowed: true,
+			op:      backend.Operation{Runtime: plans.LegacyRuntime},
+			want:    false,
+		},
+		"experimental": {
+			allowed: true,
+			op:      backend.Operation{Runtime: plans.ExperimentalRuntime},
+			want:    true,
+		},
+		"experimental not allowed": {
+			op:      backend.Operation{Runtime: plans.ExperimentalRuntime},
+			wantErr: true,
+		},
+		"compare not allowed": {
+			op:      backend.Operation{Runtime: plans.LegacyRuntime, CompareRuntimes: true},
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TOFU_X_EXPERIMENTAL_RUNTIME", "")
+			SetExperimentalRuntimeAllowed(test.allowed)
+
+			got, diags := useExperimentalRuntime(&test.op)
+			if diags.HasErrors() != test.wantErr {
+				t.Fatalf("unexpected diagnostics: %s", diags.ErrWithWarnings())
+			}
+			if got != test.want {
+				t.Errorf("wrong result %t; want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffRuntimePlans(t *testing.T) {
+	change := func(name string, action plans.Action) *plans.ResourceInstanceChangeSrc {
+		addr := addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: name,
+		}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+		return &plans.ResourceInstanceChangeSrc{
+			Addr:        addr,
+			PrevRunAddr: addr,
+			ChangeSrc:   plans.ChangeSrc{Action: action},
+		}
+	}
+	legacy := &plans.Plan{Changes: &plans.Changes{
+		Resources: []*plans.ResourceInstanceChangeSrc{
+			change("same", plans.Create),
+			change("different", plans.Update),
+			change("legacy_only", plans.Delete),
+			change("unchanged", plans.NoOp),
+		},
+	}}
+	experimental := &plans.Plan{Changes: &plans.Changes{
+		Resources: []*plans.ResourceInstanceChangeSrc{
+			change("same", plans.Create),
+			change("different", plans.DeleteThenCreate),
+			change("experimental_only", plans.Create),
+		},
+	}}
+
+	got := diffRuntimePlans(legacy, experimental)
+	want := []string{
+		"  - test_instance.different: legacy runtime planned Update, experimental runtime planned DeleteThenCreate",
+		"  - test_instance.legacy_only: legacy runtime planned Delete, experimental runtime planned nothing",
+		"  - test_instance.experimental_only: legacy runtime planned nothing, experimental runtime planned Create",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	if got := diffRuntimePlans(legacy, legacy); len(got) != 0 {
+		t.Errorf("unexpected differences between identical plans: %q", got)
+	}
+}
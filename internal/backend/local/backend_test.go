@@ -21,6 +21,7 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/encryption"
 	"github.com/rafagsiqueira/farseek/internal/states/statefile"
 	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestLocal_impl(t *testing.T) {
@@ -104,6 +105,80 @@ func TestLocal_StatePaths(t *testing.T) {
 
 }
 
+func TestLocal_workspacePaths(t *testing.T) {
+	testTmpDir(t)
+	b := New(encryption.StateEncryptionDisabled())
+
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"path":          cty.NullVal(cty.String),
+		"workspace_dir": cty.NullVal(cty.String),
+		"workspaces": cty.MapVal(map[string]cty.Value{
+			"prod": cty.ObjectVal(map[string]cty.Value{
+				"path": cty.StringVal(filepath.Join("prod", "farseek.tfstate")),
+			}),
+		}),
+	})
+	obj, diags := b.PrepareConfig(obj)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if diags := b.Configure(t.Context(), obj); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	path, _, back := b.StatePaths("prod")
+	if want := filepath.Join("prod", "farseek.tfstate"); path != want {
+		t.Errorf("expected %q, got %q", want, path)
+	}
+	if want := filepath.Join("prod", "farseek.tfstate") + DefaultBackupExtension; back != want {
+		t.Errorf("expected %q, got %q", want, back)
+	}
+	path, _, _ = b.StatePaths("staging")
+	if want := filepath.Join(DefaultWorkspaceDir, "staging", DefaultStateFilename); path != want {
+		t.Errorf("expected %q, got %q", want, path)
+	}
+
+	if _, err := b.StateMgr(t.Context(), "staging"); err != nil {
+		t.Fatal(err)
+	}
+	states, err := b.Workspaces(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{backend.DefaultStateName, "prod", "staging"}; !reflect.DeepEqual(states, want) {
+		t.Errorf("expected %q, got %q", want, states)
+	}
+	if _, err := os.Stat(filepath.Join(DefaultWorkspaceDir, "prod")); !os.IsNotExist(err) {
+		t.Errorf("prod has a workspace directory")
+	}
+
+	if err := b.DeleteWorkspace(t.Context(), "prod", true); err == nil {
+		t.Error("deleted a workspace declared in the backend configuration")
+	}
+}
+
+func TestLocal_workspacePathsInvalid(t *testing.T) {
+	b := New(encryption.StateEncryptionDisabled())
+
+	for name, path := range map[string]string{
+		backend.DefaultStateName: "default.tfstate",
+		"prod":                   "",
+	} {
+		obj := cty.ObjectVal(map[string]cty.Value{
+			"path":          cty.NullVal(cty.String),
+			"workspace_dir": cty.NullVal(cty.String),
+			"workspaces": cty.MapVal(map[string]cty.Value{
+				name: cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal(path),
+				}),
+			}),
+		})
+		if _, diags := b.PrepareConfig(obj); !diags.HasErrors() {
+			t.Errorf("workspace %q with path %q is valid", name, path)
+		}
+	}
+}
+
 func TestLocal_addAndRemoveStates(t *testing.T) {
 	testTmpDir(t)
 	dflt := backend.DefaultStateName
@@ -0,0 +1,274 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+// RecoveryJournalFilename is the name of the recovery journal within the
+// working directory's data directory.
+const RecoveryJournalFilename = "recovery.jsonl"
+
+// Statuses recorded for each resource instance in a recovery journal.
+const (
+	RecoveryStatusStarted = "started"
+	RecoveryStatusApplied = "applied"
+	RecoveryStatusFailed  = "failed"
+)
+
+// RecoveryEntry is a single line of a recovery journal, describing one step
+// of applying a change to a resource instance.
+type RecoveryEntry struct {
+	Time    time.Time `json:"time"`
+	Address string    `json:"address"`
+	Action  string    `json:"action"`
+	Status  string    `json:"status"`
+
+	// ID is the "id" attribute of the object, if it has one: the new object
+	// once a create or update has been applied, or the prior object
+	// otherwise.
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// RecoveryHook is a hook that records the changes applied to each resource
+// instance in a recovery journal.
+//
+// In Farseek stateless mode there is no state to persist while an apply is
+// running, so StateHook is not used and an interrupted apply would otherwise
+// leave no record of the objects it already created or destroyed. The
+// journal is much cheaper to write than a state snapshot: entries are
+// buffered and appended to the file at most once per PersistInterval, and
+// whenever Farseek is asked to stop.
+type RecoveryHook struct {
+	farseek.NilHook
+	sync.Mutex
+
+	// Path is the location of the journal file, which is created if needed.
+	Path string
+
+	// PersistInterval is the minimum time between writes to the journal.
+	PersistInterval time.Duration
+
+	actions   map[string]plans.Action
+	ids       map[string]string
+	pending   []RecoveryEntry
+	lastFlush time.Time
+	preserve  bool
+}
+
+var _ farseek.Hook = (*RecoveryHook)(nil)
+
+// NewRecoveryHook returns a hook that writes a recovery journal to the given
+// path. If a journal from an earlier interrupted apply already exists there,
+// new entries are appended to it and Discard leaves it in place.
+func NewRecoveryHook(path string, interval time.Duration) *RecoveryHook {
+	_, err := os.Stat(path)
+	return &RecoveryHook{
+		Path:            path,
+		PersistInterval: interval,
+		preserve:        err == nil,
+	}
+}
+
+// HasPreviousJournal returns true if the journal already existed when the
+// hook was created, which means an earlier apply did not finish.
+func (h *RecoveryHook) HasPreviousJournal() bool {
+	return h.preserve
+}
+
+func (h *RecoveryHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (farseek.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	key := addr.String()
+	if h.actions == nil {
+		h.actions = make(map[string]plans.Action)
+		h.ids = make(map[string]string)
+	}
+	h.actions[key] = action
+	h.ids[key] = objectID(priorState)
+
+	h.record(RecoveryEntry{
+		Address: key,
+		Action:  action.String(),
+		Status:  RecoveryStatusStarted,
+		ID:      h.ids[key],
+	})
+	return farseek.HookActionContinue, nil
+}
+
+func (h *RecoveryHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, err error) (farseek.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	key := addr.String()
+	entry := RecoveryEntry{
+		Address: key,
+		Action:  h.actions[key].String(),
+		Status:  RecoveryStatusApplied,
+		ID:      h.ids[key],
+	}
+	if id := objectID(newState); id != "" {
+		entry.ID = id
+	}
+	if err != nil {
+		entry.Status = RecoveryStatusFailed
+		entry.Error = err.Error()
+	}
+	h.record(entry)
+	return farseek.HookActionContinue, nil
+}
+
+func (h *RecoveryHook) Stopping() {
+	h.Lock()
+	defer h.Unlock()
+
+	// Farseek might be killed shortly after being asked to stop, so we
+	// write out everything we have so far regardless of the interval.
+	if err := h.flush(); err != nil {
+		log.Printf("[ERROR] Failed to write recovery journal after interruption: %s", err)
+	}
+}
+
+// Flush writes any buffered entries to the journal.
+func (h *RecoveryHook) Flush() error {
+	h.Lock()
+	defer h.Unlock()
+	return h.flush()
+}
+
+// Discard drops any buffered entries and removes the journal, because the
+// apply completed and so there is nothing to recover. A journal left by an
+// earlier interrupted apply is kept, since completing a later apply doesn't
+// resolve it.
+func (h *RecoveryHook) Discard() error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.pending = nil
+	if h.preserve {
+		return nil
+	}
+	if err := os.Remove(h.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (h *RecoveryHook) record(entry RecoveryEntry) {
+	entry.Time = time.Now().UTC()
+	h.pending = append(h.pending, entry)
+
+	if h.lastFlush.IsZero() {
+		// The first entry starts the clock, as for StateHook.
+		h.lastFlush = time.Now()
+		return
+	}
+	if time.Since(h.lastFlush) >= h.PersistInterval {
+		if err := h.flush(); err != nil {
+			// The journal is a best effort thing, so failing to write it
+			// must not interrupt the apply.
+			log.Printf("[ERROR] Failed to write recovery journal: %s", err)
+		}
+	}
+}
+
+func (h *RecoveryHook) flush() error {
+	h.lastFlush = time.Now()
+	if len(h.pending) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, entry := range h.pending {
+		if err := enc.Encode(entry); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	h.pending = nil
+	return f.Close()
+}
+
+// ReadRecoveryJournal reads all of the entries in the recovery journal at
+// the given path, in the order they were written. If the journal does not
+// exist, the result is empty and the error is nil.
+func ReadRecoveryJournal(path string) ([]RecoveryEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []RecoveryEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var entry RecoveryEntry
+		if err := json.Unmarshal(sc.Bytes(), &entry); err != nil {
+			// A crash can truncate the last line, but anything else is
+			// not a journal we should be guessing about.
+			if !sc.Scan() {
+				log.Printf("[WARN] Ignoring truncated last line of recovery journal %s", path)
+				break
+			}
+			return nil, fmt.Errorf("invalid recovery journal entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, sc.Err()
+}
+
+// objectID returns the "id" attribute of the given object, if it has a
+// known string value.
+func objectID(v cty.Value) string {
+	v, _ = v.UnmarkDeep()
+	if v.IsNull() || !v.IsKnown() || !v.Type().IsObjectType() || !v.Type().HasAttribute("id") {
+		return ""
+	}
+	id := v.GetAttr("id")
+	if id.IsNull() || !id.IsKnown() || id.Type() != cty.String {
+		return ""
+	}
+	return id.AsString()
+}
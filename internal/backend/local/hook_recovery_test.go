@@ -0,0 +1,103 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestRecoveryHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".farseek", RecoveryJournalFilename)
+	hook := NewRecoveryHook(path, 4*time.Hour)
+
+	created := addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_instance", "created", addrs.NoKey)
+	failed := addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_instance", "failed", addrs.NoKey)
+
+	hook.PreApply(created, states.CurrentGen, plans.Create, cty.NullVal(cty.DynamicPseudoType), cty.DynamicVal)
+	hook.PostApply(created, states.CurrentGen, cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("abc")}), nil)
+	hook.PreApply(failed, states.CurrentGen, plans.Delete, cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("def")}), cty.NullVal(cty.DynamicPseudoType))
+
+	// Nothing is written until the interval has passed or we're stopping.
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("journal written too soon: %v", err)
+	}
+	hook.Stopping()
+	hook.PostApply(failed, states.CurrentGen, cty.NullVal(cty.DynamicPseudoType), errors.New("boom"))
+	if err := hook.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadRecoveryJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RecoveryEntry{
+		{Address: "test_instance.created", Action: "Create", Status: RecoveryStatusStarted},
+		{Address: "test_instance.created", Action: "Create", Status: RecoveryStatusApplied, ID: "abc"},
+		{Address: "test_instance.failed", Action: "Delete", Status: RecoveryStatusStarted, ID: "def"},
+		{Address: "test_instance.failed", Action: "Delete", Status: RecoveryStatusFailed, ID: "def", Error: "boom"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(RecoveryEntry{}, "Time")); diff != "" {
+		t.Fatalf("wrong journal entries:\n%s", diff)
+	}
+
+	if err := hook.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("journal not removed: %v", err)
+	}
+}
+
+func TestRecoveryHook_previousJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), RecoveryJournalFilename)
+	if err := os.WriteFile(path, []byte(`{"address":"test_instance.old","action":"Create","status":"started"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := NewRecoveryHook(path, 0)
+	if !hook.HasPreviousJournal() {
+		t.Fatal("previous journal not detected")
+	}
+	if err := hook.Discard(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The earlier journal must survive a later apply completing.
+	got, err := ReadRecoveryJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Address != "test_instance.old" {
+		t.Fatalf("wrong journal entries: %#v", got)
+	}
+}
+
+func TestReadRecoveryJournal_truncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), RecoveryJournalFilename)
+	src := `{"address":"test_instance.a","action":"Create","status":"applied","id":"a"}` + "\n" + `{"address":"test_ins`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadRecoveryJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "a" {
+		t.Fatalf("wrong journal entries: %#v", got)
+	}
+}
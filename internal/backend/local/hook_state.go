@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/states"
 	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
@@ -38,9 +39,25 @@ type StateHook struct {
 	// and PersistInterval is ignored if this is nil.
 	Schemas *farseek.Schemas
 
+	// FullSnapshotEvery is how many intermediate snapshots can be persisted
+	// as deltas in a row, when StateMgr implements statemgr.DeltaPersister,
+	// before persisting a full snapshot again. If it's zero then
+	// DefaultFullSnapshotEvery is used.
+	FullSnapshotEvery int
+
 	intermediatePersist IntermediateStatePersistInfo
+
+	// changed are the resource instances updated since the last intermediate
+	// snapshot, and needFullSnapshot is set when there was an update that
+	// wasn't for a known resource instance, so a delta can't capture it.
+	changed          []addrs.AbsResourceInstance
+	needFullSnapshot bool
+	deltas           int
 }
 
+// DefaultFullSnapshotEvery is the default for StateHook.FullSnapshotEvery.
+const DefaultFullSnapshotEvery = 20
+
 type IntermediateStatePersistInfo struct {
 	// RequestedPersistInterval is the persist interval requested by whatever
 	// instantiated the StateHook.
@@ -64,12 +81,29 @@ type IntermediateStatePersistInfo struct {
 	ForcePersist bool
 }
 
-var _ farseek.Hook = (*StateHook)(nil)
+var (
+	_ farseek.Hook                      = (*StateHook)(nil)
+	_ farseek.ResourceInstanceStateHook = (*StateHook)(nil)
+)
 
 func (h *StateHook) PostStateUpdate(mutate func(*states.SyncState)) (farseek.HookAction, error) {
 	h.Lock()
 	defer h.Unlock()
 
+	h.needFullSnapshot = true
+	return h.postStateUpdate(mutate)
+}
+
+func (h *StateHook) PostResourceInstanceStateUpdate(addr addrs.AbsResourceInstance, mutate func(*states.SyncState)) (farseek.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.changed = append(h.changed, addr)
+	return h.postStateUpdate(mutate)
+}
+
+func (h *StateHook) postStateUpdate(mutate func(*states.SyncState)) (farseek.HookAction, error) {
+
 	h.intermediatePersist.RequestedPersistInterval = h.PersistInterval
 
 	if h.intermediatePersist.LastPersist.IsZero() {
@@ -92,7 +126,7 @@ func (h *StateHook) PostStateUpdate(mutate func(*states.SyncState)) (farseek.Hoo
 
 		if mgrPersist, ok := h.StateMgr.(statemgr.Persister); ok && h.PersistInterval != 0 && h.Schemas != nil {
 			if h.shouldPersist() {
-				err := mgrPersist.PersistState(context.TODO(), h.Schemas)
+				err := h.persist(mgrPersist)
 				if err != nil {
 					return farseek.HookActionHalt, err
 				}
@@ -126,7 +160,7 @@ func (h *StateHook) Stopping() {
 		h.intermediatePersist.ForcePersist = true
 
 		if h.shouldPersist() {
-			err := mgrPersist.PersistState(context.TODO(), h.Schemas)
+			err := h.persist(mgrPersist)
 			if err != nil {
 				// This hook can't affect Farseek Core's ongoing behavior,
 				// but it's a best effort thing anyway, so we'll just emit a
@@ -140,6 +174,30 @@ func (h *StateHook) Stopping() {
 
 }
 
+// persist persists an intermediate snapshot, as a delta of the resource
+// instances changed since the last one if the state manager supports it.
+func (h *StateHook) persist(mgr statemgr.Persister) error {
+	fullSnapshotEvery := h.FullSnapshotEvery
+	if fullSnapshotEvery == 0 {
+		fullSnapshotEvery = DefaultFullSnapshotEvery
+	}
+
+	if mgrDelta, ok := mgr.(statemgr.DeltaPersister); ok && !h.needFullSnapshot && h.deltas < fullSnapshotEvery {
+		if err := mgrDelta.PersistDelta(context.TODO(), h.changed, h.Schemas); err != nil {
+			return err
+		}
+		h.deltas++
+	} else {
+		if err := mgr.PersistState(context.TODO(), h.Schemas); err != nil {
+			return err
+		}
+		h.deltas = 0
+		h.needFullSnapshot = false
+	}
+	h.changed = nil
+	return nil
+}
+
 func (h *StateHook) shouldPersist() bool {
 	if m, ok := h.StateMgr.(IntermediateStateConditionalPersister); ok {
 		return m.ShouldPersistIntermediateState(&h.intermediatePersist)
@@ -281,6 +281,47 @@ func TestStateHookCustomPersistRule(t *testing.T) {
 	}
 }
 
+func TestStateHookDeltas(t *testing.T) {
+	is := &testDeltaPersistentState{}
+	hook := &StateHook{
+		StateMgr:          is,
+		Schemas:           &farseek.Schemas{},
+		PersistInterval:   time.Nanosecond,
+		FullSnapshotEvery: 2,
+	}
+
+	a := addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_instance", "a", addrs.NoKey)
+	b := addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_instance", "b", addrs.NoKey)
+	for _, addr := range []addrs.AbsResourceInstance{a, b, a, b} {
+		if _, err := hook.PostResourceInstanceStateUpdate(addr, stateHookMutator); err != nil {
+			t.Fatalf("unexpected error from PostResourceInstanceStateUpdate: %s", err)
+		}
+	}
+	// An update that isn't for a known resource instance always needs a
+	// full snapshot.
+	if _, err := hook.PostStateUpdate(stateHookMutator); err != nil {
+		t.Fatalf("unexpected error from PostStateUpdate: %s", err)
+	}
+
+	gotLog := is.CallLog
+	wantLog := []string{
+		"MutateState",
+		"PersistDelta test_instance.a",
+		"MutateState",
+		"PersistDelta test_instance.b",
+		// FullSnapshotEvery deltas in a row are followed by a full snapshot.
+		"MutateState",
+		"PersistState",
+		"MutateState",
+		"PersistDelta test_instance.b",
+		"MutateState",
+		"PersistState",
+	}
+	if diff := cmp.Diff(wantLog, gotLog); diff != "" {
+		t.Fatalf("wrong call log\n%s", diff)
+	}
+}
+
 type testPersistentState struct {
 	CallLog []string
 
@@ -349,3 +390,22 @@ func (sm *testPersistentStateThatRefusesToPersist) ShouldPersistIntermediateStat
 	sm.CallLog = append(sm.CallLog, "ShouldPersistIntermediateState")
 	return info.ForcePersist
 }
+
+type testDeltaPersistentState struct {
+	testPersistentState
+}
+
+var _ statemgr.DeltaPersister = (*testDeltaPersistentState)(nil)
+
+func (sm *testDeltaPersistentState) PersistDelta(_ context.Context, changed []addrs.AbsResourceInstance, schemas *farseek.Schemas) error {
+	if schemas == nil {
+		return fmt.Errorf("no schemas")
+	}
+	call := "PersistDelta"
+	for _, addr := range changed {
+		call += " " + addr.String()
+	}
+	sm.CallLog = append(sm.CallLog, call)
+	sm.Persisted = sm.Written
+	return nil
+}
@@ -0,0 +1,19 @@
+resource "test_instance" "foo" {
+    ami = "bar"
+}
+
+resource "test_instance" "bar" {
+    ami = test_instance.foo.id
+}
+
+locals {
+    baz_ami = test_instance.bar.ami
+}
+
+resource "test_instance" "baz" {
+    ami = local.baz_ami
+}
+
+resource "test_instance" "other" {
+    ami = "other"
+}
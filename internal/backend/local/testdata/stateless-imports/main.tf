@@ -0,0 +1,18 @@
+variable "id" {
+  type    = string
+  default = "dynamic"
+}
+
+resource "test_instance" "literal" {}
+
+resource "test_instance" "dynamic" {}
+
+import {
+  to = test_instance.literal
+  id = "i-123"
+}
+
+import {
+  to = test_instance.dynamic
+  id = var.id
+}
@@ -0,0 +1,10 @@
+terraform {
+  required_providers {
+    test = {
+      source = "acme/test"
+    }
+  }
+}
+
+resource "test_instance" "foo" {
+}
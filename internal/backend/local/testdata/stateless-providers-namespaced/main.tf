@@ -0,0 +1,29 @@
+terraform {
+  required_providers {
+    test = {
+      source = "acme/test"
+    }
+  }
+}
+
+provider "test" {
+  alias = "west"
+}
+
+provider "test" {
+  alias    = "by_region"
+  for_each = toset(["us-east-1"])
+}
+
+resource "test_instance" "aliased" {
+  provider = test.west
+}
+
+resource "test_instance" "regional" {
+  for_each = toset(["us-east-1"])
+  provider = test.by_region[each.key]
+}
+
+module "child" {
+  source = "./child"
+}
@@ -0,0 +1,24 @@
+provider "test" {
+  alias    = "by_region"
+  for_each = toset(["us-east-1", "eu-west-1"])
+}
+
+provider "test" {
+  alias = "west"
+}
+
+resource "test_instance" "regional" {
+  for_each = toset(["us-east-1", "eu-west-1"])
+  provider = test.by_region[each.key]
+}
+
+resource "test_instance" "pinned" {
+  provider = test.by_region["eu-west-1"]
+}
+
+resource "test_instance" "aliased" {
+  provider = test.west
+}
+
+resource "test_instance" "default" {
+}
@@ -0,0 +1,78 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+)
+
+// DefaultWorkspaceMetadataFile is the name of the file, in the directory of
+// a non-default workspace, that stores the workspace's metadata.
+const DefaultWorkspaceMetadataFile = "metadata.json"
+
+var _ backend.WorkspaceMetadataStore = (*Local)(nil)
+
+// WorkspaceMetadata implements backend.WorkspaceMetadataStore.
+func (b *Local) WorkspaceMetadata(ctx context.Context, name string) (*backend.WorkspaceMetadata, error) {
+	// If we have a backend handling state, defer to that.
+	if b.Backend != nil {
+		if store, ok := b.Backend.(backend.WorkspaceMetadataStore); ok {
+			return store.WorkspaceMetadata(ctx, name)
+		}
+		return &backend.WorkspaceMetadata{}, nil
+	}
+
+	// The default workspace is never created explicitly, so it has no
+	// metadata.
+	if name == backend.DefaultStateName || name == "" {
+		return &backend.WorkspaceMetadata{}, nil
+	}
+
+	src, err := os.ReadFile(b.workspaceMetadataPath(name))
+	if os.IsNotExist(err) {
+		return &backend.WorkspaceMetadata{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta backend.WorkspaceMetadata
+	if err := json.Unmarshal(src, &meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata for workspace %q: %w", name, err)
+	}
+	return &meta, nil
+}
+
+// SetWorkspaceMetadata implements backend.WorkspaceMetadataStore.
+func (b *Local) SetWorkspaceMetadata(ctx context.Context, name string, meta *backend.WorkspaceMetadata) error {
+	// If we have a backend handling state, defer to that.
+	if b.Backend != nil {
+		if store, ok := b.Backend.(backend.WorkspaceMetadataStore); ok {
+			return store.SetWorkspaceMetadata(ctx, name, meta)
+		}
+		return backend.ErrWorkspaceMetadataNotSupported
+	}
+
+	if name == backend.DefaultStateName || name == "" {
+		return backend.ErrWorkspaceMetadataNotSupported
+	}
+
+	src, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := b.createState(name); err != nil {
+		return err
+	}
+	return os.WriteFile(b.workspaceMetadataPath(name), append(src, '\n'), 0644)
+}
+
+func (b *Local) workspaceMetadataPath(name string) string {
+	return filepath.Join(b.stateWorkspaceDir(), name, DefaultWorkspaceMetadataFile)
+}
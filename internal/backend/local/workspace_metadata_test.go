@@ -0,0 +1,61 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+)
+
+func TestLocal_workspaceMetadata(t *testing.T) {
+	testTmpDir(t)
+	b := New(encryption.StateEncryptionDisabled())
+
+	if _, err := b.StateMgr(t.Context(), "prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A workspace created without metadata has empty metadata.
+	got, err := b.WorkspaceMetadata(t.Context(), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&backend.WorkspaceMetadata{}, got); diff != "" {
+		t.Errorf("wrong metadata before setting it\n%s", diff)
+	}
+
+	want := &backend.WorkspaceMetadata{
+		Description: "Production",
+		Tags:        map[string]string{"env": "prod"},
+	}
+	if err := b.SetWorkspaceMetadata(t.Context(), "prod", want); err != nil {
+		t.Fatal(err)
+	}
+	got, err = b.WorkspaceMetadata(t.Context(), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong metadata\n%s", diff)
+	}
+
+	// The metadata file must not make the workspace list any different.
+	workspaces, err := b.Workspaces(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{backend.DefaultStateName, "prod"}, workspaces); diff != "" {
+		t.Errorf("wrong workspaces\n%s", diff)
+	}
+
+	err = b.SetWorkspaceMetadata(t.Context(), backend.DefaultStateName, want)
+	if !errors.Is(err, backend.ErrWorkspaceMetadataNotSupported) {
+		t.Errorf("wrong error setting metadata of the default workspace: %v", err)
+	}
+}
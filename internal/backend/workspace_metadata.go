@@ -0,0 +1,57 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrWorkspaceMetadataNotSupported is returned when a caller attempts to
+// store metadata for a workspace in a backend that can't store it.
+var ErrWorkspaceMetadataNotSupported = errors.New("workspace metadata not supported")
+
+// WorkspaceMetadata is descriptive information about a workspace, which
+// doesn't affect how Farseek uses it.
+type WorkspaceMetadata struct {
+	// Description is a human-readable description of what the workspace is
+	// for.
+	Description string `json:"description,omitempty"`
+
+	// Tags are arbitrary key/value pairs that classify the workspace, such
+	// as env=prod, and that can be used to filter the list of workspaces.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// MatchesTag returns true if the metadata has the given tag. The tag is
+// either "key=value", which matches only that value, or just "key", which
+// matches any value.
+func (m *WorkspaceMetadata) MatchesTag(tag string) bool {
+	if m == nil {
+		return false
+	}
+	key, value, hasValue := strings.Cut(tag, "=")
+	got, ok := m.Tags[key]
+	if !ok {
+		return false
+	}
+	return !hasValue || got == value
+}
+
+// WorkspaceMetadataStore is implemented by backends that can store metadata
+// alongside their workspaces.
+type WorkspaceMetadataStore interface {
+	// WorkspaceMetadata returns the metadata of the workspace with the given
+	// name. A workspace without metadata has empty metadata, rather than
+	// nil.
+	WorkspaceMetadata(ctx context.Context, workspace string) (*WorkspaceMetadata, error)
+
+	// SetWorkspaceMetadata replaces the metadata of the workspace with the
+	// given name, which must already exist.
+	//
+	// This returns ErrWorkspaceMetadataNotSupported if the backend can't
+	// store metadata for the given workspace.
+	SetWorkspaceMetadata(ctx context.Context, workspace string, meta *WorkspaceMetadata) error
+}
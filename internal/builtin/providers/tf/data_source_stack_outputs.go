@@ -0,0 +1,117 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func dataSourceStackOutputsGetSchema() providers.Schema {
+	return providers.Schema{
+		Block: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"path": {
+					Type: cty.String,
+					Description: "The directory of the other Farseek stack, " +
+						"whose outputs are read from the `" + farseek.OutputsFilename +
+						"` file that Farseek exports there after each apply.",
+					DescriptionKind: configschema.StringMarkdown,
+					Required:        true,
+				},
+				"defaults": {
+					Type: cty.DynamicPseudoType,
+					Description: "Default values for outputs, in case " +
+						"the stack hasn't exported a required output.",
+					DescriptionKind: configschema.StringMarkdown,
+					Optional:        true,
+				},
+				"outputs": {
+					Type: cty.DynamicPseudoType,
+					Description: "An object containing every root-level " +
+						"output exported by the stack.",
+					DescriptionKind: configschema.StringMarkdown,
+					Computed:        true,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceStackOutputsValidate(cfg cty.Value) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	defaultsTy := cfg.GetAttr("defaults").Type()
+	if defaultsTy != cty.DynamicPseudoType && !defaultsTy.IsObjectType() && !defaultsTy.IsMapType() {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Invalid default values",
+			"Defaults must be given in an object value.",
+			cty.GetAttrPath("defaults"),
+		), tfdiags.CodeInvalidStackOutputsInput))
+	}
+
+	return diags
+}
+
+func dataSourceStackOutputsRead(d cty.Value, path addrs.AbsResourceInstance) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	dir := d.GetAttr("path").AsString()
+	exported, err := farseek.ReadOutputsFile(dir)
+	if os.IsNotExist(err) {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Stack has no exported outputs",
+			fmt.Sprintf("There is no %s file in %s. Farseek exports a stack's outputs when it is applied, so apply that stack first.", farseek.OutputsFilename, dir),
+			cty.GetAttrPath("path"),
+		), tfdiags.CodeStackNotApplied))
+		return cty.NilVal, diags
+	}
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Failed to read stack outputs",
+			fmt.Sprintf("Error reading the outputs exported by %s: %s.", dir, err),
+			cty.GetAttrPath("path"),
+		), tfdiags.CodeStackOutputsUnreadable))
+		return cty.NilVal, diags
+	}
+
+	outputs := make(map[string]cty.Value)
+	defaultsVal := d.GetAttr("defaults")
+	if !defaultsVal.IsNull() {
+		it := defaultsVal.ElementIterator()
+		for it.Next() {
+			k, v := it.Element()
+			outputs[k.AsString()] = v
+		}
+	}
+	for k, os := range exported {
+		v := os.Value
+		if os.Deprecated != "" {
+			v = marks.Deprecated(v, marks.DeprecationCause{
+				By:      path.Resource,
+				Key:     k,
+				Message: os.Deprecated,
+			})
+		}
+		outputs[k] = v
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"path":     d.GetAttr("path"),
+		"defaults": defaultsVal,
+		"outputs":  cty.ObjectVal(outputs),
+	}), diags
+}
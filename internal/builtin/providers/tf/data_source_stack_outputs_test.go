@@ -0,0 +1,88 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+func TestStackOutputs(t *testing.T) {
+	if err := dataSourceStackOutputsGetSchema().Block.InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	tests := map[string]struct {
+		Config cty.Value
+		Want   cty.Value
+		Err    bool
+	}{
+		"basic": {
+			cty.ObjectVal(map[string]cty.Value{
+				"path":     cty.StringVal("./testdata/stack"),
+				"defaults": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"path":     cty.StringVal("./testdata/stack"),
+				"defaults": cty.NullVal(cty.DynamicPseudoType),
+				"outputs": cty.ObjectVal(map[string]cty.Value{
+					"vpc_id": cty.StringVal("vpc-123"),
+					"zones":  cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+				}),
+			}),
+			false,
+		},
+		"defaults": {
+			cty.ObjectVal(map[string]cty.Value{
+				"path": cty.StringVal("./testdata/stack"),
+				"defaults": cty.ObjectVal(map[string]cty.Value{
+					"vpc_id": cty.StringVal("default"),
+					"region": cty.StringVal("eu-west-1"),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"path": cty.StringVal("./testdata/stack"),
+				"defaults": cty.ObjectVal(map[string]cty.Value{
+					"vpc_id": cty.StringVal("default"),
+					"region": cty.StringVal("eu-west-1"),
+				}),
+				"outputs": cty.ObjectVal(map[string]cty.Value{
+					"region": cty.StringVal("eu-west-1"),
+					"vpc_id": cty.StringVal("vpc-123"),
+					"zones":  cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+				}),
+			}),
+			false,
+		},
+		"not applied": {
+			cty.ObjectVal(map[string]cty.Value{
+				"path":     cty.StringVal("./testdata/nonexistent"),
+				"defaults": cty.NullVal(cty.DynamicPseudoType),
+			}),
+			cty.NilVal,
+			true,
+		},
+	}
+
+	addr := addrs.Resource{Mode: addrs.DataResourceMode, Type: "terraform_stack_outputs", Name: "network"}.Absolute(addrs.RootModuleInstance).Instance(addrs.NoKey)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := dataSourceStackOutputsValidate(test.Config)
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+
+			got, diags := dataSourceStackOutputsRead(test.Config, addr)
+			if test.Err != diags.HasErrors() {
+				t.Fatalf("wrong errors: %s", diags.Err())
+			}
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\nconfig: %#v\ngot:    %#v\nwant:   %#v", test.Config, got, test.Want)
+			}
+		})
+	}
+}
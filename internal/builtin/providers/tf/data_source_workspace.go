@@ -0,0 +1,87 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// WorkspaceFunc returns the name and metadata of the currently-selected
+// workspace, for the terraform_workspace data source.
+type WorkspaceFunc func(ctx context.Context) (string, *backend.WorkspaceMetadata, error)
+
+func dataSourceWorkspaceGetSchema() providers.Schema {
+	return providers.Schema{
+		Block: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"name": {
+					Type:            cty.String,
+					Description:     "The name of the currently-selected workspace.",
+					DescriptionKind: configschema.StringMarkdown,
+					Computed:        true,
+				},
+				"description": {
+					Type:            cty.String,
+					Description:     "The description of the workspace, or an empty string if it has none.",
+					DescriptionKind: configschema.StringMarkdown,
+					Computed:        true,
+				},
+				"tags": {
+					Type:            cty.Map(cty.String),
+					Description:     "The tags of the workspace.",
+					DescriptionKind: configschema.StringMarkdown,
+					Computed:        true,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceWorkspaceRead(ctx context.Context, workspace WorkspaceFunc) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if workspace == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Workspace metadata unavailable",
+			"The current workspace isn't known in this context.",
+		))
+		return cty.NilVal, diags
+	}
+	name, meta, err := workspace(ctx)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read workspace metadata",
+			fmt.Sprintf("Error reading the metadata of the current workspace: %s.", err),
+		))
+		return cty.NilVal, diags
+	}
+	if meta == nil {
+		meta = &backend.WorkspaceMetadata{}
+	}
+
+	tags := cty.MapValEmpty(cty.String)
+	if len(meta.Tags) != 0 {
+		tagVals := make(map[string]cty.Value, len(meta.Tags))
+		for k, v := range meta.Tags {
+			tagVals[k] = cty.StringVal(v)
+		}
+		tags = cty.MapVal(tagVals)
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"name":        cty.StringVal(name),
+		"description": cty.StringVal(meta.Description),
+		"tags":        tags,
+	}), diags
+}
@@ -0,0 +1,78 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+)
+
+func TestWorkspace(t *testing.T) {
+	if err := dataSourceWorkspaceGetSchema().Block.InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	tests := map[string]struct {
+		Workspace WorkspaceFunc
+		Want      cty.Value
+		Err       bool
+	}{
+		"with metadata": {
+			func(context.Context) (string, *backend.WorkspaceMetadata, error) {
+				return "prod", &backend.WorkspaceMetadata{
+					Description: "Production",
+					Tags:        map[string]string{"env": "prod"},
+				}, nil
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"name":        cty.StringVal("prod"),
+				"description": cty.StringVal("Production"),
+				"tags": cty.MapVal(map[string]cty.Value{
+					"env": cty.StringVal("prod"),
+				}),
+			}),
+			false,
+		},
+		"without metadata": {
+			func(context.Context) (string, *backend.WorkspaceMetadata, error) {
+				return "default", nil, nil
+			},
+			cty.ObjectVal(map[string]cty.Value{
+				"name":        cty.StringVal("default"),
+				"description": cty.StringVal(""),
+				"tags":        cty.MapValEmpty(cty.String),
+			}),
+			false,
+		},
+		"error": {
+			func(context.Context) (string, *backend.WorkspaceMetadata, error) {
+				return "", nil, errors.New("oops")
+			},
+			cty.NilVal,
+			true,
+		},
+		"unavailable": {
+			nil,
+			cty.NilVal,
+			true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := dataSourceWorkspaceRead(t.Context(), test.Workspace)
+			if test.Err != diags.HasErrors() {
+				t.Fatalf("wrong errors: %s", diags.Err())
+			}
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -21,13 +21,22 @@ import (
 
 // Provider is an implementation of providers.Interface
 type Provider struct {
-	funcs map[string]providerFunc
+	funcs     map[string]providerFunc
+	workspace WorkspaceFunc
 }
 
 // NewProvider returns a new farseek provider
 func NewProvider() providers.Interface {
+	return NewProviderWithWorkspace(nil)
+}
+
+// NewProviderWithWorkspace returns a new farseek provider whose
+// terraform_workspace data source describes the workspace that the given
+// function returns.
+func NewProviderWithWorkspace(workspace WorkspaceFunc) providers.Interface {
 	return &Provider{
-		funcs: getProviderFuncs(),
+		funcs:     getProviderFuncs(),
+		workspace: workspace,
 	}
 }
 
@@ -43,7 +52,9 @@ func (p *Provider) getFunctionSpecs() map[string]providers.FunctionSpec {
 func (p *Provider) GetProviderSchema(_ context.Context) providers.GetProviderSchemaResponse {
 	return providers.GetProviderSchemaResponse{
 		DataSources: map[string]providers.Schema{
-			"terraform_remote_state": dataSourceRemoteStateGetSchema(),
+			"terraform_remote_state":  dataSourceRemoteStateGetSchema(),
+			"terraform_stack_outputs": dataSourceStackOutputsGetSchema(),
+			"terraform_workspace":     dataSourceWorkspaceGetSchema(),
 		},
 		ResourceTypes: map[string]providers.Schema{
 			"terraform_data": dataStoreResourceSchema(),
@@ -68,15 +79,18 @@ func (p *Provider) ValidateDataResourceConfig(_ context.Context, req providers.V
 	// errors in farseek validate as well as during farseek plan.
 	var res providers.ValidateDataResourceConfigResponse
 
-	// This should not happen
-	if req.TypeName != "terraform_remote_state" {
-		res.Diagnostics.Append(fmt.Errorf("Error: unsupported data source %s", req.TypeName))
-		return res
+	switch req.TypeName {
+	case "terraform_remote_state":
+		res.Diagnostics = dataSourceRemoteStateValidate(req.Config)
+	case "terraform_stack_outputs":
+		res.Diagnostics = dataSourceStackOutputsValidate(req.Config)
+	case "terraform_workspace":
+		// There are no arguments to validate.
+	default:
+		// This should not happen
+		res.Diagnostics = res.Diagnostics.Append(fmt.Errorf("Error: unsupported data source %s", req.TypeName))
 	}
 
-	diags := dataSourceRemoteStateValidate(req.Config)
-	res.Diagnostics = diags
-
 	return res
 }
 
@@ -102,6 +116,15 @@ func (p *Provider) ReadDataSourceEncrypted(ctx context.Context, req providers.Re
 	// call function
 	var res providers.ReadDataSourceResponse
 
+	if req.TypeName == "terraform_stack_outputs" {
+		res.State, res.Diagnostics = dataSourceStackOutputsRead(req.Config, path)
+		return res
+	}
+	if req.TypeName == "terraform_workspace" {
+		res.State, res.Diagnostics = dataSourceWorkspaceRead(ctx, p.workspace)
+		return res
+	}
+
 	// This should not happen
 	if req.TypeName != "terraform_remote_state" {
 		res.Diagnostics.Append(fmt.Errorf("Error: unsupported data source %s", req.TypeName))
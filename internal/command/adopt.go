@@ -0,0 +1,497 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/posener/complete"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/modsdir"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// adoptImportID is the placeholder ID of the import blocks that adopt
+// suggests, which the user must replace with the real IDs.
+const adoptImportID = "REPLACE-WITH-ID"
+
+// AdoptCommand is a cli.Command implementation that compares the state file
+// of a project managed with Terraform or OpenTofu against its configuration
+// at HEAD, and suggests what it takes for Farseek to manage the project.
+type AdoptCommand struct {
+	Meta
+}
+
+// adoptReport is the result of the adopt command, also the JSON it prints
+// with -json.
+type adoptReport struct {
+	FormatVersion string `json:"format_version"`
+	StatePath     string `json:"state_path"`
+	Head          string `json:"head"`
+
+	// Both, StateOnly and ConfigOnly are the addresses of the managed
+	// resources in both the state and the configuration, in the state
+	// only, and in the configuration only.
+	Both       []string `json:"both"`
+	StateOnly  []string `json:"state_only"`
+	ConfigOnly []string `json:"config_only"`
+
+	// Moved pairs the resources that are only in the state with those of
+	// the same type that are only in the configuration, when there is just
+	// one of each, which were probably renamed. Imports are the rest of the
+	// resources only in the configuration, and Removed the rest of those
+	// only in the state.
+	Moved   []adoptMove `json:"moved"`
+	Imports []string    `json:"imports"`
+	Removed []string    `json:"removed"`
+
+	// Suggestions is a configuration file with the moved, import and
+	// removed blocks that resolve the differences.
+	Suggestions string `json:"suggestions"`
+
+	// SHARecorded is whether the command recorded HEAD in .farseek_sha,
+	// and SHANote explains why not otherwise.
+	SHARecorded bool   `json:"sha_recorded"`
+	SHANote     string `json:"sha_note,omitempty"`
+}
+
+type adoptMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (c *AdoptCommand) Run(args []string) int {
+	var statePath, outPath string
+	var jsonOutput, writeSHA bool
+
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("adopt")
+	cmdFlags.StringVar(&statePath, "state", "", "path")
+	cmdFlags.StringVar(&outPath, "out", "", "path")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&writeSHA, "write-sha", true, "write-sha")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The adopt command expects no arguments.")
+		cmdFlags.Usage()
+		return 1
+	}
+	if statePath == "" {
+		c.Ui.Error("The adopt command requires the -state option, with the path of the state file to compare.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	src, err := os.ReadFile(statePath)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read state file",
+			fmt.Sprintf("Error reading %s: %s.", statePath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	file, err := readForeignState(src)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read state file",
+			fmt.Sprintf("Error reading %s: %s", statePath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	dir := c.discoveryDir()
+	head, err := farseek.Discovery.GetCurrentSHA(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Not a git repository",
+			fmt.Sprintf("Farseek compares the state with the configuration at the current commit, but failed to find the current commit of %s: %s.", dir, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	config, configDiags := c.adoptConfigAtSHA(ctx, dir, head)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	report := adoptCompare(file.State, config)
+	report.StatePath = statePath
+	report.Head = head
+
+	if outPath != "" && report.Suggestions != "" {
+		if _, err := os.Stat(outPath); err == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Suggestions file already exists",
+				fmt.Sprintf("%s already exists. Remove it, or choose another path with the -out option.", outPath),
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+		if err := os.WriteFile(outPath, []byte(report.Suggestions), 0644); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to write suggestions",
+				fmt.Sprintf("Error writing %s: %s.", outPath, err),
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+
+	if writeSHA {
+		recorded, err := farseek.ReadSHA(dir)
+		switch {
+		case err != nil:
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading SHA", err.Error()), tfdiags.CodeSHAReadFailed))
+			c.showDiagnostics(diags)
+			return 1
+		case recorded != "":
+			report.SHANote = fmt.Sprintf("%s already records commit %s as the last one applied, so it was left alone.", farseek.SHAFilename, shortSHA(recorded))
+		case len(report.StateOnly) != 0 || len(report.ConfigOnly) != 0:
+			report.SHANote = fmt.Sprintf("%s was not written, because Farseek would then take the configuration at %s to be applied while the state and the configuration differ. Apply the suggestions with the current workflow, and run adopt again.", farseek.SHAFilename, shortSHA(head))
+		default:
+			if err := farseek.WriteSHA(dir, head); err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to record the current commit",
+					fmt.Sprintf("Error writing %s: %s.", farseek.SHAFilename, err),
+				))
+				c.showDiagnostics(diags)
+				return 1
+			}
+			report.SHARecorded = true
+		}
+	}
+
+	c.showDiagnostics(diags)
+	if jsonOutput {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal the report: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+		return 0
+	}
+	c.Ui.Output(adoptHuman(report, outPath))
+	return 0
+}
+
+// adoptConfigAtSHA loads the configuration as it is at the given commit.
+//
+// The local child modules are loaded from the commit too. Other child
+// modules aren't in the repository, so they are loaded from where "farseek
+// init" installed them, and are left out of the configuration if it hasn't.
+func (c *AdoptCommand) adoptConfigAtSHA(ctx context.Context, dir, sha string) (*configs.Config, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	configDir, err := os.MkdirTemp("", "farseek-adopt-")
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to create a directory for the configuration at %s: %w", sha, err))
+		return nil, diags
+	}
+	defer os.RemoveAll(configDir)
+	if err := farseek.Discovery.ExportConfigAtSHA(dir, sha, configDir); err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Farseek error reading the configuration",
+			fmt.Sprintf("Failed to read the configuration at %s: %s.", sha, err),
+		), tfdiags.CodeDiscoveryFailed))
+		return nil, diags
+	}
+
+	manifest, err := modsdir.ReadManifestSnapshotForDir(c.modulesDir())
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to read the installed modules: %w", err))
+		return nil, diags
+	}
+
+	call, callDiags := c.rootModuleCall(ctx, configDir)
+	diags = diags.Append(callDiags)
+	if callDiags.HasErrors() {
+		return nil, diags
+	}
+
+	parser := configs.NewParser(nil)
+	mod, hclDiags := parser.LoadConfigDir(configDir, call)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	walker := configs.ModuleWalkerFunc(func(_ context.Context, req *configs.ModuleRequest) (*configs.Module, *version.Version, hcl.Diagnostics) {
+		var modDir string
+		var modVersion *version.Version
+		if local, ok := req.SourceAddr.(addrs.ModuleSourceLocal); ok {
+			modDir = filepath.Join(req.Parent.Module.SourceDir, filepath.FromSlash(local.String()))
+		} else if record, ok := manifest[manifest.ModuleKey(req.Path)]; ok {
+			modDir, modVersion = record.Dir, record.Version
+		}
+		if modDir == "" {
+			return nil, nil, nil
+		}
+		if _, err := os.Stat(modDir); err != nil {
+			// Such as a local module outside of the repository.
+			return nil, nil, nil
+		}
+		mod, diags := parser.LoadConfigDir(modDir, req.Call)
+		return mod, modVersion, diags
+	})
+	config, hclDiags := configs.BuildConfig(ctx, mod, walker)
+	diags = diags.Append(hclDiags)
+	return config, diags
+}
+
+// adoptCompare compares the managed resources in the given state with those
+// of the given configuration. The resources of child modules that aren't in
+// the configuration, because they weren't loaded, are taken to be in it if
+// the module call that they belong to is.
+func adoptCompare(state *states.State, config *configs.Config) *adoptReport {
+	report := &adoptReport{FormatVersion: "1.0"}
+
+	inState := make(map[string]bool)
+	types := make(map[string]string)
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			addr := rs.Addr.Config().String()
+			if inState[addr] {
+				// Another instance of the same module call.
+				continue
+			}
+			inState[addr] = true
+			types[addr] = rs.Addr.Resource.Type
+
+			if adoptConfigured(config, rs.Addr.Config()) {
+				report.Both = append(report.Both, addr)
+			} else {
+				report.StateOnly = append(report.StateOnly, addr)
+			}
+		}
+	}
+	for _, mc := range config.AllModules() {
+		for _, rc := range mc.Module.ManagedResources {
+			addr := rc.Addr().InModule(mc.Path).String()
+			if !inState[addr] {
+				report.ConfigOnly = append(report.ConfigOnly, addr)
+				types[addr] = rc.Type
+			}
+		}
+	}
+	slices.Sort(report.Both)
+	slices.Sort(report.StateOnly)
+	slices.Sort(report.ConfigOnly)
+
+	// A resource only in the state and one of the same type only in the
+	// configuration were probably renamed, when there is just one of each.
+	stateOnlyByType := adoptByType(report.StateOnly, types)
+	configOnlyByType := adoptByType(report.ConfigOnly, types)
+	moved := make(map[string]bool)
+	for ty, from := range stateOnlyByType {
+		to := configOnlyByType[ty]
+		if len(from) == 1 && len(to) == 1 {
+			report.Moved = append(report.Moved, adoptMove{From: from[0], To: to[0]})
+			moved[from[0]] = true
+			moved[to[0]] = true
+		}
+	}
+	slices.SortFunc(report.Moved, func(a, b adoptMove) int {
+		return strings.Compare(a.From, b.From)
+	})
+	for _, addr := range report.ConfigOnly {
+		if !moved[addr] {
+			report.Imports = append(report.Imports, addr)
+		}
+	}
+	for _, addr := range report.StateOnly {
+		if !moved[addr] {
+			report.Removed = append(report.Removed, addr)
+		}
+	}
+
+	report.Suggestions = adoptSuggestions(report)
+	return report
+}
+
+// adoptConfigured returns whether the given resource is in the
+// configuration, or belongs to a module call whose module wasn't loaded.
+func adoptConfigured(config *configs.Config, addr addrs.ConfigResource) bool {
+	mc := config
+	for _, step := range addr.Module {
+		child, ok := mc.Children[step]
+		if !ok {
+			_, called := mc.Module.ModuleCalls[step]
+			return called
+		}
+		mc = child
+	}
+	_, ok := mc.Module.ManagedResources[addr.Resource.String()]
+	return ok
+}
+
+// adoptByType groups the given resource addresses by their types.
+func adoptByType(addrs []string, types map[string]string) map[string][]string {
+	ret := make(map[string][]string)
+	for _, addr := range addrs {
+		ret[types[addr]] = append(ret[types[addr]], addr)
+	}
+	return ret
+}
+
+// adoptSuggestions returns a configuration file with the moved, import and
+// removed blocks of the given report, or an empty string if there are none.
+func adoptSuggestions(report *adoptReport) string {
+	if len(report.Moved) == 0 && len(report.Imports) == 0 && len(report.Removed) == 0 {
+		return ""
+	}
+
+	traversal := func(addr string) hcl.Traversal {
+		ret, diags := hclsyntax.ParseTraversalAbs([]byte(addr), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			// Should never happen, since the addresses came from a valid
+			// state or configuration.
+			panic(fmt.Sprintf("invalid resource address %s: %s", addr, diags.Error()))
+		}
+		return ret
+	}
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{
+			Type:  hclsyntax.TokenComment,
+			Bytes: []byte("# This file was generated by \"farseek adopt\". Review each block before\n# applying it: the moved blocks pair resources by type, and the import\n# blocks need the real IDs of the resources, if they already exist.\n"),
+		},
+	})
+	for _, move := range report.Moved {
+		body.AppendNewline()
+		b := body.AppendNewBlock("moved", nil).Body()
+		b.SetAttributeTraversal("from", traversal(move.From))
+		b.SetAttributeTraversal("to", traversal(move.To))
+	}
+	for _, addr := range report.Imports {
+		body.AppendNewline()
+		b := body.AppendNewBlock("import", nil).Body()
+		b.SetAttributeTraversal("to", traversal(addr))
+		b.SetAttributeValue("id", cty.StringVal(adoptImportID))
+	}
+	for _, addr := range report.Removed {
+		body.AppendNewline()
+		b := body.AppendNewBlock("removed", nil).Body()
+		b.SetAttributeTraversal("from", traversal(addr))
+		b.AppendNewBlock("lifecycle", nil).Body().SetAttributeValue("destroy", cty.False)
+	}
+	return string(hclwrite.Format(f.Bytes()))
+}
+
+// adoptHuman renders the given report for people to read.
+func adoptHuman(report *adoptReport, outPath string) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Compared the managed resources in %s with the configuration at commit %s.\n", report.StatePath, shortSHA(report.Head))
+
+	section := func(title string, addrs []string) {
+		fmt.Fprintf(&buf, "\n%s (%d):\n", title, len(addrs))
+		for _, addr := range addrs {
+			fmt.Fprintf(&buf, "  %s\n", addr)
+		}
+	}
+	section("In both", report.Both)
+	section("Only in the state", report.StateOnly)
+	section("Only in the configuration", report.ConfigOnly)
+
+	switch {
+	case report.Suggestions == "":
+		buf.WriteString("\nThe state and the configuration match.\n")
+	case outPath != "":
+		fmt.Fprintf(&buf, "\nWrote the suggested moved, import and removed blocks to %s.\n", outPath)
+	default:
+		fmt.Fprintf(&buf, "\nSuggested moved, import and removed blocks:\n\n%s", report.Suggestions)
+	}
+
+	switch {
+	case report.SHARecorded:
+		fmt.Fprintf(&buf, "\nRecorded commit %s as the last one applied, in %s.\n", shortSHA(report.Head), farseek.SHAFilename)
+	case report.SHANote != "":
+		fmt.Fprintf(&buf, "\n%s\n", report.SHANote)
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func (c *AdoptCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *AdoptCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-state":     complete.PredictFiles("*.tfstate"),
+		"-out":       complete.PredictFiles("*.tf"),
+		"-json":      complete.PredictNothing,
+		"-write-sha": completePredictBoolean,
+	}
+}
+
+func (c *AdoptCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] adopt -state=PATH [options]
+
+  Compares the state file of a project managed with Terraform or OpenTofu
+  against the configuration at the current commit, to help adopt Farseek.
+
+  The managed resources are reported as in both, only in the state, or only
+  in the configuration. For the differences, Farseek suggests moved blocks
+  for resources that seem renamed, import blocks for the resources only in
+  the configuration, and removed blocks for those only in the state.
+
+  If the state and the configuration match, the current commit is recorded
+  as the last one applied, in .farseek_sha, unless it already records one.
+
+Options:
+
+  -state=PATH         The state file to compare. Required.
+
+  -out=PATH           Write the suggested blocks to this configuration file,
+                      instead of showing them. It must not exist yet.
+
+  -json               Show the report as JSON.
+
+  -write-sha=false    Don't record the current commit in .farseek_sha.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AdoptCommand) Synopsis() string {
+	return "Compare a Terraform or OpenTofu state file with the configuration"
+}
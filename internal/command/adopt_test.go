@@ -0,0 +1,140 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+)
+
+func TestAdopt(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("adopt"), td)
+	t.Chdir(td)
+
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = mockDiscoverer{}
+
+	ui := new(cli.MockUi)
+	c := &AdoptCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	args := []string{"-json", "-state", "terraform.tfstate", "-out", "adopt.tf"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got adoptReport
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, ui.OutputWriter.String())
+	}
+	want := adoptReport{
+		FormatVersion: "1.0",
+		StatePath:     "terraform.tfstate",
+		Head:          "mock-sha",
+		Both:          []string{"aws_instance.web", "module.network.aws_vpc.main"},
+		StateOnly:     []string{"aws_s3_bucket.log_bucket", "aws_sqs_queue.jobs", "module.network.aws_nat_gateway.main"},
+		ConfigOnly:    []string{"aws_iam_role.deploy", "aws_s3_bucket.logs", "module.network.aws_subnet.private"},
+		Moved:         []adoptMove{{From: "aws_s3_bucket.log_bucket", To: "aws_s3_bucket.logs"}},
+		Imports:       []string{"aws_iam_role.deploy", "module.network.aws_subnet.private"},
+		Removed:       []string{"aws_sqs_queue.jobs", "module.network.aws_nat_gateway.main"},
+		Suggestions: `# This file was generated by "farseek adopt". Review each block before
+# applying it: the moved blocks pair resources by type, and the import
+# blocks need the real IDs of the resources, if they already exist.
+
+moved {
+  from = aws_s3_bucket.log_bucket
+  to   = aws_s3_bucket.logs
+}
+
+import {
+  to = aws_iam_role.deploy
+  id = "REPLACE-WITH-ID"
+}
+
+import {
+  to = module.network.aws_subnet.private
+  id = "REPLACE-WITH-ID"
+}
+
+removed {
+  from = aws_sqs_queue.jobs
+  lifecycle {
+    destroy = false
+  }
+}
+
+removed {
+  from = module.network.aws_nat_gateway.main
+  lifecycle {
+    destroy = false
+  }
+}
+`,
+		SHANote: ".farseek_sha was not written, because Farseek would then take the configuration at mock-sh to be applied while the state and the configuration differ. Apply the suggestions with the current workflow, and run adopt again.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong report\n%s", diff)
+	}
+
+	suggestions, err := os.ReadFile(filepath.Join(td, "adopt.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(suggestions) != want.Suggestions {
+		t.Errorf("wrong suggestions file\n%s", suggestions)
+	}
+	if sha, err := farseek.ReadSHA(td); err != nil || sha != "" {
+		t.Errorf("recorded SHA %q (%v) while the state and the configuration differ", sha, err)
+	}
+}
+
+func TestAdopt_matching(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("adopt-matching"), td)
+	t.Chdir(td)
+
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = mockDiscoverer{}
+
+	ui := new(cli.MockUi)
+	c := &AdoptCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"-state", "terraform.tfstate"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"In both (1):\n  aws_instance.web\n",
+		"The state and the configuration match.",
+		"Recorded commit mock-sh as the last one applied, in .farseek_sha.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, output)
+		}
+	}
+	sha, err := farseek.ReadSHA(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != "mock-sha" {
+		t.Errorf("wrong recorded SHA %q", sha)
+	}
+}
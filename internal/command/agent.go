@@ -0,0 +1,128 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/backend/agent"
+)
+
+// AgentCommand is a Command implementation that runs plan and apply
+// operations on behalf of remote clients using the "-agent" option.
+type AgentCommand struct {
+	Meta
+}
+
+func (c *AgentCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var listen string
+	cmdFlags := c.Meta.defaultFlagSet("agent")
+	cmdFlags.StringVar(&listen, "listen", "127.0.0.1:8700", "listen")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The agent command expects no positional arguments.\n")
+		return cli.RunResultHelp
+	}
+
+	token := os.Getenv(agent.TokenEnvName)
+	if token == "" && !isLoopbackAddr(listen) {
+		c.Ui.Error(fmt.Sprintf(
+			"Refusing to listen on %s without authentication. Set %s to a shared secret, or listen only on a loopback address.",
+			listen, agent.TokenEnvName,
+		))
+		return 1
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to find the Farseek executable: %s", err))
+		return 1
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to listen on %s: %s", listen, err))
+		return 1
+	}
+
+	srv := &http.Server{
+		Handler: &agent.Server{
+			Run:   agent.ExecRunner(executable),
+			Token: token,
+		},
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	c.Ui.Output(fmt.Sprintf("Farseek agent listening on %s", ln.Addr()))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		c.Ui.Error(fmt.Sprintf("Agent stopped: %s", err))
+		return 1
+	case <-c.ShutdownCh:
+		c.Ui.Output("Interrupt received. Waiting for the running operation, if any, to finish...")
+		if err := srv.Shutdown(context.Background()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.Ui.Error(fmt.Sprintf("Failed to stop the agent: %s", err))
+			return 1
+		}
+		return 0
+	}
+}
+
+// isLoopbackAddr returns true if the given listen address can only be
+// reached from the local host.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (c *AgentCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] agent [options]
+
+  Runs an agent that executes plan and apply operations sent by other
+  Farseek processes using the -agent option, so that those operations run
+  with the credentials and network access of this host.
+
+  Each operation runs in a temporary copy of the client's working directory,
+  one at a time. Requests must present the secret from the
+  FARSEEK_AGENT_TOKEN environment variable, which is required unless the
+  agent only listens on a loopback address.
+
+Options:
+
+  -listen=addr    The address to listen on. Defaults to "127.0.0.1:8700".
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AgentCommand) Synopsis() string {
+	return "Run plan and apply operations for remote clients"
+}
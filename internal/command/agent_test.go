@@ -0,0 +1,232 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rafagsiqueira/farseek/internal/backend/agent"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+)
+
+// testAgent starts an agent whose runner pretends to run each command,
+// recording the arguments it was given.
+func testAgent(t *testing.T) (url string, calls *[][]string) {
+	t.Helper()
+
+	var got [][]string
+	srv := httptest.NewServer(&agent.Server{
+		Run: func(_ context.Context, dir string, args []string, stdout io.Writer) (int, error) {
+			got = append(got, args)
+			switch args[0] {
+			case "init":
+				fmt.Fprintln(stdout, "Farseek has been successfully initialized!")
+				if err := os.MkdirAll(filepath.Join(dir, DefaultDataDir, "providers"), 0755); err != nil {
+					return 1, err
+				}
+				if err := os.WriteFile(filepath.Join(dir, DefaultDataDir, "providers", "test"), []byte("provider"), 0600); err != nil {
+					return 1, err
+				}
+			case "plan":
+				fmt.Fprintln(stdout, `{"@level":"info","@message":"Farseek 1.0.0","type":"version"}`)
+				fmt.Fprintln(stdout, `{"@level":"info","@message":"Plan: 1 to add, 0 to change, 0 to destroy.","type":"change_summary","changes":{"add":1,"change":0,"import":0,"remove":0,"forget":0,"operation":"plan"}}`)
+				if err := os.WriteFile(filepath.Join(dir, "farseek-agent.tfplan"), []byte("remote plan"), 0600); err != nil {
+					return 1, err
+				}
+			case "apply":
+				fmt.Fprintln(stdout, `{"@level":"info","@message":"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.","type":"change_summary","changes":{"add":1,"change":0,"import":0,"remove":0,"forget":0,"operation":"apply"}}`)
+				if err := os.WriteFile(filepath.Join(dir, DefaultStateFilename), []byte("remote state"), 0600); err != nil {
+					return 1, err
+				}
+				if err := os.WriteFile(filepath.Join(dir, DefaultDataDir, "recovery.jsonl"), []byte("remote journal"), 0600); err != nil {
+					return 1, err
+				}
+			}
+			return 0, nil
+		},
+	})
+	t.Cleanup(srv.Close)
+	return srv.URL, &got
+}
+
+func TestPlan_agent(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	t.Chdir(td)
+
+	url, calls := testAgent(t)
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			View: view,
+		},
+	}
+
+	code := c.Run([]string{"-agent", url, "-out=saved.tfplan", "-parallelism=2"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.All())
+	}
+
+	want := [][]string{
+		{"init", "-input=false", "-no-color"},
+		{"plan", "-json", "-input=false", "-out=farseek-agent.tfplan", "-parallelism=2"},
+	}
+	if diff := cmp.Diff(want, *calls); diff != "" {
+		t.Errorf("wrong commands run on agent\n%s", diff)
+	}
+	if got := output.Stdout(); !strings.Contains(got, "Plan: 1 to add") || strings.Contains(got, "Farseek 1.0.0") {
+		t.Errorf("wrong output\n%s", got)
+	}
+
+	saved, err := os.ReadFile("saved.tfplan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(saved), "remote plan"; got != want {
+		t.Errorf("wrong saved plan %q; want %q", got, want)
+	}
+}
+
+func TestApply_agentApproval(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	t.Chdir(td)
+
+	defer testInputMap(t, map[string]string{
+		"approve": "yes",
+	})()
+
+	url, calls := testAgent(t)
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			View: view,
+		},
+	}
+
+	code := c.Run([]string{"-agent=" + url, "-var", "foo=bar"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.All())
+	}
+
+	want := [][]string{
+		{"init", "-input=false", "-no-color"},
+		{"plan", "-json", "-input=false", "-out=farseek-agent.tfplan", "-var", "foo=bar"},
+		{"init", "-input=false", "-no-color"},
+		{"apply", "-json", "-input=false", "farseek-agent.tfplan"},
+	}
+	if diff := cmp.Diff(want, *calls); diff != "" {
+		t.Errorf("wrong commands run on agent\n%s", diff)
+	}
+	if got := output.Stdout(); !strings.Contains(got, "Apply complete!") {
+		t.Errorf("wrong output\n%s", got)
+	}
+
+	// The files that the apply changed on the agent are written back here,
+	// but not those that init installed for the agent's own use.
+	for path, want := range map[string]string{
+		DefaultStateFilename:                            "remote state",
+		filepath.Join(DefaultDataDir, "recovery.jsonl"): "remote journal",
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("file from agent not written: %s", err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("wrong content %q in %s; want %q", got, path, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(DefaultDataDir, "providers")); !os.IsNotExist(err) {
+		t.Errorf("providers installed on the agent were written back: %v", err)
+	}
+	if _, err := os.Stat("farseek-agent.tfplan"); !os.IsNotExist(err) {
+		t.Errorf("agent's saved plan was written back: %v", err)
+	}
+}
+
+func TestPlan_agentGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	// The configuration is in a subdirectory of its repository, and the
+	// agent must still have the repository's metadata to discover changes.
+	repo := t.TempDir()
+	td := filepath.Join(repo, "infra")
+	testCopyDir(t, testFixturePath("plan"), td)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %s\n%s", args[0], err, out)
+		}
+	}
+	t.Chdir(td)
+
+	var heads []string
+	srv := httptest.NewServer(&agent.Server{
+		Run: func(_ context.Context, dir string, args []string, stdout io.Writer) (int, error) {
+			if args[0] == "plan" {
+				if filepath.Base(dir) != "infra" {
+					return 1, fmt.Errorf("operation runs in %s, not in the configuration's directory", dir)
+				}
+				head, err := farseek.Discovery.GetCurrentSHA(dir)
+				if err != nil {
+					return 1, fmt.Errorf("no git metadata on the agent: %w", err)
+				}
+				heads = append(heads, head)
+			}
+			return 0, nil
+		},
+	})
+	t.Cleanup(srv.Close)
+
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			View: view,
+		},
+	}
+	code := c.Run([]string{"-agent", srv.URL})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.All())
+	}
+
+	want, err := farseek.Discovery.GetCurrentSHA(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{want}, heads); diff != "" {
+		t.Errorf("wrong commit on the agent\n%s", diff)
+	}
+}
+
+func TestAgentArgs(t *testing.T) {
+	got := agentArgs([]string{
+		"-agent", "http://example.com", "-out=x", "-json", "-input=false",
+		"-var", "json=1", "-auto-approve", "-out", "y", "-target=a.b",
+	})
+	want := []string{"-var", "json=1", "-target=a.b"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
@@ -10,16 +10,24 @@ package command
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
+	"github.com/posener/complete"
+
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/backend/agent"
+	backendLocal "github.com/rafagsiqueira/farseek/internal/backend/local"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/views"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
@@ -32,9 +40,11 @@ type ApplyCommand struct {
 	// If true, then this apply command will become the "destroy"
 	// command. It is just like apply but only processes a destroy.
 	Destroy bool
+
+	input io.Reader // STDIN if nil
 }
 
-func (c *ApplyCommand) Run(rawArgs []string) int {
+func (c *ApplyCommand) Run(rawArgs []string) (code int) {
 	var diags tfdiags.Diagnostics
 	ctx := c.CommandContext()
 
@@ -58,11 +68,41 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	}
 
 	c.View.SetShowSensitive(args.ShowSensitive)
+	c.View.SetGroupBy(args.GroupBy)
+	c.View.SetQuiet(args.Quiet)
+
+	// Operations on an agent render the remote output with their own view.
+	if args.Agent != "" && !diags.HasErrors() {
+		return c.runAgent(ctx, args, rawArgs)
+	}
 
 	// Instantiate the view, even if there are flag errors, so that we render
 	// diagnostics according to the desired view
 	view := views.NewApply(args.ViewType, c.Destroy, c.View)
 
+	// With -input=strict, the prompts that were needed are reported together
+	// once the command is done, whichever way it ends.
+	defer func() { code = c.reportStrictInput(view, code) }()
+
+	// The destroy order is only known for a destroy planned from the
+	// configuration, so -check-order makes no sense otherwise.
+	if args.CheckOrder && (args.Operation.PlanMode != plans.DestroyMode || args.PlanPath != "") {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -check-order option",
+			"The -check-order option is only valid for \"farseek destroy\" and \"farseek apply -destroy\", without a saved plan file.",
+		), tfdiags.CodeInvalidCheckOrder))
+	}
+
+	// Batches split the changes of a destroy planned from the configuration.
+	if args.BatchSize > 0 && (args.Operation.PlanMode != plans.DestroyMode || args.PlanPath != "") {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -batch-size option",
+			"The -batch-size option is only valid for \"farseek destroy\" and \"farseek apply -destroy\", without a saved plan file.",
+		), tfdiags.CodeInvalidBatchSize))
+	}
+
 	if diags.HasErrors() {
 		view.Diagnostics(diags)
 		view.HelpPrompt()
@@ -98,7 +138,8 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	// FIXME: the -input flag value is needed to initialize the backend and the
 	// operation, but there is no clear path to pass this value down, so we
 	// continue to mutate the Meta object state for now.
-	c.Meta.input = args.InputEnabled
+	c.Meta.input = args.InputEnabled && args.PlanPath != stdinArg
+	c.Meta.inputStrict = args.InputStrict
 
 	// FIXME: the -parallelism flag is used to control the concurrency of
 	// Farseek operations. At the moment, this value is used both to
@@ -108,6 +149,12 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
 
+	// FIXME: the -operation-timeout flags are only meaningful to
+	// RunOperation, which is shared by several commands, so we also carry
+	// these on the Meta object for now.
+	c.Meta.operationTimeout = args.Operation.Timeout
+	c.Meta.operationTimeoutGrace = args.Operation.TimeoutGrace
+
 	// Prepare the backend, passing the plan file if present, and the
 	// backend-specific arguments
 	be, beDiags := c.PrepareBackend(ctx, planFile, args.State, args.ViewType, enc.State())
@@ -120,15 +167,51 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	// Build the operation request
 	opReq, opDiags := c.OperationRequest(ctx, be, view, args, planFile, enc)
 	diags = diags.Append(opDiags)
+	if opDiags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+
+	// The review pages through the plan at the prompt, which only works in
+	// a terminal.
+	if opReq.ReviewPlan && c.Streams != nil && !c.Streams.Stdin.IsTerminal() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Plan review is not available",
+			"The -review option needs an interactive terminal, so Farseek shows the whole plan instead.",
+		))
+		opReq.ReviewPlan = false
+	}
+
+	// A saved plan was checked against the tag policy and the plan limits
+	// when it was made.
+	if planFile == nil {
+		opReq.TagPolicy, opDiags = c.loadTagPolicy(args.TagPolicy)
+		diags = diags.Append(opDiags)
+		if diags.HasErrors() {
+			view.Diagnostics(diags)
+			return 1
+		}
+		opReq.PlanLimits = c.planLimits(args.Limits)
+		opReq.AllowExceedingLimits = args.Limits.AllowExceeding
+	}
+
+	// The external apply hooks from the CLI configuration must see each
+	// change before the other hooks do, since they can stop it.
+	applyHooks := c.externalApplyHooks()
+	if applyHooks != nil {
+		opReq.Hooks = append([]farseek.Hook{applyHooks}, opReq.Hooks...)
+	}
 
 	// Check if we are in a Farseek-managed project (Git repo or has .farseek_sha)
+	dir := c.discoveryDir()
 	isGit := false
-	if _, err := farseek.Discovery.GetCurrentSHA("."); err == nil {
+	if _, err := farseek.Discovery.GetCurrentSHA(dir); err == nil {
 		isGit = true
 	}
-	sha, err := farseek.ReadSHA(".")
+	sha, err := farseek.ReadSHA(dir)
 	if err != nil {
-		diags = diags.Append(fmt.Errorf("Farseek error reading SHA: %w", err))
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading SHA", err.Error()), tfdiags.CodeSHAReadFailed))
 		view.Diagnostics(diags)
 		return 1
 	}
@@ -145,21 +228,44 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	if isGit || hasSHA {
 		opReq.FarseekMode = true
 		opReq.FarseekBaseSHA = sha
+		opReq.RecoveryJournalPath = filepath.Join(c.DataDir(), backendLocal.RecoveryJournalFilename)
 
 		if sha != "" {
 			log.Printf("[INFO] Farseek: Using base SHA: %s", sha)
 		}
 
+		if len(c.ApplyBranches) > 0 && !args.AllowAnyBranch {
+			diags = diags.Append(c.checkBranch(dir))
+			if diags.HasErrors() {
+				view.Diagnostics(diags)
+				return 1
+			}
+		}
+
+		if args.RequireSignedCommits || c.RequireSignedCommits {
+			diags = diags.Append(c.verifyCommits(dir, sha, args.Uncommitted))
+			if diags.HasErrors() {
+				view.Diagnostics(diags)
+				return 1
+			}
+		}
+
 		var changed []farseek.DiscoveredResource
 		if c.Destroy {
 			log.Printf("[INFO] Farseek: Destroying all resources (uncommitted=%v)", args.Uncommitted)
-			changed, err = farseek.Discovery.DiscoverAllResources(".", args.Uncommitted)
+			changed, err = farseek.Discovery.DiscoverAllResources(dir, args.Uncommitted)
 		} else {
-			changed, err = farseek.Discovery.DiscoverChangedResources(".", sha, args.Uncommitted)
+			changed, err = farseek.Discovery.DiscoverChangedResources(dir, sha, args.Uncommitted)
 		}
 
 		if err != nil {
-			diags = diags.Append(fmt.Errorf("Farseek error discovering resources: %w", err))
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error discovering resources", err.Error()), tfdiags.CodeDiscoveryFailed))
+			view.Diagnostics(diags)
+			return 1
+		}
+		changed, ignoreDiags := c.withoutIgnored(changed)
+		diags = diags.Append(ignoreDiags)
+		if ignoreDiags.HasErrors() {
 			view.Diagnostics(diags)
 			return 1
 		}
@@ -190,9 +296,9 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 			fmt.Println("No changes. Your infrastructure matches the configuration.")
 
 			// Update .farseek_sha so the next run also sees no changes
-			headSHA, err := farseek.Discovery.GetCurrentSHA(".")
+			headSHA, err := farseek.Discovery.GetCurrentSHA(dir)
 			if err == nil && headSHA != "" {
-				if err := farseek.WriteSHA(".", headSHA); err != nil {
+				if err := farseek.WriteSHA(dir, headSHA); err != nil {
 					// log it but don't fail
 				}
 			}
@@ -214,6 +320,10 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	}
 	diags = nil
 
+	if args.CheckOrder {
+		return c.checkDestroyOrder(ctx, view, dir, opReq)
+	}
+
 	// Load the schema
 	// opReq.Schemas, diags = be.Schemas(ctx, opReq.ConfigLoader, opReq.ConfigDir)
 	// if diags.HasErrors() {
@@ -222,6 +332,7 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 
 	// Run the operation
 	op, diags := c.RunOperation(ctx, be, opReq)
+	diags = diags.Append(applyHooks.Diagnostics())
 	view.Diagnostics(diags)
 	if diags.HasErrors() {
 		return 1
@@ -231,21 +342,26 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 		return op.Result.ExitStatus()
 	}
 
-	// Update .farseek_sha if it exists or if we are in FarseekMode
-	if opReq.FarseekMode {
-		headSHA, err := farseek.Discovery.GetCurrentSHA(".")
+	// Update .farseek_sha if it exists or if we are in FarseekMode, unless
+	// part of a saved plan was left for later.
+	if len(args.Targets) > 0 {
+		log.Printf("[INFO] Farseek: Not updating .farseek_sha after a targeted apply")
+	} else if opReq.FarseekMode {
+		headSHA, err := farseek.Discovery.GetCurrentSHA(dir)
 		if err == nil && headSHA != "" {
 			log.Printf("[INFO] Farseek: Updating .farseek_sha to current HEAD: %s", headSHA)
-			if err := farseek.WriteSHA(".", headSHA); err != nil {
+			if err := farseek.WriteSHA(dir, headSHA); err != nil {
 				log.Printf("[WARN] Farseek: Failed to write .farseek_sha: %s", err)
 			}
 		} else {
 			log.Printf("[ERROR] Farseek: Failed to get current HEAD SHA: %s", err)
 		}
-	} else if _, err := os.Stat(".farseek_sha"); err == nil {
-		headSHA, err := farseek.Discovery.GetCurrentSHA(".")
+	} else if sha != "" {
+		// We were run outside of FarseekMode, but a SHA has been recorded
+		// before, so we keep it current.
+		headSHA, err := farseek.Discovery.GetCurrentSHA(dir)
 		if err == nil && headSHA != "" {
-			if err := farseek.WriteSHA(".", headSHA); err != nil {
+			if err := farseek.WriteSHA(dir, headSHA); err != nil {
 				// Just log to debug, don't fail the apply
 			}
 		}
@@ -268,6 +384,47 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	return 0
 }
 
+// checkDestroyOrder reports the order in which a destroy would remove the
+// resources selected for it, without destroying anything.
+func (c *ApplyCommand) checkDestroyOrder(ctx context.Context, view views.Apply, dir string, opReq *backend.Operation) int {
+	config, diags := c.loadConfig(ctx, dir)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+
+	// In Farseek mode the destroy is limited to the discovered resources,
+	// which are its targets. Otherwise everything in the configuration
+	// would be destroyed, and since the instances of its resources aren't
+	// known without a plan, each resource stands for all of its instances.
+	var resources []addrs.AbsResourceInstance
+	if opReq.FarseekMode {
+		for _, target := range opReq.Targets {
+			switch addr := target.(type) {
+			case addrs.AbsResource:
+				resources = append(resources, addr.Instance(addrs.NoKey))
+			case addrs.AbsResourceInstance:
+				resources = append(resources, addr)
+			}
+		}
+	} else {
+		for _, mc := range config.AllModules() {
+			for _, rc := range mc.Module.ManagedResources {
+				resources = append(resources, rc.Addr().Absolute(mc.Path.UnkeyedInstanceShim()).Instance(addrs.NoKey))
+			}
+		}
+	}
+
+	waves, conflicts, moreDiags := farseek.DestroyOrder(config, resources)
+	diags = diags.Append(moreDiags)
+	view.DestroyOrder(waves, conflicts)
+	view.Diagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	return 0
+}
+
 func (c *ApplyCommand) LoadPlanFile(path string, enc encryption.Encryption) (*planfile.WrappedPlanFile, tfdiags.Diagnostics) {
 	var planFile *planfile.WrappedPlanFile
 	var diags tfdiags.Diagnostics
@@ -275,13 +432,17 @@ func (c *ApplyCommand) LoadPlanFile(path string, enc encryption.Encryption) (*pl
 	// Try to load plan if path is specified
 	if path != "" {
 		var err error
-		planFile, err = c.PlanFile(path, enc.Plan())
+		if path == stdinArg {
+			planFile, err = planfile.OpenWrappedStream(c.stdin(), enc.Plan())
+		} else {
+			planFile, err = c.PlanFile(path, enc.Plan())
+		}
 		if err != nil {
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				fmt.Sprintf("Failed to load %q as a plan file", path),
 				fmt.Sprintf("Error: %s", err),
-			))
+			), tfdiags.CodePlanFileLoadFailed))
 			return nil, diags
 		}
 
@@ -289,22 +450,22 @@ func (c *ApplyCommand) LoadPlanFile(path string, enc encryption.Encryption) (*pl
 		// nil. In that case, the user is probably trying to use the positional
 		// argument to specify a configuration path. Point them at -chdir.
 		if planFile == nil {
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				fmt.Sprintf("Failed to load %q as a plan file", path),
 				"The specified path is a directory, not a plan file. You can use the global -chdir flag to use this directory as the configuration root.",
-			))
+			), tfdiags.CodePlanFileLoadFailed))
 			return nil, diags
 		}
 
 		// If we successfully loaded a plan but this is a destroy operation,
 		// explain that this is not supported.
 		if c.Destroy {
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Destroy can't be called with a plan file",
 				fmt.Sprintf("If this plan was created using plan -destroy, apply it using:\n  farseek apply %q", path),
-			))
+			), tfdiags.CodeDestroyWithPlanFile))
 			return nil, diags
 		}
 	}
@@ -312,6 +473,14 @@ func (c *ApplyCommand) LoadPlanFile(path string, enc encryption.Encryption) (*pl
 	return planFile, diags
 }
 
+// stdin returns the stream from which to read a plan file given as "-".
+func (c *ApplyCommand) stdin() io.Reader {
+	if c.input != nil {
+		return c.input
+	}
+	return os.Stdin
+}
+
 func (c *ApplyCommand) PrepareBackend(ctx context.Context, planFile *planfile.WrappedPlanFile, args *arguments.State, viewType arguments.ViewType, enc encryption.StateEncryption) (backend.Enhanced, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
@@ -325,22 +494,24 @@ func (c *ApplyCommand) PrepareBackend(ctx context.Context, planFile *planfile.Wr
 	var be backend.Enhanced
 	var beDiags tfdiags.Diagnostics
 	if lp, ok := planFile.Local(); ok {
-		plan, err := lp.ReadPlan()
+		// Only the backend is needed here; the backend reads the whole plan
+		// again to apply it.
+		plan, err := lp.ReadPlanWithoutChanges()
 		if err != nil {
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Failed to read plan from plan file",
 				fmt.Sprintf("Cannot read the plan from the given plan file: %s.", err),
-			))
+			), tfdiags.CodePlanFileLoadFailed))
 			return nil, diags
 		}
 		if plan.Backend.Config == nil {
 			// Should never happen; always indicates a bug in the creation of the plan file
-			diags = diags.Append(tfdiags.Sourceless(
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
 				tfdiags.Error,
 				"Failed to read plan from plan file",
 				"The given plan file does not have a valid backend configuration. This is a bug in the Farseek command that generated this plan file.",
-			))
+			), tfdiags.CodePlanFileLoadFailed))
 			return nil, diags
 		}
 		be, beDiags = c.BackendForLocalPlan(ctx, plan.Backend, enc)
@@ -379,17 +550,31 @@ func (c *ApplyCommand) OperationRequest(
 	// to switch back to a release version if the schema isn't compatible,
 	// so we'll warn about it.
 	diags = diags.Append(c.providerDevOverrideRuntimeWarnings())
+	diags = diags.Append(c.providerDevOverrideStrictErrors(applyArgs.Operation.AllowDevOverrides))
+	if diags.HasErrors() {
+		return nil, diags
+	}
 
 	// Build the operation
 	opReq := c.Operation(ctx, be, applyArgs.ViewType, enc)
 	opReq.AutoApprove = applyArgs.AutoApprove
+	opReq.ReviewPlan = applyArgs.Review
 	opReq.SuppressForgetErrorsDuringDestroy = applyArgs.SuppressForgetErrorsDuringDestroy
+	opReq.DestroyBatchSize = applyArgs.BatchSize
 	opReq.ConfigDir = "."
 	opReq.PlanMode = applyArgs.Operation.PlanMode
 	opReq.Hooks = view.Hooks()
 	opReq.PlanFile = planFile
+	if applyArgs.PlanPath != stdinArg {
+		opReq.PlanFilePath = applyArgs.PlanPath
+	}
+	opReq.ApplyTargets = applyArgs.Targets
 	opReq.PlanRefresh = applyArgs.Operation.Refresh
 	opReq.ForceReplace = applyArgs.Operation.ForceReplace
+	opReq.ForceReplaceReasons = applyArgs.Operation.ForceReplaceReasons
+	opReq.RefreshProviders = applyArgs.Operation.RefreshProviders
+	opReq.DataSourceCache = c.dataSourceCache(applyArgs.Operation)
+	opReq.Runtime = applyArgs.Operation.Runtime
 	opReq.Type = backend.OperationTypeApply
 	opReq.View = view.Operation()
 
@@ -403,6 +588,106 @@ func (c *ApplyCommand) OperationRequest(
 	return opReq, diags
 }
 
+// runAgent runs the apply on an agent instead of locally. Because the
+// agent cannot prompt for approval itself, an apply without a saved plan or
+// -auto-approve runs as a remote plan followed by a remote apply of that
+// plan once the user has approved it here.
+func (c *ApplyCommand) runAgent(ctx context.Context, args *arguments.Apply, rawArgs []string) int {
+	var diags tfdiags.Diagnostics
+	view := views.NewAgent(args.ViewType, c.View)
+	run := &agentRun{view: view}
+
+	// FIXME: see the same assignment in Run.
+	c.Meta.input = args.InputEnabled
+
+	forward := rawArgs
+	if args.PlanPath != "" {
+		// Flag parsing stops at the plan path, so it's always the final
+		// argument.
+		forward = forward[:len(forward)-1]
+	} else if c.Destroy {
+		forward = append([]string{"-destroy"}, forward...)
+	}
+	forward = agentArgs(forward)
+
+	req := &agent.Request{
+		Operation: agent.OperationApply,
+		Args:      forward,
+	}
+	switch {
+	case args.PlanPath == stdinArg:
+		planFile, err := io.ReadAll(c.stdin())
+		if err != nil {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read plan from standard input",
+				fmt.Sprintf("Cannot read the plan from standard input: %s.", err),
+			), tfdiags.CodePlanFileLoadFailed))
+			view.Diagnostics(diags)
+			return 1
+		}
+		req.PlanFile = planFile
+	case args.PlanPath != "":
+		planFile, err := os.ReadFile(args.PlanPath)
+		if err != nil {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read plan from plan file",
+				fmt.Sprintf("Cannot read the plan from the given plan file: %s.", err),
+			), tfdiags.CodePlanFileLoadFailed))
+			view.Diagnostics(diags)
+			return 1
+		}
+		req.PlanFile = planFile
+	case args.AutoApprove:
+		req.AutoApprove = true
+	default:
+		planReq := &agent.Request{
+			Operation: agent.OperationPlan,
+			Args:      forward,
+		}
+		result, planDiags := c.runAgentOperation(ctx, args.Agent, planReq, run)
+		diags = diags.Append(planDiags)
+		if diags.HasErrors() {
+			view.Diagnostics(diags)
+			return 1
+		}
+		if result.ExitCode != 0 || !run.hasChanges {
+			return result.ExitCode
+		}
+
+		ok, confirmDiags := c.confirmAgentApply(c.Destroy)
+		diags = diags.Append(confirmDiags)
+		if diags.HasErrors() {
+			view.Diagnostics(diags)
+			return 1
+		}
+		if !ok {
+			if c.Destroy {
+				view.Output("Destroy cancelled.")
+			} else {
+				view.Output("Apply cancelled.")
+			}
+			return 1
+		}
+
+		// Options such as variables and targets were already applied when
+		// creating the plan, and are not allowed with a saved plan.
+		req = &agent.Request{
+			Operation: agent.OperationApply,
+			PlanFile:  result.PlanFile,
+		}
+	}
+
+	result, applyDiags := c.runAgentOperation(ctx, args.Agent, req, run)
+	diags = diags.Append(applyDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+	return result.ExitCode
+}
+
 func (c *ApplyCommand) GatherVariables(args *arguments.Vars) {
 	// FIXME the arguments package currently trivially gathers variable related
 	// arguments in a heterogeneous slice, in order to minimize the number of
@@ -420,6 +705,37 @@ func (c *ApplyCommand) GatherVariables(args *arguments.Vars) {
 	c.Meta.variableArgs = rawFlags{items: &items}
 }
 
+func (c *ApplyCommand) AutocompleteArgs() complete.Predictor {
+	if c.Destroy {
+		return complete.PredictNothing
+	}
+	return c.completePredictPlanFile(c.CommandContext())
+}
+
+func (c *ApplyCommand) AutocompleteFlags() complete.Flags {
+	flags := c.completeOperationFlags(c.CommandContext())
+	flags["-auto-approve"] = complete.PredictNothing
+	flags["-review"] = complete.PredictNothing
+	flags["-suppress-forget-errors"] = complete.PredictNothing
+	flags["-require-signed-commits"] = complete.PredictNothing
+	flags["-allow-any-branch"] = complete.PredictNothing
+	flags["-allow-dev-overrides"] = complete.PredictNothing
+	flags["-group-by"] = complete.PredictSet("module", "provider", "action")
+	flags["-quiet"] = complete.PredictNothing
+	flags["-max-create"] = complete.PredictAnything
+	flags["-max-update"] = complete.PredictAnything
+	flags["-max-destroy"] = complete.PredictAnything
+	flags["-allow-exceeding-limits"] = complete.PredictNothing
+	if c.Destroy {
+		flags["-check-order"] = complete.PredictNothing
+		flags["-batch-size"] = complete.PredictAnything
+	} else {
+		flags["-target"] = c.completePredictResourceAddress(c.CommandContext())
+		flags["-tag-policy"] = complete.PredictFiles("*.hcl")
+	}
+	return flags
+}
+
 func (c *ApplyCommand) Help() string {
 	if c.Destroy {
 		return c.helpDestroy()
@@ -428,6 +744,81 @@ func (c *ApplyCommand) Help() string {
 	return c.helpApply()
 }
 
+// checkBranch checks that HEAD is on one of the branches that the CLI
+// configuration allows applying from, so that work in progress on another
+// branch isn't applied by accident.
+func (c *ApplyCommand) checkBranch(dir string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	branch, err := farseek.Discovery.GetCurrentBranch(dir)
+	if err != nil {
+		return diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read the current branch",
+			fmt.Sprintf("Farseek only applies from some branches, but it couldn't tell which branch HEAD is on: %s.", err),
+		), tfdiags.CodeUnexpectedBranch))
+	}
+
+	allowed := strings.Join(c.ApplyBranches, ", ")
+	if branch == "" {
+		return diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Can't apply with a detached HEAD",
+			fmt.Sprintf("Farseek only applies from the branches matching %s, but HEAD isn't on a branch. Check out one of those branches, or use -allow-any-branch to apply anyway.", allowed),
+		), tfdiags.CodeUnexpectedBranch))
+	}
+	for _, pattern := range c.ApplyBranches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return diags
+		}
+	}
+	return diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Can't apply from this branch",
+		fmt.Sprintf("Farseek only applies from the branches matching %s, but HEAD is on %s. Check out one of those branches, or use -allow-any-branch to apply anyway.", allowed, branch),
+	), tfdiags.CodeUnexpectedBranch))
+}
+
+// verifyCommits checks that the commits after the base SHA, up to HEAD, are
+// signed by trusted keys, as the -require-signed-commits option or the CLI
+// configuration requires.
+func (c *ApplyCommand) verifyCommits(dir, baseSHA string, uncommitted bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if uncommitted {
+		return diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Can't apply unsigned changes",
+			"Farseek requires the commits to apply to be signed by trusted keys, so it can't apply uncommitted changes. Commit and sign them, and apply again without -uncommitted.",
+		), tfdiags.CodeUnsignedCommits))
+	}
+
+	unverified, err := farseek.Discovery.VerifyCommits(dir, baseSHA, "HEAD", c.TrustedSigningKeys)
+	if err != nil {
+		return diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to verify commit signatures",
+			fmt.Sprintf("Farseek requires the commits to apply to be signed by trusted keys, but it couldn't check their signatures: %s.", err),
+		), tfdiags.CodeUnsignedCommits))
+	}
+	if len(unverified) == 0 {
+		return diags
+	}
+
+	var buf strings.Builder
+	buf.WriteString("Farseek requires the commits to apply to be signed by trusted keys, but these commits aren't:\n")
+	for _, commit := range unverified {
+		sha := commit.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		fmt.Fprintf(&buf, "\n  - %s by %s, %q: %s", sha, commit.Author, commit.Message, commit.Reason)
+	}
+	return diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Unsigned commits",
+		buf.String(),
+	), tfdiags.CodeUnsignedCommits))
+}
+
 func (c *ApplyCommand) Synopsis() string {
 	if c.Destroy {
 		return "Destroy previously-created infrastructure"
@@ -449,8 +840,23 @@ Usage: farseek [global options] apply [options] [PLAN]
   Farseek will take the actions described in that plan without any
   confirmation prompt.
 
+  If PLAN is "-", the plan file is read from standard input, such as when
+  piped from "farseek plan -out=-".
+
 Options:
 
+  -agent=url                   Run the operation on the agent at the given URL,
+                               started with "farseek agent", instead of
+                               locally. Approval is still requested here.
+
+  -allow-any-branch            Apply even if HEAD isn't on one of the branches
+                               that the "apply_branches" CLI setting allows.
+
+  -allow-dev-overrides         Apply even if provider development overrides
+                               are in effect and the
+                               "strict_provider_dev_overrides" CLI setting
+                               is enabled.
+
   -auto-approve                Skip interactive approval of plan before applying.
 
   -backup=path                 Path to backup the existing state file before
@@ -461,6 +867,9 @@ Options:
                                accompanied by errors, show them in a more compact
                                form that includes only the summary messages.
 
+  -suppress-warning=CODE       Leave out warnings with the given code or
+                               summary. Can be used multiple times.
+
   -consolidate-warnings=false  If Farseek produces any warnings, no consolidation
                                will be performed. All locations, for all warnings
                                will be listed. Enabled by default.
@@ -481,13 +890,73 @@ Options:
 
   -input=true                  Ask for input for variables if not directly set.
 
+  -input=strict                Don't prompt, and report every input that would
+                               have been prompted for in a single error, such
+                               as all the missing variables.
+
+  -max-create=n                Fail the plan if it creates more than n
+                               objects. -max-update and -max-destroy do the
+                               same for the objects that it updates and
+                               destroys, and a replacement counts as both.
+                               These override the "plan_limits" CLI setting,
+                               and aren't valid with a saved plan file.
+
+  -allow-exceeding-limits      Only warn when the plan exceeds one of the
+                               limits set by -max-create, -max-update,
+                               -max-destroy or the "plan_limits" CLI setting.
+
   -no-color                    If specified, output won't contain any color.
 
   -concise                     Disables progress-related messages in the output.
 
+  -quiet                       Show only errors and the line that counts the
+                               changes made, leaving out the progress
+                               messages, the warnings, the plan and the
+                               outputs. Requires -auto-approve or a saved
+                               plan file, since the plan isn't shown for
+                               approval.
+
+  -group-by=module             Group the resource changes in the plan under a
+                               heading for each module, with the number of
+                               resources to add, change and destroy in it.
+                               Use "provider" or "action" to group them by
+                               provider or by action instead.
+
   -parallelism=n               Limit the number of parallel resource operations.
                                Defaults to 10.
 
+  -runtime=name                Choose the language runtime that plans and
+                               applies the changes: "legacy", or
+                               "experimental" in builds with experiments
+                               enabled.
+
+  -data-cache-ttl=duration     Reuse the results of data resources read by
+                               earlier plans within the given duration, such
+                               as "1h", unless their farseek_cache_ttl
+                               argument says otherwise.
+
+  -data-cache=false            Read every data resource again instead of
+                               using cached results.
+
+  -operation-timeout=duration  Stop the operation gracefully, as if interrupted,
+                               if it has not completed within the given
+                               duration, such as "30m". Defaults to no limit.
+
+  -operation-timeout-grace=30s How long to wait for the operation to stop
+                               after -operation-timeout has elapsed before
+                               cancelling it outright.
+
+  -require-signed-commits      Check that the commits to apply are signed by
+                               trusted keys before applying them, as the
+                               "require_signed_commits" CLI setting does.
+
+  -review                      Show a summary of the plan instead of the whole
+                               plan, and explore it at the approval prompt:
+                               page through the changes, search them by
+                               address, list the ones that destroy objects or
+                               are in a module, and show a change in full.
+                               Needs an interactive terminal.
+
   -state=path                  Path to read and save state (unless state-out
                                is specified). Defaults to "farseek.tfstate".
 
@@ -501,6 +970,18 @@ Options:
                                operation completes successfully but leaves
                                forgotten instances behind.
 
+  -tag-policy=path             Check the resources that the plan creates or
+                               updates against the tag policy file at the
+                               given path, instead of the one that the
+                               "tag_policy" CLI setting selects. Not valid
+                               with a saved plan file.
+
+  -target=resource             Apply only the changes in the saved plan for the
+                               given resource address and its instances. The
+                               other changes are saved to a remainder plan file
+                               next to PLAN, and PLAN is marked as partially
+                               applied. This flag can be set multiple times.
+
   -var 'foo=bar'               Set a variable in the Farseek configuration.
                                This flag can be set multiple times.
 
@@ -514,6 +995,11 @@ Options:
                                suitable for use in text editor integrations and
                                other automated systems. Always disables color.
 
+  -json-protocol=N             Use version N of the machine-readable JSON
+                               output. Version 1 leaves out the message types
+                               that are specific to Farseek. Defaults to the
+                               latest version, 2.
+
   -deprecation=module:m        Specify what type of warnings are shown. Accepted
                                values for "m": all, local, none. Default: all.
                                When "all" is selected, Farseek will show the
@@ -542,6 +1028,18 @@ Usage: farseek [global options] destroy [options]
 
 Options:
 
+  -batch-size=n                Destroy the resources in batches of at most n
+                               resource instances, which respect their
+                               dependencies. Each batch is approved, unless
+                               -auto-approve is set, and applied on its own,
+                               and the state is saved after each one, so the
+                               destroy can be stopped between batches.
+
+  -check-order                 Print the order in which the resources would be
+                               destroyed, grouped into waves that are destroyed
+                               in parallel, and any create_before_destroy
+                               conflicts, without destroying anything.
+
   -suppress-forget-errors      Suppress the error that occurs when a destroy
                                operation completes successfully but leaves
                                forgotten instances behind.
@@ -9,6 +9,7 @@ package command
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -619,3 +620,163 @@ func TestApply_destroySkipInStateOrphaned(t *testing.T) {
 const testApplyDestroyStr = `
 <no state>
 `
+
+func TestApply_destroyCheckOrder(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+	config := `
+resource "test_instance" "foo" {
+  ami = "foo"
+}
+
+resource "test_instance" "bar" {
+  ami = test_instance.foo.id
+}
+`
+	if err := os.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := testProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Destroy: true,
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-check-order"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	want := "Wave 1:\n    - test_instance.bar\n\n  Wave 2:\n    - test_instance.foo\n"
+	if got := output.Stdout(); !strings.Contains(got, want) {
+		t.Errorf("wrong output\ngot:\n%s\nwant to contain:\n%s", got, want)
+	}
+	if p.ApplyResourceChangeCalled {
+		t.Fatal("-check-order must not destroy anything")
+	}
+}
+
+func TestApply_destroyCheckOrderModules(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+	files := map[string]string{
+		"main.tf": `
+resource "test_instance" "foo" {
+  ami = "foo"
+}
+
+module "child" {
+  source = "./child"
+  ami    = test_instance.foo.id
+}
+`,
+		"child/main.tf": `
+variable "ami" {
+  type = string
+}
+
+resource "test_instance" "bar" {
+  ami = var.ami
+}
+`,
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	// init to install the module
+	p := testProvider()
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	initCmd := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+			ProviderSource:   providerSource,
+		},
+	}
+	if code := initCmd.Run(nil); code != 0 {
+		t.Fatalf("init failed: %d\n\n%s", code, ui.ErrorWriter)
+	}
+
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Destroy: true,
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-check-order"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	want := "Wave 1:\n    - module.child.test_instance.bar\n\n  Wave 2:\n    - test_instance.foo\n"
+	if got := output.Stdout(); !strings.Contains(got, want) {
+		t.Errorf("wrong output\ngot:\n%s\nwant to contain:\n%s", got, want)
+	}
+}
+
+func TestApply_checkOrderWithoutDestroy(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	t.Chdir(td)
+
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-check-order"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n\n%s", code, output.Stdout())
+	}
+	if got, want := output.Stderr(), "Invalid -check-order option"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:\n%s\nwant to contain: %s", got, want)
+	}
+}
+
+func TestApply_batchSizeWithoutDestroy(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	t.Chdir(td)
+
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-batch-size=10", "-auto-approve"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n\n%s", code, output.Stdout())
+	}
+	if got, want := output.Stderr(), "Invalid -batch-size option"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:\n%s\nwant to contain: %s", got, want)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -687,6 +688,43 @@ func TestApply_plan(t *testing.T) {
 	}
 }
 
+func TestApply_planStdin(t *testing.T) {
+	planPath := applyFixturePlanFile(t)
+	statePath := testTempFile(t)
+
+	planFile, err := os.Open(planPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer planFile.Close()
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+		// Hide the file behind a plain reader, since stdin is not seekable.
+		input: io.MultiReader(planFile),
+	}
+
+	args := []string{
+		"-state-out", statePath,
+		"-",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	state := testStateRead(t, statePath)
+	if state == nil {
+		t.Fatal("state should not be nil")
+	}
+}
+
 func TestApply_plan_backup(t *testing.T) {
 	statePath := testTempFile(t)
 	backupPath := testTempFile(t)
@@ -2358,3 +2396,102 @@ func TestLocal_planFarseekMode_HandlesDeletion(t *testing.T) {
 		t.Fatalf("Expected 'test_instance.foo will be destroyed', but got:\n%s", output.Stdout())
 	}
 }
+
+func TestApply_requireSignedCommits(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	t.Chdir(td)
+	t.Setenv("FARSEEK_TEST_FORCE_MODE", "true")
+	if err := os.WriteFile(".farseek_sha", []byte("base-sha"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = mockDiscoverer{
+		resources: []farseek.DiscoveredResource{
+			{Address: "test_instance.foo", Filename: "main.tf", IsNew: true},
+		},
+		unverified: []farseek.UnverifiedCommit{
+			{
+				Commit: plans.Commit{SHA: "a1b2c3d4e5f6", Author: "mallory", Message: "open the firewall"},
+				Reason: "it isn't signed",
+			},
+		},
+	}
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-auto-approve", "-require-signed-commits"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n%s", code, output.Stdout())
+	}
+	if p.ApplyResourceChangeCalled {
+		t.Error("resources were applied")
+	}
+	want := `a1b2c3d by mallory, "open the firewall": it isn't signed`
+	if got := output.Stderr(); !strings.Contains(got, "Unsigned commits") || !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+}
+
+func TestApply_applyBranches(t *testing.T) {
+	tests := map[string]struct {
+		branch string
+		args   []string
+		want   string
+	}{
+		"allowed":           {branch: "release/1.0"},
+		"wrong branch":      {branch: "feature/x", want: "Can't apply from this branch"},
+		"detached":          {want: "Can't apply with a detached HEAD"},
+		"allowed by option": {branch: "feature/x", args: []string{"-allow-any-branch"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath("apply"), td)
+			t.Chdir(td)
+			t.Setenv("FARSEEK_TEST_FORCE_MODE", "true")
+
+			oldDiscovery := farseek.Discovery
+			defer func() { farseek.Discovery = oldDiscovery }()
+			farseek.Discovery = mockDiscoverer{branch: test.branch}
+
+			p := applyFixtureProvider()
+			view, done := testView(t)
+			c := &ApplyCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(p),
+					View:             view,
+					ApplyBranches:    []string{"main", "release/*"},
+				},
+			}
+
+			code := c.Run(append([]string{"-auto-approve"}, test.args...))
+			output := done(t)
+			if test.want == "" {
+				if code != 0 {
+					t.Fatalf("bad: %d\n%s", code, output.Stderr())
+				}
+				return
+			}
+			if code != 1 {
+				t.Fatalf("wrong exit status %d; want 1\n%s", code, output.Stdout())
+			}
+			if p.ApplyResourceChangeCalled {
+				t.Error("resources were applied")
+			}
+			if got := output.Stderr(); !strings.Contains(got, test.want) {
+				t.Errorf("output doesn't contain %q\n%s", test.want, got)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ package arguments
 import (
 	"fmt"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
@@ -24,25 +25,74 @@ type Apply struct {
 	// AutoApprove skips the manual verification step for the apply operation.
 	AutoApprove bool
 
+	// Review replaces the plan shown before the approval prompt with a
+	// summary, and lets the user explore the changes at the prompt.
+	Review bool
+
 	// InputEnabled is used to disable interactive input for unspecified
 	// variable and backend config values. Default is true.
 	InputEnabled bool
 
+	// InputStrict records every input that would have been prompted for,
+	// instead of prompting, so that they can all be reported at once. It is
+	// set by -input=strict, which also sets InputEnabled.
+	InputStrict bool
+
 	// PlanPath contains an optional path to a stored plan file
 	PlanPath string
 
+	// Targets restricts the apply of the stored plan file at PlanPath to
+	// the changes for these addresses, leaving the rest for later.
+	Targets []addrs.Targetable
+
 	// ViewType specifies which output format to use
 	ViewType ViewType
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
 
+	// GroupBy selects how the human-readable plan groups the resource
+	// changes, like the option of the same name of the plan command.
+	GroupBy string
+
+	// Quiet leaves out of the human-readable output everything but the
+	// errors and the summary of the changes.
+	Quiet bool
+
 	// SuppressForgetErrorsDuringDestroy suppresses the error that occurs when a
 	// destroy operation completes successfully but leaves forgotten instances behind.
 	SuppressForgetErrorsDuringDestroy bool
 
+	// CheckOrder prints the order in which a destroy would remove resources,
+	// grouped into waves, instead of destroying them.
+	CheckOrder bool
+
+	// BatchSize, if positive, splits a destroy into batches of at most this
+	// many resource instances, each approved and applied on its own.
+	BatchSize int
+
 	// Uncommitted includes unstaged and uncommitted local changes in the drift calculation.
 	Uncommitted bool
+
+	// RequireSignedCommits requires the commits being applied to be signed
+	// by trusted keys, in addition to the CLI configuration.
+	RequireSignedCommits bool
+
+	// AllowAnyBranch skips the check that HEAD is on one of the branches
+	// that the CLI configuration allows applying from.
+	AllowAnyBranch bool
+
+	// TagPolicy is the path of a tag policy file to check the planned
+	// changes against, instead of the one in the CLI configuration.
+	TagPolicy string
+
+	// Limits are the limits of the number of objects that the plan may
+	// create, update and destroy.
+	Limits *PlanLimits
+
+	// Agent is the URL of an agent that should run the operation instead
+	// of running it locally.
+	Agent string
 }
 
 // ParseApply processes CLI arguments, returning an Apply value and errors.
@@ -54,14 +104,28 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 		State:     &State{},
 		Operation: &Operation{},
 		Vars:      &Vars{},
+		Limits:    &PlanLimits{},
 	}
 
 	cmdFlags := extendedFlagSet("apply", apply.State, apply.Operation, apply.Vars)
+	apply.Limits.addFlags(cmdFlags)
 	cmdFlags.BoolVar(&apply.AutoApprove, "auto-approve", false, "auto-approve")
-	cmdFlags.BoolVar(&apply.InputEnabled, "input", true, "input")
+	cmdFlags.BoolVar(&apply.Review, "review", false, "review")
+	cmdFlags.Var(NewFlagInput(&apply.InputEnabled, &apply.InputStrict), "input", "input")
 	cmdFlags.BoolVar(&apply.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.StringVar(&apply.GroupBy, "group-by", "", "group-by")
+	cmdFlags.BoolVar(&apply.Quiet, "quiet", false, "quiet")
 	cmdFlags.BoolVar(&apply.SuppressForgetErrorsDuringDestroy, "suppress-forget-errors", false, "suppress errors in destroy mode due to resources being forgotten")
 	cmdFlags.BoolVar(&apply.Uncommitted, "uncommitted", false, "include uncommitted changes in drift calculation")
+	cmdFlags.BoolVar(&apply.CheckOrder, "check-order", false, "check-order")
+	cmdFlags.IntVar(&apply.BatchSize, "batch-size", 0, "batch-size")
+	cmdFlags.BoolVar(&apply.RequireSignedCommits, "require-signed-commits", false, "require-signed-commits")
+	cmdFlags.BoolVar(&apply.AllowAnyBranch, "allow-any-branch", false, "allow-any-branch")
+	cmdFlags.StringVar(&apply.Agent, "agent", "", "agent")
+	cmdFlags.StringVar(&apply.TagPolicy, "tag-policy", "", "tag-policy")
+
+	var targetsRaw []string
+	cmdFlags.Var((*flagStringSlice)(&targetsRaw), "target", "target")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -88,8 +152,11 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 		))
 	}
 
-	// JSON view currently does not support input, so we disable it here.
-	if json {
+	diags = diags.Append(validateGroupBy(apply.GroupBy))
+
+	// JSON view currently does not support input, so we disable it here,
+	// unless input is strict, which never prompts.
+	if json && !apply.InputStrict {
 		apply.InputEnabled = false
 	}
 
@@ -104,7 +171,94 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 		))
 	}
 
+	// The review replaces the plan shown before the approval prompt, so
+	// there must be one.
+	if apply.Review && (apply.AutoApprove || apply.PlanPath != "" || json) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -review option",
+			"The -review option is only valid when Farseek asks for approval of a new plan, so it cannot be used with -auto-approve, -json or a saved plan file.",
+		))
+	}
+
+	// The quiet output leaves out the plan, so it mustn't be asked to
+	// approve one.
+	if apply.Quiet && !json && apply.PlanPath == "" && !apply.AutoApprove {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Plan file or auto-approve required",
+			"The -quiet option leaves out the plan, so Farseek cannot ask for approval of it. You can either apply a saved plan file, or enable the -auto-approve option.",
+		))
+	}
+
+	if apply.BatchSize < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -batch-size option",
+			"The -batch-size option must be a positive number of resource instances.",
+		))
+	}
+
+	// Each batch has its own approval prompt, so there's no single plan to
+	// review.
+	if apply.BatchSize > 0 && apply.Review {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -review option can't be used with -batch-size, because each batch is approved separately.",
+		))
+	}
+
+	for _, raw := range targetsRaw {
+		target, targetDiags := addrs.ParseTargetStr(raw)
+		if targetDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid target address %q", raw),
+				targetDiags[0].Description().Detail,
+			))
+			continue
+		}
+		apply.Targets = append(apply.Targets, target.Subject)
+	}
+
+	// Targeting selects part of a saved plan, and the rest of that plan is
+	// written back next to it, so the plan must come from a file.
+	if len(targetsRaw) > 0 && (apply.PlanPath == "" || apply.PlanPath == "-") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -target option",
+			"The -target option is only valid when applying a saved plan file, to apply part of the planned changes.",
+		))
+	}
+
+	// A saved plan was checked against the tag policy when it was made.
+	if apply.TagPolicy != "" && apply.PlanPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -tag-policy option can't be used when applying a saved plan file, because the tag policy is checked when the plan is made.",
+		))
+	}
+
+	// A saved plan was checked against the limits when it was made.
+	if apply.Limits.IsSet() && apply.PlanPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -max-create, -max-update, -max-destroy and -allow-exceeding-limits options can't be used when applying a saved plan file, because the limits are checked when the plan is made.",
+		))
+	}
+
 	diags = diags.Append(apply.Operation.Parse())
+	diags = diags.Append(apply.Limits.Parse())
+	if apply.Operation.CompareRuntimes {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid runtime",
+			"The -runtime=compare option is only valid for \"farseek plan\", because only one runtime can apply the changes.",
+		))
+	}
 
 	switch {
 	case json:
@@ -31,10 +31,12 @@ func TestParseApply_basicValid(t *testing.T) {
 				ViewType:     ViewHuman,
 				State:        &State{Lock: true},
 				Vars:         &Vars{},
+				Limits:       &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.NormalMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.NormalMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -47,10 +49,12 @@ func TestParseApply_basicValid(t *testing.T) {
 				ViewType:     ViewHuman,
 				State:        &State{Lock: true},
 				Vars:         &Vars{},
+				Limits:       &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.NormalMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.NormalMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -63,10 +67,12 @@ func TestParseApply_basicValid(t *testing.T) {
 				ViewType:     ViewHuman,
 				State:        &State{Lock: true},
 				Vars:         &Vars{},
+				Limits:       &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.DestroyMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.DestroyMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -79,10 +85,12 @@ func TestParseApply_basicValid(t *testing.T) {
 				ViewType:     ViewJSON,
 				State:        &State{Lock: true},
 				Vars:         &Vars{},
+				Limits:       &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.NormalMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.NormalMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -156,6 +164,97 @@ func TestParseApply_invalid(t *testing.T) {
 	}
 }
 
+func TestParseApply_runtime(t *testing.T) {
+	got, diags := ParseApply([]string{"-runtime=experimental"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.Operation.Runtime != plans.ExperimentalRuntime {
+		t.Errorf("wrong runtime %q", got.Operation.Runtime)
+	}
+
+	_, diags = ParseApply([]string{"-runtime=compare"})
+	if got, want := diags.Err().Error(), "only valid for \"farseek plan\""; !strings.Contains(got, want) {
+		t.Errorf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_tagPolicy(t *testing.T) {
+	got, diags := ParseApply([]string{"-tag-policy=tags.hcl"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.TagPolicy != "tags.hcl" {
+		t.Errorf("wrong tag policy %q", got.TagPolicy)
+	}
+
+	_, diags = ParseApply([]string{"-tag-policy=tags.hcl", "saved.tfplan"})
+	if got, want := diags.Err().Error(), "Incompatible command line options"; !strings.Contains(got, want) {
+		t.Errorf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_review(t *testing.T) {
+	got, diags := ParseApply([]string{"-review"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if !got.Review {
+		t.Error("review not enabled")
+	}
+
+	for _, args := range [][]string{
+		{"-review", "-auto-approve"},
+		{"-review", "saved.tfplan"},
+	} {
+		_, diags := ParseApply(args)
+		if got, want := diags.Err().Error(), "Invalid -review option"; !strings.Contains(got, want) {
+			t.Errorf("wrong diags for %q\n got: %s\nwant: %s", args, got, want)
+		}
+	}
+}
+
+func TestParseApply_quiet(t *testing.T) {
+	for _, args := range [][]string{
+		{"-quiet", "-auto-approve"},
+		{"-quiet", "saved.tfplan"},
+	} {
+		got, diags := ParseApply(args)
+		if len(diags) > 0 {
+			t.Fatalf("unexpected diags for %q: %v", args, diags)
+		}
+		if !got.Quiet {
+			t.Errorf("quiet not enabled for %q", args)
+		}
+	}
+
+	_, diags := ParseApply([]string{"-quiet"})
+	if got, want := diags.Err().Error(), "Plan file or auto-approve required"; !strings.Contains(got, want) {
+		t.Errorf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_batchSize(t *testing.T) {
+	got, diags := ParseApply([]string{"-destroy", "-batch-size=50"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.BatchSize != 50 {
+		t.Errorf("wrong batch size %d; want 50", got.BatchSize)
+	}
+
+	for args, want := range map[string]string{
+		"-batch-size=-1":         "Invalid -batch-size option",
+		"-batch-size=10 -review": "Incompatible command line options",
+		"-batch-size=ten":        "Failed to parse command-line flags",
+	} {
+		_, diags := ParseApply(strings.Fields("-destroy " + args))
+		if !diags.HasErrors() || !strings.Contains(diags.Err().Error(), want) {
+			t.Errorf("wrong diags for %q\n got: %v\nwant: %s", args, diags.Err(), want)
+		}
+	}
+}
+
 func TestParseApply_tooManyArguments(t *testing.T) {
 	got, diags := ParseApply([]string{"saved.tfplan", "please"})
 	if len(diags) == 0 {
@@ -230,6 +329,113 @@ func TestParseApply_replace(t *testing.T) {
 	}
 }
 
+func TestParseApply_replaceReasons(t *testing.T) {
+	keyed, _ := addrs.ParseAbsResourceInstanceStr(`foo_bar.baz["a:b"]`)
+	testCases := map[string]struct {
+		args        []string
+		want        []string
+		wantReasons map[string]string
+		wantErr     string
+	}{
+		"reason": {
+			args:        []string{"-replace=foo_bar.baz:kernel upgrade"},
+			want:        []string{"foo_bar.baz"},
+			wantReasons: map[string]string{"foo_bar.baz": "kernel upgrade"},
+		},
+		"reason and no reason": {
+			args:        []string{"-replace=foo_bar.baz: kernel upgrade ", "-replace=foo_bar.beep"},
+			want:        []string{"foo_bar.baz", "foo_bar.beep"},
+			wantReasons: map[string]string{"foo_bar.baz": "kernel upgrade"},
+		},
+		"colon in instance key": {
+			args:        []string{`-replace=foo_bar.baz["a:b"]:rotate: again`},
+			want:        []string{keyed.String()},
+			wantReasons: map[string]string{keyed.String(): "rotate: again"},
+		},
+		"empty reason": {
+			args:    []string{"-replace=foo_bar.baz:"},
+			wantErr: "The reason after the colon can't be empty",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParseApply(tc.args)
+			if tc.wantErr != "" {
+				if got := diags.Err(); got == nil || !strings.Contains(got.Error(), tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %v\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if len(diags) > 0 {
+				t.Fatalf("unexpected diags: %v", diags)
+			}
+			var gotAddrs []string
+			for _, addr := range got.Operation.ForceReplace {
+				gotAddrs = append(gotAddrs, addr.String())
+			}
+			if diff := cmp.Diff(tc.want, gotAddrs); diff != "" {
+				t.Errorf("wrong addresses\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantReasons, got.Operation.ForceReplaceReasons); diff != "" {
+				t.Errorf("wrong reasons\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseApply_targets(t *testing.T) {
+	foobarbaz, _ := addrs.ParseTargetStr("foo_bar.baz")
+	boop, _ := addrs.ParseTargetStr("module.boop")
+	testCases := map[string]struct {
+		args    []string
+		want    []addrs.Targetable
+		wantErr string
+	}{
+		"no addresses by default": {
+			args: []string{"saved.tfplan"},
+			want: nil,
+		},
+		"two addresses": {
+			args: []string{"-target=foo_bar.baz", "-target", "module.boop", "saved.tfplan"},
+			want: []addrs.Targetable{foobarbaz.Subject, boop.Subject},
+		},
+		"invalid address": {
+			args:    []string{"-target=foo.", "saved.tfplan"},
+			want:    nil,
+			wantErr: "Dot must be followed by attribute name",
+		},
+		"no plan file": {
+			args:    []string{"-target=foo_bar.baz"},
+			want:    []addrs.Targetable{foobarbaz.Subject},
+			wantErr: "only valid when applying a saved plan file",
+		},
+		"plan from stdin": {
+			args:    []string{"-target=foo_bar.baz", "-"},
+			want:    []addrs.Targetable{foobarbaz.Subject},
+			wantErr: "only valid when applying a saved plan file",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParseApply(tc.args)
+			if tc.wantErr == "" && len(diags) > 0 {
+				t.Fatalf("unexpected diags: %v", diags)
+			} else if tc.wantErr != "" {
+				if len(diags) == 0 {
+					t.Fatalf("expected diags but got none")
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			}
+			if !cmp.Equal(got.Targets, tc.want) {
+				t.Fatalf("unexpected result\n%s", cmp.Diff(got.Targets, tc.want))
+			}
+		})
+	}
+}
+
 func TestParseApply_vars(t *testing.T) {
 	testCases := map[string]struct {
 		args []string
@@ -294,10 +500,12 @@ func TestParseApplyDestroy_basicValid(t *testing.T) {
 				ViewType:     ViewHuman,
 				State:        &State{Lock: true},
 				Vars:         &Vars{},
+				Limits:       &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.DestroyMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.DestroyMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -309,10 +517,12 @@ func TestParseApplyDestroy_basicValid(t *testing.T) {
 				ViewType:     ViewHuman,
 				State:        &State{Lock: true},
 				Vars:         &Vars{},
+				Limits:       &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.DestroyMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.DestroyMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -347,3 +557,10 @@ func TestParseApplyDestroy_invalid(t *testing.T) {
 		}
 	})
 }
+
+func TestParseApply_limitsWithSavedPlan(t *testing.T) {
+	_, diags := ParseApply([]string{"-max-destroy=10", "saved.tfplan"})
+	if got, want := diags.Err().Error(), "the limits are checked when the plan is made"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
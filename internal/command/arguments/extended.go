@@ -10,6 +10,7 @@ package arguments
 import (
 	"flag"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
@@ -24,6 +25,11 @@ import (
 // operations as it walks the dependency graph.
 const DefaultParallelism = 10
 
+// DefaultOperationTimeoutGrace is how long Farseek waits for an operation
+// to stop gracefully after its -operation-timeout has elapsed before it
+// cancels the operation outright.
+const DefaultOperationTimeoutGrace = 30 * time.Second
+
 // State describes arguments which are used to define how Farseek interacts
 // with state.
 type State struct {
@@ -67,6 +73,16 @@ type Operation struct {
 	// state before proceeding. Default is true.
 	Refresh bool
 
+	// Timeout is an optional limit on the total running time of the
+	// operation. Once it elapses, Farseek begins the same graceful stop
+	// process it would use for an interrupt signal. The default is 0,
+	// meaning no limit.
+	Timeout time.Duration
+
+	// TimeoutGrace is how long Farseek waits for the operation to stop
+	// gracefully once Timeout has elapsed, before cancelling it outright.
+	TimeoutGrace time.Duration
+
 	// ForceReplace addresses cause Farseek to force a particular set of
 	// resource instances to generate "replace" actions in any plan where they
 	// would normally have generated "no-op" or "update" actions.
@@ -79,12 +95,49 @@ type Operation struct {
 	// learn a use-case for broader matching.
 	ForceReplace []addrs.AbsResourceInstance
 
+	// ForceReplaceReasons are the reasons given for replacing some of the
+	// ForceReplace addresses, as in -replace='aws_instance.a:kernel upgrade',
+	// by the address of the resource instance.
+	ForceReplaceReasons map[string]string
+
+	// RefreshProviders, if not empty, limits refreshing to the resources of
+	// these providers, given by their local names in the root module or by
+	// their source addresses. They are resolved against the configuration
+	// by the backend.
+	RefreshProviders []string
+
+	// DataCacheTTL is how long the results of data resources without a
+	// farseek_cache_ttl argument are cached between plans. The default is
+	// 0, meaning that only the data resources with the argument are cached.
+	DataCacheTTL time.Duration
+
+	// DataCacheBypass makes every data resource read from its provider
+	// again, instead of using a cached result. The results are still cached
+	// for later operations.
+	DataCacheBypass bool
+
+	// Runtime selects the language runtime for the operation. If empty, the
+	// TOFU_X_EXPERIMENTAL_RUNTIME environment variable decides.
+	Runtime plans.Runtime
+
+	// CompareRuntimes requests a plan from both language runtimes, and a
+	// report of how the experimental runtime's plan differs from the legacy
+	// runtime's plan, which is the one that's used.
+	CompareRuntimes bool
+
+	// AllowDevOverrides lets the operation run with provider development
+	// overrides in effect when the CLI configuration is strict about them.
+	AllowDevOverrides bool
+
 	// These private fields are used only temporarily during decoding. Use
 	// method Parse to populate the exported fields from these, validating
 	// the raw values in the process.
 	forceReplaceRaw []string
+	refreshScopeRaw []string
 	destroyRaw      bool
 	refreshOnlyRaw  bool
+	runtimeRaw      string
+	dataCacheRaw    bool
 }
 
 // Parse must be called on Operation after initial flag parse. This processes
@@ -94,7 +147,17 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
 	for _, raw := range o.forceReplaceRaw {
-		traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
+		rawAddr, reason, hasReason := cutForceReplaceReason(raw)
+		if hasReason && reason == "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid force-replace address %q", raw),
+				"The reason after the colon can't be empty. Use -replace=ADDRESS to replace a resource instance without giving a reason.",
+			))
+			continue
+		}
+
+		traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(rawAddr), "", hcl.Pos{Line: 1, Column: 1})
 		if syntaxDiags.HasErrors() {
 			diags = diags.Append(tfdiags.Sourceless(
 				tfdiags.Error,
@@ -124,6 +187,70 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 		}
 
 		o.ForceReplace = append(o.ForceReplace, addr)
+		if reason != "" {
+			if o.ForceReplaceReasons == nil {
+				o.ForceReplaceReasons = make(map[string]string)
+			}
+			o.ForceReplaceReasons[addr.String()] = reason
+		}
+	}
+
+	for _, raw := range o.refreshScopeRaw {
+		kind, name, ok := strings.Cut(raw, "=")
+		if !ok || kind != "provider" || strings.TrimSpace(name) == "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid refresh scope %q", raw),
+				"The -refresh-scope option must have the form provider=NAME, where NAME is the local name of a provider in the root module, such as \"aws\", or its source address, such as \"hashicorp/aws\".",
+			))
+			continue
+		}
+		o.RefreshProviders = append(o.RefreshProviders, strings.TrimSpace(name))
+	}
+	if len(o.refreshScopeRaw) != 0 && !o.Refresh {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible refresh options",
+			"The -refresh-scope option limits which resources are refreshed, so it can't be used at the same time as -refresh=false.",
+		))
+	}
+
+	if o.Timeout < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid operation timeout",
+			"The -operation-timeout option must not be negative.",
+		))
+	}
+	if o.TimeoutGrace < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid operation timeout grace period",
+			"The -operation-timeout-grace option must not be negative.",
+		))
+	}
+	if o.DataCacheTTL < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid data cache TTL",
+			"The -data-cache-ttl option must not be negative.",
+		))
+	}
+	o.DataCacheBypass = !o.dataCacheRaw
+
+	switch o.runtimeRaw {
+	case "":
+	case string(plans.LegacyRuntime), string(plans.ExperimentalRuntime):
+		o.Runtime = plans.Runtime(o.runtimeRaw)
+	case "compare":
+		o.Runtime = plans.LegacyRuntime
+		o.CompareRuntimes = true
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid runtime",
+			fmt.Sprintf("The -runtime option must be \"legacy\", \"experimental\", or \"compare\", not %q.", o.runtimeRaw),
+		))
 	}
 
 	// If you add a new possible value for o.PlanMode here, consider also
@@ -153,6 +280,76 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 	return diags
 }
 
+// cutForceReplaceReason splits a -replace option into the address and the
+// reason after the first colon that isn't part of an instance key, such as
+// aws_instance.a["b:c"]. hasReason is false if there is no such colon.
+func cutForceReplaceReason(raw string) (addr, reason string, hasReason bool) {
+	depth := 0
+	inString := false
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ':' && depth == 0:
+			return raw[:i], strings.TrimSpace(raw[i+1:]), true
+		}
+	}
+	return raw, "", false
+}
+
+// PlanLimits describes arguments which limit how many objects a plan may
+// create, update and destroy, in addition to the limits of the CLI
+// configuration.
+type PlanLimits struct {
+	// MaxCreate, MaxUpdate and MaxDestroy are the limits, or -1 where they
+	// aren't set.
+	MaxCreate  int
+	MaxUpdate  int
+	MaxDestroy int
+
+	// AllowExceeding reports plans that exceed the limits with warnings
+	// instead of errors.
+	AllowExceeding bool
+}
+
+func (l *PlanLimits) addFlags(f *flag.FlagSet) {
+	f.IntVar(&l.MaxCreate, "max-create", -1, "max-create")
+	f.IntVar(&l.MaxUpdate, "max-update", -1, "max-update")
+	f.IntVar(&l.MaxDestroy, "max-destroy", -1, "max-destroy")
+	f.BoolVar(&l.AllowExceeding, "allow-exceeding-limits", false, "allow-exceeding-limits")
+}
+
+// Parse must be called on PlanLimits after initial flag parse, to validate
+// the limits.
+func (l *PlanLimits) Parse() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{{"-max-create", l.MaxCreate}, {"-max-update", l.MaxUpdate}, {"-max-destroy", l.MaxDestroy}} {
+		if limit.value < -1 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid plan limit",
+				fmt.Sprintf("The %s option must be zero or more, but is %d.", limit.name, limit.value),
+			))
+		}
+	}
+	return diags
+}
+
+// IsSet returns whether any of the options are set.
+func (l *PlanLimits) IsSet() bool {
+	return l.MaxCreate >= 0 || l.MaxUpdate >= 0 || l.MaxDestroy >= 0 || l.AllowExceeding
+}
+
 // Vars describes arguments which specify non-default variable values. This
 // interface is unfortunately obscure, because the order of the CLI arguments
 // determines the final value of the gathered variables. In future it might be
@@ -198,9 +395,16 @@ func extendedFlagSet(name string, state *State, operation *Operation, vars *Vars
 	if operation != nil {
 		f.IntVar(&operation.Parallelism, "parallelism", DefaultParallelism, "parallelism")
 		f.BoolVar(&operation.Refresh, "refresh", true, "refresh")
+		f.DurationVar(&operation.Timeout, "operation-timeout", 0, "operation-timeout")
+		f.DurationVar(&operation.TimeoutGrace, "operation-timeout-grace", DefaultOperationTimeoutGrace, "operation-timeout-grace")
 		f.BoolVar(&operation.destroyRaw, "destroy", false, "destroy")
 		f.BoolVar(&operation.refreshOnlyRaw, "refresh-only", false, "refresh-only")
 		f.Var((*flagStringSlice)(&operation.forceReplaceRaw), "replace", "replace")
+		f.Var((*flagStringSlice)(&operation.refreshScopeRaw), "refresh-scope", "refresh-scope")
+		f.StringVar(&operation.runtimeRaw, "runtime", "", "runtime")
+		f.DurationVar(&operation.DataCacheTTL, "data-cache-ttl", 0, "data-cache-ttl")
+		f.BoolVar(&operation.dataCacheRaw, "data-cache", true, "data-cache")
+		f.BoolVar(&operation.AllowDevOverrides, "allow-dev-overrides", false, "allow-dev-overrides")
 	}
 
 	// Gather all -var and -var-file arguments into one heterogeneous structure
@@ -10,6 +10,7 @@ package arguments
 import (
 	"flag"
 	"fmt"
+	"strconv"
 )
 
 // flagStringSlice is a flag.Value implementation which allows collecting
@@ -28,6 +29,54 @@ func (v *flagStringSlice) Set(raw string) error {
 	return nil
 }
 
+// FlagInput is a flag.Value implementation for the -input option, which is
+// a boolean that also accepts "strict". In strict mode, input is enabled so
+// that every prompt is reached, but the prompts are recorded instead of
+// being shown.
+type FlagInput struct {
+	Enabled *bool
+	Strict  *bool
+}
+
+var _ flag.Value = FlagInput{}
+
+// NewFlagInput returns a FlagInput that sets enabled and strict, setting
+// enabled to the default of true.
+func NewFlagInput(enabled, strict *bool) FlagInput {
+	*enabled = true
+	return FlagInput{Enabled: enabled, Strict: strict}
+}
+
+func (f FlagInput) String() string {
+	if f.Strict != nil && *f.Strict {
+		return "strict"
+	}
+	if f.Enabled == nil {
+		return ""
+	}
+	return strconv.FormatBool(*f.Enabled)
+}
+
+func (f FlagInput) Set(raw string) error {
+	if raw == "strict" {
+		*f.Enabled = true
+		*f.Strict = true
+		return nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fmt.Errorf("must be true, false or strict")
+	}
+	*f.Enabled = v
+	*f.Strict = false
+	return nil
+}
+
+// IsBoolFlag allows the option to be given as just -input.
+func (f FlagInput) IsBoolFlag() bool {
+	return true
+}
+
 // flagNameValueSlice is a flag.Value implementation that appends raw flag
 // names and values to a slice. This is used to collect a sequence of flags
 // with possibly different names, preserving the overall order.
@@ -8,6 +8,13 @@
 package arguments
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -17,6 +24,11 @@ type Output struct {
 	// outputs.
 	Name string
 
+	// Path optionally selects a nested value within the named output, such
+	// as the "instances[0].id" part of "farseek output web.instances[0].id".
+	// If empty, the whole output value is shown.
+	Path cty.Path
+
 	// StatePath is an optional path to a state file, from which outputs will
 	// be loaded.
 	StatePath string
@@ -28,8 +40,23 @@ type Output struct {
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// Schema requests a JSON Schema describing the types of the selected
+	// outputs instead of their values. This is only valid with ViewJSON.
+	Schema bool
+
+	// Serve is the address to serve all of the outputs on over HTTP, or
+	// empty to print them instead. ServeToken is an optional token that
+	// clients must present, and ServeDuration is how long to serve for.
+	Serve         string
+	ServeToken    string
+	ServeDuration time.Duration
 }
 
+// defaultOutputServeDuration is how long "farseek output -serve" serves the
+// outputs for when -serve-duration isn't given.
+const defaultOutputServeDuration = 10 * time.Minute
+
 // ParseOutput processes CLI arguments, returning an Output value and errors.
 // If errors are encountered, an Output value is still returned representing
 // the best effort interpretation of the arguments.
@@ -46,6 +73,10 @@ func ParseOutput(args []string) (*Output, tfdiags.Diagnostics) {
 	cmdFlags.BoolVar(&rawOutput, "raw", false, "raw")
 	cmdFlags.StringVar(&statePath, "state", "", "path")
 	cmdFlags.BoolVar(&output.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.BoolVar(&output.Schema, "schema", false, "schema")
+	cmdFlags.StringVar(&output.Serve, "serve", "", "address")
+	cmdFlags.StringVar(&output.ServeToken, "serve-token", "", "token")
+	cmdFlags.DurationVar(&output.ServeDuration, "serve-duration", 0, "duration")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -78,8 +109,59 @@ func ParseOutput(args []string) (*Output, tfdiags.Diagnostics) {
 
 	output.StatePath = statePath
 
-	if len(args) > 0 {
-		output.Name = args[0]
+	// An empty name selects all outputs, as if none were given.
+	if len(args) > 0 && args[0] != "" {
+		name, path, pathDiags := parseOutputPath(args[0])
+		diags = diags.Append(pathDiags)
+		output.Name = name
+		output.Path = path
+	}
+
+	if output.Schema && !jsonOutput {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output format",
+			"The -schema option can only be used together with the -json option.",
+		))
+	}
+
+	if output.Schema && len(output.Path) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output format",
+			"The -schema option describes whole output values, so it cannot be used with a path into an output value.",
+		))
+	}
+
+	if output.Serve != "" {
+		switch {
+		case output.Name != "":
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid output format",
+				"The -serve option serves all of the outputs, so it cannot be used with an output name.",
+			))
+		case output.Schema:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid output format",
+				"The -schema and -serve options are mutually-exclusive.",
+			))
+		case output.ServeDuration < 0:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid serve duration",
+				"The -serve-duration option must be a positive duration, such as \"30m\".",
+			))
+		case output.ServeDuration == 0:
+			output.ServeDuration = defaultOutputServeDuration
+		}
+	} else if output.ServeToken != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output format",
+			"The -serve-token option can only be used together with the -serve option.",
+		))
 	}
 
 	if rawOutput && output.Name == "" {
@@ -101,3 +183,41 @@ func ParseOutput(args []string) (*Output, tfdiags.Diagnostics) {
 
 	return output, diags
 }
+
+// parseOutputPath splits an output argument such as "instances[0].id" into
+// the name of the output value and the path of a nested value within it.
+// A plain output name results in an empty path.
+func parseOutputPath(raw string) (string, cty.Path, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
+	if syntaxDiags.HasErrors() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			fmt.Sprintf("Invalid output path %q", raw),
+			syntaxDiags[0].Detail,
+		))
+		return raw, nil, diags
+	}
+
+	name := traversal.RootName()
+	var path cty.Path
+	for _, step := range traversal[1:] {
+		switch step := step.(type) {
+		case hcl.TraverseAttr:
+			path = path.GetAttr(step.Name)
+		case hcl.TraverseIndex:
+			path = path.Index(step.Key)
+		default:
+			// This should be unreachable, since ParseTraversalAbs only
+			// produces attribute and index steps after the root.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid output path %q", raw),
+				"An output path may contain only attribute access and index steps, such as \"instances[0].id\".",
+			))
+			return name, nil, diags
+		}
+	}
+	return name, path, diags
+}
@@ -9,10 +9,13 @@ package arguments
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestParseOutput_valid(t *testing.T) {
@@ -44,6 +47,34 @@ func TestParseOutput_valid(t *testing.T) {
 				StatePath: "",
 			},
 		},
+		"json schema": {
+			[]string{"-json", "-schema"},
+			&Output{
+				Name:      "",
+				ViewType:  ViewJSON,
+				StatePath: "",
+				Schema:    true,
+			},
+		},
+		"serve": {
+			[]string{"-serve=127.0.0.1:0", "-serve-token=secret"},
+			&Output{
+				Name:          "",
+				ViewType:      ViewHuman,
+				Serve:         "127.0.0.1:0",
+				ServeToken:    "secret",
+				ServeDuration: 10 * time.Minute,
+			},
+		},
+		"serve json with duration": {
+			[]string{"-json", "-serve=:8080", "-serve-duration=30s"},
+			&Output{
+				Name:          "",
+				ViewType:      ViewJSON,
+				Serve:         ":8080",
+				ServeDuration: 30 * time.Second,
+			},
+		},
 		"state": {
 			[]string{"-state=foobar.tfstate", "-raw", "foo"},
 			&Output{
@@ -61,7 +92,7 @@ func TestParseOutput_valid(t *testing.T) {
 				t.Fatalf("unexpected diags: %v", diags)
 			}
 			got.Vars = nil
-			if *got != *tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
 			}
 		})
@@ -119,6 +150,52 @@ func TestParseOutput_invalid(t *testing.T) {
 				),
 			},
 		},
+		"schema without json": {
+			[]string{"-schema"},
+			&Output{
+				Name:      "",
+				ViewType:  ViewHuman,
+				StatePath: "",
+				Schema:    true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output format",
+					"The -schema option can only be used together with the -json option.",
+				),
+			},
+		},
+		"serve with name": {
+			[]string{"-serve=:8080", "foo"},
+			&Output{
+				Name:     "foo",
+				ViewType: ViewHuman,
+				Serve:    ":8080",
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output format",
+					"The -serve option serves all of the outputs, so it cannot be used with an output name.",
+				),
+			},
+		},
+		"serve token without serve": {
+			[]string{"-serve-token=secret"},
+			&Output{
+				Name:       "",
+				ViewType:   ViewHuman,
+				ServeToken: "secret",
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output format",
+					"The -serve-token option can only be used together with the -serve option.",
+				),
+			},
+		},
 		"too many arguments": {
 			[]string{"-raw", "-state=foo.tfstate", "bar", "baz"},
 			&Output{
@@ -140,7 +217,7 @@ func TestParseOutput_invalid(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			got, gotDiags := ParseOutput(tc.args)
 			got.Vars = nil
-			if *got != *tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
 			}
 			if !reflect.DeepEqual(gotDiags, tc.wantDiags) {
@@ -149,3 +226,61 @@ func TestParseOutput_invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestParseOutput_path(t *testing.T) {
+	testCases := map[string]struct {
+		arg      string
+		wantName string
+		wantPath cty.Path
+		wantErr  string
+	}{
+		"name only": {
+			arg:      "foo",
+			wantName: "foo",
+			wantPath: nil,
+		},
+		"attribute": {
+			arg:      "foo.bar",
+			wantName: "foo",
+			wantPath: cty.GetAttrPath("bar"),
+		},
+		"index and attribute": {
+			arg:      "instances[0].id",
+			wantName: "instances",
+			wantPath: cty.IndexIntPath(0).GetAttr("id"),
+		},
+		"string key": {
+			arg:      `tags["Name"]`,
+			wantName: "tags",
+			wantPath: cty.IndexStringPath("Name"),
+		},
+		"splat": {
+			arg:     "instances[*].id",
+			wantErr: "Invalid output path",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParseOutput([]string{"-raw", tc.arg})
+			if tc.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatal("expected error but got none")
+				}
+				if got, want := diags.Err().Error(), tc.wantErr; !strings.Contains(got, want) {
+					t.Fatalf("wrong error\n got: %s\nwant: %s", got, want)
+				}
+				return
+			}
+			if len(diags) > 0 {
+				t.Fatalf("unexpected diags: %v", diags)
+			}
+			if got.Name != tc.wantName {
+				t.Errorf("wrong name %q; want %q", got.Name, tc.wantName)
+			}
+			if !got.Path.Equals(tc.wantPath) {
+				t.Errorf("wrong path\n got: %#v\nwant: %#v", got.Path, tc.wantPath)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@
 package arguments
 
 import (
+	"fmt"
+
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -26,9 +28,17 @@ type Plan struct {
 	// variable and backend config values. Default is true.
 	InputEnabled bool
 
+	// InputStrict records every input that would have been prompted for,
+	// instead of prompting, so that they can all be reported at once. It is
+	// set by -input=strict, which also sets InputEnabled.
+	InputStrict bool
+
 	// OutPath contains an optional path to store the plan file
 	OutPath string
 
+	// CompressPlan selects the compressed plan file format for OutPath.
+	CompressPlan bool
+
 	// GenerateConfigPath tells Farseek that config should be generated for
 	// unmatched import target paths and which path the generated file should
 	// be written to.
@@ -40,8 +50,36 @@ type Plan struct {
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
 
+	// GroupBy selects how the human-readable plan groups the resource
+	// changes: by "module", "provider" or "action", or empty to not group
+	// them.
+	GroupBy string
+
+	// Quiet leaves out of the human-readable output everything but the
+	// errors and the summary of the planned changes.
+	Quiet bool
+
 	// Uncommitted includes unstaged and uncommitted local changes in the drift calculation.
 	Uncommitted bool
+
+	// FromSHA and ToSHA select the commits that discovery compares, instead
+	// of the recorded SHA and the current configuration. The plan then shows
+	// what applying the configuration at ToSHA would change, after applying
+	// the configuration at FromSHA.
+	FromSHA string
+	ToSHA   string
+
+	// TagPolicy is the path of a tag policy file to check the planned
+	// changes against, instead of the one in the CLI configuration.
+	TagPolicy string
+
+	// Limits are the limits of the number of objects that the plan may
+	// create, update and destroy.
+	Limits *PlanLimits
+
+	// Agent is the URL of an agent that should run the operation instead
+	// of running it locally.
+	Agent string
 }
 
 // ParsePlan processes CLI arguments, returning a Plan value and errors.
@@ -53,15 +91,24 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 		State:     &State{},
 		Operation: &Operation{},
 		Vars:      &Vars{},
+		Limits:    &PlanLimits{},
 	}
 
 	cmdFlags := extendedFlagSet("plan", plan.State, plan.Operation, plan.Vars)
+	plan.Limits.addFlags(cmdFlags)
 	cmdFlags.BoolVar(&plan.DetailedExitCode, "detailed-exitcode", false, "detailed-exitcode")
-	cmdFlags.BoolVar(&plan.InputEnabled, "input", true, "input")
+	cmdFlags.Var(NewFlagInput(&plan.InputEnabled, &plan.InputStrict), "input", "input")
 	cmdFlags.StringVar(&plan.OutPath, "out", "", "out")
+	cmdFlags.BoolVar(&plan.CompressPlan, "compress-plan", false, "compress-plan")
 	cmdFlags.StringVar(&plan.GenerateConfigPath, "generate-config-out", "", "generate-config-out")
 	cmdFlags.BoolVar(&plan.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.StringVar(&plan.GroupBy, "group-by", "", "group-by")
+	cmdFlags.BoolVar(&plan.Quiet, "quiet", false, "quiet")
 	cmdFlags.BoolVar(&plan.Uncommitted, "uncommitted", false, "include uncommitted changes in drift calculation")
+	cmdFlags.StringVar(&plan.FromSHA, "from-sha", "", "from-sha")
+	cmdFlags.StringVar(&plan.ToSHA, "to-sha", "", "to-sha")
+	cmdFlags.StringVar(&plan.Agent, "agent", "", "agent")
+	cmdFlags.StringVar(&plan.TagPolicy, "tag-policy", "", "tag-policy")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -85,9 +132,44 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 	}
 
 	diags = diags.Append(plan.Operation.Parse())
+	diags = diags.Append(plan.Limits.Parse())
+	diags = diags.Append(validateGroupBy(plan.GroupBy))
+
+	if plan.CompressPlan && plan.OutPath == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -compress-plan option requires -out, because it selects the format of the saved plan file.",
+		))
+	}
+
+	if plan.Agent != "" && plan.GenerateConfigPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -generate-config-out option cannot be used with -agent, because the configuration would be generated on the agent.",
+		))
+	}
 
-	// JSON view currently does not support input, so we disable it here
-	if json {
+	if plan.ToSHA != "" && plan.Uncommitted {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -uncommitted option cannot be used with -to-sha, because the changes are taken from the given commit.",
+		))
+	}
+
+	if plan.ToSHA != "" && plan.OutPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible command line options",
+			"The -out option cannot be used with -to-sha, because applying the saved plan would record the current commit as applied instead.",
+		))
+	}
+
+	// JSON view currently does not support input, so we disable it here,
+	// unless input is strict, which never prompts.
+	if json && !plan.InputStrict {
 		plan.InputEnabled = false
 	}
 
@@ -100,3 +182,19 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 
 	return plan, diags
 }
+
+// validateGroupBy returns an error if the given value of the -group-by
+// option isn't one of the groupings of the human-readable plan.
+func validateGroupBy(groupBy string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	switch groupBy {
+	case "", "module", "provider", "action":
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid value for -group-by",
+			fmt.Sprintf("The -group-by option must be \"module\", \"provider\" or \"action\", not %q.", groupBy),
+		))
+	}
+	return diags
+}
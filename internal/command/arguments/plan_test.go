@@ -10,6 +10,7 @@ package arguments
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -30,10 +31,12 @@ func TestParsePlan_basicValid(t *testing.T) {
 				ViewType:         ViewHuman,
 				State:            &State{Lock: true},
 				Vars:             &Vars{},
+				Limits:           &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.NormalMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.NormalMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -46,10 +49,12 @@ func TestParsePlan_basicValid(t *testing.T) {
 				ViewType:         ViewHuman,
 				State:            &State{Lock: true},
 				Vars:             &Vars{},
+				Limits:           &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.DestroyMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.DestroyMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -62,10 +67,12 @@ func TestParsePlan_basicValid(t *testing.T) {
 				ViewType:         ViewJSON,
 				State:            &State{Lock: true},
 				Vars:             &Vars{},
+				Limits:           &PlanLimits{MaxCreate: -1, MaxUpdate: -1, MaxDestroy: -1},
 				Operation: &Operation{
-					PlanMode:    plans.NormalMode,
-					Parallelism: 10,
-					Refresh:     true,
+					PlanMode:     plans.NormalMode,
+					Parallelism:  10,
+					TimeoutGrace: DefaultOperationTimeoutGrace,
+					Refresh:      true,
 				},
 			},
 		},
@@ -99,6 +106,170 @@ func TestParsePlan_invalid(t *testing.T) {
 	}
 }
 
+func TestParsePlan_input(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		enabled bool
+		strict  bool
+	}{
+		"default":          {nil, true, false},
+		"bare":             {[]string{"-input"}, true, false},
+		"false":            {[]string{"-input=false"}, false, false},
+		"strict":           {[]string{"-input=strict"}, true, true},
+		"strict with JSON": {[]string{"-input=strict", "-json"}, true, true},
+		"last wins":        {[]string{"-input=strict", "-input=true"}, true, false},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				t.Fatalf("unexpected diags: %v", diags)
+			}
+			if got.InputEnabled != tc.enabled || got.InputStrict != tc.strict {
+				t.Errorf("wrong input options: enabled %t, strict %t", got.InputEnabled, got.InputStrict)
+			}
+		})
+	}
+
+	_, diags := ParsePlan([]string{"-input=sometimes"})
+	if got, want := diags.Err().Error(), "must be true, false or strict"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParsePlan_shaRange(t *testing.T) {
+	got, diags := ParsePlan([]string{"-from-sha=aaa", "-to-sha=bbb"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.FromSHA != "aaa" || got.ToSHA != "bbb" {
+		t.Fatalf("wrong range %q to %q", got.FromSHA, got.ToSHA)
+	}
+
+	for _, args := range [][]string{
+		{"-to-sha=bbb", "-uncommitted"},
+		{"-to-sha=bbb", "-out=saved.tfplan"},
+	} {
+		_, diags := ParsePlan(args)
+		if got, want := diags.Err().Error(), "Incompatible command line options"; !strings.Contains(got, want) {
+			t.Errorf("wrong diags for %q\n got: %s\nwant: %s", args, got, want)
+		}
+	}
+}
+
+func TestParsePlan_tagPolicy(t *testing.T) {
+	got, diags := ParsePlan([]string{"-tag-policy=tags.hcl"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.TagPolicy != "tags.hcl" {
+		t.Errorf("wrong tag policy %q", got.TagPolicy)
+	}
+}
+
+func TestParsePlan_groupBy(t *testing.T) {
+	got, diags := ParsePlan([]string{"-group-by=module"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.GroupBy != "module" {
+		t.Errorf("wrong group by %q", got.GroupBy)
+	}
+
+	_, diags = ParsePlan([]string{"-group-by=region"})
+	if got, want := diags.Err().Error(), "Invalid value for -group-by"; !strings.Contains(got, want) {
+		t.Errorf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParsePlan_runtime(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		runtime plans.Runtime
+		compare bool
+	}{
+		"default": {
+			nil,
+			"",
+			false,
+		},
+		"legacy": {
+			[]string{"-runtime=legacy"},
+			plans.LegacyRuntime,
+			false,
+		},
+		"experimental": {
+			[]string{"-runtime=experimental"},
+			plans.ExperimentalRuntime,
+			false,
+		},
+		"compare": {
+			[]string{"-runtime=compare"},
+			plans.LegacyRuntime,
+			true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				t.Fatalf("unexpected diags: %v", diags)
+			}
+			if got.Operation.Runtime != tc.runtime || got.Operation.CompareRuntimes != tc.compare {
+				t.Errorf("wrong result %q, %t; want %q, %t", got.Operation.Runtime, got.Operation.CompareRuntimes, tc.runtime, tc.compare)
+			}
+		})
+	}
+
+	_, diags := ParsePlan([]string{"-runtime=new"})
+	if got, want := diags.Err().Error(), "Invalid runtime"; !strings.Contains(got, want) {
+		t.Errorf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParsePlan_refreshScope(t *testing.T) {
+	got, diags := ParsePlan([]string{"-refresh-scope=provider=aws", "-refresh-scope=provider=hashicorp/google"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if diff := cmp.Diff([]string{"aws", "hashicorp/google"}, got.Operation.RefreshProviders); diff != "" {
+		t.Errorf("wrong refresh providers\n%s", diff)
+	}
+
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"no kind": {
+			[]string{"-refresh-scope=aws"},
+			"Invalid refresh scope",
+		},
+		"wrong kind": {
+			[]string{"-refresh-scope=module=network"},
+			"Invalid refresh scope",
+		},
+		"no name": {
+			[]string{"-refresh-scope=provider="},
+			"Invalid refresh scope",
+		},
+		"refresh disabled": {
+			[]string{"-refresh=false", "-refresh-scope=provider=aws"},
+			"Incompatible refresh options",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParsePlan(tc.args)
+			if !diags.HasErrors() {
+				t.Fatal("expected errors but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Errorf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestParsePlan_tooManyArguments(t *testing.T) {
 	got, diags := ParsePlan([]string{"saved.tfplan"})
 	if len(diags) == 0 {
@@ -162,3 +333,96 @@ func TestParsePlan_vars(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePlan_operationTimeout(t *testing.T) {
+	testCases := map[string]struct {
+		args      []string
+		wantLimit time.Duration
+		wantGrace time.Duration
+		wantErr   string
+	}{
+		"no limit by default": {
+			args:      nil,
+			wantLimit: 0,
+			wantGrace: DefaultOperationTimeoutGrace,
+		},
+		"limit only": {
+			args:      []string{"-operation-timeout=10m"},
+			wantLimit: 10 * time.Minute,
+			wantGrace: DefaultOperationTimeoutGrace,
+		},
+		"limit and grace": {
+			args:      []string{"-operation-timeout=1h", "-operation-timeout-grace=2m"},
+			wantLimit: time.Hour,
+			wantGrace: 2 * time.Minute,
+		},
+		"negative limit": {
+			args:      []string{"-operation-timeout=-1s"},
+			wantLimit: -time.Second,
+			wantGrace: DefaultOperationTimeoutGrace,
+			wantErr:   "Invalid operation timeout",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if tc.wantErr == "" && len(diags) > 0 {
+				t.Fatalf("unexpected diags: %v", diags)
+			}
+			if tc.wantErr != "" {
+				if len(diags) == 0 {
+					t.Fatalf("expected diags but got none")
+				}
+				if got, want := diags.Err().Error(), tc.wantErr; !strings.Contains(got, want) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+				}
+			}
+			if got.Operation.Timeout != tc.wantLimit {
+				t.Errorf("wrong timeout %s; want %s", got.Operation.Timeout, tc.wantLimit)
+			}
+			if got.Operation.TimeoutGrace != tc.wantGrace {
+				t.Errorf("wrong grace period %s; want %s", got.Operation.TimeoutGrace, tc.wantGrace)
+			}
+		})
+	}
+}
+
+func TestParsePlan_dataCache(t *testing.T) {
+	got, diags := ParsePlan(nil)
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.Operation.DataCacheTTL != 0 || got.Operation.DataCacheBypass {
+		t.Errorf("wrong defaults: TTL %s, bypass %t", got.Operation.DataCacheTTL, got.Operation.DataCacheBypass)
+	}
+
+	got, diags = ParsePlan([]string{"-data-cache-ttl=1h", "-data-cache=false"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if got.Operation.DataCacheTTL != time.Hour || !got.Operation.DataCacheBypass {
+		t.Errorf("wrong result: TTL %s, bypass %t", got.Operation.DataCacheTTL, got.Operation.DataCacheBypass)
+	}
+
+	_, diags = ParsePlan([]string{"-data-cache-ttl=-1m"})
+	if got, want := diags.Err().Error(), "Invalid data cache TTL"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParsePlan_limits(t *testing.T) {
+	got, diags := ParsePlan([]string{"-max-destroy=0", "-max-create=100", "-allow-exceeding-limits"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	want := &PlanLimits{MaxCreate: 100, MaxUpdate: -1, MaxDestroy: 0, AllowExceeding: true}
+	if diff := cmp.Diff(want, got.Limits); diff != "" {
+		t.Errorf("unexpected limits\n%s", diff)
+	}
+
+	_, diags = ParsePlan([]string{"-max-update=-5"})
+	if got, want := diags.Err().Error(), "The -max-update option must be zero or more, but is -5."; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -28,6 +28,20 @@ type Show struct {
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// GroupBy selects how a human-readable plan groups the resource
+	// changes, like the option of the same name of the plan command.
+	GroupBy string
+
+	// FormatVersion is the version of the JSON plan or state format to
+	// produce, or empty for the latest one.
+	FormatVersion string
+
+	// Redact requests a JSON plan with its values redacted, to share it
+	// outside of the organization. RedactPolicy is the path of a file with
+	// the redaction policy to apply, or empty for the default policy.
+	Redact       bool
+	RedactPolicy string
 }
 
 // ShowTargetType represents the type of object that is requested to be
@@ -52,9 +66,11 @@ const (
 	// For this target type, [Show.TargetArg] is the plan file to load.
 	ShowPlan
 
-	// ShowConfig represents a request to show the current configuration.
+	// ShowConfig represents a request to show the current configuration,
+	// or the configuration at a commit in the git history.
 	//
-	// This target type does not use [Show.TargetArg].
+	// For this target type, [Show.TargetArg] is the commit whose
+	// configuration to show, or empty for the current configuration.
 	ShowConfig
 
 	// ShowModule represents a request to show just one module in isolation,
@@ -63,6 +79,14 @@ const (
 	// For this target type, [Show.TargetArg] is a path to the directory
 	// containing the module.
 	ShowModule
+
+	// ShowOverrides represents a request to show which arguments of the
+	// blocks of the root module come from override files, in the current
+	// configuration or at a commit in the git history.
+	//
+	// For this target type, [Show.TargetArg] is the commit whose
+	// configuration to show, or empty for the current configuration.
+	ShowOverrides
 )
 
 // ParseShow processes CLI arguments, returning a Show value and errors.
@@ -78,14 +102,22 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 	var stateTarget bool
 	var planTarget string
 	var configTarget bool
+	var overridesTarget bool
+	var atSHA string
 	var moduleTarget string
 	cmdFlags := extendedFlagSet("show", nil, nil, show.Vars)
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.BoolVar(&show.ShowSensitive, "show-sensitive", false, "displays sensitive values")
+	cmdFlags.StringVar(&show.GroupBy, "group-by", "", "group-by")
 	cmdFlags.BoolVar(&stateTarget, "state", false, "show the latest state snapshot")
 	cmdFlags.StringVar(&planTarget, "plan", "", "show the plan from a saved plan file")
 	cmdFlags.BoolVar(&configTarget, "config", false, "show the current configuration")
+	cmdFlags.BoolVar(&overridesTarget, "overrides", false, "show the sources of overridden arguments")
+	cmdFlags.StringVar(&atSHA, "at-sha", "", "show the configuration at a commit")
 	cmdFlags.StringVar(&moduleTarget, "module", "", "show metadata about one module")
+	cmdFlags.StringVar(&show.FormatVersion, "format-version", "", "version of the JSON format")
+	cmdFlags.BoolVar(&show.Redact, "redact", false, "redact the values of the plan")
+	cmdFlags.StringVar(&show.RedactPolicy, "redact-policy", "", "file with the redaction policy")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -94,6 +126,7 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 			err.Error(),
 		))
 	}
+	diags = diags.Append(validateGroupBy(show.GroupBy))
 
 	// If -config or -module=... is selected, -json is required
 	if configTarget && !jsonOutput {
@@ -104,6 +137,14 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 		))
 		return show, diags
 	}
+	if atSHA != "" && !configTarget && !overridesTarget {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Commit not supported",
+			"The -at-sha option can only be used when showing the configuration, with -config or -overrides.",
+		))
+		return show, diags
+	}
 	if moduleTarget != "" && !jsonOutput {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -113,6 +154,43 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 		return show, diags
 	}
 
+	if show.FormatVersion != "" && !jsonOutput {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"JSON output required for format version",
+			"The -format-version option requires -json to be specified.",
+		))
+		return show, diags
+	}
+	if show.FormatVersion != "" && (configTarget || overridesTarget || moduleTarget != "") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Format version not supported",
+			"The -format-version option can only be used when showing a plan or state.",
+		))
+		return show, diags
+	}
+
+	if show.RedactPolicy != "" {
+		show.Redact = true
+	}
+	if show.Redact && !jsonOutput {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"JSON output required for redaction",
+			"The -redact option requires -json to be specified.",
+		))
+		return show, diags
+	}
+	if show.Redact && (stateTarget || configTarget || overridesTarget || moduleTarget != "") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Redaction not supported",
+			"The -redact option can only be used when showing a plan.",
+		))
+		return show, diags
+	}
+
 	switch {
 	case jsonOutput:
 		show.ViewType = ViewJSON
@@ -120,7 +198,7 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 		show.ViewType = ViewHuman
 	}
 
-	if planTarget == "" && moduleTarget == "" && !stateTarget && !configTarget {
+	if planTarget == "" && moduleTarget == "" && !stateTarget && !configTarget && !overridesTarget {
 		// If none of the target type options was provided then we're
 		// in the legacy mode where the target type is implied by
 		// the number of arguments.
@@ -170,18 +248,23 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 	if configTarget {
 		targetTypes++
 		show.TargetType = ShowConfig
-		show.TargetArg = ""
+		show.TargetArg = atSHA
 	}
 	if moduleTarget != "" {
 		targetTypes++
 		show.TargetType = ShowModule
 		show.TargetArg = moduleTarget
 	}
+	if overridesTarget {
+		targetTypes++
+		show.TargetType = ShowOverrides
+		show.TargetArg = atSHA
+	}
 	if targetTypes != 1 {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Conflicting object types to show",
-			"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+			"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
 		))
 	}
 	return show, diags
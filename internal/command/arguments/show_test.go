@@ -68,6 +68,34 @@ func TestParseShow_valid(t *testing.T) {
 				ViewType:   ViewJSON,
 			},
 		},
+		"saved plan file, JSON, previous format version": {
+			[]string{"-plan=tfplan", "-json", "-format-version=1.2"},
+			&Show{
+				TargetType:    ShowPlan,
+				TargetArg:     "tfplan",
+				ViewType:      ViewJSON,
+				FormatVersion: "1.2",
+			},
+		},
+		"saved plan file, JSON, redacted": {
+			[]string{"-plan=tfplan", "-json", "-redact"},
+			&Show{
+				TargetType: ShowPlan,
+				TargetArg:  "tfplan",
+				ViewType:   ViewJSON,
+				Redact:     true,
+			},
+		},
+		"saved plan file, JSON, redaction policy": {
+			[]string{"-plan=tfplan", "-json", "-redact-policy=policy.json"},
+			&Show{
+				TargetType:   ShowPlan,
+				TargetArg:    "tfplan",
+				ViewType:     ViewJSON,
+				Redact:       true,
+				RedactPolicy: "policy.json",
+			},
+		},
 		"legacy positional argument": {
 			[]string{"foo"},
 			&Show{
@@ -92,6 +120,14 @@ func TestParseShow_valid(t *testing.T) {
 				ViewType:   ViewJSON,
 			},
 		},
+		"configuration at a commit": {
+			[]string{"-config", "-at-sha=abc123", "-json"},
+			&Show{
+				TargetType: ShowConfig,
+				TargetArg:  "abc123",
+				ViewType:   ViewJSON,
+			},
+		},
 		"module with json": {
 			[]string{"-module=foo", "-json"},
 			&Show{
@@ -100,6 +136,22 @@ func TestParseShow_valid(t *testing.T) {
 				ViewType:   ViewJSON,
 			},
 		},
+		"overrides": {
+			[]string{"-overrides"},
+			&Show{
+				TargetType: ShowOverrides,
+				TargetArg:  "",
+				ViewType:   ViewHuman,
+			},
+		},
+		"overrides at a commit, JSON": {
+			[]string{"-overrides", "-at-sha=abc123", "-json"},
+			&Show{
+				TargetType: ShowOverrides,
+				TargetArg:  "abc123",
+				ViewType:   ViewJSON,
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -173,7 +225,7 @@ func TestParseShow_invalid(t *testing.T) {
 				tfdiags.Sourceless(
 					tfdiags.Error,
 					"Conflicting object types to show",
-					"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+					"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
 				),
 			},
 		},
@@ -227,7 +279,7 @@ func TestParseShow_invalid(t *testing.T) {
 				tfdiags.Sourceless(
 					tfdiags.Error,
 					"Conflicting object types to show",
-					"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+					"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
 				),
 			},
 		},
@@ -242,7 +294,76 @@ func TestParseShow_invalid(t *testing.T) {
 				tfdiags.Sourceless(
 					tfdiags.Error,
 					"Conflicting object types to show",
-					"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+					"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+				),
+			},
+		},
+		"commit without configuration": {
+			[]string{"-state", "-at-sha=abc123", "-json"},
+			&Show{
+				ViewType: ViewNone,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Commit not supported",
+					"The -at-sha option can only be used when showing the configuration, with -config or -overrides.",
+				),
+			},
+		},
+		"format version without json": {
+			[]string{"-plan=tfplan", "-format-version=1.2"},
+			&Show{
+				ViewType:      ViewNone,
+				FormatVersion: "1.2",
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"JSON output required for format version",
+					"The -format-version option requires -json to be specified.",
+				),
+			},
+		},
+		"format version with configuration": {
+			[]string{"-config", "-json", "-format-version=1.2"},
+			&Show{
+				ViewType:      ViewNone,
+				FormatVersion: "1.2",
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Format version not supported",
+					"The -format-version option can only be used when showing a plan or state.",
+				),
+			},
+		},
+		"redact without json": {
+			[]string{"-plan=tfplan", "-redact"},
+			&Show{
+				ViewType: ViewNone,
+				Redact:   true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"JSON output required for redaction",
+					"The -redact option requires -json to be specified.",
+				),
+			},
+		},
+		"redact with state": {
+			[]string{"-state", "-json", "-redact"},
+			&Show{
+				ViewType: ViewNone,
+				Redact:   true,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Redaction not supported",
+					"The -redact option can only be used when showing a plan.",
 				),
 			},
 		},
@@ -270,7 +391,7 @@ func TestParseShow_invalid(t *testing.T) {
 				tfdiags.Sourceless(
 					tfdiags.Error,
 					"Conflicting object types to show",
-					"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+					"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
 				),
 			},
 		},
@@ -285,7 +406,7 @@ func TestParseShow_invalid(t *testing.T) {
 				tfdiags.Sourceless(
 					tfdiags.Error,
 					"Conflicting object types to show",
-					"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+					"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
 				),
 			},
 		},
@@ -300,7 +421,7 @@ func TestParseShow_invalid(t *testing.T) {
 				tfdiags.Sourceless(
 					tfdiags.Error,
 					"Conflicting object types to show",
-					"The -state, -plan=FILENAME, -config, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
+					"The -state, -plan=FILENAME, -config, -overrides, and -module=DIR options are mutually-exclusive, to specify which kind of object to show.",
 				),
 			},
 		},
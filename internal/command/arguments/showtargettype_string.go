@@ -18,11 +18,12 @@ func _() {
 	_ = x[ShowPlan-2]
 	_ = x[ShowConfig-3]
 	_ = x[ShowModule-4]
+	_ = x[ShowOverrides-5]
 }
 
-const _ShowTargetType_name = "ShowUnknownTypeShowStateShowPlanShowConfigShowModule"
+const _ShowTargetType_name = "ShowUnknownTypeShowStateShowPlanShowConfigShowModuleShowOverrides"
 
-var _ShowTargetType_index = [...]uint8{0, 15, 24, 32, 42, 52}
+var _ShowTargetType_index = [...]uint8{0, 15, 24, 32, 42, 52, 65}
 
 func (i ShowTargetType) String() string {
 	idx := int(i) - 0
@@ -35,6 +35,15 @@ type View struct {
 
 	// ShowSensitive is used to display the value of variables marked as sensitive.
 	ShowSensitive bool
+
+	// SuppressWarnings lists the codes or summaries of warnings to leave out
+	// of the output, from repeated -suppress-warning options.
+	SuppressWarnings []string
+
+	// JSONProtocol is the version of the machine-readable UI protocol given
+	// with the -json-protocol option, or empty for the latest version. The
+	// view checks that it's a supported version.
+	JSONProtocol string
 }
 
 // ParseView processes CLI arguments, returning a View value and a
@@ -53,6 +62,14 @@ func ParseView(args []string) (*View, []string) {
 			common.ModuleDeprecationWarnLvl = farseek.ParseDeprecatedWarningLevel(strings.ReplaceAll(v, prefix, ""))
 			continue // continue to ensure that the counter is not incremented
 		}
+		if prefix := "-suppress-warning="; strings.HasPrefix(v, prefix) {
+			common.SuppressWarnings = append(common.SuppressWarnings, strings.TrimPrefix(v, prefix))
+			continue
+		}
+		if prefix := "-json-protocol="; strings.HasPrefix(v, prefix) {
+			common.JSONProtocol = strings.TrimPrefix(v, prefix)
+			continue
+		}
 		switch v {
 		case "-no-color":
 			common.NoColor = true
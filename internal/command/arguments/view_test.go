@@ -75,6 +75,16 @@ func TestParseView(t *testing.T) {
 			&View{NoColor: true, CompactWarnings: true, ConsolidateWarnings: true, Concise: true},
 			[]string{},
 		},
+		"suppress-warning": {
+			[]string{"-foo", "-suppress-warning=FS0101", "-suppress-warning=Argument is deprecated", "-baz"},
+			&View{ConsolidateWarnings: true, SuppressWarnings: []string{"FS0101", "Argument is deprecated"}},
+			[]string{"-foo", "-baz"},
+		},
+		"json-protocol": {
+			[]string{"-json", "-json-protocol=1"},
+			&View{ConsolidateWarnings: true, JSONProtocol: "1"},
+			[]string{"-json"},
+		},
 		"turn off warning consolidation": {
 			[]string{"-consolidate-warnings=false"},
 			&View{NoColor: false, CompactWarnings: false, ConsolidateWarnings: false, Concise: false},
@@ -104,8 +114,8 @@ func TestParseView(t *testing.T) {
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			got, gotArgs := ParseView(tc.args)
-			if *got != *tc.want {
-				t.Errorf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected result\n%s", diff)
 			}
 			if !cmp.Equal(gotArgs, tc.wantArgs) {
 				t.Errorf("unexpected args\n got: %#v\nwant: %#v", gotArgs, tc.wantArgs)
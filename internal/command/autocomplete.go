@@ -9,8 +9,13 @@ package command
 
 import (
 	"context"
+	"io"
+	"os"
+	"sort"
 
 	"github.com/posener/complete"
+
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 )
 
 // This file contains some re-usable predictors for auto-complete. The
@@ -21,6 +26,10 @@ import (
 // For completing the value of boolean flags like -foo false
 var completePredictBoolean = complete.PredictSet("true", "false")
 
+// completePredictInput predicts the values of the -input option, which also
+// accepts "strict".
+var completePredictInput = complete.PredictSet("true", "false", "strict")
+
 // We don't currently have a real predictor for module sources, but
 // we'll probably add one later.
 var completePredictModuleSource = complete.PredictAnything
@@ -37,42 +46,165 @@ func (s completePredictSequence) Predict(a complete.Args) []string {
 	return s[idx].Predict(a)
 }
 
+// CompletionProvider looks up the values that shell completion offers for
+// arguments naming objects that only exist at runtime, such as workspaces.
+//
+// Errors are never shown to the user, because there is nowhere to show them
+// while the shell is completing a command line; an implementation that
+// fails should just return an error and let completion offer nothing.
+type CompletionProvider interface {
+	// WorkspaceNames returns the names of the workspaces of the backend
+	// configured for the current working directory.
+	WorkspaceNames(ctx context.Context) ([]string, error)
+
+	// ResourceAddresses returns the addresses of the resources declared in
+	// the configuration in the current working directory.
+	ResourceAddresses(ctx context.Context) ([]string, error)
+
+	// PlanFiles returns the saved plan files, and the directories that
+	// might contain more of them, whose paths start with the given prefix.
+	PlanFiles(ctx context.Context, prefix string) ([]string, error)
+}
+
+func (m *Meta) completionProvider() CompletionProvider {
+	if m.CompletionProvider != nil {
+		return m.CompletionProvider
+	}
+	return metaCompletionProvider{m}
+}
+
 func (m *Meta) completePredictWorkspaceName(ctx context.Context) complete.Predictor {
 	return complete.PredictFunc(func(a complete.Args) []string {
-		// There are lot of things that can fail in here, so if we encounter
-		// any error then we'll just return nothing and not support autocomplete
-		// until whatever error is fixed. (The user can't actually see the error
-		// here, but other commands should produce a user-visible error before
-		// too long.)
-
-		// We assume here that we want to autocomplete for the current working
-		// directory, since we don't have enough context to know where to
-		// find any config path argument, and it might be _after_ the argument
-		// we're trying to complete here anyway.
-		configPath, err := modulePath(nil)
-		if err != nil {
-			return nil
-		}
+		names, _ := m.completionProvider().WorkspaceNames(ctx)
+		return names
+	})
+}
 
-		backendConfig, diags := m.loadBackendConfig(ctx, configPath)
-		if diags.HasErrors() {
-			return nil
-		}
+func (m *Meta) completePredictResourceAddress(ctx context.Context) complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		addrs, _ := m.completionProvider().ResourceAddresses(ctx)
+		return addrs
+	})
+}
 
-		// Load the encryption configuration
-		enc, encDiags := m.Encryption(ctx)
-		if encDiags.HasErrors() {
-			return nil
-		}
+func (m *Meta) completePredictPlanFile(ctx context.Context) complete.Predictor {
+	return complete.PredictFunc(func(a complete.Args) []string {
+		paths, _ := m.completionProvider().PlanFiles(ctx, a.Last)
+		return paths
+	})
+}
+
+// completeOperationFlags returns the predictors for the flags shared by the
+// plan, apply, and destroy commands. Callers add their own flags to the
+// result.
+func (m *Meta) completeOperationFlags(ctx context.Context) complete.Flags {
+	return complete.Flags{
+		"-agent":                   complete.PredictAnything,
+		"-compact-warnings":        complete.PredictNothing,
+		"-data-cache":              completePredictBoolean,
+		"-data-cache-ttl":          complete.PredictAnything,
+		"-destroy":                 complete.PredictNothing,
+		"-input":                   completePredictInput,
+		"-json":                    complete.PredictNothing,
+		"-json-protocol":           complete.PredictSet("1", "2"),
+		"-lock":                    completePredictBoolean,
+		"-lock-timeout":            complete.PredictAnything,
+		"-no-color":                complete.PredictNothing,
+		"-operation-timeout":       complete.PredictAnything,
+		"-operation-timeout-grace": complete.PredictAnything,
+		"-parallelism":             complete.PredictAnything,
+		"-refresh":                 completePredictBoolean,
+		"-refresh-only":            complete.PredictNothing,
+		"-refresh-scope":           complete.PredictAnything,
+		"-replace":                 m.completePredictResourceAddress(ctx),
+		"-show-sensitive":          complete.PredictNothing,
+		"-uncommitted":             complete.PredictNothing,
+		"-var":                     complete.PredictAnything,
+		"-var-file":                complete.PredictFiles("*.tfvars"),
+	}
+}
 
-		b, diags := m.Backend(ctx, &BackendOpts{
-			Config: backendConfig,
-		}, enc.State())
-		if diags.HasErrors() {
-			return nil
+// metaCompletionProvider is the default CompletionProvider, which looks
+// everything up in the current working directory.
+type metaCompletionProvider struct {
+	m *Meta
+}
+
+func (p metaCompletionProvider) WorkspaceNames(ctx context.Context) ([]string, error) {
+	// We assume here that we want to autocomplete for the current working
+	// directory, since we don't have enough context to know where to
+	// find any config path argument, and it might be _after_ the argument
+	// we're trying to complete here anyway.
+	configPath, err := modulePath(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	backendConfig, diags := p.m.loadBackendConfig(ctx, configPath)
+	if diags.HasErrors() {
+		return nil, diags.Err()
+	}
+
+	// Load the encryption configuration
+	enc, encDiags := p.m.Encryption(ctx)
+	if encDiags.HasErrors() {
+		return nil, encDiags.Err()
+	}
+
+	b, diags := p.m.Backend(ctx, &BackendOpts{
+		Config: backendConfig,
+	}, enc.State())
+	if diags.HasErrors() {
+		return nil, diags.Err()
+	}
+
+	return b.Workspaces(ctx)
+}
+
+func (p metaCompletionProvider) ResourceAddresses(_ context.Context) ([]string, error) {
+	// Discovery of the working directory includes uncommitted changes,
+	// since those are what the user is most likely to be working on.
+	discovered, err := farseek.Discovery.DiscoverAllResources(p.m.discoveryDir(), true)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(discovered))
+	for _, dr := range discovered {
+		addrs = append(addrs, dr.Address)
+	}
+	sort.Strings(addrs)
+	return addrs, nil
+}
+
+func (p metaCompletionProvider) PlanFiles(_ context.Context, prefix string) ([]string, error) {
+	var paths []string
+	for _, path := range complete.PredictFiles("*").Predict(complete.Args{Last: prefix}) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		// Directories are kept so that the user can complete paths to plan
+		// files inside them.
+		if info.IsDir() || looksLikePlanFile(path) {
+			paths = append(paths, path)
 		}
+	}
+	return paths, nil
+}
 
-		names, _ := b.Workspaces(ctx)
-		return names
-	})
+// looksLikePlanFile returns true if the file at the given path starts with
+// the signature of a zip archive, which is the container format of saved
+// plan files. This is much cheaper than opening every candidate as a plan.
+func looksLikePlanFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var sig [4]byte
+	if _, err := io.ReadFull(f, sig[:]); err != nil {
+		return false
+	}
+	return string(sig[:]) == "PK\x03\x04"
 }
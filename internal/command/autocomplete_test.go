@@ -8,8 +8,11 @@
 package command
 
 import (
+	"context"
+	"errors"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/mitchellh/cli"
@@ -40,3 +43,85 @@ func TestMetaCompletePredictWorkspaceName(t *testing.T) {
 		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
 	}
 }
+
+type testCompletionProvider struct {
+	workspaces []string
+	addrs      []string
+}
+
+func (p testCompletionProvider) WorkspaceNames(context.Context) ([]string, error) {
+	return p.workspaces, nil
+}
+
+func (p testCompletionProvider) ResourceAddresses(context.Context) ([]string, error) {
+	return p.addrs, nil
+}
+
+func (p testCompletionProvider) PlanFiles(context.Context, string) ([]string, error) {
+	return nil, errors.New("no plan files")
+}
+
+func TestMetaCompletionProvider(t *testing.T) {
+	meta := &Meta{
+		CompletionProvider: testCompletionProvider{
+			workspaces: []string{"default", "prod"},
+			addrs:      []string{"test_instance.a", "test_instance.b"},
+		},
+	}
+	ctx := t.Context()
+
+	if got, want := meta.completePredictWorkspaceName(ctx).Predict(complete.Args{}), []string{"default", "prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong workspaces\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := meta.completePredictResourceAddress(ctx).Predict(complete.Args{}), []string{"test_instance.a", "test_instance.b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong resource addresses\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := meta.completePredictPlanFile(ctx).Predict(complete.Args{}); len(got) != 0 {
+		t.Errorf("unexpected plan files: %#v", got)
+	}
+}
+
+func TestMetaCompletePredictResourceAddress(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	config := `
+resource "test_instance" "b" {}
+resource "test_instance" "a" {}
+data "test_data_source" "c" {}
+`
+	if err := os.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := &Meta{Ui: new(cli.MockUi)}
+	got := meta.completePredictResourceAddress(t.Context()).Predict(complete.Args{})
+	want := []string{"data.test_data_source.c", "test_instance.a", "test_instance.b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestMetaCompletePredictPlanFile(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	// Only the signature matters to the predictor.
+	if err := os.WriteFile("saved.tfplan", []byte("PK\x03\x04rest of the archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("main.tf", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir("plans", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := &Meta{Ui: new(cli.MockUi)}
+	got := meta.completePredictPlanFile(t.Context()).Predict(complete.Args{})
+	sort.Strings(got)
+	want := []string{"./", "plans/", "saved.tfplan"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
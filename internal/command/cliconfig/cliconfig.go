@@ -13,14 +13,17 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl"
 	"github.com/opentofu/svchost"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -52,6 +55,68 @@ type Config struct {
 	Credentials        map[string]map[string]interface{}   `hcl:"credentials"`
 	CredentialsHelpers map[string]*ConfigCredentialsHelper `hcl:"credentials_helper"`
 
+	// ProviderCredentialsHelpers maps provider source addresses, from the
+	// "provider_credentials_helper" block labels, to the programs that
+	// supply short-lived credentials to those providers.
+	ProviderCredentialsHelpers map[string]*ConfigProviderCredentialsHelper `hcl:"provider_credentials_helper"`
+
+	// Aliases maps the names of user-defined commands, from the "alias"
+	// block, to the command line arguments each one stands for.
+	Aliases map[string]string `hcl:"alias"`
+
+	// SuppressWarnings lists the codes or summaries of warnings to leave
+	// out of the output of every command.
+	SuppressWarnings []string `hcl:"suppress_warnings"`
+
+	// RequireSignedCommits requires every apply in stateless mode to check
+	// that the commits it applies are signed by trusted keys.
+	RequireSignedCommits bool `hcl:"require_signed_commits"`
+
+	// TrustedSigningKeys are the fingerprints or IDs of the keys that can
+	// sign the commits to apply. If empty, any key that git trusts can.
+	TrustedSigningKeys []string `hcl:"trusted_signing_keys"`
+
+	// StrictProviderDevOverrides makes plans and applies fail while provider
+	// development overrides are in effect, unless -allow-dev-overrides is
+	// set.
+	StrictProviderDevOverrides bool `hcl:"strict_provider_dev_overrides"`
+
+	// ApplyBranches are patterns of the names of the branches that applies
+	// in stateless mode can run from. If empty, they can run from any
+	// branch, and with HEAD detached.
+	ApplyBranches []string `hcl:"apply_branches"`
+
+	// TagPolicy is the path of the tag policy file that plans and applies
+	// check the resources they create or update against, unless the
+	// -tag-policy option selects another one.
+	TagPolicy string `hcl:"tag_policy"`
+
+	// ModuleDeprecations is the path of a file marking module versions as
+	// deprecated, in addition to those that module registries mark, which
+	// init and plan warn about.
+	ModuleDeprecations string `hcl:"module_deprecations"`
+
+	// PlanLimits are the "plan_limits" blocks, with the largest numbers of
+	// objects that plans and applies may create, update and destroy. When
+	// several files set the same limit, the last one wins.
+	PlanLimits []*ConfigPlanLimits `hcl:"-"`
+
+	// DefaultTags maps provider source addresses, from the "default_tags"
+	// block labels, to the tags that plans and applies inject into every
+	// resource of those providers.
+	DefaultTags map[string]*ConfigDefaultTags `hcl:"default_tags"`
+
+	// RunTasks maps the names of run tasks, from the "run_task" block
+	// labels, to the external services that check the configuration before
+	// plans and the plan before applies.
+	RunTasks map[string]*ConfigRunTask `hcl:"run_task"`
+
+	// Hooks are the "hooks" blocks, which configure external programs to
+	// run before and after each resource change that apply makes. These
+	// are decoded separately, because HCL 1's decoder can't represent their
+	// nested blocks.
+	Hooks []*ConfigHooks `hcl:"-"`
+
 	// RegistryProtocols contains some settings for tailoring the request
 	// timeout and retry count for metadata requests made by our registry
 	// protocol clients.
@@ -61,6 +126,11 @@ type Config struct {
 	// environment then we use some reasonable default settings.
 	RegistryProtocols *RegistryProtocolsConfig
 
+	// ProviderGRPC contains settings for the gRPC connections to the
+	// provider plugins that Farseek starts, from the "provider_grpc" block
+	// and the environment. It's nil if neither sets anything.
+	ProviderGRPC *ProviderGRPCConfig
+
 	// ProviderInstallation represents any provider_installation blocks
 	// in the configuration. Only one of these is allowed across the whole
 	// configuration, but we decode into a slice here so that we can handle
@@ -91,6 +161,36 @@ type ConfigCredentialsHelper struct {
 	Args []string `hcl:"args"`
 }
 
+// ConfigProviderCredentialsHelper is the structure of the
+// "provider_credentials_helper" nested block within the CLI configuration.
+type ConfigProviderCredentialsHelper struct {
+	Command string   `hcl:"command"`
+	Args    []string `hcl:"args"`
+}
+
+// ConfigDefaultTags is the structure of the "default_tags" nested block
+// within the CLI configuration.
+type ConfigDefaultTags struct {
+	Attribute string            `hcl:"attribute"`
+	Tags      map[string]string `hcl:"tags"`
+}
+
+// ConfigRunTask is the structure of the "run_task" nested block within the
+// CLI configuration.
+type ConfigRunTask struct {
+	URL string `hcl:"url"`
+
+	// Stage is either "post_plan", the default, or "pre_plan".
+	Stage string `hcl:"stage"`
+
+	// EnforcementLevel is either "mandatory", the default, or "advisory".
+	EnforcementLevel string `hcl:"enforcement_level"`
+
+	// Timeout is how long the service may take to respond, as a duration
+	// string such as "30s". If empty, a default applies.
+	Timeout string `hcl:"timeout"`
+}
+
 // BuiltinConfig is the built-in defaults for the configuration. These
 // can be overridden by user configurations.
 var BuiltinConfig Config
@@ -222,12 +322,21 @@ func (cl *ConfigLoader) loadConfigFile(path string) (*Config, tfdiags.Diagnostic
 	registryProtocolsConfig, registryProtocolsDiags := decodeRegistryProtocolsConfigFromConfig(obj)
 	diags = diags.Append(registryProtocolsDiags)
 	result.RegistryProtocols = registryProtocolsConfig
+	providerGRPCConfig, providerGRPCDiags := decodeProviderGRPCConfigFromConfig(obj)
+	diags = diags.Append(providerGRPCDiags)
+	result.ProviderGRPC = providerGRPCConfig
 	providerInstBlocks, providerInstDiags := decodeProviderInstallationFromConfig(obj)
 	diags = diags.Append(providerInstDiags)
 	result.ProviderInstallation = providerInstBlocks
 	ociDefaultCredsBlocks, ociDefaultCredsDiags := decodeOCIDefaultCredentialsFromConfig(obj, path)
 	diags = diags.Append(ociDefaultCredsDiags)
 	result.OCIDefaultCredentials = ociDefaultCredsBlocks
+	hooksBlocks, hooksDiags := decodeHooksFromConfig(obj)
+	diags = diags.Append(hooksDiags)
+	result.Hooks = hooksBlocks
+	planLimitsBlocks, planLimitsDiags := decodePlanLimitsFromConfig(obj)
+	diags = diags.Append(planLimitsDiags)
+	result.PlanLimits = planLimitsBlocks
 	ociCredsBlocks, ociCredsDiags := decodeOCIRepositoryCredentialsFromConfig(obj)
 	diags = diags.Append(ociCredsDiags)
 	result.OCIRepositoryCredentials = ociCredsBlocks
@@ -301,6 +410,7 @@ func envConfig(env map[string]string) *Config {
 	// protocols, because we include the default values in here if the
 	// relevant environment variables aren't set.
 	config.RegistryProtocols = decodeRegistryProtocolsConfigFromEnvironment()
+	config.ProviderGRPC = decodeProviderGRPCConfigFromEnvironment(env)
 
 	return config
 }
@@ -358,6 +468,20 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		}
 	}
 
+	// Check that all aliases are single words that stand for something.
+	for name, value := range c.Aliases {
+		if name == "" || strings.ContainsAny(name, " \t") || strings.HasPrefix(name, "-") {
+			diags = diags.Append(
+				fmt.Errorf("The alias %q has an invalid name: names must be single words that don't start with a dash", name),
+			)
+		}
+		if strings.TrimSpace(value) == "" {
+			diags = diags.Append(
+				fmt.Errorf("The alias %q must not be empty", name),
+			)
+		}
+	}
+
 	// Should have zero or one "credentials_helper" blocks
 	if len(c.CredentialsHelpers) > 1 {
 		diags = diags.Append(
@@ -365,6 +489,99 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		)
 	}
 
+	// Check that all "provider_credentials_helper" blocks are for valid
+	// provider addresses and have a program to run.
+	for givenAddr, helper := range c.ProviderCredentialsHelpers {
+		if _, moreDiags := addrs.ParseProviderSourceString(givenAddr); moreDiags.HasErrors() {
+			diags = diags.Append(
+				fmt.Errorf("The provider_credentials_helper %q block has an invalid provider address: %w", givenAddr, moreDiags.Err()),
+			)
+		}
+		if helper == nil || strings.TrimSpace(helper.Command) == "" {
+			diags = diags.Append(
+				fmt.Errorf("The provider_credentials_helper %q block must set command", givenAddr),
+			)
+		}
+	}
+
+	// Check that all "default_tags" blocks are for valid provider addresses
+	// and have some tags.
+	for givenAddr, defaults := range c.DefaultTags {
+		if _, moreDiags := addrs.ParseProviderSourceString(givenAddr); moreDiags.HasErrors() {
+			diags = diags.Append(
+				fmt.Errorf("The default_tags %q block has an invalid provider address: %w", givenAddr, moreDiags.Err()),
+			)
+		}
+		if defaults == nil || len(defaults.Tags) == 0 {
+			diags = diags.Append(
+				fmt.Errorf("The default_tags %q block must set tags", givenAddr),
+			)
+		}
+	}
+
+	// Check that the limits of all "plan_limits" blocks aren't negative.
+	for _, limits := range c.PlanLimits {
+		if limits == nil {
+			continue
+		}
+		for name, limit := range map[string]*int{"max_create": limits.MaxCreate, "max_update": limits.MaxUpdate, "max_destroy": limits.MaxDestroy} {
+			if limit != nil && *limit < 0 {
+				diags = diags.Append(
+					fmt.Errorf("The plan_limits block has an invalid %s %d: must be zero or more", name, *limit),
+				)
+			}
+		}
+	}
+
+	// Check that all "run_task" blocks have a URL and valid settings.
+	for name, task := range c.RunTasks {
+		if task == nil || strings.TrimSpace(task.URL) == "" {
+			diags = diags.Append(
+				fmt.Errorf("The run_task %q block must set url", name),
+			)
+			continue
+		}
+		if u, err := url.Parse(task.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			diags = diags.Append(
+				fmt.Errorf("The run_task %q block has an invalid url %q: must be an http or https URL", name, task.URL),
+			)
+		}
+		switch task.Stage {
+		case "", "pre_plan", "post_plan":
+		default:
+			diags = diags.Append(
+				fmt.Errorf("The run_task %q block has an invalid stage %q: must be \"pre_plan\" or \"post_plan\"", name, task.Stage),
+			)
+		}
+		switch task.EnforcementLevel {
+		case "", "advisory", "mandatory":
+		default:
+			diags = diags.Append(
+				fmt.Errorf("The run_task %q block has an invalid enforcement_level %q: must be \"advisory\" or \"mandatory\"", name, task.EnforcementLevel),
+			)
+		}
+		if task.Timeout != "" {
+			if d, err := time.ParseDuration(task.Timeout); err != nil || d <= 0 {
+				diags = diags.Append(
+					fmt.Errorf("The run_task %q block has an invalid timeout %q: must be a positive duration such as \"30s\"", name, task.Timeout),
+				)
+			}
+		}
+	}
+
+	// Check that all apply hooks have a program to run and valid settings.
+	for _, hooks := range c.Hooks {
+		if hooks == nil {
+			continue
+		}
+		for _, hook := range hooks.PreApply {
+			diags = diags.Append(hook.validate("pre_apply"))
+		}
+		for _, hook := range hooks.PostApply {
+			diags = diags.Append(hook.validate("post_apply"))
+		}
+	}
+
 	// Should have zero or one "provider_installation" blocks
 	if len(c.ProviderInstallation) > 1 {
 		diags = diags.Append(
@@ -454,7 +671,78 @@ func (c *Config) Merge(c2 *Config) *Config {
 		}
 	}
 
+	if (len(c.ProviderCredentialsHelpers) + len(c2.ProviderCredentialsHelpers)) > 0 {
+		result.ProviderCredentialsHelpers = make(map[string]*ConfigProviderCredentialsHelper)
+		for addr, helper := range c.ProviderCredentialsHelpers {
+			result.ProviderCredentialsHelpers[addr] = helper
+		}
+		for addr, helper := range c2.ProviderCredentialsHelpers {
+			result.ProviderCredentialsHelpers[addr] = helper
+		}
+	}
+
+	if (len(c.SuppressWarnings) + len(c2.SuppressWarnings)) > 0 {
+		result.SuppressWarnings = append(append([]string(nil), c.SuppressWarnings...), c2.SuppressWarnings...)
+	}
+
+	result.RequireSignedCommits = c.RequireSignedCommits || c2.RequireSignedCommits
+	if (len(c.TrustedSigningKeys) + len(c2.TrustedSigningKeys)) > 0 {
+		result.TrustedSigningKeys = append(append([]string(nil), c.TrustedSigningKeys...), c2.TrustedSigningKeys...)
+	}
+	result.StrictProviderDevOverrides = c.StrictProviderDevOverrides || c2.StrictProviderDevOverrides
+	if (len(c.ApplyBranches) + len(c2.ApplyBranches)) > 0 {
+		result.ApplyBranches = append(append([]string(nil), c.ApplyBranches...), c2.ApplyBranches...)
+	}
+	result.TagPolicy = c.TagPolicy
+	if c2.TagPolicy != "" {
+		result.TagPolicy = c2.TagPolicy
+	}
+
+	result.ModuleDeprecations = c.ModuleDeprecations
+	if c2.ModuleDeprecations != "" {
+		result.ModuleDeprecations = c2.ModuleDeprecations
+	}
+
+	if (len(c.PlanLimits) + len(c2.PlanLimits)) > 0 {
+		result.PlanLimits = append(append([]*ConfigPlanLimits(nil), c.PlanLimits...), c2.PlanLimits...)
+	}
+
+	if (len(c.DefaultTags) + len(c2.DefaultTags)) > 0 {
+		result.DefaultTags = make(map[string]*ConfigDefaultTags)
+		for addr, defaults := range c.DefaultTags {
+			result.DefaultTags[addr] = defaults
+		}
+		for addr, defaults := range c2.DefaultTags {
+			result.DefaultTags[addr] = defaults
+		}
+	}
+
+	if (len(c.RunTasks) + len(c2.RunTasks)) > 0 {
+		result.RunTasks = make(map[string]*ConfigRunTask)
+		for name, task := range c.RunTasks {
+			result.RunTasks[name] = task
+		}
+		for name, task := range c2.RunTasks {
+			result.RunTasks[name] = task
+		}
+	}
+
+	if (len(c.Hooks) + len(c2.Hooks)) > 0 {
+		result.Hooks = append(append([]*ConfigHooks(nil), c.Hooks...), c2.Hooks...)
+	}
+
+	if (len(c.Aliases) + len(c2.Aliases)) > 0 {
+		result.Aliases = make(map[string]string)
+		for name, value := range c.Aliases {
+			result.Aliases[name] = value
+		}
+		for name, value := range c2.Aliases {
+			result.Aliases[name] = value
+		}
+	}
+
 	result.RegistryProtocols = mergeRegistryProtocolConfigs(c2.RegistryProtocols, c.RegistryProtocols)
+	result.ProviderGRPC = mergeProviderGRPCConfigs(c2.ProviderGRPC, c.ProviderGRPC)
 
 	if (len(c.ProviderInstallation) + len(c2.ProviderInstallation)) > 0 {
 		result.ProviderInstallation = append(result.ProviderInstallation, c.ProviderInstallation...)
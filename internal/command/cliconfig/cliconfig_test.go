@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -227,6 +228,254 @@ func TestLoadConfig_hosts(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_aliases(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "aliases"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		Aliases: map[string]string{
+			"preview": "plan -concise -compact-warnings",
+			"ship":    "apply -auto-approve",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_suppressWarnings(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "suppress-warnings"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		SuppressWarnings: []string{"FS0101", "Argument is deprecated"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_signedCommits(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "signed-commits"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		RequireSignedCommits: true,
+		TrustedSigningKeys: []string{
+			"3AA5C34371567BD2",
+			"SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_applyBranches(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "apply-branches"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		ApplyBranches: []string{"main", "release/*"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_tagPolicy(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "tag-policy"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		TagPolicy: "/etc/farseek/tags.hcl",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_moduleDeprecations(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "module-deprecations"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		ModuleDeprecations: "/etc/farseek/module-deprecations.hcl",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_strictProviderDevOverrides(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "strict-provider-dev-overrides"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		StrictProviderDevOverrides: true,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_planLimits(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "plan-limits"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	maxCreate, maxDestroy := 100, 0
+	want := &Config{
+		PlanLimits: []*ConfigPlanLimits{
+			{MaxCreate: &maxCreate, MaxDestroy: &maxDestroy},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_defaultTags(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "default-tags"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		DefaultTags: map[string]*ConfigDefaultTags{
+			"hashicorp/aws": {
+				Tags: map[string]string{
+					"environment": "prod",
+					"managed-by":  "farseek",
+				},
+			},
+			"hashicorp/google": {
+				Attribute: "labels",
+				Tags: map[string]string{
+					"environment": "prod",
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_runTasks(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "run-tasks"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		RunTasks: map[string]*ConfigRunTask{
+			"checkov": {
+				URL:     "https://checkov.example.com/scan",
+				Timeout: "2m",
+			},
+			"naming": {
+				URL:              "http://localhost:8080/naming",
+				Stage:            "pre_plan",
+				EnforcementLevel: "advisory",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_providerCredentialsHelpers(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "provider-credentials-helpers"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		ProviderCredentialsHelpers: map[string]*ConfigProviderCredentialsHelper{
+			"hashicorp/aws": {
+				Command: "/usr/local/bin/aws-creds",
+				Args:    []string{"--role", "deploy"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_hooks(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "hooks"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		Hooks: []*ConfigHooks{
+			{
+				PreApply: []*ConfigApplyHook{
+					{
+						ResourceTypes: []string{"aws_*"},
+						Command:       "/usr/local/bin/check-change",
+						Timeout:       "30s",
+					},
+				},
+				PostApply: []*ConfigApplyHook{
+					{
+						Command:   "/usr/local/bin/update-cmdb",
+						Args:      []string{"--env", "prod"},
+						OnFailure: "continue",
+					},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_hooksErrors(t *testing.T) {
+	_, diags := loadConfigFile(filepath.Join(fixtureDir, "hooks-errors"))
+	if got, want := len(diags), 2; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.Err())
+	}
+	if got, want := diags[0].Description().Detail, "must not have any labels"; !strings.Contains(got, want) {
+		t.Errorf("wrong first error %q; want to contain %q", got, want)
+	}
+	if got, want := diags[1].Description().Detail, `Unknown hook type "on_destroy"`; !strings.Contains(got, want) {
+		t.Errorf("wrong second error %q; want to contain %q", got, want)
+	}
+}
+
 func TestLoadConfig_credentials(t *testing.T) {
 	got, err := loadConfigFile(filepath.Join(fixtureDir, "credentials"))
 	if err != nil {
@@ -284,6 +533,22 @@ func TestConfigValidate(t *testing.T) {
 			},
 			1, // host block has invalid hostname
 		},
+		"alias good": {
+			&Config{
+				Aliases: map[string]string{
+					"preview": "plan -concise",
+				},
+			},
+			0,
+		},
+		"alias with bad name and empty value": {
+			&Config{
+				Aliases: map[string]string{
+					"-preview": "",
+				},
+			},
+			2, // invalid name, empty value
+		},
 		"credentials good": {
 			&Config{
 				Credentials: map[string]map[string]interface{}{
@@ -321,6 +586,94 @@ func TestConfigValidate(t *testing.T) {
 			},
 			1, // no more than one credentials_helper block allowed
 		},
+		"provider credentials helper good": {
+			&Config{
+				ProviderCredentialsHelpers: map[string]*ConfigProviderCredentialsHelper{
+					"hashicorp/aws": {Command: "aws-creds"},
+				},
+			},
+			0,
+		},
+		"provider credentials helper with bad address and no command": {
+			&Config{
+				ProviderCredentialsHelpers: map[string]*ConfigProviderCredentialsHelper{
+					"not a provider": {},
+				},
+			},
+			2, // invalid provider address, missing command
+		},
+		"hooks good": {
+			&Config{
+				Hooks: []*ConfigHooks{
+					{
+						PreApply:  []*ConfigApplyHook{{ResourceTypes: []string{"aws_*"}, Command: "check", Timeout: "10s"}},
+						PostApply: []*ConfigApplyHook{{Command: "notify", OnFailure: "continue"}},
+					},
+				},
+			},
+			0,
+		},
+		"hooks bad": {
+			&Config{
+				Hooks: []*ConfigHooks{
+					{
+						PreApply:  []*ConfigApplyHook{{}, {ResourceTypes: []string{"aws_["}, Command: "check", Timeout: "soon"}},
+						PostApply: []*ConfigApplyHook{{Command: "notify", OnFailure: "ignore"}},
+					},
+				},
+			},
+			4, // missing command, bad pattern, bad timeout, bad on_failure
+		},
+		"default_tags good": {
+			&Config{
+				DefaultTags: map[string]*ConfigDefaultTags{
+					"hashicorp/aws": {Tags: map[string]string{"team": "platform"}},
+				},
+			},
+			0,
+		},
+		"default_tags bad": {
+			&Config{
+				DefaultTags: map[string]*ConfigDefaultTags{
+					"not a provider": {Tags: map[string]string{"team": "platform"}},
+					"hashicorp/aws":  {},
+				},
+			},
+			2, // invalid provider address, no tags
+		},
+		"plan_limits good": {
+			&Config{
+				PlanLimits: []*ConfigPlanLimits{
+					{MaxCreate: ptrTo(100), MaxDestroy: ptrTo(0)},
+				},
+			},
+			0,
+		},
+		"plan_limits bad": {
+			&Config{
+				PlanLimits: []*ConfigPlanLimits{
+					{MaxUpdate: ptrTo(-1), MaxDestroy: ptrTo(-10)},
+				},
+			},
+			2, // negative max_update and max_destroy
+		},
+		"run_task good": {
+			&Config{
+				RunTasks: map[string]*ConfigRunTask{
+					"scanner": {URL: "https://scanner.example.com/check", Stage: "pre_plan", EnforcementLevel: "advisory", Timeout: "30s"},
+				},
+			},
+			0,
+		},
+		"run_task bad": {
+			&Config{
+				RunTasks: map[string]*ConfigRunTask{
+					"missing": {},
+					"scanner": {URL: "ftp://scanner.example.com", Stage: "pre_apply", EnforcementLevel: "soft", Timeout: "soon"},
+				},
+			},
+			5, // missing url, bad url, bad stage, bad enforcement level, bad timeout
+		},
 		"provider_installation good none": {
 			&Config{
 				ProviderInstallation: nil,
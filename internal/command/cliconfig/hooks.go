@@ -0,0 +1,156 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	hclast "github.com/hashicorp/hcl/hcl/ast"
+
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ConfigHooks is the structure of the "hooks" nested block within the CLI
+// configuration.
+type ConfigHooks struct {
+	PreApply  []*ConfigApplyHook
+	PostApply []*ConfigApplyHook
+}
+
+// ConfigApplyHook is the structure of the "pre_apply" and "post_apply"
+// nested blocks within a "hooks" block.
+type ConfigApplyHook struct {
+	// ResourceTypes are glob patterns of the resource types whose changes
+	// run the hook. If empty, changes to resources of any type do.
+	ResourceTypes []string `hcl:"resource_types"`
+
+	Command string   `hcl:"command"`
+	Args    []string `hcl:"args"`
+
+	// Timeout is how long the program may run, as a duration string such
+	// as "30s". If empty, a default applies.
+	Timeout string `hcl:"timeout"`
+
+	// OnFailure is either "fail", the default, which turns a failure of the
+	// program into an error for the resource, or "continue", which only
+	// warns about it.
+	OnFailure string `hcl:"on_failure"`
+}
+
+// decodeHooksFromConfig uses the HCL AST API directly to decode "hooks"
+// blocks from the given file, since HCL 1's decoder can't represent the
+// "pre_apply" and "post_apply" blocks nested inside them.
+func decodeHooksFromConfig(hclFile *hclast.File) ([]*ConfigHooks, tfdiags.Diagnostics) {
+	var ret []*ConfigHooks
+	var diags tfdiags.Diagnostics
+
+	root := hclFile.Node.(*hclast.ObjectList)
+
+	for _, block := range root.Items {
+		if block.Keys[0].Token.Value() != "hooks" {
+			continue
+		}
+		isJSON := block.Keys[0].Token.JSON
+		body, ok := block.Val.(*hclast.ObjectType)
+		if !ok || (block.Assign.Line != 0 && !isJSON) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid hooks block",
+				fmt.Sprintf("The hooks block at %s must not be introduced with an equals sign.", block.Pos()),
+			))
+			continue
+		}
+		if len(block.Keys) > 1 && !isJSON {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid hooks block",
+				fmt.Sprintf("The hooks block at %s must not have any labels.", block.Pos()),
+			))
+		}
+
+		hooks := &ConfigHooks{}
+		for _, hookBlock := range body.List.Items {
+			hookBody, ok := hookBlock.Val.(*hclast.ObjectType)
+			if !ok || (hookBlock.Assign.Line != 0 && !isJSON) {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid hooks block",
+					fmt.Sprintf("The items inside the hooks block at %s must all be blocks.", block.Pos()),
+				))
+				continue
+			}
+
+			hookType := hookBlock.Keys[0].Token.Value().(string)
+			if hookType != "pre_apply" && hookType != "post_apply" {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid hooks block",
+					fmt.Sprintf("Unknown hook type %q at %s: must be pre_apply or post_apply.", hookType, hookBlock.Pos()),
+				))
+				continue
+			}
+			if len(hookBlock.Keys) > 1 && !isJSON {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid hooks block",
+					fmt.Sprintf("The %s block at %s must not have any labels.", hookType, hookBlock.Pos()),
+				))
+			}
+
+			var hook ConfigApplyHook
+			if err := hcl.DecodeObject(&hook, hookBody); err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid hooks block",
+					fmt.Sprintf("Invalid %s block at %s: %s.", hookType, hookBlock.Pos(), err),
+				))
+				continue
+			}
+			if hookType == "pre_apply" {
+				hooks.PreApply = append(hooks.PreApply, &hook)
+			} else {
+				hooks.PostApply = append(hooks.PostApply, &hook)
+			}
+		}
+		ret = append(ret, hooks)
+	}
+
+	return ret, diags
+}
+
+func (h *ConfigApplyHook) validate(blockType string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if h == nil || strings.TrimSpace(h.Command) == "" {
+		diags = diags.Append(
+			fmt.Errorf("Each %s hook must set command", blockType),
+		)
+		return diags
+	}
+	for _, pattern := range h.ResourceTypes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The %s hook for %q has an invalid resource type pattern %q: %w", blockType, h.Command, pattern, err),
+			)
+		}
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			diags = diags.Append(
+				fmt.Errorf("The %s hook for %q has an invalid timeout %q: must be a positive duration such as \"30s\"", blockType, h.Command, h.Timeout),
+			)
+		}
+	}
+	switch h.OnFailure {
+	case "", "fail", "continue":
+	default:
+		diags = diags.Append(
+			fmt.Errorf("The %s hook for %q has an invalid on_failure %q: must be \"fail\" or \"continue\"", blockType, h.Command, h.OnFailure),
+		)
+	}
+	return diags
+}
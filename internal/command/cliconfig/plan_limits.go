@@ -0,0 +1,66 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl"
+	hclast "github.com/hashicorp/hcl/hcl/ast"
+
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ConfigPlanLimits is the structure of the "plan_limits" nested block within
+// the CLI configuration. A nil limit isn't set.
+type ConfigPlanLimits struct {
+	MaxCreate  *int `hcl:"max_create"`
+	MaxUpdate  *int `hcl:"max_update"`
+	MaxDestroy *int `hcl:"max_destroy"`
+}
+
+// decodePlanLimitsFromConfig decodes the "plan_limits" blocks of the given
+// file. HCL 1's DecodeObject would split a block whose arguments are all
+// numbers into a block for each argument, so we decode them one by one.
+func decodePlanLimitsFromConfig(hclFile *hclast.File) ([]*ConfigPlanLimits, tfdiags.Diagnostics) {
+	var ret []*ConfigPlanLimits
+	var diags tfdiags.Diagnostics
+
+	root := hclFile.Node.(*hclast.ObjectList)
+	for _, block := range root.Items {
+		if block.Keys[0].Token.Value() != "plan_limits" {
+			continue
+		}
+		isJSON := block.Keys[0].Token.JSON
+		body, ok := block.Val.(*hclast.ObjectType)
+		if !ok || (block.Assign.Line != 0 && !isJSON) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid plan_limits block",
+				fmt.Sprintf("The plan_limits block at %s must not be introduced with an equals sign.", block.Pos()),
+			))
+			continue
+		}
+		if len(block.Keys) > 1 && !isJSON {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid plan_limits block",
+				fmt.Sprintf("The plan_limits block at %s must not have any labels.", block.Pos()),
+			))
+			continue
+		}
+
+		limits := &ConfigPlanLimits{}
+		if err := hcl.DecodeObject(limits, body); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid plan_limits block",
+				fmt.Sprintf("Invalid plan limits at %s: %s.", block.Pos(), err),
+			))
+			continue
+		}
+		ret = append(ret, limits)
+	}
+	return ret, diags
+}
@@ -0,0 +1,201 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	hclast "github.com/hashicorp/hcl/hcl/ast"
+	hcltoken "github.com/hashicorp/hcl/hcl/token"
+
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ProviderGRPCConfig models the "provider_grpc" configuration block and its
+// associated environment variables, which tune the gRPC connections to the
+// provider plugins that Farseek starts.
+//
+// A zero value in any field means that the setting is unset, and so the
+// connections use their usual behavior for it.
+type ProviderGRPCConfig struct {
+	// MaxRecvMsgSize and MaxSendMsgSize are the largest messages, in bytes,
+	// that Farseek receives from and sends to providers.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// KeepaliveInterval is how long a connection may be idle before Farseek
+	// checks that the provider is still there, and KeepaliveTimeout is how
+	// long it waits for the provider to answer that check.
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+
+	// CallTimeout is how long each call to a provider may take.
+	CallTimeout time.Duration
+}
+
+func decodeProviderGRPCConfigFromConfig(hclFile *hclast.File) (*ProviderGRPCConfig, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var ret *ProviderGRPCConfig
+	var retPos hcltoken.Pos
+
+	root := hclFile.Node.(*hclast.ObjectList)
+	for _, block := range root.Items {
+		if block.Keys[0].Token.Value() != "provider_grpc" {
+			continue
+		}
+		if ret != nil {
+			// As with registry_protocols, each file can have only one of
+			// these blocks, but other files and the environment can each
+			// have their own.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Duplicate provider_grpc block",
+				fmt.Sprintf("The provider gRPC settings were already defined at %s.", retPos),
+			))
+			continue
+		}
+
+		body, ok := block.Val.(*hclast.ObjectType)
+		if !ok {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid provider_grpc block",
+				fmt.Sprintf("The provider_grpc item at %s must have an open brace after the block type.", block.Pos()),
+			))
+			continue
+		}
+
+		config, moreDiags := decodeProviderGRPCConfigFromConfigBody(body)
+		diags = diags.Append(moreDiags)
+		ret = config
+		retPos = block.Pos()
+	}
+
+	return ret, diags
+}
+
+func decodeProviderGRPCConfigFromConfigBody(body *hclast.ObjectType) (*ProviderGRPCConfig, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	ret := &ProviderGRPCConfig{}
+
+	type BodyContent struct {
+		MaxRecvMsgSize    *int `hcl:"max_receive_message_mb"`
+		MaxSendMsgSize    *int `hcl:"max_send_message_mb"`
+		KeepaliveInterval *int `hcl:"keepalive_interval_seconds"`
+		KeepaliveTimeout  *int `hcl:"keepalive_timeout_seconds"`
+		CallTimeout       *int `hcl:"call_timeout_seconds"`
+	}
+	var bodyContent BodyContent
+	err := hcl.DecodeObject(&bodyContent, body)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid provider_grpc block",
+			fmt.Sprintf("Invalid provider gRPC settings at %s: %s.", body.Pos(), err),
+		))
+		return ret, diags
+	}
+
+	positive := func(name string, v *int) int {
+		if v == nil {
+			return 0
+		}
+		if *v <= 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid provider_grpc block",
+				fmt.Sprintf("Invalid provider gRPC settings at %s: %s must be a positive whole number.", body.Pos(), name),
+			))
+			return 0
+		}
+		return *v
+	}
+	ret.MaxRecvMsgSize = positive("max_receive_message_mb", bodyContent.MaxRecvMsgSize) << 20
+	ret.MaxSendMsgSize = positive("max_send_message_mb", bodyContent.MaxSendMsgSize) << 20
+	ret.KeepaliveInterval = time.Duration(positive("keepalive_interval_seconds", bodyContent.KeepaliveInterval)) * time.Second
+	ret.KeepaliveTimeout = time.Duration(positive("keepalive_timeout_seconds", bodyContent.KeepaliveTimeout)) * time.Second
+	ret.CallTimeout = time.Duration(positive("call_timeout_seconds", bodyContent.CallTimeout)) * time.Second
+	return ret, diags
+}
+
+// decodeProviderGRPCConfigFromEnvironment returns a [ProviderGRPCConfig]
+// object describing a "virtual" provider_grpc configuration block implied by
+// the given environment variables, if any are set. Like the registry
+// protocol variables, invalid values are ignored.
+func decodeProviderGRPCConfigFromEnvironment(env map[string]string) *ProviderGRPCConfig {
+	positive := func(name string) int {
+		v, err := strconv.Atoi(env[name])
+		if err != nil || v <= 0 {
+			return 0
+		}
+		return v
+	}
+	ret := &ProviderGRPCConfig{
+		MaxRecvMsgSize:    positive(providerGRPCMaxRecvMsgSizeEnvName) << 20,
+		MaxSendMsgSize:    positive(providerGRPCMaxSendMsgSizeEnvName) << 20,
+		KeepaliveInterval: time.Duration(positive(providerGRPCKeepaliveIntervalEnvName)) * time.Second,
+		KeepaliveTimeout:  time.Duration(positive(providerGRPCKeepaliveTimeoutEnvName)) * time.Second,
+		CallTimeout:       time.Duration(positive(providerGRPCCallTimeoutEnvName)) * time.Second,
+	}
+	if *ret == (ProviderGRPCConfig{}) {
+		// If no variable is set then we behave as if this virtual
+		// configuration block is not present at all.
+		return nil
+	}
+	return ret
+}
+
+// mergeProviderGRPCConfigs is used by [Config.Merge] for merging provider
+// gRPC configurations from multiple different sources, with each setting of
+// override taking precedence over the same setting of base.
+func mergeProviderGRPCConfigs(base, override *ProviderGRPCConfig) *ProviderGRPCConfig {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+
+	// The caller expects both of the given objects to remain unmodified.
+	ret := *base
+	if override.MaxRecvMsgSize != 0 {
+		ret.MaxRecvMsgSize = override.MaxRecvMsgSize
+	}
+	if override.MaxSendMsgSize != 0 {
+		ret.MaxSendMsgSize = override.MaxSendMsgSize
+	}
+	if override.KeepaliveInterval != 0 {
+		ret.KeepaliveInterval = override.KeepaliveInterval
+	}
+	if override.KeepaliveTimeout != 0 {
+		ret.KeepaliveTimeout = override.KeepaliveTimeout
+	}
+	if override.CallTimeout != 0 {
+		ret.CallTimeout = override.CallTimeout
+	}
+	return &ret
+}
+
+const (
+	// providerGRPCMaxRecvMsgSizeEnvName and providerGRPCMaxSendMsgSizeEnvName
+	// are the names of the environment variables that set the largest
+	// messages, in megabytes, that Farseek receives from and sends to
+	// providers.
+	providerGRPCMaxRecvMsgSizeEnvName = "TF_PROVIDER_GRPC_MAX_RECEIVE_MESSAGE_MB"
+	providerGRPCMaxSendMsgSizeEnvName = "TF_PROVIDER_GRPC_MAX_SEND_MESSAGE_MB"
+
+	// providerGRPCKeepaliveIntervalEnvName and
+	// providerGRPCKeepaliveTimeoutEnvName are the names of the environment
+	// variables that set, in seconds, how often Farseek checks that idle
+	// providers are still there and how long it waits for their answer.
+	providerGRPCKeepaliveIntervalEnvName = "TF_PROVIDER_GRPC_KEEPALIVE_INTERVAL_SECONDS"
+	providerGRPCKeepaliveTimeoutEnvName  = "TF_PROVIDER_GRPC_KEEPALIVE_TIMEOUT_SECONDS"
+
+	// providerGRPCCallTimeoutEnvName is the name of the environment variable
+	// that sets how long, in seconds, each call to a provider may take.
+	providerGRPCCallTimeoutEnvName = "TF_PROVIDER_GRPC_CALL_TIMEOUT_SECONDS"
+)
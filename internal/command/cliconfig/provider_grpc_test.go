@@ -0,0 +1,122 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfig_providerGRPC(t *testing.T) {
+	envNames := []string{
+		"TF_PROVIDER_GRPC_MAX_RECEIVE_MESSAGE_MB",
+		"TF_PROVIDER_GRPC_MAX_SEND_MESSAGE_MB",
+		"TF_PROVIDER_GRPC_KEEPALIVE_INTERVAL_SECONDS",
+		"TF_PROVIDER_GRPC_KEEPALIVE_TIMEOUT_SECONDS",
+		"TF_PROVIDER_GRPC_CALL_TIMEOUT_SECONDS",
+	}
+
+	tests := map[string]struct {
+		// The fixture names correspond to files under the "testdata" directory.
+		fixture string
+		env     map[string]string
+		want    *ProviderGRPCConfig
+		wantErr string
+	}{
+		"none": {
+			"registry-protocols-none",
+			nil,
+			nil,
+			``,
+		},
+		"config": {
+			"provider-grpc",
+			nil,
+			&ProviderGRPCConfig{
+				MaxRecvMsgSize:    256 << 20,
+				MaxSendMsgSize:    128 << 20,
+				KeepaliveInterval: 30 * time.Second,
+				CallTimeout:       10 * time.Minute,
+			},
+			``,
+		},
+		"none-env": {
+			"registry-protocols-none",
+			map[string]string{
+				"TF_PROVIDER_GRPC_MAX_RECEIVE_MESSAGE_MB":    "512",
+				"TF_PROVIDER_GRPC_KEEPALIVE_TIMEOUT_SECONDS": "5",
+				"TF_PROVIDER_GRPC_CALL_TIMEOUT_SECONDS":      "not a number",
+			},
+			&ProviderGRPCConfig{
+				MaxRecvMsgSize:   512 << 20,
+				KeepaliveTimeout: 5 * time.Second,
+			},
+			``,
+		},
+		"config-env": {
+			// The environment variables take precedence over the
+			// configuration, one setting at a time.
+			"provider-grpc",
+			map[string]string{
+				"TF_PROVIDER_GRPC_MAX_RECEIVE_MESSAGE_MB": "512",
+			},
+			&ProviderGRPCConfig{
+				MaxRecvMsgSize:    512 << 20,
+				MaxSendMsgSize:    128 << 20,
+				KeepaliveInterval: 30 * time.Second,
+				CallTimeout:       10 * time.Minute,
+			},
+			``,
+		},
+		"invalid": {
+			"provider-grpc-invalid",
+			nil,
+			&ProviderGRPCConfig{
+				CallTimeout: time.Minute,
+			},
+			`max_receive_message_mb must be a positive whole number`,
+		},
+		"invalid-multi": {
+			"provider-grpc-invalid-multi",
+			nil,
+			&ProviderGRPCConfig{
+				MaxRecvMsgSize: 256 << 20,
+			},
+			`The provider gRPC settings were already defined at 1:1`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TF_CLI_CONFIG_FILE", filepath.Join("testdata", test.fixture))
+			// We explicitly set the variables that the test doesn't use to
+			// empty, so that we won't pick up stray values from the real
+			// environment.
+			for _, name := range envNames {
+				t.Setenv(name, test.env[name])
+			}
+
+			gotConfig, diags := LoadConfig(t.Context())
+			if diags.HasErrors() {
+				errStr := diags.Err().Error()
+				if test.wantErr == "" {
+					t.Errorf("unexpected errors: %s", errStr)
+				}
+				if !strings.Contains(errStr, test.wantErr) {
+					t.Errorf("missing expected error\nwant substring: %s\ngot: %s", test.wantErr, errStr)
+				}
+			} else if test.wantErr != "" {
+				t.Errorf("unexpected success\nwant error with substring: %s", test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, gotConfig.ProviderGRPC); diff != "" {
+				t.Error("unexpected result\n" + diff)
+			}
+		})
+	}
+}
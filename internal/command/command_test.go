@@ -179,6 +179,7 @@ func testPlan(t *testing.T) *plans.Plan {
 	backendConfig := cty.ObjectVal(map[string]cty.Value{
 		"path":          cty.NullVal(cty.String),
 		"workspace_dir": cty.NullVal(cty.String),
+		"workspaces":    cty.NullVal(cty.Map(cty.Object(map[string]cty.Type{"path": cty.String}))),
 	})
 	backendConfigRaw, err := plans.NewDynamicValue(backendConfig, backendConfig.Type())
 	if err != nil {
@@ -0,0 +1,417 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/opentofu/svchost"
+	"github.com/posener/complete"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// legacyRegistryHost is the registry that Terraform state files refer to for
+// providers given without a hostname. convert-state moves them to
+// addrs.DefaultProviderRegistryHost, which has the same providers.
+const legacyRegistryHost = svchost.Hostname("registry.terraform.io")
+
+// defaultImportsFilename is the file that convert-state -stateless writes
+// the import blocks for the converted resources to, unless told otherwise.
+const defaultImportsFilename = "imports.tf"
+
+// ConvertStateCommand is a cli.Command implementation that converts a state
+// file written by Terraform or OpenTofu into one Farseek can use, or into
+// what Farseek needs to manage the same resources without state.
+type ConvertStateCommand struct {
+	Meta
+}
+
+func (c *ConvertStateCommand) Run(args []string) int {
+	var fromPath, outPath, importsPath string
+	var stateless bool
+	var providerHosts FlagStringKV
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("convert-state")
+	cmdFlags.StringVar(&fromPath, "from", "", "path")
+	cmdFlags.StringVar(&outPath, "out", DefaultStateFilename, "path")
+	cmdFlags.StringVar(&importsPath, "imports", defaultImportsFilename, "path")
+	cmdFlags.BoolVar(&stateless, "stateless", false, "stateless")
+	cmdFlags.Var(&providerHosts, "provider-host", "from=to")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The convert-state command expects no arguments.")
+		cmdFlags.Usage()
+		return 1
+	}
+	if fromPath == "" {
+		c.Ui.Error("The convert-state command requires the -from option, with the path of the state file to convert.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	hosts, hostDiags := convertStateProviderHosts(providerHosts)
+	diags = diags.Append(hostDiags)
+	if hostDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	src, err := os.ReadFile(fromPath)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read state file",
+			fmt.Sprintf("Error reading %s: %s.", fromPath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	file, err := readForeignState(src)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read state file",
+			fmt.Sprintf("Error reading %s: %s", fromPath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	remapped := remapStateProviders(file.State, hosts)
+	for _, from := range sortedProviderRemaps(remapped) {
+		c.Ui.Output(fmt.Sprintf("Provider %s is now %s.", from, remapped[from]))
+	}
+
+	if stateless {
+		return c.convertToStateless(file.State, importsPath, diags)
+	}
+
+	if _, err := os.Stat(outPath); err == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"State file already exists",
+			fmt.Sprintf("%s already exists. Remove it, or choose another path with the -out option.", outPath),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	var buf bytes.Buffer
+	if err := statefile.Write(file, &buf, encryption.StateEncryptionDisabled()); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write state file",
+			fmt.Sprintf("Error encoding the converted state: %s.", err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0644); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write state file",
+			fmt.Sprintf("Error writing %s: %s.", outPath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Wrote the converted state to %s.", outPath))
+	return 0
+}
+
+// convertToStateless writes the import blocks for the managed resources of
+// the given state to importsPath, and records the current commit as the
+// last one applied, so that Farseek takes the resources as they are
+// configured now to exist.
+func (c *ConvertStateCommand) convertToStateless(state *states.State, importsPath string, diags tfdiags.Diagnostics) int {
+	dir := c.discoveryDir()
+	sha, err := farseek.Discovery.GetCurrentSHA(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Not a git repository",
+			fmt.Sprintf("Farseek can only manage resources without state in a git repository, but failed to find the current commit of %s: %s.", dir, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if _, err := os.Stat(importsPath); err == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Import manifest already exists",
+			fmt.Sprintf("%s already exists. Remove it, or choose another path with the -imports option.", importsPath),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	imports, skipped := stateImportBlocks(state)
+	if len(skipped) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Some resources have no import ID",
+			fmt.Sprintf("These resource instances have no \"id\" attribute in the state, so there are no import blocks for them:\n  - %s\n\nAdd import blocks for them by hand, with the IDs that their providers expect.", strings.Join(skipped, "\n  - ")),
+		))
+	}
+	if err := os.WriteFile(importsPath, imports, 0644); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write import manifest",
+			fmt.Sprintf("Error writing %s: %s.", importsPath, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if err := farseek.WriteSHA(dir, sha); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to record the current commit",
+			fmt.Sprintf("Error writing %s: %s.", farseek.SHAFilename, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Wrote the import blocks to %s, and recorded commit %s as applied.", importsPath, sha))
+	return 0
+}
+
+// readForeignState reads a state file written by Terraform or OpenTofu,
+// leaving out what Farseek can't make use of.
+func readForeignState(src []byte) (*statefile.File, error) {
+	var sniff struct {
+		Version uint64 `json:"version"`
+	}
+	if err := json.Unmarshal(src, &sniff); err == nil && sniff.Version == 4 {
+		// The results of checks are only kept from one run to the next to
+		// describe changes in their status, and the kinds of checks that
+		// Terraform records don't all exist in Farseek, so we drop them.
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(src, &raw); err != nil {
+			return nil, err
+		}
+		delete(raw, "check_results")
+		var err error
+		if src, err = json.Marshal(raw); err != nil {
+			return nil, err
+		}
+	}
+	return statefile.Read(bytes.NewReader(src), encryption.StateEncryptionDisabled())
+}
+
+// convertStateProviderHosts returns the registry hosts to move providers
+// from, to the hosts to move them to, from the -provider-host options.
+func convertStateProviderHosts(raw map[string]string) (map[svchost.Hostname]svchost.Hostname, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	hosts := map[svchost.Hostname]svchost.Hostname{
+		legacyRegistryHost: addrs.DefaultProviderRegistryHost,
+	}
+	for rawFrom, rawTo := range raw {
+		from, err := svchost.ForComparison(rawFrom)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid -provider-host option",
+				fmt.Sprintf("The hostname %q is not valid: %s.", rawFrom, err),
+			))
+			continue
+		}
+		to, err := svchost.ForComparison(rawTo)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid -provider-host option",
+				fmt.Sprintf("The hostname %q is not valid: %s.", rawTo, err),
+			))
+			continue
+		}
+		hosts[from] = to
+	}
+	return hosts, diags
+}
+
+// remapStateProviders moves the providers of the resources in the given
+// state to the hosts given for their current hosts, and gives the legacy
+// providers of old state files their implied addresses. It returns the
+// providers it moved, and where to.
+func remapStateProviders(state *states.State, hosts map[svchost.Hostname]svchost.Hostname) map[addrs.Provider]addrs.Provider {
+	remapped := make(map[addrs.Provider]addrs.Provider)
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			from := rs.ProviderConfig.Provider
+			to := from
+			switch {
+			case from.IsLegacy():
+				to = addrs.ImpliedProviderForUnqualifiedType(from.Type)
+			case hosts[from.Hostname] != "":
+				to = addrs.NewProvider(hosts[from.Hostname], from.Namespace, from.Type)
+			}
+			if to != from {
+				rs.ProviderConfig.Provider = to
+				remapped[from] = to
+			}
+		}
+	}
+	return remapped
+}
+
+func sortedProviderRemaps(remapped map[addrs.Provider]addrs.Provider) []addrs.Provider {
+	ret := make([]addrs.Provider, 0, len(remapped))
+	for from := range remapped {
+		ret = append(ret, from)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// stateImportBlocks returns a configuration file with an import block for
+// each managed resource instance in the given state, along with the
+// addresses of those that have no ID to import them with.
+func stateImportBlocks(state *states.State) ([]byte, []string) {
+	type importBlock struct {
+		addr     addrs.AbsResourceInstance
+		id       string
+		provider addrs.AbsProviderConfig
+	}
+	var blocks []importBlock
+	var skipped []string
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			for key, is := range rs.Instances {
+				if is.Current == nil {
+					continue
+				}
+				addr := rs.Addr.Instance(key)
+				var attrs map[string]any
+				if err := json.Unmarshal(is.Current.AttrsJSON, &attrs); err != nil {
+					skipped = append(skipped, addr.String())
+					continue
+				}
+				id, ok := attrs["id"].(string)
+				if !ok || id == "" {
+					skipped = append(skipped, addr.String())
+					continue
+				}
+				blocks = append(blocks, importBlock{addr: addr, id: id, provider: rs.ProviderConfig})
+			}
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].addr.Less(blocks[j].addr)
+	})
+	sort.Strings(skipped)
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{
+			Type:  hclsyntax.TokenComment,
+			Bytes: []byte("# This file was generated by \"farseek convert-state\", to import the\n# resources of a state file into Farseek.\n"),
+		},
+	})
+	for _, block := range blocks {
+		body.AppendNewline()
+		b := body.AppendNewBlock("import", nil).Body()
+		to, diags := hclsyntax.ParseTraversalAbs([]byte(block.addr.String()), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			// Should never happen, since the address came from a valid
+			// state.
+			panic(fmt.Sprintf("invalid resource instance address %s: %s", block.addr, diags.Error()))
+		}
+		b.SetAttributeTraversal("to", to)
+		b.SetAttributeValue("id", cty.StringVal(block.id))
+		if block.provider.Alias != "" && block.provider.Module.IsRoot() {
+			b.SetAttributeTraversal("provider", hcl.Traversal{
+				hcl.TraverseRoot{Name: block.provider.Provider.Type},
+				hcl.TraverseAttr{Name: block.provider.Alias},
+			})
+		}
+	}
+	return hclwrite.Format(f.Bytes()), skipped
+}
+
+func (c *ConvertStateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConvertStateCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-from":          complete.PredictFiles("*.tfstate"),
+		"-out":           complete.PredictFiles("*.tfstate"),
+		"-imports":       complete.PredictFiles("*.tf"),
+		"-stateless":     completePredictBoolean,
+		"-provider-host": complete.PredictAnything,
+	}
+}
+
+func (c *ConvertStateCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] convert-state -from=PATH [options]
+
+  Converts a state file written by Terraform or OpenTofu, so that Farseek
+  can manage the resources it tracks.
+
+  Providers from registry.terraform.io are moved to registry.opentofu.org,
+  which has the same providers, and the legacy providers of older state
+  files are given their full addresses. The results of checks are dropped.
+
+  By default, the converted state is written to a new state file. With
+  -stateless, Farseek instead writes an import block for each resource to
+  a configuration file, and records the current commit as the last one
+  applied, in .farseek_sha. Farseek then takes the resources configured at
+  that commit to exist, without any state.
+
+Options:
+
+  -from=PATH               The state file to convert. Required.
+
+  -out=PATH                The state file to write. Defaults to
+                           "` + DefaultStateFilename + `". It must not exist yet.
+
+  -provider-host=FROM=TO   Also move providers from the registry at host FROM
+                           to the one at host TO. Can be given more than once.
+
+  -stateless               Write an import manifest and record the current
+                           commit, instead of writing a state file.
+
+  -imports=PATH            The configuration file to write the import blocks
+                           to, with -stateless. Defaults to "` + defaultImportsFilename + `". It
+                           must not exist yet.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConvertStateCommand) Synopsis() string {
+	return "Convert a Terraform or OpenTofu state file for Farseek"
+}
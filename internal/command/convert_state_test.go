@@ -0,0 +1,176 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+)
+
+func TestConvertState(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+	from := testFixturePath("convert-state/terraform.tfstate")
+
+	ui := new(cli.MockUi)
+	c := &ConvertStateCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	args := []string{"-from", from, "-provider-host", "registry.example.com=mirror.example.com"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	f, err := os.Open(filepath.Join(td, DefaultStateFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	file, err := statefile.Read(f, encryption.StateEncryptionDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := file.Lineage, "0a1b2c3d-4e5f-6789-abcd-ef0123456789"; got != want {
+		t.Errorf("wrong lineage %q; want %q", got, want)
+	}
+	if file.State.CheckResults != nil {
+		t.Errorf("check results were kept")
+	}
+
+	got := make(map[string]string)
+	for _, ms := range file.State.Modules {
+		for _, rs := range ms.Resources {
+			got[rs.Addr.String()] = rs.ProviderConfig.String()
+		}
+	}
+	want := map[string]string{
+		"aws_instance.web":                   `provider["registry.opentofu.org/hashicorp/aws"]`,
+		"aws_instance.replica":               `provider["registry.opentofu.org/hashicorp/aws"].west`,
+		"data.aws_ami.ubuntu":                `provider["registry.opentofu.org/hashicorp/aws"]`,
+		"module.network.custom_network.main": `provider["mirror.example.com/acme/custom"]`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong providers\n%s", diff)
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"Provider registry.terraform.io/hashicorp/aws is now registry.opentofu.org/hashicorp/aws.",
+		"Provider registry.example.com/acme/custom is now mirror.example.com/acme/custom.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q:\n%s", want, output)
+		}
+	}
+
+	// The state file it wrote mustn't be overwritten.
+	ui = new(cli.MockUi)
+	c = &ConvertStateCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "State file already exists"; !strings.Contains(got, want) {
+		t.Errorf("wrong error %q; want to contain %q", got, want)
+	}
+}
+
+func TestConvertState_stateless(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = mockDiscoverer{}
+
+	ui := new(cli.MockUi)
+	c := &ConvertStateCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	args := []string{"-stateless", "-from", testFixturePath("convert-state/terraform.tfstate")}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(td, DefaultStateFilename)); !os.IsNotExist(err) {
+		t.Errorf("wrote a state file")
+	}
+	sha, err := farseek.ReadSHA(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != "mock-sha" {
+		t.Errorf("wrong recorded SHA %q", sha)
+	}
+
+	got, err := os.ReadFile(filepath.Join(td, defaultImportsFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# This file was generated by "farseek convert-state", to import the
+# resources of a state file into Farseek.
+
+import {
+  to       = aws_instance.replica[0]
+  id       = "i-abcdef"
+  provider = aws.west
+}
+
+import {
+  to = aws_instance.web
+  id = "i-0123456789"
+}
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong import blocks\n%s", diff)
+	}
+
+	// The resource without an ID is reported.
+	if got, want := ui.ErrorWriter.String(), "module.network.custom_network.main"; !strings.Contains(got, want) {
+		t.Errorf("missing warning about %s:\n%s", want, got)
+	}
+}
+
+func TestConvertStateProviderHosts(t *testing.T) {
+	hosts, diags := convertStateProviderHosts(map[string]string{
+		"Registry.Example.com": "mirror.example.com",
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	var got []string
+	for from, to := range hosts {
+		got = append(got, from.String()+"="+to.String())
+	}
+	sort.Strings(got)
+	want := []string{
+		"registry.example.com=mirror.example.com",
+		"registry.terraform.io=registry.opentofu.org",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong hosts\n%s", diff)
+	}
+
+	_, diags = convertStateProviderHosts(map[string]string{"not a host": "mirror.example.com"})
+	if !diags.HasErrors() {
+		t.Error("succeeded with an invalid hostname")
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/cliconfig"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	"github.com/rafagsiqueira/farseek/internal/tracing"
+	"github.com/rafagsiqueira/farseek/internal/tracing/traceattrs"
+)
+
+// DoctorCommand is a Command implementation that checks the environment
+// Farseek runs in and suggests how to fix the problems it finds.
+type DoctorCommand struct {
+	Meta
+}
+
+// doctorStatus is the result of a single doctor check.
+type doctorStatus string
+
+const (
+	doctorOK      doctorStatus = "ok"
+	doctorWarning doctorStatus = "warning"
+	doctorError   doctorStatus = "error"
+)
+
+// doctorCheck describes the result of a single doctor check, and how to fix
+// it if it isn't ok.
+type doctorCheck struct {
+	Name    string       `json:"name"`
+	Status  doctorStatus `json:"status"`
+	Message string       `json:"message"`
+	Fix     string       `json:"fix,omitempty"`
+}
+
+func (c *DoctorCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("doctor")
+	var jsonOutput bool
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The doctor command expects no arguments. To check another working directory, use the global -chdir option.\n")
+		return cli.RunResultHelp
+	}
+
+	// The checks must never wait for answers, such as about migrating the
+	// state to a changed backend.
+	c.input = false
+
+	checks := []doctorCheck{
+		c.checkGit(),
+		c.checkTerminal(),
+		c.checkCLIConfig(ctx),
+		c.checkPluginCache(),
+		c.checkBackend(ctx),
+		c.checkTelemetry(ctx),
+		c.checkMode(),
+	}
+
+	if jsonOutput {
+		return c.showJSONChecks(checks)
+	}
+	return c.showChecks(checks)
+}
+
+func (c *DoctorCommand) checkGit() doctorCheck {
+	check := doctorCheck{Name: "git"}
+	path, err := exec.LookPath("git")
+	if err != nil {
+		check.Status = doctorError
+		check.Message = "git isn't installed, or isn't in the PATH."
+		check.Fix = "Install git. Farseek needs it to discover changes in stateless mode."
+		return check
+	}
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Running %s failed: %s.", path, err)
+		check.Fix = "Check that git is installed correctly, for example by running git --version."
+		return check
+	}
+	check.Status = doctorOK
+	check.Message = fmt.Sprintf("%s, at %s.", strings.TrimSpace(string(out)), path)
+	return check
+}
+
+func (c *DoctorCommand) checkTerminal() doctorCheck {
+	check := doctorCheck{Name: "terminal", Status: doctorOK}
+	if c.Streams == nil {
+		check.Message = "Farseek isn't connected to a terminal."
+		return check
+	}
+	if !c.Streams.Stdout.IsTerminal() {
+		check.Message = fmt.Sprintf("The output isn't a terminal, so Farseek wraps it at %d columns.", c.Streams.Stdout.Columns())
+		if c.color {
+			check.Status = doctorWarning
+			check.Message = "The output isn't a terminal, but it has colors."
+			check.Fix = "Use the -no-color option when writing the output to a file or another program."
+		}
+		return check
+	}
+	colors := "with colors"
+	if !c.color {
+		colors = "without colors"
+	}
+	check.Message = fmt.Sprintf("The output is a terminal %d columns wide, %s.", c.Streams.Stdout.Columns(), colors)
+	if !c.Streams.Stdin.IsTerminal() {
+		check.Message += " The input isn't a terminal, so Farseek can't ask questions."
+	}
+	return check
+}
+
+func (c *DoctorCommand) checkCLIConfig(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "cli_config"}
+	location := "the CLI configuration"
+	if path := os.Getenv("TF_CLI_CONFIG_FILE"); path != "" {
+		location = path
+	} else if path, err := cliconfig.ConfigFile(); err == nil {
+		location = path
+	}
+
+	_, diags := cliconfig.LoadConfig(ctx)
+	switch {
+	case diags.HasErrors():
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("The CLI configuration is invalid: %s", doctorDiagsMessage(diags))
+		check.Fix = fmt.Sprintf("Correct the errors in %s.", location)
+	case len(diags) > 0:
+		check.Status = doctorWarning
+		check.Message = fmt.Sprintf("The CLI configuration has warnings: %s", doctorDiagsMessage(diags))
+		check.Fix = fmt.Sprintf("Correct the warnings in %s.", location)
+	default:
+		check.Status = doctorOK
+		check.Message = "The CLI configuration is valid."
+	}
+	return check
+}
+
+func (c *DoctorCommand) checkPluginCache() doctorCheck {
+	check := doctorCheck{Name: "plugin_cache"}
+
+	if dir := c.PluginCacheDir; dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			check.Status = doctorError
+			check.Message = fmt.Sprintf("The plugin cache directory %s doesn't exist.", dir)
+			check.Fix = fmt.Sprintf("Create %s, or remove plugin_cache_dir from the CLI configuration.", dir)
+			return check
+		}
+		f, err := os.CreateTemp(dir, ".farseek-doctor-")
+		if err != nil {
+			check.Status = doctorError
+			check.Message = fmt.Sprintf("Farseek can't write to the plugin cache directory %s: %s.", dir, err)
+			check.Fix = fmt.Sprintf("Give your user permission to write to %s.", dir)
+			return check
+		}
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	locks, diags := c.lockedDependencies()
+	if diags.HasErrors() {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("The dependency lock file is invalid: %s", doctorDiagsMessage(diags))
+		check.Fix = "Correct the errors in .terraform.lock.hcl, or remove it and run farseek init."
+		return check
+	}
+
+	cacheDir := c.providerLocalCacheDir()
+	var problems []string
+	count := 0
+	providerLocks := locks.AllProviders()
+	providers := slices.SortedFunc(maps.Keys(providerLocks), func(a, b addrs.Provider) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, provider := range providers {
+		lock := providerLocks[provider]
+		if locks.ProviderIsOverridden(provider) {
+			continue
+		}
+		count++
+		cached := cacheDir.ProviderVersion(provider, lock.Version())
+		if cached == nil {
+			problems = append(problems, fmt.Sprintf("%s %s isn't installed", provider, lock.Version()))
+			continue
+		}
+		if hashes := lock.PreferredHashes(); len(hashes) != 0 {
+			matched, err := cached.MatchesAnyHash(hashes)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s can't be verified: %s", provider, lock.Version(), err))
+			} else if !matched {
+				problems = append(problems, fmt.Sprintf("%s %s doesn't match the checksums in the dependency lock file", provider, lock.Version()))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Some providers aren't ready: %s.", strings.Join(problems, "; "))
+		check.Fix = "Run farseek init to install the providers again."
+		return check
+	}
+
+	check.Status = doctorOK
+	switch count {
+	case 0:
+		check.Message = "No providers are locked in this working directory."
+	case 1:
+		check.Message = "The locked provider is installed and matches its checksums."
+	default:
+		check.Message = fmt.Sprintf("All %d locked providers are installed and match their checksums.", count)
+	}
+	if c.PluginCacheDir != "" {
+		check.Message += fmt.Sprintf(" The plugin cache directory %s is writable.", c.PluginCacheDir)
+	}
+	return check
+}
+
+func (c *DoctorCommand) checkBackend(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "backend"}
+	fail := func(diags tfdiags.Diagnostics) doctorCheck {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Farseek can't use the backend: %s", doctorDiagsMessage(diags))
+		check.Fix = "Run farseek init to initialize the backend, and check its credentials and network access."
+		return check
+	}
+
+	configPath := c.normalizePath(".")
+	enc, diags := c.EncryptionFromPath(ctx, configPath)
+	if diags.HasErrors() {
+		return fail(diags)
+	}
+	backendConfig, diags := c.loadBackendConfig(ctx, configPath)
+	if diags.HasErrors() {
+		return fail(diags)
+	}
+	b, diags := c.Backend(ctx, &BackendOpts{
+		Config: backendConfig,
+	}, enc.State())
+	if diags.HasErrors() {
+		return fail(diags)
+	}
+
+	// Listing the workspaces is the cheapest call that reaches the storage
+	// of every backend.
+	workspaces, err := b.Workspaces(ctx)
+	if err != nil {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Farseek can't reach the backend: %s.", err)
+		check.Fix = "Check the backend's credentials and network access."
+		return check
+	}
+	name := "local"
+	if backendConfig != nil {
+		name = backendConfig.Type
+	}
+	check.Status = doctorOK
+	check.Message = fmt.Sprintf("The %s backend is reachable, with %d workspace(s).", name, len(workspaces))
+	return check
+}
+
+func (c *DoctorCommand) checkTelemetry(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "telemetry"}
+	exporter := os.Getenv(tracing.OTELExporterEnvVar)
+	switch exporter {
+	case "":
+		check.Status = doctorOK
+		check.Message = "Telemetry is off."
+		return check
+	case "otlp":
+	default:
+		check.Status = doctorWarning
+		check.Message = fmt.Sprintf("%s is %q, which Farseek doesn't support, so telemetry is off.", tracing.OTELExporterEnvVar, exporter)
+		check.Fix = fmt.Sprintf("Set %s to \"otlp\" to export traces, or unset it.", tracing.OTELExporterEnvVar)
+		return check
+	}
+
+	// Telemetry fails to start when the OpenTelemetry packages Farseek is
+	// built with disagree about the schema URL of their attributes, so we
+	// build the resource that describes Farseek the same way it does.
+	if _, err := traceattrs.NewResource(ctx, tracing.DefaultServiceName); err != nil {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Telemetry can't start: %s.", err)
+		check.Fix = fmt.Sprintf("This build of Farseek has incompatible OpenTelemetry packages, which is a bug. Unset %s to turn telemetry off until it's fixed.", tracing.OTELExporterEnvVar)
+		return check
+	}
+
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		check.Status = doctorWarning
+		check.Message = "Telemetry is on, but no OTLP endpoint is set, so Farseek sends traces to a collector on localhost."
+		check.Fix = "Set OTEL_EXPORTER_OTLP_ENDPOINT to the URL of your collector."
+		return check
+	}
+	if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("The OTLP endpoint %q isn't a valid URL.", endpoint)
+		check.Fix = "Set OTEL_EXPORTER_OTLP_ENDPOINT to a URL such as http://localhost:4318."
+		return check
+	}
+	check.Status = doctorOK
+	check.Message = fmt.Sprintf("Telemetry is on, and sends traces to %s.", endpoint)
+	return check
+}
+
+func (c *DoctorCommand) checkMode() doctorCheck {
+	check := doctorCheck{Name: "mode"}
+	dir := c.discoveryDir()
+	head, gitErr := farseek.Discovery.GetCurrentSHA(dir)
+	sha, err := farseek.ReadSHA(dir)
+	if err != nil {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Farseek can't read the last applied commit: %s.", err)
+		check.Fix = fmt.Sprintf("Correct or remove %s.", farseek.SHAFilename)
+		return check
+	}
+
+	check.Status = doctorOK
+	switch {
+	case gitErr == nil && sha != "":
+		check.Message = fmt.Sprintf("Stateless mode: plans discover the changes from the last applied commit %s to the working tree at %s.", shortSHA(sha), shortSHA(head))
+	case gitErr == nil:
+		check.Message = fmt.Sprintf("Stateless mode: no commit has been applied yet, so plans include every resource of the working tree at %s.", shortSHA(head))
+	case sha != "":
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("Stateless mode with the last applied commit %s, but this isn't a git repository, so Farseek can't discover changes.", shortSHA(sha))
+		check.Fix = "Run Farseek in a clone of the repository that contains the configuration."
+	default:
+		check.Message = "Stateful mode: this isn't a git repository, so Farseek plans with the state of the backend."
+	}
+	if gitErr == nil {
+		if branch, err := farseek.Discovery.GetCurrentBranch(dir); err == nil && branch != "" {
+			check.Message += fmt.Sprintf(" The current branch is %s.", branch)
+		}
+	}
+	if project, err := farseek.FindProject(dir); err == nil && project != nil {
+		check.Message += fmt.Sprintf(" The project file %s declares %d root(s).", farseek.ProjectFilename, len(project.Roots))
+	}
+	return check
+}
+
+// doctorDiagsMessage describes the given diagnostics on a single line, for
+// the message of a check.
+func doctorDiagsMessage(diags tfdiags.Diagnostics) string {
+	parts := make([]string, 0, len(diags))
+	for _, diag := range diags {
+		desc := diag.Description()
+		part := strings.TrimSuffix(desc.Summary, ".")
+		if desc.Detail != "" {
+			part += ": " + strings.TrimSuffix(strings.Join(strings.Fields(desc.Detail), " "), ".")
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ") + "."
+}
+
+// shortSHA abbreviates a commit SHA the way git does.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// showChecks renders the checks for humans, returning 1 if any failed.
+func (c *DoctorCommand) showChecks(checks []doctorCheck) int {
+	status := 0
+	for _, check := range checks {
+		switch check.Status {
+		case doctorOK:
+			c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[green]✓[reset] [bold]%s[reset]: %s", check.Name, check.Message)))
+		case doctorWarning:
+			c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[yellow]![reset] [bold]%s[reset]: %s", check.Name, check.Message)))
+		default:
+			c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[red]✗[reset] [bold]%s[reset]: %s", check.Name, check.Message)))
+			status = 1
+		}
+		if check.Fix != "" {
+			c.Ui.Output(fmt.Sprintf("  Fix: %s", check.Fix))
+		}
+	}
+	return status
+}
+
+func (c *DoctorCommand) showJSONChecks(checks []doctorCheck) int {
+	type jsonOutput struct {
+		FormatVersion string        `json:"format_version"`
+		Healthy       bool          `json:"healthy"`
+		Checks        []doctorCheck `json:"checks"`
+	}
+
+	out := jsonOutput{
+		FormatVersion: "1.0",
+		Healthy:       true,
+		Checks:        checks,
+	}
+	for _, check := range checks {
+		if check.Status == doctorError {
+			out.Healthy = false
+		}
+	}
+	j, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		// Should never happen because we fully-control the input here
+		panic(err)
+	}
+	c.Ui.Output(string(j))
+	if !out.Healthy {
+		return 1
+	}
+	return 0
+}
+
+func (c *DoctorCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] doctor [options]
+
+  Checks the environment Farseek runs in, and suggests how to fix the
+  problems it finds. Doctor checks:
+
+    - that git is installed, and its version
+    - whether the output is a terminal, and its width
+    - that the CLI configuration is valid
+    - that the plugin cache directory is writable, and that the locked
+      providers are installed and match their checksums
+    - that the backend is initialized and reachable
+    - that telemetry, if it's on, can start and has an endpoint
+    - whether Farseek runs in stateless or stateful mode, and why
+
+  Doctor exits with status 0 if no check failed, and 1 otherwise.
+  Warnings don't fail.
+
+Options:
+
+  -json                  Produce output in a machine-readable JSON format.
+
+  -no-color              If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DoctorCommand) Synopsis() string {
+	return "Check the environment and suggest fixes"
+}
@@ -0,0 +1,115 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestDoctor(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("TF_CLI_CONFIG_FILE", "")
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+
+	run := func(t *testing.T, pluginCacheDir string) (int, map[string]doctorCheck) {
+		t.Helper()
+		ui := cli.NewMockUi()
+		view, _ := testView(t)
+		c := &DoctorCommand{
+			Meta: Meta{
+				Ui:             ui,
+				View:           view,
+				PluginCacheDir: pluginCacheDir,
+			},
+		}
+		code := c.Run([]string{"-json"})
+
+		var got struct {
+			FormatVersion string        `json:"format_version"`
+			Healthy       bool          `json:"healthy"`
+			Checks        []doctorCheck `json:"checks"`
+		}
+		if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+			t.Fatalf("invalid output: %s\n%s", err, ui.OutputWriter.String())
+		}
+		if got.Healthy != (code == 0) {
+			t.Errorf("healthy is %t, but the status is %d", got.Healthy, code)
+		}
+		checks := make(map[string]doctorCheck, len(got.Checks))
+		for _, check := range got.Checks {
+			checks[check.Name] = check
+		}
+		return code, checks
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		code, checks := run(t, "")
+		if code != 0 {
+			t.Errorf("wrong exit status %d; checks: %#v", code, checks)
+		}
+		for _, name := range []string{"git", "terminal", "cli_config", "plugin_cache", "backend", "telemetry", "mode"} {
+			if _, ok := checks[name]; !ok {
+				t.Errorf("missing check %q", name)
+			}
+		}
+		if _, err := exec.LookPath("git"); err == nil {
+			if got := checks["git"].Status; got != doctorOK {
+				t.Errorf("wrong git status %q: %s", got, checks["git"].Message)
+			}
+		}
+		if got := checks["telemetry"].Status; got != doctorOK {
+			t.Errorf("wrong telemetry status %q: %s", got, checks["telemetry"].Message)
+		}
+	})
+
+	t.Run("unsupported exporter", func(t *testing.T) {
+		t.Setenv("OTEL_TRACES_EXPORTER", "zipkin")
+		code, checks := run(t, "")
+		if code != 0 {
+			t.Errorf("wrong exit status %d; a warning shouldn't fail", code)
+		}
+		check := checks["telemetry"]
+		if check.Status != doctorWarning {
+			t.Errorf("wrong telemetry status %q: %s", check.Status, check.Message)
+		}
+		if check.Fix == "" {
+			t.Error("telemetry warning has no fix")
+		}
+	})
+
+	t.Run("invalid CLI config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "farseekrc")
+		if err := os.WriteFile(path, []byte("provider_grpc {\n  max_receive_message_mb = 0\n}\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("TF_CLI_CONFIG_FILE", path)
+		code, checks := run(t, "")
+		if code != 1 {
+			t.Errorf("wrong exit status %d", code)
+		}
+		check := checks["cli_config"]
+		if check.Status != doctorError {
+			t.Errorf("wrong cli_config status %q: %s", check.Status, check.Message)
+		}
+		if check.Fix == "" {
+			t.Error("cli_config error has no fix")
+		}
+	})
+
+	t.Run("missing plugin cache", func(t *testing.T) {
+		code, checks := run(t, filepath.Join(t.TempDir(), "missing"))
+		if code != 1 {
+			t.Errorf("wrong exit status %d", code)
+		}
+		if got := checks["plugin_cache"].Status; got != doctorError {
+			t.Errorf("wrong plugin_cache status %q: %s", got, checks["plugin_cache"].Message)
+		}
+	})
+}
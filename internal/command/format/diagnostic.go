@@ -84,7 +84,13 @@ func DiagnosticFromJSON(diag *jsonentities.Diagnostic, color *colorstring.Colori
 	// We don't wrap the summary, since we expect it to be terse, and since
 	// this is where we put the text of a native Go error it may not always
 	// be pure text that lends itself well to word-wrapping.
-	fmt.Fprintf(&buf, color.Color("[bold]%s[reset]\n\n"), ReplaceControlChars(diag.Summary))
+	fmt.Fprintf(&buf, color.Color("[bold]%s[reset]"), ReplaceControlChars(diag.Summary))
+	if diag.Code != "" {
+		// The code is added after colorizing, so that its brackets aren't
+		// taken for a color code.
+		fmt.Fprintf(&buf, " %s[%s]%s", color.Color("[dark_gray]"), diag.Code, color.Color("[reset]"))
+	}
+	buf.WriteString("\n\n")
 
 	appendSourceSnippets(&buf, diag, color)
 
@@ -102,6 +108,8 @@ func DiagnosticFromJSON(diag *jsonentities.Diagnostic, color *colorstring.Colori
 		}
 	}
 
+	appendAnnotations(&buf, diag)
+
 	// Before we return, we'll finally add the left rule prefixes to each
 	// line so that the overall message is visually delimited from what's
 	// around it. We'll do that by scanning over what we already generated
@@ -182,7 +190,11 @@ func DiagnosticPlainFromJSON(diag *jsonentities.Diagnostic, width int) string {
 	// We don't wrap the summary, since we expect it to be terse, and since
 	// this is where we put the text of a native Go error it may not always
 	// be pure text that lends itself well to word-wrapping.
-	fmt.Fprintf(&buf, "%s\n\n", diag.Summary)
+	fmt.Fprintf(&buf, "%s", diag.Summary)
+	if diag.Code != "" {
+		fmt.Fprintf(&buf, " [%s]", diag.Code)
+	}
+	buf.WriteString("\n\n")
 
 	appendSourceSnippets(&buf, diag, disabledColorize)
 
@@ -200,9 +212,32 @@ func DiagnosticPlainFromJSON(diag *jsonentities.Diagnostic, width int) string {
 		}
 	}
 
+	appendAnnotations(&buf, diag)
+
 	return buf.String()
 }
 
+// appendAnnotations lists the annotations of the resource that the
+// diagnostic is about, such as the team that owns it, so that whoever reads
+// the error knows who to turn to.
+func appendAnnotations(buf *bytes.Buffer, diag *jsonentities.Diagnostic) {
+	if len(diag.Annotations) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(diag.Annotations))
+	width := 0
+	for k := range diag.Annotations {
+		keys = append(keys, k)
+		width = max(width, len(k))
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("\nAnnotations:\n")
+	for _, k := range keys {
+		fmt.Fprintf(buf, "  %-*s = %s\n", width, ReplaceControlChars(k), ReplaceControlChars(diag.Annotations[k]))
+	}
+}
+
 // DiagnosticWarningsCompact is an alternative to Diagnostic for when all of
 // the given diagnostics are warnings and we want to show them compactly,
 // with only two lines per warning and excluding all of the detail information.
@@ -218,7 +253,11 @@ func DiagnosticWarningsCompact(diags tfdiags.Diagnostics, color *colorstring.Col
 	b.WriteString(color.Color("[bold][yellow]Warnings:[reset]\n\n"))
 	for _, diag := range diags {
 		sources := tfdiags.ConsolidatedGroupSourceRanges(diag)
-		b.WriteString(fmt.Sprintf("- %s\n", diag.Description().Summary))
+		if code := tfdiags.DiagnosticCode(diag); code != "" {
+			b.WriteString(fmt.Sprintf("- %s [%s]\n", diag.Description().Summary, code))
+		} else {
+			b.WriteString(fmt.Sprintf("- %s\n", diag.Description().Summary))
+		}
 		if len(sources) > 0 {
 			mainSource := sources[0]
 			if mainSource.Subject != nil {
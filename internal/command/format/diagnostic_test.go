@@ -44,6 +44,36 @@ func TestDiagnostic(t *testing.T) {
 [red]│[reset] does have a pretty long detail that
 [red]│[reset] should wrap over multiple lines.
 [red]╵[reset]
+`,
+		},
+		"sourceless error with code": {
+			tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Error,
+				"A coded error",
+				"It has a code.",
+			), "FS9999"),
+			`[red]╷[reset]
+[red]│[reset] [bold][red]Error: [reset][bold]A coded error[reset] [dark_gray][FS9999][reset]
+[red]│[reset]
+[red]│[reset] It has a code.
+[red]╵[reset]
+`,
+		},
+		"sourceless error with annotations": {
+			tfdiags.WithAnnotations(tfdiags.Diagnostics{tfdiags.Sourceless(
+				tfdiags.Error,
+				"An annotated error",
+				"It is about an annotated resource.",
+			)}, map[string]string{"team": "payments", "runbook": "https://example.com"})[0],
+			`[red]╷[reset]
+[red]│[reset] [bold][red]Error: [reset][bold]An annotated error[reset]
+[red]│[reset]
+[red]│[reset] It is about an annotated resource.
+[red]│[reset]
+[red]│[reset] Annotations:
+[red]│[reset]   runbook = https://example.com
+[red]│[reset]   team    = payments
+[red]╵[reset]
 `,
 		},
 		"sourceless warning": {
@@ -503,6 +533,34 @@ Error: A sourceless error
 It has no source references but it does
 have a pretty long detail that should
 wrap over multiple lines.
+`,
+		},
+		"sourceless error with code": {
+			tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Error,
+				"A coded error",
+				"It has a code.",
+			), "FS9999"),
+			`
+Error: A coded error [FS9999]
+
+It has a code.
+`,
+		},
+		"sourceless error with annotations": {
+			tfdiags.WithAnnotations(tfdiags.Diagnostics{tfdiags.Sourceless(
+				tfdiags.Error,
+				"An annotated error",
+				"It is about an annotated resource.",
+			)}, map[string]string{"team": "payments", "runbook": "https://example.com"})[0],
+			`
+Error: An annotated error
+
+It is about an annotated resource.
+
+Annotations:
+  runbook = https://example.com
+  team    = payments
 `,
 		},
 		"sourceless warning": {
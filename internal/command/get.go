@@ -30,6 +30,7 @@ func (c *GetCommand) Run(args []string) int {
 	cmdFlags := c.Meta.defaultFlagSet("get")
 	c.Meta.varFlagSet(cmdFlags)
 	cmdFlags.BoolVar(&update, "update", false, "update")
+	cmdFlags.BoolVar(&c.Meta.Offline, "offline", c.Meta.Offline, "offline")
 	cmdFlags.StringVar(&testsDirectory, "test-directory", "tests", "test-directory")
 	cmdFlags.BoolVar(&c.outputInJSON, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
@@ -92,6 +93,10 @@ Options:
 
   -no-color             Disable text coloring in the output.
 
+  -offline              Fail instead of installing modules over the network,
+                        naming each module that requires network access.
+                        Setting FARSEEK_OFFLINE=1 has the same effect.
+
   -test-directory=path  Set the Farseek test directory, defaults to "tests". When set, the
                         test command will search for test files in the current directory and
                         in the one specified by the flag.
@@ -34,6 +34,8 @@ func (c *GraphCommand) Run(args []string) int {
 	var moduleDepth int
 	var verbose bool
 	var planPath string
+	var format string
+	var grouping string
 
 	ctx := c.CommandContext()
 
@@ -45,12 +47,48 @@ func (c *GraphCommand) Run(args []string) int {
 	cmdFlags.IntVar(&moduleDepth, "module-depth", -1, "module-depth")
 	cmdFlags.BoolVar(&verbose, "verbose", false, "verbose")
 	cmdFlags.StringVar(&planPath, "plan", "", "plan")
+	cmdFlags.StringVar(&format, "format", "dot", "format")
+	cmdFlags.StringVar(&grouping, "grouping", "module", "grouping")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
 		return 1
 	}
 
+	switch format {
+	case "dot":
+	case "mermaid", "svg":
+		if graphTypeStr != "" && graphTypeStr != "plan" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible graph options",
+				fmt.Sprintf("The -format=%s option draws the resources of the configuration, so it only supports -type=plan.", format),
+			))
+		}
+		// The diagram shows the configuration, so it uses the plan graph
+		// even when rendering a plan file.
+		graphTypeStr = "plan"
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported graph format",
+			`The -format=... argument must be either "dot", "mermaid", or "svg".`,
+		))
+	}
+	switch grouping {
+	case "module", "none":
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported graph grouping",
+			`The -grouping=... argument must be either "module" or "none".`,
+		))
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
 	configPath, err := modulePath(cmdFlags.Args())
 	if err != nil {
 		c.Ui.Error(err.Error())
@@ -226,6 +264,16 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
+	if format != "dot" {
+		diagram := newGraphDiagram(g, lr.Config)
+		if format == "mermaid" {
+			c.Ui.Output(diagram.Mermaid(grouping == "module"))
+		} else {
+			c.Ui.Output(diagram.SVG(grouping == "module"))
+		}
+		return 0
+	}
+
 	graphStr, err := farseek.GraphDot(g, &dag.DotOpts{
 		DrawCycles: drawCycles,
 		MaxDepth:   moduleDepth,
@@ -256,15 +304,28 @@ Usage: farseek [global options] graph [options]
   Produces a representation of the dependency graph between different
   objects in the current configuration and state.
 
-  The graph is presented in the DOT language. The typical program that can
-  read this format is GraphViz, but many web services are also available
-  to read this format.
+  The graph is presented in the DOT language by default. The typical program
+  that can read this format is GraphViz, but many web services are also
+  available to read this format.
+
+  For large configurations, -format=mermaid and -format=svg draw a simpler
+  diagram of only the resources of the configuration and the dependencies
+  between them.
 
 Options:
 
   -plan=tfplan     Render graph using the specified plan file instead of the
                    configuration in the current directory.
 
+  -format=dot      Format of the output. Can be: dot, mermaid, or svg. The
+                   mermaid and svg formats draw the resources of the
+                   configuration, with data sources dashed, rather than the
+                   graph of the steps of an operation.
+
+  -grouping=module How to group the resources in the mermaid and svg
+                   formats. Can be: module, to draw each module as a box
+                   around its resources, or none.
+
   -draw-cycles     Highlight any cycles in the graph with colored edges.
                    This helps when diagnosing cycle errors.
 
@@ -0,0 +1,390 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/dag"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+)
+
+// graphDiagram is a diagram of the resources of a configuration and the
+// dependencies between them, for the -format=mermaid and -format=svg
+// options of the graph command. Unlike the DOT output, which shows every
+// node of Farseek's graph, it only shows the resources, and only the
+// dependencies between them that don't follow from others.
+type graphDiagram struct {
+	// nodes are sorted by the address of their resource.
+	nodes []*graphDiagramNode
+}
+
+type graphDiagramNode struct {
+	addr addrs.ConfigResource
+	id   string
+
+	// dashed is set for the resources that Farseek reads rather than
+	// manages: data sources and ephemeral resources.
+	dashed bool
+
+	// deps are the resources that this one depends on, sorted like the
+	// nodes of the diagram.
+	deps []*graphDiagramNode
+}
+
+func (n *graphDiagramNode) label() string {
+	return n.addr.Resource.String()
+}
+
+// newGraphDiagram returns the diagram of the resources of the given
+// configuration, with the dependencies that the given graph has between
+// them, either directly or through other nodes such as local values.
+func newGraphDiagram(g *farseek.Graph, config *configs.Config) *graphDiagram {
+	byAddr := make(map[string]*graphDiagramNode)
+	vertexNodes := make(map[dag.Vertex]*graphDiagramNode)
+	for _, v := range g.Vertices() {
+		rv, ok := v.(farseek.GraphNodeConfigResource)
+		if !ok {
+			continue
+		}
+		addr := rv.ResourceAddr()
+		if !graphDiagramHasResource(config, addr) {
+			// Resources that are only in the state aren't part of the
+			// configuration we're drawing.
+			continue
+		}
+		key := addr.String()
+		node, ok := byAddr[key]
+		if !ok {
+			node = &graphDiagramNode{
+				addr:   addr,
+				dashed: addr.Resource.Mode != addrs.ManagedResourceMode,
+			}
+			byAddr[key] = node
+		}
+		vertexNodes[v] = node
+	}
+
+	// Each resource depends on the resources it can reach through nodes
+	// that aren't resources. We remember what each of those nodes reaches,
+	// because large configurations route many dependencies through the same
+	// local values and variables.
+	reached := make(map[dag.Vertex][]*graphDiagramNode)
+	var reach func(v dag.Vertex) []*graphDiagramNode
+	reach = func(v dag.Vertex) []*graphDiagramNode {
+		if ret, ok := reached[v]; ok {
+			return ret
+		}
+		reached[v] = nil // in case of cycles, which a valid graph doesn't have
+		var ret []*graphDiagramNode
+		for _, dep := range g.DownEdges(v) {
+			if node, ok := vertexNodes[dep]; ok {
+				ret = append(ret, node)
+				continue
+			}
+			ret = append(ret, reach(dep)...)
+		}
+		reached[v] = ret
+		return ret
+	}
+
+	var deps dag.AcyclicGraph
+	for _, node := range byAddr {
+		deps.Add(node)
+	}
+	for v, node := range vertexNodes {
+		for _, dep := range g.DownEdges(v) {
+			targets := []*graphDiagramNode{vertexNodes[dep]}
+			if targets[0] == nil {
+				targets = reach(dep)
+			}
+			for _, target := range targets {
+				if target != node {
+					deps.Connect(dag.BasicEdge(node, target))
+				}
+			}
+		}
+	}
+	deps.TransitiveReduction()
+
+	d := &graphDiagram{}
+	for _, node := range byAddr {
+		d.nodes = append(d.nodes, node)
+	}
+	sortGraphDiagramNodes(d.nodes)
+	for i, node := range d.nodes {
+		node.id = fmt.Sprintf("n%d", i)
+	}
+	for _, node := range d.nodes {
+		for _, dep := range deps.DownEdges(node) {
+			node.deps = append(node.deps, dep.(*graphDiagramNode))
+		}
+		sortGraphDiagramNodes(node.deps)
+	}
+	return d
+}
+
+func graphDiagramHasResource(config *configs.Config, addr addrs.ConfigResource) bool {
+	mc := config.Descendent(addr.Module)
+	return mc != nil && mc.Module != nil && mc.Module.ResourceByAddr(addr.Resource) != nil
+}
+
+func sortGraphDiagramNodes(nodes []*graphDiagramNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].addr.String() < nodes[j].addr.String()
+	})
+}
+
+// graphDiagramModule is a module of the diagram, when its resources are
+// grouped by module.
+type graphDiagramModule struct {
+	path     addrs.Module
+	nodes    []*graphDiagramNode
+	children []*graphDiagramModule
+}
+
+// modules returns the root module of the diagram, with the modules called
+// from it that contain resources, directly or indirectly.
+func (d *graphDiagram) modules() *graphDiagramModule {
+	root := &graphDiagramModule{path: addrs.RootModule}
+	byPath := map[string]*graphDiagramModule{"": root}
+	var module func(path addrs.Module) *graphDiagramModule
+	module = func(path addrs.Module) *graphDiagramModule {
+		if m, ok := byPath[path.String()]; ok {
+			return m
+		}
+		parent := module(path.Parent())
+		m := &graphDiagramModule{path: path}
+		parent.children = append(parent.children, m)
+		byPath[path.String()] = m
+		return m
+	}
+	for _, node := range d.nodes {
+		m := module(node.addr.Module)
+		m.nodes = append(m.nodes, node)
+	}
+	var sortChildren func(m *graphDiagramModule)
+	sortChildren = func(m *graphDiagramModule) {
+		sort.Slice(m.children, func(i, j int) bool {
+			return m.children[i].path.String() < m.children[j].path.String()
+		})
+		for _, child := range m.children {
+			sortChildren(child)
+		}
+	}
+	sortChildren(root)
+	return root
+}
+
+// Mermaid returns the diagram as a Mermaid flowchart. If groupModules is
+// set, the resources of each module are in a subgraph of their own, nested
+// like the modules are.
+func (d *graphDiagram) Mermaid(groupModules bool) string {
+	var buf strings.Builder
+	// Dependencies are drawn on the left of the resources that depend on
+	// them, as in the SVG diagram.
+	buf.WriteString("flowchart RL\n")
+
+	writeNode := func(node *graphDiagramNode, indent string) {
+		fmt.Fprintf(&buf, "%s%s[\"%s\"]\n", indent, node.id, mermaidText(node.label()))
+	}
+	if groupModules {
+		numModules := 0
+		var writeModule func(m *graphDiagramModule, indent string)
+		writeModule = func(m *graphDiagramModule, indent string) {
+			for _, node := range m.nodes {
+				writeNode(node, indent)
+			}
+			for _, child := range m.children {
+				fmt.Fprintf(&buf, "%ssubgraph m%d[\"%s\"]\n", indent, numModules, mermaidText(child.path.String()))
+				numModules++
+				writeModule(child, indent+"  ")
+				fmt.Fprintf(&buf, "%send\n", indent)
+			}
+		}
+		writeModule(d.modules(), "  ")
+	} else {
+		for _, node := range d.nodes {
+			// Without the modules around them, the resources need their
+			// full address.
+			fmt.Fprintf(&buf, "  %s[\"%s\"]\n", node.id, mermaidText(node.addr.String()))
+		}
+	}
+
+	for _, node := range d.nodes {
+		for _, dep := range node.deps {
+			fmt.Fprintf(&buf, "  %s --> %s\n", node.id, dep.id)
+		}
+	}
+
+	var dashed []string
+	for _, node := range d.nodes {
+		if node.dashed {
+			dashed = append(dashed, node.id)
+		}
+	}
+	if len(dashed) > 0 {
+		buf.WriteString("  classDef read stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&buf, "  class %s read\n", strings.Join(dashed, ","))
+	}
+	return buf.String()
+}
+
+// mermaidText escapes the given text for a quoted Mermaid label.
+func mermaidText(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+// The dimensions of the SVG diagram, in pixels.
+const (
+	svgMargin        = 20
+	svgNodeHeight    = 36
+	svgNodePadding   = 12
+	svgCharWidth     = 7
+	svgMinNodeWidth  = 120
+	svgColumnGap     = 60
+	svgRowGap        = 16
+	svgClusterPad    = 16
+	svgClusterTitle  = 24
+	svgClusterGap    = 20
+	svgFontSize      = 12
+	svgClusterRadius = 8
+)
+
+// SVG returns the diagram as an SVG image. Resources are laid out in
+// columns by how long the chain of dependencies behind them is, so that
+// each resource is to the right of the resources it depends on. If
+// groupModules is set, the resources of each module are in a box of their
+// own.
+func (d *graphDiagram) SVG(groupModules bool) string {
+	// The column of each resource is the length of the longest chain of
+	// dependencies behind it.
+	columns := make(map[*graphDiagramNode]int)
+	var column func(node *graphDiagramNode) int
+	column = func(node *graphDiagramNode) int {
+		if c, ok := columns[node]; ok {
+			return c
+		}
+		c := 0
+		for _, dep := range node.deps {
+			c = max(c, column(dep)+1)
+		}
+		columns[node] = c
+		return c
+	}
+	numColumns := 0
+	for _, node := range d.nodes {
+		numColumns = max(numColumns, column(node)+1)
+	}
+
+	type band struct {
+		title string
+		nodes []*graphDiagramNode
+	}
+	var bands []band
+	if groupModules {
+		var addBands func(m *graphDiagramModule)
+		addBands = func(m *graphDiagramModule) {
+			if len(m.nodes) > 0 {
+				bands = append(bands, band{title: m.path.String(), nodes: m.nodes})
+			}
+			for _, child := range m.children {
+				addBands(child)
+			}
+		}
+		addBands(d.modules())
+	} else if len(d.nodes) > 0 {
+		bands = append(bands, band{nodes: d.nodes})
+	}
+
+	labels := make(map[*graphDiagramNode]string, len(d.nodes))
+	widths := make([]int, numColumns)
+	for _, node := range d.nodes {
+		label := node.label()
+		if !groupModules {
+			label = node.addr.String()
+		}
+		labels[node] = label
+		c := columns[node]
+		widths[c] = max(widths[c], svgMinNodeWidth, len(label)*svgCharWidth+2*svgNodePadding)
+	}
+	xs := make([]int, numColumns)
+	x := svgMargin + svgClusterPad
+	for c, w := range widths {
+		xs[c] = x
+		x += w + svgColumnGap
+	}
+	width := max(x-svgColumnGap+svgClusterPad+svgMargin, 2*svgMargin)
+
+	type box struct{ x, y, w, h int }
+	boxes := make(map[*graphDiagramNode]box, len(d.nodes))
+	var clusters []struct {
+		title string
+		box   box
+	}
+	y := svgMargin
+	for _, b := range bands {
+		top := y
+		if b.title != "" {
+			y += svgClusterTitle
+		}
+		y += svgClusterPad
+		rows := make([]int, numColumns)
+		for _, node := range b.nodes {
+			c := columns[node]
+			boxes[node] = box{xs[c], y + rows[c]*(svgNodeHeight+svgRowGap), widths[c], svgNodeHeight}
+			rows[c]++
+		}
+		numRows := 0
+		for _, r := range rows {
+			numRows = max(numRows, r)
+		}
+		y += numRows*(svgNodeHeight+svgRowGap) - svgRowGap + svgClusterPad
+		if b.title != "" {
+			clusters = append(clusters, struct {
+				title string
+				box   box
+			}{b.title, box{svgMargin, top, width - 2*svgMargin, y - top}})
+		}
+		y += svgClusterGap
+	}
+	height := max(y-svgClusterGap+svgMargin, 2*svgMargin)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%d">`+"\n", width, height, width, height, svgFontSize)
+	buf.WriteString(`  <defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#555"/></marker></defs>` + "\n")
+	for _, cluster := range clusters {
+		b := cluster.box
+		fmt.Fprintf(&buf, `  <g class="module"><rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="#f4f6fa" stroke="#9aa5b8"/><text x="%d" y="%d" font-weight="bold">%s</text></g>`+"\n",
+			b.x, b.y, b.w, b.h, svgClusterRadius, b.x+svgClusterPad, b.y+svgClusterTitle-6, html.EscapeString(cluster.title))
+	}
+	for _, node := range d.nodes {
+		from := boxes[node]
+		for _, dep := range node.deps {
+			to := boxes[dep]
+			x1, y1 := from.x, from.y+from.h/2
+			x2, y2 := to.x+to.w, to.y+to.h/2
+			mid := (x1 + x2) / 2
+			fmt.Fprintf(&buf, `  <path class="dependency" d="M %d %d C %d %d, %d %d, %d %d" fill="none" stroke="#555" marker-end="url(#arrow)"/>`+"\n", x1, y1, mid, y1, mid, y2, x2, y2)
+		}
+	}
+	for _, node := range d.nodes {
+		b := boxes[node]
+		dash := ""
+		class := "resource"
+		if node.dashed {
+			dash = ` stroke-dasharray="5 5"`
+			class = "data"
+		}
+		fmt.Fprintf(&buf, `  <g class="%s"><title>%s</title><rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="#ffffff" stroke="#333"%s/><text x="%d" y="%d" text-anchor="middle">%s</text></g>`+"\n",
+			class, html.EscapeString(node.addr.String()), b.x, b.y, b.w, b.h, dash, b.x+b.w/2, b.y+b.h/2+svgFontSize/3, html.EscapeString(labels[node]))
+	}
+	buf.WriteString("</svg>\n")
+	return buf.String()
+}
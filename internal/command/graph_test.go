@@ -8,6 +8,7 @@
 package command
 
 import (
+	"encoding/xml"
 	"strings"
 	"testing"
 
@@ -15,7 +16,10 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/providers"
 	"github.com/rafagsiqueira/farseek/internal/states"
 )
 
@@ -130,6 +134,7 @@ func TestGraph_plan(t *testing.T) {
 	beConfig := cty.ObjectVal(map[string]cty.Value{
 		"path":          cty.NilVal,
 		"workspace_dir": cty.NilVal,
+		"workspaces":    cty.NilVal,
 	})
 	emptyConfig, err := plans.NewDynamicValue(beConfig, beConfig.Type())
 	if err != nil {
@@ -163,3 +168,137 @@ func TestGraph_plan(t *testing.T) {
 		t.Fatalf("doesn't look like digraph: %s", output)
 	}
 }
+
+func graphDiagramProvider() *farseek.MockProvider {
+	p := applyFixtureProvider()
+	p.GetProviderSchemaResponse.DataSources = map[string]providers.Schema{
+		"test_data_source": {
+			Block: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Required: true},
+				},
+			},
+		},
+	}
+	return p
+}
+
+func TestGraph_mermaid(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph-diagram"), td)
+	t.Chdir(td)
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(graphDiagramProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-format=mermaid"}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	got := strings.TrimSpace(ui.OutputWriter.String())
+	want := strings.TrimSpace(`
+flowchart RL
+  n0["data.test_data_source.lookup"]
+  n2["test_instance.foo"]
+  subgraph m0["module.app"]
+    n1["test_instance.web"]
+  end
+  n1 --> n2
+  n2 --> n0
+  classDef read stroke-dasharray: 5 5
+  class n0 read
+`)
+	if got != want {
+		t.Fatalf("wrong output\ngot:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestGraph_mermaidNoGrouping(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph-diagram"), td)
+	t.Chdir(td)
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(graphDiagramProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-format=mermaid", "-grouping=none"}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if strings.Contains(output, "subgraph") {
+		t.Errorf("unexpected subgraph in output:\n%s", output)
+	}
+	if !strings.Contains(output, `n1["module.app.test_instance.web"]`) {
+		t.Errorf("missing full address in output:\n%s", output)
+	}
+}
+
+func TestGraph_svg(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("graph-diagram"), td)
+	t.Chdir(td)
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(graphDiagramProvider()),
+			Ui:               ui,
+		},
+	}
+
+	if code := c.Run([]string{"-format=svg"}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if err := xml.Unmarshal([]byte(output), new(struct{})); err != nil {
+		t.Fatalf("invalid SVG: %s\n%s", err, output)
+	}
+	for _, want := range []string{
+		`<g class="module">`,
+		`>module.app</text>`,
+		`<g class="data"><title>data.test_data_source.lookup</title>`,
+		`stroke-dasharray="5 5"`,
+		`<g class="resource"><title>module.app.test_instance.web</title>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in output:\n%s", want, output)
+		}
+	}
+	if got, want := strings.Count(output, `class="dependency"`), 2; got != want {
+		t.Errorf("wrong number of dependencies %d; want %d", got, want)
+	}
+}
+
+func TestGraph_invalidFormat(t *testing.T) {
+	for args, want := range map[string]string{
+		"-format=png":                    "Unsupported graph format",
+		"-grouping=provider":             "Unsupported graph grouping",
+		"-format=svg -type=plan-destroy": "Incompatible graph options",
+	} {
+		ui := new(cli.MockUi)
+		c := &GraphCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+				Ui:               ui,
+			},
+		}
+		if code := c.Run(strings.Fields(args)); code != 1 {
+			t.Errorf("%s: wrong exit status %d; want 1", args, code)
+		}
+		if got := ui.ErrorWriter.String(); !strings.Contains(got, want) {
+			t.Errorf("%s: missing error %q in:\n%s", args, want, got)
+		}
+	}
+}
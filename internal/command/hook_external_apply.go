@@ -0,0 +1,384 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// defaultExternalApplyHookTimeout is how long an external apply hook may run
+// when its configuration doesn't say.
+const defaultExternalApplyHookTimeout = time.Minute
+
+// externalApplyHookWaitDelay is how long we wait for the output of an
+// external apply hook once it's killed, in case it started other processes
+// that still hold its stdout or stderr open.
+const externalApplyHookWaitDelay = time.Second
+
+// ExternalApplyHook describes an external program to run before or after
+// each change that apply makes to a resource, as configured in a
+// "pre_apply" or "post_apply" block of a "hooks" block in the CLI
+// configuration.
+//
+// The program is run with the given arguments and receives a JSON object
+// describing the change on its stdin. A program that exits with a non-zero
+// status, or that runs for longer than Timeout, has failed.
+type ExternalApplyHook struct {
+	// ResourceTypes are glob patterns, as in path.Match, of the resource
+	// types whose changes run the program. If empty, every change does.
+	ResourceTypes []string
+
+	Command string
+	Args    []string
+	Timeout time.Duration
+
+	// ContinueOnFailure makes a failure of the program a warning, rather
+	// than an error.
+	ContinueOnFailure bool
+}
+
+// externalApplyHookPayload is the JSON object that an ExternalApplyHook
+// receives on its stdin.
+type externalApplyHookPayload struct {
+	Hook         string          `json:"hook"`
+	Address      string          `json:"address"`
+	ResourceType string          `json:"resource_type"`
+	Action       string          `json:"action"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	Error        string          `json:"error,omitempty"`
+
+	// Annotations are those that the resource declares in its
+	// farseek_annotations argument.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ReplaceReason is the reason given with the -replace option for a
+	// replacement.
+	ReplaceReason string `json:"replace_reason,omitempty"`
+}
+
+// matches returns true if the program should run for changes to resources
+// of the given type.
+func (h *ExternalApplyHook) matches(resourceType string) bool {
+	if len(h.ResourceTypes) == 0 {
+		return true
+	}
+	for _, pattern := range h.ResourceTypes {
+		if ok, _ := path.Match(pattern, resourceType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// run runs the program with the given payload on its stdin.
+func (h *ExternalApplyHook) run(ctx context.Context, payload []byte) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultExternalApplyHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	outBuf := bytes.Buffer{}
+	errBuf := bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, h.Command, h.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	cmd.WaitDelay = externalApplyHookWaitDelay
+	err := cmd.Run()
+	if out := strings.TrimSpace(outBuf.String()); out != "" {
+		log.Printf("[DEBUG] Output of apply hook %s:\n%s", h.Command, out)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s did not finish within %s", h.Command, timeout)
+	}
+	if _, isExitErr := err.(*exec.ExitError); isExitErr {
+		errText := strings.TrimSpace(errBuf.String())
+		if errText == "" {
+			return fmt.Errorf("error in %s, but it produced no error message", h.Command)
+		}
+		return fmt.Errorf("error in %s: %s", h.Command, errText)
+	} else if err != nil {
+		return fmt.Errorf("failed to run %s: %w", h.Command, err)
+	}
+	return nil
+}
+
+// externalApplyHooks is a farseek.Hook that runs the configured external
+// programs before and after each change to a resource.
+//
+// A failing pre_apply program stops the change it was run for, unless it's
+// configured to continue on failure, so this hook must come before any
+// other hook that reacts to PreApply. A post_apply program runs once the
+// change is made and can't undo it, so its failures are collected instead,
+// to report once the apply is complete.
+type externalApplyHooks struct {
+	farseek.NilHook
+
+	pre, post []*ExternalApplyHook
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// annotations returns the annotations of a resource in the
+	// configuration, once the apply has begun.
+	annotations func(addrs.ConfigResource) map[string]string
+
+	// replaceReasons are the reasons given with the -replace option, by the
+	// address of the resource instance, once the apply has begun.
+	replaceReasons map[string]string
+
+	mu      sync.Mutex
+	changes map[string]externalApplyHookChange
+	diags   tfdiags.Diagnostics
+}
+
+// externalApplyHookChange is what externalApplyHooks remembers about a
+// change between its PreApply and PostApply.
+type externalApplyHookChange struct {
+	action     plans.Action
+	priorState cty.Value
+}
+
+var _ farseek.AnnotationsHook = (*externalApplyHooks)(nil)
+var _ farseek.ReplaceReasonsHook = (*externalApplyHooks)(nil)
+
+// externalApplyHooks returns the hook that runs the apply hooks from the
+// CLI configuration, or nil if there are none.
+func (m *Meta) externalApplyHooks() *externalApplyHooks {
+	if len(m.PreApplyHooks) == 0 && len(m.PostApplyHooks) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &externalApplyHooks{
+		pre:     m.PreApplyHooks,
+		post:    m.PostApplyHooks,
+		ctx:     ctx,
+		cancel:  cancel,
+		changes: make(map[string]externalApplyHookChange),
+	}
+}
+
+func (h *externalApplyHooks) SetAnnotations(annotations func(addrs.ConfigResource) map[string]string) {
+	h.annotations = annotations
+}
+
+func (h *externalApplyHooks) SetReplaceReasons(reasons map[string]string) {
+	h.replaceReasons = reasons
+}
+
+func (h *externalApplyHooks) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (farseek.HookAction, error) {
+	if action == plans.NoOp {
+		return farseek.HookActionContinue, nil
+	}
+	resourceType := addr.Resource.Resource.Type
+
+	var payload []byte
+	for _, hook := range h.pre {
+		if !hook.matches(resourceType) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = externalApplyHookPayloadJSON("pre_apply", addr, action, priorState, plannedNewState, nil, h.resourceAnnotations(addr), h.replaceReason(addr, action))
+			if err != nil {
+				return farseek.HookActionHalt, fmt.Errorf("failed to describe the change to %s for apply hooks: %w", addr, err)
+			}
+		}
+		if err := hook.run(h.ctx, payload); err != nil {
+			if hook.ContinueOnFailure {
+				h.warn(addr, "pre_apply", err)
+				continue
+			}
+			return farseek.HookActionHalt, fmt.Errorf("The pre_apply hook stopped the change to %s: %w", addr, err)
+		}
+	}
+
+	h.mu.Lock()
+	h.changes[externalApplyHookKey(addr, gen)] = externalApplyHookChange{
+		action:     action,
+		priorState: priorState,
+	}
+	h.mu.Unlock()
+	return farseek.HookActionContinue, nil
+}
+
+func (h *externalApplyHooks) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, applyErr error) (farseek.HookAction, error) {
+	key := externalApplyHookKey(addr, gen)
+	h.mu.Lock()
+	change, ok := h.changes[key]
+	delete(h.changes, key)
+	h.mu.Unlock()
+	if !ok {
+		// We only run post_apply programs for the changes we saw start.
+		return farseek.HookActionContinue, nil
+	}
+	resourceType := addr.Resource.Resource.Type
+
+	var payload []byte
+	for _, hook := range h.post {
+		if !hook.matches(resourceType) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = externalApplyHookPayloadJSON("post_apply", addr, change.action, change.priorState, newState, applyErr, h.resourceAnnotations(addr), h.replaceReason(addr, change.action))
+			if err != nil {
+				h.fail(addr, "post_apply", fmt.Errorf("failed to describe the change: %w", err))
+				break
+			}
+		}
+		if err := hook.run(h.ctx, payload); err != nil {
+			if hook.ContinueOnFailure {
+				h.warn(addr, "post_apply", err)
+				continue
+			}
+			h.fail(addr, "post_apply", err)
+		}
+	}
+	return farseek.HookActionContinue, nil
+}
+
+// Stopping cancels the programs that are running, since Farseek is
+// shutting down.
+func (h *externalApplyHooks) Stopping() {
+	h.cancel()
+}
+
+// Diagnostics returns the failures of the post_apply programs, and the
+// failures of the programs that continue on failure, as errors and
+// warnings respectively.
+func (h *externalApplyHooks) Diagnostics() tfdiags.Diagnostics {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.diags
+}
+
+func (h *externalApplyHooks) resourceAnnotations(addr addrs.AbsResourceInstance) map[string]string {
+	if h.annotations == nil {
+		return nil
+	}
+	return h.annotations(addr.ConfigResource())
+}
+
+// replaceReason returns the reason given for a replacement of the resource
+// instance, if the action is one.
+func (h *externalApplyHooks) replaceReason(addr addrs.AbsResourceInstance, action plans.Action) string {
+	if !action.IsReplace() {
+		return ""
+	}
+	return h.replaceReasons[addr.String()]
+}
+
+func (h *externalApplyHooks) warn(addr addrs.AbsResourceInstance, event string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.diags = h.diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		fmt.Sprintf("The %s hook failed", event),
+		fmt.Sprintf("The %s hook for %s failed, but is configured to continue on failure: %s.", event, addr, err),
+	))
+}
+
+func (h *externalApplyHooks) fail(addr addrs.AbsResourceInstance, event string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.diags = h.diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		fmt.Sprintf("The %s hook failed", event),
+		fmt.Sprintf("The %s hook for %s failed after the change was made: %s.", event, addr, err),
+	))
+}
+
+func externalApplyHookKey(addr addrs.AbsResourceInstance, gen states.Generation) string {
+	if key, ok := gen.(states.DeposedKey); ok {
+		return addr.String() + " (deposed " + key.String() + ")"
+	}
+	return addr.String()
+}
+
+// externalApplyHookPayloadJSON returns the JSON object describing a change
+// that the programs for the given event receive.
+func externalApplyHookPayloadJSON(event string, addr addrs.AbsResourceInstance, action plans.Action, before, after cty.Value, applyErr error, annotations map[string]string, replaceReason string) ([]byte, error) {
+	payload := externalApplyHookPayload{
+		Hook:          event,
+		Address:       addr.String(),
+		ResourceType:  addr.Resource.Resource.Type,
+		Action:        externalApplyHookAction(action),
+		Annotations:   annotations,
+		ReplaceReason: replaceReason,
+	}
+	var err error
+	if payload.Before, err = externalApplyHookValueJSON(before); err != nil {
+		return nil, err
+	}
+	if payload.After, err = externalApplyHookValueJSON(after); err != nil {
+		return nil, err
+	}
+	if applyErr != nil {
+		payload.Error = applyErr.Error()
+	}
+	return json.Marshal(payload)
+}
+
+func externalApplyHookAction(action plans.Action) string {
+	switch {
+	case action.IsReplace():
+		return "replace"
+	case action == plans.ForgetThenCreate:
+		return "forget_then_create"
+	default:
+		return strings.ToLower(action.String())
+	}
+}
+
+// externalApplyHookValueJSON returns the given resource object as JSON. The
+// values that aren't known yet, and those that are sensitive, are null.
+func externalApplyHookValueJSON(v cty.Value) (json.RawMessage, error) {
+	if v == cty.NilVal || v.IsNull() {
+		return json.RawMessage("null"), nil
+	}
+	v, pvms := v.UnmarkDeepWithPaths()
+	v, err := cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			return cty.NullVal(v.Type()), nil
+		}
+		for _, pvm := range pvms {
+			if _, sensitive := pvm.Marks[marks.Sensitive]; sensitive && pvm.Path.Equals(p) {
+				return cty.NullVal(v.Type()), nil
+			}
+		}
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ctyjson.Marshal(v, v.Type())
+}
@@ -0,0 +1,227 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestExternalApplyHooks(t *testing.T) {
+	td := t.TempDir()
+	prePayload := filepath.Join(td, "pre.json")
+	postPayload := filepath.Join(td, "post.json")
+
+	m := &Meta{
+		PreApplyHooks: []*ExternalApplyHook{
+			{
+				ResourceTypes: []string{"test_*"},
+				Command:       "sh",
+				Args:          []string{"-c", "cat > " + prePayload},
+			},
+			{
+				ResourceTypes: []string{"other_*"},
+				Command:       "sh",
+				Args:          []string{"-c", "exit 1"},
+			},
+		},
+		PostApplyHooks: []*ExternalApplyHook{
+			{
+				Command: "sh",
+				Args:    []string{"-c", "cat > " + postPayload},
+			},
+		},
+	}
+	h := m.externalApplyHooks()
+	h.SetAnnotations(func(addr addrs.ConfigResource) map[string]string {
+		return map[string]string{"team": "payments"}
+	})
+	h.SetReplaceReasons(map[string]string{"test_instance.foo": "kernel upgrade"})
+
+	addr := mustResourceInstanceAddr("test_instance.foo")
+	prior := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("a"),
+		"password": cty.StringVal("secret").Mark(marks.Sensitive),
+	})
+	planned := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.UnknownVal(cty.String),
+		"password": cty.StringVal("secret").Mark(marks.Sensitive),
+	})
+	action, err := h.PreApply(addr, states.CurrentGen, plans.CreateThenDelete, prior, planned)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if action != farseek.HookActionContinue {
+		t.Fatalf("wrong action %v", action)
+	}
+
+	newState := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("b"),
+		"password": cty.StringVal("secret").Mark(marks.Sensitive),
+	})
+	if _, err := h.PostApply(addr, states.CurrentGen, newState, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diags := h.Diagnostics(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.ErrWithWarnings())
+	}
+
+	for path, want := range map[string]map[string]any{
+		prePayload: {
+			"hook":           "pre_apply",
+			"address":        "test_instance.foo",
+			"resource_type":  "test_instance",
+			"action":         "replace",
+			"before":         map[string]any{"id": "a", "password": nil},
+			"after":          map[string]any{"id": nil, "password": nil},
+			"annotations":    map[string]any{"team": "payments"},
+			"replace_reason": "kernel upgrade",
+		},
+		postPayload: {
+			"hook":           "post_apply",
+			"address":        "test_instance.foo",
+			"resource_type":  "test_instance",
+			"action":         "replace",
+			"before":         map[string]any{"id": "a", "password": nil},
+			"after":          map[string]any{"id": "b", "password": nil},
+			"annotations":    map[string]any{"team": "payments"},
+			"replace_reason": "kernel upgrade",
+		},
+	} {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(src, &got); err != nil {
+			t.Fatalf("invalid payload: %s", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong payload in %s\n%s", filepath.Base(path), diff)
+		}
+	}
+}
+
+func TestExternalApplyHooks_noOp(t *testing.T) {
+	m := &Meta{
+		PreApplyHooks: []*ExternalApplyHook{
+			{Command: "sh", Args: []string{"-c", "exit 1"}},
+		},
+		PostApplyHooks: []*ExternalApplyHook{
+			{Command: "sh", Args: []string{"-c", "exit 1"}},
+		},
+	}
+	h := m.externalApplyHooks()
+
+	addr := mustResourceInstanceAddr("test_instance.foo")
+	val := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("a")})
+	if _, err := h.PreApply(addr, states.CurrentGen, plans.NoOp, val, val); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := h.PostApply(addr, states.CurrentGen, val, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diags := h.Diagnostics(); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %s", diags.ErrWithWarnings())
+	}
+}
+
+func TestExternalApplyHooks_failures(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_instance.foo")
+	val := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("a")})
+
+	t.Run("pre_apply fails", func(t *testing.T) {
+		m := &Meta{
+			PreApplyHooks: []*ExternalApplyHook{
+				{Command: "sh", Args: []string{"-c", "echo change not approved >&2; exit 1"}},
+			},
+		}
+		h := m.externalApplyHooks()
+		_, err := h.PreApply(addr, states.CurrentGen, plans.Create, cty.NullVal(val.Type()), val)
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		if got, want := err.Error(), "change not approved"; !strings.Contains(got, want) {
+			t.Errorf("wrong error %q; want to contain %q", got, want)
+		}
+	})
+
+	t.Run("pre_apply times out", func(t *testing.T) {
+		m := &Meta{
+			PreApplyHooks: []*ExternalApplyHook{
+				{Command: "sh", Args: []string{"-c", "exec sleep 5"}, Timeout: 100 * time.Millisecond},
+			},
+		}
+		h := m.externalApplyHooks()
+		_, err := h.PreApply(addr, states.CurrentGen, plans.Create, cty.NullVal(val.Type()), val)
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		if got, want := err.Error(), "did not finish within 100ms"; !strings.Contains(got, want) {
+			t.Errorf("wrong error %q; want to contain %q", got, want)
+		}
+	})
+
+	t.Run("pre_apply continues", func(t *testing.T) {
+		m := &Meta{
+			PreApplyHooks: []*ExternalApplyHook{
+				{Command: "sh", Args: []string{"-c", "exit 1"}, ContinueOnFailure: true},
+			},
+		}
+		h := m.externalApplyHooks()
+		if _, err := h.PreApply(addr, states.CurrentGen, plans.Create, cty.NullVal(val.Type()), val); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		diags := h.Diagnostics()
+		if len(diags) != 1 || diags[0].Severity() != tfdiags.Warning {
+			t.Fatalf("want one warning; got %#v", diags)
+		}
+	})
+
+	t.Run("post_apply fails", func(t *testing.T) {
+		m := &Meta{
+			PostApplyHooks: []*ExternalApplyHook{
+				{Command: "sh", Args: []string{"-c", "echo cmdb unavailable >&2; exit 1"}},
+			},
+		}
+		h := m.externalApplyHooks()
+		if _, err := h.PreApply(addr, states.CurrentGen, plans.Create, cty.NullVal(val.Type()), val); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		// The change was already made, so the failure mustn't interrupt
+		// the other hooks.
+		if _, err := h.PostApply(addr, states.CurrentGen, val, nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		diags := h.Diagnostics()
+		if !diags.HasErrors() {
+			t.Fatal("want error diagnostics")
+		}
+		if got, want := diags.Err().Error(), "cmdb unavailable"; !strings.Contains(got, want) {
+			t.Errorf("wrong error %q; want to contain %q", got, want)
+		}
+	})
+}
+
+func mustResourceInstanceAddr(s string) addrs.AbsResourceInstance {
+	addr, diags := addrs.ParseAbsResourceInstanceStr(s)
+	if diags.HasErrors() {
+		panic(diags.Err())
+	}
+	return addr
+}
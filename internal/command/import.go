@@ -16,6 +16,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/posener/complete"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
@@ -32,7 +33,11 @@ type ImportCommand struct {
 	Meta
 }
 
-func (c *ImportCommand) Run(args []string) int {
+func (c *ImportCommand) Run(args []string) (code int) {
+	// With -input=strict, the prompts that were needed are reported together
+	// once the command is done, whichever way it ends.
+	defer func() { code = c.reportStrictInput(nil, code) }()
+
 	ctx := c.CommandContext()
 
 	// Get the pwd since its our default -config flag value
@@ -294,6 +299,25 @@ func (c *ImportCommand) Run(args []string) int {
 	return 0
 }
 
+func (c *ImportCommand) AutocompleteArgs() complete.Predictor {
+	return completePredictSequence{
+		c.completePredictResourceAddress(c.CommandContext()),
+		complete.PredictAnything,
+	}
+}
+
+func (c *ImportCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-config":       complete.PredictDirs(""),
+		"-input":        completePredictInput,
+		"-lock":         completePredictBoolean,
+		"-lock-timeout": complete.PredictAnything,
+		"-no-color":     complete.PredictNothing,
+		"-var":          complete.PredictAnything,
+		"-var-file":     complete.PredictFiles("*.tfvars"),
+	}
+}
+
 func (c *ImportCommand) Help() string {
 	helpText := `
 Usage: farseek [global options] import [options] ADDR ID
@@ -336,6 +360,9 @@ Options:
 
   -input=false            Disable interactive input prompts.
 
+  -input=strict           Don't prompt, and report every input that would have
+                          been prompted for in a single error.
+
   -lock=false             Don't hold a state lock during the operation. This is
                           dangerous if others might concurrently run commands
                           against the same workspace.
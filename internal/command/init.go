@@ -26,6 +26,7 @@ import (
 
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/views"
+	viewsjson "github.com/rafagsiqueira/farseek/internal/command/views/json"
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
@@ -44,16 +45,24 @@ import (
 // module and clones it to the working directory.
 type InitCommand struct {
 	Meta
+
+	// jsonView is set when the -json option is used, to report structured
+	// provider installation events.
+	jsonView *views.JSONView
 }
 
-func (c *InitCommand) Run(args []string) int {
+func (c *InitCommand) Run(args []string) (code int) {
+	// With -input=strict, the prompts that were needed are reported together
+	// once the command is done, whichever way it ends.
+	defer func() { code = c.reportStrictInput(nil, code) }()
+
 	ctx := c.CommandContext()
 
 	ctx, span := tracing.Tracer().Start(ctx, "Init")
 	defer span.End()
 
 	var flagFromModule, flagLockfile, testsDirectory string
-	var flagBackend, flagGet, flagUpgrade bool
+	var flagBackend, flagGet, flagUpgrade, flagFixProviders, flagStatus bool
 	var flagPluginPath FlagStringSlice
 	flagConfigExtra := newRawFlags("-backend-config")
 
@@ -70,11 +79,14 @@ func (c *InitCommand) Run(args []string) int {
 	cmdFlags.BoolVar(&c.reconfigure, "reconfigure", false, "reconfigure")
 	cmdFlags.BoolVar(&c.migrateState, "migrate-state", false, "migrate state")
 	cmdFlags.BoolVar(&flagUpgrade, "upgrade", false, "")
+	cmdFlags.BoolVar(&c.Meta.Offline, "offline", c.Meta.Offline, "install only from local mirrors and caches")
+	cmdFlags.BoolVar(&flagFixProviders, "fix-providers", false, "add implied providers to required_providers")
 	cmdFlags.Var(&flagPluginPath, "plugin-dir", "plugin directory")
 	cmdFlags.StringVar(&flagLockfile, "lockfile", "", "Set a dependency lockfile mode")
 	cmdFlags.BoolVar(&c.Meta.ignoreRemoteVersion, "ignore-remote-version", false, "continue even if remote and local Farseek versions are incompatible")
 	cmdFlags.StringVar(&testsDirectory, "test-directory", "tests", "test-directory")
 	cmdFlags.BoolVar(&c.outputInJSON, "json", false, "json")
+	cmdFlags.BoolVar(&flagStatus, "status", false, "status")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -84,9 +96,10 @@ func (c *InitCommand) Run(args []string) int {
 		c.Meta.color = false
 		c.Meta.Color = false
 		c.oldUi = c.Ui
+		c.jsonView = views.NewJSONView(c.View)
 		c.Ui = &WrappedUi{
 			cliUi:        c.oldUi,
-			jsonView:     views.NewJSONView(c.View),
+			jsonView:     c.jsonView,
 			outputInJSON: true,
 		}
 	}
@@ -118,6 +131,10 @@ func (c *InitCommand) Run(args []string) int {
 		return 1
 	}
 
+	if flagStatus {
+		return c.showInitStatus(ctx, path)
+	}
+
 	if err := c.storePluginPath(c.pluginPath); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error saving -plugin-path values: %s", err))
 		return 1
@@ -316,6 +333,31 @@ func (c *InitCommand) Run(args []string) int {
 		return 1
 	}
 
+	if flagFixProviders {
+		fixed, fixDiags := c.fixProviderRequirements(config)
+		diags = diags.Append(fixDiags)
+		if fixDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		if fixed {
+			header = true
+
+			// The provider requirements are decoded when the configuration
+			// is loaded, so we must load it again to see the new entries.
+			// The loader caches the files it has parsed, so we need a new
+			// one to read the updated files.
+			c.configLoader = nil
+			config, confDiags = c.loadConfigWithTests(ctx, path, testsDirectory)
+			if confDiags.HasErrors() {
+				diags = diags.Append(confDiags)
+				c.Ui.Error(strings.TrimSpace(errInitConfigError))
+				c.showDiagnostics(diags)
+				return 1
+			}
+		}
+	}
+
 	if state != nil {
 		// Since we now have the full configuration loaded, we can use it to migrate the in-memory state view
 		// prior to fetching providers.
@@ -345,6 +387,17 @@ func (c *InitCommand) Run(args []string) int {
 		c.Ui.Output("")
 	}
 
+	// Tools can read how the directory was initialized without having to
+	// run a plan. The directory is usable without the file, so failing to
+	// write it is only a warning.
+	if err := c.writeInitStatus(); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to record the init status",
+			fmt.Sprintf("Farseek couldn't write %s: %s. Tools that check it will consider the working directory uninitialized.", c.initStatusPath(), err),
+		))
+	}
+
 	// If we accumulated any warnings along the way that weren't accompanied
 	// by errors then we'll output them here so that the success message is
 	// still the final thing shown.
@@ -591,6 +644,13 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 	// and incomplete providers are stored here for later analysis.
 	var incompleteProviders []string
 
+	// In JSON mode we report each provider installation as a start event and
+	// a complete event, so we track the download size reported in between
+	// and which providers were fetched into the shared cache before being
+	// linked from it.
+	downloadedBytes := make(map[addrs.Provider]int64)
+	fetchedToCache := make(map[addrs.Provider]bool)
+
 	// Because we're currently just streaming a series of events sequentially
 	// into the terminal, we're showing only a subset of the events to keep
 	// things relatively concise. Later it'd be nice to have a progress UI
@@ -603,6 +663,18 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 			))
 		},
 		ProviderAlreadyInstalled: func(provider addrs.Provider, selectedVersion getproviders.Version, inProviderCache bool) {
+			if c.jsonView != nil {
+				// A provider found in the shared cache is reported when
+				// it's linked from there.
+				if !inProviderCache {
+					c.jsonView.ProviderInstallComplete(&viewsjson.ProviderInstallComplete{
+						Provider: provider.String(),
+						Version:  selectedVersion.String(),
+						CacheHit: true,
+					})
+				}
+				return
+			}
 			if inProviderCache {
 				c.Ui.Info(fmt.Sprintf("- Detected previously-installed %s v%s in the shared cache directory", provider.ForDisplay(), selectedVersion))
 			} else {
@@ -631,9 +703,39 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 			}
 		},
 		LinkFromCacheBegin: func(provider addrs.Provider, version getproviders.Version, cacheRoot string) {
+			if c.jsonView != nil {
+				if !fetchedToCache[provider] {
+					c.jsonView.ProviderInstallStart(&viewsjson.ProviderInstallStart{
+						Provider: provider.String(),
+						Version:  version.String(),
+						Source:   cacheRoot,
+						CacheHit: true,
+					})
+				}
+				return
+			}
 			c.Ui.Info(fmt.Sprintf("- Using %s v%s from the shared cache directory", provider.ForDisplay(), version))
 		},
+		LinkFromCacheSuccess: func(provider addrs.Provider, version getproviders.Version, localDir string) {
+			if c.jsonView != nil && !fetchedToCache[provider] {
+				c.jsonView.ProviderInstallComplete(&viewsjson.ProviderInstallComplete{
+					Provider: provider.String(),
+					Version:  version.String(),
+					CacheHit: true,
+				})
+			}
+		},
 		FetchPackageBegin: func(provider addrs.Provider, version getproviders.Version, location getproviders.PackageLocation, inProviderCache bool) {
+			if c.jsonView != nil {
+				fetchedToCache[provider] = inProviderCache
+				c.jsonView.ProviderInstallStart(&viewsjson.ProviderInstallStart{
+					Provider:    provider.String(),
+					Version:     version.String(),
+					Source:      location.String(),
+					SharedCache: inProviderCache,
+				})
+				return
+			}
 			if inProviderCache {
 				c.Ui.Info(fmt.Sprintf("- Installing %s v%s to the shared cache directory...", provider.ForDisplay(), version))
 			} else {
@@ -642,6 +744,14 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 		},
 		QueryPackagesFailure: func(provider addrs.Provider, err error) {
 			switch errorTy := err.(type) {
+			case getproviders.ErrOffline:
+				diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Provider requires network access",
+					fmt.Sprintf("Provider %s isn't in the plugin cache, a filesystem mirror, or the working directory, and installing it requires network access to %s, which isn't allowed in offline mode.\n\nRun \"farseek init\" without offline mode on a machine with network access to install the provider, or add it to a filesystem mirror.",
+						provider.ForDisplay(), errorTy.Source,
+					),
+				), tfdiags.CodeOfflineNetworkRequired))
 			case getproviders.ErrProviderNotFound:
 				sources := errorTy.Sources
 				displaySources := make([]string, len(sources))
@@ -836,7 +946,41 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 				))
 			}
 		},
+		FetchPackageProgress: func(provider addrs.Provider, version getproviders.Version, downloaded, total int64) {
+			downloadedBytes[provider] = downloaded
+			if c.jsonView != nil {
+				c.jsonView.ProviderInstallProgress(&viewsjson.ProviderInstallProgress{
+					Provider:   provider.String(),
+					Version:    version.String(),
+					Bytes:      downloaded,
+					TotalBytes: total,
+				})
+			}
+		},
 		FetchPackageSuccess: func(provider addrs.Provider, version getproviders.Version, localDir string, authResult *getproviders.PackageAuthenticationResult) {
+			if c.jsonView != nil {
+				complete := &viewsjson.ProviderInstallComplete{
+					Provider: provider.String(),
+					Version:  version.String(),
+					Bytes:    downloadedBytes[provider],
+				}
+				if authResult != nil {
+					complete.Authentication = authResult.String()
+					for hash := range authResult.HashesWithDisposition(func(d *getproviders.HashDisposition) bool { return d.VerifiedLocally }) {
+						complete.VerifiedHashes = append(complete.VerifiedHashes, hash.String())
+					}
+					sort.Strings(complete.VerifiedHashes)
+					if authResult.Signed() {
+						complete.KeyIDs = authResult.GPGKeyIDsString()
+					}
+					if authResult.SigningSkipped() {
+						c.Ui.Warn(fmt.Sprintf("Signature validation was skipped for %s v%s due to the registry not containing GPG keys for this provider", provider.ForDisplay(), version))
+					}
+				}
+				c.jsonView.ProviderInstallComplete(complete)
+				return
+			}
+
 			var keyID string
 			if authResult != nil && authResult.Signed() {
 				keyID = authResult.GPGKeyIDsString()
@@ -1157,15 +1301,18 @@ func (c *InitCommand) AutocompleteFlags() complete.Flags {
 		"-backend":        completePredictBoolean,
 		"-cloud":          completePredictBoolean,
 		"-backend-config": complete.PredictFiles("*.tfvars"), // can also be key=value, but we can't "predict" that
+		"-fix-providers":  complete.PredictNothing,
 		"-force-copy":     complete.PredictNothing,
 		"-from-module":    completePredictModuleSource,
 		"-get":            completePredictBoolean,
-		"-input":          completePredictBoolean,
+		"-input":          completePredictInput,
 		"-lock":           completePredictBoolean,
 		"-lock-timeout":   complete.PredictAnything,
 		"-no-color":       complete.PredictNothing,
+		"-offline":        complete.PredictNothing,
 		"-plugin-dir":     complete.PredictDirs(""),
 		"-reconfigure":    complete.PredictNothing,
+		"-status":         complete.PredictNothing,
 		"-migrate-state":  complete.PredictNothing,
 		"-upgrade":        completePredictBoolean,
 	}
@@ -1216,6 +1363,10 @@ Options:
                           will be performed. All locations, for all errors
                           will be listed. Disabled by default
 
+  -fix-providers          Add an entry to the required_providers block of each
+                          local module for every provider that its resources use
+                          without declaring it, and show the changes made.
+
   -force-copy             Suppress prompts about copying state data when
                           initializing a new state backend. This is
                           equivalent to providing a "yes" to all confirmation
@@ -1230,6 +1381,9 @@ Options:
                           require interactive prompts and will error if input is
                           disabled.
 
+  -input=strict           Don't prompt, and report every input that would have
+                          been prompted for in a single error.
+
   -lock=false             Don't hold a state lock during backend migration.
                           This is dangerous if others might concurrently run
                           commands against the same workspace.
@@ -1238,6 +1392,13 @@ Options:
 
   -no-color               If specified, output won't contain any color.
 
+  -offline               Don't install providers or modules over the network.
+                          Providers are installed only from filesystem mirrors,
+                          the plugin cache, and the working directory, and
+                          init fails, naming each provider and module that
+                          requires network access, if they don't have them.
+                          Setting FARSEEK_OFFLINE=1 has the same effect.
+
   -plugin-dir             Directory containing plugin binaries. This overrides all
                           default search paths for plugins, and prevents the
                           automatic installation of plugins. This flag can be used
@@ -1268,6 +1429,12 @@ Options:
                           test command will search for test files in the current directory and
                           in the one specified by the flag.
 
+  -status                 Instead of initializing, show how the working
+                          directory was last initialized, and check that the
+                          backend, providers and modules it installed still
+                          match the configuration. Exits with status 1 if
+                          "farseek init" must run again.
+
   -json                   Produce output in a machine-readable JSON format, 
                           suitable for use in text editor integrations and other 
                           automated systems. Always disables color.
@@ -0,0 +1,202 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// fixProvidersFilename is the file that "init -fix-providers" creates a
+// "terraform" block in when a module doesn't have one already.
+const fixProvidersFilename = "versions.tf"
+
+// fixProviderRequirements adds an entry to the required_providers block of
+// each module in the configuration for every provider that the module's
+// resources and provider blocks imply but that it doesn't declare, and
+// shows the changes it made as a diff.
+//
+// Only the modules loaded from local directories are updated. Modules
+// installed from remote sources are read-only copies, and so we only warn
+// about their missing entries.
+//
+// If changed is true, the caller must reload the configuration to see the
+// new requirements.
+func (c *InitCommand) fixProviderRequirements(config *configs.Config) (changed bool, diags tfdiags.Diagnostics) {
+	for _, cfg := range config.AllModules() {
+		missing := impliedProviderRequirements(cfg.Module)
+		if len(missing) == 0 {
+			continue
+		}
+
+		if _, local := cfg.SourceAddr.(addrs.ModuleSourceLocal); cfg.SourceAddr != nil && !local {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Cannot fix provider requirements of a remote module",
+				fmt.Sprintf("The module %s from %s uses providers that it doesn't declare in required_providers: %s. Farseek only updates modules in local directories, so this must be fixed in the module's source.", cfg.Path, cfg.SourceAddr, strings.Join(sortedProviderNames(missing), ", ")),
+			))
+			continue
+		}
+
+		filename, src, newSrc, moreDiags := addProviderRequirements(cfg.Module.SourceDir, missing)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		if err := os.WriteFile(filename, newSrc, 0644); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to update provider requirements",
+				fmt.Sprintf("Could not write %s: %s.", filename, err),
+			))
+			continue
+		}
+		changed = true
+
+		if c.outputInJSON {
+			continue
+		}
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
+			"[reset][bold]Added implied providers to required_providers in %s:", filename,
+		)))
+		diff, err := bytesDiff(src, newSrc, filename)
+		if err != nil {
+			// The diff is only informational, so we just list the
+			// providers if we can't produce it.
+			for _, name := range sortedProviderNames(missing) {
+				c.Ui.Output(fmt.Sprintf("  + %s = %q", name, missing[name].ForDisplay()))
+			}
+			continue
+		}
+		c.Ui.Output(string(diff))
+	}
+	return changed, diags
+}
+
+// impliedProviderRequirements returns the providers, by local name, that the
+// resources and provider blocks of the given module use without a
+// corresponding entry in the module's required_providers block.
+func impliedProviderRequirements(mod *configs.Module) map[string]addrs.Provider {
+	ret := make(map[string]addrs.Provider)
+	add := func(localName string) {
+		if _, declared := mod.ProviderRequirements.RequiredProviders[localName]; declared {
+			return
+		}
+		provider := addrs.ImpliedProviderForUnqualifiedType(localName)
+		if provider.IsBuiltIn() {
+			// Built-in providers never need to be declared.
+			return
+		}
+		ret[localName] = provider
+	}
+
+	for _, resources := range []map[string]*configs.Resource{mod.ManagedResources, mod.DataResources, mod.EphemeralResources} {
+		for _, rc := range resources {
+			add(rc.ProviderConfigAddr().LocalName)
+		}
+	}
+	for _, pc := range mod.ProviderConfigs {
+		add(pc.Name)
+	}
+	return ret
+}
+
+// addProviderRequirements returns the name of the file in the given module
+// directory that should declare the given providers, along with its current
+// and updated contents.
+//
+// The providers are added to the first file that has a required_providers
+// block, or else to the first file that has a "terraform" block. If there is
+// neither, a "terraform" block is added to versions.tf, which is created if
+// it doesn't exist.
+func addProviderRequirements(dir string, providers map[string]addrs.Provider) (filename string, src, newSrc []byte, diags tfdiags.Diagnostics) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to list the files of module directory %s: %w", dir, err))
+		return "", nil, nil, diags
+	}
+	sort.Strings(paths)
+
+	var file *hclwrite.File
+	var requiredProviders, terraformBlock *hclwrite.Block
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_override.tf") || filepath.Base(path) == "override.tf" {
+			continue
+		}
+		pathSrc, err := os.ReadFile(path)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to read %s: %w", path, err))
+			return "", nil, nil, diags
+		}
+		f, hclDiags := hclwrite.ParseConfig(pathSrc, path, hcl.InitialPos)
+		diags = diags.Append(hclDiags)
+		if hclDiags.HasErrors() {
+			return "", nil, nil, diags
+		}
+		for _, block := range f.Body().Blocks() {
+			if block.Type() != "terraform" {
+				continue
+			}
+			if terraformBlock == nil {
+				filename, src, file, terraformBlock = path, pathSrc, f, block
+			}
+			if rp := block.Body().FirstMatchingBlock("required_providers", nil); rp != nil {
+				filename, src, file, terraformBlock, requiredProviders = path, pathSrc, f, block, rp
+				break
+			}
+		}
+		if requiredProviders != nil {
+			break
+		}
+	}
+
+	if terraformBlock == nil {
+		filename = filepath.Join(dir, fixProvidersFilename)
+		src, err = os.ReadFile(filename)
+		if err != nil && !os.IsNotExist(err) {
+			diags = diags.Append(fmt.Errorf("Failed to read %s: %w", filename, err))
+			return "", nil, nil, diags
+		}
+		var hclDiags hcl.Diagnostics
+		file, hclDiags = hclwrite.ParseConfig(src, filename, hcl.InitialPos)
+		diags = diags.Append(hclDiags)
+		if hclDiags.HasErrors() {
+			return "", nil, nil, diags
+		}
+		if len(file.Body().Attributes()) != 0 || len(file.Body().Blocks()) != 0 {
+			file.Body().AppendNewline()
+		}
+		terraformBlock = file.Body().AppendNewBlock("terraform", nil)
+	}
+	if requiredProviders == nil {
+		requiredProviders = terraformBlock.Body().AppendNewBlock("required_providers", nil)
+	}
+
+	for _, name := range sortedProviderNames(providers) {
+		requiredProviders.Body().SetAttributeValue(name, cty.ObjectVal(map[string]cty.Value{
+			"source": cty.StringVal(providers[name].ForDisplay()),
+		}))
+	}
+	return filename, src, hclwrite.Format(file.Bytes()), diags
+}
+
+func sortedProviderNames(providers map[string]addrs.Provider) []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
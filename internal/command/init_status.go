@@ -0,0 +1,352 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	backendInit "github.com/rafagsiqueira/farseek/internal/backend/init"
+	"github.com/rafagsiqueira/farseek/internal/command/clistate"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/depsfile"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/modsdir"
+	tfversion "github.com/rafagsiqueira/farseek/version"
+)
+
+// InitStatusFilename is the name of the file in the data directory that
+// describes how "farseek init" last initialized the working directory.
+const InitStatusFilename = "farseek-init.json"
+
+// initStatusFormatVersion is the version of the format of the init status
+// file. Its major version changes only when a change would break tools that
+// read the file.
+const initStatusFormatVersion = "1.0"
+
+// initStatus is what "farseek init" records about the working directory it
+// initialized, so that tools can check whether the directory is ready to use
+// without running a plan.
+type initStatus struct {
+	FormatVersion  string               `json:"format_version"`
+	FarseekVersion string               `json:"farseek_version"`
+	Backend        initStatusBackend    `json:"backend"`
+	Providers      []initStatusProvider `json:"providers"`
+	Modules        []initStatusModule   `json:"modules"`
+	Mode           initStatusMode       `json:"mode"`
+}
+
+type initStatusBackend struct {
+	// Type is the canonical name of the backend type, never an alias.
+	Type string `json:"type"`
+}
+
+type initStatusProvider struct {
+	Address string   `json:"address"`
+	Version string   `json:"version"`
+	Hashes  []string `json:"hashes"`
+}
+
+type initStatusModule struct {
+	// Key is the address of the module call, such as "network.subnets",
+	// as recorded in the module manifest.
+	Key     string `json:"key"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	Dir     string `json:"dir"`
+}
+
+type initStatusMode struct {
+	// Farseek is true if plans and applies in the directory run in Farseek
+	// mode, which discovers the resources to manage from the git history
+	// instead of reading them from state.
+	Farseek bool   `json:"farseek"`
+	Git     bool   `json:"git"`
+	BaseSHA string `json:"base_sha,omitempty"`
+}
+
+// initStatusPath returns the path of the init status file of the working
+// directory.
+func (c *InitCommand) initStatusPath() string {
+	return filepath.Join(c.DataDir(), InitStatusFilename)
+}
+
+// currentInitStatus describes the working directory as it is now, from the
+// backend recorded in the data directory, the dependency lock file and the
+// module manifest.
+func (c *InitCommand) currentInitStatus() (*initStatus, error) {
+	status := &initStatus{
+		FormatVersion:  initStatusFormatVersion,
+		FarseekVersion: tfversion.String(),
+		Backend:        initStatusBackend{Type: "local"},
+		Providers:      []initStatusProvider{},
+		Modules:        []initStatusModule{},
+	}
+
+	sMgr := &clistate.LocalState{Path: filepath.Join(c.DataDir(), DefaultStateFilename)}
+	if err := sMgr.RefreshState(context.TODO()); err != nil {
+		return nil, fmt.Errorf("failed to read the backend configuration: %w", err)
+	}
+	if s := sMgr.State(); s != nil && !s.Backend.Empty() && s.Backend.Type != "" {
+		status.Backend.Type = s.Backend.Type
+	}
+
+	locks, lockDiags := c.lockedDependencies()
+	if lockDiags.HasErrors() {
+		return nil, lockDiags.Err()
+	}
+	status.Providers = initStatusProviders(locks)
+
+	manifest, err := modsdir.ReadManifestSnapshotForDir(c.modulesDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the module manifest: %w", err)
+	}
+	for _, record := range manifest {
+		if record.Key == "" {
+			// The root module is always the configuration directory.
+			continue
+		}
+		m := initStatusModule{
+			Key:    record.Key,
+			Source: record.SourceAddr,
+			Dir:    record.Dir,
+		}
+		if record.Version != nil {
+			m.Version = record.Version.String()
+		}
+		status.Modules = append(status.Modules, m)
+	}
+	slices.SortFunc(status.Modules, func(a, b initStatusModule) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	dir := c.discoveryDir()
+	if _, err := farseek.Discovery.GetCurrentSHA(dir); err == nil {
+		status.Mode.Git = true
+	}
+	sha, err := farseek.ReadSHA(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", farseek.SHAFilename, err)
+	}
+	status.Mode.BaseSHA = sha
+	status.Mode.Farseek = status.Mode.Git || sha != ""
+
+	return status, nil
+}
+
+func initStatusProviders(locks *depsfile.Locks) []initStatusProvider {
+	ret := []initStatusProvider{}
+	for addr, lock := range locks.AllProviders() {
+		p := initStatusProvider{
+			Address: addr.String(),
+			Version: lock.Version().String(),
+			Hashes:  []string{},
+		}
+		for _, hash := range lock.AllHashes() {
+			p.Hashes = append(p.Hashes, hash.String())
+		}
+		slices.Sort(p.Hashes)
+		ret = append(ret, p)
+	}
+	slices.SortFunc(ret, func(a, b initStatusProvider) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return ret
+}
+
+// writeInitStatus records the working directory as "farseek init" left it.
+func (c *InitCommand) writeInitStatus() error {
+	status, err := c.currentInitStatus()
+	if err != nil {
+		return err
+	}
+	src, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.DataDir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.initStatusPath(), append(src, '\n'), 0o644)
+}
+
+// readInitStatus reads the init status file of the working directory.
+func (c *InitCommand) readInitStatus() (*initStatus, error) {
+	src, err := os.ReadFile(c.initStatusPath())
+	if err != nil {
+		return nil, err
+	}
+	var status initStatus
+	if err := json.Unmarshal(src, &status); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if major, _, _ := strings.Cut(status.FormatVersion, "."); major != strings.Split(initStatusFormatVersion, ".")[0] {
+		return nil, fmt.Errorf("unsupported format version %q", status.FormatVersion)
+	}
+	return &status, nil
+}
+
+// initStatusProblems compares the recorded init status with the working
+// directory and its configuration, and returns a description of each
+// difference that means "farseek init" must run again.
+func (c *InitCommand) initStatusProblems(ctx context.Context, path string, recorded *initStatus) []string {
+	var problems []string
+
+	current, err := c.currentInitStatus()
+	if err != nil {
+		return append(problems, fmt.Sprintf("The working directory can't be read: %s.", err))
+	}
+
+	if root, diags := c.loadSingleModule(ctx, path, configs.SelectiveLoadBackend); !diags.HasErrors() {
+		configured := "local"
+		if root.Backend != nil {
+			_, configured = backendInit.Backend(root.Backend.Type)
+		}
+		if configured != recorded.Backend.Type {
+			problems = append(problems, fmt.Sprintf("The configuration uses the %q backend, but the working directory was initialized for the %q backend.", configured, recorded.Backend.Type))
+		}
+	}
+	if current.Backend.Type != recorded.Backend.Type {
+		problems = append(problems, fmt.Sprintf("The working directory is configured for the %q backend, but init recorded the %q backend.", current.Backend.Type, recorded.Backend.Type))
+	}
+
+	recordedProviders := make(map[string]initStatusProvider, len(recorded.Providers))
+	for _, p := range recorded.Providers {
+		recordedProviders[p.Address] = p
+	}
+	for _, p := range current.Providers {
+		r, ok := recordedProviders[p.Address]
+		delete(recordedProviders, p.Address)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("The dependency lock file selects provider %s %s, which init didn't install.", p.Address, p.Version))
+		case r.Version != p.Version:
+			problems = append(problems, fmt.Sprintf("The dependency lock file selects provider %s %s, but init installed %s.", p.Address, p.Version, r.Version))
+		case !slices.Equal(r.Hashes, p.Hashes):
+			problems = append(problems, fmt.Sprintf("The checksums of provider %s %s in the dependency lock file changed since init.", p.Address, p.Version))
+		}
+	}
+	for _, p := range recorded.Providers {
+		if _, ok := recordedProviders[p.Address]; ok {
+			problems = append(problems, fmt.Sprintf("Init installed provider %s %s, which the dependency lock file no longer selects.", p.Address, p.Version))
+		}
+	}
+	cacheDir := c.providerLocalCacheDir()
+	locks, _ := c.lockedDependencies()
+	if locks != nil {
+		for addr, lock := range locks.AllProviders() {
+			if _, overridden := c.ProviderDevOverrides[addr]; overridden {
+				continue
+			}
+			if cacheDir.ProviderVersion(addr, lock.Version()) == nil {
+				problems = append(problems, fmt.Sprintf("Provider %s %s isn't installed in %s.", addr, lock.Version(), cacheDir.BasePath()))
+			}
+		}
+	}
+
+	recordedModules := make(map[string]initStatusModule, len(recorded.Modules))
+	for _, m := range recorded.Modules {
+		recordedModules[m.Key] = m
+	}
+	for _, m := range current.Modules {
+		r, ok := recordedModules[m.Key]
+		delete(recordedModules, m.Key)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("Module %q was installed after init.", m.Key))
+		case r.Source != m.Source || r.Version != m.Version:
+			problems = append(problems, fmt.Sprintf("Module %q was installed from %s since init, which installed it from %s.", m.Key, moduleDisplaySource(m), moduleDisplaySource(r)))
+		}
+		if _, err := os.Stat(m.Dir); err != nil {
+			problems = append(problems, fmt.Sprintf("The directory %s of module %q is missing.", m.Dir, m.Key))
+		}
+	}
+	for _, m := range recorded.Modules {
+		if _, ok := recordedModules[m.Key]; ok {
+			problems = append(problems, fmt.Sprintf("Module %q that init installed is no longer in the module manifest.", m.Key))
+		}
+	}
+
+	// The configuration must load with the installed modules, and each
+	// provider it requires must be locked.
+	config, configDiags := c.loadConfig(ctx, path)
+	if configDiags.HasErrors() {
+		return append(problems, fmt.Sprintf("The configuration can't be loaded with the installed modules: %s", configDiags.Err()))
+	}
+	reqs, _, _ := config.ProviderRequirements()
+	var missing []addrs.Provider
+	for addr := range reqs {
+		if !addr.IsBuiltIn() && (locks == nil || locks.Provider(addr) == nil) {
+			missing = append(missing, addr)
+		}
+	}
+	slices.SortFunc(missing, func(a, b addrs.Provider) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, addr := range missing {
+		problems = append(problems, fmt.Sprintf("The configuration requires provider %s, which the dependency lock file doesn't select.", addr))
+	}
+	return problems
+}
+
+func moduleDisplaySource(m initStatusModule) string {
+	if m.Version == "" {
+		return m.Source
+	}
+	return fmt.Sprintf("%s %s", m.Source, m.Version)
+}
+
+// showInitStatus implements "farseek init -status", which prints the
+// recorded init status and checks it against the working directory.
+func (c *InitCommand) showInitStatus(ctx context.Context, path string) int {
+	status, err := c.readInitStatus()
+	if errors.Is(err, os.ErrNotExist) {
+		c.Ui.Error(fmt.Sprintf("The working directory hasn't been initialized: %s doesn't exist. Run \"farseek init\" to initialize it.", c.initStatusPath()))
+		return 1
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read %s: %s", c.initStatusPath(), err))
+		return 1
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Initialized by Farseek v%s\n", status.FarseekVersion)
+	fmt.Fprintf(&buf, "Backend: %s\n", status.Backend.Type)
+	switch {
+	case status.Mode.Farseek && status.Mode.BaseSHA != "":
+		fmt.Fprintf(&buf, "Mode: Farseek, from base commit %s\n", status.Mode.BaseSHA)
+	case status.Mode.Farseek:
+		buf.WriteString("Mode: Farseek, with no commit applied yet\n")
+	default:
+		buf.WriteString("Mode: state\n")
+	}
+	if len(status.Providers) > 0 {
+		buf.WriteString("Providers:\n")
+		for _, p := range status.Providers {
+			fmt.Fprintf(&buf, "  - %s %s\n", p.Address, p.Version)
+		}
+	}
+	if len(status.Modules) > 0 {
+		buf.WriteString("Modules:\n")
+		for _, m := range status.Modules {
+			fmt.Fprintf(&buf, "  - %s: %s\n", m.Key, moduleDisplaySource(m))
+		}
+	}
+	c.Ui.Output(buf.String())
+
+	problems := c.initStatusProblems(ctx, path, status)
+	if len(problems) == 0 {
+		c.Ui.Output("The working directory is initialized for the current configuration.")
+		return 0
+	}
+	c.Ui.Error("The working directory must be initialized again with \"farseek init\":\n  - " + strings.Join(problems, "\n  - "))
+	return 1
+}
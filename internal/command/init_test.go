@@ -191,6 +191,78 @@ func TestInit_get(t *testing.T) {
 	}
 }
 
+func TestInit_status(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-get"), td)
+	t.Chdir(td)
+
+	newCommand := func(ui cli.Ui) *InitCommand {
+		view, _ := testView(t)
+		return &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+			},
+		}
+	}
+
+	// Before init there is nothing to check.
+	ui := new(cli.MockUi)
+	if code := newCommand(ui).Run([]string{"-status"}); code != 1 {
+		t.Fatalf("wrong exit status %d before init; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "hasn't been initialized"; !strings.Contains(got, want) {
+		t.Fatalf("missing %q in output:\n%s", want, got)
+	}
+
+	ui = new(cli.MockUi)
+	if code := newCommand(ui).Run(nil); code != 0 {
+		t.Fatalf("init failed: \n%s", ui.ErrorWriter.String())
+	}
+
+	src, err := os.ReadFile(filepath.Join(DefaultDataDir, InitStatusFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status initStatus
+	if err := json.Unmarshal(src, &status); err != nil {
+		t.Fatal(err)
+	}
+	wantModules := []initStatusModule{
+		{Key: "foo", Source: "./foo", Dir: "foo"},
+	}
+	if diff := cmp.Diff(wantModules, status.Modules); diff != "" {
+		t.Errorf("wrong modules\n%s", diff)
+	}
+	if status.Backend.Type != "local" {
+		t.Errorf("wrong backend %q; want \"local\"", status.Backend.Type)
+	}
+
+	ui = new(cli.MockUi)
+	if code := newCommand(ui).Run([]string{"-status"}); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\n%s", code, ui.ErrorWriter.String())
+	}
+	output := ui.OutputWriter.String()
+	for _, want := range []string{"Backend: local", "  - foo: ./foo", "The working directory is initialized"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in output:\n%s", want, output)
+		}
+	}
+
+	// A module call added since init means init must run again.
+	if err := os.WriteFile("bar.tf", []byte(`module "bar" { source = "./foo" }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ui = new(cli.MockUi)
+	if code := newCommand(ui).Run([]string{"-status"}); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "can't be loaded with the installed modules"; !strings.Contains(got, want) {
+		t.Errorf("missing %q in output:\n%s", want, got)
+	}
+}
+
 func TestInit_getUpgradeModules(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -337,7 +409,7 @@ func TestInit_backendConfigFile(t *testing.T) {
 
 		// Read our saved backend config and verify we have our settings
 		state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-		if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null}`; got != want {
+		if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null,"workspaces":null}`; got != want {
 			t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 		}
 	})
@@ -420,7 +492,7 @@ func TestInit_backendConfigFile(t *testing.T) {
 
 		// Read our saved backend config and verify the backend config is empty
 		state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-		if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":null,"workspace_dir":null}`; got != want {
+		if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":null,"workspace_dir":null,"workspaces":null}`; got != want {
 			t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 		}
 	})
@@ -555,7 +627,7 @@ func TestInit_backendConfigFileChange(t *testing.T) {
 
 	// Read our saved backend config and verify we have our settings
 	state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null}`; got != want {
+	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null,"workspaces":null}`; got != want {
 		t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 	}
 }
@@ -617,7 +689,7 @@ func TestInit_backendConfigKV(t *testing.T) {
 
 	// Read our saved backend config and verify we have our settings
 	state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null}`; got != want {
+	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null,"workspaces":null}`; got != want {
 		t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 	}
 }
@@ -795,7 +867,7 @@ func TestInit_backendReinitWithExtra(t *testing.T) {
 
 	// Read our saved backend config and verify we have our settings
 	state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null}`; got != want {
+	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null,"workspaces":null}`; got != want {
 		t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 	}
 
@@ -808,7 +880,7 @@ func TestInit_backendReinitWithExtra(t *testing.T) {
 		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
 	}
 	state = testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null}`; got != want {
+	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"hello","workspace_dir":null,"workspaces":null}`; got != want {
 		t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 	}
 	if state.Backend.Hash != uint64(cHash) {
@@ -838,7 +910,7 @@ func TestInit_backendReinitConfigToExtra(t *testing.T) {
 
 	// Read our saved backend config and verify we have our settings
 	state := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"foo","workspace_dir":null}`; got != want {
+	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"foo","workspace_dir":null,"workspaces":null}`; got != want {
 		t.Errorf("wrong config\ngot:  %s\nwant: %s", got, want)
 	}
 
@@ -865,7 +937,7 @@ func TestInit_backendReinitConfigToExtra(t *testing.T) {
 		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
 	}
 	state = testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
-	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"foo","workspace_dir":null}`; got != want {
+	if got, want := normalizeJSON(t, state.Backend.ConfigRaw), `{"path":"foo","workspace_dir":null,"workspaces":null}`; got != want {
 		t.Errorf("wrong config after moving to arg\ngot:  %s\nwant: %s", got, want)
 	}
 
@@ -1059,6 +1131,96 @@ func TestInit_getProvider(t *testing.T) {
 	})
 }
 
+func TestInit_fixProviders(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-fix-providers"), td)
+	t.Chdir(td)
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"exact":   {"1.2.3"},
+		"greater": {"2.3.4"},
+		"between": {"3.4.5"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+			ProviderSource:   providerSource,
+		},
+	}
+
+	args := []string{"-backend=false", "-fix-providers"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	// The root module already has a required_providers block, so the
+	// missing provider is added to it.
+	rootSrc, err := os.ReadFile("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRoot := `terraform {
+  required_providers {
+    exact = {
+      source  = "hashicorp/exact"
+      version = "1.2.3"
+    }
+    greater = {
+      source = "hashicorp/greater"
+    }
+  }
+}
+`
+	if got := string(rootSrc); !strings.HasPrefix(got, wantRoot) {
+		t.Errorf("wrong root module\n%s", cmp.Diff(wantRoot, got))
+	}
+
+	// The child module has no terraform block, so one is created.
+	childSrc, err := os.ReadFile(filepath.Join("child", "versions.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChild := `terraform {
+  required_providers {
+    between = {
+      source = "hashicorp/between"
+    }
+  }
+}
+`
+	if diff := cmp.Diff(wantChild, string(childSrc)); diff != "" {
+		t.Errorf("wrong child module\n%s", diff)
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Added implied providers to required_providers in main.tf") {
+		t.Errorf("missing summary of the root module changes\n%s", output)
+	}
+	for _, provider := range []string{"exact", "greater", "between"} {
+		path := fmt.Sprintf(".farseek/providers/registry.opentofu.org/hashicorp/%s", provider)
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("provider %q not installed: %s", provider, err)
+		}
+	}
+
+	// Running it again finds nothing left to fix.
+	ui = cli.NewMockUi()
+	c.Meta.Ui = ui
+	c.Meta.configLoader = nil
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if output := ui.OutputWriter.String(); strings.Contains(output, "Added implied providers") {
+		t.Errorf("unexpected changes on second run\n%s", output)
+	}
+}
+
 func TestInit_getProviderSource(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -1344,6 +1506,70 @@ func TestInit_getProviderDetectedDuplicate(t *testing.T) {
 
 }
 
+func TestInit_offline(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-get-providers"), td)
+	t.Chdir(td)
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"exact":        {"1.2.3"},
+		"greater-than": {"2.3.4"},
+		"between":      {"2.3.4"},
+	})
+	defer close()
+
+	init := func(args ...string) (int, *cli.MockUi) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		c := &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+				ProviderSource:   providerSource,
+			},
+		}
+		return c.Run(append([]string{"-backend=false"}, args...)), ui
+	}
+
+	if code, ui := init(); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	calls := len(providerSource.CallLog())
+
+	t.Run("installed", func(t *testing.T) {
+		// The providers the first init installed are used again without
+		// consulting the network source.
+		if code, ui := init("-offline"); code != 0 {
+			t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+		}
+		if got := len(providerSource.CallLog()); got != calls {
+			t.Errorf("the provider source was called %d times in offline mode", got-calls)
+		}
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		if err := os.RemoveAll(".farseek"); err != nil {
+			t.Fatal(err)
+		}
+		code, ui := init("-offline")
+		if code == 0 {
+			t.Fatalf("expected error, got output:\n%s", ui.OutputWriter.String())
+		}
+		errStr := ui.ErrorWriter.String()
+		for _, name := range []string{"exact", "greater-than", "between"} {
+			want := fmt.Sprintf("Provider hashicorp/%s isn't in the plugin cache", name)
+			if !strings.Contains(strings.Join(strings.Fields(errStr), " "), want) {
+				t.Errorf("missing error for %s:\n%s", name, errStr)
+			}
+		}
+		if got := len(providerSource.CallLog()); got != calls {
+			t.Errorf("the provider source was called %d times in offline mode", got-calls)
+		}
+	})
+}
+
 func TestInit_providerSource(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
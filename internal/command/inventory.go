@@ -0,0 +1,563 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/getproviders"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// InventoryCommand is a Command implementation that summarizes the resource
+// types, providers and modules of the configuration, and what Farseek
+// discovers about its changes, for a fleet-level view of the repositories
+// it manages.
+type InventoryCommand struct {
+	Meta
+}
+
+// inventory is the summary that "farseek inventory" prints.
+type inventory struct {
+	FormatVersion string                   `json:"format_version"`
+	ResourceTypes []*inventoryResourceType `json:"resource_types"`
+	Providers     []inventoryProvider      `json:"providers"`
+	Modules       []inventoryModule        `json:"modules"`
+	Discovery     inventoryDiscovery       `json:"discovery"`
+
+	// Workspaces is only set with the -all-workspaces option.
+	Workspaces []*inventoryWorkspace `json:"workspaces,omitempty"`
+}
+
+type inventoryResourceType struct {
+	Mode     string `json:"mode"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+
+	// Resources is the number of resource blocks of the type in all the
+	// modules of the configuration.
+	Resources int `json:"resources"`
+
+	// Changed is the number of those resources that Farseek discovers as
+	// changed since the last applied commit.
+	Changed int `json:"changed"`
+
+	// Instances is the number of instances of the type in the states of all
+	// the workspaces, and is only set with the -all-workspaces option.
+	Instances *int `json:"instances,omitempty"`
+}
+
+type inventoryProvider struct {
+	Source string `json:"source"`
+
+	// VersionConstraints are the version constraints of all the modules
+	// that require the provider, combined.
+	VersionConstraints string `json:"version_constraints,omitempty"`
+
+	// Version is the version the dependency lock file selects, if any.
+	Version string `json:"version,omitempty"`
+}
+
+type inventoryModule struct {
+	Path    string `json:"path"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+}
+
+type inventoryDiscovery struct {
+	// Mode is "stateless" in a git repository, where Farseek discovers the
+	// changes to plan from the git history, and "stateful" otherwise.
+	Mode           string `json:"mode"`
+	Head           string `json:"head,omitempty"`
+	Branch         string `json:"branch,omitempty"`
+	LastAppliedSHA string `json:"last_applied_sha,omitempty"`
+
+	// ChangedResources is the number of resources changed since the last
+	// applied commit, which the next plan includes.
+	ChangedResources int `json:"changed_resources"`
+
+	// IgnoredResources is the number of changed resources that the
+	// .farseekignore file skips.
+	IgnoredResources int `json:"ignored_resources"`
+
+	// ForgottenResources is the number of resources that
+	// "farseek state forget" recorded as no longer managed.
+	ForgottenResources int `json:"forgotten_resources"`
+}
+
+type inventoryWorkspace struct {
+	Name string `json:"name"`
+
+	// Resources is the number of managed resource instances in the state of
+	// the workspace, by resource type.
+	Resources map[string]int `json:"resources"`
+
+	Error string `json:"error,omitempty"`
+}
+
+func (c *InventoryCommand) Run(args []string) int {
+	var jsonOutput, allWorkspaces bool
+
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("inventory")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.BoolVar(&allWorkspaces, "all-workspaces", false, "count the resources in every workspace")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The inventory command expects no arguments. To summarize the configuration in another directory, use the global -chdir option.\n")
+		return 1
+	}
+
+	// Reading the states must never wait for answers, such as about
+	// migrating the state to a changed backend.
+	c.input = false
+
+	var diags tfdiags.Diagnostics
+
+	configPath := c.Meta.normalizePath(".")
+	config, configDiags := c.loadConfig(ctx, configPath)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	locks, lockDiags := c.lockedDependencies()
+	diags = diags.Append(lockDiags)
+	if lockDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	inv := &inventory{
+		FormatVersion: "1.0",
+		ResourceTypes: []*inventoryResourceType{},
+		Providers:     []inventoryProvider{},
+		Modules:       []inventoryModule{},
+	}
+
+	types := make(map[string]*inventoryResourceType)
+	config.DeepEach(func(cfg *configs.Config) {
+		for _, rcs := range []map[string]*configs.Resource{cfg.Module.ManagedResources, cfg.Module.DataResources} {
+			for _, rc := range rcs {
+				key := rc.Mode.String() + "." + rc.Type
+				rt, ok := types[key]
+				if !ok {
+					rt = &inventoryResourceType{
+						Mode:     inventoryResourceMode(rc.Mode),
+						Type:     rc.Type,
+						Provider: rc.Provider.String(),
+					}
+					types[key] = rt
+					inv.ResourceTypes = append(inv.ResourceTypes, rt)
+				}
+				rt.Resources++
+			}
+		}
+		if cfg.Path.IsRoot() {
+			return
+		}
+		m := inventoryModule{
+			Path:   cfg.Path.String(),
+			Source: cfg.SourceAddr.String(),
+		}
+		if cfg.Version != nil {
+			m.Version = cfg.Version.String()
+		}
+		inv.Modules = append(inv.Modules, m)
+	})
+	slices.SortFunc(inv.ResourceTypes, func(a, b *inventoryResourceType) int {
+		if a.Mode != b.Mode {
+			return strings.Compare(a.Mode, b.Mode)
+		}
+		return strings.Compare(a.Type, b.Type)
+	})
+	slices.SortFunc(inv.Modules, func(a, b inventoryModule) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	reqs, _, reqDiags := config.ProviderRequirements()
+	diags = diags.Append(reqDiags)
+	providers := make(map[addrs.Provider]*inventoryProvider)
+	for addr, constraints := range reqs {
+		providers[addr] = &inventoryProvider{
+			Source:             addr.String(),
+			VersionConstraints: getproviders.VersionConstraintsString(constraints),
+		}
+	}
+	for addr, lock := range locks.AllProviders() {
+		p, ok := providers[addr]
+		if !ok {
+			p = &inventoryProvider{Source: addr.String()}
+			providers[addr] = p
+		}
+		p.Version = lock.Version().String()
+	}
+	for _, p := range providers {
+		inv.Providers = append(inv.Providers, *p)
+	}
+	slices.SortFunc(inv.Providers, func(a, b inventoryProvider) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+
+	discovery, moreDiags := c.discover(types)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	inv.Discovery = discovery
+
+	if allWorkspaces {
+		workspaces, moreDiags := c.workspaceResources(ctx, configPath)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		inv.Workspaces = workspaces
+		for _, rt := range inv.ResourceTypes {
+			if rt.Mode != "managed" {
+				continue
+			}
+			instances := 0
+			for _, ws := range workspaces {
+				instances += ws.Resources[rt.Type]
+			}
+			rt.Instances = &instances
+		}
+	}
+
+	c.showDiagnostics(diags)
+	if jsonOutput {
+		j, err := json.MarshalIndent(inv, "", "  ")
+		if err != nil {
+			// Should never happen because we fully-control the input here
+			panic(err)
+		}
+		c.Ui.Output(string(j))
+	} else {
+		c.showInventory(inv)
+	}
+	for _, ws := range inv.Workspaces {
+		if ws.Error != "" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// inventoryResourceMode returns the name of the given resource mode in the
+// inventory.
+func inventoryResourceMode(mode addrs.ResourceMode) string {
+	if mode == addrs.DataResourceMode {
+		return "data"
+	}
+	return "managed"
+}
+
+// discover describes the discovery status of the working directory, like
+// a plan would discover it, and counts the changed resources of each of the
+// given resource types.
+func (c *InventoryCommand) discover(types map[string]*inventoryResourceType) (inventoryDiscovery, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var ret inventoryDiscovery
+
+	dir := c.discoveryDir()
+	sha, err := farseek.ReadSHA(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading SHA", err.Error()), tfdiags.CodeSHAReadFailed))
+		return ret, diags
+	}
+	ret.LastAppliedSHA = sha
+
+	forgotten, err := farseek.ReadIgnored(filepath.Join(c.DataDir(), farseek.IgnoredFilename))
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read ignored resources",
+			fmt.Sprintf("Farseek could not read the resources that it no longer manages: %s.", err),
+		), tfdiags.CodeIgnoredReadFailed))
+		return ret, diags
+	}
+	ret.ForgottenResources = len(forgotten)
+
+	head, err := farseek.Discovery.GetCurrentSHA(dir)
+	if err != nil {
+		ret.Mode = "stateful"
+		return ret, diags
+	}
+	ret.Mode = "stateless"
+	ret.Head = head
+	if branch, err := farseek.Discovery.GetCurrentBranch(dir); err == nil {
+		ret.Branch = branch
+	}
+
+	changed, err := farseek.Discovery.DiscoverChangedResources(dir, sha, false)
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error discovering changed resources", err.Error()), tfdiags.CodeDiscoveryFailed))
+		return ret, diags
+	}
+	rules, err := farseek.LoadIgnoreRules(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read ignored resources",
+			fmt.Sprintf("Farseek could not read the %s file: %s.", farseek.IgnoreFilename, err),
+		), tfdiags.CodeIgnoredReadFailed))
+		return ret, diags
+	}
+	changed, skipped := rules.Filter(changed)
+	ret.IgnoredResources = len(skipped)
+	for _, dr := range changed {
+		if slices.Contains(forgotten, dr.Address) {
+			continue
+		}
+		ret.ChangedResources++
+		target, targetDiags := addrs.ParseTargetStr(dr.Address)
+		if targetDiags.HasErrors() {
+			continue
+		}
+		var resource addrs.Resource
+		switch subject := target.Subject.(type) {
+		case addrs.AbsResource:
+			resource = subject.Resource
+		case addrs.AbsResourceInstance:
+			resource = subject.Resource.Resource
+		default:
+			continue
+		}
+		if rt, ok := types[resource.Mode.String()+"."+resource.Type]; ok {
+			rt.Changed++
+		}
+	}
+	return ret, diags
+}
+
+// workspaceResources counts the managed resource instances in the state of
+// every workspace of the backend, by resource type.
+func (c *InventoryCommand) workspaceResources(ctx context.Context, configPath string) ([]*inventoryWorkspace, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	enc, encDiags := c.EncryptionFromPath(ctx, configPath)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		return nil, diags
+	}
+	backendConfig, backendDiags := c.loadBackendConfig(ctx, configPath)
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		return nil, diags
+	}
+	b, backendDiags := c.Backend(ctx, &BackendOpts{
+		Config: backendConfig,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		return nil, diags
+	}
+
+	names, err := b.Workspaces(ctx)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to list workspaces",
+			fmt.Sprintf("Farseek could not list the workspaces of the backend: %s.", err),
+		))
+		return nil, diags
+	}
+
+	workspaces := make([]*inventoryWorkspace, len(names))
+	var wg sync.WaitGroup
+	sem := farseek.NewSemaphore(workspaceListConcurrency)
+	for i, name := range names {
+		wg.Go(func() {
+			sem.Acquire()
+			defer sem.Release()
+			workspaces[i] = inventoryWorkspaceOf(ctx, b, name)
+		})
+	}
+	wg.Wait()
+	return workspaces, diags
+}
+
+func inventoryWorkspaceOf(ctx context.Context, b backend.Backend, name string) *inventoryWorkspace {
+	ws := &inventoryWorkspace{
+		Name:      name,
+		Resources: make(map[string]int),
+	}
+
+	stateMgr, err := b.StateMgr(ctx, name)
+	if err == nil {
+		err = stateMgr.RefreshState(ctx)
+	}
+	if err != nil {
+		ws.Error = fmt.Sprintf("Failed to load the state: %s", err)
+		return ws
+	}
+
+	state := stateMgr.State()
+	if state == nil {
+		return ws
+	}
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			for _, is := range rs.Instances {
+				if is.Current != nil {
+					ws.Resources[rs.Addr.Resource.Type]++
+				}
+			}
+		}
+	}
+	return ws
+}
+
+// showInventory renders the inventory as tables.
+func (c *InventoryCommand) showInventory(inv *inventory) {
+	var out bytes.Buffer
+
+	out.WriteString("Resource types:\n\n")
+	w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+	header := "  TYPE\tMODE\tPROVIDER\tRESOURCES\tCHANGED"
+	if inv.Workspaces != nil {
+		header += "\tINSTANCES"
+	}
+	fmt.Fprintln(w, header)
+	for _, rt := range inv.ResourceTypes {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%d", rt.Type, rt.Mode, rt.Provider, rt.Resources, rt.Changed)
+		switch {
+		case rt.Instances != nil:
+			fmt.Fprintf(w, "\t%d", *rt.Instances)
+		case inv.Workspaces != nil:
+			fmt.Fprint(w, "\t-")
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	out.WriteString("\nProviders:\n\n")
+	w = tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  PROVIDER\tCONSTRAINTS\tVERSION")
+	for _, p := range inv.Providers {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", p.Source, inventoryOrDash(p.VersionConstraints), inventoryOrDash(p.Version))
+	}
+	w.Flush()
+
+	if len(inv.Modules) != 0 {
+		out.WriteString("\nModules:\n\n")
+		w = tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "  MODULE\tSOURCE\tVERSION")
+		for _, m := range inv.Modules {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", m.Path, m.Source, inventoryOrDash(m.Version))
+		}
+		w.Flush()
+	}
+
+	if inv.Workspaces != nil {
+		out.WriteString("\nWorkspaces:\n\n")
+		w = tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "  WORKSPACE\tRESOURCES")
+		for _, ws := range inv.Workspaces {
+			if ws.Error != "" {
+				fmt.Fprintf(w, "  %s\t-\n", ws.Name)
+				c.Ui.Error(fmt.Sprintf("Workspace %q: %s", ws.Name, ws.Error))
+				continue
+			}
+			total := 0
+			for _, n := range ws.Resources {
+				total += n
+			}
+			fmt.Fprintf(w, "  %s\t%d\n", ws.Name, total)
+		}
+		w.Flush()
+	}
+
+	out.WriteString("\nDiscovery:\n\n")
+	d := inv.Discovery
+	switch {
+	case d.Mode == "stateful":
+		out.WriteString("  Stateful mode: this isn't a git repository, so Farseek plans with the state of the backend.\n")
+	case d.LastAppliedSHA != "":
+		fmt.Fprintf(&out, "  Stateless mode: %d resource(s) changed from the last applied commit %s to %s.\n", d.ChangedResources, shortSHA(d.LastAppliedSHA), shortSHA(d.Head))
+	default:
+		fmt.Fprintf(&out, "  Stateless mode: no commit has been applied yet, so plans include all %d resource(s) at %s.\n", d.ChangedResources, shortSHA(d.Head))
+	}
+	if d.IgnoredResources != 0 {
+		fmt.Fprintf(&out, "  The %s file skips %d changed resource(s).\n", farseek.IgnoreFilename, d.IgnoredResources)
+	}
+	if d.ForgottenResources != 0 {
+		fmt.Fprintf(&out, "  Farseek no longer manages %d forgotten resource(s).\n", d.ForgottenResources)
+	}
+
+	c.Ui.Output(strings.TrimRight(out.String(), "\n"))
+}
+
+func inventoryOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (c *InventoryCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *InventoryCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-all-workspaces": complete.PredictNothing,
+		"-json":           complete.PredictNothing,
+	}
+}
+
+func (c *InventoryCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] inventory [options]
+
+  Summarizes the configuration for platform teams: the number of resources
+  of each type, the versions of the providers and modules it uses, and
+  which resources Farseek discovers as changed since the last applied
+  commit.
+
+  Provider versions are those of the dependency lock file, so run
+  "farseek init" first.
+
+Options:
+
+  -all-workspaces  Also count the resource instances in the state of every
+                   workspace of the backend.
+
+  -json            Print the inventory as JSON.
+
+  -no-color        If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *InventoryCommand) Synopsis() string {
+	return "Summarize resource types, providers and modules in use"
+}
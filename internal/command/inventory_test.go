@@ -0,0 +1,105 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestInventory_json(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("inventory"), td)
+	t.Chdir(td)
+
+	testStateFileDefault(t, testState())
+	testStateFileWorkspaceDefault(t, "staging", testState())
+	testStateFileWorkspaceDefault(t, "empty", states.NewState())
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &InventoryCommand{
+		Meta: Meta{
+			Ui:               ui,
+			View:             view,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+		},
+	}
+	if code := c.Run([]string{"-json", "-all-workspaces"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got inventory
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, ui.OutputWriter.String())
+	}
+	two := 2
+	want := inventory{
+		FormatVersion: "1.0",
+		ResourceTypes: []*inventoryResourceType{
+			{Mode: "data", Type: "test_data_source", Provider: "registry.opentofu.org/hashicorp/test", Resources: 1},
+			{Mode: "managed", Type: "test_instance", Provider: "registry.opentofu.org/hashicorp/test", Resources: 3, Instances: &two},
+		},
+		Providers: []inventoryProvider{
+			{Source: "registry.opentofu.org/hashicorp/test", VersionConstraints: "~> 1.0", Version: "1.2.3"},
+		},
+		Modules: []inventoryModule{
+			{Path: "module.network", Source: "./network"},
+		},
+		Discovery: inventoryDiscovery{Mode: "stateful"},
+		Workspaces: []*inventoryWorkspace{
+			{Name: "default", Resources: map[string]int{"test_instance": 1}},
+			{Name: "empty", Resources: map[string]int{}},
+			{Name: "staging", Resources: map[string]int{"test_instance": 1}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong inventory\n%s", diff)
+	}
+}
+
+func TestInventory_human(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("inventory"), td)
+	t.Chdir(td)
+
+	ui := new(cli.MockUi)
+	c := &InventoryCommand{
+		Meta: Meta{Ui: ui},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := strings.Join([]string{
+		"Resource types:",
+		"",
+		"  TYPE              MODE     PROVIDER                              RESOURCES  CHANGED",
+		"  test_data_source  data     registry.opentofu.org/hashicorp/test  1          0",
+		"  test_instance     managed  registry.opentofu.org/hashicorp/test  3          0",
+		"",
+		"Providers:",
+		"",
+		"  PROVIDER                              CONSTRAINTS  VERSION",
+		"  registry.opentofu.org/hashicorp/test  ~> 1.0       1.2.3",
+		"",
+		"Modules:",
+		"",
+		"  MODULE          SOURCE     VERSION",
+		"  module.network  ./network  -",
+		"",
+		"Discovery:",
+		"",
+		"  Stateful mode: this isn't a git repository, so Farseek plans with the state of the backend.",
+	}, "\n")
+	if diff := cmp.Diff(want, strings.TrimRight(ui.OutputWriter.String(), "\n")); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
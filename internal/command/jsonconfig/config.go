@@ -394,60 +394,72 @@ func marshalModule(c *configs.Config, schemas *farseek.Schemas, addr string) (mo
 
 	module.ModuleCalls = marshalModuleCalls(c, schemas)
 
-	if len(c.Module.Variables) > 0 {
-		vars := make(variables, len(c.Module.Variables))
-		for k, v := range c.Module.Variables {
-			typeConstraint := cty.DynamicPseudoType
-			if v.ConstraintType != cty.NilType {
-				typeConstraint = v.ConstraintType
-			}
+	vars, err := marshalVariables(c.Module.Variables)
+	if err != nil {
+		return module, err
+	}
+	module.Variables = vars
 
-			var typeJSON []byte
-			// We leave the "type" property unset in output when it
-			// would be DynamicPseudoType, because the most typical way to
-			// represent this situation in our source language is to
-			// omit the type argument from the declaration -- it essentially
-			// represents "no type constrant at all" -- and because this
-			// avoids exposing a potentially-confusing detail that cty
-			// describes DynamicPseudoType as "dynamic" in JSON, while HCL
-			// prefers to call it "any".
-			if !typeConstraint.Equals(cty.DynamicPseudoType) {
-				typeJSON, err = typeConstraint.MarshalJSON()
-				if err != nil {
-					// Should not get here, because v.ConstraintType should always
-					// be a valid cty type when it isn't NilType, so this uses
-					// the internal type stringification to get the most detailed
-					// error message in a potential bug report.
-					return module, fmt.Errorf("failed to marshal %#v as JSON: %w", typeConstraint, err)
-				}
-			}
+	return module, nil
+}
 
-			var defaultValJSON []byte
-			var required bool
-			if v.Default == cty.NilVal {
-				defaultValJSON = nil
-				required = true
-			} else {
-				defaultValJSON, err = ctyjson.Marshal(v.Default, v.Default.Type())
-				required = false
-				if err != nil {
-					return module, err
-				}
+// marshalVariables returns the JSON representation of the given input
+// variable declarations, or nil if there are none.
+func marshalVariables(vars map[string]*configs.Variable) (variables, error) {
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	ret := make(variables, len(vars))
+	var err error
+	for k, v := range vars {
+		typeConstraint := cty.DynamicPseudoType
+		if v.ConstraintType != cty.NilType {
+			typeConstraint = v.ConstraintType
+		}
+
+		var typeJSON []byte
+		// We leave the "type" property unset in output when it
+		// would be DynamicPseudoType, because the most typical way to
+		// represent this situation in our source language is to
+		// omit the type argument from the declaration -- it essentially
+		// represents "no type constrant at all" -- and because this
+		// avoids exposing a potentially-confusing detail that cty
+		// describes DynamicPseudoType as "dynamic" in JSON, while HCL
+		// prefers to call it "any".
+		if !typeConstraint.Equals(cty.DynamicPseudoType) {
+			typeJSON, err = typeConstraint.MarshalJSON()
+			if err != nil {
+				// Should not get here, because v.ConstraintType should always
+				// be a valid cty type when it isn't NilType, so this uses
+				// the internal type stringification to get the most detailed
+				// error message in a potential bug report.
+				return nil, fmt.Errorf("failed to marshal %#v as JSON: %w", typeConstraint, err)
 			}
-			vars[k] = &variable{
-				Type:        typeJSON,
-				Default:     defaultValJSON,
-				Required:    required,
-				Description: v.Description,
-				Sensitive:   v.Sensitive,
-				Ephemeral:   v.Ephemeral,
-				Deprecated:  v.Deprecated,
+		}
+
+		var defaultValJSON []byte
+		var required bool
+		if v.Default == cty.NilVal {
+			defaultValJSON = nil
+			required = true
+		} else {
+			defaultValJSON, err = ctyjson.Marshal(v.Default, v.Default.Type())
+			required = false
+			if err != nil {
+				return nil, err
 			}
 		}
-		module.Variables = vars
+		ret[k] = &variable{
+			Type:        typeJSON,
+			Default:     defaultValJSON,
+			Required:    required,
+			Description: v.Description,
+			Sensitive:   v.Sensitive,
+			Ephemeral:   v.Ephemeral,
+			Deprecated:  v.Deprecated,
+		}
 	}
-
-	return module, nil
+	return ret, nil
 }
 
 func marshalModuleCalls(c *configs.Config, schemas *farseek.Schemas) map[string]moduleCall {
@@ -0,0 +1,67 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonconfig
+
+import (
+	"github.com/rafagsiqueira/farseek/internal/configs"
+)
+
+// ModuleSignature describes the interface of a module, which is its input
+// variables and its outputs, along with the signatures of the modules that
+// it calls. Unlike the representation produced by [Marshal], it leaves out
+// the module's resources and all expressions.
+type ModuleSignature struct {
+	Variables   variables                      `json:"variables,omitempty"`
+	Outputs     map[string]output              `json:"outputs,omitempty"`
+	ModuleCalls map[string]moduleCallSignature `json:"module_calls,omitempty"`
+}
+
+type moduleCallSignature struct {
+	Source            string           `json:"source,omitempty"`
+	VersionConstraint string           `json:"version_constraint,omitempty"`
+	Module            *ModuleSignature `json:"module,omitempty"`
+}
+
+// MarshalSignatures returns the signature of the root module of the given
+// configuration, including the signatures of all of its descendent modules.
+func MarshalSignatures(c *configs.Config) (*ModuleSignature, error) {
+	vars, err := marshalVariables(c.Module.Variables)
+	if err != nil {
+		return nil, err
+	}
+	ret := &ModuleSignature{
+		Variables: vars,
+	}
+
+	if len(c.Module.Outputs) > 0 {
+		ret.Outputs = make(map[string]output, len(c.Module.Outputs))
+		for name, o := range c.Module.Outputs {
+			ret.Outputs[name] = output{
+				Sensitive:   o.Sensitive,
+				Ephemeral:   o.Ephemeral,
+				Deprecated:  o.Deprecated,
+				Description: o.Description,
+			}
+		}
+	}
+
+	if len(c.Module.ModuleCalls) > 0 {
+		ret.ModuleCalls = make(map[string]moduleCallSignature, len(c.Module.ModuleCalls))
+		for name, mc := range c.Module.ModuleCalls {
+			call := moduleCallSignature{
+				Source:            mc.SourceAddrRaw,
+				VersionConstraint: mc.Version.Required.String(),
+			}
+			if child := c.Children[name]; child != nil {
+				call.Module, err = MarshalSignatures(child)
+				if err != nil {
+					return nil, err
+				}
+			}
+			ret.ModuleCalls[name] = call
+		}
+	}
+
+	return ret, nil
+}
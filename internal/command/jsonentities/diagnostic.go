@@ -39,10 +39,16 @@ const (
 // range field.
 
 type Diagnostic struct {
-	Severity   string             `json:"severity"`
-	Summary    string             `json:"summary"`
-	Detail     string             `json:"detail"`
-	Address    string             `json:"address,omitempty"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+	Code     string `json:"code,omitempty"`
+	Address  string `json:"address,omitempty"`
+
+	// Annotations are those that the resource the diagnostic is about
+	// declares in its farseek_annotations argument.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
 	Range      *DiagnosticRange   `json:"range,omitempty"`
 	Snippet    *DiagnosticSnippet `json:"snippet,omitempty"`
 	Difference *jsonplan.Change   `json:"difference,omitempty"`
@@ -170,13 +176,15 @@ func NewDiagnostic(diag tfdiags.Diagnostic, sources map[string]*hcl.File) *Diagn
 
 	desc := diag.Description()
 	return &Diagnostic{
-		Severity:   sev,
-		Summary:    desc.Summary,
-		Detail:     desc.Detail,
-		Address:    desc.Address,
-		Range:      newDiagnosticRange(highlightRange),
-		Snippet:    snippet,
-		Difference: difference,
+		Severity:    sev,
+		Summary:     desc.Summary,
+		Detail:      desc.Detail,
+		Code:        string(tfdiags.DiagnosticCode(diag)),
+		Address:     desc.Address,
+		Annotations: tfdiags.DiagnosticAnnotations(diag),
+		Range:       newDiagnosticRange(highlightRange),
+		Snippet:     snippet,
+		Difference:  difference,
 	}
 }
 
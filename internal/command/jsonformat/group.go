@@ -0,0 +1,194 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+)
+
+// GroupBy selects how the human-readable plan groups the resource changes
+// under headings, so that large plans are easier to review.
+type GroupBy string
+
+const (
+	// GroupByNone lists the resource changes without headings, in the order
+	// of the plan.
+	GroupByNone GroupBy = ""
+
+	// GroupByModule groups the resource changes by the module that
+	// declares the resources, with the root module first.
+	GroupByModule GroupBy = "module"
+
+	// GroupByProvider groups the resource changes by the provider of the
+	// resources.
+	GroupByProvider GroupBy = "provider"
+
+	// GroupByAction groups the resource changes by the action that Farseek
+	// will take, in the same order as the legend of the plan.
+	GroupByAction GroupBy = "action"
+)
+
+// groupActionOrder is the order of the groups when grouping by action.
+var groupActionOrder = []plans.Action{
+	plans.Create,
+	plans.Update,
+	plans.Delete,
+	plans.DeleteThenCreate,
+	plans.CreateThenDelete,
+	plans.Read,
+	plans.ForgetThenCreate,
+	plans.Forget,
+	plans.NoOp,
+}
+
+// diffGroup is the changes under one heading of a grouped plan.
+type diffGroup struct {
+	key     string
+	heading string
+	changes []diff
+}
+
+// renderHumanDiffGroups renders the given changes like renderHumanDiffs,
+// but under a heading for each group that renderer.GroupBy selects, with
+// the number of resources to add, change and destroy in the group.
+func renderHumanDiffGroups(renderer Renderer, changes []diff) {
+	if renderer.GroupBy == GroupByNone {
+		renderHumanDiffs(renderer, changes)
+		return
+	}
+
+	for _, group := range groupDiffs(renderer.GroupBy, changes) {
+		renderer.Streams.Println()
+		renderer.Streams.Println(renderer.Colorize.Color(fmt.Sprintf(
+			"[bold]%s[reset] (%s)", group.heading, groupSubtotals(group.changes),
+		)))
+		renderHumanDiffs(renderer, group.changes)
+	}
+}
+
+// groupDiffs returns the given changes in groups, in the order they should
+// be rendered. The changes within each group keep their order.
+func groupDiffs(groupBy GroupBy, changes []diff) []diffGroup {
+	byKey := make(map[string]*diffGroup)
+	var groups []*diffGroup
+	for _, change := range changes {
+		key, heading := groupKey(groupBy, change)
+		group, exists := byKey[key]
+		if !exists {
+			group = &diffGroup{key: key, heading: heading}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.changes = append(group.changes, change)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].key < groups[j].key
+	})
+
+	ret := make([]diffGroup, len(groups))
+	for i, group := range groups {
+		ret[i] = *group
+	}
+	return ret
+}
+
+// groupKey returns the key that orders the group of the given change, and
+// the heading of the group.
+func groupKey(groupBy GroupBy, change diff) (string, string) {
+	switch groupBy {
+	case GroupByModule:
+		if change.change.ModuleAddress == "" {
+			// The empty key sorts the root module first.
+			return "", "Root module"
+		}
+		return change.change.ModuleAddress, change.change.ModuleAddress
+	case GroupByProvider:
+		return change.change.ProviderName, "Provider " + change.change.ProviderName
+	case GroupByAction:
+		action := jsonplan.UnmarshalActions(change.change.Change.Actions)
+		for i, candidate := range groupActionOrder {
+			if candidate == action {
+				return fmt.Sprintf("%02d", i), groupActionHeading(action)
+			}
+		}
+		return action.String(), groupActionHeading(action)
+	default:
+		panic(fmt.Sprintf("unsupported group by %q", groupBy))
+	}
+}
+
+// groupActionHeading returns the heading of the group of changes with the
+// given action.
+func groupActionHeading(action plans.Action) string {
+	switch action {
+	case plans.Create:
+		return "Create"
+	case plans.Update:
+		return "Update in-place"
+	case plans.Delete:
+		return "Destroy"
+	case plans.DeleteThenCreate:
+		return "Destroy and then create replacement"
+	case plans.CreateThenDelete:
+		return "Create replacement and then destroy"
+	case plans.Read:
+		return "Read"
+	case plans.ForgetThenCreate:
+		return "Forget and then create replacement"
+	case plans.Forget:
+		return "Forget"
+	case plans.NoOp:
+		// Changes without an action are only rendered when they import.
+		return "Import"
+	default:
+		return action.String()
+	}
+}
+
+// groupSubtotals returns the numbers of resources that the given changes
+// add, change and destroy, in the form of the plan summary.
+func groupSubtotals(changes []diff) string {
+	var importing, toAdd, toChange, toDestroy, forgetting int
+	for _, change := range changes {
+		if change.Importing() {
+			importing++
+		}
+		switch jsonplan.UnmarshalActions(change.change.Change.Actions) {
+		case plans.Create:
+			toAdd++
+		case plans.Update:
+			toChange++
+		case plans.Delete:
+			toDestroy++
+		case plans.DeleteThenCreate, plans.CreateThenDelete:
+			toAdd++
+			toDestroy++
+		case plans.ForgetThenCreate:
+			toAdd++
+			forgetting++
+		case plans.Forget:
+			forgetting++
+		}
+	}
+
+	var parts []string
+	if importing > 0 {
+		parts = append(parts, fmt.Sprintf("%d to import", importing))
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d to add", toAdd),
+		fmt.Sprintf("%d to change", toChange),
+		fmt.Sprintf("%d to destroy", toDestroy),
+	)
+	if forgetting > 0 {
+		parts = append(parts, fmt.Sprintf("%d to forget", forgetting))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/colorstring"
+
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonprovider"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/terminal"
+)
+
+func TestRenderHuman_GroupBy(t *testing.T) {
+	color := &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true}
+
+	schema := &jsonprovider.Provider{
+		ResourceSchemas: map[string]*jsonprovider.Schema{
+			"test_resource": {
+				Block: &jsonprovider.Block{
+					Attributes: map[string]*jsonprovider.Attribute{
+						"id": {
+							AttributeType: marshalJson(t, "string"),
+						},
+					},
+				},
+			},
+		},
+	}
+	schemas := map[string]*jsonprovider.Provider{
+		"test":  schema,
+		"other": schema,
+	}
+
+	change := func(module, name, provider, action string) jsonplan.ResourceChange {
+		address := "test_resource." + name
+		if module != "" {
+			address = module + "." + address
+		}
+		before, after := marshalJson(t, map[string]interface{}{"id": "old"}), marshalJson(t, map[string]interface{}{"id": "new"})
+		switch action {
+		case "create":
+			before = marshalJson(t, nil)
+		case "delete":
+			after = marshalJson(t, nil)
+		}
+		return jsonplan.ResourceChange{
+			Address:       address,
+			ModuleAddress: module,
+			Mode:          "managed",
+			Type:          "test_resource",
+			Name:          name,
+			ProviderName:  provider,
+			Change: jsonplan.Change{
+				Actions: []string{action},
+				Before:  before,
+				After:   after,
+			},
+		}
+	}
+	changes := []jsonplan.ResourceChange{
+		change("module.network", "a", "test", "update"),
+		change("", "b", "other", "create"),
+		change("module.network", "c", "test", "create"),
+		change("module.db", "d", "other", "delete"),
+	}
+
+	tcs := map[string]struct {
+		groupBy GroupBy
+		output  string
+	}{
+		"module": {
+			groupBy: GroupByModule,
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  + create
+  ~ update in-place (current -> planned)
+  - destroy
+
+Farseek will perform the following actions:
+
+Root module (1 to add, 0 to change, 0 to destroy)
+
+  # test_resource.b will be created
+  + resource "test_resource" "b" {
+      + id = "new"
+    }
+
+module.db (0 to add, 0 to change, 1 to destroy)
+
+  # module.db.test_resource.d will be destroyed
+  - resource "test_resource" "d" {
+      - id = "old" -> null
+    }
+
+module.network (1 to add, 1 to change, 0 to destroy)
+
+  # module.network.test_resource.a will be updated in-place
+  ~ resource "test_resource" "a" {
+      ~ id = "old" -> "new"
+    }
+
+  # module.network.test_resource.c will be created
+  + resource "test_resource" "c" {
+      + id = "new"
+    }
+
+Plan: 2 to add, 1 to change, 1 to destroy.
+`,
+		},
+		"provider": {
+			groupBy: GroupByProvider,
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  + create
+  ~ update in-place (current -> planned)
+  - destroy
+
+Farseek will perform the following actions:
+
+Provider other (1 to add, 0 to change, 1 to destroy)
+
+  # test_resource.b will be created
+  + resource "test_resource" "b" {
+      + id = "new"
+    }
+
+  # module.db.test_resource.d will be destroyed
+  - resource "test_resource" "d" {
+      - id = "old" -> null
+    }
+
+Provider test (1 to add, 1 to change, 0 to destroy)
+
+  # module.network.test_resource.a will be updated in-place
+  ~ resource "test_resource" "a" {
+      ~ id = "old" -> "new"
+    }
+
+  # module.network.test_resource.c will be created
+  + resource "test_resource" "c" {
+      + id = "new"
+    }
+
+Plan: 2 to add, 1 to change, 1 to destroy.
+`,
+		},
+		"action": {
+			groupBy: GroupByAction,
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  + create
+  ~ update in-place (current -> planned)
+  - destroy
+
+Farseek will perform the following actions:
+
+Create (2 to add, 0 to change, 0 to destroy)
+
+  # test_resource.b will be created
+  + resource "test_resource" "b" {
+      + id = "new"
+    }
+
+  # module.network.test_resource.c will be created
+  + resource "test_resource" "c" {
+      + id = "new"
+    }
+
+Update in-place (0 to add, 1 to change, 0 to destroy)
+
+  # module.network.test_resource.a will be updated in-place
+  ~ resource "test_resource" "a" {
+      ~ id = "old" -> "new"
+    }
+
+Destroy (0 to add, 0 to change, 1 to destroy)
+
+  # module.db.test_resource.d will be destroyed
+  - resource "test_resource" "d" {
+      - id = "old" -> null
+    }
+
+Plan: 2 to add, 1 to change, 1 to destroy.
+`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+
+			plan := Plan{
+				PlanFormatVersion:     jsonplan.FormatVersion,
+				ProviderFormatVersion: jsonprovider.FormatVersion,
+				ProviderSchemas:       schemas,
+				ResourceChanges:       changes,
+			}
+
+			renderer := Renderer{
+				Colorize: color,
+				Streams:  streams,
+				GroupBy:  tc.groupBy,
+			}
+			plan.renderHuman(renderer, plans.NormalMode)
+
+			got := done(t).Stdout()
+			want := tc.output
+			if diff := cmp.Diff(want, got); len(diff) > 0 {
+				t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+			}
+		})
+	}
+}
@@ -64,12 +64,16 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 
 	diffs := precomputeDiffs(plan, mode)
 	haveRefreshChanges := renderHumanDiffDrift(renderer, diffs, mode)
+	renderHumanOutOfBandChanges(renderer, plan.ResourceChanges)
 
 	willPrintResourceChanges := false
 	counts := make(map[plans.Action]int)
 	importingCount := 0
 	forgettingCount := 0
-	var changes []diff
+	// Moves without other changes, resources that Farseek stops managing, and
+	// resources removed from version control each get a section of their
+	// own, so that they don't get lost among the other changes.
+	var changes, moved, forgotten, removedFromVCS []diff
 	for _, diff := range diffs.changes {
 		action := jsonplan.UnmarshalActions(diff.change.Change.Actions)
 		if action == plans.NoOp && !diff.Moved() && !diff.Importing() {
@@ -85,7 +89,16 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 			continue
 		}
 
-		changes = append(changes, diff)
+		switch {
+		case action == plans.NoOp && !diff.Importing():
+			moved = append(moved, diff)
+		case action == plans.Forget:
+			forgotten = append(forgotten, diff)
+		case action == plans.Delete && diff.change.ActionReason == jsonplan.ResourceInstanceDeleteBecauseRemovedFromVCS:
+			removedFromVCS = append(removedFromVCS, diff)
+		default:
+			changes = append(changes, diff)
+		}
 
 		if diff.Importing() {
 			importingCount++
@@ -101,12 +114,13 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 			counts[action]++
 		}
 	}
+	haveChanges := len(changes) > 0 || len(moved) > 0 || len(forgotten) > 0 || len(removedFromVCS) > 0
 
 	// Precompute the outputs early, so we can make a decision about whether we
 	// display the "there are no changes messages".
 	outputs := renderHumanDiffOutputs(renderer, diffs.outputs)
 
-	if len(changes) == 0 && len(outputs) == 0 {
+	if !haveChanges && len(outputs) == 0 {
 		// If we didn't find any changes to report at all then this is a
 		// "No changes" plan. How we'll present this depends on whether
 		// the plan is "applyable" and, if so, whether it had refresh changes
@@ -224,18 +238,37 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 		}
 	}
 
-	if len(changes) > 0 {
-		if checkOpts(plans.Errored) {
-			renderer.Streams.Printf("\nFarseek planned the following actions, but then encountered a problem:\n")
-		} else {
-			renderer.Streams.Printf("\nFarseek will perform the following actions:\n")
+	if haveChanges {
+		if len(changes) > 0 {
+			if checkOpts(plans.Errored) {
+				renderer.Streams.Printf("\nFarseek planned the following actions, but then encountered a problem:\n")
+			} else {
+				renderer.Streams.Printf("\nFarseek will perform the following actions:\n")
+			}
+			renderHumanDiffGroups(renderer, changes)
 		}
 
-		for _, change := range changes {
-			diff, render := renderHumanDiff(renderer, change, proposedChange)
-			if render {
-				fmt.Fprintln(renderer.Streams.Stdout.File)
-				renderer.Streams.Println(diff)
+		if len(removedFromVCS) > 0 {
+			renderer.Streams.Println(format.WordWrap(
+				"\nFarseek will destroy the following resources, which were removed from the configuration in version control:",
+				renderer.Streams.Stdout.Columns()))
+			renderHumanDiffGroups(renderer, removedFromVCS)
+		}
+
+		if len(forgotten) > 0 {
+			renderer.Streams.Println(format.WordWrap(
+				"\nFarseek will stop managing the following resources, but will not destroy them:",
+				renderer.Streams.Stdout.Columns()))
+			renderHumanDiffGroups(renderer, forgotten)
+		}
+
+		if len(moved) > 0 {
+			renderer.Streams.Println(format.WordWrap(
+				"\nFarseek will record that the following resources have moved, without changing them:",
+				renderer.Streams.Stdout.Columns()))
+			fmt.Fprintln(renderer.Streams.Stdout.File)
+			for _, diff := range moved {
+				renderer.Streams.Println(renderer.Colorize.Color(fmt.Sprintf("  # [bold]%s[reset] has moved to [bold]%s[reset]", diff.change.PreviousAddress, diff.change.Address)))
 			}
 		}
 
@@ -295,6 +328,18 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 	}
 }
 
+// renderHumanDiffs renders each of the given changes, separated by blank
+// lines.
+func renderHumanDiffs(renderer Renderer, changes []diff) {
+	for _, change := range changes {
+		diff, render := renderHumanDiff(renderer, change, proposedChange)
+		if render {
+			fmt.Fprintln(renderer.Streams.Stdout.File)
+			renderer.Streams.Println(diff)
+		}
+	}
+}
+
 func renderHumanDiffOutputs(renderer Renderer, outputs map[string]computed.Diff) string {
 	var rendered []string
 
@@ -378,6 +423,36 @@ func renderHumanDiffDrift(renderer Renderer, diffs diffs, mode plans.Mode) bool
 	return true
 }
 
+// renderHumanOutOfBandChanges renders the attributes of resources that were
+// changed outside of version control since the base SHA of a plan in Farseek
+// mode, apart from the changes that the configuration drives.
+func renderHumanOutOfBandChanges(renderer Renderer, changes []jsonplan.ResourceChange) {
+	var buf strings.Builder
+	for _, change := range changes {
+		if len(change.OutOfBandChanges) == 0 {
+			continue
+		}
+		buf.WriteString(renderer.Colorize.Color(fmt.Sprintf("\n  [bold]# %s[reset]\n", change.Address)))
+		for _, oob := range change.OutOfBandChanges {
+			value := "(sensitive value)"
+			if !oob.Sensitive {
+				value = fmt.Sprintf("%s -> %s", oob.Declared, oob.Actual)
+			}
+			buf.WriteString(fmt.Sprintf("    %s %s = %s\n", renderer.Colorize.Color(renderers.DiffActionSymbol(plans.Update)), oob.Attribute, value))
+		}
+	}
+	if buf.Len() == 0 {
+		return
+	}
+
+	renderer.Streams.Print(renderer.Colorize.Color("\n[bold][cyan]Note:[reset][bold] Objects have changed outside of version control\n"))
+	renderer.Streams.Println()
+	renderer.Streams.Print(format.WordWrap(
+		"Farseek detected the following attributes whose values differ from those the configuration declared at the last applied commit, so they were changed outside of version control, such as in the console of the provider:\n",
+		renderer.Streams.Stdout.Columns()))
+	renderer.Streams.Print(buf.String())
+}
+
 func renderHumanDiff(renderer Renderer, diff diff, cause string) (string, bool) {
 	if diff.change.Mode == jsonstate.EphemeralResourceMode {
 		// render nothing for ephemeral resources
@@ -475,6 +550,8 @@ func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action
 			buf.WriteString(fmt.Sprintf("\n  # (because %s.%s is not in configuration)", resource.Type, resource.Name))
 		case jsonplan.ResourceInstanceDeleteBecauseNoMoveTarget:
 			buf.WriteString(fmt.Sprintf("\n  # (because %s was moved to %s, which is not in configuration)", resource.PreviousAddress, resource.Address))
+		case jsonplan.ResourceInstanceDeleteBecauseRemovedFromVCS:
+			buf.WriteString(fmt.Sprintf("\n  # (because %s.%s was removed from the configuration in version control)", resource.Type, resource.Name))
 		case jsonplan.ResourceInstanceDeleteBecauseNoModule:
 			// FIXME: Ideally we'd truncate addr.Module to reflect the earliest
 			// step that doesn't exist, so it's clearer which call this refers
@@ -598,7 +675,32 @@ func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action
 	if resource.Change.Importing != nil && (action == plans.CreateThenDelete || action == plans.DeleteThenCreate) {
 		buf.WriteString("  # [reset][yellow]Warning: this will destroy the imported resource[reset]\n")
 	}
+	if resource.ReplaceReason != "" {
+		buf.WriteString(fmt.Sprintf("  # [reset](reason: %s)\n", resource.ReplaceReason))
+	}
+	if resource.Commit != nil {
+		buf.WriteString(fmt.Sprintf("  # [reset](%s)\n", commitDescription(resource.Commit)))
+	}
+
+	return buf.String()
+}
 
+// commitDescription describes who changed a resource in which commit, such
+// as "changed by alice in a1b2c3d: resize instance".
+func commitDescription(commit *jsonplan.Commit) string {
+	sha := commit.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	var buf strings.Builder
+	buf.WriteString("changed")
+	if commit.Author != "" {
+		buf.WriteString(" by " + commit.Author)
+	}
+	buf.WriteString(" in " + sha)
+	if commit.Message != "" {
+		buf.WriteString(": " + commit.Message)
+	}
 	return buf.String()
 }
 
@@ -431,6 +431,271 @@ Plan: 1 to import, 1 to add, 0 to change, 1 to destroy.
 	}
 }
 
+func TestRenderHuman_Sections(t *testing.T) {
+	color := &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true}
+
+	schemas := map[string]*jsonprovider.Provider{
+		"test": {
+			ResourceSchemas: map[string]*jsonprovider.Schema{
+				"test_resource": {
+					Block: &jsonprovider.Block{
+						Attributes: map[string]*jsonprovider.Attribute{
+							"id": {
+								AttributeType: marshalJson(t, "string"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	state := marshalJson(t, map[string]interface{}{
+		"id": "i-1234",
+	})
+
+	tcs := map[string]struct {
+		plan   Plan
+		output string
+	}{
+		"moved": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:         "test_resource.after",
+						PreviousAddress: "test_resource.before",
+						Mode:            "managed",
+						Type:            "test_resource",
+						Name:            "after",
+						ProviderName:    "test",
+						Change: jsonplan.Change{
+							Actions: []string{"no-op"},
+							Before:  state,
+							After:   state,
+						},
+					},
+				},
+			},
+			output: `
+Farseek will record that the following resources have moved, without changing
+them:
+
+  # test_resource.before has moved to test_resource.after
+
+Plan: 0 to add, 0 to change, 0 to destroy.
+`,
+		},
+		"forgotten": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_resource.resource",
+						Mode:         "managed",
+						Type:         "test_resource",
+						Name:         "resource",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions: []string{"forget"},
+							Before:  state,
+							After:   marshalJson(t, nil),
+						},
+					},
+				},
+			},
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  . forget
+
+Farseek will stop managing the following resources, but will not destroy
+them:
+
+  # test_resource.resource will be removed from the Farseek state but will not be destroyed
+  . resource "test_resource" "resource" {
+    id = "i-1234"
+}
+
+Plan: 0 to add, 0 to change, 0 to destroy, 1 to forget.
+`,
+		},
+		"removed_from_vcs": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_resource.resource",
+						Mode:         "managed",
+						Type:         "test_resource",
+						Name:         "resource",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions: []string{"delete"},
+							Before:  state,
+							After:   marshalJson(t, nil),
+						},
+						ActionReason: jsonplan.ResourceInstanceDeleteBecauseRemovedFromVCS,
+					},
+				},
+			},
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  - destroy
+
+Farseek will destroy the following resources, which were removed from the
+configuration in version control:
+
+  # test_resource.resource will be destroyed
+  # (because test_resource.resource was removed from the configuration in version control)
+  - resource "test_resource" "resource" {
+      - id = "i-1234" -> null
+    }
+
+Plan: 0 to add, 0 to change, 1 to destroy.
+`,
+		},
+		"commit": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_resource.resource",
+						Mode:         "managed",
+						Type:         "test_resource",
+						Name:         "resource",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions: []string{"create"},
+							Before:  marshalJson(t, nil),
+							After:   state,
+						},
+						Commit: &jsonplan.Commit{
+							SHA:     "a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0",
+							Author:  "alice",
+							Message: "resize instance",
+						},
+					},
+				},
+			},
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  + create
+
+Farseek will perform the following actions:
+
+  # test_resource.resource will be created
+  # (changed by alice in a1b2c3d: resize instance)
+  + resource "test_resource" "resource" {
+      + id = "i-1234"
+    }
+
+Plan: 1 to add, 0 to change, 0 to destroy.
+`,
+		},
+		"replace_reason": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_resource.resource",
+						Mode:         "managed",
+						Type:         "test_resource",
+						Name:         "resource",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions: []string{"delete", "create"},
+							Before:  state,
+							After:   state,
+						},
+						ActionReason:  jsonplan.ResourceInstanceReplaceByRequest,
+						ReplaceReason: "kernel upgrade",
+					},
+				},
+			},
+			output: `
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+-/+ destroy and then create replacement
+
+Farseek will perform the following actions:
+
+  # test_resource.resource will be replaced, as requested
+  # (reason: kernel upgrade)
+-/+ resource "test_resource" "resource" {
+        id = "i-1234"
+    }
+
+Plan: 1 to add, 0 to change, 1 to destroy.
+`,
+		},
+		"out_of_band": {
+			plan: Plan{
+				ResourceChanges: []jsonplan.ResourceChange{
+					{
+						Address:      "test_resource.resource",
+						Mode:         "managed",
+						Type:         "test_resource",
+						Name:         "resource",
+						ProviderName: "test",
+						Change: jsonplan.Change{
+							Actions: []string{"update"},
+							Before:  marshalJson(t, map[string]interface{}{"id": "i-5678"}),
+							After:   state,
+						},
+						OutOfBandChanges: []jsonplan.OutOfBandChange{
+							{Attribute: "id", Declared: marshalJson(t, "i-1234"), Actual: marshalJson(t, "i-5678")},
+							{Attribute: "password", Sensitive: true},
+						},
+					},
+				},
+			},
+			output: `
+Note: Objects have changed outside of version control
+
+Farseek detected the following attributes whose values differ from those the
+configuration declared at the last applied commit, so they were changed
+outside of version control, such as in the console of the provider:
+
+  # test_resource.resource
+      ~ id = "i-1234" -> "i-5678"
+      ~ password = (sensitive value)
+
+Farseek used the selected providers to generate the following execution plan.
+Resource actions are indicated with the following symbols:
+  ~ update in-place (current -> planned)
+
+Farseek will perform the following actions:
+
+  # test_resource.resource will be updated in-place
+  ~ resource "test_resource" "resource" {
+      ~ id = "i-5678" -> "i-1234"
+    }
+
+Plan: 0 to add, 1 to change, 0 to destroy.
+`,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+
+			plan := tc.plan
+			plan.PlanFormatVersion = jsonplan.FormatVersion
+			plan.ProviderFormatVersion = jsonprovider.FormatVersion
+			plan.ProviderSchemas = schemas
+
+			renderer := Renderer{
+				Colorize: color,
+				Streams:  streams,
+			}
+			plan.renderHuman(renderer, plans.NormalMode)
+
+			got := done(t).Stdout()
+			want := tc.output
+			if diff := cmp.Diff(want, got); len(diff) > 0 {
+				t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+			}
+		})
+	}
+}
+
 func TestResourceChange_primitiveTypes(t *testing.T) {
 	testCases := map[string]testCase{
 		"creation": {
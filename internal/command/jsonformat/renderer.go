@@ -84,6 +84,9 @@ type Renderer struct {
 
 	RunningInAutomation bool
 	ShowSensitive       bool
+
+	// GroupBy selects how RenderHumanPlan groups the resource changes.
+	GroupBy GroupBy
 }
 
 func (renderer Renderer) RenderHumanPlan(plan Plan, mode plans.Mode, opts ...plans.Quality) {
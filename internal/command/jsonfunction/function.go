@@ -59,6 +59,31 @@ func Marshal(f map[string]function.Function) ([]byte, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	signatures := newFunctions()
 
+	sigs, sigDiags := MarshalSignatures(f)
+	diags = diags.Append(sigDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	signatures.Signatures = sigs
+
+	ret, err := json.Marshal(signatures)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to serialize functions",
+			err.Error(),
+		))
+		return nil, diags
+	}
+	return ret, nil
+}
+
+// MarshalSignatures returns the signatures of the given functions, by name,
+// for callers that include them in a larger JSON document.
+func MarshalSignatures(f map[string]function.Function) (map[string]*FunctionSignature, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	signatures := make(map[string]*FunctionSignature, len(f))
+
 	for name, v := range f {
 		// Even though it's not possible to have a provider namespaced function end up in here,
 		// we want to qualify the function name to be sure that we check exactly for the
@@ -66,9 +91,9 @@ func Marshal(f map[string]function.Function) ([]byte, tfdiags.Diagnostics) {
 		fqFuncAddr := addrs.ParseFunction(name).FullyQualified().String()
 		switch fqFuncAddr {
 		case addrs.ParseFunction("can").FullyQualified().String():
-			signatures.Signatures[name] = marshalCan(v)
+			signatures[name] = marshalCan(v)
 		case addrs.ParseFunction("try").FullyQualified().String():
-			signatures.Signatures[name] = marshalTry(v)
+			signatures[name] = marshalTry(v)
 		default:
 			signature, err := marshalFunction(v)
 			if err != nil {
@@ -78,24 +103,14 @@ func Marshal(f map[string]function.Function) ([]byte, tfdiags.Diagnostics) {
 					err.Error(),
 				))
 			}
-			signatures.Signatures[name] = signature
+			signatures[name] = signature
 		}
 	}
 
 	if diags.HasErrors() {
 		return nil, diags
 	}
-
-	ret, err := json.Marshal(signatures)
-	if err != nil {
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Failed to serialize functions",
-			err.Error(),
-		))
-		return nil, diags
-	}
-	return ret, nil
+	return signatures, nil
 }
 
 func marshalFunction(f function.Function) (*FunctionSignature, error) {
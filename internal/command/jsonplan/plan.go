@@ -34,7 +34,7 @@ import (
 // incremented for any change to this format that requires changes to a
 // consuming parser.
 const (
-	FormatVersion = "1.2"
+	FormatVersion = "1.6"
 
 	ResourceInstanceReplaceBecauseCannotUpdate            = "replace_because_cannot_update"
 	ResourceInstanceReplaceBecauseTainted                 = "replace_because_tainted"
@@ -47,6 +47,7 @@ const (
 	ResourceInstanceDeleteBecauseEachKey                  = "delete_because_each_key"
 	ResourceInstanceDeleteBecauseNoModule                 = "delete_because_no_module"
 	ResourceInstanceDeleteBecauseNoMoveTarget             = "delete_because_no_move_target"
+	ResourceInstanceDeleteBecauseRemovedFromVCS           = "delete_because_removed_from_vcs"
 	ResourceInstanceReadBecauseConfigUnknown              = "read_because_config_unknown"
 	ResourceInstanceReadBecauseDependencyPending          = "read_because_dependency_pending"
 	ResourceInstanceReadBecauseCheckNested                = "read_because_check_nested"
@@ -182,6 +183,36 @@ type Variable struct {
 func MarshalForRenderer(
 	p *plans.Plan,
 	schemas *farseek.Schemas,
+) (map[string]Change, []ResourceChange, []ResourceChange, []ResourceAttr, error) {
+	return MarshalForRendererFrom(p, PlanResourceChanges(p), schemas)
+}
+
+// ResourceChangeSource calls fn for each of the planned resource changes of
+// a plan, stopping early if fn returns an error, like
+// planfile.Reader.ResourceChanges.
+type ResourceChangeSource func(fn func(*plans.ResourceInstanceChangeSrc) error) error
+
+// PlanResourceChanges returns a ResourceChangeSource for the resource changes
+// held in the given plan.
+func PlanResourceChanges(p *plans.Plan) ResourceChangeSource {
+	return func(fn func(*plans.ResourceInstanceChangeSrc) error) error {
+		for _, rc := range p.Changes.Resources {
+			if err := fn(rc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// MarshalForRendererFrom is like MarshalForRenderer, but takes the planned
+// resource changes from the given source rather than from the plan. Each
+// change is marshaled as soon as it's read, so a source that decodes them
+// lazily never has to hold all of them at once.
+func MarshalForRendererFrom(
+	p *plans.Plan,
+	resourceChanges ResourceChangeSource,
+	schemas *farseek.Schemas,
 ) (map[string]Change, []ResourceChange, []ResourceChange, []ResourceAttr, error) {
 	output := newPlan()
 
@@ -190,9 +221,17 @@ func MarshalForRenderer(
 		return nil, nil, nil, nil, err
 	}
 
-	if output.ResourceChanges, err = MarshalResourceChanges(p.Changes.Resources, schemas); err != nil {
+	if output.ResourceChanges, err = marshalResourceChangesFrom(resourceChanges, schemas); err != nil {
 		return nil, nil, nil, nil, err
 	}
+	markReplaceReasons(output.ResourceChanges, p.ForceReplaceReasons)
+	if p.FarseekMode {
+		markRemovedFromVCS(output.ResourceChanges)
+		markCommits(output.ResourceChanges, p.Commits)
+		if err := markOutOfBandChanges(output.ResourceChanges, p.OutOfBandChanges); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
 
 	if len(p.DriftedResources) > 0 {
 		// In refresh-only mode, we render all resources marked as drifted,
@@ -278,6 +317,15 @@ func MarshalForLog(
 		if err != nil {
 			return nil, fmt.Errorf("error in marshaling resource changes: %w", err)
 		}
+		markAnnotations(output.ResourceChanges, config)
+		markReplaceReasons(output.ResourceChanges, p.ForceReplaceReasons)
+		if p.FarseekMode {
+			markRemovedFromVCS(output.ResourceChanges)
+			markCommits(output.ResourceChanges, p.Commits)
+			if err := markOutOfBandChanges(output.ResourceChanges, p.OutOfBandChanges); err != nil {
+				return nil, fmt.Errorf("error in marshaling out-of-band changes: %w", err)
+			}
+		}
 	}
 
 	// output.OutputChanges
@@ -314,18 +362,18 @@ func Marshal(
 	sf *statefile.File,
 	schemas *farseek.Schemas,
 ) ([]byte, error) {
-	output, err := MarshalForLog(config, p, sf, schemas)
-	if err != nil {
-		return nil, err
-	}
-
-	return json.Marshal(output)
+	return MarshalVersion(FormatVersion, config, p, sf, schemas)
 }
 
 func (p *Plan) marshalPlanVariables(vars map[string]plans.DynamicValue, decls map[string]*configs.Variable) error {
 	p.Variables = make(Variables, len(vars))
 
 	for k, v := range vars {
+		if decl, ok := decls[k]; ok && decl.Ephemeral {
+			// The values of ephemeral variables must never be persisted,
+			// and the JSON plan is often saved alongside the plan file.
+			continue
+		}
 		val, err := v.Decode(cty.DynamicPseudoType)
 		if err != nil {
 			return err
@@ -355,7 +403,7 @@ func (p *Plan) marshalPlanVariables(vars map[string]plans.DynamicValue, decls ma
 	// on our old behavior and so we'll fake it here just in time so that
 	// outside consumers won't see a behavior change.
 	for name, decl := range decls {
-		if _, ok := p.Variables[name]; ok {
+		if _, ok := p.Variables[name]; ok || decl.Ephemeral {
 			continue
 		}
 		if val := decl.Default; val != cty.NilVal {
@@ -376,6 +424,43 @@ func (p *Plan) marshalPlanVariables(vars map[string]plans.DynamicValue, decls ma
 	return nil
 }
 
+// marshalResourceChangesFrom is like MarshalResourceChanges, but marshals
+// each of the changes as the source yields it, and sorts the results in the
+// same order at the end.
+func marshalResourceChangesFrom(resourceChanges ResourceChangeSource, schemas *farseek.Schemas) ([]ResourceChange, error) {
+	type marshaledChange struct {
+		addr    addrs.AbsResourceInstance
+		deposed states.DeposedKey
+		change  ResourceChange
+	}
+	var changes []marshaledChange
+	err := resourceChanges(func(rc *plans.ResourceInstanceChangeSrc) error {
+		marshaled, err := MarshalResourceChanges([]*plans.ResourceInstanceChangeSrc{rc}, schemas)
+		if err != nil {
+			return err
+		}
+		for _, change := range marshaled {
+			changes = append(changes, marshaledChange{rc.Addr, rc.DeposedKey, change})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].addr.Equal(changes[j].addr) {
+			return changes[i].addr.Less(changes[j].addr)
+		}
+		return changes[i].deposed < changes[j].deposed
+	})
+	var ret []ResourceChange
+	for _, c := range changes {
+		ret = append(ret, c.change)
+	}
+	return ret, nil
+}
+
 // MarshalResourceChanges converts the provided internal representation of
 // ResourceInstanceChangeSrc objects into the public structured JSON changes.
 //
@@ -592,6 +677,103 @@ func MarshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schema
 	return ret, nil
 }
 
+// markRemovedFromVCS gives a more specific reason to the deletions in a plan
+// created in Farseek stateless mode. In that mode the prior state only has
+// the resources that were in the configuration at the previous commit, so a
+// resource without configuration was removed from version control. Resources
+// that a removed block forgets keep their original reason.
+func markRemovedFromVCS(changes []ResourceChange) {
+	for i := range changes {
+		if changes[i].ActionReason != ResourceInstanceDeleteBecauseNoResourceConfig {
+			continue
+		}
+		if actions := changes[i].Change.Actions; len(actions) == 1 && actions[0] == "delete" {
+			changes[i].ActionReason = ResourceInstanceDeleteBecauseRemovedFromVCS
+		}
+	}
+}
+
+// markAnnotations sets the annotations of each of the given resource changes
+// from the farseek_annotations argument of its resource in the configuration.
+func markAnnotations(changes []ResourceChange, config *configs.Config) {
+	for i := range changes {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(changes[i].Address)
+		if diags.HasErrors() {
+			continue
+		}
+		changes[i].Annotations = config.ResourceAnnotations(addr.ConfigResource())
+	}
+}
+
+// markReplaceReasons sets the reason of each of the given resource changes
+// that replaces its resource instance because the -replace option asked to,
+// from the reasons given with the option.
+func markReplaceReasons(changes []ResourceChange, reasons map[string]string) {
+	if len(reasons) == 0 {
+		return
+	}
+	for i := range changes {
+		if changes[i].ActionReason != ResourceInstanceReplaceByRequest {
+			continue
+		}
+		changes[i].ReplaceReason = reasons[changes[i].Address]
+	}
+}
+
+// markCommits sets the commit of each of the given resource changes from the
+// commits that last changed the configuration of each resource.
+func markCommits(changes []ResourceChange, commits map[string]*plans.Commit) {
+	if len(commits) == 0 {
+		return
+	}
+	for i := range changes {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(changes[i].Address)
+		if diags.HasErrors() {
+			continue
+		}
+		commit, ok := commits[addr.ContainingResource().String()]
+		if !ok {
+			continue
+		}
+		changes[i].Commit = &Commit{
+			SHA:       commit.SHA,
+			Author:    commit.Author,
+			Timestamp: commit.Time.Format(time.RFC3339),
+			Message:   commit.Message,
+		}
+	}
+}
+
+// markOutOfBandChanges sets the out-of-band changes of each of the given
+// resource changes, leaving out the values of sensitive attributes.
+func markOutOfBandChanges(changes []ResourceChange, outOfBand map[string][]*plans.OutOfBandChange) error {
+	if len(outOfBand) == 0 {
+		return nil
+	}
+	for i := range changes {
+		if changes[i].Deposed != "" {
+			continue
+		}
+		for _, oob := range outOfBand[changes[i].Address] {
+			change := OutOfBandChange{
+				Attribute: oob.Attribute,
+				Sensitive: oob.Sensitive,
+			}
+			if !oob.Sensitive {
+				var err error
+				if change.Declared, err = ctyjson.Marshal(oob.Declared, oob.Declared.Type()); err != nil {
+					return fmt.Errorf("%s: %w", changes[i].Address, err)
+				}
+				if change.Actual, err = ctyjson.Marshal(oob.Actual, oob.Actual.Type()); err != nil {
+					return fmt.Errorf("%s: %w", changes[i].Address, err)
+				}
+			}
+			changes[i].OutOfBandChanges = append(changes[i].OutOfBandChanges, change)
+		}
+	}
+	return nil
+}
+
 func ensureEphemeralMarksAreValid(addr addrs.AbsResourceInstance, valMarks []cty.PathValueMarks) error {
 	// ephemeral resources will have the ephemeral mark at the root of the value, got from schema.ValueMarks
 	// so we don't want to error for those particular ones
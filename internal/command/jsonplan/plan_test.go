@@ -13,11 +13,13 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/lang/marks"
 	"github.com/rafagsiqueira/farseek/internal/plans"
 )
@@ -564,3 +566,132 @@ func TestGenerateChange(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkRemovedFromVCS(t *testing.T) {
+	changes := []ResourceChange{
+		{Address: "test_instance.a", Change: Change{Actions: []string{"delete"}}, ActionReason: ResourceInstanceDeleteBecauseNoResourceConfig},
+		{Address: "test_instance.b", Change: Change{Actions: []string{"delete"}}, ActionReason: ResourceInstanceDeleteBecauseNoModule},
+		{Address: "test_instance.c", Change: Change{Actions: []string{"forget"}}, ActionReason: ResourceInstanceDeleteBecauseNoResourceConfig},
+		{Address: "test_instance.d", Change: Change{Actions: []string{"no-op"}}},
+	}
+	markRemovedFromVCS(changes)
+
+	want := []string{ResourceInstanceDeleteBecauseRemovedFromVCS, ResourceInstanceDeleteBecauseNoModule, ResourceInstanceDeleteBecauseNoResourceConfig, ""}
+	for i, change := range changes {
+		if change.ActionReason != want[i] {
+			t.Errorf("wrong action reason for %s: got %q, want %q", change.Address, change.ActionReason, want[i])
+		}
+	}
+}
+
+func TestMarkCommits(t *testing.T) {
+	commit := &plans.Commit{
+		SHA:     "a1b2c3d",
+		Author:  "alice",
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "resize instance",
+	}
+	changes := []ResourceChange{
+		{Address: "test_instance.a[0]"},
+		{Address: "test_instance.b"},
+		{Address: "data.test_data_source.a"},
+	}
+	markCommits(changes, map[string]*plans.Commit{
+		"test_instance.a":         commit,
+		"data.test_data_source.a": commit,
+	})
+
+	want := &Commit{SHA: "a1b2c3d", Author: "alice", Timestamp: "2024-01-02T03:04:05Z", Message: "resize instance"}
+	for i, wantCommit := range []*Commit{want, nil, want} {
+		if diff := cmp.Diff(wantCommit, changes[i].Commit); diff != "" {
+			t.Errorf("wrong commit for %s\n%s", changes[i].Address, diff)
+		}
+	}
+}
+
+func TestMarkAnnotations(t *testing.T) {
+	annotations := map[string]string{"team": "payments"}
+	config := &configs.Config{
+		Module: &configs.Module{
+			ManagedResources: map[string]*configs.Resource{
+				"test_instance.a": {Mode: addrs.ManagedResourceMode, Type: "test_instance", Name: "a", Annotations: annotations},
+				"test_instance.b": {Mode: addrs.ManagedResourceMode, Type: "test_instance", Name: "b"},
+			},
+		},
+	}
+	changes := []ResourceChange{
+		{Address: "test_instance.a[0]"},
+		{Address: "test_instance.b"},
+		{Address: "test_instance.gone"},
+	}
+	markAnnotations(changes, config)
+
+	for i, want := range []map[string]string{annotations, nil, nil} {
+		if diff := cmp.Diff(want, changes[i].Annotations); diff != "" {
+			t.Errorf("wrong annotations for %s\n%s", changes[i].Address, diff)
+		}
+	}
+}
+
+func TestMarshalPlanVariables(t *testing.T) {
+	dynVal := func(v cty.Value) plans.DynamicValue {
+		dv, err := plans.NewDynamicValue(v, cty.DynamicPseudoType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dv
+	}
+	vars := map[string]plans.DynamicValue{
+		"region": dynVal(cty.StringVal("eu-west-1")),
+		"token":  dynVal(cty.StringVal("s3cr3t")),
+	}
+	decls := map[string]*configs.Variable{
+		"region": {Name: "region"},
+		"token":  {Name: "token", Ephemeral: true},
+		"size":   {Name: "size", Default: cty.StringVal("small")},
+		"cert":   {Name: "cert", Default: cty.StringVal("pem"), Ephemeral: true},
+	}
+
+	p := newPlan()
+	if err := p.marshalPlanVariables(vars, decls); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Variables{
+		"region": {Value: json.RawMessage(`"eu-west-1"`)},
+		"size":   {Value: json.RawMessage(`"small"`)},
+	}
+	if diff := cmp.Diff(want, p.Variables); diff != "" {
+		t.Errorf("wrong variables\n%s", diff)
+	}
+}
+
+func TestMarkOutOfBandChanges(t *testing.T) {
+	changes := []ResourceChange{
+		{Address: "test_instance.a[0]"},
+		{Address: "test_instance.a[0]", Deposed: "00000001"},
+		{Address: "test_instance.b"},
+	}
+	err := markOutOfBandChanges(changes, map[string][]*plans.OutOfBandChange{
+		"test_instance.a[0]": {
+			{Attribute: "ami", Declared: cty.StringVal("ami-1"), Actual: cty.StringVal("ami-2")},
+			{Attribute: "password", Declared: cty.StringVal("old"), Actual: cty.StringVal("new"), Sensitive: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []OutOfBandChange{
+		{Attribute: "ami", Declared: json.RawMessage(`"ami-1"`), Actual: json.RawMessage(`"ami-2"`)},
+		{Attribute: "password", Sensitive: true},
+	}
+	if diff := cmp.Diff(want, changes[0].OutOfBandChanges); diff != "" {
+		t.Errorf("wrong out-of-band changes\n%s", diff)
+	}
+	for _, change := range changes[1:] {
+		if change.OutOfBandChanges != nil {
+			t.Errorf("unexpected out-of-band changes for %s %s: %#v", change.Address, change.Deposed, change.OutOfBandChanges)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonplan
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// RedactPolicy selects what Redact removes from a JSON plan. The zero value
+// redacts as much as possible while keeping the structure of the plan.
+type RedactPolicy struct {
+	// KeepAttributeValues keeps the values that aren't sensitive, such as
+	// the ones of resource attributes, outputs, variables and constants in
+	// the configuration, rather than replacing them with their hashes.
+	KeepAttributeValues bool `json:"keep_attribute_values"`
+
+	// KeepAttributes are the names of the top-level resource attributes
+	// whose values are kept even when KeepAttributeValues is false, such as
+	// "tags". Sensitive values are removed regardless.
+	KeepAttributes []string `json:"keep_attributes"`
+
+	// KeepProviderConfig keeps the provider configuration blocks in the
+	// "configuration" of the plan.
+	KeepProviderConfig bool `json:"keep_provider_config"`
+
+	// HashKey, if set, makes the hashes HMAC-SHA256 hashes with this key
+	// rather than SHA-256 hashes. Without a key, the values of few
+	// possibilities, such as booleans or small numbers, can be guessed from
+	// their hashes. Use the same key to compare redacted plans.
+	HashKey string `json:"hash_key"`
+}
+
+// Redact returns the given JSON plan with sensitive values removed, and the
+// other values handled according to the policy, so that it can be shared
+// outside of the organization.
+//
+// Sensitive values become null, leaving their "sensitive" markers in place.
+// Other values become strings of their hashes, so that changed values can
+// still be told apart from unchanged ones. The structure of the plan, such as
+// addresses, actions and the attributes of objects, is kept.
+func Redact(planJSON []byte, policy RedactPolicy) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(planJSON))
+	dec.UseNumber()
+	var plan map[string]any
+	if err := dec.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("invalid JSON plan: %w", err)
+	}
+
+	r := redactor{policy: policy}
+
+	sensitiveVars := map[string]bool{}
+	if config, ok := plan["configuration"].(map[string]any); ok {
+		if root, ok := config["root_module"].(map[string]any); ok {
+			if vars, ok := root["variables"].(map[string]any); ok {
+				for name, v := range vars {
+					if v, ok := v.(map[string]any); ok && v["sensitive"] == true {
+						sensitiveVars[name] = true
+					}
+				}
+			}
+		}
+		r.configuration(config)
+	}
+
+	if vars, ok := plan["variables"].(map[string]any); ok {
+		for name, v := range vars {
+			if v, ok := v.(map[string]any); ok {
+				v["value"] = r.value(v["value"], sensitiveVars[name], false)
+			}
+		}
+	}
+	if values, ok := plan["planned_values"].(map[string]any); ok {
+		r.stateValues(values)
+	}
+	if prior, ok := plan["prior_state"].(map[string]any); ok {
+		if values, ok := prior["values"].(map[string]any); ok {
+			r.stateValues(values)
+		}
+	}
+	for _, key := range []string{"resource_changes", "resource_drift"} {
+		changes, _ := plan[key].([]any)
+		for _, rc := range changes {
+			if rc, ok := rc.(map[string]any); ok {
+				if change, ok := rc["change"].(map[string]any); ok {
+					r.change(change, true)
+				}
+			}
+		}
+	}
+	if changes, ok := plan["output_changes"].(map[string]any); ok {
+		for _, change := range changes {
+			if change, ok := change.(map[string]any); ok {
+				r.change(change, false)
+			}
+		}
+	}
+	if checks, ok := plan["checks"].([]any); ok {
+		r.checks(checks)
+	}
+
+	return json.Marshal(plan)
+}
+
+type redactor struct {
+	policy RedactPolicy
+}
+
+// value redacts a value, given the value of the same structure that marks
+// its sensitive parts with true. resource selects the KeepAttributes of the
+// policy for the top-level attributes of resource objects.
+func (r redactor) value(v, sensitive any, resource bool) any {
+	if sensitive == true {
+		return nil
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		sensitive, _ := sensitive.(map[string]any)
+		for k, elem := range v {
+			if resource && slices.Contains(r.policy.KeepAttributes, k) {
+				v[k] = r.keep(elem, sensitive[k])
+				continue
+			}
+			v[k] = r.value(elem, sensitive[k], false)
+		}
+		return v
+	case []any:
+		sensitive, _ := sensitive.([]any)
+		for i, elem := range v {
+			var s any
+			if i < len(sensitive) {
+				s = sensitive[i]
+			}
+			v[i] = r.value(elem, s, false)
+		}
+		return v
+	case nil:
+		return nil
+	default:
+		if r.policy.KeepAttributeValues {
+			return v
+		}
+		return r.hash(v)
+	}
+}
+
+// keep removes the sensitive parts of a value, keeping the rest.
+func (r redactor) keep(v, sensitive any) any {
+	keep := r
+	keep.policy.KeepAttributeValues = true
+	return keep.value(v, sensitive, false)
+}
+
+func (r redactor) hash(v any) string {
+	// The JSON encoding tells apart values of different types, such as "1"
+	// and 1.
+	raw, _ := json.Marshal(v)
+	if r.policy.HashKey != "" {
+		mac := hmac.New(sha256.New, []byte(r.policy.HashKey))
+		mac.Write(raw)
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+	}
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// stateValues redacts the "values" of a state, as found in the planned
+// values and prior state of a plan.
+func (r redactor) stateValues(values map[string]any) {
+	if outputs, ok := values["outputs"].(map[string]any); ok {
+		for _, output := range outputs {
+			if output, ok := output.(map[string]any); ok {
+				output["value"] = r.value(output["value"], output["sensitive"], false)
+			}
+		}
+	}
+	if root, ok := values["root_module"].(map[string]any); ok {
+		r.module(root)
+	}
+}
+
+func (r redactor) module(module map[string]any) {
+	resources, _ := module["resources"].([]any)
+	for _, resource := range resources {
+		if resource, ok := resource.(map[string]any); ok {
+			resource["values"] = r.value(resource["values"], resource["sensitive_values"], true)
+		}
+	}
+	children, _ := module["child_modules"].([]any)
+	for _, child := range children {
+		if child, ok := child.(map[string]any); ok {
+			r.module(child)
+		}
+	}
+}
+
+func (r redactor) change(change map[string]any, resource bool) {
+	for _, key := range []string{"before", "after"} {
+		if v, ok := change[key]; ok {
+			change[key] = r.value(v, change[key+"_sensitive"], resource)
+		}
+	}
+	if importing, ok := change["importing"].(map[string]any); ok {
+		if id, ok := importing["id"]; ok {
+			importing["id"] = r.value(id, nil, false)
+		}
+	}
+	// The generated configuration is made of the values of the object.
+	delete(change, "generated_config")
+}
+
+// configuration redacts the constant values in the expressions of the
+// configuration, and the default values of variables.
+func (r redactor) configuration(config map[string]any) {
+	if !r.policy.KeepProviderConfig {
+		delete(config, "provider_config")
+	}
+	if root, ok := config["root_module"].(map[string]any); ok {
+		r.configModule(root)
+	}
+}
+
+func (r redactor) configModule(module map[string]any) {
+	for k, v := range module {
+		switch k {
+		case "variables":
+			vars, _ := v.(map[string]any)
+			for _, v := range vars {
+				if v, ok := v.(map[string]any); ok {
+					if def, ok := v["default"]; ok {
+						v["default"] = r.value(def, v["sensitive"], false)
+					}
+				}
+			}
+		case "module_calls":
+			calls, _ := v.(map[string]any)
+			for _, call := range calls {
+				call, _ := call.(map[string]any)
+				for k, v := range call {
+					if child, ok := v.(map[string]any); ok && k == "module" {
+						r.configModule(child)
+						continue
+					}
+					r.constantValues(v)
+				}
+			}
+		default:
+			r.constantValues(v)
+		}
+	}
+}
+
+// constantValues redacts the "constant_value" of every expression in the
+// given part of the configuration.
+func (r redactor) constantValues(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, elem := range v {
+			if k == "constant_value" {
+				v[k] = r.value(elem, nil, false)
+				continue
+			}
+			r.constantValues(elem)
+		}
+	case []any:
+		for _, elem := range v {
+			r.constantValues(elem)
+		}
+	}
+}
+
+// checks redacts the messages of the problems found by checks, which can
+// include values.
+func (r redactor) checks(checks []any) {
+	for _, check := range checks {
+		check, _ := check.(map[string]any)
+		instances, _ := check["instances"].([]any)
+		for _, instance := range instances {
+			instance, _ := instance.(map[string]any)
+			problems, _ := instance["problems"].([]any)
+			for _, problem := range problems {
+				if problem, ok := problem.(map[string]any); ok {
+					problem["message"] = r.value(problem["message"], nil, false)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonplan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedact(t *testing.T) {
+	plan := `{
+		"format_version": "1.3",
+		"variables": {
+			"region": {"value": "eu-west-1"},
+			"password": {"value": "hunter2"}
+		},
+		"resource_changes": [
+			{
+				"address": "test_instance.foo",
+				"change": {
+					"actions": ["update"],
+					"before": {"ami": "ami-1", "password": "old", "tags": {"team": "a"}, "ports": [80, 443]},
+					"after": {"ami": "ami-2", "password": "new", "tags": {"team": "b"}, "ports": [80, 443]},
+					"before_sensitive": {"password": true},
+					"after_sensitive": {"password": true},
+					"importing": {"id": "i-123"},
+					"generated_config": "resource \"test_instance\" \"foo\" {}"
+				}
+			}
+		],
+		"output_changes": {
+			"secret": {"actions": ["create"], "after": "s3cr3t", "after_sensitive": true}
+		},
+		"configuration": {
+			"provider_config": {"test": {"name": "test", "expressions": {"token": {"constant_value": "abc"}}}},
+			"root_module": {
+				"resources": [{"address": "test_instance.foo", "expressions": {"ami": {"constant_value": "ami-2"}}}],
+				"variables": {
+					"region": {"default": "eu-west-1"},
+					"password": {"default": "hunter2", "sensitive": true}
+				}
+			}
+		}
+	}`
+
+	got, err := Redact([]byte(plan), RedactPolicy{KeepAttributes: []string{"tags"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := func(v any) string {
+		raw, _ := json.Marshal(v)
+		sum := sha256.Sum256(raw)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	want := map[string]any{
+		"format_version": "1.3",
+		"variables": map[string]any{
+			"region":   map[string]any{"value": h("eu-west-1")},
+			"password": map[string]any{"value": nil},
+		},
+		"resource_changes": []any{
+			map[string]any{
+				"address": "test_instance.foo",
+				"change": map[string]any{
+					"actions":          []any{"update"},
+					"before":           map[string]any{"ami": h("ami-1"), "password": nil, "tags": map[string]any{"team": "a"}, "ports": []any{h(80), h(443)}},
+					"after":            map[string]any{"ami": h("ami-2"), "password": nil, "tags": map[string]any{"team": "b"}, "ports": []any{h(80), h(443)}},
+					"before_sensitive": map[string]any{"password": true},
+					"after_sensitive":  map[string]any{"password": true},
+					"importing":        map[string]any{"id": h("i-123")},
+				},
+			},
+		},
+		"output_changes": map[string]any{
+			"secret": map[string]any{"actions": []any{"create"}, "after": nil, "after_sensitive": true},
+		},
+		"configuration": map[string]any{
+			"root_module": map[string]any{
+				"resources": []any{map[string]any{"address": "test_instance.foo", "expressions": map[string]any{"ami": map[string]any{"constant_value": h("ami-2")}}}},
+				"variables": map[string]any{
+					"region":   map[string]any{"default": h("eu-west-1")},
+					"password": map[string]any{"default": nil, "sensitive": true},
+				},
+			},
+		},
+	}
+
+	var gotValue map[string]any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, gotValue); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestRedact_hashKey(t *testing.T) {
+	plan := []byte(`{"variables": {"region": {"value": "eu-west-1"}}}`)
+
+	a, err := Redact(plan, RedactPolicy{HashKey: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := Redact(plan, RedactPolicy{HashKey: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Redact(plan, RedactPolicy{HashKey: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a) != string(a2) {
+		t.Errorf("the same key gave different hashes:\n%s\n%s", a, a2)
+	}
+	if string(a) == string(b) {
+		t.Errorf("different keys gave the same hashes:\n%s", a)
+	}
+}
+
+func TestRedact_keep(t *testing.T) {
+	plan := `{"configuration": {"provider_config": {"test": {"name": "test"}}}, "output_changes": {"name": {"after": "foo"}}}`
+	got, err := Redact([]byte(plan), RedactPolicy{KeepAttributeValues: true, KeepProviderConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"configuration":{"provider_config":{"test":{"name":"test"}}},"output_changes":{"name":{"after":"foo"}}}`
+	if string(got) != want {
+		t.Errorf("wrong result\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -96,4 +96,49 @@ type ResourceChange struct {
 	// information should be resilient to encountering unrecognized values
 	// and treat them as an unspecified reason.
 	ActionReason string `json:"action_reason,omitempty"`
+
+	// ReplaceReason is the reason given with the -replace option for
+	// replacing this resource instance, if its ActionReason is
+	// "replace_by_request".
+	ReplaceReason string `json:"replace_reason,omitempty"`
+
+	// Commit is the commit that last changed the configuration of this
+	// resource, in plans created in Farseek stateless mode.
+	Commit *Commit `json:"commit,omitempty"`
+
+	// OutOfBandChanges are the attributes of this resource instance whose
+	// remote values differ from the values that its configuration declared at
+	// the base SHA, in plans created in Farseek stateless mode.
+	OutOfBandChanges []OutOfBandChange `json:"out_of_band_changes,omitempty"`
+
+	// Annotations are those that the resource declares in its
+	// farseek_annotations argument, such as the team that owns it.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Commit describes a commit in the version control history of the
+// configuration.
+type Commit struct {
+	SHA    string `json:"sha"`
+	Author string `json:"author,omitempty"`
+
+	// Timestamp is the time of the commit, in RFC3339 format.
+	Timestamp string `json:"timestamp,omitempty"`
+
+	// Message is the first line of the commit message.
+	Message string `json:"message,omitempty"`
+}
+
+// OutOfBandChange describes an attribute of a resource instance that was
+// changed outside of version control since the base SHA.
+type OutOfBandChange struct {
+	Attribute string `json:"attribute"`
+
+	// Declared is the value that the configuration declared at the base SHA,
+	// and Actual the value of the remote object. Both are omitted if the
+	// attribute is sensitive.
+	Declared json.RawMessage `json:"declared,omitempty"`
+	Actual   json.RawMessage `json:"actual,omitempty"`
+
+	Sensitive bool `json:"sensitive,omitempty"`
 }
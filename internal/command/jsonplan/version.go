@@ -0,0 +1,118 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+)
+
+// SupportedFormatVersions are the versions of the JSON plan format that
+// MarshalVersion can produce, oldest first. The last one is always
+// FormatVersion.
+//
+// Each older version is produced by removing from the current version what
+// was added since, so that tools written against it keep working. Every
+// change to the format must bump FormatVersion, and teach downgrade how to
+// undo it for the versions before.
+var SupportedFormatVersions = []string{
+	// 1.2 is the format inherited from OpenTofu, without any of the
+	// additions for Farseek.
+	"1.2",
+
+	// 1.3 adds the "commit" of resource changes, and the
+	// "delete_because_removed_from_vcs" action reason.
+	"1.3",
+
+	// 1.4 adds the "out_of_band_changes" of resource changes.
+	"1.4",
+
+	// 1.5 adds the "annotations" of resource changes.
+	"1.5",
+
+	// 1.6 adds the "replace_reason" of resource changes.
+	"1.6",
+}
+
+// UnsupportedFormatVersionError is returned by MarshalVersion when asked for
+// a version that isn't one of SupportedFormatVersions.
+type UnsupportedFormatVersionError struct {
+	Version string
+}
+
+func (e UnsupportedFormatVersionError) Error() string {
+	return fmt.Sprintf("unsupported plan format version %q; supported versions are %s", e.Version, strings.Join(SupportedFormatVersions, ", "))
+}
+
+// MarshalVersion returns the json encoding of a farseek plan in the given
+// version of the format, which must be one of SupportedFormatVersions. An
+// empty version selects FormatVersion.
+func MarshalVersion(
+	formatVersion string,
+	config *configs.Config,
+	p *plans.Plan,
+	sf *statefile.File,
+	schemas *farseek.Schemas,
+) ([]byte, error) {
+	if formatVersion == "" {
+		formatVersion = FormatVersion
+	}
+	if !slices.Contains(SupportedFormatVersions, formatVersion) {
+		return nil, UnsupportedFormatVersionError{Version: formatVersion}
+	}
+
+	output, err := MarshalForLog(config, p, sf, schemas)
+	if err != nil {
+		return nil, err
+	}
+	output.downgrade(formatVersion)
+
+	return json.Marshal(output)
+}
+
+// downgrade removes from the plan what was added to the format after the
+// given version, which must be one of SupportedFormatVersions.
+func (p *Plan) downgrade(formatVersion string) {
+	if formatVersion == FormatVersion {
+		return
+	}
+	p.FormatVersion = formatVersion
+
+	for _, changes := range [][]ResourceChange{p.ResourceChanges, p.ResourceDrift} {
+		for i := range changes {
+			changes[i].downgrade(formatVersion)
+		}
+	}
+}
+
+func (c *ResourceChange) downgrade(formatVersion string) {
+	if formatVersionBefore(formatVersion, "1.6") {
+		c.ReplaceReason = ""
+	}
+	if formatVersionBefore(formatVersion, "1.5") {
+		c.Annotations = nil
+	}
+	if formatVersionBefore(formatVersion, "1.4") {
+		c.OutOfBandChanges = nil
+	}
+	if formatVersionBefore(formatVersion, "1.3") {
+		c.Commit = nil
+		if c.ActionReason == ResourceInstanceDeleteBecauseRemovedFromVCS {
+			c.ActionReason = ResourceInstanceDeleteBecauseNoResourceConfig
+		}
+	}
+}
+
+// formatVersionBefore returns true if the given version of the format came
+// before the other one. Both must be SupportedFormatVersions.
+func formatVersionBefore(version, other string) bool {
+	return slices.Index(SupportedFormatVersions, version) < slices.Index(SupportedFormatVersions, other)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonplan
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanDowngrade(t *testing.T) {
+	newPlan := func() *Plan {
+		return &Plan{
+			FormatVersion: FormatVersion,
+			ResourceChanges: []ResourceChange{
+				{
+					Address:      "test_instance.removed",
+					ActionReason: ResourceInstanceDeleteBecauseRemovedFromVCS,
+					Commit:       &Commit{SHA: "abc123"},
+				},
+				{
+					Address:          "test_instance.edited",
+					OutOfBandChanges: []OutOfBandChange{{Attribute: "ami", Declared: []byte(`"ami-1"`), Actual: []byte(`"ami-2"`)}},
+				},
+				{
+					Address:      "test_instance.moved",
+					ActionReason: ResourceInstanceDeleteBecauseNoMoveTarget,
+				},
+				{
+					Address:     "test_instance.annotated",
+					Annotations: map[string]string{"team": "payments"},
+				},
+				{
+					Address:       "test_instance.replaced",
+					ActionReason:  ResourceInstanceReplaceByRequest,
+					ReplaceReason: "kernel upgrade",
+				},
+			},
+			ResourceDrift: []ResourceChange{
+				{
+					Address: "test_instance.drifted",
+					Commit:  &Commit{SHA: "abc123"},
+				},
+			},
+		}
+	}
+
+	t.Run("current", func(t *testing.T) {
+		got := newPlan()
+		got.downgrade(FormatVersion)
+		if diff := cmp.Diff(newPlan(), got); diff != "" {
+			t.Errorf("current version was changed\n%s", diff)
+		}
+	})
+
+	t.Run("1.5", func(t *testing.T) {
+		got := newPlan()
+		got.downgrade("1.5")
+		want := newPlan()
+		want.FormatVersion = "1.5"
+		want.ResourceChanges[4].ReplaceReason = ""
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+
+	t.Run("1.4", func(t *testing.T) {
+		got := newPlan()
+		got.downgrade("1.4")
+		want := newPlan()
+		want.FormatVersion = "1.4"
+		want.ResourceChanges[3].Annotations = nil
+		want.ResourceChanges[4].ReplaceReason = ""
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+
+	t.Run("1.3", func(t *testing.T) {
+		got := newPlan()
+		got.downgrade("1.3")
+		want := newPlan()
+		want.FormatVersion = "1.3"
+		want.ResourceChanges[1].OutOfBandChanges = nil
+		want.ResourceChanges[3].Annotations = nil
+		want.ResourceChanges[4].ReplaceReason = ""
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+
+	t.Run("1.2", func(t *testing.T) {
+		got := newPlan()
+		got.downgrade("1.2")
+		want := &Plan{
+			FormatVersion: "1.2",
+			ResourceChanges: []ResourceChange{
+				{
+					Address:      "test_instance.removed",
+					ActionReason: ResourceInstanceDeleteBecauseNoResourceConfig,
+				},
+				{
+					Address: "test_instance.edited",
+				},
+				{
+					Address:      "test_instance.moved",
+					ActionReason: ResourceInstanceDeleteBecauseNoMoveTarget,
+				},
+				{
+					Address: "test_instance.annotated",
+				},
+				{
+					Address:      "test_instance.replaced",
+					ActionReason: ResourceInstanceReplaceByRequest,
+				},
+			},
+			ResourceDrift: []ResourceChange{
+				{
+					Address: "test_instance.drifted",
+				},
+			},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+}
+
+func TestMarshalVersion_unsupported(t *testing.T) {
+	_, err := MarshalVersion("1.1", nil, nil, nil, nil)
+	if _, ok := err.(UnsupportedFormatVersionError); !ok {
+		t.Fatalf("wrong error %#v", err)
+	}
+}
+
+func TestSupportedFormatVersions(t *testing.T) {
+	if got := SupportedFormatVersions[len(SupportedFormatVersions)-1]; got != FormatVersion {
+		t.Errorf("latest supported version is %s; want FormatVersion, %s", got, FormatVersion)
+	}
+}
+
+// TestFormatVersionFields guards the JSON plan format against changes that
+// don't bump FormatVersion. If it fails because you changed the format,
+// bump FormatVersion, add the new version to SupportedFormatVersions, teach
+// Plan.downgrade to undo your change for the older versions, and then update
+// the fields here.
+func TestFormatVersionFields(t *testing.T) {
+	want := map[string]map[string][]string{
+		"1.6": {
+			"Plan": {
+				"checks", "configuration", "errored", "format_version",
+				"output_changes", "planned_values", "prior_state",
+				"relevant_attributes", "resource_changes", "resource_drift",
+				"terraform_version", "timestamp", "variables",
+			},
+			"ResourceChange": {
+				"action_reason", "address", "annotations", "change", "commit",
+				"deposed", "index", "mode", "module_address", "name",
+				"out_of_band_changes", "previous_address", "provider_name",
+				"replace_reason", "type",
+			},
+		},
+	}
+
+	got := map[string][]string{
+		"Plan":           jsonFieldNames(reflect.TypeOf(Plan{})),
+		"ResourceChange": jsonFieldNames(reflect.TypeOf(ResourceChange{})),
+	}
+	if diff := cmp.Diff(want[FormatVersion], got); diff != "" {
+		t.Errorf("the fields of format version %s changed\n%s", FormatVersion, diff)
+	}
+}
+
+func jsonFieldNames(ty reflect.Type) []string {
+	var names []string
+	for i := 0; i < ty.NumField(); i++ {
+		name, _, _ := strings.Cut(ty.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
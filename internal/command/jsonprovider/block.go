@@ -17,6 +17,7 @@ type Block struct {
 	Description     string                `json:"description,omitempty"`
 	DescriptionKind string                `json:"description_kind,omitempty"`
 	Deprecated      bool                  `json:"deprecated,omitempty"`
+	Ephemeral       bool                  `json:"ephemeral,omitempty"`
 }
 
 type BlockType struct {
@@ -46,6 +47,7 @@ func marshalBlock(configBlock *configschema.Block) *Block {
 
 	ret := Block{
 		Deprecated:      configBlock.Deprecated,
+		Ephemeral:       configBlock.Ephemeral,
 		Description:     configBlock.Description,
 		DescriptionKind: marshalStringKind(configBlock.DescriptionKind),
 	}
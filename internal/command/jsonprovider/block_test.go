@@ -64,6 +64,39 @@ func TestMarshalBlock(t *testing.T) {
 				DescriptionKind: "plain",
 			},
 		},
+		{
+			Input: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"password": {Type: cty.String, Optional: true, WriteOnly: true},
+					"legacy":   {Type: cty.String, Optional: true, Deprecated: true},
+				},
+				BlockTypes: map[string]*configschema.NestedBlock{
+					"rule": {
+						Nesting:  configschema.NestingList,
+						Block:    configschema.Block{Deprecated: true},
+						MinItems: 1,
+						MaxItems: 3,
+					},
+				},
+				Ephemeral: true,
+			},
+			Want: &Block{
+				Attributes: map[string]*Attribute{
+					"password": {AttributeType: json.RawMessage(`"string"`), Optional: true, WriteOnly: true, DescriptionKind: "plain"},
+					"legacy":   {AttributeType: json.RawMessage(`"string"`), Optional: true, Deprecated: true, DescriptionKind: "plain"},
+				},
+				BlockTypes: map[string]*BlockType{
+					"rule": {
+						NestingMode: "list",
+						Block:       &Block{Deprecated: true, DescriptionKind: "plain"},
+						MinItems:    1,
+						MaxItems:    3,
+					},
+				},
+				DescriptionKind: "plain",
+				Ephemeral:       true,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -21,11 +21,12 @@ const (
 
 // Function is the top-level object returned when exporting function schemas
 type Function struct {
-	Description       string           `json:"description"`
-	Summary           string           `json:"summary"`
-	ReturnType        any              `json:"return_type"`
-	Parameters        []*FunctionParam `json:"parameters,omitempty"`
-	VariadicParameter *FunctionParam   `json:"variadic_parameter,omitempty"`
+	Description        string           `json:"description"`
+	Summary            string           `json:"summary"`
+	DeprecationMessage string           `json:"deprecation_message,omitempty"`
+	ReturnType         any              `json:"return_type"`
+	Parameters         []*FunctionParam `json:"parameters,omitempty"`
+	VariadicParameter  *FunctionParam   `json:"variadic_parameter,omitempty"`
 }
 
 // FunctionParam is the object for wrapping the functions parameters and return types
@@ -95,6 +96,7 @@ func marshalFunction(function providers.FunctionSpec) *Function {
 	var output Function
 	output.Description = function.Description
 	output.Summary = function.Summary
+	output.DeprecationMessage = function.DeprecationMessage
 	output.ReturnType = marshalReturnType(function.Return)
 	output.Parameters = marshalParameters(function.Parameters)
 	if function.VariadicParameter != nil {
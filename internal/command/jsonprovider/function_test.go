@@ -184,6 +184,18 @@ func TestMarshalFunction(t *testing.T) {
 				ReturnType:  cty.String,
 			},
 		},
+		"deprecated": {
+			Arg: providers.FunctionSpec{
+				Description:        "old string func",
+				Return:             cty.String,
+				DeprecationMessage: "Use new_func instead.",
+			},
+			Expected: Function{
+				Description:        "old string func",
+				ReturnType:         cty.String,
+				DeprecationMessage: "Use new_func instead.",
+			},
+		},
 		"variadic": {
 			Arg: providers.FunctionSpec{
 				Description: "basic string func",
@@ -34,6 +34,11 @@ const (
 	EphemeralResourceMode = "ephemeral"
 )
 
+// SupportedFormatVersions are the versions of the JSON state format that
+// Marshal can produce. Farseek hasn't changed the format since it was
+// inherited from OpenTofu, so there is only the one.
+var SupportedFormatVersions = []string{FormatVersion}
+
 // State is the top-level representation of the json format of a farseek
 // state.
 type State struct {
@@ -0,0 +1,453 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonentities"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/lang"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// LintCommand is a Command implementation that checks a whole configuration
+// for problems that aren't errors, such as declarations that nothing uses.
+type LintCommand struct {
+	Meta
+}
+
+// lintFinding is a single problem found by the lint command. Autofixable
+// findings are those that can be fixed by removing the declaration that the
+// finding points at, without changing the behavior of the configuration.
+type lintFinding struct {
+	diag        tfdiags.Diagnostic
+	autofixable bool
+}
+
+func (c *LintCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("lint")
+	var jsonOutput bool
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		c.Ui.Error("The lint command expects at most one argument.\n")
+		return cli.RunResultHelp
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+	config, configDiags := c.loadConfig(ctx, dir)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We need the provider schemas to know which resource types are
+	// deprecated, so like validate, lint needs an initialized directory.
+	opts, err := c.contextOpts(ctx)
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	tfCtx, ctxDiags := farseek.NewContext(opts)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	schemas, schemaDiags := tfCtx.Schemas(ctx, config, nil)
+	diags = diags.Append(schemaDiags)
+	if schemaDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	var findings []lintFinding
+	for _, finding := range lintConfig(config, schemas, c.configSources()) {
+		if c.View != nil && len(c.View.FilterSuppressedWarnings(tfdiags.Diagnostics{finding.diag})) == 0 {
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	if jsonOutput {
+		return c.showJSONFindings(findings)
+	}
+
+	for _, finding := range findings {
+		diags = diags.Append(finding.diag)
+	}
+	c.showDiagnostics(diags)
+	if len(findings) > 0 {
+		return 2
+	}
+	c.Ui.Output(c.Colorize().Color("[green][bold]No problems found.[reset]"))
+	return 0
+}
+
+func (c *LintCommand) showJSONFindings(findings []lintFinding) int {
+	type jsonFinding struct {
+		*jsonentities.Diagnostic
+		Autofixable bool `json:"autofixable"`
+	}
+	type jsonOutput struct {
+		FormatVersion string        `json:"format_version"`
+		Findings      []jsonFinding `json:"findings"`
+	}
+
+	sources := c.configSources()
+	out := jsonOutput{
+		FormatVersion: "1.0",
+		Findings:      []jsonFinding{},
+	}
+	for _, finding := range findings {
+		out.Findings = append(out.Findings, jsonFinding{
+			Diagnostic:  jsonentities.NewDiagnostic(finding.diag, sources),
+			Autofixable: finding.autofixable,
+		})
+	}
+	j, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		// Should never happen because we fully-control the input here
+		panic(err)
+	}
+	c.Ui.Output(string(j))
+	if len(findings) > 0 {
+		return 2
+	}
+	return 0
+}
+
+// lintConfig returns the findings for the root module of the given
+// configuration and for the local modules that it calls. Modules installed
+// from elsewhere are left out, because their problems are for their authors
+// to fix.
+func lintConfig(config *configs.Config, schemas *farseek.Schemas, sources map[string]*hcl.File) []lintFinding {
+	var findings []lintFinding
+	requirements := make(map[addrs.Provider][]*configs.RequiredProvider)
+
+	var walk func(cfg *configs.Config)
+	walk = func(cfg *configs.Config) {
+		findings = append(findings, lintModule(cfg, schemas, sources)...)
+		if reqs := cfg.Module.ProviderRequirements; reqs != nil {
+			for _, req := range reqs.RequiredProviders {
+				requirements[req.Type] = append(requirements[req.Type], req)
+			}
+		}
+		for _, name := range sortedKeys(cfg.Children) {
+			child := cfg.Children[name]
+			if _, ok := child.SourceAddr.(addrs.ModuleSourceLocal); ok {
+				walk(child)
+			}
+		}
+	}
+	walk(config)
+
+	for _, provider := range sortedProviders(requirements) {
+		findings = append(findings, lintRequirements(provider, requirements[provider])...)
+	}
+	return findings
+}
+
+// lintModule returns the findings for a single module.
+func lintModule(cfg *configs.Config, schemas *farseek.Schemas, sources map[string]*hcl.File) []lintFinding {
+	var findings []lintFinding
+	mod := cfg.Module
+
+	// We can only find the references in native syntax files, so we don't
+	// report unused declarations in modules that have JSON files.
+	if refs, ok := moduleReferences(mod, sources); ok {
+		for _, name := range sortedKeys(mod.Variables) {
+			if refs.variables[name] {
+				continue
+			}
+			v := mod.Variables[name]
+			findings = append(findings, lintFinding{
+				diag:        lintWarning(tfdiags.CodeUnusedVariable, "Unused variable", fmt.Sprintf("The variable %q is declared but never used in this module.", name), v.DeclRange),
+				autofixable: true,
+			})
+		}
+		for _, name := range sortedKeys(mod.Locals) {
+			if refs.locals[name] {
+				continue
+			}
+			l := mod.Locals[name]
+			findings = append(findings, lintFinding{
+				diag:        lintWarning(tfdiags.CodeUnusedLocal, "Unused local value", fmt.Sprintf("The local value %q is declared but never used in this module.", name), l.DeclRange),
+				autofixable: true,
+			})
+		}
+		if reqs := mod.ProviderRequirements; reqs != nil {
+			used := usedProviders(cfg, refs.providerFunctions)
+			for _, name := range sortedKeys(reqs.RequiredProviders) {
+				req := reqs.RequiredProviders[name]
+				if used[req.Type] || req.Type.IsBuiltIn() {
+					continue
+				}
+				findings = append(findings, lintFinding{
+					diag:        lintWarning(tfdiags.CodeUnusedProviderRequirement, "Unused provider requirement", fmt.Sprintf("The provider %s is required, but nothing in this module or the modules it calls uses it.", req.Type.ForDisplay()), req.DeclRange),
+					autofixable: true,
+				})
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(mod.Outputs) {
+		output := mod.Outputs[name]
+		refs, _ := lang.ReferencesInExpr(addrs.ParseRef, output.Expr)
+		for _, ref := range refs {
+			var addr addrs.Resource
+			switch subject := ref.Subject.(type) {
+			case addrs.Resource:
+				addr = subject
+			case addrs.ResourceInstance:
+				addr = subject.Resource
+			default:
+				continue
+			}
+			r := mod.ResourceByAddr(addr)
+			if r == nil {
+				continue
+			}
+			schema, _ := schemas.ResourceTypeConfig(r.Provider, addr.Mode, addr.Type)
+			if schema == nil || !schema.Deprecated {
+				continue
+			}
+			findings = append(findings, lintFinding{
+				diag: lintWarning(tfdiags.CodeDeprecatedResourceOutput, "Output refers to a deprecated resource type", fmt.Sprintf("The output %q refers to %s, but the %s provider has deprecated the %s resource type.", name, addr, r.Provider.ForDisplay(), addr.Type), ref.SourceRange.ToHCL()),
+			})
+		}
+	}
+
+	return findings
+}
+
+// lintRequirements returns the findings for the requirements for a provider
+// that several modules declare. Modules are free to require the same
+// provider, but requirements that disagree with each other make it harder
+// to see which version of the provider Farseek will choose.
+func lintRequirements(provider addrs.Provider, reqs []*configs.RequiredProvider) []lintFinding {
+	if len(reqs) < 2 {
+		return nil
+	}
+	sort.Slice(reqs, func(i, j int) bool {
+		return reqs[i].DeclRange.String() < reqs[j].DeclRange.String()
+	})
+	first := reqs[0]
+	var findings []lintFinding
+	for _, req := range reqs[1:] {
+		switch {
+		case req.Name != first.Name:
+			findings = append(findings, lintFinding{
+				diag: lintWarning(tfdiags.CodeDuplicatedProviderRequirement, "Duplicated provider requirement", fmt.Sprintf("The provider %s is required with the local name %q here, but with the local name %q at %s. Using the same name in every module makes the configuration easier to follow.", provider.ForDisplay(), req.Name, first.Name, first.DeclRange), req.DeclRange),
+			})
+		case req.Requirement.Required.String() != first.Requirement.Required.String():
+			findings = append(findings, lintFinding{
+				diag: lintWarning(tfdiags.CodeDuplicatedProviderRequirement, "Duplicated provider requirement", fmt.Sprintf("The provider %s is required with the version constraint %q here, but with %q at %s. Farseek chooses a version that meets all of them.", provider.ForDisplay(), req.Requirement.Required, first.Requirement.Required, first.DeclRange), req.DeclRange),
+			})
+		}
+	}
+	return findings
+}
+
+// lintReferences are the names that a module refers to.
+type lintReferences struct {
+	variables         map[string]bool
+	locals            map[string]bool
+	providerFunctions map[string]bool
+}
+
+// moduleReferences returns the input variables, local values, and provider
+// functions, by provider local name, that the given module refers to. It
+// returns false if the module has files that aren't in the native syntax.
+func moduleReferences(mod *configs.Module, sources map[string]*hcl.File) (lintReferences, bool) {
+	refs := lintReferences{
+		variables:         make(map[string]bool),
+		locals:            make(map[string]bool),
+		providerFunctions: make(map[string]bool),
+	}
+	visit := func(node hclsyntax.Node) hcl.Diagnostics {
+		switch node := node.(type) {
+		case *hclsyntax.ScopeTraversalExpr:
+			if len(node.Traversal) < 2 {
+				return nil
+			}
+			attr, ok := node.Traversal[1].(hcl.TraverseAttr)
+			if !ok {
+				return nil
+			}
+			switch node.Traversal.RootName() {
+			case "var":
+				refs.variables[attr.Name] = true
+			case "local":
+				refs.locals[attr.Name] = true
+			}
+		case *hclsyntax.FunctionCallExpr:
+			if parts := strings.Split(node.Name, "::"); len(parts) == 3 && parts[0] == "provider" {
+				refs.providerFunctions[parts[1]] = true
+			}
+		}
+		return nil
+	}
+
+	dir := filepath.Clean(mod.SourceDir)
+	for filename, file := range sources {
+		if filepath.Dir(filename) != dir {
+			continue
+		}
+		if strings.HasSuffix(filename, ".tftest.hcl") || strings.HasSuffix(filename, ".tofutest.hcl") {
+			continue
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			return refs, false
+		}
+		for _, block := range body.Blocks {
+			// A variable's validation rules refer to the variable itself,
+			// which doesn't count as using it.
+			if block.Type == "variable" {
+				continue
+			}
+			hclsyntax.VisitAll(block.Body, visit)
+		}
+	}
+	return refs, true
+}
+
+// usedProviders returns the providers that the given module, or any of the
+// modules it calls, uses for a resource, a provider configuration, or a
+// provider function.
+func usedProviders(cfg *configs.Config, providerFunctions map[string]bool) map[addrs.Provider]bool {
+	used := make(map[addrs.Provider]bool)
+	mod := cfg.Module
+	for _, resources := range []map[string]*configs.Resource{mod.ManagedResources, mod.DataResources, mod.EphemeralResources} {
+		for _, r := range resources {
+			used[r.Provider] = true
+		}
+	}
+	for _, check := range mod.Checks {
+		if check.DataResource != nil {
+			used[check.DataResource.Provider] = true
+		}
+	}
+	for _, i := range mod.Import {
+		used[i.Provider] = true
+	}
+	for _, p := range mod.ProviderConfigs {
+		used[mod.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: p.Name})] = true
+	}
+	for _, call := range mod.ModuleCalls {
+		for _, passed := range call.Providers {
+			used[mod.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: passed.InParent.Name})] = true
+		}
+	}
+	for name := range providerFunctions {
+		used[mod.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: name})] = true
+	}
+	for _, child := range cfg.Children {
+		for provider := range usedProviders(child, nil) {
+			used[provider] = true
+		}
+	}
+	return used
+}
+
+// lintWarning returns a warning diagnostic with the given code about the
+// configuration in the given range.
+func lintWarning(code tfdiags.Code, summary, detail string, subject hcl.Range) tfdiags.Diagnostic {
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  subject.Ptr(),
+	})
+	return tfdiags.WithCode(diags[0], code)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedProviders[V any](m map[addrs.Provider]V) []addrs.Provider {
+	keys := make([]addrs.Provider, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].LessThan(keys[j])
+	})
+	return keys
+}
+
+func (c *LintCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] lint [options] [DIR]
+
+  Checks the configuration in the given directory, or in the current
+  directory, and in the local modules that it calls, for problems that
+  aren't errors:
+
+    - variables and local values that are declared but never used
+    - outputs that refer to resource types their provider has deprecated
+    - providers that are required but never used
+    - providers that modules require under different local names or with
+      different version constraints
+
+  Like validate, lint needs an initialized working directory. It exits
+  with status 0 if it finds no problems, 1 if there is an error, and 2 if
+  it finds problems.
+
+Options:
+
+  -json                  Produce output in a machine-readable JSON format,
+                         which also says which problems can be fixed
+                         automatically.
+
+  -no-color              If specified, output won't contain any color.
+
+  -suppress-warning=CODE Don't show problems with the given code. Use this
+                         option more than once to suppress more codes.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *LintCommand) Synopsis() string {
+	return "Check the configuration for unused and deprecated declarations"
+}
@@ -0,0 +1,155 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/terminal"
+)
+
+// setupLintTest initializes a copy of the lint fixture, and returns a
+// command to lint it with.
+func setupLintTest(t *testing.T) (*LintCommand, *cli.MockUi) {
+	t.Helper()
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("lint"), td)
+	t.Chdir(td)
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id":  {Type: cty.String, Computed: true},
+						"ami": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+			"test_legacy": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id": {Type: cty.String, Computed: true},
+					},
+					Deprecated: true,
+				},
+			},
+		},
+	}
+	providerSource, closeSource := newMockProviderSource(t, map[string][]string{
+		"hashicorp/test":  {"1.2.3"},
+		"hashicorp2/test": {"1.0.0"},
+	})
+	t.Cleanup(closeSource)
+	ui := new(cli.MockUi)
+	streams, _ := terminal.StreamsForTesting(t)
+	meta := Meta{
+		testingOverrides: metaOverridesForProvider(p),
+		Ui:               ui,
+		View:             views.NewView(streams),
+		ProviderSource:   providerSource,
+	}
+
+	ic := &InitCommand{Meta: meta}
+	if code := ic.Run(nil); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+	ui.OutputWriter.Reset()
+	ui.ErrorWriter.Reset()
+
+	return &LintCommand{Meta: meta}, ui
+}
+
+func TestLint(t *testing.T) {
+	c, ui := setupLintTest(t)
+	if code := c.Run([]string{"-no-color"}); code != 2 {
+		t.Fatalf("wrong exit status %d; want 2\n%s", code, ui.ErrorWriter)
+	}
+
+	output := ui.ErrorWriter.String()
+	for _, want := range []string{
+		`Warning: Unused variable [FS0301]`,
+		`The variable "unused" is declared but never used in this module.`,
+		`Warning: Unused local value [FS0302]`,
+		`Warning: Output refers to a deprecated resource type [FS0303]`,
+		`Warning: Unused provider requirement [FS0304]`,
+		`Warning: Duplicated provider requirement [FS0305]`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{`"used"`, `test_instance`} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("output contains %q\n%s", unwanted, output)
+		}
+	}
+}
+
+func TestLint_json(t *testing.T) {
+	c, ui := setupLintTest(t)
+	if code := c.Run([]string{"-json"}); code != 2 {
+		t.Fatalf("wrong exit status %d; want 2\n%s", code, ui.ErrorWriter)
+	}
+
+	var got struct {
+		Findings []struct {
+			Code        string `json:"code"`
+			Autofixable bool   `json:"autofixable"`
+			Range       *struct {
+				Filename string `json:"filename"`
+			} `json:"range"`
+		} `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter)
+	}
+
+	type finding struct {
+		Code        string
+		Autofixable bool
+		Filename    string
+	}
+	var findings []finding
+	for _, f := range got.Findings {
+		if f.Range == nil {
+			t.Fatalf("finding %s has no range", f.Code)
+		}
+		findings = append(findings, finding{f.Code, f.Autofixable, f.Range.Filename})
+	}
+	want := []finding{
+		{"FS0301", true, "main.tf"},
+		{"FS0302", true, "main.tf"},
+		{"FS0304", true, "main.tf"},
+		{"FS0303", false, "main.tf"},
+		{"FS0305", false, "main.tf"},
+	}
+	if diff := cmp.Diff(want, findings); diff != "" {
+		t.Errorf("wrong findings\n%s", diff)
+	}
+}
+
+func TestLint_suppressed(t *testing.T) {
+	c, ui := setupLintTest(t)
+	args := []string{"-no-color"}
+	for _, code := range []string{"FS0301", "FS0302", "FS0303", "FS0304", "FS0305"} {
+		args = append(args, "-suppress-warning="+code)
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\n%s", code, ui.ErrorWriter)
+	}
+	if got, want := strings.TrimSpace(ui.OutputWriter.String()), "No problems found."; got != want {
+		t.Errorf("wrong output %q; want %q", got, want)
+	}
+}
@@ -37,12 +37,16 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/command/workdir"
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configload"
+	"github.com/rafagsiqueira/farseek/internal/defaulttags"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/getmodules"
 	"github.com/rafagsiqueira/farseek/internal/getproviders"
 	legacy "github.com/rafagsiqueira/farseek/internal/legacy/farseek"
+	"github.com/rafagsiqueira/farseek/internal/planlimits"
+	"github.com/rafagsiqueira/farseek/internal/providerrecord"
 	"github.com/rafagsiqueira/farseek/internal/providers"
 	"github.com/rafagsiqueira/farseek/internal/provisioners"
+	"github.com/rafagsiqueira/farseek/internal/runtask"
 	"github.com/rafagsiqueira/farseek/internal/states"
 	"github.com/rafagsiqueira/farseek/internal/terminal"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
@@ -100,6 +104,12 @@ type Meta struct {
 	// the specific commands being run.
 	RunningInAutomation bool
 
+	// Offline prevents commands from installing providers and modules over
+	// the network, so that they fail early, naming each dependency that
+	// isn't available locally. Providers are installed only from the local
+	// mirrors and the plugin cache.
+	Offline bool
+
 	// CLIConfigDir is the directory from which CLI configuration files were
 	// read by the caller and the directory where any changes to CLI
 	// configuration files by commands should be made.
@@ -142,6 +152,15 @@ type Meta struct {
 	// unit testing.
 	ModulePackageFetcher *getmodules.PackageFetcher
 
+	// OCIModulePackagePushStore returns the repository that
+	// "farseek registry publish-module" publishes module packages to when
+	// the destination is an OCI repository, using the OCI credentials
+	// policy from the CLI configuration.
+	//
+	// Leaving this nil means that module packages can't be published to OCI
+	// repositories, which is only reasonable for unit testing.
+	OCIModulePackagePushStore func(ctx context.Context, registryDomain, repositoryName string) (getmodules.OCIRepositoryPushStore, error)
+
 	// MakeRegistryHTTPClient is a function called each time a command needs
 	// an HTTP client that will be used to make requests to a module or
 	// provider registry.
@@ -155,6 +174,11 @@ type Meta struct {
 	// web browser.
 	BrowserLauncher webbrowser.Launcher
 
+	// CompletionProvider supplies the values that shell completion offers
+	// for workspace names, resource addresses, and plan files. If this is
+	// nil then they are looked up in the current working directory.
+	CompletionProvider CompletionProvider
+
 	// A context.Context provided by the caller -- typically "package main" --
 	// which might be carrying telemetry-related metadata and so should be
 	// used when creating downstream traces, etc.
@@ -184,6 +208,63 @@ type Meta struct {
 	// just trusting that someone else did it before running Farseek.
 	UnmanagedProviders map[addrs.Provider]*plugin.ReattachConfig
 
+	// ProviderCredentialsHelpers are the programs that supply short-lived
+	// credentials to providers that Farseek starts, as configured in the
+	// CLI configuration.
+	ProviderCredentialsHelpers map[addrs.Provider]*ProviderCredentialsHelper
+
+	// ProviderGRPC tunes the gRPC connections to the providers that Farseek
+	// starts, as configured in the CLI configuration. If nil, the
+	// connections use their usual settings.
+	ProviderGRPC *ProviderGRPCOptions
+
+	// ProviderRecorder, if set, records the calls to the providers that
+	// Farseek starts, as requested with the FARSEEK_RECORD environment
+	// variable. ProviderReplayer, if set, replays recorded calls instead of
+	// starting the providers, as requested with FARSEEK_REPLAY.
+	ProviderRecorder *providerrecord.Recorder
+	ProviderReplayer *providerrecord.Replayer
+
+	// StrictProviderDevOverrides makes plans and applies refuse to run while
+	// ProviderDevOverrides has any providers, unless explicitly allowed.
+	StrictProviderDevOverrides bool
+
+	// RequireSignedCommits and TrustedSigningKeys are the CLI configuration
+	// of the check that the commits to apply are signed by trusted keys.
+	RequireSignedCommits bool
+	TrustedSigningKeys   []string
+
+	// ApplyBranches are the patterns of the branches that the CLI
+	// configuration allows applying from.
+	ApplyBranches []string
+
+	// TagPolicyPath is the path of the tag policy file from the CLI
+	// configuration, used when the -tag-policy option isn't set.
+	TagPolicyPath string
+
+	// ModuleDeprecationsPath is the path of the file from the CLI
+	// configuration that marks module versions as deprecated, if any.
+	ModuleDeprecationsPath string
+
+	// PlanLimits are the limits on the objects that a plan can create,
+	// update and destroy from the CLI configuration, which the -max-create,
+	// -max-update and -max-destroy options override.
+	PlanLimits planlimits.Limits
+
+	// RunTasks are the external services that the CLI configuration calls
+	// to check the configuration before plans and the plan before applies.
+	RunTasks []*runtask.Task
+
+	// DefaultTags are the tags that the CLI configuration injects into
+	// every resource of some providers, keyed by the provider.
+	DefaultTags map[addrs.Provider]*defaulttags.Defaults
+
+	// PreApplyHooks and PostApplyHooks are the external programs that the
+	// CLI configuration runs before and after each change that apply makes
+	// to a resource.
+	PreApplyHooks  []*ExternalApplyHook
+	PostApplyHooks []*ExternalApplyHook
+
 	// AllowExperimentalFeatures controls whether a command that embeds this
 	// Meta is permitted to make use of experimental Farseek features.
 	//
@@ -227,10 +308,19 @@ type Meta struct {
 	// backendState is the currently active backend state
 	backendState *legacy.BackendState
 
+	// workspaceMetadata is the store of workspace metadata of the most
+	// recently loaded backend, if it has one.
+	workspaceMetadata backend.WorkspaceMetadataStore
+
 	// Variables for the context (private)
 	variableArgs rawFlags
 	input        bool
 
+	// inputStrict is set by -input=strict, which records the prompts in
+	// strictInput instead of asking for input.
+	inputStrict bool
+	strictInput *StrictUIInput
+
 	// Targets for this context (private)
 	targets     []addrs.Targetable
 	targetFlags []string
@@ -261,6 +351,11 @@ type Meta struct {
 	// parallelism is used to control the number of concurrent operations
 	// allowed when walking the graph
 	//
+	// operationTimeout is the optional limit on the running time of an
+	// operation started with RunOperation, after which it is stopped as if
+	// interrupted. operationTimeoutGrace is how long to then wait for the
+	// graceful stop before cancelling the operation outright.
+	//
 	// provider is to specify specific resource providers
 	//
 	// stateLock is set to false to disable state locking
@@ -284,18 +379,28 @@ type Meta struct {
 	//
 	// consolidateErrors (-consolidate-errors=true) enables consolidation
 	// of errors in the output, printing a single instances of a particular warning.
-	statePath           string
-	stateOutPath        string
-	backupPath          string
-	parallelism         int
-	stateLock           bool
-	stateLockTimeout    time.Duration
-	forceInitCopy       bool
-	reconfigure         bool
-	migrateState        bool
-	compactWarnings     bool
-	consolidateWarnings bool
-	consolidateErrors   bool
+	statePath             string
+	stateOutPath          string
+	backupPath            string
+	parallelism           int
+	operationTimeout      time.Duration
+	operationTimeoutGrace time.Duration
+	stateLock             bool
+	stateLockTimeout      time.Duration
+	forceInitCopy         bool
+	reconfigure           bool
+	migrateState          bool
+	compactWarnings       bool
+	consolidateWarnings   bool
+	consolidateErrors     bool
+
+	// suppressWarnings are the codes or summaries of warnings given with
+	// the -suppress-warning option, which can be repeated.
+	suppressWarnings []string
+
+	// jsonProtocol is the version of the machine-readable UI protocol given
+	// with the -json-protocol option.
+	jsonProtocol string
 
 	// Used with commands which write state to allow users to write remote
 	// state even if the remote and local Farseek versions don't match.
@@ -369,6 +474,15 @@ func (m *Meta) fixupMissingWorkingDir() {
 	}
 }
 
+// discoveryDir returns the directory whose configuration files Farseek
+// compares against git history to discover changed resources, and whose last
+// applied commit SHA it tracks. This is the root module directory, which
+// already accounts for the -chdir option.
+func (m *Meta) discoveryDir() string {
+	m.fixupMissingWorkingDir()
+	return m.WorkingDir.RootModuleDir()
+}
+
 // DataDir returns the directory where local data will be stored.
 // Defaults to DefaultDataDir in the current working directory.
 func (m *Meta) DataDir() string {
@@ -376,6 +490,60 @@ func (m *Meta) DataDir() string {
 	return m.WorkingDir.DataDir()
 }
 
+// dataSourceCache returns the cache for the results of data resources that
+// the given operation arguments select, stored in the data directory.
+func (m *Meta) dataSourceCache(args *arguments.Operation) *farseek.DataSourceCache {
+	return &farseek.DataSourceCache{
+		Dir:        filepath.Join(m.DataDir(), farseek.DataSourceCacheDir),
+		DefaultTTL: args.DataCacheTTL,
+		Bypass:     args.DataCacheBypass,
+	}
+}
+
+// withoutIgnored returns the given discovered resources without those that
+// the .farseekignore file of the configuration root skips, and those that
+// "farseek state forget" recorded in the data directory. It warns about the
+// resources that the .farseekignore file skips, since they might be
+// skipped by mistake.
+func (m *Meta) withoutIgnored(resources []farseek.DiscoveredResource) ([]farseek.DiscoveredResource, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	rules, err := farseek.LoadIgnoreRules(m.discoveryDir())
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read ignored resources",
+			fmt.Sprintf("Farseek could not read the %s file: %s.", farseek.IgnoreFilename, err),
+		), tfdiags.CodeIgnoredReadFailed))
+		return nil, diags
+	}
+	resources, skipped := rules.Filter(resources)
+	if len(skipped) > 0 {
+		var detail strings.Builder
+		fmt.Fprintf(&detail, "The %s file skips these changed resources, so Farseek won't create, update, or destroy them:\n", farseek.IgnoreFilename)
+		for _, dr := range skipped {
+			fmt.Fprintf(&detail, "\n  - %s, in %s (%s)", dr.Address, dr.Filename, dr.Pattern)
+		}
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Ignored changed resources",
+			detail.String(),
+		), tfdiags.CodeResourcesIgnored))
+	}
+
+	path := filepath.Join(m.DataDir(), farseek.IgnoredFilename)
+	ignored, err := farseek.ReadIgnored(path)
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read ignored resources",
+			fmt.Sprintf("Farseek could not read the resources that it no longer manages from %s: %s.", path, err),
+		), tfdiags.CodeIgnoredReadFailed))
+		return nil, diags
+	}
+	return farseek.FilterIgnored(resources, ignored), diags
+}
+
 const (
 	// InputModeEnvVar is the environment variable that, if set to "false" or
 	// "0", causes farseek commands to behave as if the `-input=false` flag was
@@ -386,6 +554,9 @@ const (
 // InputMode returns the type of input we should ask for in the form of
 // farseek.InputMode which is passed directly to Context.Input.
 func (m *Meta) InputMode() farseek.InputMode {
+	if m.inputStrict {
+		return farseek.InputModeProvider
+	}
 	if test || !m.input {
 		return 0
 	}
@@ -406,6 +577,12 @@ func (m *Meta) InputMode() farseek.InputMode {
 
 // UIInput returns a UIInput object to be used for asking for input.
 func (m *Meta) UIInput() farseek.UIInput {
+	if m.inputStrict {
+		if m.strictInput == nil {
+			m.strictInput = &StrictUIInput{}
+		}
+		return m.strictInput
+	}
 	return &UIInput{
 		Colorize: m.Colorize(),
 	}
@@ -539,8 +716,20 @@ func (m *Meta) RunOperation(ctx context.Context, b backend.Enhanced, opReq *back
 		return nil, diags.Append(fmt.Errorf("error starting operation: %w", err))
 	}
 
-	// Wait for the operation to complete or an interrupt to occur
+	// If the caller requested a time limit then we'll treat its expiry
+	// in the same way as an interrupt, so that the operation still has a
+	// chance to clean up and persist whatever it has done so far.
+	var timeoutCh <-chan time.Time
+	if m.operationTimeout > 0 {
+		timer := time.NewTimer(m.operationTimeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	// Wait for the operation to complete, an interrupt, or the time limit
 	select {
+	case <-timeoutCh:
+		return m.stopTimedOutOperation(op, opReq, diags)
 	case <-m.ShutdownCh:
 		// gracefully stop the operation
 		op.Stop()
@@ -575,6 +764,42 @@ func (m *Meta) RunOperation(ctx context.Context, b backend.Enhanced, opReq *back
 	return op, diags
 }
 
+// stopTimedOutOperation handles an operation whose -operation-timeout has
+// elapsed, using the same graceful stop as for an interrupt signal and then
+// cancelling the operation if it is still running once the grace period has
+// also elapsed.
+func (m *Meta) stopTimedOutOperation(op *backend.RunningOperation, opReq *backend.Operation, diags tfdiags.Diagnostics) (*backend.RunningOperation, tfdiags.Diagnostics) {
+	op.Stop()
+	opReq.View.Stopping()
+
+	timedOut := tfdiags.Sourceless(
+		tfdiags.Error,
+		"Operation timed out",
+		fmt.Sprintf("The operation did not complete within the time limit of %s set by the -operation-timeout option, so Farseek stopped it early. Some changes may have been only partially applied.", m.operationTimeout),
+	)
+
+	select {
+	case <-op.Done():
+		// operation stopped gracefully within the grace period
+		return op, diags.Append(timedOut)
+	case <-m.ShutdownCh:
+		opReq.View.FatalInterrupt()
+	case <-time.After(m.operationTimeoutGrace):
+	}
+
+	// cancel the operation completely
+	op.Cancel()
+
+	// the operation should return asap
+	// but timeout just in case
+	select {
+	case <-op.Done():
+	case <-time.After(5 * time.Second):
+	}
+
+	return nil, diags.Append(timedOut).Append(errors.New("operation canceled"))
+}
+
 // contextOpts returns the options to use to initialize a Farseek
 // context with the settings from this Meta.
 func (m *Meta) contextOpts(ctx context.Context) (*farseek.ContextOpts, error) {
@@ -649,7 +874,7 @@ func (m *Meta) varFlagSet(f *flag.FlagSet) {
 func (m *Meta) extendedFlagSet(n string) *flag.FlagSet {
 	f := m.defaultFlagSet(n)
 
-	f.BoolVar(&m.input, "input", true, "input")
+	f.Var(arguments.NewFlagInput(&m.input, &m.inputStrict), "input", "input")
 	f.Var((*FlagStringSlice)(&m.targetFlags), "target", "resource to target")
 	f.Var((*FlagStringSlice)(&m.excludeFlags), "exclude", "resource to exclude")
 	f.BoolVar(&m.compactWarnings, "compact-warnings", false, "use compact warnings")
@@ -682,6 +907,10 @@ func (m *Meta) process(args []string) []string {
 		if v == "-no-color" {
 			m.color = false
 			m.Color = false
+		} else if prefix := "-suppress-warning="; strings.HasPrefix(v, prefix) {
+			m.suppressWarnings = append(m.suppressWarnings, strings.TrimPrefix(v, prefix))
+		} else if prefix := "-json-protocol="; strings.HasPrefix(v, prefix) {
+			m.jsonProtocol = strings.TrimPrefix(v, prefix)
 		} else {
 			// copy and increment index
 			args[i] = v
@@ -709,6 +938,8 @@ func (m *Meta) process(args []string) []string {
 			ConsolidateWarnings: m.consolidateWarnings,
 			ConsolidateErrors:   m.consolidateErrors,
 			NoColor:             !m.Color,
+			SuppressWarnings:    m.suppressWarnings,
+			JSONProtocol:        m.jsonProtocol,
 		})
 	}
 
@@ -757,6 +988,9 @@ func (m *Meta) showDiagnostics(vals ...interface{}) {
 	var diags tfdiags.Diagnostics
 	diags = diags.Append(vals...)
 	diags.Sort()
+	if m.View != nil {
+		diags = m.View.FilterSuppressedWarnings(diags)
+	}
 
 	if len(diags) == 0 {
 		return
@@ -769,6 +1003,9 @@ func (m *Meta) showDiagnostics(vals ...interface{}) {
 	}
 
 	outputWidth := m.ErrorColumns()
+	if m.View != nil {
+		m.View.NoteErrorCodes(diags)
+	}
 
 	if m.consolidateWarnings {
 		diags = diags.Consolidate(1, tfdiags.Warning)
@@ -838,6 +1075,20 @@ func (m *Meta) Workspace(ctx context.Context) (string, error) {
 	return current, nil
 }
 
+// currentWorkspaceMetadata returns the name and metadata of the currently
+// selected workspace, as described by the terraform_workspace data source.
+func (m *Meta) currentWorkspaceMetadata(ctx context.Context) (string, *backend.WorkspaceMetadata, error) {
+	workspace, err := m.Workspace(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if m.workspaceMetadata == nil {
+		return workspace, &backend.WorkspaceMetadata{}, nil
+	}
+	meta, err := m.workspaceMetadata.WorkspaceMetadata(ctx, workspace)
+	return workspace, meta, err
+}
+
 // WorkspaceOverridden returns the name of the currently configured workspace,
 // corresponding to the desired named state, as well as a bool saying whether
 // this was set via the TF_WORKSPACE environment variable.
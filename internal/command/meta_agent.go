@@ -0,0 +1,226 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/backend/agent"
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	viewsjson "github.com/rafagsiqueira/farseek/internal/command/views/json"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// agentRun tracks what we learn from the stream of events of a remote
+// operation while rendering them.
+type agentRun struct {
+	view views.Agent
+
+	// hasChanges records whether the most recent change summary from the
+	// remote operation included any changes.
+	hasChanges bool
+}
+
+func (r *agentRun) handle(ev *agent.Event) {
+	switch ev.Type {
+	case agent.EventLog:
+		var msg struct {
+			Type    viewsjson.MessageType    `json:"type"`
+			Changes *viewsjson.ChangeSummary `json:"changes"`
+		}
+		if err := json.Unmarshal(ev.Log, &msg); err == nil && msg.Type == viewsjson.MessageChangeSummary && msg.Changes != nil {
+			c := msg.Changes
+			r.hasChanges = c.Add+c.Change+c.Remove+c.Import+c.Forget > 0
+		}
+		r.view.Log(ev.Log)
+	case agent.EventOutput:
+		r.view.Output(ev.Output)
+	}
+}
+
+// runAgentOperation packs the current working directory and sends it to the
+// agent at the given URL along with the given request, rendering the events
+// of the remote operation as they arrive. The files that the operation
+// changed on the agent, such as the state, are written back here whether or
+// not it succeeded.
+func (m *Meta) runAgentOperation(ctx context.Context, agentURL string, req *agent.Request, run *agentRun) (*agent.Result, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	root, dir, err := agentArchiveRoot()
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to prepare configuration for agent",
+			fmt.Sprintf("Could not find the Git repository of the working directory: %s.", err),
+		))
+		return nil, diags
+	}
+
+	// The local data directory holds providers and modules installed for
+	// this host, so the agent prepares its own instead.
+	var config bytes.Buffer
+	if err := agent.PackDir(root, &config, filepath.Base(m.DataDir())); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to prepare configuration for agent",
+			fmt.Sprintf("Could not archive the working directory: %s.", err),
+		))
+		return nil, diags
+	}
+	req.Config = config.Bytes()
+	req.Dir = dir
+
+	client := &agent.Client{
+		BaseURL: agentURL,
+		Token:   os.Getenv(agent.TokenEnvName),
+	}
+	result, err := client.Run(ctx, req, run.handle)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Agent operation failed",
+			fmt.Sprintf("The operation could not be completed on the agent at %s: %s.", agentURL, err),
+		))
+		return nil, diags
+	}
+
+	if len(result.Files) != 0 {
+		if err := m.writeAgentFiles(result.Files); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to save files from agent",
+				fmt.Sprintf("The operation on the agent changed files in the working directory, such as the state, but they could not all be written here: %s.", err),
+			))
+			return nil, diags
+		}
+	}
+	return result, diags
+}
+
+// agentArchiveRoot returns the directory to archive for an agent, and the
+// slash-separated path of the current working directory within it. That is
+// the root of the Git repository containing the working directory, if any,
+// since the agent needs its metadata to discover changed resources.
+func agentArchiveRoot() (string, string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	root, err := farseek.RepositoryRoot(wd)
+	if err != nil || root == "" {
+		return ".", "", err
+	}
+
+	// Git reports the real path of the repository, which can differ from
+	// the working directory through symlinks.
+	if wd, err = filepath.EvalSymlinks(wd); err != nil {
+		return "", "", err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(root, wd)
+	if err != nil {
+		return "", "", err
+	}
+	if rel == "." {
+		rel = ""
+	}
+	return root, filepath.ToSlash(rel), nil
+}
+
+// writeAgentFiles writes the files from the archive in an agent's result
+// into the working directory. The agent uses the default data directory, so
+// files within it are written to the data directory in use here instead.
+func (m *Meta) writeAgentFiles(archive []byte) error {
+	tmp, err := os.MkdirTemp("", "farseek-agent-files-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := agent.UnpackDir(bytes.NewReader(archive), tmp); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(tmp, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(tmp, path)
+		if err != nil {
+			return err
+		}
+		dst := rel
+		if inData, err := filepath.Rel(DefaultDataDir, rel); err == nil && filepath.IsLocal(inData) {
+			dst = filepath.Join(m.DataDir(), inData)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, src, info.Mode().Perm())
+	})
+}
+
+// agentArgs returns the given raw command line arguments without the
+// options that only make sense locally or that the agent controls itself.
+// Any positional arguments must already have been removed.
+func agentArgs(rawArgs []string) []string {
+	var ret []string
+	for i := 0; i < len(rawArgs); i++ {
+		arg := rawArgs[i]
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") {
+			ret = append(ret, arg)
+			continue
+		}
+		switch name {
+		case "agent", "out":
+			if !hasValue {
+				i++ // skip the value in the following argument too
+			}
+		case "json", "input", "auto-approve":
+		default:
+			ret = append(ret, arg)
+		}
+	}
+	return ret
+}
+
+// confirmAgentApply asks the user to approve applying the plan that the
+// agent just created.
+func (m *Meta) confirmAgentApply(destroy bool) (bool, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	opts := &farseek.InputOpts{
+		Id:          "approve",
+		Query:       "\nDo you want to perform these actions?",
+		Description: "Farseek will perform the actions described above.\nOnly 'yes' will be accepted to approve.",
+	}
+	if destroy {
+		opts.Query = "\nDo you really want to destroy all resources?"
+		opts.Description = "Farseek will destroy all your managed infrastructure, as shown above.\nThere is no undo. Only 'yes' will be accepted to confirm."
+	}
+	ok, err := m.confirm(opts)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("error asking for approval: %w", err))
+	}
+	return ok, diags
+}
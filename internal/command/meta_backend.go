@@ -177,6 +177,7 @@ func (m *Meta) Backend(ctx context.Context, opts *BackendOpts, enc encryption.St
 	// then return that as-is. This works even if b == nil (it will be !ok).
 	if enhanced, ok := b.(backend.Enhanced); ok {
 		log.Printf("[TRACE] Meta.Backend: backend %T supports operations", b)
+		m.workspaceMetadata, _ = enhanced.(backend.WorkspaceMetadataStore)
 		return enhanced, nil
 	}
 
@@ -218,6 +219,7 @@ func (m *Meta) Backend(ctx context.Context, opts *BackendOpts, enc encryption.St
 		}
 	}
 
+	m.workspaceMetadata = local
 	return local, nil
 }
 
@@ -440,10 +442,13 @@ func (m *Meta) Operation(ctx context.Context, b backend.Backend, vt arguments.Vi
 		Targets:         m.targets,
 		Excludes:        m.excludes,
 		UIIn:            m.UIInput(),
+		InputStrict:     m.inputStrict,
 		UIOut:           m.Ui,
 		Workspace:       workspace,
 		StateLocker:     stateLocker,
 		DependencyLocks: depLocks,
+		DefaultTags:     m.DefaultTags,
+		RunTasks:        m.RunTasks,
 	}
 }
 
@@ -629,7 +629,9 @@ func TestMetaBackend_configureNewWithStateExistingNoMigrate(t *testing.T) {
 
 // Saved backend state matching config
 func TestMetaBackend_configuredUnchanged(t *testing.T) {
-	t.Chdir(testFixturePath("backend-unchanged"))
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-unchanged"), td)
+	t.Chdir(td)
 
 	// Setup the meta
 	m := testMetaBackend(t, nil)
@@ -667,6 +669,42 @@ func TestMetaBackend_configuredUnchanged(t *testing.T) {
 	}
 }
 
+// Saved backend state matching config, with a hash computed before the local
+// backend had the "workspaces" argument, used without running init again
+func TestMetaBackend_configuredUnchangedLegacyHash(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("backend-unchanged"), td)
+	t.Chdir(td)
+
+	// The fixture's saved backend config has no "workspaces" attribute, and
+	// its hash was computed without it.
+	saved := testDataStateRead(t, filepath.Join(DefaultDataDir, DefaultStateFilename))
+	if got, want := saved.Backend.Hash, uint64(4282859327); got != want {
+		t.Fatalf("wrong saved hash %d in fixture; want %d", got, want)
+	}
+
+	// Setup the meta
+	m := testMetaBackend(t, nil)
+
+	// Get the backend, as a command other than init would
+	b, diags := m.Backend(t.Context(), nil, encryption.StateEncryptionDisabled())
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	// Check the state
+	s, err := b.StateMgr(t.Context(), backend.DefaultStateName)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.RefreshState(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if testStateMgrCurrentLineage(s) != "configuredUnchanged" {
+		t.Fatalf("bad: %#v", s.State())
+	}
+}
+
 // Saved backend state matching config when the configuration uses static eval references
 // and there's an argument overridden on the commandl ine.
 func TestMetaBackend_configuredUnchangedWithStaticEvalVars(t *testing.T) {
@@ -1700,6 +1738,7 @@ func TestMetaBackend_planLocal(t *testing.T) {
 	backendConfigBlock := cty.ObjectVal(map[string]cty.Value{
 		"path":          cty.NullVal(cty.String),
 		"workspace_dir": cty.NullVal(cty.String),
+		"workspaces":    cty.NullVal(cty.Map(cty.Object(map[string]cty.Type{"path": cty.String}))),
 	})
 	backendConfigRaw, err := plans.NewDynamicValue(backendConfigBlock, backendConfigBlock.Type())
 	if err != nil {
@@ -1793,6 +1832,7 @@ func TestMetaBackend_planLocalStatePath(t *testing.T) {
 	backendConfigBlock := cty.ObjectVal(map[string]cty.Value{
 		"path":          cty.NullVal(cty.String),
 		"workspace_dir": cty.NullVal(cty.String),
+		"workspaces":    cty.NullVal(cty.Map(cty.Object(map[string]cty.Type{"path": cty.String}))),
 	})
 	backendConfigRaw, err := plans.NewDynamicValue(backendConfigBlock, backendConfigBlock.Type())
 	if err != nil {
@@ -1894,6 +1934,7 @@ func TestMetaBackend_planLocalMatch(t *testing.T) {
 	backendConfigBlock := cty.ObjectVal(map[string]cty.Value{
 		"path":          cty.NullVal(cty.String),
 		"workspace_dir": cty.NullVal(cty.String),
+		"workspaces":    cty.NullVal(cty.Map(cty.Object(map[string]cty.Type{"path": cty.String}))),
 	})
 	backendConfigRaw, err := plans.NewDynamicValue(backendConfigBlock, backendConfigBlock.Type())
 	if err != nil {
@@ -9,6 +9,7 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -23,13 +24,18 @@ import (
 	"github.com/zclconf/go-cty/cty/convert"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configload"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/httpclient"
 	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/modsdir"
+	"github.com/rafagsiqueira/farseek/internal/moduledeprecations"
+	"github.com/rafagsiqueira/farseek/internal/planlimits"
 	"github.com/rafagsiqueira/farseek/internal/registry"
+	"github.com/rafagsiqueira/farseek/internal/tagpolicy"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -207,6 +213,60 @@ func (m *Meta) loadSingleModuleWithTests(ctx context.Context, dir string, testDi
 	return module, diags
 }
 
+// loadTagPolicy reads the tag policy file at the given path, or else at the
+// path from the CLI configuration. It returns nil if neither is set.
+func (m *Meta) loadTagPolicy(path string) (*tagpolicy.Policy, tfdiags.Diagnostics) {
+	if path == "" {
+		path = m.TagPolicyPath
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return tagpolicy.Load(path)
+}
+
+// moduleDeprecations returns the checker for the module calls that use a
+// deprecated version of a module, according to the registries the modules
+// were installed from or to the mapping file from the CLI configuration.
+func (m *Meta) moduleDeprecations() (*moduledeprecations.Checker, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	checker := &moduledeprecations.Checker{}
+
+	if m.ModuleDeprecationsPath != "" {
+		var moreDiags tfdiags.Diagnostics
+		checker.Mapping, moreDiags = moduledeprecations.Load(m.ModuleDeprecationsPath)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+	}
+	manifest, err := modsdir.ReadManifestSnapshotForDir(m.modulesDir())
+	if err != nil {
+		// Without the manifest we only lose the deprecations recorded by
+		// the registries, so this isn't worth failing for.
+		log.Printf("[WARN] failed to read the modules manifest for deprecations: %s", err)
+	}
+	checker.Manifest = manifest
+	return checker, diags
+}
+
+// planLimits returns the limits on the objects that a plan can create,
+// update and destroy, from the CLI configuration and the given options.
+func (m *Meta) planLimits(args *arguments.PlanLimits) planlimits.Limits {
+	limits := m.PlanLimits
+	flag := func(max int, name string) *planlimits.Limit {
+		if max < 0 {
+			return nil
+		}
+		return &planlimits.Limit{Max: max, Source: fmt.Sprintf("the -%s option", name)}
+	}
+	return limits.Override(planlimits.Limits{
+		Create:  flag(args.MaxCreate, "max-create"),
+		Update:  flag(args.MaxUpdate, "max-update"),
+		Destroy: flag(args.MaxDestroy, "max-destroy"),
+	})
+}
+
 // dirIsConfigPath checks if the given path is a directory that contains at
 // least one Farseek configuration file (.tf or .tf.json), returning true
 // if so.
@@ -296,6 +356,7 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 	}
 
 	inst := initwd.NewModuleInstaller(m.modulesDir(), loader, m.registryClient(ctx), m.ModulePackageFetcher)
+	inst.SetOffline(m.Offline)
 
 	call, vDiags := m.rootModuleCall(ctx, rootDir)
 	diags = diags.Append(vDiags)
@@ -303,8 +364,13 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 		return true, diags
 	}
 
-	_, moreDiags := inst.InstallModules(ctx, rootDir, testsDir, upgrade, installErrsOnly, hooks, call)
+	config, moreDiags := inst.InstallModules(ctx, rootDir, testsDir, upgrade, installErrsOnly, hooks, call)
 	diags = diags.Append(moreDiags)
+	if !moreDiags.HasErrors() {
+		deprecations, moreDiags := m.moduleDeprecations()
+		diags = diags.Append(moreDiags)
+		diags = diags.Append(deprecations.Check(config))
+	}
 
 	if ctx.Err() == context.Canceled {
 		m.showDiagnostics(diags)
@@ -331,6 +397,19 @@ func (m *Meta) initDirFromModule(ctx context.Context, targetDir string, addr str
 		return true, diags
 	}
 
+	if m.Offline {
+		if source, err := addrs.ParseModuleSource(addr); err == nil {
+			if _, local := source.(addrs.ModuleSourceLocal); !local {
+				diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Module requires network access",
+					fmt.Sprintf("Copying the module %q into the working directory requires network access, which isn't allowed in offline mode. Run \"farseek init -from-module\" without offline mode, or copy the module from a local path.", source.ForDisplay()),
+				), tfdiags.CodeOfflineNetworkRequired))
+				return true, diags
+			}
+		}
+	}
+
 	targetDir = m.normalizePath(targetDir)
 	moreDiags := initwd.DirFromModule(ctx, loader, targetDir, m.modulesDir(), addr, m.registryClient(ctx), m.ModulePackageFetcher, hooks)
 	diags = diags.Append(moreDiags)
@@ -373,6 +452,7 @@ func (m *Meta) inputForSchema(given cty.Value, schema *configschema.Block) (cty.
 	sort.Strings(names)
 
 	input := m.UIInput()
+	var strictErr error
 	for _, name := range names {
 		attrS := schema.Attributes[name]
 
@@ -382,6 +462,12 @@ func (m *Meta) inputForSchema(given cty.Value, schema *configschema.Block) (cty.
 				Query:       name,
 				Description: attrS.Description,
 			})
+			if errors.Is(err, errInputStrict) {
+				// Record the prompts for the remaining arguments too, so
+				// that they are all reported at once.
+				strictErr = fmt.Errorf("%s: %w", name, err)
+				break
+			}
 			if err != nil {
 				return cty.UnknownVal(schema.ImpliedType()), fmt.Errorf("%s: %w", name, err)
 			}
@@ -397,6 +483,9 @@ func (m *Meta) inputForSchema(given cty.Value, schema *configschema.Block) (cty.
 			break
 		}
 	}
+	if strictErr != nil {
+		return cty.UnknownVal(schema.ImpliedType()), strictErr
+	}
 
 	return cty.ObjectVal(retVals), nil
 }
@@ -19,6 +19,11 @@ import (
 
 // Input returns whether or not input asking is enabled.
 func (m *Meta) Input() bool {
+	// Strict input never reads from the terminal, so it is allowed
+	// wherever we would otherwise prompt.
+	if m.inputStrict {
+		return true
+	}
 	if test || !m.input {
 		return false
 	}
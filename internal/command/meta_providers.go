@@ -163,6 +163,17 @@ func (m *Meta) providerInstallSource() getproviders.Source {
 		// always-empty source.
 		return getproviders.MultiSource(nil)
 	}
+	if m.Offline {
+		// In offline mode, the providers that would be installed from a
+		// registry or a network mirror must already be in the plugin cache
+		// or the working directory, where a previous init installed them.
+		m.fixupMissingWorkingDir()
+		dirs := []string{m.WorkingDir.ProviderLocalCacheDir()}
+		if m.PluginCacheDir != "" {
+			dirs = append(dirs, m.PluginCacheDir)
+		}
+		return getproviders.Offline(context.TODO(), m.ProviderSource, dirs)
+	}
 	return m.ProviderSource
 }
 
@@ -225,6 +236,31 @@ func (m *Meta) providerDevOverrideRuntimeWarnings() tfdiags.Diagnostics {
 	}
 }
 
+// providerDevOverrideStrictErrors returns an error if there is at least one
+// provider development override in effect and the CLI configuration is
+// strict about them, unless the operation explicitly allows them.
+//
+// Plans and applies use this so that a development build of a provider left
+// in the CLI configuration can't change real infrastructure by accident.
+func (m *Meta) providerDevOverrideStrictErrors(allowed bool) tfdiags.Diagnostics {
+	if !m.StrictProviderDevOverrides || allowed || len(m.ProviderDevOverrides) == 0 {
+		return nil
+	}
+	var detailMsg strings.Builder
+	detailMsg.WriteString("The following provider development overrides are set in the CLI configuration:\n")
+	for addr, path := range m.ProviderDevOverrides {
+		detailMsg.WriteString(fmt.Sprintf(" - %s in %s\n", addr.ForDisplay(), path))
+	}
+	detailMsg.WriteString("\nThe CLI configuration sets strict_provider_dev_overrides, so Farseek won't plan or apply changes with them. Remove the overrides, or use the -allow-dev-overrides option if you meant to use them.")
+	return tfdiags.Diagnostics{
+		tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Provider development overrides are not allowed",
+			detailMsg.String(),
+		), tfdiags.CodeProviderDevOverrides),
+	}
+}
+
 // providerFactories uses the selections made previously by an installer in
 // the local cache directory (m.providerLocalCacheDir) to produce a map
 // from provider addresses to factory functions to create instances of
@@ -338,14 +374,43 @@ func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error)
 				return nil, checkErr
 			}
 
-			return providerFactory(cached)()
+			if helper, ok := m.ProviderCredentialsHelpers[provider]; ok {
+				return credentialedProviderFactory(cached, helper, m.ProviderGRPC)()
+			}
+			return providerFactory(cached, m.ProviderGRPC)()
 		}
 	}
 	for provider, localDir := range devOverrideProviders {
-		factories[provider] = devOverrideProviderFactory(provider, localDir)
+		factories[provider] = devOverrideProviderFactory(provider, localDir, m.ProviderCredentialsHelpers[provider], m.ProviderGRPC)
 	}
 	for provider, reattach := range unmanagedProviders {
-		factories[provider] = unmanagedProviderFactory(provider, reattach)
+		factories[provider] = unmanagedProviderFactory(provider, reattach, m.ProviderGRPC)
+	}
+
+	// The providers we'd start can instead replay, or record, their calls
+	// for integration tests. The internal providers need neither, because
+	// they don't call anything outside of Farseek.
+	switch {
+	case m.ProviderReplayer != nil:
+		// A replayed provider doesn't need its package, so the providers
+		// that lack one can be replayed too.
+		for provider := range errs {
+			factories[provider] = nil
+		}
+		errs = nil
+		for provider := range factories {
+			if provider.IsBuiltIn() {
+				continue
+			}
+			factories[provider] = m.ProviderReplayer.Factory(provider)
+		}
+	case m.ProviderRecorder != nil:
+		for provider, factory := range factories {
+			if provider.IsBuiltIn() {
+				continue
+			}
+			factories[provider] = m.ProviderRecorder.Wrap(provider, factory)
+		}
 	}
 
 	var err error
@@ -358,93 +423,123 @@ func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error)
 func (m *Meta) internalProviders() map[string]providers.Factory {
 	return map[string]providers.Factory{
 		"terraform": func() (providers.Interface, error) {
-			return terraformProvider.NewProvider(), nil
+			return terraformProvider.NewProviderWithWorkspace(m.currentWorkspaceMetadata), nil
 		},
 	}
 }
 
 // providerFactory produces a provider factory that runs up the executable
 // file in the given cache package and uses go-plugin to implement
-// providers.Interface against it.
-func providerFactory(meta *providercache.CachedProvider) providers.Factory {
+// providers.Interface against it, connecting with the given gRPC options.
+func providerFactory(meta *providercache.CachedProvider, grpcOpts *ProviderGRPCOptions) providers.Factory {
 	schemaCache := providers.NewSchemaCache()
 
 	return func() (providers.Interface, error) {
-		execFile, err := meta.ExecutableFile()
-		if err != nil {
-			return nil, err
-		}
+		return startProvider(meta, schemaCache, nil, grpcOpts)
+	}
+}
 
-		config := &plugin.ClientConfig{
-			HandshakeConfig:  tfplugin.Handshake,
-			Logger:           logging.NewProviderLogger(""),
-			AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
-			Managed:          true,
-			Cmd:              exec.Command(execFile),
-			AutoMTLS:         enableProviderAutoMTLS,
-			VersionedPlugins: tfplugin.VersionedPlugins,
-			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", meta.Provider)),
-			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", meta.Provider)),
-		}
+// startProvider runs up the executable file in the given cache package,
+// adding the given environment variables to those it inherits from Farseek,
+// and uses go-plugin to implement providers.Interface against it, connecting
+// with the given gRPC options.
+func startProvider(meta *providercache.CachedProvider, schemaCache providers.SchemaCache, env []string, grpcOpts *ProviderGRPCOptions) (providers.Interface, error) {
+	execFile, err := meta.ExecutableFile()
+	if err != nil {
+		return nil, err
+	}
 
-		client := plugin.NewClient(config)
-		rpcClient, err := client.Client()
-		if err != nil {
-			return nil, err
-		}
+	cmd := exec.Command(execFile)
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
-		raw, err := rpcClient.Dispense(tfplugin.ProviderPluginName)
-		if err != nil {
-			return nil, err
-		}
+	config := &plugin.ClientConfig{
+		HandshakeConfig:  tfplugin.Handshake,
+		Logger:           logging.NewProviderLogger(""),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		Managed:          true,
+		Cmd:              cmd,
+		// go-plugin would otherwise add our own environment after cmd.Env,
+		// overriding any variables that env sets again.
+		SkipHostEnv:      len(env) != 0,
+		AutoMTLS:         enableProviderAutoMTLS,
+		VersionedPlugins: tfplugin.VersionedPlugins,
+		SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", meta.Provider)),
+		SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", meta.Provider)),
+		GRPCDialOptions:  grpcOpts.dialOptions(),
+	}
 
-		protoVer := client.NegotiatedVersion()
-		p, err := initializeProviderInstance(raw, protoVer, client, schemaCache)
-		if errors.Is(err, errUnsupportedProtocolVersion) {
-			panic(err)
-		}
+	client := plugin.NewClient(config)
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, err
+	}
 
-		return p, err
+	raw, err := rpcClient.Dispense(tfplugin.ProviderPluginName)
+	if err != nil {
+		return nil, err
 	}
+
+	protoVer := client.NegotiatedVersion()
+	p, err := initializeProviderInstance(raw, protoVer, client, schemaCache, grpcOpts)
+	if errors.Is(err, errUnsupportedProtocolVersion) {
+		panic(err)
+	}
+
+	return p, err
 }
 
 // initializeProviderInstance uses the plugin dispensed by the RPC client, and initializes a plugin instance
 // per the protocol version
-func initializeProviderInstance(plugin interface{}, protoVer int, pluginClient *plugin.Client, schemaCache providers.SchemaCache) (providers.Interface, error) {
+func initializeProviderInstance(plugin interface{}, protoVer int, pluginClient *plugin.Client, schemaCache providers.SchemaCache, grpcOpts *ProviderGRPCOptions) (providers.Interface, error) {
+	// The schema call sets its own limit on the size of the response, so
+	// we must also raise that one if the options raise the limit.
+	var maxSchemaSize int
+	if grpcOpts != nil {
+		maxSchemaSize = grpcOpts.MaxRecvMsgSize
+	}
+
 	// store the client so that the plugin can kill the child process
 	switch protoVer {
 	case 5:
 		p := plugin.(*tfplugin.GRPCProvider)
 		p.PluginClient = pluginClient
 		p.SchemaCache = schemaCache
+		p.MaxSchemaRecvMsgSize = maxSchemaSize
 		return p, nil
 	case 6:
 		p := plugin.(*tfplugin6.GRPCProvider)
 		p.PluginClient = pluginClient
 		p.SchemaCache = schemaCache
+		p.MaxSchemaRecvMsgSize = maxSchemaSize
 		return p, nil
 	default:
 		return nil, errUnsupportedProtocolVersion
 	}
 }
 
-func devOverrideProviderFactory(provider addrs.Provider, localDir getproviders.PackageLocalDir) providers.Factory {
+func devOverrideProviderFactory(provider addrs.Provider, localDir getproviders.PackageLocalDir, helper *ProviderCredentialsHelper, grpcOpts *ProviderGRPCOptions) providers.Factory {
 	// A dev override is essentially a synthetic cache entry for our purposes
 	// here, so that's how we'll construct it. The providerFactory function
 	// doesn't actually care about the version, so we can leave it
 	// unspecified: overridden providers are not explicitly versioned.
 	log.Printf("[DEBUG] Provider %s is overridden to load from %s", provider, localDir)
-	return providerFactory(&providercache.CachedProvider{
+	cached := &providercache.CachedProvider{
 		Provider:   provider,
 		Version:    getproviders.UnspecifiedVersion,
 		PackageDir: string(localDir),
-	})
+	}
+	if helper != nil {
+		return credentialedProviderFactory(cached, helper, grpcOpts)
+	}
+	return providerFactory(cached, grpcOpts)
 }
 
 // unmanagedProviderFactory produces a provider factory that uses the passed
 // reattach information to connect to go-plugin processes that are already
 // running, and implements providers.Interface against it.
-func unmanagedProviderFactory(provider addrs.Provider, reattach *plugin.ReattachConfig) providers.Factory {
+func unmanagedProviderFactory(provider addrs.Provider, reattach *plugin.ReattachConfig, grpcOpts *ProviderGRPCOptions) providers.Factory {
 	schemaCache := providers.NewSchemaCache()
 
 	return func() (providers.Interface, error) {
@@ -456,6 +551,7 @@ func unmanagedProviderFactory(provider addrs.Provider, reattach *plugin.Reattach
 			Reattach:         reattach,
 			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", provider)),
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", provider)),
+			GRPCDialOptions:  grpcOpts.dialOptions(),
 		}
 
 		if reattach.ProtocolVersion == 0 {
@@ -496,7 +592,7 @@ func unmanagedProviderFactory(provider addrs.Provider, reattach *plugin.Reattach
 			protoVer = 5
 		}
 
-		return initializeProviderInstance(raw, protoVer, client, schemaCache)
+		return initializeProviderInstance(raw, protoVer, client, schemaCache, grpcOpts)
 	}
 }
 
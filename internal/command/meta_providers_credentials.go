@@ -0,0 +1,415 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/providercache"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// providerCredentialsExpiryWindow is how long before their expiry time we
+// replace a provider's credentials, so that requests already in flight don't
+// run with credentials that are about to expire.
+const providerCredentialsExpiryWindow = time.Minute
+
+// ProviderCredentialsHelper describes an external program that supplies
+// short-lived credentials to a provider, as configured in a
+// "provider_credentials_helper" block in the CLI configuration.
+//
+// The program is run with the given arguments followed by the provider's
+// source address, and must print a JSON object to its stdout with an "env"
+// property giving the environment variables to set for the provider and
+// an optional "expires_at" property giving the time, in RFC 3339 format,
+// at which those credentials expire.
+type ProviderCredentialsHelper struct {
+	Command string
+	Args    []string
+}
+
+// providerCredentials is the result of running a ProviderCredentialsHelper.
+type providerCredentials struct {
+	Env       map[string]string `json:"env"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// environ returns the credentials as environment variable assignments for
+// a provider's child process.
+func (c *providerCredentials) environ() []string {
+	ret := make([]string, 0, len(c.Env))
+	for name, value := range c.Env {
+		ret = append(ret, name+"="+value)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// fetch runs the helper program to obtain new credentials for the given
+// provider.
+func (h *ProviderCredentialsHelper) fetch(ctx context.Context, provider addrs.Provider) (*providerCredentials, error) {
+	args := make([]string, len(h.Args), len(h.Args)+1)
+	copy(args, h.Args)
+	args = append(args, provider.String())
+
+	outBuf := bytes.Buffer{}
+	errBuf := bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, h.Command, args...)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	err := cmd.Run()
+	if _, isExitErr := err.(*exec.ExitError); isExitErr {
+		errText := errBuf.String()
+		if errText == "" {
+			// Shouldn't happen for a well-behaved helper program
+			return nil, fmt.Errorf("error in %s, but it produced no error message", h.Command)
+		}
+		return nil, fmt.Errorf("error in %s: %s", h.Command, errText)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", h.Command, err)
+	}
+
+	var creds providerCredentials
+	if err := json.Unmarshal(outBuf.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("malformed output from %s: %w", h.Command, err)
+	}
+	return &creds, nil
+}
+
+// credentialedProviderFactory produces a provider factory like
+// providerFactory, but which starts the provider with credentials from the
+// given helper program in its environment and restarts it with new
+// credentials whenever they are about to expire.
+func credentialedProviderFactory(meta *providercache.CachedProvider, helper *ProviderCredentialsHelper, grpcOpts *ProviderGRPCOptions) providers.Factory {
+	schemaCache := providers.NewSchemaCache()
+
+	return func() (providers.Interface, error) {
+		p := &credentialedProvider{
+			fetch: func(ctx context.Context) (*providerCredentials, error) {
+				return helper.fetch(ctx, meta.Provider)
+			},
+			start: func(env []string) (providers.Interface, error) {
+				return startProvider(meta, schemaCache, env, grpcOpts)
+			},
+			now: time.Now,
+		}
+		if err := p.refreshLocked(context.Background()); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// credentialedProvider is a providers.Interface that replaces the provider
+// process it delegates to whenever that process's credentials are about to
+// expire.
+//
+// A replacement process is configured with the same request as the one it
+// replaces, so it can continue from where the other left off. Ephemeral
+// resources opened by the replaced process are not carried over.
+type credentialedProvider struct {
+	fetch func(context.Context) (*providerCredentials, error)
+	start func(env []string) (providers.Interface, error)
+	now   func() time.Time
+
+	// mu is held for reading for the duration of each call to the provider,
+	// and for writing while replacing it.
+	mu        sync.RWMutex
+	expires   time.Time
+	configure *providers.ConfigureProviderRequest
+
+	// liveMu guards provider separately from mu, so that Stop can reach the
+	// current process even while a replacement is waiting for calls to
+	// finish.
+	liveMu   sync.Mutex
+	provider providers.Interface
+}
+
+var _ providers.Interface = (*credentialedProvider)(nil)
+
+// acquire returns the provider to make a call to, replacing it first if its
+// credentials are about to expire. The caller must call the returned
+// function once the call is complete.
+func (p *credentialedProvider) acquire(ctx context.Context) (providers.Interface, func(), tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	p.mu.RLock()
+	if !p.expiringLocked() {
+		return p.provider, p.mu.RUnlock, diags
+	}
+	p.mu.RUnlock()
+
+	p.mu.Lock()
+	if p.expiringLocked() {
+		if err := p.refreshLocked(ctx); err != nil {
+			p.mu.Unlock()
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to refresh provider credentials",
+				fmt.Sprintf("The provider's credentials expired and could not be replaced: %s.", err),
+			))
+			return nil, nil, diags
+		}
+	}
+	// We just replaced the provider, so we keep the lock we already hold
+	// for this one call rather than risk seeing it expire again.
+	return p.provider, p.mu.Unlock, diags
+}
+
+func (p *credentialedProvider) expiringLocked() bool {
+	return !p.expires.IsZero() && !p.now().Add(providerCredentialsExpiryWindow).Before(p.expires)
+}
+
+// refreshLocked obtains new credentials and starts a new provider process
+// with them, replacing the current one. The caller must hold mu for writing.
+func (p *credentialedProvider) refreshLocked(ctx context.Context) error {
+	creds, err := p.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if !creds.ExpiresAt.IsZero() && !creds.ExpiresAt.After(p.now()) {
+		return errors.New("the credentials helper returned credentials that have already expired")
+	}
+
+	log.Printf("[DEBUG] Starting provider with credentials that expire at %s", creds.ExpiresAt)
+	next, err := p.start(creds.environ())
+	if err != nil {
+		return err
+	}
+	if p.configure != nil {
+		resp := next.ConfigureProvider(ctx, *p.configure)
+		if resp.Diagnostics.HasErrors() {
+			_ = next.Close(ctx)
+			return fmt.Errorf("failed to configure the restarted provider: %w", resp.Diagnostics.Err())
+		}
+	}
+
+	p.liveMu.Lock()
+	prev := p.provider
+	p.provider = next
+	p.liveMu.Unlock()
+	p.expires = creds.ExpiresAt
+
+	if prev != nil {
+		if err := prev.Close(ctx); err != nil {
+			log.Printf("[WARN] Failed to close provider with expired credentials: %s", err)
+		}
+	}
+	return nil
+}
+
+func (p *credentialedProvider) GetProviderSchema(ctx context.Context) (resp providers.GetProviderSchemaResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.GetProviderSchema(ctx)
+}
+
+func (p *credentialedProvider) ValidateProviderConfig(ctx context.Context, req providers.ValidateProviderConfigRequest) (resp providers.ValidateProviderConfigResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ValidateProviderConfig(ctx, req)
+}
+
+func (p *credentialedProvider) ValidateResourceConfig(ctx context.Context, req providers.ValidateResourceConfigRequest) (resp providers.ValidateResourceConfigResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ValidateResourceConfig(ctx, req)
+}
+
+func (p *credentialedProvider) ValidateDataResourceConfig(ctx context.Context, req providers.ValidateDataResourceConfigRequest) (resp providers.ValidateDataResourceConfigResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ValidateDataResourceConfig(ctx, req)
+}
+
+func (p *credentialedProvider) ValidateEphemeralConfig(ctx context.Context, req providers.ValidateEphemeralConfigRequest) (resp providers.ValidateEphemeralConfigResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ValidateEphemeralConfig(ctx, req)
+}
+
+func (p *credentialedProvider) MoveResourceState(ctx context.Context, req providers.MoveResourceStateRequest) (resp providers.MoveResourceStateResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.MoveResourceState(ctx, req)
+}
+
+func (p *credentialedProvider) CallFunction(ctx context.Context, req providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Error = diags.Err()
+		return resp
+	}
+	defer release()
+	return provider.CallFunction(ctx, req)
+}
+
+func (p *credentialedProvider) ConfigureProvider(ctx context.Context, req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	resp = provider.ConfigureProvider(ctx, req)
+	if !resp.Diagnostics.HasErrors() {
+		// Other calls may also hold mu for reading, so we record the
+		// request under liveMu.
+		p.liveMu.Lock()
+		p.configure = &req
+		p.liveMu.Unlock()
+	}
+	return resp
+}
+
+func (p *credentialedProvider) Close(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+	return p.provider.Close(ctx)
+}
+
+func (p *credentialedProvider) Stop(ctx context.Context) error {
+	p.liveMu.Lock()
+	provider := p.provider
+	p.liveMu.Unlock()
+	return provider.Stop(ctx)
+}
+
+func (p *credentialedProvider) UpgradeResourceState(ctx context.Context, req providers.UpgradeResourceStateRequest) (resp providers.UpgradeResourceStateResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.UpgradeResourceState(ctx, req)
+}
+
+func (p *credentialedProvider) ReadResource(ctx context.Context, req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ReadResource(ctx, req)
+}
+
+func (p *credentialedProvider) PlanResourceChange(ctx context.Context, req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.PlanResourceChange(ctx, req)
+}
+
+func (p *credentialedProvider) ApplyResourceChange(ctx context.Context, req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ApplyResourceChange(ctx, req)
+}
+
+func (p *credentialedProvider) ImportResourceState(ctx context.Context, req providers.ImportResourceStateRequest) (resp providers.ImportResourceStateResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ImportResourceState(ctx, req)
+}
+
+func (p *credentialedProvider) ReadDataSource(ctx context.Context, req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.ReadDataSource(ctx, req)
+}
+
+func (p *credentialedProvider) OpenEphemeralResource(ctx context.Context, req providers.OpenEphemeralResourceRequest) (resp providers.OpenEphemeralResourceResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.OpenEphemeralResource(ctx, req)
+}
+
+func (p *credentialedProvider) RenewEphemeralResource(ctx context.Context, req providers.RenewEphemeralResourceRequest) (resp providers.RenewEphemeralResourceResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.RenewEphemeralResource(ctx, req)
+}
+
+func (p *credentialedProvider) CloseEphemeralResource(ctx context.Context, req providers.CloseEphemeralResourceRequest) (resp providers.CloseEphemeralResourceResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.CloseEphemeralResource(ctx, req)
+}
+
+func (p *credentialedProvider) GetFunctions(ctx context.Context) (resp providers.GetFunctionsResponse) {
+	provider, release, diags := p.acquire(ctx)
+	if diags.HasErrors() {
+		resp.Diagnostics = diags
+		return resp
+	}
+	defer release()
+	return provider.GetFunctions(ctx)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+)
+
+func TestProviderCredentialsHelper_fetch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper program is a shell script")
+	}
+
+	helper := &ProviderCredentialsHelper{
+		Command: "sh",
+		Args:    []string{"-c", `printf '{"env":{"PROVIDER":"%s","TOKEN":"abc"},"expires_at":"2026-01-02T03:04:05Z"}' "$1"`, "helper"},
+	}
+	got, err := helper.fetch(context.Background(), addrs.NewDefaultProvider("aws"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"PROVIDER=registry.opentofu.org/hashicorp/aws", "TOKEN=abc"}
+	if diff := cmp.Diff(want, got.environ()); diff != "" {
+		t.Errorf("wrong environment\n%s", diff)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !got.ExpiresAt.Equal(want) {
+		t.Errorf("wrong expiry time %s; want %s", got.ExpiresAt, want)
+	}
+
+	helper = &ProviderCredentialsHelper{
+		Command: "sh",
+		Args:    []string{"-c", "echo 'no credentials' >&2; exit 1", "helper"},
+	}
+	_, err = helper.fetch(context.Background(), addrs.NewDefaultProvider("aws"))
+	if err == nil || err.Error() != "error in sh: no credentials\n" {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestCredentialedProvider_refresh(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var started []*farseek.MockProvider
+	var envs [][]string
+
+	p := &credentialedProvider{
+		fetch: func(context.Context) (*providerCredentials, error) {
+			return &providerCredentials{
+				Env:       map[string]string{"TOKEN": now.Format(time.RFC3339)},
+				ExpiresAt: now.Add(time.Hour),
+			}, nil
+		},
+		start: func(env []string) (providers.Interface, error) {
+			provider := testProvider()
+			started = append(started, provider)
+			envs = append(envs, env)
+			return provider, nil
+		},
+		now: func() time.Time { return now },
+	}
+	if err := p.refreshLocked(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	config := cty.ObjectVal(map[string]cty.Value{"region": cty.StringVal("eu-west-1")})
+	if resp := p.ConfigureProvider(ctx, providers.ConfigureProviderRequest{Config: config}); resp.Diagnostics.HasErrors() {
+		t.Fatal(resp.Diagnostics.Err())
+	}
+	p.ReadResource(ctx, providers.ReadResourceRequest{TypeName: "test_instance"})
+	if len(started) != 1 {
+		t.Fatalf("started %d providers before the credentials expired; want 1", len(started))
+	}
+
+	// Once the credentials are close to expiring, the next call must go to
+	// a new provider configured in the same way as the first.
+	now = now.Add(time.Hour - time.Second)
+	p.ReadResource(ctx, providers.ReadResourceRequest{TypeName: "test_instance"})
+	if len(started) != 2 {
+		t.Fatalf("started %d providers after the credentials expired; want 2", len(started))
+	}
+	first, second := started[0], started[1]
+	if !first.CloseCalled {
+		t.Error("provider with expired credentials was not closed")
+	}
+	if !second.ConfigureProviderCalled || !second.ConfigureProviderRequest.Config.RawEquals(config) {
+		t.Error("new provider was not configured like the one it replaced")
+	}
+	if !second.ReadResourceCalled {
+		t.Error("call was not made to the new provider")
+	}
+	if diff := cmp.Diff([][]string{{"TOKEN=2026-01-01T12:00:00Z"}, {"TOKEN=2026-01-01T12:59:59Z"}}, envs); diff != "" {
+		t.Errorf("wrong provider environments\n%s", diff)
+	}
+}
+
+func TestCredentialedProvider_refreshExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := &credentialedProvider{
+		fetch: func(context.Context) (*providerCredentials, error) {
+			return &providerCredentials{ExpiresAt: now.Add(-time.Minute)}, nil
+		},
+		start: func([]string) (providers.Interface, error) {
+			t.Fatal("provider started with expired credentials")
+			return nil, nil
+		},
+		now: func() time.Time { return now },
+	}
+	if err := p.refreshLocked(context.Background()); err == nil {
+		t.Fatal("succeeded with expired credentials; want error")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ProviderGRPCOptions tune the gRPC connections to the provider plugins
+// that Farseek starts, as configured in the "provider_grpc" block of the
+// CLI configuration or its environment variables.
+//
+// A zero value in any field leaves the usual behavior for that setting.
+type ProviderGRPCOptions struct {
+	// MaxRecvMsgSize and MaxSendMsgSize are the largest messages, in bytes,
+	// that Farseek receives from and sends to providers.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// KeepaliveInterval is how long a connection may be idle before Farseek
+	// checks that the provider is still there, and KeepaliveTimeout is how
+	// long it waits for the provider to answer that check.
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+
+	// CallTimeout is how long each call to a provider may take.
+	CallTimeout time.Duration
+}
+
+// dialOptions returns the options for go-plugin to dial providers with.
+// They're applied after go-plugin's own options, so they take precedence.
+func (o *ProviderGRPCOptions) dialOptions() []grpc.DialOption {
+	if o == nil {
+		return nil
+	}
+	var ret []grpc.DialOption
+	if o.MaxRecvMsgSize != 0 {
+		ret = append(ret, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(o.MaxRecvMsgSize)))
+	}
+	if o.MaxSendMsgSize != 0 {
+		ret = append(ret, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(o.MaxSendMsgSize)))
+	}
+	if o.KeepaliveInterval != 0 || o.KeepaliveTimeout != 0 {
+		ret = append(ret, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    o.KeepaliveInterval,
+			Timeout: o.KeepaliveTimeout,
+			// Farseek can wait a long time between calls to a provider, such
+			// as while waiting for others, so we keep checking idle ones.
+			PermitWithoutStream: true,
+		}))
+	}
+	if o.CallTimeout != 0 {
+		ret = append(ret, grpc.WithChainUnaryInterceptor(providerCallTimeout(o.CallTimeout)))
+	}
+	return ret
+}
+
+// providerCallTimeout returns an interceptor that cancels each call to a
+// provider that takes longer than the given timeout.
+func providerCallTimeout(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestProviderGRPCOptions_dialOptions(t *testing.T) {
+	var opts *ProviderGRPCOptions
+	if got := opts.dialOptions(); got != nil {
+		t.Errorf("nil options produced %d dial options; want none", len(got))
+	}
+
+	opts = &ProviderGRPCOptions{}
+	if got := opts.dialOptions(); len(got) != 0 {
+		t.Errorf("empty options produced %d dial options; want none", len(got))
+	}
+
+	opts = &ProviderGRPCOptions{
+		MaxRecvMsgSize:    256 << 20,
+		MaxSendMsgSize:    128 << 20,
+		KeepaliveInterval: 30 * time.Second,
+		CallTimeout:       time.Minute,
+	}
+	if got, want := len(opts.dialOptions()), 4; got != want {
+		t.Errorf("produced %d dial options; want %d", got, want)
+	}
+}
+
+func TestProviderCallTimeout(t *testing.T) {
+	interceptor := providerCallTimeout(time.Millisecond)
+
+	err := interceptor(t.Context(), "/tfplugin6.Provider/ReadResource", nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("call has no deadline")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("wrong error %v; want %v", err, context.DeadlineExceeded)
+	}
+}
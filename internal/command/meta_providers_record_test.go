@@ -0,0 +1,55 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/providerrecord"
+)
+
+func TestMetaProviderFactories_replay(t *testing.T) {
+	t.Chdir(t.TempDir())
+	lockSrc := `
+provider "registry.opentofu.org/hashicorp/test" {
+  version = "1.0.0"
+}
+`
+	if err := os.WriteFile(dependencyLockFilename, []byte(lockSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	addr := addrs.NewDefaultProvider("test")
+
+	t.Run("not cached", func(t *testing.T) {
+		m := Meta{}
+		if _, err := m.providerFactories(); err == nil {
+			t.Fatal("succeeded; want error for the provider that isn't cached")
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		// A replayed provider doesn't need its package, so the same
+		// provider that isn't cached has a factory, which replays from the
+		// recording directory.
+		m := Meta{ProviderReplayer: providerrecord.NewReplayer(t.TempDir())}
+		factories, err := m.providerFactories()
+		if err != nil {
+			t.Fatal(err)
+		}
+		factory, ok := factories[addr]
+		if !ok {
+			t.Fatalf("no factory for %s", addr)
+		}
+		_, err = factory()
+		if err == nil || !strings.Contains(err.Error(), "there is no recording of "+addr.String()) {
+			t.Fatalf("wrong error %v", err)
+		}
+		if _, ok := factories[addrs.NewBuiltInProvider("terraform")]; !ok {
+			t.Error("no factory for the internal provider")
+		}
+	})
+}
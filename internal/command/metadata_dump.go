@@ -0,0 +1,122 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/command/jsonconfig"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonfunction"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonprovider"
+)
+
+// MetadataDumpCommand is a Command implementation that prints out, in a
+// single document, the provider schemas, functions, and module signatures
+// available to the configuration in the current directory.
+type MetadataDumpCommand struct {
+	Meta
+}
+
+// metadataDump is the document printed by the metadata dump command.
+type metadataDump struct {
+	FormatVersion   string                                     `json:"format_version"`
+	ProviderSchemas map[string]*jsonprovider.Provider          `json:"provider_schemas,omitempty"`
+	Functions       map[string]*jsonfunction.FunctionSignature `json:"function_signatures,omitempty"`
+	RootModule      *jsonconfig.ModuleSignature                `json:"root_module"`
+}
+
+func (c *MetadataDumpCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("metadata dump")
+	var jsonOutput bool
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The metadata dump command expects no positional arguments.\n")
+		return cli.RunResultHelp
+	}
+	if !jsonOutput {
+		c.Ui.Error(
+			"The `farseek metadata dump` command requires the `-json` flag.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	config, diags := c.loadConfig(ctx, ".")
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	schemas, schemaDiags := c.MaybeGetSchemas(ctx, nil, config)
+	diags = diags.Append(schemaDiags)
+	if schemaDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	functions, funcDiags := jsonfunction.MarshalSignatures(availableFunctions())
+	diags = diags.Append(funcDiags)
+	if funcDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	rootModule, err := jsonconfig.MarshalSignatures(config)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to marshal module signatures to json: %s", err))
+		return 1
+	}
+
+	dump := metadataDump{
+		FormatVersion: "1.0",
+		Functions:     functions,
+		RootModule:    rootModule,
+	}
+	if schemas != nil {
+		dump.ProviderSchemas = jsonprovider.MarshalForRenderer(schemas)
+	}
+	j, err := json.Marshal(dump)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to marshal metadata to json: %s", err))
+		return 1
+	}
+	c.Ui.Output(string(j))
+
+	return 0
+}
+
+func (c *MetadataDumpCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] metadata dump -json
+
+  Prints out a json document describing everything that can be used in the
+  configuration in the current directory: the schemas of its providers, the
+  signatures of the available functions, and the variables and outputs of
+  its modules.
+
+  The working directory must be initialized, because the provider schemas
+  come from the installed providers.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *MetadataDumpCommand) Synopsis() string {
+	return "Show provider schemas, functions, and module signatures"
+}
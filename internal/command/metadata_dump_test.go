@@ -0,0 +1,124 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+)
+
+func TestMetadataDump_error(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &MetadataDumpCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	// This test will always error because it's missing the -json flag
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("expected error, got:\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestMetadataDump_output(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("metadata-dump"), td)
+	t.Chdir(td)
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"ami": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	providerSource, closeSource := newMockProviderSource(t, map[string][]string{
+		"hashicorp/test": {"1.2.3"},
+	})
+	defer closeSource()
+	ui := new(cli.MockUi)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(p),
+		Ui:               ui,
+		ProviderSource:   providerSource,
+	}
+
+	ic := &InitCommand{Meta: m}
+	if code := ic.Run(nil); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+	ui.OutputWriter.Reset()
+
+	c := &MetadataDumpCommand{Meta: m}
+	if code := c.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\nstderr: %s", code, ui.ErrorWriter.String())
+	}
+
+	var got struct {
+		FormatVersion   string `json:"format_version"`
+		ProviderSchemas map[string]struct {
+			ResourceSchemas map[string]json.RawMessage `json:"resource_schemas"`
+		} `json:"provider_schemas"`
+		Functions  map[string]json.RawMessage `json:"function_signatures"`
+		RootModule json.RawMessage            `json:"root_module"`
+	}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.FormatVersion != "1.0" {
+		t.Errorf("wrong format version %q", got.FormatVersion)
+	}
+	if _, ok := got.ProviderSchemas["registry.opentofu.org/hashicorp/test"].ResourceSchemas["test_instance"]; !ok {
+		t.Errorf("missing schema for test_instance: %#v", got.ProviderSchemas)
+	}
+	if _, ok := got.Functions["max"]; !ok {
+		t.Error(`missing function signature for "max"`)
+	}
+
+	wantRootModule := `{
+		"variables": {
+			"region": {"type": "string", "description": "The region to deploy to.", "required": true}
+		},
+		"outputs": {
+			"vpc_id": {"description": "The ID of the VPC."}
+		},
+		"module_calls": {
+			"network": {
+				"source": "./network",
+				"module": {
+					"variables": {
+						"cidr": {"type": "string", "default": "10.0.0.0/8"}
+					},
+					"outputs": {
+						"vpc_id": {"sensitive": true}
+					}
+				}
+			}
+		}
+	}`
+	var gotRoot, wantRoot interface{}
+	if err := json.Unmarshal(got.RootModule, &gotRoot); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(wantRootModule), &wantRoot); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantRoot, gotRoot); diff != "" {
+		t.Errorf("wrong root module signature\n%s", diff)
+	}
+}
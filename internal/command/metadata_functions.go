@@ -57,17 +57,7 @@ func (c *MetadataFunctionsCommand) Run(args []string) int {
 		return 1
 	}
 
-	scope := &lang.Scope{}
-	funcs := scope.Functions()
-	filteredFuncs := make(map[string]function.Function)
-	for k, v := range funcs {
-		if isIgnoredFunction(k) {
-			continue
-		}
-		filteredFuncs[k] = v
-	}
-
-	jsonFunctions, marshalDiags := jsonfunction.Marshal(filteredFuncs)
+	jsonFunctions, marshalDiags := jsonfunction.Marshal(availableFunctions())
 	if marshalDiags.HasErrors() {
 		c.showDiagnostics(marshalDiags)
 		return 1
@@ -83,6 +73,21 @@ Usage: farseek [global options] metadata functions -json
   Prints out a json representation of the available function signatures.
 `
 
+// availableFunctions returns the built-in functions to describe to users,
+// leaving out the ones that are only kept for backward compatibility.
+func availableFunctions() map[string]function.Function {
+	scope := &lang.Scope{}
+	funcs := scope.Functions()
+	filteredFuncs := make(map[string]function.Function)
+	for k, v := range funcs {
+		if isIgnoredFunction(k) {
+			continue
+		}
+		filteredFuncs[k] = v
+	}
+	return filteredFuncs
+}
+
 func isIgnoredFunction(name string) bool {
 	funcAddr := addrs.ParseFunction(name).FullyQualified().String()
 	for _, i := range ignoredFunctions {
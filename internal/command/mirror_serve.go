@@ -0,0 +1,127 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/getproviders"
+)
+
+// MirrorServeCommand is a Command implementation that serves the providers
+// in a filesystem mirror directory using the provider network mirror
+// protocol.
+type MirrorServeCommand struct {
+	Meta
+}
+
+func (c *MirrorServeCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var dir, listen, tlsCert, tlsKey string
+	cmdFlags := c.Meta.defaultFlagSet("mirror-serve")
+	cmdFlags.StringVar(&dir, "dir", "", "dir")
+	cmdFlags.StringVar(&listen, "listen", ":8080", "listen")
+	cmdFlags.StringVar(&tlsCert, "tls-cert", "", "tls-cert")
+	cmdFlags.StringVar(&tlsKey, "tls-key", "", "tls-key")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The mirror-serve command expects no positional arguments.\n")
+		return cli.RunResultHelp
+	}
+	if dir == "" {
+		c.Ui.Error("The mirror-serve command requires the -dir option.\n")
+		return cli.RunResultHelp
+	}
+	if (tlsCert == "") != (tlsKey == "") {
+		c.Ui.Error("The -tls-cert and -tls-key options must be used together.\n")
+		return cli.RunResultHelp
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		c.Ui.Error(fmt.Sprintf("The mirror directory %s does not exist or is not a directory.", dir))
+		return 1
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to listen on %s: %s", listen, err))
+		return 1
+	}
+
+	srv := &http.Server{
+		Handler:           getproviders.NewFilesystemMirrorServer(dir),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	if tlsCert == "" {
+		c.Ui.Warn("Serving without TLS. Farseek only uses network mirrors over HTTPS, so clients must reach this server through a proxy that terminates TLS.")
+	}
+	c.Ui.Output(fmt.Sprintf("Farseek provider mirror for %s listening on %s", dir, ln.Addr()))
+
+	errCh := make(chan error, 1)
+	go func() {
+		if tlsCert != "" {
+			errCh <- srv.ServeTLS(ln, tlsCert, tlsKey)
+		} else {
+			errCh <- srv.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		c.Ui.Error(fmt.Sprintf("Mirror server stopped: %s", err))
+		return 1
+	case <-c.ShutdownCh:
+		c.Ui.Output("Interrupt received. Waiting for downloads in progress to finish...")
+		if err := srv.Shutdown(context.Background()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.Ui.Error(fmt.Sprintf("Failed to stop the mirror server: %s", err))
+			return 1
+		}
+		return 0
+	}
+}
+
+func (c *MirrorServeCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] mirror-serve -dir=DIR [options]
+
+  Serves the provider packages in a local mirror directory, such as one
+  populated by "farseek providers mirror", using the provider network
+  mirror protocol. Other Farseek installations can then install providers
+  from it by using a network_mirror block in their CLI configuration.
+
+  The directory uses the same layout as a filesystem_mirror. Packages in
+  the unpacked layout are served as zip archives. Providers added to the
+  directory are served without restarting.
+
+Options:
+
+  -dir=DIR           The mirror directory to serve. Required.
+
+  -listen=addr       The address to listen on. Defaults to ":8080".
+
+  -tls-cert=FILE     A TLS certificate file to serve HTTPS with. Farseek
+  -tls-key=FILE      only uses network mirrors over HTTPS, so without these
+                     options the server must be behind a proxy that
+                     terminates TLS.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *MirrorServeCommand) Synopsis() string {
+	return "Serve a provider mirror directory over the network"
+}
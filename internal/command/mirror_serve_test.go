@@ -0,0 +1,64 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestMirrorServe(t *testing.T) {
+	t.Run("shutdown", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		shutdownCh := make(chan struct{})
+		close(shutdownCh)
+		c := &MirrorServeCommand{
+			Meta: Meta{Ui: ui, ShutdownCh: shutdownCh},
+		}
+		code := c.Run([]string{"-dir", t.TempDir(), "-listen", "127.0.0.1:0"})
+		if code != 0 {
+			t.Fatalf("wrong exit code. expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+		}
+		if got := ui.OutputWriter.String(); !strings.Contains(got, "listening on 127.0.0.1:") {
+			t.Errorf("missing listen address from output, got:\n%s", got)
+		}
+		if got := ui.ErrorWriter.String(); !strings.Contains(got, "Serving without TLS") {
+			t.Errorf("missing TLS warning from output, got:\n%s", got)
+		}
+	})
+
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"missing dir": {
+			nil,
+			"requires the -dir option",
+		},
+		"nonexistent dir": {
+			[]string{"-dir", "does-not-exist"},
+			"does not exist or is not a directory",
+		},
+		"certificate without key": {
+			[]string{"-dir", ".", "-tls-cert", "cert.pem"},
+			"must be used together",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ui := new(cli.MockUi)
+			c := &MirrorServeCommand{
+				Meta: Meta{Ui: ui},
+			}
+			if code := c.Run(test.args); code == 0 {
+				t.Fatal("succeeded; want error")
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, test.want) {
+				t.Errorf("missing %q from output, got:\n%s", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+// ModulesCommand is a Command implementation that just shows help for
+// the subcommands nested below it.
+type ModulesCommand struct {
+	Meta
+}
+
+func (c *ModulesCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *ModulesCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] modules <subcommand> [options] [args]
+
+  This command has subcommands for inspecting the modules that the
+  configuration calls.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ModulesCommand) Synopsis() string {
+	return "Inspect the modules in the configuration"
+}
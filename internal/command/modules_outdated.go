@@ -0,0 +1,178 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hashicorp/go-version"
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/registry/response"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ModulesOutdatedCommand is a Command implementation that lists the module
+// calls whose registry has newer versions of the module than the installed
+// one.
+type ModulesOutdatedCommand struct {
+	Meta
+}
+
+func (c *ModulesOutdatedCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("modules outdated")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	configPath, err := modulePath(cmdFlags.Args())
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	config, configDiags := c.loadConfig(ctx, configPath)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	reg := c.registryClient(ctx)
+	// Several module calls often use the same module, so we ask the
+	// registry for its versions only once.
+	available := make(map[string]*response.ModuleProviderVersions)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tSOURCE\tCURRENT\tWANTED\tLATEST")
+	outdated := 0
+	children := config.AllModules()
+	slices.SortFunc(children, func(a, b *configs.Config) int {
+		return strings.Compare(a.Path.String(), b.Path.String())
+	})
+	for _, child := range children {
+		if child.Parent == nil || child.Version == nil {
+			continue
+		}
+		source, ok := child.SourceAddr.(addrs.ModuleSourceRegistry)
+		if !ok {
+			continue
+		}
+		call := child.Parent.Module.ModuleCalls[child.Path[len(child.Path)-1]]
+		if call == nil {
+			continue
+		}
+
+		key := source.Package.String()
+		versions, ok := available[key]
+		if !ok {
+			resp, err := reg.ModulePackageVersions(ctx, source.Package)
+			if err != nil || len(resp.Modules) == 0 {
+				if err == nil {
+					err = fmt.Errorf("the registry returned no versions")
+				}
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Warning,
+					"Failed to check for newer module versions",
+					fmt.Sprintf("Farseek could not retrieve the versions of %s, called by %s: %s.", source.Package, child.Path, err),
+				))
+			} else {
+				versions = resp.Modules[0]
+			}
+			available[key] = versions
+		}
+		if versions == nil {
+			continue
+		}
+
+		var wanted, latest *version.Version
+		deprecated := false
+		for _, mv := range versions.Versions {
+			v, err := version.NewVersion(mv.Version)
+			if err != nil || v.Prerelease() != "" {
+				// Prereleases are only ever selected exactly, so they're
+				// never newer versions to upgrade to.
+				continue
+			}
+			if v.Equal(child.Version) && mv.Deprecation != nil {
+				deprecated = true
+			}
+			if latest == nil || v.GreaterThan(latest) {
+				latest = v
+			}
+			if call.Version.Required.Check(v) && (wanted == nil || v.GreaterThan(wanted)) {
+				wanted = v
+			}
+		}
+		if latest == nil || !latest.GreaterThan(child.Version) {
+			continue
+		}
+
+		current := child.Version.String()
+		if deprecated {
+			current += " (deprecated)"
+		}
+		wantedStr := "-"
+		if wanted != nil {
+			wantedStr = wanted.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", child.Path, source.ForDisplay(), current, wantedStr, latest)
+		outdated++
+	}
+	w.Flush()
+
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	if outdated == 0 {
+		c.Ui.Output("All module calls use the latest versions of their modules.")
+		return 0
+	}
+	c.Ui.Output(strings.TrimSpace(buf.String()))
+	return 0
+}
+
+func (c *ModulesOutdatedCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("")
+}
+
+func (c *ModulesOutdatedCommand) AutocompleteFlags() complete.Flags {
+	return nil
+}
+
+func (c *ModulesOutdatedCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] modules outdated [DIR]
+
+  Lists the module calls that use a module from a module registry which has
+  newer versions than the installed one.
+
+  For each such call, this shows the installed version, the newest version
+  that the call's version constraint allows, which "farseek init -upgrade"
+  would install, and the newest version of the module. Installed versions
+  that the registry marks as deprecated are flagged.
+
+  Run "farseek init" first to install the modules.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ModulesOutdatedCommand) Synopsis() string {
+	return "List the module calls with newer module versions available"
+}
@@ -0,0 +1,40 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	registrytest "github.com/rafagsiqueira/farseek/internal/registry/test"
+)
+
+func TestModulesOutdated(t *testing.T) {
+	t.Chdir(testFixturePath("modules-outdated"))
+	server := registrytest.Registry()
+	defer server.Close()
+
+	ui := new(cli.MockUi)
+	c := &ModulesOutdatedCommand{
+		Meta: Meta{
+			Ui:       ui,
+			Services: registrytest.Disco(server),
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `
+MODULE             SOURCE                       CURRENT             WANTED  LATEST
+module.deprecated  deprecated/name/provider     1.0.0 (deprecated)  1.0.0   1.1.0
+module.old         test-versions/name/provider  1.2.1               1.2.2   2.2.0
+`
+	if diff := cmp.Diff(strings.TrimSpace(want), strings.TrimSpace(ui.OutputWriter.String())); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
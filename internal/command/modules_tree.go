@@ -0,0 +1,342 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/posener/complete"
+	"github.com/xlab/treeprint"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/engine/plugins"
+	"github.com/rafagsiqueira/farseek/internal/lang/eval"
+	"github.com/rafagsiqueira/farseek/internal/lang/exprs"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ModulesTreeCommand is a Command implementation that prints the tree of
+// module calls in the configuration, optionally expanded into the module
+// instances that a plan would enumerate.
+type ModulesTreeCommand struct {
+	Meta
+}
+
+func (c *ModulesTreeCommand) Run(args []string) int {
+	var expand bool
+
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("modules tree")
+	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.BoolVar(&expand, "expand", false, "expand")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	configPath, err := modulePath(cmdFlags.Args())
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	config, configDiags := c.loadConfig(ctx, configPath)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Expanding uses the experimental language runtime, which is available
+	// only where the backend would allow it too.
+	if expand && !c.AllowExperimentalFeatures {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Experimental runtime not available",
+			"The -expand option uses the experimental language runtime, which is only available in an experiments-enabled build of Farseek.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	printRoot := treeprint.New()
+	if !expand {
+		c.populateStaticTreeNode(printRoot, config)
+		c.showDiagnostics(diags)
+		c.Ui.Output("\nModules called by configuration:")
+		c.Ui.Output(printRoot.String())
+		return 0
+	}
+
+	instAddrs, expandDiags := c.expandModules(ctx, configPath, config)
+	diags = diags.Append(expandDiags)
+	if expandDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// The instances are in order, so each group of children stays in
+	// order too.
+	children := make(map[string][]addrs.ModuleInstance)
+	for _, addr := range instAddrs {
+		if addr.IsRoot() {
+			continue
+		}
+		parentKey := addr.Parent().String()
+		children[parentKey] = append(children[parentKey], addr)
+	}
+	c.populateExpandedTreeNode(printRoot, config, addrs.RootModuleInstance, children)
+
+	c.showDiagnostics(diags)
+	c.Ui.Output("\nModule instances in configuration:")
+	c.Ui.Output(printRoot.String())
+	return 0
+}
+
+// expandModules uses the language runtime's module compiler to decide the
+// instances of every module call in the given configuration.
+func (c *ModulesTreeCommand) expandModules(ctx context.Context, configPath string, config *configs.Config) ([]addrs.ModuleInstance, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	inputValues, moreDiags := c.rootInputValues(config)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	opts, err := c.contextOpts(ctx)
+	if err != nil {
+		diags = diags.Append(err)
+		return nil, diags
+	}
+	// Expanding the modules only needs the schemas of the providers, so
+	// none of them are configured.
+	plugins := plugins.NewRuntimePlugins(opts.Providers, opts.Provisioners)
+	defer func() {
+		if err := plugins.Close(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("[ERROR] plugin shutdown failed: %s", err)
+		}
+	}()
+
+	configInst, moreDiags := eval.NewConfigInstance(ctx, &eval.ConfigCall{
+		RootModuleSource: addrs.ModuleSourceLocal("."),
+		InputValues:      inputValues,
+		EvalContext: &eval.EvalContext{
+			RootModuleDir:      configPath,
+			OriginalWorkingDir: c.WorkingDir.OriginalWorkingDir(),
+			Modules:            configModules{config: config},
+			Providers:          plugins,
+			Provisioners:       plugins,
+		},
+		Parallelism: c.parallelism,
+	})
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	instAddrs, moreDiags := configInst.ExpandModules(ctx)
+	diags = diags.Append(moreDiags)
+	return instAddrs, diags
+}
+
+// rootInputValues returns the values of the root module's input variables,
+// from the -var and -var-file options and the default variables files.
+//
+// Unlike for a plan, required variables don't need to be set. Their values
+// are unknown, so that module calls whose instances depend on them show as
+// having an unknown number of instances.
+func (c *ModulesTreeCommand) rootInputValues(config *configs.Config) (exprs.Valuer, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	rawVals, moreDiags := c.collectVariableValues()
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+	vals, moreDiags := backend.ParseDeclaredVariableValues(rawVals, config.Module.Variables)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	attrs := make(map[string]cty.Value, len(config.Module.Variables))
+	for name, vc := range config.Module.Variables {
+		switch {
+		case vals[name] != nil:
+			attrs[name] = vals[name].Value
+		case vc.Required():
+			attrs[name] = cty.DynamicVal
+		}
+	}
+	return exprs.ConstantValuer(cty.ObjectVal(attrs)), diags
+}
+
+func (c *ModulesTreeCommand) populateStaticTreeNode(tree treeprint.Tree, config *configs.Config) {
+	for _, name := range slices.Sorted(maps.Keys(config.Children)) {
+		child := config.Children[name]
+		label := moduleCallLabel(name, child)
+		if call := config.Module.ModuleCalls[name]; call != nil {
+			switch {
+			case call.Count != nil:
+				label += " [count]"
+			case call.ForEach != nil:
+				label += " [for_each]"
+			case call.Enabled != nil:
+				label += " [enabled]"
+			}
+		}
+		branch := tree.AddBranch(label)
+		c.populateStaticTreeNode(branch, child)
+	}
+}
+
+func (c *ModulesTreeCommand) populateExpandedTreeNode(tree treeprint.Tree, config *configs.Config, instAddr addrs.ModuleInstance, children map[string][]addrs.ModuleInstance) {
+	for _, name := range slices.Sorted(maps.Keys(config.Children)) {
+		child := config.Children[name]
+		var insts []addrs.ModuleInstance
+		for _, addr := range children[instAddr.String()] {
+			if addr[len(addr)-1].Name == name {
+				insts = append(insts, addr)
+			}
+		}
+
+		label := moduleCallLabel(name, child)
+		call := config.Module.ModuleCalls[name]
+		if call == nil || (call.Count == nil && call.ForEach == nil && call.Enabled == nil) {
+			// A module call without any repetition has exactly one
+			// instance, which we show as the call itself.
+			branch := tree.AddBranch(label)
+			for _, addr := range insts {
+				c.populateExpandedTreeNode(branch, child, addr, children)
+			}
+			continue
+		}
+
+		branch := tree.AddBranch(label + " " + moduleInstancesCardinality(insts))
+		for _, addr := range insts {
+			instBranch := branch.AddBranch("module." + addr[len(addr)-1].String())
+			c.populateExpandedTreeNode(instBranch, child, addr, children)
+		}
+	}
+}
+
+// moduleCallLabel returns how the tree shows the module call with the given
+// name, which calls the given module.
+func moduleCallLabel(name string, child *configs.Config) string {
+	source := child.SourceAddr.String()
+	if child.Version != nil {
+		source += " " + child.Version.String()
+	}
+	return fmt.Sprintf("module.%s (%s)", name, source)
+}
+
+// moduleInstancesCardinality describes how many instances a module call with
+// the given instances has.
+func moduleInstancesCardinality(insts []addrs.ModuleInstance) string {
+	for _, addr := range insts {
+		if _, ok := addr[len(addr)-1].InstanceKey.(addrs.WildcardKey); ok {
+			return "[unknown number of instances]"
+		}
+	}
+	switch len(insts) {
+	case 0:
+		return "[no instances]"
+	case 1:
+		return "[1 instance]"
+	default:
+		return fmt.Sprintf("[%d instances]", len(insts))
+	}
+}
+
+// configModules is an implementation of [eval.ExternalModules] that returns
+// the modules of an already-loaded configuration, which includes those that
+// "farseek init" installed from remote sources.
+type configModules struct {
+	config *configs.Config
+}
+
+var _ eval.ExternalModules = configModules{}
+
+// ModuleConfig implements eval.ExternalModules.
+func (m configModules) ModuleConfig(ctx context.Context, source addrs.ModuleSource, allowedVersions versions.Set, forCall *addrs.AbsModuleCall) (eval.UncompiledModule, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if forCall == nil {
+		return eval.PrepareTofu2024Module(source, m.config.Module), diags
+	}
+	// The loaded configuration has one module for each module call,
+	// whichever instance of its parent calls it.
+	path := append(forCall.Module.Module(), forCall.Call.Name)
+	child := m.config.Descendent(path)
+	if child == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Module not installed",
+			fmt.Sprintf("The module called by %s is not installed. Run \"farseek init\" to install all modules required by this configuration.", forCall),
+		))
+		return nil, diags
+	}
+	return eval.PrepareTofu2024Module(child.SourceAddr, child.Module), diags
+}
+
+func (c *ModulesTreeCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("")
+}
+
+func (c *ModulesTreeCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-expand":   completePredictBoolean,
+		"-var":      complete.PredictAnything,
+		"-var-file": complete.PredictFiles("*.tfvars"),
+	}
+}
+
+func (c *ModulesTreeCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] modules tree [options] [DIR]
+
+  Prints the tree of module calls in the configuration, with the source
+  address and selected version of each module.
+
+  With -expand, Farseek also evaluates the configuration to find the
+  instances of each module call, as a plan would enumerate them, without
+  reading any state or configuring any providers. Calls whose count or
+  for_each depends on values that won't be known until apply, such as
+  resource attributes, are shown with an unknown number of instances.
+
+Options:
+
+  -expand             Show the instances of each module call. This uses the
+                      experimental language runtime.
+
+  -var 'foo=bar'      Set a value for one of the input variables in the root
+                      module of the configuration. Use this option more than
+                      once to set more than one variable. Required variables
+                      that aren't set are unknown.
+
+  -var-file=filename  Load variable values from the given file, in addition
+                      to the default files terraform.tfvars and *.auto.tfvars.
+                      Use this option more than once to include more than one
+                      variables file.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ModulesTreeCommand) Synopsis() string {
+	return "Show the tree of modules in the configuration"
+}
@@ -0,0 +1,90 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+)
+
+func TestModulesTree(t *testing.T) {
+	t.Chdir(testFixturePath("modules-tree"))
+
+	ui := new(cli.MockUi)
+	c := &ModulesTreeCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `
+Modules called by configuration:
+.
+├── module.app (./app) [count]
+├── module.network (./network) [for_each]
+│   └── module.subnet (./subnet)
+└── module.pending (./app) [count]
+`
+	if diff := cmp.Diff(strings.TrimSpace(want), strings.TrimSpace(ui.OutputWriter.String())); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
+
+func TestModulesTree_expand(t *testing.T) {
+	t.Chdir(testFixturePath("modules-tree"))
+
+	ui := new(cli.MockUi)
+	c := &ModulesTreeCommand{
+		Meta: Meta{
+			testingOverrides:          metaOverridesForProvider(applyFixtureProvider()),
+			Ui:                        ui,
+			AllowExperimentalFeatures: true,
+		},
+	}
+	if code := c.Run([]string{"-expand", "-var", `regions=["ap"]`}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	want := `
+Module instances in configuration:
+.
+├── module.app (./app) [2 instances]
+│   ├── module.app[0]
+│   └── module.app[1]
+├── module.network (./network) [1 instance]
+│   └── module.network["ap"]
+│       └── module.subnet (./subnet)
+└── module.pending (./app) [unknown number of instances]
+    └── module.pending[*]
+`
+	if diff := cmp.Diff(strings.TrimSpace(want), strings.TrimSpace(ui.OutputWriter.String())); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
+
+func TestModulesTree_expandNotAllowed(t *testing.T) {
+	t.Chdir(testFixturePath("modules-tree"))
+
+	ui := new(cli.MockUi)
+	c := &ModulesTreeCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-expand", "-var", `regions=["ap"]`}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+
+	if got, want := ui.ErrorWriter.String(), "Experimental runtime not available"; !strings.Contains(got, want) {
+		t.Errorf("error doesn't contain %q:\n%s", want, got)
+	}
+}
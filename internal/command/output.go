@@ -62,7 +62,14 @@ func (c *OutputCommand) Run(rawArgs []string) int {
 	}
 
 	// Render the view
-	viewDiags := view.Output(args.Name, outputs)
+	var viewDiags tfdiags.Diagnostics
+	if args.Serve != "" {
+		viewDiags = c.serveOutputs(args, view, outputs)
+	} else if args.Schema {
+		viewDiags = view.Schema(args.Name, outputs)
+	} else {
+		viewDiags = view.Output(args.Name, args.Path, outputs)
+	}
 	diags = diags.Append(viewDiags)
 
 	view.Diagnostics(diags)
@@ -131,13 +138,17 @@ func (c *OutputCommand) GatherVariables(args *arguments.Vars) {
 
 func (c *OutputCommand) Help() string {
 	helpText := `
-Usage: farseek [global options] output [options] [NAME]
+Usage: farseek [global options] output [options] [NAME[PATH]]
 
   Reads an output variable from a Farseek state file and prints
   the value. With no additional arguments, output will display all
   the outputs for the root module.  If NAME is not specified, all
   outputs are printed.
 
+  NAME may be followed by a path selecting a nested value within the
+  output, using the same attribute and index syntax as in expressions,
+  such as "instances[0].id".
+
 Options:
 
   -state=path        Path to the state file to read. Defaults to
@@ -157,6 +168,26 @@ Options:
 					 Use this with care when stdout is a terminal and when
 					 the output value might contain control characters.
 
+                     Give a path after the output name to print a nested
+                     value from an output with a complex type.
+
+  -schema            Together with -json, print a JSON Schema describing
+                     the JSON representation of the output values instead
+                     of the values themselves.
+
+  -serve=addr        Instead of printing the outputs, serve all of them as
+                     read-only JSON over HTTP at the given address, such as
+                     "127.0.0.1:8080", for other processes on this host to
+                     fetch. Without a host, the address is on the loopback
+                     interface. The state is read once, when the command
+                     starts.
+
+  -serve-duration=d  How long to serve the outputs for, such as "30m".
+                     Defaults to 10 minutes.
+
+  -serve-token=token If specified, requests to the -serve address must have
+                     an "Authorization: Bearer token" header.
+
   -show-sensitive    If specified, sensitive values will be displayed.
 
   -var 'foo=bar'     Set a value for one of the input variables in the root
@@ -0,0 +1,112 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// serveOutputs serves the given outputs over HTTP, as requested by the
+// -serve option, until the serve duration expires or Farseek is
+// interrupted.
+func (c *OutputCommand) serveOutputs(args *arguments.Output, view views.Output, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	// The outputs often contain secrets, so we listen only on the loopback
+	// interface unless a host is given explicitly.
+	addr := args.Serve
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to serve outputs",
+			fmt.Sprintf("Farseek could not listen on %s: %s.", args.Serve, err),
+		))
+	}
+
+	srv := &http.Server{
+		Handler:           &outputsServer{outputs: outputs, token: args.ServeToken},
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	view.Serving(fmt.Sprintf("http://%s/", ln.Addr()), time.Now().Add(args.ServeDuration))
+
+	select {
+	case err := <-errCh:
+		return diags.Append(fmt.Errorf("output server stopped: %w", err))
+	case <-time.After(args.ServeDuration):
+	case <-c.ShutdownCh:
+	}
+	if err := srv.Shutdown(context.Background()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		diags = diags.Append(fmt.Errorf("failed to stop the output server: %w", err))
+	}
+	return diags
+}
+
+// outputsServer is an http.Handler that serves root module outputs in the
+// same JSON format as "farseek output -json": all of the outputs at the
+// root path, or the value of a single output at its name.
+type outputsServer struct {
+	outputs map[string]*states.OutputValue
+
+	// token is the bearer token that requests must present, if not empty.
+	token string
+}
+
+func (s *outputsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeOutputsError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeOutputsError(w, http.StatusUnauthorized, "a valid bearer token is required")
+			return
+		}
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if _, ok := s.outputs[name]; name != "" && !ok {
+		writeOutputsError(w, http.StatusNotFound, fmt.Sprintf("output %q not found", name))
+		return
+	}
+	body, diags := views.MarshalOutputsJSON(name, nil, s.outputs)
+	if diags.HasErrors() {
+		log.Printf("[ERROR] Failed to serve outputs: %s", diags.Err())
+		writeOutputsError(w, http.StatusInternalServerError, "failed to encode outputs")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func writeOutputsError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,141 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestOutput_serve(t *testing.T) {
+	originalState := states.BuildState(func(s *states.SyncState) {
+		s.SetOutputValue(
+			addrs.OutputValue{Name: "foo"}.Absolute(addrs.RootModuleInstance),
+			cty.StringVal("bar"),
+			false,
+			"",
+		)
+	})
+	statePath := testStateFile(t, originalState)
+
+	view, done := testView(t)
+	c := &OutputCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-json",
+		"-serve=:0",
+		"-serve-duration=10ms",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: \n%s", output.Stderr())
+	}
+
+	var serving struct {
+		URL       string `json:"url"`
+		ExpiresAt string `json:"expires_at"`
+	}
+	if err := json.Unmarshal([]byte(output.Stdout()), &serving); err != nil {
+		t.Fatalf("output is not the expected JSON: %s\n%s", err, output.Stdout())
+	}
+	if !strings.HasPrefix(serving.URL, "http://127.0.0.1:") {
+		t.Errorf("outputs were not served on the loopback interface: %s", serving.URL)
+	}
+}
+
+func TestOutputsServer(t *testing.T) {
+	srv := httptest.NewServer(&outputsServer{
+		outputs: map[string]*states.OutputValue{
+			"foo": {Value: cty.StringVal("bar")},
+			"ids": {Value: cty.ListVal([]cty.Value{cty.StringVal("a")}), Sensitive: true},
+		},
+		token: "secret",
+	})
+	defer srv.Close()
+
+	get := func(t *testing.T, method, path, token string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	tests := map[string]struct {
+		method, path, token string
+		wantStatus          int
+		wantBody            string
+	}{
+		"all outputs": {
+			http.MethodGet, "/", "secret",
+			http.StatusOK,
+			"{\n  \"foo\": {\n    \"sensitive\": false,\n    \"type\": \"string\",\n    \"value\": \"bar\"\n  },\n  \"ids\": {\n    \"sensitive\": true,\n    \"type\": [\n      \"list\",\n      \"string\"\n    ],\n    \"value\": [\n      \"a\"\n    ]\n  }\n}",
+		},
+		"single output": {
+			http.MethodGet, "/foo", "secret",
+			http.StatusOK,
+			`"bar"`,
+		},
+		"missing output": {
+			http.MethodGet, "/baz", "secret",
+			http.StatusNotFound,
+			"{\"error\":\"output \\\"baz\\\" not found\"}\n",
+		},
+		"no token": {
+			http.MethodGet, "/foo", "",
+			http.StatusUnauthorized,
+			"{\"error\":\"a valid bearer token is required\"}\n",
+		},
+		"wrong token": {
+			http.MethodGet, "/foo", "guess",
+			http.StatusUnauthorized,
+			"{\"error\":\"a valid bearer token is required\"}\n",
+		},
+		"write method": {
+			http.MethodPost, "/foo", "secret",
+			http.StatusMethodNotAllowed,
+			"{\"error\":\"method not allowed\"}\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, body := get(t, test.method, test.path, test.token)
+			if status != test.wantStatus {
+				t.Errorf("wrong status %d; want %d", status, test.wantStatus)
+			}
+			if body != test.wantBody {
+				t.Errorf("wrong body\ngot:  %q\nwant: %q", body, test.wantBody)
+			}
+		})
+	}
+}
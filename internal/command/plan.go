@@ -10,12 +10,16 @@ package command
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 
+	"github.com/posener/complete"
+
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/backend/agent"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/views"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
@@ -29,7 +33,7 @@ type PlanCommand struct {
 	Meta
 }
 
-func (c *PlanCommand) Run(rawArgs []string) int {
+func (c *PlanCommand) Run(rawArgs []string) (code int) {
 	ctx := c.CommandContext()
 
 	// Parse and apply global view arguments
@@ -46,11 +50,28 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	args, diags := arguments.ParsePlan(rawArgs)
 
 	c.View.SetShowSensitive(args.ShowSensitive)
+	c.View.SetGroupBy(args.GroupBy)
+	c.View.SetQuiet(args.Quiet)
+
+	// When writing the plan to stdout, all other output goes to stderr.
+	var planOut io.Writer
+	if args.OutPath == stdinArg {
+		planOut = c.View.TakeStdout()
+	}
+
+	// Operations on an agent render the remote output with their own view.
+	if args.Agent != "" && !diags.HasErrors() {
+		return c.runAgent(ctx, args, rawArgs, planOut)
+	}
 
 	// Instantiate the view, even if there are flag errors, so that we render
 	// diagnostics according to the desired view
 	view := views.NewPlan(args.ViewType, c.View)
 
+	// With -input=strict, the prompts that were needed are reported together
+	// once the command is done, whichever way it ends.
+	defer func() { code = c.reportStrictInput(view, code) }()
+
 	if diags.HasErrors() {
 		view.Diagnostics(diags)
 		view.HelpPrompt()
@@ -69,6 +90,7 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	// operation, but there is no clear path to pass this value down, so we
 	// continue to mutate the Meta object state for now.
 	c.Meta.input = args.InputEnabled
+	c.Meta.inputStrict = args.InputStrict
 
 	// FIXME: the -parallelism flag is used to control the concurrency of
 	// Farseek operations. At the moment, this value is used both to
@@ -78,7 +100,18 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
 
+	// FIXME: the -operation-timeout flags are only meaningful to
+	// RunOperation, which is shared by several commands, so we also carry
+	// these on the Meta object for now.
+	c.Meta.operationTimeout = args.Operation.Timeout
+	c.Meta.operationTimeoutGrace = args.Operation.TimeoutGrace
+
 	diags = diags.Append(c.providerDevOverrideRuntimeWarnings())
+	diags = diags.Append(c.providerDevOverrideStrictErrors(args.Operation.AllowDevOverrides))
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
 
 	// Inject variables from args into meta for static evaluation
 	c.GatherVariables(args.Vars)
@@ -99,7 +132,6 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
-	// Build the operation request
 	// Build the operation request
 	opReq, opDiags := c.OperationRequest(ctx, be, view, args.ViewType, args.Operation, args.OutPath, args.GenerateConfigPath, enc)
 	diags = diags.Append(opDiags)
@@ -107,15 +139,33 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		view.Diagnostics(diags)
 		return 1
 	}
+	opReq.PlanOutWriter = planOut
+	opReq.PlanOutCompress = args.CompressPlan
+
+	opReq.TagPolicy, opDiags = c.loadTagPolicy(args.TagPolicy)
+	diags = diags.Append(opDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+	opReq.PlanLimits = c.planLimits(args.Limits)
+	opReq.AllowExceedingLimits = args.Limits.AllowExceeding
+	opReq.ModuleDeprecations, opDiags = c.moduleDeprecations()
+	diags = diags.Append(opDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
 
 	// Check if we are in a Farseek-managed project (Git repo or has .farseek_sha)
+	dir := c.discoveryDir()
 	isGit := false
-	if _, err := farseek.Discovery.GetCurrentSHA("."); err == nil {
+	if _, err := farseek.Discovery.GetCurrentSHA(dir); err == nil {
 		isGit = true
 	}
-	sha, err := farseek.ReadSHA(".")
+	sha, err := farseek.ReadSHA(dir)
 	if err != nil {
-		diags = diags.Append(fmt.Errorf("Farseek error reading SHA: %w", err))
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading SHA", err.Error()), tfdiags.CodeSHAReadFailed))
 		view.Diagnostics(diags)
 		return 1
 	}
@@ -128,6 +178,25 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		hasSHA = false
 	}
 
+	// A range of commits replaces the recorded SHA and the current
+	// configuration, and needs the git history to read them from.
+	inRange := args.FromSHA != "" || args.ToSHA != ""
+	if inRange {
+		if !isGit {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Not a git repository",
+				"The -from-sha and -to-sha options compare two commits, so Farseek can only use them in a git repository.",
+			))
+			view.Diagnostics(diags)
+			return 1
+		}
+		if args.FromSHA != "" {
+			sha = args.FromSHA
+			hasSHA = true
+		}
+	}
+
 	// FARSEEK: Selective Polling based on Git Drift
 	if isGit || hasSHA {
 		opReq.FarseekMode = true
@@ -137,9 +206,21 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 			log.Printf("[INFO] Farseek: Using base SHA: %s", sha)
 		}
 
-		changed, err := farseek.Discovery.DiscoverChangedResources(".", sha, args.Uncommitted)
+		var changed []farseek.DiscoveredResource
+		if args.ToSHA != "" {
+			log.Printf("[INFO] Farseek: Planning the changes from %q to %q", sha, args.ToSHA)
+			changed, err = farseek.Discovery.DiscoverChangedResourcesBetween(dir, sha, args.ToSHA)
+		} else {
+			changed, err = farseek.Discovery.DiscoverChangedResources(dir, sha, args.Uncommitted)
+		}
 		if err != nil {
-			diags = diags.Append(fmt.Errorf("Farseek error discovering changed resources: %w", err))
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error discovering changed resources", err.Error()), tfdiags.CodeDiscoveryFailed))
+			view.Diagnostics(diags)
+			return 1
+		}
+		changed, ignoreDiags := c.withoutIgnored(changed)
+		diags = diags.Append(ignoreDiags)
+		if ignoreDiags.HasErrors() {
 			view.Diagnostics(diags)
 			return 1
 		}
@@ -162,10 +243,15 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 			view.Diagnostics(diags)
 			fmt.Println("No changes. Your infrastructure matches the configuration.")
 
-			// Update .farseek_sha so the next run also sees no changes
-			headSHA, err := farseek.Discovery.GetCurrentSHA(".")
+			// Update .farseek_sha so the next run also sees no changes. A
+			// range of commits says nothing about what was applied, so we
+			// leave it alone then.
+			if inRange {
+				return 0
+			}
+			headSHA, err := farseek.Discovery.GetCurrentSHA(dir)
 			if err == nil && headSHA != "" {
-				if err := farseek.WriteSHA(".", headSHA); err != nil {
+				if err := farseek.WriteSHA(dir, headSHA); err != nil {
 					// log it but don't fail
 				}
 			}
@@ -178,6 +264,27 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		}
 	}
 
+	// Plan the configuration at the end of the range, which we export to a
+	// temporary directory. Discovery, and the history it reads, stays in
+	// the working directory, along with the modules and providers that it
+	// installed.
+	if args.ToSHA != "" {
+		configDir, err := os.MkdirTemp("", "farseek-config-")
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to create a directory for the configuration at %s: %w", args.ToSHA, err))
+			view.Diagnostics(diags)
+			return 1
+		}
+		defer os.RemoveAll(configDir)
+		if err := farseek.Discovery.ExportConfigAtSHA(dir, args.ToSHA, configDir); err != nil {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading the configuration", fmt.Sprintf("Failed to read the configuration at %s: %s.", args.ToSHA, err)), tfdiags.CodeDiscoveryFailed))
+			view.Diagnostics(diags)
+			return 1
+		}
+		opReq.ConfigDir = configDir
+		opReq.DiscoveryDir = dir
+	}
+
 	// Before we delegate to the backend, we'll print any warning diagnostics
 	// we've accumulated here, since the backend will start fresh with its own
 	// diagnostics.
@@ -247,6 +354,11 @@ func (c *PlanCommand) OperationRequest(
 	opReq.PlanOutPath = planOutPath
 	opReq.GenerateConfigOut = generateConfigOut
 	opReq.ForceReplace = args.ForceReplace
+	opReq.ForceReplaceReasons = args.ForceReplaceReasons
+	opReq.RefreshProviders = args.RefreshProviders
+	opReq.DataSourceCache = c.dataSourceCache(args)
+	opReq.Runtime = args.Runtime
+	opReq.CompareRuntimes = args.CompareRuntimes
 	opReq.Type = backend.OperationTypePlan
 	opReq.View = view.Operation()
 
@@ -266,6 +378,42 @@ func (c *PlanCommand) OperationRequest(
 	return opReq, diags
 }
 
+// runAgent runs the plan on an agent instead of locally, saving the
+// resulting plan locally if requested. If planOut is not nil, the plan is
+// written there instead of to a file.
+func (c *PlanCommand) runAgent(ctx context.Context, args *arguments.Plan, rawArgs []string, planOut io.Writer) int {
+	view := views.NewAgent(args.ViewType, c.View)
+
+	req := &agent.Request{
+		Operation: agent.OperationPlan,
+		Args:      agentArgs(rawArgs),
+	}
+	result, diags := c.runAgentOperation(ctx, args.Agent, req, &agentRun{view: view})
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+
+	if args.OutPath != "" && len(result.PlanFile) != 0 {
+		var err error
+		if planOut != nil {
+			_, err = planOut.Write(result.PlanFile)
+		} else {
+			err = os.WriteFile(args.OutPath, result.PlanFile, 0644)
+		}
+		if err != nil {
+			diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to write plan file",
+				fmt.Sprintf("The plan created by the agent could not be saved to %s: %s.", args.OutPath, err),
+			), tfdiags.CodePlanFileWriteFailed))
+			view.Diagnostics(diags)
+			return 1
+		}
+	}
+	return result.ExitCode
+}
+
 func (c *PlanCommand) GatherVariables(args *arguments.Vars) {
 	// FIXME the arguments package currently trivially gathers variable related
 	// arguments in a heterogeneous slice, in order to minimize the number of
@@ -283,6 +431,27 @@ func (c *PlanCommand) GatherVariables(args *arguments.Vars) {
 	c.Meta.variableArgs = rawFlags{items: &items}
 }
 
+func (c *PlanCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *PlanCommand) AutocompleteFlags() complete.Flags {
+	flags := c.completeOperationFlags(c.CommandContext())
+	flags["-detailed-exitcode"] = complete.PredictNothing
+	flags["-out"] = complete.PredictFiles("*")
+	flags["-compress-plan"] = complete.PredictNothing
+	flags["-generate-config-out"] = complete.PredictFiles("*.tf")
+	flags["-tag-policy"] = complete.PredictFiles("*.hcl")
+	flags["-max-create"] = complete.PredictAnything
+	flags["-max-update"] = complete.PredictAnything
+	flags["-max-destroy"] = complete.PredictAnything
+	flags["-allow-exceeding-limits"] = complete.PredictNothing
+	flags["-allow-dev-overrides"] = complete.PredictNothing
+	flags["-group-by"] = complete.PredictSet("module", "provider", "action")
+	flags["-quiet"] = complete.PredictNothing
+	return flags
+}
+
 func (c *PlanCommand) Help() string {
 	helpText := `
 Usage: farseek [global options] plan [options]
@@ -315,13 +484,30 @@ Plan Customization Options:
                           planning against a stale record of the remote system
                           state.
 
+  -refresh-scope=provider=name
+                          Only check for external changes to the remote
+                          objects of the given provider, identified by its
+                          local name or source address. You can use this
+                          option multiple times to refresh the objects of
+                          more than one provider.
+
   -replace=resource       Force replacement of a particular resource instance
                           using its resource address. If the plan would've
                           otherwise produced an update or no-op action for this
                           instance, Farseek will plan to replace it instead.
                           You can use this option multiple times to replace
-                          more than one object.
+                          more than one object. Add a reason after a colon,
+                          as in -replace='aws_instance.a:kernel upgrade', to
+                          show it with the replacement and save it in the
+                          plan.
 
+  -from-sha=sha           Discover the changes made since the given commit,
+                          instead of since the recorded commit.
+
+  -to-sha=sha             Plan the configuration at the given commit, and
+                          discover the changes made up to it, instead of the
+                          current configuration. Modules and providers are
+                          still those installed in the working directory.
 
   -var 'foo=bar'          Set a value for one of the input variables in the
                           root module of the configuration. Use this option
@@ -334,6 +520,10 @@ Plan Customization Options:
 
 Other Options:
 
+  -agent=url                   Run the plan on the agent at the given URL,
+                               started with "farseek agent", instead of
+                               locally.
+
   -compact-warnings            If Farseek produces any warnings that are not
                                accompanied by errors, shows them in a more
                                compact form that includes only the summary
@@ -346,6 +536,9 @@ Other Options:
   -consolidate-errors          If Farseek produces any errors, attempt to
                                consolidate similar messages into a single item.
 
+  -suppress-warning=CODE       Leave out warnings with the given code or
+                               summary. Can be used multiple times.
+
   -detailed-exitcode           Return detailed exit codes when the command
                                exits. The detailed exit codes are:
                                  0 - Succeeded but no changes proposed
@@ -360,9 +553,19 @@ Other Options:
                                Farseek may still attempt to write
                                configuration if planning fails with an error.
 
+  -group-by=module             Group the resource changes in the plan under a
+                               heading for each module, with the number of
+                               resources to add, change and destroy in it.
+                               Use "provider" or "action" to group them by
+                               provider or by action instead.
+
   -input=false                 Disable prompting for required input variables
                                that are not set some other way.
 
+  -input=strict                Don't prompt, and report every input that would
+                               have been prompted for in a single error, such
+                               as all the missing variables.
+
   -lock=false                  Don't hold a state lock during the operation.
                                This is dangerous if others might concurrently
                                run commands against the same workspace.
@@ -374,12 +577,48 @@ Other Options:
 
   -concise                     Disable progress-related messages.
 
+  -quiet                       Show only errors and the line that counts the
+                               planned changes, leaving out the progress
+                               messages, the warnings and the details of the
+                               plan.
+
   -out=path                    Write a plan file to the given path. This can be
-                               used as input to the "apply" command.
+                               used as input to the "apply" command. Use "-"
+                               to write the plan file to standard output, in
+                               which case all other output goes to standard
+                               error.
+
+  -compress-plan               Save the plan file given by -out in a compressed
+                               format, which is much smaller for large plans.
+                               The "apply" and "show" commands detect it
+                               automatically.
 
   -parallelism=n               Limit the number of concurrent operations.
                                Defaults to 10.
 
+  -runtime=name                Choose the language runtime that creates the
+                               plan: "legacy", or "experimental" in builds
+                               with experiments enabled. "compare" creates
+                               the plan with the legacy runtime, and also
+                               reports how the experimental runtime's plan
+                               differs from it.
+
+  -data-cache-ttl=duration     Reuse the results of data resources read by
+                               earlier plans within the given duration, such
+                               as "1h", unless their farseek_cache_ttl
+                               argument says otherwise.
+
+  -data-cache=false            Read every data resource again instead of
+                               using cached results.
+
+  -operation-timeout=duration  Stop the operation gracefully, as if interrupted,
+                               if it has not completed within the given
+                               duration, such as "30m". Defaults to no limit.
+
+  -operation-timeout-grace=30s How long to wait for the operation to stop
+                               after -operation-timeout has elapsed before
+                               cancelling it outright.
+
   -state=statefile             A legacy option used for the local backend only.
                                Refer to the local backend's documentation for
                                more information.
@@ -387,10 +626,35 @@ Other Options:
   -show-sensitive              If specified, sensitive values will not be
                                redacted in te UI output.
 
+  -tag-policy=path             Check the resources that the plan creates or
+                               updates against the tag policy file at the
+                               given path, instead of the one that the
+                               "tag_policy" CLI setting selects.
+
+  -max-create=n                Fail the plan if it creates more than n
+                               objects. -max-update and -max-destroy do the
+                               same for the objects that it updates and
+                               destroys, and a replacement counts as both.
+                               These override the "plan_limits" CLI setting.
+
+  -allow-exceeding-limits      Only warn when the plan exceeds one of the
+                               limits set by -max-create, -max-update,
+                               -max-destroy or the "plan_limits" CLI setting.
+
+  -allow-dev-overrides         Plan even if provider development overrides
+                               are in effect and the
+                               "strict_provider_dev_overrides" CLI setting
+                               is enabled.
+
   -json                        Produce output in a machine-readable JSON
                                format, suitable for use in text editor
                                integrations and other automated systems.
 
+  -json-protocol=N             Use version N of the machine-readable JSON
+                               output. Version 1 leaves out the message types
+                               that are specific to Farseek. Defaults to the
+                               latest version, 2.
+
   -deprecation=module:m        Specify what type of warnings are shown.
                                Accepted values for "m": all, local, none. 
                                Default: all. When "all" is selected, Farseek
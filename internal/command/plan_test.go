@@ -14,6 +14,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -24,9 +25,12 @@ import (
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/checks"
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/getproviders"
 	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
 	"github.com/rafagsiqueira/farseek/internal/providers"
@@ -192,6 +196,42 @@ func TestPlan_noState(t *testing.T) {
 	}
 }
 
+func TestPlan_strictProviderDevOverrides(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	t.Chdir(td)
+
+	newCommand := func(view *views.View) *PlanCommand {
+		return &PlanCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(planFixtureProvider()),
+				View:             view,
+				ProviderDevOverrides: map[addrs.Provider]getproviders.PackageLocalDir{
+					addrs.NewDefaultProvider("test"): getproviders.PackageLocalDir(td),
+				},
+				StrictProviderDevOverrides: true,
+			},
+		}
+	}
+
+	view, done := testView(t)
+	code := newCommand(view).Run(nil)
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\nstdout: %s", code, output.Stdout())
+	}
+	if got, want := output.Stderr(), "Provider development overrides are not allowed"; !strings.Contains(got, want) {
+		t.Fatalf("missing error %q in output:\n%s", want, got)
+	}
+
+	view, done = testView(t)
+	code = newCommand(view).Run([]string{"-allow-dev-overrides"})
+	output = done(t)
+	if code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\nstderr: %s", code, output.Stderr())
+	}
+}
+
 func TestPlan_noTestVars(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan-no-test-vars"), td)
@@ -298,6 +338,45 @@ func TestPlan_outPath(t *testing.T) {
 	testReadPlan(t, outPath) // will call t.Fatal itself if the file cannot be read
 }
 
+func TestPlan_outStdout(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	t.Chdir(td)
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	p.PlanResourceChangeResponse = &providers.PlanResourceChangeResponse{
+		PlannedState: cty.NullVal(cty.EmptyObject),
+	}
+
+	args := []string{
+		"-out=-",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	// Only the plan file itself is written to stdout.
+	planPath := filepath.Join(td, "test.plan")
+	if err := os.WriteFile(planPath, []byte(output.Stdout()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testReadPlan(t, planPath) // will call t.Fatal itself if the file cannot be read
+
+	if got, want := output.Stderr(), "farseek apply -"; !strings.Contains(got, want) {
+		t.Errorf("missing next step %q in stderr\n%s", want, got)
+	}
+}
+
 func TestPlan_outPathNoChange(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -745,6 +824,46 @@ func TestPlan_varsUnset(t *testing.T) {
 	}
 }
 
+func TestPlan_inputStrict(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-vars"), td)
+	t.Chdir(td)
+
+	p := planVarsFixtureProvider()
+	p.GetProviderSchemaResponse.Provider = providers.Schema{
+		Block: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"region": {Type: cty.String, Required: true, Description: "The region to use."},
+			},
+		},
+	}
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	// Strict input never prompts, so both the variable and the provider
+	// argument are reported together.
+	code := c.Run([]string{"-input=strict"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n\n%s", code, output.All())
+	}
+	got := output.Stderr()
+	for _, want := range []string{
+		"Missing input for 2 value(s)",
+		"- provider.test.region: The region to use.",
+		"- var.foo",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
 // This test adds a required argument to the test provider to validate
 // processing of user input:
 // https://github.com/hashicorp/terraform/issues/26035
@@ -1404,6 +1523,75 @@ func TestPlan_shutdown(t *testing.T) {
 	}
 }
 
+func TestPlan_operationTimeout(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply-shutdown"), td)
+	t.Chdir(td)
+
+	stopped := make(chan struct{})
+
+	p := testProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+			ShutdownCh:       make(chan struct{}),
+		},
+	}
+
+	var once sync.Once
+	p.StopFn = func() error {
+		once.Do(func() {
+			close(stopped)
+		})
+		return nil
+	}
+
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		// Block until the operation timeout has triggered the graceful
+		// stop, so that the plan cannot complete before the limit.
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+		}
+
+		s := req.ProposedNewState.AsValueMap()
+		s["ami"] = cty.StringVal("bar")
+		resp.PlannedState = cty.ObjectVal(s)
+		return
+	}
+
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"ami": {Type: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+
+	code := c.Run([]string{"-operation-timeout=100ms"})
+	output := done(t)
+	if code != 1 {
+		t.Errorf("wrong exit code %d; want 1\noutput:\n%s", code, output.Stdout())
+	}
+
+	select {
+	case <-stopped:
+	default:
+		t.Error("command not stopped")
+	}
+
+	if got, want := output.Stderr(), "Operation timed out"; !strings.Contains(got, want) {
+		t.Errorf("missing timeout error\n got: %s\nwant: %s", got, want)
+	}
+}
+
 func TestPlan_init_required(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -1500,6 +1688,75 @@ func TestPlan_replace(t *testing.T) {
 	}
 }
 
+func TestPlan_replaceReason(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-replace"), td)
+	t.Chdir(td)
+
+	originalState := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_instance.a"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"hello"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+	})
+	statePath := testStateFile(t, originalState)
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id": {Type: cty.String, Computed: true},
+					},
+				},
+			},
+		},
+	}
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+		return providers.PlanResourceChangeResponse{
+			PlannedState: req.ProposedNewState,
+		}
+	}
+
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-no-color",
+		"-out", "tfplan",
+		"-replace", "test_instance.a:kernel upgrade",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("wrong exit code %d\n\n%s", code, output.Stderr())
+	}
+
+	if got, want := output.Stdout(), "test_instance.a will be replaced, as requested\n  # (reason: kernel upgrade)"; !strings.Contains(got, want) {
+		t.Errorf("missing replace reason\ngot output:\n%s\n\nwant substring: %s", got, want)
+	}
+
+	plan := testReadPlan(t, "tfplan")
+	if got, want := plan.ForceReplaceReasons, map[string]string{"test_instance.a": "kernel upgrade"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong reasons in the saved plan\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
 // Verify that the parallelism flag allows no more than the desired number of
 // concurrent calls to PlanResourceChange.
 func TestPlan_parallelism(t *testing.T) {
@@ -1927,14 +2184,130 @@ func TestPlan_farseekDrift(t *testing.T) {
 			t.Errorf("expected exactly 1 resource (test_instance.foo) to be refreshed, but got %d: %v", len(readResources), readResources)
 		}
 	})
+
+	// Case 3: The changed resource was forgotten -> 0 ReadResource calls
+	t.Run("ignored", func(t *testing.T) {
+		changed := []farseek.DiscoveredResource{{Address: "test_instance.foo"}}
+		readResources := runFarseekTest(t, "plan-existing-state", changed, "test_instance.foo")
+		if len(readResources) > 0 {
+			t.Errorf("expected 0 resources to be refreshed if the changed resource is ignored, but got %d: %v", len(readResources), readResources)
+		}
+	})
+}
+
+func TestPlan_farseekIgnoreFile(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-existing-state"), td)
+	t.Chdir(td)
+	if err := os.WriteFile(filepath.Join(td, ".farseek_sha"), []byte("previous-sha"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(td, farseek.IgnoreFilename), []byte("test_instance.*\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FARSEEK_TEST_FORCE_MODE", "true")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = mockDiscoverer{resources: []farseek.DiscoveredResource{
+		{Address: "test_instance.foo", Filename: "main.tf"},
+	}}
+
+	code := c.Run(nil)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n%s", code, output.Stderr())
+	}
+	if p.ReadResourceCalled {
+		t.Errorf("ignored resource was refreshed")
+	}
+	if got, want := output.All(), "test_instance.foo, in main.tf (test_instance.*)"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+}
+
+func TestPlan_farseekRange(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-existing-state"), td)
+	t.Chdir(td)
+	t.Setenv("FARSEEK_TEST_FORCE_MODE", "true")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	discoverer := &rangeDiscoverer{mockDiscoverer: mockDiscoverer{resources: []farseek.DiscoveredResource{
+		{Address: "test_instance.foo", Filename: "main.tf", IsNew: true},
+	}}}
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = discoverer
+
+	code := c.Run([]string{"-from-sha=aaa", "-to-sha=bbb"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n%s", code, output.Stderr())
+	}
+	if got, want := discoverer.between, [2]string{"aaa", "bbb"}; got != want {
+		t.Errorf("wrong range %q; want %q", got, want)
+	}
+	if got, want := discoverer.exported, "bbb"; got != want {
+		t.Errorf("wrong exported configuration %q; want %q", got, want)
+	}
+	if got, want := output.Stdout(), "1 to add"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+
+	// Planning a range says nothing about what was applied.
+	if _, err := os.Stat(filepath.Join(td, ".farseek_sha")); !os.IsNotExist(err) {
+		t.Errorf("expected no .farseek_sha, got %v", err)
+	}
+}
+
+// rangeDiscoverer records the commits that a plan of a range of commits
+// asks for.
+type rangeDiscoverer struct {
+	mockDiscoverer
+	between  [2]string
+	exported string
+}
+
+func (m *rangeDiscoverer) DiscoverChangedResourcesBetween(dir, fromSHA, toSHA string) ([]farseek.DiscoveredResource, error) {
+	m.between = [2]string{fromSHA, toSHA}
+	return m.resources, nil
+}
+
+func (m *rangeDiscoverer) ExportConfigAtSHA(dir, sha, dest string) error {
+	m.exported = sha
+	return m.mockDiscoverer.ExportConfigAtSHA(dir, sha, dest)
 }
 
 // runFarseekTest is a helper for creative reuse of existing fixtures in Farseek mode.
-func runFarseekTest(t *testing.T, fixture string, changedResources []farseek.DiscoveredResource) map[string]bool {
+// Any ignored addresses are recorded as forgotten with "farseek state forget".
+func runFarseekTest(t *testing.T, fixture string, changedResources []farseek.DiscoveredResource, ignored ...string) map[string]bool {
 	// 1. Setup a temporary directory using the fixture
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath(fixture), td)
 	t.Chdir(td)
+	for _, addr := range ignored {
+		if _, err := farseek.AddIgnored(filepath.Join(td, DefaultDataDir, farseek.IgnoredFilename), addr); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	// Clean up existing state to ensure state-less mode is active
 	os.Remove(filepath.Join(td, "farseek.tfstate"))
@@ -1994,13 +2367,24 @@ func runFarseekTest(t *testing.T, fixture string, changedResources []farseek.Dis
 }
 
 type mockDiscoverer struct {
-	resources []farseek.DiscoveredResource
+	resources  []farseek.DiscoveredResource
+	unverified []farseek.UnverifiedCommit
+	branch     string
 }
 
 func (m mockDiscoverer) DiscoverChangedResources(dir, baseSHA string, includeUncommitted bool) ([]farseek.DiscoveredResource, error) {
 	return m.resources, nil
 }
 
+func (m mockDiscoverer) DiscoverChangedResourcesBetween(dir, fromSHA, toSHA string) ([]farseek.DiscoveredResource, error) {
+	return m.resources, nil
+}
+
+// ExportConfigAtSHA copies the current configuration, whatever the SHA.
+func (m mockDiscoverer) ExportConfigAtSHA(dir, sha, dest string) error {
+	return os.CopyFS(dest, os.DirFS(dir))
+}
+
 func (m mockDiscoverer) DiscoverAllResources(dir string, includeUncommitted bool) ([]farseek.DiscoveredResource, error) {
 	return m.resources, nil
 }
@@ -2009,6 +2393,22 @@ func (m mockDiscoverer) GetResourceAttributeFromSHA(dir, sha, filename, address,
 	return "mock-value", nil
 }
 
+func (m mockDiscoverer) GetResourceDependenciesFromSHA(dir, sha string) (map[string][]addrs.ConfigResource, error) {
+	return nil, nil
+}
+
+func (m mockDiscoverer) GetResourcesFromSHA(dir, sha string) (map[string]*configs.Resource, error) {
+	return nil, nil
+}
+
 func (m mockDiscoverer) GetCurrentSHA(dir string) (string, error) {
 	return "mock-sha", nil
 }
+
+func (m mockDiscoverer) GetCurrentBranch(dir string) (string, error) {
+	return m.branch, nil
+}
+
+func (m mockDiscoverer) VerifyCommits(dir, fromSHA, toSHA string, trustedKeys []string) ([]farseek.UnverifiedCommit, error) {
+	return m.unverified, nil
+}
@@ -0,0 +1,253 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonentities"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// PreflightCommand is a Command implementation that checks that each
+// provider the configuration uses can be configured with its credentials,
+// without planning any resources.
+type PreflightCommand struct {
+	Meta
+}
+
+func (c *PreflightCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("preflight")
+	c.Meta.varFlagSet(cmdFlags)
+	var jsonOutput bool
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The preflight command expects no arguments. To check the configuration in another directory, use the global -chdir option.\n")
+		return cli.RunResultHelp
+	}
+	configPath := c.Meta.normalizePath(".")
+
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	enc, encDiags := c.EncryptionFromPath(ctx, configPath)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	backendConfig, backendDiags := c.loadBackendConfig(ctx, configPath)
+	diags = diags.Append(backendDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	b, backendDiags := c.Backend(ctx, &BackendOpts{
+		Config: backendConfig,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	local, ok := b.(backend.Local)
+	if !ok {
+		c.showDiagnostics(diags)
+		c.Ui.Error(ErrUnsupportedLocalOp)
+		return 1
+	}
+
+	// Like the console, preflight only reads the configuration, and
+	// evaluates the variables that aren't set as unknown, which defers the
+	// providers whose configurations depend on them.
+	opReq := c.Operation(ctx, b, arguments.ViewHuman, enc)
+	opReq.ConfigDir = configPath
+	opReq.ConfigLoader, err = c.initConfigLoader()
+	opReq.AllowUnsetVariables = true
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	var moreDiags, callDiags tfdiags.Diagnostics
+	opReq.Variables, moreDiags = c.collectVariableValues()
+	opReq.RootCall, callDiags = c.rootModuleCall(ctx, opReq.ConfigDir)
+	diags = diags.Append(moreDiags).Append(callDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	lr, _, ctxDiags := local.LocalRun(ctx, opReq)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	defer func() {
+		diags := opReq.StateLocker.Unlock()
+		if diags.HasErrors() {
+			c.showDiagnostics(diags)
+		}
+	}()
+
+	evalOpts := &farseek.EvalOpts{}
+	if lr.PlanOpts != nil {
+		evalOpts.SetVariables = lr.PlanOpts.SetVariables
+	}
+	checks, moreDiags := lr.Core.Preflight(ctx, lr.Config, lr.InputState, evalOpts)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if jsonOutput {
+		return c.showJSONChecks(checks)
+	}
+	c.showDiagnostics(diags)
+	return c.showChecks(checks)
+}
+
+// showChecks renders the checks for humans, returning 1 if any failed.
+func (c *PreflightCommand) showChecks(checks []*farseek.ProviderCheck) int {
+	if len(checks) == 0 {
+		c.Ui.Output("The configuration uses no providers.")
+		return 0
+	}
+
+	status := 0
+	var diags tfdiags.Diagnostics
+	for _, check := range checks {
+		addr := check.Addr.InstanceString(check.Key)
+		switch check.Status {
+		case farseek.ProviderReady:
+			if check.Probe != "" {
+				c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[green]✓[reset] %s: [bold]ready[reset] (checked with %s)", addr, check.Probe)))
+			} else {
+				c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[green]✓[reset] %s: [bold]ready[reset] (configured)", addr)))
+			}
+		case farseek.ProviderDeferred:
+			c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[yellow]-[reset] %s: [bold]deferred[reset] (its configuration isn't known until apply)", addr)))
+		default:
+			c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[red]✗[reset] %s: [bold]failed[reset]", addr)))
+			status = 1
+		}
+		diags = diags.Append(check.Diagnostics)
+	}
+	if len(diags) > 0 {
+		c.Ui.Output("")
+		c.showDiagnostics(diags)
+	}
+	return status
+}
+
+func (c *PreflightCommand) showJSONChecks(checks []*farseek.ProviderCheck) int {
+	type jsonCheck struct {
+		Address     string                     `json:"address"`
+		Provider    string                     `json:"provider"`
+		Status      farseek.ProviderStatus     `json:"status"`
+		Probe       string                     `json:"probe,omitempty"`
+		Diagnostics []*jsonentities.Diagnostic `json:"diagnostics"`
+	}
+	type jsonOutput struct {
+		FormatVersion string      `json:"format_version"`
+		Ready         bool        `json:"ready"`
+		Providers     []jsonCheck `json:"providers"`
+	}
+
+	sources := c.configSources()
+	out := jsonOutput{
+		FormatVersion: "1.0",
+		Ready:         true,
+		Providers:     []jsonCheck{},
+	}
+	for _, check := range checks {
+		jc := jsonCheck{
+			Address:     check.Addr.InstanceString(check.Key),
+			Provider:    check.Addr.Provider.String(),
+			Status:      check.Status,
+			Probe:       check.Probe,
+			Diagnostics: []*jsonentities.Diagnostic{},
+		}
+		for _, diag := range check.Diagnostics {
+			jc.Diagnostics = append(jc.Diagnostics, jsonentities.NewDiagnostic(diag, sources))
+		}
+		if check.Status == farseek.ProviderFailed {
+			out.Ready = false
+		}
+		out.Providers = append(out.Providers, jc)
+	}
+	j, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		// Should never happen because we fully-control the input here
+		panic(err)
+	}
+	c.Ui.Output(string(j))
+	if !out.Ready {
+		return 1
+	}
+	return 0
+}
+
+func (c *PreflightCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] preflight [options]
+
+  Checks that each provider the configuration uses is ready, before a plan
+  that could fail at its first API call. Preflight configures each provider
+  instance with the current configuration, without planning any resources,
+  and then checks its credentials with a cheap call, such as reading the
+  aws_caller_identity data source of the AWS provider. The providers we
+  know no such call for are checked by configuring them.
+
+  Providers whose configuration depends on resources or data sources, or
+  on variables that aren't set, can't be checked until apply, and are
+  reported as deferred.
+
+  Preflight needs an initialized working directory. It exits with status 0
+  if no provider failed, and 1 otherwise.
+
+Options:
+
+  -json                  Produce output in a machine-readable JSON format.
+
+  -no-color              If specified, output won't contain any color.
+
+  -var 'foo=bar'         Set a value for one of the input variables in the
+                         root module of the configuration. Use this option
+                         more than once to set more than one variable.
+
+  -var-file=filename     Load variable values from the given file, in
+                         addition to the default files terraform.tfvars
+                         and *.auto.tfvars. Use this option more than once
+                         to include more than one variables file.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PreflightCommand) Synopsis() string {
+	return "Check that the providers can be configured with their credentials"
+}
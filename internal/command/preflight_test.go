@@ -0,0 +1,111 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestPreflight(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("preflight"), td)
+	t.Chdir(td)
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		Provider: providers.Schema{
+			Block: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"region": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id": {Type: cty.String, Computed: true},
+					},
+				},
+			},
+		},
+	}
+	p.ConfigureProviderFn = func(req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+		if req.Config.GetAttr("region").AsString() != "eu-west-1" {
+			resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(tfdiags.Error, "Invalid region", "The region doesn't exist."))
+		}
+		return resp
+	}
+
+	run := func(args ...string) (int, *cli.MockUi) {
+		ui := cli.NewMockUi()
+		view, _ := testView(t)
+		c := &PreflightCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				Ui:               ui,
+				View:             view,
+			},
+		}
+		return c.Run(args), ui
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		code, ui := run("-var", "region=eu-west-1")
+		if code != 0 {
+			t.Fatalf("wrong exit status %d\n%s", code, ui.ErrorWriter)
+		}
+		if got, want := ui.OutputWriter.String(), `provider["registry.opentofu.org/hashicorp/test"]: ready`; !strings.Contains(got, want) {
+			t.Errorf("wrong output\n got: %s\nwant: %s", got, want)
+		}
+		if p.PlanResourceChangeCalled {
+			t.Error("preflight should not plan resources")
+		}
+	})
+
+	t.Run("deferred", func(t *testing.T) {
+		code, ui := run()
+		if code != 0 {
+			t.Fatalf("wrong exit status %d\n%s", code, ui.ErrorWriter)
+		}
+		if got, want := ui.OutputWriter.String(), "deferred"; !strings.Contains(got, want) {
+			t.Errorf("wrong output\n got: %s\nwant: %s", got, want)
+		}
+	})
+
+	t.Run("failed json", func(t *testing.T) {
+		code, ui := run("-json", "-var", "region=mars-1")
+		if code != 1 {
+			t.Fatalf("wrong exit status %d\n%s", code, ui.ErrorWriter)
+		}
+		var got struct {
+			Ready     bool `json:"ready"`
+			Providers []struct {
+				Address     string `json:"address"`
+				Status      string `json:"status"`
+				Diagnostics []struct {
+					Summary string `json:"summary"`
+				} `json:"diagnostics"`
+			} `json:"providers"`
+		}
+		if err := json.Unmarshal(ui.OutputWriter.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON: %s\n%s", err, ui.OutputWriter)
+		}
+		if got.Ready || len(got.Providers) != 1 || got.Providers[0].Status != "failed" {
+			t.Fatalf("wrong result: %#v", got)
+		}
+		if diags := got.Providers[0].Diagnostics; len(diags) != 1 || diags[0].Summary != "Invalid region" {
+			t.Errorf("wrong diagnostics: %#v", diags)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/getproviders"
+	tfplugin "github.com/rafagsiqueira/farseek/internal/plugin"
+	tfplugin6 "github.com/rafagsiqueira/farseek/internal/plugin6"
+	"github.com/rafagsiqueira/farseek/internal/providercache"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+)
+
+// ProvidersDevStatusCommand is a Command implementation that reports on the
+// provider development overrides in the CLI configuration: where each
+// overridden provider's executable is, which plugin protocol it speaks and
+// whether its schema can be loaded.
+type ProvidersDevStatusCommand struct {
+	Meta
+}
+
+func (c *ProvidersDevStatusCommand) Synopsis() string {
+	return "Show the provider development overrides in effect"
+}
+
+func (c *ProvidersDevStatusCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("providers dev-status")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The providers dev-status command expects no positional arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	if len(c.ProviderDevOverrides) == 0 {
+		c.Ui.Output("There are no provider development overrides in effect.")
+		return 0
+	}
+
+	addrList := make([]addrs.Provider, 0, len(c.ProviderDevOverrides))
+	for addr := range c.ProviderDevOverrides {
+		addrList = append(addrList, addr)
+	}
+	slices.SortFunc(addrList, func(a, b addrs.Provider) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	failed := false
+	var buf strings.Builder
+	for _, addr := range addrList {
+		dir := c.ProviderDevOverrides[addr]
+		fmt.Fprintf(&buf, "%s\n", addr.ForDisplay())
+		fmt.Fprintf(&buf, "  Directory: %s\n", dir)
+
+		cached := &providercache.CachedProvider{
+			Provider:   addr,
+			Version:    getproviders.UnspecifiedVersion,
+			PackageDir: string(dir),
+		}
+		execFile, err := cached.ExecutableFile()
+		if err != nil {
+			fmt.Fprintf(&buf, "  Executable: %s\n\n", err)
+			failed = true
+			continue
+		}
+		fmt.Fprintf(&buf, "  Executable: %s\n", execFile)
+
+		factory := devOverrideProviderFactory(addr, dir, c.ProviderCredentialsHelpers[addr], c.ProviderGRPC)
+		if c.testingOverrides != nil {
+			factory = c.testingOverrides.Providers[addr]
+		}
+		provider, err := factory()
+		if err != nil {
+			fmt.Fprintf(&buf, "  Protocol: unknown\n  Schema: the provider failed to start: %s\n\n", err)
+			failed = true
+			continue
+		}
+		fmt.Fprintf(&buf, "  Protocol: %s\n", providerProtocolVersion(provider))
+
+		resp := provider.GetProviderSchema(ctx)
+		if resp.Diagnostics.HasErrors() {
+			fmt.Fprintf(&buf, "  Schema: failed to load: %s\n\n", resp.Diagnostics.Err())
+			failed = true
+		} else {
+			fmt.Fprintf(&buf, "  Schema: loaded (%d resource types, %d data sources)\n\n", len(resp.ResourceTypes), len(resp.DataSources))
+		}
+		_ = provider.Close(ctx)
+	}
+	if c.StrictProviderDevOverrides {
+		buf.WriteString("The \"strict_provider_dev_overrides\" CLI setting is enabled, so plans and\napplies refuse to run with these overrides unless -allow-dev-overrides is set.\n")
+	}
+	c.Ui.Output(strings.TrimSpace(buf.String()))
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// providerProtocolVersion returns the major version of the plugin protocol
+// that the given running provider speaks, for display.
+func providerProtocolVersion(provider providers.Interface) string {
+	switch provider.(type) {
+	case *tfplugin.GRPCProvider:
+		return "5"
+	case *tfplugin6.GRPCProvider:
+		return "6"
+	default:
+		return "unknown"
+	}
+}
+
+func (c *ProvidersDevStatusCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ProvidersDevStatusCommand) AutocompleteFlags() complete.Flags {
+	return nil
+}
+
+func (c *ProvidersDevStatusCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] providers dev-status
+
+  Shows the provider development overrides that the "dev_overrides" block of
+  the CLI configuration sets.
+
+  For each overridden provider, this shows the directory it's loaded from and
+  the executable found there, then starts the provider to show the plugin
+  protocol version it speaks and whether its schema can be loaded.
+
+  Exits with status 1 if any of the overridden providers can't be started or
+  its schema can't be loaded.
+`
+	return strings.TrimSpace(helpText)
+}
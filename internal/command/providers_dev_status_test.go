@@ -0,0 +1,89 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/getproviders"
+)
+
+func TestProvidersDevStatus(t *testing.T) {
+	dir := t.TempDir()
+	execFile := filepath.Join(dir, "terraform-provider-test")
+	if err := os.WriteFile(execFile, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &ProvidersDevStatusCommand{
+		Meta: Meta{
+			Ui:               ui,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			ProviderDevOverrides: map[addrs.Provider]getproviders.PackageLocalDir{
+				addrs.NewDefaultProvider("test"): getproviders.PackageLocalDir(dir),
+			},
+			StrictProviderDevOverrides: true,
+		},
+	}
+
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\n%s%s", code, ui.ErrorWriter.String(), ui.OutputWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"hashicorp/test",
+		"Executable: " + execFile,
+		"Protocol: unknown",
+		"Schema: loaded (0 resource types, 0 data sources)",
+		"-allow-dev-overrides",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestProvidersDevStatus_noExecutable(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &ProvidersDevStatusCommand{
+		Meta: Meta{
+			Ui:               ui,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			ProviderDevOverrides: map[addrs.Provider]getproviders.PackageLocalDir{
+				addrs.NewDefaultProvider("test"): getproviders.PackageLocalDir(t.TempDir()),
+			},
+		},
+	}
+
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "could not find executable file"; !strings.Contains(got, want) {
+		t.Errorf("output missing %q:\n%s", want, got)
+	}
+}
+
+func TestProvidersDevStatus_none(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &ProvidersDevStatusCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0", code)
+	}
+	if got, want := ui.OutputWriter.String(), "There are no provider development overrides in effect."; !strings.Contains(got, want) {
+		t.Errorf("output missing %q:\n%s", want, got)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/depsfile"
@@ -36,9 +38,18 @@ const (
 // is configured for normal provider installation.
 type ProvidersLockCommand struct {
 	Meta
+
+	// If true, then this command will become the "providers upgrade"
+	// command. It is just like "providers lock" but selects the newest
+	// versions of the providers that the configuration allows, instead of
+	// keeping the versions already recorded in the lock file.
+	Upgrade bool
 }
 
 func (c *ProvidersLockCommand) Synopsis() string {
+	if c.Upgrade {
+		return "Upgrade the locked versions of the configured providers"
+	}
 	return "Write out dependency locks for the configured providers"
 }
 
@@ -47,8 +58,13 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	ctx, span := tracing.Tracer().Start(ctx, "Providers lock")
 	defer span.End()
 
+	cmdName := "providers lock"
+	if c.Upgrade {
+		cmdName = "providers upgrade"
+	}
+
 	args = c.Meta.process(args)
-	cmdFlags := c.Meta.defaultFlagSet("providers lock")
+	cmdFlags := c.Meta.defaultFlagSet(cmdName)
 	c.Meta.varFlagSet(cmdFlags)
 	var optPlatforms FlagStringSlice
 	var fsMirrorDir string
@@ -204,6 +220,17 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 		return 1
 	}
 
+	// When upgrading, we start from locks that don't include the providers
+	// we're upgrading, so that the installer selects their newest allowed
+	// versions and we don't retain the checksums of their old versions.
+	baseLocks := oldLocks
+	if c.Upgrade {
+		baseLocks = oldLocks.DeepCopy()
+		for addr := range reqs {
+			baseLocks.RemoveProvider(addr)
+		}
+	}
+
 	// Our general strategy here is to install the requested providers into
 	// a separate temporary directory -- thus ensuring that the results won't
 	// ever be inadvertently executed by other Farseek commands -- and then
@@ -277,7 +304,7 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 		dir := providercache.NewDirWithPlatform(tempDir, platform)
 		installer := providercache.NewInstaller(dir, source)
 
-		newLocks, err := installer.EnsureProviderVersions(ctx, oldLocks, reqs, providercache.InstallNewProvidersForce)
+		newLocks, err := installer.EnsureProviderVersions(ctx, baseLocks, reqs, providercache.InstallNewProvidersForce)
 		if err != nil {
 			diags = diags.Append(tfdiags.Sourceless(
 				tfdiags.Error,
@@ -296,19 +323,15 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 		return 1
 	}
 
-	// Track whether we've made any changes to the lock file as part of this
-	// operation. We can customise the final message based on our actions.
-	madeAnyChange := false
-
 	// We now have a separate updated locks object for each platform. We need
 	// to merge those all together so that the final result has the union of
 	// all of the checksums we saw for each of the providers we've worked on.
 	//
 	// We'll copy the old locks first because we want to retain any existing
 	// locks for providers that we _didn't_ visit above.
-	newLocks := oldLocks.DeepCopy()
+	newLocks := baseLocks.DeepCopy()
 	for provider := range reqs {
-		oldLock := oldLocks.Provider(provider)
+		oldLock := baseLocks.Provider(provider)
 
 		var version getproviders.Version
 		var constraints getproviders.VersionConstraints
@@ -334,16 +357,19 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 			// At this point, we've merged all the hashes for this (provider, platform)
 			// combo into the combined hashes for this provider. Let's take this
 			// opportunity to print out a summary for this particular combination.
+			if c.Upgrade {
+				// The summary of changes below is more useful when
+				// upgrading, because every provider is new to baseLocks.
+				continue
+			}
 			switch providersLockCalculateChangeType(oldLock, platformLock) {
 			case providersLockChangeTypeNewProvider:
-				madeAnyChange = true
 				c.Ui.Output(
 					fmt.Sprintf(
 						"- Obtained %s checksums for %s; This was a new provider and the checksums for this platform are now tracked in the lock file",
 						provider.ForDisplay(),
 						platform))
 			case providersLockChangeTypeNewHashes:
-				madeAnyChange = true
 				c.Ui.Output(
 					fmt.Sprintf(
 						"- Obtained %s checksums for %s; Additional checksums for this platform are now tracked in the lock file",
@@ -368,7 +394,11 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 		return 1
 	}
 
-	if madeAnyChange {
+	if changes := providersLockDiff(oldLocks, newLocks); len(changes) != 0 {
+		c.Ui.Output(fmt.Sprintf("\nFarseek has made the following changes to %s:\n", dependencyLockFilename))
+		for _, change := range changes {
+			c.Ui.Output(c.Colorize().Color("  " + change))
+		}
 		c.Ui.Output(c.Colorize().Color("\n[bold][green]Success![reset] [bold]Farseek has updated the lock file.[reset]"))
 		c.Ui.Output("\nReview the changes in .farseek.lock.hcl and then commit to your\nversion control system to retain the new checksums.\n")
 	} else {
@@ -378,6 +408,9 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 }
 
 func (c *ProvidersLockCommand) Help() string {
+	if c.Upgrade {
+		return providersUpgradeHelp
+	}
 	return `
 Usage: farseek [global options] providers lock [options] [providers...]
 
@@ -469,3 +502,125 @@ func providersLockCalculateChangeType(oldLock *depsfile.ProviderLock, platformLo
 	}
 	return providersLockChangeTypeNewHashes
 }
+
+// providersLockDiff describes the differences between the provider entries
+// of two lock files, with one line per added, removed or changed provider.
+// The lines start with "+", "-" or "~" and include color codes for
+// Colorize.
+func providersLockDiff(oldLocks, newLocks *depsfile.Locks) []string {
+	oldProviders := oldLocks.AllProviders()
+	newProviders := newLocks.AllProviders()
+
+	var providers []addrs.Provider
+	for addr := range oldProviders {
+		providers = append(providers, addr)
+	}
+	for addr := range newProviders {
+		if _, exists := oldProviders[addr]; !exists {
+			providers = append(providers, addr)
+		}
+	}
+	slices.SortFunc(providers, func(a, b addrs.Provider) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	var ret []string
+	for _, addr := range providers {
+		oldLock, newLock := oldProviders[addr], newProviders[addr]
+		switch {
+		case newLock == nil:
+			ret = append(ret, fmt.Sprintf("[red]-[reset] %s %s", addr.ForDisplay(), oldLock.Version()))
+		case oldLock == nil:
+			ret = append(ret, fmt.Sprintf("[green]+[reset] %s %s (%s)", addr.ForDisplay(), newLock.Version(), checksumsCount(len(newLock.AllHashes()))))
+		default:
+			var details []string
+			oldConstraints := getproviders.VersionConstraintsString(oldLock.VersionConstraints())
+			newConstraints := getproviders.VersionConstraintsString(newLock.VersionConstraints())
+			if oldConstraints != newConstraints {
+				details = append(details, fmt.Sprintf("constraints %q -> %q", oldConstraints, newConstraints))
+			}
+			added := countMissingHashes(newLock.AllHashes(), oldLock.AllHashes())
+			removed := countMissingHashes(oldLock.AllHashes(), newLock.AllHashes())
+			if added != 0 {
+				details = append(details, checksumsCount(added)+" added")
+			}
+			if removed != 0 {
+				details = append(details, checksumsCount(removed)+" removed")
+			}
+
+			change := fmt.Sprintf("%s %s", addr.ForDisplay(), newLock.Version())
+			if !oldLock.Version().Same(newLock.Version()) {
+				change = fmt.Sprintf("%s %s -> %s", addr.ForDisplay(), oldLock.Version(), newLock.Version())
+			} else if len(details) == 0 {
+				continue
+			}
+			if len(details) != 0 {
+				change += " (" + strings.Join(details, ", ") + ")"
+			}
+			ret = append(ret, "[yellow]~[reset] "+change)
+		}
+	}
+	return ret
+}
+
+// countMissingHashes returns how many of the hashes in a are not in b.
+func countMissingHashes(a, b []getproviders.Hash) int {
+	n := 0
+	for _, hash := range a {
+		if !slices.Contains(b, hash) {
+			n++
+		}
+	}
+	return n
+}
+
+func checksumsCount(n int) string {
+	if n == 1 {
+		return "1 checksum"
+	}
+	return fmt.Sprintf("%d checksums", n)
+}
+
+const providersUpgradeHelp = `
+Usage: farseek [global options] providers upgrade [options] [providers...]
+
+  Selects the newest version of each of the given providers that the
+  version constraints in the configuration allow, and records it in the
+  dependency lock file (.farseek.lock.hcl) along with its checksums from
+  the origin registry. The changes made to the lock file are shown when
+  the command completes.
+
+  Unlike "farseek init -upgrade", this command only changes the lock file:
+  it doesn't install the new versions, and it leaves the other providers
+  locked at their current versions.
+
+  By default this command upgrades every provider declared in the
+  configuration. You can override that behavior by providing one or more
+  provider source addresses on the command line.
+
+  The checksums recorded for the previous version of an upgraded provider
+  are discarded, so use -platform for each platform you need checksums for.
+
+Options:
+
+  -fs-mirror=dir     Consult the given filesystem mirror directory instead
+                     of the origin registry for each of the given providers.
+
+  -net-mirror=url    Consult the given network mirror (given as a base URL)
+                     instead of the origin registry for each of the given
+                     providers.
+
+  -platform=os_arch  Choose a target platform to request package checksums
+                     for. Use this option multiple times to include
+                     checksums for multiple target systems. Defaults to the
+                     platform where you run this command.
+
+  -var 'foo=bar'     Set a value for one of the input variables in the root
+                     module of the configuration. Use this option more than
+                     once to set more than one variable.
+
+  -var-file=filename Load variable values from the given file, in addition
+                     to the default files terraform.tfvars and *.auto.tfvars.
+                     Use this option more than once to include more than one
+                     variables file.
+`
@@ -115,6 +115,103 @@ func runProviderLockGenericTest(t *testing.T, testDirectory, expected string) {
 	}
 }
 
+func TestProvidersUpgrade(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-upgrade"), td)
+	t.Chdir(td)
+
+	// As in runProviderLockGenericTest, the fixture's mirror packages must
+	// be moved to the platform where this test is running.
+	platform := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		versionDir := filepath.Join(td, "fs-mirror/registry.opentofu.org/hashicorp/test", version)
+		if err := os.Rename(filepath.Join(versionDir, "os_arch"), filepath.Join(versionDir, platform)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	ui := new(cli.MockUi)
+	c := &ProvidersLockCommand{
+		Meta: Meta{
+			Ui:               ui,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+		},
+		Upgrade: true,
+	}
+	if code := c.Run([]string{"-fs-mirror=fs-mirror", "hashicorp/test"}); code != 0 {
+		t.Fatalf("wrong exit code; expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+	}
+
+	locks, diags := depsfile.LoadLocksFromFile(".farseek.lock.hcl")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	lock := locks.Provider(addrs.NewDefaultProvider("test"))
+	if got, want := lock.Version(), getproviders.MustParseVersion("1.1.0"); !got.Same(want) {
+		t.Errorf("wrong locked version %s; want %s", got, want)
+	}
+	if hashes := lock.AllHashes(); len(hashes) != 1 || hashes[0] == "h1:invalid" {
+		t.Errorf("wrong hashes %s; want only the hash of the new version", hashes)
+	}
+	if other := locks.Provider(addrs.NewDefaultProvider("other")); other == nil || other.Version().String() != "2.0.0" {
+		t.Errorf("lock for a provider that wasn't upgraded was not retained")
+	}
+
+	output := ui.OutputWriter.String()
+	if want := "~ hashicorp/test 1.0.0 -> 1.1.0 (1 checksum added, 1 checksum removed)"; !strings.Contains(output, want) {
+		t.Errorf("output doesn't describe the change %q:\n%s", want, output)
+	}
+}
+
+func TestProvidersUnlock(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-upgrade"), td)
+	t.Chdir(td)
+
+	ui := new(cli.MockUi)
+	c := &ProvidersUnlockCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"hashicorp/test"}); code != 0 {
+		t.Fatalf("wrong exit code; expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+	}
+
+	locks, diags := depsfile.LoadLocksFromFile(".farseek.lock.hcl")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if locks.Provider(addrs.NewDefaultProvider("test")) != nil {
+		t.Error("provider is still locked")
+	}
+	if locks.Provider(addrs.NewDefaultProvider("other")) == nil {
+		t.Error("lock for another provider was removed")
+	}
+	if output, want := ui.OutputWriter.String(), "- hashicorp/test 1.0.0"; !strings.Contains(output, want) {
+		t.Errorf("output doesn't describe the change %q:\n%s", want, output)
+	}
+
+	// Unlocking a provider that isn't locked is an error, and must leave
+	// the lock file untouched.
+	ui = new(cli.MockUi)
+	c = &ProvidersUnlockCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"hashicorp/test", "hashicorp/other"}); code != 1 {
+		t.Fatalf("wrong exit code; expected 1, got %d", code)
+	}
+	if output, want := ui.ErrorWriter.String(), "Error: Provider not locked"; !strings.Contains(output, want) {
+		t.Errorf("missing expected error message %q:\n%s", want, output)
+	}
+	locks, _ = depsfile.LoadLocksFromFile(".farseek.lock.hcl")
+	if locks.Provider(addrs.NewDefaultProvider("other")) == nil {
+		t.Error("lock file was changed despite the error")
+	}
+}
+
 func TestProvidersLock_args(t *testing.T) {
 
 	t.Run("mirror collision", func(t *testing.T) {
@@ -0,0 +1,101 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ProvidersUnlockCommand is a Command implementation that implements the
+// "farseek providers unlock" command, which removes the entries for the
+// given providers from the current configuration's dependency lock file so
+// that the next "farseek init" selects and records new versions for them.
+type ProvidersUnlockCommand struct {
+	Meta
+}
+
+func (c *ProvidersUnlockCommand) Synopsis() string {
+	return "Remove dependency locks for the given providers"
+}
+
+func (c *ProvidersUnlockCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("providers unlock")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	providerStrs := cmdFlags.Args()
+	if len(providerStrs) == 0 {
+		c.Ui.Error("The providers unlock command requires at least one provider source address.\n")
+		return cli.RunResultHelp
+	}
+
+	var diags tfdiags.Diagnostics
+
+	oldLocks, moreDiags := c.lockedDependenciesWithPredecessorRegistryShimmed()
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	newLocks := oldLocks.DeepCopy()
+	for _, raw := range providerStrs {
+		addr, moreDiags := addrs.ParseProviderSourceString(raw)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		if oldLocks.Provider(addr) == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Provider not locked",
+				fmt.Sprintf("The dependency lock file has no entry for %s.", addr.String()),
+			))
+			continue
+		}
+		newLocks.RemoveProvider(addr)
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	diags = diags.Append(c.replaceLockedDependencies(ctx, newLocks))
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Farseek has made the following changes to %s:\n", dependencyLockFilename))
+	for _, change := range providersLockDiff(oldLocks, newLocks) {
+		c.Ui.Output(c.Colorize().Color("  " + change))
+	}
+	c.Ui.Output(c.Colorize().Color("\n[bold][green]Success![reset] [bold]Farseek has updated the lock file.[reset]"))
+	c.Ui.Output("\nThe next \"farseek init\" will select new versions of the unlocked providers\nand record their checksums.\n")
+	return 0
+}
+
+func (c *ProvidersUnlockCommand) Help() string {
+	return `
+Usage: farseek [global options] providers unlock providers...
+
+  Removes the entries for the given providers from the dependency lock file
+  (.farseek.lock.hcl), so that the next "farseek init" selects the newest
+  versions of them that the configuration allows, without upgrading any
+  other providers. The removed entries are shown when the command completes.
+
+  Use "farseek providers upgrade" instead to select and record the new
+  versions immediately.
+`
+}
@@ -0,0 +1,170 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	backendLocal "github.com/rafagsiqueira/farseek/internal/backend/local"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+)
+
+// RecoverCommand is a Command implementation that reads the recovery journal
+// left by an interrupted apply and suggests how to bring the objects it
+// changed back under management.
+type RecoverCommand struct {
+	Meta
+}
+
+func (c *RecoverCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var clearJournal bool
+	cmdFlags := c.Meta.defaultFlagSet("recover")
+	cmdFlags.BoolVar(&clearJournal, "clear", false, "clear")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The recover command expects no positional arguments.\n")
+		return cli.RunResultHelp
+	}
+
+	path := filepath.Join(c.DataDir(), backendLocal.RecoveryJournalFilename)
+	entries, err := backendLocal.ReadRecoveryJournal(path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read the recovery journal %s: %s", path, err))
+		return 1
+	}
+	if len(entries) == 0 {
+		c.Ui.Output("There is no recovery journal, so there is nothing to recover.")
+		return 0
+	}
+
+	c.Ui.Output(recoverySuggestions(entries))
+
+	if clearJournal {
+		if err := os.Remove(path); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to remove the recovery journal %s: %s", path, err))
+			return 1
+		}
+		c.Ui.Output(fmt.Sprintf("Removed the recovery journal %s.", path))
+	}
+	return 0
+}
+
+// recoverySuggestions summarizes the final outcome recorded for each resource
+// instance in the given journal entries, as import blocks for objects that
+// exist but that Farseek would otherwise try to create again, and a list of
+// changes that must be checked by hand.
+func recoverySuggestions(entries []backendLocal.RecoveryEntry) string {
+	final := finalRecoveryEntries(entries)
+
+	var imports, unknownIDs, incomplete, destroyed []string
+	for _, entry := range final {
+		addr := entry.Address
+		creates := entry.Action == plans.Create.String() ||
+			entry.Action == plans.DeleteThenCreate.String() ||
+			entry.Action == plans.CreateThenDelete.String() ||
+			entry.Action == plans.ForgetThenCreate.String()
+
+		switch {
+		case entry.Status == backendLocal.RecoveryStatusStarted:
+			incomplete = append(incomplete, fmt.Sprintf("  - %s (%s)", addr, entry.Action))
+		case entry.Action == plans.Delete.String() && entry.Status == backendLocal.RecoveryStatusApplied:
+			destroyed = append(destroyed, "  - "+addr)
+		case !creates:
+			// Updates are made in place, so the next apply finds the object
+			// where it expects it.
+		case entry.ID != "":
+			// A failed create can still leave an object behind, which the
+			// provider reports by returning its ID.
+			imports = append(imports, fmt.Sprintf("import {\n  to = %s\n  id = %q\n}\n", addr, entry.ID))
+		case entry.Status == backendLocal.RecoveryStatusApplied:
+			unknownIDs = append(unknownIDs, "  - "+addr)
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "The recovery journal records changes to %d resource instances from an apply that did not finish.\n", len(final))
+	if len(imports) > 0 {
+		buf.WriteString("\nThese objects were created, but Farseek will plan to create them again. To keep\n")
+		buf.WriteString("them, add the following import blocks to your configuration and apply again. To\n")
+		buf.WriteString("destroy them instead, apply once with these import blocks and then remove their\n")
+		buf.WriteString("resource blocks.\n\n")
+		buf.WriteString(strings.Join(imports, "\n"))
+	}
+	if len(unknownIDs) > 0 {
+		buf.WriteString("\nThese objects were created, but their IDs are unknown. Find them with your\n")
+		buf.WriteString("provider's tools and import or delete them:\n")
+		buf.WriteString(strings.Join(unknownIDs, "\n") + "\n")
+	}
+	if len(incomplete) > 0 {
+		buf.WriteString("\nThese changes were started but might not have completed. Check whether the\n")
+		buf.WriteString("objects exist and import or delete them as needed:\n")
+		buf.WriteString(strings.Join(incomplete, "\n") + "\n")
+	}
+	if len(destroyed) > 0 {
+		buf.WriteString("\nThese objects were destroyed, and need no further action:\n")
+		buf.WriteString(strings.Join(destroyed, "\n") + "\n")
+	}
+	buf.WriteString("\nRun \"farseek recover -clear\" once you have dealt with these changes.")
+	return buf.String()
+}
+
+// finalRecoveryEntries returns the last entry recorded for each resource
+// instance in the given journal entries, in the order the instances first
+// appear in the journal.
+func finalRecoveryEntries(entries []backendLocal.RecoveryEntry) []backendLocal.RecoveryEntry {
+	var order []string
+	last := make(map[string]backendLocal.RecoveryEntry)
+	for _, entry := range entries {
+		if _, seen := last[entry.Address]; !seen {
+			order = append(order, entry.Address)
+		}
+		last[entry.Address] = entry
+	}
+
+	final := make([]backendLocal.RecoveryEntry, len(order))
+	for i, addr := range order {
+		final[i] = last[addr]
+	}
+	return final
+}
+
+func (c *RecoverCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] recover [options]
+       farseek [global options] recover rebuild-state [options]
+
+  Shows how to recover from an apply that was interrupted before it
+  finished.
+
+  In stateless mode, apply records each change it makes in the recovery
+  journal .farseek/recovery.jsonl, and removes the journal once it
+  completes. If Farseek is killed or crashes during the apply, this command
+  reads the journal and suggests import blocks for the objects that were
+  created, so that the next apply does not try to create them again, along
+  with the changes that must be checked by hand.
+
+  The rebuild-state subcommand instead writes a state file with the objects
+  that the journal records, read from their providers.
+
+Options:
+
+  -clear    Remove the recovery journal after showing the suggestions.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RecoverCommand) Synopsis() string {
+	return "Recover from an interrupted apply"
+}
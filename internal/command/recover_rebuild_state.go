@@ -0,0 +1,277 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	backendLocal "github.com/rafagsiqueira/farseek/internal/backend/local"
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// DefaultRecoveredStateFilename is the default path of the state file that
+// "farseek recover rebuild-state" writes.
+const DefaultRecoveredStateFilename = "recovered.tfstate"
+
+// RecoverRebuildStateCommand is a Command implementation that writes a state
+// file with the objects that the recovery journal of an interrupted apply
+// records, by importing them again from their providers.
+type RecoverRebuildStateCommand struct {
+	Meta
+}
+
+func (c *RecoverRebuildStateCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+
+	var outPath string
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.extendedFlagSet("recover rebuild-state")
+	cmdFlags.StringVar(&outPath, "out", DefaultRecoveredStateFilename, "path")
+	cmdFlags.IntVar(&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The recover rebuild-state command expects no positional arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+	// The state is only read, to build the context, so it isn't locked.
+	c.Meta.stateLock = false
+
+	if _, err := os.Stat(outPath); err == nil {
+		c.Ui.Error(fmt.Sprintf("The file %s already exists. Use -out to write the rebuilt state somewhere else.", outPath))
+		return 1
+	}
+
+	journalPath := filepath.Join(c.DataDir(), backendLocal.RecoveryJournalFilename)
+	entries, err := backendLocal.ReadRecoveryJournal(journalPath)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read the recovery journal %s: %s", journalPath, err))
+		return 1
+	}
+	if len(entries) == 0 {
+		c.Ui.Output("There is no recovery journal, so there is nothing to recover.")
+		return 0
+	}
+
+	var diags tfdiags.Diagnostics
+
+	var targets []*farseek.ImportTarget
+	var unknownIDs, incomplete []string
+	for _, entry := range finalRecoveryEntries(entries) {
+		switch {
+		case entry.Status == backendLocal.RecoveryStatusStarted:
+			incomplete = append(incomplete, fmt.Sprintf("  - %s (%s)", entry.Address, entry.Action))
+			continue
+		case entry.Action == plans.Delete.String() && entry.Status == backendLocal.RecoveryStatusApplied:
+			continue
+		case entry.ID == "":
+			if entry.Status == backendLocal.RecoveryStatusApplied {
+				unknownIDs = append(unknownIDs, "  - "+entry.Address)
+			}
+			continue
+		}
+		addr, addrDiags := addrs.ParseAbsResourceInstanceStr(entry.Address)
+		diags = diags.Append(addrDiags)
+		if addrDiags.HasErrors() {
+			continue
+		}
+		targets = append(targets, &farseek.ImportTarget{
+			CommandLineImportTarget: &farseek.CommandLineImportTarget{
+				Addr: addr,
+				ID:   entry.ID,
+			},
+		})
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	enc, encDiags := c.EncryptionFromPath(ctx, ".")
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	newState := states.NewState()
+	if len(targets) > 0 {
+		var moreDiags tfdiags.Diagnostics
+		newState, moreDiags = c.importTargets(targets, enc)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+
+	log.Printf("[INFO] Writing rebuilt state to: %s", outPath)
+	stateMgr := statemgr.NewFilesystem(outPath, enc.State())
+	if err := stateMgr.WriteState(newState); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing state file: %s", err))
+		return 1
+	}
+	if err := stateMgr.PersistState(ctx, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing state file: %s", err))
+		return 1
+	}
+	c.showDiagnostics(diags)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Wrote %d resource instances recorded in the recovery journal to %s.\n", len(targets), outPath)
+	if len(unknownIDs) > 0 {
+		buf.WriteString("\nThese objects were created, but their IDs are unknown, so they aren't in the\n")
+		buf.WriteString("state. Find them with your provider's tools and import or delete them:\n")
+		buf.WriteString(strings.Join(unknownIDs, "\n") + "\n")
+	}
+	if len(incomplete) > 0 {
+		buf.WriteString("\nThese changes were started but might not have completed, so they aren't in\n")
+		buf.WriteString("the state. Check whether the objects exist and import or delete them as needed:\n")
+		buf.WriteString(strings.Join(incomplete, "\n") + "\n")
+	}
+	c.Ui.Output(strings.TrimSpace(buf.String()))
+	return 0
+}
+
+// importTargets imports the given objects into an empty state, reading each
+// of them from its provider as "farseek import" does.
+func (c *RecoverRebuildStateCommand) importTargets(targets []*farseek.ImportTarget, enc encryption.Encryption) (_ *states.State, diags tfdiags.Diagnostics) {
+	ctx := c.CommandContext()
+
+	config, configDiags := c.loadConfig(ctx, ".")
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		return nil, diags
+	}
+
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		diags = diags.Append(fmt.Errorf("Error loading plugin path: %w", err))
+		return nil, diags
+	}
+
+	b, backendDiags := c.Backend(ctx, &BackendOpts{
+		Config: config.Module.Backend,
+	}, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		return nil, diags
+	}
+	local, ok := b.(backend.Local)
+	if !ok {
+		diags = diags.Append(fmt.Errorf("%s", ErrUnsupportedLocalOp))
+		return nil, diags
+	}
+
+	opReq := c.Operation(ctx, b, arguments.ViewHuman, enc)
+	opReq.ConfigDir = "."
+	opReq.ConfigLoader, err = c.initConfigLoader()
+	if err != nil {
+		diags = diags.Append(err)
+		return nil, diags
+	}
+	opReq.Hooks = []farseek.Hook{c.uiHook()}
+	{
+		var moreDiags, callDiags tfdiags.Diagnostics
+		opReq.Variables, moreDiags = c.collectVariableValues()
+		opReq.RootCall, callDiags = c.rootModuleCall(ctx, opReq.ConfigDir)
+		diags = diags.Append(moreDiags).Append(callDiags)
+		if moreDiags.HasErrors() {
+			return nil, diags
+		}
+	}
+	opReq.View = views.NewOperation(arguments.ViewHuman, c.RunningInAutomation, c.View)
+
+	lr, _, ctxDiags := local.LocalRun(ctx, opReq)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		return nil, diags
+	}
+	defer func() {
+		diags = diags.Append(opReq.StateLocker.Unlock())
+	}()
+
+	// The objects are imported whether or not they still have resource
+	// blocks, since the configuration may have changed since the apply.
+	newState, importDiags := lr.Core.ImportStateless(ctx, lr.Config, states.NewState(), &farseek.ImportOpts{
+		Targets:      targets,
+		SetVariables: lr.PlanOpts.SetVariables,
+	})
+	diags = diags.Append(importDiags)
+	return newState, diags
+}
+
+func (c *RecoverRebuildStateCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *RecoverRebuildStateCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-input":       completePredictInput,
+		"-no-color":    complete.PredictNothing,
+		"-out":         complete.PredictFiles("*.tfstate"),
+		"-parallelism": complete.PredictAnything,
+		"-var":         complete.PredictAnything,
+		"-var-file":    complete.PredictFiles("*.tfvars"),
+	}
+}
+
+func (c *RecoverRebuildStateCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] recover rebuild-state [options]
+
+  Writes a state file with the objects that the recovery journal of an
+  interrupted apply records as created or changed.
+
+  Each object is read from its provider by its ID, as "farseek import" would,
+  so the state reflects the objects as they are now. Objects that the apply
+  destroyed are left out, as are those whose IDs the journal doesn't record
+  and changes that might not have completed, which are listed to be checked
+  by hand.
+
+  The state file can be used to stop using stateless mode, or to inspect the
+  objects with the usual state commands, such as
+  "farseek state list -state=recovered.tfstate".
+
+Options:
+
+  -out=path           Write the state to the given path, which must not
+                      exist. Defaults to "recovered.tfstate".
+
+  -parallelism=n      Limit the number of concurrent operations.
+                      Defaults to 10.
+
+  -var 'foo=bar'      Set a variable in the Farseek configuration. This
+                      flag can be set multiple times. The provider
+                      configurations may need them.
+
+  -var-file=foo       Set variables in the Farseek configuration from
+                      a file. If "terraform.tfvars" or any ".auto.tfvars"
+                      files are present, they will be automatically loaded.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RecoverRebuildStateCommand) Synopsis() string {
+	return "Rebuild a state file from the recovery journal"
+}
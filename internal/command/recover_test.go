@@ -0,0 +1,160 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/zclconf/go-cty/cty"
+
+	backendLocal "github.com/rafagsiqueira/farseek/internal/backend/local"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+)
+
+func TestRecover(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	journal := strings.Join([]string{
+		`{"address":"test_instance.created","action":"Create","status":"started"}`,
+		`{"address":"test_instance.created","action":"Create","status":"applied","id":"abc"}`,
+		`{"address":"test_instance.pending","action":"Create","status":"started"}`,
+		`{"address":"test_instance.gone","action":"Delete","status":"started","id":"def"}`,
+		`{"address":"test_instance.gone","action":"Delete","status":"applied","id":"def"}`,
+		`{"address":"test_instance.changed","action":"Update","status":"applied","id":"ghi"}`,
+	}, "\n") + "\n"
+	path := filepath.Join(DefaultDataDir, backendLocal.RecoveryJournalFilename)
+	if err := os.MkdirAll(DefaultDataDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(journal), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	c := &RecoverCommand{
+		Meta: Meta{Ui: ui},
+	}
+	if code := c.Run([]string{"-clear"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"changes to 4 resource instances",
+		"import {\n  to = test_instance.created\n  id = \"abc\"\n}",
+		"  - test_instance.pending (Create)",
+		"  - test_instance.gone",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "test_instance.changed") {
+		t.Errorf("output should not mention updated objects:\n%s", output)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("journal was not removed: %v", err)
+	}
+}
+
+func TestRecover_noJournal(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	ui := cli.NewMockUi()
+	c := &RecoverCommand{
+		Meta: Meta{Ui: ui},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "nothing to recover") {
+		t.Fatalf("wrong output: %s", got)
+	}
+}
+
+func TestRecoverRebuildState(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("import-provider-implicit"), td)
+	t.Chdir(td)
+
+	journal := strings.Join([]string{
+		`{"address":"test_instance.foo","action":"Create","status":"started"}`,
+		`{"address":"test_instance.foo","action":"Create","status":"applied","id":"abc"}`,
+		`{"address":"test_instance.removed","action":"Update","status":"applied","id":"ghi"}`,
+		`{"address":"test_instance.gone","action":"Delete","status":"applied","id":"def"}`,
+		`{"address":"test_instance.pending","action":"Create","status":"started"}`,
+	}, "\n") + "\n"
+	if err := os.MkdirAll(DefaultDataDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(DefaultDataDir, backendLocal.RecoveryJournalFilename), []byte(journal), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id": {Type: cty.String, Optional: true, Computed: true},
+					},
+				},
+			},
+		},
+	}
+	p.ImportResourceStateFn = func(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+		return providers.ImportResourceStateResponse{
+			ImportedResources: []providers.ImportedResource{
+				{
+					TypeName: req.TypeName,
+					State: cty.ObjectVal(map[string]cty.Value{
+						"id": cty.StringVal(req.ID),
+					}),
+				},
+			},
+		}
+	}
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &RecoverRebuildStateCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	testStateOutput(t, DefaultRecoveredStateFilename, `
+test_instance.foo:
+  ID = abc
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+test_instance.removed:
+  ID = ghi
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+`)
+	output := ui.OutputWriter.String()
+	for _, want := range []string{
+		"Wrote 2 resource instances",
+		"  - test_instance.pending (Create)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q:\n%s", want, output)
+		}
+	}
+
+	// The rebuilt state is never overwritten.
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("overwrote the rebuilt state: %d", code)
+	}
+}
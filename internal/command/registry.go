@@ -0,0 +1,35 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+// RegistryCommand is a Command implementation that just shows help for
+// the subcommands nested below it.
+type RegistryCommand struct {
+	Meta
+}
+
+func (c *RegistryCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *RegistryCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] registry <subcommand> [options] [args]
+
+  This command has subcommands for publishing to module registries and OCI
+  repositories.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RegistryCommand) Synopsis() string {
+	return "Publish modules to registries"
+}
@@ -0,0 +1,349 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apparentlymart/go-versions/versions"
+	regaddr "github.com/opentofu/registry-address/v2"
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/getmodules"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// moduleIgnoreFilename is the name of the file, in the directory of a
+// module, that lists the files to leave out of its module package, using
+// the syntax of .gitignore files.
+const moduleIgnoreFilename = ".terraformignore"
+
+// modulePackageDefaultIgnores are the files that are never part of a module
+// package, in addition to those in the ignore file.
+const modulePackageDefaultIgnores = `
+.git/
+.terraform/
+`
+
+// RegistryPublishModuleCommand is a Command implementation that implements
+// the "farseek registry publish-module" command, which packages the module
+// in the current directory and publishes it to a module registry or to an
+// OCI repository.
+type RegistryPublishModuleCommand struct {
+	Meta
+}
+
+func (c *RegistryPublishModuleCommand) Synopsis() string {
+	return "Package the current module and publish it"
+}
+
+func (c *RegistryPublishModuleCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("registry publish-module")
+	var moduleVersion string
+	cmdFlags.StringVar(&moduleVersion, "module-version", "", "module version")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No destination specified",
+			"The registry publish-module command requires the address of the module in a registry, or an OCI repository address starting with \"oci://\", as a command-line argument.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	destination := args[0]
+
+	var packageAddr regaddr.ModulePackage
+	var ociDomain, ociRepository string
+	if rest, ok := strings.CutPrefix(destination, "oci://"); ok {
+		ociDomain, ociRepository, _ = strings.Cut(rest, "/")
+		if ociDomain == "" || ociRepository == "" || strings.ContainsAny(ociRepository, "?#") {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid OCI repository address",
+				fmt.Sprintf("The destination %q must be of the form oci://REGISTRY/REPOSITORY. Use the -module-version option to choose the tag to publish.", destination),
+			))
+		}
+	} else {
+		addr, err := regaddr.ParseModuleSource(destination)
+		switch {
+		case err != nil:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid module registry address",
+				fmt.Sprintf("The destination %q is not a valid module registry address: %s.", destination, err),
+			))
+		case addr.Subdir != "":
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid module registry address",
+				fmt.Sprintf("The destination %q must not include a subdirectory, because a module package is always published as a whole.", destination),
+			))
+		case moduleVersion == "":
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"No module version specified",
+				"Publishing to a module registry requires the version of the module, in the -module-version option.",
+			))
+		default:
+			if _, err := versions.ParseVersion(moduleVersion); err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid module version",
+					fmt.Sprintf("The string %q given in the -module-version option is not a valid semantic version: %s.", moduleVersion, err),
+				))
+			}
+		}
+		packageAddr = addr.Package
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Publishing can be cancelled by SIGINT and similar.
+	ctx, done := c.InterruptibleContext(c.CommandContext())
+	defer done()
+
+	// We don't publish a module that Farseek can't load, since no caller
+	// would be able to use it either.
+	dir := c.normalizePath(".")
+	_, moreDiags := c.loadSingleModule(ctx, dir, configs.SelectiveLoadAll)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	pkg, err := packModule(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to package module",
+			fmt.Sprintf("Could not package the module in %s: %s.", dir, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf(
+		"Packaged %d files (%d bytes, sha256:%s).",
+		len(pkg.Files), len(pkg.Archive), pkg.Checksum,
+	))
+
+	if ociDomain != "" {
+		tagName := moduleVersion
+		if tagName == "" {
+			tagName = "latest"
+		}
+		digest, err := c.publishModuleOCI(ctx, ociDomain, ociRepository, tagName, pkg.Archive)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to publish module",
+				fmt.Sprintf("Could not publish the module package to %s: %s.", destination, err),
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+		c.Ui.Output(fmt.Sprintf("Published %s with tag %q (manifest digest %s).", destination, tagName, digest))
+
+		c.showDiagnostics(diags)
+		return 0
+	}
+
+	client := c.registryClient(ctx)
+	if err := client.PublishModulePackage(ctx, packageAddr, moduleVersion, pkg.Archive); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to publish module",
+			fmt.Sprintf("Could not publish the module package to %s: %s.", packageAddr, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf("Published %s version %s.", packageAddr, moduleVersion))
+
+	c.showDiagnostics(diags)
+	return 0
+}
+
+// publishModuleOCI pushes the given module package archive to the given OCI
+// repository with the given tag, returning the digest of its manifest.
+func (c *RegistryPublishModuleCommand) publishModuleOCI(ctx context.Context, registryDomain, repositoryName, tagName string, archive []byte) (string, error) {
+	if c.OCIModulePackagePushStore == nil {
+		return "", fmt.Errorf("publishing to OCI repositories isn't available")
+	}
+	store, err := c.OCIModulePackagePushStore(ctx, registryDomain, repositoryName)
+	if err != nil {
+		return "", err
+	}
+	desc, err := getmodules.PushOCIModulePackage(ctx, store, archive, tagName)
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// modulePackage is a zip archive of the files of a module, ready to be
+// published.
+type modulePackage struct {
+	Archive []byte
+
+	// Files are the slash-separated paths of the files in the archive, in
+	// the order they were added.
+	Files []string
+
+	// Checksum is the SHA-256 checksum of Archive, in hex.
+	Checksum string
+}
+
+// packModule builds a zip archive of the module in the given directory,
+// leaving out the files that moduleIgnoreFilename and
+// modulePackageDefaultIgnores select.
+//
+// The archive only depends on the paths, contents and permissions of the
+// files, so that packaging the same module twice gives the same checksum.
+func packModule(dir string) (*modulePackage, error) {
+	ignoreSrc, err := os.ReadFile(filepath.Join(dir, moduleIgnoreFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	rules, err := farseek.ParseIgnoreRules(append([]byte(modulePackageDefaultIgnores), ignoreSrc...))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", moduleIgnoreFilename, err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rules.MatchPath(rel + "/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rules.MatchPath(rel) {
+			return nil
+		}
+
+		// Symbolic links to files are packaged as copies of their targets,
+		// since not every module installer can extract links.
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s is a symbolic link to a directory, which module packages can't contain", rel)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", rel)
+		}
+
+		header := &zip.FileHeader{
+			Name:   rel,
+			Method: zip.Deflate,
+		}
+		header.SetMode(info.Mode().Perm())
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(buf.Bytes())
+	return &modulePackage{
+		Archive:  buf.Bytes(),
+		Files:    files,
+		Checksum: hex.EncodeToString(checksum[:]),
+	}, nil
+}
+
+func (c *RegistryPublishModuleCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *RegistryPublishModuleCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-module-version": complete.PredictAnything,
+	}
+}
+
+func (c *RegistryPublishModuleCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] registry publish-module [options] DESTINATION
+
+  Packages the module in the current directory as a zip archive and
+  publishes it to DESTINATION, which is either the address of the module
+  in a private module registry, like example.com/namespace/name/system,
+  or an OCI repository, like oci://example.com/modules/name.
+
+  The package leaves out the files that match the patterns in a
+  .terraformignore file in the module directory, which uses the syntax of
+  .gitignore files, along with the .git and .terraform directories. The
+  command prints the SHA-256 checksum of the package, which doesn't change
+  unless the files of the module do.
+
+  Module registries must support the upload endpoint that the Farseek
+  documentation describes. Credentials for registries come from the CLI
+  configuration, and credentials for OCI repositories from the same
+  configuration that Farseek uses to install modules from them.
+
+Options:
+
+  -module-version=VERSION  The version to publish. For module registries,
+                           this is required and must be a semantic version.
+                           For OCI repositories, this is the tag to
+                           publish, and defaults to "latest".
+`
+	return strings.TrimSpace(helpText)
+}
@@ -0,0 +1,207 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+	orasMemoryStore "oras.land/oras-go/v2/content/memory"
+
+	"github.com/rafagsiqueira/farseek/internal/getmodules"
+	registrytest "github.com/rafagsiqueira/farseek/internal/registry/test"
+)
+
+// testModulePackageDir creates a module directory with some files that the
+// package must leave out, and changes into it.
+func testModulePackageDir(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.tf":            `variable "name" {}`,
+		"modules/sub/sub.tf": `output "id" { value = "sub" }`,
+		"prod.tfvars":        `name = "prod"`,
+		"examples/basic.tf":  `module "example" { source = "../" }`,
+		".git/HEAD":          "ref: refs/heads/main",
+		".terraform/x.json":  "{}",
+		".terraformignore":   "*.tfvars\n/examples/\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestPackModule(t *testing.T) {
+	testModulePackageDir(t)
+
+	pkg, err := packModule(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{".terraformignore", "main.tf", "modules/sub/sub.tf"}
+	if diff := cmp.Diff(want, pkg.Files); diff != "" {
+		t.Errorf("wrong files\n%s", diff)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(pkg.Archive), int64(len(pkg.Archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong archive contents\n%s", diff)
+	}
+
+	// Packaging the same files again gives the same checksum.
+	again, err := packModule(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if again.Checksum != pkg.Checksum {
+		t.Errorf("checksum changed from %s to %s", pkg.Checksum, again.Checksum)
+	}
+}
+
+func TestRegistryPublishModule_registry(t *testing.T) {
+	testModulePackageDir(t)
+
+	var gotPath string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	ui := new(cli.MockUi)
+	c := &RegistryPublishModuleCommand{
+		Meta: Meta{
+			Ui:       ui,
+			Services: registrytest.Disco(server),
+		},
+	}
+	if code := c.Run([]string{"-module-version=1.2.0", "example.com/acme/network/aws"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if want := "/v1/modules/acme/network/aws/1.2.0/upload"; gotPath != want {
+		t.Errorf("wrong upload path %q; want %q", gotPath, want)
+	}
+	pkg, err := packModule(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotBody, pkg.Archive) {
+		t.Errorf("uploaded archive doesn't match the package")
+	}
+	output := ui.OutputWriter.String()
+	if want := "Packaged 3 files"; !strings.Contains(output, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, output)
+	}
+	if want := "sha256:" + pkg.Checksum; !strings.Contains(output, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, output)
+	}
+	if want := "Published example.com/acme/network/aws version 1.2.0."; !strings.Contains(output, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, output)
+	}
+}
+
+func TestRegistryPublishModule_oci(t *testing.T) {
+	testModulePackageDir(t)
+
+	store := orasMemoryStore.New()
+	var gotDomain, gotRepository string
+	ui := new(cli.MockUi)
+	c := &RegistryPublishModuleCommand{
+		Meta: Meta{
+			Ui: ui,
+			OCIModulePackagePushStore: func(ctx context.Context, registryDomain, repositoryName string) (getmodules.OCIRepositoryPushStore, error) {
+				gotDomain, gotRepository = registryDomain, repositoryName
+				return store, nil
+			},
+		},
+	}
+	if code := c.Run([]string{"-module-version=v1.2.0", "oci://example.com/modules/network"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if gotDomain != "example.com" || gotRepository != "modules/network" {
+		t.Errorf("wrong repository %s/%s", gotDomain, gotRepository)
+	}
+	desc, err := store.Resolve(t.Context(), "v1.2.0")
+	if err != nil {
+		t.Fatalf("tag wasn't published: %s", err)
+	}
+	output := ui.OutputWriter.String()
+	if want := `Published oci://example.com/modules/network with tag "v1.2.0" (manifest digest ` + desc.Digest.String() + `).`; !strings.Contains(output, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, output)
+	}
+}
+
+func TestRegistryPublishModule_invalid(t *testing.T) {
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"no destination": {
+			nil,
+			"No destination specified",
+		},
+		"no version for registry": {
+			[]string{"example.com/acme/network/aws"},
+			"No module version specified",
+		},
+		"invalid version": {
+			[]string{"-module-version=latest", "example.com/acme/network/aws"},
+			"Invalid module version",
+		},
+		"subdirectory": {
+			[]string{"-module-version=1.0.0", "example.com/acme/network/aws//modules/sub"},
+			"Invalid module registry address",
+		},
+		"invalid oci address": {
+			[]string{"oci://example.com"},
+			"Invalid OCI repository address",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ui := new(cli.MockUi)
+			c := &RegistryPublishModuleCommand{
+				Meta: Meta{
+					Ui: ui,
+				},
+			}
+			if code := c.Run(test.args); code != 1 {
+				t.Fatalf("wrong exit code %d; want 1", code)
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, test.want) {
+				t.Errorf("error doesn't contain %q:\n%s", test.want, got)
+			}
+		})
+	}
+}
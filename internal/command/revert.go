@@ -0,0 +1,91 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+)
+
+// RevertCommand is a cli.Command implementation that plans to undo the
+// changes made by a commit, or a range of commits, to the resources they
+// touched.
+type RevertCommand struct {
+	Meta
+}
+
+func (c *RevertCommand) Run(rawArgs []string) int {
+	if len(rawArgs) == 0 || strings.HasPrefix(rawArgs[len(rawArgs)-1], "-") {
+		c.Ui.Error("The revert command expects a commit, or a range of commits, as its last argument.")
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	fromSHA, toSHA, err := parseRevertRange(rawArgs[len(rawArgs)-1])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Reverting is planning the range of commits backwards: from the
+	// configuration after the commits, to the configuration before them.
+	args := append(rawArgs[:len(rawArgs)-1:len(rawArgs)-1], "-from-sha="+fromSHA, "-to-sha="+toSHA)
+	plan := &PlanCommand{Meta: c.Meta}
+	return plan.Run(args)
+}
+
+// parseRevertRange returns the commits to plan from and to in order to
+// revert the given commit, or the commits in the given "A..B" range.
+func parseRevertRange(spec string) (fromSHA, toSHA string, err error) {
+	if before, after, ok := strings.Cut(spec, ".."); ok {
+		if before == "" || after == "" || strings.HasPrefix(after, ".") {
+			return "", "", fmt.Errorf("Invalid range of commits %q: expected a range like A..B, which reverts the commits after A up to B.", spec)
+		}
+		return after, before, nil
+	}
+	return spec, spec + "^", nil
+}
+
+func (c *RevertCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *RevertCommand) AutocompleteFlags() complete.Flags {
+	return nil
+}
+
+func (c *RevertCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] revert [options] COMMIT
+
+  Plans to undo the changes that a commit made to infrastructure.
+
+  Farseek discovers the resources that the commit changed, and plans them
+  with the configuration from before the commit. Resources that the commit
+  added are destroyed, and those that it changed or removed are restored.
+  Other resources are left alone, even if the configuration from before the
+  commit differs for them.
+
+  COMMIT can also be a range of commits, A..B, to undo the changes made by
+  the commits after A, up to and including B.
+
+  This command is a convenience alias for:
+      farseek plan -from-sha=COMMIT -to-sha=COMMIT^
+
+  The plan can't be saved. To roll back the infrastructure, revert the
+  commit in git, and apply the result as usual.
+
+Options:
+
+  This command accepts many of the options accepted by the farseek plan
+  command. For more information on those options, run:
+      farseek plan -help
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RevertCommand) Synopsis() string {
+	return "Plan to undo the infrastructure changes of a commit"
+}
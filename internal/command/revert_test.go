@@ -0,0 +1,93 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+)
+
+func TestParseRevertRange(t *testing.T) {
+	tests := map[string]struct {
+		from, to string
+		wantErr  bool
+	}{
+		"abc":       {from: "abc", to: "abc^"},
+		"abc..def":  {from: "def", to: "abc"},
+		"abc..":     {wantErr: true},
+		"..def":     {wantErr: true},
+		"abc...def": {wantErr: true},
+	}
+	for spec, test := range tests {
+		t.Run(spec, func(t *testing.T) {
+			from, to, err := parseRevertRange(spec)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != test.from || to != test.to {
+				t.Errorf("wrong range %q to %q; want %q to %q", from, to, test.from, test.to)
+			}
+		})
+	}
+}
+
+func TestRevert(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-existing-state"), td)
+	t.Chdir(td)
+	t.Setenv("FARSEEK_TEST_FORCE_MODE", "true")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &RevertCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	discoverer := &rangeDiscoverer{mockDiscoverer: mockDiscoverer{resources: []farseek.DiscoveredResource{
+		{Address: "test_instance.foo", Filename: "main.tf", IsNew: true},
+	}}}
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = discoverer
+
+	code := c.Run([]string{"-refresh=false", "abc"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n%s", code, output.Stderr())
+	}
+	if got, want := discoverer.between, [2]string{"abc", "abc^"}; got != want {
+		t.Errorf("wrong range %q; want %q", got, want)
+	}
+	if got, want := discoverer.exported, "abc^"; got != want {
+		t.Errorf("wrong exported configuration %q; want %q", got, want)
+	}
+}
+
+func TestRevert_noCommit(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &RevertCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"-refresh=false"}); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "expects a commit"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+}
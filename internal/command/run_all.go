@@ -0,0 +1,279 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/dag"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// runAllCommands are the commands that run-all can run in each root.
+var runAllCommands = map[string]bool{
+	"init":     true,
+	"validate": true,
+	"plan":     true,
+	"apply":    true,
+	"destroy":  true,
+}
+
+// RunAllCommand is a Command implementation that runs another command in
+// each root of a project, in the order given by the roots' dependencies.
+type RunAllCommand struct {
+	Meta
+
+	// run runs a Farseek command in the given directory. If it is nil, the
+	// command runs the Farseek executable as a child process.
+	run func(ctx context.Context, dir string, args []string, stdout, stderr io.Writer) (int, error)
+}
+
+func (c *RunAllCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	var parallelism int
+	cmdFlags := c.Meta.defaultFlagSet("run-all")
+	cmdFlags.IntVar(&parallelism, "parallelism", 4, "parallelism")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) == 0 {
+		c.Ui.Error("The run-all command expects the name of the command to run in each root.\n")
+		return cli.RunResultHelp
+	}
+	if !runAllCommands[args[0]] {
+		c.Ui.Error(fmt.Sprintf("The run-all command can't run %q. It can run init, validate, plan, apply, and destroy.\n", args[0]))
+		return cli.RunResultHelp
+	}
+	if parallelism < 1 {
+		c.Ui.Error("The -parallelism option must be at least 1.\n")
+		return cli.RunResultHelp
+	}
+	destroy := args[0] == "destroy" || (args[0] == "apply" && hasFlag(args[1:], "destroy"))
+	if (args[0] == "apply" || args[0] == "destroy") && !hasFlag(args[1:], "auto-approve") {
+		c.Ui.Error(fmt.Sprintf("The run-all command runs %s in several roots at once, so it can't ask for approval. Use -auto-approve to approve all of the changes.\n", args[0]))
+		return 1
+	}
+
+	project, err := farseek.FindProject(c.discoveryDir())
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load the project file: %s", err))
+		return 1
+	}
+	if project == nil {
+		c.Ui.Error(fmt.Sprintf("No %s file found in this directory or its parents. The run-all command needs a project file that declares the roots to run in.", farseek.ProjectFilename))
+		return 1
+	}
+
+	run := c.run
+	if run == nil {
+		executable, err := os.Executable()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to find the Farseek executable: %s", err))
+			return 1
+		}
+		run = execRunAll(executable)
+	}
+
+	// Dependencies run first, so that their exported outputs are up to date
+	// when the roots that read them run. Destroying goes the other way, so
+	// that no root outlives the roots it depends on.
+	g := project.Graph()
+	if destroy {
+		g = reverseGraph(g)
+	}
+
+	// An interrupt reaches the running child processes directly, and lets
+	// them stop gracefully, so we only use it to avoid starting more.
+	base := c.CommandContext()
+	ctx, done := c.InterruptibleContext(base)
+	defer done()
+
+	var mu sync.Mutex
+	ui := func(name string, errOut bool) io.Writer {
+		return &prefixWriter{prefix: "[" + name + "] ", write: func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if errOut {
+				c.Ui.Error(line)
+			} else {
+				c.Ui.Output(line)
+			}
+		}}
+	}
+
+	sem := make(chan struct{}, parallelism)
+	ran := make(map[*farseek.ProjectRoot]bool)
+	var failed []string
+	g.Walk(func(v dag.Vertex) tfdiags.Diagnostics {
+		var diags tfdiags.Diagnostics
+		root := v.(*farseek.ProjectRoot)
+		sem <- struct{}{}
+		defer func() { <-sem }()
+
+		if ctx.Err() != nil {
+			return diags.Append(ctx.Err())
+		}
+		mu.Lock()
+		ran[root] = true
+		mu.Unlock()
+
+		stdout, stderr := ui(root.Name, false), ui(root.Name, true)
+		code, err := run(base, filepath.Join(project.Dir, root.Path), args, stdout, stderr)
+		stdout.(*prefixWriter).Flush()
+		stderr.(*prefixWriter).Flush()
+		if err != nil || code != 0 {
+			mu.Lock()
+			failed = append(failed, root.Name)
+			mu.Unlock()
+			if err == nil {
+				err = fmt.Errorf("exit status %d", code)
+			}
+			return diags.Append(err)
+		}
+		return diags
+	})
+
+	var skipped []string
+	for _, root := range project.Roots {
+		if !ran[root] {
+			skipped = append(skipped, root.Name)
+		}
+	}
+	if len(failed) == 0 && len(skipped) == 0 {
+		c.Ui.Output(fmt.Sprintf("\nRan %s in %d roots.", args[0], len(project.Roots)))
+		return 0
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		c.Ui.Error(fmt.Sprintf("\n%s failed in roots: %s", args[0], strings.Join(failed, ", ")))
+	}
+	if len(skipped) > 0 {
+		c.Ui.Error(fmt.Sprintf("Skipped roots because of failed dependencies or an interrupt: %s", strings.Join(skipped, ", ")))
+	}
+	return 1
+}
+
+// hasFlag returns true if the given arguments include the named boolean
+// flag, in any of the forms the flag package accepts.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		arg = strings.TrimLeft(arg, "-")
+		if arg == name || arg == name+"=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// reverseGraph returns a copy of g with the direction of all of its edges
+// reversed.
+func reverseGraph(g *dag.AcyclicGraph) *dag.AcyclicGraph {
+	var ret dag.AcyclicGraph
+	for _, v := range g.Vertices() {
+		ret.Add(v)
+	}
+	for _, e := range g.Edges() {
+		ret.Connect(dag.BasicEdge(e.Target(), e.Source()))
+	}
+	return &ret
+}
+
+// execRunAll returns a function that runs the Farseek binary at the given
+// path as a child process. The child processes can't prompt for input,
+// since several of them may run at once.
+func execRunAll(executable string) func(ctx context.Context, dir string, args []string, stdout, stderr io.Writer) (int, error) {
+	return func(ctx context.Context, dir string, args []string, stdout, stderr io.Writer) (int, error) {
+		cmd := exec.CommandContext(ctx, executable, args...)
+		cmd.Dir = dir
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.Env = append(os.Environ(), InputModeEnvVar+"=0", "TF_IN_AUTOMATION=1")
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		if err != nil {
+			return 1, err
+		}
+		return 0, nil
+	}
+}
+
+// prefixWriter is an io.Writer that calls write with each complete line
+// written to it, with the prefix added.
+type prefixWriter struct {
+	prefix string
+	write  func(string)
+	buf    bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write.
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		w.write(w.prefix + strings.TrimSuffix(line, "\n"))
+	}
+}
+
+// Flush writes any incomplete line left in the buffer.
+func (w *prefixWriter) Flush() {
+	if w.buf.Len() > 0 {
+		w.write(w.prefix + w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (c *RunAllCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] run-all [options] COMMAND [args]
+
+  Runs a command in each of the roots declared in the farseek.hcl project
+  file of the current directory, or of its nearest parent directory that
+  has one.
+
+  Roots run after the roots listed in their "dependencies", and roots that
+  don't depend on each other run in parallel. Destroying runs in the
+  opposite order. Roots whose dependencies fail are skipped.
+
+  Applying a root exports its outputs, which the roots that depend on it
+  read with the terraform_stack_outputs data source. Because roots run in
+  parallel, the apply and destroy commands require -auto-approve.
+
+  COMMAND is one of init, validate, plan, apply, or destroy. The arguments
+  after it are passed to the command in each root.
+
+Options:
+
+  -parallelism=n    Limit the number of roots to run at once. Defaults
+                    to 4.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RunAllCommand) Synopsis() string {
+	return "Run a command in each root of a project"
+}
@@ -0,0 +1,127 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+const runAllProject = `
+root "network" {
+  path = "network"
+}
+
+root "dns" {
+  path = "dns"
+}
+
+root "app" {
+  path         = "app"
+  dependencies = ["../network", "../dns"]
+}
+`
+
+// testRunAll runs the run-all command with the given arguments in a project
+// of three roots, where app depends on network and dns. It returns the
+// order in which the roots ran and the command's output. The roots named in
+// fail exit with an error.
+func testRunAll(t *testing.T, args []string, fail ...string) (int, []string, *cli.MockUi) {
+	t.Helper()
+	td := t.TempDir()
+	for _, dir := range []string{"network", "dns", "app"} {
+		if err := os.Mkdir(filepath.Join(td, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(td, "farseek.hcl"), []byte(runAllProject), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(td)
+
+	var mu sync.Mutex
+	var order []string
+	ui := new(cli.MockUi)
+	c := &RunAllCommand{
+		Meta: Meta{Ui: ui},
+		run: func(_ context.Context, dir string, args []string, stdout, _ io.Writer) (int, error) {
+			name := filepath.Base(dir)
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			fmt.Fprintf(stdout, "running %s\n", strings.Join(args, " "))
+			for _, f := range fail {
+				if f == name {
+					return 1, nil
+				}
+			}
+			return 0, nil
+		},
+	}
+	code := c.Run(args)
+	return code, order, ui
+}
+
+func TestRunAll(t *testing.T) {
+	code, order, ui := testRunAll(t, []string{"-parallelism=1", "apply", "-auto-approve"})
+	if code != 0 {
+		t.Fatalf("wrong exit code %d\n%s", code, ui.ErrorWriter.String())
+	}
+	if len(order) != 3 || order[2] != "app" {
+		t.Errorf("wrong order %q; app must run after its dependencies", order)
+	}
+	if output := ui.OutputWriter.String(); !strings.Contains(output, "[app] running apply -auto-approve") {
+		t.Errorf("output of the roots is missing:\n%s", output)
+	}
+}
+
+func TestRunAll_destroy(t *testing.T) {
+	code, order, ui := testRunAll(t, []string{"destroy", "-auto-approve"})
+	if code != 0 {
+		t.Fatalf("wrong exit code %d\n%s", code, ui.ErrorWriter.String())
+	}
+	if len(order) != 3 || order[0] != "app" {
+		t.Errorf("wrong order %q; app must be destroyed before its dependencies", order)
+	}
+}
+
+func TestRunAll_failedDependency(t *testing.T) {
+	code, order, ui := testRunAll(t, []string{"plan"}, "network")
+	if code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	for _, name := range order {
+		if name == "app" {
+			t.Error("app ran although one of its dependencies failed")
+		}
+	}
+	output := ui.ErrorWriter.String()
+	if !strings.Contains(output, "plan failed in roots: network") {
+		t.Errorf("output doesn't report the failed root:\n%s", output)
+	}
+	if !strings.Contains(output, "Skipped roots because of failed dependencies or an interrupt: app") {
+		t.Errorf("output doesn't report the skipped root:\n%s", output)
+	}
+}
+
+func TestRunAll_requiresAutoApprove(t *testing.T) {
+	code, order, ui := testRunAll(t, []string{"apply"})
+	if code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if len(order) != 0 {
+		t.Errorf("roots ran without approval: %q", order)
+	}
+	if output := ui.ErrorWriter.String(); !strings.Contains(output, "-auto-approve") {
+		t.Errorf("wrong error:\n%s", output)
+	}
+}
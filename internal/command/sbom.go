@@ -0,0 +1,507 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/posener/complete"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/getproviders"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	tfversion "github.com/rafagsiqueira/farseek/version"
+)
+
+// SBOMCommand is a Command implementation that prints a software bill of
+// materials of the providers and modules that the configuration depends on.
+type SBOMCommand struct {
+	Meta
+
+	// now returns the time recorded in the document. It's time.Now unless
+	// overridden by tests.
+	now func() time.Time
+}
+
+func (c *SBOMCommand) Run(args []string) int {
+	var format string
+
+	ctx := c.CommandContext()
+
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("sbom")
+	cmdFlags.StringVar(&format, "format", "cyclonedx", "format")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The sbom command expects no arguments. To describe the configuration in another directory, use the global -chdir option.\n")
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	var marshal func(*sbomInventory) (any, error)
+	switch format {
+	case "cyclonedx":
+		marshal = c.cycloneDX
+	case "spdx":
+		marshal = c.spdx
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid SBOM format",
+			fmt.Sprintf("The format %q isn't supported. The -format option must be \"cyclonedx\" or \"spdx\".", format),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	configPath := c.Meta.normalizePath(".")
+	config, configDiags := c.loadConfig(ctx, configPath)
+	diags = diags.Append(configDiags)
+	if configDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	locks, lockDiags := c.lockedDependencies()
+	diags = diags.Append(lockDiags)
+	if lockDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	inv := &sbomInventory{Name: filepath.Base(configPath)}
+	if abs, err := filepath.Abs(configPath); err == nil {
+		inv.Name = filepath.Base(abs)
+	}
+
+	// The providers are those of the lock file, which records the versions
+	// and hashes that init selected. Providers that the configuration
+	// requires but that aren't locked can't be described.
+	reqs, _, reqDiags := config.ProviderRequirements()
+	diags = diags.Append(reqDiags)
+	var missing []addrs.Provider
+	for addr := range reqs {
+		if !addr.IsBuiltIn() && locks.Provider(addr) == nil {
+			missing = append(missing, addr)
+		}
+	}
+	slices.SortFunc(missing, func(a, b addrs.Provider) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	for _, addr := range missing {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Provider missing from the dependency lock file",
+			fmt.Sprintf("The configuration requires provider %s, but the dependency lock file doesn't record a version of it, so the bill of materials doesn't include it. Run \"farseek init\" to select a version.", addr.ForDisplay()),
+		))
+	}
+	for addr, lock := range locks.AllProviders() {
+		p := sbomProvider{
+			Addr:    addr,
+			Version: lock.Version().String(),
+		}
+		for _, hash := range lock.AllHashes() {
+			p.Hashes = append(p.Hashes, hash.String())
+		}
+		slices.Sort(p.Hashes)
+		inv.Providers = append(inv.Providers, p)
+	}
+	slices.SortFunc(inv.Providers, func(a, b sbomProvider) int {
+		return strings.Compare(a.Addr.String(), b.Addr.String())
+	})
+
+	var walkErr error
+	config.DeepEach(func(cfg *configs.Config) {
+		if cfg.Path.IsRoot() || walkErr != nil {
+			return
+		}
+		m := sbomModule{
+			Path:   cfg.Path.String(),
+			Source: cfg.SourceAddr.String(),
+		}
+		if cfg.Version != nil {
+			m.Version = cfg.Version.String()
+		}
+		m.Hash, walkErr = moduleContentHash(cfg.Module.SourceDir)
+		inv.Modules = append(inv.Modules, m)
+	})
+	if walkErr != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to hash module",
+			fmt.Sprintf("Couldn't compute the content hash of an installed module: %s.", walkErr),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	slices.SortFunc(inv.Modules, func(a, b sbomModule) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	doc, err := marshal(inv)
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+	j, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		// Should never happen because we fully-control the input here
+		panic(err)
+	}
+	c.showDiagnostics(diags)
+	c.Ui.Output(string(j))
+	return 0
+}
+
+// sbomInventory is the dependencies of a configuration, before they're
+// described in one of the SBOM formats.
+type sbomInventory struct {
+	// Name is the name of the configuration, the base name of its directory.
+	Name string
+
+	Providers []sbomProvider
+	Modules   []sbomModule
+}
+
+type sbomProvider struct {
+	Addr    addrs.Provider
+	Version string
+
+	// Hashes are the hashes the lock file records for the provider, in
+	// their string form, such as "h1:..." or "zh:...".
+	Hashes []string
+}
+
+type sbomModule struct {
+	// Path is the address of the module, such as "module.network".
+	Path    string
+	Source  string
+	Version string
+
+	// Hash is the content hash of the directory of the module, in the
+	// "h1:..." form of the hashes of the lock file.
+	Hash string
+}
+
+// moduleContentHash returns the hash of the files in the given module
+// directory, computed like the "h1:" hashes of provider packages. Hidden
+// directories, such as .git, aren't part of the module and are skipped.
+func moduleContentHash(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// sha256Hex returns the SHA-256 digest of an "h1:" or "zh:" hash in
+// hexadecimal, as the SBOM formats expect, or an empty string for hashes of
+// other schemes.
+func sha256Hex(hash string) string {
+	h := getproviders.Hash(hash)
+	switch h.Scheme() {
+	case getproviders.HashSchemeZip:
+		return h.Value()
+	case getproviders.HashScheme1:
+		sum, err := base64.StdEncoding.DecodeString(h.Value())
+		if err != nil {
+			return ""
+		}
+		return hex.EncodeToString(sum)
+	default:
+		return ""
+	}
+}
+
+func (c *SBOMCommand) timestamp() string {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	return now().UTC().Format(time.RFC3339)
+}
+
+// cycloneDX describes the inventory as a CycloneDX 1.5 JSON document.
+func (c *SBOMCommand) cycloneDX(inv *sbomInventory) (any, error) {
+	type hash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+	type property struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type component struct {
+		Type       string     `json:"type"`
+		BOMRef     string     `json:"bom-ref,omitempty"`
+		Name       string     `json:"name"`
+		Version    string     `json:"version,omitempty"`
+		Hashes     []hash     `json:"hashes,omitempty"`
+		Properties []property `json:"properties,omitempty"`
+	}
+	type dependency struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	}
+	type metadata struct {
+		Timestamp string `json:"timestamp"`
+		Tools     struct {
+			Components []component `json:"components"`
+		} `json:"tools"`
+		Component component `json:"component"`
+	}
+	type document struct {
+		BOMFormat    string       `json:"bomFormat"`
+		SpecVersion  string       `json:"specVersion"`
+		SerialNumber string       `json:"serialNumber"`
+		Version      int          `json:"version"`
+		Metadata     metadata     `json:"metadata"`
+		Components   []component  `json:"components"`
+		Dependencies []dependency `json:"dependencies"`
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	root := component{
+		Type:   "application",
+		BOMRef: "configuration",
+		Name:   inv.Name,
+	}
+	doc := document{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + id,
+		Version:      1,
+		Metadata: metadata{
+			Timestamp: c.timestamp(),
+			Component: root,
+		},
+		Components: []component{},
+	}
+	doc.Metadata.Tools.Components = []component{
+		{Type: "application", Name: "farseek", Version: tfversion.String()},
+	}
+
+	deps := dependency{Ref: root.BOMRef, DependsOn: []string{}}
+	for _, p := range inv.Providers {
+		comp := component{
+			Type:    "application",
+			BOMRef:  "provider:" + p.Addr.String(),
+			Name:    p.Addr.String(),
+			Version: p.Version,
+		}
+		for _, h := range p.Hashes {
+			// Only the "zh:" hashes are digests of the packages that can
+			// be downloaded, so only they are hashes of the component.
+			if getproviders.Hash(h).HasScheme(getproviders.HashSchemeZip) {
+				comp.Hashes = append(comp.Hashes, hash{Alg: "SHA-256", Content: sha256Hex(h)})
+			}
+			comp.Properties = append(comp.Properties, property{Name: "farseek:lock_hash", Value: h})
+		}
+		doc.Components = append(doc.Components, comp)
+		deps.DependsOn = append(deps.DependsOn, comp.BOMRef)
+	}
+	for _, m := range inv.Modules {
+		comp := component{
+			Type:    "library",
+			BOMRef:  m.Path,
+			Name:    m.Source,
+			Version: m.Version,
+			Hashes:  []hash{{Alg: "SHA-256", Content: sha256Hex(m.Hash)}},
+			Properties: []property{
+				{Name: "farseek:module_address", Value: m.Path},
+				{Name: "farseek:content_hash", Value: m.Hash},
+			},
+		}
+		doc.Components = append(doc.Components, comp)
+		deps.DependsOn = append(deps.DependsOn, comp.BOMRef)
+	}
+	doc.Dependencies = []dependency{deps}
+	return doc, nil
+}
+
+// spdxIDInvalidChars matches the characters that SPDX identifiers can't
+// contain.
+var spdxIDInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdx describes the inventory as an SPDX 2.3 JSON document.
+func (c *SBOMCommand) spdx(inv *sbomInventory) (any, error) {
+	type checksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+	type pkg struct {
+		SPDXID           string     `json:"SPDXID"`
+		Name             string     `json:"name"`
+		VersionInfo      string     `json:"versionInfo,omitempty"`
+		DownloadLocation string     `json:"downloadLocation"`
+		FilesAnalyzed    bool       `json:"filesAnalyzed"`
+		Checksums        []checksum `json:"checksums,omitempty"`
+		Comment          string     `json:"comment,omitempty"`
+	}
+	type relationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+	type creationInfo struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	}
+	type document struct {
+		SPDXVersion       string         `json:"spdxVersion"`
+		DataLicense       string         `json:"dataLicense"`
+		SPDXID            string         `json:"SPDXID"`
+		Name              string         `json:"name"`
+		DocumentNamespace string         `json:"documentNamespace"`
+		CreationInfo      creationInfo   `json:"creationInfo"`
+		Packages          []pkg          `json:"packages"`
+		Relationships     []relationship `json:"relationships"`
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	spdxID := func(prefix, name string) string {
+		return "SPDXRef-" + prefix + "-" + strings.Trim(spdxIDInvalidChars.ReplaceAllString(name, "-"), "-")
+	}
+
+	root := pkg{
+		SPDXID:           "SPDXRef-Configuration",
+		Name:             inv.Name,
+		DownloadLocation: "NOASSERTION",
+	}
+	doc := document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              inv.Name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/farseek-" + inv.Name + "-" + id,
+		CreationInfo: creationInfo{
+			Created:  c.timestamp(),
+			Creators: []string{"Tool: farseek-" + tfversion.String()},
+		},
+		Packages: []pkg{root},
+		Relationships: []relationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: root.SPDXID},
+		},
+	}
+
+	for _, p := range inv.Providers {
+		pk := pkg{
+			SPDXID:           spdxID("Provider", p.Addr.String()),
+			Name:             p.Addr.String(),
+			VersionInfo:      p.Version,
+			DownloadLocation: "NOASSERTION",
+			Comment:          "Hashes in the dependency lock file: " + strings.Join(p.Hashes, ", "),
+		}
+		for _, h := range p.Hashes {
+			if getproviders.Hash(h).HasScheme(getproviders.HashSchemeZip) {
+				pk.Checksums = append(pk.Checksums, checksum{Algorithm: "SHA256", ChecksumValue: sha256Hex(h)})
+			}
+		}
+		doc.Packages = append(doc.Packages, pk)
+		doc.Relationships = append(doc.Relationships, relationship{
+			SPDXElementID: root.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: pk.SPDXID,
+		})
+	}
+	for _, m := range inv.Modules {
+		pk := pkg{
+			SPDXID:           spdxID("Module", m.Path),
+			Name:             m.Source,
+			VersionInfo:      m.Version,
+			DownloadLocation: "NOASSERTION",
+			Checksums:        []checksum{{Algorithm: "SHA256", ChecksumValue: sha256Hex(m.Hash)}},
+			Comment:          "Module " + m.Path + ", with the content hash " + m.Hash,
+		}
+		doc.Packages = append(doc.Packages, pk)
+		doc.Relationships = append(doc.Relationships, relationship{
+			SPDXElementID: root.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: pk.SPDXID,
+		})
+	}
+	return doc, nil
+}
+
+func (c *SBOMCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *SBOMCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-format": complete.PredictSet("cyclonedx", "spdx"),
+	}
+}
+
+func (c *SBOMCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] sbom [options]
+
+  Prints a software bill of materials (SBOM) of the providers and modules
+  that the configuration depends on, for tracking the supply chain of its
+  infrastructure tooling.
+
+  Providers are described with the versions and hashes recorded in the
+  dependency lock file, and modules with their source addresses, versions,
+  and the hashes of their contents. Run "farseek init" first, so that the
+  lock file and the installed modules are up to date.
+
+Options:
+
+  -format=FORMAT  The format of the bill of materials, "cyclonedx" for
+                  CycloneDX 1.5 JSON (the default) or "spdx" for SPDX 2.3
+                  JSON.
+
+  -no-color       If specified, output won't contain any color.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *SBOMCommand) Synopsis() string {
+	return "Print a software bill of materials of the dependencies"
+}
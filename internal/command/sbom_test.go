@@ -0,0 +1,203 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+func TestSBOM_cycloneDX(t *testing.T) {
+	t.Chdir(testFixturePath("sbom"))
+
+	ui := new(cli.MockUi)
+	c := &SBOMCommand{
+		Meta: Meta{Ui: ui},
+		now:  func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "Provider missing from the dependency lock file"; !strings.Contains(got, want) {
+		t.Errorf("missing warning %q in:\n%s", want, got)
+	}
+
+	var got struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Timestamp string `json:"timestamp"`
+			Component struct {
+				Name string `json:"name"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components   []map[string]any `json:"components"`
+		Dependencies []map[string]any `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, ui.OutputWriter.String())
+	}
+	if got.BOMFormat != "CycloneDX" || got.SpecVersion != "1.5" {
+		t.Errorf("wrong format %q %q", got.BOMFormat, got.SpecVersion)
+	}
+	if !strings.HasPrefix(got.SerialNumber, "urn:uuid:") {
+		t.Errorf("wrong serial number %q", got.SerialNumber)
+	}
+	if got.Metadata.Timestamp != "2026-01-02T03:04:05Z" || got.Metadata.Component.Name != "sbom" {
+		t.Errorf("wrong metadata %#v", got.Metadata)
+	}
+
+	consulHash := testModuleHash(t, ".farseek/modules/consul")
+	networkHash := testModuleHash(t, "network")
+	want := []map[string]any{
+		{
+			"type":    "application",
+			"bom-ref": "provider:registry.opentofu.org/hashicorp/test",
+			"name":    "registry.opentofu.org/hashicorp/test",
+			"version": "1.2.3",
+			"hashes": []any{
+				map[string]any{"alg": "SHA-256", "content": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+			},
+			"properties": []any{
+				map[string]any{"name": "farseek:lock_hash", "value": "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="},
+				map[string]any{"name": "farseek:lock_hash", "value": "zh:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+			},
+		},
+		{
+			"type":    "library",
+			"bom-ref": "module.consul",
+			"name":    "registry.opentofu.org/hashicorp/consul/aws",
+			"version": "0.1.0",
+			"hashes": []any{
+				map[string]any{"alg": "SHA-256", "content": sha256Hex(consulHash)},
+			},
+			"properties": []any{
+				map[string]any{"name": "farseek:module_address", "value": "module.consul"},
+				map[string]any{"name": "farseek:content_hash", "value": consulHash},
+			},
+		},
+		{
+			"type":    "library",
+			"bom-ref": "module.network",
+			"name":    "./network",
+			"hashes": []any{
+				map[string]any{"alg": "SHA-256", "content": sha256Hex(networkHash)},
+			},
+			"properties": []any{
+				map[string]any{"name": "farseek:module_address", "value": "module.network"},
+				map[string]any{"name": "farseek:content_hash", "value": networkHash},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got.Components); diff != "" {
+		t.Errorf("wrong components\n%s", diff)
+	}
+	wantDeps := []map[string]any{
+		{
+			"ref":       "configuration",
+			"dependsOn": []any{"provider:registry.opentofu.org/hashicorp/test", "module.consul", "module.network"},
+		},
+	}
+	if diff := cmp.Diff(wantDeps, got.Dependencies); diff != "" {
+		t.Errorf("wrong dependencies\n%s", diff)
+	}
+}
+
+func TestSBOM_spdx(t *testing.T) {
+	t.Chdir(testFixturePath("sbom"))
+
+	ui := new(cli.MockUi)
+	c := &SBOMCommand{
+		Meta: Meta{Ui: ui},
+	}
+	if code := c.Run([]string{"-format=spdx"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	var got struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			SPDXID      string `json:"SPDXID"`
+			Name        string `json:"name"`
+			VersionInfo string `json:"versionInfo"`
+			Checksums   []struct {
+				Algorithm     string `json:"algorithm"`
+				ChecksumValue string `json:"checksumValue"`
+			} `json:"checksums"`
+		} `json:"packages"`
+		Relationships []struct {
+			SPDXElementID      string `json:"spdxElementId"`
+			RelationshipType   string `json:"relationshipType"`
+			RelatedSPDXElement string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %s\n%s", err, ui.OutputWriter.String())
+	}
+	if got.SPDXVersion != "SPDX-2.3" {
+		t.Errorf("wrong version %q", got.SPDXVersion)
+	}
+
+	var ids []string
+	for _, pkg := range got.Packages {
+		ids = append(ids, pkg.SPDXID+" "+pkg.Name+" "+pkg.VersionInfo)
+	}
+	wantIDs := []string{
+		"SPDXRef-Configuration sbom ",
+		"SPDXRef-Provider-registry.opentofu.org-hashicorp-test registry.opentofu.org/hashicorp/test 1.2.3",
+		"SPDXRef-Module-module.consul registry.opentofu.org/hashicorp/consul/aws 0.1.0",
+		"SPDXRef-Module-module.network ./network ",
+	}
+	if diff := cmp.Diff(wantIDs, ids); diff != "" {
+		t.Errorf("wrong packages\n%s", diff)
+	}
+	if sums := got.Packages[1].Checksums; len(sums) != 1 || sums[0].Algorithm != "SHA256" || sums[0].ChecksumValue != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("wrong provider checksums %#v", sums)
+	}
+
+	var rels []string
+	for _, rel := range got.Relationships {
+		rels = append(rels, rel.SPDXElementID+" "+rel.RelationshipType+" "+rel.RelatedSPDXElement)
+	}
+	wantRels := []string{
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Configuration",
+		"SPDXRef-Configuration DEPENDS_ON SPDXRef-Provider-registry.opentofu.org-hashicorp-test",
+		"SPDXRef-Configuration DEPENDS_ON SPDXRef-Module-module.consul",
+		"SPDXRef-Configuration DEPENDS_ON SPDXRef-Module-module.network",
+	}
+	if diff := cmp.Diff(wantRels, rels); diff != "" {
+		t.Errorf("wrong relationships\n%s", diff)
+	}
+}
+
+func TestSBOM_invalidFormat(t *testing.T) {
+	t.Chdir(testFixturePath("sbom"))
+
+	ui := new(cli.MockUi)
+	c := &SBOMCommand{
+		Meta: Meta{Ui: ui},
+	}
+	if code := c.Run([]string{"-format=swid"}); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid SBOM format"; !strings.Contains(got, want) {
+		t.Errorf("missing error %q in:\n%s", want, got)
+	}
+}
+
+func testModuleHash(t *testing.T, dir string) string {
+	t.Helper()
+	hash, err := dirhash.HashDir(dir, "", dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
@@ -8,15 +8,20 @@
 package command
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/posener/complete"
+
 	"github.com/rafagsiqueira/farseek/internal/backend"
 
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
 	"github.com/rafagsiqueira/farseek/internal/command/views"
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
@@ -72,6 +77,7 @@ func (c *ShowCommand) Run(rawArgs []string) int {
 	}
 	c.viewType = args.ViewType
 	c.View.SetShowSensitive(args.ShowSensitive)
+	c.View.SetGroupBy(args.GroupBy)
 
 	//nolint:ineffassign - As this is a high-level call, we want to ensure that we are correctly using the right ctx later on when
 	ctx, span := tracing.Tracer().Start(ctx, "Show",
@@ -85,7 +91,16 @@ func (c *ShowCommand) Run(rawArgs []string) int {
 	defer span.End()
 
 	// Set up view
-	view := views.NewShow(args.ViewType, c.View)
+	var redact *jsonplan.RedactPolicy
+	if args.Redact {
+		var redactDiags tfdiags.Diagnostics
+		redact, redactDiags = loadRedactPolicy(args.RedactPolicy)
+		if redactDiags.HasErrors() {
+			c.View.Diagnostics(redactDiags)
+			return 1
+		}
+	}
+	view := views.NewShow(args.ViewType, args.FormatVersion, redact, c.View)
 
 	// Check for user-supplied plugin path
 	var err error
@@ -120,6 +135,27 @@ func (c *ShowCommand) Run(rawArgs []string) int {
 	return renderResult(view)
 }
 
+func (c *ShowCommand) AutocompleteArgs() complete.Predictor {
+	return c.completePredictPlanFile(c.CommandContext())
+}
+
+func (c *ShowCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-json":           complete.PredictNothing,
+		"-show-sensitive": complete.PredictNothing,
+		"-group-by":       complete.PredictSet("module", "provider", "action"),
+		"-state":          complete.PredictNothing,
+		"-plan":           c.completePredictPlanFile(c.CommandContext()),
+		"-config":         complete.PredictNothing,
+		"-overrides":      complete.PredictNothing,
+		"-at-sha":         complete.PredictNothing,
+		"-module":         complete.PredictDirs(""),
+		"-redact":         complete.PredictNothing,
+		"-redact-policy":  complete.PredictFiles("*.json"),
+		"-no-color":       complete.PredictNothing,
+	}
+}
+
 func (c *ShowCommand) Help() string {
 	helpText := `
 Usage: farseek [global options] show [target-selection-option] [other-options]
@@ -134,17 +170,38 @@ Target selection options:
     -state          The latest state snapshot, if any.
     -plan=FILENAME  The plan from a saved plan file.
     -config         Show the current configuration (requires -json).
+    -overrides      Show which arguments of each block of the configuration
+                    come from override files, and which from the primary
+                    files.
 
   If no target selection options are provided, -state is the default.
 
 Other options:
 
+  -at-sha=SHA         With -config or -overrides, show the configuration at
+                      the given commit rather than the current one. The
+                      configuration is read from the git history, without
+                      checking out the commit.
+
   -no-color           Disable terminal escape sequences.
 
   -json               Show the information in a machine-readable form.
 
   -show-sensitive     If specified, sensitive values will be displayed.
 
+  -group-by=module    Group the resource changes of a plan under a heading
+                      for each module, with the number of resources to add,
+                      change and destroy in it. Use "provider" or "action"
+                      to group them by provider or by action instead.
+
+  -redact             Redact the values of a plan, to share it outside of
+                      your organization (requires -json). Sensitive values
+                      are removed, other values are replaced with their
+                      hashes, and provider configuration is omitted.
+
+  -redact-policy=path Redact the values of a plan according to the policy in
+                      the given JSON file, rather than the default policy.
+
   -var 'foo=bar'      Set a value for one of the input variables in the root
                       module of the configuration. Use this option more than
                       once to set more than one variable.
@@ -188,9 +245,11 @@ func (c *ShowCommand) show(ctx context.Context, targetType arguments.ShowTargetT
 	case arguments.ShowPlan:
 		return c.showFromSavedPlanFile(ctx, targetArg, enc)
 	case arguments.ShowConfig:
-		return c.showConfiguration(ctx)
+		return c.showConfiguration(ctx, targetArg)
 	case arguments.ShowModule:
 		return c.showModule(ctx, targetArg)
+	case arguments.ShowOverrides:
+		return c.showOverrides(targetArg)
 	case arguments.ShowUnknownType:
 		// This is a legacy case where we just have a filename and need to
 		// try treating it as either a saved plan file or a local state
@@ -252,7 +311,7 @@ func (c *ShowCommand) showFromSavedPlanFile(ctx context.Context, filename string
 		return nil, diags
 	}
 
-	plan, stateFile, config, err := c.getPlanFromPath(ctx, filename, enc, rootCall)
+	plan, resourceChanges, stateFile, config, err := c.getPlanFromPath(ctx, filename, enc, rootCall)
 	if err != nil {
 		diags = diags.Append(err)
 		return nil, diags
@@ -265,7 +324,7 @@ func (c *ShowCommand) showFromSavedPlanFile(ctx context.Context, filename string
 	}
 
 	return func(view views.Show) int {
-		return view.DisplayPlan(ctx, plan, config, stateFile, schemas)
+		return view.DisplayPlan(ctx, plan, resourceChanges, config, stateFile, schemas)
 	}, diags
 }
 
@@ -273,6 +332,7 @@ func (c *ShowCommand) legacyShowFromPath(ctx context.Context, path string, enc e
 	var diags tfdiags.Diagnostics
 	var planErr, stateErr error
 	var plan *plans.Plan
+	var resourceChanges jsonplan.ResourceChangeSource
 
 	var stateFile *statefile.File
 	var config *configs.Config
@@ -289,7 +349,7 @@ func (c *ShowCommand) legacyShowFromPath(ctx context.Context, path string, enc e
 	// Path might be a local plan file or a state file. First, try to get a plan
 	// and associated data from a local plan file.
 	// If that fails, try to get the statefile from the path argument.
-	plan, stateFile, config, planErr = c.getPlanFromPath(ctx, path, enc, rootCall)
+	plan, resourceChanges, stateFile, config, planErr = c.getPlanFromPath(ctx, path, enc, rootCall)
 	if planErr != nil {
 		stateFile, stateErr = getStateFromPath(path, enc)
 		if stateErr != nil {
@@ -361,7 +421,7 @@ func (c *ShowCommand) legacyShowFromPath(ctx context.Context, path string, enc e
 	switch {
 	case plan != nil:
 		return func(view views.Show) int {
-			return view.DisplayPlan(ctx, plan, config, stateFile, schemas)
+			return view.DisplayPlan(ctx, plan, resourceChanges, config, stateFile, schemas)
 		}, diags
 	default:
 		// We treat all other cases as a state, and DisplayState
@@ -372,28 +432,31 @@ func (c *ShowCommand) legacyShowFromPath(ctx context.Context, path string, enc e
 	}
 }
 
-// getPlanFromPath returns a plan, statefile, and config if the
-// user-supplied path points to a local plan file. Note that
+// getPlanFromPath returns a plan, the source of its resource changes, a
+// statefile, and config if the user-supplied path points to a local plan
+// file. Note that
 // some of the return values will be nil no matter what; local plan files do not
 // yield a json plan, and cloud plans do not yield real plan/state/config
 // structs. An error generally suggests that the given path is either a
 // directory or a statefile.
-func (c *ShowCommand) getPlanFromPath(ctx context.Context, path string, enc encryption.Encryption, rootCall configs.StaticModuleCall) (*plans.Plan, *statefile.File, *configs.Config, error) {
+func (c *ShowCommand) getPlanFromPath(ctx context.Context, path string, enc encryption.Encryption, rootCall configs.StaticModuleCall) (*plans.Plan, jsonplan.ResourceChangeSource, *statefile.File, *configs.Config, error) {
 	var err error
 	var plan *plans.Plan
+	var resourceChanges jsonplan.ResourceChangeSource
 	var stateFile *statefile.File
 	var config *configs.Config
 
 	pf, err := planfile.OpenWrapped(path, enc.Plan())
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	if lp, ok := pf.Local(); ok {
 		plan, stateFile, config, err = getDataFromPlanfileReader(ctx, lp, rootCall)
+		resourceChanges = lp.ResourceChanges
 	}
 
-	return plan, stateFile, config, err
+	return plan, resourceChanges, stateFile, config, err
 }
 
 // maybeGetSchemas is a thin wrapper around [Meta.MaybeGetSchemas] that
@@ -419,9 +482,12 @@ func (c *ShowCommand) maybeGetSchemas(ctx context.Context, stateFile *statefile.
 }
 
 // getDataFromPlanfileReader returns a plan, statefile, and config, extracted from a local plan file.
+//
+// The plan is returned without its resource changes, which the caller reads
+// from planReader one chunk at a time instead.
 func getDataFromPlanfileReader(ctx context.Context, planReader *planfile.Reader, rootCall configs.StaticModuleCall) (*plans.Plan, *statefile.File, *configs.Config, error) {
 	// Get plan
-	plan, err := planReader.ReadPlan()
+	plan, err := planReader.ReadPlanWithoutChanges()
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -482,11 +548,23 @@ func getStateFromBackend(ctx context.Context, b backend.Backend, workspace strin
 
 // showConfiguration returns a function that will display the current configuration
 // in JSON format. This is a new feature that requires -json to be specified.
-func (c *ShowCommand) showConfiguration(ctx context.Context) (showRenderFunc, tfdiags.Diagnostics) {
+//
+// If sha isn't empty, it displays the configuration at that commit instead,
+// which we export from the git history to a temporary directory without
+// checking it out. Like a plan with -to-sha, it uses the modules and
+// providers installed in the working directory.
+func (c *ShowCommand) showConfiguration(ctx context.Context, sha string) (showRenderFunc, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
+	configDir, cleanup, exportDiags := c.showConfigDir(sha)
+	diags = diags.Append(exportDiags)
+	if exportDiags.HasErrors() {
+		return nil, diags
+	}
+	defer cleanup()
+
 	// Check if the directory is empty
-	empty, err := configs.IsEmptyDir(".")
+	empty, err := configs.IsEmptyDir(configDir)
 	if err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -505,7 +583,7 @@ func (c *ShowCommand) showConfiguration(ctx context.Context) (showRenderFunc, tf
 	}
 
 	// Load the configuration
-	config, configDiags := c.loadConfig(ctx, ".")
+	config, configDiags := c.loadConfig(ctx, configDir)
 	diags = diags.Append(configDiags)
 	if configDiags.HasErrors() {
 		return nil, diags
@@ -533,6 +611,58 @@ func (c *ShowCommand) showConfiguration(ctx context.Context) (showRenderFunc, tf
 	}, diags
 }
 
+// showConfigDir returns the directory of the configuration to show: the
+// current directory, or a temporary directory with the configuration at the
+// given commit, exported from the git history, which the returned function
+// removes.
+func (c *ShowCommand) showConfigDir(sha string) (string, func(), tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if sha == "" {
+		return ".", func() {}, diags
+	}
+
+	configDir, err := os.MkdirTemp("", "farseek-config-")
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to create a directory for the configuration at %s: %w", sha, err))
+		return "", nil, diags
+	}
+	cleanup := func() { os.RemoveAll(configDir) }
+	if err := farseek.Discovery.ExportConfigAtSHA(c.discoveryDir(), sha, configDir); err != nil {
+		cleanup()
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading the configuration", fmt.Sprintf("Failed to read the configuration at %s: %s.", sha, err)), tfdiags.CodeDiscoveryFailed))
+		return "", nil, diags
+	}
+	return configDir, cleanup, diags
+}
+
+// showOverrides returns a function that will display which arguments of the
+// blocks of the root module come from its override files, for the current
+// configuration or the configuration at the given commit.
+//
+// Only the syntax of the files is read, so this works without initializing
+// the working directory, and for configurations whose override files make
+// them invalid.
+func (c *ShowCommand) showOverrides(sha string) (showRenderFunc, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	configDir, cleanup, exportDiags := c.showConfigDir(sha)
+	diags = diags.Append(exportDiags)
+	if exportDiags.HasErrors() {
+		return nil, diags
+	}
+	defer cleanup()
+
+	sources, hclDiags := configs.NewParser(nil).LoadOverrideSources(configDir)
+	diags = diags.Append(hclDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return func(view views.Show) int {
+		return view.DisplayOverrides(sources)
+	}, diags
+}
+
 // showModule returns a function that will display metadata about the module
 // in the given directory, in JSON format.
 //
@@ -561,3 +691,35 @@ func (c *ShowCommand) showModule(ctx context.Context, dir string) (showRenderFun
 		return view.DisplaySingleModule(mod)
 	}, diags
 }
+
+// loadRedactPolicy reads the redaction policy for "farseek show -redact" from
+// the JSON file at the given path, or returns the default policy if the path
+// is empty.
+func loadRedactPolicy(path string) (*jsonplan.RedactPolicy, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	policy := &jsonplan.RedactPolicy{}
+	if path == "" {
+		return policy, diags
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read redaction policy",
+			fmt.Sprintf("Could not read the redaction policy file %s: %s.", path, err),
+		))
+		return nil, diags
+	}
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(policy); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid redaction policy",
+			fmt.Sprintf("The redaction policy file %s is invalid: %s.", path, err),
+		))
+		return nil, diags
+	}
+	return policy, diags
+}
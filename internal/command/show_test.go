@@ -21,6 +21,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/plans"
@@ -1395,6 +1396,96 @@ func TestShow_config(t *testing.T) {
 	}
 }
 
+func TestShow_configAtSHA(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("show-config-module"), td)
+	t.Chdir(td)
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.0.0"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	ic := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			Ui:               ui,
+			ProviderSource:   providerSource,
+		},
+	}
+	if code := ic.Run([]string{}); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+
+	// The configuration at the commit has another resource, and no module.
+	discoverer := &historyDiscoverer{
+		files: map[string]string{
+			"main.tf": `
+resource "test_instance" "old" {
+  foo = "baz"
+}
+`,
+		},
+	}
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = discoverer
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+			ProviderSource:   providerSource,
+		},
+	}
+	code := c.Run([]string{"-config", "-at-sha=abc123", "-json", "-no-color"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+	if got, want := discoverer.exported, "abc123"; got != want {
+		t.Errorf("wrong exported configuration %q; want %q", got, want)
+	}
+
+	var got struct {
+		RootModule struct {
+			Resources []struct {
+				Address string `json:"address"`
+			} `json:"resources"`
+			ModuleCalls map[string]interface{} `json:"module_calls"`
+		} `json:"root_module"`
+	}
+	if err := json.Unmarshal([]byte(output.Stdout()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, output.Stdout())
+	}
+	if len(got.RootModule.Resources) != 1 || got.RootModule.Resources[0].Address != "test_instance.old" {
+		t.Errorf("wrong resources in the configuration at the commit: %#v", got.RootModule.Resources)
+	}
+	if len(got.RootModule.ModuleCalls) != 0 {
+		t.Errorf("unexpected module calls in the configuration at the commit: %#v", got.RootModule.ModuleCalls)
+	}
+}
+
+// historyDiscoverer exports the given files as the configuration at any
+// commit, recording the commit it was asked for.
+type historyDiscoverer struct {
+	mockDiscoverer
+	files    map[string]string
+	exported string
+}
+
+func (m *historyDiscoverer) ExportConfigAtSHA(dir, sha, dest string) error {
+	m.exported = sha
+	for name, content := range m.files {
+		if err := os.WriteFile(filepath.Join(dest, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func TestShow_config_noArgs(t *testing.T) {
 	td := t.TempDir()
 	t.Chdir(td)
@@ -1693,3 +1784,131 @@ func TestShow_module_noJson(t *testing.T) {
 		t.Errorf("unexpected output\ngot: %s\nwant:\n%s", got, want)
 	}
 }
+
+func TestLoadRedactPolicy(t *testing.T) {
+	td := t.TempDir()
+
+	policy, diags := loadRedactPolicy("")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if diff := cmp.Diff(&jsonplan.RedactPolicy{}, policy); diff != "" {
+		t.Errorf("wrong default policy\n%s", diff)
+	}
+
+	path := filepath.Join(td, "policy.json")
+	if err := os.WriteFile(path, []byte(`{"keep_attributes": ["tags"], "hash_key": "secret"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, diags = loadRedactPolicy(path)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	want := &jsonplan.RedactPolicy{KeepAttributes: []string{"tags"}, HashKey: "secret"}
+	if diff := cmp.Diff(want, policy); diff != "" {
+		t.Errorf("wrong policy\n%s", diff)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"keep_everything": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, diags = loadRedactPolicy(path)
+	if got, want := diags.Err().Error(), "Invalid redaction policy"; !strings.Contains(got, want) {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+}
+
+func TestShow_overrides(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+	for name, src := range map[string]string{
+		"main.tf": `
+resource "test_instance" "foo" {
+  ami  = "ami-123"
+  size = "small"
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+`,
+		"main_override.tf": `
+resource "test_instance" "foo" {
+  size = "large"
+  tags = { Team = "web" }
+}
+`,
+	} {
+		if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			View: view,
+		},
+	}
+	code := c.Run([]string{"-overrides", "-no-color"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+	want := `Override files, in the order they are merged:
+  main_override.tf
+
+test_instance.foo (main.tf, overridden by main_override.tf)
+  ami      main.tf:3
+  size     main_override.tf:3, replacing main.tf:4
+  tags     main_override.tf:4, added
+
+1 other block is not overridden.
+`
+	if diff := cmp.Diff(want, output.Stdout()); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
+
+func TestShow_overridesAtSHA(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	discoverer := &historyDiscoverer{
+		files: map[string]string{
+			"main.tf": `
+locals {
+  env = "prod"
+}
+`,
+			"override.tf": `
+locals {
+  env = "staging"
+}
+`,
+		},
+	}
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = discoverer
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			View: view,
+		},
+	}
+	code := c.Run([]string{"-overrides", "-at-sha=abc123", "-json"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+	if got, want := discoverer.exported, "abc123"; got != want {
+		t.Errorf("wrong exported configuration %q; want %q", got, want)
+	}
+
+	want := `{"format_version":"1.0","override_files":["override.tf"],"blocks":[{"address":"local.env","base_file":"main.tf","override_files":["override.tf"],"arguments":[{"name":"value","source":{"file":"override.tf","line":3},"override":true,"replaces":{"file":"main.tf","line":3}}]}]}` + "\n"
+	if diff := cmp.Diff(want, output.Stdout()); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+}
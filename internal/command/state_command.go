@@ -0,0 +1,36 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+// StateCommand is a Command implementation that just shows help for
+// the subcommands nested below it.
+type StateCommand struct {
+	Meta
+}
+
+func (c *StateCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *StateCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] state <subcommand> [options] [args]
+
+  This command has subcommands for inspecting, copying, and replacing the
+  state, and for changing which resources Farseek manages, without changing
+  the resources themselves.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateCommand) Synopsis() string {
+	return "Inspect or replace the state and change which resources Farseek manages"
+}
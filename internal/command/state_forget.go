@@ -0,0 +1,249 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/clistate"
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// StateForgetCommand is a cli.Command implementation that stops Farseek
+// from managing a resource instance, without destroying it.
+type StateForgetCommand struct {
+	Meta
+}
+
+func (c *StateForgetCommand) Run(args []string) int {
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state forget")
+	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
+	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("The state forget command expects exactly one argument.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	addr, addrDiags := addrs.ParseAbsResourceInstanceStr(args[0])
+	diags = diags.Append(addrDiags)
+	if addrDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if addr.Resource.Resource.Mode != addrs.ManagedResourceMode {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid resource address",
+			fmt.Sprintf("Only managed resources can be forgotten, but %s is not a managed resource.", addr),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Check if we are in a Farseek-managed project, the same way as plan and
+	// apply do.
+	dir := c.discoveryDir()
+	_, err := farseek.Discovery.GetCurrentSHA(dir)
+	isGit := err == nil
+	sha, err := farseek.ReadSHA(dir)
+	if err != nil {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Farseek error reading SHA", err.Error()), tfdiags.CodeSHAReadFailed))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if c.Meta.testingOverrides != nil && os.Getenv("FARSEEK_TEST_FORCE_MODE") != "true" {
+		isGit = false
+		sha = ""
+	}
+
+	if isGit || sha != "" {
+		return c.forgetDiscovered(addr, diags)
+	}
+	return c.forgetInState(ctx, addr, diags)
+}
+
+// forgetDiscovered records the given resource in the ignore file, so that
+// discovery leaves it out of future plans.
+func (c *StateForgetCommand) forgetDiscovered(addr addrs.AbsResourceInstance, diags tfdiags.Diagnostics) int {
+	// Discovery finds resource blocks, not their instances, so we can only
+	// forget whole resources.
+	if addr.Resource.Key != addrs.NoKey {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Can't forget a single resource instance",
+			fmt.Sprintf("Farseek discovers changes to whole resource blocks, so it can't forget only %s. Use the address of the resource, %s, to forget all of its instances.", addr, addr.ContainingResource()),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	path := filepath.Join(c.DataDir(), farseek.IgnoredFilename)
+	added, err := farseek.AddIgnored(path, addr.String())
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to update %s: %s", path, err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	if !added {
+		c.Ui.Output(fmt.Sprintf("Farseek already ignores %s.", addr))
+		return 0
+	}
+	c.Ui.Output(fmt.Sprintf("Farseek will no longer create, update, or destroy %s. It is recorded in %s.", addr, path))
+	return 0
+}
+
+// forgetInState removes the given resource instance from the state of the
+// current workspace.
+func (c *StateForgetCommand) forgetInState(ctx context.Context, addr addrs.AbsResourceInstance, diags tfdiags.Diagnostics) int {
+	enc, encDiags := c.Encryption(ctx)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	workspace, err := c.Workspace(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+
+	stateMgr, err := b.StateMgr(ctx, workspace)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state forget"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	state := stateMgr.State()
+	ss := state.SyncWrapper()
+	is := ss.ResourceInstance(addr)
+	if is == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No such resource instance",
+			fmt.Sprintf("There is no resource instance in the state with the address %s.", addr),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	ss.ForgetResourceInstanceAll(addr)
+	ss.RemoveResourceIfEmpty(addr.ContainingResource())
+
+	if err := stateMgr.WriteState(state); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing state file: %s", err))
+		return 1
+	}
+	if err := stateMgr.PersistState(context.TODO(), nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing state file: %s", err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(fmt.Sprintf("Removed %s from the state. The remote object was not destroyed.", addr))
+	return 0
+}
+
+func (c *StateForgetCommand) AutocompleteArgs() complete.Predictor {
+	return completePredictSequence{
+		c.completePredictResourceAddress(c.CommandContext()),
+	}
+}
+
+func (c *StateForgetCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-lock":         completePredictBoolean,
+		"-lock-timeout": complete.PredictAnything,
+	}
+}
+
+func (c *StateForgetCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] state forget [options] ADDRESS
+
+  Stops Farseek from managing the given resource, without destroying the
+  remote object.
+
+  In a git repository, or in a configuration root with a recorded commit,
+  Farseek records the resource in the data directory, in
+  .farseek/ignored.json. Farseek then leaves the resource out of the
+  changes it discovers, so it neither creates, updates, nor destroys it,
+  even when its resource block changes or is removed. Because Farseek
+  discovers resource blocks, the address can't have an instance key.
+
+  Otherwise, Farseek removes the resource instance from the state of the
+  current workspace.
+
+Options:
+
+  -lock=false             Don't hold a state lock during the operation. This is
+                          dangerous if others might concurrently run commands
+                          against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -ignore-remote-version  A rare option used for the remote backend only. See
+                          the remote backend documentation for more information.
+
+  -state, state-out, and -backup are legacy options supported for the local
+  backend only. For more information, see the local backend's documentation.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateForgetCommand) Synopsis() string {
+	return "Stop managing a resource without destroying it"
+}
@@ -0,0 +1,133 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestStateForget(t *testing.T) {
+	testCwdTemp(t)
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, name := range []string{"foo", "bar"} {
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: "test_instance",
+					Name: name,
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"` + name + `"}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+	statePath := testStateFile(t, state)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateForgetCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.bar:
+  ID = bar
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, statePath, expected)
+}
+
+func TestStateForget_missing(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, states.NewState())
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateForgetCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "No such resource instance"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+}
+
+func TestStateForget_farseek(t *testing.T) {
+	td := testCwdTemp(t)
+
+	oldDiscovery := farseek.Discovery
+	defer func() { farseek.Discovery = oldDiscovery }()
+	farseek.Discovery = mockDiscoverer{}
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateForgetCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	for _, addr := range []string{"test_instance.foo", "module.child.test_instance.bar", "test_instance.foo"} {
+		if code := c.Run([]string{addr}); code != 0 {
+			t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+		}
+	}
+
+	ignored, err := farseek.ReadIgnored(filepath.Join(td, DefaultDataDir, farseek.IgnoredFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"module.child.test_instance.bar", "test_instance.foo"}, ignored); diff != "" {
+		t.Errorf("wrong ignored resources\n%s", diff)
+	}
+
+	// Discovery works with resource blocks, so a single instance can't be
+	// forgotten.
+	ui.ErrorWriter.Reset()
+	if code := c.Run([]string{"test_instance.baz[0]"}); code != 1 {
+		t.Fatalf("wrong exit status %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Can't forget a single resource instance"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q\n%s", want, got)
+	}
+}
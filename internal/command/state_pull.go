@@ -0,0 +1,155 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// StatePullCommand is a cli.Command implementation that reads the state of
+// the current workspace and prints it, or writes it to a file.
+type StatePullCommand struct {
+	Meta
+}
+
+func (c *StatePullCommand) Run(args []string) int {
+	var outPath string
+
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("state pull")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
+	cmdFlags.StringVar(&outPath, "output", "", "path")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The state pull command expects no arguments.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	enc, encDiags := c.Encryption(ctx)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	workspace, err := c.Workspace(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+
+	stateMgr, err := b.StateMgr(ctx, workspace)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+		return 1
+	}
+
+	stateFile := statemgr.Export(stateMgr)
+	if stateFile == nil || stateFile.State == nil {
+		stateFile = statemgr.NewStateFile()
+	}
+
+	c.showDiagnostics(diags)
+
+	// A file is encrypted in the same way as the state it was pulled from,
+	// so that it's no less protected on disk and "state push" can read it
+	// back with the same configuration. The standard output is for reading
+	// the state, so it's always plain JSON.
+	if outPath != "" {
+		if err := writeStatePullOutput(outPath, stateFile, enc.State()); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to write state to %s: %s", outPath, err))
+			return 1
+		}
+		return 0
+	}
+
+	var buf bytes.Buffer
+	if err := statefile.Write(stateFile, &buf, encryption.StateEncryptionDisabled()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write state: %s", err))
+		return 1
+	}
+	c.Ui.Output(buf.String())
+	return 0
+}
+
+// writeStatePullOutput writes the given state file to path, readable only
+// by the current user, since it may contain sensitive values.
+func writeStatePullOutput(path string, stateFile *statefile.File, enc encryption.StateEncryption) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := statefile.Write(stateFile, f, enc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *StatePullCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *StatePullCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-output": complete.PredictFiles("*"),
+	}
+}
+
+func (c *StatePullCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] state pull [options]
+
+  Reads the state of the current workspace and prints it as JSON.
+
+  With -output, Farseek writes the state to the given file instead,
+  encrypted in the same way as the state itself if state encryption is
+  configured, so that "farseek state push" can read it back.
+
+Options:
+
+  -output=path            Write the state to the given file rather than to
+                          the standard output.
+
+  -state is a legacy option supported for the local backend only. For more
+  information, see the local backend's documentation.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StatePullCommand) Synopsis() string {
+	return "Read the state of the current workspace"
+}
@@ -0,0 +1,91 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+// testStatePushPullState returns a state with a test_instance resource of
+// each of the given names.
+func testStatePushPullState(names ...string) *states.State {
+	return states.BuildState(func(s *states.SyncState) {
+		for _, name := range names {
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: "test_instance",
+					Name: name,
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"` + name + `"}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+}
+
+func TestStatePull(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo"))
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePullCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	got := ui.OutputWriter.String()
+	for _, want := range []string{`"lineage":"fake-for-testing"`, `"type":"test_instance"`, `"name":"foo"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestStatePull_output(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo"))
+	outPath := filepath.Join(t.TempDir(), "pulled.tfstate")
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePullCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, "-output", outPath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.foo:
+  ID = foo
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, outPath, expected)
+}
@@ -0,0 +1,321 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/clistate"
+	"github.com/rafagsiqueira/farseek/internal/command/views"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// StatePushCommand is a cli.Command implementation that replaces the state
+// of the current workspace with the contents of a state file, after showing
+// how they differ and asking for confirmation.
+type StatePushCommand struct {
+	Meta
+}
+
+func (c *StatePushCommand) Run(args []string) int {
+	var force bool
+
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.ignoreRemoteVersionFlagSet("state push")
+	cmdFlags.BoolVar(&force, "force", false, "")
+	cmdFlags.Var(arguments.NewFlagInput(&c.Meta.input, &c.Meta.inputStrict), "input", "input")
+	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("The state push command expects exactly one argument.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	// A state read from the standard input leaves nothing to read the
+	// confirmation from.
+	c.Meta.input = c.Meta.input && args[0] != stdinArg
+	if !force && !c.Meta.input {
+		c.Ui.Error(strings.TrimSpace(errStatePushInputDisabled))
+		return 1
+	}
+
+	enc, encDiags := c.Encryption(ctx)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// A file is read with the state encryption configuration, the same as
+	// "state pull -output" writes it, and the standard input as plain JSON,
+	// the same as "state pull" prints it.
+	var r io.Reader = os.Stdin
+	srcEnc := encryption.StateEncryptionDisabled()
+	if args[0] != stdinArg {
+		f, err := os.Open(args[0])
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		defer f.Close()
+		r = f
+		srcEnc = enc.State()
+	}
+	srcStateFile, err := statefile.Read(r, srcEnc)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading source state %q: %s", args[0], err))
+		return 1
+	}
+
+	b, backendDiags := c.Backend(ctx, nil, enc.State())
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	workspace, err := c.Workspace(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+
+	stateMgr, err := b.StateMgr(ctx, workspace)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load destination state: %s", err))
+		return 1
+	}
+
+	if c.stateLock {
+		stateLocker := clistate.NewLocker(c.stateLockTimeout, views.NewStateLocker(arguments.ViewHuman, c.View))
+		if diags := stateLocker.Lock(stateMgr, "state push"); diags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer func() {
+			if diags := stateLocker.Unlock(); diags.HasErrors() {
+				c.showDiagnostics(diags)
+			}
+		}()
+	}
+
+	if err := stateMgr.RefreshState(context.TODO()); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to refresh destination state: %s", err))
+		return 1
+	}
+	dstStateFile := statemgr.Export(stateMgr)
+
+	if !force {
+		if err := statemgr.CheckValidImport(srcStateFile, dstStateFile); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to push state: %s\n\nUse -force to push it anyway.", err))
+			return 1
+		}
+	}
+
+	c.showDiagnostics(diags)
+	c.Ui.Output(statePushSummary(workspace, dstStateFile, srcStateFile))
+
+	if !force {
+		v, err := c.UIInput().Input(context.Background(), &farseek.InputOpts{
+			Id:          "state-push",
+			Query:       "Do you want to replace the state with this one?",
+			Description: "Farseek will overwrite the state of the workspace as shown above.\nOnly 'yes' will be accepted to confirm.",
+		})
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error asking for confirmation: %s", err))
+			return 1
+		}
+		if v != "yes" {
+			c.Ui.Output("State push cancelled.")
+			return 1
+		}
+	}
+
+	if err := statemgr.Import(srcStateFile, stateMgr, force); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write state: %s", err))
+		return 1
+	}
+	if err := stateMgr.PersistState(context.TODO(), nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to persist state: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Pushed the state to workspace %q.", workspace))
+	return 0
+}
+
+// statePushSummary describes how pushing src would change dst, the current
+// state of the workspace: the resource instances it would add, remove, or
+// change, and the lineage and serial of each.
+func statePushSummary(workspace string, dst, src *statefile.File) string {
+	dstInstances := stateResourceInstances(dst)
+	srcInstances := stateResourceInstances(src)
+
+	var added, removed, changed []string
+	for addr, is := range srcInstances {
+		switch existing, ok := dstInstances[addr]; {
+		case !ok:
+			added = append(added, addr)
+		case !existing.Equal(is):
+			changed = append(changed, addr)
+		}
+	}
+	for addr := range dstInstances {
+		if _, ok := srcInstances[addr]; !ok {
+			removed = append(removed, addr)
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Pushing this state will change the state of workspace %q:\n\n", workspace)
+	fmt.Fprintf(&buf, "  Lineage: %s\n", statePushCompare(stateFileLineage(dst), stateFileLineage(src)))
+	fmt.Fprintf(&buf, "  Serial:  %s\n", statePushCompare(fmt.Sprint(stateFileSerial(dst)), fmt.Sprint(stateFileSerial(src))))
+	if len(added)+len(removed)+len(changed) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, group := range []struct {
+		symbol string
+		addrs  []string
+	}{
+		{"+", added},
+		{"-", removed},
+		{"~", changed},
+	} {
+		sort.Strings(group.addrs)
+		for _, addr := range group.addrs {
+			fmt.Fprintf(&buf, "  %s %s\n", group.symbol, addr)
+		}
+	}
+	fmt.Fprintf(&buf, "\n%d to add, %d to remove, %d to change.", len(added), len(removed), len(changed))
+	return buf.String()
+}
+
+func statePushCompare(before, after string) string {
+	if before == after {
+		return before + " (unchanged)"
+	}
+	return before + " -> " + after
+}
+
+// stateResourceInstances returns the resource instances in the given state
+// file, by their addresses.
+func stateResourceInstances(f *statefile.File) map[string]*states.ResourceInstance {
+	ret := map[string]*states.ResourceInstance{}
+	if f == nil || f.State == nil {
+		return ret
+	}
+	for _, ms := range f.State.Modules {
+		for _, rs := range ms.Resources {
+			for key, is := range rs.Instances {
+				ret[rs.Addr.Instance(key).String()] = is
+			}
+		}
+	}
+	return ret
+}
+
+func stateFileLineage(f *statefile.File) string {
+	if f == nil || f.Lineage == "" {
+		return "(none)"
+	}
+	return f.Lineage
+}
+
+func stateFileSerial(f *statefile.File) uint64 {
+	if f == nil {
+		return 0
+	}
+	return f.Serial
+}
+
+const errStatePushInputDisabled = `
+Can't ask for confirmation to push the state when interactive input is
+disabled, which it is when the state is read from the standard input.
+
+To push the state without confirmation, add the "-force" option. Otherwise,
+remove the "-input=false" option, or pass the path of a state file, and try
+again.
+`
+
+func (c *StatePushCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *StatePushCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-force":        completePredictBoolean,
+		"-lock":         completePredictBoolean,
+		"-lock-timeout": complete.PredictAnything,
+	}
+}
+
+func (c *StatePushCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] state push [options] PATH
+
+  Replaces the state of the current workspace with the state file at PATH,
+  or read from the standard input if PATH is "-".
+
+  Farseek first shows the resources that the new state adds, removes, or
+  changes, along with the lineage and serial of both states, and asks for
+  confirmation. It refuses to push a state with a different lineage, or an
+  older serial, than the current state, unless -force is given.
+
+  If state encryption is configured, the file is decrypted with it, like a
+  file written by "farseek state pull -output". The standard input is read
+  as plain JSON, like "farseek state pull" prints it, and since there is
+  then no way to confirm the push, it requires -force.
+
+Options:
+
+  -force                  Push the state without asking for confirmation,
+                          even if its lineage differs or its serial is older.
+
+  -input=true             Ask for confirmation. With -input=false, the push
+                          requires -force.
+
+  -lock=false             Don't hold a state lock during the operation. This is
+                          dangerous if others might concurrently run commands
+                          against the same workspace.
+
+  -lock-timeout=0s        Duration to retry a state lock.
+
+  -ignore-remote-version  A rare option used for the remote backend only. See
+                          the remote backend documentation for more information.
+
+  -state is a legacy option supported for the local backend only. For more
+  information, see the local backend's documentation.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StatePushCommand) Synopsis() string {
+	return "Replace the state of the current workspace"
+}
@@ -0,0 +1,320 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+)
+
+// testStatePushSource writes a state file to push, with the given lineage
+// and serial, and returns its path.
+func testStatePushSource(t *testing.T, state *states.State, lineage string, serial uint64) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "push.tfstate")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sf := &statefile.File{
+		Lineage: lineage,
+		Serial:  serial,
+		State:   state,
+	}
+	if err := statefile.Write(sf, f, encryption.StateEncryptionDisabled()); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStatePush(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo", "bar"))
+	srcPath := testStatePushSource(t, testStatePushPullState("bar", "baz"), "fake-for-testing", 1)
+
+	defer testInputMap(t, map[string]string{
+		"state-push": "yes",
+	})()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, srcPath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		"Lineage: fake-for-testing (unchanged)",
+		"Serial:  0 -> 1",
+		"+ test_instance.baz",
+		"- test_instance.foo",
+		"1 to add, 1 to remove, 0 to change.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.bar:
+  ID = bar
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+test_instance.baz:
+  ID = baz
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, statePath, expected)
+}
+
+func TestStatePush_cancelled(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo"))
+	srcPath := testStatePushSource(t, testStatePushPullState("bar"), "fake-for-testing", 1)
+
+	defer testInputMap(t, map[string]string{
+		"state-push": "no",
+	})()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, srcPath}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "State push cancelled."; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, got)
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.foo:
+  ID = foo
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, statePath, expected)
+}
+
+func TestStatePush_lineageMismatch(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo"))
+	srcPath := testStatePushSource(t, testStatePushPullState("bar"), "other", 5)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, srcPath}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n\n%s", code, ui.OutputWriter.String())
+	}
+	if got, want := ui.ErrorWriter.String(), "cannot import state with lineage"; !strings.Contains(got, want) {
+		t.Errorf("error doesn't contain %q:\n%s", want, got)
+	}
+
+	// With -force, the state is replaced without asking.
+	ui = new(cli.MockUi)
+	c = &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+	if code := c.Run([]string{"-state", statePath, "-force", srcPath}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got, want := ui.OutputWriter.String(), "Lineage: fake-for-testing -> other"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, got)
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.bar:
+  ID = bar
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, statePath, expected)
+}
+
+func TestStatePush_stdinRequiresForce(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo"))
+	srcPath := testStatePushSource(t, testStatePushPullState("bar"), "fake-for-testing", 1)
+	src, err := os.ReadFile(srcPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	defer testStdinPipe(t, bytes.NewReader(src))()
+	if code := c.Run([]string{"-state", statePath, "-"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), `add the "-force" option`; !strings.Contains(got, want) {
+		t.Errorf("error doesn't contain %q:\n%s", want, got)
+	}
+
+	ui = new(cli.MockUi)
+	c = &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+	if code := c.Run([]string{"-state", statePath, "-force", "-"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.bar:
+  ID = bar
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, statePath, expected)
+}
+
+func TestStatePush_inputDisabled(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, testStatePushPullState("foo"))
+	srcPath := testStatePushSource(t, testStatePushPullState("bar"), "fake-for-testing", 1)
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StatePushCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, "-input=false", srcPath}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "interactive input is\ndisabled"; !strings.Contains(got, want) {
+		t.Errorf("error doesn't contain %q:\n%s", want, got)
+	}
+
+	expected := strings.TrimSpace(`
+test_instance.foo:
+  ID = foo
+  provider = provider["registry.opentofu.org/hashicorp/test"]
+	`)
+	testStateOutput(t, statePath, expected)
+}
+
+func TestStatePushPull_encrypted(t *testing.T) {
+	// With state encryption configured, the state pulled to the standard
+	// output can be pushed back from the standard input, and the state
+	// pulled to a file can be pushed back from that file.
+	td := t.TempDir()
+	t.Chdir(td)
+	err := os.WriteFile("main.tf", []byte(`
+terraform {
+  encryption {
+    key_provider "pbkdf2" "key" {
+      passphrase = "correct-horse-battery-staple"
+    }
+    method "aes_gcm" "main" {
+      keys = key_provider.pbkdf2.key
+    }
+    state {
+      method   = method.aes_gcm.main
+      enforced = true
+    }
+  }
+}
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(td, "encrypted.tfstate")
+	view, _ := testView(t)
+
+	push := func(t *testing.T, stdin []byte, args ...string) {
+		t.Helper()
+		if stdin != nil {
+			defer testStdinPipe(t, bytes.NewReader(stdin))()
+		}
+		ui := new(cli.MockUi)
+		c := &StatePushCommand{Meta: Meta{Ui: ui, View: view}}
+		if code := c.Run(append([]string{"-state", statePath}, args...)); code != 0 {
+			t.Fatalf("push failed: %d\n\n%s", code, ui.ErrorWriter.String())
+		}
+	}
+	pull := func(t *testing.T, args ...string) string {
+		t.Helper()
+		ui := new(cli.MockUi)
+		c := &StatePullCommand{Meta: Meta{Ui: ui, View: view}}
+		if code := c.Run(append([]string{"-state", statePath}, args...)); code != 0 {
+			t.Fatalf("pull failed: %d\n\n%s", code, ui.ErrorWriter.String())
+		}
+		if ui.OutputWriter == nil {
+			return ""
+		}
+		return ui.OutputWriter.String()
+	}
+
+	src, err := os.ReadFile(testStatePushSource(t, testStatePushPullState("foo"), "fake-for-testing", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	push(t, src, "-force", "-")
+	if raw, err := os.ReadFile(statePath); err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(string(raw), "test_instance") {
+		t.Fatalf("state was written in plain text:\n%s", raw)
+	}
+
+	pulled := pull(t)
+	if !strings.Contains(pulled, `"name":"foo"`) {
+		t.Fatalf("pulled state isn't plain JSON:\n%s", pulled)
+	}
+	push(t, []byte(pulled), "-force", "-")
+
+	outPath := filepath.Join(td, "pulled.tfstate")
+	pull(t, "-output", outPath)
+	if raw, err := os.ReadFile(outPath); err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(string(raw), "test_instance") {
+		t.Fatalf("pulled state was written in plain text:\n%s", raw)
+	}
+	defer testInputMap(t, map[string]string{
+		"state-push": "yes",
+	})()
+	push(t, nil, outPath)
+}
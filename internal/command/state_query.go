@@ -0,0 +1,285 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/posener/complete"
+
+	"github.com/rafagsiqueira/farseek/internal/plans/planfile"
+	"github.com/rafagsiqueira/farseek/internal/statequery"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// StateQueryCommand is a cli.Command implementation that runs queries over
+// the state, or over the prior state of a saved plan, and prints the results
+// as JSON.
+type StateQueryCommand struct {
+	Meta
+}
+
+func (c *StateQueryCommand) Run(args []string) int {
+	var planPath string
+
+	ctx := c.CommandContext()
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("state query")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
+	cmdFlags.StringVar(&planPath, "plan", "", "path")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	args = cmdFlags.Args()
+	if len(args) > 1 {
+		c.Ui.Error("The state query command expects at most one argument.")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	// We parse the query before loading the state, so that a mistake in it
+	// doesn't wait on the backend.
+	var query *statequery.Query
+	if len(args) == 1 {
+		var err error
+		query, err = statequery.Parse(args[0])
+		if err != nil {
+			diags = diags.Append(invalidStateQuery(err))
+			c.showDiagnostics(diags)
+			return 1
+		}
+	}
+
+	enc, encDiags := c.Encryption(ctx)
+	diags = diags.Append(encDiags)
+	if encDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	var stateFile *statefile.File
+	if planPath != "" {
+		pf, err := planfile.OpenWrapped(planPath, enc.Plan())
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to read plan file: %s", err))
+			return 1
+		}
+		lp, ok := pf.Local()
+		if !ok {
+			c.Ui.Error("The state query command can only read local plan files.")
+			return 1
+		}
+		// The prior state of a plan is the state as Farseek refreshed it
+		// while planning, which in stateless mode is the only state there is.
+		stateFile, err = lp.ReadStateFile()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to read the state from the plan file: %s", err))
+			return 1
+		}
+	} else {
+		b, backendDiags := c.Backend(ctx, nil, enc.State())
+		diags = diags.Append(backendDiags)
+		if backendDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+
+		workspace, err := c.Workspace(ctx)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+			return 1
+		}
+
+		stateFile, err = getStateFromBackend(ctx, b, workspace)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+			return 1
+		}
+	}
+
+	var state *states.State
+	if stateFile != nil {
+		state = stateFile.State
+	}
+	doc, err := statequery.Document(state)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read the state: %s", err))
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+
+	if query != nil {
+		out, err := stateQueryResult(query, doc)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+		return 0
+	}
+	if c.StdinPiped() {
+		return c.modePiped(doc)
+	}
+	return c.modeInteractive(doc)
+}
+
+// modePiped runs each line of the standard input as a query, stopping at
+// the first one that is invalid.
+func (c *StateQueryCommand) modePiped(doc any) int {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		out, err := c.handle(line, doc)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		c.Ui.Output(out)
+	}
+	return 0
+}
+
+// modeInteractive prompts for queries until the user exits, showing errors
+// in queries without exiting.
+func (c *StateQueryCommand) modeInteractive(doc any) int {
+	l, err := readline.NewEx(&readline.Config{
+		Prompt:            "> ",
+		InterruptPrompt:   "^C",
+		EOFPrompt:         "exit",
+		HistorySearchFold: true,
+		Stdin:             os.Stdin,
+		Stdout:            os.Stdout,
+		Stderr:            os.Stderr,
+	})
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing state query: %s", err))
+		return 1
+	}
+	defer l.Close()
+
+	for {
+		line, err := l.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			if len(line) == 0 {
+				break
+			}
+			continue
+		} else if errors.Is(err, io.EOF) {
+			break
+		}
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+			continue
+		case "exit":
+			return 0
+		}
+		out, err := c.handle(line, doc)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			continue
+		}
+		c.Ui.Output(out)
+	}
+	return 0
+}
+
+func (c *StateQueryCommand) handle(src string, doc any) (string, error) {
+	query, err := statequery.Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("Invalid query: %w", err)
+	}
+	return stateQueryResult(query, doc)
+}
+
+func stateQueryResult(query *statequery.Query, doc any) (string, error) {
+	out, err := json.MarshalIndent(query.Eval(doc), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode the result of the query: %w", err)
+	}
+	return string(out), nil
+}
+
+func invalidStateQuery(err error) tfdiags.Diagnostic {
+	return tfdiags.Sourceless(
+		tfdiags.Error,
+		"Invalid query",
+		fmt.Sprintf("The query is invalid: %s.", err),
+	)
+}
+
+func (c *StateQueryCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *StateQueryCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-plan":  complete.PredictFiles("*"),
+		"-state": complete.PredictFiles("*"),
+	}
+}
+
+func (c *StateQueryCommand) Help() string {
+	helpText := `
+Usage: farseek [global options] state query [options] [QUERY]
+
+  Runs a query over the state of the current workspace and prints the
+  result as JSON.
+
+  The query selects values from a document with two attributes: resources,
+  a list of the resource instances in the state, and outputs, an object of
+  the root module output values. Each resource instance has the attributes
+  address, module, mode, type, name, index, provider, status, and attrs.
+
+  Select attributes with a dot, and list elements with an index in
+  brackets. [*] selects every element, and a condition in brackets selects
+  the elements for which it's true. After either, the rest of the query
+  applies to each selected element. For example:
+
+      farseek state query 'resources[type=="aws_instance" && attrs.tags.env=="prod"].attrs.id'
+
+  Conditions can compare with ==, !=, <, <=, >, >=, and =~ for a regular
+  expression, and combine comparisons with &&, ||, and !.
+
+  Without a query, Farseek reads queries from the standard input, one per
+  line, and prints the result of each.
+
+  In stateless mode, there is no state to query. Save a plan with
+  farseek plan -out=FILE and use the -plan option to query the state that
+  Farseek read while planning.
+
+Options:
+
+  -plan=path          Query the prior state of the given saved plan instead
+                      of the state of the current workspace.
+
+  -state=statefile    Path to a Farseek state file to use to look up
+                      Farseek-managed resources. By default, Farseek will
+                      consult the state of the currently-selected workspace.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateQueryCommand) Synopsis() string {
+	return "Query the state and print the result as JSON"
+}
@@ -0,0 +1,93 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestStateQuery(t *testing.T) {
+	testCwdTemp(t)
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for name, env := range map[string]string{"foo": "prod", "bar": "dev"} {
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: "test_instance",
+					Name: name,
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"id":"` + name + `","tags":{"env":"` + env + `"}}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: addrs.NewDefaultProvider("test"),
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+	statePath := testStateFile(t, state)
+
+	tests := map[string]string{
+		`resources[type=="test_instance" && attrs.tags.env=="prod"].attrs.id`: `[
+  "foo"
+]`,
+		`resources[*].address`: `[
+  "test_instance.bar",
+  "test_instance.foo"
+]`,
+		`resources[0].provider`: `"provider[\"registry.opentofu.org/hashicorp/test\"]"`,
+		`outputs`:               `{}`,
+	}
+	for query, want := range tests {
+		t.Run(query, func(t *testing.T) {
+			ui := new(cli.MockUi)
+			view, _ := testView(t)
+			c := &StateQueryCommand{
+				Meta: Meta{
+					Ui:   ui,
+					View: view,
+				},
+			}
+
+			if code := c.Run([]string{"-state", statePath, query}); code != 0 {
+				t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+			}
+			if diff := cmp.Diff(want, strings.TrimSpace(ui.OutputWriter.String())); diff != "" {
+				t.Errorf("wrong output\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStateQuery_invalid(t *testing.T) {
+	testCwdTemp(t)
+	statePath := testStateFile(t, states.NewState())
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	c := &StateQueryCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+
+	if code := c.Run([]string{"-state", statePath, "resources[type =="}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid query"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
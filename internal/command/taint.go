@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/posener/complete"
+
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/clistate"
@@ -193,6 +195,21 @@ func (c *TaintCommand) Run(args []string) int {
 	return 0
 }
 
+func (c *TaintCommand) AutocompleteArgs() complete.Predictor {
+	return completePredictSequence{
+		c.completePredictResourceAddress(c.CommandContext()),
+	}
+}
+
+func (c *TaintCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-allow-missing": complete.PredictNothing,
+		"-lock":          completePredictBoolean,
+		"-lock-timeout":  complete.PredictAnything,
+		"-no-color":      complete.PredictNothing,
+	}
+}
+
 func (c *TaintCommand) Help() string {
 	helpText := `
 Usage: farseek [global options] taint [options] <address>
@@ -0,0 +1,15 @@
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+
+resource "aws_iam_role" "deploy" {
+  name = "deploy"
+}
+
+module "network" {
+  source = "./network"
+}
@@ -0,0 +1,7 @@
+variable "ami" {
+  type = string
+}
+
+resource "test_instance" "web" {
+  ami = var.ami
+}
@@ -0,0 +1,16 @@
+data "test_data_source" "lookup" {
+  id = "ami"
+}
+
+resource "test_instance" "foo" {
+  ami = data.test_data_source.lookup.id
+}
+
+locals {
+  ami = test_instance.foo.id
+}
+
+module "app" {
+  source = "./app"
+  ami    = local.ami
+}
@@ -0,0 +1,2 @@
+data "between_thing" "d" {
+}
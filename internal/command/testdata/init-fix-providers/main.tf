@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    exact = {
+      source  = "hashicorp/exact"
+      version = "1.2.3"
+    }
+  }
+}
+
+resource "exact_thing" "a" {
+}
+
+resource "greater_thing" "b" {
+}
+
+resource "terraform_data" "c" {
+}
+
+module "child" {
+  source = "./child"
+}
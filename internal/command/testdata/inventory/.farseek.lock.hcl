@@ -0,0 +1,10 @@
+# This file is maintained automatically by "farseek init".
+# Manual edits may be lost in future updates.
+
+provider "registry.opentofu.org/hashicorp/test" {
+  version = "1.2.3"
+  hashes = [
+    "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+    "zh:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+  ]
+}
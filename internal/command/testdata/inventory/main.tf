@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    test = {
+      source  = "hashicorp/test"
+      version = "~> 1.0"
+    }
+  }
+}
+
+resource "test_instance" "web" {
+}
+
+resource "test_instance" "db" {
+}
+
+data "test_data_source" "ami" {
+}
+
+module "network" {
+  source = "./network"
+}
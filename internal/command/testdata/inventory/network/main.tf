@@ -0,0 +1,2 @@
+resource "test_instance" "vpc" {
+}
@@ -0,0 +1,10 @@
+terraform {
+  required_providers {
+    test = {
+      source  = "hashicorp/test"
+      version = ">= 1.2.0"
+    }
+  }
+}
+
+resource "test_instance" "baz" {}
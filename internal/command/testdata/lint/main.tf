@@ -0,0 +1,39 @@
+terraform {
+  required_providers {
+    test = {
+      source  = "hashicorp/test"
+      version = "~> 1.0"
+    }
+    other = {
+      source = "hashicorp2/test"
+    }
+  }
+}
+
+variable "used" {}
+
+variable "unused" {
+  validation {
+    condition     = var.unused != ""
+    error_message = "Must not be empty."
+  }
+}
+
+locals {
+  used   = var.used
+  unused = "unused"
+}
+
+resource "test_instance" "foo" {
+  ami = local.used
+}
+
+resource "test_legacy" "bar" {}
+
+output "bar" {
+  value = test_legacy.bar.id
+}
+
+module "child" {
+  source = "./child"
+}
@@ -0,0 +1,26 @@
+terraform {
+  required_providers {
+    test = {
+      source = "hashicorp/test"
+    }
+  }
+}
+
+variable "region" {
+  type        = string
+  description = "The region to deploy to."
+}
+
+resource "test_instance" "foo" {
+  ami = var.region
+}
+
+module "network" {
+  source = "./network"
+  cidr   = "10.0.0.0/16"
+}
+
+output "vpc_id" {
+  value       = module.network.vpc_id
+  description = "The ID of the VPC."
+}
@@ -0,0 +1,9 @@
+variable "cidr" {
+  type    = string
+  default = "10.0.0.0/8"
+}
+
+output "vpc_id" {
+  value     = var.cidr
+  sensitive = true
+}
@@ -0,0 +1,14 @@
+module "current" {
+  source  = "test-versions/name/provider"
+  version = "~> 2.2"
+}
+
+module "old" {
+  source  = "test-versions/name/provider"
+  version = "~> 1.2.0"
+}
+
+module "deprecated" {
+  source  = "deprecated/name/provider"
+  version = "1.0.0"
+}
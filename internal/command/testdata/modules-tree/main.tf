@@ -0,0 +1,22 @@
+variable "regions" {
+  type    = set(string)
+  default = ["eu", "us"]
+}
+
+resource "test_instance" "foo" {
+}
+
+module "network" {
+  source   = "./network"
+  for_each = var.regions
+}
+
+module "app" {
+  source = "./app"
+  count  = 2
+}
+
+module "pending" {
+  source = "./app"
+  count  = length(test_instance.foo.id)
+}
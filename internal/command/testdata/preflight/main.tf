@@ -0,0 +1,10 @@
+variable "region" {
+  type = string
+}
+
+provider "test" {
+  region = var.region
+}
+
+resource "test_instance" "foo" {
+}
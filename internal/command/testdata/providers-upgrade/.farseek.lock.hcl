@@ -0,0 +1,16 @@
+# This file is maintained automatically by "farseek init".
+# Manual edits may be lost in future updates.
+
+provider "registry.opentofu.org/hashicorp/other" {
+  version = "2.0.0"
+  hashes = [
+    "h1:other",
+  ]
+}
+
+provider "registry.opentofu.org/hashicorp/test" {
+  version = "1.0.0"
+  hashes = [
+    "h1:invalid",
+  ]
+}
@@ -0,0 +1,3 @@
+output "servers" {
+  value = 3
+}
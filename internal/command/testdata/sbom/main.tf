@@ -0,0 +1,19 @@
+terraform {
+  required_providers {
+    test = {
+      source = "hashicorp/test"
+    }
+    missing = {
+      source = "hashicorp/missing"
+    }
+  }
+}
+
+module "network" {
+  source = "./network"
+}
+
+module "consul" {
+  source  = "hashicorp/consul/aws"
+  version = "0.1.0"
+}
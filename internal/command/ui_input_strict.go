@@ -0,0 +1,116 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/colorstring"
+
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// errInputStrict is returned for every prompt in strict input mode.
+var errInputStrict = errors.New("input is not allowed with -input=strict")
+
+// StrictUIInput is an implementation of farseek.UIInput for -input=strict,
+// which records every prompt instead of asking for input, so that all the
+// missing values can be reported together at the end of the operation.
+type StrictUIInput struct {
+	mu      sync.Mutex
+	prompts []*farseek.InputOpts
+}
+
+var _ farseek.UIInput = (*StrictUIInput)(nil)
+
+func (i *StrictUIInput) Input(ctx context.Context, opts *farseek.InputOpts) (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, prompt := range i.prompts {
+		if prompt.Id == opts.Id {
+			return "", errInputStrict
+		}
+	}
+	i.prompts = append(i.prompts, opts)
+	return "", errInputStrict
+}
+
+// Diagnostics returns an error listing every prompt recorded since the last
+// call, or no diagnostics if there were none.
+func (i *StrictUIInput) Diagnostics() tfdiags.Diagnostics {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var diags tfdiags.Diagnostics
+	if len(i.prompts) == 0 {
+		return diags
+	}
+
+	var buf strings.Builder
+	buf.WriteString("Farseek needed the following input, but -input=strict disables interactive prompts:\n")
+	for _, prompt := range i.prompts {
+		fmt.Fprintf(&buf, "\n  - %s", prompt.Id)
+		if summary := inputPromptSummary(prompt); summary != "" {
+			fmt.Fprintf(&buf, ": %s", summary)
+		}
+	}
+	buf.WriteString("\n\nSet these values with -var, -var-file, environment variables, -backend-config or the configuration, as appropriate, and run the command again.")
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		fmt.Sprintf("Missing input for %d value(s)", len(i.prompts)),
+		buf.String(),
+	))
+	i.prompts = nil
+	return diags
+}
+
+// inputPromptSummary returns the first line of the description of the
+// prompt, or of its query if it has no description, without color codes.
+func inputPromptSummary(prompt *farseek.InputOpts) string {
+	text := prompt.Description
+	if strings.TrimSpace(text) == "" {
+		text = prompt.Query
+	}
+	noColor := &colorstring.Colorize{
+		Colors:  colorstring.DefaultColors,
+		Disable: true,
+	}
+	text, _, _ = strings.Cut(strings.TrimSpace(noColor.Color(text)), "\n")
+	text = strings.TrimSpace(text)
+	if text == prompt.Id {
+		return ""
+	}
+	return text
+}
+
+// strictInputDiagnostics returns an error listing the prompts recorded in
+// strict input mode, if any.
+func (m *Meta) strictInputDiagnostics() tfdiags.Diagnostics {
+	if m.strictInput == nil {
+		return nil
+	}
+	return m.strictInput.Diagnostics()
+}
+
+// reportStrictInput shows the prompts recorded in strict input mode, if
+// any, returning the exit status the command should end with. Commands
+// without a view pass a nil view, to show them with the legacy UI.
+func (m *Meta) reportStrictInput(view interface{ Diagnostics(tfdiags.Diagnostics) }, code int) int {
+	diags := m.strictInputDiagnostics()
+	if len(diags) == 0 {
+		return code
+	}
+	if view != nil {
+		view.Diagnostics(diags)
+	} else {
+		m.showDiagnostics(diags)
+	}
+	return 1
+}
@@ -9,6 +9,7 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -29,10 +30,12 @@ type UnlockCommand struct {
 func (c *UnlockCommand) Run(args []string) int {
 	ctx := c.CommandContext()
 	args = c.Meta.process(args)
-	var force bool
+	var force, status, jsonOutput bool
 	cmdFlags := c.Meta.defaultFlagSet("force-unlock")
 	c.Meta.varFlagSet(cmdFlags)
 	cmdFlags.BoolVar(&force, "force", false, "force")
+	cmdFlags.BoolVar(&status, "status", false, "status")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -40,13 +43,19 @@ func (c *UnlockCommand) Run(args []string) int {
 	}
 
 	args = cmdFlags.Args()
-	if len(args) != 1 {
-		c.Ui.Error("Expected a single argument: LOCK_ID")
+	if jsonOutput && !status {
+		c.Ui.Error("The -json option can only be used with -status")
 		return cli.RunResultHelp
 	}
-
-	lockID := args[0]
-	args = args[1:]
+	var lockID string
+	if !status {
+		if len(args) != 1 {
+			c.Ui.Error("Expected a single argument: LOCK_ID")
+			return cli.RunResultHelp
+		}
+		lockID = args[0]
+		args = args[1:]
+	}
 
 	// assume everything is initialized. The user can manually init if this is
 	// required.
@@ -105,6 +114,10 @@ func (c *UnlockCommand) Run(args []string) int {
 		}
 	}
 
+	if status {
+		return c.showLockStatus(ctx, env, stateMgr, jsonOutput)
+	}
+
 	// Proceed with unlocking logic if locking is enabled
 	if !force {
 		// Forcing this doesn't do anything, but doesn't break anything either,
@@ -142,9 +155,55 @@ func (c *UnlockCommand) Run(args []string) int {
 	return 0
 }
 
+// showLockStatus prints who holds the lock on the state of the given
+// workspace, if anyone does.
+func (c *UnlockCommand) showLockStatus(ctx context.Context, workspace string, stateMgr statemgr.Full, jsonOutput bool) int {
+	inspector, ok := stateMgr.(statemgr.LockInspector)
+	if !ok {
+		c.Ui.Error("This backend can't report the status of its state lock")
+		return 1
+	}
+	holder, err := inspector.LockHolder(ctx)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to read the state lock: %s", err))
+		return 1
+	}
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(lockStatus{
+			Workspace: workspace,
+			Locked:    holder != nil,
+			Lock:      holder,
+		}, "", "  ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal the state lock status: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+		return 0
+	}
+
+	if holder == nil {
+		c.Ui.Output(fmt.Sprintf("The state for workspace %q is not locked.", workspace))
+		return 0
+	}
+	c.Ui.Output(fmt.Sprintf("The state for workspace %q is locked.\n", workspace))
+	c.Ui.Output(strings.TrimSpace(holder.String()))
+	return 0
+}
+
+// lockStatus is the JSON representation of the output of
+// "force-unlock -status".
+type lockStatus struct {
+	Workspace string             `json:"workspace"`
+	Locked    bool               `json:"locked"`
+	Lock      *statemgr.LockInfo `json:"lock"`
+}
+
 func (c *UnlockCommand) Help() string {
 	helpText := `
 Usage: farseek [global options] force-unlock [options] LOCK_ID
+       farseek [global options] force-unlock -status [options]
 
   Manually unlock the state for the defined configuration.
 
@@ -153,10 +212,20 @@ Usage: farseek [global options] force-unlock [options] LOCK_ID
   on the backend being used. Local state files cannot be unlocked by another
   process.
 
+  With -status, this command instead shows who holds the lock on the state
+  for the current workspace, if anyone does, including the LOCK_ID needed
+  to unlock it.
+
 Options:
 
   -force                 Don't ask for input for unlock confirmation.
 
+  -status                Show the current lock holder instead of unlocking
+                         the state.
+
+  -json                  With -status, produce the output in a
+                         machine-readable JSON format.
+
   -var 'foo=bar'         Set a value for one of the input variables in the root
                          module of the configuration. Use this option more than
                          once to set more than one variable.
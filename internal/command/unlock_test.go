@@ -0,0 +1,113 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestUnlock_statusNotLocked(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &UnlockCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := c.Run([]string{"-status"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	if got, want := strings.TrimSpace(ui.OutputWriter.String()), `The state for workspace "default" is not locked.`; got != want {
+		t.Errorf("wrong output\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestUnlock_statusLocked(t *testing.T) {
+	td := t.TempDir()
+	lockerPath, err := filepath.Abs(testFixturePath("statelocker.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(td)
+
+	// The state must be locked by another process, since the locks of a
+	// process don't conflict with each other.
+	locker := exec.Command("go", "run", lockerPath, filepath.Join(td, "farseek.tfstate"))
+	locker.Dir = pkgDir
+	locker.Stderr = os.Stderr
+	stdin, err := locker.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := locker.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := locker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		stdin.Close()
+		locker.Wait()
+	}()
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to lock the state: %s", err)
+	}
+	lockID := strings.TrimSpace(strings.TrimPrefix(line, "LOCKID "))
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &UnlockCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := c.Run([]string{"-status"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	output := ui.OutputWriter.String()
+	for _, want := range []string{`The state for workspace "default" is locked.`, "ID:        " + lockID, "Operation: test"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q\n%s", want, output)
+		}
+	}
+
+	ui = cli.NewMockUi()
+	c = &UnlockCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := c.Run([]string{"-status", "-json"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	var got lockStatus
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter)
+	}
+	if got.Workspace != "default" || !got.Locked || got.Lock == nil || got.Lock.ID != lockID || got.Lock.Info != "state locker" {
+		t.Errorf("wrong JSON output\n%s", ui.OutputWriter)
+	}
+}
+
+func TestUnlock_jsonWithoutStatus(t *testing.T) {
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	c := &UnlockCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := c.Run([]string{"-json", "abc"}); code != cli.RunResultHelp {
+		t.Fatalf("wrong exit code %d", code)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/posener/complete"
+
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/clistate"
@@ -194,6 +196,21 @@ func (c *UntaintCommand) Run(args []string) int {
 	return 0
 }
 
+func (c *UntaintCommand) AutocompleteArgs() complete.Predictor {
+	return completePredictSequence{
+		c.completePredictResourceAddress(c.CommandContext()),
+	}
+}
+
+func (c *UntaintCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"-allow-missing": complete.PredictNothing,
+		"-lock":          completePredictBoolean,
+		"-lock-timeout":  complete.PredictAnything,
+		"-no-color":      complete.PredictNothing,
+	}
+}
+
 func (c *UntaintCommand) Help() string {
 	helpText := `
 Usage: farseek [global options] untaint [options] name
@@ -205,6 +205,10 @@ Options:
                         accompanied by errors, show them in a more compact
                         form that includes only the summary messages.
 
+  -suppress-warning=CODE
+                        Leave out warnings with the given code or summary.
+                        Can be used multiple times.
+
   -consolidate-warnings If Farseek produces any warnings, no consolidation
                         will be performed. All locations, for all warnings
                         will be listed. Enabled by default.
@@ -0,0 +1,129 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	encJson "encoding/json"
+	"fmt"
+
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/format"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonentities"
+	"github.com/rafagsiqueira/farseek/internal/command/views/json"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// The Agent view renders the output of an operation that runs on a remote
+// agent, which arrives as a stream of machine-readable UI messages.
+type Agent interface {
+	// Log renders a single machine-readable UI message from the remote
+	// operation.
+	Log(line []byte)
+
+	// Output renders a line of other output from the agent, such as from
+	// preparing its working directory.
+	Output(line string)
+
+	Diagnostics(diags tfdiags.Diagnostics)
+}
+
+// NewAgent returns an initialized Agent implementation for the given ViewType.
+func NewAgent(vt arguments.ViewType, view *View) Agent {
+	switch vt {
+	case arguments.ViewJSON:
+		return &AgentJSON{view: NewJSONView(view)}
+	case arguments.ViewHuman:
+		return &AgentHuman{view: view}
+	default:
+		panic(fmt.Sprintf("unknown view type %v", vt))
+	}
+}
+
+// The AgentHuman implementation renders the message text of each remote
+// UI message, and the full text of any diagnostics.
+type AgentHuman struct {
+	view *View
+}
+
+var _ Agent = (*AgentHuman)(nil)
+
+// agentMessage is the subset of a machine-readable UI message that the
+// human view needs.
+type agentMessage struct {
+	Level      string                   `json:"@level"`
+	Message    string                   `json:"@message"`
+	Type       json.MessageType         `json:"type"`
+	Diagnostic *jsonentities.Diagnostic `json:"diagnostic"`
+}
+
+func (v *AgentHuman) Log(line []byte) {
+	var msg agentMessage
+	if err := encJson.Unmarshal(line, &msg); err != nil {
+		// Not a UI message, so we'll show it as-is.
+		v.view.streams.Println(string(line))
+		return
+	}
+
+	switch msg.Type {
+	case json.MessageVersion:
+		// The version of the remote Farseek is not interesting here.
+	case json.MessageDiagnostic:
+		if msg.Diagnostic == nil {
+			break
+		}
+		var text string
+		if v.view.colorize.Disable {
+			text = format.DiagnosticPlainFromJSON(msg.Diagnostic, v.view.errorColumns())
+		} else {
+			text = format.DiagnosticFromJSON(msg.Diagnostic, v.view.colorize, v.view.errorColumns())
+		}
+		if msg.Diagnostic.Severity == jsonentities.DiagnosticSeverityError {
+			v.view.streams.Eprint(text)
+		} else {
+			v.view.streams.Print(text)
+		}
+	default:
+		v.view.streams.Println(msg.Message)
+	}
+}
+
+func (v *AgentHuman) Output(line string) {
+	v.view.streams.Println(line)
+}
+
+func (v *AgentHuman) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
+// The AgentJSON implementation passes the remote UI messages through
+// unchanged, so that the result is indistinguishable from running the
+// operation locally with the -json option. Other output from the agent is
+// logged as plain messages.
+type AgentJSON struct {
+	view *JSONView
+}
+
+var _ Agent = (*AgentJSON)(nil)
+
+func (v *AgentJSON) Log(line []byte) {
+	var msg agentMessage
+	if err := encJson.Unmarshal(line, &msg); err == nil && msg.Type == json.MessageVersion {
+		// We already logged our own version message when the view was
+		// created, so the remote one would be redundant.
+		return
+	} else if err == nil && !v.view.emits(msg.Type) {
+		// The remote Farseek always uses the latest protocol, so we leave
+		// out what isn't part of the one that was asked for.
+		return
+	}
+	v.view.view.streams.Println(string(line))
+}
+
+func (v *AgentJSON) Output(line string) {
+	v.view.Log(line)
+}
+
+func (v *AgentJSON) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
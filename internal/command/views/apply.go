@@ -10,6 +10,7 @@ package views
 import (
 	"fmt"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/format"
 	"github.com/rafagsiqueira/farseek/internal/command/views/json"
@@ -22,6 +23,7 @@ import (
 type Apply interface {
 	ResourceCount(stateOutPath string)
 	Outputs(outputValues map[string]*states.OutputValue)
+	DestroyOrder(waves [][]addrs.AbsResourceInstance, conflicts []farseek.DestroyOrderConflict)
 
 	Operation() Operation
 	Hooks() []farseek.Hook
@@ -112,9 +114,29 @@ func (v *ApplyHuman) ResourceCount(stateOutPath string) {
 }
 
 func (v *ApplyHuman) Outputs(outputValues map[string]*states.OutputValue) {
-	if len(outputValues) > 0 {
+	if len(outputValues) > 0 && !v.view.quiet {
 		v.view.streams.Print(v.view.colorize.Color("[reset][bold][green]\nOutputs:\n\n"))
-		NewOutput(arguments.ViewHuman, v.view).Output("", outputValues)
+		NewOutput(arguments.ViewHuman, v.view).Output("", nil, outputValues)
+	}
+}
+
+func (v *ApplyHuman) DestroyOrder(waves [][]addrs.AbsResourceInstance, conflicts []farseek.DestroyOrderConflict) {
+	if len(waves) == 0 {
+		v.view.streams.Println("No resources would be destroyed.")
+		return
+	}
+	v.view.streams.Print(v.view.colorize.Color("[reset][bold]Destroy order:[reset]\n"))
+	for i, wave := range waves {
+		v.view.streams.Printf("\n  Wave %d:\n", i+1)
+		for _, addr := range wave {
+			v.view.streams.Printf("    - %s\n", addr)
+		}
+	}
+	if len(conflicts) > 0 {
+		v.view.streams.Print(v.view.colorize.Color("\n[reset][bold][yellow]create_before_destroy conflicts:[reset]\n"))
+		for _, conflict := range conflicts {
+			v.view.streams.Printf("  - %s uses create_before_destroy, but depends on %s, which disables it\n", conflict.Resource, conflict.Dependency)
+		}
 	}
 }
 
@@ -176,6 +198,17 @@ func (v *ApplyJSON) Outputs(outputValues map[string]*states.OutputValue) {
 	}
 }
 
+func (v *ApplyJSON) DestroyOrder(waves [][]addrs.AbsResourceInstance, conflicts []farseek.DestroyOrderConflict) {
+	order := json.NewDestroyOrder(waves)
+	for _, conflict := range conflicts {
+		order.Conflicts = append(order.Conflicts, json.DestroyOrderConflict{
+			Resource:   conflict.Resource.String(),
+			Dependency: conflict.Dependency.String(),
+		})
+	}
+	v.view.DestroyOrder(order)
+}
+
 func (v *ApplyJSON) Operation() Operation {
 	return &OperationJSON{view: v.view}
 }
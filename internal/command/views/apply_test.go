@@ -16,6 +16,7 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/lang/marks"
 	"github.com/rafagsiqueira/farseek/internal/states"
 	"github.com/rafagsiqueira/farseek/internal/terminal"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -70,6 +71,32 @@ func TestApplyHuman_outputsEmpty(t *testing.T) {
 	}
 }
 
+// Quiet output leaves out the outputs and the warnings, but not the summary
+// of the changes or the errors.
+func TestApplyHuman_quiet(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	view.SetQuiet(true)
+	v := NewApply(arguments.ViewHuman, false, view)
+
+	v.Outputs(map[string]*states.OutputValue{
+		"foo": {Value: cty.StringVal("secret")},
+	})
+	v.ResourceCount("")
+	v.Diagnostics(tfdiags.Diagnostics{
+		tfdiags.Sourceless(tfdiags.Warning, "A warning", "Something to look at."),
+		tfdiags.Sourceless(tfdiags.Error, "An error", "Something went wrong."),
+	})
+
+	output := done(t)
+	if got, want := output.Stdout(), "\nApply complete! Resources: 0 added, 0 changed, 0 destroyed.\n"; got != want {
+		t.Errorf("wrong stdout\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := output.Stderr(), "An error"; !strings.Contains(got, want) {
+		t.Errorf("wrong stderr\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
 // Ensure that the correct view type and in-automation settings propagate to the
 // Operation view.
 func TestApplyHuman_operation(t *testing.T) {
@@ -29,10 +29,11 @@ const heartbeatInterval = 10 * time.Second
 
 func newJSONHook(view *JSONView) *jsonHook {
 	return &jsonHook{
-		view:      view,
-		applying:  make(map[string]applyProgress),
-		timeNow:   time.Now,
-		timeAfter: time.After,
+		view:         view,
+		applying:     make(map[string]applyProgress),
+		timeNow:      time.Now,
+		timeAfter:    time.After,
+		summaryAfter: time.After,
 	}
 }
 
@@ -46,12 +47,23 @@ type jsonHook struct {
 	// progress, and post-apply messages to share data about the resource
 	applying map[string]applyProgress
 
-	// Mockable functions for testing the progress timer goroutine
-	timeNow   func() time.Time
-	timeAfter func(time.Duration) <-chan time.Time
+	// Counts of resource instances by apply status, reported periodically
+	// in state_summary messages. These are also guarded by applyingLock.
+	summary        applySummary
+	summaryRunning bool
+
+	// annotations returns the annotations of a resource in the
+	// configuration, once the apply has begun.
+	annotations func(addrs.ConfigResource) map[string]string
+
+	// Mockable functions for testing the progress timer goroutines
+	timeNow      func() time.Time
+	timeAfter    func(time.Duration) <-chan time.Time
+	summaryAfter func(time.Duration) <-chan time.Time
 }
 
 var _ farseek.Hook = (*jsonHook)(nil)
+var _ farseek.AnnotationsHook = (*jsonHook)(nil)
 
 type applyProgress struct {
 	addr   addrs.AbsResourceInstance
@@ -69,10 +81,27 @@ type applyProgress struct {
 	elapsed chan time.Duration
 }
 
+type applySummary struct {
+	pending   int
+	completed int
+	failed    int
+}
+
+func (h *jsonHook) SetAnnotations(annotations func(addrs.ConfigResource) map[string]string) {
+	h.annotations = annotations
+}
+
+func (h *jsonHook) resourceAnnotations(addr addrs.AbsResourceInstance) map[string]string {
+	if h.annotations == nil {
+		return nil
+	}
+	return h.annotations(addr.ConfigResource())
+}
+
 func (h *jsonHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (farseek.HookAction, error) {
 	if action != plans.NoOp {
 		idKey, idValue := format.ObjectValueIDOrName(priorState)
-		h.view.Hook(json.NewApplyStart(addr, action, idKey, idValue))
+		h.view.Hook(json.NewApplyStart(addr, action, idKey, idValue, h.resourceAnnotations(addr)))
 	}
 
 	progress := applyProgress{
@@ -85,14 +114,46 @@ func (h *jsonHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generatio
 	}
 	h.applyingLock.Lock()
 	h.applying[addr.String()] = progress
+	startSummary := false
+	if action != plans.NoOp {
+		h.summary.pending++
+		if !h.summaryRunning {
+			h.summaryRunning = true
+			startSummary = true
+		}
+	}
 	h.applyingLock.Unlock()
 
 	if action != plans.NoOp {
 		go h.applyingHeartbeat(progress)
 	}
+	if startSummary {
+		go h.summaryHeartbeat()
+	}
 	return farseek.HookActionContinue, nil
 }
 
+// summaryHeartbeat periodically emits a state_summary message for as long as
+// there is at least one resource instance still being applied. A new
+// goroutine is started by PreApply if a resource instance begins applying
+// after this one has exited.
+func (h *jsonHook) summaryHeartbeat() {
+	for {
+		<-h.summaryAfter(heartbeatInterval)
+
+		h.applyingLock.Lock()
+		summary := h.summary
+		if summary.pending == 0 {
+			h.summaryRunning = false
+			h.applyingLock.Unlock()
+			return
+		}
+		h.applyingLock.Unlock()
+
+		h.view.Hook(json.NewStateSummary(summary.pending, summary.completed, summary.failed))
+	}
+}
+
 func (h *jsonHook) applyingHeartbeat(progress applyProgress) {
 	defer close(progress.heartbeatDone)
 	defer close(progress.elapsed)
@@ -117,6 +178,14 @@ func (h *jsonHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generati
 		close(progress.done)
 	}
 	delete(h.applying, key)
+	if progress.done != nil && progress.action != plans.NoOp {
+		h.summary.pending--
+		if err != nil {
+			h.summary.failed++
+		} else {
+			h.summary.completed++
+		}
+	}
 	h.applyingLock.Unlock()
 
 	if progress.action == plans.NoOp {
@@ -129,10 +198,10 @@ func (h *jsonHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generati
 		// Errors are collected and displayed post-apply, so no need to
 		// re-render them here. Instead just signal that this resource failed
 		// to apply.
-		h.view.Hook(json.NewApplyErrored(addr, progress.action, elapsed))
+		h.view.Hook(json.NewApplyErrored(addr, progress.action, elapsed, h.resourceAnnotations(addr)))
 	} else {
 		idKey, idValue := format.ObjectValueID(newState)
-		h.view.Hook(json.NewApplyComplete(addr, progress.action, idKey, idValue, elapsed))
+		h.view.Hook(json.NewApplyComplete(addr, progress.action, idKey, idValue, elapsed, h.resourceAnnotations(addr)))
 	}
 	return farseek.HookActionContinue, nil
 }
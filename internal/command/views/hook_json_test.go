@@ -282,6 +282,197 @@ func TestJSONHook_errors(t *testing.T) {
 	testJSONViewOutputEquals(t, done(t).Stdout(), want)
 }
 
+func TestJSONHook_annotations(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	hook := newJSONHook(NewJSONView(NewView(streams)))
+	hook.SetAnnotations(func(addr addrs.ConfigResource) map[string]string {
+		if addr.String() != "test_instance.boop" {
+			return nil
+		}
+		return map[string]string{"team": "payments"}
+	})
+
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "boop",
+	}.Instance(addrs.IntKey(0)).Absolute(addrs.RootModuleInstance)
+	priorState := cty.NullVal(cty.Object(map[string]cty.Type{
+		"id": cty.String,
+	}))
+	plannedNewState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.StringVal("test"),
+	})
+
+	action, err := hook.PreApply(addr, states.CurrentGen, plans.Create, priorState, plannedNewState)
+	testHookReturnValues(t, action, err)
+	action, err = hook.PostApply(addr, states.CurrentGen, plannedNewState, fmt.Errorf("provider was sad"))
+	testHookReturnValues(t, action, err)
+
+	wantResource := map[string]interface{}{
+		"addr":             string("test_instance.boop[0]"),
+		"implied_provider": string("test"),
+		"module":           string(""),
+		"resource":         string("test_instance.boop[0]"),
+		"resource_key":     float64(0),
+		"resource_name":    string("boop"),
+		"resource_type":    string("test_instance"),
+	}
+	wantAnnotations := map[string]interface{}{"team": "payments"}
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "test_instance.boop[0]: Creating...",
+			"@module":  "farseek.ui",
+			"type":     "apply_start",
+			"hook": map[string]interface{}{
+				"action":      string("create"),
+				"resource":    wantResource,
+				"annotations": wantAnnotations,
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "test_instance.boop[0]: Creation errored after 0s",
+			"@module":  "farseek.ui",
+			"type":     "apply_errored",
+			"hook": map[string]interface{}{
+				"action":          string("create"),
+				"elapsed_seconds": float64(0),
+				"resource":        wantResource,
+				"annotations":     wantAnnotations,
+			},
+		},
+	}
+
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
+func TestJSONHook_stateSummary(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	hook := newJSONHook(NewJSONView(NewView(streams)))
+
+	now := time.Now()
+	hook.timeNow = func() time.Time { return now }
+	hook.timeAfter = func(time.Duration) <-chan time.Time { return nil }
+
+	// Each call to summaryAfter marks the end of the previous iteration of
+	// the summary goroutine, which lets us wait for its output.
+	after := make(chan time.Time)
+	waiting := make(chan struct{}, 1)
+	hook.summaryAfter = func(time.Duration) <-chan time.Time {
+		waiting <- struct{}{}
+		return after
+	}
+
+	boop := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "boop",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	beep := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "beep",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	priorState := cty.NullVal(cty.Object(map[string]cty.Type{
+		"id": cty.String,
+	}))
+	plannedNewState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.StringVal("test"),
+	})
+
+	action, err := hook.PreApply(boop, states.CurrentGen, plans.Create, priorState, plannedNewState)
+	testHookReturnValues(t, action, err)
+	action, err = hook.PreApply(beep, states.CurrentGen, plans.Create, priorState, plannedNewState)
+	testHookReturnValues(t, action, err)
+	<-waiting
+
+	action, err = hook.PostApply(boop, states.CurrentGen, plannedNewState, nil)
+	testHookReturnValues(t, action, err)
+
+	after <- now
+	<-waiting
+
+	action, err = hook.PostApply(beep, states.CurrentGen, plannedNewState, fmt.Errorf("provider was sad"))
+	testHookReturnValues(t, action, err)
+
+	// Nothing is left pending, so the summary goroutine exits without
+	// producing any more output.
+	after <- now
+
+	resource := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"addr":             "test_instance." + name,
+			"implied_provider": "test",
+			"module":           "",
+			"resource":         "test_instance." + name,
+			"resource_key":     nil,
+			"resource_name":    name,
+			"resource_type":    "test_instance",
+		}
+	}
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "test_instance.boop: Creating...",
+			"@module":  "farseek.ui",
+			"type":     "apply_start",
+			"hook": map[string]interface{}{
+				"action":   "create",
+				"resource": resource("boop"),
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "test_instance.beep: Creating...",
+			"@module":  "farseek.ui",
+			"type":     "apply_start",
+			"hook": map[string]interface{}{
+				"action":   "create",
+				"resource": resource("beep"),
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "test_instance.boop: Creation complete after 0s [id=test]",
+			"@module":  "farseek.ui",
+			"type":     "apply_complete",
+			"hook": map[string]interface{}{
+				"action":          "create",
+				"elapsed_seconds": float64(0),
+				"id_key":          "id",
+				"id_value":        "test",
+				"resource":        resource("boop"),
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "Apply progress: 1 pending, 1 completed, 0 failed",
+			"@module":  "farseek.ui",
+			"type":     "state_summary",
+			"hook": map[string]interface{}{
+				"pending":   float64(1),
+				"completed": float64(1),
+				"failed":    float64(0),
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "test_instance.beep: Creation errored after 0s",
+			"@module":  "farseek.ui",
+			"type":     "apply_errored",
+			"hook": map[string]interface{}{
+				"action":          "create",
+				"elapsed_seconds": float64(0),
+				"resource":        resource("beep"),
+			},
+		},
+	}
+
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
 func TestJSONHook_refresh(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	hook := newJSONHook(NewJSONView(NewView(streams)))
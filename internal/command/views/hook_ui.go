@@ -40,7 +40,7 @@ func NewUiHook(view *View) *UiHook {
 }
 
 func NewUIOptionalHook(view *View) farseek.Hook {
-	if view.concise {
+	if view.concise || view.quiet {
 		return &farseek.NilHook{}
 	}
 	return NewUiHook(view)
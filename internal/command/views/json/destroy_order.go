@@ -0,0 +1,45 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"fmt"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+// DestroyOrder describes the order in which a destroy would remove
+// resources, as waves of resources that are destroyed in parallel.
+type DestroyOrder struct {
+	Waves     [][]string             `json:"waves"`
+	Conflicts []DestroyOrderConflict `json:"conflicts,omitempty"`
+}
+
+// DestroyOrderConflict is a create_before_destroy resource that depends on a
+// resource which disables create_before_destroy.
+type DestroyOrderConflict struct {
+	Resource   string `json:"resource"`
+	Dependency string `json:"dependency"`
+}
+
+func NewDestroyOrder(waves [][]addrs.AbsResourceInstance) *DestroyOrder {
+	ret := &DestroyOrder{
+		Waves: make([][]string, len(waves)),
+	}
+	for i, wave := range waves {
+		ret.Waves[i] = make([]string, len(wave))
+		for j, addr := range wave {
+			ret.Waves[i][j] = addr.String()
+		}
+	}
+	return ret
+}
+
+func (o *DestroyOrder) String() string {
+	count := 0
+	for _, wave := range o.Waves {
+		count += len(wave)
+	}
+	return fmt.Sprintf("Destroy order: %d resources in %d waves", count, len(o.Waves))
+}
@@ -23,11 +23,12 @@ type Hook interface {
 
 // ApplyStart: triggered by PreApply hook
 type applyStart struct {
-	Resource   jsonentities.ResourceAddr `json:"resource"`
-	Action     jsonentities.ChangeAction `json:"action"`
-	IDKey      string                    `json:"id_key,omitempty"`
-	IDValue    string                    `json:"id_value,omitempty"`
-	actionVerb string
+	Resource    jsonentities.ResourceAddr `json:"resource"`
+	Action      jsonentities.ChangeAction `json:"action"`
+	IDKey       string                    `json:"id_key,omitempty"`
+	IDValue     string                    `json:"id_value,omitempty"`
+	Annotations map[string]string         `json:"annotations,omitempty"`
+	actionVerb  string
 }
 
 var _ Hook = (*applyStart)(nil)
@@ -44,13 +45,14 @@ func (h *applyStart) String() string {
 	return fmt.Sprintf("%s: %s...%s", h.Resource.Addr, h.actionVerb, id)
 }
 
-func NewApplyStart(addr addrs.AbsResourceInstance, action plans.Action, idKey string, idValue string) Hook {
+func NewApplyStart(addr addrs.AbsResourceInstance, action plans.Action, idKey string, idValue string, annotations map[string]string) Hook {
 	hook := &applyStart{
-		Resource:   jsonentities.NewResourceAddr(addr),
-		Action:     jsonentities.ParseChangeAction(action),
-		IDKey:      idKey,
-		IDValue:    idValue,
-		actionVerb: startActionVerb(action),
+		Resource:    jsonentities.NewResourceAddr(addr),
+		Action:      jsonentities.ParseChangeAction(action),
+		IDKey:       idKey,
+		IDValue:     idValue,
+		Annotations: annotations,
+		actionVerb:  startActionVerb(action),
 	}
 
 	return hook
@@ -88,13 +90,14 @@ func NewApplyProgress(addr addrs.AbsResourceInstance, action plans.Action, elaps
 
 // ApplyComplete: triggered by PostApply hook
 type applyComplete struct {
-	Resource   jsonentities.ResourceAddr `json:"resource"`
-	Action     jsonentities.ChangeAction `json:"action"`
-	IDKey      string                    `json:"id_key,omitempty"`
-	IDValue    string                    `json:"id_value,omitempty"`
-	Elapsed    float64                   `json:"elapsed_seconds"`
-	actionNoun string
-	elapsed    time.Duration
+	Resource    jsonentities.ResourceAddr `json:"resource"`
+	Action      jsonentities.ChangeAction `json:"action"`
+	IDKey       string                    `json:"id_key,omitempty"`
+	IDValue     string                    `json:"id_value,omitempty"`
+	Elapsed     float64                   `json:"elapsed_seconds"`
+	Annotations map[string]string         `json:"annotations,omitempty"`
+	actionNoun  string
+	elapsed     time.Duration
 }
 
 var _ Hook = (*applyComplete)(nil)
@@ -111,26 +114,28 @@ func (h *applyComplete) String() string {
 	return fmt.Sprintf("%s: %s complete after %s%s", h.Resource.Addr, h.actionNoun, h.elapsed, id)
 }
 
-func NewApplyComplete(addr addrs.AbsResourceInstance, action plans.Action, idKey, idValue string, elapsed time.Duration) Hook {
+func NewApplyComplete(addr addrs.AbsResourceInstance, action plans.Action, idKey, idValue string, elapsed time.Duration, annotations map[string]string) Hook {
 	return &applyComplete{
-		Resource:   jsonentities.NewResourceAddr(addr),
-		Action:     jsonentities.ParseChangeAction(action),
-		IDKey:      idKey,
-		IDValue:    idValue,
-		Elapsed:    elapsed.Seconds(),
-		actionNoun: actionNoun(action),
-		elapsed:    elapsed,
+		Resource:    jsonentities.NewResourceAddr(addr),
+		Action:      jsonentities.ParseChangeAction(action),
+		IDKey:       idKey,
+		IDValue:     idValue,
+		Elapsed:     elapsed.Seconds(),
+		Annotations: annotations,
+		actionNoun:  actionNoun(action),
+		elapsed:     elapsed,
 	}
 }
 
 // ApplyErrored: triggered by PostApply hook on failure. This will be followed
 // by diagnostics when the apply finishes.
 type applyErrored struct {
-	Resource   jsonentities.ResourceAddr `json:"resource"`
-	Action     jsonentities.ChangeAction `json:"action"`
-	Elapsed    float64                   `json:"elapsed_seconds"`
-	actionNoun string
-	elapsed    time.Duration
+	Resource    jsonentities.ResourceAddr `json:"resource"`
+	Action      jsonentities.ChangeAction `json:"action"`
+	Elapsed     float64                   `json:"elapsed_seconds"`
+	Annotations map[string]string         `json:"annotations,omitempty"`
+	actionNoun  string
+	elapsed     time.Duration
 }
 
 var _ Hook = (*applyErrored)(nil)
@@ -143,13 +148,44 @@ func (h *applyErrored) String() string {
 	return fmt.Sprintf("%s: %s errored after %s", h.Resource.Addr, h.actionNoun, h.elapsed)
 }
 
-func NewApplyErrored(addr addrs.AbsResourceInstance, action plans.Action, elapsed time.Duration) Hook {
+func NewApplyErrored(addr addrs.AbsResourceInstance, action plans.Action, elapsed time.Duration, annotations map[string]string) Hook {
 	return &applyErrored{
-		Resource:   jsonentities.NewResourceAddr(addr),
-		Action:     jsonentities.ParseChangeAction(action),
-		Elapsed:    elapsed.Seconds(),
-		actionNoun: actionNoun(action),
-		elapsed:    elapsed,
+		Resource:    jsonentities.NewResourceAddr(addr),
+		Action:      jsonentities.ParseChangeAction(action),
+		Elapsed:     elapsed.Seconds(),
+		Annotations: annotations,
+		actionNoun:  actionNoun(action),
+		elapsed:     elapsed,
+	}
+}
+
+// StateSummary: currently triggered by a timer started on the first PreApply
+// hook, and repeated for as long as any resource instance is still applying.
+type stateSummary struct {
+	Pending   int `json:"pending"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+var _ Hook = (*stateSummary)(nil)
+
+func (h *stateSummary) HookType() MessageType {
+	return MessageStateSummary
+}
+
+func (h *stateSummary) String() string {
+	return fmt.Sprintf("Apply progress: %d pending, %d completed, %d failed", h.Pending, h.Completed, h.Failed)
+}
+
+// NewStateSummary returns a hook message summarizing the progress of an
+// apply. Pending counts the resource instances that have started applying
+// but not yet finished, while completed and failed count those that have
+// finished successfully or unsuccessfully, respectively.
+func NewStateSummary(pending, completed, failed int) Hook {
+	return &stateSummary{
+		Pending:   pending,
+		Completed: completed,
+		Failed:    failed,
 	}
 }
 
@@ -20,12 +20,19 @@ const (
 	MessagePlannedChange MessageType = "planned_change"
 	MessageChangeSummary MessageType = "change_summary"
 	MessageOutputs       MessageType = "outputs"
+	MessageDestroyOrder  MessageType = "destroy_order"
+
+	// Provider installation messages
+	MessageProviderInstallStart    MessageType = "provider_install_start"
+	MessageProviderInstallProgress MessageType = "provider_install_progress"
+	MessageProviderInstallComplete MessageType = "provider_install_complete"
 
 	// Hook-driven messages
 	MessageApplyStart              MessageType = "apply_start"
 	MessageApplyProgress           MessageType = "apply_progress"
 	MessageApplyComplete           MessageType = "apply_complete"
 	MessageApplyErrored            MessageType = "apply_errored"
+	MessageStateSummary            MessageType = "state_summary"
 	MessageProvisionStart          MessageType = "provision_start"
 	MessageProvisionProgress       MessageType = "provision_progress"
 	MessageProvisionComplete       MessageType = "provision_complete"
@@ -45,3 +52,19 @@ const (
 	MessageTestCleanup   MessageType = "test_cleanup"
 	MessageTestInterrupt MessageType = "test_interrupt"
 )
+
+// FarseekOnly returns true for the message types that Farseek added to the
+// protocol inherited from OpenTofu, which consumers of that protocol don't
+// know about.
+func (t MessageType) FarseekOnly() bool {
+	switch t {
+	case MessageDestroyOrder,
+		MessageStateSummary,
+		MessageProviderInstallStart,
+		MessageProviderInstallProgress,
+		MessageProviderInstallComplete:
+		return true
+	default:
+		return false
+	}
+}
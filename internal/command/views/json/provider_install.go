@@ -0,0 +1,86 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"fmt"
+)
+
+// ProviderInstallStart describes a provider package that "farseek init" is
+// about to install.
+type ProviderInstallStart struct {
+	Provider string `json:"provider"`
+	Version  string `json:"version"`
+
+	// Source is the location the package is installed from, which is the
+	// shared cache directory for a cache hit.
+	Source   string `json:"source"`
+	CacheHit bool   `json:"cache_hit"`
+
+	// SharedCache is true if the package is being installed into the
+	// shared plugin cache directory rather than into the working directory.
+	SharedCache bool `json:"shared_cache,omitempty"`
+}
+
+func (s *ProviderInstallStart) String() string {
+	switch {
+	case s.CacheHit:
+		return fmt.Sprintf("- Using %s v%s from the shared cache directory", s.Provider, s.Version)
+	case s.SharedCache:
+		return fmt.Sprintf("- Installing %s v%s to the shared cache directory...", s.Provider, s.Version)
+	default:
+		return fmt.Sprintf("- Installing %s v%s...", s.Provider, s.Version)
+	}
+}
+
+// ProviderInstallProgress reports how much of a provider package has been
+// downloaded so far.
+type ProviderInstallProgress struct {
+	Provider string `json:"provider"`
+	Version  string `json:"version"`
+	Bytes    int64  `json:"bytes"`
+
+	// TotalBytes is the size of the package, or -1 if the source didn't
+	// report it.
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+func (p *ProviderInstallProgress) String() string {
+	if p.TotalBytes < 0 {
+		return fmt.Sprintf("- Downloading %s v%s: %d bytes", p.Provider, p.Version, p.Bytes)
+	}
+	return fmt.Sprintf("- Downloading %s v%s: %d of %d bytes", p.Provider, p.Version, p.Bytes, p.TotalBytes)
+}
+
+// ProviderInstallComplete describes a provider package that "farseek init"
+// has installed, or found already installed.
+type ProviderInstallComplete struct {
+	Provider string `json:"provider"`
+	Version  string `json:"version"`
+	CacheHit bool   `json:"cache_hit"`
+
+	// Bytes is the size of the package downloaded for this installation,
+	// if it was downloaded from the network.
+	Bytes int64 `json:"bytes,omitempty"`
+
+	// Authentication summarizes how the package was authenticated, such as
+	// "signed" or "verified checksum", and VerifiedHashes are the hashes
+	// that the package was checked against.
+	Authentication string   `json:"authentication,omitempty"`
+	VerifiedHashes []string `json:"verified_hashes,omitempty"`
+	KeyIDs         string   `json:"key_ids,omitempty"`
+}
+
+func (c *ProviderInstallComplete) String() string {
+	switch {
+	case c.CacheHit:
+		return fmt.Sprintf("- Using previously-installed %s v%s", c.Provider, c.Version)
+	case c.KeyIDs != "":
+		return fmt.Sprintf("- Installed %s v%s (%s, key ID %s)", c.Provider, c.Version, c.Authentication, c.KeyIDs)
+	case c.Authentication != "":
+		return fmt.Sprintf("- Installed %s v%s (%s)", c.Provider, c.Version, c.Authentication)
+	default:
+		return fmt.Sprintf("- Installed %s v%s", c.Provider, c.Version)
+	}
+}
@@ -10,6 +10,7 @@ package views
 import (
 	encJson "encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/go-hclog"
 
@@ -22,7 +23,58 @@ import (
 // This version describes the schema of JSON UI messages. This version must be
 // updated after making any changes to this view, the jsonHook, or any of the
 // command/views/json package.
-const JSON_UI_VERSION = "1.2"
+const JSON_UI_VERSION = "1.3"
+
+// The versions of the JSON UI protocol, which consumers select with the
+// -json-protocol option. Unlike JSON_UI_VERSION, which only ever grows, an
+// older protocol stays available so that consumers written against it keep
+// working as we add message types.
+const (
+	// JSONProtocolOpenTofu is the protocol inherited from OpenTofu, with
+	// schema version 1.2 and none of the message types Farseek added.
+	JSONProtocolOpenTofu = 1
+
+	// JSONProtocolFarseek adds the message types that are specific to
+	// Farseek, and is the default.
+	JSONProtocolFarseek = 2
+
+	JSONProtocolLatest = JSONProtocolFarseek
+)
+
+// The capability flags listed in the version message, which tell consumers
+// which optional parts of the protocol the stream can contain.
+const (
+	// JSONCapabilityFarseekDiscovery means that the stream can contain the
+	// message types that Farseek added, for which json.MessageType.FarseekOnly
+	// returns true. Consumers should discover them by their type, and show at
+	// least the @message of those they don't know.
+	JSONCapabilityFarseekDiscovery = "farseek_discovery"
+
+	// JSONCapabilityHeartbeats means that long-running applies report their
+	// progress periodically, with apply_progress messages and, along with
+	// farseek_discovery, state_summary messages.
+	JSONCapabilityHeartbeats = "heartbeats"
+
+	// JSONCapabilityTimings means that messages about the end of an
+	// operation on a resource include its elapsed_seconds.
+	JSONCapabilityTimings = "timings"
+)
+
+// jsonProtocols are the JSON UI schema versions and capability flags of each
+// protocol.
+var jsonProtocols = map[int]struct {
+	ui           string
+	capabilities []string
+}{
+	JSONProtocolOpenTofu: {
+		ui:           "1.2",
+		capabilities: []string{JSONCapabilityHeartbeats, JSONCapabilityTimings},
+	},
+	JSONProtocolFarseek: {
+		ui:           JSON_UI_VERSION,
+		capabilities: []string{JSONCapabilityFarseekDiscovery, JSONCapabilityHeartbeats, JSONCapabilityTimings},
+	},
+}
 
 func NewJSONView(view *View) *JSONView {
 	log := hclog.New(&hclog.LoggerOptions{
@@ -31,14 +83,38 @@ func NewJSONView(view *View) *JSONView {
 		JSONFormat:         true,
 		JSONEscapeDisabled: true,
 	})
+	protocol, diags := parseJSONProtocol(view.jsonProtocol)
 	jv := &JSONView{
-		log:  log,
-		view: view,
+		log:      log,
+		view:     view,
+		protocol: protocol,
 	}
 	jv.Version()
+	jv.Diagnostics(diags)
 	return jv
 }
 
+// parseJSONProtocol returns the version of the JSON UI protocol that was
+// asked for with the -json-protocol option, or the latest one with a warning
+// if it isn't supported. The consumer can tell from the version message
+// which one it got.
+func parseJSONProtocol(raw string) (int, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if raw == "" {
+		return JSONProtocolLatest, diags
+	}
+	protocol, err := strconv.Atoi(raw)
+	if _, ok := jsonProtocols[protocol]; err != nil || !ok {
+		diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Unsupported JSON protocol",
+			fmt.Sprintf("The -json-protocol option must be one of the supported versions, 1 to %d, but was %q. Farseek is using version %d instead.", JSONProtocolLatest, raw, JSONProtocolLatest),
+		), tfdiags.CodeUnsupportedJSONProtocol))
+		return JSONProtocolLatest, diags
+	}
+	return protocol, diags
+}
+
 type JSONView struct {
 	// hclog is used for all output in JSON UI mode. The logger has an internal
 	// mutex to ensure that messages are not interleaved.
@@ -52,18 +128,36 @@ type JSONView struct {
 	// Do not be tempted to dereference the configSource value upon logger init,
 	// as it will likely be updated later.
 	view *View
+
+	// protocol is the version of the JSON UI protocol of the stream, which
+	// is one of the keys of jsonProtocols.
+	protocol int
 }
 
 func (v *JSONView) Version() {
 	version := tfversion.String()
+	protocol := jsonProtocols[v.protocol]
 	v.log.Info(
 		fmt.Sprintf("Farseek %s", version),
 		"type", json.MessageVersion,
 		"farseek", version,
-		"ui", JSON_UI_VERSION,
+		"ui", protocol.ui,
+		"protocol", v.protocol,
+		"capabilities", protocol.capabilities,
 	)
 }
 
+// Protocol returns the version of the JSON UI protocol of the stream.
+func (v *JSONView) Protocol() int {
+	return v.protocol
+}
+
+// emits returns true if messages of the given type are part of the protocol
+// of the stream.
+func (v *JSONView) emits(t json.MessageType) bool {
+	return v.protocol >= JSONProtocolFarseek || !t.FarseekOnly()
+}
+
 func (v *JSONView) Log(message string) {
 	v.log.Info(message, "type", json.MessageLog)
 }
@@ -78,7 +172,7 @@ func (v *JSONView) StateDump(state string) {
 
 func (v *JSONView) Diagnostics(diags tfdiags.Diagnostics, metadata ...interface{}) {
 	sources := v.view.configSources()
-	for _, diag := range diags {
+	for _, diag := range v.view.FilterSuppressedWarnings(diags) {
 		diagnostic := jsonentities.NewDiagnostic(diag, sources)
 
 		args := []interface{}{"type", json.MessageDiagnostic, "diagnostic", diagnostic}
@@ -118,6 +212,9 @@ func (v *JSONView) ChangeSummary(cs *json.ChangeSummary) {
 }
 
 func (v *JSONView) Hook(h json.Hook) {
+	if !v.emits(h.HookType()) {
+		return
+	}
 	v.log.Info(
 		h.String(),
 		"type", h.HookType(),
@@ -133,6 +230,50 @@ func (v *JSONView) Outputs(outputs jsonentities.Outputs) {
 	)
 }
 
+func (v *JSONView) DestroyOrder(o *json.DestroyOrder) {
+	if !v.emits(json.MessageDestroyOrder) {
+		return
+	}
+	v.log.Info(
+		o.String(),
+		"type", json.MessageDestroyOrder,
+		"destroy_order", o,
+	)
+}
+
+func (v *JSONView) ProviderInstallStart(s *json.ProviderInstallStart) {
+	if !v.emits(json.MessageProviderInstallStart) {
+		return
+	}
+	v.log.Info(
+		s.String(),
+		"type", json.MessageProviderInstallStart,
+		"provider_install", s,
+	)
+}
+
+func (v *JSONView) ProviderInstallProgress(p *json.ProviderInstallProgress) {
+	if !v.emits(json.MessageProviderInstallProgress) {
+		return
+	}
+	v.log.Info(
+		p.String(),
+		"type", json.MessageProviderInstallProgress,
+		"provider_install", p,
+	)
+}
+
+func (v *JSONView) ProviderInstallComplete(c *json.ProviderInstallComplete) {
+	if !v.emits(json.MessageProviderInstallComplete) {
+		return
+	}
+	v.log.Info(
+		c.String(),
+		"type", json.MessageProviderInstallComplete,
+		"provider_install", c,
+	)
+}
+
 // Output is designed for supporting command.WrappedUi
 func (v *JSONView) Output(message string) {
 	v.log.Info(message, "type", "output")
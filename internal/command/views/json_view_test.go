@@ -17,6 +17,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/jsonentities"
 	viewsjson "github.com/rafagsiqueira/farseek/internal/command/views/json"
 	"github.com/rafagsiqueira/farseek/internal/plans"
@@ -40,12 +41,85 @@ func TestNewJSONView(t *testing.T) {
 			"type":     "version",
 			"farseek":  version,
 			"ui":       JSON_UI_VERSION,
+			"protocol": float64(JSONProtocolLatest),
+			"capabilities": []interface{}{
+				"farseek_discovery",
+				"heartbeats",
+				"timings",
+			},
 		},
 	}
 
 	testJSONViewOutputEqualsFull(t, done(t).Stdout(), want)
 }
 
+func TestNewJSONView_protocol(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	view.Configure(&arguments.View{JSONProtocol: "1"})
+	jv := NewJSONView(view)
+	if got, want := jv.Protocol(), JSONProtocolOpenTofu; got != want {
+		t.Fatalf("wrong protocol %d; want %d", got, want)
+	}
+
+	// The message types that Farseek added aren't part of the protocol
+	// inherited from OpenTofu.
+	jv.Hook(viewsjson.NewStateSummary(1, 2, 0))
+	jv.DestroyOrder(&viewsjson.DestroyOrder{})
+	jv.Log("hello")
+
+	version := tfversion.String()
+	want := []map[string]interface{}{
+		{
+			"@level":       "info",
+			"@message":     fmt.Sprintf("Farseek %s", version),
+			"@module":      "farseek.ui",
+			"type":         "version",
+			"farseek":      version,
+			"ui":           "1.2",
+			"protocol":     float64(JSONProtocolOpenTofu),
+			"capabilities": []interface{}{"heartbeats", "timings"},
+		},
+		{
+			"@level":   "info",
+			"@message": "hello",
+			"@module":  "farseek.ui",
+			"type":     "log",
+		},
+	}
+	testJSONViewOutputEqualsFull(t, done(t).Stdout(), want)
+}
+
+func TestNewJSONView_unsupportedProtocol(t *testing.T) {
+	for _, raw := range []string{"0", "99", "latest"} {
+		t.Run(raw, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			view := NewView(streams)
+			view.Configure(&arguments.View{JSONProtocol: raw})
+			jv := NewJSONView(view)
+			if got, want := jv.Protocol(), JSONProtocolLatest; got != want {
+				t.Fatalf("wrong protocol %d; want %d", got, want)
+			}
+
+			want := []map[string]interface{}{
+				{
+					"@level":   "warn",
+					"@message": "Warning: Unsupported JSON protocol",
+					"@module":  "farseek.ui",
+					"type":     "diagnostic",
+					"diagnostic": map[string]interface{}{
+						"severity": "warning",
+						"summary":  "Unsupported JSON protocol",
+						"detail":   fmt.Sprintf("The -json-protocol option must be one of the supported versions, 1 to 2, but was %q. Farseek is using version 2 instead.", raw),
+						"code":     "FS0011",
+					},
+				},
+			}
+			testJSONViewOutputEquals(t, done(t).Stdout(), want)
+		})
+	}
+}
+
 func TestJSONView_Log(t *testing.T) {
 	testCases := []struct {
 		caseName string
@@ -134,6 +208,64 @@ func TestJSONView_Diagnostics(t *testing.T) {
 	testJSONViewOutputEquals(t, done(t).Stdout(), want)
 }
 
+func TestJSONView_DiagnosticsSuppressed(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams).SetSuppressedWarnings([]string{"fs0101"})
+	view.Configure(&arguments.View{SuppressWarnings: []string{`Improper use of "less"`, "Unusually stripey cat detected"}})
+	jv := NewJSONView(view)
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		`Improper use of "less"`,
+		`You probably mean "10 buckets or fewer"`,
+	))
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Previous apply was interrupted",
+		"Run farseek recover.",
+	), tfdiags.CodeApplyInterrupted))
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Unrelated warning",
+		"This one is shown.",
+	))
+	// Errors are never suppressed.
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Unusually stripey cat detected",
+		"Are you sure this random_pet isn't a cheetah?",
+	))
+
+	jv.Diagnostics(diags)
+
+	want := []map[string]interface{}{
+		{
+			"@level":   "warn",
+			"@message": "Warning: Unrelated warning",
+			"@module":  "farseek.ui",
+			"type":     "diagnostic",
+			"diagnostic": map[string]interface{}{
+				"severity": "warning",
+				"summary":  "Unrelated warning",
+				"detail":   "This one is shown.",
+			},
+		},
+		{
+			"@level":   "error",
+			"@message": "Error: Unusually stripey cat detected",
+			"@module":  "farseek.ui",
+			"type":     "diagnostic",
+			"diagnostic": map[string]interface{}{
+				"severity": "error",
+				"summary":  "Unusually stripey cat detected",
+				"detail":   "Are you sure this random_pet isn't a cheetah?",
+			},
+		},
+	}
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
 func TestJSONView_DiagnosticsWithMetadata(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	jv := NewJSONView(NewView(streams))
@@ -365,7 +497,7 @@ func TestJSONView_Hook(t *testing.T) {
 	}
 	managed := addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_instance", Name: "bar"}
 	addr := managed.Instance(addrs.StringKey("boop")).Absolute(foo)
-	hook := viewsjson.NewApplyComplete(addr, plans.Create, "id", "boop-beep", 34*time.Second)
+	hook := viewsjson.NewApplyComplete(addr, plans.Create, "id", "boop-beep", 34*time.Second, nil)
 
 	jv.Hook(hook)
 
@@ -435,6 +567,88 @@ func TestJSONView_Outputs(t *testing.T) {
 	testJSONViewOutputEquals(t, done(t).Stdout(), want)
 }
 
+func TestJSONView_ProviderInstall(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	jv := NewJSONView(NewView(streams))
+
+	jv.ProviderInstallStart(&viewsjson.ProviderInstallStart{
+		Provider: "registry.opentofu.org/hashicorp/null",
+		Version:  "3.2.1",
+		Source:   "https://example.com/null.zip",
+	})
+	jv.ProviderInstallProgress(&viewsjson.ProviderInstallProgress{
+		Provider:   "registry.opentofu.org/hashicorp/null",
+		Version:    "3.2.1",
+		Bytes:      1024,
+		TotalBytes: 2048,
+	})
+	jv.ProviderInstallComplete(&viewsjson.ProviderInstallComplete{
+		Provider:       "registry.opentofu.org/hashicorp/null",
+		Version:        "3.2.1",
+		Bytes:          2048,
+		Authentication: "verified checksum",
+		VerifiedHashes: []string{"h1:abc", "zh:def"},
+	})
+	jv.ProviderInstallComplete(&viewsjson.ProviderInstallComplete{
+		Provider: "registry.opentofu.org/hashicorp/random",
+		Version:  "3.6.0",
+		CacheHit: true,
+	})
+
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "- Installing registry.opentofu.org/hashicorp/null v3.2.1...",
+			"@module":  "farseek.ui",
+			"type":     "provider_install_start",
+			"provider_install": map[string]interface{}{
+				"provider":  "registry.opentofu.org/hashicorp/null",
+				"version":   "3.2.1",
+				"source":    "https://example.com/null.zip",
+				"cache_hit": false,
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "- Downloading registry.opentofu.org/hashicorp/null v3.2.1: 1024 of 2048 bytes",
+			"@module":  "farseek.ui",
+			"type":     "provider_install_progress",
+			"provider_install": map[string]interface{}{
+				"provider":    "registry.opentofu.org/hashicorp/null",
+				"version":     "3.2.1",
+				"bytes":       float64(1024),
+				"total_bytes": float64(2048),
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "- Installed registry.opentofu.org/hashicorp/null v3.2.1 (verified checksum)",
+			"@module":  "farseek.ui",
+			"type":     "provider_install_complete",
+			"provider_install": map[string]interface{}{
+				"provider":        "registry.opentofu.org/hashicorp/null",
+				"version":         "3.2.1",
+				"cache_hit":       false,
+				"bytes":           float64(2048),
+				"authentication":  "verified checksum",
+				"verified_hashes": []interface{}{"h1:abc", "zh:def"},
+			},
+		},
+		{
+			"@level":   "info",
+			"@message": "- Using previously-installed registry.opentofu.org/hashicorp/random v3.6.0",
+			"@module":  "farseek.ui",
+			"type":     "provider_install_complete",
+			"provider_install": map[string]interface{}{
+				"provider":  "registry.opentofu.org/hashicorp/random",
+				"version":   "3.6.0",
+				"cache_hit": true,
+			},
+		},
+	}
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
 // This helper function tests a possibly multi-line JSONView output string
 // against a slice of structs representing the desired log messages. It
 // verifies that the output of JSONView is in JSON log format, one message per
@@ -98,6 +98,11 @@ func (v *OperationHuman) EmergencyDumpState(stateFile *statefile.File, enc encry
 }
 
 func (v *OperationHuman) Plan(plan *plans.Plan, schemas *farseek.Schemas) {
+	if v.view.quiet {
+		v.planSummary(plan)
+		return
+	}
+
 	outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
 	if err != nil {
 		v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
@@ -109,6 +114,7 @@ func (v *OperationHuman) Plan(plan *plans.Plan, schemas *farseek.Schemas) {
 		Streams:             v.view.streams,
 		RunningInAutomation: v.inAutomation,
 		ShowSensitive:       v.view.showSensitive,
+		GroupBy:             v.view.groupBy,
 	}
 
 	jplan := jsonformat.Plan{
@@ -133,6 +139,71 @@ func (v *OperationHuman) Plan(plan *plans.Plan, schemas *farseek.Schemas) {
 	renderer.RenderHumanPlan(jplan, plan.UIMode, opts...)
 }
 
+// planSummary prints only the line of the human-readable plan that counts
+// the changes, for the -quiet option.
+func (v *OperationHuman) planSummary(plan *plans.Plan) {
+	if plan.Errored {
+		// The errors say what went wrong, and the changes are incomplete.
+		return
+	}
+	if plan.UIMode == plans.RefreshOnlyMode {
+		if len(plan.DriftedResources) == 0 {
+			v.view.streams.Print(v.view.colorize.Color("\n[reset][bold][green]No changes.[reset][bold] Your infrastructure still matches the configuration.[reset]\n"))
+			return
+		}
+		v.view.streams.Printf(v.view.colorize.Color("\n[bold]Plan:[reset] %d objects changed outside of Farseek.\n"), len(plan.DriftedResources))
+		return
+	}
+
+	var toImport, toAdd, toChange, toDestroy, toForget int
+	for _, rc := range plan.Changes.Resources {
+		if rc.Addr.Resource.Resource.Mode != addrs.ManagedResourceMode {
+			continue
+		}
+		if rc.Importing != nil {
+			toImport++
+		}
+		switch rc.Action {
+		case plans.Create, plans.ForgetThenCreate:
+			toAdd++
+		case plans.Update:
+			toChange++
+		case plans.Delete:
+			toDestroy++
+		case plans.DeleteThenCreate, plans.CreateThenDelete:
+			toAdd++
+			toDestroy++
+		}
+		if rc.Action == plans.Forget || rc.Action == plans.ForgetThenCreate {
+			toForget++
+		}
+	}
+	outputChanges := 0
+	for _, oc := range plan.Changes.Outputs {
+		if oc.Action != plans.NoOp {
+			outputChanges++
+		}
+	}
+	if toImport+toAdd+toChange+toDestroy+toForget+outputChanges == 0 {
+		v.view.streams.Print(v.view.colorize.Color("\n[reset][bold][green]No changes.[reset][bold] Your infrastructure matches the configuration.[reset]\n"))
+		return
+	}
+
+	var counts []string
+	if toImport > 0 {
+		counts = append(counts, fmt.Sprintf("%d to import", toImport))
+	}
+	counts = append(counts,
+		fmt.Sprintf("%d to add", toAdd),
+		fmt.Sprintf("%d to change", toChange),
+		fmt.Sprintf("%d to destroy", toDestroy),
+	)
+	if toForget > 0 {
+		counts = append(counts, fmt.Sprintf("%d to forget", toForget))
+	}
+	v.view.streams.Printf(v.view.colorize.Color("\n[bold]Plan:[reset] %s.\n"), strings.Join(counts, ", "))
+}
+
 func (v *OperationHuman) PlannedChange(change *plans.ResourceInstanceChangeSrc) {
 	// PlannedChange is primarily for machine-readable output in order to
 	// get a per-resource-instance change description. We don't use it
@@ -141,9 +212,10 @@ func (v *OperationHuman) PlannedChange(change *plans.ResourceInstanceChangeSrc)
 }
 
 // PlanNextStep gives the user some next-steps, unless we're running in an
-// automation tool which is presumed to provide its own UI for further actions.
+// automation tool which is presumed to provide its own UI for further actions,
+// or the output is quiet.
 func (v *OperationHuman) PlanNextStep(planPath string, genConfigPath string) {
-	if v.inAutomation {
+	if v.inAutomation || v.view.quiet {
 		return
 	}
 	v.view.outputHorizRule()
@@ -157,14 +229,22 @@ func (v *OperationHuman) PlanNextStep(planPath string, genConfigPath string) {
 		)
 	}
 
-	if planPath == "" {
+	switch planPath {
+	case "":
 		v.view.streams.Print(
 			format.WordWrap(
 				"\n"+strings.TrimSpace(planHeaderNoOutput),
 				v.view.outputColumns(),
 			) + "\n",
 		)
-	} else {
+	case "-":
+		v.view.streams.Print(
+			format.WordWrap(
+				"\n"+strings.TrimSpace(planHeaderStdoutOutput),
+				v.view.outputColumns(),
+			) + "\n",
+		)
+	default:
 		v.view.streams.Print(
 			format.WordWrap(
 				"\n"+strings.TrimSpace(fmt.Sprintf(planHeaderYesOutput, planPath, planPath)),
@@ -319,6 +399,13 @@ To perform exactly these actions, run the following command to apply:
     farseek apply %q
 `
 
+const planHeaderStdoutOutput = `
+Wrote the plan to standard output.
+
+To perform exactly these actions, pipe it to the following command:
+    farseek apply -
+`
+
 const planHeaderGenConfig = `
 Farseek has generated configuration and written it to %s. Please review the configuration and edit it as necessary before adding it to version control.
 `
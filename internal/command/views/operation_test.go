@@ -465,6 +465,21 @@ Plan: 1 to add, 0 to change, 0 to destroy.
 		t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s", got, want)
 	}
 }
+func TestOperation_planQuiet(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	view.SetQuiet(true)
+	v := NewOperation(arguments.ViewHuman, false, view)
+
+	v.Plan(testPlan(t), testSchemas())
+	v.PlanNextStep("", "")
+
+	want := "\nPlan: 1 to add, 0 to change, 0 to destroy.\n"
+	if got := done(t).Stdout(); got != want {
+		t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestOperation_planNextStep(t *testing.T) {
 	testCases := map[string]struct {
 		path string
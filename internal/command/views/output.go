@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
@@ -25,9 +26,19 @@ import (
 )
 
 // The Output view renders either one or all outputs, depending on whether or
-// not the name argument is empty.
+// not the name argument is empty. When rendering a single output, a non-empty
+// path selects a nested value within that output to render instead.
 type Output interface {
-	Output(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics
+	Output(name string, path cty.Path, outputs map[string]*states.OutputValue) tfdiags.Diagnostics
+
+	// Schema renders a JSON Schema describing the type of either one or all
+	// outputs, rather than their values. Only the JSON view supports this.
+	Schema(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics
+
+	// Serving announces that the outputs are being served over HTTP at the
+	// given URL until the given time, for "farseek output -serve".
+	Serving(url string, expiresAt time.Time)
+
 	Diagnostics(diags tfdiags.Diagnostics)
 }
 
@@ -53,7 +64,7 @@ type OutputHuman struct {
 
 var _ Output = (*OutputHuman)(nil)
 
-func (v *OutputHuman) Output(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+func (v *OutputHuman) Output(name string, path cty.Path, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
 	if len(outputs) == 0 {
@@ -67,7 +78,12 @@ func (v *OutputHuman) Output(name string, outputs map[string]*states.OutputValue
 			diags = diags.Append(missingOutputError(name))
 			return diags
 		}
-		result := repl.FormatValue(output.Value, 0)
+		value, pathDiags := outputValueAtPath(name, path, output.Value)
+		diags = diags.Append(pathDiags)
+		if pathDiags.HasErrors() {
+			return diags
+		}
+		result := repl.FormatValue(value, 0)
 		v.view.streams.Println(result)
 		return nil
 	}
@@ -102,6 +118,14 @@ func (v *OutputHuman) Output(name string, outputs map[string]*states.OutputValue
 	return nil
 }
 
+func (v *OutputHuman) Serving(url string, expiresAt time.Time) {
+	v.view.streams.Printf("Serving outputs at %s until %s. Press Ctrl-C to stop.\n", url, expiresAt.Format(time.Kitchen))
+}
+
+func (v *OutputHuman) Schema(_ string, _ map[string]*states.OutputValue) tfdiags.Diagnostics {
+	return unsupportedOutputSchemaError()
+}
+
 func (v *OutputHuman) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -116,7 +140,7 @@ type OutputRaw struct {
 
 var _ Output = (*OutputRaw)(nil)
 
-func (v *OutputRaw) Output(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+func (v *OutputRaw) Output(name string, path cty.Path, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
 	if len(outputs) == 0 {
@@ -135,14 +159,25 @@ func (v *OutputRaw) Output(name string, outputs map[string]*states.OutputValue)
 		return diags
 	}
 
-	strV, err := convert.Convert(output.Value, cty.String)
+	value, pathDiags := outputValueAtPath(name, path, output.Value)
+	diags = diags.Append(pathDiags)
+	if pathDiags.HasErrors() {
+		return diags
+	}
+	if len(path) > 0 {
+		// From here on we only describe the selected value, so we'll
+		// refer to it by its full path in any error messages.
+		name = name + tfdiags.FormatCtyPath(path)
+	}
+
+	strV, err := convert.Convert(value, cty.String)
 	if err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Unsupported value for raw output",
 			fmt.Sprintf(
-				"The -raw option only supports strings, numbers, and boolean values, but output value %q is %s.\n\nUse the -json option for machine-readable representations of output values that have complex types.",
-				name, output.Value.Type().FriendlyName(),
+				"The -raw option only supports strings, numbers, and boolean values, but output value %q is %s.\n\nUse the -json option for machine-readable representations of output values that have complex types, or give a path to a nested value such as %q.",
+				name, value.Type().FriendlyName(), name+rawOutputPathExample(value.Type()),
 			),
 		))
 		return diags
@@ -179,6 +214,14 @@ func (v *OutputRaw) Output(name string, outputs map[string]*states.OutputValue)
 	return nil
 }
 
+func (v *OutputRaw) Serving(url string, _ time.Time) {
+	v.view.streams.Println(url)
+}
+
+func (v *OutputRaw) Schema(_ string, _ map[string]*states.OutputValue) tfdiags.Diagnostics {
+	return unsupportedOutputSchemaError()
+}
+
 func (v *OutputRaw) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -193,26 +236,54 @@ type OutputJSON struct {
 
 var _ Output = (*OutputJSON)(nil)
 
-func (v *OutputJSON) Output(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+func (v *OutputJSON) Output(name string, path cty.Path, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+	jsonOutput, diags := MarshalOutputsJSON(name, path, outputs)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	v.view.streams.Println(string(jsonOutput))
+
+	return diags
+}
+
+func (v *OutputJSON) Serving(url string, expiresAt time.Time) {
+	serving, err := json.Marshal(map[string]string{
+		"url":        url,
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		// Can't happen: the value is a map of strings.
+		panic(err)
+	}
+	v.view.streams.Println(string(serving))
+}
+
+// MarshalOutputsJSON returns the JSON that "farseek output -json" prints
+// for the given arguments: either the bare value of a single output, or an
+// object of output metadata when name is empty.
+func MarshalOutputsJSON(name string, path cty.Path, outputs map[string]*states.OutputValue) ([]byte, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
 	if name != "" {
 		output, ok := outputs[name]
 		if !ok {
 			diags = diags.Append(missingOutputError(name))
-			return diags
+			return nil, diags
+		}
+		value, pathDiags := outputValueAtPath(name, path, output.Value)
+		diags = diags.Append(pathDiags)
+		if pathDiags.HasErrors() {
+			return nil, diags
 		}
-		value := output.Value
 
 		jsonOutput, err := ctyjson.Marshal(value, value.Type())
 		if err != nil {
 			diags = diags.Append(err)
-			return diags
+			return nil, diags
 		}
 
-		v.view.streams.Println(string(jsonOutput))
-
-		return nil
+		return jsonOutput, nil
 	}
 
 	// Due to a historical accident, the switch from state version 2 to
@@ -233,12 +304,12 @@ func (v *OutputJSON) Output(name string, outputs map[string]*states.OutputValue)
 		jsonVal, err := ctyjson.Marshal(os.Value, os.Value.Type())
 		if err != nil {
 			diags = diags.Append(err)
-			return diags
+			return nil, diags
 		}
 		jsonType, err := ctyjson.MarshalType(os.Value.Type())
 		if err != nil {
 			diags = diags.Append(err)
-			return diags
+			return nil, diags
 		}
 		outputMetas[n] = OutputMeta{
 			Sensitive:  os.Sensitive,
@@ -249,12 +320,57 @@ func (v *OutputJSON) Output(name string, outputs map[string]*states.OutputValue)
 	}
 
 	jsonOutputs, err := json.MarshalIndent(outputMetas, "", "  ")
+	if err != nil {
+		diags = diags.Append(err)
+		return nil, diags
+	}
+
+	return jsonOutputs, nil
+}
+
+// Schema renders a JSON Schema document describing the JSON that Output
+// would render for the same arguments: either the bare value of a single
+// output, or the object of output metadata when rendering all outputs.
+func (v *OutputJSON) Schema(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var schema map[string]interface{}
+	if name != "" {
+		output, ok := outputs[name]
+		if !ok {
+			diags = diags.Append(missingOutputError(name))
+			return diags
+		}
+		schema = ctyTypeJSONSchema(output.Value.Type())
+	} else {
+		properties := make(map[string]interface{}, len(outputs))
+		for n, os := range outputs {
+			properties[n] = map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"sensitive":  map[string]interface{}{"type": "boolean"},
+					"deprecated": map[string]interface{}{"type": "string"},
+					"type":       map[string]interface{}{},
+					"value":      ctyTypeJSONSchema(os.Value.Type()),
+				},
+				"required": []string{"sensitive", "type", "value"},
+			}
+		}
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	}
+	schema["$schema"] = jsonSchemaDialect
+
+	jsonSchema, err := json.MarshalIndent(schema, "", "  ")
 	if err != nil {
 		diags = diags.Append(err)
 		return diags
 	}
 
-	v.view.streams.Println(string(jsonOutputs))
+	v.view.streams.Println(string(jsonSchema))
 
 	return nil
 }
@@ -263,6 +379,112 @@ func (v *OutputJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
 
+// jsonSchemaDialect is the JSON Schema version that OutputJSON.Schema
+// produces documents for.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// ctyTypeJSONSchema returns a JSON Schema describing the JSON serialization
+// of values of the given type, as produced by the ctyjson package.
+func ctyTypeJSONSchema(ty cty.Type) map[string]interface{} {
+	switch {
+	case ty == cty.String:
+		return map[string]interface{}{"type": "string"}
+	case ty == cty.Number:
+		return map[string]interface{}{"type": "number"}
+	case ty == cty.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case ty.IsListType():
+		return map[string]interface{}{
+			"type":  "array",
+			"items": ctyTypeJSONSchema(ty.ElementType()),
+		}
+	case ty.IsSetType():
+		return map[string]interface{}{
+			"type":        "array",
+			"items":       ctyTypeJSONSchema(ty.ElementType()),
+			"uniqueItems": true,
+		}
+	case ty.IsTupleType():
+		etys := ty.TupleElementTypes()
+		prefixItems := make([]interface{}, len(etys))
+		for i, ety := range etys {
+			prefixItems[i] = ctyTypeJSONSchema(ety)
+		}
+		return map[string]interface{}{
+			"type":        "array",
+			"prefixItems": prefixItems,
+			"items":       false,
+			"minItems":    len(etys),
+		}
+	case ty.IsMapType():
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": ctyTypeJSONSchema(ty.ElementType()),
+		}
+	case ty.IsObjectType():
+		atys := ty.AttributeTypes()
+		properties := make(map[string]interface{}, len(atys))
+		required := make([]string, 0, len(atys))
+		for name, aty := range atys {
+			properties[name] = ctyTypeJSONSchema(aty)
+			if !ty.AttributeOptional(name) {
+				required = append(required, name)
+			}
+		}
+		sort.Strings(required)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		// cty.DynamicPseudoType, and anything else we don't recognize,
+		// could be serialized as any JSON value at all.
+		return map[string]interface{}{}
+	}
+}
+
+// outputValueAtPath returns the value at the given path within the value of
+// the named output, or the whole value if the path is empty.
+func outputValueAtPath(name string, path cty.Path, value cty.Value) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(path) == 0 {
+		return value, diags
+	}
+
+	result, err := path.Apply(value)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output path",
+			fmt.Sprintf("Cannot select %s from output value %q: %s.", tfdiags.FormatCtyPath(path), name, err),
+		))
+		return cty.DynamicVal, diags
+	}
+	return result, diags
+}
+
+// rawOutputPathExample returns an example path step for selecting a nested
+// value from a value of the given type, for use in error messages.
+func rawOutputPathExample(ty cty.Type) string {
+	switch {
+	case ty.IsObjectType():
+		atys := ty.AttributeTypes()
+		names := make([]string, 0, len(atys))
+		for name := range atys {
+			names = append(names, name)
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return "." + names[0]
+		}
+	case ty.IsMapType():
+		return `["key"]`
+	}
+	return "[0]"
+}
+
 // For text and raw output modes, an empty map of outputs is considered a
 // separate and higher priority failure mode than an output not being present
 // in a non-empty map. This warning diagnostic explains how this might have
@@ -280,6 +502,16 @@ func noOutputsWarning() tfdiags.Diagnostic {
 	)
 }
 
+// Only the JSON output format can describe output types as a JSON Schema.
+func unsupportedOutputSchemaError() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	return diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Unsupported output format",
+		"Output schemas can only be rendered in JSON format. Use the -json option together with -schema.",
+	))
+}
+
 // Attempting to display a missing output results in this failure, which
 // includes suggestions on how to rectify the problem.
 func missingOutputError(name string) tfdiags.Diagnostic {
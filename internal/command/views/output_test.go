@@ -61,7 +61,7 @@ func TestOutputHuman_single(t *testing.T) {
 			outputs := map[string]*states.OutputValue{
 				"foo": {Value: tc.value},
 			}
-			diags := v.Output("foo", outputs)
+			diags := v.Output("foo", nil, outputs)
 
 			if diags.HasErrors() {
 				if !tc.wantErr {
@@ -97,7 +97,7 @@ func TestOutput_sensitive(t *testing.T) {
 					Sensitive: true,
 				},
 			}
-			diags := v.Output("foo", outputs)
+			diags := v.Output("foo", nil, outputs)
 
 			if diags.HasErrors() {
 				t.Fatalf("unexpected diagnostics: %s", diags)
@@ -203,7 +203,7 @@ foobar = "abcde"
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			v := NewOutput(tc.vt, NewView(streams))
-			diags := v.Output("", outputs)
+			diags := v.Output("", nil, outputs)
 
 			if diags.HasErrors() {
 				t.Fatalf("unexpected diagnostics: %s", diags)
@@ -222,7 +222,7 @@ func TestOutputJSON_empty(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	v := NewOutput(arguments.ViewJSON, NewView(streams))
 
-	diags := v.Output("", map[string]*states.OutputValue{})
+	diags := v.Output("", nil, map[string]*states.OutputValue{})
 
 	if diags.HasErrors() {
 		t.Fatalf("unexpected diagnostics: %s", diags)
@@ -245,7 +245,7 @@ func TestOutput_emptyWarning(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			v := NewOutput(vt, NewView(streams))
 
-			diags := v.Output("", map[string]*states.OutputValue{})
+			diags := v.Output("", nil, map[string]*states.OutputValue{})
 
 			if got, want := done(t).Stdout(), ""; got != want {
 				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
@@ -302,7 +302,7 @@ func TestOutputRaw(t *testing.T) {
 			outputs := map[string]*states.OutputValue{
 				name: {Value: value},
 			}
-			diags := v.Output(name, outputs)
+			diags := v.Output(name, nil, outputs)
 
 			if diags.HasErrors() {
 				if !test.WantErr {
@@ -328,7 +328,7 @@ func TestOutputRaw_all(t *testing.T) {
 		"foo": {Value: cty.StringVal("secret")},
 		"bar": {Value: cty.True},
 	}
-	diags := v.Output("", outputs)
+	diags := v.Output("", nil, outputs)
 
 	if got, want := done(t).Stdout(), ""; got != want {
 		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
@@ -357,7 +357,7 @@ func TestOutput_missing(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			v := NewOutput(vt, NewView(streams))
 
-			diags := v.Output("foo", map[string]*states.OutputValue{
+			diags := v.Output("foo", nil, map[string]*states.OutputValue{
 				"bar": {Value: cty.StringVal("boop")},
 			})
 
@@ -379,3 +379,123 @@ func TestOutput_missing(t *testing.T) {
 		})
 	}
 }
+
+// Raw output can render a nested primitive value from a complex output when
+// given a path.
+func TestOutputRaw_path(t *testing.T) {
+	value := cty.ObjectVal(map[string]cty.Value{
+		"instances": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"id": cty.StringVal("i-abc123"),
+			}),
+		}),
+	})
+
+	tests := map[string]struct {
+		Path       cty.Path
+		WantOutput string
+		WantErr    string
+	}{
+		"primitive": {
+			Path:       cty.GetAttrPath("instances").IndexInt(0).GetAttr("id"),
+			WantOutput: "i-abc123",
+		},
+		"complex": {
+			Path:    cty.GetAttrPath("instances").IndexInt(0),
+			WantErr: "Unsupported value for raw output",
+		},
+		"missing": {
+			Path:    cty.GetAttrPath("instances").IndexInt(1),
+			WantErr: "Invalid output path",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			v := NewOutput(arguments.ViewRaw, NewView(streams))
+
+			outputs := map[string]*states.OutputValue{
+				"web": {Value: value},
+			}
+			diags := v.Output("web", test.Path, outputs)
+
+			if test.WantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatalf("succeeded, but want error")
+				}
+				if got, want := diags[0].Description().Summary, test.WantErr; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+			} else if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+
+			if got, want := done(t).Stdout(), test.WantOutput; got != want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+// JSON output format can describe the types of outputs as a JSON Schema.
+func TestOutputJSON_schema(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewOutput(arguments.ViewJSON, NewView(streams))
+
+	outputs := map[string]*states.OutputValue{
+		"foo": {
+			Value: cty.ObjectVal(map[string]cty.Value{
+				"ids":  cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				"tags": cty.MapVal(map[string]cty.Value{"Name": cty.StringVal("b")}),
+				"pair": cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.True}),
+			}),
+		},
+	}
+	diags := v.Schema("foo", outputs)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags)
+	}
+
+	want := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "additionalProperties": false,
+  "properties": {
+    "ids": {
+      "items": {
+        "type": "string"
+      },
+      "type": "array"
+    },
+    "pair": {
+      "items": false,
+      "minItems": 2,
+      "prefixItems": [
+        {
+          "type": "number"
+        },
+        {
+          "type": "boolean"
+        }
+      ],
+      "type": "array"
+    },
+    "tags": {
+      "additionalProperties": {
+        "type": "string"
+      },
+      "type": "object"
+    }
+  },
+  "required": [
+    "ids",
+    "pair",
+    "tags"
+  ],
+  "type": "object"
+}
+`
+	if got := done(t).Stdout(); got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
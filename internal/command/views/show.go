@@ -9,7 +9,10 @@ package views
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/jsonconfig"
@@ -30,7 +33,10 @@ type Show interface {
 
 	// DisplayPlan renders the given plan, returning a status code for "farseek show" to return.
 	//
-	DisplayPlan(ctx context.Context, plan *plans.Plan, config *configs.Config, priorStateFile *statefile.File, schemas *farseek.Schemas) int
+	// If resourceChanges isn't nil, it yields the planned resource changes in
+	// place of those in the plan, so that a large plan file can be rendered
+	// without decoding all of them at once.
+	DisplayPlan(ctx context.Context, plan *plans.Plan, resourceChanges jsonplan.ResourceChangeSource, config *configs.Config, priorStateFile *statefile.File, schemas *farseek.Schemas) int
 
 	// DisplayConfig renders the given configuration, returning a status code for "farseek show" to return.
 	DisplayConfig(config *configs.Config, schemas *farseek.Schemas) int
@@ -40,14 +46,23 @@ type Show interface {
 	// schema or child module information.
 	DisplaySingleModule(module *configs.Module) int
 
+	// DisplayOverrides renders which arguments of the blocks of a module
+	// come from its override files, returning a status code for "farseek
+	// show" to return.
+	DisplayOverrides(sources *configs.OverrideSources) int
+
 	// Diagnostics renders early diagnostics, resulting from argument parsing.
 	Diagnostics(diags tfdiags.Diagnostics)
 }
 
-func NewShow(vt arguments.ViewType, view *View) Show {
+// NewShow returns the view for the show command. formatVersion is the
+// version of the JSON plan or state format to produce, or empty for the
+// latest one, and redact the policy to redact JSON plans with, or nil to
+// show them as they are. Both only apply to the JSON view.
+func NewShow(vt arguments.ViewType, formatVersion string, redact *jsonplan.RedactPolicy, view *View) Show {
 	switch vt {
 	case arguments.ViewJSON:
-		return &ShowJSON{view: view}
+		return &ShowJSON{view: view, formatVersion: formatVersion, redact: redact}
 	case arguments.ViewHuman:
 		return &ShowHuman{view: view}
 	default:
@@ -92,18 +107,22 @@ func (v *ShowHuman) DisplayState(_ context.Context, stateFile *statefile.File, s
 	return 0
 }
 
-func (v *ShowHuman) DisplayPlan(_ context.Context, plan *plans.Plan, config *configs.Config, priorStateFile *statefile.File, schemas *farseek.Schemas) int {
+func (v *ShowHuman) DisplayPlan(_ context.Context, plan *plans.Plan, resourceChanges jsonplan.ResourceChangeSource, config *configs.Config, priorStateFile *statefile.File, schemas *farseek.Schemas) int {
 	renderer := jsonformat.Renderer{
 		Colorize:            v.view.colorize,
 		Streams:             v.view.streams,
 		RunningInAutomation: v.view.runningInAutomation,
 		ShowSensitive:       v.view.showSensitive,
+		GroupBy:             v.view.groupBy,
 	}
 
 	// Prefer to display a pre-built JSON plan, if we got one; then, fall back
 	// to building one ourselves.
 	if plan != nil {
-		outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
+		if resourceChanges == nil {
+			resourceChanges = jsonplan.PlanResourceChanges(plan)
+		}
+		outputs, changed, drift, attrs, err := jsonplan.MarshalForRendererFrom(plan, resourceChanges, schemas)
 		if err != nil {
 			v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
 			return 1
@@ -148,17 +167,82 @@ func (v *ShowHuman) DisplaySingleModule(_ *configs.Module) int {
 	return 1
 }
 
+func (v *ShowHuman) DisplayOverrides(sources *configs.OverrideSources) int {
+	if len(sources.OverrideFiles) == 0 {
+		v.view.streams.Println("The configuration has no override files.")
+		return 0
+	}
+
+	v.view.streams.Println(v.view.colorize.Color("[bold]Override files[reset], in the order they are merged:"))
+	for _, file := range sources.OverrideFiles {
+		v.view.streams.Printf("  %s\n", file)
+	}
+
+	others := 0
+	for _, block := range sources.Blocks {
+		if !block.Overridden() {
+			others++
+			continue
+		}
+		base := block.BaseFile
+		if base == "" {
+			base = "no primary file"
+		}
+		v.view.streams.Println()
+		v.view.streams.Println(v.view.colorize.Color(fmt.Sprintf("[bold]%s[reset] (%s, overridden by %s)", block.Addr, base, strings.Join(block.OverrideFiles, ", "))))
+
+		width := 0
+		for _, arg := range block.Arguments {
+			width = max(width, len(arg.Name))
+		}
+		for _, arg := range block.Arguments {
+			name := arg.Name
+			if arg.Block {
+				name += " {}"
+			}
+			source := fmt.Sprintf("%s:%d", arg.Source.Filename, arg.Source.Start.Line)
+			switch {
+			case arg.Override && arg.Replaces != nil:
+				source = v.view.colorize.Color(fmt.Sprintf("[yellow]%s[reset], replacing %s:%d", source, arg.Replaces.Filename, arg.Replaces.Start.Line))
+			case arg.Override:
+				source = v.view.colorize.Color(fmt.Sprintf("[green]%s[reset], added", source))
+			}
+			v.view.streams.Printf("  %-*s  %s\n", width+3, name, source)
+		}
+	}
+
+	if others != 0 {
+		v.view.streams.Println()
+		if others == 1 {
+			v.view.streams.Println("1 other block is not overridden.")
+		} else {
+			v.view.streams.Printf("%d other blocks are not overridden.\n", others)
+		}
+	}
+	return 0
+}
+
 func (v *ShowHuman) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
 
 type ShowJSON struct {
-	view *View
+	view          *View
+	formatVersion string
+	redact        *jsonplan.RedactPolicy
 }
 
 var _ Show = (*ShowJSON)(nil)
 
 func (v *ShowJSON) DisplayState(_ context.Context, stateFile *statefile.File, schemas *farseek.Schemas) int {
+	if v.formatVersion != "" && !slices.Contains(jsonstate.SupportedFormatVersions, v.formatVersion) {
+		v.view.streams.Eprintf("Unsupported state format version %q; supported versions are %s", v.formatVersion, strings.Join(jsonstate.SupportedFormatVersions, ", "))
+		return 1
+	}
+	if v.redact != nil {
+		v.view.streams.Eprintf("The -redact option can only be used when showing a plan, not a state")
+		return 1
+	}
 	jsonState, err := jsonstate.Marshal(stateFile, schemas)
 	if err != nil {
 		v.view.streams.Eprintf("Failed to marshal state to json: %s", err)
@@ -168,16 +252,40 @@ func (v *ShowJSON) DisplayState(_ context.Context, stateFile *statefile.File, sc
 	return 0
 }
 
-func (v *ShowJSON) DisplayPlan(_ context.Context, plan *plans.Plan, config *configs.Config, priorStateFile *statefile.File, schemas *farseek.Schemas) int {
+func (v *ShowJSON) DisplayPlan(_ context.Context, plan *plans.Plan, resourceChanges jsonplan.ResourceChangeSource, config *configs.Config, priorStateFile *statefile.File, schemas *farseek.Schemas) int {
+	if v.formatVersion != "" && !slices.Contains(jsonplan.SupportedFormatVersions, v.formatVersion) {
+		v.view.streams.Eprintf("Unsupported plan format version %q; supported versions are %s", v.formatVersion, strings.Join(jsonplan.SupportedFormatVersions, ", "))
+		return 1
+	}
 	// Prefer to display a pre-built JSON plan, if we got one; then, fall back
 	// to building one ourselves.
 	if plan != nil {
-		planJSON, err := jsonplan.Marshal(config, plan, priorStateFile, schemas)
+		// The JSON plan refers to the resource changes in several places, so
+		// they're all needed at once.
+		if resourceChanges != nil {
+			plan.Changes.Resources = nil
+			err := resourceChanges(func(rc *plans.ResourceInstanceChangeSrc) error {
+				plan.Changes.Resources = append(plan.Changes.Resources, rc)
+				return nil
+			})
+			if err != nil {
+				v.view.streams.Eprintf("Failed to read the resource changes of the plan: %s", err)
+				return 1
+			}
+		}
+		planJSON, err := jsonplan.MarshalVersion(v.formatVersion, config, plan, priorStateFile, schemas)
 
 		if err != nil {
 			v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
 			return 1
 		}
+		if v.redact != nil {
+			planJSON, err = jsonplan.Redact(planJSON, *v.redact)
+			if err != nil {
+				v.view.streams.Eprintf("Failed to redact plan: %s", err)
+				return 1
+			}
+		}
 		v.view.streams.Println(string(planJSON))
 	} else {
 		// Should not get here because at least one of the two plan arguments
@@ -208,6 +316,73 @@ func (v *ShowJSON) DisplaySingleModule(module *configs.Module) int {
 	return 0
 }
 
+// overridesJSON is the JSON representation of configs.OverrideSources.
+type overridesJSON struct {
+	FormatVersion string                `json:"format_version"`
+	OverrideFiles []string              `json:"override_files"`
+	Blocks        []overriddenBlockJSON `json:"blocks"`
+}
+
+type overriddenBlockJSON struct {
+	Address       string                   `json:"address"`
+	BaseFile      string                   `json:"base_file,omitempty"`
+	OverrideFiles []string                 `json:"override_files,omitempty"`
+	Arguments     []overriddenArgumentJSON `json:"arguments"`
+}
+
+type overriddenArgumentJSON struct {
+	Name     string              `json:"name"`
+	Block    bool                `json:"block,omitempty"`
+	Source   overrideSourceJSON  `json:"source"`
+	Override bool                `json:"override"`
+	Replaces *overrideSourceJSON `json:"replaces,omitempty"`
+}
+
+type overrideSourceJSON struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+func (v *ShowJSON) DisplayOverrides(sources *configs.OverrideSources) int {
+	ret := overridesJSON{
+		FormatVersion: "1.0",
+		OverrideFiles: sources.OverrideFiles,
+		Blocks:        []overriddenBlockJSON{},
+	}
+	if ret.OverrideFiles == nil {
+		ret.OverrideFiles = []string{}
+	}
+	for _, block := range sources.Blocks {
+		jb := overriddenBlockJSON{
+			Address:       block.Addr,
+			BaseFile:      block.BaseFile,
+			OverrideFiles: block.OverrideFiles,
+			Arguments:     []overriddenArgumentJSON{},
+		}
+		for _, arg := range block.Arguments {
+			ja := overriddenArgumentJSON{
+				Name:     arg.Name,
+				Block:    arg.Block,
+				Source:   overrideSourceJSON{File: arg.Source.Filename, Line: arg.Source.Start.Line},
+				Override: arg.Override,
+			}
+			if arg.Replaces != nil {
+				ja.Replaces = &overrideSourceJSON{File: arg.Replaces.Filename, Line: arg.Replaces.Start.Line}
+			}
+			jb.Arguments = append(jb.Arguments, ja)
+		}
+		ret.Blocks = append(ret.Blocks, jb)
+	}
+
+	out, err := json.Marshal(ret)
+	if err != nil {
+		v.view.streams.Eprintf("Failed to marshal override sources to JSON: %s", err)
+		return 1
+	}
+	v.view.streams.Println(string(out))
+	return 0
+}
+
 // Diagnostics should only be called if show cannot be executed.
 // In this case, we choose to render human-readable diagnostic output,
 // primarily for backwards compatibility.
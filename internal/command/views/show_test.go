@@ -17,6 +17,7 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonplan"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/initwd"
@@ -52,9 +53,9 @@ func TestShowHuman_DisplayPlan(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			view := NewView(streams)
 			view.Configure(&arguments.View{NoColor: true})
-			v := NewShow(arguments.ViewHuman, view)
+			v := NewShow(arguments.ViewHuman, "", nil, view)
 
-			code := v.DisplayPlan(t.Context(), testCase.plan, nil, nil, testCase.schemas)
+			code := v.DisplayPlan(t.Context(), testCase.plan, nil, nil, nil, testCase.schemas)
 			if code != 0 {
 				t.Errorf("expected 0 return code, got %d", code)
 			}
@@ -69,6 +70,36 @@ func TestShowHuman_DisplayPlan(t *testing.T) {
 	}
 }
 
+func TestShowHuman_DisplayPlanResourceChanges(t *testing.T) {
+	// The resource changes can come from a separate source, with the plan
+	// itself holding none of them.
+	plan := testPlan(t)
+	changes := plan.Changes.Resources
+	plan.Changes.Resources = nil
+
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	view.Configure(&arguments.View{NoColor: true})
+	v := NewShow(arguments.ViewHuman, "", nil, view)
+
+	source := func(fn func(*plans.ResourceInstanceChangeSrc) error) error {
+		for _, rc := range changes {
+			if err := fn(rc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if code := v.DisplayPlan(t.Context(), plan, source, nil, nil, testSchemas()); code != 0 {
+		t.Errorf("expected 0 return code, got %d", code)
+	}
+
+	got := done(t).Stdout()
+	if want := "# test_resource.foo will be created"; !strings.Contains(got, want) {
+		t.Errorf("output doesn't contain %q:\n%s", want, got)
+	}
+}
+
 func TestShowHuman_DisplayState(t *testing.T) {
 	testCases := map[string]struct {
 		stateFile  *statefile.File
@@ -108,7 +139,7 @@ func TestShowHuman_DisplayState(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			view := NewView(streams)
 			view.Configure(&arguments.View{NoColor: true})
-			v := NewShow(arguments.ViewHuman, view)
+			v := NewShow(arguments.ViewHuman, "", nil, view)
 
 			code := v.DisplayState(t.Context(), testCase.stateFile, testCase.schemas)
 			if code != 0 {
@@ -163,7 +194,7 @@ func TestShowJSON_DisplayPlan(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			view := NewView(streams)
 			view.Configure(&arguments.View{NoColor: true})
-			v := NewShow(arguments.ViewJSON, view)
+			v := NewShow(arguments.ViewJSON, "", nil, view)
 
 			schemas := &farseek.Schemas{
 				Providers: map[addrs.Provider]providers.ProviderSchema{
@@ -182,7 +213,7 @@ func TestShowJSON_DisplayPlan(t *testing.T) {
 				},
 			}
 
-			code := v.DisplayPlan(t.Context(), testCase.plan, config, testCase.stateFile, schemas)
+			code := v.DisplayPlan(t.Context(), testCase.plan, nil, config, testCase.stateFile, schemas)
 
 			if code != 0 {
 				t.Errorf("expected 0 return code, got %d", code)
@@ -228,7 +259,7 @@ func TestShowJSON_DisplayState(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			view := NewView(streams)
 			view.Configure(&arguments.View{NoColor: true})
-			v := NewShow(arguments.ViewJSON, view)
+			v := NewShow(arguments.ViewJSON, "", nil, view)
 
 			schemas := &farseek.Schemas{
 				Providers: map[addrs.Provider]providers.ProviderSchema{
@@ -289,3 +320,81 @@ func testState() *states.State {
 		// operation, and all fields have been copied correctly.
 	}).DeepCopy()
 }
+
+func TestShowJSON_unsupportedFormatVersion(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	view.Configure(&arguments.View{NoColor: true})
+	v := NewShow(arguments.ViewJSON, "0.9", nil, view)
+
+	if code := v.DisplayPlan(t.Context(), &plans.Plan{}, nil, nil, nil, &farseek.Schemas{}); code != 1 {
+		t.Errorf("expected 1 return code for a plan, got %d", code)
+	}
+	if code := v.DisplayState(t.Context(), nil, &farseek.Schemas{}); code != 1 {
+		t.Errorf("expected 1 return code for a state, got %d", code)
+	}
+	got := done(t).Stderr()
+	for _, want := range []string{
+		`Unsupported plan format version "0.9"; supported versions are 1.2, 1.3`,
+		`Unsupported state format version "0.9"; supported versions are 1.0`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestShowJSON_redact(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	view.Configure(&arguments.View{NoColor: true})
+	v := NewShow(arguments.ViewJSON, "", &jsonplan.RedactPolicy{}, view)
+
+	schemas := &farseek.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			addrs.NewDefaultProvider("test"): {
+				ResourceTypes: map[string]providers.Schema{
+					"test_resource": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"id":  {Type: cty.String, Optional: true, Computed: true},
+								"foo": {Type: cty.String, Optional: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	config, _ := initwd.MustLoadConfigForTests(t, "./testdata/show", "tests")
+
+	if code := v.DisplayPlan(t.Context(), testPlan(t), nil, config, nil, schemas); code != 0 {
+		t.Fatalf("expected 0 return code, got %d", code)
+	}
+	if code := v.DisplayState(t.Context(), nil, schemas); code != 1 {
+		t.Errorf("expected 1 return code for a state, got %d", code)
+	}
+
+	output := done(t)
+	var result struct {
+		ResourceChanges []struct {
+			Change struct {
+				After map[string]any `json:"after"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(output.Stdout()), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ResourceChanges) == 0 {
+		t.Fatalf("no resource changes:\n%s", output.Stdout())
+	}
+	for _, rc := range result.ResourceChanges {
+		if foo, ok := rc.Change.After["foo"].(string); ok && !strings.HasPrefix(foo, "sha256:") {
+			t.Errorf("value of foo wasn't redacted: %q", foo)
+		}
+	}
+	if got, want := output.Stderr(), "can only be used when showing a plan"; !strings.Contains(got, want) {
+		t.Errorf("missing error %q:\n%s", want, got)
+	}
+}
@@ -8,10 +8,15 @@
 package views
 
 import (
+	"io"
+	"slices"
+	"strings"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/mitchellh/colorstring"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/format"
+	"github.com/rafagsiqueira/farseek/internal/command/jsonformat"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/terminal"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
@@ -39,12 +44,33 @@ type View struct {
 	// only the important details.
 	concise bool
 
+	// quiet leaves out everything but errors and the summary of the
+	// changes, for the -quiet option of plan and apply.
+	quiet bool
+
 	// ModuleDeprecationWarnLvl is used to filter out deprecation warnings for outputs and variables as requested by the user.
 	ModuleDeprecationWarnLvl farseek.DeprecationWarningLevel
 
 	// showSensitive is used to display the value of variables marked as sensitive.
 	showSensitive bool
 
+	// groupBy selects how human-readable plans group the resource changes.
+	groupBy jsonformat.GroupBy
+
+	// suppressWarnings are the codes or summaries of warnings to leave out
+	// of the output, from the command line, and configSuppressWarnings are
+	// those from the CLI configuration.
+	suppressWarnings       []string
+	configSuppressWarnings []string
+
+	// errorCodes are the codes of the errors rendered so far, in the order
+	// they were first seen, for ErrorCodeSummary.
+	errorCodes []tfdiags.Code
+
+	// jsonProtocol is the version of the machine-readable UI protocol that
+	// was asked for, or empty for the latest version.
+	jsonProtocol string
+
 	// This unfortunate wart is required to enable rendering of diagnostics which
 	// have associated source code in the configuration. This function pointer
 	// will be dereferenced as late as possible when rendering diagnostics in
@@ -90,6 +116,47 @@ func (v *View) Configure(view *arguments.View) {
 	v.consolidateErrors = view.ConsolidateErrors
 	v.concise = view.Concise
 	v.ModuleDeprecationWarnLvl = view.ModuleDeprecationWarnLvl
+	v.suppressWarnings = view.SuppressWarnings
+	v.jsonProtocol = view.JSONProtocol
+}
+
+// SetSuppressedWarnings sets the codes or summaries of warnings that the CLI
+// configuration suppresses. These apply in addition to any given with the
+// -suppress-warning option.
+//
+// For convenient use during initialization (in conjunction with NewView),
+// SetSuppressedWarnings returns the receiver after modifying it.
+func (v *View) SetSuppressedWarnings(suppress []string) *View {
+	v.configSuppressWarnings = suppress
+	return v
+}
+
+// FilterSuppressedWarnings returns the given diagnostics without the
+// warnings that the user asked to suppress, matching either their code or
+// their summary.
+func (v *View) FilterSuppressedWarnings(diags tfdiags.Diagnostics) tfdiags.Diagnostics {
+	if len(v.suppressWarnings) == 0 && len(v.configSuppressWarnings) == 0 {
+		return diags
+	}
+	var ret tfdiags.Diagnostics
+	for _, diag := range diags {
+		if diag.Severity() == tfdiags.Warning && (warningSuppressed(diag, v.suppressWarnings) || warningSuppressed(diag, v.configSuppressWarnings)) {
+			continue
+		}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
+func warningSuppressed(diag tfdiags.Diagnostic, suppress []string) bool {
+	code := tfdiags.DiagnosticCode(diag)
+	summary := diag.Description().Summary
+	for _, s := range suppress {
+		if (code != "" && strings.EqualFold(s, string(code))) || s == summary {
+			return true
+		}
+	}
+	return false
 }
 
 // SetConfigSources overrides the default no-op callback with a new function
@@ -123,6 +190,19 @@ func (v *View) Diagnostics(diags tfdiags.Diagnostics) {
 		}
 		diags = newDiags
 	}
+	diags = v.FilterSuppressedWarnings(diags)
+	if v.quiet {
+		var errs tfdiags.Diagnostics
+		for _, diag := range diags {
+			if diag.Severity() == tfdiags.Error {
+				errs = append(errs, diag)
+			}
+		}
+		diags = errs
+	}
+	if len(diags) == 0 {
+		return
+	}
 
 	if v.consolidateWarnings {
 		diags = diags.Consolidate(1, tfdiags.Warning)
@@ -153,6 +233,7 @@ func (v *View) Diagnostics(diags tfdiags.Diagnostics) {
 		}
 	}
 
+	v.NoteErrorCodes(diags)
 	for _, diag := range diags {
 		var msg string
 		if v.colorize.Disable {
@@ -169,6 +250,41 @@ func (v *View) Diagnostics(diags tfdiags.Diagnostics) {
 	}
 }
 
+// NoteErrorCodes records the codes of the errors among the given
+// diagnostics, for ErrorCodeSummary. Diagnostics calls it for the errors it
+// renders; other code that renders diagnostics in human-readable form must
+// call it itself.
+func (v *View) NoteErrorCodes(diags tfdiags.Diagnostics) {
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Error {
+			continue
+		}
+		code := tfdiags.DiagnosticCode(diag)
+		if code != "" && !slices.Contains(v.errorCodes, code) {
+			v.errorCodes = append(v.errorCodes, code)
+		}
+	}
+}
+
+// ErrorCodeSummary prints the codes of the errors rendered while running a
+// command, if any had codes, so that they can be looked up once the command
+// has failed without scrolling back through its output.
+func (v *View) ErrorCodeSummary() {
+	if len(v.errorCodes) == 0 {
+		return
+	}
+	codes := make([]string, len(v.errorCodes))
+	for i, code := range v.errorCodes {
+		codes[i] = string(code)
+	}
+	v.streams.Eprintf(errorCodeSummary, strings.Join(codes, ", "))
+}
+
+const errorCodeSummary = `
+Farseek failed with errors %s. To look them up, see the list
+of diagnostic codes in the Farseek documentation.
+`
+
 // HelpPrompt is intended to be called from commands which fail to parse all
 // of their CLI arguments successfully. It refers users to the full help output
 // rather than rendering it directly, which can be overwhelming and confusing.
@@ -208,6 +324,35 @@ func (v *View) outputHorizRule() {
 	v.streams.Println(format.HorizontalRule(v.colorize, v.outputColumns()))
 }
 
+// TakeStdout returns the standard output stream for a command to write
+// data to, such as a saved plan, and redirects all further output from the
+// view to standard error so that the two cannot be interleaved.
+//
+// This must be called before creating any command-specific view from the
+// receiver, since some of those capture the output stream when created.
+func (v *View) TakeStdout() io.Writer {
+	stdout := v.streams.Stdout.File
+	v.streams = &terminal.Streams{
+		Stdout: v.streams.Stderr,
+		Stderr: v.streams.Stderr,
+		Stdin:  v.streams.Stdin,
+	}
+	return stdout
+}
+
 func (v *View) SetShowSensitive(showSensitive bool) {
 	v.showSensitive = showSensitive
 }
+
+// SetQuiet makes the human-readable views of plan and apply leave out the
+// progress of each resource, the warnings and the details of the changes,
+// so that only the errors and the summary of the changes are shown.
+func (v *View) SetQuiet(quiet bool) {
+	v.quiet = quiet
+}
+
+// SetGroupBy selects how human-readable plans group the resource changes,
+// from the -group-by option.
+func (v *View) SetGroupBy(groupBy string) {
+	v.groupBy = jsonformat.GroupBy(groupBy)
+}
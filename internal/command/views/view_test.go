@@ -0,0 +1,58 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/terminal"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestView_ErrorCodeSummary(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+
+	// Nothing is printed until an error with a code has been rendered.
+	view.ErrorCodeSummary()
+	if got := done(t).Stderr(); got != "" {
+		t.Fatalf("unexpected summary without errors:\n%s", got)
+	}
+
+	streams, done = terminal.StreamsForTesting(t)
+	view = NewView(streams)
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Failed to discover changes",
+		"The recorded commit isn't in the repository.",
+	), tfdiags.CodeDiscoveryFailed))
+	// Warnings and errors without codes aren't summarized.
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Previous apply was interrupted",
+		"Run farseek recover.",
+	), tfdiags.CodeApplyInterrupted))
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Unusually stripey cat detected",
+		"Are you sure this random_pet isn't a cheetah?",
+	))
+	view.Diagnostics(diags)
+
+	// A code rendered again, or noted by code that renders diagnostics
+	// itself, is listed once, in the order first seen.
+	view.NoteErrorCodes(tfdiags.Diagnostics{
+		tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Invalid plan file", ""), tfdiags.CodePlanFileLoadFailed),
+		tfdiags.WithCode(tfdiags.Sourceless(tfdiags.Error, "Failed to discover changes", ""), tfdiags.CodeDiscoveryFailed),
+	})
+	view.ErrorCodeSummary()
+
+	got := done(t).Stderr()
+	if want := "Farseek failed with errors FS0002, FS0004."; !strings.Contains(got, want) {
+		t.Fatalf("summary doesn't contain %q:\n%s", want, got)
+	}
+}
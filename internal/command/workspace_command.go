@@ -8,6 +8,7 @@
 package command
 
 import (
+	"fmt"
 	"net/url"
 	"strings"
 
@@ -52,6 +53,23 @@ func validWorkspaceName(name string) bool {
 	return name == url.PathEscape(name)
 }
 
+// parseWorkspaceTags parses the values of the -tag options of "workspace
+// new", each of which is a "key=value" pair.
+func parseWorkspaceTags(raw []string) (map[string]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(raw))
+	for _, tag := range raw {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("Invalid tag %q: expected a tag like key=value.", tag)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
 func envCommandShowWarning(ui cli.Ui, show bool) {
 	if !show {
 		return
@@ -85,6 +103,12 @@ or include the "-or-create" flag with the "select" subcommand.`
 You're now on a new, empty workspace. Workspaces isolate their state,
 so if you run "farseek plan" Farseek will not see any existing state
 for this configuration.
+`
+
+	envMetadataNotStored = `
+Warning: Failed to store the metadata of workspace %q: %s
+
+The workspace was created without its description and tags.
 `
 
 	envDeleted = `[reset][green]Deleted workspace %q!`
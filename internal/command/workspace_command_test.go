@@ -8,15 +8,22 @@
 package command
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/mitchellh/cli"
 
 	"github.com/rafagsiqueira/farseek/internal/backend"
+	backendInit "github.com/rafagsiqueira/farseek/internal/backend/init"
 	"github.com/rafagsiqueira/farseek/internal/backend/local"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
 
 	legacy "github.com/rafagsiqueira/farseek/internal/legacy/farseek"
 )
@@ -109,7 +116,209 @@ func TestWorkspace_createAndList(t *testing.T) {
 	}
 }
 
+// List the workspaces with the details of their states.
+func TestWorkspace_listDetailed(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	testStateFileWorkspaceDefault(t, "test_a", testState())
+	testStateFileWorkspaceDefault(t, "test_b", states.NewState())
+
+	ui := cli.NewMockUi()
+	view, _ := testView(t)
+	listCmd := &WorkspaceListCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := listCmd.Run([]string{"-detailed"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	want := strings.Join([]string{
+		"  WORKSPACE  RESOURCES  SERIAL  LOCK",
+		"* default    0          -       unlocked",
+		"  test_a     1          0       unlocked",
+		"  test_b     0          0       unlocked",
+	}, "\n")
+	if diff := cmp.Diff(want, strings.TrimRight(ui.OutputWriter.String(), "\n")); diff != "" {
+		t.Errorf("wrong output\n%s", diff)
+	}
+
+	ui = cli.NewMockUi()
+	view, _ = testView(t)
+	listCmd = &WorkspaceListCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := listCmd.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	var got struct {
+		Workspaces []struct {
+			Name      string  `json:"name"`
+			Current   bool    `json:"current"`
+			Resources *int    `json:"resources"`
+			Serial    *uint64 `json:"serial"`
+			Locked    *bool   `json:"locked"`
+		} `json:"workspaces"`
+	}
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter)
+	}
+	if len(got.Workspaces) != 3 {
+		t.Fatalf("wrong number of workspaces\n%s", ui.OutputWriter)
+	}
+	if ws := got.Workspaces[0]; ws.Name != "default" || !ws.Current || ws.Serial != nil {
+		t.Errorf("wrong default workspace\n%s", ui.OutputWriter)
+	}
+	if ws := got.Workspaces[1]; ws.Name != "test_a" || ws.Resources == nil || *ws.Resources != 1 || ws.Locked == nil || *ws.Locked {
+		t.Errorf("wrong test_a workspace\n%s", ui.OutputWriter)
+	}
+}
+
+// Create workspaces with metadata, filter the list output by their tags, and
+// check the metadata of the current workspace.
+func TestWorkspace_createWithMetadata(t *testing.T) {
+	td := t.TempDir()
+	t.Chdir(td)
+
+	workspaces := []struct {
+		name string
+		args []string
+	}{
+		{"staging", []string{"-tag=env=staging"}},
+		{"scratch", nil},
+		{"prod", []string{"-description=Production", "-tag=env=prod", "-tag=team=infra"}},
+	}
+	var newCmd *WorkspaceNewCommand
+	for _, ws := range workspaces {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		newCmd = &WorkspaceNewCommand{
+			Meta: Meta{Ui: ui, View: view},
+		}
+		if code := newCmd.Run(append(ws.args, ws.name)); code != 0 {
+			t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+		}
+	}
+
+	name, meta, err := newCmd.currentWorkspaceMetadata(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMeta := &backend.WorkspaceMetadata{
+		Description: "Production",
+		Tags:        map[string]string{"env": "prod", "team": "infra"},
+	}
+	if name != "prod" {
+		t.Errorf("wrong current workspace %q; want %q", name, "prod")
+	}
+	if diff := cmp.Diff(wantMeta, meta); diff != "" {
+		t.Errorf("wrong metadata\n%s", diff)
+	}
+
+	tests := map[string]struct {
+		args []string
+		want string
+	}{
+		"any value": {
+			[]string{"-tag=env"},
+			"* prod\n  staging",
+		},
+		"exact value": {
+			[]string{"-tag=env=staging"},
+			"staging",
+		},
+		"all tags": {
+			[]string{"-tag=env", "-tag=team=infra"},
+			"* prod",
+		},
+		"no match": {
+			[]string{"-tag=env=dev"},
+			"",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			view, _ := testView(t)
+			listCmd := &WorkspaceListCommand{
+				Meta: Meta{Ui: ui, View: view},
+			}
+			if code := listCmd.Run(test.args); code != 0 {
+				t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+			}
+			if got := strings.TrimSpace(ui.OutputWriter.String()); got != test.want {
+				t.Errorf("\nexpected: %q\nactual:  %q", test.want, got)
+			}
+		})
+	}
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	newCmd = &WorkspaceNewCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := newCmd.Run([]string{"-tag=env", "dev"}); code != 1 {
+		t.Fatalf("invalid tag accepted\n\n%s", ui.OutputWriter)
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid tag"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 // Create some workspaces and test the show output.
+func TestWorkspace_createWithMetadataNotSupported(t *testing.T) {
+	// A backend that can't store metadata still creates and selects the
+	// workspace, and only warns that its metadata wasn't stored.
+	td := t.TempDir()
+	t.Chdir(td)
+	if err := os.WriteFile("main.tf", []byte(`
+terraform {
+  backend "_test_no_metadata" {}
+}
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var created []string
+	t.Cleanup(
+		backendInit.RegisterTemp("_test_no_metadata", func(enc encryption.StateEncryption) backend.Backend {
+			return &backendInit.MockBackend{
+				WorkspacesFn: func() ([]string, error) {
+					return append([]string{backend.DefaultStateName}, created...), nil
+				},
+				StateMgrFn: func(workspace string) (statemgr.Full, error) {
+					if !slices.Contains(created, workspace) && workspace != backend.DefaultStateName {
+						created = append(created, workspace)
+					}
+					return statemgr.NewFilesystem(workspace+".tfstate", enc), nil
+				},
+			}
+		}),
+	)
+
+	m := testMetaBackend(t, nil)
+	if _, diags := m.Backend(t.Context(), &BackendOpts{Init: true}, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	newCmd := &WorkspaceNewCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := newCmd.Run([]string{"-description=Production", "prod"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+	}
+	if got, want := ui.ErrorWriter.String(), `Failed to store the metadata of workspace "prod"`; !strings.Contains(got, want) {
+		t.Errorf("missing warning %q:\n%s", want, got)
+	}
+	if current, _ := newCmd.Workspace(t.Context()); current != "prod" {
+		t.Errorf("wrong current workspace %q; want %q", current, "prod")
+	}
+	if !slices.Contains(created, "prod") {
+		t.Errorf("workspace wasn't created")
+	}
+}
+
 func TestWorkspace_createAndShow(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
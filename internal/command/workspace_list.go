@@ -9,14 +9,26 @@ package command
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"text/tabwriter"
 
 	"github.com/posener/complete"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/backend"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
+// workspaceListConcurrency is the number of workspaces whose states
+// "workspace list -detailed" reads at the same time.
+const workspaceListConcurrency = 8
+
 type WorkspaceListCommand struct {
 	Meta
 	LegacyName bool
@@ -27,8 +39,13 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	envCommandShowWarning(c.Ui, c.LegacyName)
 
+	var tagFilters FlagStringSlice
+	var detailed, jsonOutput bool
 	cmdFlags := c.Meta.defaultFlagSet("workspace list")
 	c.Meta.varFlagSet(cmdFlags)
+	cmdFlags.Var(&tagFilters, "tag", "tag filter")
+	cmdFlags.BoolVar(&detailed, "detailed", false, "show the state of each workspace")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -76,8 +93,30 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 		return 1
 	}
 
+	if len(tagFilters) != 0 {
+		states, err = c.filterByTags(ctx, b, states, tagFilters)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
 	env, isOverridden := c.WorkspaceOverridden(ctx)
 
+	if detailed || jsonOutput {
+		details := c.workspaceDetails(ctx, b, states)
+		var status int
+		if jsonOutput {
+			status = c.showJSONDetails(details, env)
+		} else {
+			status = c.showDetails(details, env)
+		}
+		if isOverridden && !jsonOutput {
+			c.Ui.Output(envIsOverriddenNote)
+		}
+		return status
+	}
+
 	var out bytes.Buffer
 	for _, s := range states {
 		if s == env {
@@ -97,12 +136,202 @@ func (c *WorkspaceListCommand) Run(args []string) int {
 	return 0
 }
 
+// filterByTags returns the given workspaces that have all of the given tags,
+// each of which is either "key=value" or just "key" to match any value.
+func (c *WorkspaceListCommand) filterByTags(ctx context.Context, b backend.Backend, workspaces []string, tags []string) ([]string, error) {
+	store, ok := b.(backend.WorkspaceMetadataStore)
+	if !ok {
+		return nil, fmt.Errorf("Failed to filter workspaces by tag: %w", backend.ErrWorkspaceMetadataNotSupported)
+	}
+
+	var ret []string
+	for _, workspace := range workspaces {
+		meta, err := store.WorkspaceMetadata(ctx, workspace)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the metadata of workspace %q: %w", workspace, err)
+		}
+		matches := true
+		for _, tag := range tags {
+			if !meta.MatchesTag(tag) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			ret = append(ret, workspace)
+		}
+	}
+	return ret, nil
+}
+
+// workspaceDetail is what "workspace list -detailed" shows about the state
+// of a workspace.
+type workspaceDetail struct {
+	Name      string
+	Resources int
+	Serial    uint64
+	HasSerial bool
+
+	// Locked is nil if the backend can't tell whether the state is locked.
+	Locked *bool
+
+	Err error
+}
+
+// workspaceDetails reads the states of the given workspaces, several at a
+// time, returning their details in the same order. A workspace whose state
+// can't be read has its error recorded in its details.
+func (c *WorkspaceListCommand) workspaceDetails(ctx context.Context, b backend.Backend, workspaces []string) []*workspaceDetail {
+	details := make([]*workspaceDetail, len(workspaces))
+	var wg sync.WaitGroup
+	sem := farseek.NewSemaphore(workspaceListConcurrency)
+	for i, workspace := range workspaces {
+		wg.Go(func() {
+			sem.Acquire()
+			defer sem.Release()
+			details[i] = workspaceDetailOf(ctx, b, workspace)
+		})
+	}
+	wg.Wait()
+	return details
+}
+
+func workspaceDetailOf(ctx context.Context, b backend.Backend, workspace string) *workspaceDetail {
+	detail := &workspaceDetail{Name: workspace}
+
+	stateMgr, err := b.StateMgr(ctx, workspace)
+	if err != nil {
+		detail.Err = fmt.Errorf("Failed to load the state: %w", err)
+		return detail
+	}
+	if err := stateMgr.RefreshState(ctx); err != nil {
+		detail.Err = fmt.Errorf("Failed to load the state: %w", err)
+		return detail
+	}
+
+	if state := stateMgr.State(); state != nil {
+		for _, ms := range state.Modules {
+			for _, rs := range ms.Resources {
+				if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+					continue
+				}
+				for _, is := range rs.Instances {
+					if is.Current != nil {
+						detail.Resources++
+					}
+				}
+			}
+		}
+	}
+	if meta, ok := stateMgr.(statemgr.PersistentMeta); ok {
+		if snapshot := meta.StateSnapshotMeta(); snapshot.Lineage != "" {
+			detail.Serial = snapshot.Serial
+			detail.HasSerial = true
+		}
+	}
+	if inspector, ok := stateMgr.(statemgr.LockInspector); ok {
+		holder, err := inspector.LockHolder(ctx)
+		if err != nil {
+			detail.Err = fmt.Errorf("Failed to read the state lock: %w", err)
+			return detail
+		}
+		locked := holder != nil
+		detail.Locked = &locked
+	}
+	return detail
+}
+
+// showDetails renders the details of the workspaces as a table, returning 1
+// if the state of any couldn't be read.
+func (c *WorkspaceListCommand) showDetails(details []*workspaceDetail, current string) int {
+	status := 0
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  WORKSPACE\tRESOURCES\tSERIAL\tLOCK")
+	for _, detail := range details {
+		marker := "  "
+		if detail.Name == current {
+			marker = "* "
+		}
+		if detail.Err != nil {
+			fmt.Fprintf(w, "%s%s\t-\t-\t-\n", marker, detail.Name)
+			c.Ui.Error(fmt.Sprintf("Workspace %q: %s", detail.Name, detail.Err))
+			status = 1
+			continue
+		}
+		serial := "-"
+		if detail.HasSerial {
+			serial = fmt.Sprint(detail.Serial)
+		}
+		lock := "unknown"
+		if detail.Locked != nil {
+			lock = "unlocked"
+			if *detail.Locked {
+				lock = "locked"
+			}
+		}
+		fmt.Fprintf(w, "%s%s\t%d\t%s\t%s\n", marker, detail.Name, detail.Resources, serial, lock)
+	}
+	w.Flush()
+	c.Ui.Output(out.String())
+	return status
+}
+
+func (c *WorkspaceListCommand) showJSONDetails(details []*workspaceDetail, current string) int {
+	type jsonWorkspace struct {
+		Name      string  `json:"name"`
+		Current   bool    `json:"current"`
+		Resources *int    `json:"resources"`
+		Serial    *uint64 `json:"serial"`
+		Locked    *bool   `json:"locked"`
+		Error     string  `json:"error,omitempty"`
+	}
+	type jsonOutput struct {
+		FormatVersion string          `json:"format_version"`
+		Workspaces    []jsonWorkspace `json:"workspaces"`
+	}
+
+	status := 0
+	out := jsonOutput{
+		FormatVersion: "1.0",
+		Workspaces:    []jsonWorkspace{},
+	}
+	for _, detail := range details {
+		jw := jsonWorkspace{
+			Name:    detail.Name,
+			Current: detail.Name == current,
+		}
+		if detail.Err != nil {
+			jw.Error = detail.Err.Error()
+			status = 1
+		} else {
+			jw.Resources = &detail.Resources
+			if detail.HasSerial {
+				jw.Serial = &detail.Serial
+			}
+			jw.Locked = detail.Locked
+		}
+		out.Workspaces = append(out.Workspaces, jw)
+	}
+	j, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		// Should never happen because we fully-control the input here
+		panic(err)
+	}
+	c.Ui.Output(string(j))
+	return status
+}
+
 func (c *WorkspaceListCommand) AutocompleteArgs() complete.Predictor {
 	return complete.PredictDirs("")
 }
 
 func (c *WorkspaceListCommand) AutocompleteFlags() complete.Flags {
-	return nil
+	return complete.Flags{
+		"-tag":      complete.PredictAnything,
+		"-detailed": complete.PredictNothing,
+		"-json":     complete.PredictNothing,
+	}
 }
 
 func (c *WorkspaceListCommand) Help() string {
@@ -113,6 +342,19 @@ Usage: farseek [global options] workspace list [options]
 
 Options:
 
+  -detailed          Also show the number of resources in the state of each
+                     workspace, the serial of its latest snapshot, and
+                     whether it is locked. The states are read at the same
+                     time, several at once.
+
+  -json              Show the details of the workspaces, as with -detailed,
+                     in a machine-readable JSON format.
+
+  -tag=key=value     List only the workspaces with the given tag. Give just a
+                     key, as in -tag=env, to match any value. Use this option
+                     more than once to list only the workspaces that have all
+                     of the given tags.
+
   -var 'foo=bar'     Set a value for one of the input variables in the root
                      module of the configuration. Use this option more than
                      once to set more than one variable.
@@ -17,6 +17,7 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 
+	"github.com/rafagsiqueira/farseek/internal/backend"
 	"github.com/rafagsiqueira/farseek/internal/command/arguments"
 	"github.com/rafagsiqueira/farseek/internal/command/clistate"
 	"github.com/rafagsiqueira/farseek/internal/command/views"
@@ -37,12 +38,15 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 
 	var stateLock bool
 	var stateLockTimeout time.Duration
-	var statePath string
+	var statePath, description string
+	var tagFlags FlagStringSlice
 	cmdFlags := c.Meta.defaultFlagSet("workspace new")
 	c.Meta.varFlagSet(cmdFlags)
 	cmdFlags.BoolVar(&stateLock, "lock", true, "lock state")
 	cmdFlags.DurationVar(&stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.StringVar(&statePath, "state", "", "farseek state file")
+	cmdFlags.StringVar(&description, "description", "", "workspace description")
+	cmdFlags.Var(&tagFlags, "tag", "workspace tag")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -62,6 +66,12 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 		return 1
 	}
 
+	tags, err := parseWorkspaceTags(tagFlags)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
 	// You can't ask to create a workspace when you're overriding the
 	// workspace name to be something different.
 	if current, isOverridden := c.WorkspaceOverridden(ctx); current != workspace && isOverridden {
@@ -116,6 +126,15 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 		}
 	}
 
+	// Check that the backend can store the metadata before creating the
+	// workspace, so that a backend that can't doesn't leave it behind.
+	hasMetadata := description != "" || len(tags) != 0
+	store, ok := b.(backend.WorkspaceMetadataStore)
+	if hasMetadata && !ok {
+		c.Ui.Error(fmt.Sprintf("Failed to store the metadata of workspace %q: %s", workspace, backend.ErrWorkspaceMetadataNotSupported))
+		return 1
+	}
+
 	_, err = b.StateMgr(ctx, workspace)
 	if err != nil {
 		c.Ui.Error(err.Error())
@@ -131,6 +150,18 @@ func (c *WorkspaceNewCommand) Run(args []string) int {
 	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
 		strings.TrimSpace(envCreated), workspace)))
 
+	// The workspace exists and is selected by now, so failing to store its
+	// metadata doesn't fail the command.
+	if hasMetadata {
+		err := store.SetWorkspaceMetadata(ctx, workspace, &backend.WorkspaceMetadata{
+			Description: description,
+			Tags:        tags,
+		})
+		if err != nil {
+			c.Ui.Warn(fmt.Sprintf(envMetadataNotStored, workspace, err))
+		}
+	}
+
 	if statePath == "" {
 		// if we're not loading a state, then we're done
 		return 0
@@ -193,7 +224,9 @@ func (c *WorkspaceNewCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *WorkspaceNewCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"-state": complete.PredictFiles("*.tfstate"),
+		"-description": complete.PredictAnything,
+		"-state":       complete.PredictFiles("*.tfstate"),
+		"-tag":         complete.PredictAnything,
 	}
 }
 
@@ -205,6 +238,10 @@ Usage: farseek [global options] workspace new [OPTIONS] NAME
 
 Options:
 
+    -description=text   Describe what the workspace is for. The description
+                        is available to the configuration through the
+                        terraform_workspace data source.
+
     -lock=false         Don't hold a state lock during the operation. This is
                         dangerous if others might concurrently run commands
                         against the same workspace.
@@ -213,6 +250,15 @@ Options:
 
     -state=path         Copy an existing state file into the new workspace.
 
+    -tag=key=value      Tag the workspace, for example with env=prod. Use this
+                        option more than once to set more than one tag. Tags
+                        can be used to filter "workspace list", and are
+                        available to the configuration through the
+                        terraform_workspace data source.
+
+                        Only the local backend stores descriptions and tags.
+                        With other backends, the workspace is created
+                        without them, with a warning.
 
     -var 'foo=bar'      Set a value for one of the input variables in the root
                         module of the configuration. Use this option more than
@@ -10,6 +10,7 @@ package configs
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
@@ -18,6 +19,7 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/lang"
 	"github.com/rafagsiqueira/farseek/internal/lang/marks"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 // Backend represents a "backend" block inside a "terraform" block in a module
@@ -92,13 +94,46 @@ func (b *Backend) Hash(ctx context.Context, schema *configschema.Block) (int, hc
 }
 
 func (b *Backend) Decode(ctx context.Context, schema *configschema.Block) (cty.Value, hcl.Diagnostics) {
-	return b.Eval.DecodeBlock(ctx, b.Config, schema.DecoderSpec(), StaticIdentifier{
+	return b.Eval.DecodeBlockWithParent(ctx, backendEvalContext, b.Config, schema.DecoderSpec(), StaticIdentifier{
 		Module:    addrs.RootModule,
 		Subject:   fmt.Sprintf("backend.%s", b.Type),
 		DeclRange: b.DeclRange,
 	})
 }
 
+// backendEvalContext provides the functions that are only available in
+// backend configuration, in addition to the usual static evaluation scope.
+var backendEvalContext = &hcl.EvalContext{
+	Functions: map[string]function.Function{
+		"env": backendEnvFunc,
+	},
+}
+
+// backendEnvFunc returns the value of an environment variable. The backend
+// configuration is evaluated by "farseek init", so this allows pipelines to
+// supply settings such as bucket names without -backend-config files. It's
+// an error for the variable to be unset, so that a missing setting isn't
+// silently replaced with an empty string.
+var backendEnvFunc = function.New(&function.Spec{
+	Description: "Returns the value of an environment variable.",
+	Params: []function.Parameter{
+		{
+			Name: "name",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: func(rb *cty.RefinementBuilder) *cty.RefinementBuilder { return rb.NotNull() },
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		name := args[0].AsString()
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "environment variable %q is not set", name)
+		}
+		return cty.StringVal(val), nil
+	},
+})
+
 // This is a hack that may not be needed, but preserves the idea that invalid backends will show a cryptic error about running init during plan/apply startup.
 func (b *Backend) referenceDiagnostics(ctx context.Context, schema *configschema.Block) hcl.Diagnostics {
 	var diags hcl.Diagnostics
@@ -198,6 +198,24 @@ func (c *Config) DescendentForInstance(path addrs.ModuleInstance) *Config {
 	return current
 }
 
+// ResourceAnnotations returns the annotations that the farseek_annotations
+// argument of the given resource declares, or nil if the resource has none
+// or isn't in the configuration.
+func (c *Config) ResourceAnnotations(addr addrs.ConfigResource) map[string]string {
+	if c == nil {
+		return nil
+	}
+	mc := c.Descendent(addr.Module)
+	if mc == nil || mc.Module == nil {
+		return nil
+	}
+	rc := mc.Module.ResourceByAddr(addr.Resource)
+	if rc == nil {
+		return nil
+	}
+	return rc.Annotations
+}
+
 // EntersNewPackage returns true if this call is to an external module, either
 // directly via a remote source address or indirectly via a registry source
 // address.
@@ -84,6 +84,36 @@ func TestConfigProviderTypes_nested(t *testing.T) {
 	}
 }
 
+func TestConfigResourceAnnotations(t *testing.T) {
+	cfg, diags := testNestedModuleConfigFromDir(t, "testdata/valid-modules/resource-annotations")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	tests := map[string]map[string]string{
+		"test_instance.payments": {
+			"team":    "payments",
+			"runbook": "https://runbooks.example.com/payments",
+		},
+		"test_instance.unowned":                 nil,
+		"test_instance.overridden":              {"team": "networking"},
+		"module.child.data.test_lookup.owner":   {"team": "identity", "severity": "2"},
+		"test_instance.missing":                 nil,
+		"module.missing.test_instance.payments": nil,
+	}
+	for addrStr, want := range tests {
+		t.Run(addrStr, func(t *testing.T) {
+			addr, diags := addrs.ParseAbsResourceStr(addrStr)
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+			if diff := cmp.Diff(want, cfg.ResourceAnnotations(addr.Config())); diff != "" {
+				t.Errorf("wrong annotations\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestConfigResolveAbsProviderAddr(t *testing.T) {
 	cfg, diags := testModuleConfigFromDir(t.Context(), "testdata/providers-explicit-fqn")
 	if diags.HasErrors() {
@@ -0,0 +1,175 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	tfversion "github.com/rafagsiqueira/farseek/version"
+)
+
+// farseekFeatures are the Farseek-specific features that a module can
+// require in the required_features argument of a "farseek" block, so that
+// it can't be used by an older Farseek, or another tool, that would silently
+// ignore the behavior it relies on.
+//
+// Names are never removed from this set while Farseek still has the feature,
+// and a feature must be in this set before modules can require it.
+var farseekFeatures = map[string]bool{
+	// Discovering which resources to plan from the changes in git, instead
+	// of from state.
+	"stateless": true,
+
+	// Installing providers from OCI registries.
+	"oci_providers": true,
+
+	// Installing modules from OCI registries.
+	"oci_modules": true,
+
+	// Skipping the files and resources listed in .farseekignore during
+	// discovery.
+	"farseekignore": true,
+
+	// Project files, farseek.hcl, declaring several configuration roots and
+	// the dependencies between them.
+	"projects": true,
+
+	// The farseek_annotations meta-argument of resources.
+	"annotations": true,
+
+	// Provisioners with when = update.
+	"update_provisioners": true,
+
+	// The farseek_cache_ttl meta-argument of data resources.
+	"data_source_cache": true,
+}
+
+// RequiredFeature is a Farseek-specific feature that a module requires in
+// the required_features argument of a "farseek" block.
+type RequiredFeature struct {
+	Name      string
+	DeclRange hcl.Range
+}
+
+// sniffRequiredFeatures does minimal parsing of the given body for "farseek"
+// blocks with "required_features" attributes, returning the features found.
+//
+// Like sniffCoreVersionRequirements, this is a "best effort" method that
+// tolerates constructs from future Farseek versions elsewhere in the body,
+// so that a module requiring a feature this version doesn't have reports
+// that instead of whatever else is new to this version.
+func sniffRequiredFeatures(body hcl.Body) ([]RequiredFeature, hcl.Diagnostics) {
+	rootContent, _, diags := body.PartialContent(configFileFarseekBlockSniffRootSchema)
+
+	var features []RequiredFeature
+	for _, block := range rootContent.Blocks {
+		content, _, blockDiags := block.Body.PartialContent(configFileFeaturesSniffBlockSchema)
+		diags = append(diags, blockDiags...)
+
+		attr, exists := content.Attributes["required_features"]
+		if !exists {
+			continue
+		}
+
+		exprs, listDiags := hcl.ExprList(attr.Expr)
+		diags = append(diags, listDiags...)
+		for _, expr := range exprs {
+			val, valDiags := expr.Value(nil)
+			diags = append(diags, valDiags...)
+			if valDiags.HasErrors() {
+				continue
+			}
+			if val.Type() != cty.String || val.IsNull() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid required feature",
+					Detail:   "Each required feature must be the name of a Farseek feature, as a string.",
+					Subject:  expr.Range().Ptr(),
+				})
+				continue
+			}
+			features = append(features, RequiredFeature{
+				Name:      val.AsString(),
+				DeclRange: expr.Range(),
+			})
+		}
+	}
+
+	return features, diags
+}
+
+// checkRequiredFeatures returns an error for each feature that the module
+// requires but this version of Farseek doesn't have.
+func (m *Module) checkRequiredFeatures(path addrs.Module, sourceAddr addrs.ModuleSource) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for _, feature := range m.RequiredFeatures {
+		if farseekFeatures[feature.Name] {
+			continue
+		}
+		module := "This configuration"
+		if len(path) != 0 {
+			module = fmt.Sprintf("Module %s (from %s)", path, sourceAddr)
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported Farseek feature",
+			Detail: fmt.Sprintf(
+				"%s requires the Farseek feature %q, which Farseek version %s doesn't have. Use a version of Farseek that has it, or remove it from required_features if the configuration doesn't rely on it. The features of this version are %s.",
+				module, feature.Name, tfversion.String(), supportedFeaturesList(),
+			),
+			Subject: feature.DeclRange.Ptr(),
+			Extra:   tfdiags.CodeExtra(tfdiags.CodeUnsupportedFeature),
+		})
+	}
+
+	return diags
+}
+
+// supportedFeaturesList returns the names of the features in farseekFeatures,
+// for error messages.
+func supportedFeaturesList() string {
+	names := make([]string, 0, len(farseekFeatures))
+	for name := range farseekFeatures {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// farseekBlockSchema is the schema for a top-level "farseek" block in a
+// configuration file. Both of its arguments are sniffed before the rest of
+// the file is decoded, by sniffCoreVersionRequirements and
+// sniffRequiredFeatures.
+var farseekBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "required_version"},
+		{Name: "required_features"},
+	},
+}
+
+// configFileFarseekBlockSniffRootSchema is a schema for
+// sniffRequiredFeatures.
+var configFileFarseekBlockSniffRootSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "farseek",
+		},
+	},
+}
+
+// configFileFeaturesSniffBlockSchema is a schema for sniffRequiredFeatures,
+// to decode a single attribute from inside a "farseek" block.
+var configFileFeaturesSniffBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "required_features"},
+	},
+}
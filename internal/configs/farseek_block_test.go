@@ -0,0 +1,139 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestModule_farseekBlock(t *testing.T) {
+	tests := map[string]struct {
+		files       map[string]string
+		wantSummary string
+		wantDetail  string
+	}{
+		"supported features": {
+			files: map[string]string{
+				"mod/main.tf": `
+farseek {
+  required_version  = ">= 0.0.1"
+  required_features = ["stateless", "oci_providers"]
+}
+`,
+			},
+		},
+		"unsupported feature": {
+			files: map[string]string{
+				"mod/main.tf": `
+farseek {
+  required_features = ["stateless", "time_travel"]
+}
+`,
+			},
+			wantSummary: "Unsupported Farseek feature",
+			wantDetail:  `requires the Farseek feature "time_travel"`,
+		},
+		"unsupported version": {
+			files: map[string]string{
+				"mod/main.tf": `
+farseek {
+  required_version = "< 0.0.1"
+}
+`,
+			},
+			wantSummary: "Unsupported Farseek Core version",
+		},
+		"overridden feature": {
+			files: map[string]string{
+				"mod/main.tf": `
+farseek {
+  required_features = ["time_travel"]
+}
+`,
+				"mod/main_override.tf": `
+farseek {
+  required_features = ["stateless"]
+}
+`,
+			},
+		},
+		"unsupported feature alongside future constructs": {
+			files: map[string]string{
+				"mod/main.tf": `
+farseek {
+  required_features = ["time_travel"]
+}
+
+resource "foo" "bar" {
+  future_argument = 1
+}
+`,
+			},
+			wantSummary: "Unsupported Farseek feature",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := testParser(test.files)
+			mod, _ := parser.LoadConfigDir("mod", RootModuleCallForTesting())
+			if mod == nil {
+				t.Fatal("no module")
+			}
+
+			diags := mod.CheckCoreVersionRequirements(addrs.RootModule, nil)
+			if test.wantSummary == "" {
+				assertNoDiagnostics(t, diags)
+				return
+			}
+			assertDiagnosticCount(t, diags, 1)
+			assertDiagnosticSummary(t, diags, test.wantSummary)
+			if !strings.Contains(diags[0].Detail, test.wantDetail) {
+				t.Errorf("wrong detail %q; want it to contain %q", diags[0].Detail, test.wantDetail)
+			}
+		})
+	}
+}
+
+func TestModule_farseekBlockFeatureCode(t *testing.T) {
+	parser := testParser(map[string]string{
+		"mod/main.tf": `
+farseek {
+  required_features = ["time_travel"]
+}
+`,
+	})
+	mod, diags := parser.LoadConfigDir("mod", RootModuleCallForTesting())
+	assertNoDiagnostics(t, diags)
+
+	diags = mod.CheckCoreVersionRequirements(addrs.RootModule, nil)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+	got := tfdiags.DiagnosticCode(tfdiags.Diagnostics(nil).Append(diags)[0])
+	if got != tfdiags.CodeUnsupportedFeature {
+		t.Errorf("wrong code %q; want %q", got, tfdiags.CodeUnsupportedFeature)
+	}
+}
+
+func TestParserLoadConfigFile_farseekBlockInvalid(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+farseek {
+  required_features = [1, "stateless"]
+  unknown           = true
+}
+`,
+	})
+	f, diags := parser.LoadConfigFile("main.tf")
+	assertDiagnosticCount(t, diags, 2)
+	assertDiagnosticSummary(t, diags, "Unsupported argument")
+	if len(f.RequiredFeatures) != 1 || f.RequiredFeatures[0].Name != "stateless" {
+		t.Errorf("wrong required features %#v", f.RequiredFeatures)
+	}
+}
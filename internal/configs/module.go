@@ -35,6 +35,7 @@ type Module struct {
 	SourceDir string
 
 	CoreVersionConstraints []VersionConstraint
+	RequiredFeatures       []RequiredFeature
 
 	ActiveExperiments experiments.Set
 
@@ -92,6 +93,7 @@ func (m *Module) GetProviderConfig(name, alias string) (*Provider, bool) {
 // duplicate declarations.
 type File struct {
 	CoreVersionConstraints []VersionConstraint
+	RequiredFeatures       []RequiredFeature
 
 	ActiveExperiments experiments.Set
 
@@ -305,6 +307,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 	// If there are any conflicting requirements then we'll catch them
 	// when we actually check these constraints.
 	m.CoreVersionConstraints = append(m.CoreVersionConstraints, file.CoreVersionConstraints...)
+	m.RequiredFeatures = append(m.RequiredFeatures, file.RequiredFeatures...)
 
 	m.ActiveExperiments = experiments.SetUnion(m.ActiveExperiments, file.ActiveExperiments)
 
@@ -612,6 +615,12 @@ func (m *Module) mergeFile(file *File) hcl.Diagnostics {
 		m.CoreVersionConstraints = append(m.CoreVersionConstraints, file.CoreVersionConstraints...)
 	}
 
+	if len(file.RequiredFeatures) != 0 {
+		// Override files clobber the required features in the same way.
+		m.RequiredFeatures = nil
+		m.RequiredFeatures = append(m.RequiredFeatures, file.RequiredFeatures...)
+	}
+
 	if len(file.Backends) != 0 {
 		switch len(file.Backends) {
 		case 1:
@@ -929,6 +938,8 @@ func (m *Module) CheckCoreVersionRequirements(path addrs.Module, sourceAddr addr
 		}
 	}
 
+	diags = append(diags, m.checkRequiredFeatures(path, sourceAddr)...)
+
 	return diags
 }
 
@@ -246,6 +246,13 @@ func (r *Resource) merge(or *Resource, rps map[string]*RequiredProvider) hcl.Dia
 		}
 	}
 
+	if or.Annotations != nil {
+		r.Annotations = or.Annotations
+	}
+	if or.CacheTTL != nil {
+		r.CacheTTL = or.CacheTTL
+	}
+
 	// Provider FQN is set by Farseek during Merge
 
 	if r.Mode == addrs.ManagedResourceMode {
@@ -0,0 +1,264 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// OverrideSources describes which files the arguments of each block of a
+// module come from, once its override files are merged into its primary
+// files, to audit the effective configuration of modules that rely on
+// override files.
+type OverrideSources struct {
+	// OverrideFiles are the override files of the module, in the order they
+	// are merged in.
+	OverrideFiles []string
+
+	// Blocks are the blocks that can be overridden, sorted by address.
+	Blocks []*OverrideBlock
+}
+
+// OverrideBlock is a block of a module, with the sources of its arguments.
+type OverrideBlock struct {
+	// Addr is the address of the block, such as aws_instance.web, var.name
+	// or provider.aws.west. Each local value is a block of its own, whose
+	// only argument is "value".
+	Addr string
+
+	// BaseFile is the primary file that declares the block, or empty if
+	// only override files do. OverrideFiles are the override files that
+	// change it, if any.
+	BaseFile      string
+	OverrideFiles []string
+
+	// Arguments are the arguments and nested block types of the block,
+	// sorted by name. The arguments of a lifecycle block are named like
+	// lifecycle.create_before_destroy, since they are overridden one by one.
+	Arguments []*OverrideArgument
+}
+
+// Overridden returns whether an override file changes the block.
+func (b *OverrideBlock) Overridden() bool {
+	return len(b.OverrideFiles) != 0
+}
+
+// OverrideArgument is an argument or nested block type of a block, with the
+// file that the effective configuration takes it from.
+type OverrideArgument struct {
+	Name string
+
+	// Block is true for a nested block type, all of whose blocks an
+	// override file replaces at once.
+	Block bool
+
+	// Source is where the argument is set in the effective configuration.
+	// If Override is true, it is in an override file, and Replaces is where
+	// it was set before, if anywhere.
+	Source   hcl.Range
+	Override bool
+	Replaces *hcl.Range
+}
+
+// overrideSourceSchema is the schema of the top-level blocks that override
+// files can change argument by argument.
+var overrideSourceSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "ephemeral", LabelNames: []string{"type", "name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "locals"},
+	},
+}
+
+// LoadOverrideSources reads the primary and override files of the module in
+// the given directory, and returns which file each argument of its blocks
+// comes from once they are merged.
+//
+// Unlike loading the module, this only looks at the syntax of the files, so
+// it still describes a module whose override files make it invalid.
+func (p *Parser) LoadOverrideSources(dir string) (*OverrideSources, hcl.Diagnostics) {
+	primaryPaths, overridePaths, _, diags := p.dirFiles(dir, "")
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ret := &OverrideSources{}
+	blocks := make(map[string]*OverrideBlock)
+	arguments := make(map[string]map[string]*OverrideArgument)
+	rel := func(path string) string {
+		if r, err := filepath.Rel(dir, path); err == nil {
+			return r
+		}
+		return path
+	}
+
+	merge := func(path string, override bool) {
+		body, fileDiags := p.LoadHCLFile(path)
+		diags = append(diags, fileDiags...)
+		if body == nil {
+			return
+		}
+		content, _, contentDiags := body.PartialContent(overrideSourceSchema)
+		diags = append(diags, contentDiags...)
+
+		file := rel(path)
+		if override {
+			ret.OverrideFiles = append(ret.OverrideFiles, file)
+		}
+		for _, decl := range overrideSourceDecls(content.Blocks) {
+			block, ok := blocks[decl.addr]
+			if !ok {
+				block = &OverrideBlock{Addr: decl.addr}
+				blocks[decl.addr] = block
+				arguments[decl.addr] = make(map[string]*OverrideArgument)
+			}
+			if !override && block.BaseFile == "" {
+				block.BaseFile = file
+			}
+			if override && !slices.Contains(block.OverrideFiles, file) {
+				block.OverrideFiles = append(block.OverrideFiles, file)
+			}
+
+			for _, arg := range decl.arguments {
+				arg.Source.Filename = rel(arg.Source.Filename)
+				if override {
+					arg.Override = true
+					if prev, ok := arguments[decl.addr][arg.Name]; ok {
+						arg.Replaces = prev.Source.Ptr()
+					}
+				}
+				arguments[decl.addr][arg.Name] = arg
+			}
+		}
+	}
+	for _, path := range primaryPaths {
+		merge(path, false)
+	}
+	for _, path := range overridePaths {
+		merge(path, true)
+	}
+
+	for addr, block := range blocks {
+		for _, arg := range arguments[addr] {
+			block.Arguments = append(block.Arguments, arg)
+		}
+		slices.SortFunc(block.Arguments, func(a, b *OverrideArgument) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		ret.Blocks = append(ret.Blocks, block)
+	}
+	slices.SortFunc(ret.Blocks, func(a, b *OverrideBlock) int {
+		return strings.Compare(a.Addr, b.Addr)
+	})
+	return ret, diags
+}
+
+type overrideSourceDecl struct {
+	addr      string
+	arguments []*OverrideArgument
+}
+
+// overrideSourceDecls returns the addresses and arguments of the given
+// top-level blocks, splitting locals blocks into a block for each local
+// value.
+func overrideSourceDecls(blocks hcl.Blocks) []overrideSourceDecl {
+	var ret []overrideSourceDecl
+	for _, block := range blocks {
+		switch block.Type {
+		case "locals":
+			attrs, _ := block.Body.JustAttributes()
+			for name, attr := range attrs {
+				ret = append(ret, overrideSourceDecl{
+					addr:      "local." + name,
+					arguments: []*OverrideArgument{{Name: "value", Source: attr.Range}},
+				})
+			}
+			continue
+		}
+
+		var addr string
+		switch block.Type {
+		case "resource":
+			addr = block.Labels[0] + "." + block.Labels[1]
+		case "data", "ephemeral":
+			addr = block.Type + "." + block.Labels[0] + "." + block.Labels[1]
+		case "variable":
+			addr = "var." + block.Labels[0]
+		case "provider":
+			addr = "provider." + block.Labels[0]
+			if alias := overrideSourceAlias(block.Body); alias != "" {
+				addr += "." + alias
+			}
+		default:
+			addr = block.Type + "." + block.Labels[0]
+		}
+		ret = append(ret, overrideSourceDecl{
+			addr:      addr,
+			arguments: overrideSourceArguments(block.Body, ""),
+		})
+	}
+	return ret
+}
+
+// overrideSourceArguments returns the arguments and nested block types of
+// the given block body, with the arguments of its lifecycle block given
+// separately.
+func overrideSourceArguments(body hcl.Body, prefix string) []*OverrideArgument {
+	var ret []*OverrideArgument
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		// The JSON syntax doesn't tell arguments and nested blocks apart
+		// without a schema, so they are all taken to be arguments.
+		attrs, _ := body.JustAttributes()
+		for name, attr := range attrs {
+			ret = append(ret, &OverrideArgument{Name: prefix + name, Source: attr.Range})
+		}
+		return ret
+	}
+
+	for name, attr := range syntaxBody.Attributes {
+		ret = append(ret, &OverrideArgument{Name: prefix + name, Source: attr.SrcRange})
+	}
+	seen := make(map[string]bool)
+	for _, block := range syntaxBody.Blocks {
+		if block.Type == "lifecycle" && prefix == "" {
+			ret = append(ret, overrideSourceArguments(block.Body, "lifecycle.")...)
+			continue
+		}
+		if seen[block.Type] {
+			continue
+		}
+		seen[block.Type] = true
+		ret = append(ret, &OverrideArgument{Name: prefix + block.Type, Block: true, Source: block.DefRange()})
+	}
+	return ret
+}
+
+// overrideSourceAlias returns the alias of a provider block, if it is a
+// literal string.
+func overrideSourceAlias(body hcl.Body) string {
+	content, _, _ := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "alias"}},
+	})
+	attr, ok := content.Attributes["alias"]
+	if !ok {
+		return ""
+	}
+	var alias string
+	if diags := gohcl.DecodeExpression(attr.Expr, nil, &alias); diags.HasErrors() {
+		return ""
+	}
+	return alias
+}
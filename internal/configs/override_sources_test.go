@@ -0,0 +1,109 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadOverrideSources(t *testing.T) {
+	p := testParser(map[string]string{
+		"mod/main.tf": `
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = "t3.micro"
+
+  ebs_block_device {
+    device_name = "/dev/sdb"
+  }
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-2"
+}
+
+locals {
+  env  = "prod"
+  name = "web"
+}
+
+variable "unchanged" {
+  type = string
+}
+`,
+		"mod/main_override.tf": `
+resource "aws_instance" "web" {
+  instance_type = "t3.large"
+  tags          = { Team = "web" }
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+provider "aws" {
+  alias  = "west"
+  region = "us-west-1"
+}
+
+locals {
+  env = "staging"
+}
+`,
+		"mod/override.tf": `
+resource "aws_instance" "web" {
+  instance_type = "t3.xlarge"
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+  }
+}
+`,
+	})
+
+	sources, diags := p.LoadOverrideSources("mod")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	if got, want := sources.OverrideFiles, []string{"main_override.tf", "override.tf"}; !cmp.Equal(got, want) {
+		t.Errorf("wrong override files\n%s", cmp.Diff(want, got))
+	}
+
+	// Render the blocks compactly, as address, base file, override files
+	// and then each argument with its source and what it replaces.
+	var got []string
+	for _, block := range sources.Blocks {
+		line := fmt.Sprintf("%s base=%s overrides=%s", block.Addr, block.BaseFile, strings.Join(block.OverrideFiles, ","))
+		for _, arg := range block.Arguments {
+			line += fmt.Sprintf(" %s@%s:%d", arg.Name, arg.Source.Filename, arg.Source.Start.Line)
+			if arg.Block {
+				line += "(block)"
+			}
+			if arg.Replaces != nil {
+				line += fmt.Sprintf("<%s:%d", arg.Replaces.Filename, arg.Replaces.Start.Line)
+			}
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"aws_instance.web base=main.tf overrides=main_override.tf,override.tf ami@main.tf:3 ebs_block_device@override.tf:5(block)<main.tf:6 instance_type@override.tf:3<main_override.tf:3 lifecycle.create_before_destroy@main.tf:11 lifecycle.prevent_destroy@main_override.tf:7 tags@main_override.tf:4",
+		"local.env base=main.tf overrides=main_override.tf value@main_override.tf:17<main.tf:21",
+		"local.name base=main.tf overrides= value@main.tf:22",
+		"provider.aws.west base=main.tf overrides=main_override.tf alias@main_override.tf:12<main.tf:16 region@main_override.tf:13<main.tf:17",
+		"var.unchanged base=main.tf overrides= type@main.tf:26",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong blocks\n%s", diff)
+	}
+}
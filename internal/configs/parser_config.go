@@ -71,6 +71,10 @@ func loadConfigFileBody(body hcl.Body, filename string, override bool, allowExpe
 	file.CoreVersionConstraints, reqDiags = sniffCoreVersionRequirements(body)
 	diags = append(diags, reqDiags...)
 
+	var featDiags hcl.Diagnostics
+	file.RequiredFeatures, featDiags = sniffRequiredFeatures(body)
+	diags = append(diags, featDiags...)
+
 	// We'll load the experiments first because other decoding logic in the
 	// loop below might depend on these experiments.
 	var expDiags hcl.Diagnostics
@@ -83,6 +87,13 @@ func loadConfigFileBody(body hcl.Body, filename string, override bool, allowExpe
 	for _, block := range content.Blocks {
 		switch block.Type {
 
+		case "farseek":
+			// Both of the arguments of a "farseek" block were already dealt
+			// with by sniffCoreVersionRequirements and sniffRequiredFeatures
+			// above, so we only check here that there's nothing else in it.
+			_, contentDiags := block.Body.Content(farseekBlockSchema)
+			diags = append(diags, contentDiags...)
+
 		case "terraform":
 			content, contentDiags := block.Body.Content(terraformBlockSchema)
 			diags = append(diags, contentDiags...)
@@ -238,8 +249,8 @@ func loadConfigFileBody(body hcl.Body, filename string, override bool, allowExpe
 }
 
 // sniffCoreVersionRequirements does minimal parsing of the given body for
-// "terraform" blocks with "required_version" attributes, returning the
-// requirements found.
+// "terraform" and "farseek" blocks with "required_version" attributes,
+// returning the requirements found.
 //
 // This is intended to maximize the chance that we'll be able to read the
 // requirements (syntax errors notwithstanding) even if the config file contains
@@ -249,7 +260,7 @@ func loadConfigFileBody(body hcl.Body, filename string, override bool, allowExpe
 // able to find, but may return no constraints at all if the given body is
 // so invalid that it cannot be decoded at all.
 func sniffCoreVersionRequirements(body hcl.Body) ([]VersionConstraint, hcl.Diagnostics) {
-	rootContent, _, diags := body.PartialContent(configFileTerraformBlockSniffRootSchema)
+	rootContent, _, diags := body.PartialContent(configFileVersionSniffRootSchema)
 
 	var constraints []VersionConstraint
 
@@ -280,6 +291,9 @@ var configFileSchema = &hcl.BodySchema{
 		{
 			Type: "terraform",
 		},
+		{
+			Type: "farseek",
+		},
 		{
 			// This one is not really valid, but we include it here so we
 			// can create a specialized error message hinting the user to
@@ -363,7 +377,7 @@ var terraformBlockSchema = &hcl.BodySchema{
 }
 
 // configFileTerraformBlockSniffRootSchema is a schema for
-// sniffCoreVersionRequirements and sniffActiveExperiments.
+// sniffActiveExperiments.
 var configFileTerraformBlockSniffRootSchema = &hcl.BodySchema{
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -372,6 +386,19 @@ var configFileTerraformBlockSniffRootSchema = &hcl.BodySchema{
 	},
 }
 
+// configFileVersionSniffRootSchema is a schema for
+// sniffCoreVersionRequirements.
+var configFileVersionSniffRootSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "terraform",
+		},
+		{
+			Type: "farseek",
+		},
+	},
+}
+
 // configFileVersionSniffBlockSchema is a schema for sniffCoreVersionRequirements
 var configFileVersionSniffBlockSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
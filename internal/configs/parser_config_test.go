@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -168,6 +169,12 @@ func TestParserLoadConfigFileFailureMessages(t *testing.T) {
 			"Invalid default value for variable",
 			`This default value is not compatible with the variable's type constraint: ["mykey"].field: a bool is required.`,
 		},
+		{
+			"invalid-files/data-cache-ttl-invalid.tf",
+			hcl.DiagError,
+			"Invalid farseek_cache_ttl argument",
+			`The farseek_cache_ttl argument must be a duration string, such as "1h" or "30m", but "an hour" isn't a valid duration.`,
+		},
 		{
 			"invalid-files/empty-deprecated-output-attr.tf",
 			hcl.DiagError,
@@ -339,3 +346,39 @@ func TestParserLoadConfigFileError(t *testing.T) {
 		})
 	}
 }
+
+func TestParserLoadConfigFile_dataCacheTTL(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+data "test_lookup" "cached" {
+  farseek_cache_ttl = "1h30m"
+}
+
+data "test_lookup" "never" {
+  farseek_cache_ttl = "0s"
+}
+
+data "test_lookup" "default" {
+}
+`,
+	})
+
+	file, diags := parser.LoadConfigFile("main.tf")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	got := make(map[string]*time.Duration)
+	for _, r := range file.DataResources {
+		got[r.Name] = r.CacheTTL
+	}
+	hourAndHalf, zero := 90*time.Minute, time.Duration(0)
+	want := map[string]*time.Duration{
+		"cached":  &hourAndHalf,
+		"never":   &zero,
+		"default": nil,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong cache TTLs\n%s", diff)
+	}
+}
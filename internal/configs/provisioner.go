@@ -56,13 +56,15 @@ func decodeProvisionerBlock(block *hcl.Block) (*Provisioner, hcl.Diagnostics) {
 		switch hcl.ExprAsKeyword(expr) {
 		case "create":
 			pv.When = ProvisionerWhenCreate
+		case "update":
+			pv.When = ProvisionerWhenUpdate
 		case "destroy":
 			pv.When = ProvisionerWhenDestroy
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid \"when\" keyword",
-				Detail:   "The \"when\" argument requires one of the following keywords: create or destroy.",
+				Detail:   "The \"when\" argument requires one of the following keywords: create, update, or destroy.",
 				Subject:  expr.Range().Ptr(),
 			})
 		}
@@ -216,6 +218,10 @@ const (
 	ProvisionerWhenInvalid ProvisionerWhen = iota
 	ProvisionerWhenCreate
 	ProvisionerWhenDestroy
+
+	// ProvisionerWhenUpdate provisioners run after an object is updated in
+	// place, but not when it's created or replaced.
+	ProvisionerWhenUpdate
 )
 
 // ProvisionerOnFailure is an enum for valid values for on_failure options
@@ -101,6 +101,32 @@ func TestProvisionerBlock_decode(t *testing.T) {
 			},
 			err: "Invalid reference from destroy provisioner",
 		},
+		"refer to self when update": {
+			input: &hcl.Block{
+				Type:   "provisioner",
+				Labels: []string{"local-exec"},
+				Body: hcltest.MockBody(&hcl.BodyContent{
+					Attributes: hcl.Attributes{
+						"when": {
+							Name: "when",
+							Expr: hcltest.MockExprTraversalSrc("update"),
+						},
+						"command": {
+							Name: "command",
+							Expr: hcltest.MockExprTraversalSrc("self.id"),
+						},
+					},
+				}),
+				DefRange:    blockRange,
+				LabelRanges: []hcl.Range{hcl.Range{}},
+			},
+			want: &Provisioner{
+				Type:      "local-exec",
+				When:      ProvisionerWhenUpdate,
+				OnFailure: ProvisionerOnFailureFail,
+				DeclRange: blockRange,
+			},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -16,11 +16,12 @@ func _() {
 	_ = x[ProvisionerWhenInvalid-0]
 	_ = x[ProvisionerWhenCreate-1]
 	_ = x[ProvisionerWhenDestroy-2]
+	_ = x[ProvisionerWhenUpdate-3]
 }
 
-const _ProvisionerWhen_name = "ProvisionerWhenInvalidProvisionerWhenCreateProvisionerWhenDestroy"
+const _ProvisionerWhen_name = "ProvisionerWhenInvalidProvisionerWhenCreateProvisionerWhenDestroyProvisionerWhenUpdate"
 
-var _ProvisionerWhen_index = [...]uint8{0, 22, 43, 65}
+var _ProvisionerWhen_index = [...]uint8{0, 22, 43, 65, 86}
 
 func (i ProvisionerWhen) String() string {
 	idx := int(i) - 0
@@ -9,12 +9,15 @@ package configs
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	hcljson "github.com/hashicorp/hcl/v2/json"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs/hcl2shim"
@@ -42,6 +45,17 @@ type Resource struct {
 
 	TriggersReplacement []hcl.Expression
 
+	// Annotations are the annotations from the farseek_annotations argument,
+	// such as the team that owns the resource. They aren't sent to the
+	// provider, and are only reported alongside the resource's changes,
+	// apply hooks, and errors.
+	Annotations map[string]string
+
+	// CacheTTL is how long Farseek may reuse the result of reading a data
+	// resource in later plans, from the farseek_cache_ttl argument. It is nil
+	// if the argument isn't set, and is only ever set for data resources.
+	CacheTTL *time.Duration
+
 	// Managed is populated only for Mode = addrs.ManagedResourceMode,
 	// containing the additional fields that apply to managed resources.
 	// For all other resource modes, this field is nil.
@@ -182,6 +196,12 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 		r.DependsOn = append(r.DependsOn, deps...)
 	}
 
+	if attr, exists := content.Attributes["farseek_annotations"]; exists {
+		var annotationsDiags hcl.Diagnostics
+		r.Annotations, annotationsDiags = decodeAnnotations(attr)
+		diags = append(diags, annotationsDiags...)
+	}
+
 	var seenLifecycle *hcl.Block
 	var seenConnection *hcl.Block
 	var seenEscapeBlock *hcl.Block
@@ -481,6 +501,18 @@ func decodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Di
 		r.DependsOn = append(r.DependsOn, deps...)
 	}
 
+	if attr, exists := content.Attributes["farseek_annotations"]; exists {
+		var annotationsDiags hcl.Diagnostics
+		r.Annotations, annotationsDiags = decodeAnnotations(attr)
+		diags = append(diags, annotationsDiags...)
+	}
+
+	if attr, exists := content.Attributes["farseek_cache_ttl"]; exists {
+		var ttlDiags hcl.Diagnostics
+		r.CacheTTL, ttlDiags = decodeCacheTTL(attr)
+		diags = append(diags, ttlDiags...)
+	}
+
 	var seenEscapeBlock *hcl.Block
 	var seenLifecycle *hcl.Block
 	for _, block := range content.Blocks {
@@ -1089,6 +1121,80 @@ func (r *ProviderConfigRef) InstanceValidation(blockType string, isInstanced boo
 	return diags
 }
 
+// decodeAnnotations decodes the farseek_annotations argument of a resource,
+// which must be a map of strings that doesn't refer to anything, since the
+// annotations are reported even where the resource can't be evaluated.
+func decodeAnnotations(attr *hcl.Attribute) (map[string]string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	val, valDiags := attr.Expr.Value(nil)
+	diags = append(diags, valDiags...)
+	if valDiags.HasErrors() {
+		return nil, diags
+	}
+
+	ty := val.Type()
+	if val.IsNull() || !(ty.IsObjectType() || ty.IsMapType()) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid farseek_annotations argument",
+			Detail:   "The farseek_annotations argument must be a map of strings, such as { team = \"payments\" }.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+
+	annotations := make(map[string]string, val.LengthInt())
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		v, err := convert.Convert(v, cty.String)
+		if err != nil || v.IsNull() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid farseek_annotations argument",
+				Detail:   fmt.Sprintf("The value of the %q annotation must be a string.", k.AsString()),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+		annotations[k.AsString()] = v.AsString()
+	}
+	return annotations, diags
+}
+
+// decodeCacheTTL decodes the farseek_cache_ttl argument of a data resource,
+// which must be a duration string, such as "1h", that doesn't refer to
+// anything. A duration of zero opts the data resource out of a default TTL
+// set on the command line.
+func decodeCacheTTL(attr *hcl.Attribute) (*time.Duration, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	val, valDiags := attr.Expr.Value(nil)
+	diags = append(diags, valDiags...)
+	if valDiags.HasErrors() {
+		return nil, diags
+	}
+
+	if val.Type() != cty.String || val.IsNull() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid farseek_cache_ttl argument",
+			Detail:   "The farseek_cache_ttl argument must be a duration string, such as \"1h\" or \"30m\".",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+	ttl, err := time.ParseDuration(val.AsString())
+	if err != nil || ttl < 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid farseek_cache_ttl argument",
+			Detail:   fmt.Sprintf("The farseek_cache_ttl argument must be a duration string, such as \"1h\" or \"30m\", but %q isn't a valid duration.", val.AsString()),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+	return &ttl, diags
+}
+
 var commonResourceAttributes = []hcl.AttributeSchema{
 	{
 		Name: "count",
@@ -1102,6 +1208,9 @@ var commonResourceAttributes = []hcl.AttributeSchema{
 	{
 		Name: "depends_on",
 	},
+	{
+		Name: "farseek_annotations",
+	},
 }
 
 // ResourceBlockSchema is the schema for a resource or data resource type within
@@ -1121,7 +1230,9 @@ var ResourceBlockSchema = &hcl.BodySchema{
 }
 
 var dataBlockSchema = &hcl.BodySchema{
-	Attributes: commonResourceAttributes,
+	Attributes: append(slices.Clone(commonResourceAttributes), hcl.AttributeSchema{
+		Name: "farseek_cache_ttl",
+	}),
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: "lifecycle"},
 		{Type: "locals"}, // reserved for future use
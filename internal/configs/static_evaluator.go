@@ -126,6 +126,12 @@ func (s StaticEvaluator) DecodeExpression(ctx context.Context, expr hcl.Expressi
 }
 
 func (s StaticEvaluator) DecodeBlock(ctx context.Context, body hcl.Body, spec hcldec.Spec, ident StaticIdentifier) (cty.Value, hcl.Diagnostics) {
+	return s.DecodeBlockWithParent(ctx, nil, body, spec, ident)
+}
+
+// DecodeBlockWithParent is like DecodeBlock, but the variables and functions
+// of the given parent EvalContext are also available to the block.
+func (s StaticEvaluator) DecodeBlockWithParent(ctx context.Context, parent *hcl.EvalContext, body hcl.Body, spec hcldec.Spec, ident StaticIdentifier) (cty.Value, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	refs, refsDiags := lang.References(addrs.ParseRef, hcldec.Variables(body, spec))
@@ -134,7 +140,7 @@ func (s StaticEvaluator) DecodeBlock(ctx context.Context, body hcl.Body, spec hc
 		return cty.DynamicVal, diags
 	}
 
-	hclCtx, ctxDiags := s.scope(ident).EvalContext(ctx, refs)
+	hclCtx, ctxDiags := s.scope(ident).EvalContextWithParent(ctx, parent, refs)
 	diags = append(diags, ctxDiags.ToHCL()...)
 	if diags.HasErrors() {
 		return cty.DynamicVal, diags
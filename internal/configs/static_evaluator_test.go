@@ -459,3 +459,44 @@ terraform {
 		})
 	}
 }
+
+func TestBackend_DecodeEnv(t *testing.T) {
+	t.Setenv("FARSEEK_TEST_BACKEND_PATH", "from-env")
+
+	parser := testParser(map[string]string{"eval.tf": `
+terraform {
+	backend "local" {
+		thing = "${env("FARSEEK_TEST_BACKEND_PATH")}.tfstate"
+		other = env("FARSEEK_TEST_BACKEND_UNSET")
+	}
+}`})
+	file, fileDiags := parser.LoadConfigFile("eval.tf")
+	if fileDiags.HasErrors() {
+		t.Fatal(fileDiags)
+	}
+	mod, _ := NewModule([]*File{file}, nil, RootModuleCallForTesting(), "dir", SelectiveLoadAll)
+
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"thing": {Type: cty.String, Optional: true},
+			"other": {Type: cty.String, Optional: true},
+		},
+	}
+	_, diags := mod.Backend.Decode(t.Context(), schema)
+	assertExactDiagnostics(t, diags, []string{
+		`eval.tf:5,16-42: Invalid function argument; Invalid value for "name" parameter: environment variable "FARSEEK_TEST_BACKEND_UNSET" is not set.`,
+	})
+
+	t.Setenv("FARSEEK_TEST_BACKEND_UNSET", "")
+	val, diags := mod.Backend.Decode(t.Context(), schema)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"thing": cty.StringVal("from-env.tfstate"),
+		"other": cty.StringVal(""),
+	})
+	if !val.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", val, want)
+	}
+}
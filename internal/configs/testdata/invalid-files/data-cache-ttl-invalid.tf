@@ -0,0 +1,3 @@
+data "test_lookup" "ami" {
+  farseek_cache_ttl = "an hour"
+}
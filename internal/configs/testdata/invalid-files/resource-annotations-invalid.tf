@@ -0,0 +1,15 @@
+resource "test_instance" "reference" {
+  farseek_annotations = {
+    team = var.team
+  }
+}
+
+resource "test_instance" "list" {
+  farseek_annotations = ["payments"]
+}
+
+data "test_lookup" "nested" {
+  farseek_annotations = {
+    owners = ["payments"]
+  }
+}
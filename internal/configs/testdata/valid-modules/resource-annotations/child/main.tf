@@ -0,0 +1,6 @@
+data "test_lookup" "owner" {
+  farseek_annotations = {
+    team     = "identity"
+    severity = 2
+  }
+}
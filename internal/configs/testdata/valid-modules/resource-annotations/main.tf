@@ -0,0 +1,19 @@
+resource "test_instance" "payments" {
+  farseek_annotations = {
+    team    = "payments"
+    runbook = "https://runbooks.example.com/payments"
+  }
+}
+
+resource "test_instance" "unowned" {
+}
+
+resource "test_instance" "overridden" {
+  farseek_annotations = {
+    team = "platform"
+  }
+}
+
+module "child" {
+  source = "./child"
+}
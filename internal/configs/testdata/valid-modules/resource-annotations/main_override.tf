@@ -0,0 +1,5 @@
+resource "test_instance" "overridden" {
+  farseek_annotations = {
+    team = "networking"
+  }
+}
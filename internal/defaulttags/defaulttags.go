@@ -0,0 +1,201 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package defaulttags injects default tags, or labels, into the
+// configuration of every managed resource of some providers, so that
+// platform-wide tags don't depend on each module setting them.
+package defaulttags
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+)
+
+// DefaultAttribute is the attribute that the tags are merged into when
+// Defaults doesn't name one.
+const DefaultAttribute = "tags"
+
+// Defaults are the default tags of the resources of one provider.
+type Defaults struct {
+	// Attribute is the map or object attribute holding the tags, such as
+	// "tags" or "labels". Resource types whose schemas don't declare it
+	// don't get the tags.
+	Attribute string
+
+	// Tags are merged under the tags a resource sets itself, which take
+	// precedence.
+	Tags map[string]string
+}
+
+// Apply injects the default tags of each provider into the configuration of
+// the managed resources of that provider, in the given configuration and all
+// of its descendants.
+//
+// The tags are merged into the attribute when the configuration is decoded
+// with the schema of the resource type, so they're injected in the same way
+// by plan and apply, and only into resource types that declare the
+// attribute.
+func Apply(config *configs.Config, defaults map[addrs.Provider]*Defaults) {
+	if config == nil || len(defaults) == 0 {
+		return
+	}
+	config.DeepEach(func(c *configs.Config) {
+		if c.Module == nil {
+			return
+		}
+		for _, rc := range c.Module.ManagedResources {
+			ApplyResource(rc, defaults[rc.Provider])
+		}
+	})
+}
+
+// ApplyResource injects the given default tags into the configuration of a
+// single managed resource, such as one loaded from the configuration at
+// another commit. It does nothing if the defaults are nil.
+func ApplyResource(rc *configs.Resource, d *Defaults) {
+	if d == nil || len(d.Tags) == 0 || rc.Config == nil {
+		return
+	}
+	attribute := d.Attribute
+	if attribute == "" {
+		attribute = DefaultAttribute
+	}
+	rc.Config = &body{Body: rc.Config, attribute: attribute, tags: d.Tags}
+}
+
+// body wraps the configuration body of a resource, merging the default tags
+// into the tags attribute whenever a schema that declares it is decoded.
+type body struct {
+	hcl.Body
+	attribute string
+	tags      map[string]string
+}
+
+var _ hcl.Body = (*body)(nil)
+
+func (b *body) Content(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Diagnostics) {
+	content, diags := b.Body.Content(schema)
+	return b.inject(schema, content), diags
+}
+
+func (b *body) PartialContent(schema *hcl.BodySchema) (*hcl.BodyContent, hcl.Body, hcl.Diagnostics) {
+	content, remain, diags := b.Body.PartialContent(schema)
+	if remain != nil {
+		remain = &body{Body: remain, attribute: b.attribute, tags: b.tags}
+	}
+	return b.inject(schema, content), remain, diags
+}
+
+// inject replaces the tags attribute of the given content, or adds it if
+// the resource doesn't set it, if the schema declares it.
+func (b *body) inject(schema *hcl.BodySchema, content *hcl.BodyContent) *hcl.BodyContent {
+	if content == nil || schema == nil {
+		return content
+	}
+	declared := false
+	for _, attr := range schema.Attributes {
+		if attr.Name == b.attribute {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return content
+	}
+
+	attrs := make(hcl.Attributes, len(content.Attributes)+1)
+	for name, attr := range content.Attributes {
+		attrs[name] = attr
+	}
+	if attr, ok := attrs[b.attribute]; ok {
+		attrs[b.attribute] = &hcl.Attribute{
+			Name:      attr.Name,
+			Expr:      &expr{Expression: attr.Expr, tags: b.tags},
+			Range:     attr.Range,
+			NameRange: attr.NameRange,
+		}
+	} else {
+		rng := b.Body.MissingItemRange()
+		attrs[b.attribute] = &hcl.Attribute{
+			Name:      b.attribute,
+			Expr:      &expr{rng: rng, tags: b.tags},
+			Range:     rng,
+			NameRange: rng,
+		}
+	}
+
+	ret := *content
+	ret.Attributes = attrs
+	return &ret
+}
+
+// expr is the expression of a tags attribute with the default tags merged
+// under its value. Expression is nil if the resource doesn't set the tags.
+type expr struct {
+	hcl.Expression
+	rng  hcl.Range
+	tags map[string]string
+}
+
+var _ hcl.Expression = (*expr)(nil)
+
+func (e *expr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	if e.Expression == nil {
+		return merge(cty.NullVal(cty.DynamicPseudoType), e.tags), nil
+	}
+	v, diags := e.Expression.Value(ctx)
+	if diags.HasErrors() {
+		return v, diags
+	}
+	return merge(v, e.tags), diags
+}
+
+func (e *expr) Variables() []hcl.Traversal {
+	if e.Expression == nil {
+		return nil
+	}
+	return e.Expression.Variables()
+}
+
+func (e *expr) Range() hcl.Range {
+	if e.Expression == nil {
+		return e.rng
+	}
+	return e.Expression.Range()
+}
+
+func (e *expr) StartRange() hcl.Range {
+	if e.Expression == nil {
+		return e.rng
+	}
+	return e.Expression.StartRange()
+}
+
+// merge returns the given tags with the default tags merged under them. It
+// returns tags that aren't a known map or object unchanged, for the usual
+// type checks to report.
+func merge(v cty.Value, defaults map[string]string) cty.Value {
+	v, marks := v.Unmark()
+	if !v.IsKnown() {
+		return v.WithMarks(marks)
+	}
+	ty := v.Type()
+	if !v.IsNull() && !ty.IsMapType() && !ty.IsObjectType() {
+		return v.WithMarks(marks)
+	}
+
+	vals := make(map[string]cty.Value, len(defaults))
+	for k, tag := range defaults {
+		vals[k] = cty.StringVal(tag)
+	}
+	if !v.IsNull() {
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			vals[k.AsString()] = ev
+		}
+	}
+	return cty.ObjectVal(vals).WithMarks(marks)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package defaulttags
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/initwd"
+)
+
+func TestApply(t *testing.T) {
+	config, _ := initwd.MustLoadConfigForTests(t, "testdata/config", "tests")
+	Apply(config, map[addrs.Provider]*Defaults{
+		addrs.NewDefaultProvider("test"): {
+			Tags: map[string]string{
+				"environment": "prod",
+				"managed-by":  "farseek",
+			},
+		},
+	})
+
+	tagged := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"tags": {Type: cty.Map(cty.String), Optional: true},
+		},
+	}
+	untagged := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"name": {Type: cty.String, Optional: true},
+		},
+	}
+
+	tests := map[string]struct {
+		schema *configschema.Block
+		want   cty.Value
+	}{
+		"test_instance.tagged": {
+			tagged,
+			cty.ObjectVal(map[string]cty.Value{
+				"tags": cty.MapVal(map[string]cty.Value{
+					"environment": cty.StringVal("dev"),
+					"managed-by":  cty.StringVal("farseek"),
+					"owner":       cty.StringVal("platform"),
+				}),
+			}),
+		},
+		"test_instance.untagged": {
+			tagged,
+			cty.ObjectVal(map[string]cty.Value{
+				"tags": cty.MapVal(map[string]cty.Value{
+					"environment": cty.StringVal("prod"),
+					"managed-by":  cty.StringVal("farseek"),
+				}),
+			}),
+		},
+		"other_instance.untagged": {
+			tagged,
+			cty.ObjectVal(map[string]cty.Value{
+				"tags": cty.NullVal(cty.Map(cty.String)),
+			}),
+		},
+	}
+	for addr, test := range tests {
+		t.Run(addr, func(t *testing.T) {
+			rc := config.Module.ResourceByAddr(mustResource(t, addr))
+			got, diags := hcldec.Decode(rc.Config, test.schema.DecoderSpec(), nil)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+
+	t.Run("without the attribute", func(t *testing.T) {
+		rc := config.Module.ResourceByAddr(mustResource(t, "test_instance.untagged"))
+		got, diags := hcldec.Decode(rc.Config, untagged.DecoderSpec(), nil)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		want := cty.ObjectVal(map[string]cty.Value{
+			"name": cty.NullVal(cty.String),
+		})
+		if !got.RawEquals(want) {
+			t.Errorf("wrong value\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+}
+
+func mustResource(t *testing.T, addr string) addrs.Resource {
+	t.Helper()
+	ref, diags := addrs.ParseRefStr(addr)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	return ref.Subject.(addrs.Resource)
+}
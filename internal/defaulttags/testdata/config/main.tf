@@ -0,0 +1,12 @@
+resource "test_instance" "tagged" {
+  tags = {
+    owner       = "platform"
+    environment = "dev"
+  }
+}
+
+resource "test_instance" "untagged" {
+}
+
+resource "other_instance" "untagged" {
+}
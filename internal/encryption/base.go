@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/encryption/config"
@@ -33,6 +34,14 @@ type baseEncryption struct {
 	encMethod  method.Method
 	encMeta    keyProviderMetadata
 	staticEval *configs.StaticEvaluator
+
+	// decMethods caches the methods set up to decrypt, by the index of their
+	// configuration and the key provider metadata they were set up with, so
+	// that decrypting the same data again doesn't call the key providers
+	// again. Remote key providers such as KMS and Vault would otherwise be
+	// called every time the state is read.
+	decMethods     map[string]method.Method
+	decMethodsLock sync.Mutex
 }
 
 type keyProviderMetamap map[keyprovider.MetaStorageKey][]byte
@@ -98,6 +107,7 @@ func newBaseEncryption(ctx context.Context, enc *encryption, target *config.Targ
 		methods:    methods,
 		encMethod:  encMethod,
 		encMeta:    encMeta,
+		decMethods: make(map[string]method.Method),
 	}
 
 	return base, diags
@@ -209,11 +219,7 @@ func (base *baseEncryption) decrypt(ctx context.Context, data []byte, validator
 			continue
 		}
 
-		// TODO Discuss if we should potentially cache this based on a json-encoded version of inputData.Meta and reduce overhead dramatically
-		decMethod, diags := setupMethod(ctx, base.enc.cfg, method, keyProviderMetadata{
-			input:  inputData.Meta,
-			output: outputData.Meta,
-		}, base.enc.reg, base.staticEval)
+		decMethod, diags := base.decryptionMethod(ctx, i, method, inputData.Meta, outputData.Meta)
 		if diags.HasErrors() {
 			// This cast to error here is safe as we know that at least one error exists
 			return nil, StatusUnknown, diags
@@ -237,3 +243,30 @@ func (base *baseEncryption) decrypt(ctx context.Context, data []byte, validator
 
 	return nil, StatusUnknown, errors.New(errors.Join(errs...).Error())
 }
+
+// decryptionMethod sets up the method at the given index of base.methods to
+// decrypt data encrypted with the given key provider metadata, or returns the
+// one it set up before for the same metadata.
+func (base *baseEncryption) decryptionMethod(ctx context.Context, i int, cfg config.MethodConfig, input, output keyProviderMetamap) (method.Method, hcl.Diagnostics) {
+	// json.Marshal sorts the keys of maps, so the same metadata always
+	// results in the same cache key.
+	meta, err := json.Marshal(input)
+	if err != nil {
+		// Can't happen for a map of byte slices, but setting the method up
+		// again is always correct.
+		return setupMethod(ctx, base.enc.cfg, cfg, keyProviderMetadata{input: input, output: output}, base.enc.reg, base.staticEval)
+	}
+	key := fmt.Sprintf("%d:%s", i, meta)
+
+	base.decMethodsLock.Lock()
+	defer base.decMethodsLock.Unlock()
+
+	if m, ok := base.decMethods[key]; ok {
+		return m, nil
+	}
+	m, diags := setupMethod(ctx, base.enc.cfg, cfg, keyProviderMetadata{input: input, output: output}, base.enc.reg, base.staticEval)
+	if !diags.HasErrors() {
+		base.decMethods[key] = m
+	}
+	return m, diags
+}
@@ -0,0 +1,96 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package encryption
+
+import (
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/encryption/config"
+	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider"
+	"github.com/rafagsiqueira/farseek/internal/encryption/method/aesgcm"
+	"github.com/rafagsiqueira/farseek/internal/encryption/registry/lockingencryptionregistry"
+)
+
+func TestDecryptCachesKeys(t *testing.T) {
+	provider := &countingKeyProvider{}
+	reg := lockingencryptionregistry.New()
+	if err := reg.RegisterKeyProvider(countingDescriptor{provider}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterMethod(aesgcm.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, diags := config.LoadConfigFromString("test", `key_provider "counting" "test" {}
+		method "aes_gcm" "test" {
+			keys = key_provider.counting.test
+		}
+		state {
+			method = method.aes_gcm.test
+		}`)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	staticEval := configs.NewStaticEvaluator(nil, configs.RootModuleCallForTesting())
+	enc, diags := New(t.Context(), reg, cfg, staticEval)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	sfe := enc.State()
+
+	encrypted, err := sfe.EncryptState([]byte(`{"serial": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := provider.calls
+	for range 3 {
+		if _, _, err := sfe.DecryptState(encrypted); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := provider.calls - calls; got != 1 {
+		t.Errorf("the key provider was called %d times to decrypt the same state; want 1", got)
+	}
+}
+
+type countingDescriptor struct {
+	provider *countingKeyProvider
+}
+
+func (d countingDescriptor) ID() keyprovider.ID {
+	return "counting"
+}
+
+func (d countingDescriptor) ConfigStruct() keyprovider.Config {
+	return &countingConfig{provider: d.provider}
+}
+
+type countingConfig struct {
+	provider *countingKeyProvider
+}
+
+func (c countingConfig) Build() (keyprovider.KeyProvider, keyprovider.KeyMeta, error) {
+	return c.provider, new(countingMeta), nil
+}
+
+type countingMeta struct {
+	Present bool `json:"present"`
+}
+
+// countingKeyProvider provides a fixed key, and counts how many times it was
+// asked to.
+type countingKeyProvider struct {
+	calls int
+}
+
+func (p *countingKeyProvider) Provide(meta keyprovider.KeyMeta) (keyprovider.Output, keyprovider.KeyMeta, error) {
+	p.calls++
+	key := []byte("0123456789abcdef0123456789abcdef")
+	out := keyprovider.Output{EncryptionKey: key}
+	if meta.(*countingMeta).Present {
+		out.DecryptionKey = key
+	}
+	return out, &countingMeta{Present: true}, nil
+}
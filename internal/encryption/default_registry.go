@@ -8,6 +8,7 @@
 package encryption
 
 import (
+	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider/age"
 	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider/aws_kms"
 	externalKeyProvider "github.com/rafagsiqueira/farseek/internal/encryption/keyprovider/external"
 	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider/gcp_kms"
@@ -34,6 +35,12 @@ func init() {
 	if err := DefaultRegistry.RegisterKeyProvider(openbao.New()); err != nil {
 		panic(err)
 	}
+	if err := DefaultRegistry.RegisterKeyProvider(openbao.NewVault()); err != nil {
+		panic(err)
+	}
+	if err := DefaultRegistry.RegisterKeyProvider(age.New()); err != nil {
+		panic(err)
+	}
 	if err := DefaultRegistry.RegisterKeyProvider(externalKeyProvider.New()); err != nil {
 		panic(err)
 	}
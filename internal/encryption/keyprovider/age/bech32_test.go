@@ -0,0 +1,76 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package age
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// bech32Encode is the reverse of bech32Decode, to generate keys in tests.
+func bech32Encode(hrp string, data []byte) string {
+	values, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		panic(err)
+	}
+	lower := strings.ToLower(hrp)
+	chk := bech32Polymod(append(append(bech32HRPExpand(lower), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := 0; i < 6; i++ {
+		values = append(values, byte(chk>>(5*(5-i)))&31)
+	}
+	var sb strings.Builder
+	sb.WriteString(lower)
+	sb.WriteByte('1')
+	for _, v := range values {
+		sb.WriteByte(bech32Charset[v])
+	}
+	if strings.ToUpper(hrp) == hrp {
+		return strings.ToUpper(sb.String())
+	}
+	return sb.String()
+}
+
+func TestBech32Decode(t *testing.T) {
+	// Test vectors from BIP 173, and the recipient of the age README.
+	valid := map[string]string{
+		"A12UEL5L": "a",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw":                  "abcdef",
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p": "age",
+	}
+	for s, wantHRP := range valid {
+		hrp, data, err := bech32Decode(s)
+		if err != nil {
+			t.Errorf("%s: %s", s, err)
+			continue
+		}
+		if hrp != wantHRP {
+			t.Errorf("%s: wrong human-readable part %q", s, hrp)
+		}
+		if got := bech32Encode(hrp, data); got != strings.ToLower(s) {
+			t.Errorf("%s: encodes back to %s", s, got)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"pzry9x0s0muk",
+		"1pzry9x0s0muk",
+		"x1b4n0q5v",
+		"li1dgmt3",
+		"A1G7SGD8",
+		"A12uEL5L",
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q",
+	}
+	for _, s := range invalid {
+		if _, _, err := bech32Decode(s); err == nil {
+			t.Errorf("%q: decoded", s)
+		}
+	}
+
+	data := []byte{0, 1, 2, 253, 254, 255}
+	if _, got, err := bech32Decode(bech32Encode("AGE-SECRET-KEY-", data)); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("wrong round trip: %v, %v", got, err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package age
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider/compliancetest"
+)
+
+// generateIdentity returns a new age identity and its recipient.
+func generateIdentity(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bech32Encode("AGE-SECRET-KEY-", key.Bytes()), bech32Encode(recipientHRP, key.PublicKey().Bytes())
+}
+
+func TestKeyProvider(t *testing.T) {
+	identity, recipient := generateIdentity(t)
+	_, otherRecipient := generateIdentity(t)
+
+	compliancetest.ComplianceTest(
+		t,
+		compliancetest.TestConfiguration[*descriptor, *Config, *keyMeta, *keyProvider]{
+			Descriptor: New().(*descriptor),
+			HCLParseTestCases: map[string]compliancetest.HCLParseTestCase[*Config, *keyProvider]{
+				"success": {
+					HCL: fmt.Sprintf(`key_provider "age" "foo" {
+							identities = ["%s"]
+						}`, identity),
+					ValidHCL:   true,
+					ValidBuild: true,
+					Validate: func(_ *Config, p *keyProvider) error {
+						if len(p.identities) != 1 || len(p.recipients) != 1 {
+							return fmt.Errorf("wrong identities and recipients: %d, %d", len(p.identities), len(p.recipients))
+						}
+						return nil
+					},
+				},
+				"recipients": {
+					HCL: fmt.Sprintf(`key_provider "age" "foo" {
+							identities = ["%s"]
+							recipients = ["%s", "%s"]
+						}`, identity, recipient, otherRecipient),
+					ValidHCL:   true,
+					ValidBuild: true,
+					Validate: func(_ *Config, p *keyProvider) error {
+						// The recipient of the identity is only added once.
+						if len(p.recipients) != 2 {
+							return fmt.Errorf("wrong number of recipients: %d", len(p.recipients))
+						}
+						return nil
+					},
+				},
+				"empty": {
+					HCL:        `key_provider "age" "foo" {}`,
+					ValidHCL:   true,
+					ValidBuild: false,
+				},
+				"invalid-identity": {
+					HCL: `key_provider "age" "foo" {
+							identities = ["AGE-SECRET-KEY-1QQQQ"]
+						}`,
+					ValidHCL:   true,
+					ValidBuild: false,
+				},
+				"recipient-as-identity": {
+					HCL: fmt.Sprintf(`key_provider "age" "foo" {
+							identities = ["%s"]
+						}`, recipient),
+					ValidHCL:   true,
+					ValidBuild: false,
+				},
+				"invalid-key-length": {
+					HCL: fmt.Sprintf(`key_provider "age" "foo" {
+							identities = ["%s"]
+							key_length = 8
+						}`, identity),
+					ValidHCL:   true,
+					ValidBuild: false,
+				},
+				"unknown-property": {
+					HCL: fmt.Sprintf(`key_provider "age" "foo" {
+							identities = ["%s"]
+							unknown_property = "foo"
+						}`, identity),
+					ValidHCL:   false,
+					ValidBuild: false,
+				},
+			},
+			ConfigStructTestCases: map[string]compliancetest.ConfigStructTestCase[*Config, *keyProvider]{
+				"success": {
+					Config: &Config{
+						Identities: []string{identity},
+						KeyLength:  16,
+					},
+					ValidBuild: true,
+					Validate: func(p *keyProvider) error {
+						if p.keyLength != 16 {
+							return fmt.Errorf("invalid key length: %v", p.keyLength)
+						}
+						return nil
+					},
+				},
+				"success-default-values": {
+					Config: &Config{
+						Recipients: []string{recipient},
+					},
+					ValidBuild: true,
+					Validate: func(p *keyProvider) error {
+						if p.keyLength != 32 {
+							return fmt.Errorf("invalid default key length: %v", p.keyLength)
+						}
+						return nil
+					},
+				},
+				"empty": {
+					Config:     &Config{},
+					ValidBuild: false,
+				},
+			},
+			MetadataStructTestCases: map[string]compliancetest.MetadataStructTestCase[*Config, *keyMeta]{
+				"empty": {
+					ValidConfig: &Config{
+						Identities: []string{identity},
+					},
+					Meta:      &keyMeta{},
+					IsPresent: false,
+				},
+				"invalid-share": {
+					ValidConfig: &Config{
+						Identities: []string{identity},
+					},
+					Meta: &keyMeta{
+						Stanzas: []stanza{{Share: []byte("foo"), Body: []byte("bar")}},
+					},
+					IsPresent: true,
+					IsValid:   false,
+				},
+			},
+			ProvideTestCase: compliancetest.ProvideTestCase[*Config, *keyMeta]{
+				ValidConfig: &Config{
+					Identities: []string{identity},
+					Recipients: []string{otherRecipient},
+				},
+				ValidateMetadata: func(meta *keyMeta) error {
+					if len(meta.Stanzas) != 2 {
+						return fmt.Errorf("wrong number of stanzas: %d", len(meta.Stanzas))
+					}
+					return nil
+				},
+			},
+		},
+	)
+}
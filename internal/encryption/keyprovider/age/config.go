@@ -0,0 +1,110 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package age
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"fmt"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider"
+)
+
+const (
+	recipientHRP = "age"
+	identityHRP  = "age-secret-key-"
+
+	defaultKeyLength = 32
+	minKeyLength     = 16
+	maxKeyLength     = 64
+)
+
+// Config contains the configuration for this key provider supplied by the user. This struct must have hcl tags in order
+// to function.
+type Config struct {
+	// Recipients are the age public keys (age1...) to encrypt the key to.
+	Recipients []string `hcl:"recipients,optional"`
+	// Identities are the age private keys (AGE-SECRET-KEY-1...) to decrypt the key with. The key is also encrypted
+	// to their public keys.
+	Identities []string `hcl:"identities,optional"`
+
+	KeyLength int `hcl:"key_length,optional"`
+}
+
+// Build will create the usable key provider.
+func (c Config) Build() (keyprovider.KeyProvider, keyprovider.KeyMeta, error) {
+	if len(c.Recipients) == 0 && len(c.Identities) == 0 {
+		return nil, nil, &keyprovider.ErrInvalidConfiguration{
+			Message: "no recipients or identities found",
+		}
+	}
+
+	if c.KeyLength == 0 {
+		c.KeyLength = defaultKeyLength
+	}
+	if c.KeyLength < minKeyLength || c.KeyLength > maxKeyLength {
+		return nil, nil, &keyprovider.ErrInvalidConfiguration{
+			Message: fmt.Sprintf("key length should be between %d and %d bytes: got %d", minKeyLength, maxKeyLength, c.KeyLength),
+		}
+	}
+
+	p := &keyProvider{
+		keyLength: c.KeyLength,
+	}
+	for i, s := range c.Identities {
+		identity, err := parseIdentity(s)
+		if err != nil {
+			// The identity is a secret, so it mustn't appear in the error.
+			return nil, nil, &keyprovider.ErrInvalidConfiguration{
+				Message: fmt.Sprintf("invalid age identity at index %d", i),
+				Cause:   err,
+			}
+		}
+		p.identities = append(p.identities, identity)
+		p.addRecipient(identity.PublicKey())
+	}
+	for _, s := range c.Recipients {
+		recipient, err := parseRecipient(s)
+		if err != nil {
+			return nil, nil, &keyprovider.ErrInvalidConfiguration{
+				Message: fmt.Sprintf("invalid age recipient %q", s),
+				Cause:   err,
+			}
+		}
+		p.addRecipient(recipient)
+	}
+
+	return p, new(keyMeta), nil
+}
+
+func parseRecipient(s string) (*ecdh.PublicKey, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != recipientHRP {
+		return nil, fmt.Errorf("not an age X25519 recipient, which starts with %q", recipientHRP+"1")
+	}
+	return ecdh.X25519().NewPublicKey(data)
+}
+
+func parseIdentity(s string) (*ecdh.PrivateKey, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != identityHRP {
+		return nil, fmt.Errorf("not an age X25519 identity, which starts with \"AGE-SECRET-KEY-1\"")
+	}
+	return ecdh.X25519().NewPrivateKey(data)
+}
+
+func (p *keyProvider) addRecipient(recipient *ecdh.PublicKey) {
+	for _, r := range p.recipients {
+		if bytes.Equal(r.Bytes(), recipient.Bytes()) {
+			return
+		}
+	}
+	p.recipients = append(p.recipients, recipient)
+}
@@ -0,0 +1,159 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package age
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider"
+)
+
+// x25519Label is the HKDF info of age X25519 recipient stanzas. The key is
+// wrapped the same way age wraps its file keys, so the usual age keys and
+// tooling can be used to manage the identities.
+const x25519Label = "age-encryption.org/v1/X25519"
+
+// keyMeta holds the key, wrapped for each of the recipients.
+type keyMeta struct {
+	Stanzas []stanza `json:"stanzas"`
+}
+
+// stanza is the key wrapped for one recipient: Share is the ephemeral
+// X25519 public key, and Body the key encrypted with ChaCha20-Poly1305.
+type stanza struct {
+	Share []byte `json:"share"`
+	Body  []byte `json:"body"`
+}
+
+func (m keyMeta) isPresent() bool {
+	return len(m.Stanzas) != 0
+}
+
+type keyProvider struct {
+	recipients []*ecdh.PublicKey
+	identities []*ecdh.PrivateKey
+	keyLength  int
+}
+
+func (p keyProvider) Provide(rawMeta keyprovider.KeyMeta) (keyprovider.Output, keyprovider.KeyMeta, error) {
+	if rawMeta == nil {
+		return keyprovider.Output{}, nil, &keyprovider.ErrInvalidMetadata{
+			Message: "bug: no metadata struct provided",
+		}
+	}
+	inMeta, ok := rawMeta.(*keyMeta)
+	if !ok {
+		return keyprovider.Output{}, nil, &keyprovider.ErrInvalidMetadata{
+			Message: fmt.Sprintf("bug: invalid metadata struct type: %T", rawMeta),
+		}
+	}
+
+	// A new key is generated and wrapped for the recipients every time, so
+	// that replacing the recipients takes effect the next time the data is
+	// written.
+	encryptionKey := make([]byte, p.keyLength)
+	if _, err := rand.Read(encryptionKey); err != nil {
+		return keyprovider.Output{}, nil, &keyprovider.ErrKeyProviderFailure{
+			Message: "failed to generate key",
+			Cause:   err,
+		}
+	}
+	outMeta := &keyMeta{}
+	for _, recipient := range p.recipients {
+		s, err := wrap(recipient, encryptionKey)
+		if err != nil {
+			return keyprovider.Output{}, nil, &keyprovider.ErrKeyProviderFailure{
+				Message: "failed to wrap key for age recipient",
+				Cause:   err,
+			}
+		}
+		outMeta.Stanzas = append(outMeta.Stanzas, s)
+	}
+
+	out := keyprovider.Output{
+		EncryptionKey: encryptionKey,
+	}
+	if inMeta.isPresent() {
+		var err error
+		out.DecryptionKey, err = p.unwrap(inMeta.Stanzas)
+		if err != nil {
+			return keyprovider.Output{}, nil, err
+		}
+	}
+
+	return out, outMeta, nil
+}
+
+func wrap(recipient *ecdh.PublicKey, key []byte) (stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return stanza{}, err
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return stanza{}, err
+	}
+	share := ephemeral.PublicKey().Bytes()
+	aead, err := wrappingAEAD(shared, share, recipient.Bytes())
+	if err != nil {
+		return stanza{}, err
+	}
+	return stanza{
+		Share: share,
+		Body:  aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), key, nil),
+	}, nil
+}
+
+func (p keyProvider) unwrap(stanzas []stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		share, err := ecdh.X25519().NewPublicKey(s.Share)
+		if err != nil {
+			return nil, &keyprovider.ErrInvalidMetadata{
+				Message: "invalid age stanza share",
+				Cause:   err,
+			}
+		}
+		for _, identity := range p.identities {
+			shared, err := identity.ECDH(share)
+			if err != nil {
+				return nil, &keyprovider.ErrInvalidMetadata{
+					Message: "invalid age stanza share",
+					Cause:   err,
+				}
+			}
+			aead, err := wrappingAEAD(shared, s.Share, identity.PublicKey().Bytes())
+			if err != nil {
+				return nil, &keyprovider.ErrKeyProviderFailure{
+					Message: "failed to derive age wrapping key",
+					Cause:   err,
+				}
+			}
+			// The stanza is for another recipient if it doesn't open.
+			if key, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), s.Body, nil); err == nil {
+				return key, nil
+			}
+		}
+	}
+	return nil, &keyprovider.ErrKeyProviderFailure{
+		Message: "none of the configured age identities can decrypt the key (check that the identity of a recipient the data was last written for is configured)",
+	}
+}
+
+func wrappingAEAD(shared, share, recipient []byte) (cipher.AEAD, error) {
+	salt := make([]byte, 0, len(share)+len(recipient))
+	salt = append(salt, share...)
+	salt = append(salt, recipient...)
+	wrappingKey, err := hkdf.Key(sha256.New, shared, salt, x25519Label, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(wrappingKey)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package age
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider"
+)
+
+// TestRotation rotates from one identity to another: data written for the
+// old identity is read with it, and written again for the new one only.
+func TestRotation(t *testing.T) {
+	oldIdentity, _ := generateIdentity(t)
+	newIdentity, _ := generateIdentity(t)
+
+	provide := func(cfg Config, meta keyprovider.KeyMeta) (keyprovider.Output, keyprovider.KeyMeta, error) {
+		t.Helper()
+		p, emptyMeta, err := cfg.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta == nil {
+			meta = emptyMeta
+		}
+		return p.Provide(meta)
+	}
+
+	written, oldMeta, err := provide(Config{Identities: []string{oldIdentity}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := Config{Identities: []string{newIdentity, oldIdentity}}
+	out, rewrapped, err := provide(rotated, oldMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.DecryptionKey, written.EncryptionKey) {
+		t.Fatal("the old key wasn't decrypted")
+	}
+
+	// Once written again, the old identity is no longer needed.
+	out2, _, err := provide(Config{Identities: []string{newIdentity}}, rewrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out2.DecryptionKey, out.EncryptionKey) {
+		t.Fatal("the rewrapped key wasn't decrypted")
+	}
+
+	// The old data can't be read without the old identity.
+	_, _, err = provide(Config{Identities: []string{newIdentity}}, oldMeta)
+	var failure *keyprovider.ErrKeyProviderFailure
+	if !errors.As(err, &failure) {
+		t.Fatalf("wrong error %v", err)
+	}
+}
@@ -40,7 +40,7 @@ func (s service) generateDataKey(ctx context.Context, keyName string, bitSize in
 		"bits": bitSize,
 	})
 	if err != nil {
-		return dataKey{}, fmt.Errorf("error sending datakey request to OpenBao: %w", err)
+		return dataKey{}, fmt.Errorf("error sending datakey request to the transit engine: %w", err)
 	}
 
 	key := dataKey{}
@@ -65,7 +65,7 @@ func (s service) decryptData(ctx context.Context, keyName string, ciphertext []b
 		"ciphertext": string(ciphertext),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("error sending decryption request to OpenBao: %w", err)
+		return nil, fmt.Errorf("error sending decryption request to the transit engine: %w", err)
 	}
 
 	return retrievePlaintext(secret)
@@ -74,12 +74,12 @@ func (s service) decryptData(ctx context.Context, keyName string, ciphertext []b
 func retrievePlaintext(s *openbao.Secret) ([]byte, error) {
 	base64Plaintext, ok := s.Data["plaintext"].(string)
 	if !ok {
-		return nil, errors.New("failed to deserialize 'plaintext' (it's either Farseek bug or incompatible OpenBao version)")
+		return nil, errors.New("failed to deserialize 'plaintext' (it's either Farseek bug or incompatible server version)")
 	}
 
 	plaintext, err := base64.StdEncoding.DecodeString(base64Plaintext)
 	if err != nil {
-		return nil, fmt.Errorf("base64 decoding 'plaintext' (it's either Farseek bug or incompatible OpenBao version): %w", err)
+		return nil, fmt.Errorf("base64 decoding 'plaintext' (it's either Farseek bug or incompatible server version): %w", err)
 	}
 
 	return plaintext, nil
@@ -88,7 +88,7 @@ func retrievePlaintext(s *openbao.Secret) ([]byte, error) {
 func retrieveCiphertext(s *openbao.Secret) ([]byte, error) {
 	ciphertext, ok := s.Data["ciphertext"].(string)
 	if !ok {
-		return nil, errors.New("failed to deserialize 'ciphertext' (it's either Farseek bug or incompatible OpenBao version)")
+		return nil, errors.New("failed to deserialize 'ciphertext' (it's either Farseek bug or incompatible server version)")
 	}
 
 	return []byte(ciphertext), nil
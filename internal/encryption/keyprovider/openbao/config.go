@@ -21,6 +21,9 @@ type Config struct {
 	KeyName           string        `hcl:"key_name"`
 	KeyLength         DataKeyLength `hcl:"key_length,optional"`
 	TransitEnginePath string        `hcl:"transit_engine_path,optional"`
+
+	// product is the name of the server, for error messages.
+	product string
 }
 
 const (
@@ -49,7 +52,12 @@ func (c Config) Build() (keyprovider.KeyProvider, keyprovider.KeyMeta, error) {
 		c.TransitEnginePath = defaultTransitEnginePath
 	}
 
-	// DefaultConfig reads BAO_ADDR and some other optional env variables.
+	if c.product == "" {
+		c.product = "OpenBao"
+	}
+
+	// DefaultConfig reads BAO_ADDR and some other optional env variables,
+	// falling back to their VAULT_ counterparts.
 	config := openbao.DefaultConfig()
 	if config.Error != nil {
 		return nil, nil, &keyprovider.ErrInvalidConfiguration{
@@ -76,6 +84,7 @@ func (c Config) Build() (keyprovider.KeyProvider, keyprovider.KeyMeta, error) {
 		},
 		keyName:   c.KeyName,
 		keyLength: c.KeyLength,
+		product:   c.product,
 	}, new(keyMeta), nil
 }
 
@@ -102,10 +111,11 @@ type clientConstructor func(config *openbao.Config, token string) (client, error
 var newClient clientConstructor = newOpenBaoClient
 
 func newOpenBaoClient(config *openbao.Config, token string) (client, error) {
-	// NewClient reads BAO_TOKEN and some other optional env variables.
+	// NewClient reads BAO_TOKEN and some other optional env variables,
+	// falling back to their VAULT_ counterparts.
 	c, err := openbao.NewClient(config)
 	if err != nil {
-		return nil, fmt.Errorf("error creating OpenBao client: %w", err)
+		return nil, fmt.Errorf("error creating client: %w", err)
 	}
 
 	// Token from HCL supersedes BAO_TOKEN.
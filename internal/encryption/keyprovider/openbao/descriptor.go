@@ -10,16 +10,24 @@ package openbao
 import "github.com/rafagsiqueira/farseek/internal/encryption/keyprovider"
 
 func New() keyprovider.Descriptor {
-	return &descriptor{}
+	return &descriptor{id: "openbao", product: "OpenBao"}
+}
+
+// NewVault returns the descriptor of the key provider for the Transit Secret
+// Engine of HashiCorp Vault, which has the same API as the one of OpenBao.
+func NewVault() keyprovider.Descriptor {
+	return &descriptor{id: "vault", product: "Vault"}
 }
 
 type descriptor struct {
+	id      keyprovider.ID
+	product string
 }
 
 func (f descriptor) ID() keyprovider.ID {
-	return "openbao"
+	return f.id
 }
 
 func (f descriptor) ConfigStruct() keyprovider.Config {
-	return &Config{}
+	return &Config{product: f.product}
 }
@@ -9,6 +9,7 @@ package openbao
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/rafagsiqueira/farseek/internal/encryption/keyprovider"
 )
@@ -25,6 +26,7 @@ type keyProvider struct {
 	svc       service
 	keyName   string
 	keyLength DataKeyLength
+	product   string
 }
 
 func (p keyProvider) Provide(rawMeta keyprovider.KeyMeta) (keyprovider.Output, keyprovider.KeyMeta, error) {
@@ -46,7 +48,7 @@ func (p keyProvider) Provide(rawMeta keyprovider.KeyMeta) (keyprovider.Output, k
 	dataKey, err := p.svc.generateDataKey(ctx, p.keyName, p.keyLength.Bits())
 	if err != nil {
 		return keyprovider.Output{}, nil, &keyprovider.ErrKeyProviderFailure{
-			Message: "failed to generate OpenBao data key (check if the configuration valid and OpenBao server accessible)",
+			Message: fmt.Sprintf("failed to generate %[1]s data key (check if the configuration valid and %[1]s server accessible)", p.product),
 			Cause:   err,
 		}
 	}
@@ -63,7 +65,7 @@ func (p keyProvider) Provide(rawMeta keyprovider.KeyMeta) (keyprovider.Output, k
 		out.DecryptionKey, err = p.svc.decryptData(ctx, p.keyName, inMeta.Ciphertext)
 		if err != nil {
 			return keyprovider.Output{}, nil, &keyprovider.ErrKeyProviderFailure{
-				Message: "failed to decrypt ciphertext (check if the configuration valid and OpenBao server accessible)",
+				Message: fmt.Sprintf("failed to decrypt ciphertext (check if the configuration valid and %s server accessible)", p.product),
 				Cause:   err,
 			}
 		}
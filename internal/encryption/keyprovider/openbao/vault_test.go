@@ -0,0 +1,58 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package openbao
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openbao "github.com/openbao/openbao/api/v2"
+)
+
+func TestVaultKeyProvider(t *testing.T) {
+	t.Cleanup(injectDefaultClient)
+
+	descriptor := NewVault()
+	if got := descriptor.ID(); got != "vault" {
+		t.Fatalf("wrong ID %q", got)
+	}
+	cfg := descriptor.ConfigStruct().(*Config)
+	cfg.KeyName = defaultTestKeyName
+
+	injectMock(prepareClientMockForKeyProviderTest(t, defaultTestKeyName))
+	provider, meta, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, meta, err := provider.Provide(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := provider.Provide(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.EncryptionKey, second.DecryptionKey) {
+		t.Errorf("the decryption key doesn't match the encryption key")
+	}
+
+	// Errors name Vault rather than OpenBao.
+	injectMock(func(context.Context, string, map[string]interface{}) (*openbao.Secret, error) {
+		return nil, errors.New("permission denied")
+	})
+	provider, meta, err = cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = provider.Provide(meta)
+	if err == nil {
+		t.Fatal("succeeded without access to the server")
+	}
+	if got := err.Error(); !strings.Contains(got, "Vault server") {
+		t.Errorf("error doesn't name Vault: %s", got)
+	}
+}
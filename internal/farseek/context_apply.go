@@ -73,6 +73,15 @@ func (c *Context) Apply(ctx context.Context, plan *plans.Plan, config *configs.C
 		return nil, diags
 	}
 
+	for _, h := range c.hooks {
+		if h, ok := h.(AnnotationsHook); ok {
+			h.SetAnnotations(config.ResourceAnnotations)
+		}
+		if h, ok := h.(ReplaceReasonsHook); ok {
+			h.SetReplaceReasons(plan.ForceReplaceReasons)
+		}
+	}
+
 	var forgetCount int
 
 	for _, rc := range plan.Changes.Resources {
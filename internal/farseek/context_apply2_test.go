@@ -9,7 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,6 +23,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/checks"
@@ -6948,3 +6951,386 @@ func TestContext2Apply_ephemeralInModuleWithExpansion(t *testing.T) {
 
 	}
 }
+
+func TestContext2Apply_providerConfigDeferred(t *testing.T) {
+	// The configuration of the "test" provider depends on a resource that
+	// doesn't exist yet, so it can't be configured until apply, and neither
+	// can anything that uses it be planned or read until then.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "creds_token" "main" {
+}
+
+provider "test" {
+  token = creds_token.main.id
+}
+
+resource "test_resource" "a" {
+  value = "a"
+}
+
+data "test_data_source" "b" {
+  value = "b"
+}
+`,
+	})
+
+	creds := new(MockProvider)
+	creds.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"creds_token": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Computed: true},
+				},
+			},
+		},
+	})
+	creds.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		resp.NewState = cty.ObjectVal(map[string]cty.Value{
+			"id": cty.StringVal("token-123"),
+		})
+		return resp
+	}
+
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		Provider: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"token": {Type: cty.String, Required: true},
+			},
+		},
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"id":    {Type: cty.String, Computed: true},
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+		DataSources: map[string]*configschema.Block{
+			"test_data_source": {
+				Attributes: map[string]*configschema.Attribute{
+					"id":    {Type: cty.String, Computed: true},
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+	p.ConfigureProviderFn = func(req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+		if got, want := req.Config.GetAttr("token"), cty.StringVal("token-123"); !got.RawEquals(want) {
+			resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("wrong token %#v", got))
+		}
+		return resp
+	}
+	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+		resp.State = cty.ObjectVal(map[string]cty.Value{
+			"id":    cty.StringVal("data"),
+			"value": req.Config.GetAttr("value"),
+		})
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("creds"): testProviderFuncFixed(creds),
+			addrs.NewDefaultProvider("test"):  testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	if p.ConfigureProviderCalled {
+		t.Fatalf("provider was configured during plan with %#v", p.ConfigureProviderRequest.Config)
+	}
+	for _, test := range []struct {
+		addr   string
+		action plans.Action
+	}{
+		{"test_resource.a", plans.Create},
+		{"data.test_data_source.b", plans.Read},
+	} {
+		change := plan.Changes.ResourceInstance(mustResourceInstanceAddr(test.addr))
+		if change == nil {
+			t.Fatalf("no change for %s", test.addr)
+		}
+		if change.Action != test.action {
+			t.Errorf("wrong action for %s: %s; want %s", test.addr, change.Action, test.action)
+		}
+	}
+
+	state, diags := ctx.Apply(context.Background(), plan, m, nil)
+	assertNoErrors(t, diags)
+
+	if !p.ConfigureProviderCalled {
+		t.Fatal("provider was not configured during apply")
+	}
+	for _, addr := range []string{"test_resource.a", "data.test_data_source.b"} {
+		if state.ResourceInstance(mustResourceInstanceAddr(addr)) == nil {
+			t.Errorf("%s is missing from the state", addr)
+		}
+	}
+}
+
+func TestContext2Apply_providerConfigDeferredExisting(t *testing.T) {
+	// The objects already exist, so while the "test" provider waits for its
+	// configuration, the one that matches its configuration is left alone
+	// and the others are updated, or replaced once the provider says so
+	// during apply.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "creds_token" "main" {
+}
+
+provider "test" {
+  token = creds_token.main.id
+}
+
+resource "test_resource" "same" {
+  value = "same"
+}
+
+resource "test_resource" "replaced" {
+  value = "new"
+}
+
+resource "test_resource" "replaced_cbd" {
+  value = "new"
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`,
+	})
+
+	creds := new(MockProvider)
+	creds.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"creds_token": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Computed: true},
+				},
+			},
+		},
+	})
+	creds.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		resp.NewState = cty.ObjectVal(map[string]cty.Value{
+			"id": cty.StringVal("token-123"),
+		})
+		return resp
+	}
+
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		Provider: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"token": {Type: cty.String, Required: true},
+			},
+		},
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"id":    {Type: cty.String, Computed: true},
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		resp.PlannedState = req.ProposedNewState
+		if req.PriorState.IsNull() || req.ProposedNewState.IsNull() {
+			if !req.ProposedNewState.IsNull() {
+				resp.PlannedState = cty.ObjectVal(map[string]cty.Value{
+					"id":    cty.UnknownVal(cty.String),
+					"value": req.ProposedNewState.GetAttr("value"),
+				})
+			}
+			return resp
+		}
+		if !req.PriorState.GetAttr("value").RawEquals(req.ProposedNewState.GetAttr("value")) {
+			resp.RequiresReplace = []cty.Path{cty.GetAttrPath("value")}
+			resp.PlannedState = cty.ObjectVal(map[string]cty.Value{
+				"id":    cty.UnknownVal(cty.String),
+				"value": req.ProposedNewState.GetAttr("value"),
+			})
+		}
+		return resp
+	}
+	var mu sync.Mutex
+	var destroyed []string
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		if req.PlannedState.IsNull() {
+			mu.Lock()
+			destroyed = append(destroyed, req.PriorState.GetAttr("id").AsString())
+			mu.Unlock()
+			resp.NewState = req.PlannedState
+			return resp
+		}
+		resp.NewState = cty.ObjectVal(map[string]cty.Value{
+			"id":    cty.StringVal("new-" + req.PlannedState.GetAttr("value").AsString()),
+			"value": req.PlannedState.GetAttr("value"),
+		})
+		return resp
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for name, attrs := range map[string]string{
+			"same":         `{"id":"old-same","value":"same"}`,
+			"replaced":     `{"id":"old-replaced","value":"old"}`,
+			"replaced_cbd": `{"id":"old-replaced_cbd","value":"old"}`,
+		} {
+			s.SetResourceInstanceCurrent(
+				mustResourceInstanceAddr("test_resource."+name),
+				&states.ResourceInstanceObjectSrc{
+					Status:    states.ObjectReady,
+					AttrsJSON: []byte(attrs),
+				},
+				mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+				addrs.NoKey,
+			)
+		}
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("creds"): testProviderFuncFixed(creds),
+			addrs.NewDefaultProvider("test"):  testProviderFuncFixed(unconfiguredUpgradeProvider{p}),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	if p.ConfigureProviderCalled {
+		t.Fatalf("provider was configured during plan with %#v", p.ConfigureProviderRequest.Config)
+	}
+	for _, test := range []struct {
+		addr   string
+		action plans.Action
+	}{
+		{"test_resource.same", plans.NoOp},
+		{"test_resource.replaced", plans.Update},
+		{"test_resource.replaced_cbd", plans.Update},
+	} {
+		change := plan.Changes.ResourceInstance(mustResourceInstanceAddr(test.addr))
+		if change == nil {
+			t.Fatalf("no change for %s", test.addr)
+		}
+		if change.Action != test.action {
+			t.Errorf("wrong action for %s: %s; want %s", test.addr, change.Action, test.action)
+		}
+		if !change.ProviderDeferred {
+			t.Errorf("change for %s wasn't marked as planned without its provider", test.addr)
+		}
+	}
+
+	state, diags = ctx.Apply(context.Background(), plan, m, nil)
+	assertNoErrors(t, diags)
+
+	sort.Strings(destroyed)
+	if got, want := destroyed, []string{"old-replaced", "old-replaced_cbd"}; !cmp.Equal(got, want) {
+		t.Errorf("wrong objects destroyed\n%s", cmp.Diff(want, got))
+	}
+	for name, want := range map[string]string{
+		"same":         "old-same",
+		"replaced":     "new-new",
+		"replaced_cbd": "new-new",
+	} {
+		rs := state.ResourceInstance(mustResourceInstanceAddr("test_resource." + name))
+		if rs == nil || rs.Current == nil {
+			t.Errorf("test_resource.%s is missing from the state", name)
+			continue
+		}
+		if len(rs.Deposed) != 0 {
+			t.Errorf("test_resource.%s still has deposed objects: %#v", name, rs.Deposed)
+		}
+		obj, err := rs.Current.Decode(cty.Object(map[string]cty.Type{"id": cty.String, "value": cty.String}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := obj.Value.GetAttr("id").AsString(); got != want {
+			t.Errorf("wrong id for test_resource.%s: %s; want %s", name, got, want)
+		}
+	}
+}
+
+// unconfiguredUpgradeProvider is a MockProvider that upgrades the state of
+// its resources before it's configured, as the plugin protocol allows, which
+// it must do for a plan to read existing objects while its configuration is
+// deferred.
+type unconfiguredUpgradeProvider struct {
+	*MockProvider
+}
+
+func (p unconfiguredUpgradeProvider) UpgradeResourceState(_ context.Context, req providers.UpgradeResourceStateRequest) (resp providers.UpgradeResourceStateResponse) {
+	schema := p.GetProviderSchemaResponse.ResourceTypes[req.TypeName]
+	v, err := ctyjson.Unmarshal(req.RawStateJSON, schema.Block.ImpliedType())
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(err)
+		return resp
+	}
+	resp.UpgradedState = v
+	return resp
+}
+
+func TestContext2Apply_annotations(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_instance" "a" {
+	value = "a"
+
+	farseek_annotations = {
+		team = "payments"
+	}
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.PlanResourceChangeFn = testDiffFn
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		if got := req.Config.GetAttr("value"); got != cty.StringVal("a") {
+			t.Errorf("wrong config sent to the provider: %#v", req.Config)
+		}
+		resp.NewState = req.PriorState
+		resp.Diagnostics = resp.Diagnostics.Append(errors.New("provider was sad"))
+		return resp
+	}
+
+	hook := &testAnnotationsHook{}
+	ctx := testContext2(t, &ContextOpts{
+		Hooks: []Hook{hook},
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	_, diags = ctx.Apply(context.Background(), plan, m, nil)
+	if !diags.HasErrors() {
+		t.Fatal("expected errors")
+	}
+	want := map[string]string{"team": "payments"}
+	if got := tfdiags.DiagnosticAnnotations(diags[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong annotations for the error %#v; want %#v", got, want)
+	}
+	if hook.annotations == nil {
+		t.Fatal("annotations weren't passed to the hook")
+	}
+	if got := hook.annotations(mustConfigResourceAddr("test_instance.a")); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong annotations for the hook %#v; want %#v", got, want)
+	}
+}
+
+type testAnnotationsHook struct {
+	NilHook
+
+	annotations func(addrs.ConfigResource) map[string]string
+}
+
+func (h *testAnnotationsHook) SetAnnotations(annotations func(addrs.ConfigResource) map[string]string) {
+	h.annotations = annotations
+}
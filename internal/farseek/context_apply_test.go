@@ -4983,6 +4983,65 @@ func TestContext2Apply_provisionerDestroy(t *testing.T) {
 	}
 }
 
+func TestContext2Apply_provisionerUpdate(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "aws_instance" "foo" {
+  foo = "baz"
+
+  provisioner "shell" {
+    command = "create ${self.foo}"
+  }
+
+  provisioner "shell" {
+    when    = update
+    command = "update ${self.foo}"
+  }
+}
+`,
+	})
+	p := testProvider("aws")
+	pr := testProvisioner()
+	p.PlanResourceChangeFn = testDiffFn
+	var commands []string
+	pr.ProvisionResourceFn = func(req provisioners.ProvisionResourceRequest) (resp provisioners.ProvisionResourceResponse) {
+		commands = append(commands, req.Config.GetAttr("command").AsString())
+		return
+	}
+
+	state := states.NewState()
+	root := state.RootModule()
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr(`aws_instance.foo`).Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"id":"bar","foo":"bar","type":"aws_instance"}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/aws"]`),
+		addrs.NoKey,
+	)
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+		},
+		Provisioners: map[string]provisioners.Factory{
+			"shell": testProvisionerFuncFixed(pr),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	_, diags = ctx.Apply(context.Background(), plan, m, nil)
+	assertNoErrors(t, diags)
+
+	// Only the update-time provisioner runs for an update.
+	if diff := cmp.Diff([]string{"update baz"}, commands); diff != "" {
+		t.Fatalf("wrong provisioner commands\n%s", diff)
+	}
+}
+
 // Verify that on destroy provisioner failure, nothing happens to the instance
 func TestContext2Apply_provisionerDestroyFail(t *testing.T) {
 	m := testModule(t, "apply-provisioner-destroy")
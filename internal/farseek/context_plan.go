@@ -46,6 +46,12 @@ type PlanOpts struct {
 	// instance using its corresponding provider.
 	SkipRefresh bool
 
+	// If RefreshProviders has a non-zero length then only the managed
+	// resource instances of these providers are refreshed, and the others
+	// are planned from their prior state, as if SkipRefresh were set for
+	// them. It has no effect if SkipRefresh is set.
+	RefreshProviders []addrs.Provider
+
 	// PreDestroyRefresh indicated that this is being passed to a plan used to
 	// refresh the state immediately before a destroy plan.
 	// FIXME: This is a temporary fix to allow the pre-destroy refresh to
@@ -114,6 +120,10 @@ type PlanOpts struct {
 
 	// FarseekMode indicates that we are running in Farseek mode (stateless).
 	FarseekMode bool
+
+	// DataSourceCache, if set, lets the plan reuse the results of reading
+	// data resources in earlier plans, and stores the results it reads.
+	DataSourceCache *DataSourceCache
 }
 
 // Plan generates an execution plan by comparing the given configuration
@@ -274,12 +284,13 @@ The -target and -exclude options are not for routine use, and are provided only
 	}
 
 	// insert the run-specific data from the context into the plan; variables,
-	// targets and provider SHAs.
+	// targets, force-replace addresses and provider SHAs.
 	if plan != nil {
 		plan.VariableValues = varVals
 		plan.EphemeralVariables = config.Module.EphemeralVariablesHints()
 		plan.TargetAddrs = opts.Targets
 		plan.ExcludeAddrs = opts.Excludes
+		plan.ForceReplaceAddrs = opts.ForceReplace
 	} else if !diags.HasErrors() {
 		panic("nil plan but no errors")
 	}
@@ -803,6 +814,7 @@ func (c *Context) planWalk(ctx context.Context, config *configs.Config, prevRunS
 		MoveResults:             moveResults,
 		PlanTimeTimestamp:       timestamp,
 		ProviderFunctionTracker: providerFunctionTracker,
+		DataSourceCache:         opts.DataSourceCache,
 	})
 	diags = diags.Append(walker.NonFatalDiagnostics)
 	diags = diags.Append(walkDiags)
@@ -879,6 +891,7 @@ func (c *Context) planGraph(ctx context.Context, config *configs.Config, prevRun
 			Excludes:                opts.Excludes,
 			ForceReplace:            opts.ForceReplace,
 			skipRefresh:             opts.SkipRefresh,
+			refreshProviders:        opts.RefreshProviders,
 			preDestroyRefresh:       opts.PreDestroyRefresh,
 			Operation:               walkPlan,
 			ExternalReferences:      opts.ExternalReferences,
@@ -898,6 +911,7 @@ func (c *Context) planGraph(ctx context.Context, config *configs.Config, prevRun
 			Targets:                 opts.Targets,
 			Excludes:                opts.Excludes,
 			skipRefresh:             opts.SkipRefresh,
+			refreshProviders:        opts.RefreshProviders,
 			skipPlanChanges:         true, // this activates "refresh only" mode.
 			Operation:               walkPlan,
 			ExternalReferences:      opts.ExternalReferences,
@@ -914,6 +928,7 @@ func (c *Context) planGraph(ctx context.Context, config *configs.Config, prevRun
 			Targets:                 opts.Targets,
 			Excludes:                opts.Excludes,
 			skipRefresh:             opts.SkipRefresh,
+			refreshProviders:        opts.RefreshProviders,
 			Operation:               walkPlanDestroy,
 			ProviderFunctionTracker: providerFunctionTracker,
 			FarseekMode:             opts.FarseekMode,
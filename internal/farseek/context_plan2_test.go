@@ -1119,6 +1119,76 @@ import {
 	}
 }
 
+func TestContext2Plan_refreshProviders(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "a"
+}
+
+resource "other_object" "b" {
+  test_string = "b"
+}
+`,
+	})
+
+	testP := simpleMockProvider()
+	otherP := &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			Provider: providers.Schema{Block: simpleTestSchema()},
+			ResourceTypes: map[string]providers.Schema{
+				"other_object": {Block: simpleTestSchema()},
+			},
+		},
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.a"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"a"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("other_object.b"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"b"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/other"]`), addrs.NoKey)
+		// An orphan of the provider that isn't refreshed.
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("other_object.gone"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"gone"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/other"]`), addrs.NoKey)
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"):  testProviderFuncFixed(testP),
+			addrs.NewDefaultProvider("other"): testProviderFuncFixed(otherP),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode:             plans.NormalMode,
+		RefreshProviders: []addrs.Provider{addrs.NewDefaultProvider("test")},
+	})
+	assertNoErrors(t, diags)
+
+	if !testP.ReadResourceCalled {
+		t.Errorf("ReadResource of the test provider wasn't called; should've been")
+	}
+	if otherP.ReadResourceCalled {
+		t.Errorf("ReadResource of the other provider was called; shouldn't have been")
+	}
+
+	for _, c := range plan.Changes.Resources {
+		want := plans.NoOp
+		if c.Addr.String() == "other_object.gone" {
+			want = plans.Delete
+		}
+		if c.Action != want {
+			t.Errorf("wrong action %s for %s; want %s", c.Action, c.Addr, want)
+		}
+	}
+}
+
 func TestContext2Plan_destroySkipRefresh(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
@@ -6253,6 +6323,133 @@ import {
 	})
 }
 
+func TestContext2Plan_importResourceAlreadyInStateFarseekMode(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "foo"
+}
+
+import {
+  to   = test_object.a
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	// In Farseek mode, the prior state holds a placeholder recovered from
+	// the configuration history, which the import block takes precedence
+	// over.
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(
+		addr.Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+		addrs.NoKey,
+	)
+
+	plan, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode:        plans.NormalMode,
+		FarseekMode: true,
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	instPlan := plan.Changes.ResourceInstance(addr)
+	if instPlan == nil {
+		t.Fatalf("no plan for %s at all", addr)
+	}
+	if got, want := instPlan.Action, plans.NoOp; got != want {
+		t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+	}
+	if instPlan.Importing == nil || instPlan.Importing.ID != "123" {
+		t.Errorf("expected import change from \"123\", got %+v", instPlan.Importing)
+	}
+	if !p.ImportResourceStateCalled {
+		t.Errorf("provider's ImportResourceState wasn't called")
+	}
+}
+
+func TestContext2Plan_checkFirstAmbiguousImport(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  name = "web"
+}
+`,
+	})
+
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"id":   {Type: cty.String, Computed: true},
+					"name": {Type: cty.String, Required: true},
+				},
+			},
+		},
+	})
+	object := func(id string) providers.ImportedResource {
+		return providers.ImportedResource{
+			TypeName: "test_object",
+			State: cty.ObjectVal(map[string]cty.Value{
+				"id":   cty.StringVal(id),
+				"name": cty.StringVal("web"),
+			}),
+		}
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{object("1"), object("2")},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	// Checking whether the resource already exists finds two objects with
+	// its name, so planning to create it could duplicate one of them.
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+		Mode:        plans.NormalMode,
+		FarseekMode: true,
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want an ambiguous import error")
+	}
+	if got, want := diags.Err().Error(), `the provider returned 2 objects, and 2 of them match the configuration`; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func TestContext2Plan_importResourceUpdate(t *testing.T) {
 	addr := mustResourceInstanceAddr("test_object.a")
 	m := testModuleInline(t, map[string]string{
@@ -7812,6 +8009,57 @@ func TestContext2Plan_removedResourceBasic(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_removedResourceFarseekMode(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			removed {
+				from = test_object.a
+				lifecycle {
+					destroy = false
+				}
+			}
+		`,
+	})
+
+	// In Farseek mode, the prior state holds the resources recovered from
+	// the configuration history, and the plan targets the resources that
+	// changed since then.
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"foo"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`), addrs.NoKey)
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, state, &PlanOpts{
+		Mode:        plans.NormalMode,
+		FarseekMode: true,
+		Targets:     []addrs.Targetable{addr},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	instPlan := plan.Changes.ResourceInstance(addr)
+	if instPlan == nil {
+		t.Fatalf("no plan for %s at all", addr)
+	}
+	if got, want := instPlan.Action, plans.Forget; got != want {
+		t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+	}
+	if p.ApplyResourceChangeCalled {
+		t.Errorf("provider's ApplyResourceChange was called")
+	}
+}
+
 func TestContext2Plan_removedModuleBasic(t *testing.T) {
 	desposedKey := states.DeposedKey("deposed")
 	addr := mustResourceInstanceAddr("module.mod.test_object.a")
@@ -8807,3 +9055,90 @@ func featuresBlockTestSchema() *configschema.Block {
 		},
 	}
 }
+
+func TestContext2Plan_providerConfigCycle(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+provider "test" {
+  test_string = data.test_object.creds.test_string
+}
+
+data "test_object" "creds" {
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags[0].Description().Summary, "Provider configuration depends on itself"; got != want {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+	if got, want := diags[0].Description().Detail, "data.test_object.creds"; !strings.Contains(got, want) {
+		t.Errorf("error doesn't mention %s:\n%s", want, got)
+	}
+}
+
+func TestContext2Plan_providerConfigUnknownWithoutPendingChanges(t *testing.T) {
+	// The configuration of the provider isn't known until apply, but not
+	// because of a data source or resource with pending changes, so there's
+	// nothing to wait for and it's configured with the unknown values.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+provider "test" {
+  token = timestamp()
+}
+
+resource "test_resource" "a" {
+  value = "a"
+}
+`,
+	})
+
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		Provider: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"token": {Type: cty.String, Required: true},
+			},
+		},
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(context.Background(), m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	if !p.ConfigureProviderCalled {
+		t.Fatal("provider was not configured during plan")
+	}
+	if got := p.ConfigureProviderRequest.Config.GetAttr("token"); got.IsKnown() {
+		t.Errorf("provider was configured with a known token %#v", got)
+	}
+	change := plan.Changes.ResourceInstance(mustResourceInstanceAddr("test_resource.a"))
+	if change == nil {
+		t.Fatal("no change for test_resource.a")
+	}
+	if change.ProviderDeferred {
+		t.Error("change for test_resource.a was planned without its provider")
+	}
+}
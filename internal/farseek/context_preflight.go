@@ -0,0 +1,216 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/dag"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	"github.com/rafagsiqueira/farseek/internal/tracing"
+)
+
+// ProviderStatus is the outcome of checking a provider instance with
+// Context.Preflight.
+type ProviderStatus string
+
+const (
+	// ProviderReady means that the provider instance was configured, and
+	// that reading its probe data source, if it has one, succeeded.
+	ProviderReady ProviderStatus = "ready"
+
+	// ProviderFailed means that configuring the provider instance, or
+	// reading its probe data source, failed.
+	ProviderFailed ProviderStatus = "failed"
+
+	// ProviderDeferred means that the configuration of the provider
+	// instance depends on values that won't be known until apply, so it
+	// couldn't be checked.
+	ProviderDeferred ProviderStatus = "deferred"
+)
+
+// ProviderCheck is the result of checking a single provider instance.
+type ProviderCheck struct {
+	Addr addrs.AbsProviderConfig
+	Key  addrs.InstanceKey
+
+	Status ProviderStatus
+
+	// Probe is the data source that was read to check the credentials of
+	// the provider, or empty if the provider has none that we know of, in
+	// which case only configuring the provider checked them.
+	Probe string
+
+	// Diagnostics are the diagnostics of configuring the provider and
+	// reading its probe.
+	Diagnostics tfdiags.Diagnostics
+}
+
+// preflightProbes are the data sources, by provider namespace and type, that
+// take no arguments and make a single cheap call with the credentials of the
+// provider, which fails if they aren't valid.
+var preflightProbes = map[string]string{
+	"hashicorp/aws":         "aws_caller_identity",
+	"hashicorp/azurerm":     "azurerm_client_config",
+	"hashicorp/google":      "google_client_config",
+	"hashicorp/google-beta": "google_client_config",
+}
+
+// Preflight configures each provider instance that the configuration uses,
+// without planning any resources, and then reads a data source of the
+// provider that checks its credentials, if we know of one. It returns the
+// result for each provider instance, sorted by address.
+//
+// The configurations of providers can refer to input variables and local
+// values, but not to the attributes of resources or data sources, which
+// aren't known without a plan, so the providers configured with them are
+// deferred rather than checked.
+//
+// The returned diagnostics are those of evaluating the configuration; those
+// of the providers are in their checks.
+func (c *Context) Preflight(ctx context.Context, config *configs.Config, state *states.State, opts *EvalOpts) ([]*ProviderCheck, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	defer c.acquireRun("preflight")()
+
+	ctx, span := tracing.Tracer().Start(
+		ctx, "Preflight phase",
+	)
+	defer span.End()
+
+	state = state.DeepCopy()
+	diags = diags.Append(checkInputVariables(config.Module.Variables, opts.SetVariables))
+
+	log.Printf("[DEBUG] Building and walking 'preflight' graph")
+
+	results := &preflightResults{}
+	providerFunctionTracker := make(ProviderFunctionMapping)
+	graph, moreDiags := (&EvalGraphBuilder{
+		Config:                  config,
+		State:                   state,
+		RootVariableValues:      opts.SetVariables,
+		Plugins:                 c.plugins,
+		ProviderFunctionTracker: providerFunctionTracker,
+		ConcreteProvider: func(a *NodeAbstractProvider) dag.Vertex {
+			return &nodePreflightProvider{
+				NodeApplyableProvider: &NodeApplyableProvider{NodeAbstractProvider: a},
+				results:               results,
+			}
+		},
+	}).Build(ctx, addrs.RootModuleInstance)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	walker, moreDiags := c.walk(ctx, graph, walkEval, &graphWalkOpts{
+		InputState:              state,
+		Config:                  config,
+		ProviderFunctionTracker: providerFunctionTracker,
+	})
+	diags = diags.Append(moreDiags)
+	if walker != nil {
+		diags = diags.Append(walker.NonFatalDiagnostics)
+	}
+
+	checks := results.checks
+	sort.Slice(checks, func(i, j int) bool {
+		a, b := checks[i].Addr.InstanceString(checks[i].Key), checks[j].Addr.InstanceString(checks[j].Key)
+		return a < b
+	})
+	return checks, diags
+}
+
+type preflightResults struct {
+	mu     sync.Mutex
+	checks []*ProviderCheck
+}
+
+func (r *preflightResults) add(check *ProviderCheck) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check)
+}
+
+// nodePreflightProvider configures the instances of a provider, like it is
+// configured for a plan, and then reads its probe data source, recording the
+// results rather than failing the walk.
+type nodePreflightProvider struct {
+	*NodeApplyableProvider
+	results *preflightResults
+}
+
+var _ GraphNodeExecutable = (*nodePreflightProvider)(nil)
+
+// GraphNodeExecutable
+func (n *nodePreflightProvider) Execute(ctx context.Context, evalCtx EvalContext, _ walkOperation) tfdiags.Diagnostics {
+	instances, diags := n.initInstances(ctx, evalCtx, walkPlan)
+	if diags.HasErrors() {
+		n.results.add(&ProviderCheck{
+			Addr:        n.Addr,
+			Key:         addrs.NoKey,
+			Status:      ProviderFailed,
+			Diagnostics: diags,
+		})
+		return nil
+	}
+
+	for key, provider := range instances {
+		check := &ProviderCheck{
+			Addr: n.Addr,
+			Key:  key,
+		}
+		check.Diagnostics = n.ConfigureProvider(ctx, evalCtx, key, provider, walkEval)
+		switch {
+		case check.Diagnostics.HasErrors():
+			check.Status = ProviderFailed
+		case evalCtx.ProviderDeferred(n.Addr, key):
+			check.Status = ProviderDeferred
+		default:
+			check.Probe, check.Diagnostics = n.probe(ctx, provider, check.Diagnostics)
+			check.Status = ProviderReady
+			if check.Diagnostics.HasErrors() {
+				check.Status = ProviderFailed
+			}
+		}
+		n.results.add(check)
+	}
+	return nil
+}
+
+// probe reads the probe data source of the provider, if it has one,
+// returning its name along with the given diagnostics and those of reading it.
+func (n *nodePreflightProvider) probe(ctx context.Context, provider providers.Interface, diags tfdiags.Diagnostics) (string, tfdiags.Diagnostics) {
+	name, ok := preflightProbes[n.Addr.Provider.Namespace+"/"+n.Addr.Provider.Type]
+	if !ok {
+		return "", diags
+	}
+	schemaResp := provider.GetProviderSchema(ctx)
+	schema, ok := schemaResp.DataSources[name]
+	if !ok || schema.Block == nil {
+		// An older or newer version of the provider than we expected.
+		log.Printf("[WARN] preflight: %s has no %s data source to check its credentials with", n.Addr, name)
+		return "", diags
+	}
+
+	log.Printf("[TRACE] preflight: reading %s to check the credentials of %s", name, n.Addr)
+	metaConfigVal := cty.NullVal(cty.DynamicPseudoType)
+	if schemaResp.ProviderMeta.Block != nil {
+		metaConfigVal = cty.NullVal(schemaResp.ProviderMeta.Block.ImpliedType())
+	}
+	resp := provider.ReadDataSource(ctx, providers.ReadDataSourceRequest{
+		TypeName:     name,
+		Config:       schema.Block.EmptyValue(),
+		ProviderMeta: metaConfigVal,
+	})
+	return name, diags.Append(resp.Diagnostics)
+}
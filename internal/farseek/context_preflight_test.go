@@ -0,0 +1,109 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestContextPreflight(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "name" {
+  type    = string
+  default = "foo"
+}
+
+provider "test" {
+  test_string = var.name
+}
+
+provider "test" {
+  alias       = "later"
+  test_string = test_object.a.test_string
+}
+
+provider "aws" {
+  test_string = "bar"
+}
+
+resource "test_object" "a" {
+}
+
+resource "test_object" "b" {
+  provider = test.later
+}
+
+resource "aws_object" "a" {
+}
+`,
+	})
+
+	test := simpleMockProvider()
+	aws := simpleMockProvider()
+	aws.GetProviderSchemaResponse.ResourceTypes = map[string]providers.Schema{
+		"aws_object": {Block: simpleTestSchema()},
+	}
+	aws.GetProviderSchemaResponse.DataSources = map[string]providers.Schema{
+		"aws_caller_identity": {Block: simpleTestSchema()},
+	}
+	aws.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(tfdiags.Error, "Invalid credentials", "The security token is not valid."))
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(test),
+			addrs.NewDefaultProvider("aws"):  testProviderFuncFixed(aws),
+		},
+	})
+
+	checks, diags := ctx.Preflight(t.Context(), m, states.NewState(), &EvalOpts{
+		SetVariables: testInputValuesUnset(m.Module.Variables),
+	})
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	got := map[string]*ProviderCheck{}
+	for _, check := range checks {
+		got[check.Addr.InstanceString(check.Key)] = check
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d checks, want 3: %#v", len(got), got)
+	}
+
+	if check := got[`provider["registry.opentofu.org/hashicorp/test"]`]; check == nil || check.Status != ProviderReady || check.Probe != "" {
+		t.Errorf("wrong check of the test provider: %#v", check)
+	}
+	if !test.ConfigureProviderCalled {
+		t.Error("the test provider wasn't configured")
+	} else if got, want := test.ConfigureProviderRequest.Config.GetAttr("test_string"), cty.StringVal("foo"); !got.RawEquals(want) {
+		t.Errorf("wrong configuration of the test provider: %#v", got)
+	}
+	if test.ReadResourceCalled || test.PlanResourceChangeCalled {
+		t.Error("preflight should not plan or read resources")
+	}
+
+	if check := got[`provider["registry.opentofu.org/hashicorp/test"].later`]; check == nil || check.Status != ProviderDeferred {
+		t.Errorf("wrong check of the test provider with a configuration that isn't known: %#v", check)
+	}
+
+	check := got[`provider["registry.opentofu.org/hashicorp/aws"]`]
+	if check == nil || check.Status != ProviderFailed || check.Probe != "aws_caller_identity" {
+		t.Fatalf("wrong check of the aws provider: %#v", check)
+	}
+	if got, want := check.Diagnostics.Err().Error(), "Invalid credentials"; !strings.Contains(got, want) {
+		t.Errorf("wrong diagnostics of the aws provider\n got: %s\nwant: %s", got, want)
+	}
+}
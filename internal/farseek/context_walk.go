@@ -45,6 +45,10 @@ type graphWalkOpts struct {
 	MoveResults refactoring.MoveResults
 
 	ProviderFunctionTracker ProviderFunctionMapping
+
+	// DataSourceCache is set only for plan walks, so that the apply phase
+	// always reads data resources from their providers.
+	DataSourceCache *DataSourceCache
 }
 
 func (c *Context) walk(ctx context.Context, graph *Graph, operation walkOperation, opts *graphWalkOpts) (*ContextGraphWalker, tfdiags.Diagnostics) {
@@ -156,5 +160,6 @@ func (c *Context) graphWalker(operation walkOperation, opts *graphWalkOpts) *Con
 		PlanTimestamp:           opts.PlanTimeTimestamp,
 		Encryption:              c.encryption,
 		ProviderFunctionTracker: opts.ProviderFunctionTracker,
+		DataSourceCache:         opts.DataSourceCache,
 	}
 }
@@ -0,0 +1,161 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// DataSourceCacheDir is the name of the directory, in the data directory of
+// a working directory, that holds the data source cache.
+const DataSourceCacheDir = "data-cache"
+
+// DataSourceCache stores the results of reading data resources during a
+// plan, so that the plans that follow within a TTL can reuse them instead of
+// reading them again from slow external APIs.
+//
+// A result is reused only for the same provider configuration, data source
+// type and configuration. A nil *DataSourceCache caches nothing.
+type DataSourceCache struct {
+	// Dir is the directory that holds the cached results.
+	Dir string
+
+	// DefaultTTL is the TTL of the data resources without a
+	// farseek_cache_ttl argument. Zero means that only the data resources
+	// with the argument are cached.
+	DefaultTTL time.Duration
+
+	// Bypass, if set, makes every data resource read from its provider
+	// again. The results are still stored for later plans.
+	Bypass bool
+
+	// now returns the current time, and is overridden in tests.
+	now func() time.Time
+}
+
+// dataSourceCacheEntry is the format of a file in the cache directory.
+type dataSourceCacheEntry struct {
+	Provider string          `json:"provider"`
+	Type     string          `json:"type"`
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// TTL returns how long the result of a data resource with the given
+// farseek_cache_ttl argument may be reused, or zero if it must not be
+// cached.
+func (c *DataSourceCache) TTL(configured *time.Duration) time.Duration {
+	if c == nil {
+		return 0
+	}
+	if configured != nil {
+		return *configured
+	}
+	return c.DefaultTTL
+}
+
+// Get returns the result stored for reading the given data source type with
+// the given configuration from the given provider configuration, if there is
+// one that is younger than ttl and conforms to the given type.
+func (c *DataSourceCache) Get(provider, typeName string, config cty.Value, ty cty.Type, ttl time.Duration) (cty.Value, bool) {
+	if c == nil || c.Bypass || ttl <= 0 {
+		return cty.NilVal, false
+	}
+	path, err := c.path(provider, typeName, config)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	var entry dataSourceCacheEntry
+	if err := json.Unmarshal(src, &entry); err != nil {
+		return cty.NilVal, false
+	}
+	if c.timeNow().Sub(entry.StoredAt) >= ttl {
+		return cty.NilVal, false
+	}
+	// An entry whose value no longer conforms to the schema, such as after
+	// a provider upgrade, is just a miss.
+	val, err := ctyjson.Unmarshal(entry.Value, ty)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// Put stores the result of reading the given data source type with the
+// given configuration from the given provider configuration.
+func (c *DataSourceCache) Put(provider, typeName string, config cty.Value, val cty.Value) error {
+	if c == nil {
+		return nil
+	}
+	path, err := c.path(provider, typeName, config)
+	if err != nil {
+		return err
+	}
+	valSrc, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return err
+	}
+	src, err := json.Marshal(dataSourceCacheEntry{
+		Provider: provider,
+		Type:     typeName,
+		StoredAt: c.timeNow().UTC(),
+		Value:    valSrc,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Results can include secrets, so only the user can read them, and we
+	// replace the file with a rename so that concurrent plans never read a
+	// partial entry.
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// path returns the path of the file that holds the result for the given
+// key, which is a hash of the key so that it is a valid filename.
+func (c *DataSourceCache) path(provider, typeName string, config cty.Value) (string, error) {
+	configSrc, err := ctyjson.Marshal(config, cty.DynamicPseudoType)
+	if err != nil {
+		return "", fmt.Errorf("can't hash data source configuration: %w", err)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", provider, typeName)
+	h.Write(configSrc)
+	return filepath.Join(c.Dir, hex.EncodeToString(h.Sum(nil))+".json"), nil
+}
+
+func (c *DataSourceCache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
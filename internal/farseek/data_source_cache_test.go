@@ -0,0 +1,193 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestDataSourceCache(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cache := &DataSourceCache{
+		Dir: t.TempDir(),
+		now: func() time.Time { return now },
+	}
+	ty := cty.Object(map[string]cty.Type{"id": cty.String})
+	config := cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("ubuntu")})
+	val := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("ami-1")})
+
+	if _, ok := cache.Get("provider", "aws_ami", config, ty, time.Hour); ok {
+		t.Fatal("unexpected hit in an empty cache")
+	}
+	if err := cache.Put("provider", "aws_ami", config, val); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	got, ok := cache.Get("provider", "aws_ami", config, ty, time.Hour)
+	if !ok {
+		t.Fatal("unexpected miss within the TTL")
+	}
+	if !got.RawEquals(val) {
+		t.Errorf("wrong value %#v; want %#v", got, val)
+	}
+
+	otherConfig := cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("debian")})
+	if _, ok := cache.Get("provider", "aws_ami", otherConfig, ty, time.Hour); ok {
+		t.Error("unexpected hit for another configuration")
+	}
+	if _, ok := cache.Get("other", "aws_ami", config, ty, time.Hour); ok {
+		t.Error("unexpected hit for another provider configuration")
+	}
+	if _, ok := cache.Get("provider", "aws_ami", config, cty.Object(map[string]cty.Type{"arn": cty.String}), time.Hour); ok {
+		t.Error("unexpected hit for a value that doesn't conform to the schema")
+	}
+	if _, ok := cache.Get("provider", "aws_ami", config, ty, 10*time.Minute); ok {
+		t.Error("unexpected hit after the TTL")
+	}
+
+	cache.Bypass = true
+	if _, ok := cache.Get("provider", "aws_ami", config, ty, time.Hour); ok {
+		t.Error("unexpected hit when bypassing the cache")
+	}
+}
+
+func TestDataSourceCache_TTL(t *testing.T) {
+	var nilCache *DataSourceCache
+	hour, zero := time.Hour, time.Duration(0)
+	if got := nilCache.TTL(&hour); got != 0 {
+		t.Errorf("wrong TTL %s without a cache", got)
+	}
+
+	cache := &DataSourceCache{DefaultTTL: 5 * time.Minute}
+	if got := cache.TTL(nil); got != 5*time.Minute {
+		t.Errorf("wrong default TTL %s", got)
+	}
+	if got := cache.TTL(&hour); got != hour {
+		t.Errorf("wrong configured TTL %s", got)
+	}
+	if got := cache.TTL(&zero); got != 0 {
+		t.Errorf("wrong TTL %s for a data resource that opts out", got)
+	}
+}
+
+func TestContext2Plan_dataSourceCache(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+data "test_data_source" "cached" {
+  name              = "cached"
+  farseek_cache_ttl = "1h"
+}
+
+data "test_data_source" "uncached" {
+  name = "uncached"
+}
+
+data "test_secret_source" "secret" {
+  name              = "secret"
+  farseek_cache_ttl = "1h"
+}
+
+data "test_data_source" "marked" {
+  name              = sensitive("marked")
+  farseek_cache_ttl = "1h"
+}
+`,
+	})
+
+	reads := make(map[string]int)
+	p := &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			DataSources: map[string]providers.Schema{
+				"test_data_source": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"name": {Type: cty.String, Required: true},
+							"id":   {Type: cty.String, Computed: true},
+						},
+					},
+				},
+				"test_secret_source": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"name":  {Type: cty.String, Required: true},
+							"id":    {Type: cty.String, Computed: true},
+							"token": {Type: cty.String, Computed: true, Sensitive: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+		name := req.Config.GetAttr("name").AsString()
+		reads[name]++
+		attrs := map[string]cty.Value{
+			"name": req.Config.GetAttr("name"),
+			"id":   cty.StringVal(name + "-id"),
+		}
+		if req.TypeName == "test_secret_source" {
+			attrs["token"] = cty.StringVal("hunter2")
+		}
+		resp.State = cty.ObjectVal(attrs)
+		return resp
+	}
+
+	cache := &DataSourceCache{Dir: t.TempDir()}
+	plan := func(t *testing.T) {
+		t.Helper()
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+		plan, diags := ctx.Plan(context.Background(), m, states.NewState(), &PlanOpts{
+			Mode:            DefaultPlanOpts.Mode,
+			DataSourceCache: cache,
+		})
+		assertNoErrors(t, diags)
+
+		addr := mustResourceInstanceAddr("data.test_data_source.cached")
+		rs := plan.PriorState.ResourceInstance(addr)
+		if rs == nil || rs.Current == nil {
+			t.Fatalf("no state for %s", addr)
+		}
+	}
+
+	plan(t)
+	plan(t)
+
+	// The data source whose schema has a sensitive attribute, and the one
+	// whose configuration has a sensitive value, are read every time, even
+	// though their configurations ask for caching.
+	want := map[string]int{"cached": 1, "uncached": 2, "secret": 2, "marked": 2}
+	for name, count := range want {
+		if reads[name] != count {
+			t.Errorf("%s read %d times; want %d", name, reads[name], count)
+		}
+	}
+
+	// Bypassing the cache reads again, and a default TTL caches the data
+	// sources without their own.
+	cache.Bypass = true
+	cache.DefaultTTL = time.Hour
+	plan(t)
+	cache.Bypass = false
+	plan(t)
+	want = map[string]int{"cached": 2, "uncached": 3, "secret": 4, "marked": 4}
+	for name, count := range want {
+		if reads[name] != count {
+			t.Errorf("%s read %d times; want %d", name, reads[name], count)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// DestroyOrderConflict describes a resource instance that is declared with
+// create_before_destroy but depends on a resource that explicitly opts out
+// of it, which Farseek can't order consistently when replacing them.
+type DestroyOrderConflict struct {
+	Resource   addrs.AbsResourceInstance
+	Dependency addrs.AbsResourceInstance
+}
+
+// DestroyOrder groups the given managed resource instances of a
+// configuration into the waves in which a destroy would remove them. The
+// instances in each wave don't depend on one another and so are destroyed in
+// parallel, and each wave starts only after the previous one is complete.
+//
+// Stateless destroys have no dependencies recorded in state, so they are
+// taken from the references in the configuration instead, following local
+// values, data resources, and the input variables and output values that
+// connect modules. As in a plan, an instance depends on every instance of
+// the resources it refers to, except that a reference within a module only
+// reaches the instances in the same instance of the module. Instances of
+// resources that aren't declared in the configuration have no known
+// dependencies.
+func DestroyOrder(config *configs.Config, resources []addrs.AbsResourceInstance) (waves [][]addrs.AbsResourceInstance, conflicts []DestroyOrderConflict, diags tfdiags.Diagnostics) {
+	selected := make(map[string]addrs.AbsResourceInstance, len(resources))
+	for _, addr := range resources {
+		if addr.Resource.Resource.Mode == addrs.ManagedResourceMode {
+			selected[addr.String()] = addr
+		}
+	}
+
+	// dependents counts, for each instance, how many of the selected
+	// instances depend on it and so must be destroyed first.
+	configDeps := make(map[string][]addrs.ConfigResource)
+	deps := make(map[string][]addrs.AbsResourceInstance, len(selected))
+	dependents := make(map[string]int, len(selected))
+	for key, addr := range selected {
+		configAddr := addr.ConfigResource()
+		resourceDeps, ok := configDeps[configAddr.String()]
+		if !ok {
+			resourceDeps = resourceDependencies(config, configAddr)
+			configDeps[configAddr.String()] = resourceDeps
+		}
+		for depKey, dep := range selected {
+			if depKey == key || !dependsOn(addr, dep, resourceDeps) {
+				continue
+			}
+			deps[key] = append(deps[key], dep)
+			dependents[depKey]++
+		}
+	}
+
+	remaining := make(map[string]addrs.AbsResourceInstance, len(selected))
+	for key, addr := range selected {
+		remaining[key] = addr
+	}
+	for len(remaining) > 0 {
+		var wave []addrs.AbsResourceInstance
+		for key, addr := range remaining {
+			if dependents[key] == 0 {
+				wave = append(wave, addr)
+			}
+		}
+		if len(wave) == 0 {
+			var cycle []string
+			for key := range remaining {
+				cycle = append(cycle, key)
+			}
+			sort.Strings(cycle)
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Dependency cycle",
+				fmt.Sprintf("The destroy order can't be determined, because these resources depend on each other:\n  - %s", strings.Join(cycle, "\n  - ")),
+			))
+			break
+		}
+		sortResourceInstances(wave)
+		for _, addr := range wave {
+			delete(remaining, addr.String())
+			for _, dep := range deps[addr.String()] {
+				dependents[dep.String()]--
+			}
+		}
+		waves = append(waves, wave)
+	}
+
+	for _, wave := range waves {
+		for _, addr := range wave {
+			rc := configResource(config, addr.ConfigResource())
+			if rc == nil || rc.Managed == nil || !rc.Managed.CreateBeforeDestroy {
+				continue
+			}
+			sortResourceInstances(deps[addr.String()])
+			for _, dep := range deps[addr.String()] {
+				dc := configResource(config, dep.ConfigResource())
+				if dc != nil && dc.Managed != nil && dc.Managed.CreateBeforeDestroySet && !dc.Managed.CreateBeforeDestroy {
+					conflicts = append(conflicts, DestroyOrderConflict{Resource: addr, Dependency: dep})
+				}
+			}
+		}
+	}
+
+	return waves, conflicts, diags
+}
+
+// dependsOn returns true if the resource instance addr depends on the
+// instance dep, given the resources that addr's resource refers to.
+func dependsOn(addr, dep addrs.AbsResourceInstance, resourceDeps []addrs.ConfigResource) bool {
+	depConfigAddr := dep.ConfigResource()
+	for _, resourceDep := range resourceDeps {
+		if !resourceDep.Equal(depConfigAddr) {
+			continue
+		}
+		// A reference to a resource in the same module refers to the
+		// instances in the same instance of the module.
+		if resourceDep.Module.Equal(addr.Module.Module()) {
+			return addr.Module.Equal(dep.Module)
+		}
+		return true
+	}
+	return false
+}
+
+// ResourceDependencies returns, for each managed resource in a root module,
+// the managed resources it refers to in its configuration, keyed by resource
+// address.
+func ResourceDependencies(mod *configs.Module) map[string][]addrs.ConfigResource {
+	config := &configs.Config{Module: mod}
+	config.Root = config
+	ret := make(map[string][]addrs.ConfigResource, len(mod.ManagedResources))
+	for _, rc := range mod.ManagedResources {
+		addr := rc.Addr()
+		deps := resourceDependencies(config, addr.InModule(addrs.RootModule))
+		if len(deps) == 0 {
+			continue
+		}
+		ret[addr.String()] = deps
+	}
+	return ret
+}
+
+// resourceDependencies returns the managed resources that the given resource
+// refers to in its configuration, directly or through local values, data
+// resources, input variables, and the output values of other modules, in
+// order of their addresses.
+func resourceDependencies(config *configs.Config, addr addrs.ConfigResource) []addrs.ConfigResource {
+	var ret []addrs.ConfigResource
+	seen := make(map[string]bool)
+
+	var visit func(c *configs.Config, traversals []hcl.Traversal)
+	visitOutput := func(c *configs.Config, name string) {
+		if c == nil {
+			return
+		}
+		oc := c.Module.Outputs[name]
+		key := addrs.OutputValue{Name: name}.InModule(c.Path).String()
+		if oc == nil || seen[key] {
+			return
+		}
+		seen[key] = true
+		visit(c, oc.Expr.Variables())
+		visit(c, oc.DependsOn)
+	}
+	// A reference to a whole module refers to all of its output values.
+	visitOutputs := func(c *configs.Config) {
+		if c == nil {
+			return
+		}
+		for name := range c.Module.Outputs {
+			visitOutput(c, name)
+		}
+	}
+	visit = func(c *configs.Config, traversals []hcl.Traversal) {
+		for _, traversal := range traversals {
+			ref, diags := addrs.ParseRef(traversal)
+			if diags.HasErrors() {
+				continue
+			}
+			var resource addrs.Resource
+			switch subject := ref.Subject.(type) {
+			case addrs.ResourceInstance:
+				resource = subject.Resource
+			case addrs.Resource:
+				resource = subject
+			case addrs.LocalValue:
+				local := c.Module.Locals[subject.Name]
+				key := subject.Absolute(c.Path.UnkeyedInstanceShim()).String()
+				if local == nil || seen[key] {
+					continue
+				}
+				seen[key] = true
+				visit(c, local.Expr.Variables())
+				continue
+			case addrs.InputVariable:
+				// An input variable has the value of the argument of the
+				// same name in the call to the module.
+				key := subject.Absolute(c.Path.UnkeyedInstanceShim()).String()
+				if c.Parent == nil || seen[key] {
+					continue
+				}
+				seen[key] = true
+				if call := c.Parent.Module.ModuleCalls[c.Path[len(c.Path)-1]]; call != nil {
+					if expr := bodyAttribute(call.Config, subject.Name); expr != nil {
+						visit(c.Parent, expr.Variables())
+					}
+				}
+				continue
+			case addrs.ModuleCallInstanceOutput:
+				visitOutput(c.Children[subject.Call.Call.Name], subject.Name)
+				continue
+			case addrs.ModuleCallOutput:
+				visitOutput(c.Children[subject.Call.Name], subject.Name)
+				continue
+			case addrs.ModuleCallInstance:
+				visitOutputs(c.Children[subject.Call.Name])
+				continue
+			case addrs.ModuleCall:
+				visitOutputs(c.Children[subject.Name])
+				continue
+			default:
+				continue
+			}
+			depAddr := resource.InModule(c.Path)
+			if seen[depAddr.String()] {
+				continue
+			}
+			seen[depAddr.String()] = true
+			if resource.Mode == addrs.ManagedResourceMode {
+				ret = append(ret, depAddr)
+				continue
+			}
+			// A data resource is read before the resources that refer to
+			// it, so its own dependencies are theirs too.
+			if rc := c.Module.ResourceByAddr(resource); rc != nil {
+				visit(c, configTraversals(rc))
+			}
+		}
+	}
+
+	c := config.Root.Descendent(addr.Module)
+	if c == nil {
+		return nil
+	}
+	rc := c.Module.ResourceByAddr(addr.Resource)
+	if rc == nil {
+		return nil
+	}
+	seen[addr.String()] = true
+	visit(c, configTraversals(rc))
+
+	// Every resource in a module also depends on what the calls to the
+	// module and its ancestors depend on.
+	for ; c.Parent != nil; c = c.Parent {
+		if call := c.Parent.Module.ModuleCalls[c.Path[len(c.Path)-1]]; call != nil {
+			visit(c.Parent, call.DependsOn)
+			for _, expr := range []hcl.Expression{call.Count, call.ForEach, call.Enabled} {
+				if expr != nil {
+					visit(c.Parent, expr.Variables())
+				}
+			}
+		}
+	}
+
+	sortConfigResources(ret)
+	return ret
+}
+
+// configTraversals returns all of the traversals in the configuration of a
+// resource, including its meta-arguments.
+func configTraversals(rc *configs.Resource) []hcl.Traversal {
+	ret := bodyTraversals(rc.Config)
+	for _, expr := range []hcl.Expression{rc.Count, rc.ForEach} {
+		if expr != nil {
+			ret = append(ret, expr.Variables()...)
+		}
+	}
+	return append(ret, rc.DependsOn...)
+}
+
+// bodyTraversals returns the traversals in all of the expressions in a body,
+// without needing its schema.
+func bodyTraversals(body hcl.Body) []hcl.Traversal {
+	if body == nil {
+		return nil
+	}
+	var ret []hcl.Traversal
+	if synBody, ok := body.(*hclsyntax.Body); ok {
+		for _, attr := range synBody.Attributes {
+			ret = append(ret, attr.Expr.Variables()...)
+		}
+		for _, block := range synBody.Blocks {
+			ret = append(ret, bodyTraversals(block.Body)...)
+		}
+		return ret
+	}
+	// Other syntaxes, such as JSON, can represent nested blocks as
+	// attributes, so we can find the references without a schema.
+	attrs, _ := body.JustAttributes()
+	for _, attr := range attrs {
+		ret = append(ret, attr.Expr.Variables()...)
+	}
+	return ret
+}
+
+// configResource returns the configuration of the given resource, or nil if
+// it isn't declared.
+func configResource(config *configs.Config, addr addrs.ConfigResource) *configs.Resource {
+	c := config.Root.Descendent(addr.Module)
+	if c == nil {
+		return nil
+	}
+	return c.Module.ResourceByAddr(addr.Resource)
+}
+
+// bodyAttribute returns the expression of the attribute with the given name
+// in a body, without needing its schema, or nil if there is none.
+func bodyAttribute(body hcl.Body, name string) hcl.Expression {
+	if body == nil {
+		return nil
+	}
+	if synBody, ok := body.(*hclsyntax.Body); ok {
+		if attr, ok := synBody.Attributes[name]; ok {
+			return attr.Expr
+		}
+		return nil
+	}
+	attrs, _ := body.JustAttributes()
+	if attr, ok := attrs[name]; ok {
+		return attr.Expr
+	}
+	return nil
+}
+
+func sortConfigResources(resources []addrs.ConfigResource) {
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].String() < resources[j].String()
+	})
+}
+
+func sortResourceInstances(resources []addrs.AbsResourceInstance) {
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Less(resources[j])
+	})
+}
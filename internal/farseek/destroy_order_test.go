@@ -0,0 +1,250 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+func TestDestroyOrder(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "network" {
+}
+
+resource "test_object" "subnet" {
+  test_string = test_object.network.test_string
+}
+
+data "test_data_source" "lookup" {
+  id = test_object.subnet.test_string
+}
+
+locals {
+  subnet = data.test_data_source.lookup.id
+}
+
+resource "test_object" "server" {
+  count       = 2
+  test_string = local.subnet
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+resource "test_object" "dns" {
+  test_string = "example"
+  depends_on  = [test_object.network]
+}
+
+resource "test_object" "pinned" {
+  lifecycle {
+    create_before_destroy = false
+  }
+}
+
+resource "test_object" "replacement" {
+  test_string = test_object.pinned.test_string
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`,
+	})
+
+	resources := testDestroyOrderResources(t,
+		"test_object.network",
+		"test_object.subnet",
+		"test_object.server[0]",
+		"test_object.server[1]",
+		"test_object.dns",
+		"test_object.pinned",
+		"test_object.replacement",
+		// Data resources are never destroyed, and undeclared resources
+		// have no known dependencies.
+		"data.test_data_source.lookup",
+		"test_object.removed",
+	)
+
+	waves, conflicts, diags := DestroyOrder(m, resources)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	got := make([][]string, len(waves))
+	for i, wave := range waves {
+		for _, addr := range wave {
+			got[i] = append(got[i], addr.String())
+		}
+	}
+	want := [][]string{
+		{"test_object.dns", "test_object.removed", "test_object.replacement", "test_object.server[0]", "test_object.server[1]"},
+		{"test_object.pinned", "test_object.subnet"},
+		{"test_object.network"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong destroy order\n%s", diff)
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("wrong number of conflicts %d; want 1", len(conflicts))
+	}
+	if got, want := conflicts[0].Resource.String(), "test_object.replacement"; got != want {
+		t.Errorf("wrong conflicting resource %s; want %s", got, want)
+	}
+	if got, want := conflicts[0].Dependency.String(), "test_object.pinned"; got != want {
+		t.Errorf("wrong conflicting dependency %s; want %s", got, want)
+	}
+}
+
+func TestDestroyOrder_cycle(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = test_object.b.test_string
+}
+
+resource "test_object" "b" {
+  test_string = test_object.a.test_string
+}
+
+resource "test_object" "c" {
+}
+`,
+	})
+
+	resources := testDestroyOrderResources(t, "test_object.a", "test_object.b", "test_object.c")
+
+	waves, _, diags := DestroyOrder(m, resources)
+	if !diags.HasErrors() {
+		t.Fatal("expected an error")
+	}
+	if got := diags.Err().Error(); !strings.Contains(got, "test_object.a\n  - test_object.b") {
+		t.Errorf("wrong error: %s", got)
+	}
+	if len(waves) != 1 || len(waves[0]) != 1 || waves[0][0].String() != "test_object.c" {
+		t.Errorf("wrong waves %v", waves)
+	}
+}
+
+func TestDestroyOrder_modules(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "network" {
+}
+
+module "app" {
+  source   = "./app"
+  for_each = toset(["a", "b"])
+  network  = test_object.network.test_string
+}
+
+resource "test_object" "dns" {
+  test_string = module.app["a"].address
+}
+
+module "unrelated" {
+  source = "./unrelated"
+}
+`,
+		"app/main.tf": `
+variable "network" {
+  type = string
+}
+
+resource "test_object" "subnet" {
+  test_string = var.network
+}
+
+resource "test_object" "server" {
+  test_string = test_object.subnet.test_string
+}
+
+output "address" {
+  value = test_object.server.test_string
+}
+`,
+		"unrelated/main.tf": `
+resource "test_object" "other" {
+}
+`,
+	})
+
+	resources := testDestroyOrderResources(t,
+		"test_object.network",
+		"test_object.dns",
+		`module.app["a"].test_object.subnet`,
+		`module.app["a"].test_object.server`,
+		`module.app["b"].test_object.subnet`,
+		`module.app["b"].test_object.server`,
+		"module.unrelated.test_object.other",
+	)
+
+	waves, _, diags := DestroyOrder(m, resources)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	got := make([][]string, len(waves))
+	for i, wave := range waves {
+		for _, addr := range wave {
+			got[i] = append(got[i], addr.String())
+		}
+	}
+	// The references within the module reach only the instances in the same
+	// instance of the module, but those that go through its input variables
+	// and output values reach every instance, as they do in a plan.
+	want := [][]string{
+		{"test_object.dns", "module.unrelated.test_object.other"},
+		{`module.app["a"].test_object.server`, `module.app["b"].test_object.server`},
+		{`module.app["a"].test_object.subnet`, `module.app["b"].test_object.subnet`},
+		{"test_object.network"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong destroy order\n%s", diff)
+	}
+
+	// The server in one instance of the module doesn't depend on the
+	// subnet in the other.
+	resources = testDestroyOrderResources(t,
+		"test_object.network",
+		`module.app["a"].test_object.server`,
+		`module.app["b"].test_object.subnet`,
+	)
+	waves, _, diags = DestroyOrder(m, resources)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	got = make([][]string, len(waves))
+	for i, wave := range waves {
+		for _, addr := range wave {
+			got[i] = append(got[i], addr.String())
+		}
+	}
+	want = [][]string{
+		{`module.app["a"].test_object.server`, `module.app["b"].test_object.subnet`},
+		{"test_object.network"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong destroy order\n%s", diff)
+	}
+}
+
+func testDestroyOrderResources(t *testing.T, addrStrs ...string) []addrs.AbsResourceInstance {
+	t.Helper()
+	ret := make([]addrs.AbsResourceInstance, len(addrStrs))
+	for i, addrStr := range addrStrs {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(addrStr)
+		if diags.HasErrors() {
+			t.Fatal(diags.Err())
+		}
+		ret[i] = addr
+	}
+	return ret
+}
@@ -78,6 +78,14 @@ type EvalContext interface {
 	// provider configuration does not match the Path() of the EvalContext.
 	ConfigureProvider(context.Context, addrs.AbsProviderConfig, addrs.InstanceKey, cty.Value) tfdiags.Diagnostics
 
+	// DeferProvider records that the provider instance with the given
+	// address isn't configured during this walk, because its configuration
+	// depends on values that won't be known until apply. ProviderDeferred
+	// reports whether it was called for a provider instance, so that the
+	// actions that need that instance can be deferred to apply too.
+	DeferProvider(addrs.AbsProviderConfig, addrs.InstanceKey)
+	ProviderDeferred(addrs.AbsProviderConfig, addrs.InstanceKey) bool
+
 	// ProviderInput and SetProviderInput are used to configure providers
 	// from user input.
 	//
@@ -219,4 +227,8 @@ type EvalContext interface {
 
 	// Returns the currently configured encryption setup
 	GetEncryption() encryption.Encryption
+
+	// DataSourceCache returns the cache of data resource results to use,
+	// which is nil outside of plan walks or if caching isn't enabled.
+	DataSourceCache() *DataSourceCache
 }
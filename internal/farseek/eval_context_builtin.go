@@ -67,6 +67,7 @@ type BuiltinEvalContext struct {
 	ProviderLock        *sync.Mutex
 	ProviderCache       map[string]map[addrs.InstanceKey]providers.Interface
 	ProviderInputConfig map[string]map[string]cty.Value
+	DeferredProviders   map[string]struct{}
 
 	ProvisionerLock  *sync.Mutex
 	ProvisionerCache map[string]provisioners.Interface
@@ -81,6 +82,7 @@ type BuiltinEvalContext struct {
 	ImportResolverValue     *ImportResolver
 	Encryption              encryption.Encryption
 	ProviderFunctionTracker ProviderFunctionMapping
+	DataSourceCacheValue    *DataSourceCache
 }
 
 // BuiltinEvalContext implements EvalContext
@@ -217,6 +219,21 @@ func (c *BuiltinEvalContext) ConfigureProvider(ctx context.Context, addr addrs.A
 	return resp.Diagnostics
 }
 
+func (c *BuiltinEvalContext) DeferProvider(addr addrs.AbsProviderConfig, providerKey addrs.InstanceKey) {
+	c.ProviderLock.Lock()
+	defer c.ProviderLock.Unlock()
+
+	c.DeferredProviders[addr.InstanceString(providerKey)] = struct{}{}
+}
+
+func (c *BuiltinEvalContext) ProviderDeferred(addr addrs.AbsProviderConfig, providerKey addrs.InstanceKey) bool {
+	c.ProviderLock.Lock()
+	defer c.ProviderLock.Unlock()
+
+	_, deferred := c.DeferredProviders[addr.InstanceString(providerKey)]
+	return deferred
+}
+
 func (c *BuiltinEvalContext) ProviderInput(_ context.Context, pc addrs.AbsProviderConfig) map[string]cty.Value {
 	c.ProviderLock.Lock()
 	defer c.ProviderLock.Unlock()
@@ -572,3 +589,7 @@ func (c *BuiltinEvalContext) ImportResolver() *ImportResolver {
 func (c *BuiltinEvalContext) GetEncryption() encryption.Encryption {
 	return c.Encryption
 }
+
+func (c *BuiltinEvalContext) DataSourceCache() *DataSourceCache {
+	return c.DataSourceCacheValue
+}
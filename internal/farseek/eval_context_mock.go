@@ -73,6 +73,11 @@ type MockEvalContext struct {
 	ConfigureProviderConfig cty.Value
 	ConfigureProviderDiags  tfdiags.Diagnostics
 
+	DeferProviderCalled bool
+	DeferProviderAddr   addrs.AbsProviderConfig
+
+	ProviderDeferredValue bool
+
 	ProvisionerCalled      bool
 	ProvisionerName        string
 	ProvisionerProvisioner provisioners.Interface
@@ -158,6 +163,8 @@ type MockEvalContext struct {
 
 	InstanceExpanderCalled   bool
 	InstanceExpanderExpander *instances.Expander
+
+	DataSourceCacheValue *DataSourceCache
 }
 
 // MockEvalContext implements EvalContext
@@ -219,6 +226,15 @@ func (c *MockEvalContext) ConfigureProvider(_ context.Context, addr addrs.AbsPro
 	return c.ConfigureProviderDiags
 }
 
+func (c *MockEvalContext) DeferProvider(addr addrs.AbsProviderConfig, _ addrs.InstanceKey) {
+	c.DeferProviderCalled = true
+	c.DeferProviderAddr = addr
+}
+
+func (c *MockEvalContext) ProviderDeferred(addrs.AbsProviderConfig, addrs.InstanceKey) bool {
+	return c.ProviderDeferredValue
+}
+
 func (c *MockEvalContext) ProviderInput(_ context.Context, addr addrs.AbsProviderConfig) map[string]cty.Value {
 	c.ProviderInputCalled = true
 	c.ProviderInputAddr = addr
@@ -417,3 +433,7 @@ func (c *MockEvalContext) InstanceExpander() *instances.Expander {
 func (c *MockEvalContext) GetEncryption() encryption.Encryption {
 	return encryption.Disabled()
 }
+
+func (c *MockEvalContext) DataSourceCache() *DataSourceCache {
+	return c.DataSourceCacheValue
+}
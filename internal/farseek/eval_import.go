@@ -6,6 +6,7 @@ package farseek
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -13,6 +14,7 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/instances"
 	"github.com/rafagsiqueira/farseek/internal/lang/evalchecks"
 	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+	"github.com/rafagsiqueira/farseek/internal/providers"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
@@ -230,3 +232,148 @@ func parseImportIndexKeyExpr(evalCtx EvalContext, expr hcl.Expression, keyData i
 	idx.Key = unmarkedVal
 	return idx, diags
 }
+
+// importIdentityAttributes are the attributes that identify a remote object
+// across most providers, in the order that candidates are described in.
+var importIdentityAttributes = []string{"id", "arn", "self_link", "name"}
+
+// selectImportedResource picks the object to import to addr among those that
+// the provider returned for importId. Some providers return a collection for
+// an ambiguous ID, such as a name that several objects share, or include the
+// objects related to the one imported. Only the objects of the resource's
+// type are candidates, and if there is still more than one, those whose
+// identity attributes match the given configuration value, or whose ID or
+// name is the import ID when the configuration sets none of them.
+//
+// If there isn't exactly one match, the returned error lists the candidates,
+// with the code CodeAmbiguousImport.
+func selectImportedResource(addr addrs.AbsResourceInstance, importId string, imported []providers.ImportedResource, config cty.Value) (providers.ImportedResource, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(imported) == 1 {
+		return imported[0], diags
+	}
+
+	var candidates []providers.ImportedResource
+	for _, obj := range imported {
+		if obj.TypeName == addr.Resource.Resource.Type {
+			candidates = append(candidates, obj)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], diags
+	}
+
+	want := make(map[string]cty.Value)
+	if config != cty.NilVal {
+		config, _ = config.UnmarkDeep()
+		if config.IsKnown() && !config.IsNull() && config.Type().IsObjectType() {
+			for _, name := range importIdentityAttributes {
+				if !config.Type().HasAttribute(name) {
+					continue
+				}
+				if v := config.GetAttr(name); v.IsKnown() && !v.IsNull() && v.Type().IsPrimitiveType() {
+					want[name] = v
+				}
+			}
+		}
+	}
+	var matches []providers.ImportedResource
+	for _, obj := range candidates {
+		if importedResourceMatches(obj, want, importId) {
+			matches = append(matches, obj)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], diags
+	}
+
+	if len(candidates) == 0 {
+		candidates = imported
+	}
+	var buf strings.Builder
+	for _, obj := range candidates {
+		fmt.Fprintf(&buf, "\n  - %s", describeImportedResource(obj))
+	}
+	reason := "none of them matches the configuration"
+	if len(matches) > 1 {
+		reason = fmt.Sprintf("%d of them match the configuration", len(matches))
+	}
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Ambiguous import result",
+		fmt.Sprintf(
+			"While attempting to import %s with ID %q, the provider returned %d objects, and %s, so Farseek can't tell which one to import:\n%s\n\nSet the attributes that identify the object, such as its name, in the configuration of the resource, or import it with an ID that only matches one object.",
+			addr, importId, len(imported), reason, buf.String(),
+		),
+	), tfdiags.CodeAmbiguousImport))
+	return providers.ImportedResource{}, diags
+}
+
+// importedResourceMatches returns whether the identity attributes of the
+// given imported object have the wanted values, or if none are wanted,
+// whether one of them is the import ID.
+func importedResourceMatches(obj providers.ImportedResource, want map[string]cty.Value, importId string) bool {
+	if obj.State == cty.NilVal {
+		return false
+	}
+	state, _ := obj.State.UnmarkDeep()
+	if !state.IsKnown() || state.IsNull() || !state.Type().IsObjectType() {
+		return false
+	}
+
+	if len(want) == 0 {
+		for _, name := range importIdentityAttributes {
+			if !state.Type().HasAttribute(name) {
+				continue
+			}
+			if v := state.GetAttr(name); v.IsKnown() && !v.IsNull() && v.Type() == cty.String && v.AsString() == importId {
+				return true
+			}
+		}
+		return false
+	}
+
+	compared := 0
+	for name, v := range want {
+		if !state.Type().HasAttribute(name) {
+			continue
+		}
+		got := state.GetAttr(name)
+		if !got.IsKnown() || got.IsNull() || !got.RawEquals(v) {
+			return false
+		}
+		compared++
+	}
+	return compared != 0
+}
+
+// describeImportedResource describes an object returned by an import by its
+// type and its first identity attribute that is set.
+func describeImportedResource(obj providers.ImportedResource) string {
+	if obj.State == cty.NilVal {
+		return obj.TypeName
+	}
+	if state, _ := obj.State.UnmarkDeep(); state.IsKnown() && !state.IsNull() && state.Type().IsObjectType() {
+		for _, name := range importIdentityAttributes {
+			if !state.Type().HasAttribute(name) {
+				continue
+			}
+			if v := state.GetAttr(name); v.IsKnown() && !v.IsNull() && v.Type() == cty.String {
+				return fmt.Sprintf("%s with %s %q", obj.TypeName, name, v.AsString())
+			}
+		}
+	}
+	return obj.TypeName
+}
+
+// isAmbiguousImport returns whether the given diagnostics report that an
+// import returned several objects that selectImportedResource couldn't pick
+// from.
+func isAmbiguousImport(diags tfdiags.Diagnostics) bool {
+	for _, diag := range diags {
+		if tfdiags.DiagnosticCode(diag) == tfdiags.CodeAmbiguousImport {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@
 package farseek
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -11,6 +12,8 @@ import (
 	"github.com/hashicorp/hcl/v2/hcltest"
 	"github.com/rafagsiqueira/farseek/internal/lang"
 	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -74,3 +77,96 @@ func TestEvaluateImportIdExpression_SensitiveValue(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectImportedResource(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	object := func(typeName, id, name string) providers.ImportedResource {
+		return providers.ImportedResource{
+			TypeName: typeName,
+			State: cty.ObjectVal(map[string]cty.Value{
+				"id":   cty.StringVal(id),
+				"name": cty.StringVal(name),
+			}),
+		}
+	}
+	config := func(name string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"id":   cty.NullVal(cty.String),
+			"name": cty.StringVal(name).Mark(marks.Sensitive),
+		})
+	}
+
+	testCases := []struct {
+		name     string
+		importId string
+		imported []providers.ImportedResource
+		config   cty.Value
+		wantID   string
+		wantErr  string
+	}{
+		{
+			name:     "single",
+			importId: "web",
+			imported: []providers.ImportedResource{object("test_object", "1", "web")},
+			wantID:   "1",
+		},
+		{
+			name:     "by_type",
+			importId: "web",
+			imported: []providers.ImportedResource{object("test_rule", "2", "web"), object("test_object", "1", "web")},
+			wantID:   "1",
+		},
+		{
+			name:     "by_configured_name",
+			importId: "web",
+			imported: []providers.ImportedResource{object("test_object", "1", "web-old"), object("test_object", "2", "web")},
+			config:   config("web"),
+			wantID:   "2",
+		},
+		{
+			name:     "by_import_id",
+			importId: "2",
+			imported: []providers.ImportedResource{object("test_object", "1", "web"), object("test_object", "2", "web")},
+			config:   cty.NilVal,
+			wantID:   "2",
+		},
+		{
+			name:     "ambiguous",
+			importId: "web",
+			imported: []providers.ImportedResource{object("test_object", "1", "web"), object("test_object", "2", "web")},
+			config:   config("web"),
+			wantErr:  "Ambiguous import result: While attempting to import test_object.a with ID \"web\", the provider returned 2 objects, and 2 of them match the configuration, so Farseek can't tell which one to import:\n\n  - test_object with id \"1\"\n  - test_object with id \"2\"\n\nSet the attributes that identify the object, such as its name, in the configuration of the resource, or import it with an ID that only matches one object.",
+		},
+		{
+			name:     "no_match",
+			importId: "web",
+			imported: []providers.ImportedResource{object("test_object", "1", "a"), object("test_object", "2", "b")},
+			config:   config("web"),
+			wantErr:  "none of them matches the configuration",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, diags := selectImportedResource(addr, tc.importId, tc.imported, tc.config)
+			if tc.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatalf("expected an error, got %#v", got)
+				}
+				if !strings.Contains(diags.Err().Error(), tc.wantErr) {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", diags.Err(), tc.wantErr)
+				}
+				if !isAmbiguousImport(diags) {
+					t.Errorf("error has no %s code", tfdiags.CodeAmbiguousImport)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+			if id := got.State.GetAttr("id").AsString(); id != tc.wantID {
+				t.Errorf("selected object %q, want %q", id, tc.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFilename is the name of the file, in a configuration root, that
+// lists the files and resources that discovery must skip.
+//
+// Each line is a pattern, using the syntax of .gitignore files. A pattern
+// matches the configuration files it would match in a .gitignore file, and
+// also the resource addresses that it matches as a glob, where "*" matches
+// any sequence of characters. A pattern starting with "!" includes again
+// what an earlier pattern ignored.
+const IgnoreFilename = ".farseekignore"
+
+// IgnoreRules are the patterns read from an ignore file.
+type IgnoreRules struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	path    *regexp.Regexp
+	// addr is nil for patterns that can only match paths.
+	addr *regexp.Regexp
+}
+
+// IgnoredResource is a discovered resource that an ignore rule skipped.
+type IgnoredResource struct {
+	DiscoveredResource
+
+	// Pattern is the pattern that matched the resource.
+	Pattern string
+}
+
+// LoadIgnoreRules reads the ignore file in the configuration root in dir. It
+// returns nil rules if there is no ignore file.
+func LoadIgnoreRules(dir string) (*IgnoreRules, error) {
+	src, err := os.ReadFile(filepath.Join(dir, IgnoreFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseIgnoreRules(src)
+}
+
+// ParseIgnoreRules parses the contents of an ignore file.
+func ParseIgnoreRules(src []byte) (*IgnoreRules, error) {
+	ret := &IgnoreRules{}
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	for line := 1; sc.Scan(); line++ {
+		pattern := strings.TrimRight(sc.Text(), " \t\r")
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		rule := ignoreRule{pattern: pattern}
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\`) {
+			pattern = pattern[1:]
+		}
+		if pattern == "" || pattern == "/" {
+			return nil, fmt.Errorf("line %d: empty pattern", line)
+		}
+
+		rule.path = pathPatternRegexp(pattern)
+		if !strings.Contains(pattern, "/") {
+			rule.addr = addrPatternRegexp(pattern)
+		}
+		ret.rules = append(ret.rules, rule)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Match returns true if the given resource is ignored, along with the last
+// pattern that matched it.
+func (r *IgnoreRules) Match(dr DiscoveredResource) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	filename := filepath.ToSlash(dr.Filename)
+	var pattern string
+	var ignored bool
+	for _, rule := range r.rules {
+		if (filename != "" && rule.path.MatchString(filename)) || (rule.addr != nil && rule.addr.MatchString(dr.Address)) {
+			pattern, ignored = rule.pattern, !rule.negate
+		}
+	}
+	return pattern, ignored
+}
+
+// MatchPath returns true if the given slash-separated path, relative to the
+// directory of the ignore file, is ignored. Only the .gitignore meaning of
+// the patterns applies, so that the rules can also select the files of a
+// module package. A directory path must end with a slash.
+func (r *IgnoreRules) MatchPath(filename string) bool {
+	if r == nil {
+		return false
+	}
+	var ignored bool
+	for _, rule := range r.rules {
+		if rule.path.MatchString(filename) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// Filter splits the given discovered resources into those that the rules
+// keep and those that they ignore.
+func (r *IgnoreRules) Filter(resources []DiscoveredResource) ([]DiscoveredResource, []IgnoredResource) {
+	if r == nil {
+		return resources, nil
+	}
+	var kept []DiscoveredResource
+	var ignored []IgnoredResource
+	for _, dr := range resources {
+		if pattern, ok := r.Match(dr); ok {
+			ignored = append(ignored, IgnoredResource{DiscoveredResource: dr, Pattern: pattern})
+			continue
+		}
+		kept = append(kept, dr)
+	}
+	return kept, ignored
+}
+
+// pathPatternRegexp returns a regular expression matching the slash-separated
+// paths that the given .gitignore pattern matches, including the paths of
+// files in the directories that it matches.
+func pathPatternRegexp(pattern string) *regexp.Regexp {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	// As in .gitignore, a pattern with a slash other than at its end is
+	// relative to the root, and any other pattern can match at any depth.
+	var buf strings.Builder
+	buf.WriteString("^")
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		buf.WriteString("(.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			buf.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			buf.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			buf.WriteString("[^/]*")
+		case pattern[i] == '?':
+			buf.WriteString("[^/]")
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	if dirOnly {
+		buf.WriteString("/.*$")
+	} else {
+		buf.WriteString("(/.*)?$")
+	}
+	return regexp.MustCompile(buf.String())
+}
+
+// addrPatternRegexp returns a regular expression matching the resource
+// addresses that the given glob matches.
+func addrPatternRegexp(pattern string) *regexp.Regexp {
+	var buf strings.Builder
+	buf.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			buf.WriteString(".*")
+		case '?':
+			buf.WriteString(".")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.MustCompile(buf.String())
+}
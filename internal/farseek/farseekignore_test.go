@@ -0,0 +1,94 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := ParseIgnoreRules([]byte(`
+# Generated files
+generated_*.tf
+/legacy/
+vendor/**/main.tf
+
+# Resources
+aws_iam_*.bootstrap
+data.*
+!data.aws_caller_identity.current
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		resource DiscoveredResource
+		want     string
+	}{
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "main.tf"}, ""},
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "generated_web.tf"}, "generated_*.tf"},
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "nested/generated_web.tf"}, "generated_*.tf"},
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "legacy/main.tf"}, "/legacy/"},
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "nested/legacy/main.tf"}, ""},
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "vendor/main.tf"}, "vendor/**/main.tf"},
+		{DiscoveredResource{Address: "aws_instance.web", Filename: "vendor/a/b/main.tf"}, "vendor/**/main.tf"},
+		{DiscoveredResource{Address: "aws_iam_role.bootstrap", Filename: "main.tf"}, "aws_iam_*.bootstrap"},
+		{DiscoveredResource{Address: "aws_iam_role.app", Filename: "main.tf"}, ""},
+		{DiscoveredResource{Address: "data.aws_ami.ubuntu", Filename: "main.tf"}, "data.*"},
+		{DiscoveredResource{Address: "data.aws_caller_identity.current", Filename: "main.tf"}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.resource.Filename+":"+test.resource.Address, func(t *testing.T) {
+			pattern, ignored := rules.Match(test.resource)
+			if ignored != (test.want != "") {
+				t.Fatalf("wrong result %t for pattern %q; want %q", ignored, pattern, test.want)
+			}
+			if ignored && pattern != test.want {
+				t.Errorf("wrong pattern %q; want %q", pattern, test.want)
+			}
+		})
+	}
+
+	kept, skipped := rules.Filter([]DiscoveredResource{
+		{Address: "aws_instance.web", Filename: "main.tf"},
+		{Address: "aws_iam_role.bootstrap", Filename: "main.tf"},
+	})
+	if len(kept) != 1 || kept[0].Address != "aws_instance.web" {
+		t.Errorf("wrong kept resources %v", kept)
+	}
+	if len(skipped) != 1 || skipped[0].Address != "aws_iam_role.bootstrap" || skipped[0].Pattern != "aws_iam_*.bootstrap" {
+		t.Errorf("wrong ignored resources %v", skipped)
+	}
+}
+
+func TestIgnoreRules_MatchPath(t *testing.T) {
+	rules, err := ParseIgnoreRules([]byte(`
+*.tfvars
+/examples/
+!examples.tfvars
+data.*
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"main.tf":             false,
+		"prod.tfvars":         true,
+		"nested/prod.tfvars":  true,
+		"examples.tfvars":     false,
+		"examples/":           true,
+		"examples/main.tf":    true,
+		"nested/examples/":    false,
+		"data.tf":             true,
+		"nested/data.tf":      true,
+		"nested/data/main.tf": false,
+	}
+	for filename, want := range tests {
+		if got := rules.MatchPath(filename); got != want {
+			t.Errorf("wrong result for %q: got %t, want %t", filename, got, want)
+		}
+	}
+}
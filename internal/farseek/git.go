@@ -4,24 +4,39 @@
 package farseek
 
 import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/zclconf/go-cty/cty"
 )
 
 // ResourceDiscoverer is an interface for finding which resources have changed.
 type ResourceDiscoverer interface {
 	DiscoverChangedResources(dir, baseSHA string, includeUncommitted bool) ([]DiscoveredResource, error)
+	DiscoverChangedResourcesBetween(dir, fromSHA, toSHA string) ([]DiscoveredResource, error)
+	ExportConfigAtSHA(dir, sha, dest string) error
 	DiscoverAllResources(dir string, includeUncommitted bool) ([]DiscoveredResource, error)
 	GetResourceAttributeFromSHA(dir, sha, filename, address, attribute string) (string, error)
+	GetResourceDependenciesFromSHA(dir, sha string) (map[string][]addrs.ConfigResource, error)
+	GetResourcesFromSHA(dir, sha string) (map[string]*configs.Resource, error)
 	GetCurrentSHA(dir string) (string, error)
+	GetCurrentBranch(dir string) (string, error)
+	VerifyCommits(dir, fromSHA, toSHA string, trustedKeys []string) ([]UnverifiedCommit, error)
 }
 
 type DiscoveredResource struct {
@@ -29,6 +44,10 @@ type DiscoveredResource struct {
 	Filename string
 	Config   hcl.Body // Might be nil if only in old state or if we couldn't parse it
 	IsNew    bool     // True if not in base search (e.g. not in Git history)
+
+	// Commit is the last commit in the discovered range that changed the
+	// file the resource is in, or nil if only uncommitted changes did.
+	Commit *plans.Commit
 }
 
 // GitDiscoverer implements ResourceDiscoverer using Git.
@@ -50,10 +69,12 @@ func (g GitDiscoverer) DiscoverChangedResources(dir, baseSHA string, includeUnco
 		return nil, err
 	}
 
-	// Map to verify which resources are in changed files
-	isChangedFile := make(map[string]bool)
+	// Map to verify which resources are in changed files, to the names git
+	// has for them, which can differ in case from the names in the working
+	// tree on case-insensitive filesystems.
+	changedFiles := make(map[string]string)
 	for _, f := range files {
-		isChangedFile[f] = true
+		changedFiles[pathKey(f)] = f
 	}
 
 	// Load historical resources if baseSHA is present
@@ -88,11 +109,11 @@ func (g GitDiscoverer) DiscoverChangedResources(dir, baseSHA string, includeUnco
 
 		processResource := func(addr string, body hcl.Body) {
 			currentAddresses[addr] = true
-			if isChangedFile[f] {
+			if gitName, changed := changedFiles[pathKey(f)]; changed {
 				_, existed := historicalResources[addr]
 				results = append(results, DiscoveredResource{
 					Address:  addr,
-					Filename: f,
+					Filename: gitName,
 					Config:   body,
 					IsNew:    !existed,
 				})
@@ -136,9 +157,123 @@ func (g GitDiscoverer) DiscoverChangedResources(dir, baseSHA string, includeUnco
 		}
 	}
 
+	g.attachCommits(dir, baseSHA, "HEAD", results)
+	return results, nil
+}
+
+// DiscoverChangedResourcesBetween is like DiscoverChangedResources, but it
+// compares the configuration at toSHA with the configuration at fromSHA,
+// instead of the current configuration with the configuration at the base
+// SHA. If fromSHA is empty, all of the resources at toSHA are new.
+func (g GitDiscoverer) DiscoverChangedResourcesBetween(dir, fromSHA, toSHA string) ([]DiscoveredResource, error) {
+	current, err := g.discoverRootResourcesAtSHA(dir, toSHA)
+	if err != nil {
+		return nil, err
+	}
+	if fromSHA == "" {
+		for i := range current {
+			current[i].IsNew = true
+		}
+		g.attachCommits(dir, "", toSHA, current)
+		return current, nil
+	}
+
+	files, err := g.diffFiles(dir, fromSHA, toSHA)
+	if err != nil {
+		return nil, err
+	}
+	isChangedFile := make(map[string]bool)
+	for _, f := range files {
+		isChangedFile[pathKey(f)] = true
+	}
+
+	historical, err := g.discoverRootResourcesAtSHA(dir, fromSHA)
+	if err != nil {
+		return nil, err
+	}
+	existed := make(map[string]bool)
+	for _, dr := range historical {
+		existed[dr.Address] = true
+	}
+
+	var results []DiscoveredResource
+	currentAddresses := make(map[string]bool)
+	for _, dr := range current {
+		currentAddresses[dr.Address] = true
+		if isChangedFile[pathKey(dr.Filename)] {
+			dr.IsNew = !existed[dr.Address]
+			results = append(results, dr)
+		}
+	}
+	for _, dr := range historical {
+		if !currentAddresses[dr.Address] {
+			results = append(results, dr)
+		}
+	}
+	g.attachCommits(dir, fromSHA, toSHA, results)
 	return results, nil
 }
 
+// discoverRootResourcesAtSHA is like discoverAllResourcesAtSHA, but only
+// returns the resources of the root module, which are those in files
+// directly in dir.
+func (g GitDiscoverer) discoverRootResourcesAtSHA(dir, sha string) ([]DiscoveredResource, error) {
+	all, err := g.discoverAllResourcesAtSHA(dir, sha)
+	if err != nil {
+		return nil, err
+	}
+	var ret []DiscoveredResource
+	for _, dr := range all {
+		if !strings.Contains(dr.Filename, "/") {
+			ret = append(ret, dr)
+		}
+	}
+	return ret, nil
+}
+
+// ExportConfigAtSHA writes the files under dir, as they were at the given
+// SHA, to the directory dest.
+func (g GitDiscoverer) ExportConfigAtSHA(dir, sha, dest string) error {
+	cmd := exec.Command("git", "archive", "--format=tar", sha+":./")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(bytes.NewReader(out))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dest, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dest)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path %q in archive", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, data, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (g GitDiscoverer) DiscoverAllResources(dir string, includeUncommitted bool) ([]DiscoveredResource, error) {
 	if includeUncommitted {
 		return g.discoverAllResourcesInWorkingDir(dir)
@@ -185,22 +320,14 @@ func (g GitDiscoverer) discoverAllResourcesInWorkingDir(dir string) ([]Discovere
 }
 
 func (g GitDiscoverer) discoverAllResourcesAtSHA(dir, sha string) ([]DiscoveredResource, error) {
-	// List all files at SHA
-	cmd := exec.Command("git", "ls-tree", "-r", "--name-only", sha)
-	cmd.Dir = dir
-	out, err := cmd.Output()
+	files, err := g.getTfFilesAtSHA(dir, sha)
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	var results []DiscoveredResource
 
-	for _, f := range lines {
-		if f == "" || !(strings.HasSuffix(f, ".tf") || strings.HasSuffix(f, ".tf.json")) {
-			continue
-		}
-
+	for _, f := range files {
 		content, err := g.getFileContentAtSHA(dir, sha, f)
 		if err != nil {
 			continue
@@ -240,16 +367,34 @@ func (g GitDiscoverer) discoverAllResourcesAtSHA(dir, sha string) ([]DiscoveredR
 	return results, nil
 }
 
+// getTfFilesAtSHA lists the configuration files under dir at a specific SHA.
+func (g GitDiscoverer) getTfFilesAtSHA(dir, sha string) ([]string, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", "-z", "--name-only", sha)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range splitGitPaths(out) {
+		if isConfigFilename(f) {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
 func (g GitDiscoverer) getAllTfFiles(dir string) ([]string, error) {
 	var files []string
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && (strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json")) {
+		if !info.IsDir() && isConfigFilename(path) {
 			rel, err := filepath.Rel(dir, path)
 			if err == nil {
-				files = append(files, rel)
+				files = append(files, gitPath(rel))
 			}
 		}
 		// Don't descend into subdirectories for now, Tofu usually handles them separately
@@ -263,17 +408,25 @@ func (g GitDiscoverer) getAllTfFiles(dir string) ([]string, error) {
 }
 
 func (g GitDiscoverer) getFileContentAtSHA(dir, sha, path string) ([]byte, error) {
-	// Using ./path with git show ensures it's relative to the current directory
-	// even if we are not at the repo root.
-	cmd := exec.Command("git", "show", sha+":./"+path)
+	cmd := exec.Command("git", "show", gitRevPath(sha, path))
 	cmd.Dir = dir
 	return cmd.Output()
 }
 
 func (g GitDiscoverer) getChangedFiles(dir, baseSHA string, includeUncommitted bool) ([]string, error) {
-	args := []string{"diff", "--name-only", "--relative", baseSHA}
-	if !includeUncommitted {
-		args = append(args, "HEAD")
+	if includeUncommitted {
+		return g.diffFiles(dir, baseSHA, "")
+	}
+	return g.diffFiles(dir, baseSHA, "HEAD")
+}
+
+// diffFiles lists the configuration files under dir that differ between
+// fromSHA and toSHA, or between fromSHA and the working tree if toSHA is
+// empty.
+func (g GitDiscoverer) diffFiles(dir, fromSHA, toSHA string) ([]string, error) {
+	args := []string{"diff", "--name-only", "-z", "--relative", fromSHA}
+	if toSHA != "" {
+		args = append(args, toSHA)
 	}
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
@@ -282,16 +435,67 @@ func (g GitDiscoverer) getChangedFiles(dir, baseSHA string, includeUncommitted b
 		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	var files []string
-	for _, line := range lines {
-		if line != "" && (strings.HasSuffix(line, ".tf") || strings.HasSuffix(line, ".tf.json")) {
-			files = append(files, line)
+	for _, f := range splitGitPaths(out) {
+		if isConfigFilename(f) {
+			files = append(files, f)
 		}
 	}
 	return files, nil
 }
 
+// attachCommits sets the commit of each of the given resources to the last
+// commit after fromSHA, up to toSHA, that changed its file. If fromSHA is
+// empty, it is the last commit up to toSHA.
+func (g GitDiscoverer) attachCommits(dir, fromSHA, toSHA string, resources []DiscoveredResource) {
+	revs := toSHA
+	if fromSHA != "" {
+		revs = fromSHA + ".." + toSHA
+	}
+	commits := make(map[string]*plans.Commit)
+	for i, dr := range resources {
+		commit, ok := commits[dr.Filename]
+		if !ok {
+			var err error
+			commit, err = g.lastCommit(dir, revs, dr.Filename)
+			if err != nil {
+				log.Printf("[WARN] Farseek: failed to find the last commit changing %s: %s", dr.Filename, err)
+			}
+			commits[dr.Filename] = commit
+		}
+		resources[i].Commit = commit
+	}
+}
+
+// lastCommit returns the last commit in the given revision range that
+// changed the given file, or nil if there is none.
+func (g GitDiscoverer) lastCommit(dir, revs, filename string) (*plans.Commit, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%H%x00%an%x00%at%x00%s", revs, "--", filename)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	line := strings.TrimSpace(string(out))
+	if line == "" {
+		return nil, nil
+	}
+	fields := strings.SplitN(line, "\x00", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected output %q from git log", line)
+	}
+	secs, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit timestamp %q: %w", fields[2], err)
+	}
+	return &plans.Commit{
+		SHA:     fields[0],
+		Author:  fields[1],
+		Time:    time.Unix(secs, 0).UTC(),
+		Message: fields[3],
+	}, nil
+}
+
 func (g GitDiscoverer) GetCurrentSHA(dir string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "HEAD")
 	cmd.Dir = dir
@@ -302,6 +506,42 @@ func (g GitDiscoverer) GetCurrentSHA(dir string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// RepositoryRoot returns the top-level directory of the Git working tree
+// that contains dir, or an empty string if dir isn't in one.
+func RepositoryRoot(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		// rev-parse exits with an error status outside of a working tree,
+		// and there's no working tree to find without git at all.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) || errors.Is(err, exec.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetCurrentBranch returns the name of the branch that HEAD is on, or an
+// empty string if HEAD is detached.
+func (g GitDiscoverer) GetCurrentBranch(dir string) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		// symbolic-ref exits with 1, and no other error, if HEAD is
+		// detached.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // GetResourceAttributeFromSHA extracts a specific attribute (e.g. "name") from a resource block
 // in a file at a specific SHA. It uses best-effort parsing.
 func (g GitDiscoverer) GetResourceAttributeFromSHA(dir, sha, filename, address, attribute string) (string, error) {
@@ -373,5 +613,70 @@ func (g GitDiscoverer) GetResourceAttributeFromSHA(dir, sha, filename, address,
 	return "", nil
 }
 
+// GetResourceDependenciesFromSHA reconstructs the dependencies between the
+// resources of the root module as it was configured at a specific SHA, keyed
+// by resource address. Stateless operations have no dependencies recorded in
+// state, so these are used instead to order destroys.
+func (g GitDiscoverer) GetResourceDependenciesFromSHA(dir, sha string) (map[string][]addrs.ConfigResource, error) {
+	mod, err := g.rootModuleAtSHA(dir, sha)
+	if err != nil {
+		return nil, err
+	}
+	return ResourceDependencies(mod), nil
+}
+
+// GetResourcesFromSHA returns the managed resources of the root module as it
+// was configured at a specific SHA, keyed by resource address. Stateless
+// operations use them to recover what they know of resources that have since
+// been removed from the configuration.
+func (g GitDiscoverer) GetResourcesFromSHA(dir, sha string) (map[string]*configs.Resource, error) {
+	mod, err := g.rootModuleAtSHA(dir, sha)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[string]*configs.Resource, len(mod.ManagedResources))
+	for _, rc := range mod.ManagedResources {
+		ret[rc.Addr().String()] = rc
+	}
+	return ret, nil
+}
+
+// rootModuleAtSHA loads the root module in dir as it was at a specific SHA,
+// on a best-effort basis.
+func (g GitDiscoverer) rootModuleAtSHA(dir, sha string) (*configs.Module, error) {
+	files, err := g.getTfFilesAtSHA(dir, sha)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := configs.NewParser(nil)
+	var primary, override []*configs.File
+	for _, f := range files {
+		// Files in subdirectories belong to other modules.
+		if strings.Contains(f, "/") {
+			continue
+		}
+		content, err := g.getFileContentAtSHA(dir, sha, f)
+		if err != nil {
+			return nil, err
+		}
+		file, diags := parser.LoadConfigFileFromSource(content, f)
+		if diags.HasErrors() {
+			log.Printf("[WARN] Farseek: Skipping %s at %s: %s", f, sha, diags.Error())
+			continue
+		}
+		if base := strings.TrimSuffix(strings.TrimSuffix(f, ".json"), ".tf"); base == "override" || strings.HasSuffix(base, "_override") {
+			override = append(override, file)
+		} else {
+			primary = append(primary, file)
+		}
+	}
+
+	// The historical files are only analyzed, so problems such as
+	// duplicate declarations don't prevent recovering what we can.
+	mod, _ := configs.NewModuleUneval(primary, override, dir, configs.SelectiveLoadAll)
+	return mod, nil
+}
+
 // Global discoverer that can be overridden in tests.
 var Discovery ResourceDiscoverer = GitDiscoverer{}
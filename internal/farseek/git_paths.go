@@ -0,0 +1,69 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// The paths of the files that GitDiscoverer discovers are relative to the
+// configuration directory and always use forward slashes, as git does on
+// every platform, so that the same file has the same name whether it was
+// found in the working tree or in git's history.
+
+// pathsFoldCase is true on the platforms whose filesystems are usually case
+// insensitive, where git's name for a file can differ in case from the name
+// the filesystem reports for it, such as after a rename that only changed
+// the case.
+var pathsFoldCase = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// gitPath returns the given path, relative to the configuration directory,
+// in the form git uses: cleaned and separated by forward slashes.
+func gitPath(p string) string {
+	return path.Clean(filepath.ToSlash(p))
+}
+
+// gitRevPath returns the git revision syntax for the file at the given path,
+// relative to the directory that git runs in, as it was at the given commit.
+func gitRevPath(sha, p string) string {
+	// The "./" makes git resolve the path relative to the directory it runs
+	// in, rather than to the root of the repository.
+	return sha + ":./" + gitPath(p)
+}
+
+// pathKey returns the key that identifies the file at the given path when
+// comparing paths from git and from the filesystem, or two paths on the
+// filesystem, which ignores case on platforms whose filesystems are usually
+// case insensitive. This also makes Windows drive letters compare equal
+// whatever their case.
+func pathKey(p string) string {
+	p = gitPath(p)
+	if pathsFoldCase {
+		return strings.ToLower(p)
+	}
+	return p
+}
+
+// splitGitPaths returns the paths in the output of a git command run with
+// the -z option, which separates them with NUL bytes and doesn't quote
+// them, so that names with spaces or non-ASCII characters come through
+// unchanged on every platform.
+func splitGitPaths(out []byte) []string {
+	var ret []string
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p != "" {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// isConfigFilename returns true if the file with the given name is a
+// configuration file that GitDiscoverer discovers resources in.
+func isConfigFilename(name string) bool {
+	return strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")
+}
@@ -8,6 +8,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/plans"
 )
 
 func TestGitDiscoverer_DiscoverChangedResources(t *testing.T) {
@@ -100,6 +105,353 @@ func TestGitDiscoverer_DiscoverChangedResources(t *testing.T) {
 	}
 }
 
+func TestGitDiscoverer_GetResourceDependenciesFromSHA(t *testing.T) {
+	dir := t.TempDir()
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+
+	config := `
+resource "test_vpc" "main" {}
+
+resource "test_subnet" "a" {
+  vpc_id = test_vpc.main.id
+}
+
+locals {
+  subnet_ids = [test_subnet.a.id]
+}
+
+resource "test_instance" "web" {
+  subnet_id = local.subnet_ids[0]
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "main.tf")
+	runGit(t, dir, "commit", "-m", "Initial commit")
+	baseSHA := getHeadSHA(t, dir)
+
+	// Dependencies come from the historical configuration, even once the
+	// resources have been removed from it.
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "commit", "-am", "Remove everything")
+
+	deps, err := GitDiscoverer{}.GetResourceDependenciesFromSHA(dir, baseSHA)
+	if err != nil {
+		t.Fatalf("GetResourceDependenciesFromSHA failed: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for addr, addrDeps := range deps {
+		for _, dep := range addrDeps {
+			got[addr] = append(got[addr], dep.String())
+		}
+	}
+	want := map[string][]string{
+		"test_subnet.a":     {"test_vpc.main"},
+		"test_instance.web": {"test_subnet.a"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong dependencies\n%s", diff)
+	}
+}
+
+func TestGitDiscoverer_GetResourcesFromSHA(t *testing.T) {
+	dir := t.TempDir()
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+
+	config := `
+resource "test_instance" "web" {
+  ami = "ami-123"
+
+  provisioner "local-exec" {
+    when    = destroy
+    command = "echo ${self.ami}"
+  }
+}
+
+data "test_data_source" "foo" {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "main.tf")
+	runGit(t, dir, "commit", "-m", "Initial commit")
+	baseSHA := getHeadSHA(t, dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "commit", "-am", "Remove everything")
+
+	resources, err := GitDiscoverer{}.GetResourcesFromSHA(dir, baseSHA)
+	if err != nil {
+		t.Fatalf("GetResourcesFromSHA failed: %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("expected only the managed resource, got %d resources", len(resources))
+	}
+	rc := resources["test_instance.web"]
+	if rc == nil {
+		t.Fatal("test_instance.web is missing")
+	}
+	if got := len(rc.Managed.Provisioners); got != 1 {
+		t.Fatalf("expected 1 provisioner, got %d", got)
+	}
+	if got := rc.Managed.Provisioners[0].When; got != configs.ProvisionerWhenDestroy {
+		t.Errorf("wrong provisioner when %s", got)
+	}
+}
+
+func TestGitDiscoverer_DiscoverChangedResourcesBetween(t *testing.T) {
+	dir := t.TempDir()
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("main.tf", `resource "test_instance" "foo" {}`)
+	writeFile("old.tf", `resource "test_instance" "old" {}`)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "Initial commit")
+	fromSHA := getHeadSHA(t, dir)
+
+	writeFile("main.tf", `resource "test_instance" "foo" { count = 2 }`)
+	writeFile("new.tf", `resource "test_instance" "new" {}`)
+	runGit(t, dir, "rm", "-q", "old.tf")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "Release")
+	toSHA := getHeadSHA(t, dir)
+
+	// Changes after toSHA, and in the working tree, don't count.
+	writeFile("later.tf", `resource "test_instance" "later" {}`)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "Later")
+	writeFile("uncommitted.tf", `resource "test_instance" "uncommitted" {}`)
+
+	g := GitDiscoverer{}
+	resources, err := g.DiscoverChangedResourcesBetween(dir, fromSHA, toSHA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	for _, dr := range resources {
+		got[dr.Address] = dr.IsNew
+	}
+	want := map[string]bool{
+		"test_instance.foo": false,
+		"test_instance.new": true,
+		"test_instance.old": false,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong resources\n%s", diff)
+	}
+
+	// The configuration at toSHA can be exported for planning.
+	dest := t.TempDir()
+	if err := g.ExportConfigAtSHA(dir, toSHA, dest); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dest, "main.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(content), `resource "test_instance" "foo" { count = 2 }`; got != want {
+		t.Errorf("wrong exported main.tf %q; want %q", got, want)
+	}
+	for _, name := range []string{"old.tf", "later.tf", "uncommitted.tf"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was exported", name)
+		}
+	}
+}
+
+func TestGitDiscoverer_commits(t *testing.T) {
+	dir := t.TempDir()
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("main.tf", `resource "test_instance" "foo" {}`)
+	writeFile("other.tf", `resource "test_instance" "bar" {}`)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "Initial commit")
+	baseSHA := getHeadSHA(t, dir)
+
+	writeFile("main.tf", `resource "test_instance" "foo" { count = 2 }`)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "Scale foo\n\nTwo are better than one.")
+	scaleSHA := getHeadSHA(t, dir)
+	writeFile("other.tf", `resource "test_instance" "bar" { ami = "new" }`)
+
+	g := GitDiscoverer{}
+	resources, err := g.DiscoverChangedResources(dir, baseSHA, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]*plans.Commit)
+	for _, dr := range resources {
+		got[dr.Address] = dr.Commit
+	}
+	if len(got) != 2 {
+		t.Fatalf("wrong resources %v", got)
+	}
+
+	commit := got["test_instance.foo"]
+	if commit == nil {
+		t.Fatal("no commit for test_instance.foo")
+	}
+	if commit.SHA != scaleSHA || commit.Author != "Your Name" || commit.Message != "Scale foo" || commit.Time.IsZero() {
+		t.Errorf("wrong commit %#v", commit)
+	}
+
+	// Only uncommitted changes changed test_instance.bar since the base SHA.
+	if commit := got["test_instance.bar"]; commit != nil {
+		t.Errorf("unexpected commit %#v for test_instance.bar", commit)
+	}
+}
+
+func TestGitDiscoverer_GetCurrentBranch(t *testing.T) {
+	dir := t.TempDir()
+
+	runGit(t, dir, "init", "--initial-branch=main")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "Initial commit")
+
+	g := GitDiscoverer{}
+	for _, test := range []struct {
+		checkout []string
+		want     string
+	}{
+		{nil, "main"},
+		{[]string{"checkout", "-q", "-b", "feature/x"}, "feature/x"},
+		{[]string{"checkout", "-q", "--detach"}, ""},
+	} {
+		if test.checkout != nil {
+			runGit(t, dir, test.checkout...)
+		}
+		got, err := g.GetCurrentBranch(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("wrong branch %q; want %q", got, test.want)
+		}
+	}
+
+	if _, err := g.GetCurrentBranch(t.TempDir()); err == nil {
+		t.Error("expected an error outside of a git repository")
+	}
+}
+
+func TestGitDiscoverer_unusualFilenames(t *testing.T) {
+	dir := t.TempDir()
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+	// Git quotes names like these in its output unless it's run with -z,
+	// whatever this setting says.
+	runGit(t, dir, "config", "core.quotePath", "true")
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("café.tf", `resource "test_instance" "cafe" {}`)
+	writeFile("my network.tf", "resource \"test_instance\" \"network\" {\r\n  name = \"net\"\r\n}\r\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "Initial commit")
+	baseSHA := getHeadSHA(t, dir)
+
+	writeFile("café.tf", `resource "test_instance" "cafe" { count = 2 }`)
+	writeFile("my network.tf", "resource \"test_instance\" \"network\" {\r\n  name = \"net2\"\r\n}\r\n")
+
+	g := GitDiscoverer{}
+	resources, err := g.DiscoverChangedResources(dir, baseSHA, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, dr := range resources {
+		got[dr.Address] = dr.Filename
+	}
+	want := map[string]string{
+		"test_instance.cafe":    "café.tf",
+		"test_instance.network": "my network.tf",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong resources\n%s", diff)
+	}
+
+	// Files with CRLF line endings in history can be read too.
+	name, err := g.GetResourceAttributeFromSHA(dir, baseSHA, "my network.tf", "test_instance.network", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "net" {
+		t.Errorf("wrong name %q; want %q", name, "net")
+	}
+}
+
+func TestPathKey(t *testing.T) {
+	defer func(prev bool) { pathsFoldCase = prev }(pathsFoldCase)
+
+	tests := []struct {
+		a, b     string
+		foldCase bool
+		same     bool
+	}{
+		{"main.tf", "main.tf", false, true},
+		{"main.tf", "./main.tf", false, true},
+		{"modules/net/main.tf", filepath.Join("modules", "net", "main.tf"), false, true},
+		{"Main.tf", "main.tf", false, false},
+		{"Main.tf", "main.tf", true, true},
+		{"C:/Repo/infra", "c:/repo/infra", true, true},
+	}
+	for _, test := range tests {
+		pathsFoldCase = test.foldCase
+		if got := pathKey(test.a) == pathKey(test.b); got != test.same {
+			t.Errorf("pathKey(%q) == pathKey(%q) with foldCase %t is %t; want %t", test.a, test.b, test.foldCase, got, test.same)
+		}
+	}
+}
+
+func TestSplitGitPaths(t *testing.T) {
+	got := splitGitPaths([]byte("main.tf\x00my network.tf\x00caf\xc3\xa9.tf\x00"))
+	want := []string{"main.tf", "my network.tf", "café.tf"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong paths\n%s", diff)
+	}
+	if got := splitGitPaths(nil); len(got) != 0 {
+		t.Errorf("wrong paths for no output %q", got)
+	}
+}
+
 func runGit(t *testing.T, dir string, args ...string) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = dir
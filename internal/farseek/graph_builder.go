@@ -64,6 +64,7 @@ func (b *BasicGraphBuilder) Build(ctx context.Context, path addrs.ModuleInstance
 
 	if err := g.Validate(); err != nil {
 		log.Printf("[ERROR] Graph validation failed. Graph:\n\n%s", g.String())
+		diags = diags.Append(providerCycleDiagnostics(g.Cycles()))
 		diags = diags.Append(err)
 		return nil, diags
 	}
@@ -45,6 +45,10 @@ type EvalGraphBuilder struct {
 	Plugins *contextPlugins
 
 	ProviderFunctionTracker ProviderFunctionMapping
+
+	// ConcreteProvider, if set, makes the nodes of the providers, which
+	// otherwise only start up the providers without configuring them.
+	ConcreteProvider ConcreteProviderNodeFunc
 }
 
 // See GraphBuilder
@@ -57,9 +61,12 @@ func (b *EvalGraphBuilder) Build(ctx context.Context, path addrs.ModuleInstance)
 
 // See GraphBuilder
 func (b *EvalGraphBuilder) Steps() []GraphTransformer {
-	concreteProvider := func(a *NodeAbstractProvider) dag.Vertex {
-		return &NodeEvalableProvider{
-			NodeAbstractProvider: a,
+	concreteProvider := b.ConcreteProvider
+	if concreteProvider == nil {
+		concreteProvider = func(a *NodeAbstractProvider) dag.Vertex {
+			return &NodeEvalableProvider{
+				NodeAbstractProvider: a,
+			}
 		}
 	}
 
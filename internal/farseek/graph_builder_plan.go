@@ -57,6 +57,10 @@ type PlanGraphBuilder struct {
 	// skipRefresh indicates that we should skip refreshing managed resources
 	skipRefresh bool
 
+	// refreshProviders, if not empty, limits refreshing to the managed
+	// resources of these providers.
+	refreshProviders []addrs.Provider
+
 	// preDestroyRefresh indicates that we are executing the refresh which
 	// happens immediately before a destroy plan, which happens to use the
 	// normal planing mode so skipPlanChanges cannot be set.
@@ -300,6 +304,7 @@ func (b *PlanGraphBuilder) initPlan() {
 		return &nodeExpandPlannableResource{
 			NodeAbstractResource: a,
 			skipRefresh:          b.skipRefresh,
+			refreshProviders:     b.refreshProviders,
 			skipPlanChanges:      b.skipPlanChanges,
 			preDestroyRefresh:    b.preDestroyRefresh,
 			forceReplace:         b.ForceReplace,
@@ -311,6 +316,7 @@ func (b *PlanGraphBuilder) initPlan() {
 		return &NodePlannableResourceInstanceOrphan{
 			NodeAbstractResourceInstance: a,
 			skipRefresh:                  b.skipRefresh,
+			refreshProviders:             b.refreshProviders,
 			skipPlanChanges:              b.skipPlanChanges,
 			RemoveStatements:             b.RemoveStatements,
 			FarseekMode:                  b.FarseekMode,
@@ -323,6 +329,7 @@ func (b *PlanGraphBuilder) initPlan() {
 			DeposedKey:                   key,
 
 			skipRefresh:      b.skipRefresh,
+			refreshProviders: b.refreshProviders,
 			skipPlanChanges:  b.skipPlanChanges,
 			RemoveStatements: b.RemoveStatements,
 			FarseekMode:      b.FarseekMode,
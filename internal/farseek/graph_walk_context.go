@@ -45,6 +45,7 @@ type ContextGraphWalker struct {
 	PlanTimestamp           time.Time
 	Encryption              encryption.Encryption
 	ProviderFunctionTracker ProviderFunctionMapping
+	DataSourceCache         *DataSourceCache
 
 	// This is an output. Do not set this, nor read it while a graph walk
 	// is in progress.
@@ -60,6 +61,10 @@ type ContextGraphWalker struct {
 	providerLock  sync.Mutex
 	providerCache map[string]map[addrs.InstanceKey]providers.Interface
 
+	// deferredProviders are the provider instances that weren't configured
+	// during a plan, keyed by their addresses. Guarded by providerLock.
+	deferredProviders map[string]struct{}
+
 	provisionerLock  sync.Mutex
 	provisionerCache map[string]provisioners.Interface
 }
@@ -110,6 +115,7 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		ImportResolverValue:     w.ImportResolver,
 		ProviderCache:           w.providerCache,
 		ProviderInputConfig:     w.Context.providerInputConfig,
+		DeferredProviders:       w.deferredProviders,
 		ProviderLock:            &w.providerLock,
 		ProvisionerCache:        w.provisionerCache,
 		ProvisionerLock:         &w.provisionerLock,
@@ -123,6 +129,7 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		VariableValuesLock:      &w.variableValuesLock,
 		Encryption:              w.Encryption,
 		ProviderFunctionTracker: w.ProviderFunctionTracker,
+		DataSourceCacheValue:    w.DataSourceCache,
 	}
 
 	return ctx
@@ -131,6 +138,7 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 func (w *ContextGraphWalker) init() {
 	w.contexts = make(map[string]*BuiltinEvalContext)
 	w.providerCache = make(map[string]map[addrs.InstanceKey]providers.Interface)
+	w.deferredProviders = make(map[string]struct{})
 	w.provisionerCache = make(map[string]provisioners.Interface)
 	w.variableValues = make(map[string]map[string]cty.Value)
 
@@ -137,6 +137,38 @@ type Hook interface {
 	PostStateUpdate(func(*states.SyncState)) (HookAction, error)
 }
 
+// AnnotationsHook is an optional interface for a Hook that reports the
+// annotations that resources declare in their farseek_annotations argument,
+// such as the team that owns them. Before it applies a plan, Farseek calls
+// SetAnnotations with a function that returns the annotations of a resource
+// in the configuration, or nil if it has none.
+type AnnotationsHook interface {
+	Hook
+
+	SetAnnotations(annotations func(addrs.ConfigResource) map[string]string)
+}
+
+// ResourceInstanceStateHook is an optional interface for a Hook that needs
+// to know which resource instance each state update is for, such as to
+// persist only the parts of the state that changed. Farseek calls
+// PostResourceInstanceStateUpdate instead of PostStateUpdate on the hooks
+// that implement it, when the update is for a single resource instance.
+type ResourceInstanceStateHook interface {
+	Hook
+
+	PostResourceInstanceStateUpdate(addr addrs.AbsResourceInstance, mutate func(*states.SyncState)) (HookAction, error)
+}
+
+// ReplaceReasonsHook is an optional interface for a Hook that reports why
+// resource instances are replaced. Before it applies a plan, Farseek calls
+// SetReplaceReasons with the reasons given with the -replace option, by the
+// address of the resource instance.
+type ReplaceReasonsHook interface {
+	Hook
+
+	SetReplaceReasons(reasons map[string]string)
+}
+
 // NilHook is a Hook implementation that does nothing. It exists only to
 // simplify implementing hooks. You can embed this into your Hook implementation
 // and only implement the functions you are interested in.
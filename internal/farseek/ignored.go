@@ -0,0 +1,77 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// IgnoredFilename is the name of the file, in the data directory, that
+// records the resources that "farseek state forget" removed from
+// management. Discovery leaves these resources out, so that Farseek neither
+// recreates nor destroys them.
+const IgnoredFilename = "ignored.json"
+
+type ignoredFile struct {
+	Resources []string `json:"resources"`
+}
+
+// ReadIgnored returns the addresses of the resources recorded in the ignore
+// file at path, or none if the file doesn't exist.
+func ReadIgnored(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var f ignoredFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return f.Resources, nil
+}
+
+// AddIgnored records the given resource address in the ignore file at path,
+// creating the file if necessary. It returns false if the address was
+// already recorded.
+func AddIgnored(path, addr string) (bool, error) {
+	ignored, err := ReadIgnored(path)
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(ignored, addr) {
+		return false, nil
+	}
+	ignored = append(ignored, addr)
+	slices.Sort(ignored)
+
+	data, err := json.MarshalIndent(ignoredFile{Resources: ignored}, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// FilterIgnored returns the given discovered resources, without those whose
+// addresses are in ignored.
+func FilterIgnored(resources []DiscoveredResource, ignored []string) []DiscoveredResource {
+	if len(ignored) == 0 {
+		return resources
+	}
+	var ret []DiscoveredResource
+	for _, dr := range resources {
+		if !slices.Contains(ignored, dr.Address) {
+			ret = append(ret, dr)
+		}
+	}
+	return ret
+}
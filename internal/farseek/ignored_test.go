@@ -0,0 +1,49 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIgnored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".farseek", IgnoredFilename)
+
+	ignored, err := ReadIgnored(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ignored) != 0 {
+		t.Fatalf("unexpected ignored resources %q", ignored)
+	}
+
+	for _, addr := range []string{"test_instance.b", "test_instance.a", "test_instance.b"} {
+		if _, err := AddIgnored(path, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignored, err = ReadIgnored(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"test_instance.a", "test_instance.b"}, ignored); diff != "" {
+		t.Errorf("wrong ignored resources\n%s", diff)
+	}
+
+	resources := []DiscoveredResource{
+		{Address: "test_instance.a"},
+		{Address: "test_instance.c", IsNew: true},
+		{Address: "module.child.test_instance.b"},
+	}
+	var got []string
+	for _, dr := range FilterIgnored(resources, ignored) {
+		got = append(got, dr.Address)
+	}
+	if diff := cmp.Diff([]string{"test_instance.c", "module.child.test_instance.b"}, got); diff != "" {
+		t.Errorf("wrong filtered resources\n%s", diff)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/plans"
 	"github.com/rafagsiqueira/farseek/internal/providers"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 	"github.com/rafagsiqueira/farseek/internal/tracing"
@@ -47,6 +48,11 @@ const traceAttrProviderInstanceAddr = "opentofu.provider_instance.address"
 // NodeApplyableProvider represents a configured provider.
 type NodeApplyableProvider struct {
 	*NodeAbstractProvider
+
+	// dependencies are the data sources and resources that the provider
+	// configuration depends on, directly or through other objects, as
+	// attached by AttachDependenciesTransformer.
+	dependencies []addrs.ConfigResource
 }
 
 var (
@@ -113,10 +119,10 @@ func (n *NodeApplyableProvider) executeInstance(ctx context.Context, evalCtx Eva
 		return n.ValidateProvider(ctx, evalCtx, providerKey, provider)
 	case walkPlan, walkPlanDestroy, walkApply, walkDestroy:
 		log.Printf("[TRACE] NodeApplyableProvider: configuring %s", n.Addr)
-		return n.ConfigureProvider(ctx, evalCtx, providerKey, provider, false)
+		return n.ConfigureProvider(ctx, evalCtx, providerKey, provider, op)
 	case walkImport:
 		log.Printf("[TRACE] NodeApplyableProvider: configuring %s (requiring that configuration is wholly known)", n.Addr)
-		return n.ConfigureProvider(ctx, evalCtx, providerKey, provider, true)
+		return n.ConfigureProvider(ctx, evalCtx, providerKey, provider, op)
 	}
 	return nil
 }
@@ -190,9 +196,15 @@ func (n *NodeApplyableProvider) ValidateProvider(ctx context.Context, evalCtx Ev
 }
 
 // ConfigureProvider configures a provider that is already initialized and retrieved.
-// If verifyConfigIsKnown is true, ConfigureProvider will return an error if the
-// provider configVal is not wholly known and is meant only for use during import.
-func (n *NodeApplyableProvider) ConfigureProvider(ctx context.Context, evalCtx EvalContext, providerKey addrs.InstanceKey, provider providers.Interface, verifyConfigIsKnown bool) tfdiags.Diagnostics {
+//
+// The walk operation decides what happens if the configuration is not wholly
+// known: during import, ConfigureProvider returns an error; during a plan, it
+// defers configuring the provider instance until apply if the configuration
+// depends on data sources or resources with pending changes, which will have
+// been read or applied by then. Otherwise the provider is configured with the
+// unknown values as they are. Preflight checks, which plan nothing, always
+// defer it.
+func (n *NodeApplyableProvider) ConfigureProvider(ctx context.Context, evalCtx EvalContext, providerKey addrs.InstanceKey, provider providers.Interface, op walkOperation) tfdiags.Diagnostics {
 	_, span := tracing.Tracer().Start(
 		ctx, "Configure provider",
 		tracing.SpanAttributes(
@@ -232,15 +244,28 @@ func (n *NodeApplyableProvider) ConfigureProvider(ctx context.Context, evalCtx E
 		return diags
 	}
 
-	if verifyConfigIsKnown && !configVal.IsWhollyKnown() {
-		diags = diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Invalid provider configuration",
-			Detail:   fmt.Sprintf("The configuration for %s depends on values that cannot be determined until apply.", n.Addr),
-			Subject:  &config.DeclRange,
-		})
-		tracing.SetSpanError(span, diags)
-		return diags
+	if !configVal.IsWhollyKnown() {
+		switch op {
+		case walkImport:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid provider configuration",
+				Detail:   fmt.Sprintf("The configuration for %s depends on values that cannot be determined until apply.", n.Addr),
+				Subject:  &config.DeclRange,
+			})
+			tracing.SetSpanError(span, diags)
+			return diags
+		case walkPlan, walkPlanDestroy, walkEval:
+			if op != walkEval && !n.dependenciesHavePendingChanges(evalCtx) {
+				break
+			}
+			// Most providers can't do anything useful with a configuration
+			// that isn't known yet, so rather than configuring this one with
+			// unknown values, everything that needs it waits for apply too.
+			log.Printf("[DEBUG] NodeApplyableProvider: deferring the configuration of %s until apply, because it depends on values that are not known yet", n.Addr.InstanceString(providerKey))
+			evalCtx.DeferProvider(n.Addr, providerKey)
+			return diags
+		}
 	}
 
 	// If our config value contains any marked values, ensure those are
@@ -296,6 +321,21 @@ func (n *NodeApplyableProvider) ConfigureProvider(ctx context.Context, evalCtx E
 	return diags
 }
 
+// dependenciesHavePendingChanges reports whether any of the data sources and
+// resources that the provider configuration depends on have changes planned,
+// which are what make the configuration unknown until apply.
+func (n *NodeApplyableProvider) dependenciesHavePendingChanges(evalCtx EvalContext) bool {
+	changes := evalCtx.Changes()
+	for _, dep := range n.dependencies {
+		for _, change := range changes.GetChangesForConfigResource(dep) {
+			if change != nil && change.Action != plans.NoOp {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 const providerConfigErr = `Provider %q requires explicit configuration. Add a provider block to the root module and configure the provider's required arguments as described in the provider documentation.
 `
 
@@ -44,7 +44,7 @@ func TestNodeApplyableProviderExecute(t *testing.T) {
 		Provider: addrs.NewDefaultProvider("foo"),
 	}
 
-	n := &NodeApplyableProvider{&NodeAbstractProvider{
+	n := &NodeApplyableProvider{NodeAbstractProvider: &NodeAbstractProvider{
 		Addr:   providerAddr,
 		Config: config,
 	}}
@@ -91,7 +91,7 @@ func TestNodeApplyableProviderExecute_unknownImport(t *testing.T) {
 		Module:   addrs.RootModule,
 		Provider: addrs.NewDefaultProvider("foo"),
 	}
-	n := &NodeApplyableProvider{&NodeAbstractProvider{
+	n := &NodeApplyableProvider{NodeAbstractProvider: &NodeAbstractProvider{
 		Addr:   providerAddr,
 		Config: config,
 	}}
@@ -126,7 +126,7 @@ func TestNodeApplyableProviderExecute_unknownApply(t *testing.T) {
 		Module:   addrs.RootModule,
 		Provider: addrs.NewDefaultProvider("foo"),
 	}
-	n := &NodeApplyableProvider{&NodeAbstractProvider{
+	n := &NodeApplyableProvider{NodeAbstractProvider: &NodeAbstractProvider{
 		Addr:   providerAddr,
 		Config: config,
 	}}
@@ -163,7 +163,7 @@ func TestNodeApplyableProviderExecute_sensitive(t *testing.T) {
 		Provider: addrs.NewDefaultProvider("foo"),
 	}
 
-	n := &NodeApplyableProvider{&NodeAbstractProvider{
+	n := &NodeApplyableProvider{NodeAbstractProvider: &NodeAbstractProvider{
 		Addr:   providerAddr,
 		Config: config,
 	}}
@@ -200,7 +200,7 @@ func TestNodeApplyableProviderExecute_sensitiveValidate(t *testing.T) {
 		Provider: addrs.NewDefaultProvider("foo"),
 	}
 
-	n := &NodeApplyableProvider{&NodeAbstractProvider{
+	n := &NodeApplyableProvider{NodeAbstractProvider: &NodeAbstractProvider{
 		Addr:   providerAddr,
 		Config: config,
 	}}
@@ -242,7 +242,7 @@ func TestNodeApplyableProviderExecute_emptyValidate(t *testing.T) {
 		Provider: addrs.NewDefaultProvider("foo"),
 	}
 
-	n := &NodeApplyableProvider{&NodeAbstractProvider{
+	n := &NodeApplyableProvider{NodeAbstractProvider: &NodeAbstractProvider{
 		Addr:   providerAddr,
 		Config: config,
 	}}
@@ -367,7 +367,7 @@ func TestNodeApplyableProvider_ConfigProvider(t *testing.T) {
 			},
 		}
 
-		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 		if diags.HasErrors() {
 			t.Errorf("unexpected error with valid config: %s", diags.Err())
 		}
@@ -380,7 +380,7 @@ func TestNodeApplyableProvider_ConfigProvider(t *testing.T) {
 			},
 		}
 
-		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 		if !diags.HasErrors() {
 			t.Fatal("missing expected error with nil config")
 		}
@@ -401,7 +401,7 @@ func TestNodeApplyableProvider_ConfigProvider(t *testing.T) {
 			},
 		}
 
-		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 		if !diags.HasErrors() {
 			t.Fatal("missing expected error with invalid config")
 		}
@@ -456,7 +456,7 @@ func TestNodeApplyableProvider_ConfigProvider_config_fn_err(t *testing.T) {
 			},
 		}
 
-		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 		if diags.HasErrors() {
 			t.Errorf("unexpected error with valid config: %s", diags.Err())
 		}
@@ -469,7 +469,7 @@ func TestNodeApplyableProvider_ConfigProvider_config_fn_err(t *testing.T) {
 			},
 		}
 
-		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 		if !diags.HasErrors() {
 			t.Fatal("missing expected error with nil config")
 		}
@@ -490,7 +490,7 @@ func TestNodeApplyableProvider_ConfigProvider_config_fn_err(t *testing.T) {
 			},
 		}
 
-		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+		diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 		if !diags.HasErrors() {
 			t.Fatal("missing expected error with invalid config")
 		}
@@ -516,7 +516,7 @@ func TestGetSchemaError(t *testing.T) {
 		},
 	}
 
-	diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, false)
+	diags := node.ConfigureProvider(t.Context(), evalCtx, addrs.NoKey, provider, walkApply)
 	for _, d := range diags {
 		desc := d.Description()
 		if desc.Address != providerAddr.String() {
@@ -220,7 +220,7 @@ func (n *NodeAbstractResource) References() []*addrs.Reference {
 			}
 
 			for _, p := range c.Managed.Provisioners {
-				if p.When != configs.ProvisionerWhenCreate {
+				if p.When == configs.ProvisionerWhenDestroy {
 					continue
 				}
 				if p.Connection != nil {
@@ -835,6 +835,23 @@ func (n *NodeAbstractResourceInstance) planDestroy(ctx context.Context, evalCtx
 	// operation.
 	nullVal := cty.NullVal(unmarkedPriorVal.Type())
 
+	// A provider that isn't configured yet can't check the destroy plan, so
+	// it will only be involved when it's applied.
+	if evalCtx.ProviderDeferred(n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey) {
+		log.Printf("[TRACE] planDestroy: %s provider configuration is not known yet, so planning without it", absAddr)
+		return &plans.ResourceInstanceChange{
+			Addr:        absAddr,
+			PrevRunAddr: n.prevRunAddr(evalCtx),
+			DeposedKey:  deposedKey,
+			Change: plans.Change{
+				Action: plans.Delete,
+				Before: currentState.Value,
+				After:  nullVal,
+			},
+			ProviderAddr: n.ResolvedProvider.ProviderConfig,
+		}, diags
+	}
+
 	provider, _, err := n.getProvider(ctx, evalCtx)
 	if err != nil {
 		return plan, diags.Append(err)
@@ -976,6 +993,10 @@ func (n *NodeAbstractResourceInstance) refresh(ctx context.Context, evalCtx Eval
 		log.Printf("[DEBUG] refresh: %s: no state, so not refreshing", absAddr)
 		return state, diags
 	}
+	if evalCtx.ProviderDeferred(n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey) {
+		log.Printf("[DEBUG] refresh: %s: provider configuration is not known yet, so not refreshing", absAddr)
+		return state, diags
+	}
 
 	schema, _ := providerSchema.SchemaForResourceAddr(n.Addr.Resource.ContainingResource())
 	if schema == nil {
@@ -1142,8 +1163,10 @@ func (n *NodeAbstractResourceInstance) plan(
 	}
 
 	if plannedChange != nil {
-		// If we already planned the action, we stick to that plan
-		createBeforeDestroy = plannedChange.Action == plans.CreateThenDelete
+		// If we already planned the action, we stick to that plan, unless
+		// it was planned without the provider, which alone can tell whether
+		// the object must be replaced.
+		createBeforeDestroy = plannedChange.Action == plans.CreateThenDelete || (plannedChange.ProviderDeferred && createBeforeDestroy)
 	}
 
 	// Evaluate the configuration
@@ -1261,6 +1284,12 @@ func (n *NodeAbstractResourceInstance) plan(
 	}
 	log.Printf("[TRACE] plan: %s lifecycle.destroy evaluation result: skipDestroy=%t", n.Addr, skipDestroy)
 
+	if evalCtx.ProviderDeferred(n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey) {
+		plan, state, deferDiags := n.planWithDeferredProvider(evalCtx, schema, priorVal, priorValTainted, unmarkedConfigVal, proposedNewVal, unmarkedPaths, createBeforeDestroy, skipDestroy)
+		diags = diags.Append(deferDiags)
+		return plan, state, keyData, diags
+	}
+
 	resp := provider.PlanResourceChange(ctx, providers.PlanResourceChangeRequest{
 		TypeName:         n.Addr.Resource.Resource.Type,
 		Config:           unmarkedConfigVal,
@@ -1270,7 +1299,7 @@ func (n *NodeAbstractResourceInstance) plan(
 		ProviderMeta:     metaConfigVal,
 	})
 
-	diags = diags.Append(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()))
+	diags = diags.Append(tfdiags.WithAnnotations(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()), config.Annotations))
 	if diags.HasErrors() {
 		return nil, nil, keyData, diags
 	}
@@ -1652,6 +1681,80 @@ func (n *NodeAbstractResourceInstance) plan(
 	return plan, state, keyData, diags
 }
 
+// planWithDeferredProvider plans a change for a resource instance whose
+// provider instance won't be configured until apply. Without the provider,
+// all we can predict is that the object will match its configuration, with
+// everything else known after apply, when the change is planned again with
+// the configured provider. An existing object that already matches its
+// configuration has no change planned.
+//
+// The change is marked as planned without its provider, because only the
+// provider can tell whether an update requires replacing the object.
+func (n *NodeAbstractResourceInstance) planWithDeferredProvider(evalCtx EvalContext, schema *configschema.Block, priorVal, priorValTainted, configVal, proposedNewVal cty.Value, configMarkPaths []cty.PathValueMarks, createBeforeDestroy, skipDestroy bool) (*plans.ResourceInstanceChange, *states.ResourceInstanceObject, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	log.Printf("[TRACE] plan: %s provider configuration is not known yet, so deferring to apply phase", n.Addr)
+
+	plannedNewVal := objchange.PlannedUnknownObject(schema, configVal)
+	if len(configMarkPaths) > 0 {
+		plannedNewVal = plannedNewVal.MarkWithPaths(configMarkPaths)
+	}
+
+	unmarkedPriorVal, _ := priorVal.UnmarkDeep()
+	eqV := proposedNewVal.Equals(unmarkedPriorVal)
+	unchanged := !priorVal.IsNull() && eqV.IsKnown() && eqV.True()
+
+	action := plans.Update
+	var actionReason plans.ResourceInstanceChangeActionReason
+	switch {
+	case !priorValTainted.IsNull():
+		action = plans.DeleteThenCreate
+		if createBeforeDestroy {
+			action = plans.CreateThenDelete
+		}
+		if skipDestroy {
+			action = plans.ForgetThenCreate
+		}
+		priorVal = priorValTainted
+		actionReason = plans.ResourceInstanceReplaceBecauseTainted
+	case priorVal.IsNull():
+		action = plans.Create
+	case unchanged:
+		action = plans.NoOp
+		plannedNewVal = priorVal
+	}
+
+	if action != plans.NoOp {
+		diags = diags.Append(evalCtx.Hook(func(h Hook) (HookAction, error) {
+			return h.Deferred(n.Addr, "a provider configuration that is not known yet")
+		}))
+	}
+	diags = diags.Append(evalCtx.Hook(func(h Hook) (HookAction, error) {
+		return h.PostDiff(n.Addr, states.CurrentGen, action, priorVal, plannedNewVal)
+	}))
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	plan := &plans.ResourceInstanceChange{
+		Addr:         n.Addr,
+		PrevRunAddr:  n.prevRunAddr(evalCtx),
+		ProviderAddr: n.ResolvedProvider.ProviderConfig,
+		Change: plans.Change{
+			Action: action,
+			Before: priorVal,
+			After:  plannedNewVal,
+		},
+		ActionReason:     actionReason,
+		ProviderDeferred: true,
+	}
+	state := &states.ResourceInstanceObject{
+		Status:      states.ObjectPlanned,
+		Value:       plannedNewVal,
+		SkipDestroy: skipDestroy,
+	}
+	return plan, state, diags
+}
+
 func (n *NodeAbstractResource) processIgnoreChanges(prior, config cty.Value, schema *configschema.Block) (cty.Value, tfdiags.Diagnostics) {
 	// ignore_changes only applies when an object already exists, since we
 	// can't ignore changes to a thing we've not created yet.
@@ -1935,6 +2038,24 @@ func (n *NodeAbstractResourceInstance) readDataSource(ctx context.Context, evalC
 		return newVal, diags
 	}
 
+	// Results that may contain sensitive values aren't cached, since the
+	// cache is stored in plain text, and neither are the results of
+	// data sources that read encrypted data, like terraform_remote_state.
+	// That includes a configuration with marked values, since the cache is
+	// keyed by the unmarked configuration.
+	cache := evalCtx.DataSourceCache()
+	cacheTTL := cache.TTL(config.CacheTTL)
+	cacheProvider := n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey)
+	cacheType := n.Addr.ContainingResource().Resource.Type
+	_, withEncryption := provider.(ProviderWithEncryption)
+	cacheable := cacheTTL > 0 && !withEncryption && !schema.ContainsSensitive() && len(pvm) == 0
+	if cacheable {
+		if cached, ok := cache.Get(cacheProvider, cacheType, configVal, schema.ImpliedType(), cacheTTL); ok {
+			log.Printf("[INFO] readDataSource: using the cached result for %s", n.Addr)
+			return cached, diags
+		}
+	}
+
 	// If we get down here then our configuration is complete and we're read
 	// to actually call the provider to read the data.
 	log.Printf("[TRACE] readDataSource: %s configuration is complete, so reading from provider", n.Addr)
@@ -1958,7 +2079,7 @@ func (n *NodeAbstractResourceInstance) readDataSource(ctx context.Context, evalC
 	} else {
 		resp = provider.ReadDataSource(ctx, req)
 	}
-	diags = diags.Append(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()))
+	diags = diags.Append(tfdiags.WithAnnotations(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()), config.Annotations))
 	if diags.HasErrors() {
 		return newVal, diags
 	}
@@ -2013,6 +2134,12 @@ func (n *NodeAbstractResourceInstance) readDataSource(ctx context.Context, evalC
 		newVal = cty.UnknownAsNull(newVal)
 	}
 
+	if cacheable && !diags.HasErrors() {
+		if err := cache.Put(cacheProvider, cacheType, configVal, newVal); err != nil {
+			log.Printf("[WARN] readDataSource: failed to cache the result for %s: %s", n.Addr, err)
+		}
+	}
+
 	if len(pvm) > 0 {
 		newVal = newVal.MarkWithPaths(pvm)
 	}
@@ -2255,11 +2382,12 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx context.Context, evalC
 
 	configKnown := configVal.IsWhollyKnown()
 	depsPending := n.dependenciesHavePendingChanges(evalCtx)
+	providerDeferred := evalCtx.ProviderDeferred(n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey)
 	// If our configuration contains any unknown values, or we depend on any
-	// unknown values then we must defer the read to the apply phase by
-	// producing a "Read" change for this resource, and a placeholder value for
-	// it in the state.
-	if depsPending || !configKnown {
+	// unknown values, including through the configuration of our provider,
+	// then we must defer the read to the apply phase by producing a "Read"
+	// change for this resource, and a placeholder value for it in the state.
+	if depsPending || !configKnown || providerDeferred {
 		// We can't plan any changes if we're only refreshing, so the only
 		// value we can set here is whatever was in state previously.
 		if skipPlanChanges {
@@ -2282,6 +2410,9 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx context.Context, evalC
 			// specific.
 			log.Printf("[TRACE] planDataSource: %s configuration is fully known, at least one dependency has changes pending", n.Addr)
 			reason = plans.ResourceInstanceReadBecauseDependencyPending
+		case providerDeferred:
+			log.Printf("[TRACE] planDataSource: %s provider configuration is not known yet, so deferring to apply phase", n.Addr)
+			reason = plans.ResourceInstanceReadBecauseDependencyPending
 		}
 
 		unmarkedConfigVal, configMarkPaths := configVal.UnmarkDeepWithPaths()
@@ -2974,6 +3105,7 @@ func (n *NodeAbstractResourceInstance) apply(
 	applyDiags := resp.Diagnostics
 	if applyConfig != nil {
 		applyDiags = applyDiags.InConfigBody(applyConfig.Config, n.Addr.String())
+		applyDiags = tfdiags.WithAnnotations(applyDiags, applyConfig.Annotations)
 	}
 	diags = diags.Append(applyDiags)
 
@@ -3358,11 +3490,12 @@ func (n *NodeAbstractResourceInstance) planEphemeralResource(ctx context.Context
 
 	configKnown := configVal.IsWhollyKnown()
 	depsPending := n.dependenciesHavePendingChanges(evalCtx)
+	providerDeferred := evalCtx.ProviderDeferred(n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey)
 	// If our configuration contains any unknown values, or we depend on any
-	// unknown values then we must defer the opening to the apply phase by
-	// producing an "Open" change for this resource, and a placeholder value for
-	// it in the state.
-	if depsPending || !configKnown {
+	// unknown values, including through the configuration of our provider,
+	// then we must defer the opening to the apply phase by producing an "Open"
+	// change for this resource, and a placeholder value for it in the state.
+	if depsPending || !configKnown || providerDeferred {
 		// We can't plan any changes if we're only refreshing, so the only
 		// value we can set here is whatever was in state previously.
 		if skipPlanChanges {
@@ -3381,6 +3514,9 @@ func (n *NodeAbstractResourceInstance) planEphemeralResource(ctx context.Context
 		} else if depsPending {
 			log.Printf("[TRACE] planEphemeralResource: %s configuration is fully known, at least one dependency has changes pending", n.Addr)
 			reason = "pending dependencies"
+		} else if providerDeferred {
+			log.Printf("[TRACE] planEphemeralResource: %s provider configuration is not known yet, so deferring to apply phase", n.Addr)
+			reason = "a provider configuration that is not known yet"
 		}
 
 		plannedChange, plannedNewState, deferDiags := n.deferEphemeralResource(evalCtx, schema, priorVal, configVal, reason)
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/instances"
@@ -335,7 +337,7 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx context.Conte
 
 	// Make a new diff, in case we've learned new values in the state
 	// during apply which we can now incorporate.
-	diffApply, _, repeatData, planDiags := n.plan(ctx, evalCtx, diff, state, false, n.forceReplace)
+	diffApply, _, repeatData, planDiags := n.plan(ctx, evalCtx, diff, state, diff.ProviderDeferred && n.CreateBeforeDestroy(), n.forceReplace)
 	diags = diags.Append(planDiags)
 	if diags.HasErrors() {
 		return diags
@@ -347,6 +349,31 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx context.Conte
 		return diags
 	}
 
+	// A change planned without its provider can turn out to require
+	// replacing the object, but then no destroy node was planned for the
+	// prior object, so it's destroyed here instead: before its replacement
+	// is created, or after if create_before_destroy is set.
+	var replacedState *states.ResourceInstanceObject
+	if diff.ProviderDeferred {
+		switch {
+		case diff.Action == plans.NoOp && diffApply.Action != plans.NoOp:
+			log.Printf("[DEBUG] managedResourceExecute: %s was planned without its provider as unchanged, so its %s change is left for the next plan", n.Addr, diffApply.Action)
+			diffApply = diff
+		case diffApply.Action == plans.CreateThenDelete && diff.Action != plans.CreateThenDelete:
+			deposedKey = evalCtx.State().DeposeResourceInstanceObject(n.Addr)
+			log.Printf("[TRACE] managedResourceExecute: prior object for %s now deposed with key %s, to be destroyed after its replacement is created", n.Addr, deposedKey)
+			createBeforeDestroyEnabled = true
+			replacedState = state
+			state = nil
+		case diffApply.Action == plans.DeleteThenCreate && diff.Action != plans.DeleteThenCreate:
+			diags = diags.Append(n.destroyReplacedObject(ctx, evalCtx, state, states.NotDeposed))
+			if diags.HasErrors() {
+				return diags
+			}
+			state = nil
+		}
+	}
+
 	diffApply = reducePlan(addr, diffApply, false)
 	// reducePlan may have simplified our planned change
 	// into a NoOp if it only requires destroying, since destroying
@@ -390,6 +417,12 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx context.Conte
 	// Run Provisioners
 	createNew := (diffApply.Action == plans.Create || diffApply.Action.IsReplace())
 	applyProvisionersDiags := n.evalApplyProvisioners(ctx, evalCtx, state, createNew, configs.ProvisionerWhenCreate)
+	if diffApply.Action == plans.Update && !diags.HasErrors() {
+		// A failed update doesn't taint the object, so we must check for
+		// errors ourselves to only run update-time provisioners after a
+		// successful update.
+		applyProvisionersDiags = n.evalApplyProvisioners(ctx, evalCtx, state, false, configs.ProvisionerWhenUpdate)
+	}
 	// the provisioner errors count as port of the apply error, so we can bundle the diags
 	diags = diags.Append(applyProvisionersDiags)
 
@@ -434,6 +467,10 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx context.Conte
 		}
 	}
 
+	if replacedState != nil && !diags.HasErrors() {
+		diags = diags.Append(n.destroyReplacedObject(ctx, evalCtx, replacedState, deposedKey))
+	}
+
 	diags = diags.Append(n.postApplyHook(evalCtx, state, diags.Err()))
 	diags = diags.Append(updateStateHook(evalCtx, n.Addr))
 
@@ -444,6 +481,56 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx context.Conte
 	return diags.Append(n.managedResourcePostconditions(ctx, evalCtx, repeatData))
 }
 
+// destroyReplacedObject destroys the prior object of a change that was
+// planned without its provider and turned out to require replacing the
+// object during apply, as NodeDestroyResourceInstance would have if the
+// replacement had been planned. The object is the current one, or the deposed
+// one with the given key if its replacement was created first.
+func (n *NodeApplyableResourceInstance) destroyReplacedObject(ctx context.Context, evalCtx EvalContext, prior *states.ResourceInstanceObject, deposedKey states.DeposedKey) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if prior == nil || prior.Value.IsNull() {
+		return diags
+	}
+
+	change := &plans.ResourceInstanceChange{
+		Addr:         n.Addr,
+		PrevRunAddr:  n.Addr,
+		DeposedKey:   deposedKey,
+		ProviderAddr: n.ResolvedProvider.ProviderConfig,
+		Change: plans.Change{
+			Action: plans.Delete,
+			Before: prior.Value,
+			After:  cty.NullVal(prior.Value.Type()),
+		},
+		Private: prior.Private,
+	}
+	diags = diags.Append(n.preApplyHook(evalCtx, change))
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if prior.Status != states.ObjectTainted {
+		diags = diags.Append(n.evalApplyProvisioners(ctx, evalCtx, prior, false, configs.ProvisionerWhenDestroy))
+		if diags.HasErrors() {
+			return diags.Append(n.postApplyHook(evalCtx, prior, diags.Err()))
+		}
+	}
+
+	state, applyDiags := n.apply(ctx, evalCtx, prior, change, nil, instances.RepetitionData{}, false)
+	diags = diags.Append(applyDiags)
+
+	var err error
+	if deposedKey == states.NotDeposed {
+		err = n.writeResourceInstanceState(ctx, evalCtx, state, workingState)
+	} else {
+		err = n.writeResourceInstanceStateDeposed(ctx, evalCtx, deposedKey, state, workingState)
+	}
+	if err != nil {
+		return diags.Append(err)
+	}
+	return diags.Append(n.postApplyHook(evalCtx, state, diags.Err()))
+}
+
 func (n *NodeApplyableResourceInstance) managedResourcePostconditions(ctx context.Context, evalCtx EvalContext, repeatData instances.RepetitionData) (diags tfdiags.Diagnostics) {
 
 	checkDiags := evalCheckRules(
@@ -477,8 +564,20 @@ func (n *NodeApplyableResourceInstance) checkPlannedChange(evalCtx EvalContext,
 
 	log.Printf("[TRACE] checkPlannedChange: Verifying that actual change (action %s) matches planned change (action %s)", actualChange.Action, plannedChange.Action)
 
+	if plannedChange.ProviderDeferred && plannedChange.Action == plans.NoOp {
+		// An object that matched its configuration when it was planned
+		// without its provider is left alone, whatever the provider plans
+		// for it now.
+		return diags
+	}
+
 	if plannedChange.Action != actualChange.Action {
 		switch {
+		case plannedChange.ProviderDeferred && plannedChange.Action == plans.Update && (actualChange.Action.IsReplace() || actualChange.Action == plans.ForgetThenCreate):
+			// Only the provider can tell that an update requires replacing
+			// the object, and it wasn't configured when the change was
+			// planned.
+			log.Printf("[DEBUG] %s change was planned without its provider, which now requires replacing the object", absAddr)
 		case plannedChange.Action == plans.ForgetThenCreate && actualChange.Action == plans.Create:
 			// This is an expected alteration of the action, since we are, first - forgetting the resource and then calling
 			// the diffApply plan, with no state for the resource, we are generating the Create action instead of ForgetThenCreate
@@ -44,6 +44,10 @@ type NodePlanDeposedResourceInstanceObject struct {
 	// skipRefresh indicates that we should skip refreshing individual instances
 	skipRefresh bool
 
+	// refreshProviders, if not empty, are the only providers whose
+	// instances are refreshed.
+	refreshProviders []addrs.Provider
+
 	// skipPlanChanges indicates we should skip trying to plan change actions
 	// for any instances.
 	skipPlanChanges bool
@@ -91,6 +95,9 @@ func (n *NodePlanDeposedResourceInstanceObject) References() []*addrs.Reference
 // GraphNodeEvalable impl.
 func (n *NodePlanDeposedResourceInstanceObject) Execute(ctx context.Context, evalCtx EvalContext, op walkOperation) (diags tfdiags.Diagnostics) {
 	log.Printf("[TRACE] NodePlanDeposedResourceInstanceObject: planning %s deposed object %s", n.Addr, n.DeposedKey)
+	if !refreshesProvider(n.refreshProviders, n.ResolvedProvider.ProviderConfig.Provider) {
+		n.skipRefresh = true
+	}
 
 	_, span := tracing.Tracer().Start(
 		ctx, traceNamePlanResourceInstance,
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"sync"
 
@@ -30,6 +31,10 @@ type nodeExpandPlannableResource struct {
 	// skipRefresh indicates that we should skip refreshing individual instances
 	skipRefresh bool
 
+	// refreshProviders, if not empty, are the only providers whose
+	// instances are refreshed.
+	refreshProviders []addrs.Provider
+
 	preDestroyRefresh bool
 
 	// skipPlanChanges indicates we should skip trying to plan change actions
@@ -103,6 +108,20 @@ func (n *nodeExpandPlannableResource) ModifyCreateBeforeDestroy(v bool) error {
 	return nil
 }
 
+// instancesSkipRefresh returns whether the instances of the resource skip
+// refreshing, because refreshing is disabled or because their provider
+// isn't one of the providers that refreshing is limited to.
+func (n *nodeExpandPlannableResource) instancesSkipRefresh() bool {
+	return n.skipRefresh || !refreshesProvider(n.refreshProviders, n.ResolvedProvider.ProviderConfig.Provider)
+}
+
+// refreshesProvider returns whether the managed resource instances of the
+// given provider are refreshed when refreshing is limited to the given
+// providers, which it isn't if there are none.
+func refreshesProvider(refreshProviders []addrs.Provider, provider addrs.Provider) bool {
+	return len(refreshProviders) == 0 || slices.Contains(refreshProviders, provider)
+}
+
 func (n *nodeExpandPlannableResource) DynamicExpand(evalCtx EvalContext) (*Graph, error) {
 	var g Graph
 
@@ -146,7 +165,7 @@ func (n *nodeExpandPlannableResource) DynamicExpand(evalCtx EvalContext) (*Graph
 
 		return &NodePlannableResourceInstanceOrphan{
 			NodeAbstractResourceInstance: a,
-			skipRefresh:                  n.skipRefresh,
+			skipRefresh:                  n.instancesSkipRefresh(),
 			skipPlanChanges:              n.skipPlanChanges,
 			FarseekMode:                  n.FarseekMode,
 		}
@@ -385,7 +404,7 @@ func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx context.Conte
 			// to force on CreateBeforeDestroy due to dependencies on other
 			// nodes that have it.
 			ForceCreateBeforeDestroy: n.CreateBeforeDestroy(),
-			skipRefresh:              n.skipRefresh,
+			skipRefresh:              n.instancesSkipRefresh(),
 			skipPlanChanges:          n.skipPlanChanges,
 			forceReplace:             n.forceReplace,
 			FarseekMode:              n.FarseekMode,
@@ -416,7 +435,7 @@ func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx context.Conte
 
 		return &NodePlannableResourceInstanceOrphan{
 			NodeAbstractResourceInstance: a,
-			skipRefresh:                  n.skipRefresh,
+			skipRefresh:                  n.instancesSkipRefresh(),
 			skipPlanChanges:              n.skipPlanChanges,
 			FarseekMode:                  n.FarseekMode,
 		}
@@ -285,10 +285,20 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx context.Conte
 		return diags
 	}
 
+	providerDeferred := evalCtx.ProviderDeferred(n.ResolvedProvider.ProviderConfig, n.ResolvedProviderKey)
+	if importing && providerDeferred {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Cannot import with a provider configuration that is not known yet",
+			Detail:   fmt.Sprintf("Importing %s requires %s, whose configuration depends on values that won't be known until apply. Apply the changes those values depend on first, and then import the resource.", n.Addr, n.ResolvedProvider.ProviderConfig.InstanceString(n.ResolvedProviderKey)),
+			Subject:  n.importTarget.Config.DeclRange.Ptr(),
+		})
+	}
+
 	// If the resource is to be imported, we now ask the provider for an Import
 	// and a Refresh, and save the resulting state to instanceRefreshState.
 	if importing {
-		instanceRefreshState, diags = n.importState(ctx, evalCtx, addr, n.importTarget.ID, provider, providerSchema)
+		instanceRefreshState, diags = n.importState(ctx, evalCtx, addr, n.importTarget.ID, cty.NilVal, provider, providerSchema)
 	} else {
 		var readDiags tfdiags.Diagnostics
 		instanceRefreshState, readDiags = n.readResourceInstanceState(ctx, evalCtx, addr)
@@ -301,7 +311,7 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx context.Conte
 		// In stateless mode, if the resource is missing from state, we try to see if it
 		// already exists in the cloud by speculatively importing it using the 'name'
 		// attribute from its configuration.
-		if instanceRefreshState == nil && n.FarseekMode && n.Config != nil && n.Config.Managed != nil {
+		if instanceRefreshState == nil && n.FarseekMode && n.Config != nil && n.Config.Managed != nil && !providerDeferred {
 			schema, _ := providerSchema.SchemaForResourceAddr(addr.Resource.Resource)
 			if schema != nil {
 				// Evaluate the block to get the 'name' attribute
@@ -313,10 +323,14 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx context.Conte
 					if nameVal, ok := attrs["name"]; ok && !nameVal.IsNull() && nameVal.Type() == cty.String && nameVal.IsKnown() {
 						id := nameVal.AsString()
 						log.Printf("[INFO] Farseek: Speculatively importing %s with ID %q (Check First)", addr, id)
-						s, impDiags := n.importState(ctx, evalCtx, addr, id, provider, providerSchema)
+						s, impDiags := n.importState(ctx, evalCtx, addr, id, val, provider, providerSchema)
 						if !impDiags.HasErrors() && s != nil {
 							instanceRefreshState = s
 							log.Printf("[INFO] Farseek: Successfully speculatively imported %s", addr)
+						} else if isAmbiguousImport(impDiags) {
+							// The object may exist, so planning to create it
+							// could duplicate it.
+							return diags.Append(impDiags)
 						}
 					}
 				}
@@ -616,7 +630,10 @@ func (n *NodePlannableResourceInstance) replaceTriggered(ctx context.Context, ev
 	return diags
 }
 
-func (n *NodePlannableResourceInstance) importState(ctx context.Context, evalCtx EvalContext, addr addrs.AbsResourceInstance, importId string, provider providers.Interface, providerSchema providers.ProviderSchema) (*states.ResourceInstanceObject, tfdiags.Diagnostics) {
+// importState imports the remote object with the given ID to addr. The
+// configuration value, if not cty.NilVal, selects the object to import when
+// the provider returns several.
+func (n *NodePlannableResourceInstance) importState(ctx context.Context, evalCtx EvalContext, addr addrs.AbsResourceInstance, importId string, config cty.Value, provider providers.Interface, providerSchema providers.ProviderSchema) (*states.ResourceInstanceObject, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	absAddr := addr.Resource.Absolute(evalCtx.Path())
 
@@ -652,30 +669,23 @@ func (n *NodePlannableResourceInstance) importState(ctx context.Context, evalCtx
 	for _, obj := range imported {
 		log.Printf("[TRACE] graphNodeImportState: import %s %q produced instance object of type %s", absAddr.String(), importId, obj.TypeName)
 	}
-	if len(imported) > 1 {
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Multiple import states not supported",
-			fmt.Sprintf("While attempting to import with ID %s, the provider "+
-				"returned multiple resource instance states. This "+
-				"is not currently supported.",
-				importId,
-			),
-		))
+	obj, selectDiags := selectImportedResource(absAddr, importId, imported, config)
+	diags = diags.Append(selectDiags)
+	if diags.HasErrors() {
 		return nil, diags
 	}
 
 	// call post-import hook
 	diags = diags.Append(evalCtx.Hook(func(h Hook) (HookAction, error) {
-		return h.PostPlanImport(absAddr, imported)
+		return h.PostPlanImport(absAddr, []providers.ImportedResource{obj})
 	}))
 
-	if imported[0].TypeName == "" {
+	if obj.TypeName == "" {
 		diags = diags.Append(fmt.Errorf("import of %s didn't set type", n.Addr.String()))
 		return nil, diags
 	}
 
-	importedState := imported[0].AsInstanceObject()
+	importedState := obj.AsInstanceObject()
 
 	if importedState.Value.IsNull() {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -808,6 +818,13 @@ func (n *NodePlannableResourceInstance) shouldImport(evalCtx EvalContext) bool {
 		return false
 	}
 
+	// In Farseek mode the prior state only holds placeholders recovered from
+	// the configuration history, so the import block, which names the real
+	// object, takes precedence over them.
+	if n.FarseekMode {
+		return true
+	}
+
 	// If the import target already has a state - we should not attempt to import it, but instead run a normal plan
 	// for it
 	state := evalCtx.State()
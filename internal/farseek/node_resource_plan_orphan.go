@@ -27,6 +27,10 @@ type NodePlannableResourceInstanceOrphan struct {
 	// skipRefresh indicates that we should skip refreshing individual instances
 	skipRefresh bool
 
+	// refreshProviders, if not empty, are the only providers whose
+	// instances are refreshed.
+	refreshProviders []addrs.Provider
+
 	// skipPlanChanges indicates we should skip trying to plan change actions
 	// for any instances.
 	skipPlanChanges bool
@@ -58,6 +62,9 @@ func (n *NodePlannableResourceInstanceOrphan) Name() string {
 // GraphNodeExecutable
 func (n *NodePlannableResourceInstanceOrphan) Execute(ctx context.Context, evalCtx EvalContext, op walkOperation) tfdiags.Diagnostics {
 	addr := n.ResourceInstanceAddr()
+	if !refreshesProvider(n.refreshProviders, n.ResolvedProvider.ProviderConfig.Provider) {
+		n.skipRefresh = true
+	}
 
 	ctx, span := tracing.Tracer().Start(
 		ctx, traceNamePlanResourceInstance,
@@ -0,0 +1,92 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+// OutputsFilename is the name of the file in a configuration root that
+// exports its root module outputs for other stacks to read, because a
+// stateless stack has no state that another stack could read them from.
+const OutputsFilename = "farseek.outputs.json"
+
+// exportedOutput is the JSON representation of a single output in an
+// exported outputs file, which is the same as in "farseek output -json".
+type exportedOutput struct {
+	Sensitive  bool            `json:"sensitive"`
+	Deprecated string          `json:"deprecated,omitempty"`
+	Type       json.RawMessage `json:"type"`
+	Value      json.RawMessage `json:"value"`
+}
+
+// ReadOutputsFile returns the outputs exported by the configuration root in
+// dir. The error satisfies os.IsNotExist if nothing was exported yet.
+func ReadOutputsFile(dir string) (map[string]*states.OutputValue, error) {
+	path := filepath.Join(dir, OutputsFilename)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var exported map[string]exportedOutput
+	if err := json.Unmarshal(src, &exported); err != nil {
+		return nil, fmt.Errorf("invalid exported outputs file %s: %w", path, err)
+	}
+
+	ret := make(map[string]*states.OutputValue, len(exported))
+	for name, output := range exported {
+		ty, err := ctyjson.UnmarshalType(output.Type)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type for output %q in %s: %w", name, path, err)
+		}
+		val, err := ctyjson.Unmarshal(output.Value, ty)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for output %q in %s: %w", name, path, err)
+		}
+		ret[name] = &states.OutputValue{
+			Value:      val,
+			Sensitive:  output.Sensitive,
+			Deprecated: output.Deprecated,
+		}
+	}
+	return ret, nil
+}
+
+// WriteOutputsFile exports the given root module outputs from the
+// configuration root in dir. Sensitive outputs are left out, so that the
+// file is safe to commit alongside the configuration.
+func WriteOutputsFile(dir string, outputs map[string]*states.OutputValue) error {
+	exported := make(map[string]exportedOutput, len(outputs))
+	for name, output := range outputs {
+		if output.Sensitive || !output.Value.IsWhollyKnown() {
+			continue
+		}
+		ty, err := ctyjson.MarshalType(output.Value.Type())
+		if err != nil {
+			return fmt.Errorf("failed to export output %q: %w", name, err)
+		}
+		val, err := ctyjson.Marshal(output.Value, output.Value.Type())
+		if err != nil {
+			return fmt.Errorf("failed to export output %q: %w", name, err)
+		}
+		exported[name] = exportedOutput{
+			Deprecated: output.Deprecated,
+			Type:       ty,
+			Value:      val,
+		}
+	}
+
+	src, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, OutputsFilename), append(src, '\n'), 0644)
+}
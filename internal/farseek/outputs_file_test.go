@@ -0,0 +1,52 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"os"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestOutputsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ReadOutputsFile(dir); !os.IsNotExist(err) {
+		t.Fatalf("wrong error for a missing file: %v", err)
+	}
+
+	err := WriteOutputsFile(dir, map[string]*states.OutputValue{
+		"vpc_id": {Value: cty.StringVal("vpc-123")},
+		"subnets": {
+			Value:      cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			Deprecated: "Use subnet_ids instead.",
+		},
+		"password": {Value: cty.StringVal("hunter2"), Sensitive: true},
+		"pending":  {Value: cty.UnknownVal(cty.String)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadOutputsFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("wrong number of exported outputs %d; want 2, without sensitive or unknown values", len(got))
+	}
+	if v := got["vpc_id"].Value; !v.RawEquals(cty.StringVal("vpc-123")) {
+		t.Errorf("wrong value for vpc_id: %#v", v)
+	}
+	subnets := got["subnets"]
+	if !subnets.Value.RawEquals(cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")})) {
+		t.Errorf("wrong value for subnets: %#v", subnets.Value)
+	}
+	if subnets.Deprecated != "Use subnet_ids instead." {
+		t.Errorf("wrong deprecation message for subnets: %q", subnets.Deprecated)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/rafagsiqueira/farseek/internal/dag"
+)
+
+// ProjectFilename is the name of the file that declares the configuration
+// roots of a repository containing more than one of them.
+const ProjectFilename = "farseek.hcl"
+
+// Project describes a repository with several configuration roots, each
+// tracked against its own last applied commit SHA.
+type Project struct {
+	// Dir is the directory containing the project file. Root paths are
+	// relative to it.
+	Dir string
+
+	Roots []*ProjectRoot
+}
+
+// ProjectRoot is a single "root" block of a project file.
+type ProjectRoot struct {
+	Name string `hcl:"name,label"`
+
+	// Path is the directory containing the root module, relative to the
+	// project directory.
+	Path string `hcl:"path"`
+
+	// SHAFile optionally overrides where the last applied commit SHA of
+	// this root is kept, relative to the project directory. It defaults to
+	// the .farseek_sha file inside the root's own directory.
+	SHAFile string `hcl:"sha_file,optional"`
+
+	// Dependencies are the directories of other roots whose outputs this
+	// root uses, relative to this root's directory. Orchestrated runs
+	// across the project operate on a root only after its dependencies.
+	Dependencies []string `hcl:"dependencies,optional"`
+
+	dependsOn []*ProjectRoot
+}
+
+func (r *ProjectRoot) String() string {
+	return r.Name
+}
+
+// DependsOn returns the roots that this root declares as dependencies.
+func (r *ProjectRoot) DependsOn() []*ProjectRoot {
+	return r.dependsOn
+}
+
+type projectFile struct {
+	Roots []*ProjectRoot `hcl:"root,block"`
+}
+
+// FindProject looks for a project file in dir and then in each of its
+// parent directories, stopping at the top of the git repository that
+// contains dir. It returns nil if there is no project file.
+func FindProject(dir string) (*Project, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(abs, ProjectFilename)
+		if _, err := os.Stat(path); err == nil {
+			return LoadProject(path)
+		}
+		if _, err := os.Stat(filepath.Join(abs, ".git")); err == nil {
+			return nil, nil
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return nil, nil
+		}
+		abs = parent
+	}
+}
+
+// LoadProject reads and validates the project file at the given path.
+func LoadProject(path string) (*Project, error) {
+	f, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var pf projectFile
+	if diags := gohcl.DecodeBody(f.Body, nil, &pf); diags.HasErrors() {
+		return nil, diags
+	}
+
+	project := &Project{
+		Dir:   filepath.Dir(path),
+		Roots: pf.Roots,
+	}
+	names := make(map[string]bool)
+	paths := make(map[string]string)
+	for _, root := range project.Roots {
+		if names[root.Name] {
+			return nil, fmt.Errorf("%s: duplicate root %q", path, root.Name)
+		}
+		names[root.Name] = true
+
+		clean := filepath.Clean(root.Path)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s: the path of root %q must be a directory inside %s", path, root.Name, project.Dir)
+		}
+		if other, exists := paths[pathKey(clean)]; exists {
+			return nil, fmt.Errorf("%s: roots %q and %q have the same path", path, other, root.Name)
+		}
+		paths[pathKey(clean)] = root.Name
+		root.Path = clean
+	}
+
+	byPath := make(map[string]*ProjectRoot)
+	for _, root := range project.Roots {
+		byPath[pathKey(root.Path)] = root
+	}
+	for _, root := range project.Roots {
+		for _, dep := range root.Dependencies {
+			target, ok := byPath[pathKey(filepath.Join(root.Path, dep))]
+			if !ok {
+				return nil, fmt.Errorf("%s: root %q depends on %q, which is not the path of a root in this project", path, root.Name, dep)
+			}
+			if target == root {
+				return nil, fmt.Errorf("%s: root %q depends on itself", path, root.Name)
+			}
+			root.dependsOn = append(root.dependsOn, target)
+		}
+	}
+	if cycles := project.Graph().Cycles(); len(cycles) > 0 {
+		msgs := make([]string, len(cycles))
+		for i, cycle := range cycles {
+			names := make([]string, len(cycle))
+			for j, v := range cycle {
+				names[j] = fmt.Sprintf("%q", v.(*ProjectRoot).Name)
+			}
+			sort.Strings(names)
+			msgs[i] = strings.Join(names, ", ")
+		}
+		return nil, fmt.Errorf("%s: dependency cycle between roots %s", path, strings.Join(msgs, "; and between roots "))
+	}
+	return project, nil
+}
+
+// Graph returns a graph of the project's roots, with an edge from each root
+// to each of its dependencies.
+func (p *Project) Graph() *dag.AcyclicGraph {
+	var g dag.AcyclicGraph
+	for _, root := range p.Roots {
+		g.Add(root)
+	}
+	for _, root := range p.Roots {
+		for _, dep := range root.dependsOn {
+			g.Connect(dag.BasicEdge(root, dep))
+		}
+	}
+	return &g
+}
+
+// Root returns the root whose directory is dir, or nil if dir isn't one of
+// the project's roots.
+func (p *Project) Root(dir string) *ProjectRoot {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	for _, root := range p.Roots {
+		if pathKey(filepath.Join(p.Dir, root.Path)) == pathKey(abs) {
+			return root
+		}
+	}
+	return nil
+}
+
+// SHAPath returns the path of the file recording the last applied commit
+// SHA for the configuration root in dir. This is the .farseek_sha file in
+// dir unless a project file declares dir as a root with a different one.
+func SHAPath(dir string) (string, error) {
+	project, err := FindProject(dir)
+	if err != nil {
+		return "", err
+	}
+	if project != nil {
+		if root := project.Root(dir); root != nil && root.SHAFile != "" {
+			return filepath.Join(project.Dir, root.SHAFile), nil
+		}
+	}
+	return filepath.Join(dir, SHAFilename), nil
+}
@@ -0,0 +1,202 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/dag"
+)
+
+func TestProjectSHAs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	network := filepath.Join(dir, "infra", "network")
+	app := filepath.Join(dir, "infra", "app")
+	for _, d := range []string{network, app} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	project := `
+root "network" {
+  path = "infra/network"
+}
+
+root "app" {
+  path     = "infra/app/"
+  sha_file = ".farseek/app.sha"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, ProjectFilename), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each root keeps its own SHA, so writing one doesn't affect the other.
+	if err := WriteSHA(network, "aaa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSHA(app, "bbb"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadSHA(network); err != nil || got != "aaa" {
+		t.Fatalf("wrong network SHA %q (err %v)", got, err)
+	}
+	if got, err := ReadSHA(app); err != nil || got != "bbb" {
+		t.Fatalf("wrong app SHA %q (err %v)", got, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(network, SHAFilename)); err != nil {
+		t.Errorf("network SHA is not in its root directory: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".farseek", "app.sha")); err != nil {
+		t.Errorf("app SHA is not in its configured file: %s", err)
+	}
+}
+
+func TestFindProject_stopsAtRepository(t *testing.T) {
+	outer := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outer, ProjectFilename), []byte(`root "x" { path = "x" }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(outer, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A project file outside the repository must not apply to it.
+	project, err := FindProject(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project != nil {
+		t.Fatalf("unexpected project in %s", project.Dir)
+	}
+}
+
+func TestLoadProject_dependencies(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ProjectFilename)
+	src := `
+root "network" {
+  path = "infra/network"
+}
+
+root "dns" {
+  path = "infra/dns"
+}
+
+root "app" {
+  path         = "infra/app"
+  dependencies = ["../network", "../dns/"]
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := LoadProject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := project.Roots[2]
+	var got []string
+	for _, dep := range app.DependsOn() {
+		got = append(got, dep.Name)
+	}
+	if want := []string{"network", "dns"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("wrong dependencies %q; want %q", got, want)
+	}
+
+	g := project.Graph()
+	if err := g.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !g.HasEdge(dag.BasicEdge(app, project.Roots[0])) || !g.HasEdge(dag.BasicEdge(app, project.Roots[1])) {
+		t.Errorf("graph is missing edges from app to its dependencies:\n%s", g.String())
+	}
+}
+
+func TestLoadProject_invalid(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"duplicate name": {
+			`
+root "a" { path = "one" }
+root "a" { path = "two" }
+`,
+			`duplicate root "a"`,
+		},
+		"duplicate path": {
+			`
+root "a" { path = "one" }
+root "b" { path = "./one" }
+`,
+			`roots "a" and "b" have the same path`,
+		},
+		"outside project": {
+			`root "a" { path = "../elsewhere" }`,
+			`must be a directory inside`,
+		},
+		"unknown dependency": {
+			`
+root "a" {
+  path         = "a"
+  dependencies = ["../b"]
+}
+`,
+			`root "a" depends on "../b", which is not the path of a root`,
+		},
+		"self dependency": {
+			`
+root "a" {
+  path         = "a"
+  dependencies = ["."]
+}
+`,
+			`root "a" depends on itself`,
+		},
+		"dependency cycle": {
+			`
+root "a" {
+  path         = "a"
+  dependencies = ["../c"]
+}
+root "b" {
+  path         = "b"
+  dependencies = ["../a"]
+}
+root "c" {
+  path         = "c"
+  dependencies = ["../b"]
+}
+root "d" {
+  path         = "d"
+  dependencies = ["../a"]
+}
+`,
+			`dependency cycle between roots "a", "b", "c"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ProjectFilename)
+			if err := os.WriteFile(path, []byte(test.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadProject(path)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("wrong error: %v", err)
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
@@ -15,6 +17,8 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/providers"
 	"github.com/rafagsiqueira/farseek/internal/states"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	"github.com/rafagsiqueira/farseek/internal/tracing"
+	"github.com/rafagsiqueira/farseek/internal/tracing/traceattrs"
 )
 
 // Schemas is a container for various kinds of schema that Farseek needs
@@ -84,6 +88,12 @@ func loadSchemas(ctx context.Context, config *configs.Config, state *states.Stat
 	}, diags.Err()
 }
 
+// schemaLoadConcurrency is the maximum number of providers that
+// loadProviderSchemas starts at once to read their schemas. Each one is a
+// separate plugin process, so starting dozens at once would compete for
+// memory and CPU rather than finish sooner.
+const schemaLoadConcurrency = 8
+
 func loadProviderSchemas(ctx context.Context, config *configs.Config, state *states.State, plugins *contextPlugins) (map[addrs.Provider]providers.ProviderSchema, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	schemas := map[addrs.Provider]providers.ProviderSchema{}
@@ -101,13 +111,38 @@ func loadProviderSchemas(ctx context.Context, config *configs.Config, state *sta
 		}
 	}
 
+	ctx, span := tracing.Tracer().Start(
+		ctx, "Load provider schemas",
+	)
+	span.SetAttributes(
+		traceattrs.StringSlice("opentofu.provider.sources", tracing.StringSlice(span, maps.Keys(schemas))),
+	)
+	defer span.End()
+
+	// Each provider is read in a separate worker, at most
+	// schemaLoadConcurrency at a time, since starting providers and reading
+	// their schemas is slow.
 	var wg sync.WaitGroup
 	var lock sync.Mutex
-	lock.Lock() // Prevent anything from started until we have finished schema map reads
-	for fqn := range schemas {
+	sem := NewSemaphore(schemaLoadConcurrency)
+	for _, fqn := range slices.Collect(maps.Keys(schemas)) {
 		wg.Go(func() {
+			sem.Acquire()
+			defer sem.Release()
+
+			ctx, span := tracing.Tracer().Start(
+				ctx, "Load provider schema",
+				tracing.SpanAttributes(
+					traceattrs.String(traceAttrProviderAddr, fqn.String()),
+				),
+			)
+			defer span.End()
+
 			log.Printf("[TRACE] LoadSchemas: retrieving schema for provider type %q", fqn.String())
 			schema, err := plugins.ProviderSchema(ctx, fqn)
+			if err != nil {
+				tracing.SetSpanError(span, err)
+			}
 
 			// Ensure that we don't race on diags or schemas now that the hard work is done
 			lock.Lock()
@@ -122,12 +157,12 @@ func loadProviderSchemas(ctx context.Context, config *configs.Config, state *sta
 		})
 	}
 
-	// Allow execution to start now that reading of schemas map has completed
-	lock.Unlock()
-
 	// Wait for all of the scheduled routines to complete
 	wg.Wait()
 
+	if diags.HasErrors() {
+		tracing.SetSpanError(span, diags)
+	}
 	return schemas, diags
 }
 
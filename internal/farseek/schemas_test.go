@@ -6,11 +6,14 @@ package farseek
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
 	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/states"
 )
 
 // TestResourceTypeConfig checks that ResourceTypeConfig works correctly in all possible combinations:
@@ -312,3 +315,64 @@ func schemaOnlyProvidersForTesting(schemas map[addrs.Provider]providers.Provider
 
 	return newContextPlugins(factories, nil)
 }
+
+func TestLoadProviderSchemas_concurrency(t *testing.T) {
+	const count = schemaLoadConcurrency * 3
+
+	var lock sync.Mutex
+	var active, maxActive int
+	factories := make(map[addrs.Provider]providers.Factory)
+	state := states.BuildState(func(s *states.SyncState) {
+		for i := range count {
+			provider := addrs.NewDefaultProvider(fmt.Sprintf("test%d", i))
+			factories[provider] = func() (providers.Interface, error) {
+				lock.Lock()
+				active++
+				maxActive = max(maxActive, active)
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				active--
+				lock.Unlock()
+				return simpleMockProvider(), nil
+			}
+			s.SetResourceInstanceCurrent(
+				addrs.Resource{
+					Mode: addrs.ManagedResourceMode,
+					Type: provider.Type + "_instance",
+					Name: "foo",
+				}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				&states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{}`),
+					Status:    states.ObjectReady,
+				},
+				addrs.AbsProviderConfig{
+					Provider: provider,
+					Module:   addrs.RootModule,
+				},
+				addrs.NoKey,
+			)
+		}
+	})
+
+	schemas, diags := loadProviderSchemas(context.Background(), nil, state, newContextPlugins(factories, nil))
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if len(schemas) != count {
+		t.Errorf("got %d schemas; want %d", len(schemas), count)
+	}
+	for provider, schema := range schemas {
+		if schema.Provider.Block == nil {
+			t.Errorf("no schema for %s", provider)
+		}
+	}
+	if maxActive > schemaLoadConcurrency {
+		t.Errorf("started %d providers at once; want at most %d", maxActive, schemaLoadConcurrency)
+	}
+	if maxActive < 2 {
+		t.Errorf("providers were started one at a time")
+	}
+}
@@ -11,9 +11,14 @@ import (
 
 const SHAFilename = ".farseek_sha"
 
-// ReadSHA reads the last analyzed commit SHA from the .farseek_sha file.
+// ReadSHA reads the last analyzed commit SHA for the configuration root in
+// dir, from the file given by SHAPath.
 func ReadSHA(dir string) (string, error) {
-	data, err := os.ReadFile(filepath.Join(dir, SHAFilename))
+	path, err := SHAPath(dir)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return "", nil
@@ -23,7 +28,15 @@ func ReadSHA(dir string) (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
-// WriteSHA updates the .farseek_sha file with the given commit SHA.
+// WriteSHA records the given commit SHA for the configuration root in dir,
+// in the file given by SHAPath.
 func WriteSHA(dir, sha string) error {
-	return os.WriteFile(filepath.Join(dir, SHAFilename), []byte(sha), 0644)
+	path, err := SHAPath(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(sha), 0644)
 }
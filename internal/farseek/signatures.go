@@ -0,0 +1,117 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/plans"
+)
+
+// UnverifiedCommit is a commit whose signature couldn't be verified.
+type UnverifiedCommit struct {
+	plans.Commit
+
+	// Reason explains why the signature couldn't be verified, such as "it
+	// isn't signed".
+	Reason string
+}
+
+// VerifyCommits checks the signatures of the commits after fromSHA, up to
+// and including toSHA, and returns those that aren't signed by a trusted
+// key. If fromSHA is empty, it only checks toSHA.
+//
+// Git verifies the signatures, so they can be of any kind that git is
+// configured for, such as GPG, SSH, or Sigstore through gitsign. A key is
+// trusted if git trusts it or, when trustedKeys isn't empty, if its
+// fingerprint or ID is in trustedKeys.
+func (g GitDiscoverer) VerifyCommits(dir, fromSHA, toSHA string, trustedKeys []string) ([]UnverifiedCommit, error) {
+	args := []string{"log", "--format=%H%x00%an%x00%at%x00%G?%x00%GF%x00%GP%x00%GK%x00%s"}
+	if fromSHA == "" {
+		args = append(args, "-1", toSHA)
+	} else {
+		args = append(args, fromSHA+".."+toSHA)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []UnverifiedCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 8)
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("unexpected output %q from git log", line)
+		}
+		secs, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit timestamp %q: %w", fields[2], err)
+		}
+		commit := plans.Commit{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Time:    time.Unix(secs, 0).UTC(),
+			Message: fields[7],
+		}
+		if reason := signatureProblem(fields[3], fields[4:7], trustedKeys); reason != "" {
+			ret = append(ret, UnverifiedCommit{Commit: commit, Reason: reason})
+		}
+	}
+	return ret, nil
+}
+
+// signatureProblem returns why a commit with the given signature status, as
+// reported by git's %G? format, and signing keys isn't signed by a trusted
+// key, or an empty string if it is.
+func signatureProblem(status string, keys []string, trustedKeys []string) string {
+	switch status {
+	case "G", "U":
+		if len(trustedKeys) == 0 {
+			if status == "U" {
+				return "it is signed by a key that git doesn't trust"
+			}
+			return ""
+		}
+		for _, key := range keys {
+			if key != "" && isTrustedKey(key, trustedKeys) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("it is signed by %s, which isn't a trusted key", keys[0])
+	case "N":
+		return "it isn't signed"
+	case "B":
+		return "its signature is bad"
+	case "X":
+		return "its signature has expired"
+	case "Y":
+		return "it is signed by an expired key"
+	case "R":
+		return "it is signed by a revoked key"
+	default:
+		return "its signature can't be checked, for example because the key is missing"
+	}
+}
+
+// isTrustedKey returns true if the given key fingerprint or ID is one of the
+// trusted keys. Hexadecimal GPG fingerprints and IDs match regardless of
+// case, unlike the base64 fingerprints of SSH keys.
+func isTrustedKey(key string, trustedKeys []string) bool {
+	for _, trusted := range trustedKeys {
+		trusted = strings.TrimSpace(trusted)
+		if trusted == key || (!strings.HasPrefix(key, "SHA256:") && strings.EqualFold(trusted, key)) {
+			return true
+		}
+	}
+	return false
+}
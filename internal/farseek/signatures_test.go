@@ -0,0 +1,120 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package farseek
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGitDiscoverer_VerifyCommits(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is needed to sign commits")
+	}
+	dir := t.TempDir()
+	keyDir := t.TempDir()
+	key := filepath.Join(keyDir, "key")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %s\n%s", err, out)
+	}
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowedSigners := filepath.Join(keyDir, "allowed_signers")
+	if err := os.WriteFile(allowedSigners, []byte("you@example.com "+string(pub)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("ssh-keygen", "-l", "-f", key+".pub").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := strings.Fields(string(out))[1]
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "you@example.com")
+	runGit(t, dir, "config", "user.name", "Your Name")
+	runGit(t, dir, "config", "gpg.format", "ssh")
+	runGit(t, dir, "config", "user.signingkey", key)
+	runGit(t, dir, "config", "gpg.ssh.allowedSignersFile", allowedSigners)
+
+	commit := func(message string, sign bool) string {
+		t.Helper()
+		args := []string{"commit", "--allow-empty", "-m", message}
+		if sign {
+			args = append(args, "-S")
+		} else {
+			args = append(args, "--no-gpg-sign")
+		}
+		runGit(t, dir, args...)
+		return getHeadSHA(t, dir)
+	}
+	baseSHA := commit("Initial commit", false)
+	commit("Signed", true)
+	unsignedSHA := commit("Unsigned", false)
+	headSHA := commit("Signed again", true)
+
+	g := GitDiscoverer{}
+	got, err := g.VerifyCommits(dir, baseSHA, headSHA, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].SHA != unsignedSHA || got[0].Message != "Unsigned" || got[0].Reason != "it isn't signed" {
+		t.Errorf("wrong unverified commits %#v", got)
+	}
+
+	// Only the trusted keys can sign.
+	got, err = g.VerifyCommits(dir, unsignedSHA, headSHA, []string{fingerprint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("unexpected unverified commits %#v", got)
+	}
+	got, err = g.VerifyCommits(dir, unsignedSHA, headSHA, []string{"SHA256:other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Reason != "it is signed by "+fingerprint+", which isn't a trusted key" {
+		t.Errorf("wrong unverified commits %#v", got)
+	}
+
+	// Without a base SHA, only the last commit is checked.
+	got, err = g.VerifyCommits(dir, "", unsignedSHA, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].SHA != unsignedSHA {
+		t.Errorf("wrong unverified commits %#v", got)
+	}
+}
+
+func TestSignatureProblem(t *testing.T) {
+	tests := []struct {
+		status      string
+		keys        []string
+		trustedKeys []string
+		want        string
+	}{
+		{"G", []string{"ABCDEF", "", ""}, nil, ""},
+		{"U", []string{"ABCDEF", "", ""}, nil, "it is signed by a key that git doesn't trust"},
+		{"U", []string{"ABCDEF", "", ""}, []string{"abcdef"}, ""},
+		{"G", []string{"ABCDEF", "012345", "2345"}, []string{"2345"}, ""},
+		{"G", []string{"SHA256:abc", "", ""}, []string{"SHA256:ABC"}, "it is signed by SHA256:abc, which isn't a trusted key"},
+		{"N", []string{"", "", ""}, nil, "it isn't signed"},
+		{"B", []string{"ABCDEF", "", ""}, nil, "its signature is bad"},
+		{"E", []string{"", "", ""}, nil, "its signature can't be checked, for example because the key is missing"},
+	}
+	for _, test := range tests {
+		got := signatureProblem(test.status, test.keys, test.trustedKeys)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("wrong result for %s %q trusting %q\n%s", test.status, test.keys, test.trustedKeys, diff)
+		}
+	}
+}
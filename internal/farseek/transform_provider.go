@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/rafagsiqueira/farseek/internal/addrs"
@@ -1006,3 +1007,41 @@ func (t *ProviderConfigTransformer) attachProviderConfigs(g *Graph) error {
 
 	return nil
 }
+
+// providerCycleDiagnostics explains the cycles in a graph that go through a
+// provider configuration, which happen when that configuration refers to
+// data sources or resources that need the same provider configuration.
+func providerCycleDiagnostics(cycles [][]dag.Vertex) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	for _, cycle := range cycles {
+		var provider GraphNodeProvider
+		var others []string
+		for _, v := range cycle {
+			if p, ok := v.(GraphNodeProvider); ok && provider == nil {
+				provider = p
+				continue
+			}
+			if _, ok := v.(GraphNodeCloseProvider); ok {
+				continue
+			}
+			others = append(others, dag.VertexName(v))
+		}
+		if provider == nil {
+			continue
+		}
+
+		diag := &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Provider configuration depends on itself",
+			Detail: fmt.Sprintf(
+				"The configuration of %s depends on %s, which can't be planned without that same provider configuration.\n\nFarseek can wait until apply to configure a provider whose configuration depends on values that are not known yet, but not on values that need the provider itself. Configure the provider from data sources and resources that use another provider configuration, such as one with an alias.",
+				provider.ProviderAddr(), strings.Join(others, ", "),
+			),
+		}
+		if n, ok := provider.(*NodeApplyableProvider); ok && n.ProviderConfig() != nil {
+			diag.Subject = n.ProviderConfig().DeclRange.Ptr()
+		}
+		diags = diags.Append(diag)
+	}
+	return diags
+}
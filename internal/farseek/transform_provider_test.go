@@ -515,7 +515,7 @@ output "output_test" {
 `})
 	concrete := func(a *NodeAbstractProvider) dag.Vertex {
 		return &NodeApplyableProvider{
-			a,
+			NodeAbstractProvider: a,
 		}
 	}
 
@@ -221,57 +221,78 @@ func (t attachResourceDependsOnTransformer) Transform(_ context.Context, g *Grap
 // AttachDependenciesTransformer records all resource dependencies for each
 // instance, and attaches the addresses to the node itself. Managed resource
 // will record these in the state for proper ordering of destroy operations.
+// Provider configurations record them too, to tell during a plan whether
+// their configurations depend on pending changes.
 type AttachDependenciesTransformer struct {
 }
 
 func (t AttachDependenciesTransformer) Transform(_ context.Context, g *Graph) error {
 	for _, v := range g.Vertices() {
-		attacher, ok := v.(GraphNodeAttachDependencies)
-		if !ok {
-			continue
-		}
-		selfAddr := attacher.ResourceAddr()
-
-		ans, err := g.Ancestors(v)
-		if err != nil {
-			return err
-		}
-
-		// dedupe addrs when there's multiple instances involved, or
-		// multiple paths in the un-reduced graph
-		depMap := map[string]addrs.ConfigResource{}
-		for _, d := range ans {
-			var addr addrs.ConfigResource
-
-			switch d := d.(type) {
-			case GraphNodeResourceInstance:
-				instAddr := d.ResourceInstanceAddr()
-				addr = instAddr.ContainingResource().Config()
-			case GraphNodeConfigResource:
-				addr = d.ResourceAddr()
-			default:
-				continue
+		switch v := v.(type) {
+		case GraphNodeAttachDependencies:
+			selfAddr := v.ResourceAddr()
+			ancestors, err := resourceAncestors(g, v)
+			if err != nil {
+				return err
+			}
+			deps := make([]addrs.ConfigResource, 0, len(ancestors))
+			for _, addr := range ancestors {
+				if !addr.Equal(selfAddr) {
+					deps = append(deps, addr)
+				}
 			}
 
-			if addr.Equal(selfAddr) {
-				continue
+			log.Printf("[TRACE] AttachDependenciesTransformer: %s depends on %s", v.ResourceAddr(), deps)
+			v.AttachDependencies(deps)
+		case *NodeApplyableProvider:
+			deps, err := resourceAncestors(g, v)
+			if err != nil {
+				return err
 			}
-			depMap[addr.String()] = addr
+
+			log.Printf("[TRACE] AttachDependenciesTransformer: %s depends on %s", v.Addr, deps)
+			v.dependencies = deps
 		}
+	}
+
+	return nil
+}
+
+// resourceAncestors returns the addresses of the resources that the given
+// vertex depends on, sorted and without duplicates.
+func resourceAncestors(g *Graph, v dag.Vertex) ([]addrs.ConfigResource, error) {
+	ans, err := g.Ancestors(v)
+	if err != nil {
+		return nil, err
+	}
 
-		deps := make([]addrs.ConfigResource, 0, len(depMap))
-		for _, d := range depMap {
-			deps = append(deps, d)
+	// dedupe addrs when there's multiple instances involved, or
+	// multiple paths in the un-reduced graph
+	depMap := map[string]addrs.ConfigResource{}
+	for _, d := range ans {
+		var addr addrs.ConfigResource
+
+		switch d := d.(type) {
+		case GraphNodeResourceInstance:
+			instAddr := d.ResourceInstanceAddr()
+			addr = instAddr.ContainingResource().Config()
+		case GraphNodeConfigResource:
+			addr = d.ResourceAddr()
+		default:
+			continue
 		}
-		sort.Slice(deps, func(i, j int) bool {
-			return deps[i].String() < deps[j].String()
-		})
 
-		log.Printf("[TRACE] AttachDependenciesTransformer: %s depends on %s", attacher.ResourceAddr(), deps)
-		attacher.AttachDependencies(deps)
+		depMap[addr.String()] = addr
 	}
 
-	return nil
+	deps := make([]addrs.ConfigResource, 0, len(depMap))
+	for _, d := range depMap {
+		deps = append(deps, d)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].String() < deps[j].String()
+	})
+	return deps, nil
 }
 
 func isDependableResource(v dag.Vertex) bool {
@@ -38,6 +38,11 @@ func (t *TargetingTransformer) Transform(_ context.Context, g *Graph) error {
 	var targetedNodes dag.Set
 	if len(t.Targets) > 0 {
 		targetedNodes = t.selectTargetedNodes(g, t.Targets)
+		if len(t.Excludes) > 0 {
+			// A stateless operation leaves out some of the objects it
+			// targets, along with their dependents.
+			targetedNodes = targetedNodes.Intersection(t.removeExcludedNodes(g, t.Excludes))
+		}
 	} else if len(t.Excludes) > 0 {
 		targetedNodes = t.removeExcludedNodes(g, t.Excludes)
 	} else {
@@ -8,22 +8,29 @@ import (
 	"github.com/rafagsiqueira/farseek/internal/states"
 )
 
-// updateState calls the PostStateUpdate hook with the state modification function
+// updateState calls the PostStateUpdate hook with the state modification
+// function, or the PostResourceInstanceStateUpdate hook for the hooks that
+// implement ResourceInstanceStateHook.
 func updateStateHook(evalCtx EvalContext, addr addrs.AbsResourceInstance) error {
+	mutate := func(s *states.SyncState) {
+		provider := evalCtx.State().ResourceProvider(addr.ContainingResource())
+		if provider == nil {
+			// If there is no provider currently defined for the resource, it has been removed
+			// See the documentation of ResourceProvider for more details
+			s.RemoveResource(addr.ContainingResource())
+		} else {
+			// The individual instance may be nil, but that can happen when destroying
+			// some but not all instances of a resource (or when that is in-progress).
+			// SetResourceInstance handles that nil correctly and updates the state accordingly.
+			s.SetResourceInstance(addr, evalCtx.State().ResourceInstance(addr), *provider)
+		}
+	}
+
 	// Call the hook
 	return evalCtx.Hook(func(h Hook) (HookAction, error) {
-		return h.PostStateUpdate(func(s *states.SyncState) {
-			provider := evalCtx.State().ResourceProvider(addr.ContainingResource())
-			if provider == nil {
-				// If there is no provider currently defined for the resource, it has been removed
-				// See the documentation of ResourceProvider for more details
-				s.RemoveResource(addr.ContainingResource())
-			} else {
-				// The individual instance may be nil, but that can happen when destroying
-				// some but not all instances of a resource (or when that is in-progress).
-				// SetResourceInstance handles that nil correctly and updates the state accordingly.
-				s.SetResourceInstance(addr, evalCtx.State().ResourceInstance(addr), *provider)
-			}
-		})
+		if h, ok := h.(ResourceInstanceStateHook); ok {
+			return h.PostResourceInstanceStateUpdate(addr, mutate)
+		}
+		return h.PostStateUpdate(mutate)
 	})
 }
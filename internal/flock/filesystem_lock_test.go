@@ -363,3 +363,38 @@ func TestConcurrentLocking(t *testing.T) {
 	t.Logf("Total successful lock acquisitions: %d out of %d attempts",
 		totalSuccesses, numGoroutines*iterations)
 }
+
+func TestLocked_Unlocked(t *testing.T) {
+	// Tests that Locked reports a file that nobody has locked as unlocked,
+	// and doesn't leave it locked
+
+	tmpDir := t.TempDir()
+	lockFile := filepath.Join(tmpDir, "locked.lock")
+
+	if err := os.WriteFile(lockFile, []byte("state"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	f, err := os.Open(lockFile)
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer f.Close()
+
+	locked, err := Locked(f)
+	if err != nil {
+		t.Fatalf("Failed to inspect lock: %v", err)
+	}
+	if locked {
+		t.Fatal("Unlocked file reported as locked")
+	}
+
+	f2, err := os.OpenFile(lockFile, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open test file with a second handler: %v", err)
+	}
+	defer f2.Close()
+	if err := Lock(f2); err != nil {
+		t.Fatalf("Failed to acquire lock after inspecting it: %v", err)
+	}
+	_ = Unlock(f2)
+}
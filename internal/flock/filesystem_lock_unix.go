@@ -90,6 +90,23 @@ func LockBlocking(ctx context.Context, f *os.File) error {
 	}
 }
 
+// Locked reports whether another process holds a lock on the given file,
+// without trying to take it. A lock held by the current process isn't
+// reported.
+func Locked(f *os.File) (bool, error) {
+	flock := &syscall.Flock_t{
+		Type:   syscall.F_WRLCK,
+		Whence: int16(io.SeekStart),
+		Start:  0,
+		Len:    0,
+	}
+
+	if err := syscall.FcntlFlock(f.Fd(), syscall.F_GETLK, flock); err != nil {
+		return false, err
+	}
+	return flock.Type != syscall.F_UNLCK, nil
+}
+
 func Unlock(f *os.File) error {
 	flock := &syscall.Flock_t{
 		Type:   syscall.F_UNLCK,
@@ -12,6 +12,7 @@ package flock
 import (
 	"context"
 	"errors"
+	"io"
 	"log"
 	"math"
 	"os"
@@ -98,6 +99,28 @@ func LockBlocking(ctx context.Context, f *os.File) error {
 	return <-resultChan
 }
 
+// Locked reports whether another process holds a lock on the given file,
+// without trying to take it.
+//
+// Windows has no way to query a lock, but reading from a range that another
+// handle has locked exclusively fails, so Locked reads the first byte. That
+// doesn't work for an empty file, for which Locked returns
+// errors.ErrUnsupported.
+func Locked(f *os.File) (bool, error) {
+	_, err := f.ReadAt(make([]byte, 1), 0)
+	var errno syscall.Errno
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, io.EOF):
+		return false, errors.ErrUnsupported
+	case errors.As(err, &errno) && errno == ERROR_LOCK_VIOLATION:
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
 func Unlock(*os.File) error {
 	// the lock is released when Close() is called
 	return nil
@@ -18,6 +18,7 @@ import (
 	getter "github.com/hashicorp/go-getter"
 
 	"github.com/rafagsiqueira/farseek/internal/copy"
+	"github.com/rafagsiqueira/farseek/internal/tracing"
 )
 
 // We configure our own go-getter detector and getter sets here, because
@@ -190,6 +191,7 @@ func (g *reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAd
 		if err != nil {
 			return fmt.Errorf("failed to copy from %s to %s: %w", prevDir, instPath, err)
 		}
+		tracing.AddToCounter(ctx, "farseek.init.module.cache_hits", "{hit}", "Module packages copied from an earlier fetch in the same run.", 1)
 	} else {
 		log.Printf("[TRACE] getmodules: fetching %q to %q", packageAddr, instPath)
 		client := getter.Client{
@@ -208,6 +210,7 @@ func (g *reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAd
 		if err != nil {
 			return err
 		}
+		tracing.AddToCounter(ctx, "farseek.init.module.fetches", "{fetch}", "Module packages fetched from their source.", 1)
 		// Remember where we installed this so we might reuse this directory
 		// on subsequent calls to avoid re-downloading.
 		g.previousInstalls[packageAddr] = instPath
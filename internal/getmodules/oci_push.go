@@ -0,0 +1,121 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	ociDigest "github.com/opencontainers/go-digest"
+	ociSpecs "github.com/opencontainers/image-spec/specs-go"
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	orasRegistry "oras.land/oras-go/v2/registry"
+
+	"github.com/rafagsiqueira/farseek/internal/tracing"
+	"github.com/rafagsiqueira/farseek/internal/tracing/traceattrs"
+)
+
+// OCIRepositoryPushStore is the interface that [PushOCIModulePackage] uses
+// to publish a module package to a single OCI Distribution repository.
+//
+// As with [OCIRepositoryStore], this intentionally matches a subset of the
+// interfaces defined in the ORAS-Go library.
+type OCIRepositoryPushStore interface {
+	// Exists returns true if the repository already has the content
+	// identified by the digest in the given descriptor.
+	Exists(ctx context.Context, target ociv1.Descriptor) (bool, error)
+
+	// Push uploads the given content, which must match the digest and size
+	// in the given descriptor.
+	Push(ctx context.Context, expected ociv1.Descriptor, content io.Reader) error
+
+	// Tag associates the given tag name with the manifest described by the
+	// given descriptor.
+	Tag(ctx context.Context, desc ociv1.Descriptor, tagName string) error
+}
+
+// PushOCIModulePackage publishes the given zip archive of a module package
+// to the given repository, in the form that the "oci" module source type
+// installs, and then associates the given tag name with it.
+//
+// The result describes the image manifest that refers to the package, whose
+// digest callers can use in a "digest" argument of an "oci" source address.
+func PushOCIModulePackage(ctx context.Context, store OCIRepositoryPushStore, archive []byte, tagName string) (ociv1.Descriptor, error) {
+	ctx, span := tracing.Tracer().Start(
+		ctx, "Push module package",
+		tracing.SpanAttributes(
+			traceattrs.FarseekOCIReferenceTag(tagName),
+		),
+	)
+	defer span.End()
+	prepErr := func(err error) error {
+		tracing.SetSpanError(span, err)
+		return err
+	}
+
+	tagRef := orasRegistry.Reference{Reference: tagName}
+	if err := tagRef.ValidateReferenceAsTag(); err != nil {
+		return ociv1.Descriptor{}, prepErr(err) // message includes suitable context prefix already
+	}
+
+	layerDesc := ociv1.Descriptor{
+		MediaType: ociBlobMediaTypePreference[0],
+		Digest:    ociDigest.FromBytes(archive),
+		Size:      int64(len(archive)),
+	}
+	if err := pushOCIBlobIfMissing(ctx, store, ociv1.DescriptorEmptyJSON, ociv1.DescriptorEmptyJSON.Data); err != nil {
+		return ociv1.Descriptor{}, prepErr(fmt.Errorf("pushing config blob: %w", err))
+	}
+	if err := pushOCIBlobIfMissing(ctx, store, layerDesc, archive); err != nil {
+		return ociv1.Descriptor{}, prepErr(fmt.Errorf("pushing module package blob: %w", err))
+	}
+
+	manifest := &ociv1.Manifest{
+		Versioned: ociSpecs.Versioned{
+			SchemaVersion: 2,
+		},
+		MediaType:    ociv1.MediaTypeImageManifest,
+		ArtifactType: ociIndexManifestArtifactType,
+		Config:       ociv1.DescriptorEmptyJSON,
+		Layers:       []ociv1.Descriptor{layerDesc},
+	}
+	manifestSrc, err := json.Marshal(manifest)
+	if err != nil {
+		return ociv1.Descriptor{}, prepErr(fmt.Errorf("serializing manifest: %w", err))
+	}
+	manifestDesc := ociv1.Descriptor{
+		MediaType:    manifest.MediaType,
+		ArtifactType: manifest.ArtifactType,
+		Digest:       ociDigest.FromBytes(manifestSrc),
+		Size:         int64(len(manifestSrc)),
+	}
+	span.SetAttributes(
+		traceattrs.OCIManifestDigest(manifestDesc.Digest.String()),
+	)
+	if err := pushOCIBlobIfMissing(ctx, store, manifestDesc, manifestSrc); err != nil {
+		return ociv1.Descriptor{}, prepErr(fmt.Errorf("pushing manifest: %w", err))
+	}
+
+	if err := store.Tag(ctx, manifestDesc, tagName); err != nil {
+		return ociv1.Descriptor{}, prepErr(fmt.Errorf("creating tag %q: %w", tagName, err))
+	}
+	return manifestDesc, nil
+}
+
+// pushOCIBlobIfMissing pushes the given content unless the repository
+// already has it, which is common for the empty config blob and when
+// publishing the same package under another tag.
+func pushOCIBlobIfMissing(ctx context.Context, store OCIRepositoryPushStore, desc ociv1.Descriptor, content []byte) error {
+	exists, err := store.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return store.Push(ctx, desc, bytes.NewReader(content))
+}
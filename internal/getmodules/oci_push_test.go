@@ -0,0 +1,104 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-getter"
+	orasMemoryStore "oras.land/oras-go/v2/content/memory"
+)
+
+func TestPushOCIModulePackage(t *testing.T) {
+	store := digestResolvingInMemoryOCIStore{
+		orasMemoryStore.New(),
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, err := zw.Create("main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte(`variable "name" {}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := PushOCIModulePackage(t.Context(), store, buf.Bytes(), "v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if desc.ArtifactType != ociIndexManifestArtifactType {
+		t.Errorf("wrong artifact type %q", desc.ArtifactType)
+	}
+
+	// Publishing the same package again under another tag reuses the blobs
+	// that the repository already has.
+	again, err := PushOCIModulePackage(t.Context(), store, buf.Bytes(), "latest")
+	if err != nil {
+		t.Fatalf("unexpected error pushing again: %s", err)
+	}
+	if again.Digest != desc.Digest {
+		t.Errorf("wrong digest %s for the same package; want %s", again.Digest, desc.Digest)
+	}
+
+	// The installer must then be able to install the package from either
+	// the tag or the digest of the manifest.
+	ociGetter := &ociDistributionGetter{
+		getOCIRepositoryStore: func(ctx context.Context, registryDomain, repositoryName string) (OCIRepositoryStore, error) {
+			return store, nil
+		},
+	}
+	for _, source := range []string{
+		"oci://example.com/main?tag=v1.0.0",
+		"oci://example.com/main",
+		"oci://example.com/main?digest=" + desc.Digest.String(),
+	} {
+		t.Run(source, func(t *testing.T) {
+			instPath := t.TempDir()
+			client := getter.Client{
+				Src:       source,
+				Dst:       instPath,
+				Pwd:       instPath,
+				Mode:      getter.ClientModeDir,
+				Detectors: goGetterNoDetectors,
+				Getters: map[string]getter.Getter{
+					"oci": ociGetter,
+				},
+				Ctx: t.Context(),
+			}
+			if err := client.Get(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			got, err := os.ReadFile(filepath.Join(instPath, "main.tf"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != `variable "name" {}` {
+				t.Errorf("wrong content %q", got)
+			}
+		})
+	}
+}
+
+func TestPushOCIModulePackage_invalidTag(t *testing.T) {
+	store := digestResolvingInMemoryOCIStore{
+		orasMemoryStore.New(),
+	}
+	_, err := PushOCIModulePackage(t.Context(), store, []byte("not used"), "in$valid")
+	if err == nil {
+		t.Fatal("unexpected success")
+	}
+	if got, want := err.Error(), `invalid reference: invalid tag "in$valid"`; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/tracing"
+	"github.com/rafagsiqueira/farseek/internal/tracing/traceattrs"
+)
+
+// downloadProgressInterval is the minimum time between two reports of the
+// progress of the same download, so that fast downloads don't flood the
+// caller with reports.
+const downloadProgressInterval = time.Second
+
+// DownloadProgress is called while a provider package is downloaded, with
+// the number of bytes downloaded so far and the total size of the package,
+// which is -1 if the source didn't report it. It is called at least once,
+// when the download is complete.
+type DownloadProgress func(downloaded, total int64)
+
+type downloadProgressKey struct{}
+
+// ContextWithDownloadProgress returns a context that carries the given
+// function, so that package locations that download packages from the
+// network will report their progress to it.
+func ContextWithDownloadProgress(ctx context.Context, progress DownloadProgress) context.Context {
+	return context.WithValue(ctx, downloadProgressKey{}, progress)
+}
+
+// newDownloadProgressReader wraps r so that reading from it reports progress to the
+// function registered on the given context, if any.
+func newDownloadProgressReader(ctx context.Context, r io.Reader, total int64) *downloadProgressReader {
+	progress, _ := ctx.Value(downloadProgressKey{}).(DownloadProgress)
+	return &downloadProgressReader{r: r, total: total, progress: progress}
+}
+
+type downloadProgressReader struct {
+	r        io.Reader
+	progress DownloadProgress
+
+	total    int64
+	read     int64
+	reported time.Time
+}
+
+func (r *downloadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.progress != nil && time.Since(r.reported) >= downloadProgressInterval {
+		r.progress(r.read, r.total)
+		r.reported = time.Now()
+	}
+	return n, err
+}
+
+// done reports the final size of the download.
+func (r *downloadProgressReader) done() {
+	if r.progress != nil {
+		r.progress(r.read, r.total)
+	}
+}
+
+// countDownload adds a download of the given number of bytes of the provider
+// package that meta describes to the provider download metrics, labeled with
+// the kind of location it came from.
+func countDownload(ctx context.Context, meta PackageMeta, location string, size int64) {
+	attrs := []traceattrs.KeyValue{
+		traceattrs.FarseekProviderAddress(meta.Provider.String()),
+		traceattrs.FarseekProviderVersion(meta.Version.String()),
+		traceattrs.String("farseek.provider.location", location),
+	}
+	tracing.AddToCounter(ctx, "farseek.init.provider.downloads", "{download}", "Provider packages downloaded.", 1, attrs...)
+	tracing.AddToCounter(ctx, "farseek.init.provider.download.size", "By", "Bytes of provider packages downloaded.", size, attrs...)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDownloadProgressReader(t *testing.T) {
+	type report struct{ downloaded, total int64 }
+	var reports []report
+	ctx := ContextWithDownloadProgress(context.Background(), func(downloaded, total int64) {
+		reports = append(reports, report{downloaded, total})
+	})
+
+	r := newDownloadProgressReader(ctx, strings.NewReader("provider package"), 16)
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	r.done()
+
+	// Reports are throttled, so we can only rely on the first read being
+	// reported and on the final report.
+	if len(reports) < 2 {
+		t.Fatalf("got %d progress reports; want at least 2", len(reports))
+	}
+	if got, want := reports[len(reports)-1], (report{16, 16}); got != want {
+		t.Errorf("wrong final report %v; want %v", got, want)
+	}
+}
+
+func TestDownloadProgressReader_noProgress(t *testing.T) {
+	// Without a progress function on the context, the reader must just
+	// pass through the data.
+	r := newDownloadProgressReader(context.Background(), strings.NewReader("provider package"), -1)
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.done()
+	if string(got) != "provider package" {
+		t.Errorf("wrong data %q", got)
+	}
+}
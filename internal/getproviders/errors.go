@@ -231,6 +231,25 @@ func (err ErrRequestCanceled) Error() string {
 	return "request canceled"
 }
 
+// ErrOffline is an error type used to indicate that installing a provider
+// would require network access, because it's in none of the local
+// directories that an offline source consults, but Farseek is running in
+// offline mode.
+type ErrOffline struct {
+	Provider addrs.Provider
+
+	// Source describes the source that would have been consulted over the
+	// network, as returned by its ForDisplay method.
+	Source string
+}
+
+func (err ErrOffline) Error() string {
+	return fmt.Sprintf(
+		"provider %s isn't available locally, and installing it requires network access to %s",
+		err.Provider, err.Source,
+	)
+}
+
 // ErrIsNotExist returns true if and only if the given error is one of the
 // errors from this package that represents an affirmative response that a
 // requested object does not exist.
@@ -0,0 +1,291 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+// FilesystemMirrorServer is an http.Handler that implements the provider
+// network mirror protocol, as consumed by HTTPMirrorSource, for the
+// providers in a local directory using the directory structure defined for
+// FilesystemMirrorSource.
+//
+// The directory is scanned again for each request, so providers added to it
+// while the server is running are served without a restart. Packages in
+// the unpacked layout are served as zip archives built on the fly.
+type FilesystemMirrorServer struct {
+	baseDir string
+
+	// hashes caches the hashes of packages, which are expensive to
+	// calculate, by their location and modification time.
+	mu     sync.Mutex
+	hashes map[mirrorHashKey][]string
+}
+
+type mirrorHashKey struct {
+	location PackageLocation
+	modTime  time.Time
+	size     int64
+}
+
+var _ http.Handler = (*FilesystemMirrorServer)(nil)
+
+// NewFilesystemMirrorServer constructs and returns a new server for the
+// filesystem mirror at the given base directory.
+func NewFilesystemMirrorServer(baseDir string) *FilesystemMirrorServer {
+	return &FilesystemMirrorServer{
+		baseDir: baseDir,
+		hashes:  make(map[mirrorHashKey][]string),
+	}
+}
+
+func (s *FilesystemMirrorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// All of the mirror's URLs have the form HOSTNAME/NAMESPACE/TYPE/FILE.
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+	provider, diags := addrs.ParseProviderSourceString(strings.Join(parts[:3], "/"))
+	if diags.HasErrors() {
+		http.NotFound(w, r)
+		return
+	}
+
+	all, err := SearchLocalDirectory(s.baseDir)
+	if err != nil {
+		log.Printf("[ERROR] Failed to scan provider mirror directory %s: %s", s.baseDir, err)
+		http.Error(w, "failed to read mirror directory", http.StatusInternalServerError)
+		return
+	}
+	metas := all[provider]
+	if len(metas) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch name := parts[3]; {
+	case name == "index.json":
+		s.serveIndex(w, metas)
+	case strings.HasSuffix(name, ".json"):
+		version, err := ParseVersion(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveVersion(w, r, metas, version)
+	default:
+		s.serveArchive(w, r, metas, name)
+	}
+}
+
+func (s *FilesystemMirrorServer) serveIndex(w http.ResponseWriter, metas PackageMetaList) {
+	type ResponseBody struct {
+		Versions map[string]struct{} `json:"versions"`
+	}
+	body := ResponseBody{Versions: make(map[string]struct{})}
+	for _, meta := range metas {
+		body.Versions[meta.Version.String()] = struct{}{}
+	}
+	writeMirrorJSON(w, body)
+}
+
+func (s *FilesystemMirrorServer) serveVersion(w http.ResponseWriter, r *http.Request, metas PackageMetaList, version Version) {
+	type ResponseArchiveMeta struct {
+		RelativeURL string   `json:"url"`
+		Hashes      []string `json:"hashes,omitempty"`
+	}
+	type ResponseBody struct {
+		Archives map[string]*ResponseArchiveMeta `json:"archives"`
+	}
+	body := ResponseBody{Archives: make(map[string]*ResponseArchiveMeta)}
+	for _, meta := range mirrorPackages(metas) {
+		if !meta.Version.Same(version) {
+			continue
+		}
+		hashes, err := s.packageHashes(meta)
+		if err != nil {
+			log.Printf("[ERROR] Failed to hash provider package at %s: %s", meta.Location, err)
+			http.Error(w, "failed to read provider package", http.StatusInternalServerError)
+			return
+		}
+		body.Archives[meta.TargetPlatform.String()] = &ResponseArchiveMeta{
+			RelativeURL: meta.Filename,
+			Hashes:      hashes,
+		}
+	}
+	if len(body.Archives) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	writeMirrorJSON(w, body)
+}
+
+func (s *FilesystemMirrorServer) serveArchive(w http.ResponseWriter, r *http.Request, metas PackageMetaList, filename string) {
+	for _, meta := range mirrorPackages(metas) {
+		if meta.Filename != filename {
+			continue
+		}
+		switch loc := meta.Location.(type) {
+		case PackageLocalArchive:
+			w.Header().Set("Content-Type", "application/zip")
+			http.ServeFile(w, r, string(loc))
+		case PackageLocalDir:
+			w.Header().Set("Content-Type", "application/zip")
+			if r.Method == http.MethodHead {
+				return
+			}
+			if err := writeDirArchive(w, string(loc)); err != nil {
+				// We've probably already sent the headers, so the client
+				// will see a truncated archive that fails its checksum.
+				log.Printf("[ERROR] Failed to archive provider package at %s: %s", loc, err)
+			}
+		}
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// packageHashes returns the hashes to report for the given package, using
+// cached values where the package hasn't changed since they were calculated.
+func (s *FilesystemMirrorServer) packageHashes(meta PackageMeta) ([]string, error) {
+	info, err := os.Stat(meta.Location.String())
+	if err != nil {
+		return nil, err
+	}
+	key := mirrorHashKey{location: meta.Location, modTime: info.ModTime(), size: info.Size()}
+
+	s.mu.Lock()
+	hashes, ok := s.hashes[key]
+	s.mu.Unlock()
+	if ok {
+		return hashes, nil
+	}
+
+	h1, err := PackageHashV1(meta.Location)
+	if err != nil {
+		return nil, err
+	}
+	hashes = []string{h1.String()}
+	if archive, ok := meta.Location.(PackageLocalArchive); ok {
+		zh, err := PackageHashLegacyZipSHA(archive)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, zh.String())
+	}
+
+	s.mu.Lock()
+	s.hashes[key] = hashes
+	s.mu.Unlock()
+	return hashes, nil
+}
+
+// mirrorPackages returns the packages that the mirror serves from the given
+// list, which is at most one per version and platform. A package in the
+// packed layout is preferred over an unpacked copy of the same package,
+// because it can be served as-is.
+func mirrorPackages(metas PackageMetaList) PackageMetaList {
+	type versionPlatform struct {
+		version  string
+		platform Platform
+	}
+	seen := make(map[versionPlatform]int)
+	var ret PackageMetaList
+	for _, meta := range metas {
+		switch meta.Location.(type) {
+		case PackageLocalArchive, PackageLocalDir:
+		default:
+			continue
+		}
+		key := versionPlatform{meta.Version.String(), meta.TargetPlatform}
+		if i, ok := seen[key]; ok {
+			if _, packed := meta.Location.(PackageLocalArchive); packed {
+				ret[i] = meta
+			}
+			continue
+		}
+		seen[key] = len(ret)
+		ret = append(ret, meta)
+	}
+	return ret
+}
+
+// writeDirArchive writes a zip archive of the files in the given unpacked
+// package directory to w.
+func writeDirArchive(w io.Writer, dir string) error {
+	// Package directories in a mirror are often symlinks into a cache.
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// We follow symlinks to files, but not to directories.
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", dir, err)
+	}
+	return zw.Close()
+}
+
+func writeMirrorJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("[ERROR] Failed to write provider mirror response: %s", err)
+	}
+}
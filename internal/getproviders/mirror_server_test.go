@@ -0,0 +1,146 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+func TestFilesystemMirrorServer(t *testing.T) {
+	// The packages in the testdata mirrors are placeholders, so we make a
+	// mirror with real packages in both layouts.
+	mirrorDir := t.TempDir()
+	providerDir := filepath.Join(mirrorDir, "registry.opentofu.org", "hashicorp", "null")
+	unpackedDir := filepath.Join(providerDir, "2.0.0", "linux_amd64")
+	if err := os.MkdirAll(unpackedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(unpackedDir, "terraform-provider-null"), []byte("unpacked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(providerDir, "terraform-provider-null_2.1.0_linux_amd64.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	fw, err := zw.Create("terraform-provider-null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("packed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	httpServer := httptest.NewTLSServer(NewFilesystemMirrorServer(mirrorDir))
+	defer httpServer.Close()
+	baseURL, err := url.Parse(httpServer.URL)
+	if err != nil {
+		t.Fatalf("httptest.NewTLSServer returned a server with an invalid URL")
+	}
+	retryHTTPClient := retryablehttp.NewClient()
+	retryHTTPClient.HTTPClient = httpServer.Client()
+	source := newHTTPMirrorSourceWithHTTPClient(baseURL, nil, retryHTTPClient, LocationConfig{})
+
+	nullProvider := addrs.MustParseProviderSourceString("registry.opentofu.org/hashicorp/null")
+	linuxPlatform := Platform{OS: "linux", Arch: "amd64"}
+
+	// download fetches the package for the given metadata and checks it
+	// against the hashes the mirror reported for it.
+	download := func(t *testing.T, meta PackageMeta) {
+		t.Helper()
+		resp, err := httpServer.Client().Get(meta.Location.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("wrong status %d downloading %s", resp.StatusCode, meta.Location)
+		}
+		archive := filepath.Join(t.TempDir(), meta.Filename)
+		f, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(f, resp.Body); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		if meta.Authentication == nil {
+			t.Fatal("mirror reported no hashes for the package")
+		}
+		if _, err := meta.Authentication.AuthenticatePackage(PackageLocalArchive(archive)); err != nil {
+			t.Fatalf("downloaded package doesn't match its hashes: %s", err)
+		}
+	}
+
+	t.Run("AvailableVersions", func(t *testing.T) {
+		got, _, err := source.AvailableVersions(context.Background(), nullProvider)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := VersionList{
+			MustParseVersion("2.0.0"),
+			MustParseVersion("2.1.0"),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+	t.Run("AvailableVersions for provider that doesn't exist", func(t *testing.T) {
+		_, _, err := source.AvailableVersions(context.Background(), addrs.MustParseProviderSourceString("registry.opentofu.org/hashicorp/missing"))
+		if _, ok := err.(ErrProviderNotFound); !ok {
+			t.Fatalf("wrong error type %T; want ErrProviderNotFound", err)
+		}
+	})
+	t.Run("PackageMeta for a packed package", func(t *testing.T) {
+		meta, err := source.PackageMeta(context.Background(), nullProvider, MustParseVersion("2.1.0"), linuxPlatform)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := meta.Filename, "terraform-provider-null_2.1.0_linux_amd64.zip"; got != want {
+			t.Errorf("wrong filename %q; want %q", got, want)
+		}
+		download(t, meta)
+	})
+	t.Run("PackageMeta for an unpacked package", func(t *testing.T) {
+		meta, err := source.PackageMeta(context.Background(), nullProvider, MustParseVersion("2.0.0"), linuxPlatform)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		download(t, meta)
+	})
+	t.Run("PackageMeta for an unsupported platform", func(t *testing.T) {
+		_, err := source.PackageMeta(context.Background(), nullProvider, MustParseVersion("2.1.0"), Platform{OS: "tos", Arch: "m68k"})
+		if _, ok := err.(ErrPlatformNotSupported); !ok {
+			t.Fatalf("wrong error type %T; want ErrPlatformNotSupported", err)
+		}
+	})
+	t.Run("write methods", func(t *testing.T) {
+		resp, err := httpServer.Client().Post(httpServer.URL+"/registry.opentofu.org/hashicorp/null/index.json", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("wrong status %d; want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+		}
+	})
+}
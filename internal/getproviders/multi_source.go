@@ -45,6 +45,7 @@ func (s MultiSource) AvailableVersions(ctx context.Context, provider addrs.Provi
 	// sources that have matching patterns that accept the given provider.
 	vs := make(map[Version]struct{})
 	var registryError bool
+	var offlineErr error
 	var warnings []string
 	for _, selector := range s {
 		if !selector.CanHandleProvider(provider) {
@@ -59,6 +60,11 @@ func (s MultiSource) AvailableVersions(ctx context.Context, provider addrs.Provi
 			continue // ignore, then
 		case ErrProviderNotFound:
 			continue // ignore, then
+		case ErrOffline:
+			// Another source might have it locally, but if none does
+			// then this error says which network source would have.
+			offlineErr = err
+			continue
 		default:
 			return nil, nil, err
 		}
@@ -71,6 +77,9 @@ func (s MultiSource) AvailableVersions(ctx context.Context, provider addrs.Provi
 	}
 
 	if len(vs) == 0 {
+		if offlineErr != nil {
+			return nil, nil, offlineErr
+		}
 		if registryError {
 			return nil, nil, ErrRegistryProviderNotKnown{provider}
 		} else {
@@ -101,7 +110,7 @@ func (s MultiSource) PackageMeta(ctx context.Context, provider addrs.Provider, v
 		switch err.(type) {
 		case nil:
 			return meta, nil
-		case ErrProviderNotFound, ErrRegistryProviderNotKnown, ErrPlatformNotSupported:
+		case ErrProviderNotFound, ErrRegistryProviderNotKnown, ErrPlatformNotSupported, ErrOffline:
 			continue // ignore, then
 		default:
 			return PackageMeta{}, err
@@ -0,0 +1,75 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+// Offline returns a source that behaves like the given one, except that each
+// of the underlying sources that would make network requests, such as
+// provider registries and network mirrors, is replaced by a source that
+// consults only the given local directories. Those directories, such as the
+// plugin cache, must have the unpacked layout of filesystem mirrors.
+//
+// A provider that the local directories don't have fails with ErrOffline,
+// naming the source that would have been consulted over the network.
+func Offline(ctx context.Context, source Source, dirs []string) Source {
+	var local MultiSource
+	for _, dir := range dirs {
+		local = append(local, MultiSourceSelector{
+			Source: NewFilesystemMirrorSource(ctx, dir),
+		})
+	}
+	return offline(source, local)
+}
+
+func offline(source Source, local MultiSource) Source {
+	switch source := source.(type) {
+	case MultiSource:
+		ret := make(MultiSource, len(source))
+		for i, selector := range source {
+			selector.Source = offline(selector.Source, local)
+			ret[i] = selector
+		}
+		return ret
+	case *MemoizeSource:
+		return offline(source.underlying, local)
+	case *FilesystemMirrorSource:
+		return source
+	default:
+		// Any other source might make network requests.
+		return &offlineSource{network: source, local: local}
+	}
+}
+
+// offlineSource is a Source that stands in for one that makes network
+// requests, in offline mode.
+type offlineSource struct {
+	network Source
+	local   MultiSource
+}
+
+var _ Source = (*offlineSource)(nil)
+
+func (s *offlineSource) AvailableVersions(ctx context.Context, provider addrs.Provider) (VersionList, Warnings, error) {
+	versions, warnings, err := s.local.AvailableVersions(ctx, provider)
+	if err != nil || len(versions) == 0 {
+		return nil, nil, ErrOffline{
+			Provider: provider,
+			Source:   s.network.ForDisplay(provider),
+		}
+	}
+	return versions, warnings, nil
+}
+
+func (s *offlineSource) PackageMeta(ctx context.Context, provider addrs.Provider, version Version, target Platform) (PackageMeta, error) {
+	return s.local.PackageMeta(ctx, provider, version, target)
+}
+
+func (s *offlineSource) ForDisplay(provider addrs.Provider) string {
+	return s.network.ForDisplay(provider) + " (offline)"
+}
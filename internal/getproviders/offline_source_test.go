@@ -0,0 +1,58 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+	"github.com/google/go-cmp/cmp"
+	"github.com/opentofu/svchost"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+func TestOffline(t *testing.T) {
+	awsProvider := addrs.NewProvider(svchost.Hostname("registry.opentofu.org"), "hashicorp", "aws")
+	network := NewMockSource([]PackageMeta{
+		FakePackageMeta(randomProvider, versions.MustParseVersion("9.9.9"), VersionList{versions.MustParseVersion("5.0")}, Platform{"linux", "amd64"}),
+		FakePackageMeta(awsProvider, versions.MustParseVersion("5.0.0"), VersionList{versions.MustParseVersion("5.0")}, Platform{"linux", "amd64"}),
+	}, nil)
+	source := Offline(t.Context(), MultiSource{
+		{Source: NewMemoizeSource(network)},
+	}, []string{"testdata/filesystem-mirror"})
+
+	t.Run("available locally", func(t *testing.T) {
+		got, _, err := source.AvailableVersions(t.Context(), randomProvider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := VersionList{versions.MustParseVersion("1.2.0")}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong versions\n%s", diff)
+		}
+
+		meta, err := source.PackageMeta(t.Context(), randomProvider, versions.MustParseVersion("1.2.0"), Platform{"linux", "amd64"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantLocation := PackageLocalDir(filepath.FromSlash("testdata/filesystem-mirror/registry.opentofu.org/hashicorp/random/1.2.0/linux_amd64"))
+		if meta.Location != wantLocation {
+			t.Errorf("wrong location %q; want %q", meta.Location, wantLocation)
+		}
+	})
+
+	t.Run("requires network", func(t *testing.T) {
+		_, _, err := source.AvailableVersions(t.Context(), awsProvider)
+		want := ErrOffline{Provider: awsProvider, Source: "mock source"}
+		if err != want {
+			t.Fatalf("wrong error %#v; want %#v", err, want)
+		}
+	})
+
+	if calls := network.CallLog(); len(calls) != 0 {
+		t.Errorf("network source was called: %#v", calls)
+	}
+}
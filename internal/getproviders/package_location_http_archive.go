@@ -97,20 +97,27 @@ func (p PackageHTTPURL) InstallProviderPackage(ctx context.Context, meta Package
 
 	// We'll borrow go-getter's "cancelable copy" implementation here so that
 	// the download can potentially be interrupted partway through.
-	n, err := getter.Copy(ctx, f, resp.Body)
+	body := newDownloadProgressReader(ctx, resp.Body, resp.ContentLength)
+	n, err := getter.Copy(ctx, f, body)
 	if err == nil && n < resp.ContentLength {
 		err = fmt.Errorf("incorrect response size: expected %d bytes, but got %d bytes", resp.ContentLength, n)
 	}
 	if err != nil {
 		return nil, err
 	}
+	body.done()
+	countDownload(ctx, meta, "http", n)
 
 	archiveFilename := f.Name()
 	localLocation := PackageLocalArchive(archiveFilename)
 
 	var authResult *PackageAuthenticationResult
 	if meta.Authentication != nil {
-		if authResult, err = meta.Authentication.AuthenticatePackage(localLocation); err != nil {
+		_, authSpan := tracing.Tracer().Start(ctx, "Authenticate (http)")
+		authResult, err = meta.Authentication.AuthenticatePackage(localLocation)
+		tracing.SetSpanError(authSpan, err)
+		authSpan.End()
+		if err != nil {
 			return authResult, err
 		}
 	}
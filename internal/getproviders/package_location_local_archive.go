@@ -9,9 +9,11 @@ package getproviders
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/hashicorp/go-getter"
 
@@ -39,13 +41,66 @@ var _ PackageLocation = PackageLocalArchive("")
 func (p PackageLocalArchive) String() string { return string(p) }
 
 func (p PackageLocalArchive) InstallProviderPackage(ctx context.Context, meta PackageMeta, targetDir string, allowedHashes []Hash) (*PackageAuthenticationResult, error) {
-	_, span := tracing.Tracer().Start(ctx, "Decompress (local archive)")
+	ctx, span := tracing.Tracer().Start(ctx, "Decompress (local archive)")
+	defer span.End()
+
+	authResult, err := verifyLocalArchive(ctx, meta, allowedHashes)
+	if err != nil {
+		tracing.SetSpanError(span, err)
+		return authResult, err
+	}
+
+	filename := meta.Location.String()
+	span.SetAttributes(traceattrs.FilePath(filename))
+
+	// NOTE: Packages are immutable, but we may want to skip overwriting the existing
+	// files in due to specific scenarios defined below.
+
+	if _, err := os.Stat(targetDir); err == nil {
+		// If the package might already be installed, we should try to skip overwriting the contents.
+		// When run with TF_PLUGIN_CACHE_DIR or similar, a given provider might already be executing
+		// and therefore locking the provider binary in the target directory (preventing the overwrite below)
+		//
+		// This does incur the overhead of two additional hash computations and could be
+		// skipped with smarter checks around re-use scenarios in the future.
+
+		targetHash, targetErr := PackageHashV1(PackageLocalDir(targetDir))
+		fileHash, fileErr := PackageHashV1(meta.Location)
+
+		if targetHash == fileHash && fileErr == nil && targetErr == nil {
+			// Package is properly installed, bad or missing lock file will be caught elsewhere
+			log.Printf("[INFO] Skipping local installation of provider %s %s as the existing contents already match the new contents", meta.Provider, meta.Version)
+			return authResult, nil
+		}
+	}
+
+	err = installAtomically(targetDir, func(dir string) error {
+		//nolint:mnd // magic number predates us using this linter
+		return unzip.Decompress(dir, filename, true, 0000)
+	})
+	if err != nil {
+		tracing.SetSpanError(span, err)
+		return authResult, err
+	}
+
+	return authResult, nil
+}
+
+// verifyLocalArchive authenticates the local archive that meta describes, if
+// meta includes an authentication method, and checks that it matches one of
+// the allowed hashes, if any.
+func verifyLocalArchive(ctx context.Context, meta PackageMeta, allowedHashes []Hash) (*PackageAuthenticationResult, error) {
+	_, span := tracing.Tracer().Start(ctx, "Verify (local archive)", tracing.SpanAttributes(
+		traceattrs.FarseekProviderAddress(meta.Provider.String()),
+		traceattrs.FarseekProviderVersion(meta.Version.String()),
+	))
 	defer span.End()
 
 	var authResult *PackageAuthenticationResult
 	if meta.Authentication != nil {
 		var err error
 		if authResult, err = meta.Authentication.AuthenticatePackage(meta.Location); err != nil {
+			tracing.SetSpanError(span, err)
 			return nil, err
 		}
 	}
@@ -67,37 +122,79 @@ func (p PackageLocalArchive) InstallProviderPackage(ctx context.Context, meta Pa
 			return authResult, err
 		}
 	}
+	return authResult, nil
+}
 
-	filename := meta.Location.String()
-	span.SetAttributes(traceattrs.FilePath(filename))
-
-	// NOTE: Packages are immutable, but we may want to skip overwriting the existing
-	// files in due to specific scenarios defined below.
+// installAtomically unpacks a package into a new directory next to
+// targetDir, using the given function, and then renames it into place. Other
+// processes sharing a cache directory, such as parallel CI jobs with the same
+// TF_PLUGIN_CACHE_DIR, therefore never see a partially-unpacked package, and
+// an interrupted installation leaves no broken package behind.
+func installAtomically(targetDir string, unpack func(dir string) error) error {
+	parentDir := filepath.Dir(targetDir)
+	//nolint:mnd // directory permissions
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return fmt.Errorf("failed to create parent directories leading to %s: %w", targetDir, err)
+	}
+	stagingDir, err := os.MkdirTemp(parentDir, stagingPrefix(targetDir))
+	if err != nil {
+		return fmt.Errorf("failed to create a staging directory for %s: %w", targetDir, err)
+	}
+	// This is a no-op once the staging directory has been renamed.
+	defer os.RemoveAll(stagingDir)
 
-	if _, err := os.Stat(targetDir); err == nil {
-		// If the package might already be installed, we should try to skip overwriting the contents.
-		// When run with TF_PLUGIN_CACHE_DIR or similar, a given provider might already be executing
-		// and therefore locking the provider binary in the target directory (preventing the overwrite below)
-		//
-		// This does incur the overhead of two additional hash computations and could be
-		// skipped with smarter checks around re-use scenarios in the future.
+	if err := unpack(stagingDir); err != nil {
+		return err
+	}
+	// MkdirTemp creates the directory only for the current user.
+	//nolint:mnd // directory permissions
+	if err := os.Chmod(stagingDir, 0755); err != nil {
+		return err
+	}
 
-		targetHash, targetErr := PackageHashV1(PackageLocalDir(targetDir))
-		fileHash, fileErr := PackageHashV1(meta.Location)
+	if err := os.Rename(stagingDir, targetDir); err == nil {
+		return nil
+	}
 
-		if targetHash == fileHash && fileErr == nil && targetErr == nil {
-			// Package is properly installed, bad or missing lock file will be caught elsewhere
-			log.Printf("[INFO] Skipping local installation of provider %s %s as the existing contents already match the new contents", meta.Provider, meta.Version)
-			return authResult, nil
+	// The target directory exists, so we move it aside before renaming the
+	// new one into place, and restore it if that fails.
+	oldDir := stagingDir + ".old"
+	if err := os.Rename(targetDir, oldDir); err != nil {
+		return fmt.Errorf("failed to replace the existing package at %s: %w", targetDir, err)
+	}
+	if err := os.Rename(stagingDir, targetDir); err != nil {
+		if restoreErr := os.Rename(oldDir, targetDir); restoreErr != nil {
+			log.Printf("[WARN] failed to restore the previous package at %s: %s", targetDir, restoreErr)
 		}
+		return fmt.Errorf("failed to install the package at %s: %w", targetDir, err)
 	}
+	if err := os.RemoveAll(oldDir); err != nil {
+		log.Printf("[WARN] failed to remove the previous package at %s: %s", oldDir, err)
+	}
+	return nil
+}
 
-	//nolint:mnd // magic number predates us using this linter
-	err := unzip.Decompress(targetDir, filename, true, 0000)
+// stagingPrefix returns the prefix of the names of the directories that
+// packages are unpacked into before they're renamed to targetDir. They're
+// hidden, and aren't valid platform names, so that searches for packages
+// ignore them.
+func stagingPrefix(targetDir string) string {
+	return "." + filepath.Base(targetDir) + ".tmp-"
+}
+
+// RemoveStaleInstalls removes the staging directories that interrupted
+// installations into targetDir left behind. The caller must hold a lock that
+// excludes other installations into targetDir, whose staging directories
+// would otherwise be removed while in use.
+func RemoveStaleInstalls(targetDir string) error {
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(targetDir), stagingPrefix(targetDir)+"*"))
 	if err != nil {
-		tracing.SetSpanError(span, err)
-		return authResult, err
+		return err
 	}
-
-	return authResult, nil
+	var errs []error
+	for _, dir := range matches {
+		log.Printf("[TRACE] getproviders: removing %s, left behind by an interrupted installation", dir)
+		errs = append(errs, os.RemoveAll(dir))
+	}
+	return errors.Join(errs...)
 }
@@ -141,6 +141,7 @@ func (p PackageOCIBlobArchive) InstallProviderPackage(ctx context.Context, meta
 		return nil, prepErr(fmt.Errorf("fetching provider package blob %s: %w", pkgDesc.Digest.String(), err))
 	}
 	defer os.Remove(string(localLoc)) // Best effort to remove the temporary file before we return
+	countDownload(ctx, meta, "oci", pkgDesc.Size)
 
 	// We'll now delegate the final installation step to the localLoc object,
 	// which knows how to extract the temporary archive into the target
@@ -218,7 +219,7 @@ func fetchOCIBlobToTemporaryFile(ctx context.Context, desc ociv1.Descriptor, sto
 		return PackageLocalArchive(""), err
 	}
 	defer readCloser.Close()
-	reader := io.LimitReader(readCloser, desc.Size)
+	reader := newDownloadProgressReader(ctx, io.LimitReader(readCloser, desc.Size), desc.Size)
 
 	f, err := os.CreateTemp("", "opentofu-provider")
 	if err != nil {
@@ -247,6 +248,7 @@ func fetchOCIBlobToTemporaryFile(ctx context.Context, desc ociv1.Descriptor, sto
 	if err != nil {
 		return loc, err
 	}
+	reader.done()
 
 	// Before we return we'll make sure that the file we've just created matches
 	// the digest we were given for it, since the ORAS fetcher doesn't do that
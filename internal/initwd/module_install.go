@@ -41,6 +41,10 @@ type ModuleInstaller struct {
 	reg     *registry.Client
 	fetcher *getmodules.PackageFetcher
 
+	// offline is true if modules whose installation requires network access
+	// must fail instead.
+	offline bool
+
 	// The keys in moduleVersions are resolved and trimmed registry source
 	// addresses and the values are the registry response.
 	registryPackageVersions map[addrs.ModuleRegistryPackage]*response.ModuleVersions
@@ -84,6 +88,14 @@ func NewModuleInstaller(modsDir string, loader *configload.Loader, registryClien
 	}
 }
 
+// SetOffline sets whether the installer runs in offline mode, in which
+// modules that aren't installed yet fail if installing them requires network
+// access, as for registry and remote module sources. Modules that are
+// already installed, and local modules, are unaffected.
+func (i *ModuleInstaller) SetOffline(offline bool) {
+	i.offline = offline
+}
+
 // isSubDirNonExistent checks if the error is due to a non-existent subdirectory
 // within an otherwise valid module. This helps distinguish between a genuine
 // Farseek bug when failing to get the module and a user configuration error where they've specified a
@@ -339,6 +351,11 @@ func (i *ModuleInstaller) moduleInstallWalker(_ context.Context, manifest modsdi
 			// the module. There are some variants to this process depending
 			// on what type of module source address we have.
 
+			if i.offline && !isLocalModuleSource(req.SourceAddr) {
+				diags = diags.Append(offlineModuleDiagnostic(req))
+				return nil, nil, diags
+			}
+
 			switch addr := req.SourceAddr.(type) {
 
 			case addrs.ModuleSourceLocal:
@@ -598,6 +615,7 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 	modMeta := resp.Modules[0]
 
 	var latestMatch *version.Version
+	var latestMatchMeta *response.ModuleVersion
 	var latestVersion *version.Version
 	for _, mv := range modMeta.Versions {
 		v, err := version.NewVersion(mv.Version)
@@ -712,6 +730,7 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 		if req.VersionConstraint.Required.Check(v) {
 			if latestMatch == nil || v.GreaterThan(latestMatch) {
 				latestMatch = v
+				latestMatchMeta = mv
 			}
 		}
 	}
@@ -863,11 +882,19 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 	}
 
 	// Note the local location in our manifest.
+	var deprecation *modsdir.RecordDeprecation
+	if dep := latestMatchMeta.Deprecation; dep != nil {
+		deprecation = &modsdir.RecordDeprecation{
+			Reason:      dep.Reason,
+			Replacement: dep.Replacement,
+		}
+	}
 	manifest[key] = modsdir.Record{
-		Key:        key,
-		Version:    latestMatch,
-		Dir:        modDir,
-		SourceAddr: req.SourceAddr.String(),
+		Key:         key,
+		Version:     latestMatch,
+		Dir:         modDir,
+		SourceAddr:  req.SourceAddr.String(),
+		Deprecation: deprecation,
 	}
 	log.Printf("[DEBUG] Module installer: %s installed at %s", key, modDir)
 	hooks.Install(key, latestMatch, modDir)
@@ -1001,6 +1028,27 @@ func (i *ModuleInstaller) packageInstallPath(modulePath addrs.Module) string {
 	return filepath.Join(i.modsDir, strings.Join(modulePath, "."))
 }
 
+func isLocalModuleSource(addr addrs.ModuleSource) bool {
+	_, ok := addr.(addrs.ModuleSourceLocal)
+	return ok
+}
+
+// offlineModuleDiagnostic returns the error for a module that isn't
+// installed yet when the installer runs in offline mode, naming the module
+// call and the source address that would need the network.
+func offlineModuleDiagnostic(req *configs.ModuleRequest) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Module requires network access",
+		Detail: fmt.Sprintf(
+			"Module %s isn't installed, and installing it from %q requires network access, which isn't allowed in offline mode.\n\nRun \"farseek init\" without offline mode on a machine with network access to install the module, or change its source to a local path.",
+			req.Path, req.SourceAddr.ForDisplay(),
+		),
+		Subject: req.SourceAddrRange.Ptr(),
+		Extra:   tfdiags.CodeExtra(tfdiags.CodeOfflineNetworkRequired),
+	}
+}
+
 // maybeImproveLocalInstallError is a helper function which can recognize
 // some specific situations where it can return a more helpful error message
 // and thus replace the given errors with those if so.
@@ -402,6 +402,46 @@ func TestModuleInstaller_invalidVersionConstraintLocal(t *testing.T) {
 	}
 }
 
+func TestModuleInstaller_offline(t *testing.T) {
+	fixtureDir := filepath.Clean("testdata/registry-modules")
+	dir := tempChdir(t, fixtureDir)
+
+	hooks := &testInstallHooks{}
+
+	modulesDir := filepath.Join(dir, ".terraform/modules")
+
+	loader := configload.NewLoaderForTests(t)
+	inst := NewModuleInstaller(modulesDir, loader, nil, nil)
+	inst.SetOffline(true)
+	_, diags := inst.InstallModules(context.Background(), ".", "tests", false, false, hooks, configs.RootModuleCallForTesting())
+
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	got := 0
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Error {
+			continue
+		}
+		if diag.Description().Summary != "Module requires network access" {
+			t.Errorf("unexpected error: %s: %s", diag.Description().Summary, diag.Description().Detail)
+			continue
+		}
+		if code := tfdiags.DiagnosticCode(diag); code != tfdiags.CodeOfflineNetworkRequired {
+			t.Errorf("wrong code %q", code)
+		}
+		got++
+	}
+	if got != 3 {
+		t.Errorf("wrong number of errors %d; want one for each registry module", got)
+	}
+	for _, rec := range hooks.Calls {
+		if rec.Name == "Download" {
+			t.Errorf("unexpected download of %s", rec.ModuleAddr)
+		}
+	}
+}
+
 func TestModuleInstaller_symlink(t *testing.T) {
 	fixtureDir := filepath.Clean("testdata/local-module-symlink")
 	dir := tempChdir(t, fixtureDir)
@@ -134,3 +134,98 @@ func BenchmarkManyResourceInstances(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkWideDiamond covers a "diamond-shaped" configuration where many
+// resource instances all depend on the same upstream objects, both directly
+// and through other resource instances that depend on them. Each object
+// must be evaluated only once per walk regardless of how many other objects
+// refer to it, so the cost of this should grow linearly with the number of
+// instances.
+//
+//	go test ./internal/lang/eval -bench='^BenchmarkWideDiamond$'
+func BenchmarkWideDiamond(b *testing.B) {
+	// instanceCount is the number of instances we declare for each of the
+	// two wide resources, so there are 2*instanceCount+1 instances total.
+	const instanceCount = 1000
+	configInst, diags := eval.NewConfigInstance(b.Context(), &eval.ConfigCall{
+		EvalContext: evalglue.EvalContextForTesting(b, &eval.EvalContext{
+			Modules: eval.ModulesForTesting(map[addrs.ModuleSourceLocal]*configs.Module{
+				addrs.ModuleSourceLocal("."): configs.ModuleFromStringForTesting(b, `
+					# Every instance of "mid" refers to the validated variable
+					# and to "root", and every instance of "leaf" refers to
+					# those and also to the whole of "mid".
+
+					terraform {
+						required_providers {
+							foo = {
+								source = "test/foo"
+							}
+						}
+					}
+
+					variable "instance_count" {
+						type = number
+					}
+
+					variable "base" {
+						type = number
+
+						validation {
+							condition     = var.base >= 0
+							error_message = "Must not be negative."
+						}
+					}
+
+					resource "foo" "root" {
+						num = var.base
+					}
+
+					resource "foo" "mid" {
+						count = var.instance_count
+
+						num = foo.root.num + var.base + count.index
+					}
+
+					resource "foo" "leaf" {
+						count = var.instance_count
+
+						num = foo.mid[count.index].num + length(foo.mid) + var.base
+					}
+				`),
+			}),
+			Providers: eval.ProvidersForTesting(map[addrs.Provider]*providers.GetProviderSchemaResponse{
+				addrs.MustParseProviderSourceString("test/foo"): {
+					ResourceTypes: map[string]providers.Schema{
+						"foo": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"num": {
+										Type:     cty.Number,
+										Required: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+		}),
+		RootModuleSource: addrs.ModuleSourceLocal("."),
+		InputValues: eval.InputValuesForTesting(map[string]cty.Value{
+			"instance_count": cty.NumberIntVal(instanceCount),
+			"base":           cty.NumberIntVal(1),
+		}),
+	})
+	if diags.HasErrors() {
+		b.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	b.ResetTimer() // the above setup code is not included in the benchmark
+
+	for b.Loop() {
+		diags = configInst.Validate(b.Context())
+		if diags.HasErrors() {
+			b.Fatalf("unexpected errors: %s", diags.Err())
+		}
+	}
+}
@@ -9,6 +9,7 @@ package eval
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/apparentlymart/go-versions/versions"
 
@@ -28,8 +29,14 @@ type ConfigInstance struct {
 	inputValues          exprs.Valuer
 	evalContext          *evalglue.EvalContext
 	allowImpureFunctions bool
+	parallelism          int
 }
 
+// DefaultParallelism is the number of objects in the configuration that
+// are checked concurrently when [ConfigCall.Parallelism] is zero. It matches
+// the default of the -parallelism command line option.
+const DefaultParallelism = 10
+
 // ConfigCall describes a call to a root module that acts conceptually like
 // a "module" block but is instead implied by something outside of the
 // module language itself, such as running an Farseek CLI command.
@@ -66,6 +73,11 @@ type ConfigCall struct {
 	// with cross-cutting concerns like which providers are available and how
 	// to load them.
 	EvalContext *evalglue.EvalContext
+
+	// Parallelism limits how many objects in the configuration, such as
+	// resource instances, are checked concurrently while walking the
+	// configuration tree. Zero means to use [DefaultParallelism].
+	Parallelism int
 }
 
 // NewConfigInstance builds a new [ConfigInstance] based on the information
@@ -82,19 +94,28 @@ type ConfigCall struct {
 func NewConfigInstance(ctx context.Context, call *ConfigCall) (*ConfigInstance, tfdiags.Diagnostics) {
 	call.EvalContext.AssertValid()
 
+	var diags tfdiags.Diagnostics
+	if call.Parallelism < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid parallelism value",
+			fmt.Sprintf("The parallelism must be a positive value. Not %d.", call.Parallelism),
+		))
+		return nil, diags
+	}
+
 	inst := &ConfigInstance{
 		rootModuleSource:     call.RootModuleSource,
 		inputValues:          call.InputValues,
 		allowImpureFunctions: call.AllowImpureFunctions,
 		evalContext:          call.EvalContext,
+		parallelism:          call.Parallelism,
 	}
 
 	// We currently don't do any other early work here and instead just wait
 	// until we're asked a more specific question using one of the methods
-	// of the result. If that continues to be true then perhaps we'll drop
-	// the tfdiags.Diagnostics result from this function to be clearer
-	// that it's purely a constructor and doesn't do any "real work".
-	return inst, nil
+	// of the result.
+	return inst, diags
 }
 
 // EvalContext returns the [EvalContext] that the [ConfigInstance] would use
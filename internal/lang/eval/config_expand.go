@@ -0,0 +1,68 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package eval
+
+import (
+	"context"
+	"slices"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/lang/eval/internal/evalglue"
+	"github.com/rafagsiqueira/farseek/internal/lang/grapheval"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// ExpandModules evaluates the configuration instance just enough to decide
+// the instances of each module call, and returns the addresses of all of
+// the module instances in the configuration tree, starting with the root
+// module instance.
+//
+// This uses unknown value placeholders for all resource instances, in the
+// same way as [ConfigInstance.Validate], and so doesn't need to configure
+// any providers. When the instances of a module call depend on a value that
+// isn't known until the apply phase, the result includes a single placeholder
+// instance of that call whose key is an [addrs.WildcardKey], standing in for
+// zero or more instances.
+//
+// Unlike [ConfigInstance.Validate], this only reports problems with the
+// arguments that decide the module instances, and so success of this method
+// DOES NOT imply that the configuration is valid.
+//
+// The addresses are in the order of [addrs.ModuleInstance.Less]. If the
+// diagnostics have errors then the result is nil.
+func (c *ConfigInstance) ExpandModules(ctx context.Context) ([]addrs.ModuleInstance, tfdiags.Diagnostics) {
+	// All of our work will be associated with a workgraph worker that serves
+	// as the initial worker node in the work graph.
+	ctx = grapheval.ContextWithNewWorker(ctx)
+
+	internalGlue := &preparationGlue{
+		providers: c.evalContext.Providers,
+	}
+	rootModuleInstance, diags := c.newRootModuleInstance(ctx, internalGlue)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var ret []addrs.ModuleInstance
+	for addr, moduleInst := range evalglue.ModuleInstancesDeep(ctx, rootModuleInstance) {
+		diags = diags.Append(moduleInst.CheckChildModuleCalls(ctx))
+		// ModuleInstancesDeep reuses the backing array of its addresses, so
+		// we must copy them to keep them.
+		ret = append(ret, slices.Clip(slices.Clone(addr)))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	slices.SortFunc(ret, func(a, b addrs.ModuleInstance) int {
+		switch {
+		case a.Less(b):
+			return -1
+		case b.Less(a):
+			return 1
+		default:
+			return 0
+		}
+	})
+	return ret, diags
+}
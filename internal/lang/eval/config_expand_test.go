@@ -0,0 +1,135 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/lang/eval"
+	"github.com/rafagsiqueira/farseek/internal/lang/eval/internal/evalglue"
+)
+
+func TestExpandModules(t *testing.T) {
+	configInst, diags := eval.NewConfigInstance(t.Context(), &eval.ConfigCall{
+		EvalContext: evalglue.EvalContextForTesting(t, &eval.EvalContext{
+			Modules: eval.ModulesForTesting(map[addrs.ModuleSourceLocal]*configs.Module{
+				addrs.ModuleSourceLocal("."): configs.ModuleFromStringForTesting(t, `
+					variable "names" {
+						type = set(string)
+					}
+					variable "unknown" {
+						type = number
+					}
+					module "single" {
+						source = "./child"
+					}
+					module "counted" {
+						source = "./child"
+						count  = 2
+					}
+					module "each" {
+						source   = "./child"
+						for_each = var.names
+					}
+					module "none" {
+						source = "./child"
+						count  = 0
+					}
+					module "unknown" {
+						source = "./child"
+						count  = var.unknown
+					}
+				`),
+				addrs.ModuleSourceLocal("./child"): configs.ModuleFromStringForTesting(t, `
+					variable "copies" {
+						type    = number
+						default = 1
+					}
+					module "grandchild" {
+						source = "./grandchild"
+						count  = var.copies
+					}
+				`),
+				addrs.ModuleSourceLocal("./child/grandchild"): configs.ModuleFromStringForTesting(t, ``),
+			}),
+		}),
+		RootModuleSource: addrs.ModuleSourceLocal("."),
+		InputValues: eval.InputValuesForTesting(map[string]cty.Value{
+			"names":   cty.SetVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			"unknown": cty.UnknownVal(cty.Number),
+		}),
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	got, diags := configInst.ExpandModules(t.Context())
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	var gotStrs []string
+	for _, addr := range got {
+		gotStrs = append(gotStrs, addr.String())
+	}
+	want := []string{
+		"",
+		"module.counted[0]",
+		"module.counted[1]",
+		`module.each["a"]`,
+		`module.each["b"]`,
+		"module.single",
+		"module.unknown[*]",
+		"module.counted[0].module.grandchild[0]",
+		"module.counted[1].module.grandchild[0]",
+		`module.each["a"].module.grandchild[0]`,
+		`module.each["b"].module.grandchild[0]`,
+		"module.single.module.grandchild[0]",
+		"module.unknown[*].module.grandchild[0]",
+	}
+	if diff := cmp.Diff(want, gotStrs); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestExpandModules_invalidCount(t *testing.T) {
+	configInst, diags := eval.NewConfigInstance(t.Context(), &eval.ConfigCall{
+		EvalContext: evalglue.EvalContextForTesting(t, &eval.EvalContext{
+			Modules: eval.ModulesForTesting(map[addrs.ModuleSourceLocal]*configs.Module{
+				addrs.ModuleSourceLocal("."): configs.ModuleFromStringForTesting(t, `
+					module "child" {
+						source = "./child"
+					}
+				`),
+				addrs.ModuleSourceLocal("./child"): configs.ModuleFromStringForTesting(t, `
+					module "grandchild" {
+						source = "./grandchild"
+						count  = "many"
+					}
+				`),
+				addrs.ModuleSourceLocal("./child/grandchild"): configs.ModuleFromStringForTesting(t, ``),
+			}),
+		}),
+		RootModuleSource: addrs.ModuleSourceLocal("."),
+		InputValues:      eval.InputValuesForTesting(map[string]cty.Value{}),
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	got, diags := configInst.ExpandModules(t.Context())
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success; want error about the count")
+	}
+	if summary := diags[0].Description().Summary; summary != "Invalid value for instance count" {
+		t.Errorf("wrong error %q", summary)
+	}
+	if got != nil {
+		t.Errorf("unexpected result %#v", got)
+	}
+}
@@ -162,7 +162,7 @@ func (c *ConfigInstance) DrivePlanning(ctx context.Context, buildGlue func(*Plan
 	var orphanDiags tfdiags.Diagnostics
 	wg.Go(func() {
 		ctx := grapheval.ContextWithNewWorker(ctx)
-		checkDiags = checkAll(ctx, rootModuleInstance)
+		checkDiags = checkAll(ctx, rootModuleInstance, c.parallelism)
 	})
 	wg.Go(func() {
 		ctx := grapheval.ContextWithNewWorker(ctx)
@@ -156,7 +156,7 @@ func (c *ConfigInstance) precheckedModuleInstance(ctx context.Context) (evalglue
 	// For validation purposes we don't need to do anything other than the
 	// full-tree check that would normally run alongside the driving of
 	// some other operation.
-	moreDiags = checkAll(ctx, rootModuleInstance)
+	moreDiags = checkAll(ctx, rootModuleInstance, c.parallelism)
 	diags = diags.Append(moreDiags)
 	return rootModuleInstance, diags
 }
@@ -206,15 +206,11 @@ func (v *preparationGlue) ResourceInstanceValue(ctx context.Context, ri *configg
 	// We now have enough information to produce a placeholder "planned new
 	// state" by placing unknown values in any location that the provider
 	// would be allowed to choose a value.
-	// NOTE: With the implementation of this function as of commit
-	// dd5257d58e27b1af3b8dde97c80daec97f6ca55e this shows as a pretty
-	// hot path in CPU profiling, which is not a huge surprise -- we've
-	// known it as relatively expensive from its use in "package farseek"
-	// already -- but it stands out more in this new implementation because
-	// it's not competing with other expensive work like performing transitive
-	// reduction on a dag, etc. The main problem seems to be that it allocates
-	// a _lot_ of temporary objects, and so there's lots of GC pressure.
-	return objchange.ProposedNew(
-		schema.Block, cty.NullVal(schema.Block.ImpliedType()), configVal,
-	), diags
+	// NOTE: This used to call objchange.ProposedNew with a null prior
+	// state, which showed as a pretty hot path in CPU profiling because it
+	// allocates a _lot_ of temporary objects. With no prior state to merge,
+	// objchange.PlannedUnknownObject produces the same placeholder by
+	// walking only the schema and the configuration, and so with much less
+	// GC pressure.
+	return objchange.PlannedUnknownObject(schema.Block, configVal), diags
 }
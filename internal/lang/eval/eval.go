@@ -13,6 +13,7 @@ import (
 
 	"github.com/apparentlymart/go-workgraph/workgraph"
 
+	"github.com/rafagsiqueira/farseek/internal/lang/eval/internal/configgraph"
 	"github.com/rafagsiqueira/farseek/internal/lang/eval/internal/evalglue"
 	"github.com/rafagsiqueira/farseek/internal/lang/grapheval"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
@@ -27,15 +28,35 @@ import (
 // [configgraph.ModuleInstance.CheckAll], but it's important to use this
 // because it arranges for tracking workgraph request IDs so we can return
 // helpful error messages when expression evaluation encounters a
-// self-dependency problem.
-func checkAll(ctx context.Context, rootModuleInstance evalglue.CompiledModuleInstance) tfdiags.Diagnostics {
+// self-dependency problem, and limits how many nodes are checked
+// concurrently to the given parallelism.
+func checkAll(ctx context.Context, rootModuleInstance evalglue.CompiledModuleInstance, parallelism int) tfdiags.Diagnostics {
 	// If the grapheval package detects a self-dependency problem during
 	// evaluation then it'll use this tracker to find human-friendly names
 	// for all of the requests involved in the error.
 	ctx = grapheval.ContextWithRequestTracker(ctx, workgraphRequestTracker{rootModuleInstance})
+	ctx = configgraph.ContextWithCheckLimiter(ctx, newCheckLimiter(parallelism))
 	return rootModuleInstance.CheckAll(ctx)
 }
 
+// newCheckLimiter returns the limiter for a tree walk with the given
+// parallelism, which is the number of nodes that can be checked at once.
+//
+// Each subsystem can use all but one of those slots, so that checks in the
+// other subsystems can still make progress when, for example, there are
+// thousands of resource instances waiting to be checked.
+func newCheckLimiter(parallelism int) *configgraph.CheckLimiter {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	subsystemLimit := max(parallelism-1, 1)
+	return configgraph.NewCheckLimiter(parallelism, map[configgraph.CheckSubsystem]int{
+		configgraph.CheckSubsystemResources: subsystemLimit,
+		configgraph.CheckSubsystemProviders: subsystemLimit,
+		configgraph.CheckSubsystemModules:   subsystemLimit,
+	})
+}
+
 // workgraphRequestTracker is an awkward piece of glue that helps the
 // code in [grapheval] to find user-friendly names for requests in progress
 // when it needs to report errors.
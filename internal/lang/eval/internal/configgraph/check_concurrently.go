@@ -40,6 +40,11 @@ type CheckGroup struct {
 	mu    sync.Mutex
 }
 
+// CheckChild starts checking the given child and everything beneath it.
+//
+// This is never subject to a [CheckLimiter], because the child's check waits
+// for the checks of its own descendants, which might need the slots it would
+// otherwise hold.
 func (g *CheckGroup) CheckChild(ctx context.Context, child allChecker) {
 	g.wg.Go(func() {
 		diags := child.CheckAll(grapheval.ContextWithNewWorker(ctx))
@@ -49,8 +54,15 @@ func (g *CheckGroup) CheckChild(ctx context.Context, child allChecker) {
 	})
 }
 
+// CheckValuer starts checking the given valuer by calling its Value method.
+//
+// If the context has a [CheckLimiter] then this blocks until the limiter
+// allows the check to start, so that a walk over many nodes doesn't start
+// a goroutine for each of them all at once.
 func (g *CheckGroup) CheckValuer(ctx context.Context, v exprs.Valuer) {
+	release := checkLimiterFromContext(ctx).acquire(ctx, checkSubsystemForValuer(v))
 	g.wg.Go(func() {
+		defer release()
 		// We use Value to make sure we're running the same codepath that
 		// normal evaluation would use, but we only care about the diags.
 		_, diags := v.Value(grapheval.ContextWithNewWorker(ctx))
@@ -60,8 +72,12 @@ func (g *CheckGroup) CheckValuer(ctx context.Context, v exprs.Valuer) {
 	})
 }
 
+// CheckDiagsFunc starts a check that calls the given function, which is
+// subject only to the global limit of any [CheckLimiter] in the context.
 func (g *CheckGroup) CheckDiagsFunc(ctx context.Context, f func(ctx context.Context) tfdiags.Diagnostics) {
+	release := checkLimiterFromContext(ctx).acquire(ctx, CheckSubsystemOther)
 	g.wg.Go(func() {
+		defer release()
 		diags := f(grapheval.ContextWithNewWorker(ctx))
 		g.mu.Lock()
 		g.diags = g.diags.Append(diags)
@@ -0,0 +1,131 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configgraph
+
+import (
+	"context"
+
+	"github.com/rafagsiqueira/farseek/internal/lang/exprs"
+)
+
+// CheckSubsystem identifies a group of checks that share a concurrency limit
+// in a [CheckLimiter], in addition to the limit shared by all checks.
+type CheckSubsystem int
+
+const (
+	// CheckSubsystemOther is for checks that don't belong to any of the
+	// other subsystems, such as those of local and output values. These
+	// checks are only subject to the global limit.
+	CheckSubsystemOther CheckSubsystem = iota
+
+	// CheckSubsystemResources is for checks of resource instances.
+	CheckSubsystemResources
+
+	// CheckSubsystemProviders is for checks of provider instances.
+	CheckSubsystemProviders
+
+	// CheckSubsystemModules is for checks of module call instances.
+	CheckSubsystemModules
+)
+
+// CheckLimiter limits how many of the checks started through [CheckGroup]
+// can run concurrently in a tree walk, so that the number of goroutines
+// and the memory they use are bounded even for very large configurations.
+//
+// A CheckLimiter has no effect until it's associated with the context of a
+// tree walk using [ContextWithCheckLimiter]. Without one, a tree walk starts
+// a goroutine for every check as soon as it's requested.
+//
+// Only the checks that evaluate something are limited. The checks that just
+// visit the children of a node must not be, because they wait for the checks
+// of their descendants and so could otherwise deadlock by holding slots that
+// those descendants need.
+type CheckLimiter struct {
+	global     chan struct{}
+	subsystems map[CheckSubsystem]chan struct{}
+}
+
+// NewCheckLimiter returns a [CheckLimiter] that runs up to global checks at
+// once, and up to the given number of checks at once in each of the given
+// subsystems. Subsystems that aren't in the map are only subject to the
+// global limit.
+//
+// All of the limits must be greater than zero.
+func NewCheckLimiter(global int, subsystems map[CheckSubsystem]int) *CheckLimiter {
+	if global <= 0 {
+		panic("check limiter with global limit <= 0")
+	}
+	ret := &CheckLimiter{
+		global:     make(chan struct{}, global),
+		subsystems: make(map[CheckSubsystem]chan struct{}, len(subsystems)),
+	}
+	for subsystem, limit := range subsystems {
+		if limit <= 0 {
+			panic("check limiter with subsystem limit <= 0")
+		}
+		ret.subsystems[subsystem] = make(chan struct{}, limit)
+	}
+	return ret
+}
+
+// acquire blocks until a check in the given subsystem can start, and then
+// returns a function that the check must call once it's complete.
+//
+// If the given context is cancelled while waiting then acquire returns
+// without waiting any longer, so that the checks can quickly unwind.
+func (l *CheckLimiter) acquire(ctx context.Context, subsystem CheckSubsystem) (release func()) {
+	if l == nil {
+		return func() {}
+	}
+	// We always acquire the subsystem slot before the global one, so that
+	// a check waiting for its subsystem doesn't hold a global slot that a
+	// check in another subsystem could use.
+	var held []chan struct{}
+	for _, sem := range []chan struct{}{l.subsystems[subsystem], l.global} {
+		if sem == nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+			held = append(held, sem)
+		case <-ctx.Done():
+		}
+	}
+	return func() {
+		for _, sem := range held {
+			<-sem
+		}
+	}
+}
+
+// ContextWithCheckLimiter returns a child of the given context that is
+// associated with the given [CheckLimiter], which then limits all of the
+// [CheckGroup] calls made with contexts derived from it.
+func ContextWithCheckLimiter(parent context.Context, limiter *CheckLimiter) context.Context {
+	return context.WithValue(parent, checkLimiterContextKey, limiter)
+}
+
+func checkLimiterFromContext(ctx context.Context) *CheckLimiter {
+	limiter, _ := ctx.Value(checkLimiterContextKey).(*CheckLimiter)
+	return limiter
+}
+
+// checkSubsystemForValuer returns the subsystem whose limit applies to
+// checking the given valuer.
+func checkSubsystemForValuer(v exprs.Valuer) CheckSubsystem {
+	switch v.(type) {
+	case *ResourceInstance:
+		return CheckSubsystemResources
+	case *ProviderInstance:
+		return CheckSubsystemProviders
+	case *ModuleCallInstance:
+		return CheckSubsystemModules
+	default:
+		return CheckSubsystemOther
+	}
+}
+
+type checkLimiterContextKeyType int
+
+const checkLimiterContextKey checkLimiterContextKeyType = 0
@@ -0,0 +1,126 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package configgraph
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/lang/grapheval"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestCheckGroup_limiter(t *testing.T) {
+	const limit = 3
+	limiter := NewCheckLimiter(limit, nil)
+	ctx := ContextWithCheckLimiter(grapheval.ContextWithNewWorker(t.Context()), limiter)
+
+	tracker := &concurrencyTracker{}
+	var cg CheckGroup
+	for range 20 {
+		cg.CheckValuer(ctx, &trackingValuer{tracker: tracker})
+	}
+	cg.CheckDiagsFunc(ctx, func(ctx context.Context) tfdiags.Diagnostics {
+		tracker.run()
+		return nil
+	})
+	if diags := cg.Complete(ctx); diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+
+	if tracker.calls != 21 {
+		t.Errorf("ran %d checks; want 21", tracker.calls)
+	}
+	if tracker.max > limit {
+		t.Errorf("ran %d checks at once; want at most %d", tracker.max, limit)
+	}
+}
+
+func TestCheckLimiter_subsystems(t *testing.T) {
+	limiter := NewCheckLimiter(3, map[CheckSubsystem]int{
+		CheckSubsystemResources: 2,
+	})
+	ctx := t.Context()
+
+	// The resources subsystem can only use two of the three slots...
+	release1 := limiter.acquire(ctx, CheckSubsystemResources)
+	release2 := limiter.acquire(ctx, CheckSubsystemResources)
+	acquired := make(chan func())
+	go func() {
+		acquired <- limiter.acquire(ctx, CheckSubsystemResources)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired a third resources slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// ...which leaves the last one for the other subsystems.
+	releaseOther := limiter.acquire(ctx, CheckSubsystemOther)
+	releaseOther()
+
+	release1()
+	select {
+	case release3 := <-acquired:
+		release3()
+	case <-time.After(5 * time.Second):
+		t.Fatal("didn't acquire a resources slot after one was released")
+	}
+	release2()
+}
+
+func TestCheckLimiter_nil(t *testing.T) {
+	// Tree walks without a limiter aren't limited at all.
+	var limiter *CheckLimiter
+	for range 100 {
+		limiter.acquire(t.Context(), CheckSubsystemResources)
+	}
+}
+
+// concurrencyTracker records the number of calls to its run method, and the
+// greatest number of them that were running at the same time.
+type concurrencyTracker struct {
+	mu      sync.Mutex
+	current int
+	max     int
+	calls   int
+}
+
+func (c *concurrencyTracker) run() {
+	c.mu.Lock()
+	c.current++
+	c.calls++
+	c.max = max(c.max, c.current)
+	c.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+}
+
+// trackingValuer is an [exprs.Valuer] that reports its calls to a
+// [concurrencyTracker].
+type trackingValuer struct {
+	tracker *concurrencyTracker
+}
+
+func (v *trackingValuer) StaticCheckTraversal(traversal hcl.Traversal) tfdiags.Diagnostics {
+	return nil
+}
+
+func (v *trackingValuer) Value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
+	v.tracker.run()
+	return cty.True, nil
+}
+
+func (v *trackingValuer) ValueSourceRange() *tfdiags.SourceRange {
+	return nil
+}
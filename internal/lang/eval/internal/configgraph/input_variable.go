@@ -39,7 +39,6 @@ type InputVariable struct {
 	TargetType     cty.Type
 	TargetDefaults *typeexpr.Defaults
 
-	// TODO: Default value
 	// TODO: ForceEphemeral, ForceSensitive
 
 	// Validation rules are user-defined checks that must succeed for the
@@ -51,6 +50,11 @@ type InputVariable struct {
 	// test whether the author's configured conditions have been met
 	// for the given value.
 	CompileValidationRules func(ctx context.Context, value cty.Value) iter.Seq[*CheckRule]
+
+	// valueResult memoizes the result of [InputVariable.Value], so that
+	// the type conversion and validation rules run only once no matter how
+	// many expressions refer to the variable.
+	valueResult grapheval.Once[cty.Value]
 }
 
 var _ exprs.Valuer = (*InputVariable)(nil)
@@ -70,6 +74,10 @@ func (i *InputVariable) StaticCheckTraversal(traversal hcl.Traversal) tfdiags.Di
 
 // Value implements exprs.Valuer.
 func (i *InputVariable) Value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
+	return i.valueResult.Do(ctx, i.value)
+}
+
+func (i *InputVariable) value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
 	rawV, moreDiags := i.RawValue.Value(ctx)
@@ -143,8 +151,14 @@ func (i *InputVariable) AnnounceAllGraphevalRequests(announce func(workgraph.Req
 		Name:        fmt.Sprintf("value for %s", i.Addr),
 		SourceRange: i.RawValue.ValueSourceRange(),
 	})
-	// FIXME: This doesn't currently cover any of the validation rules because
-	// we're not currently using a distinct workgraph request for each of
-	// those. Should our Value method be evaluating those through a
-	// grapheval.Once so that they can have their own RequestInfo values?
+	if valueReqId := i.valueResult.RequestID(); valueReqId != workgraph.NoRequest {
+		announce(valueReqId, grapheval.RequestInfo{
+			Name:        fmt.Sprintf("validated value for %s", i.Addr),
+			SourceRange: i.RawValue.ValueSourceRange(),
+		})
+	}
+	// FIXME: This doesn't currently cover the individual validation rules
+	// because we're not using a distinct workgraph request for each of
+	// those. Should they have their own grapheval.Once so that they can have
+	// their own RequestInfo values?
 }
@@ -11,6 +11,8 @@ import (
 	"context"
 	"fmt"
 	"iter"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/hashicorp/hcl/v2"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/lang/exprs"
+	"github.com/rafagsiqueira/farseek/internal/lang/grapheval"
 	"github.com/rafagsiqueira/farseek/internal/tfdiags"
 )
 
@@ -139,3 +142,44 @@ This problem was reported by the validation rule at child.tf:0,0.`,
 		},
 	})
 }
+
+func TestInputVariable_ValueOnce(t *testing.T) {
+	// The validation rules should run only once, no matter how many
+	// references to the variable request its value.
+	var compiles atomic.Int32
+	v := &InputVariable{
+		Addr:       addrs.InputVariable{Name: "foo"}.Absolute(addrs.RootModuleInstance),
+		RawValue:   constantOnceValuer(cty.True),
+		TargetType: cty.String,
+		CompileValidationRules: func(ctx context.Context, value cty.Value) iter.Seq[*CheckRule] {
+			compiles.Add(1)
+			return func(yield func(*CheckRule) bool) {
+				yield(&CheckRule{
+					ConditionValuer:    constantOnceValuer(cty.True),
+					ErrorMessageValuer: constantOnceValuer(cty.StringVal("Unreachable.")),
+				})
+			}
+		},
+	}
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := grapheval.ContextWithNewWorker(t.Context())
+			got, diags := v.Value(ctx)
+			if diags.HasErrors() {
+				t.Errorf("unexpected errors: %s", diags.Err())
+			}
+			if !got.RawEquals(cty.StringVal("true")) {
+				t.Errorf("wrong result: %#v", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := compiles.Load(); got != 1 {
+		t.Errorf("validation rules compiled %d times; want 1", got)
+	}
+}
@@ -88,6 +88,11 @@ type ModuleCall struct {
 	// Only the decideInstances method accesses this directly. Use that
 	// method to obtain the coalesced result for use elsewhere.
 	instancesResult grapheval.Once[*compiledInstances[*ModuleCallInstance]]
+
+	// valueResult memoizes the result of [ModuleCall.Value], which
+	// aggregates the values of all of the instances and so would otherwise
+	// be rebuilt for every reference to the module call.
+	valueResult grapheval.Once[cty.Value]
 }
 
 var _ exprs.Valuer = (*ModuleCall)(nil)
@@ -278,6 +283,10 @@ func (c *ModuleCall) StaticCheckTraversal(traversal hcl.Traversal) tfdiags.Diagn
 
 // Value implements exprs.Valuer.
 func (c *ModuleCall) Value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
+	return c.valueResult.Do(ctx, c.value)
+}
+
+func (c *ModuleCall) value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
 	// We'll first check whether the arguments specifying which module to
 	// call are valid, because we can't really do anything else if not.
 	maybeSourceArgs, sourceMarks, diags := c.SourceArguments(ctx)
@@ -314,6 +323,25 @@ func (c *ModuleCall) ValueSourceRange() *tfdiags.SourceRange {
 	return &c.DeclRange
 }
 
+// CheckInstances returns diagnostics for the arguments that decide which
+// module this call refers to and which instances of it are declared, without
+// checking anything about the instances themselves.
+func (c *ModuleCall) CheckInstances(ctx context.Context) tfdiags.Diagnostics {
+	maybeSourceArgs, _, diags := c.SourceArguments(ctx)
+	sourceArgs, ok := GetKnown(maybeSourceArgs)
+	if !ok {
+		return diags
+	}
+	moreDiags := c.ValidateSourceArguments(ctx, sourceArgs)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+	_, moreDiags = c.decideInstances(ctx)
+	diags = diags.Append(moreDiags)
+	return diags
+}
+
 // CheckAll implements allChecker.
 func (c *ModuleCall) CheckAll(ctx context.Context) tfdiags.Diagnostics {
 	var cg CheckGroup
@@ -342,6 +370,12 @@ func (c *ModuleCall) AnnounceAllGraphevalRequests(announce func(workgraph.Reques
 	// There might be other grapheval requests in our dynamic instances, but
 	// they are hidden behind another request themselves so we'll try to
 	// report them only if that request was already started.
+	if valueReqId := c.valueResult.RequestID(); valueReqId != workgraph.NoRequest {
+		announce(valueReqId, grapheval.RequestInfo{
+			Name:        fmt.Sprintf("value for %s", c.Addr),
+			SourceRange: &c.DeclRange,
+		})
+	}
 	instancesReqId := c.instancesResult.RequestID()
 	if instancesReqId == workgraph.NoRequest {
 		return
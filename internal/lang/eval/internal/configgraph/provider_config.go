@@ -56,6 +56,11 @@ type ProviderConfig struct {
 	// Only the decideInstances method accesses this directly. Use that
 	// method to obtain the coalesced result for use elsewhere.
 	instancesResult grapheval.Once[*compiledInstances[*ProviderInstance]]
+
+	// valueResult memoizes the result of [ProviderConfig.Value], which
+	// aggregates the values of all of the instances and so would otherwise
+	// be rebuilt for every reference to the provider config.
+	valueResult grapheval.Once[cty.Value]
 }
 
 var _ exprs.Valuer = (*ProviderConfig)(nil)
@@ -87,8 +92,10 @@ func (p *ProviderConfig) StaticCheckTraversal(traversal hcl.Traversal) tfdiags.D
 
 // Value implements exprs.Valuer.
 func (p *ProviderConfig) Value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
-	selection, diags := p.decideInstances(ctx)
-	return valueForInstances(ctx, selection), diags
+	return p.valueResult.Do(ctx, func(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
+		selection, diags := p.decideInstances(ctx)
+		return valueForInstances(ctx, selection), diags
+	})
 }
 
 // ValueSourceRange implements exprs.Valuer.
@@ -115,6 +122,12 @@ func (p *ProviderConfig) AnnounceAllGraphevalRequests(announce func(workgraph.Re
 	// There might be other grapheval requests in our dynamic instances, but
 	// they are hidden behind another request themselves so we'll try to
 	// report them only if that request was already started.
+	if valueReqId := p.valueResult.RequestID(); valueReqId != workgraph.NoRequest {
+		announce(valueReqId, grapheval.RequestInfo{
+			Name:        fmt.Sprintf("value for %s", p.Addr),
+			SourceRange: &p.DeclRange,
+		})
+	}
 	instancesReqId := p.instancesResult.RequestID()
 	if instancesReqId == workgraph.NoRequest {
 		return
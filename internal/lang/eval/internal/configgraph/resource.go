@@ -55,6 +55,11 @@ type Resource struct {
 	// Only the decideInstances method accesses this directly. Use that
 	// method to obtain the coalesced result for use elsewhere.
 	instancesResult grapheval.Once[*compiledInstances[*ResourceInstance]]
+
+	// valueResult memoizes the result of [Resource.Value], which aggregates
+	// the values of all of the instances and so would otherwise be rebuilt
+	// for every reference to the resource.
+	valueResult grapheval.Once[cty.Value]
 }
 
 var _ exprs.Valuer = (*Resource)(nil)
@@ -94,8 +99,10 @@ func (r *Resource) StaticCheckTraversal(traversal hcl.Traversal) tfdiags.Diagnos
 
 // Value implements exprs.Valuer.
 func (r *Resource) Value(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
-	selection, diags := r.decideInstances(ctx)
-	return valueForInstances(ctx, selection), diags
+	return r.valueResult.Do(ctx, func(ctx context.Context) (cty.Value, tfdiags.Diagnostics) {
+		selection, diags := r.decideInstances(ctx)
+		return valueForInstances(ctx, selection), diags
+	})
 }
 
 // ValueSourceRange implements exprs.Valuer.
@@ -130,6 +137,12 @@ func (r *Resource) AnnounceAllGraphevalRequests(announce func(workgraph.RequestI
 	// There might be other grapheval requests in our dynamic instances, but
 	// they are hidden behind another request themselves so we'll try to
 	// report them only if that request was already started.
+	if valueReqId := r.valueResult.RequestID(); valueReqId != workgraph.NoRequest {
+		announce(valueReqId, grapheval.RequestInfo{
+			Name:        fmt.Sprintf("value for %s", r.Addr),
+			SourceRange: &r.DeclRange,
+		})
+	}
 	instancesReqId := r.instancesResult.RequestID()
 	if instancesReqId == workgraph.NoRequest {
 		return
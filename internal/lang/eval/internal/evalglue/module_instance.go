@@ -60,6 +60,15 @@ type CompiledModuleInstance interface {
 	// planned and so their "planned new state" values have been decided.
 	CheckAll(ctx context.Context) tfdiags.Diagnostics
 
+	// CheckChildModuleCalls collects diagnostics for deciding which module
+	// each of the module calls declared in this module instance refers to,
+	// and which instances of it are declared.
+	//
+	// Unlike [CompiledModuleInstance.CheckAll] this doesn't visit anything
+	// else in the module instance, nor the child module instances, and so
+	// it's for callers that only need to enumerate the module instances.
+	CheckChildModuleCalls(ctx context.Context) tfdiags.Diagnostics
+
 	// ResultValuer returns the [exprs.Valuer] representing the module
 	// instance's overall result value, which is what should be used to
 	// represent this module instance when referred to in its parent module.
@@ -92,17 +92,22 @@ func compileModuleInstanceInputVariables(_ context.Context, configs map[string]*
 					})
 					return cty.DynamicVal.WithSameMarks(v), diags
 				} else {
-					// For a non-required variable we'll provide a placeholder
-					// null value so that the evaluator can treat this the same
-					// as if there was an explicit definition evaluating to null.
-					return cty.NullVal(cty.DynamicPseudoType).WithSameMarks(v), diags
+					// A non-required variable always has a default value,
+					// which might itself be null.
+					return vc.Default.WithSameMarks(v), diags
 				}
 			}
 			// After all of the checks above we should now be able to call
 			// GetAttr for this name without panicking. (If v is unknown
 			// or marked then cty will automatically return a derived unknown
 			// or marked value.)
-			return v.GetAttr(name), diags
+			attrV := v.GetAttr(name)
+			if attrV.IsNull() && !vc.Nullable && !vc.Required() {
+				// A non-nullable variable uses its default value in place
+				// of an explicit null.
+				return vc.Default.WithSameMarks(attrV), diags
+			}
+			return attrV, diags
 		})
 		ret[addr] = &configgraph.InputVariable{
 			Addr:           moduleInstAddr.InputVariable(name),
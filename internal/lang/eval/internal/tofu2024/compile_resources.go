@@ -9,6 +9,9 @@ package tofu2024
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
 
 	"github.com/zclconf/go-cty/cty"
 
@@ -66,6 +69,24 @@ func compileModuleInstanceResource(
 		resourceAddr.Mode,
 		resourceAddr.Type,
 	)
+	if !diags.HasErrors() && resourceTypeSchema == nil {
+		// ResourceTypeSchema leaves it to us to report a resource type that
+		// the provider doesn't offer, which we do with the same messages as
+		// the validate walk of the legacy runtime.
+		summary, kind := "Invalid resource type", "resource type"
+		switch resourceAddr.Mode {
+		case addrs.DataResourceMode:
+			summary, kind = "Invalid data source", "data source"
+		case addrs.EphemeralResourceMode:
+			summary, kind = "Invalid ephemeral resource", "ephemeral resource"
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  summary,
+			Detail:   fmt.Sprintf("The provider %s does not support %s %q.", config.Provider.ForDisplay(), kind, resourceAddr.Type),
+			Subject:  config.TypeRange.Ptr(),
+		})
+	}
 	if diags.HasErrors() {
 		configEvalable = exprs.ForcedErrorEvalable(diags, tfdiags.SourceRangeFromHCL(config.DeclRange))
 	} else {
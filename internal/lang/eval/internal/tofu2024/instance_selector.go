@@ -161,9 +161,81 @@ func compileInstanceSelectorEnabled(_ context.Context, enabledValuer exprs.Value
 }
 
 func compileInstanceSelectorForEach(_ context.Context, forEachValuer exprs.Valuer) configgraph.InstanceSelector {
-	// TODO: The logic for this one is a little more complex so I'll come
-	// back to this once more of the rest of this is working.
-	panic("unimplemented")
+	forEachValuer = configgraph.ValuerOnce(forEachValuer)
+	return &instanceSelector{
+		keyType:     addrs.StringKeyType,
+		sourceRange: nil,
+		selectInstances: func(ctx context.Context) (configgraph.Maybe[configgraph.InstancesSeq], cty.ValueMarks, tfdiags.Diagnostics) {
+			forEachVal, diags := forEachValuer.Value(ctx)
+			if diags.HasErrors() {
+				return nil, nil, diags
+			}
+			forEachVal, marks := forEachVal.Unmark()
+			ty := forEachVal.Type()
+			var err error
+			switch {
+			case forEachVal.IsNull():
+				err = errors.New("must not be null")
+			case ty.IsSetType():
+				if !ty.ElementType().Equals(cty.String) && ty.ElementType() != cty.DynamicPseudoType {
+					err = fmt.Errorf("a set must have elements of type string, not %s", ty.ElementType().FriendlyName())
+				} else if !forEachVal.IsWhollyKnown() {
+					// The keys of a set's instances are its elements, so
+					// we need all of them to be known.
+					return nil, marks, diags
+				}
+			case ty.IsMapType() || ty.IsObjectType():
+				if !forEachVal.IsKnown() {
+					return nil, marks, diags
+				}
+			case ty == cty.DynamicPseudoType:
+				// We represent "unknown" by returning a nil configgraph.Maybe
+				// without any error diagnostics, but we will still report
+				// what marks we found on the unknown value.
+				return nil, marks, diags
+			default:
+				err = fmt.Errorf("must be a map, or a set of strings, not %s", ty.FriendlyName())
+			}
+			if err == nil && ty.IsSetType() {
+				for it := forEachVal.ElementIterator(); it.Next(); {
+					_, v := it.Element()
+					if v.IsNull() {
+						err = errors.New("a set must not contain null values")
+						break
+					}
+				}
+			}
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid for_each argument",
+					Detail:   fmt.Sprintf("Unsuitable value for the \"for_each\" meta-argument: %s.", tfdiags.FormatError(err)),
+					Subject:  configgraph.MaybeHCLSourceRange(forEachValuer.ValueSourceRange()),
+				})
+				return nil, marks, diags
+			}
+			// If we manage to get here then each element of the value
+			// declares an instance, keyed by the element's key for a map or
+			// by the element itself for a set.
+			seq := func(yield func(addrs.InstanceKey, instances.RepetitionData) bool) {
+				for it := forEachVal.ElementIterator(); it.Next(); {
+					k, v := it.Element()
+					if ty.IsSetType() {
+						k = v
+					}
+					k, _ = k.Unmark()
+					more := yield(addrs.StringKey(k.AsString()), instances.RepetitionData{
+						EachKey:   k,
+						EachValue: v,
+					})
+					if !more {
+						break
+					}
+				}
+			}
+			return configgraph.Known(seq), marks, diags
+		},
+	}
 }
 
 type instanceSelector struct {
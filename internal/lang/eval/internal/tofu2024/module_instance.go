@@ -89,6 +89,15 @@ func (c *CompiledModuleInstance) CheckAll(ctx context.Context) tfdiags.Diagnosti
 	return cg.Complete(ctx)
 }
 
+// CheckChildModuleCalls implements evalglue.CompiledModuleInstance.
+func (c *CompiledModuleInstance) CheckChildModuleCalls(ctx context.Context) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	for _, n := range c.moduleCallNodes {
+		diags = diags.Append(n.CheckInstances(ctx))
+	}
+	return diags
+}
+
 // ResultValuer implements evalglue.CompiledModuleInstance.
 func (c *CompiledModuleInstance) ResultValuer(ctx context.Context) exprs.Valuer {
 	// This causes our module instance to effectively be bridged directly into
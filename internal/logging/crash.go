@@ -0,0 +1,232 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/rafagsiqueira/farseek/version"
+)
+
+// envCrashDir is the environment variable that selects the directory crash
+// reports are written to, instead of the current working directory.
+const envCrashDir = "FARSEEK_CRASH_DIR"
+
+// crashLogLines is the number of the most recent log lines that a crash
+// report includes.
+const crashLogLines = 200
+
+// crashReportIssues is where users should report crashes.
+const crashReportIssues = "https://github.com/rafagsiqueira/farseek/issues"
+
+var (
+	// crashLogs keeps the most recent log lines for crash reports, even
+	// when the usual log output is off.
+	crashLogs = &lineRing{max: crashLogLines}
+
+	// crashContext holds the sections that the running operation recorded
+	// with SetCrashContext.
+	crashContextMu sync.Mutex
+	crashContext   = make(map[string]string)
+)
+
+// registerCrashLogSink starts keeping the most recent log lines of the
+// global logger for crash reports.
+func registerCrashLogSink() {
+	if l, ok := logger.(hclog.InterceptLogger); ok {
+		level, _ := globalLogLevel()
+		l.RegisterSink(hclog.NewSinkAdapter(&hclog.LoggerOptions{
+			Level:      crashLogLevel(level),
+			Output:     crashLogs,
+			JSONFormat: true,
+		}))
+	}
+}
+
+// crashLogLevel returns the level of the log lines kept for crash reports,
+// given the level of the usual log output. Crash reports keep lines at the
+// INFO level or higher, since the lines below it are too many to keep
+// cheaply and the most likely to include details of the infrastructure,
+// or only the lines at the configured level if that is higher.
+func crashLogLevel(level hclog.Level) hclog.Level {
+	if level > hclog.Info && level != hclog.Off {
+		return level
+	}
+	return hclog.Info
+}
+
+// SetCrashContext records a section of the crash report that is written if
+// Farseek panics, such as a summary of the plan that the running operation
+// is working on, replacing any earlier section with the same name. An empty
+// content removes the section.
+//
+// The content is written as is, so it must never include sensitive values.
+func SetCrashContext(name, content string) {
+	crashContextMu.Lock()
+	defer crashContextMu.Unlock()
+
+	if content == "" {
+		delete(crashContext, name)
+		return
+	}
+	crashContext[name] = content
+}
+
+// writeCrashReport writes a crash report for the recovered panic value to a
+// new file in dir, returning its path. stack is the stack of the panicking
+// goroutine, and trace is the stack of the goroutine that started it, if
+// known.
+func writeCrashReport(dir string, now time.Time, recovered any, stack, trace []byte) (string, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "Farseek crash report, %s\n", now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "Please attach this file to an issue at %s.\n", crashReportIssues)
+	fmt.Fprintf(&buf, "Review it first: it doesn't include variable values or the values in the plan, but log lines can include details of your infrastructure.\n")
+
+	crashSection(&buf, "Panic", fmt.Sprint(recovered))
+
+	stacks := string(stack)
+	if trace != nil {
+		stacks += "\nWith go-routine called from:\n" + string(trace)
+	}
+	crashSection(&buf, "Stack trace", stacks)
+
+	crashSection(&buf, "Environment", crashEnvironment())
+
+	crashContextMu.Lock()
+	names := make([]string, 0, len(crashContext))
+	for name := range crashContext {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		crashSection(&buf, name, crashContext[name])
+	}
+	crashContextMu.Unlock()
+
+	if pluginPanics := PluginPanics(); len(pluginPanics) != 0 {
+		crashSection(&buf, "Plugin panics", strings.Join(pluginPanics, "\n"))
+	}
+
+	crashSection(&buf, "Recent logs", strings.Join(crashLogs.lines(), "\n"))
+
+	all := make([]byte, 1<<20)
+	all = all[:runtime.Stack(all, true)]
+	crashSection(&buf, "All goroutines", string(all))
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "farseek-crash-"+now.UTC().Format("20060102T150405Z")+".log")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func crashSection(buf *bytes.Buffer, name, content string) {
+	fmt.Fprintf(buf, "\n=== %s ===\n\n%s\n", name, strings.TrimRight(content, "\n"))
+}
+
+// crashEnvironment describes the environment Farseek runs in, without the
+// values of its arguments or of the environment variables, which could be
+// secrets.
+func crashEnvironment() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Farseek version: %s\n", version.String())
+	fmt.Fprintf(&buf, "Go runtime: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if len(os.Args) > 0 {
+		fmt.Fprintf(&buf, "Arguments: %s\n", strings.Join(redactArgs(os.Args[1:]), " "))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		fmt.Fprintf(&buf, "Working directory: %s\n", wd)
+	}
+	var vars []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "FARSEEK_") || strings.HasPrefix(name, "TF_") || name == "GODEBUG" {
+			vars = append(vars, name)
+		}
+	}
+	slices.Sort(vars)
+	fmt.Fprintf(&buf, "Environment variables set: %s\n", strings.Join(vars, ", "))
+	return buf.String()
+}
+
+// redactArgs returns the given command line arguments with only the
+// subcommand and the names of the options, since the values of options
+// such as -var can be secrets.
+func redactArgs(args []string) []string {
+	ret := make([]string, 0, len(args))
+	options := false
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "-"):
+			options = true
+			if name, _, ok := strings.Cut(arg, "="); ok {
+				arg = name + "=<redacted>"
+			}
+		case options:
+			arg = "<redacted>"
+		}
+		ret = append(ret, arg)
+	}
+	return ret
+}
+
+// lineRing is an io.Writer that keeps the last max lines written to it.
+type lineRing struct {
+	mu    sync.Mutex
+	max   int
+	ring  []string
+	next  int
+	extra []byte
+}
+
+func (r *lineRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.extra, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		r.add(string(data[:i]))
+		data = data[i+1:]
+	}
+	r.extra = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (r *lineRing) add(line string) {
+	if len(r.ring) < r.max {
+		r.ring = append(r.ring, line)
+		return
+	}
+	r.ring[r.next] = line
+	r.next = (r.next + 1) % r.max
+}
+
+// lines returns the lines in the order they were written.
+func (r *lineRing) lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := make([]string, 0, len(r.ring))
+	ret = append(ret, r.ring[r.next:]...)
+	ret = append(ret, r.ring[:r.next]...)
+	return ret
+}
@@ -0,0 +1,94 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-hclog"
+)
+
+func TestWriteCrashReport(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	log.Printf("[INFO] crash report marker")
+	log.Printf("[DEBUG] crash report debug line")
+	SetCrashContext("Plan summary", "create aws_instance.web")
+	defer SetCrashContext("Plan summary", "")
+
+	path, err := writeCrashReport(dir, now, "boom", []byte("panic stack"), []byte("caller stack"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "farseek-crash-20260102T030405Z.log"); path != want {
+		t.Errorf("wrong path %q; want %q", path, want)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(src)
+	for _, want := range []string{
+		"=== Panic ===\n\nboom\n",
+		"=== Stack trace ===\n\npanic stack\nWith go-routine called from:\ncaller stack\n",
+		"=== Environment ===",
+		"Farseek version: ",
+		"=== Plan summary ===\n\ncreate aws_instance.web\n",
+		"=== Recent logs ===",
+		"crash report marker",
+		"=== All goroutines ===",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("crash report doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "crash report debug line") {
+		t.Errorf("crash report contains a line below the INFO level:\n%s", got)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	got := redactArgs([]string{"state", "forget", "-var=secret=1", "-lock", "-var-file", "prod.tfvars", "aws_instance.web"})
+	want := []string{"state", "forget", "-var=<redacted>", "-lock", "-var-file", "<redacted>", "<redacted>"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestCrashLogLevel(t *testing.T) {
+	tests := map[hclog.Level]hclog.Level{
+		hclog.Off:   hclog.Info,
+		hclog.Trace: hclog.Info,
+		hclog.Debug: hclog.Info,
+		hclog.Info:  hclog.Info,
+		hclog.Warn:  hclog.Warn,
+		hclog.Error: hclog.Error,
+	}
+	for level, want := range tests {
+		if got := crashLogLevel(level); got != want {
+			t.Errorf("wrong crash log level %s for %s; want %s", got, level, want)
+		}
+	}
+}
+
+func TestLineRing(t *testing.T) {
+	r := &lineRing{max: 3}
+	for i := range 5 {
+		fmt.Fprintf(r, "line %d\n", i)
+	}
+	fmt.Fprint(r, "partial")
+
+	want := []string{"line 2", "line 3", "line 4"}
+	if diff := cmp.Diff(want, r.lines()); diff != "" {
+		t.Errorf("wrong lines\n%s", diff)
+	}
+}
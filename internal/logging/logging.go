@@ -51,6 +51,7 @@ var (
 func init() {
 	logger = newHCLogger("")
 	logWriter = logger.StandardWriter(&hclog.StandardLoggerOptions{InferLevels: true})
+	registerCrashLogSink()
 
 	// set up the default std library logger to use our output
 	log.SetFlags(0)
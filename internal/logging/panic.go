@@ -13,6 +13,7 @@ import (
 	"runtime/debug"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 )
@@ -90,16 +91,32 @@ func panicHandler(recovered interface{}, trace []byte) {
 		return
 	}
 
+	// When called from a deferred function, debug.Stack will include the
+	// full stack from the point of the pending panic.
+	stack := debug.Stack()
+
+	// We write everything into a crash report, and only point to it, so
+	// that users attach the whole report to issues instead of the part of
+	// a stack dump that fits on their screen. If the report can't be
+	// written, we fall back to printing it all.
+	dir := os.Getenv(envCrashDir)
+	if dir == "" {
+		dir = "."
+	}
+	path, err := writeCrashReport(dir, time.Now(), recovered, stack, trace)
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "\nFarseek crashed, which is always a bug. Please report it at %s, attaching the crash report written to %s.\n", crashReportIssues, path)
+		os.Exit(11)
+	}
+
 	fmt.Fprint(os.Stderr, panicOutput)
 	fmt.Fprint(os.Stderr, recovered, "\n")
-
-	// When called from a deferred function, debug.PrintStack will include the
-	// full stack from the point of the pending panic.
-	debug.PrintStack()
+	os.Stderr.Write(stack)
 	if trace != nil {
 		fmt.Fprint(os.Stderr, "With go-routine called from:\n")
 		os.Stderr.Write(trace)
 	}
+	fmt.Fprintf(os.Stderr, "\nFarseek couldn't write a crash report: %s\n", err)
 
 	// An exit code of 11 keeps us out of the way of the detailed exitcodes
 	// from plan, and also happens to be the same code as SIGSEGV which is
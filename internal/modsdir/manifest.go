@@ -49,6 +49,19 @@ type Record struct {
 
 	// Dir is the path to the local directory where the module is installed.
 	Dir string `json:"Dir"`
+
+	// Deprecation is set when the registry that the module was installed
+	// from marked its version as deprecated, so that later commands can
+	// warn about it without asking the registry again.
+	Deprecation *RecordDeprecation `json:"Deprecation,omitempty"`
+}
+
+// RecordDeprecation is why a registry marked the installed version of a
+// module as deprecated, with the source address of a module to use instead
+// if it suggested one.
+type RecordDeprecation struct {
+	Reason      string `json:"Reason,omitempty"`
+	Replacement string `json:"Replacement,omitempty"`
 }
 
 // Manifest is a map used to keep track of the filesystem locations
@@ -0,0 +1,183 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package moduledeprecations warns about the module calls that use a
+// deprecated version of a module, as marked by the registry it was installed
+// from or by a local mapping file.
+package moduledeprecations
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/modsdir"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// Mapping is a local mapping file, which marks module versions as deprecated
+// for the registries that can't, or for modules that aren't in a registry.
+type Mapping struct {
+	Rules []*Rule `hcl:"module,block"`
+}
+
+// Rule is a single "module" block of a mapping file.
+type Rule struct {
+	// Source is the source address of the deprecated module, as written in
+	// module calls.
+	Source string `hcl:"source,label"`
+
+	// Versions is a version constraint selecting the deprecated versions.
+	// If it's empty then every version is deprecated, including for
+	// modules that don't have versions.
+	Versions string `hcl:"versions,optional"`
+
+	// Reason explains why the versions are deprecated.
+	Reason string `hcl:"reason,optional"`
+
+	// Replacement is the source address of a module to use instead.
+	Replacement string `hcl:"replacement,optional"`
+
+	DeclRange hcl.Range
+
+	source   addrs.ModuleSource
+	versions version.Constraints
+}
+
+// Load reads and validates the mapping file at the given path.
+func Load(path string) (*Mapping, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	f, hclDiags := hclparse.NewParser().ParseHCLFile(path)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	mapping := &Mapping{}
+	hclDiags = gohcl.DecodeBody(f.Body, nil, mapping)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	// gohcl doesn't record the ranges of blocks, so we find them again.
+	content, _ := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "module", LabelNames: []string{"source"}}},
+	})
+	for i, rule := range mapping.Rules {
+		if i < len(content.Blocks) {
+			rule.DeclRange = content.Blocks[i].DefRange
+		}
+		source, err := addrs.ParseModuleSource(rule.Source)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid module source address",
+				Detail:   fmt.Sprintf("The deprecated module must be given by its source address, as written in module calls: %s.", err),
+				Subject:  rule.DeclRange.Ptr(),
+			})
+			continue
+		}
+		rule.source = source
+		if rule.Versions != "" {
+			rule.versions, err = version.NewConstraint(rule.Versions)
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid version constraint",
+					Detail:   fmt.Sprintf("The deprecated versions of %s must be given by a version constraint: %s.", rule.Source, err),
+					Subject:  rule.DeclRange.Ptr(),
+				})
+			}
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return mapping, diags
+}
+
+// deprecation returns the first rule that deprecates the given version of
+// the module with the given source address, or nil if none does.
+func (m *Mapping) deprecation(source addrs.ModuleSource, v *version.Version) *Rule {
+	if m == nil {
+		return nil
+	}
+	for _, rule := range m.Rules {
+		if rule.source == nil || rule.source.String() != source.String() {
+			continue
+		}
+		if rule.versions != nil && (v == nil || !rule.versions.Check(v)) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// Checker knows which module versions are deprecated, from a mapping file and
+// from the deprecations that the module installer recorded in the modules
+// manifest when it installed modules from a registry. Either can be nil.
+type Checker struct {
+	Mapping  *Mapping
+	Manifest modsdir.Manifest
+}
+
+// Check returns a warning for each module call in the configuration that
+// uses a deprecated version of a module. The mapping takes precedence over
+// the registries. The warnings point at the source argument of the module
+// call.
+func (ch *Checker) Check(config *configs.Config) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if ch == nil || config == nil {
+		return diags
+	}
+	mapping, manifest := ch.Mapping, ch.Manifest
+	config.DeepEach(func(c *configs.Config) {
+		if c.Parent == nil || c.SourceAddr == nil {
+			return
+		}
+		call := c.Parent.Module.ModuleCalls[c.Path[len(c.Path)-1]]
+		if call == nil {
+			return
+		}
+
+		var reason, replacement string
+		if rule := mapping.deprecation(c.SourceAddr, c.Version); rule != nil {
+			reason, replacement = rule.Reason, rule.Replacement
+		} else if record, ok := manifest[manifest.ModuleKey(c.Path)]; ok && record.Deprecation != nil && record.Version != nil && c.Version != nil && record.Version.Equal(c.Version) {
+			reason, replacement = record.Deprecation.Reason, record.Deprecation.Replacement
+		} else {
+			return
+		}
+
+		what := fmt.Sprintf("module %s", c.SourceAddr.ForDisplay())
+		if c.Version != nil {
+			what = fmt.Sprintf("version %s of module %s", c.Version, c.SourceAddr.ForDisplay())
+		}
+		detail := fmt.Sprintf("The module call %q uses %s, which is deprecated.", call.Name, what)
+		if reason != "" {
+			detail += "\n\n" + reason
+		}
+		if replacement != "" {
+			detail += fmt.Sprintf("\n\nUse %s instead.", replacement)
+		}
+		subject := call.DeclRange
+		if call.Source != nil {
+			subject = call.Source.Range()
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated module version",
+			Detail:   detail,
+			Subject:  subject.Ptr(),
+			Extra:    tfdiags.CodeExtra(tfdiags.CodeDeprecatedModule),
+		})
+	})
+	return diags
+}
@@ -0,0 +1,164 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduledeprecations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	"github.com/rafagsiqueira/farseek/internal/modsdir"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deprecations.hcl")
+	src := `
+module "example/network/aws" {
+  versions    = "< 2.0.0"
+  reason      = "The 1.x series is no longer maintained."
+  replacement = "example/vpc/aws"
+}
+
+module "./legacy" {
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mapping, diags := Load(path)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if got, want := len(mapping.Rules), 2; got != want {
+		t.Fatalf("wrong number of rules %d; want %d", got, want)
+	}
+
+	source := addrs.MustParseModuleSource("example/network/aws")
+	if rule := mapping.deprecation(source, version.Must(version.NewVersion("1.4.0"))); rule == nil || rule.Replacement != "example/vpc/aws" {
+		t.Errorf("1.4.0 is not deprecated by the first rule: %#v", rule)
+	}
+	if rule := mapping.deprecation(source, version.Must(version.NewVersion("2.0.0"))); rule != nil {
+		t.Errorf("2.0.0 is deprecated: %#v", rule)
+	}
+	if rule := mapping.deprecation(addrs.MustParseModuleSource("./legacy"), nil); rule == nil {
+		t.Errorf("./legacy is not deprecated")
+	}
+}
+
+func TestLoad_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deprecations.hcl")
+	src := `
+module "example/network/aws" {
+  versions = "not a version"
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diags := Load(path)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), "Invalid version constraint"; !strings.Contains(got, want) {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+}
+
+func TestCheckerCheck(t *testing.T) {
+	sourceRange := hcl.Range{Filename: "main.tf", Start: hcl.Pos{Line: 2, Column: 12}, End: hcl.Pos{Line: 2, Column: 33}}
+	root := &configs.Config{
+		Module: &configs.Module{
+			ModuleCalls: map[string]*configs.ModuleCall{
+				"network": {
+					Name:   "network",
+					Source: hcl.StaticExpr(cty.StringVal("example/network/aws"), sourceRange),
+				},
+				"current": {
+					Name: "current",
+				},
+			},
+		},
+	}
+	root.Root = root
+	root.Children = map[string]*configs.Config{
+		"network": {
+			Parent:     root,
+			Root:       root,
+			Path:       addrs.Module{"network"},
+			SourceAddr: addrs.MustParseModuleSource("example/network/aws"),
+			Version:    version.Must(version.NewVersion("1.4.0")),
+			Module:     &configs.Module{},
+		},
+		"current": {
+			Parent:     root,
+			Root:       root,
+			Path:       addrs.Module{"current"},
+			SourceAddr: addrs.MustParseModuleSource("example/network/aws"),
+			Version:    version.Must(version.NewVersion("2.1.0")),
+			Module:     &configs.Module{},
+		},
+	}
+
+	checker := &Checker{
+		Manifest: modsdir.Manifest{
+			"network": {
+				Key:     "network",
+				Version: version.Must(version.NewVersion("1.4.0")),
+				Deprecation: &modsdir.RecordDeprecation{
+					Reason:      "The 1.x series is no longer maintained.",
+					Replacement: "example/vpc/aws",
+				},
+			},
+			"current": {
+				Key:     "current",
+				Version: version.Must(version.NewVersion("2.1.0")),
+			},
+		},
+	}
+	diags := checker.Check(root)
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.ErrWithWarnings())
+	}
+	diag := diags[0]
+	if got, want := diag.Severity(), tfdiags.Warning; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	wantDetail := "The module call \"network\" uses version 1.4.0 of module example/network/aws, which is deprecated.\n\nThe 1.x series is no longer maintained.\n\nUse example/vpc/aws instead."
+	if got := diag.Description().Detail; got != wantDetail {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, wantDetail)
+	}
+	if got := diag.Source().Subject; got == nil || got.Start.Line != 2 {
+		t.Errorf("wrong subject %#v; want the source argument", got)
+	}
+	if got, want := tfdiags.DiagnosticCode(diag), tfdiags.CodeDeprecatedModule; got != want {
+		t.Errorf("wrong code %q; want %q", got, want)
+	}
+
+	// A mapping takes precedence, and applies whatever registry the module
+	// came from.
+	mapping := &Mapping{Rules: []*Rule{{
+		Source:      "example/network/aws",
+		Reason:      "Use the VPC module.",
+		source:      addrs.MustParseModuleSource("example/network/aws"),
+		versions:    version.MustConstraints(version.NewConstraint(">= 2.0.0")),
+		Replacement: "example/vpc/aws",
+	}}}
+	diags = (&Checker{Mapping: mapping}).Check(root)
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.ErrWithWarnings())
+	}
+	if got := diags[0].Description().Detail; !strings.Contains(got, `"current"`) || !strings.Contains(got, "Use the VPC module.") {
+		t.Errorf("wrong detail %q", got)
+	}
+}
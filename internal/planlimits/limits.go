@@ -0,0 +1,119 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package planlimits checks the number of objects that a plan creates,
+// updates and destroys against configured limits, so that a bad git diff
+// can't destroy or create many resources by accident.
+package planlimits
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// maxListed is how many of the changes over a limit a diagnostic lists.
+const maxListed = 10
+
+// Limit is the largest number of objects that a plan can change in some way.
+type Limit struct {
+	Max int
+
+	// Source describes where the limit is set, such as "the -max-destroy
+	// option", for diagnostics.
+	Source string
+}
+
+// Limits are the limits of a plan. A nil limit means no limit.
+type Limits struct {
+	Create  *Limit
+	Update  *Limit
+	Destroy *Limit
+}
+
+// Empty returns whether there are no limits.
+func (l Limits) Empty() bool {
+	return l.Create == nil && l.Update == nil && l.Destroy == nil
+}
+
+// Override returns the limits, with those set in other taking precedence.
+func (l Limits) Override(other Limits) Limits {
+	if other.Create != nil {
+		l.Create = other.Create
+	}
+	if other.Update != nil {
+		l.Update = other.Update
+	}
+	if other.Destroy != nil {
+		l.Destroy = other.Destroy
+	}
+	return l
+}
+
+// Check counts the objects that the plan creates, updates and destroys, and
+// returns an error for each limit that the plan exceeds, or a warning
+// instead if allowExceeding is true. A replacement counts as both a create
+// and a destroy.
+func (l Limits) Check(plan *plans.Plan, allowExceeding bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if plan == nil || plan.Changes == nil || l.Empty() {
+		return diags
+	}
+
+	var create, update, destroy []string
+	for _, change := range plan.Changes.Resources {
+		addr := change.Addr.String()
+		if change.DeposedKey != states.NotDeposed {
+			addr = fmt.Sprintf("%s (deposed object %s)", addr, change.DeposedKey)
+		}
+		switch change.Action {
+		case plans.Create, plans.ForgetThenCreate:
+			create = append(create, addr)
+		case plans.Update:
+			update = append(update, addr)
+		case plans.Delete:
+			destroy = append(destroy, addr)
+		case plans.DeleteThenCreate, plans.CreateThenDelete:
+			create = append(create, addr)
+			destroy = append(destroy, addr)
+		}
+	}
+
+	diags = diags.Append(check(l.Create, "create", "creates", create, allowExceeding))
+	diags = diags.Append(check(l.Update, "update", "updates", update, allowExceeding))
+	diags = diags.Append(check(l.Destroy, "destroy", "destroys", destroy, allowExceeding))
+	return diags
+}
+
+func check(limit *Limit, noun, verb string, addrs []string, allowExceeding bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if limit == nil || len(addrs) <= limit.Max {
+		return diags
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "The plan %s %d objects, more than the limit of %d set by %s:\n", verb, len(addrs), limit.Max, limit.Source)
+	for _, addr := range addrs[:min(len(addrs), maxListed)] {
+		fmt.Fprintf(&buf, "\n  - %s", addr)
+	}
+	if len(addrs) > maxListed {
+		fmt.Fprintf(&buf, "\n  - and %d more", len(addrs)-maxListed)
+	}
+
+	severity := tfdiags.Error
+	if allowExceeding {
+		severity = tfdiags.Warning
+		buf.WriteString("\n\nThe plan was allowed to exceed the limit by the -allow-exceeding-limits option.")
+	} else {
+		buf.WriteString("\n\nCheck that the changes are intended, such as that the commits being planned don't remove or rename more resources than expected. If they are, plan again with the -allow-exceeding-limits option.")
+	}
+	diags = diags.Append(tfdiags.WithCode(tfdiags.Sourceless(
+		severity,
+		fmt.Sprintf("Plan exceeds the %s limit", noun),
+		buf.String(),
+	), tfdiags.CodePlanLimitExceeded))
+	return diags
+}
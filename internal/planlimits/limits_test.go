@@ -0,0 +1,88 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package planlimits
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestCheck(t *testing.T) {
+	changes := &plans.Changes{}
+	add := func(action plans.Action, n int) {
+		for i := range n {
+			addr := addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: fmt.Sprintf("%s%d", strings.ToLower(action.String()), i),
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+			changes.Resources = append(changes.Resources, &plans.ResourceInstanceChangeSrc{
+				Addr:      addr,
+				ChangeSrc: plans.ChangeSrc{Action: action},
+			})
+		}
+	}
+	add(plans.Create, 3)
+	add(plans.Update, 2)
+	add(plans.Delete, 11)
+	add(plans.DeleteThenCreate, 1)
+	add(plans.NoOp, 20)
+	plan := &plans.Plan{Changes: changes}
+
+	limits := Limits{
+		Create:  &Limit{Max: 4, Source: "the -max-create option"},
+		Destroy: &Limit{Max: 10, Source: "the plan_limits block of the CLI configuration"},
+	}
+
+	diags := limits.Check(plan, false)
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, want 1: %s", len(diags), diags.ErrWithWarnings())
+	}
+	diag := diags[0]
+	if diag.Severity() != tfdiags.Error {
+		t.Errorf("wrong severity %s", diag.Severity())
+	}
+	if got, want := tfdiags.DiagnosticCode(diag), tfdiags.CodePlanLimitExceeded; got != want {
+		t.Errorf("wrong code %q, want %q", got, want)
+	}
+	desc := diag.Description()
+	if got, want := desc.Summary, "Plan exceeds the destroy limit"; got != want {
+		t.Errorf("wrong summary %q, want %q", got, want)
+	}
+	for _, want := range []string{
+		"The plan destroys 12 objects, more than the limit of 10 set by the plan_limits block of the CLI configuration:",
+		"  - test_instance.delete0\n",
+		"  - and 2 more",
+		"-allow-exceeding-limits",
+	} {
+		if !strings.Contains(desc.Detail, want) {
+			t.Errorf("detail doesn't contain %q:\n%s", want, desc.Detail)
+		}
+	}
+
+	diags = limits.Check(plan, true)
+	if len(diags) != 1 || diags[0].Severity() != tfdiags.Warning {
+		t.Errorf("want a single warning when allowed to exceed the limits, got %s", diags.ErrWithWarnings())
+	}
+
+	if diags := (Limits{}).Check(plan, false); len(diags) != 0 {
+		t.Errorf("unexpected diagnostics without limits: %s", diags.ErrWithWarnings())
+	}
+}
+
+func TestOverride(t *testing.T) {
+	config := Limits{
+		Create:  &Limit{Max: 100, Source: "config"},
+		Destroy: &Limit{Max: 10, Source: "config"},
+	}
+	got := config.Override(Limits{Destroy: &Limit{Max: 0, Source: "flag"}})
+	if got.Create.Source != "config" || got.Destroy.Source != "flag" || got.Destroy.Max != 0 || got.Update != nil {
+		t.Errorf("wrong limits %#v", got)
+	}
+}
@@ -267,6 +267,13 @@ type ResourceInstanceChange struct {
 	// Farseek that relates to this change. Farseek will save this
 	// byte-for-byte and return it to the provider in the apply call.
 	Private []byte
+
+	// ProviderDeferred is set for a change that was planned without its
+	// provider, because the provider's configuration wasn't known yet. Only
+	// the configuration decided the action of such a change, so when it's
+	// planned again with the configured provider during apply, an update
+	// may turn out to be a replacement.
+	ProviderDeferred bool
 }
 
 // Encode produces a variant of the receiver that has its change values
@@ -284,14 +291,15 @@ func (rc *ResourceInstanceChange) Encode(ty cty.Type) (*ResourceInstanceChangeSr
 		prevRunAddr = rc.Addr
 	}
 	return &ResourceInstanceChangeSrc{
-		Addr:            rc.Addr,
-		PrevRunAddr:     prevRunAddr,
-		DeposedKey:      rc.DeposedKey,
-		ProviderAddr:    rc.ProviderAddr,
-		ChangeSrc:       *cs,
-		ActionReason:    rc.ActionReason,
-		RequiredReplace: rc.RequiredReplace,
-		Private:         rc.Private,
+		Addr:             rc.Addr,
+		PrevRunAddr:      prevRunAddr,
+		DeposedKey:       rc.DeposedKey,
+		ProviderAddr:     rc.ProviderAddr,
+		ChangeSrc:        *cs,
+		ActionReason:     rc.ActionReason,
+		RequiredReplace:  rc.RequiredReplace,
+		Private:          rc.Private,
+		ProviderDeferred: rc.ProviderDeferred,
 	}, err
 }
 
@@ -76,6 +76,10 @@ type ResourceInstanceChangeSrc struct {
 	// Farseek that relates to this change. Farseek will save this
 	// byte-for-byte and return it to the provider in the apply call.
 	Private []byte
+
+	// ProviderDeferred is set for a change that was planned without its
+	// provider. See the field of the same name in ResourceInstanceChange.
+	ProviderDeferred bool
 }
 
 // Decode unmarshals the raw representation of the instance object being
@@ -93,14 +97,15 @@ func (rcs *ResourceInstanceChangeSrc) Decode(ty cty.Type) (*ResourceInstanceChan
 		prevRunAddr = rcs.Addr
 	}
 	return &ResourceInstanceChange{
-		Addr:            rcs.Addr,
-		PrevRunAddr:     prevRunAddr,
-		DeposedKey:      rcs.DeposedKey,
-		ProviderAddr:    rcs.ProviderAddr,
-		Change:          *change,
-		ActionReason:    rcs.ActionReason,
-		RequiredReplace: rcs.RequiredReplace,
-		Private:         rcs.Private,
+		Addr:             rcs.Addr,
+		PrevRunAddr:      prevRunAddr,
+		DeposedKey:       rcs.DeposedKey,
+		ProviderAddr:     rcs.ProviderAddr,
+		Change:           *change,
+		ActionReason:     rcs.ActionReason,
+		RequiredReplace:  rcs.RequiredReplace,
+		Private:          rcs.Private,
+		ProviderDeferred: rcs.ProviderDeferred,
 	}, nil
 }
 
@@ -0,0 +1,19 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"time"
+)
+
+// Commit describes a commit in the version control history of a
+// configuration, which a plan in Farseek mode can attribute changes to.
+type Commit struct {
+	SHA    string
+	Author string
+	Time   time.Time
+
+	// Message is the first line of the commit message.
+	Message string
+}
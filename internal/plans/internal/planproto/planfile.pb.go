@@ -419,6 +419,14 @@ type Plan struct {
 	// EphemeralVariables records the ephemeral variables later used
 	// be able to validate the values for these during the apply command.
 	EphemeralVariables []string `protobuf:"bytes,22,rep,name=ephemeral_variables,json=ephemeralVariables,proto3" json:"ephemeral_variables,omitempty"`
+	// Runtime is the name of the language runtime that created the plan,
+	// either "legacy" or "experimental". It's unset for plans created before
+	// this field was added, which all used the legacy runtime.
+	Runtime string `protobuf:"bytes,23,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// ForceReplaceReasons are the reasons given for replacing some of the
+	// force_replace_addrs, by the address of the resource instance, so that
+	// the plan can show them.
+	ForceReplaceReasons map[string]string `protobuf:"bytes,24,rep,name=force_replace_reasons,json=forceReplaceReasons,proto3" json:"force_replace_reasons,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	// TempExecutionGraph is a temporary addition for the "walking skeleton"
 	// phase of implementing the new language runtime, and in particular
 	// the internal/engine packages. It's always unset when using the
@@ -582,6 +590,20 @@ func (x *Plan) GetEphemeralVariables() []string {
 	return nil
 }
 
+func (x *Plan) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+func (x *Plan) GetForceReplaceReasons() map[string]string {
+	if x != nil {
+		return x.ForceReplaceReasons
+	}
+	return nil
+}
+
 func (x *Plan) GetTempExecutionGraph() []byte {
 	if x != nil {
 		return x.TempExecutionGraph
@@ -792,9 +814,13 @@ type ResourceInstanceChange struct {
 	// Optional extra user-oriented context for why change.Action was chosen.
 	// This is for user feedback only and never used to drive behavior during
 	// apply.
-	ActionReason  ResourceInstanceActionReason `protobuf:"varint,12,opt,name=action_reason,json=actionReason,proto3,enum=tfplan.ResourceInstanceActionReason" json:"action_reason,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	ActionReason ResourceInstanceActionReason `protobuf:"varint,12,opt,name=action_reason,json=actionReason,proto3,enum=tfplan.ResourceInstanceActionReason" json:"action_reason,omitempty"`
+	// provider_deferred, if set, indicates that this change was planned
+	// without its provider, whose configuration wasn't known yet, so the
+	// change may become a replacement when it's planned again during apply.
+	ProviderDeferred bool `protobuf:"varint,15,opt,name=provider_deferred,json=providerDeferred,proto3" json:"provider_deferred,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *ResourceInstanceChange) Reset() {
@@ -883,6 +909,13 @@ func (x *ResourceInstanceChange) GetActionReason() ResourceInstanceActionReason
 	return ResourceInstanceActionReason_NONE
 }
 
+func (x *ResourceInstanceChange) GetProviderDeferred() bool {
+	if x != nil {
+		return x.ProviderDeferred
+	}
+	return false
+}
+
 type OutputChange struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Name of the output as defined in the root module.
@@ -1235,7 +1268,7 @@ type CheckResults_ObjectResult struct {
 
 func (x *CheckResults_ObjectResult) Reset() {
 	*x = CheckResults_ObjectResult{}
-	mi := &file_planfile_proto_msgTypes[11]
+	mi := &file_planfile_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1247,7 +1280,7 @@ func (x *CheckResults_ObjectResult) String() string {
 func (*CheckResults_ObjectResult) ProtoMessage() {}
 
 func (x *CheckResults_ObjectResult) ProtoReflect() protoreflect.Message {
-	mi := &file_planfile_proto_msgTypes[11]
+	mi := &file_planfile_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1297,7 +1330,7 @@ type Path_Step struct {
 
 func (x *Path_Step) Reset() {
 	*x = Path_Step{}
-	mi := &file_planfile_proto_msgTypes[12]
+	mi := &file_planfile_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1309,7 +1342,7 @@ func (x *Path_Step) String() string {
 func (*Path_Step) ProtoMessage() {}
 
 func (x *Path_Step) ProtoReflect() protoreflect.Message {
-	mi := &file_planfile_proto_msgTypes[12]
+	mi := &file_planfile_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1374,7 +1407,7 @@ var File_planfile_proto protoreflect.FileDescriptor
 
 const file_planfile_proto_rawDesc = "" +
 	"\n" +
-	"\x0eplanfile.proto\x12\x06tfplan\"\xeb\a\n" +
+	"\x0eplanfile.proto\x12\x06tfplan\"\xa8\t\n" +
 	"\x04Plan\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\x04R\aversion\x12%\n" +
 	"\aui_mode\x18\x11 \x01(\x0e2\f.tfplan.ModeR\x06uiMode\x12\x18\n" +
@@ -1391,14 +1424,19 @@ const file_planfile_proto_rawDesc = "" +
 	"\abackend\x18\r \x01(\v2\x0f.tfplan.BackendR\abackend\x12K\n" +
 	"\x13relevant_attributes\x18\x0f \x03(\v2\x1a.tfplan.Plan.resource_attrR\x12relevantAttributes\x12\x1c\n" +
 	"\ttimestamp\x18\x15 \x01(\tR\ttimestamp\x12/\n" +
-	"\x13ephemeral_variables\x18\x16 \x03(\tR\x12ephemeralVariables\x124\n" +
+	"\x13ephemeral_variables\x18\x16 \x03(\tR\x12ephemeralVariables\x12\x18\n" +
+	"\aruntime\x18\x17 \x01(\tR\aruntime\x12Y\n" +
+	"\x15force_replace_reasons\x18\x18 \x03(\v2%.tfplan.Plan.ForceReplaceReasonsEntryR\x13forceReplaceReasons\x124\n" +
 	"\x14temp_execution_graph\x18\x80ʵ\xee\x01 \x01(\fR\x12tempExecutionGraph\x1aR\n" +
 	"\x0eVariablesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
 	"\x05value\x18\x02 \x01(\v2\x14.tfplan.DynamicValueR\x05value:\x028\x01\x1aM\n" +
 	"\rresource_attr\x12\x1a\n" +
 	"\bresource\x18\x01 \x01(\tR\bresource\x12 \n" +
-	"\x04attr\x18\x02 \x01(\v2\f.tfplan.PathR\x04attr\"i\n" +
+	"\x04attr\x18\x02 \x01(\v2\f.tfplan.PathR\x04attr\x1aF\n" +
+	"\x18ForceReplaceReasonsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"i\n" +
 	"\aBackend\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12,\n" +
 	"\x06config\x18\x02 \x01(\v2\x14.tfplan.DynamicValueR\x06config\x12\x1c\n" +
@@ -1409,7 +1447,7 @@ const file_planfile_proto_rawDesc = "" +
 	"\x16before_sensitive_paths\x18\x03 \x03(\v2\f.tfplan.PathR\x14beforeSensitivePaths\x12@\n" +
 	"\x15after_sensitive_paths\x18\x04 \x03(\v2\f.tfplan.PathR\x13afterSensitivePaths\x12/\n" +
 	"\timporting\x18\x05 \x01(\v2\x11.tfplan.ImportingR\timporting\x12)\n" +
-	"\x10generated_config\x18\x06 \x01(\tR\x0fgeneratedConfig\"\xd3\x02\n" +
+	"\x10generated_config\x18\x06 \x01(\tR\x0fgeneratedConfig\"\x80\x03\n" +
 	"\x16ResourceInstanceChange\x12\x12\n" +
 	"\x04addr\x18\r \x01(\tR\x04addr\x12\"\n" +
 	"\rprev_run_addr\x18\x0e \x01(\tR\vprevRunAddr\x12\x1f\n" +
@@ -1420,7 +1458,8 @@ const file_planfile_proto_rawDesc = "" +
 	"\aprivate\x18\n" +
 	" \x01(\fR\aprivate\x127\n" +
 	"\x10required_replace\x18\v \x03(\v2\f.tfplan.PathR\x0frequiredReplace\x12I\n" +
-	"\raction_reason\x18\f \x01(\x0e2$.tfplan.ResourceInstanceActionReasonR\factionReason\"h\n" +
+	"\raction_reason\x18\f \x01(\x0e2$.tfplan.ResourceInstanceActionReasonR\factionReason\x12+\n" +
+	"\x11provider_deferred\x18\x0f \x01(\bR\x10providerDeferred\"h\n" +
 	"\fOutputChange\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12&\n" +
 	"\x06change\x18\x02 \x01(\v2\x0e.tfplan.ChangeR\x06change\x12\x1c\n" +
@@ -1514,7 +1553,7 @@ func file_planfile_proto_rawDescGZIP() []byte {
 }
 
 var file_planfile_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_planfile_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_planfile_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_planfile_proto_goTypes = []any{
 	(Mode)(0),                         // 0: tfplan.Mode
 	(Action)(0),                       // 1: tfplan.Action
@@ -1532,8 +1571,9 @@ var file_planfile_proto_goTypes = []any{
 	(*Importing)(nil),                 // 13: tfplan.Importing
 	nil,                               // 14: tfplan.Plan.VariablesEntry
 	(*PlanResourceAttr)(nil),          // 15: tfplan.Plan.resource_attr
-	(*CheckResults_ObjectResult)(nil), // 16: tfplan.CheckResults.ObjectResult
-	(*Path_Step)(nil),                 // 17: tfplan.Path.Step
+	nil,                               // 16: tfplan.Plan.ForceReplaceReasonsEntry
+	(*CheckResults_ObjectResult)(nil), // 17: tfplan.CheckResults.ObjectResult
+	(*Path_Step)(nil),                 // 18: tfplan.Path.Step
 }
 var file_planfile_proto_depIdxs = []int32{
 	0,  // 0: tfplan.Plan.ui_mode:type_name -> tfplan.Mode
@@ -1544,29 +1584,30 @@ var file_planfile_proto_depIdxs = []int32{
 	10, // 5: tfplan.Plan.check_results:type_name -> tfplan.CheckResults
 	6,  // 6: tfplan.Plan.backend:type_name -> tfplan.Backend
 	15, // 7: tfplan.Plan.relevant_attributes:type_name -> tfplan.Plan.resource_attr
-	11, // 8: tfplan.Backend.config:type_name -> tfplan.DynamicValue
-	1,  // 9: tfplan.Change.action:type_name -> tfplan.Action
-	11, // 10: tfplan.Change.values:type_name -> tfplan.DynamicValue
-	12, // 11: tfplan.Change.before_sensitive_paths:type_name -> tfplan.Path
-	12, // 12: tfplan.Change.after_sensitive_paths:type_name -> tfplan.Path
-	13, // 13: tfplan.Change.importing:type_name -> tfplan.Importing
-	7,  // 14: tfplan.ResourceInstanceChange.change:type_name -> tfplan.Change
-	12, // 15: tfplan.ResourceInstanceChange.required_replace:type_name -> tfplan.Path
-	2,  // 16: tfplan.ResourceInstanceChange.action_reason:type_name -> tfplan.ResourceInstanceActionReason
-	7,  // 17: tfplan.OutputChange.change:type_name -> tfplan.Change
-	4,  // 18: tfplan.CheckResults.kind:type_name -> tfplan.CheckResults.ObjectKind
-	3,  // 19: tfplan.CheckResults.status:type_name -> tfplan.CheckResults.Status
-	16, // 20: tfplan.CheckResults.objects:type_name -> tfplan.CheckResults.ObjectResult
-	17, // 21: tfplan.Path.steps:type_name -> tfplan.Path.Step
-	11, // 22: tfplan.Plan.VariablesEntry.value:type_name -> tfplan.DynamicValue
-	12, // 23: tfplan.Plan.resource_attr.attr:type_name -> tfplan.Path
-	3,  // 24: tfplan.CheckResults.ObjectResult.status:type_name -> tfplan.CheckResults.Status
-	11, // 25: tfplan.Path.Step.element_key:type_name -> tfplan.DynamicValue
-	26, // [26:26] is the sub-list for method output_type
-	26, // [26:26] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
+	16, // 8: tfplan.Plan.force_replace_reasons:type_name -> tfplan.Plan.ForceReplaceReasonsEntry
+	11, // 9: tfplan.Backend.config:type_name -> tfplan.DynamicValue
+	1,  // 10: tfplan.Change.action:type_name -> tfplan.Action
+	11, // 11: tfplan.Change.values:type_name -> tfplan.DynamicValue
+	12, // 12: tfplan.Change.before_sensitive_paths:type_name -> tfplan.Path
+	12, // 13: tfplan.Change.after_sensitive_paths:type_name -> tfplan.Path
+	13, // 14: tfplan.Change.importing:type_name -> tfplan.Importing
+	7,  // 15: tfplan.ResourceInstanceChange.change:type_name -> tfplan.Change
+	12, // 16: tfplan.ResourceInstanceChange.required_replace:type_name -> tfplan.Path
+	2,  // 17: tfplan.ResourceInstanceChange.action_reason:type_name -> tfplan.ResourceInstanceActionReason
+	7,  // 18: tfplan.OutputChange.change:type_name -> tfplan.Change
+	4,  // 19: tfplan.CheckResults.kind:type_name -> tfplan.CheckResults.ObjectKind
+	3,  // 20: tfplan.CheckResults.status:type_name -> tfplan.CheckResults.Status
+	17, // 21: tfplan.CheckResults.objects:type_name -> tfplan.CheckResults.ObjectResult
+	18, // 22: tfplan.Path.steps:type_name -> tfplan.Path.Step
+	11, // 23: tfplan.Plan.VariablesEntry.value:type_name -> tfplan.DynamicValue
+	12, // 24: tfplan.Plan.resource_attr.attr:type_name -> tfplan.Path
+	3,  // 25: tfplan.CheckResults.ObjectResult.status:type_name -> tfplan.CheckResults.Status
+	11, // 26: tfplan.Path.Step.element_key:type_name -> tfplan.DynamicValue
+	27, // [27:27] is the sub-list for method output_type
+	27, // [27:27] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_planfile_proto_init() }
@@ -1574,7 +1615,7 @@ func file_planfile_proto_init() {
 	if File_planfile_proto != nil {
 		return
 	}
-	file_planfile_proto_msgTypes[12].OneofWrappers = []any{
+	file_planfile_proto_msgTypes[13].OneofWrappers = []any{
 		(*Path_Step_AttributeName)(nil),
 		(*Path_Step_ElementKey)(nil),
 	}
@@ -1584,7 +1625,7 @@ func file_planfile_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_planfile_proto_rawDesc), len(file_planfile_proto_rawDesc)),
 			NumEnums:      5,
-			NumMessages:   13,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
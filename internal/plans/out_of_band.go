@@ -0,0 +1,26 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// OutOfBandChange describes an attribute of a resource instance whose remote
+// value differs from the value that its configuration declared at the base
+// SHA of a plan in Farseek mode. Since the configuration didn't change it,
+// something outside of version control did, such as an edit in the console
+// of the cloud provider.
+type OutOfBandChange struct {
+	Attribute string
+
+	// Declared is the value that the configuration at the base SHA declared,
+	// and Actual the value that refreshing the remote object found.
+	Declared cty.Value
+	Actual   cty.Value
+
+	// Sensitive is true if the schema of the resource type marks the
+	// attribute as sensitive.
+	Sensitive bool
+}
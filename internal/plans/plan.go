@@ -63,9 +63,24 @@ type Plan struct {
 	ForceReplaceAddrs []addrs.AbsResourceInstance
 	Backend           Backend
 
+	// ForceReplaceReasons are the reasons given for replacing some of the
+	// ForceReplaceAddrs, by the address of the resource instance.
+	ForceReplaceReasons map[string]string
+
 	// FarseekMode is true if the plan was created in Farseek stateless mode.
 	FarseekMode bool
 
+	// Commits are the commits that last changed the configuration of the
+	// resources that Farseek discovered, by the address of the resource. It
+	// is only set in Farseek stateless mode, and isn't saved in plan files.
+	Commits map[string]*Commit
+
+	// OutOfBandChanges are the attributes of resource instances whose remote
+	// values differ from the values that their configuration declared at the
+	// base SHA, by the address of the resource instance. It is only set in
+	// Farseek stateless mode, and isn't saved in plan files.
+	OutOfBandChanges map[string][]*OutOfBandChange
+
 	// Errored is true if the Changes information is incomplete because
 	// the planning operation failed. An errored plan cannot be applied,
 	// but can be cautiously inspected for debugging purposes.
@@ -145,6 +160,11 @@ type Plan struct {
 	// because that runtime generates the apply-time execution graph during
 	// the apply phase using data in the other fields of this type.
 	ExecutionGraph []byte
+
+	// Runtime is the language runtime that created this plan. It's empty
+	// for plans that were created before the runtime was recorded, which
+	// were all created by [LegacyRuntime].
+	Runtime Runtime
 }
 
 // CanApply returns true if and only if the receiving plan includes content
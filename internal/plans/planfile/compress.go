@@ -0,0 +1,51 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"archive/zip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdMethod is the compression method number that the zip format
+// specification assigns to Zstandard.
+//
+// Plan files created with CreateArgs.Compress use this method for all of
+// their entries. Readers register a decompressor for it unconditionally, so
+// both layouts are detected automatically when opening a plan file.
+const zstdMethod uint16 = 93
+
+// tfplanChangesPrefix is the prefix of the names of the entries that hold
+// the planned resource changes in a compressed plan file, in chunks of at
+// most tfplanChunkSize changes each, so that they can be read one chunk at
+// a time. The "tfplan" entry then contains no resource changes itself.
+const tfplanChangesPrefix = "tfplan-changes/"
+
+const tfplanChunkSize = 500
+
+func zstdCompressor(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func zstdDecompressor(r io.Reader) io.ReadCloser {
+	dec, err := zstd.NewReader(r)
+	if err != nil {
+		return errReadCloser{err}
+	}
+	return dec.IOReadCloser()
+}
+
+// errReadCloser is an io.ReadCloser that always fails with the given error,
+// because a zip.Decompressor cannot return an error directly.
+type errReadCloser struct {
+	err error
+}
+
+func (r errReadCloser) Read([]byte) (int, error) { return 0, r.err }
+func (r errReadCloser) Close() error             { return nil }
+
+var _ zip.Compressor = zstdCompressor
+var _ zip.Decompressor = zstdDecompressor
@@ -154,7 +154,7 @@ func readConfigSnapshot(z *zip.Reader) (*configload.Snapshot, error) {
 // This file creates new files in the writer, so any already-open writer
 // for the file will be invalidated by this call. The writer remains open
 // when this function returns.
-func writeConfigSnapshot(snap *configload.Snapshot, z *zip.Writer) error {
+func writeConfigSnapshot(snap *configload.Snapshot, z *zip.Writer, method uint16) error {
 	// Errors from this function are expected to be reported with some
 	// additional prefix context about them being in a config snapshot,
 	// so they should not themselves refer to the config snapshot.
@@ -186,7 +186,7 @@ func writeConfigSnapshot(snap *configload.Snapshot, z *zip.Writer) error {
 		for filename, src := range snapMod.Files {
 			zh := &zip.FileHeader{
 				Name:     pathPrefix + filename,
-				Method:   zip.Deflate,
+				Method:   method,
 				Modified: time.Now(),
 			}
 			w, err := z.CreateHeader(zh)
@@ -204,7 +204,7 @@ func writeConfigSnapshot(snap *configload.Snapshot, z *zip.Writer) error {
 	{
 		zh := &zip.FileHeader{
 			Name:     configSnapshotManifestFile,
-			Method:   zip.Deflate,
+			Method:   method,
 			Modified: time.Now(),
 		}
 		src, err := json.MarshalIndent(manifest, "", "  ")
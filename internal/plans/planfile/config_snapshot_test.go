@@ -36,7 +36,7 @@ func TestConfigSnapshotRoundtrip(t *testing.T) {
 
 	var buf bytes.Buffer
 	zw := zip.NewWriter(&buf)
-	err = writeConfigSnapshot(snapIn, zw)
+	err = writeConfigSnapshot(snapIn, zw, zip.Deflate)
 	if err != nil {
 		t.Fatalf("failed to write snapshot: %s", err)
 	}
@@ -0,0 +1,120 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+)
+
+// appliedFilename is the plan file entry listing the resource instances whose
+// changes were already applied by a targeted apply of the plan. A plan file
+// with this entry is partially applied and must not be applied again.
+const appliedFilename = "applied"
+
+func writeAppliedAddrs(zw *zip.Writer, method uint16, applied []addrs.AbsResourceInstance) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     appliedFilename,
+		Method:   method,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create applied changes file: %w", err)
+	}
+	for _, addr := range applied {
+		if _, err := fmt.Fprintln(w, addr.String()); err != nil {
+			return fmt.Errorf("failed to write applied changes file: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemainderPath returns the path of the plan file that holds the changes left
+// over after a targeted apply of the plan file at the given path.
+func RemainderPath(filename string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + ".remainder" + ext
+}
+
+// ReadAppliedAddrs returns the addresses of the resource instances whose
+// changes were already applied from this plan by a targeted apply.
+//
+// The result is empty for a plan file that hasn't been partially applied.
+func (r *Reader) ReadAppliedAddrs() ([]addrs.AbsResourceInstance, error) {
+	file := r.file(appliedFilename)
+	if file == nil {
+		return nil, nil
+	}
+	fr, err := file.Open()
+	if err != nil {
+		return nil, errUnusable(fmt.Errorf("failed to retrieve applied changes from plan file: %w", err))
+	}
+	defer fr.Close()
+
+	var ret []addrs.AbsResourceInstance
+	sc := bufio.NewScanner(fr)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		addr, diags := addrs.ParseAbsResourceInstanceStr(line)
+		if diags.HasErrors() {
+			return nil, errUnusable(fmt.Errorf("invalid applied resource instance address %q in plan file", line))
+		}
+		ret = append(ret, addr)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, errUnusable(fmt.Errorf("failed to read applied changes from plan file: %w", err))
+	}
+	return ret, nil
+}
+
+// CreateArgs returns arguments for Create or Write that reproduce this plan
+// file, so that callers can write an amended copy of it.
+func (r *Reader) CreateArgs() (CreateArgs, error) {
+	var args CreateArgs
+	var err error
+
+	if args.ConfigSnapshot, err = r.ReadConfigSnapshot(); err != nil {
+		return args, err
+	}
+	if args.Plan, err = r.ReadPlan(); err != nil {
+		return args, err
+	}
+	if args.StateFile, err = r.ReadStateFile(); err != nil {
+		return args, err
+	}
+	if args.PreviousRunStateFile, err = r.ReadPrevStateFile(); err != nil {
+		return args, err
+	}
+	if args.AppliedAddrs, err = r.ReadAppliedAddrs(); err != nil {
+		return args, err
+	}
+	if r.file(dependencyLocksFilename) != nil {
+		locks, diags := r.ReadDependencyLocks()
+		if diags.HasErrors() {
+			return args, diags.Err()
+		}
+		args.DependencyLocks = locks
+	}
+	args.Compress = r.compressed()
+	return args, nil
+}
+
+// file returns the entry with the given name, or nil if there is none.
+func (r *Reader) file(name string) *zip.File {
+	for _, file := range r.zip.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
@@ -8,12 +8,14 @@
 package planfile
 
 import (
+	"io"
 	"path/filepath"
 	"strings"
 	"syscall"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs"
@@ -210,3 +212,371 @@ func TestWrappedError(t *testing.T) {
 		t.Fatalf("expected  %q, got %q", missingFileError, err)
 	}
 }
+
+func TestRoundtripStream(t *testing.T) {
+	fixtureDir := filepath.Join("testdata", "test-config")
+	loader, err := configload.NewLoader(&configload.Config{
+		ModulesDir: filepath.Join(fixtureDir, ".farseek", "modules"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, snapIn, diags := loader.LoadConfigWithSnapshot(t.Context(), fixtureDir, configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	stateFileIn := &statefile.File{
+		TerraformVersion: tfversion.SemVer,
+		Serial:           1,
+		Lineage:          "abc123",
+		State:            states.NewState(),
+		EncryptionStatus: encryption.StatusSatisfied,
+	}
+	planIn := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{},
+			Outputs:   []*plans.OutputChangeSrc{},
+		},
+		DriftedResources:   []*plans.ResourceInstanceChangeSrc{},
+		VariableValues:     map[string]plans.DynamicValue{},
+		EphemeralVariables: map[string]bool{},
+		Backend: plans.Backend{
+			Type:      "local",
+			Config:    plans.DynamicValue([]byte("config placeholder")),
+			Workspace: "default",
+		},
+		Checks:       &states.CheckResults{},
+		PrevRunState: stateFileIn.State,
+		PriorState:   stateFileIn.State,
+	}
+
+	// A pipe is not seekable, so this makes sure that neither side needs
+	// random access to the stream itself.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(Write(pw, CreateArgs{
+			ConfigSnapshot:       snapIn,
+			PreviousRunStateFile: stateFileIn,
+			StateFile:            stateFileIn,
+			Plan:                 planIn,
+		}, encryption.PlanEncryptionDisabled()))
+	}()
+
+	wpf, err := OpenWrappedStream(pr, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to open plan file from stream: %s", err)
+	}
+	r, ok := wpf.Local()
+	if !ok {
+		t.Fatalf("failed to open plan file as a local plan file")
+	}
+	planOut, err := r.ReadPlan()
+	if err != nil {
+		t.Fatalf("failed to read plan: %s", err)
+	}
+	if diff := cmp.Diff(planIn, planOut); diff != "" {
+		t.Errorf("plan did not survive round-trip\n%s", diff)
+	}
+
+	_, err = OpenWrappedStream(strings.NewReader(""), encryption.PlanEncryptionDisabled())
+	if err == nil || !strings.Contains(err.Error(), "no plan file data") {
+		t.Fatalf("wrong error for empty stream: %v", err)
+	}
+}
+
+func TestRoundtripCompressed(t *testing.T) {
+	fixtureDir := filepath.Join("testdata", "test-config")
+	loader, err := configload.NewLoader(&configload.Config{
+		ModulesDir: filepath.Join(fixtureDir, ".farseek", "modules"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, snapIn, diags := loader.LoadConfigWithSnapshot(t.Context(), fixtureDir, configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	stateFileIn := &statefile.File{
+		TerraformVersion: tfversion.SemVer,
+		Serial:           1,
+		Lineage:          "abc123",
+		State:            states.NewState(),
+		EncryptionStatus: encryption.StatusSatisfied,
+	}
+
+	// Enough changes to need several chunks, the last one partial.
+	objTy := cty.Object(map[string]cty.Type{"id": cty.String})
+	var changesIn []*plans.ResourceInstanceChangeSrc
+	for i := 0; i < tfplanChunkSize*2+1; i++ {
+		addr := addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_thing",
+			Name: "woot",
+		}.Instance(addrs.IntKey(i)).Absolute(addrs.RootModuleInstance)
+		changesIn = append(changesIn, &plans.ResourceInstanceChangeSrc{
+			Addr:        addr,
+			PrevRunAddr: addr,
+			ProviderAddr: addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			ChangeSrc: plans.ChangeSrc{
+				Action: plans.Create,
+				After: mustNewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+					"id": cty.UnknownVal(cty.String),
+				}), objTy),
+			},
+		})
+	}
+
+	planIn := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: changesIn,
+			Outputs:   []*plans.OutputChangeSrc{},
+		},
+		DriftedResources:   []*plans.ResourceInstanceChangeSrc{},
+		VariableValues:     map[string]plans.DynamicValue{},
+		EphemeralVariables: map[string]bool{},
+		Backend: plans.Backend{
+			Type:      "local",
+			Config:    plans.DynamicValue([]byte("config placeholder")),
+			Workspace: "default",
+		},
+		Checks:       &states.CheckResults{},
+		PrevRunState: stateFileIn.State,
+		PriorState:   stateFileIn.State,
+	}
+
+	planFn := filepath.Join(t.TempDir(), "tfplan")
+	err = Create(planFn, CreateArgs{
+		ConfigSnapshot:       snapIn,
+		PreviousRunStateFile: stateFileIn,
+		StateFile:            stateFileIn,
+		Plan:                 planIn,
+		Compress:             true,
+	}, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to create plan file: %s", err)
+	}
+
+	pr, err := Open(planFn, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to open plan file for reading: %s", err)
+	}
+	chunks := 0
+	for _, file := range pr.zip.File {
+		if file.Method != zstdMethod {
+			t.Errorf("entry %s uses compression method %d; want %d", file.Name, file.Method, zstdMethod)
+		}
+		if strings.HasPrefix(file.Name, tfplanChangesPrefix) {
+			chunks++
+		}
+	}
+	if chunks != 3 {
+		t.Errorf("wrong number of resource change chunks %d; want 3", chunks)
+	}
+
+	t.Run("ReadPlan", func(t *testing.T) {
+		planOut, err := pr.ReadPlan()
+		if err != nil {
+			t.Fatalf("failed to read plan: %s", err)
+		}
+		if diff := cmp.Diff(planIn, planOut); diff != "" {
+			t.Errorf("plan did not survive round-trip\n%s", diff)
+		}
+	})
+
+	t.Run("ReadPlanWithoutChanges", func(t *testing.T) {
+		planOut, err := pr.ReadPlanWithoutChanges()
+		if err != nil {
+			t.Fatalf("failed to read plan: %s", err)
+		}
+		if len(planOut.Changes.Resources) != 0 {
+			t.Errorf("plan has %d resource changes; want none", len(planOut.Changes.Resources))
+		}
+		if diff := cmp.Diff(planIn.Backend, planOut.Backend); diff != "" {
+			t.Errorf("backend did not survive round-trip\n%s", diff)
+		}
+	})
+
+	t.Run("ResourceChanges", func(t *testing.T) {
+		var changesOut []*plans.ResourceInstanceChangeSrc
+		err := pr.ResourceChanges(func(change *plans.ResourceInstanceChangeSrc) error {
+			changesOut = append(changesOut, change)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to read resource changes: %s", err)
+		}
+		if diff := cmp.Diff(changesIn, changesOut); diff != "" {
+			t.Errorf("resource changes did not survive round-trip\n%s", diff)
+		}
+	})
+}
+
+func TestCreateArgsCompressed(t *testing.T) {
+	// A compressed plan with no resource changes has no chunks of them, but
+	// an amended copy of it must still be compressed.
+	stateFileIn := &statefile.File{
+		TerraformVersion: tfversion.SemVer,
+		Serial:           1,
+		Lineage:          "abc123",
+		State:            states.NewState(),
+		EncryptionStatus: encryption.StatusSatisfied,
+	}
+	planIn := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{},
+			Outputs:   []*plans.OutputChangeSrc{},
+		},
+		DriftedResources:   []*plans.ResourceInstanceChangeSrc{},
+		VariableValues:     map[string]plans.DynamicValue{},
+		EphemeralVariables: map[string]bool{},
+		Backend: plans.Backend{
+			Type:      "local",
+			Config:    plans.DynamicValue([]byte("config placeholder")),
+			Workspace: "default",
+		},
+		Checks:       &states.CheckResults{},
+		PrevRunState: stateFileIn.State,
+		PriorState:   stateFileIn.State,
+	}
+
+	for _, compress := range []bool{false, true} {
+		planPath := filepath.Join(t.TempDir(), "saved.tfplan")
+		err := Create(planPath, CreateArgs{
+			ConfigSnapshot:       configload.NewEmptySnapshot(),
+			PreviousRunStateFile: stateFileIn,
+			StateFile:            stateFileIn,
+			Plan:                 planIn,
+			Compress:             compress,
+		}, encryption.PlanEncryptionDisabled())
+		if err != nil {
+			t.Fatalf("failed to create plan file: %s", err)
+		}
+
+		pr, err := Open(planPath, encryption.PlanEncryptionDisabled())
+		if err != nil {
+			t.Fatalf("failed to open plan file: %s", err)
+		}
+		args, err := pr.CreateArgs()
+		if err != nil {
+			t.Fatalf("failed to read plan file: %s", err)
+		}
+		if args.Compress != compress {
+			t.Errorf("wrong Compress %t for a plan file created with Compress %t", args.Compress, compress)
+		}
+	}
+}
+
+func TestRoundtripPartiallyApplied(t *testing.T) {
+	fixtureDir := filepath.Join("testdata", "test-config")
+	loader, err := configload.NewLoader(&configload.Config{
+		ModulesDir: filepath.Join(fixtureDir, ".farseek", "modules"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, snapIn, diags := loader.LoadConfigWithSnapshot(t.Context(), fixtureDir, configs.RootModuleCallForTesting())
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	stateFileIn := &statefile.File{
+		TerraformVersion: tfversion.SemVer,
+		Serial:           1,
+		Lineage:          "abc123",
+		State:            states.NewState(),
+		EncryptionStatus: encryption.StatusSatisfied,
+	}
+	planIn := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{},
+			Outputs:   []*plans.OutputChangeSrc{},
+		},
+		DriftedResources:   []*plans.ResourceInstanceChangeSrc{},
+		VariableValues:     map[string]plans.DynamicValue{},
+		EphemeralVariables: map[string]bool{},
+		Backend: plans.Backend{
+			Type:      "local",
+			Config:    plans.DynamicValue([]byte("config placeholder")),
+			Workspace: "default",
+		},
+		Checks:       &states.CheckResults{},
+		PrevRunState: stateFileIn.State,
+		PriorState:   stateFileIn.State,
+	}
+
+	planPath := filepath.Join(t.TempDir(), "saved.tfplan")
+	err = Create(planPath, CreateArgs{
+		ConfigSnapshot:       snapIn,
+		PreviousRunStateFile: stateFileIn,
+		StateFile:            stateFileIn,
+		Plan:                 planIn,
+	}, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to create plan file: %s", err)
+	}
+
+	pr, err := Open(planPath, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to open plan file: %s", err)
+	}
+	applied, err := pr.ReadAppliedAddrs()
+	if err != nil {
+		t.Fatalf("failed to read applied addresses: %s", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("new plan file has applied addresses %v", applied)
+	}
+
+	// Marking the plan as partially applied keeps everything else intact.
+	args, err := pr.CreateArgs()
+	if err != nil {
+		t.Fatalf("failed to read plan file: %s", err)
+	}
+	appliedIn := []addrs.AbsResourceInstance{
+		mustResourceInstanceAddr("test_thing.woot[0]"),
+		mustResourceInstanceAddr("module.child.test_thing.woot[\"a\"]"),
+	}
+	args.AppliedAddrs = appliedIn
+	if err := Create(planPath, args, encryption.PlanEncryptionDisabled()); err != nil {
+		t.Fatalf("failed to rewrite plan file: %s", err)
+	}
+
+	pr, err = Open(planPath, encryption.PlanEncryptionDisabled())
+	if err != nil {
+		t.Fatalf("failed to open plan file: %s", err)
+	}
+	appliedOut, err := pr.ReadAppliedAddrs()
+	if err != nil {
+		t.Fatalf("failed to read applied addresses: %s", err)
+	}
+	if diff := cmp.Diff(appliedIn, appliedOut); diff != "" {
+		t.Errorf("applied addresses did not survive round-trip\n%s", diff)
+	}
+	planOut, err := pr.ReadPlan()
+	if err != nil {
+		t.Fatalf("failed to read plan: %s", err)
+	}
+	if diff := cmp.Diff(planIn, planOut); diff != "" {
+		t.Errorf("plan did not survive round-trip\n%s", diff)
+	}
+
+	if got, want := RemainderPath(planPath), strings.TrimSuffix(planPath, ".tfplan")+".remainder.tfplan"; got != want {
+		t.Errorf("wrong remainder path %q; want %q", got, want)
+	}
+}
+
+func mustResourceInstanceAddr(s string) addrs.AbsResourceInstance {
+	addr, diags := addrs.ParseAbsResourceInstanceStr(s)
+	if diags.HasErrors() {
+		panic(diags.Err())
+	}
+	return addr
+}
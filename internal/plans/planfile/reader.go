@@ -14,6 +14,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/rafagsiqueira/farseek/internal/configs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configload"
@@ -66,13 +68,33 @@ func Open(filename string, enc encryption.PlanEncryption) (*Reader, error) {
 	if err != nil {
 		return nil, err
 	}
+	return openBytes(raw, enc)
+}
+
+// OpenStream is like Open but reads the plan file from a stream, such as
+// standard input, which need not be seekable. Because the plan file format
+// requires random access, the whole stream is buffered in memory.
+func OpenStream(r io.Reader, enc encryption.PlanEncryption) (*Reader, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("no plan file data was received")
+	}
+	return openBytes(raw, enc)
+}
 
+func openBytes(raw []byte, enc encryption.PlanEncryption) (*Reader, error) {
 	decrypted, diags := enc.DecryptPlan(raw)
 	if diags != nil {
 		return nil, diags
 	}
 
 	r, err := zip.NewReader(bytes.NewReader(decrypted), int64(len(decrypted)))
+	if err == nil {
+		r.RegisterDecompressor(zstdMethod, zstdDecompressor)
+	}
 	if err != nil {
 		// Check to see if it's encrypted
 		if encrypted, _ := encryption.IsEncryptionPayload(decrypted); encrypted {
@@ -81,10 +103,8 @@ func Open(filename string, enc encryption.PlanEncryption) (*Reader, error) {
 
 		// To give a better error message, we'll sniff to see if this looks
 		// like our old plan format from versions prior to 0.12.
-		if b, sErr := os.ReadFile(filename); sErr == nil {
-			if bytes.HasPrefix(b, []byte("tfplan")) {
-				return nil, errUnusable(fmt.Errorf("the given plan file was created by an earlier version of Farseek, or an earlier version of Terraform; plan files cannot be shared between different Farseek or Terraform versions"))
-			}
+		if bytes.HasPrefix(raw, []byte("tfplan")) {
+			return nil, errUnusable(fmt.Errorf("the given plan file was created by an earlier version of Farseek, or an earlier version of Terraform; plan files cannot be shared between different Farseek or Terraform versions"))
 		}
 		return nil, err
 	}
@@ -117,13 +137,94 @@ func Open(filename string, enc encryption.PlanEncryption) (*Reader, error) {
 // is not of an appropriate format version, if it was created by a different
 // version of Farseek, if it is invalid, etc.
 func (r *Reader) ReadPlan() (*plans.Plan, error) {
-	var planFile *zip.File
-	for _, file := range r.zip.File {
-		if file.Name == tfplanFilename {
-			planFile = file
-			break
+	ret, err := r.readTfplan()
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.eachChangesChunk(func(changes []*plans.ResourceInstanceChangeSrc) error {
+		ret.Changes.Resources = append(ret.Changes.Resources, changes...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.readPlanStates(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ReadPlanWithoutChanges is like ReadPlan, but leaves out the planned
+// resource changes, for callers that read them with ResourceChanges instead
+// or don't need them at all.
+//
+// For plan files that aren't compressed, the resource changes are decoded
+// along with the rest of the plan regardless, so this saves nothing.
+func (r *Reader) ReadPlanWithoutChanges() (*plans.Plan, error) {
+	ret, err := r.readTfplan()
+	if err != nil {
+		return nil, err
+	}
+	ret.Changes.Resources = []*plans.ResourceInstanceChangeSrc{}
+
+	if err := r.readPlanStates(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// readPlanStates adds the previous run and prior states to a plan decoded
+// from the "tfplan" entry.
+func (r *Reader) readPlanStates(plan *plans.Plan) error {
+	prevRunStateFile, err := r.ReadPrevStateFile()
+	if err != nil {
+		return errUnusable(fmt.Errorf("failed to read previous run state from plan file: %w", err))
+	}
+	priorStateFile, err := r.ReadStateFile()
+	if err != nil {
+		return errUnusable(fmt.Errorf("failed to read prior state from plan file: %w", err))
+	}
+
+	plan.PrevRunState = prevRunStateFile.State
+	plan.PriorState = priorStateFile.State
+	return nil
+}
+
+// ResourceChanges calls fn for each of the planned resource changes in the
+// plan file, in order, stopping early if fn returns an error.
+//
+// For compressed plan files, only one chunk of changes is decoded at a time,
+// so this uses considerably less memory than ReadPlan for large plans.
+func (r *Reader) ResourceChanges(fn func(*plans.ResourceInstanceChangeSrc) error) error {
+	if r.chunked() {
+		return r.eachChangesChunk(func(changes []*plans.ResourceInstanceChangeSrc) error {
+			for _, change := range changes {
+				if err := fn(change); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	plan, err := r.readTfplan()
+	if err != nil {
+		return err
+	}
+	for _, change := range plan.Changes.Resources {
+		if err := fn(change); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// readTfplan decodes the "tfplan" entry alone, which includes the planned
+// resource changes only if the plan file isn't chunked.
+func (r *Reader) readTfplan() (*plans.Plan, error) {
+	planFile := r.file(tfplanFilename)
 	if planFile == nil {
 		// This should never happen because we checked for this file during
 		// Open, but we'll check anyway to be safe.
@@ -134,6 +235,7 @@ func (r *Reader) ReadPlan() (*plans.Plan, error) {
 	if err != nil {
 		return nil, errUnusable(fmt.Errorf("failed to retrieve plan from plan file: %w", err))
 	}
+	defer pr.Close()
 
 	// There's a slight mismatch in how plans.Plan is modeled vs. how
 	// the underlying plan file format works, because the "tfplan" embedded
@@ -150,20 +252,55 @@ func (r *Reader) ReadPlan() (*plans.Plan, error) {
 	if err != nil {
 		return nil, errUnusable(err)
 	}
+	return ret, nil
+}
 
-	prevRunStateFile, err := r.ReadPrevStateFile()
-	if err != nil {
-		return nil, errUnusable(fmt.Errorf("failed to read previous run state from plan file: %w", err))
-	}
-	priorStateFile, err := r.ReadStateFile()
-	if err != nil {
-		return nil, errUnusable(fmt.Errorf("failed to read prior state from plan file: %w", err))
+// compressed returns true if the plan file uses the compressed layout.
+func (r *Reader) compressed() bool {
+	planFile := r.file(tfplanFilename)
+	return planFile != nil && planFile.Method == zstdMethod
+}
+
+// chunked returns true if the plan file stores its resource changes in
+// separate chunks, rather than in the "tfplan" entry.
+func (r *Reader) chunked() bool {
+	for _, file := range r.zip.File {
+		if strings.HasPrefix(file.Name, tfplanChangesPrefix) {
+			return true
+		}
 	}
+	return false
+}
 
-	ret.PrevRunState = prevRunStateFile.State
-	ret.PriorState = priorStateFile.State
+// eachChangesChunk decodes each chunk of resource changes in turn, in the
+// order they were written, and passes it to fn.
+func (r *Reader) eachChangesChunk(fn func([]*plans.ResourceInstanceChangeSrc) error) error {
+	var chunks []*zip.File
+	for _, file := range r.zip.File {
+		if strings.HasPrefix(file.Name, tfplanChangesPrefix) {
+			chunks = append(chunks, file)
+		}
+	}
+	// The chunk names are zero-padded, so they sort in order.
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Name < chunks[j].Name
+	})
 
-	return ret, nil
+	for _, file := range chunks {
+		cr, err := file.Open()
+		if err != nil {
+			return errUnusable(fmt.Errorf("failed to retrieve resource changes from plan file: %w", err))
+		}
+		changes, err := readTfplanChanges(cr)
+		cr.Close()
+		if err != nil {
+			return errUnusable(err)
+		}
+		if err := fn(changes); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ReadStateFile reads the state file embedded in the plan file, which
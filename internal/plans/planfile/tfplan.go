@@ -241,6 +241,9 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		}
 		plan.ForceReplaceAddrs = append(plan.ForceReplaceAddrs, addr)
 	}
+	if len(rawPlan.ForceReplaceReasons) != 0 {
+		plan.ForceReplaceReasons = rawPlan.ForceReplaceReasons
+	}
 
 	for name, rawVal := range rawPlan.Variables {
 		val, err := valueFromTfplan(rawVal)
@@ -282,6 +285,13 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 	// Farseek language runtime.
 	plan.ExecutionGraph = rawPlan.TempExecutionGraph
 
+	switch runtime := plans.Runtime(rawPlan.Runtime); runtime {
+	case "", plans.LegacyRuntime, plans.ExperimentalRuntime:
+		plan.Runtime = runtime
+	default:
+		return nil, fmt.Errorf("plan was created by unsupported language runtime %q", runtime)
+	}
+
 	return plan, nil
 }
 
@@ -345,6 +355,7 @@ func resourceChangeFromTfplan(rawChange *planproto.ResourceInstanceChange) (*pla
 	}
 
 	ret.ChangeSrc = *change
+	ret.ProviderDeferred = rawChange.ProviderDeferred
 
 	switch rawChange.ActionReason {
 	case planproto.ResourceInstanceActionReason_NONE:
@@ -502,11 +513,97 @@ func valueFromTfplan(rawV *planproto.DynamicValue) (plans.DynamicValue, error) {
 // writeTfplan serializes the given plan into the protobuf-based format used
 // for the "tfplan" portion of a plan file.
 func writeTfplan(plan *plans.Plan, w io.Writer) error {
+	rawPlan, err := tfplanToProto(plan)
+	if err != nil {
+		return err
+	}
+	return writeTfplanProto(rawPlan, w)
+}
+
+// writeTfplanChunked is like writeTfplan but writes the planned resource
+// changes separately from the rest of the plan, in chunks of at most
+// chunkSize changes. It calls create to obtain a writer for the "tfplan"
+// entry and then for each chunk in turn.
+//
+// Each chunk is encoded as a plan message that contains only resource
+// changes, which readTfplanChanges decodes.
+func writeTfplanChunked(plan *plans.Plan, chunkSize int, create func(name string) (io.Writer, error)) error {
+	rawPlan, err := tfplanToProto(plan)
+	if err != nil {
+		return err
+	}
+	changes := rawPlan.ResourceChanges
+	rawPlan.ResourceChanges = nil
+
+	w, err := create(tfplanFilename)
+	if err != nil {
+		return fmt.Errorf("failed to create tfplan file: %w", err)
+	}
+	if err := writeTfplanProto(rawPlan, w); err != nil {
+		return err
+	}
+
+	for i := 0; len(changes) > 0; i++ {
+		n := min(chunkSize, len(changes))
+		w, err := create(fmt.Sprintf("%s%06d", tfplanChangesPrefix, i))
+		if err != nil {
+			return fmt.Errorf("failed to create resource changes file: %w", err)
+		}
+		chunk := &planproto.Plan{ResourceChanges: changes[:n]}
+		if err := writeTfplanProto(chunk, w); err != nil {
+			return err
+		}
+		changes = changes[n:]
+	}
+	return nil
+}
+
+// readTfplanChanges reads one chunk of resource changes written by
+// writeTfplanChunked.
+func readTfplanChanges(r io.Reader) ([]*plans.ResourceInstanceChangeSrc, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawPlan planproto.Plan
+	err = proto.Unmarshal(src, &rawPlan)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	ret := make([]*plans.ResourceInstanceChangeSrc, 0, len(rawPlan.ResourceChanges))
+	for _, rawRC := range rawPlan.ResourceChanges {
+		change, err := resourceChangeFromTfplan(rawRC)
+		if err != nil {
+			// errors from resourceChangeFromTfplan already include context
+			return nil, err
+		}
+		ret = append(ret, change)
+	}
+	return ret, nil
+}
+
+func writeTfplanProto(rawPlan *planproto.Plan, w io.Writer) error {
+	src, err := proto.Marshal(rawPlan)
+	if err != nil {
+		return fmt.Errorf("serialization error: %w", err)
+	}
+
+	_, err = w.Write(src)
+	if err != nil {
+		return fmt.Errorf("failed to write plan to plan file: %w", err)
+	}
+
+	return nil
+}
+
+func tfplanToProto(plan *plans.Plan) (*planproto.Plan, error) {
 	if plan == nil {
-		return fmt.Errorf("cannot write plan file for nil plan")
+		return nil, fmt.Errorf("cannot write plan file for nil plan")
 	}
 	if plan.Changes == nil {
-		return fmt.Errorf("cannot write plan file with nil changeset")
+		return nil, fmt.Errorf("cannot write plan file with nil changeset")
 	}
 
 	rawPlan := &planproto.Plan{
@@ -531,7 +628,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	case plans.RefreshOnlyMode:
 		rawPlan.UiMode = planproto.Mode_REFRESH_ONLY
 	default:
-		return fmt.Errorf("plan has unsupported mode %s", plan.UIMode)
+		return nil, fmt.Errorf("plan has unsupported mode %s", plan.UIMode)
 	}
 
 	for _, oc := range plan.Changes.Outputs {
@@ -549,7 +646,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 		// original type when we read the values back in readTFPlan.
 		protoChange, err := changeToTfplan(&oc.ChangeSrc)
 		if err != nil {
-			return fmt.Errorf("cannot write output value %q: %w", name, err)
+			return nil, fmt.Errorf("cannot write output value %q: %w", name, err)
 		}
 
 		rawPlan.OutputChanges = append(rawPlan.OutputChanges, &planproto.OutputChange{
@@ -575,7 +672,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 			case checks.StatusError:
 				pcrs.Status = planproto.CheckResults_ERROR
 			default:
-				return fmt.Errorf("checkable configuration %s has unsupported aggregate status %s", configElem.Key, crs.Status)
+				return nil, fmt.Errorf("checkable configuration %s has unsupported aggregate status %s", configElem.Key, crs.Status)
 			}
 			switch kind := configElem.Key.CheckableKind(); kind {
 			case addrs.CheckableResource:
@@ -587,7 +684,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 			case addrs.CheckableInputVariable:
 				pcrs.Kind = planproto.CheckResults_INPUT_VARIABLE
 			default:
-				return fmt.Errorf("checkable configuration %s has unsupported object type kind %s", configElem.Key, kind)
+				return nil, fmt.Errorf("checkable configuration %s has unsupported object type kind %s", configElem.Key, kind)
 			}
 
 			for _, objectElem := range configElem.Value.ObjectResults.Elems {
@@ -606,7 +703,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 				case checks.StatusError:
 					pcr.Status = planproto.CheckResults_ERROR
 				default:
-					return fmt.Errorf("checkable object %s has unsupported status %s", objectElem.Key, crs.Status)
+					return nil, fmt.Errorf("checkable object %s has unsupported status %s", objectElem.Key, crs.Status)
 				}
 				pcrs.Objects = append(pcrs.Objects, pcr)
 			}
@@ -618,7 +715,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	for _, rc := range plan.Changes.Resources {
 		rawRC, err := resourceChangeToTfplan(rc)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rawPlan.ResourceChanges = append(rawPlan.ResourceChanges, rawRC)
 	}
@@ -626,7 +723,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	for _, rc := range plan.DriftedResources {
 		rawRC, err := resourceChangeToTfplan(rc)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rawPlan.ResourceDrift = append(rawPlan.ResourceDrift, rawRC)
 	}
@@ -634,7 +731,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	for _, ra := range plan.RelevantAttributes {
 		rawRA, err := resourceAttrToTfplan(ra)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rawPlan.RelevantAttributes = append(rawPlan.RelevantAttributes, rawRA)
 	}
@@ -650,6 +747,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	for _, replaceAddr := range plan.ForceReplaceAddrs {
 		rawPlan.ForceReplaceAddrs = append(rawPlan.ForceReplaceAddrs, replaceAddr.String())
 	}
+	rawPlan.ForceReplaceReasons = plan.ForceReplaceReasons
 
 	for name, val := range plan.VariableValues {
 		if is, ok := plan.EphemeralVariables[name]; ok && is {
@@ -664,7 +762,7 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 		// This suggests a bug in the code that created the plan, since it
 		// ought to always have a backend populated, even if it's the default
 		// "local" backend with a local state file.
-		return fmt.Errorf("plan does not have a backend configuration")
+		return nil, fmt.Errorf("plan does not have a backend configuration")
 	}
 
 	rawPlan.Backend = &planproto.Backend{
@@ -683,18 +781,9 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 	// This field is always unset for plans generated by the traditional
 	// Farseek language runtime.
 	rawPlan.TempExecutionGraph = plan.ExecutionGraph
+	rawPlan.Runtime = string(plan.Runtime)
 
-	src, err := proto.Marshal(rawPlan)
-	if err != nil {
-		return fmt.Errorf("serialization error: %w", err)
-	}
-
-	_, err = w.Write(src)
-	if err != nil {
-		return fmt.Errorf("failed to write plan to plan file: %w", err)
-	}
-
-	return nil
+	return rawPlan, nil
 }
 
 func resourceAttrToTfplan(ra globalref.ResourceAttr) (*planproto.PlanResourceAttr, error) {
@@ -767,6 +856,7 @@ func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc) (*planproto
 		return nil, fmt.Errorf("failed to serialize resource %s change: %w", change.Addr, err)
 	}
 	ret.Change = valChange
+	ret.ProviderDeferred = change.ProviderDeferred
 
 	switch change.ActionReason {
 	case plans.ResourceInstanceChangeNoReason:
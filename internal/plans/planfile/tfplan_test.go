@@ -153,6 +153,32 @@ func TestTFPlanRoundTrip(t *testing.T) {
 						}), objTy),
 					},
 				},
+				{
+					Addr: addrs.Resource{
+						Mode: addrs.ManagedResourceMode,
+						Type: "test_thing",
+						Name: "deferred",
+					}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+					PrevRunAddr: addrs.Resource{
+						Mode: addrs.ManagedResourceMode,
+						Type: "test_thing",
+						Name: "deferred",
+					}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+					ProviderAddr: addrs.AbsProviderConfig{
+						Provider: addrs.NewDefaultProvider("test"),
+						Module:   addrs.RootModule,
+					},
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.Update,
+						Before: mustNewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+							"id": cty.StringVal("bar-baz-deferred"),
+						}), objTy),
+						After: mustNewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+							"id": cty.UnknownVal(cty.String),
+						}), objTy),
+					},
+					ProviderDeferred: true,
+				},
 				{
 					Addr: addrs.Resource{
 						Mode: addrs.ManagedResourceMode,
@@ -323,6 +349,17 @@ func TestTFPlanRoundTrip(t *testing.T) {
 			),
 			Workspace: "default",
 		},
+		ForceReplaceAddrs: []addrs.AbsResourceInstance{
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_thing",
+				Name: "woot",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+		},
+		ForceReplaceReasons: map[string]string{
+			"test_thing.woot": "kernel upgrade",
+		},
+		Runtime: plans.ExperimentalRuntime,
 	}
 
 	var buf bytes.Buffer
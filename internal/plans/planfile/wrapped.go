@@ -10,6 +10,7 @@ package planfile
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/rafagsiqueira/farseek/internal/encryption"
 )
@@ -56,6 +57,17 @@ func NewWrappedLocal(l *Reader) *WrappedPlanFile {
 func OpenWrapped(filename string, enc encryption.PlanEncryption) (*WrappedPlanFile, error) {
 	// First, try to load it as a local planfile.
 	local, localErr := Open(filename, enc)
+	return wrapLocal(local, localErr)
+}
+
+// OpenWrappedStream is like OpenWrapped but reads the plan file from a
+// stream, such as standard input, using OpenStream.
+func OpenWrappedStream(r io.Reader, enc encryption.PlanEncryption) (*WrappedPlanFile, error) {
+	local, localErr := OpenStream(r, enc)
+	return wrapLocal(local, localErr)
+}
+
+func wrapLocal(local *Reader, localErr error) (*WrappedPlanFile, error) {
 	if localErr == nil {
 		return &WrappedPlanFile{local: local}, nil
 	}
@@ -11,9 +11,11 @@ import (
 	"archive/zip"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	"github.com/rafagsiqueira/farseek/internal/configs/configload"
 	"github.com/rafagsiqueira/farseek/internal/depsfile"
 	"github.com/rafagsiqueira/farseek/internal/encryption"
@@ -48,6 +50,17 @@ type CreateArgs struct {
 	// checked prior to creating the plan, so we can make sure that all of the
 	// same dependencies are still available when applying the plan.
 	DependencyLocks *depsfile.Locks
+
+	// Compress selects the compressed plan file layout, in which all entries
+	// are compressed with Zstandard and the planned resource changes are
+	// stored in chunks that can be read individually. Open detects either
+	// layout automatically.
+	Compress bool
+
+	// AppliedAddrs marks the plan as partially applied, recording the
+	// resource instances whose changes were already applied by a targeted
+	// apply. It is empty for a plan that hasn't been applied at all.
+	AppliedAddrs []addrs.AbsResourceInstance
 }
 
 // Create creates a new plan file with the given filename, overwriting any
@@ -58,14 +71,42 @@ type CreateArgs struct {
 // if the world has changed since the plan was created and thus refuse to
 // apply it.
 func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, args, enc); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+// Write is like Create but writes the plan file to a stream, such as
+// standard output, which need not be seekable. Nothing is written unless
+// the whole plan file was built successfully.
+func Write(w io.Writer, args CreateArgs, enc encryption.PlanEncryption) error {
 	buff := bytes.NewBuffer(make([]byte, 0))
 	zw := zip.NewWriter(buff)
 
+	method := zip.Deflate
+	if args.Compress {
+		zw.RegisterCompressor(zstdMethod, zstdCompressor)
+		method = zstdMethod
+	}
+
 	// tfplan file
-	{
+	if args.Compress {
+		err := writeTfplanChunked(args.Plan, tfplanChunkSize, func(name string) (io.Writer, error) {
+			return zw.CreateHeader(&zip.FileHeader{
+				Name:     name,
+				Method:   method,
+				Modified: time.Now(),
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+	} else {
 		w, err := zw.CreateHeader(&zip.FileHeader{
 			Name:     tfplanFilename,
-			Method:   zip.Deflate,
+			Method:   method,
 			Modified: time.Now(),
 		})
 		if err != nil {
@@ -81,7 +122,7 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 	{
 		w, err := zw.CreateHeader(&zip.FileHeader{
 			Name:     tfstateFilename,
-			Method:   zip.Deflate,
+			Method:   method,
 			Modified: time.Now(),
 		})
 		if err != nil {
@@ -97,7 +138,7 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 	{
 		w, err := zw.CreateHeader(&zip.FileHeader{
 			Name:     tfstatePreviousFilename,
-			Method:   zip.Deflate,
+			Method:   method,
 			Modified: time.Now(),
 		})
 		if err != nil {
@@ -111,7 +152,7 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 
 	// tfconfig directory
 	{
-		err := writeConfigSnapshot(args.ConfigSnapshot, zw)
+		err := writeConfigSnapshot(args.ConfigSnapshot, zw, method)
 		if err != nil {
 			return fmt.Errorf("failed to write config snapshot: %w", err)
 		}
@@ -126,7 +167,7 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 
 		w, err := zw.CreateHeader(&zip.FileHeader{
 			Name:     dependencyLocksFilename,
-			Method:   zip.Deflate,
+			Method:   method,
 			Modified: time.Now(),
 		})
 		if err != nil {
@@ -138,6 +179,12 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 		}
 	}
 
+	if len(args.AppliedAddrs) > 0 {
+		if err := writeAppliedAddrs(zw, method, args.AppliedAddrs); err != nil {
+			return err
+		}
+	}
+
 	// Finish zip file
 	zw.Close()
 	// Encrypt payload
@@ -145,5 +192,6 @@ func Create(filename string, args CreateArgs, enc encryption.PlanEncryption) err
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, encrypted, 0644)
+	_, err = w.Write(encrypted)
+	return err
 }
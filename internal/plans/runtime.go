@@ -0,0 +1,16 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+// Runtime identifies the language runtime that created a plan.
+type Runtime string
+
+const (
+	// LegacyRuntime is the traditional language runtime in "package farseek".
+	LegacyRuntime Runtime = "legacy"
+
+	// ExperimentalRuntime is the new language runtime in lang/eval and
+	// engine/planning, which is available only in experiments-enabled builds.
+	ExperimentalRuntime Runtime = "experimental"
+)
@@ -64,6 +64,22 @@ func grpcErr(err error) (diags tfdiags.Diagnostics) {
 			"Request cancelled",
 			fmt.Sprintf("The %s request was cancelled.", requestName),
 		))
+	case codes.ResourceExhausted:
+		// This is usually a message larger than the limit of the client,
+		// such as a very large schema or state.
+		diags = diags.Append(tfdiags.WholeContainingBody(
+			tfdiags.Error,
+			"Plugin message too large",
+			fmt.Sprintf("The %s call exceeded a limit of the plugin connection: %s. "+
+				"To raise the message size limits, set max_receive_message_mb or max_send_message_mb in the provider_grpc block of the CLI configuration.", requestName, status.Convert(err).Message()),
+		))
+	case codes.DeadlineExceeded:
+		diags = diags.Append(tfdiags.WholeContainingBody(
+			tfdiags.Error,
+			"Plugin call timed out",
+			fmt.Sprintf("The plugin didn't respond to the %s call in time. "+
+				"The call_timeout_seconds argument in the provider_grpc block of the CLI configuration sets how long each call may take.", requestName),
+		))
 	case codes.Unimplemented:
 		diags = diags.Append(tfdiags.WholeContainingBody(
 			tfdiags.Error,
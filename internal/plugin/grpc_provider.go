@@ -86,6 +86,10 @@ type GRPCProvider struct {
 	// of the provider.
 	SchemaCache providers.SchemaCache
 
+	// MaxSchemaRecvMsgSize is the largest schema, in bytes, that the provider
+	// may return. If zero, the limit is defaultMaxSchemaRecvMsgSize.
+	MaxSchemaRecvMsgSize int
+
 	// Keep track of if the proto schema fetch call has happend for GetProviderSchemaOptional
 	// This allows caching to still function efficiently, without violating legacy provider's requirements
 	hasFetchedSchema bool
@@ -165,6 +169,11 @@ func (p *GRPCProvider) getProviderSchema(ctx context.Context) (resp providers.Ge
 	return resp
 }
 
+// defaultMaxSchemaRecvMsgSize is the largest schema, in bytes, that a
+// provider may return unless GRPCProvider.MaxSchemaRecvMsgSize says
+// otherwise.
+const defaultMaxSchemaRecvMsgSize = 64 << 20
+
 // Common code to fetch the raw schema data from the provider. This is called from
 // multiple locations due to GetProviderSchemaOptional
 func (p *GRPCProvider) getProtoProviderSchema(ctx context.Context) (*proto.GetProviderSchema_Response, error) {
@@ -176,7 +185,10 @@ func (p *GRPCProvider) getProtoProviderSchema(ctx context.Context) (*proto.GetPr
 	// this for compatibility, but recent providers all set the max message
 	// size much higher on the server side, which is the supported method for
 	// determining payload size.
-	const maxRecvSize = 64 << 20
+	maxRecvSize := p.MaxSchemaRecvMsgSize
+	if maxRecvSize == 0 {
+		maxRecvSize = defaultMaxSchemaRecvMsgSize
+	}
 	resp, err := p.client.GetSchema(ctx, new(proto.GetProviderSchema_Request), grpc.MaxRecvMsgSizeCallOption{MaxRecvMsgSize: maxRecvSize})
 
 	// Mark that we have handled the internal requirement for legacy providers (!GetProviderSchemaOptional)
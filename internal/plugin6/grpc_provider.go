@@ -94,6 +94,10 @@ type GRPCProvider struct {
 	// of the provider.
 	SchemaCache providers.SchemaCache
 
+	// MaxSchemaRecvMsgSize is the largest schema, in bytes, that the provider
+	// may return. If zero, the limit is defaultMaxSchemaRecvMsgSize.
+	MaxSchemaRecvMsgSize int
+
 	// Keep track of if the proto schema fetch call has happend for GetProviderSchemaOptional
 	// This allows caching to still function efficiently, without violating legacy provider's requirements
 	hasFetchedSchema bool
@@ -173,6 +177,11 @@ func (p *GRPCProvider) getProviderSchema(ctx context.Context) (resp providers.Ge
 	return resp
 }
 
+// defaultMaxSchemaRecvMsgSize is the largest schema, in bytes, that a
+// provider may return unless GRPCProvider.MaxSchemaRecvMsgSize says
+// otherwise.
+const defaultMaxSchemaRecvMsgSize = 64 << 20
+
 // Common code to fetch the raw schema data from the provider. This is called from
 // multiple locations due to GetProviderSchemaOptional
 func (p *GRPCProvider) getProtoProviderSchema(ctx context.Context) (*proto6.GetProviderSchema_Response, error) {
@@ -184,7 +193,10 @@ func (p *GRPCProvider) getProtoProviderSchema(ctx context.Context) (*proto6.GetP
 	// this for compatibility, but recent providers all set the max message
 	// size much higher on the server side, which is the supported method for
 	// determining payload size.
-	const maxRecvSize = 64 << 20
+	maxRecvSize := p.MaxSchemaRecvMsgSize
+	if maxRecvSize == 0 {
+		maxRecvSize = defaultMaxSchemaRecvMsgSize
+	}
 	resp, err := p.client.GetProviderSchema(ctx, new(proto6.GetProviderSchema_Request), grpc.MaxRecvMsgSizeCallOption{MaxRecvMsgSize: maxRecvSize})
 
 	// Mark that we have handled the internal requirement for legacy providers (!GetProviderSchemaOptional)
@@ -22,6 +22,8 @@ import (
 	"github.com/zclconf/go-cty/cty/msgpack"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/rafagsiqueira/farseek/internal/legacy/hcl2shim"
@@ -212,6 +214,56 @@ func TestGRPCProvider_GetSchema_GRPCError(t *testing.T) {
 	checkDiagsHasError(t, resp.Diagnostics)
 }
 
+func TestGRPCProvider_GetSchema_maxRecvMsgSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mockproto.NewMockProviderClient(ctrl)
+
+	var gotSize int
+	client.EXPECT().GetProviderSchema(
+		gomock.Any(),
+		gomock.Any(),
+		gomock.Any(),
+	).DoAndReturn(func(_ context.Context, _ *proto.GetProviderSchema_Request, opts ...grpc.CallOption) (*proto.GetProviderSchema_Response, error) {
+		for _, opt := range opts {
+			if opt, ok := opt.(grpc.MaxRecvMsgSizeCallOption); ok {
+				gotSize = opt.MaxRecvMsgSize
+			}
+		}
+		return providerProtoSchema(), nil
+	})
+
+	p := newGRPCProvider(client)
+	p.MaxSchemaRecvMsgSize = 256 << 20
+
+	resp := p.GetProviderSchema(t.Context())
+	checkDiags(t, resp.Diagnostics)
+	if gotSize != 256<<20 {
+		t.Errorf("wrong maximum schema size %d; want %d", gotSize, 256<<20)
+	}
+}
+
+func TestGRPCProvider_GetSchema_messageTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mockproto.NewMockProviderClient(ctrl)
+
+	client.EXPECT().GetProviderSchema(
+		gomock.Any(),
+		gomock.Any(),
+		gomock.Any(),
+	).Return(nil, status.Error(codes.ResourceExhausted, "grpc: received message larger than max (70000000 vs. 67108864)"))
+
+	p := newGRPCProvider(client)
+
+	resp := p.GetProviderSchema(t.Context())
+	checkDiagsHasError(t, resp.Diagnostics)
+	if got, want := resp.Diagnostics[0].Description().Summary, "Plugin message too large"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := resp.Diagnostics[0].Description().Detail, "max_receive_message_mb"; !strings.Contains(got, want) {
+		t.Errorf("wrong detail %q; want it to mention %q", got, want)
+	}
+}
+
 // Ensure that provider error diagnostics are returned early.
 // Reference: https://github.com/hashicorp/terraform/issues/31047
 func TestGRPCProvider_GetSchema_ResponseErrorDiagnostic(t *testing.T) {
@@ -49,6 +49,11 @@ func (d *Dir) installPackageWithLock(ctx context.Context, meta getproviders.Pack
 
 	log.Printf("[TRACE] providercache.Dir.InstallPackage: installing %s v%s from %s", meta.Provider, meta.Version, meta.Location)
 
+	// We hold the lock of the package, so no one else is installing it.
+	if err := getproviders.RemoveStaleInstalls(newPath); err != nil {
+		log.Printf("[WARN] providercache.Dir.InstallPackage: failed to clean up after an interrupted installation of %s v%s: %s", meta.Provider, meta.Version, err)
+	}
+
 	// Check to see if it is already installed
 	if entry := d.ProviderVersion(meta.Provider, meta.Version); entry != nil {
 		if allowSkippingInstallWithoutHashes && len(allowedHashes) == 0 {
@@ -8,7 +8,9 @@
 package providercache
 
 import (
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/apparentlymart/go-versions/versions"
@@ -71,6 +73,83 @@ func TestInstallPackage(t *testing.T) {
 	}
 }
 
+func TestInstallPackage_concurrent(t *testing.T) {
+	tmpDirPath, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linuxPlatform := getproviders.Platform{
+		OS:   "linux",
+		Arch: "amd64",
+	}
+	nullProvider := addrs.NewProvider(
+		addrs.DefaultProviderRegistryHost, "hashicorp", "null",
+	)
+	meta := getproviders.PackageMeta{
+		Provider: nullProvider,
+		Version:  versions.MustParseVersion("2.1.0"),
+
+		ProtocolVersions: getproviders.VersionList{versions.MustParseVersion("5.0.0")},
+		TargetPlatform:   linuxPlatform,
+
+		Filename: "provider-null_2.1.0_linux_amd64.zip",
+		Location: getproviders.PackageLocalArchive("testdata/provider-null_2.1.0_linux_amd64.zip"),
+	}
+	packageDir := filepath.Join(tmpDirPath, "registry.opentofu.org/hashicorp/null/2.1.0/linux_amd64")
+
+	// An interrupted installation left a staging directory behind, and an
+	// earlier version of Farseek a partially-unpacked package.
+	stale := filepath.Join(tmpDirPath, "registry.opentofu.org/hashicorp/null/2.1.0/.linux_amd64.tmp-123")
+	if err := os.MkdirAll(stale, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(packageDir, "partial"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each Dir stands for a separate "farseek init" sharing the cache.
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Go(func() {
+			dir := NewDirWithPlatform(tmpDirPath, linuxPlatform)
+			_, errs[i] = dir.InstallPackage(t.Context(), meta, nil, false)
+		})
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("InstallPackage failed: %s", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(packageDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if diff := cmp.Diff([]string{"linux_amd64", "linux_amd64.lock"}, names); diff != "" {
+		t.Errorf("wrong cache contents after install\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(packageDir, "partial")); !os.IsNotExist(err) {
+		t.Errorf("the partially-unpacked package wasn't replaced: %v", err)
+	}
+	entry := NewDirWithPlatform(tmpDirPath, linuxPlatform).ProviderVersion(nullProvider, meta.Version)
+	if entry == nil {
+		t.Fatal("the package isn't in the cache")
+	}
+	if _, err := entry.ExecutableFile(); err != nil {
+		t.Errorf("the package has no executable: %s", err)
+	}
+}
+
 func TestLinkFromOtherCache(t *testing.T) {
 	srcDirPath := "testdata/cachedir"
 	tmpDirPath, err := filepath.EvalSymlinks(t.TempDir())
@@ -534,6 +534,7 @@ func (i *Installer) ensureProviderVersionInstalled(
 				if cb := evts.ProviderAlreadyInstalled; cb != nil {
 					cb(provider, version, false)
 				}
+				countCacheHit(ctx, provider, version, false)
 				// Even though the package is installed, the requirements in the lockfile may still need to be updated
 				return nil, lock.AllHashes(), nil
 			}
@@ -624,6 +625,7 @@ func (i *Installer) ensureProviderVersionInDirectory(
 				if cb := evts.ProviderAlreadyInstalled; cb != nil {
 					cb(provider, version, isGlobalCache)
 				}
+				countCacheHit(ctx, provider, version, isGlobalCache)
 
 				// Even though the package is installed, the requirements in the lockfile may still need to be updated
 				return nil, lock.AllHashes(), nil
@@ -659,8 +661,15 @@ func (i *Installer) ensureProviderVersionInDirectory(
 		allowedHashes = []getproviders.Hash{}
 	}
 
+	installCtx := ctx
+	if cb := evts.FetchPackageProgress; cb != nil {
+		installCtx = getproviders.ContextWithDownloadProgress(ctx, func(downloaded, total int64) {
+			cb(provider, version, downloaded, total)
+		})
+	}
+
 	allowSkippingInstallWithoutHashes := i.globalCacheDirMayBreakDependencyLockFile && isGlobalCache
-	authResult, err := installTo.InstallPackage(ctx, meta, allowedHashes, allowSkippingInstallWithoutHashes)
+	authResult, err := installTo.InstallPackage(installCtx, meta, allowedHashes, allowSkippingInstallWithoutHashes)
 	if err != nil {
 		// TODO: Consider retrying for certain kinds of error that seem
 		// likely to be transient. For now, we just treat all errors equally.
@@ -710,7 +719,13 @@ func (i *Installer) ensureProviderVersionInDirectory(
 		// own set of packages and thus its own hashes.
 		priorHashes = append(priorHashes, preferredHashes...)
 	}
+	_, hashSpan := tracing.Tracer().Start(ctx, "Hash Provider Package", tracing.SpanAttributes(
+		traceattrs.FarseekProviderAddress(provider.String()),
+		traceattrs.FarseekProviderVersion(version.String()),
+	))
 	newHash, err := new.Hash()
+	tracing.SetSpanError(hashSpan, err)
+	hashSpan.End()
 	if err != nil {
 		err := fmt.Errorf("after installing %s, failed to compute a checksum for it: %w", provider, err)
 		if cb := evts.FetchPackageFailure; cb != nil {
@@ -776,6 +791,21 @@ func (i *Installer) ensureProviderVersionInDirectory(
 	return authResult, newHashes, nil
 }
 
+// countCacheHit adds a provider package that was already installed in the
+// target directory, or in the global cache directory if isGlobalCache is
+// true, to the provider cache metrics.
+func countCacheHit(ctx context.Context, provider addrs.Provider, version getproviders.Version, isGlobalCache bool) {
+	cache := "local"
+	if isGlobalCache {
+		cache = "global"
+	}
+	tracing.AddToCounter(ctx, "farseek.init.provider.cache_hits", "{hit}", "Provider packages found already installed.", 1,
+		traceattrs.FarseekProviderAddress(provider.String()),
+		traceattrs.FarseekProviderVersion(version.String()),
+		traceattrs.String("farseek.provider.cache", cache),
+	)
+}
+
 // checkUnspecifiedVersion Check the presence of version 0.0.0 and return an error with a tip
 func checkUnspecifiedVersion(acceptableVersions versions.Set) error {
 	if !acceptableVersions.Exactly(versions.Unspecified) {
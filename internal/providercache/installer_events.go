@@ -115,6 +115,14 @@ type InstallerEvents struct {
 	FetchPackageSuccess func(provider addrs.Provider, version getproviders.Version, localDir string, authResult *getproviders.PackageAuthenticationResult)
 	FetchPackageFailure func(provider addrs.Provider, version getproviders.Version, err error)
 
+	// FetchPackageProgress reports the progress of downloading a package
+	// from the network, with the number of bytes downloaded so far and the
+	// total size of the package, or -1 if the total size isn't known. It
+	// may be called any number of times between FetchPackageBegin and
+	// FetchPackageSuccess, and isn't called for packages that don't need
+	// to be downloaded.
+	FetchPackageProgress func(provider addrs.Provider, version getproviders.Version, downloaded, total int64)
+
 	// CacheDirLockContended is called if acquiring a lock on the specified
 	// cache directory takes more than a few seconds, suggesting that some
 	// other process is already holding a lock.
@@ -252,6 +260,13 @@ func (e *InstallerEvents) Sync() *InstallerEvents {
 				e.FetchPackageBegin(provider, version, location, inProviderCache)
 			}
 		},
+		FetchPackageProgress: func(provider addrs.Provider, version getproviders.Version, downloaded, total int64) {
+			lock.Lock()
+			defer lock.Unlock()
+			if e.FetchPackageProgress != nil {
+				e.FetchPackageProgress(provider, version, downloaded, total)
+			}
+		},
 		FetchPackageSuccess: func(provider addrs.Provider, version getproviders.Version, localDir string, authResult *getproviders.PackageAuthenticationResult) {
 			lock.Lock()
 			defer lock.Unlock()
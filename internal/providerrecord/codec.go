@@ -0,0 +1,433 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package providerrecord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"github.com/zclconf/go-cty/cty/msgpack"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/plugin6/convert"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	proto "github.com/rafagsiqueira/farseek/internal/tfplugin6"
+)
+
+// schemas are the blocks that the values of a request or response conform
+// to, which decide which of their attributes are sensitive.
+type schemas struct {
+	// block is the schema of the values of the request or response, such as
+	// the schema of the resource type for ReadResource, or nil if the values
+	// have no schema.
+	block *configschema.Block
+
+	// meta is the schema of the ProviderMeta values.
+	meta *configschema.Block
+}
+
+var (
+	ctyValueType    = reflect.TypeOf(cty.Value{})
+	ctyPathType     = reflect.TypeOf(cty.Path(nil))
+	diagnosticsType = reflect.TypeOf(tfdiags.Diagnostics(nil))
+	blockPtrType    = reflect.TypeOf((*configschema.Block)(nil))
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// encode returns the JSON form of a request or response of
+// [providers.Interface], with its sensitive values scrubbed.
+//
+// The requests and responses are structs of values that encoding/json
+// handles, apart from the cty values, diagnostics, paths, schemas, and
+// errors, which encode handles itself at any depth.
+func encode(v any, s schemas) (json.RawMessage, error) {
+	enc, err := encodeReflect(reflect.ValueOf(v), "", s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(enc)
+}
+
+func encodeReflect(rv reflect.Value, field string, s schemas) (any, error) {
+	switch rv.Type() {
+	case ctyValueType:
+		block := s.block
+		if field == "ProviderMeta" {
+			block = s.meta
+		}
+		return encodeValue(rv.Interface().(cty.Value), block)
+	case ctyPathType:
+		return encodePath(rv.Interface().(cty.Path)), nil
+	case diagnosticsType:
+		return encodeDiagnostics(rv.Interface().(tfdiags.Diagnostics)), nil
+	case blockPtrType:
+		block := rv.Interface().(*configschema.Block)
+		if block == nil {
+			return nil, nil
+		}
+		return json.RawMessage(protojson.Format(convert.ConfigSchemaToProto(block))), nil
+	case errorType:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return encodeError(rv.Interface().(error)), nil
+	}
+	if rv.Type().Implements(marshalerType) {
+		return rv.Interface(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		ret := make(map[string]any, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			v, err := encodeReflect(rv.Field(i), f.Name, s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			ret[f.Name] = v
+		}
+		return ret, nil
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return encodeReflect(rv.Elem(), field, s)
+	case reflect.Slice:
+		if rv.IsNil() || rv.Type().Elem().Kind() == reflect.Uint8 {
+			// encoding/json already encodes byte slices in base64.
+			return rv.Interface(), nil
+		}
+		ret := make([]any, rv.Len())
+		for i := range ret {
+			v, err := encodeReflect(rv.Index(i), field, s)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = v
+		}
+		return ret, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		ret := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			v, err := encodeReflect(iter.Value(), field, s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", iter.Key(), err)
+			}
+			ret[iter.Key().String()] = v
+		}
+		return ret, nil
+	default:
+		return rv.Interface(), nil
+	}
+}
+
+// decode sets the response that resp points to from the JSON form that
+// encode returned for it.
+func decode(raw json.RawMessage, resp any) error {
+	return decodeReflect(raw, reflect.ValueOf(resp).Elem())
+}
+
+func decodeReflect(raw json.RawMessage, rv reflect.Value) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	switch rv.Type() {
+	case ctyValueType:
+		v, err := decodeValue(raw)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	case ctyPathType:
+		p, err := decodePath(raw)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(p))
+		return nil
+	case diagnosticsType:
+		diags, err := decodeDiagnostics(raw)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(diags))
+		return nil
+	case blockPtrType:
+		var block proto.Schema_Block
+		if err := protojson.Unmarshal(raw, &block); err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(convert.ProtoToConfigSchema(&block)))
+		return nil
+	case errorType:
+		err, decodeErr := decodeError(raw)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		rv.Set(reflect.ValueOf(&err).Elem())
+		return nil
+	}
+	if rv.Type().Implements(marshalerType) {
+		return json.Unmarshal(raw, rv.Addr().Interface())
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return err
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if err := decodeReflect(fields[f.Name], rv.Field(i)); err != nil {
+				return fmt.Errorf("%s: %w", f.Name, err)
+			}
+		}
+		return nil
+	case reflect.Pointer:
+		elem := reflect.New(rv.Type().Elem())
+		if err := decodeReflect(raw, elem.Elem()); err != nil {
+			return err
+		}
+		rv.Set(elem)
+		return nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return json.Unmarshal(raw, rv.Addr().Interface())
+		}
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return err
+		}
+		ret := reflect.MakeSlice(rv.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := decodeReflect(elem, ret.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(ret)
+		return nil
+	case reflect.Map:
+		var elems map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return err
+		}
+		ret := reflect.MakeMapWithSize(rv.Type(), len(elems))
+		for k, elem := range elems {
+			v := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeReflect(elem, v); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			ret.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), v)
+		}
+		rv.Set(ret)
+		return nil
+	default:
+		return json.Unmarshal(raw, rv.Addr().Interface())
+	}
+}
+
+// jsonValue is the JSON form of a cty value. Values that are wholly known
+// are in JSON, for readability, and others in base64-encoded msgpack, which
+// can represent unknown values.
+type jsonValue struct {
+	Type    json.RawMessage `json:"type"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Msgpack []byte          `json:"msgpack,omitempty"`
+}
+
+func encodeValue(v cty.Value, block *configschema.Block) (any, error) {
+	if v == cty.NilVal {
+		return nil, nil
+	}
+	v, _ = v.UnmarkDeep()
+	v = scrub(v, block)
+
+	ty, err := ctyjson.MarshalType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	ret := jsonValue{Type: ty}
+	if v.IsWhollyKnown() {
+		ret.Value, err = ctyjson.Marshal(v, v.Type())
+	} else {
+		ret.Msgpack, err = msgpack.Marshal(v, v.Type())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func decodeValue(raw json.RawMessage) (cty.Value, error) {
+	var jv jsonValue
+	if err := json.Unmarshal(raw, &jv); err != nil {
+		return cty.NilVal, err
+	}
+	ty, err := ctyjson.UnmarshalType(jv.Type)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if jv.Msgpack != nil {
+		return msgpack.Unmarshal(jv.Msgpack, ty)
+	}
+	return ctyjson.Unmarshal(jv.Value, ty)
+}
+
+// jsonPathStep is the JSON form of a step of a cty path, which has exactly
+// one of its fields set.
+type jsonPathStep struct {
+	Attr *string    `json:"attr,omitempty"`
+	Key  *string    `json:"key,omitempty"`
+	Int  *big.Float `json:"int,omitempty"`
+}
+
+func encodePath(path cty.Path) []jsonPathStep {
+	if path == nil {
+		return nil
+	}
+	ret := make([]jsonPathStep, 0, len(path))
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			ret = append(ret, jsonPathStep{Attr: &step.Name})
+		case cty.IndexStep:
+			switch {
+			case step.Key.Type() == cty.String && step.Key.IsKnown():
+				key := step.Key.AsString()
+				ret = append(ret, jsonPathStep{Key: &key})
+			case step.Key.Type() == cty.Number && step.Key.IsKnown():
+				ret = append(ret, jsonPathStep{Int: step.Key.AsBigFloat()})
+			}
+		}
+	}
+	return ret
+}
+
+func decodePath(raw json.RawMessage) (cty.Path, error) {
+	var steps []jsonPathStep
+	if err := json.Unmarshal(raw, &steps); err != nil {
+		return nil, err
+	}
+	ret := make(cty.Path, 0, len(steps))
+	for _, step := range steps {
+		switch {
+		case step.Attr != nil:
+			ret = ret.GetAttr(*step.Attr)
+		case step.Key != nil:
+			ret = ret.Index(cty.StringVal(*step.Key))
+		case step.Int != nil:
+			ret = ret.Index(cty.NumberVal(step.Int))
+		}
+	}
+	return ret, nil
+}
+
+// jsonDiagnostic is the JSON form of a diagnostic from a provider. Like the
+// diagnostics that come over the plugin protocol, it has no source location,
+// but can have the path of the attribute it's about.
+type jsonDiagnostic struct {
+	Severity  string         `json:"severity"`
+	Summary   string         `json:"summary"`
+	Detail    string         `json:"detail,omitempty"`
+	Attribute []jsonPathStep `json:"attribute,omitempty"`
+}
+
+func encodeDiagnostics(diags tfdiags.Diagnostics) []jsonDiagnostic {
+	if len(diags) == 0 {
+		return nil
+	}
+	ret := make([]jsonDiagnostic, 0, len(diags))
+	for _, diag := range diags {
+		desc := diag.Description()
+		jd := jsonDiagnostic{
+			Severity:  "error",
+			Summary:   desc.Summary,
+			Detail:    desc.Detail,
+			Attribute: encodePath(tfdiags.GetAttribute(diag)),
+		}
+		if diag.Severity() == tfdiags.Warning {
+			jd.Severity = "warning"
+		}
+		ret = append(ret, jd)
+	}
+	return ret
+}
+
+func decodeDiagnostics(raw json.RawMessage) (tfdiags.Diagnostics, error) {
+	var jds []jsonDiagnostic
+	if err := json.Unmarshal(raw, &jds); err != nil {
+		return nil, err
+	}
+	var diags tfdiags.Diagnostics
+	for _, jd := range jds {
+		severity := tfdiags.Error
+		if jd.Severity == "warning" {
+			severity = tfdiags.Warning
+		}
+		if jd.Attribute != nil {
+			raw, err := json.Marshal(jd.Attribute)
+			if err != nil {
+				return nil, err
+			}
+			path, err := decodePath(raw)
+			if err != nil {
+				return nil, err
+			}
+			diags = diags.Append(tfdiags.AttributeValue(severity, jd.Summary, jd.Detail, path))
+			continue
+		}
+		diags = diags.Append(tfdiags.WholeContainingBody(severity, jd.Summary, jd.Detail))
+	}
+	return diags, nil
+}
+
+// jsonError is the JSON form of the error of a function call, with the
+// index of the argument it's about, if any.
+type jsonError struct {
+	Text     string `json:"text"`
+	Argument *int   `json:"argument,omitempty"`
+}
+
+func encodeError(err error) jsonError {
+	ret := jsonError{Text: err.Error()}
+	var argErr *providers.CallFunctionArgumentError
+	if errors.As(err, &argErr) {
+		ret.Argument = &argErr.FunctionArgument
+	}
+	return ret
+}
+
+func decodeError(raw json.RawMessage) (error, error) {
+	var je jsonError
+	if err := json.Unmarshal(raw, &je); err != nil {
+		return nil, err
+	}
+	if je.Argument != nil {
+		return &providers.CallFunctionArgumentError{Text: je.Text, FunctionArgument: *je.Argument}, nil
+	}
+	return errors.New(je.Text), nil
+}
@@ -0,0 +1,418 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package providerrecord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// schemaKind says which of the schemas of a provider the values of a
+// request and its response conform to.
+type schemaKind int
+
+const (
+	noSchema schemaKind = iota
+	providerSchema
+	resourceSchema
+	dataSourceSchema
+	ephemeralSchema
+)
+
+func (k schemaKind) schemas(resp *providers.GetProviderSchemaResponse, typeName string) schemas {
+	ret := schemas{meta: resp.ProviderMeta.Block}
+	switch k {
+	case providerSchema:
+		ret.block = resp.Provider.Block
+	case resourceSchema:
+		ret.block = resp.ResourceTypes[typeName].Block
+	case dataSourceSchema:
+		ret.block = resp.DataSources[typeName].Block
+	case ephemeralSchema:
+		ret.block = resp.EphemeralResources[typeName].Block
+	}
+	return ret
+}
+
+// noRequest is the request of the calls that have none, such as
+// GetProviderSchema.
+type noRequest struct{}
+
+// recordingProvider is a providers.Interface that records the calls to the
+// provider it wraps.
+type recordingProvider struct {
+	provider  providers.Interface
+	recording *recording
+}
+
+var _ providers.Interface = (*recordingProvider)(nil)
+
+// schema returns the schema of the provider, which decides which values to
+// scrub, or nil if the provider can't return it.
+func (p *recordingProvider) schema(ctx context.Context) *providers.GetProviderSchemaResponse {
+	p.recording.mu.Lock()
+	schema := p.recording.schema
+	p.recording.mu.Unlock()
+	if schema != nil {
+		return schema
+	}
+
+	// We record the schema as if Farseek had asked for it, so that the
+	// replay can scrub the requests the same way even if Farseek only asked
+	// for it in an earlier recording.
+	resp := p.GetProviderSchema(ctx)
+	if resp.Diagnostics.HasErrors() {
+		return nil
+	}
+	return &resp
+}
+
+func (p *recordingProvider) record(ctx context.Context, method string, kind schemaKind, typeName string, req, resp any) {
+	var s schemas
+	if kind != noSchema {
+		schema := p.schema(ctx)
+		if schema == nil {
+			// Without the schema we can't tell which values to scrub, so
+			// we'd rather leave the call out of the recording.
+			log.Printf("[WARN] Not recording %s call to %s, because its schema is unavailable", method, p.recording.file.Provider)
+			return
+		}
+		s = kind.schemas(schema, typeName)
+	}
+
+	reqJSON, err := encode(req, s)
+	if err != nil {
+		log.Printf("[WARN] Failed to record %s call to %s: %s", method, p.recording.file.Provider, err)
+		return
+	}
+	respJSON, err := encode(resp, s)
+	if err != nil {
+		log.Printf("[WARN] Failed to record %s call to %s: %s", method, p.recording.file.Provider, err)
+		return
+	}
+	p.recording.add(interaction{Method: method, Request: reqJSON, Response: respJSON})
+}
+
+func (p *recordingProvider) GetProviderSchema(ctx context.Context) providers.GetProviderSchemaResponse {
+	resp := p.provider.GetProviderSchema(ctx)
+	if !resp.Diagnostics.HasErrors() {
+		p.recording.mu.Lock()
+		p.recording.schema = &resp
+		p.recording.mu.Unlock()
+	}
+	p.record(ctx, "GetProviderSchema", noSchema, "", noRequest{}, resp)
+	return resp
+}
+
+func (p *recordingProvider) ValidateProviderConfig(ctx context.Context, req providers.ValidateProviderConfigRequest) providers.ValidateProviderConfigResponse {
+	resp := p.provider.ValidateProviderConfig(ctx, req)
+	p.record(ctx, "ValidateProviderConfig", providerSchema, "", req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ValidateResourceConfig(ctx context.Context, req providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	resp := p.provider.ValidateResourceConfig(ctx, req)
+	p.record(ctx, "ValidateResourceConfig", resourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ValidateDataResourceConfig(ctx context.Context, req providers.ValidateDataResourceConfigRequest) providers.ValidateDataResourceConfigResponse {
+	resp := p.provider.ValidateDataResourceConfig(ctx, req)
+	p.record(ctx, "ValidateDataResourceConfig", dataSourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ValidateEphemeralConfig(ctx context.Context, req providers.ValidateEphemeralConfigRequest) providers.ValidateEphemeralConfigResponse {
+	resp := p.provider.ValidateEphemeralConfig(ctx, req)
+	p.record(ctx, "ValidateEphemeralConfig", ephemeralSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) MoveResourceState(ctx context.Context, req providers.MoveResourceStateRequest) providers.MoveResourceStateResponse {
+	resp := p.provider.MoveResourceState(ctx, req)
+	p.record(ctx, "MoveResourceState", resourceSchema, req.TargetTypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) CallFunction(ctx context.Context, req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	resp := p.provider.CallFunction(ctx, req)
+	p.record(ctx, "CallFunction", noSchema, "", req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ConfigureProvider(ctx context.Context, req providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+	resp := p.provider.ConfigureProvider(ctx, req)
+	p.record(ctx, "ConfigureProvider", providerSchema, "", req, resp)
+	return resp
+}
+
+func (p *recordingProvider) Close(ctx context.Context) error {
+	err := p.provider.Close(ctx)
+	if saveErr := p.recording.save(); saveErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to save the recording of %s: %w", p.recording.file.Provider, saveErr))
+	}
+	return err
+}
+
+func (p *recordingProvider) Stop(ctx context.Context) error {
+	return p.provider.Stop(ctx)
+}
+
+func (p *recordingProvider) UpgradeResourceState(ctx context.Context, req providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	resp := p.provider.UpgradeResourceState(ctx, req)
+	p.record(ctx, "UpgradeResourceState", resourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ReadResource(ctx context.Context, req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	resp := p.provider.ReadResource(ctx, req)
+	p.record(ctx, "ReadResource", resourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) PlanResourceChange(ctx context.Context, req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	resp := p.provider.PlanResourceChange(ctx, req)
+	p.record(ctx, "PlanResourceChange", resourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ApplyResourceChange(ctx context.Context, req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	resp := p.provider.ApplyResourceChange(ctx, req)
+	p.record(ctx, "ApplyResourceChange", resourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ImportResourceState(ctx context.Context, req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+	resp := p.provider.ImportResourceState(ctx, req)
+	p.record(ctx, "ImportResourceState", resourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) ReadDataSource(ctx context.Context, req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	resp := p.provider.ReadDataSource(ctx, req)
+	p.record(ctx, "ReadDataSource", dataSourceSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) OpenEphemeralResource(ctx context.Context, req providers.OpenEphemeralResourceRequest) providers.OpenEphemeralResourceResponse {
+	resp := p.provider.OpenEphemeralResource(ctx, req)
+	p.record(ctx, "OpenEphemeralResource", ephemeralSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) RenewEphemeralResource(ctx context.Context, req providers.RenewEphemeralResourceRequest) providers.RenewEphemeralResourceResponse {
+	resp := p.provider.RenewEphemeralResource(ctx, req)
+	p.record(ctx, "RenewEphemeralResource", ephemeralSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) CloseEphemeralResource(ctx context.Context, req providers.CloseEphemeralResourceRequest) providers.CloseEphemeralResourceResponse {
+	resp := p.provider.CloseEphemeralResource(ctx, req)
+	p.record(ctx, "CloseEphemeralResource", ephemeralSchema, req.TypeName, req, resp)
+	return resp
+}
+
+func (p *recordingProvider) GetFunctions(ctx context.Context) providers.GetFunctionsResponse {
+	resp := p.provider.GetFunctions(ctx)
+	p.record(ctx, "GetFunctions", noSchema, "", noRequest{}, resp)
+	return resp
+}
+
+// replayProvider is a providers.Interface that replays the recorded calls to
+// a provider.
+type replayProvider struct {
+	replay *replay
+}
+
+var _ providers.Interface = (*replayProvider)(nil)
+
+// schema returns the recorded schema of the provider, which decides which
+// values to scrub before matching requests.
+func (p *replayProvider) schema(ctx context.Context) (*providers.GetProviderSchemaResponse, tfdiags.Diagnostics) {
+	p.replay.mu.Lock()
+	schema := p.replay.schema
+	p.replay.mu.Unlock()
+	if schema != nil {
+		return schema, nil
+	}
+
+	var resp providers.GetProviderSchemaResponse
+	diags := p.do(ctx, "GetProviderSchema", noSchema, "", noRequest{}, &resp)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	p.replay.mu.Lock()
+	p.replay.schema = &resp
+	p.replay.mu.Unlock()
+	return &resp, nil
+}
+
+// do sets the response that resp points to from the recorded response to
+// the given request.
+func (p *replayProvider) do(ctx context.Context, method string, kind schemaKind, typeName string, req, resp any) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var s schemas
+	if kind != noSchema {
+		schema, moreDiags := p.schema(ctx)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			return diags
+		}
+		s = kind.schemas(schema, typeName)
+	}
+
+	reqJSON, err := encode(req, s)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to replay provider call",
+			fmt.Sprintf("Farseek couldn't encode the %s request to %s to find its recorded response: %s.", method, p.replay.addr, err),
+		))
+		return diags
+	}
+	raw, ok := p.replay.next(method + "\n" + string(reqJSON))
+	if !ok {
+		forType := ""
+		if typeName != "" {
+			forType = " for " + typeName
+		}
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No recorded provider response",
+			fmt.Sprintf("The recording %s has no response to this %s request%s. Check that the configuration and its input variables are the same as when the calls were recorded, or record them again with FARSEEK_RECORD.", p.replay.path, method, forType),
+		))
+		return diags
+	}
+	if err := decode(raw, resp); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid provider recording",
+			fmt.Sprintf("The recorded response to a %s request in %s is invalid: %s.", method, p.replay.path, err),
+		))
+	}
+	return diags
+}
+
+func (p *replayProvider) GetProviderSchema(ctx context.Context) (resp providers.GetProviderSchemaResponse) {
+	diags := p.do(ctx, "GetProviderSchema", noSchema, "", noRequest{}, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ValidateProviderConfig(ctx context.Context, req providers.ValidateProviderConfigRequest) (resp providers.ValidateProviderConfigResponse) {
+	diags := p.do(ctx, "ValidateProviderConfig", providerSchema, "", req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ValidateResourceConfig(ctx context.Context, req providers.ValidateResourceConfigRequest) (resp providers.ValidateResourceConfigResponse) {
+	diags := p.do(ctx, "ValidateResourceConfig", resourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ValidateDataResourceConfig(ctx context.Context, req providers.ValidateDataResourceConfigRequest) (resp providers.ValidateDataResourceConfigResponse) {
+	diags := p.do(ctx, "ValidateDataResourceConfig", dataSourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ValidateEphemeralConfig(ctx context.Context, req providers.ValidateEphemeralConfigRequest) (resp providers.ValidateEphemeralConfigResponse) {
+	diags := p.do(ctx, "ValidateEphemeralConfig", ephemeralSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) MoveResourceState(ctx context.Context, req providers.MoveResourceStateRequest) (resp providers.MoveResourceStateResponse) {
+	diags := p.do(ctx, "MoveResourceState", resourceSchema, req.TargetTypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) CallFunction(ctx context.Context, req providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+	if diags := p.do(ctx, "CallFunction", noSchema, "", req, &resp); diags.HasErrors() {
+		resp.Error = diags.Err()
+	}
+	return resp
+}
+
+func (p *replayProvider) ConfigureProvider(ctx context.Context, req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+	diags := p.do(ctx, "ConfigureProvider", providerSchema, "", req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) Close(context.Context) error {
+	return nil
+}
+
+func (p *replayProvider) Stop(context.Context) error {
+	return nil
+}
+
+func (p *replayProvider) UpgradeResourceState(ctx context.Context, req providers.UpgradeResourceStateRequest) (resp providers.UpgradeResourceStateResponse) {
+	diags := p.do(ctx, "UpgradeResourceState", resourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ReadResource(ctx context.Context, req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+	diags := p.do(ctx, "ReadResource", resourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) PlanResourceChange(ctx context.Context, req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+	diags := p.do(ctx, "PlanResourceChange", resourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ApplyResourceChange(ctx context.Context, req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+	diags := p.do(ctx, "ApplyResourceChange", resourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ImportResourceState(ctx context.Context, req providers.ImportResourceStateRequest) (resp providers.ImportResourceStateResponse) {
+	diags := p.do(ctx, "ImportResourceState", resourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) ReadDataSource(ctx context.Context, req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+	diags := p.do(ctx, "ReadDataSource", dataSourceSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) OpenEphemeralResource(ctx context.Context, req providers.OpenEphemeralResourceRequest) (resp providers.OpenEphemeralResourceResponse) {
+	diags := p.do(ctx, "OpenEphemeralResource", ephemeralSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) RenewEphemeralResource(ctx context.Context, req providers.RenewEphemeralResourceRequest) (resp providers.RenewEphemeralResourceResponse) {
+	diags := p.do(ctx, "RenewEphemeralResource", ephemeralSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) CloseEphemeralResource(ctx context.Context, req providers.CloseEphemeralResourceRequest) (resp providers.CloseEphemeralResourceResponse) {
+	diags := p.do(ctx, "CloseEphemeralResource", ephemeralSchema, req.TypeName, req, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
+
+func (p *replayProvider) GetFunctions(ctx context.Context) (resp providers.GetFunctionsResponse) {
+	diags := p.do(ctx, "GetFunctions", noSchema, "", noRequest{}, &resp)
+	resp.Diagnostics = resp.Diagnostics.Append(diags)
+	return resp
+}
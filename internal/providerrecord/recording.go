@@ -0,0 +1,272 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package providerrecord records the calls that Farseek makes to providers,
+// and replays them in place of the providers, so that integration tests of
+// configurations can run quickly and offline.
+//
+// Each provider has a recording file in the recording directory, at
+// HOSTNAME/NAMESPACE/TYPE.json, with the calls to all of its instances. A
+// replayed call gets the response of the recorded call with the same method
+// and request. The values of sensitive attributes are scrubbed from both
+// before they're recorded, and before they're matched, using the schema of
+// the provider.
+package providerrecord
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/replacefile"
+)
+
+const formatVersion = "1.0"
+
+// file is the JSON form of the recording of a provider.
+type file struct {
+	FormatVersion string        `json:"format_version"`
+	Provider      string        `json:"provider"`
+	Interactions  []interaction `json:"interactions"`
+}
+
+// interaction is a recorded call to a provider.
+type interaction struct {
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// key identifies the interactions that a replayed call can get the response
+// of.
+func (i interaction) key() (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, i.Request); err != nil {
+		return "", err
+	}
+	return i.Method + "\n" + buf.String(), nil
+}
+
+// Path returns the path of the recording of the given provider in the given
+// recording directory.
+func Path(dir string, addr addrs.Provider) string {
+	return filepath.Join(dir, addr.Hostname.String(), addr.Namespace, addr.Type+".json")
+}
+
+func readFile(path string, addr addrs.Provider) (*file, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(src, &f); err != nil {
+		return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+	}
+	if f.FormatVersion != formatVersion {
+		return nil, fmt.Errorf("recording %s has unsupported format version %q", path, f.FormatVersion)
+	}
+	if f.Provider != addr.String() {
+		return nil, fmt.Errorf("recording %s is for %s, not %s", path, f.Provider, addr)
+	}
+	return &f, nil
+}
+
+// Recorder records the calls to providers in a recording directory.
+//
+// The recording of each provider continues any recording already in the
+// directory, so that the calls of several commands, such as a plan and the
+// apply that follows it, can be recorded one after the other. To start a
+// new recording, use an empty directory.
+type Recorder struct {
+	dir string
+
+	mu         sync.Mutex
+	recordings map[addrs.Provider]*recording
+}
+
+// NewRecorder returns a recorder that records in the given directory.
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{
+		dir:        dir,
+		recordings: make(map[addrs.Provider]*recording),
+	}
+}
+
+// Wrap returns a factory for providers that record the calls to the
+// providers of the given factory. The calls are saved when each provider is
+// closed.
+func (r *Recorder) Wrap(addr addrs.Provider, factory providers.Factory) providers.Factory {
+	return func() (providers.Interface, error) {
+		rec, err := r.recording(addr)
+		if err != nil {
+			return nil, err
+		}
+		p, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		return &recordingProvider{provider: p, recording: rec}, nil
+	}
+}
+
+func (r *Recorder) recording(addr addrs.Provider) (*recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec, ok := r.recordings[addr]; ok {
+		return rec, nil
+	}
+	rec := &recording{
+		path: Path(r.dir, addr),
+		file: file{
+			FormatVersion: formatVersion,
+			Provider:      addr.String(),
+		},
+	}
+	f, err := readFile(rec.path, addr)
+	switch {
+	case err == nil:
+		rec.file = *f
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+	r.recordings[addr] = rec
+	return rec, nil
+}
+
+// recording is the recording of a provider, shared by all of its instances.
+type recording struct {
+	path string
+
+	mu     sync.Mutex
+	file   file
+	dirty  bool
+	schema *providers.GetProviderSchemaResponse
+}
+
+func (r *recording) add(i interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.file.Interactions = append(r.file.Interactions, i)
+	r.dirty = true
+}
+
+// save writes the recording, if it has changed since it was last written.
+func (r *recording) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.dirty {
+		return nil
+	}
+	src, err := json.MarshalIndent(r.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	if err := replacefile.AtomicWriteFile(r.path, append(src, '\n'), 0o644); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+// Replayer replays the calls to providers from a recording directory.
+type Replayer struct {
+	dir string
+
+	mu      sync.Mutex
+	replays map[addrs.Provider]*replay
+}
+
+// NewReplayer returns a replayer that replays from the given directory.
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{
+		dir:     dir,
+		replays: make(map[addrs.Provider]*replay),
+	}
+}
+
+// Factory returns a factory for providers that replay the recorded calls to
+// the given provider, without starting it.
+func (r *Replayer) Factory(addr addrs.Provider) providers.Factory {
+	return func() (providers.Interface, error) {
+		rep, err := r.replay(addr)
+		if err != nil {
+			return nil, err
+		}
+		return &replayProvider{replay: rep}, nil
+	}
+}
+
+func (r *Replayer) replay(addr addrs.Provider) (*replay, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rep, ok := r.replays[addr]; ok {
+		return rep, nil
+	}
+	path := Path(r.dir, addr)
+	f, err := readFile(path, addr)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("there is no recording of %s in %s", addr, r.dir)
+	} else if err != nil {
+		return nil, err
+	}
+	rep := &replay{
+		addr:      addr,
+		path:      path,
+		responses: make(map[string][]json.RawMessage),
+		replayed:  make(map[string]int),
+	}
+	for _, i := range f.Interactions {
+		key, err := i.key()
+		if err != nil {
+			return nil, fmt.Errorf("invalid recording %s: %w", path, err)
+		}
+		rep.responses[key] = append(rep.responses[key], i.Response)
+	}
+	r.replays[addr] = rep
+	return rep, nil
+}
+
+// replay is the state of replaying the recording of a provider, shared by
+// all of its instances.
+type replay struct {
+	addr addrs.Provider
+	path string
+
+	mu        sync.Mutex
+	responses map[string][]json.RawMessage
+	replayed  map[string]int
+	schema    *providers.GetProviderSchemaResponse
+}
+
+// next returns the response to the call with the given key. Calls with the
+// same key get the recorded responses in the order they were recorded, and
+// then the last one again, because Farseek makes some calls, such as for
+// the schema, as many times as it needs.
+func (r *replay) next(key string) (json.RawMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		return nil, false
+	}
+	i := r.replayed[key]
+	if i >= len(responses) {
+		return responses[len(responses)-1], true
+	}
+	r.replayed[key]++
+	return responses[i], true
+}
@@ -0,0 +1,266 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package providerrecord
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func testProvider() *farseek.MockProvider {
+	return &farseek.MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			Provider: providers.Schema{
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"region": {Type: cty.String, Optional: true},
+						"token":  {Type: cty.String, Optional: true, Sensitive: true},
+					},
+				},
+			},
+			ResourceTypes: map[string]providers.Schema{
+				"test_instance": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"id":       {Type: cty.String, Computed: true},
+							"size":     {Type: cty.Number, Optional: true},
+							"password": {Type: cty.String, Computed: true, Sensitive: true},
+						},
+					},
+				},
+			},
+		},
+		ReadResourceFn: func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+			return providers.ReadResourceResponse{
+				NewState: cty.ObjectVal(map[string]cty.Value{
+					"id":       cty.StringVal("i-abc123"),
+					"size":     cty.NumberIntVal(2),
+					"password": cty.StringVal("hunter2"),
+				}),
+				Private: []byte("private"),
+				Diagnostics: tfdiags.Diagnostics{
+					tfdiags.AttributeValue(tfdiags.Warning, "Size is deprecated", "Use type instead.", cty.GetAttrPath("size")),
+				},
+			}
+		},
+		PlanResourceChangeFn: func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+			return providers.PlanResourceChangeResponse{
+				PlannedState: cty.ObjectVal(map[string]cty.Value{
+					"id":       cty.UnknownVal(cty.String),
+					"size":     req.Config.GetAttr("size"),
+					"password": cty.UnknownVal(cty.String),
+				}),
+				RequiresReplace: []cty.Path{cty.GetAttrPath("size")},
+			}
+		},
+		CallFunctionResponse: &providers.CallFunctionResponse{
+			Error: &providers.CallFunctionArgumentError{Text: "invalid argument", FunctionArgument: 1},
+		},
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	addr := addrs.NewDefaultProvider("test")
+
+	configure := providers.ConfigureProviderRequest{
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"region": cty.StringVal("eu-west-1"),
+			"token":  cty.StringVal("s3cr3t"),
+		}),
+	}
+	read := providers.ReadResourceRequest{
+		TypeName: "test_instance",
+		PriorState: cty.ObjectVal(map[string]cty.Value{
+			"id":       cty.StringVal("i-abc123"),
+			"size":     cty.NumberIntVal(1),
+			"password": cty.StringVal("hunter2"),
+		}),
+	}
+	plan := providers.PlanResourceChangeRequest{
+		TypeName:   "test_instance",
+		PriorState: cty.NullVal(read.PriorState.Type()),
+		ProposedNewState: cty.ObjectVal(map[string]cty.Value{
+			"id":       cty.UnknownVal(cty.String),
+			"size":     cty.NumberIntVal(3),
+			"password": cty.UnknownVal(cty.String),
+		}),
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"id":       cty.NullVal(cty.String),
+			"size":     cty.NumberIntVal(3),
+			"password": cty.NullVal(cty.String),
+		}),
+	}
+	call := providers.CallFunctionRequest{
+		Name:      "parse",
+		Arguments: []cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)},
+	}
+
+	type responses struct {
+		Schema    providers.GetProviderSchemaResponse
+		Configure providers.ConfigureProviderResponse
+		Read      providers.ReadResourceResponse
+		Plan      providers.PlanResourceChangeResponse
+		Call      providers.CallFunctionResponse
+	}
+	calls := func(p providers.Interface) responses {
+		return responses{
+			Schema:    p.GetProviderSchema(ctx),
+			Configure: p.ConfigureProvider(ctx, configure),
+			Read:      p.ReadResource(ctx, read),
+			Plan:      p.PlanResourceChange(ctx, plan),
+			Call:      p.CallFunction(ctx, call),
+		}
+	}
+
+	recorder := NewRecorder(dir)
+	recorded, err := recorder.Wrap(addr, func() (providers.Interface, error) {
+		return testProvider(), nil
+	})()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := calls(recorded)
+	if err := recorded.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := os.ReadFile(Path(dir, addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t", "hunter2"} {
+		if strings.Contains(string(src), secret) {
+			t.Errorf("recording contains sensitive value %q:\n%s", secret, src)
+		}
+	}
+
+	replayed, err := NewReplayer(dir).Factory(addr)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := calls(replayed)
+
+	// The replayed responses have the sensitive values scrubbed, and like
+	// the diagnostics that come over the plugin protocol, the replayed
+	// diagnostics only keep their description and attribute.
+	want.Read.NewState = cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("i-abc123"),
+		"size":     cty.NumberIntVal(2),
+		"password": cty.StringVal(Scrubbed),
+	})
+	if diff := cmp.Diff(want.Read.Diagnostics.ForRPC(), got.Read.Diagnostics.ForRPC()); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+	if got, want := tfdiags.GetAttribute(got.Read.Diagnostics[0]), cty.GetAttrPath("size"); !got.Equals(want) {
+		t.Errorf("wrong diagnostic attribute %#v", got)
+	}
+	want.Read.Diagnostics, got.Read.Diagnostics = nil, nil
+
+	opts := []cmp.Option{
+		cmp.Comparer(func(a, b cty.Value) bool { return a.RawEquals(b) }),
+		cmp.Comparer(func(a, b cty.Type) bool { return a.Equals(b) }),
+		cmp.Comparer(func(a, b cty.Path) bool { return a.Equals(b) }),
+		cmp.Comparer(func(a, b error) bool { return a.Error() == b.Error() }),
+		cmpopts.EquateEmpty(),
+	}
+	if diff := cmp.Diff(want, got, opts...); diff != "" {
+		t.Errorf("wrong replayed responses\n%s", diff)
+	}
+	var argErr *providers.CallFunctionArgumentError
+	if !strings.Contains(got.Call.Error.Error(), "invalid argument") || !errors.As(got.Call.Error, &argErr) || argErr.FunctionArgument != 1 {
+		t.Errorf("wrong function error %#v", got.Call.Error)
+	}
+}
+
+func TestRecord_continues(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	addr := addrs.NewDefaultProvider("test")
+
+	// Each recorder continues the recording of the one before, as when
+	// recording a plan and then its apply.
+	for _, region := range []string{"eu-west-1", "us-east-1"} {
+		p, err := NewRecorder(dir).Wrap(addr, func() (providers.Interface, error) {
+			return testProvider(), nil
+		})()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.ConfigureProvider(ctx, providers.ConfigureProviderRequest{
+			Config: cty.ObjectVal(map[string]cty.Value{
+				"region": cty.StringVal(region),
+				"token":  cty.NullVal(cty.String),
+			}),
+		})
+		if err := p.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := readFile(Path(dir, addr), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var methods []string
+	for _, i := range f.Interactions {
+		methods = append(methods, i.Method)
+	}
+	// Each recorder also records the schema it fetches to scrub the
+	// configuration.
+	want := []string{"GetProviderSchema", "ConfigureProvider", "GetProviderSchema", "ConfigureProvider"}
+	if diff := cmp.Diff(want, methods); diff != "" {
+		t.Errorf("wrong interactions\n%s", diff)
+	}
+}
+
+func TestReplay_missing(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	addr := addrs.NewDefaultProvider("test")
+
+	if _, err := NewReplayer(dir).Factory(addr)(); err == nil || !strings.Contains(err.Error(), "there is no recording of") {
+		t.Fatalf("wrong error for a missing recording: %v", err)
+	}
+
+	p, err := NewRecorder(dir).Wrap(addr, func() (providers.Interface, error) {
+		return testProvider(), nil
+	})()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.GetProviderSchema(ctx)
+	if err := p.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := NewReplayer(dir).Factory(addr)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := replayed.ReadDataSource(ctx, providers.ReadDataSourceRequest{
+		TypeName: "test_data",
+		Config:   cty.EmptyObjectVal,
+	})
+	if !resp.Diagnostics.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := resp.Diagnostics.Err().Error(), "No recorded provider response"; !strings.Contains(got, want) {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+}
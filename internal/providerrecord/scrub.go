@@ -0,0 +1,86 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package providerrecord
+
+import (
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	"github.com/rafagsiqueira/farseek/internal/lang/marks"
+)
+
+// Scrubbed is the string that replaces the known values of sensitive string
+// attributes in recordings.
+const Scrubbed = "(scrubbed)"
+
+// scrub replaces the values of the attributes that the given schema marks as
+// sensitive with placeholders of the same type, so that recordings don't
+// keep secrets such as credentials or generated passwords.
+//
+// Null and unknown values are kept as they are, because they don't reveal
+// anything and the providers can depend on the difference.
+func scrub(v cty.Value, block *configschema.Block) cty.Value {
+	if block == nil || v.IsNull() || !v.IsKnown() || !block.ContainsSensitive() {
+		return v
+	}
+
+	var sensitive []cty.Path
+	for _, pvm := range block.ValueMarks(v, nil) {
+		if _, ok := pvm.Marks[marks.Sensitive]; ok {
+			sensitive = append(sensitive, pvm.Path)
+		}
+	}
+	ret, err := cty.Transform(v, func(path cty.Path, v cty.Value) (cty.Value, error) {
+		for _, p := range sensitive {
+			if p.Equals(path) {
+				return placeholder(v), nil
+			}
+		}
+		return v, nil
+	})
+	if err != nil {
+		// Our transform never fails.
+		panic(err)
+	}
+	return ret
+}
+
+// placeholder returns a value of the same type as v to record in its place.
+func placeholder(v cty.Value) cty.Value {
+	if v.IsNull() || !v.IsKnown() {
+		return v
+	}
+	return placeholderOfType(v.Type())
+}
+
+func placeholderOfType(ty cty.Type) cty.Value {
+	switch {
+	case ty == cty.String:
+		return cty.StringVal(Scrubbed)
+	case ty == cty.Number:
+		return cty.Zero
+	case ty == cty.Bool:
+		return cty.False
+	case ty.IsListType():
+		return cty.ListValEmpty(ty.ElementType())
+	case ty.IsSetType():
+		return cty.SetValEmpty(ty.ElementType())
+	case ty.IsMapType():
+		return cty.MapValEmpty(ty.ElementType())
+	case ty.IsObjectType():
+		attrs := make(map[string]cty.Value, len(ty.AttributeTypes()))
+		for name, aty := range ty.AttributeTypes() {
+			attrs[name] = placeholderOfType(aty)
+		}
+		return cty.ObjectVal(attrs)
+	case ty.IsTupleType():
+		elems := make([]cty.Value, len(ty.TupleElementTypes()))
+		for i, ety := range ty.TupleElementTypes() {
+			elems[i] = placeholderOfType(ety)
+		}
+		return cty.TupleVal(elems)
+	default:
+		return cty.NullVal(ty)
+	}
+}
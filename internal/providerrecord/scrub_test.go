@@ -0,0 +1,66 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package providerrecord
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+)
+
+func TestScrub(t *testing.T) {
+	block := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"name":  {Type: cty.String, Optional: true},
+			"port":  {Type: cty.Number, Optional: true, Sensitive: true},
+			"tags":  {Type: cty.Map(cty.String), Optional: true, Sensitive: true},
+			"token": {Type: cty.String, Optional: true, Sensitive: true},
+			"key":   {Type: cty.String, Computed: true, Sensitive: true},
+		},
+		BlockTypes: map[string]*configschema.NestedBlock{
+			"login": {
+				Nesting: configschema.NestingList,
+				Block: configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"user":     {Type: cty.String, Optional: true},
+						"password": {Type: cty.String, Optional: true, Sensitive: true},
+					},
+				},
+			},
+		},
+	}
+
+	got := scrub(cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("web"),
+		"port":  cty.NumberIntVal(8080),
+		"tags":  cty.MapVal(map[string]cty.Value{"env": cty.StringVal("prod")}),
+		"token": cty.NullVal(cty.String),
+		"key":   cty.UnknownVal(cty.String),
+		"login": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"user":     cty.StringVal("admin"),
+				"password": cty.StringVal("hunter2"),
+			}),
+		}),
+	}), block)
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("web"),
+		"port":  cty.Zero,
+		"tags":  cty.MapValEmpty(cty.String),
+		"token": cty.NullVal(cty.String),
+		"key":   cty.UnknownVal(cty.String),
+		"login": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"user":     cty.StringVal("admin"),
+				"password": cty.StringVal(Scrubbed),
+			}),
+		}),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
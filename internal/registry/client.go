@@ -9,6 +9,8 @@ package registry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -35,6 +37,8 @@ const (
 	xTerraformGet     = "X-Terraform-Get"
 	xTerraformVersion = "X-Terraform-Version"
 	modulesServiceID  = "modules.v1"
+
+	xFarseekChecksumSHA256 = "X-Farseek-Checksum-SHA256"
 )
 
 var (
@@ -275,3 +279,63 @@ func modulePackageEndpointURL(baseURL *url.URL, packageAddr regaddr.ModulePackag
 	}
 	return baseURL.ResolveReference(relURL)
 }
+
+// PublishModulePackage uploads a zip archive of a module package to the
+// registry as the given version of the given package.
+//
+// Publishing isn't part of the "modules.v1" protocol, so this uses a
+// convention that private registries can implement alongside it: a PUT
+// request to the "upload" endpoint of the version, with the archive as the
+// body and its SHA-256 checksum, in hex, in the X-Farseek-Checksum-SHA256
+// header, so that the registry can verify the upload.
+func (c *Client) PublishModulePackage(ctx context.Context, packageAddr regaddr.ModulePackage, version string, archive []byte) error {
+	ctx, span := tracing.Tracer().Start(ctx, "Publish Module Package", tracing.SpanAttributes(
+		traceattrs.FarseekModuleSource(packageAddr.String()),
+		traceattrs.FarseekModuleVersion(version),
+	))
+	defer span.End()
+
+	host := packageAddr.Host
+	baseURL, err := c.discoverBaseURL(ctx, host)
+	if err != nil {
+		return err
+	}
+	uploadURL := modulePackageEndpointURL(baseURL, packageAddr, version, "upload")
+
+	log.Printf("[DEBUG] publishing module package to %q", uploadURL)
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "PUT", uploadURL.String(), archive)
+	if err != nil {
+		return err
+	}
+
+	c.addRequestCreds(ctx, host, req.Request)
+	req.Header.Set(xTerraformVersion, tfVersion)
+	req.Header.Set("Content-Type", "application/zip")
+	checksum := sha256.Sum256(archive)
+	req.Header.Set(xFarseekChecksumSHA256, hex.EncodeToString(checksum[:]))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body from registry: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusConflict:
+		return fmt.Errorf("module %q version %q already exists in the registry", packageAddr, version)
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return fmt.Errorf("the registry at %s doesn't support publishing module packages", host.ForDisplay())
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("not authorized to publish module %q to %s: %s", packageAddr, host.ForDisplay(), resp.Status)
+	default:
+		return fmt.Errorf("error publishing module %q version %q: %s resp:%s", packageAddr, version, resp.Status, body)
+	}
+}
@@ -9,7 +9,10 @@ package registry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -428,6 +431,77 @@ func TestModuleLocation_readRegistryResponse(t *testing.T) {
 	}
 }
 
+func TestPublishModulePackage(t *testing.T) {
+	archive := []byte("fake module package")
+	checksum := sha256.Sum256(archive)
+
+	cases := map[string]struct {
+		status  int
+		wantErr string
+	}{
+		"created": {
+			status: http.StatusCreated,
+		},
+		"already exists": {
+			status:  http.StatusConflict,
+			wantErr: `module "registry.opentofu.org/acme/network/aws" version "1.2.0" already exists in the registry`,
+		},
+		"publishing not supported": {
+			status:  http.StatusNotFound,
+			wantErr: `the registry at registry.opentofu.org doesn't support publishing module packages`,
+		},
+		"not authorized": {
+			status:  http.StatusForbidden,
+			wantErr: `not authorized to publish module "registry.opentofu.org/acme/network/aws" to registry.opentofu.org: 403 Forbidden`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var gotMethod, gotPath, gotAuth, gotChecksum string
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotPath = r.Method, r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				gotChecksum = r.Header.Get("X-Farseek-Checksum-SHA256")
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(t.Context(), test.Disco(server), nil)
+			modsrc := testParseModulePackageAddr(t, "acme/network/aws")
+
+			err := client.PublishModulePackage(t.Context(), modsrc, "1.2.0", archive)
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected success\nwant error: %s", tc.wantErr)
+				}
+				if got := err.Error(); got != tc.wantErr {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if gotMethod != "PUT" || gotPath != "/v1/modules/acme/network/aws/1.2.0/upload" {
+				t.Errorf("wrong request %s %s", gotMethod, gotPath)
+			}
+			if gotAuth != "Bearer test-auth-token" {
+				t.Errorf("wrong authorization %q", gotAuth)
+			}
+			if want := hex.EncodeToString(checksum[:]); gotChecksum != want {
+				t.Errorf("wrong checksum %q; want %q", gotChecksum, want)
+			}
+			if string(gotBody) != string(archive) {
+				t.Errorf("wrong body %q", gotBody)
+			}
+		})
+	}
+}
+
 func testParseModulePackageAddr(t *testing.T, src string) regaddr.ModulePackage {
 	sourceAddr, err := addrs.ParseModuleSourceRegistry(src)
 	if err != nil {
@@ -27,6 +27,16 @@ type ModuleVersion struct {
 	Version    string              `json:"version"`
 	Root       VersionSubmodule    `json:"root"`
 	Submodules []*VersionSubmodule `json:"submodules"`
+
+	// Deprecation is set by registries that mark the version as deprecated.
+	Deprecation *ModuleVersionDeprecation `json:"deprecation,omitempty"`
+}
+
+// ModuleVersionDeprecation explains why a module version is deprecated, and
+// may suggest the source address of a module to use instead.
+type ModuleVersionDeprecation struct {
+	Reason      string `json:"reason,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
 }
 
 // VersionSubmodule is the output metadata for a submodule within a given
@@ -49,8 +49,9 @@ func Disco(s *httptest.Server) *disco.Disco {
 // Map of module names and location of test modules.
 // Only one version for now, as we only lookup latest from the registry.
 type testMod struct {
-	location string
-	version  string
+	location    string
+	version     string
+	deprecation *response.ModuleVersionDeprecation
 }
 
 // Map of provider names and location of test providers.
@@ -104,6 +105,13 @@ var testMods = map[string][]testMod{
 	"private/name/provider": {
 		{version: "1.0.0"},
 	},
+	"deprecated/name/provider": {
+		{version: "1.1.0"},
+		{version: "1.0.0", deprecation: &response.ModuleVersionDeprecation{
+			Reason:      "Version 1.0.0 has a known bug.",
+			Replacement: "deprecated/name/provider 1.1.0",
+		}},
+	},
 }
 
 var testProviders = map[string][]testProvider{
@@ -228,7 +236,8 @@ func mockRegHandler(config map[uint8]struct{}) http.Handler {
 
 		for _, v := range versions {
 			mv := &response.ModuleVersion{
-				Version: v.version,
+				Version:     v.version,
+				Deprecation: v.deprecation,
 			}
 			mpvs.Versions = append(mpvs.Versions, mv)
 		}
@@ -0,0 +1,161 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package runtask calls run tasks: external HTTP services, such as security
+// scanners, that check a configuration before it's planned or a plan before
+// it's applied, and that can stop the operation if the check fails.
+package runtask
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// Stage is when a run task is called.
+type Stage string
+
+const (
+	// PrePlan tasks are called before planning, with the configuration.
+	PrePlan Stage = "pre_plan"
+
+	// PostPlan tasks are called after planning and before applying, with
+	// the plan.
+	PostPlan Stage = "post_plan"
+)
+
+// EnforcementLevel is what happens when a run task fails.
+type EnforcementLevel string
+
+const (
+	// Advisory tasks only warn when they fail.
+	Advisory EnforcementLevel = "advisory"
+
+	// Mandatory tasks stop the operation when they fail.
+	Mandatory EnforcementLevel = "mandatory"
+)
+
+// DefaultTimeout is how long a run task may take to respond when Task
+// doesn't set a timeout.
+const DefaultTimeout = 60 * time.Second
+
+// Task is a run task from the CLI configuration.
+type Task struct {
+	Name             string
+	URL              string
+	Stage            Stage
+	EnforcementLevel EnforcementLevel
+
+	// Timeout is how long the service may take to respond. If zero,
+	// DefaultTimeout applies.
+	Timeout time.Duration
+}
+
+// Request is the body of the POST request that calls a run task.
+type Request struct {
+	TaskName string `json:"task_name"`
+	Stage    Stage  `json:"stage"`
+
+	// Configuration is the JSON representation of the configuration, for
+	// the pre_plan stage.
+	Configuration json.RawMessage `json:"configuration,omitempty"`
+
+	// Plan is the JSON representation of the plan, for the post_plan stage.
+	Plan json.RawMessage `json:"plan,omitempty"`
+}
+
+// Response is the body of the response of a run task.
+type Response struct {
+	// Status is either "passed" or "failed".
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+)
+
+// Run calls each of the given tasks of the given stage in turn, with the
+// given payload, and returns diagnostics about the ones that fail: errors
+// for mandatory tasks and warnings for advisory ones. Tasks that can't be
+// reached, time out, or respond with something other than a status count
+// as failed.
+func Run(ctx context.Context, client *http.Client, tasks []*Task, stage Stage, payload Request) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	for _, task := range tasks {
+		if task.Stage != stage {
+			continue
+		}
+		req := payload
+		req.TaskName = task.Name
+		req.Stage = stage
+		resp, err := task.call(ctx, client, &req)
+		switch {
+		case err != nil:
+			diags = diags.Append(task.failure(fmt.Sprintf("Farseek couldn't get a result from the %s run task: %s.", task.Name, err)))
+		case resp.Status == StatusFailed:
+			detail := fmt.Sprintf("The %s run task failed.", task.Name)
+			if resp.Message != "" {
+				detail = fmt.Sprintf("The %s run task failed: %s", task.Name, resp.Message)
+			}
+			diags = diags.Append(task.failure(detail))
+		}
+	}
+	return diags
+}
+
+func (t *Task) call(ctx context.Context, client *http.Client, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	timeout := t.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, fmt.Errorf("the service responded with %s", httpResp.Status)
+	}
+	var resp Response
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Status != StatusPassed && resp.Status != StatusFailed {
+		return nil, fmt.Errorf("invalid response status %q: must be %q or %q", resp.Status, StatusPassed, StatusFailed)
+	}
+	return &resp, nil
+}
+
+func (t *Task) failure(detail string) tfdiags.Diagnostic {
+	severity := tfdiags.Error
+	if t.EnforcementLevel == Advisory {
+		severity = tfdiags.Warning
+		detail += " Its enforcement level is advisory, so Farseek continues."
+	}
+	return tfdiags.WithCode(tfdiags.Sourceless(severity, "Run task failed", detail), tfdiags.CodeRunTaskFailed)
+}
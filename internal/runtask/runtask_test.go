@@ -0,0 +1,89 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package runtask
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestRun(t *testing.T) {
+	var got []Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %s", err)
+		}
+		got = append(got, req)
+		switch r.URL.Path {
+		case "/pass":
+			w.Write([]byte(`{"status":"passed"}`))
+		case "/fail":
+			w.Write([]byte(`{"status":"failed","message":"2 high severity findings."}`))
+		case "/unknown":
+			w.Write([]byte(`{"status":"pending"}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(`{"status":"passed"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tasks := []*Task{
+		{Name: "pass", URL: server.URL + "/pass", Stage: PostPlan, EnforcementLevel: Mandatory},
+		{Name: "fail", URL: server.URL + "/fail", Stage: PostPlan, EnforcementLevel: Mandatory},
+		{Name: "advise", URL: server.URL + "/fail", Stage: PostPlan, EnforcementLevel: Advisory},
+		{Name: "unknown", URL: server.URL + "/unknown", Stage: PostPlan, EnforcementLevel: Mandatory},
+		{Name: "missing", URL: server.URL + "/missing", Stage: PostPlan, EnforcementLevel: Advisory},
+		{Name: "slow", URL: server.URL + "/slow", Stage: PostPlan, EnforcementLevel: Mandatory, Timeout: 50 * time.Millisecond},
+		{Name: "early", URL: server.URL + "/pass", Stage: PrePlan, EnforcementLevel: Mandatory},
+	}
+	diags := Run(context.Background(), server.Client(), tasks, PostPlan, Request{
+		Plan: json.RawMessage(`{"format_version":"1.5"}`),
+	})
+
+	if len(got) != 6 {
+		t.Fatalf("wrong number of calls %d; want 6", len(got))
+	}
+	for _, req := range got {
+		if req.Stage != PostPlan || string(req.Plan) != `{"format_version":"1.5"}` || req.Configuration != nil {
+			t.Errorf("wrong request %#v", req)
+		}
+	}
+	if got[0].TaskName != "pass" || got[1].TaskName != "fail" {
+		t.Errorf("wrong task names %q and %q", got[0].TaskName, got[1].TaskName)
+	}
+
+	type result struct {
+		severity tfdiags.Severity
+		detail   string
+	}
+	want := []result{
+		{tfdiags.Error, "The fail run task failed: 2 high severity findings."},
+		{tfdiags.Warning, "The advise run task failed: 2 high severity findings. Its enforcement level is advisory"},
+		{tfdiags.Error, `invalid response status "pending"`},
+		{tfdiags.Warning, "404 Not Found"},
+		{tfdiags.Error, "context deadline exceeded"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", len(diags), len(want), diags.ErrWithWarnings())
+	}
+	for i, diag := range diags {
+		if diag.Severity() != want[i].severity || !strings.Contains(diag.Description().Detail, want[i].detail) {
+			t.Errorf("wrong diagnostic %d: %s %q; want %s containing %q", i, diag.Severity(), diag.Description().Detail, want[i].severity, want[i].detail)
+		}
+		if code := tfdiags.DiagnosticCode(diag); code != tfdiags.CodeRunTaskFailed {
+			t.Errorf("wrong code %q for diagnostic %d", code, i)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statequery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+// Document returns the document that queries run over for the given state.
+// It's an object with two attributes:
+//
+//   - resources is a list of the current objects of the resource instances,
+//     sorted by address, each an object with the attributes address, module,
+//     mode, type, name, index, provider, status, and attrs.
+//   - outputs is an object with an attribute for each root module output
+//     value, itself an object with the attributes value and sensitive.
+//
+// Like farseek show -json, the document includes sensitive values.
+func Document(state *states.State) (map[string]any, error) {
+	resources := []any{}
+	outputs := map[string]any{}
+	if state == nil {
+		return map[string]any{"resources": resources, "outputs": outputs}, nil
+	}
+
+	var instances []addrs.AbsResourceInstance
+	objects := make(map[string]*states.ResourceInstanceObjectSrc)
+	providers := make(map[string]addrs.AbsProviderConfig)
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			for key, is := range rs.Instances {
+				if is.Current == nil {
+					continue
+				}
+				addr := rs.Addr.Instance(key)
+				instances = append(instances, addr)
+				objects[addr.String()] = is.Current
+				providers[addr.String()] = rs.ProviderConfig
+			}
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Less(instances[j])
+	})
+
+	for _, addr := range instances {
+		obj := objects[addr.String()]
+		attrs, err := objectAttrs(obj)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attributes for %s: %w", addr, err)
+		}
+		module := ""
+		if !addr.Module.IsRoot() {
+			module = addr.Module.String()
+		}
+		status := "ready"
+		if obj.Status == states.ObjectTainted {
+			status = "tainted"
+		}
+		resources = append(resources, map[string]any{
+			"address":  addr.String(),
+			"module":   module,
+			"mode":     modeString(addr.Resource.Resource.Mode),
+			"type":     addr.Resource.Resource.Type,
+			"name":     addr.Resource.Resource.Name,
+			"index":    keyValue(addr.Resource.Key),
+			"provider": providers[addr.String()].String(),
+			"status":   status,
+			"attrs":    attrs,
+		})
+	}
+
+	if root := state.RootModule(); root != nil {
+		for name, ov := range root.OutputValues {
+			val, _ := ov.Value.UnmarkDeep()
+			raw, err := ctyjson.Marshal(val, val.Type())
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for output %q: %w", name, err)
+			}
+			var v any
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, fmt.Errorf("invalid value for output %q: %w", name, err)
+			}
+			outputs[name] = map[string]any{
+				"value":     v,
+				"sensitive": ov.Sensitive,
+			}
+		}
+	}
+
+	return map[string]any{"resources": resources, "outputs": outputs}, nil
+}
+
+func objectAttrs(obj *states.ResourceInstanceObjectSrc) (any, error) {
+	if obj.AttrsJSON == nil {
+		// Very old states have flat attributes, which we can only show as
+		// they are.
+		attrs := make(map[string]any, len(obj.AttrsFlat))
+		for k, v := range obj.AttrsFlat {
+			attrs[k] = v
+		}
+		return attrs, nil
+	}
+	var attrs any
+	if err := json.Unmarshal(obj.AttrsJSON, &attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+func modeString(mode addrs.ResourceMode) string {
+	switch mode {
+	case addrs.DataResourceMode:
+		return "data"
+	case addrs.EphemeralResourceMode:
+		return "ephemeral"
+	default:
+		return "managed"
+	}
+}
+
+func keyValue(key addrs.InstanceKey) any {
+	switch key := key.(type) {
+	case addrs.IntKey:
+		return float64(key)
+	case addrs.StringKey:
+		return string(key)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statequery
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestDocument(t *testing.T) {
+	provider := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: "foo",
+			}.Instance(addrs.IntKey(1)).Absolute(addrs.RootModuleInstance.Child("app", addrs.NoKey)),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"foo"}`),
+				Status:    states.ObjectTainted,
+			},
+			provider,
+			addrs.NoKey,
+		)
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.DataResourceMode,
+				Type: "test_data",
+				Name: "bar",
+			}.Instance(addrs.StringKey("a")).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"bar"}`),
+				Status:    states.ObjectReady,
+			},
+			provider,
+			addrs.NoKey,
+		)
+		s.SetOutputValue(addrs.OutputValue{Name: "secret"}.Absolute(addrs.RootModuleInstance), cty.StringVal("hunter2"), true, "")
+	})
+
+	got, err := Document(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"resources": []any{
+			map[string]any{
+				"address":  `data.test_data.bar["a"]`,
+				"module":   "",
+				"mode":     "data",
+				"type":     "test_data",
+				"name":     "bar",
+				"index":    "a",
+				"provider": `provider["registry.opentofu.org/hashicorp/test"]`,
+				"status":   "ready",
+				"attrs":    map[string]any{"id": "bar"},
+			},
+			map[string]any{
+				"address":  "module.app.test_instance.foo[1]",
+				"module":   "module.app",
+				"mode":     "managed",
+				"type":     "test_instance",
+				"name":     "foo",
+				"index":    float64(1),
+				"provider": `provider["registry.opentofu.org/hashicorp/test"]`,
+				"status":   "tainted",
+				"attrs":    map[string]any{"id": "foo"},
+			},
+		},
+		"outputs": map[string]any{
+			"secret": map[string]any{"value": "hunter2", "sensitive": true},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong document\n%s", diff)
+	}
+}
@@ -0,0 +1,534 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package statequery implements a small query language, in the style of
+// JMESPath, over a JSON document describing the resources and outputs of a
+// state, for scripted analysis of the objects Farseek manages.
+//
+// A query is a path from the root of the document:
+//
+//	resources[type == "aws_instance" && attrs.tags.env == "prod"].attrs.id
+//
+// Each step of the path is one of the following:
+//
+//   - .name selects an attribute of an object. Names that aren't
+//     identifiers can be quoted, as in attrs.tags."cost-center".
+//   - [N] selects an element of a list, counting from the end if negative.
+//   - [*] selects all the elements of a list, or all the attribute values of
+//     an object.
+//   - [expression] selects the elements of a list for which the expression
+//     is true.
+//
+// After [*] or a filter, the rest of the path applies to each selected
+// element, and the elements for which it's null are left out, so the result
+// is a list.
+//
+// Filter expressions compare paths relative to the element, or @ for the
+// element itself, with literals: strings in double or single quotes,
+// numbers, true, false, and null. The operators are ==, !=, <, <=, >, >=,
+// =~ to match a string against a regular expression, &&, ||, and !, with
+// parentheses for grouping. A value on its own is true unless it's false,
+// null, or empty.
+package statequery
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed query.
+type Query struct {
+	steps []step
+}
+
+// Parse parses the given query.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	steps, err := p.path(true)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+	return &Query{steps: steps}, nil
+}
+
+// Eval returns the result of the query over the given document, which must
+// be made of the types that encoding/json decodes into an interface value.
+func (q *Query) Eval(doc any) any {
+	return evalSteps(doc, q.steps)
+}
+
+type step interface {
+	isStep()
+}
+
+type fieldStep struct{ name string }
+type indexStep struct{ index int }
+type wildcardStep struct{}
+type filterStep struct{ cond expr }
+
+func (fieldStep) isStep()    {}
+func (indexStep) isStep()    {}
+func (wildcardStep) isStep() {}
+func (filterStep) isStep()   {}
+
+func evalSteps(v any, steps []step) any {
+	if len(steps) == 0 {
+		return v
+	}
+	rest := steps[1:]
+	switch s := steps[0].(type) {
+	case fieldStep:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		return evalSteps(obj[s.name], rest)
+	case indexStep:
+		list, ok := v.([]any)
+		if !ok {
+			return nil
+		}
+		i := s.index
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil
+		}
+		return evalSteps(list[i], rest)
+	case wildcardStep, filterStep:
+		var elems []any
+		switch v := v.(type) {
+		case []any:
+			elems = v
+		case map[string]any:
+			if _, ok := s.(filterStep); ok {
+				return nil
+			}
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				elems = append(elems, v[k])
+			}
+		default:
+			return nil
+		}
+		ret := []any{}
+		for _, elem := range elems {
+			if f, ok := s.(filterStep); ok && !truthy(f.cond.eval(elem)) {
+				continue
+			}
+			if result := evalSteps(elem, rest); result != nil {
+				ret = append(ret, result)
+			}
+		}
+		return ret
+	default:
+		panic(fmt.Sprintf("unsupported step %T", s))
+	}
+}
+
+type expr interface {
+	eval(elem any) any
+}
+
+type literalExpr struct{ val any }
+type pathExpr struct{ steps []step }
+type notExpr struct{ operand expr }
+type andExpr struct{ lhs, rhs expr }
+type orExpr struct{ lhs, rhs expr }
+type compareExpr struct {
+	op       string
+	lhs, rhs expr
+}
+type matchExpr struct {
+	lhs expr
+	re  *regexp.Regexp
+}
+
+func (e literalExpr) eval(any) any   { return e.val }
+func (e pathExpr) eval(elem any) any { return evalSteps(elem, e.steps) }
+func (e notExpr) eval(elem any) any  { return !truthy(e.operand.eval(elem)) }
+func (e andExpr) eval(elem any) any  { return truthy(e.lhs.eval(elem)) && truthy(e.rhs.eval(elem)) }
+func (e orExpr) eval(elem any) any   { return truthy(e.lhs.eval(elem)) || truthy(e.rhs.eval(elem)) }
+func (e matchExpr) eval(elem any) any {
+	s, ok := e.lhs.eval(elem).(string)
+	return ok && e.re.MatchString(s)
+}
+
+func (e compareExpr) eval(elem any) any {
+	lhs, rhs := e.lhs.eval(elem), e.rhs.eval(elem)
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(lhs, rhs)
+	case "!=":
+		return !reflect.DeepEqual(lhs, rhs)
+	}
+
+	// The ordering operators only compare numbers with numbers and strings
+	// with strings, and are false for anything else.
+	var cmp int
+	switch lhs := lhs.(type) {
+	case float64:
+		rhs, ok := rhs.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case lhs < rhs:
+			cmp = -1
+		case lhs > rhs:
+			cmp = 1
+		}
+	case string:
+		rhs, ok := rhs.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(lhs, rhs)
+	default:
+		return false
+	}
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// truthy returns whether the given value counts as true in a filter.
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) != 0
+	case map[string]any:
+		return len(v) != 0
+	default:
+		return true
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString, tokenQuotedIdent:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// punctuation lists the operators and delimiters, longest first so that
+// lexing prefers them.
+var punctuation = []string{"==", "!=", "<=", ">=", "=~", "&&", "||", ".", "[", "]", "(", ")", "<", ">", "!", "*", "@"}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			var buf strings.Builder
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at position %d", start+1)
+				}
+				if rune(src[i]) == c {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				buf.WriteByte(src[i])
+				i++
+			}
+			kind := tokenString
+			// A double-quoted string right after a dot is a field name.
+			if c == '"' && len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenPunct && tokens[len(tokens)-1].text == "." {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: buf.String(), pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			i++
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], pos: start})
+		default:
+			found := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(src[i:], p) {
+					tokens = append(tokens, token{kind: tokenPunct, text: p, pos: i})
+					i += len(p)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) accept(punct string) bool {
+	if tok := p.peek(); tok.kind == tokenPunct && tok.text == punct {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.accept(punct) {
+		return fmt.Errorf("expected %q, but found %s at position %d", punct, p.peek(), p.peek().pos+1)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	return fmt.Errorf("unexpected %s at position %d", tok, tok.pos+1)
+}
+
+// path parses a path. Top-level paths, unlike the paths in filters, can
+// select elements with [*] and filters.
+func (p *parser) path(topLevel bool) ([]step, error) {
+	var steps []step
+	tok := p.next()
+	switch {
+	case tok.kind == tokenIdent:
+		steps = append(steps, fieldStep{name: tok.text})
+	case tok.kind == tokenPunct && tok.text == "@":
+	default:
+		return nil, p.unexpected(tok)
+	}
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.next()
+			if tok.kind != tokenIdent && tok.kind != tokenQuotedIdent {
+				return nil, p.unexpected(tok)
+			}
+			steps = append(steps, fieldStep{name: tok.text})
+		case p.accept("["):
+			s, err := p.bracket(topLevel)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		default:
+			return steps, nil
+		}
+	}
+}
+
+func (p *parser) bracket(topLevel bool) (step, error) {
+	if tok := p.peek(); tok.kind == tokenNumber {
+		p.next()
+		i, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %s at position %d", tok.text, tok.pos+1)
+		}
+		return indexStep{index: i}, p.expect("]")
+	}
+	if !topLevel {
+		return nil, fmt.Errorf("paths in filters can only select elements by index, at position %d", p.peek().pos+1)
+	}
+	if p.accept("*") {
+		return wildcardStep{}, p.expect("]")
+	}
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	return filterStep{cond: cond}, p.expect("]")
+}
+
+func (p *parser) or() (expr, error) {
+	lhs, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		rhs, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		lhs = orExpr{lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) and() (expr, error) {
+	lhs, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		rhs, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = andExpr{lhs: lhs, rhs: rhs}
+	}
+	return lhs, nil
+}
+
+func (p *parser) unary() (expr, error) {
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{operand: operand}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (expr, error) {
+	lhs, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokenPunct {
+		return lhs, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		rhs, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr{op: tok.text, lhs: lhs, rhs: rhs}, nil
+	case "=~":
+		p.next()
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, fmt.Errorf("the =~ operator needs a string with a regular expression, but found %s at position %d", pattern, pattern.pos+1)
+		}
+		re, err := regexp.Compile(pattern.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %w", pattern.pos+1, err)
+		}
+		return matchExpr{lhs: lhs, re: re}, nil
+	}
+	return lhs, nil
+}
+
+func (p *parser) operand() (expr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenString:
+		p.next()
+		return literalExpr{val: tok.text}, nil
+	case tokenNumber:
+		p.next()
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", tok.text, tok.pos+1)
+		}
+		return literalExpr{val: n}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			p.next()
+			return literalExpr{val: true}, nil
+		case "false":
+			p.next()
+			return literalExpr{val: false}, nil
+		case "null":
+			p.next()
+			return literalExpr{val: nil}, nil
+		}
+	case tokenPunct:
+		if p.accept("(") {
+			e, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+	}
+	steps, err := p.path(false)
+	if err != nil {
+		return nil, err
+	}
+	return pathExpr{steps: steps}, nil
+}
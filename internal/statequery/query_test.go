@@ -0,0 +1,96 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statequery
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDocument = `{
+  "resources": [
+    {"address": "aws_instance.web[0]", "type": "aws_instance", "index": 0, "attrs": {"id": "i-1", "cpus": 2, "tags": {"env": "prod", "cost-center": "42"}}},
+    {"address": "aws_instance.web[1]", "type": "aws_instance", "index": 1, "attrs": {"id": "i-2", "cpus": 8, "tags": {"env": "dev"}}},
+    {"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "index": null, "attrs": {"id": "logs", "tags": null}}
+  ],
+  "outputs": {
+    "a": {"value": 1, "sensitive": false},
+    "b": {"value": "two", "sensitive": true}
+  }
+}`
+
+func TestQuery(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(testDocument), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		`resources[type=="aws_instance" && attrs.tags.env=="prod"].attrs.id`: `["i-1"]`,
+		`resources[*].attrs.id`:                                           `["i-1","i-2","logs"]`,
+		`resources[*].attrs.tags.env`:                                     `["prod","dev"]`,
+		`resources[attrs.cpus > 4].address`:                               `["aws_instance.web[1]"]`,
+		`resources[attrs.cpus >= 2 && attrs.cpus <= 2].index`:             `[0]`,
+		`resources[!attrs.tags].address`:                                  `["aws_s3_bucket.logs"]`,
+		`resources[attrs.tags."cost-center" == '42'].attrs.id`:            `["i-1"]`,
+		`resources[address =~ "^aws_instance\\.web\\[[01]\\]$"].attrs.id`: `["i-1","i-2"]`,
+		`resources[type != "aws_instance" || (index == 1)].address`:       `["aws_instance.web[1]","aws_s3_bucket.logs"]`,
+		`resources[-1].attrs.id`:                                          `"logs"`,
+		`resources[5]`:                                                    `null`,
+		`resources[0].attrs.tags`:                                         `{"cost-center":"42","env":"prod"}`,
+		`outputs[*].value`:                                                `[1,"two"]`,
+		`outputs.b.sensitive`:                                             `true`,
+		`outputs[sensitive]`:                                              `null`,
+		`resources[attrs.tags[0]].address`:                                `[]`,
+		`resources[@.type == "aws_s3_bucket"]`:                            `[{"address":"aws_s3_bucket.logs","attrs":{"id":"logs","tags":null},"index":null,"type":"aws_s3_bucket"}]`,
+		`resources[attrs.cpus < "8"].attrs.id`:                            `[]`,
+		`resources[attrs.tags.env == null].address`:                       `["aws_s3_bucket.logs"]`,
+	}
+	for src, want := range tests {
+		t.Run(src, func(t *testing.T) {
+			q, err := Parse(src)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			got, err := json.Marshal(q.Eval(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, string(got)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	tests := map[string]string{
+		``:                              "unexpected end of query at position 1",
+		`resources[`:                    "unexpected end of query at position 11",
+		`resources[*`:                   `expected "]", but found end of query`,
+		`resources.`:                    "unexpected end of query",
+		`resources[type == "a"`:         `expected "]"`,
+		`resources[type == "a]`:         "unterminated string at position 19",
+		`resources[attrs[*]]`:           "paths in filters can only select elements by index",
+		`resources[type =~ type]`:       "the =~ operator needs a string",
+		`resources[type =~ "("]`:        "invalid regular expression",
+		`resources # comment`:           `unexpected character '#' at position 11`,
+		`resources foo`:                 `unexpected "foo" at position 11`,
+		`resources[(type == "a"].attrs`: `expected ")"`,
+	}
+	for src, want := range tests {
+		t.Run(src, func(t *testing.T) {
+			_, err := Parse(src)
+			if err == nil {
+				t.Fatal("succeeded; want error")
+			}
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("wrong error %q; want to contain %q", err, want)
+			}
+		})
+	}
+}
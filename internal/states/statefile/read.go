@@ -8,6 +8,7 @@
 package statefile
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -138,7 +139,11 @@ func readState(src []byte) (*File, error) {
 	case 3:
 		result, diags = readStateV3(src)
 	case 4:
-		result, diags = readStateV4(src)
+		if len(src) >= streamReadThreshold {
+			result, diags = readStateV4Stream(bytes.NewReader(src))
+		} else {
+			result, diags = readStateV4(src)
+		}
 	default:
 		thisVersion := tfversion.SemVer.String()
 		creatingVersion := sniffJSONStateTerraformVersion(src)
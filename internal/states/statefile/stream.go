@@ -0,0 +1,299 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statefile
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	version "github.com/hashicorp/go-version"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+	tfversion "github.com/rafagsiqueira/farseek/version"
+)
+
+// StreamResourceThreshold is the number of resources at which Write switches
+// to the streaming serializer used by WriteStream, which produces an
+// equivalent state file without building the whole document in memory first.
+const StreamResourceThreshold = 10000
+
+// streamReadThreshold is the size in bytes of a version 4 state above which
+// Read decodes it one resource at a time instead of all at once.
+const streamReadThreshold = 32 << 20
+
+// WriteStream writes the given state to the given writer in the current
+// state serialization format, without encryption.
+//
+// Unlike Write, WriteStream never holds more than one resource in its
+// serialized form at a time: it writes the header fields first, then each
+// resource as a separate line, and then the output values and check results
+// as a footer. The result is an ordinary state file, so any of the functions
+// in this package can read it, but ReadStream can do so with similarly
+// bounded memory.
+func WriteStream(s *File, w io.Writer) error {
+	// Always record the current farseek version in the state.
+	s.TerraformVersion = tfversion.SemVer
+
+	return writeStateV4Stream(s, w).Err()
+}
+
+// ReadStream reads a version 4 state file, as written by either Write or
+// WriteStream, from the given reader without buffering all of it. Each
+// resource is added to the resulting state as soon as it is decoded.
+//
+// ReadStream does not support encrypted state or earlier state format
+// versions; use Read for those.
+func ReadStream(r io.Reader) (*File, error) {
+	file, diags := readStateV4Stream(r)
+	return file, diags.Err()
+}
+
+func writeStateV4Stream(file *File, w io.Writer) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if file == nil || file.State == nil {
+		panic("attempt to write nil state to file")
+	}
+
+	var terraformVersion string
+	if file.TerraformVersion != nil {
+		terraformVersion = file.TerraformVersion.String()
+	}
+
+	// Resources are written in the same order that stateV4.normalize would
+	// put them in, but we only sort lightweight references to them here.
+	type resourceRef struct {
+		key [4]string
+		ms  *states.Module
+		rs  *states.Resource
+	}
+	var refs []resourceRef
+	for _, ms := range file.State.Modules {
+		for _, rs := range ms.Resources {
+			refs = append(refs, resourceRef{
+				key: [4]string{ms.Addr.String(), resourceModeV4(rs), rs.Addr.Resource.Type, rs.Addr.Resource.Name},
+				ms:  ms,
+				rs:  rs,
+			})
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		for k := range refs[i].key {
+			if refs[i].key[k] != refs[j].key[k] {
+				return refs[i].key[k] < refs[j].key[k]
+			}
+		}
+		return false
+	})
+
+	bw := bufio.NewWriter(w)
+	sw := &streamWriter{w: bw}
+
+	sw.printf("{\"version\":%d,", 4)
+	sw.field("terraform_version", terraformVersion)
+	sw.printf(",")
+	sw.field("serial", file.Serial)
+	sw.printf(",")
+	sw.field("lineage", file.Lineage)
+	sw.printf(",\n\"resources\":[")
+	first := true
+	for _, ref := range refs {
+		rsV4, rsDiags := resourceV4(ref.ms.Addr, ref.rs)
+		diags = diags.Append(rsDiags)
+		if rsV4 == nil {
+			continue
+		}
+		sort.Stable(sortInstancesV4(rsV4.Instances))
+		if first {
+			sw.printf("\n")
+			first = false
+		} else {
+			sw.printf(",\n")
+		}
+		sw.value(rsV4)
+	}
+	sw.printf("\n],\n")
+
+	rootOutputs, outputDiags := rootOutputsV4(file.State)
+	diags = diags.Append(outputDiags)
+	sw.field("outputs", rootOutputs)
+	sw.printf(",")
+	sw.field("check_results", encodeCheckResultsV4(file.State.CheckResults))
+	sw.printf("}\n")
+
+	if sw.err == nil {
+		sw.err = bw.Flush()
+	}
+	if sw.err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write state",
+			fmt.Sprintf("An error occurred while writing the serialized state: %s.", sw.err),
+		))
+	}
+	return diags
+}
+
+// resourceModeV4 returns the mode string that resourceV4 uses for the given
+// resource, for sorting purposes only.
+func resourceModeV4(rs *states.Resource) string {
+	if rs.Addr.Resource.Mode == addrs.DataResourceMode {
+		return "data"
+	}
+	return "managed"
+}
+
+// streamWriter writes the parts of a JSON document, remembering the first
+// error so that callers need only check once at the end.
+type streamWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *streamWriter) printf(format string, args ...interface{}) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = fmt.Fprintf(sw.w, format, args...)
+}
+
+func (sw *streamWriter) field(name string, v interface{}) {
+	sw.value(name)
+	sw.printf(":")
+	sw.value(v)
+}
+
+func (sw *streamWriter) value(v interface{}) {
+	if sw.err != nil {
+		return
+	}
+	src, err := json.Marshal(v)
+	if err != nil {
+		sw.err = err
+		return
+	}
+	_, sw.err = sw.w.Write(src)
+}
+
+func readStateV4Stream(r io.Reader) (*File, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	invalid := func(err error) (*File, tfdiags.Diagnostics) {
+		diags = diags.Append(jsonUnmarshalDiags(err))
+		return nil, diags
+	}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return invalid(err)
+	}
+
+	// The fields other than the resources are small, so we decode them into
+	// a stateV4 as usual and then prepare everything except the resources
+	// once we've seen all of them.
+	sV4 := &stateV4{}
+	var stateVersion uint64
+	state := states.NewState()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return invalid(err)
+		}
+		name, _ := tok.(string)
+
+		switch name {
+		case "version":
+			err = dec.Decode(&stateVersion)
+			if err == nil && stateVersion != 4 {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					unsupportedFormat,
+					fmt.Sprintf("The state file uses format version %d, but only version 4 can be read as a stream.", stateVersion),
+				))
+				return nil, diags
+			}
+		case "terraform_version":
+			err = dec.Decode(&sV4.TerraformVersion)
+		case "serial":
+			err = dec.Decode(&sV4.Serial)
+		case "lineage":
+			err = dec.Decode(&sV4.Lineage)
+		case "outputs":
+			err = dec.Decode(&sV4.RootOutputs)
+		case "check_results":
+			err = dec.Decode(&sV4.CheckResults)
+		case "resources":
+			if err := expectDelim(dec, '['); err != nil {
+				return invalid(err)
+			}
+			for dec.More() {
+				var rsV4 resourceStateV4
+				if err := dec.Decode(&rsV4); err != nil {
+					return invalid(err)
+				}
+				diags = diags.Append(prepareResourceV4(state, &rsV4))
+			}
+			err = expectDelim(dec, ']')
+		default:
+			// Unknown fields are ignored, as they would be by json.Unmarshal.
+			var ignored json.RawMessage
+			err = dec.Decode(&ignored)
+		}
+		if err != nil {
+			return invalid(err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return invalid(err)
+	}
+	if stateVersion != 4 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			unsupportedFormat,
+			"The state file does not specify format version 4, so it cannot be read as a stream.",
+		))
+		return nil, diags
+	}
+
+	file := &File{
+		Serial:  sV4.Serial,
+		Lineage: sV4.Lineage,
+	}
+	if sV4.TerraformVersion != "" {
+		tfVersion, err := version.NewVersion(sV4.TerraformVersion)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid Farseek version string",
+				fmt.Sprintf("State file claims to have been written by Farseek version %q, which is not a valid version string.", sV4.TerraformVersion),
+			))
+		}
+		file.TerraformVersion = tfVersion
+	}
+
+	diags = diags.Append(prepareRootOutputsV4(state, sV4.RootOutputs))
+	if sV4.CheckResults != nil {
+		var moreDiags tfdiags.Diagnostics
+		state.CheckResults, moreDiags = decodeCheckResultsV4(sV4.CheckResults)
+		diags = diags.Append(moreDiags)
+	}
+
+	file.State = state
+	return file, diags
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if got, ok := tok.(json.Delim); !ok || got != want {
+		return &json.SyntaxError{Offset: dec.InputOffset()}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statefile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/states"
+)
+
+func TestRoundtripStream(t *testing.T) {
+	const dir = "testdata/roundtrip"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, info := range entries {
+		const outSuffix = ".out.tfstate"
+
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, outSuffix) || !strings.HasPrefix(name, "v4") {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, diags := readStateV4(src)
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+
+			// The streaming reader must agree with the normal one.
+			got, err := ReadStream(bytes.NewReader(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatal("wrong result from ReadStream:\n" + diff)
+			}
+
+			// The streaming writer must produce a state file that the
+			// normal reader understands, with the same content.
+			var buf bytes.Buffer
+			if err := WriteStream(got, &buf); err != nil {
+				t.Fatal(err)
+			}
+			written, diags := readStateV4(buf.Bytes())
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+			if diff := cmp.Diff(want, written, cmpopts.IgnoreFields(File{}, "TerraformVersion")); diff != "" {
+				t.Error("wrong result from WriteStream:\n" + diff)
+			}
+		})
+	}
+}
+
+func TestReadStream_version(t *testing.T) {
+	src := []byte(`{"version": 3, "serial": 1, "lineage": "abc"}`)
+	_, err := ReadStream(bytes.NewReader(src))
+	if err == nil || !strings.Contains(err.Error(), "only version 4 can be read as a stream") {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
+
+func TestWrite_largeState(t *testing.T) {
+	f := largeStateFile(StreamResourceThreshold)
+
+	var buf bytes.Buffer
+	if err := Write(f, &buf, encryption.StateEncryptionDisabled()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf, encryption.StateEncryptionDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(f.State, got.State); diff != "" {
+		t.Error("wrong result:\n" + diff)
+	}
+}
+
+func BenchmarkWrite(b *testing.B) {
+	f := largeStateFile(100000)
+	b.ReportAllocs()
+	for range b.N {
+		if diags := writeStateV4(f, discardWriter{}, encryption.StateEncryptionDisabled()); diags.HasErrors() {
+			b.Fatal(diags.Err())
+		}
+	}
+}
+
+func BenchmarkWriteStream(b *testing.B) {
+	f := largeStateFile(100000)
+	b.ReportAllocs()
+	for range b.N {
+		if err := WriteStream(f, discardWriter{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	src := largeStateSource(b, 100000)
+	b.ReportAllocs()
+	for range b.N {
+		if _, diags := readStateV4(src); diags.HasErrors() {
+			b.Fatal(diags.Err())
+		}
+	}
+}
+
+func BenchmarkReadStream(b *testing.B) {
+	src := largeStateSource(b, 100000)
+	b.ReportAllocs()
+	for range b.N {
+		if _, err := ReadStream(bytes.NewReader(src)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func largeStateSource(b *testing.B, count int) []byte {
+	var buf bytes.Buffer
+	if err := WriteStream(largeStateFile(count), &buf); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// largeStateFile returns a state file with the given number of managed
+// resources, spread across a handful of modules.
+func largeStateFile(count int) *File {
+	state := states.NewState()
+	provider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+	for i := range count {
+		module := addrs.RootModuleInstance
+		if n := i % 4; n != 0 {
+			module = module.Child(fmt.Sprintf("mod%d", n), addrs.NoKey)
+		}
+		addr := addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_thing",
+			Name: fmt.Sprintf("r%d", i),
+		}.Instance(addrs.NoKey)
+		state.EnsureModule(module).SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectReady,
+			AttrsJSON:    []byte(fmt.Sprintf(`{"id":"thing-%d","value":"some moderately long attribute value"}`, i)),
+			Dependencies: []addrs.ConfigResource{},
+		}, provider, addrs.NoKey)
+	}
+	return &File{
+		Serial:  1,
+		Lineage: "large-state",
+		State:   state,
+	}
+}
@@ -63,305 +63,322 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 
 	state := states.NewState()
 
-	for _, rsV4 := range sV4.Resources {
-		rAddr := addrs.Resource{
-			Type: rsV4.Type,
-			Name: rsV4.Name,
-		}
-		switch rsV4.Mode {
-		case "managed":
-			rAddr.Mode = addrs.ManagedResourceMode
-		case "data":
-			rAddr.Mode = addrs.DataResourceMode
-		default:
-			// This covers also addrs.EphemeralResourceMode. Should never happen, so this comment is just an indication
-			// that this part was checked during ephemeral resources implementation.
-			diags = diags.Append(tfdiags.Sourceless(
-				tfdiags.Error,
-				"Invalid resource mode in state",
-				fmt.Sprintf("State contains a resource with mode %q (%q %q) which is not supported.", rsV4.Mode, rAddr.Type, rAddr.Name),
-			))
-			continue
-		}
+	for i := range sV4.Resources {
+		diags = diags.Append(prepareResourceV4(state, &sV4.Resources[i]))
+	}
 
-		moduleAddr := addrs.RootModuleInstance
-		if rsV4.Module != "" {
-			var addrDiags tfdiags.Diagnostics
-			moduleAddr, addrDiags = addrs.ParseModuleInstanceStr(rsV4.Module)
-			diags = diags.Append(addrDiags)
-			if addrDiags.HasErrors() {
-				continue
-			}
-		}
+	// The root module is special in that we persist its attributes and thus
+	// need to reload them now. (For descendent modules we just re-calculate
+	// them based on the latest configuration on each run.)
+	diags = diags.Append(prepareRootOutputsV4(state, sV4.RootOutputs))
 
-		var providerAddr addrs.AbsProviderConfig
-		var addrDiags tfdiags.Diagnostics
-		if rsV4.ProviderInstance != "" {
-			providerAddr, addrDiags = addrs.ParseAbsProviderConfigStr(rsV4.ProviderInstance)
-			if addrDiags.HasErrors() {
-				// If ParseAbsProviderConfigStr returns an error, the state may have
-				// been written before Provider FQNs were introduced and the
-				// AbsProviderInstance string format will need normalization. If so,
-				// we treat it like a legacy provider (namespace "-") and let the
-				// provider installer handle detecting the FQN.
-				var legacyAddrDiags tfdiags.Diagnostics
-				providerAddr, legacyAddrDiags = addrs.ParseLegacyAbsProviderConfigStr(rsV4.ProviderInstance)
-				if legacyAddrDiags.HasErrors() {
-					// Neither parse formats are valid, let's report the original error
-					diags = diags.Append(addrDiags)
-					continue
-				}
+	// Saved check results from the previous run, if any.
+	// We differentiate absence from an empty array here so that we can
+	// recognize if the previous run was with a version of Farseek that
+	// didn't support checks yet, or if there just weren't any checkable
+	// objects to record, in case that's important for certain messaging.
+	if sV4.CheckResults != nil {
+		var moreDiags tfdiags.Diagnostics
+		state.CheckResults, moreDiags = decodeCheckResultsV4(sV4.CheckResults)
+		diags = diags.Append(moreDiags)
+	}
 
-				// Valid legacy address, but may contain warnings
-				diags = diags.Append(legacyAddrDiags)
-			} else {
-				// Valid address, but may contain warnings
-				diags = diags.Append(addrDiags)
-			}
+	file.State = state
+	return file, diags
+}
+
+// prepareResourceV4 adds the given resource and its instances to the given
+// state.
+func prepareResourceV4(state *states.State, rsV4 *resourceStateV4) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	rAddr := addrs.Resource{
+		Type: rsV4.Type,
+		Name: rsV4.Name,
+	}
+	switch rsV4.Mode {
+	case "managed":
+		rAddr.Mode = addrs.ManagedResourceMode
+	case "data":
+		rAddr.Mode = addrs.DataResourceMode
+	default:
+		// This covers also addrs.EphemeralResourceMode. Should never happen, so this comment is just an indication
+		// that this part was checked during ephemeral resources implementation.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid resource mode in state",
+			fmt.Sprintf("State contains a resource with mode %q (%q %q) which is not supported.", rsV4.Mode, rAddr.Type, rAddr.Name),
+		))
+		return diags
+	}
+
+	moduleAddr := addrs.RootModuleInstance
+	if rsV4.Module != "" {
+		var addrDiags tfdiags.Diagnostics
+		moduleAddr, addrDiags = addrs.ParseModuleInstanceStr(rsV4.Module)
+		diags = diags.Append(addrDiags)
+		if addrDiags.HasErrors() {
+			return diags
 		}
+	}
 
-		ms := state.EnsureModule(moduleAddr)
-
-		// Ensure the resource container object is present in the state.
-		ms.SetResourceProvider(rAddr, providerAddr)
-
-		// Keep track of instance providers for validation
-		var instanceProviders []addrs.AbsProviderConfig
-
-		for _, isV4 := range rsV4.Instances {
-			keyRaw := isV4.IndexKey
-			var key addrs.InstanceKey
-			switch tk := keyRaw.(type) {
-			case int:
-				key = addrs.IntKey(tk)
-			case float64:
-				// Since JSON only has one number type, reading from encoding/json
-				// gives us a float64 here even if the number is whole.
-				// float64 has a smaller integer range than int, but in practice
-				// we rarely have more than a few tens of instances and so
-				// it's unlikely that we'll exhaust the 52 bits in a float64.
-				key = addrs.IntKey(int(tk))
-			case string:
-				key = addrs.StringKey(tk)
-			default:
-				if keyRaw != nil {
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Invalid resource instance metadata in state",
-						fmt.Sprintf("Resource %s has an instance with the invalid instance key %#v.", rAddr.Absolute(moduleAddr), keyRaw),
-					))
-					continue
-				}
-				key = addrs.NoKey
+	var providerAddr addrs.AbsProviderConfig
+	var addrDiags tfdiags.Diagnostics
+	if rsV4.ProviderInstance != "" {
+		providerAddr, addrDiags = addrs.ParseAbsProviderConfigStr(rsV4.ProviderInstance)
+		if addrDiags.HasErrors() {
+			// If ParseAbsProviderConfigStr returns an error, the state may have
+			// been written before Provider FQNs were introduced and the
+			// AbsProviderInstance string format will need normalization. If so,
+			// we treat it like a legacy provider (namespace "-") and let the
+			// provider installer handle detecting the FQN.
+			var legacyAddrDiags tfdiags.Diagnostics
+			providerAddr, legacyAddrDiags = addrs.ParseLegacyAbsProviderConfigStr(rsV4.ProviderInstance)
+			if legacyAddrDiags.HasErrors() {
+				// Neither parse formats are valid, let's report the original error
+				diags = diags.Append(addrDiags)
+				return diags
 			}
 
-			if isV4.ProviderInstance != "" && rsV4.ProviderInstance != "" {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Warning,
-					"Provider field conflict in state",
-					fmt.Sprintf("Resource %s has a provider address %s, as well as instance %s with provider address %s.", rAddr.Absolute(moduleAddr), rsV4.ProviderInstance, key, isV4.ProviderInstance),
-				))
-			}
+			// Valid legacy address, but may contain warnings
+			diags = diags.Append(legacyAddrDiags)
+		} else {
+			// Valid address, but may contain warnings
+			diags = diags.Append(addrDiags)
+		}
+	}
 
-			if isV4.ProviderInstance == "" && rsV4.ProviderInstance == "" {
+	ms := state.EnsureModule(moduleAddr)
+
+	// Ensure the resource container object is present in the state.
+	ms.SetResourceProvider(rAddr, providerAddr)
+
+	// Keep track of instance providers for validation
+	var instanceProviders []addrs.AbsProviderConfig
+
+	for _, isV4 := range rsV4.Instances {
+		keyRaw := isV4.IndexKey
+		var key addrs.InstanceKey
+		switch tk := keyRaw.(type) {
+		case int:
+			key = addrs.IntKey(tk)
+		case float64:
+			// Since JSON only has one number type, reading from encoding/json
+			// gives us a float64 here even if the number is whole.
+			// float64 has a smaller integer range than int, but in practice
+			// we rarely have more than a few tens of instances and so
+			// it's unlikely that we'll exhaust the 52 bits in a float64.
+			key = addrs.IntKey(int(tk))
+		case string:
+			key = addrs.StringKey(tk)
+		default:
+			if keyRaw != nil {
 				diags = diags.Append(tfdiags.Sourceless(
 					tfdiags.Error,
-					"Provider field missing state",
-					fmt.Sprintf("Resource %s is missing a provider address, both on the resource and the resource instances.", rAddr.Absolute(moduleAddr)),
+					"Invalid resource instance metadata in state",
+					fmt.Sprintf("Resource %s has an instance with the invalid instance key %#v.", rAddr.Absolute(moduleAddr), keyRaw),
 				))
+				continue
 			}
+			key = addrs.NoKey
+		}
 
-			instanceProvider := providerAddr
-			instanceProviderKey := addrs.NoKey
-			if isV4.ProviderInstance != "" {
-				instanceProvider, instanceProviderKey, addrDiags = addrs.ParseAbsProviderConfigInstanceStr(isV4.ProviderInstance)
-				diags = diags.Append(addrDiags)
-				instanceProviders = append(instanceProviders, instanceProvider)
-			}
+		if isV4.ProviderInstance != "" && rsV4.ProviderInstance != "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Provider field conflict in state",
+				fmt.Sprintf("Resource %s has a provider address %s, as well as instance %s with provider address %s.", rAddr.Absolute(moduleAddr), rsV4.ProviderInstance, key, isV4.ProviderInstance),
+			))
+		}
 
-			instAddr := rAddr.Instance(key)
+		if isV4.ProviderInstance == "" && rsV4.ProviderInstance == "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Provider field missing state",
+				fmt.Sprintf("Resource %s is missing a provider address, both on the resource and the resource instances.", rAddr.Absolute(moduleAddr)),
+			))
+		}
 
-			obj := &states.ResourceInstanceObjectSrc{
-				SchemaVersion:       isV4.SchemaVersion,
-				CreateBeforeDestroy: isV4.CreateBeforeDestroy,
-				SkipDestroy:         isV4.SkipDestroy,
-			}
+		instanceProvider := providerAddr
+		instanceProviderKey := addrs.NoKey
+		if isV4.ProviderInstance != "" {
+			instanceProvider, instanceProviderKey, addrDiags = addrs.ParseAbsProviderConfigInstanceStr(isV4.ProviderInstance)
+			diags = diags.Append(addrDiags)
+			instanceProviders = append(instanceProviders, instanceProvider)
+		}
 
-			{
-				// Instance attributes
-				switch {
-				case isV4.AttributesRaw != nil:
-					obj.AttrsJSON = isV4.AttributesRaw
-				case isV4.AttributesFlat != nil:
-					obj.AttrsFlat = isV4.AttributesFlat
-				default:
-					// This is odd, but we'll accept it and just treat the
-					// object has being empty. In practice this should arise
-					// only from the contrived sort of state objects we tend
-					// to hand-write inline in tests.
-					obj.AttrsJSON = []byte{'{', '}'}
-				}
-			}
+		instAddr := rAddr.Instance(key)
 
-			// Sensitive paths
-			if isV4.AttributeSensitivePaths != nil {
-				paths, pathsDiags := unmarshalPaths([]byte(isV4.AttributeSensitivePaths))
-				diags = diags.Append(pathsDiags)
-				if pathsDiags.HasErrors() {
-					continue
-				}
+		obj := &states.ResourceInstanceObjectSrc{
+			SchemaVersion:       isV4.SchemaVersion,
+			CreateBeforeDestroy: isV4.CreateBeforeDestroy,
+			SkipDestroy:         isV4.SkipDestroy,
+		}
 
-				var pvm []cty.PathValueMarks
-				for _, path := range paths {
-					pvm = append(pvm, cty.PathValueMarks{
-						Path:  path,
-						Marks: cty.NewValueMarks(marks.Sensitive),
-					})
-				}
-				obj.AttrSensitivePaths = pvm
+		{
+			// Instance attributes
+			switch {
+			case isV4.AttributesRaw != nil:
+				obj.AttrsJSON = isV4.AttributesRaw
+			case isV4.AttributesFlat != nil:
+				obj.AttrsFlat = isV4.AttributesFlat
+			default:
+				// This is odd, but we'll accept it and just treat the
+				// object has being empty. In practice this should arise
+				// only from the contrived sort of state objects we tend
+				// to hand-write inline in tests.
+				obj.AttrsJSON = []byte{'{', '}'}
 			}
+		}
 
-			{
-				// Status
-				raw := isV4.Status
-				switch raw {
-				case "":
-					obj.Status = states.ObjectReady
-				case "tainted":
-					obj.Status = states.ObjectTainted
-				default:
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Invalid resource instance metadata in state",
-						fmt.Sprintf("Instance %s has invalid status %q.", instAddr.Absolute(moduleAddr), raw),
-					))
-					continue
-				}
+		// Sensitive paths
+		if isV4.AttributeSensitivePaths != nil {
+			paths, pathsDiags := unmarshalPaths([]byte(isV4.AttributeSensitivePaths))
+			diags = diags.Append(pathsDiags)
+			if pathsDiags.HasErrors() {
+				continue
 			}
 
-			if raw := isV4.PrivateRaw; len(raw) > 0 {
-				obj.Private = raw
+			var pvm []cty.PathValueMarks
+			for _, path := range paths {
+				pvm = append(pvm, cty.PathValueMarks{
+					Path:  path,
+					Marks: cty.NewValueMarks(marks.Sensitive),
+				})
 			}
+			obj.AttrSensitivePaths = pvm
+		}
 
-			{
-				depsRaw := isV4.Dependencies
-				deps := make([]addrs.ConfigResource, 0, len(depsRaw))
-				for _, depRaw := range depsRaw {
-					addr, addrDiags := addrs.ParseAbsResourceStr(depRaw)
-					diags = diags.Append(addrDiags)
-					if addrDiags.HasErrors() {
-						continue
-					}
-					deps = append(deps, addr.Config())
-				}
-				obj.Dependencies = deps
+		{
+			// Status
+			raw := isV4.Status
+			switch raw {
+			case "":
+				obj.Status = states.ObjectReady
+			case "tainted":
+				obj.Status = states.ObjectTainted
+			default:
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid resource instance metadata in state",
+					fmt.Sprintf("Instance %s has invalid status %q.", instAddr.Absolute(moduleAddr), raw),
+				))
+				continue
 			}
+		}
 
-			switch {
-			case isV4.Deposed != "":
-				dk := states.DeposedKey(isV4.Deposed)
-				if len(dk) != 8 {
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Invalid resource instance metadata in state",
-						fmt.Sprintf("Instance %s has an object with deposed key %q, which is not correctly formatted.", instAddr.Absolute(moduleAddr), isV4.Deposed),
-					))
-					continue
-				}
-				is := ms.ResourceInstance(instAddr)
-				if is.HasDeposed(dk) {
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Duplicate resource instance in state",
-						fmt.Sprintf("Instance %s deposed object %q appears multiple times in the state file.", instAddr.Absolute(moduleAddr), dk),
-					))
-					continue
-				}
+		if raw := isV4.PrivateRaw; len(raw) > 0 {
+			obj.Private = raw
+		}
 
-				ms.SetResourceInstanceDeposed(instAddr, dk, obj, instanceProvider, instanceProviderKey)
-			default:
-				is := ms.ResourceInstance(instAddr)
-				if is.HasCurrent() {
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Duplicate resource instance in state",
-						fmt.Sprintf("Instance %s appears multiple times in the state file.", instAddr.Absolute(moduleAddr)),
-					))
+		{
+			depsRaw := isV4.Dependencies
+			deps := make([]addrs.ConfigResource, 0, len(depsRaw))
+			for _, depRaw := range depsRaw {
+				addr, addrDiags := addrs.ParseAbsResourceStr(depRaw)
+				diags = diags.Append(addrDiags)
+				if addrDiags.HasErrors() {
 					continue
 				}
-
-				ms.SetResourceInstanceCurrent(instAddr, obj, instanceProvider, instanceProviderKey)
+				deps = append(deps, addr.Config())
 			}
+			obj.Dependencies = deps
 		}
 
-		// Validate instance providers
-		for i := 1; i < len(instanceProviders); i++ {
-			if instanceProviders[i-1].String() != instanceProviders[i].String() {
+		switch {
+		case isV4.Deposed != "":
+			dk := states.DeposedKey(isV4.Deposed)
+			if len(dk) != 8 {
 				diags = diags.Append(tfdiags.Sourceless(
 					tfdiags.Error,
-					"Provider instance field conflict in state",
-					fmt.Sprintf("Resource %s has instances with different provider addresses: %q != %q.", rAddr.Absolute(moduleAddr), instanceProviders[i-1], instanceProviders[i]),
+					"Invalid resource instance metadata in state",
+					fmt.Sprintf("Instance %s has an object with deposed key %q, which is not correctly formatted.", instAddr.Absolute(moduleAddr), isV4.Deposed),
 				))
-				break
-			}
-		}
-	}
-
-	// The root module is special in that we persist its attributes and thus
-	// need to reload them now. (For descendent modules we just re-calculate
-	// them based on the latest configuration on each run.)
-	{
-		rootModule := state.RootModule()
-		for name, fos := range sV4.RootOutputs {
-			os := &states.OutputValue{
-				Addr: addrs.AbsOutputValue{
-					OutputValue: addrs.OutputValue{
-						Name: name,
-					},
-				},
+				continue
 			}
-			os.Sensitive = fos.Sensitive
-
-			os.Deprecated = fos.Deprecated
-
-			ty, err := ctyjson.UnmarshalType([]byte(fos.ValueTypeRaw))
-			if err != nil {
+			is := ms.ResourceInstance(instAddr)
+			if is.HasDeposed(dk) {
 				diags = diags.Append(tfdiags.Sourceless(
 					tfdiags.Error,
-					"Invalid output value type in state",
-					fmt.Sprintf("The state file has an invalid type specification for output %q: %s.", name, err),
+					"Duplicate resource instance in state",
+					fmt.Sprintf("Instance %s deposed object %q appears multiple times in the state file.", instAddr.Absolute(moduleAddr), dk),
 				))
 				continue
 			}
 
-			val, err := ctyjson.Unmarshal([]byte(fos.ValueRaw), ty)
-			if err != nil {
+			ms.SetResourceInstanceDeposed(instAddr, dk, obj, instanceProvider, instanceProviderKey)
+		default:
+			is := ms.ResourceInstance(instAddr)
+			if is.HasCurrent() {
 				diags = diags.Append(tfdiags.Sourceless(
 					tfdiags.Error,
-					"Invalid output value saved in state",
-					fmt.Sprintf("The state file has an invalid value for output %q: %s.", name, err),
+					"Duplicate resource instance in state",
+					fmt.Sprintf("Instance %s appears multiple times in the state file.", instAddr.Absolute(moduleAddr)),
 				))
 				continue
 			}
 
-			os.Value = val
-			rootModule.OutputValues[name] = os
+			ms.SetResourceInstanceCurrent(instAddr, obj, instanceProvider, instanceProviderKey)
 		}
 	}
 
-	// Saved check results from the previous run, if any.
-	// We differentiate absence from an empty array here so that we can
-	// recognize if the previous run was with a version of Farseek that
-	// didn't support checks yet, or if there just weren't any checkable
-	// objects to record, in case that's important for certain messaging.
-	if sV4.CheckResults != nil {
-		var moreDiags tfdiags.Diagnostics
-		state.CheckResults, moreDiags = decodeCheckResultsV4(sV4.CheckResults)
-		diags = diags.Append(moreDiags)
+	// Validate instance providers
+	for i := 1; i < len(instanceProviders); i++ {
+		if instanceProviders[i-1].String() != instanceProviders[i].String() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Provider instance field conflict in state",
+				fmt.Sprintf("Resource %s has instances with different provider addresses: %q != %q.", rAddr.Absolute(moduleAddr), instanceProviders[i-1], instanceProviders[i]),
+			))
+			break
+		}
 	}
 
-	file.State = state
-	return file, diags
+	return diags
+}
+
+// prepareRootOutputsV4 adds the given root module output values to the
+// given state.
+func prepareRootOutputsV4(state *states.State, outputs map[string]outputStateV4) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	rootModule := state.RootModule()
+	for name, fos := range outputs {
+		os := &states.OutputValue{
+			Addr: addrs.AbsOutputValue{
+				OutputValue: addrs.OutputValue{
+					Name: name,
+				},
+			},
+		}
+		os.Sensitive = fos.Sensitive
+
+		os.Deprecated = fos.Deprecated
+
+		ty, err := ctyjson.UnmarshalType([]byte(fos.ValueTypeRaw))
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid output value type in state",
+				fmt.Sprintf("The state file has an invalid type specification for output %q: %s.", name, err),
+			))
+			continue
+		}
+
+		val, err := ctyjson.Unmarshal([]byte(fos.ValueRaw), ty)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid output value saved in state",
+				fmt.Sprintf("The state file has an invalid value for output %q: %s.", name, err),
+			))
+			continue
+		}
+
+		os.Value = val
+		rootModule.OutputValues[name] = os
+	}
+	return diags
 }
 
 func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdiags.Diagnostics {
@@ -391,7 +408,59 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 		Resources:        []resourceStateV4{},
 	}
 
-	for name, os := range file.State.RootModule().OutputValues {
+	rootOutputs, outputDiags := rootOutputsV4(file.State)
+	diags = diags.Append(outputDiags)
+	sV4.RootOutputs = rootOutputs
+
+	for _, ms := range file.State.Modules {
+		for _, rs := range ms.Resources {
+			rsV4, rsDiags := resourceV4(ms.Addr, rs)
+			diags = diags.Append(rsDiags)
+			if rsV4 != nil {
+				sV4.Resources = append(sV4.Resources, *rsV4)
+			}
+		}
+	}
+
+	sV4.CheckResults = encodeCheckResultsV4(file.State.CheckResults)
+
+	sV4.normalize()
+
+	src, err := json.Marshal(sV4)
+	if err != nil {
+		// Shouldn't happen if we do our conversion to *stateV4 correctly above.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to serialize state",
+			fmt.Sprintf("An error occurred while serializing the state to save it. This is a bug in Farseek and should be reported: %s.", err),
+		))
+		return diags
+	}
+	src = append(src, '\n')
+
+	encrypted, encDiags := enc.EncryptState(src)
+	diags = diags.Append(encDiags)
+
+	_, err = w.Write(encrypted)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to write state",
+			fmt.Sprintf("An error occurred while writing the serialized state: %s.", err),
+		))
+		return diags
+	}
+
+	return diags
+}
+
+// rootOutputsV4 returns the serialized form of the root module output values
+// of the given state.
+func rootOutputsV4(state *states.State) (map[string]outputStateV4, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	ret := map[string]outputStateV4{}
+
+	for name, os := range state.RootModule().OutputValues {
 		src, err := ctyjson.Marshal(os.Value, os.Value.Type())
 		if err != nil {
 			diags = diags.Append(tfdiags.Sourceless(
@@ -412,7 +481,7 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 			continue
 		}
 
-		sV4.RootOutputs[name] = outputStateV4{
+		ret[name] = outputStateV4{
 			Sensitive:    os.Sensitive,
 			Deprecated:   os.Deprecated,
 			ValueRaw:     json.RawMessage(src),
@@ -420,105 +489,78 @@ func writeStateV4(file *File, w io.Writer, enc encryption.StateEncryption) tfdia
 		}
 	}
 
-	for _, ms := range file.State.Modules {
-		moduleAddr := ms.Addr
-		for _, rs := range ms.Resources {
-			resourceAddr := rs.Addr.Resource
-
-			var mode string
-			switch resourceAddr.Mode {
-			case addrs.ManagedResourceMode:
-				mode = "managed"
-			case addrs.DataResourceMode:
-				mode = "data"
-			case addrs.EphemeralResourceMode:
-				// Ephemeral resources are the resources that are meant not to be written to the state file.
-				// Therefore, even though we can find those in the state (for evaluation reasons), we want to
-				// skip these from the state file.
-				continue
-			default:
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Failed to serialize resource in state",
-					fmt.Sprintf("Resource %s has mode %s, which cannot be serialized in state", resourceAddr.Absolute(moduleAddr), resourceAddr.Mode),
-				))
-				continue
-			}
+	return ret, diags
+}
 
-			hasProviderInstanceKeys := false
-			for _, is := range rs.Instances {
-				if is.ProviderKey != addrs.NoKey {
-					hasProviderInstanceKeys = true
-					break
-				}
-			}
+// resourceV4 returns the serialized form of the given resource, or nil if
+// the resource must not be saved in state at all.
+func resourceV4(moduleAddr addrs.ModuleInstance, rs *states.Resource) (*resourceStateV4, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
 
-			var providerInstance string
-			if !hasProviderInstanceKeys {
-				providerInstance = rs.ProviderConfig.String()
-			}
+	resourceAddr := rs.Addr.Resource
+
+	var mode string
+	switch resourceAddr.Mode {
+	case addrs.ManagedResourceMode:
+		mode = "managed"
+	case addrs.DataResourceMode:
+		mode = "data"
+	case addrs.EphemeralResourceMode:
+		// Ephemeral resources are the resources that are meant not to be written to the state file.
+		// Therefore, even though we can find those in the state (for evaluation reasons), we want to
+		// skip these from the state file.
+		return nil, diags
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to serialize resource in state",
+			fmt.Sprintf("Resource %s has mode %s, which cannot be serialized in state", resourceAddr.Absolute(moduleAddr), resourceAddr.Mode),
+		))
+		return nil, diags
+	}
 
-			sV4.Resources = append(sV4.Resources, resourceStateV4{
-				Module:           moduleAddr.String(),
-				Mode:             mode,
-				Type:             resourceAddr.Type,
-				Name:             resourceAddr.Name,
-				ProviderInstance: providerInstance,
-				Instances:        []instanceObjectStateV4{},
-			})
-			rsV4 := &(sV4.Resources[len(sV4.Resources)-1])
-
-			for key, is := range rs.Instances {
-				if is.HasCurrent() {
-					var objDiags tfdiags.Diagnostics
-					rsV4.Instances, objDiags = appendInstanceObjectStateV4(
-						rs, is, key, is.Current, states.NotDeposed,
-						rsV4.Instances, hasProviderInstanceKeys,
-					)
-					diags = diags.Append(objDiags)
-				}
-				for dk, obj := range is.Deposed {
-					var objDiags tfdiags.Diagnostics
-					rsV4.Instances, objDiags = appendInstanceObjectStateV4(
-						rs, is, key, obj, dk,
-						rsV4.Instances, hasProviderInstanceKeys,
-					)
-					diags = diags.Append(objDiags)
-				}
-			}
+	hasProviderInstanceKeys := false
+	for _, is := range rs.Instances {
+		if is.ProviderKey != addrs.NoKey {
+			hasProviderInstanceKeys = true
+			break
 		}
 	}
 
-	sV4.CheckResults = encodeCheckResultsV4(file.State.CheckResults)
-
-	sV4.normalize()
-
-	src, err := json.Marshal(sV4)
-	if err != nil {
-		// Shouldn't happen if we do our conversion to *stateV4 correctly above.
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Failed to serialize state",
-			fmt.Sprintf("An error occurred while serializing the state to save it. This is a bug in Farseek and should be reported: %s.", err),
-		))
-		return diags
+	var providerInstance string
+	if !hasProviderInstanceKeys {
+		providerInstance = rs.ProviderConfig.String()
 	}
-	src = append(src, '\n')
 
-	encrypted, encDiags := enc.EncryptState(src)
-	diags = diags.Append(encDiags)
+	rsV4 := &resourceStateV4{
+		Module:           moduleAddr.String(),
+		Mode:             mode,
+		Type:             resourceAddr.Type,
+		Name:             resourceAddr.Name,
+		ProviderInstance: providerInstance,
+		Instances:        []instanceObjectStateV4{},
+	}
 
-	_, err = w.Write(encrypted)
-	if err != nil {
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Failed to write state",
-			fmt.Sprintf("An error occurred while writing the serialized state: %s.", err),
-		))
-		return diags
+	for key, is := range rs.Instances {
+		if is.HasCurrent() {
+			var objDiags tfdiags.Diagnostics
+			rsV4.Instances, objDiags = appendInstanceObjectStateV4(
+				rs, is, key, is.Current, states.NotDeposed,
+				rsV4.Instances, hasProviderInstanceKeys,
+			)
+			diags = diags.Append(objDiags)
+		}
+		for dk, obj := range is.Deposed {
+			var objDiags tfdiags.Diagnostics
+			rsV4.Instances, objDiags = appendInstanceObjectStateV4(
+				rs, is, key, obj, dk,
+				rsV4.Instances, hasProviderInstanceKeys,
+			)
+			diags = diags.Append(objDiags)
+		}
 	}
 
-	return diags
+	return rsV4, diags
 }
 
 func appendInstanceObjectStateV4(rs *states.Resource, is *states.ResourceInstance, key addrs.InstanceKey, obj *states.ResourceInstanceObjectSrc, deposed states.DeposedKey, isV4s []instanceObjectStateV4, hasProviderInstanceKeys bool) ([]instanceObjectStateV4, tfdiags.Diagnostics) {
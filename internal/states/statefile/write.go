@@ -8,9 +8,11 @@
 package statefile
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/states"
 	tfversion "github.com/rafagsiqueira/farseek/version"
 )
 
@@ -20,10 +22,34 @@ func Write(s *File, w io.Writer, enc encryption.StateEncryption) error {
 	// Always record the current farseek version in the state.
 	s.TerraformVersion = tfversion.SemVer
 
+	if s.State != nil && resourceCount(s.State) >= StreamResourceThreshold {
+		// Encryption needs the whole document, but streaming it into a buffer
+		// still avoids holding a second, intermediate copy of every resource.
+		var buf bytes.Buffer
+		diags := writeStateV4Stream(s, &buf)
+		if diags.HasErrors() {
+			return diags.Err()
+		}
+		encrypted, err := enc.EncryptState(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(encrypted)
+		return err
+	}
+
 	diags := writeStateV4(s, w, enc)
 	return diags.Err()
 }
 
+func resourceCount(state *states.State) int {
+	count := 0
+	for _, ms := range state.Modules {
+		count += len(ms.Resources)
+	}
+	return count
+}
+
 // WriteForTest writes the given state to the given writer in the current state
 // serialization format without recording the current farseek version. This is
 // intended for use in tests that need to override the current farseek
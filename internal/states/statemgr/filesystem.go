@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -66,6 +67,10 @@ type Filesystem struct {
 	backupFile     *statefile.File
 	writtenBackup  bool
 
+	// wroteSnapshot is set once persistState has written a full snapshot to
+	// path, which PersistDelta needs for its deltas to apply to.
+	wroteSnapshot bool
+
 	encryption encryption.StateEncryption
 }
 
@@ -73,6 +78,7 @@ var (
 	_ Full           = (*Filesystem)(nil)
 	_ PersistentMeta = (*Filesystem)(nil)
 	_ Migrator       = (*Filesystem)(nil)
+	_ DeltaPersister = (*Filesystem)(nil)
 )
 
 // NewFilesystem creates a filesystem-based state manager that reads and writes
@@ -274,8 +280,15 @@ func (s *Filesystem) persistState(schemas *farseek.Schemas) error {
 		return err
 	}
 
+	// Any deltas persisted before are part of the snapshot we've just
+	// written, so their journal is now redundant.
+	if err := s.removeDeltas(); err != nil {
+		return fmt.Errorf("failed to remove state delta journal: %w", err)
+	}
+
 	// Any future reads must come from the file we've now updated
 	s.readPath = s.path
+	s.wroteSnapshot = true
 	return nil
 }
 
@@ -353,6 +366,13 @@ func (s *Filesystem) refreshState() error {
 
 	s.file = f
 	s.readFile = s.file.DeepCopy()
+	if s.file != nil && s.file.State != nil {
+		// The deltas persisted since the snapshot was written are applied to
+		// s.file only, so that the next full snapshot gets a new serial.
+		if err := s.applyDeltas(s.readPath, s.file); err != nil {
+			return err
+		}
+	}
 	if s.file != nil {
 		log.Printf("[TRACE] statemgr.Filesystem: read snapshot with lineage %q serial %d", s.file.Lineage, s.file.Serial)
 	} else {
@@ -451,6 +471,51 @@ func (s *Filesystem) Unlock(_ context.Context, id string) error {
 	return unlockErr
 }
 
+// LockHolder implements LockInspector.
+//
+// The lock is a discretionary lock on the state file, so LockHolder asks the
+// operating system whether it's held. The lock info file alone isn't enough,
+// since a process that exits without unlocking leaves it behind, so it's
+// only relied on where the lock can't be inspected.
+func (s *Filesystem) LockHolder(_ context.Context) (*LockInfo, error) {
+	defer s.mutex()()
+
+	if s.lockID != "" {
+		return s.lockInfo()
+	}
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		// There's no state file to lock yet.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	locked, err := flock.Locked(f)
+	switch {
+	case errors.Is(err, errors.ErrUnsupported):
+		info, err := s.lockInfo()
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return info, err
+	case err != nil:
+		return nil, err
+	case !locked:
+		return nil, nil
+	}
+
+	info, err := s.lockInfo()
+	if os.IsNotExist(err) {
+		// The lock holder hasn't written its lock info yet.
+		return &LockInfo{Path: s.readPath}, nil
+	}
+	return info, err
+}
+
 // StateSnapshotMeta returns the metadata from the most recently persisted
 // or refreshed persistent state snapshot.
 //
@@ -0,0 +1,169 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package statemgr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/states/statefile"
+)
+
+// DeltaFileSuffix is appended to the path of a state file to get the path of
+// the journal of the deltas persisted since the last full snapshot.
+const DeltaFileSuffix = ".delta"
+
+// filesystemDelta is a line of the journal of deltas next to a state file.
+type filesystemDelta struct {
+	// Lineage and Serial are those of the full snapshot that the delta
+	// applies to. Deltas for any other snapshot are stale and ignored.
+	Lineage string `json:"lineage"`
+	Serial  uint64 `json:"serial"`
+
+	// Resources are the addresses of the resources that the delta replaces,
+	// and State is a state file with their new state and the root module
+	// output values. A resource that isn't in State was removed.
+	Resources []string `json:"resources"`
+	State     []byte   `json:"state"`
+}
+
+// PersistDelta is an implementation of DeltaPersister, which appends the
+// delta to a journal next to the state file. The journal is applied when the
+// state file is read again, and removed when PersistState writes a full
+// snapshot.
+func (s *Filesystem) PersistDelta(_ context.Context, changed []addrs.AbsResourceInstance, schemas *farseek.Schemas) error {
+	defer s.mutex()()
+
+	hasSnapshot := s.wroteSnapshot || (s.readPath == s.path && s.readFile != nil)
+	if s.stateFileOut == nil || !hasSnapshot || s.file == nil || s.file.State == nil {
+		// There's no full snapshot at our path for a delta to apply to.
+		return s.persistState(schemas)
+	}
+
+	delta := filesystemDelta{
+		Lineage: s.file.Lineage,
+		Serial:  s.file.Serial,
+	}
+	partial := states.NewState()
+	seen := make(map[string]bool)
+	for _, addr := range changed {
+		resourceAddr := addr.ContainingResource()
+		key := resourceAddr.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		delta.Resources = append(delta.Resources, key)
+
+		if rs := s.file.State.Resource(resourceAddr); rs != nil {
+			partial.EnsureModule(resourceAddr.Module).Resources[resourceAddr.Resource.String()] = rs.DeepCopy()
+		}
+	}
+	for name, ov := range s.file.State.RootModule().OutputValues {
+		partial.RootModule().OutputValues[name] = ov.DeepCopy()
+	}
+
+	var buf bytes.Buffer
+	partialFile := &statefile.File{
+		Lineage:          s.file.Lineage,
+		Serial:           s.file.Serial,
+		TerraformVersion: s.file.TerraformVersion,
+		State:            partial,
+	}
+	if err := statefile.Write(partialFile, &buf, s.encryption); err != nil {
+		return fmt.Errorf("failed to encode state delta: %w", err)
+	}
+	delta.State = buf.Bytes()
+
+	line, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("failed to encode state delta: %w", err)
+	}
+	f, err := os.OpenFile(s.path+DeltaFileSuffix, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open state delta journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write state delta journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		log.Printf("[ERROR] Unable to sync state delta journal %s: %s", f.Name(), err)
+	}
+	log.Printf("[TRACE] statemgr.Filesystem: persisted a delta of %d resources to %s", len(delta.Resources), f.Name())
+	return nil
+}
+
+// applyDeltas applies the journal of deltas next to the state file at the
+// given path to the snapshot read from it, so that the changes persisted by
+// PersistDelta since the last full snapshot aren't lost.
+func (s *Filesystem) applyDeltas(path string, file *statefile.File) error {
+	f, err := os.Open(path + DeltaFileSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<30)
+	applied := 0
+	for scanner.Scan() {
+		var delta filesystemDelta
+		if err := json.Unmarshal(scanner.Bytes(), &delta); err != nil {
+			// A delta that's cut short was being written when Farseek was
+			// stopped, and is the last one, so we keep what came before.
+			log.Printf("[WARN] statemgr.Filesystem: ignoring an incomplete delta in %s: %s", f.Name(), err)
+			break
+		}
+		if delta.Lineage != file.Lineage || delta.Serial != file.Serial {
+			continue
+		}
+		partial, err := statefile.Read(bytes.NewReader(delta.State), s.encryption)
+		if err != nil {
+			return fmt.Errorf("invalid state delta in %s: %w", f.Name(), err)
+		}
+
+		for _, raw := range delta.Resources {
+			addr, diags := addrs.ParseAbsResourceStr(raw)
+			if diags.HasErrors() {
+				return fmt.Errorf("invalid resource address %q in state delta in %s: %w", raw, f.Name(), diags.Err())
+			}
+			if ms := file.State.Module(addr.Module); ms != nil {
+				ms.RemoveResource(addr.Resource)
+			}
+			if rs := partial.State.Resource(addr); rs != nil {
+				file.State.EnsureModule(addr.Module).Resources[addr.Resource.String()] = rs
+			}
+		}
+		file.State.RootModule().OutputValues = partial.State.RootModule().OutputValues
+		applied++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read state delta journal: %w", err)
+	}
+	if applied > 0 {
+		log.Printf("[TRACE] statemgr.Filesystem: applied %d deltas from %s", applied, f.Name())
+	}
+	return nil
+}
+
+// removeDeltas removes the journal of deltas once a full snapshot makes it
+// redundant.
+func (s *Filesystem) removeDeltas() error {
+	err := os.Remove(s.path + DeltaFileSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
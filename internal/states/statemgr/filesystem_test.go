@@ -307,6 +307,85 @@ func TestFilesystem_backupAndReadPath(t *testing.T) {
 	})
 }
 
+func TestFilesystem_lockHolder(t *testing.T) {
+	s := testFilesystem(t)
+	defer os.Remove(s.readPath)
+
+	holder, err := s.LockHolder(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder != nil {
+		t.Fatalf("unlocked state has lock holder %#v", holder)
+	}
+
+	info := NewLockInfo()
+	info.Operation = "test"
+	lockID, err := s.Lock(t.Context(), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder, err = s.LockHolder(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder == nil || holder.ID != lockID || holder.Operation != "test" {
+		t.Fatalf("wrong lock holder %#v", holder)
+	}
+
+	// Another process finds the lock holder without taking the lock.
+	out, err := exec.Command("go", "run", "testdata/lockholder.go", s.path).CombinedOutput()
+	if err != nil {
+		t.Fatal("unexpected failure", err, string(out))
+	}
+	if got, want := string(out), "test"; got != want {
+		t.Fatalf("wrong lock holder operation from another process %q; want %q", got, want)
+	}
+	if _, err := s.lockInfo(); err != nil {
+		t.Fatalf("lock info is gone after inspecting the lock: %s", err)
+	}
+
+	// A lock info file left behind by a process that didn't unlock the
+	// state doesn't mean that the state is locked.
+	infoPath := s.lockInfoPath()
+	infoData, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unlock(t.Context(), lockID); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(infoPath, infoData, 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(infoPath)
+	holder, err = s.LockHolder(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder != nil {
+		t.Fatalf("unlocked state with stale lock info has lock holder %#v", holder)
+	}
+	out, err = exec.Command("go", "run", "testdata/lockholder.go", s.path).CombinedOutput()
+	if err != nil {
+		t.Fatal("unexpected failure", err, string(out))
+	}
+	if got, want := string(out), "unlocked"; got != want {
+		t.Fatalf("wrong lock holder operation from another process %q; want %q", got, want)
+	}
+}
+
+func TestFilesystem_lockHolderNonExist(t *testing.T) {
+	s := NewFilesystem(filepath.Join(t.TempDir(), "farseek.tfstate"), encryption.StateEncryptionDisabled())
+	holder, err := s.LockHolder(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder != nil {
+		t.Fatalf("nonexistent state has lock holder %#v", holder)
+	}
+}
+
 func TestFilesystem_nonExist(t *testing.T) {
 	defer testOverrideVersion(t, "1.2.3")()
 	ls := NewFilesystem("ishouldntexist", encryption.StateEncryptionDisabled())
@@ -475,3 +554,75 @@ func testOverrideVersion(t *testing.T, v string) func() {
 		tfversion.SemVer = oldSemVer
 	}
 }
+
+func TestFilesystem_deltas(t *testing.T) {
+	defer testOverrideVersion(t, "1.2.3")()
+	ls := testFilesystem(t)
+	defer os.Remove(ls.path)
+	defer os.Remove(ls.path + DeltaFileSuffix)
+
+	lockID, err := ls.Lock(t.Context(), NewLockInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ls.Unlock(t.Context(), lockID); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	created := addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "null_resource", "bar", addrs.NoKey)
+	removed := addrs.RootModuleInstance.Child("child", addrs.NoKey).ResourceInstance(addrs.ManagedResourceMode, "null_resource", "foo", addrs.NoKey)
+	err = ls.MutateState(func(state *states.State) *states.State {
+		state.RootModule().SetResourceInstanceCurrent(
+			created.Resource,
+			&states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(`{"id":"bar"}`),
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("null"),
+				Module:   addrs.RootModule,
+			},
+			addrs.NoKey,
+		)
+		state.Module(removed.Module).RemoveResource(removed.Resource.Resource)
+		return state
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ls.PersistDelta(t.Context(), []addrs.AbsResourceInstance{created, removed}, nil); err != nil {
+		t.Fatalf("failed to persist delta: %s", err)
+	}
+
+	checkState := func(t *testing.T) {
+		t.Helper()
+		reader := NewFilesystem(ls.path, encryption.StateEncryptionDisabled())
+		if err := reader.RefreshState(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		state := reader.State()
+		if state.ResourceInstance(created) == nil {
+			t.Errorf("%s is missing from the state", created)
+		}
+		if state.Resource(removed.ContainingResource()) != nil {
+			t.Errorf("%s is still in the state", removed.ContainingResource())
+		}
+		if got := len(state.RootModule().OutputValues); got != 2 {
+			t.Errorf("wrong number of output values %d; want 2", got)
+		}
+	}
+
+	// The snapshot itself is unchanged, but reading it applies the delta.
+	checkState(t)
+
+	// A full snapshot makes the journal of deltas redundant.
+	if err := ls.PersistState(t.Context(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ls.path + DeltaFileSuffix); !os.IsNotExist(err) {
+		t.Errorf("the journal of deltas still exists after a full snapshot")
+	}
+	checkState(t)
+}
@@ -78,6 +78,21 @@ type OptionalLocker interface {
 	IsLockingEnabled() bool
 }
 
+// LockInspector is implemented by state managers that can report who holds
+// their lock without trying to take it.
+//
+// Only Filesystem, the state manager of the local backend, implements it.
+// Callers must handle state managers that don't, which can't report the
+// status of their lock.
+type LockInspector interface {
+	// LockHolder returns the information about the lock that is currently
+	// held on the state, or nil if the state isn't locked.
+	//
+	// The returned information may be incomplete if the lock holder didn't
+	// record it, but LockHolder never returns nil for a locked state.
+	LockHolder(ctx context.Context) (*LockInfo, error)
+}
+
 // test hook to verify that LockWithContext has attempted a lock
 var postLockHook func()
 
@@ -12,6 +12,7 @@ import (
 
 	version "github.com/hashicorp/go-version"
 
+	"github.com/rafagsiqueira/farseek/internal/addrs"
 	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
 	"github.com/rafagsiqueira/farseek/internal/states"
 )
@@ -90,6 +91,30 @@ type Persister interface {
 	PersistState(context.Context, *farseek.Schemas) error
 }
 
+// DeltaPersister is an optional extension of Persister for managers that can
+// persist the changes to some resource instances without writing the whole
+// state, which is much cheaper for large states that are persisted many
+// times during a long apply.
+//
+// A delta is relative to the latest snapshot persisted by PersistState, and
+// to the deltas persisted since. RefreshState must return the snapshot with
+// its deltas applied, and PersistState starts again from a full snapshot.
+// Callers should still call PersistState from time to time, and always at
+// the end of an operation, so that deltas don't accumulate without bound.
+type DeltaPersister interface {
+	Persister
+
+	// PersistDelta persists the resources containing the given resource
+	// instances, and the root module output values, as they are in the
+	// latest transient snapshot. A resource that's no longer in the
+	// transient snapshot is persisted as removed.
+	//
+	// An implementation that can't persist a delta, such as because there's
+	// no full snapshot to apply it to yet, may persist a full snapshot
+	// instead.
+	PersistDelta(ctx context.Context, changed []addrs.AbsResourceInstance, schemas *farseek.Schemas) error
+}
+
 // PersistentMeta is an optional extension to Persistent that allows inspecting
 // the metadata associated with the snapshot that was most recently either
 // read by RefreshState or written by PersistState.
@@ -0,0 +1,34 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/rafagsiqueira/farseek/internal/encryption"
+	"github.com/rafagsiqueira/farseek/internal/states/statemgr"
+)
+
+// Report who holds the lock on a farseek state file, writing the operation
+// of the lock holder to stdout, or "unlocked" if there is none.
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal(os.Args[0], "statefile")
+	}
+
+	s := statemgr.NewFilesystem(os.Args[1], encryption.StateEncryptionDisabled())
+
+	holder, err := s.LockHolder(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if holder == nil {
+		fmt.Print("unlocked")
+		return
+	}
+	fmt.Print(holder.Operation)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package tagpolicy checks that the resources a plan creates or updates have
+// the tags, or labels, that a policy file requires of their resource types.
+package tagpolicy
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/states"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+// DefaultAttribute is the attribute that rules check when they don't name
+// one.
+const DefaultAttribute = "tags"
+
+// Policy is a set of rules, each requiring some tags of the resource types
+// whose names start with a prefix.
+type Policy struct {
+	Rules []*Rule `hcl:"rule,block"`
+}
+
+// Rule is a single "rule" block of a policy file.
+type Rule struct {
+	// Prefix selects the resource types the rule applies to, such as
+	// "aws_" for all the resource types of the AWS provider, or
+	// "aws_s3_bucket" for just one of them.
+	Prefix string `hcl:"prefix,label"`
+
+	// Exclude are prefixes of resource types the rule doesn't apply to,
+	// even though they start with Prefix.
+	Exclude []string `hcl:"exclude,optional"`
+
+	// Attribute is the map or object attribute holding the tags, which
+	// defaults to "tags". Resource types without it aren't checked.
+	Attribute string `hcl:"attribute,optional"`
+
+	// Required are the keys that must be present, with a value that isn't
+	// empty.
+	Required []string `hcl:"required"`
+
+	// Severity is either "error", the default, which fails the plan, or
+	// "warning".
+	Severity string `hcl:"severity,optional"`
+
+	DeclRange hcl.Range
+}
+
+// Load reads and validates the policy file at the given path.
+func Load(path string) (*Policy, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	f, hclDiags := hclparse.NewParser().ParseHCLFile(path)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+	policy := &Policy{}
+	hclDiags = gohcl.DecodeBody(f.Body, nil, policy)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	// gohcl doesn't record the ranges of blocks, so we find them again.
+	content, _ := f.Body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "rule", LabelNames: []string{"prefix"}}},
+	})
+	for i, rule := range policy.Rules {
+		if i < len(content.Blocks) {
+			rule.DeclRange = content.Blocks[i].DefRange
+		}
+		if rule.Attribute == "" {
+			rule.Attribute = DefaultAttribute
+		}
+		switch rule.Severity {
+		case "":
+			rule.Severity = "error"
+		case "error", "warning":
+		default:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid tag policy rule severity",
+				Detail:   fmt.Sprintf("The severity of a rule must be either \"error\" or \"warning\", not %q.", rule.Severity),
+				Subject:  rule.DeclRange.Ptr(),
+			})
+		}
+		if len(rule.Required) == 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Tag policy rule requires no tags",
+				Detail:   fmt.Sprintf("The rule for resource types starting with %q must list at least one required tag.", rule.Prefix),
+				Subject:  rule.DeclRange.Ptr(),
+			})
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return policy, diags
+}
+
+// applies returns true if the rule applies to the given resource type.
+func (r *Rule) applies(resourceType string) bool {
+	if !strings.HasPrefix(resourceType, r.Prefix) {
+		return false
+	}
+	for _, exclude := range r.Exclude {
+		if strings.HasPrefix(resourceType, exclude) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns a diagnostic for each managed resource instance that the
+// plan creates or updates without the tags that the rules applying to its
+// resource type require. The diagnostics of rules with the "error" severity
+// are errors, and the plan must not be applied.
+//
+// Tags whose values won't be known until apply can't be checked, and are
+// taken to be present.
+func (p *Policy) Check(plan *plans.Plan, schemas *farseek.Schemas, config *configs.Config) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if p == nil || plan == nil || plan.Changes == nil {
+		return diags
+	}
+
+	for _, rc := range plan.Changes.Resources {
+		addr := rc.Addr
+		if addr.Resource.Resource.Mode != addrs.ManagedResourceMode || rc.DeposedKey != states.NotDeposed {
+			continue
+		}
+		switch rc.Action {
+		case plans.Create, plans.Update, plans.DeleteThenCreate, plans.CreateThenDelete:
+		default:
+			continue
+		}
+
+		var rules []*Rule
+		for _, rule := range p.Rules {
+			if rule.applies(addr.Resource.Resource.Type) {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		schema, _ := schemas.ResourceTypeConfig(rc.ProviderAddr.Provider, addrs.ManagedResourceMode, addr.Resource.Resource.Type)
+		if schema == nil {
+			log.Printf("[WARN] tagpolicy: no schema for %s, so its tags can't be checked", addr)
+			continue
+		}
+		change, err := rc.Decode(schema.ImpliedType())
+		if err != nil {
+			log.Printf("[WARN] tagpolicy: failed to decode the planned change of %s: %s", addr, err)
+			continue
+		}
+
+		for _, rule := range rules {
+			missing, ok := missingTags(change.After, rule)
+			if !ok || len(missing) == 0 {
+				continue
+			}
+			severity := hcl.DiagError
+			if rule.Severity == "warning" {
+				severity = hcl.DiagWarning
+			}
+			var diag tfdiags.Diagnostics
+			diag = diag.Append(&hcl.Diagnostic{
+				Severity: severity,
+				Summary:  "Missing required tags",
+				Detail: fmt.Sprintf(
+					"%s is missing %s %s, which the tag policy requires in %q of all resource types starting with %q.",
+					addr, plural(len(missing), "the tag", "the tags"), strings.Join(missing, ", "), rule.Attribute, rule.Prefix,
+				),
+				Subject: subject(config, addr, rule.Attribute),
+			})
+			diags = diags.Append(tfdiags.WithCode(diag[0], tfdiags.CodeTagPolicyViolation))
+		}
+	}
+	return diags
+}
+
+// missingTags returns the tags the rule requires that the given object
+// doesn't have, sorted. It returns false if it can't tell, because the object
+// has no attribute for the tags, or their keys aren't known yet.
+func missingTags(obj cty.Value, rule *Rule) ([]string, bool) {
+	obj, _ = obj.Unmark()
+	if obj.IsNull() || !obj.IsKnown() || !obj.Type().IsObjectType() || !obj.Type().HasAttribute(rule.Attribute) {
+		return nil, false
+	}
+	tags, _ := obj.GetAttr(rule.Attribute).Unmark()
+	if !tags.IsKnown() {
+		return nil, false
+	}
+	ty := tags.Type()
+	if !ty.IsMapType() && !ty.IsObjectType() {
+		return nil, false
+	}
+
+	var missing []string
+	for _, key := range rule.Required {
+		if tags.IsNull() {
+			missing = append(missing, key)
+			continue
+		}
+		if ty.IsObjectType() && !ty.HasAttribute(key) {
+			missing = append(missing, key)
+			continue
+		}
+		if ty.IsMapType() && !tags.HasIndex(cty.StringVal(key)).True() {
+			missing = append(missing, key)
+			continue
+		}
+		var v cty.Value
+		if ty.IsObjectType() {
+			v = tags.GetAttr(key)
+		} else {
+			v = tags.Index(cty.StringVal(key))
+		}
+		v, _ = v.Unmark()
+		if !v.IsKnown() {
+			continue
+		}
+		if v.IsNull() || (v.Type() == cty.String && v.AsString() == "") {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing, true
+}
+
+// subject returns the range of the tags attribute in the configuration of
+// the resource, or else of the resource block, for diagnostics about it.
+func subject(config *configs.Config, addr addrs.AbsResourceInstance, attribute string) *hcl.Range {
+	if config == nil {
+		return nil
+	}
+	modCfg := config.DescendentForInstance(addr.Module)
+	if modCfg == nil {
+		return nil
+	}
+	rc := modCfg.Module.ResourceByAddr(addr.Resource.Resource)
+	if rc == nil {
+		return nil
+	}
+	if rc.Config != nil {
+		content, _, _ := rc.Config.PartialContent(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{{Name: attribute}},
+		})
+		if attr, ok := content.Attributes[attribute]; ok {
+			return attr.Range.Ptr()
+		}
+	}
+	return rc.DeclRange.Ptr()
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,153 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tagpolicy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/rafagsiqueira/farseek/internal/addrs"
+	"github.com/rafagsiqueira/farseek/internal/configs/configschema"
+	farseek "github.com/rafagsiqueira/farseek/internal/farseek"
+	"github.com/rafagsiqueira/farseek/internal/initwd"
+	"github.com/rafagsiqueira/farseek/internal/plans"
+	"github.com/rafagsiqueira/farseek/internal/providers"
+	"github.com/rafagsiqueira/farseek/internal/tfdiags"
+)
+
+func TestLoad(t *testing.T) {
+	policy, diags := Load("testdata/policy.hcl")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if got, want := len(policy.Rules), 2; got != want {
+		t.Fatalf("got %d rules, want %d", got, want)
+	}
+	first, second := policy.Rules[0], policy.Rules[1]
+	if first.Attribute != DefaultAttribute || first.Severity != "error" {
+		t.Errorf("wrong defaults: attribute %q, severity %q", first.Attribute, first.Severity)
+	}
+	if second.Attribute != "labels" || second.Severity != "warning" {
+		t.Errorf("wrong settings: attribute %q, severity %q", second.Attribute, second.Severity)
+	}
+	if got, want := first.DeclRange.Start.Line, 1; got != want {
+		t.Errorf("wrong range of the first rule: line %d, want %d", got, want)
+	}
+	if !first.applies("test_instance") || first.applies("test_untagged_thing") || first.applies("other_instance") {
+		t.Errorf("wrong resource types selected by %q, excluding %q", first.Prefix, first.Exclude)
+	}
+}
+
+func TestLoad_invalid(t *testing.T) {
+	_, diags := Load("testdata/invalid.hcl")
+	got := diags.Err().Error()
+	for _, want := range []string{"Invalid tag policy rule severity", "Tag policy rule requires no tags"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing error %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy, diags := Load("testdata/policy.hcl")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	config, _ := initwd.MustLoadConfigForTests(t, "testdata/config", "tests")
+
+	provider := addrs.NewDefaultProvider("test")
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id":   {Type: cty.String, Computed: true},
+			"tags": {Type: cty.Map(cty.String), Optional: true},
+		},
+	}
+	schemas := &farseek.Schemas{
+		Providers: map[addrs.Provider]providers.ProviderSchema{
+			provider: {
+				ResourceTypes: map[string]providers.Schema{
+					"test_instance": {Block: schema},
+				},
+			},
+		},
+	}
+
+	change := func(name string, action plans.Action, tags cty.Value) *plans.ResourceInstanceChangeSrc {
+		t.Helper()
+		addr := addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: name,
+		}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+		after := cty.ObjectVal(map[string]cty.Value{
+			"id":   cty.UnknownVal(cty.String),
+			"tags": tags,
+		})
+		before := cty.NullVal(after.Type())
+		if action == plans.Delete {
+			before, after = after, before
+		}
+		src, err := (&plans.ResourceInstanceChange{
+			Addr:         addr,
+			PrevRunAddr:  addr,
+			ProviderAddr: addrs.AbsProviderConfig{Provider: provider, Module: addrs.RootModule},
+			Change: plans.Change{
+				Action: action,
+				Before: before,
+				After:  after,
+			},
+		}).Encode(schema.ImpliedType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return src
+	}
+
+	plan := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{
+				change("tagged", plans.Create, cty.MapVal(map[string]cty.Value{
+					"owner": cty.StringVal("platform"),
+					"env":   cty.StringVal(""),
+				})),
+				change("untagged", plans.Create, cty.NullVal(cty.Map(cty.String))),
+				change("unknown", plans.Create, cty.UnknownVal(cty.Map(cty.String))),
+				change("deleted", plans.Delete, cty.NullVal(cty.Map(cty.String))),
+				change("complete", plans.Update, cty.MapVal(map[string]cty.Value{
+					"owner": cty.StringVal("platform"),
+					"env":   cty.UnknownVal(cty.String),
+				})),
+			},
+		},
+	}
+
+	diags = policy.Check(plan, schemas, config)
+	if got, want := len(diags), 2; got != want {
+		t.Fatalf("got %d diagnostics, want %d:\n%s", got, want, diags.ErrWithWarnings())
+	}
+
+	tagged := diags[0]
+	if got, want := tagged.Description().Detail, "test_instance.tagged is missing the tag env"; !strings.HasPrefix(got, want) {
+		t.Errorf("wrong detail\n got: %s\nwant: %s...", got, want)
+	}
+	if got, want := tfdiags.DiagnosticCode(tagged), tfdiags.CodeTagPolicyViolation; got != want {
+		t.Errorf("wrong code %q, want %q", got, want)
+	}
+	if subject := tagged.Source().Subject; subject == nil || subject.Start.Line != 2 {
+		t.Errorf("wrong subject %#v, want the tags attribute", subject)
+	}
+
+	untagged := diags[1]
+	if got, want := untagged.Description().Detail, "test_instance.untagged is missing the tags env, owner"; !strings.HasPrefix(got, want) {
+		t.Errorf("wrong detail\n got: %s\nwant: %s...", got, want)
+	}
+	if subject := untagged.Source().Subject; subject == nil || subject.Start.Line != 7 {
+		t.Errorf("wrong subject %#v, want the resource block", subject)
+	}
+	if untagged.Severity() != tfdiags.Error {
+		t.Errorf("wrong severity %s", untagged.Severity())
+	}
+}
@@ -0,0 +1,8 @@
+resource "test_instance" "tagged" {
+  tags = {
+    owner = "platform"
+  }
+}
+
+resource "test_instance" "untagged" {
+}
@@ -0,0 +1,4 @@
+rule "test_" {
+  required = []
+  severity = "fatal"
+}
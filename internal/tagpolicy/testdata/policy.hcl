@@ -0,0 +1,10 @@
+rule "test_" {
+  required = ["owner", "env"]
+  exclude  = ["test_untagged"]
+}
+
+rule "test_bucket" {
+  attribute = "labels"
+  required  = ["cost-center"]
+  severity  = "warning"
+}
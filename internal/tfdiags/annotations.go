@@ -0,0 +1,62 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tfdiags
+
+// DiagnosticExtraAnnotations is an interface implemented by values in the
+// Extra field of Diagnostic when the diagnostic is about a resource that
+// declares annotations, such as the team that owns it, in its
+// farseek_annotations argument.
+type DiagnosticExtraAnnotations interface {
+	DiagnosticAnnotations() map[string]string
+}
+
+// DiagnosticAnnotations returns the annotations of the resource that the
+// given diagnostic is about, or nil if it has none.
+func DiagnosticAnnotations(diag Diagnostic) map[string]string {
+	maybe := ExtraInfo[DiagnosticExtraAnnotations](diag)
+	if maybe == nil {
+		return nil
+	}
+	return maybe.DiagnosticAnnotations()
+}
+
+// WithAnnotations returns diagnostics that are the same as the given ones,
+// but with errors carrying the given annotations of the resource that they
+// are about. Warnings are returned unchanged.
+func WithAnnotations(diags Diagnostics, annotations map[string]string) Diagnostics {
+	if len(annotations) == 0 || !diags.HasErrors() {
+		return diags
+	}
+	ret := make(Diagnostics, 0, len(diags))
+	for _, diag := range diags {
+		if diag.Severity() == Error {
+			diag = Override(diag, diag.Severity(), func() DiagnosticExtraWrapper {
+				return &annotationsExtra{annotations: annotations}
+			})
+		}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
+type annotationsExtra struct {
+	annotations map[string]string
+	inner       interface{}
+}
+
+var _ DiagnosticExtraAnnotations = (*annotationsExtra)(nil)
+var _ DiagnosticExtraWrapper = (*annotationsExtra)(nil)
+var _ DiagnosticExtraUnwrapper = (*annotationsExtra)(nil)
+
+func (e *annotationsExtra) DiagnosticAnnotations() map[string]string {
+	return e.annotations
+}
+
+func (e *annotationsExtra) WrapDiagnosticExtra(inner interface{}) {
+	e.inner = inner
+}
+
+func (e *annotationsExtra) UnwrapDiagnosticExtra() interface{} {
+	return e.inner
+}
@@ -0,0 +1,38 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tfdiags
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestWithAnnotations(t *testing.T) {
+	annotations := map[string]string{"team": "payments"}
+	var diags Diagnostics
+	diags = diags.Append(WithCode(hclDiagnostic{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "error",
+	}}, CodeApplyInterrupted))
+	diags = diags.Append(hclDiagnostic{&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "warning",
+	}})
+
+	got := WithAnnotations(diags, annotations)
+	if diff := cmp.Diff(annotations, DiagnosticAnnotations(got[0])); diff != "" {
+		t.Errorf("wrong annotations for the error\n%s", diff)
+	}
+	if got, want := DiagnosticCode(got[0]), CodeApplyInterrupted; got != want {
+		t.Errorf("wrong code %q; want %q", got, want)
+	}
+	if got := DiagnosticAnnotations(got[1]); got != nil {
+		t.Errorf("unexpected annotations for the warning: %#v", got)
+	}
+	if got := DiagnosticAnnotations(diags[0]); got != nil {
+		t.Errorf("original diagnostics were changed: %#v", got)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tfdiags
+
+// Code is a stable identifier for a particular kind of diagnostic, such as
+// "FS0101". Unlike a summary, a code doesn't change when we reword a
+// message, so users can rely on it to look up, annotate, or suppress a
+// diagnostic.
+//
+// Only the diagnostics that Farseek itself reports in the command layer, the
+// local backend, the built-in provider, lint, installation, and a few
+// configuration language checks have codes. Diagnostics from elsewhere, such
+// as providers, remote backends, and most of the configuration language,
+// have none, and DiagnosticCode returns "" for them.
+//
+// Codes are never reused. When a diagnostic is removed, its code is retired
+// along with it.
+type Code string
+
+// The codes of the diagnostics that have them. The documentation for each
+// one is in website/docs/cli/diagnostic-codes.mdx, with an anchor matching
+// the lowercased code.
+const (
+	// Git-based change discovery, in the command layer.
+	CodeSHAReadFailed           Code = "FS0001"
+	CodeDiscoveryFailed         Code = "FS0002"
+	CodeInvalidCheckOrder       Code = "FS0003"
+	CodePlanFileLoadFailed      Code = "FS0004"
+	CodeDestroyWithPlanFile     Code = "FS0005"
+	CodePlanFileWriteFailed     Code = "FS0006"
+	CodeIgnoredReadFailed       Code = "FS0007"
+	CodeResourcesIgnored        Code = "FS0008"
+	CodeUnsignedCommits         Code = "FS0009"
+	CodeUnexpectedBranch        Code = "FS0010"
+	CodeUnsupportedJSONProtocol Code = "FS0011"
+	CodeProviderDevOverrides    Code = "FS0012"
+	CodeInvalidBatchSize        Code = "FS0013"
+
+	// Operations in the local backend.
+	CodeApplyInterrupted           Code = "FS0101"
+	CodeOutputsExportFailed        Code = "FS0102"
+	CodeIncompletePlan             Code = "FS0103"
+	CodePlanPartiallyApplied       Code = "FS0104"
+	CodeNoChangesSelected          Code = "FS0105"
+	CodeUntargetedDependencies     Code = "FS0106"
+	CodeStalePlan                  Code = "FS0107"
+	CodeStatePersistFailed         Code = "FS0108"
+	CodeNoConfigurationFiles       Code = "FS0109"
+	CodeSavedPlanRemainderNotSaved Code = "FS0110"
+	CodeInconsistentLockFile       Code = "FS0111"
+	CodeTagPolicyViolation         Code = "FS0112"
+	CodeRunTaskFailed              Code = "FS0113"
+	CodeAmbiguousImport            Code = "FS0114"
+	CodePlanLimitExceeded          Code = "FS0115"
+	CodeDestroyStopped             Code = "FS0116"
+	CodeUnknownProviderInstance    Code = "FS0117"
+	CodeDiscoveredResourceSkipped  Code = "FS0118"
+
+	// The built-in provider.
+	CodeStackNotApplied          Code = "FS0201"
+	CodeStackOutputsUnreadable   Code = "FS0202"
+	CodeInvalidStackOutputsInput Code = "FS0203"
+
+	// Findings of the lint command.
+	CodeUnusedVariable                Code = "FS0301"
+	CodeUnusedLocal                   Code = "FS0302"
+	CodeDeprecatedResourceOutput      Code = "FS0303"
+	CodeUnusedProviderRequirement     Code = "FS0304"
+	CodeDuplicatedProviderRequirement Code = "FS0305"
+
+	// Installation of providers and modules.
+	CodeOfflineNetworkRequired Code = "FS0401"
+	CodeDeprecatedModule       Code = "FS0402"
+
+	// The configuration language.
+	CodeUnsupportedFeature Code = "FS0501"
+)
+
+// DiagnosticExtraCode is an interface implemented by values in the Extra
+// field of Diagnostic when the diagnostic has a Code.
+type DiagnosticExtraCode interface {
+	DiagnosticCode() Code
+}
+
+// DiagnosticCode returns the code of the given diagnostic, or an empty
+// string if it has none.
+func DiagnosticCode(diag Diagnostic) Code {
+	maybe := ExtraInfo[DiagnosticExtraCode](diag)
+	if maybe == nil {
+		return ""
+	}
+	return maybe.DiagnosticCode()
+}
+
+// WithCode returns a diagnostic that is the same as the given one, but with
+// the given code.
+func WithCode(diag Diagnostic, code Code) Diagnostic {
+	return Override(diag, diag.Severity(), func() DiagnosticExtraWrapper {
+		return &codeExtra{code: code}
+	})
+}
+
+// CodeExtra returns a value for the Extra field of an hcl.Diagnostic that
+// gives it the given code, for packages that produce HCL diagnostics.
+func CodeExtra(code Code) DiagnosticExtraCode {
+	return &codeExtra{code: code}
+}
+
+type codeExtra struct {
+	code  Code
+	inner interface{}
+}
+
+var _ DiagnosticExtraCode = (*codeExtra)(nil)
+var _ DiagnosticExtraWrapper = (*codeExtra)(nil)
+var _ DiagnosticExtraUnwrapper = (*codeExtra)(nil)
+
+func (e *codeExtra) DiagnosticCode() Code {
+	return e.code
+}
+
+func (e *codeExtra) WrapDiagnosticExtra(inner interface{}) {
+	e.inner = inner
+}
+
+func (e *codeExtra) UnwrapDiagnosticExtra() interface{} {
+	return e.inner
+}
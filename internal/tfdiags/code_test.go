@@ -0,0 +1,33 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tfdiags
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestWithCode(t *testing.T) {
+	original := hclDiagnostic{&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "summary",
+		Detail:   "detail",
+		Extra:    "extra",
+	}}
+	if got := DiagnosticCode(original); got != "" {
+		t.Errorf("unexpected code %q for a diagnostic without one", got)
+	}
+
+	coded := WithCode(original, CodeApplyInterrupted)
+	if got, want := DiagnosticCode(coded), CodeApplyInterrupted; got != want {
+		t.Errorf("wrong code %q; want %q", got, want)
+	}
+	if coded.Severity() != Warning || !coded.Description().Equal(original.Description()) {
+		t.Errorf("diagnostic changed other than its code: %#v", coded)
+	}
+	if got := ExtraInfoNext[interface{}](coded.ExtraInfo()); got != "extra" {
+		t.Errorf("original extra info was not kept, got %#v", got)
+	}
+}
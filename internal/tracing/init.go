@@ -79,7 +79,8 @@ var isTracingEnabled bool
 //
 // However, for those running Farseek in automation we allow setting
 // the standard OpenTelemetry environment variable OTEL_TRACES_EXPORTER=otlp
-// to enable an OTLP exporter, which is in turn configured by all the
+// to enable an OTLP exporter, and OTEL_METRICS_EXPORTER=otlp to enable
+// an OTLP metrics exporter, which are in turn configured by all the
 // standard OTLP exporter environment variables:
 //
 //	https://opentelemetry.io/docs/specs/otel/protocol/exporter/#configuration-options
@@ -94,20 +95,19 @@ var isTracingEnabled bool
 // if TRACEPARENT is set.
 func OpenTelemetryInit(ctx context.Context) (context.Context, error) {
 	isTracingEnabled = false
+	isMetricsEnabled = false
 
-	// We'll check the environment variable ourselves first, because the
-	// "autoexport" helper we're about to use is built under the assumption
+	// We'll check the environment variables ourselves first, because the
+	// "autoexport" helpers we're about to use are built under the assumption
 	// that exporting should always be enabled and so will expect to find
 	// an OTLP server on localhost if no environment variables are set at all.
-	if os.Getenv(OTELExporterEnvVar) != "otlp" {
-		log.Printf("[TRACE] OpenTelemetry: %s not set, OTel tracing is not enabled", OTELExporterEnvVar)
+	tracesEnabled := os.Getenv(OTELExporterEnvVar) == "otlp"
+	metricsEnabled := os.Getenv(OTELMetricsExporterEnvVar) == "otlp"
+	if !tracesEnabled && !metricsEnabled {
+		log.Printf("[TRACE] OpenTelemetry: %s and %s not set, OTel is not enabled", OTELExporterEnvVar, OTELMetricsExporterEnvVar)
 		return ctx, nil // By default, we just discard all telemetry calls
 	}
 
-	isTracingEnabled = true
-
-	log.Printf("[TRACE] OpenTelemetry: enabled")
-
 	// Get service name from environment variable or use default
 	serviceName := DefaultServiceName
 	if envServiceName := os.Getenv(ServiceNameEnvVar); envServiceName != "" {
@@ -120,6 +120,24 @@ func OpenTelemetryInit(ctx context.Context) (context.Context, error) {
 		return ctx, fmt.Errorf("failed to create resource: %w", err)
 	}
 
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Printf("[ERROR] OpenTelemetry error: %s", err)
+	}))
+
+	if metricsEnabled {
+		if err := metricsInit(ctx, otelResource); err != nil {
+			return ctx, err
+		}
+	}
+	if !tracesEnabled {
+		log.Printf("[TRACE] OpenTelemetry: %s not set, OTel tracing is not enabled", OTELExporterEnvVar)
+		return ctx, nil
+	}
+
+	isTracingEnabled = true
+
+	log.Printf("[TRACE] OpenTelemetry: tracing enabled")
+
 	// Check if the trace parent/state environment variable is set and extract it into our context
 	if traceparent := os.Getenv(traceParentEnvVar); traceparent != "" {
 		log.Printf("[TRACE] OpenTelemetry: found trace parent in environment: %s", traceparent)
@@ -161,9 +179,5 @@ func OpenTelemetryInit(ctx context.Context) (context.Context, error) {
 	logger := stdr.New(log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile))
 	otel.SetLogger(logger)
 
-	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
-		log.Printf("[ERROR] OpenTelemetry error: %s", err)
-	}))
-
 	return ctx, nil
 }
@@ -0,0 +1,94 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tracing
+
+import (
+	"context"
+	"log"
+	"runtime"
+	"time"
+
+	"go.opentelemetry.io/contrib/exporters/autoexport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// OTELMetricsExporterEnvVar is the env var that should be used to instruct
+// Farseek which metrics exporter to use. If this environment variable is set
+// to "otlp" when running Farseek CLI then we'll enable an experimental OTLP
+// metrics exporter.
+const OTELMetricsExporterEnvVar = "OTEL_METRICS_EXPORTER"
+
+// isMetricsEnabled is true if OpenTelemetry metrics are enabled.
+var isMetricsEnabled bool
+
+// metricsInit sets the global meter provider to one that exports metrics as
+// the standard OpenTelemetry environment variables say.
+func metricsInit(ctx context.Context, otelResource *resource.Resource) error {
+	reader, err := autoexport.NewMetricReader(ctx)
+	if err != nil {
+		return err
+	}
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(otelResource),
+	))
+	isMetricsEnabled = true
+
+	log.Printf("[TRACE] OpenTelemetry: metrics enabled")
+	return nil
+}
+
+// AddToCounter adds n to the counter with the given name, in the meter named
+// after the calling package, creating the counter with the given unit and
+// description if it doesn't exist yet. It does nothing unless metrics are
+// enabled.
+//
+// Counter names should be in the "farseek." namespace, and units should use
+// the UCUM case-sensitive syntax, such as "By" for bytes or "{download}" for
+// a count of downloads, as the OpenTelemetry semantic conventions recommend.
+func AddToCounter(ctx context.Context, name, unit, description string, n int64, attrs ...attribute.KeyValue) {
+	if !isMetricsEnabled {
+		return
+	}
+
+	meterName := ""
+	if pc, _, _, ok := runtime.Caller(1); ok && runtime.FuncForPC(pc) != nil {
+		meterName = extractImportPath(runtime.FuncForPC(pc).Name())
+	}
+	counter, err := otel.GetMeterProvider().Meter(meterName).Int64Counter(name,
+		metric.WithUnit(unit),
+		metric.WithDescription(description),
+	)
+	if err != nil {
+		log.Printf("[WARN] OpenTelemetry: failed to create counter %s: %s", name, err)
+		return
+	}
+	counter.Add(ctx, n, metric.WithAttributes(attrs...))
+}
+
+// forceFlushMetrics ensures that all metrics are exported to the collector
+// before the application terminates, like [ForceFlush] does for spans.
+func forceFlushMetrics(timeout time.Duration) {
+	if !isMetricsEnabled {
+		return
+	}
+
+	provider, ok := otel.GetMeterProvider().(*sdkmetric.MeterProvider)
+	if !ok {
+		log.Printf("[TRACE] OpenTelemetry: meter provider is not an SDK provider, can't force flush")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Printf("[TRACE] OpenTelemetry: flushing metrics")
+	if err := provider.ForceFlush(ctx); err != nil {
+		log.Printf("[WARN] OpenTelemetry: error flushing metrics: %v", err)
+	}
+}
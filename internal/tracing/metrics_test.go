@@ -0,0 +1,66 @@
+// Copyright (c) The Farseek Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestAddToCounter(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+
+	prevProvider := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() {
+		otel.SetMeterProvider(prevProvider)
+		isMetricsEnabled = false
+	})
+
+	// Nothing is counted while metrics are disabled.
+	isMetricsEnabled = false
+	AddToCounter(ctx, "farseek.test.things", "{thing}", "Things.", 1)
+
+	isMetricsEnabled = true
+	AddToCounter(ctx, "farseek.test.things", "{thing}", "Things.", 2, attribute.String("kind", "a"))
+	AddToCounter(ctx, "farseek.test.things", "{thing}", "Things.", 3, attribute.String("kind", "a"))
+	AddToCounter(ctx, "farseek.test.things", "{thing}", "Things.", 4, attribute.String("kind", "b"))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.ScopeMetrics) != 1 {
+		t.Fatalf("wrong number of scopes %d; want 1", len(rm.ScopeMetrics))
+	}
+	scope := rm.ScopeMetrics[0]
+	if got, want := scope.Scope.Name, "github.com/rafagsiqueira/farseek/internal/tracing"; got != want {
+		t.Errorf("wrong meter name %q; want %q", got, want)
+	}
+	if len(scope.Metrics) != 1 {
+		t.Fatalf("wrong number of metrics %d; want 1", len(scope.Metrics))
+	}
+	m := scope.Metrics[0]
+	if m.Name != "farseek.test.things" || m.Unit != "{thing}" || m.Description != "Things." {
+		t.Errorf("wrong metric %q (%q, %q)", m.Name, m.Unit, m.Description)
+	}
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("wrong metric data %T; want a sum", m.Data)
+	}
+	got := map[string]int64{}
+	for _, dp := range sum.DataPoints {
+		kind, _ := dp.Attributes.Value("kind")
+		got[kind.AsString()] = dp.Value
+	}
+	if got["a"] != 5 || got["b"] != 4 || len(got) != 2 {
+		t.Errorf("wrong counts %v; want a=5, b=4", got)
+	}
+}
@@ -11,6 +11,10 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// KeyValue is an alias for [attribute.KeyValue] just so that callers can
+// collect attributes without importing the OpenTelemetry packages directly.
+type KeyValue = attribute.KeyValue
+
 // String wraps [attribute.String] just so that we can keep most of our direct
 // OpenTelemetry package imports centralized in this package where it's
 // easier to keep our version selections consistent.
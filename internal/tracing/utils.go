@@ -70,13 +70,14 @@ func SetSpanError(span trace.Span, input any) {
 	}
 }
 
-// ForceFlush ensures that all spans are exported to the collector before
-// the application terminates. This is particularly important for CLI
+// ForceFlush ensures that all spans and metrics are exported to the collector
+// before the application terminates. This is particularly important for CLI
 // applications where the process exits immediately after the operation.
 //
 // This should be called before the application terminates to ensure
-// all spans are exported properly.
+// all spans and metrics are exported properly.
 func ForceFlush(timeout time.Duration) {
+	forceFlushMetrics(timeout)
 	if !isTracingEnabled {
 		return
 	}
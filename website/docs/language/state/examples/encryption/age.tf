@@ -0,0 +1,19 @@
+variable "age_identity" {
+  type      = string
+  sensitive = true
+}
+
+terraform {
+  encryption {
+    key_provider "age" "team" {
+      # The identity to decrypt with. The key is also encrypted for it.
+      identities = [var.age_identity]
+
+      # Optional. Other recipients to encrypt the key for, such as the
+      # public keys of your colleagues or of a recovery key.
+      recipients = [
+        "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+      ]
+    }
+  }
+}
@@ -0,0 +1,22 @@
+terraform {
+  encryption {
+    key_provider "vault" "my_vault" {
+
+      # Required. Name of the transit encryption key
+      # to use to encrypt/decrypt the data key.
+      key_name = "farseek-state"
+
+      # Optional. Authorization Token to use when accessing the Vault API.
+      # You can also set this in the VAULT_TOKEN environment variable.
+      token = "hvs.CAESIJx4bOrF0C1lWmm3tQ3zs0Yq"
+
+      # Optional. Vault server address to access the API on.
+      # You can also set this using the VAULT_ADDR environment variable.
+      address = "https://vault.example.com:8200"
+
+      # Optional. You can customize this if you mounted the
+      # transit engine on a different path. Default: /transit
+      transit_engine_path = "/my-org/transit"
+    }
+  }
+}